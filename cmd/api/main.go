@@ -2,22 +2,40 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/events"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
 	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/router"
+	"golang.org/x/sync/errgroup"
+
+	_ "github.com/cloudsweep/cloudsweep/internal/infrastructure/kubernetes" // registers the Kubernetes provider
 )
 
 var version = "dev"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "support-bundle" {
+		runSupportBundle(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	log.Printf("Starting CloudSweep API %s", version)
 
 	// Load configuration
@@ -25,6 +43,14 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	log.Printf("Configuration:\n%s", cfg.Dump())
+
+	// Operational settings are reloaded on SIGHUP, changes of the
+	// configuration file and POST /admin/reload
+	live := config.NewLive(cfg)
 
 	// Initialize database
 	db, err := database.NewPostgresConnection(cfg.Database)
@@ -32,9 +58,19 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Run migrations
-	if err := database.AutoMigrate(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// The schema is migrated by the migrate subcommand, unless configured to
+	// migrate on start; refuse to serve a schema of another version
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if cfg.Database.MigrateOnStart {
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		log.Fatalf("%v (run \"api migrate up\")", err)
 	}
 
 	// Initialize queue client
@@ -42,10 +78,33 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
-	defer queueClient.Close()
+
+	inspector := queue.NewInspector(cfg.Redis)
+
+	// Initialize object storage
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Rate limits and scan fairness are shared across instances through Redis
+	redisClient := database.NewRedisClient(cfg.Redis)
+
+	limiter := ratelimit.NewRedisLimiter(redisClient)
+	fair := queue.NewFairScheduler(redisClient, cfg.Fairness)
+
+	// Live events published by the workers are streamed to the dashboard
+	bus := events.NewBus(redisClient)
+
+	// Dashboards and organization settings are cached in Redis, and dropped
+	// on writes by any instance or worker
+	var results *cache.Cache
+	if cfg.Cache.Enabled {
+		results = cache.New(redisClient)
+	}
 
 	// Setup router
-	r := router.NewRouter(db, queueClient, cfg)
+	r := router.NewRouter(db, queueClient, inspector, store, limiter, fair, bus, queue.NewWorkerRegistry(redisClient), results, live)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -55,27 +114,69 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	srv.RegisterOnShutdown(bus.Shutdown)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	g, gCtx := errgroup.WithContext(ctx)
 
-	// Start server in goroutine
-	go func() {
+	g.Go(func() error {
 		log.Printf("Server listening on port %s", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed: %v", err)
+			return fmt.Errorf("server failed: %w", err)
 		}
-	}()
+		return nil
+	})
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// Graceful shutdown: stop accepting connections and let in-flight
+	// requests finish before the deadline
+	g.Go(func() error {
+		<-gCtx.Done()
+		log.Println("Shutting down server...")
 
-	log.Println("Shutting down server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("server forced to shutdown: %w", err)
+		}
+		return nil
+	})
+
+	// Settings are read once: shut down gracefully on secret rotation so
+	// that the supervisor restarts the process with the new secrets
+	g.Go(func() error {
+		cfg.WatchSecrets(gCtx, func(settings []string) {
+			log.Printf("Secrets rotated (%s), restarting to apply them", strings.Join(settings, ", "))
+			stop()
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		live.Watch(gCtx)
+		return nil
+	})
+
+	err = g.Wait()
+
+	// Release connections only once no request can still use them
+	if cerr := queueClient.Close(); cerr != nil {
+		log.Printf("Failed to close queue client: %v", cerr)
+	}
+	if cerr := inspector.Close(); cerr != nil {
+		log.Printf("Failed to close queue inspector: %v", cerr)
+	}
+	if cerr := redisClient.Close(); cerr != nil {
+		log.Printf("Failed to close Redis client: %v", cerr)
+	}
+	if cerr := database.Close(db); cerr != nil {
+		log.Printf("Failed to close database: %v", cerr)
+	}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
 	log.Println("Server exited properly")
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+)
+
+const migrateUsage = `Usage: api migrate <command>
+
+Commands:
+  up           Apply all pending migrations
+  down [n]     Roll back the last n migrations (default 1)
+  goto V       Migrate up or down to version V
+  force V      Set the version to V without running migrations
+  version      Print the current and expected versions
+  status       List the migrations and whether they are applied
+`
+
+// runMigrate implements the "migrate" subcommand
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewPostgresConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(db)
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch cmd := fs.Arg(0); cmd {
+	case "up":
+		n, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Failed to migrate: %v", err)
+		}
+		log.Printf("Applied %d migrations, schema at version %d", n, migrator.Latest())
+	case "down":
+		steps := 1
+		if fs.NArg() > 1 {
+			if steps, err = strconv.Atoi(fs.Arg(1)); err != nil || steps < 1 {
+				log.Fatalf("Invalid number of migrations: %s", fs.Arg(1))
+			}
+		}
+		n, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Failed to roll back: %v", err)
+		}
+		log.Printf("Rolled back %d migrations", n)
+	case "goto", "force":
+		if fs.NArg() < 2 {
+			log.Fatalf("Usage: api migrate %s VERSION", cmd)
+		}
+		version, err := strconv.ParseUint(fs.Arg(1), 10, 32)
+		if err != nil {
+			log.Fatalf("Invalid version: %s", fs.Arg(1))
+		}
+		if cmd == "force" {
+			if err := migrator.Force(ctx, uint(version)); err != nil {
+				log.Fatalf("Failed to force version: %v", err)
+			}
+			log.Printf("Schema version set to %d", version)
+			return
+		}
+		n, err := migrator.Goto(ctx, uint(version))
+		if err != nil {
+			log.Fatalf("Failed to migrate: %v", err)
+		}
+		log.Printf("Ran %d migrations, schema at version %d", n, version)
+	case "version", "status":
+		current, dirty, err := migrator.Version(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read schema version: %v", err)
+		}
+		if cmd == "status" {
+			for _, m := range migrator.Migrations() {
+				state := "pending"
+				if m.Version <= current {
+					state = "applied"
+				}
+				fmt.Printf("%06d  %-40s %s\n", m.Version, m.Name, state)
+			}
+		}
+		fmt.Printf("version %d (expected %d)", current, migrator.Latest())
+		if dirty {
+			fmt.Print(", dirty")
+		}
+		fmt.Println()
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/supportbundle"
+)
+
+// runSupportBundle implements the "support-bundle" subcommand
+func runSupportBundle(args []string) {
+	fs := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+	output := fs.String("o", "", "Output file (default: cloudsweep-support-<timestamp>.tar.gz, or .json with -demo)")
+	logFiles := fs.String("logs", "", "Comma-separated log files to include")
+	sampleSize := fs.Int("sample", 100, "Number of sample resources and scans to export")
+	demo := fs.Bool("demo", false, "Export anonymized data with readable pseudonyms for demos and screenshots")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewPostgresConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(db)
+
+	opts := supportbundle.Options{
+		Output:     *output,
+		SampleSize: *sampleSize,
+		Demo:       *demo,
+	}
+	if *logFiles != "" {
+		opts.LogFiles = strings.Split(*logFiles, ",")
+	}
+	if opts.Output == "" {
+		ext := "tar.gz"
+		if opts.Demo {
+			ext = "json"
+		}
+		opts.Output = fmt.Sprintf("cloudsweep-support-%s.%s", time.Now().Format("20060102-150405"), ext)
+	}
+
+	generator, err := supportbundle.NewGenerator(db, cfg, version)
+	if err != nil {
+		log.Fatalf("Failed to create support bundle generator: %v", err)
+	}
+
+	if err := generator.Generate(context.Background(), opts); err != nil {
+		log.Fatalf("Failed to generate support bundle: %v", err)
+	}
+
+	log.Printf("Support bundle written to %s", opts.Output)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runCleanup implements the "cleanup" command: it queues the cleanup of
+// resources given as arguments, in a file (one ID per line, - for stdin) or
+// by a saved view
+func runCleanup(args []string) error {
+	var g globals
+	fs := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	g.register(fs)
+	action := fs.String("action", "delete", "Action: delete, stop, tag, notify, quarantine, release or ticket")
+	dryRun := fs.Bool("dry-run", false, "Report what would be done without changing anything")
+	fromFile := fs.String("from-file", "", "File of resource IDs, one per line (- for stdin)")
+	view := fs.String("view", "", "Saved view ID, in place of resource IDs")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	org, err := g.requireOrg()
+	if err != nil {
+		return err
+	}
+
+	ids := fs.Args()
+	if *fromFile != "" {
+		fileIDs, err := readIDs(*fromFile)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, fileIDs...)
+	}
+	if len(ids) == 0 && *view == "" {
+		return errors.New("no resources, pass IDs, --from-file or --view")
+	}
+	if len(ids) > 0 && *view != "" {
+		return errors.New("--view cannot be combined with resource IDs")
+	}
+
+	body := map[string]any{
+		"organization_id": org,
+		"action":          *action,
+		"dry_run":         *dryRun,
+	}
+	if *view != "" {
+		body["view_id"] = *view
+	} else {
+		body["resource_ids"] = ids
+	}
+
+	var resp struct {
+		Message string `json:"message"`
+		TaskID  string `json:"task_id"`
+		DryRun  bool   `json:"dry_run"`
+	}
+	if err := g.client().post(context.Background(), "/cleanup", body, &resp); err != nil {
+		return err
+	}
+	if g.output == "json" {
+		return printJSON(resp)
+	}
+	mode := ""
+	if resp.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Printf("%s%s: task %s\n", resp.Message, mode, resp.TaskID)
+	return nil
+}
+
+// readIDs reads resource IDs, one per line, skipping blank lines and #
+// comments
+func readIDs(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	return ids, scanner.Err()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultAPIURL is used when neither the profile nor the flags set one
+const defaultAPIURL = "http://localhost:8080"
+
+// cliConfig is the configuration file of the CLI, holding named profiles
+type cliConfig struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*profile `yaml:"profiles"`
+}
+
+// profile is an API endpoint and its credentials
+type profile struct {
+	APIURL         string `yaml:"api_url"`
+	APIKey         string `yaml:"api_key,omitempty"`
+	OrganizationID string `yaml:"organization_id,omitempty"`
+}
+
+// configPath returns the path of the configuration file:
+// $CLOUDSWEEP_CONFIG, or cloudsweep/config.yaml in the user configuration
+// directory
+func configPath() (string, error) {
+	if path := os.Getenv("CLOUDSWEEP_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cloudsweep", "config.yaml"), nil
+}
+
+// loadConfig reads the configuration file, empty when it does not exist
+func loadConfig() (*cliConfig, error) {
+	cfg := &cliConfig{Profiles: map[string]*profile{}}
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*profile{}
+	}
+	return cfg, nil
+}
+
+// save writes the configuration file, readable by the user only since it
+// holds API keys
+func (c *cliConfig) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// profile returns a profile by name, the current one when name is empty. A
+// missing profile is only an error when named explicitly.
+func (c *cliConfig) profile(name string) (*profile, error) {
+	explicit := name != ""
+	if !explicit {
+		name = c.Current
+	}
+	if p, ok := c.Profiles[name]; ok {
+		return p, nil
+	}
+	if explicit {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	return &profile{}, nil
+}
+
+// runConfig implements the "config" command
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: cloudsweep config set|use|list|show")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "set":
+		fs := flag.NewFlagSet("config set", flag.ContinueOnError)
+		name := fs.String("profile", "default", "Profile to create or update")
+		apiURL := fs.String("api-url", "", "API URL")
+		apiKey := fs.String("api-key", "", "API key")
+		org := fs.String("org", "", "Organization ID")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		p, ok := cfg.Profiles[*name]
+		if !ok {
+			p = &profile{APIURL: defaultAPIURL}
+			cfg.Profiles[*name] = p
+		}
+		if *apiURL != "" {
+			p.APIURL = *apiURL
+		}
+		if *apiKey != "" {
+			p.APIKey = *apiKey
+		}
+		if *org != "" {
+			p.OrganizationID = *org
+		}
+		if cfg.Current == "" {
+			cfg.Current = *name
+		}
+		if err := cfg.save(); err != nil {
+			return err
+		}
+		fmt.Printf("Profile %s saved\n", *name)
+	case "use":
+		if len(args) != 1 {
+			return errors.New("usage: cloudsweep config use PROFILE")
+		}
+		if _, ok := cfg.Profiles[args[0]]; !ok {
+			return fmt.Errorf("unknown profile %q", args[0])
+		}
+		cfg.Current = args[0]
+		if err := cfg.save(); err != nil {
+			return err
+		}
+		fmt.Printf("Using profile %s\n", args[0])
+	case "list":
+		names := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		rows := make([][]string, 0, len(names))
+		for _, name := range names {
+			current := ""
+			if name == cfg.Current {
+				current = "*"
+			}
+			p := cfg.Profiles[name]
+			rows = append(rows, []string{current, name, p.APIURL, p.OrganizationID})
+		}
+		return printTable([]string{"CURRENT", "NAME", "API URL", "ORGANIZATION"}, rows)
+	case "show":
+		fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+		name := fs.String("profile", "", "Profile to show (default: the current one)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		p, err := cfg.profile(*name)
+		if err != nil {
+			return err
+		}
+		path, _ := configPath()
+		fmt.Printf("config:       %s\n", path)
+		fmt.Printf("profile:      %s\n", firstNonEmpty(*name, cfg.Current, "(none)"))
+		fmt.Printf("api_url:      %s\n", firstNonEmpty(p.APIURL, defaultAPIURL))
+		fmt.Printf("api_key:      %s\n", maskKey(p.APIKey))
+		fmt.Printf("organization: %s\n", p.OrganizationID)
+	default:
+		return fmt.Errorf("unknown config command %q", sub)
+	}
+	return nil
+}
+
+// maskKey hides all but the last characters of an API key
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", len(key)-4) + key[len(key)-4:]
+}
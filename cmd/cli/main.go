@@ -0,0 +1,132 @@
+// Command cloudsweep is a command-line client of the CloudSweep API, for
+// scripting and CI.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+var version = "dev"
+
+const usage = `Usage: cloudsweep <command> [flags]
+
+Commands:
+  config      Manage profiles (set, use, list, show)
+  scan        Start and follow scans (start, list, get)
+  resources   List resources (list)
+  cleanup     Queue the cleanup of resources
+  version     Print the version
+
+Flags common to all commands:
+  --profile NAME   Profile to use (default: the current one, or $CLOUDSWEEP_PROFILE)
+  --api-url URL    API URL (default: the profile's, or $CLOUDSWEEP_API_URL)
+  --api-key KEY    API key (default: the profile's, or $CLOUDSWEEP_API_KEY)
+  --org ID         Organization ID (default: the profile's, or $CLOUDSWEEP_ORGANIZATION_ID)
+  -o FORMAT        Output format: table or json (default table)
+
+Run "cloudsweep <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "config":
+		err = runConfig(args)
+	case "scan":
+		err = runScan(args)
+	case "resources":
+		err = runResources(args)
+	case "cleanup":
+		err = runCleanup(args)
+	case "version":
+		fmt.Println(version)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// globals are the flags common to all commands
+type globals struct {
+	profile string
+	apiURL  string
+	apiKey  string
+	org     string
+	output  string
+}
+
+// register adds the common flags to the flag set of a command
+func (g *globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.profile, "profile", os.Getenv("CLOUDSWEEP_PROFILE"), "Profile to use")
+	fs.StringVar(&g.apiURL, "api-url", "", "API URL")
+	fs.StringVar(&g.apiKey, "api-key", "", "API key")
+	fs.StringVar(&g.org, "org", "", "Organization ID")
+	fs.StringVar(&g.output, "o", "table", "Output format: table or json")
+}
+
+// resolve fills the settings left empty by the flags from the environment,
+// then from the profile
+func (g *globals) resolve() error {
+	if g.output != "table" && g.output != "json" {
+		return fmt.Errorf("invalid output format %q, expected table or json", g.output)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	p, err := cfg.profile(g.profile)
+	if err != nil {
+		return err
+	}
+	g.apiURL = firstNonEmpty(g.apiURL, os.Getenv("CLOUDSWEEP_API_URL"), p.APIURL, defaultAPIURL)
+	g.apiKey = firstNonEmpty(g.apiKey, os.Getenv("CLOUDSWEEP_API_KEY"), p.APIKey)
+	g.org = firstNonEmpty(g.org, os.Getenv("CLOUDSWEEP_ORGANIZATION_ID"), p.OrganizationID)
+	return nil
+}
+
+// client returns a client of the resolved API
+func (g *globals) client() *client {
+	return newClient(g.apiURL, g.apiKey)
+}
+
+// requireOrg returns the organization ID, which some commands need
+func (g *globals) requireOrg() (string, error) {
+	if g.org == "" {
+		return "", errors.New("no organization, set one with --org or in the profile")
+	}
+	return g.org, nil
+}
+
+// parse parses the flags of a command and resolves the common settings
+func (g *globals) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return g.resolve()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// resource is the part of the resources of the API shown by the CLI
+type resource struct {
+	ID              string            `json:"id"`
+	Provider        string            `json:"provider"`
+	Type            string            `json:"type"`
+	ResourceID      string            `json:"resource_id"`
+	Region          string            `json:"region"`
+	AccountID       string            `json:"account_id,omitempty"`
+	Name            string            `json:"name"`
+	Status          string            `json:"status"`
+	Tags            map[string]string `json:"tags"`
+	MonthlyCost     float64           `json:"monthly_cost"`
+	CarbonFootprint float64           `json:"carbon_footprint_kg"`
+	LastSeenAt      time.Time         `json:"last_seen_at"`
+}
+
+// runResources implements the "resources" command
+func runResources(args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return errors.New("usage: cloudsweep resources list")
+	}
+	args = args[1:]
+
+	var g globals
+	fs := flag.NewFlagSet("resources list", flag.ContinueOnError)
+	g.register(fs)
+	provider := fs.String("provider", "", "Filter by provider")
+	typ := fs.String("type", "", "Filter by resource type")
+	status := fs.String("status", "", "Filter by status: active, unused, deleted or excluded")
+	region := fs.String("region", "", "Filter by region")
+	account := fs.String("account", "", "Filter by provider account")
+	q := fs.String("q", "", "Search, with tag:key=value selectors")
+	minCost := fs.Float64("min-cost", 0, "Minimum monthly cost")
+	sort := fs.String("sort", "", "Sort by created, cost, carbon or age")
+	view := fs.String("view", "", "Saved view ID")
+	limit := fs.Int("limit", 50, "Resources per page")
+	all := fs.Bool("all", false, "Fetch all pages")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+
+	query := url.Values{"limit": {strconv.Itoa(*limit)}}
+	setIf(query, "provider", *provider)
+	setIf(query, "type", *typ)
+	setIf(query, "status", *status)
+	setIf(query, "region", *region)
+	setIf(query, "account_id", *account)
+	setIf(query, "q", *q)
+	setIf(query, "sort", *sort)
+	setIf(query, "view_id", *view)
+	if *minCost > 0 {
+		query.Set("min_cost", strconv.FormatFloat(*minCost, 'f', -1, 64))
+	}
+
+	c := g.client()
+	var resources []resource
+	var total int64
+	for {
+		var page struct {
+			Data       []resource `json:"data"`
+			Total      int64      `json:"total"`
+			NextCursor string     `json:"next_cursor"`
+		}
+		if err := c.get(context.Background(), "/resources", query, &page); err != nil {
+			return err
+		}
+		resources = append(resources, page.Data...)
+		total = page.Total
+		if !*all || page.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+
+	if g.output == "json" {
+		return printJSON(resources)
+	}
+	rows := make([][]string, 0, len(resources))
+	for _, r := range resources {
+		rows = append(rows, []string{
+			r.ID,
+			r.Provider,
+			r.Type,
+			firstNonEmpty(r.Name, r.ResourceID),
+			r.Region,
+			r.Status,
+			money(r.MonthlyCost),
+			fmt.Sprintf("%.2f", r.CarbonFootprint),
+		})
+	}
+	if err := printTable([]string{"ID", "PROVIDER", "TYPE", "NAME", "REGION", "STATUS", "COST/MONTH", "CO2E KG"}, rows); err != nil {
+		return err
+	}
+	if int64(len(resources)) < total {
+		fmt.Fprintf(os.Stderr, "\n%d of %d resources, use --all for every page\n", len(resources), total)
+	}
+	return nil
+}
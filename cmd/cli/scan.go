@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scan is the part of the scans of the API shown by the CLI
+type scan struct {
+	ID               string     `json:"id"`
+	Provider         string     `json:"provider"`
+	Regions          []string   `json:"regions"`
+	Status           string     `json:"status"`
+	ResourcesFound   int        `json:"resources_found"`
+	UnusedFound      int        `json:"unused_found"`
+	EstimatedSavings float64    `json:"estimated_savings"`
+	ErrorMessage     string     `json:"error_message,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// done reports whether a scan has stopped running
+func (s scan) done() bool {
+	switch s.Status {
+	case "completed", "partial", "failed", "cancelled":
+		return true
+	}
+	return false
+}
+
+// runScan implements the "scan" command
+func runScan(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: cloudsweep scan start|list|get")
+	}
+	switch sub, args := args[0], args[1:]; sub {
+	case "start":
+		return scanStart(args)
+	case "list":
+		return scanList(args)
+	case "get":
+		return scanGet(args)
+	default:
+		return fmt.Errorf("unknown scan command %q", sub)
+	}
+}
+
+// scanStart queues a scan, and follows it with --wait. With --wait, a scan
+// that does not complete makes the command fail, for CI.
+func scanStart(args []string) error {
+	var g globals
+	fs := flag.NewFlagSet("scan start", flag.ContinueOnError)
+	g.register(fs)
+	provider := fs.String("provider", "", "Cloud provider: aws, azure, gcp or kubernetes")
+	regions := fs.String("regions", "", "Comma-separated regions (default: the organization's)")
+	types := fs.String("types", "", "Comma-separated resource types (default: all)")
+	force := fs.Bool("force", false, "Start even if an identical scan is running")
+	wait := fs.Bool("wait", false, "Wait for the scan to finish")
+	interval := fs.Duration("interval", 5*time.Second, "Polling interval with --wait")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if *provider == "" {
+		return errors.New("--provider is required")
+	}
+	org, err := g.requireOrg()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	body := map[string]any{
+		"organization_id": org,
+		"provider":        *provider,
+		"regions":         splitList(*regions),
+		"resource_types":  splitList(*types),
+		"force":           *force,
+	}
+	var resp struct {
+		Data    scan   `json:"data"`
+		Message string `json:"message"`
+	}
+	c := g.client()
+	if err := c.post(ctx, "/scans", body, &resp); err != nil {
+		return err
+	}
+	if !*wait {
+		return printScans(g.output, resp.Data)
+	}
+
+	fmt.Fprintf(os.Stderr, "Scan %s %s\n", resp.Data.ID, resp.Message)
+	current := resp.Data
+	for !current.done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+		var got struct {
+			Data scan `json:"data"`
+		}
+		if err := c.get(ctx, "/scans/"+current.ID, nil, &got); err != nil {
+			return err
+		}
+		current = got.Data
+	}
+	if err := printScans(g.output, current); err != nil {
+		return err
+	}
+	if current.Status != "completed" {
+		return fmt.Errorf("scan %s %s", current.ID, current.Status)
+	}
+	return nil
+}
+
+// scanList lists the most recent scans
+func scanList(args []string) error {
+	var g globals
+	fs := flag.NewFlagSet("scan list", flag.ContinueOnError)
+	g.register(fs)
+	provider := fs.String("provider", "", "Filter by provider")
+	status := fs.String("status", "", "Filter by status")
+	limit := fs.Int("limit", 20, "Number of scans")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+
+	query := url.Values{"limit": {strconv.Itoa(*limit)}}
+	setIf(query, "provider", *provider)
+	setIf(query, "status", *status)
+	var resp struct {
+		Data []scan `json:"data"`
+	}
+	if err := g.client().get(context.Background(), "/scans", query, &resp); err != nil {
+		return err
+	}
+	return printScans(g.output, resp.Data...)
+}
+
+// scanGet shows a scan
+func scanGet(args []string) error {
+	var g globals
+	fs := flag.NewFlagSet("scan get", flag.ContinueOnError)
+	g.register(fs)
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: cloudsweep scan get SCAN_ID")
+	}
+
+	var resp struct {
+		Data scan `json:"data"`
+	}
+	if err := g.client().get(context.Background(), "/scans/"+url.PathEscape(fs.Arg(0)), nil, &resp); err != nil {
+		return err
+	}
+	return printScans(g.output, resp.Data)
+}
+
+func printScans(output string, scans ...scan) error {
+	if output == "json" {
+		if len(scans) == 1 {
+			return printJSON(scans[0])
+		}
+		return printJSON(scans)
+	}
+	rows := make([][]string, 0, len(scans))
+	for _, s := range scans {
+		rows = append(rows, []string{
+			s.ID,
+			s.Provider,
+			s.Status,
+			strings.Join(s.Regions, ","),
+			strconv.Itoa(s.ResourcesFound),
+			strconv.Itoa(s.UnusedFound),
+			money(s.EstimatedSavings),
+			s.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return printTable([]string{"ID", "PROVIDER", "STATUS", "REGIONS", "RESOURCES", "UNUSED", "SAVINGS", "CREATED"}, rows)
+}
+
+// splitList splits a comma-separated flag, nil when empty
+func splitList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// setIf sets a query parameter when the value is not empty
+func setIf(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
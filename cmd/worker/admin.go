@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+)
+
+// newAdminServer creates the admin HTTP server of the worker: liveness,
+// expvar metrics and the status published in its heartbeats
+func newAdminServer(addr string, heartbeat *queue.Heartbeat) *http.Server {
+	mux := http.NewServeMux()
+
+	// Unhealthy once heartbeats stop being published, e.g. Redis is
+	// unreachable
+	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
+		status, code := "ok", http.StatusOK
+		if !heartbeat.Healthy() {
+			status, code = "unhealthy", http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, map[string]any{
+			"status":         status,
+			"service":        "cloudsweep-worker",
+			"last_heartbeat": heartbeat.LastBeat(),
+		})
+	})
+	mux.Handle("/metrics", expvar.Handler())
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, heartbeat.Status())
+	})
+
+	return &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
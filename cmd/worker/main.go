@@ -1,14 +1,43 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"fmt"
 	"log"
-	"os"
+	"net/http"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/audit"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/billing"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ci"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/clientpool"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/digest"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/discovery"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/events"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/gitops"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/jobs"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ownership"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/pricing"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/retention"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/slack"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/summary"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ticketing"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/webhook"
+	"golang.org/x/sync/errgroup"
+
+	_ "github.com/cloudsweep/cloudsweep/internal/infrastructure/kubernetes" // registers the Kubernetes provider
 )
 
 var version = "dev"
@@ -21,6 +50,14 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	log.Printf("Configuration:\n%s", cfg.Dump())
+
+	// Operational settings are reloaded on SIGHUP and changes of the
+	// configuration file
+	live := config.NewLive(cfg)
 
 	// Initialize database
 	db, err := database.NewPostgresConnection(cfg.Database)
@@ -28,30 +65,272 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Migrations are run through the API binary; refuse to work on a schema
+	// of another version
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		log.Fatalf("%v (run \"api migrate up\")", err)
+	}
+
 	// Create worker server
-	worker, err := queue.NewWorkerServer(cfg.Redis, db)
+	worker, err := queue.NewWorkerServer(cfg.Redis, cfg.Worker, db)
 	if err != nil {
 		log.Fatalf("Failed to create worker server: %v", err)
 	}
 
+	// Initialize object storage
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Provider clients are shared across tasks to avoid re-authenticating
+	clients := clientpool.New(cfg.Worker.ClientPoolTTL, cfg.Worker.ClientPoolSize)
+	expvar.Publish("clientpool", expvar.Func(func() any { return clients.Stats() }))
+
+	// Cloud API calls share a per-account rate limit across tasks
+	limiters := ratelimit.NewRegistry(cfg.RateLimit)
+	expvar.Publish("ratelimit", expvar.Func(func() any { return limiters.Stats() }))
+
+	// Per-organization time between a scan being queued and starting
+	expvar.Publish("scan_queue_wait", expvar.Func(func() any { return queue.ScanWaitStats() }))
+
+	// Weekly owner digests
+	mailer := notification.NewSMTPMailer(cfg.SMTP)
+	digests := digest.NewSender(db, mailer, cfg.Digest)
+
+	// Weekly summaries of organizations, for their admins
+	summaries := summary.NewSender(db, mailer, cfg.Summary, cfg.Digest)
+
+	scheduler, err := queue.NewScheduler(cfg.Redis, cfg.Digest, cfg.Summary, cfg.Audit, cfg.Hygiene, cfg.Recommendations, cfg.Quarantine, cfg.CI, cfg.GitOps, cfg.Plans, cfg.Allocation, cfg.Integrations, cfg.Billing, cfg.Pricing, cfg.Ticketing, cfg.Owners)
+	if err != nil {
+		log.Fatalf("Failed to create scheduler: %v", err)
+	}
+
+	// Stop and start tasks of off-hours schedule policies
+	offHours, err := queue.NewOffHoursManager(cfg.Redis, db)
+	if err != nil {
+		log.Fatalf("Failed to create off-hours manager: %v", err)
+	}
+
+	// Follow-up tasks (e.g. scan webhooks) are queued from task handlers
+	queueClient, err := queue.NewAsynqClient(cfg.Redis)
+	if err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+
+	// Post-scan webhook for downstream pipelines
+	hooks := webhook.NewClient(cfg.Webhook)
+
+	// Scan results posted to the Slack channels of organizations
+	slackClient := slack.NewClient(cfg.Slack)
+
+	// Preview environments of closed pull requests
+	previews := ci.NewDetector(db, cfg.CI)
+
+	// Pull requests removing Terraform-managed resources
+	iacChanges := gitops.NewProposer(db, cfg.CI, cfg.GitOps)
+
+	// Accounts of AWS Organizations and other organization-level integrations
+	integrations := discovery.NewSyncer(db, cfg)
+
+	// Jira issues and ServiceNow change requests opened by policies
+	tickets := ticketing.NewTracker(db, cfg.Ticketing)
+
+	// Owners of resources, and the messages telling them about theirs
+	owners := ownership.NewResolver(db)
+	ownerNotices := ownership.NewNotifier(db, owners, mailer, slackClient, cfg.Owners)
+
+	// Resource costs of the accounts priced from their billing data
+	billingCosts := billing.NewReconciler(db, cfg)
+
+	// Price catalog scans estimate costs from without calling the providers
+	prices := pricing.NewRefresher(db, cfg.Pricing)
+
+	// Expired history, archived to the object storage before being purged
+	// when enabled
+	var archive storage.ObjectStore
+	if cfg.Plans.ArchiveBeforePurge {
+		archive = store
+	}
+	history := retention.NewPurger(db, archive)
+
+	// Live events for the dashboard
+	redisClient := database.NewRedisClient(cfg.Redis)
+	bus := events.NewBus(redisClient)
+
+	// Scans and cleanups drop the dashboards cached by the API
+	results := cache.New(redisClient)
+
+	// Alerts and webhook events identical to one sent within the window are
+	// dropped, and the resources matched by a policy run sent together
+	suppressor := notification.NewSuppressor(redisClient, cfg.Notifications.DedupWindow)
+	matches := queue.NewMatchCoalescer(redisClient, queueClient, cfg.Notifications.CoalesceDelay)
+
+	// Cleaners of the quarantine, off-hours and rollback tasks: shared
+	// across tasks, rate limited per account and their calls recorded
+	cleaners := audit.NewCleanerFactory(
+		clientpool.NewCleanerFactory(ratelimit.NewCleanerFactory(provider.NewCleanerFactory(cfg), limiters), clients),
+		audit.NewRecorder(db),
+	)
+	readOnly := database.NewReadOnlyGuard(db, func() config.ReadOnlyConfig { return live.Get().ReadOnly })
+
+	// Scanners: shared across tasks and rate limited per account like the
+	// cleaners
+	scanners := clientpool.NewScannerFactory(ratelimit.NewScannerFactory(provider.NewScannerFactory(cfg), limiters), clients)
+
+	// Scans price the instances their provider cannot with the catalog of
+	// the last refresh
+	catalog := pricing.NewCatalog(db)
+	if err := catalog.Load(context.Background()); err != nil {
+		log.Printf("Failed to load the price catalog, using the embedded one: %v", err)
+	}
+
 	// Create task handlers
-	mux := queue.NewServeMux(db)
+	mux := queue.NewServeMux(db, queueClient, store, mailer, digests, hooks, suppressor, matches, cfg.Webhook.LinkTTL, cfg.Audit.Retention, previews, iacChanges, integrations, billingCosts, prices, history, bus, results, slackClient, tickets, owners, ownerNotices, summaries, cleaners, readOnly, scanners, catalog, cfg)
+
+	// Fleet status: the tasks being processed, published to Redis for the
+	// API and served on the admin listener
+	tracker := queue.NewTaskTracker()
+
+	// Scans and cleanups wait for a slot of their cloud accounts, across
+	// every worker replica, before their job runs
+	limits := queue.NewAccountLimits(db, ratelimit.NewSemaphore(redisClient, cfg.Concurrency.LeaseTTL), cfg.Concurrency)
+	mux.Use(tracker.Middleware, limits.Middleware, jobs.NewTracker(db).Middleware)
+	heartbeat := queue.NewHeartbeat(queue.NewWorkerRegistry(redisClient), tracker, cfg.Worker.Concurrency, cfg.Worker.HeartbeatInterval, version)
+
+	// The task server cannot change its concurrency: it is restarted with
+	// the reloaded one
+	concurrency := make(chan int, 1)
+	live.OnReload(func(reloaded *config.Config) {
+		limiters.Update(reloaded.RateLimit)
+		if reloaded.Worker.Concurrency != heartbeat.Status().Concurrency {
+			select {
+			case <-concurrency:
+			default:
+			}
+			concurrency <- reloaded.Worker.Concurrency
+		}
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	g, gCtx := errgroup.WithContext(ctx)
 
-	// Start worker in goroutine
-	go func() {
-		log.Println("Worker started, waiting for tasks...")
-		if err := worker.Run(mux); err != nil {
-			log.Fatalf("Worker failed: %v", err)
+	g.Go(func() error {
+		for {
+			if err := worker.Start(mux); err != nil {
+				return fmt.Errorf("worker failed: %w", err)
+			}
+			log.Println("Worker started, waiting for tasks...")
+
+			select {
+			case <-gCtx.Done():
+				// Graceful shutdown: stop pulling new tasks and drain in-flight ones
+				log.Println("Shutting down worker, draining in-flight tasks...")
+				worker.Shutdown()
+				return nil
+			case n := <-concurrency:
+				log.Printf("Restarting worker with a concurrency of %d, draining in-flight tasks...", n)
+				worker.Shutdown()
+				workerCfg := cfg.Worker
+				workerCfg.Concurrency = n
+				next, err := queue.NewWorkerServer(cfg.Redis, workerCfg, db)
+				if err != nil {
+					return fmt.Errorf("failed to create worker server: %w", err)
+				}
+				worker = next
+				heartbeat.SetConcurrency(n)
+			}
 		}
-	}()
+	})
+
+	g.Go(func() error {
+		live.Watch(gCtx)
+		return nil
+	})
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	g.Go(func() error {
+		if err := scheduler.Start(); err != nil {
+			return fmt.Errorf("scheduler failed: %w", err)
+		}
+
+		<-gCtx.Done()
+		scheduler.Shutdown()
+		return nil
+	})
+
+	g.Go(func() error {
+		if err := offHours.Start(); err != nil {
+			return fmt.Errorf("off-hours manager failed: %w", err)
+		}
 
-	log.Println("Shutting down worker...")
-	worker.Shutdown()
+		<-gCtx.Done()
+		offHours.Shutdown()
+		return nil
+	})
+
+	// Settings are read once: shut down gracefully on secret rotation so
+	// that the supervisor restarts the process with the new secrets
+	g.Go(func() error {
+		cfg.WatchSecrets(gCtx, func(settings []string) {
+			log.Printf("Secrets rotated (%s), restarting to apply them", strings.Join(settings, ", "))
+			stop()
+		})
+		return nil
+	})
+
+	g.Go(func() error {
+		return heartbeat.Run(gCtx)
+	})
+
+	if cfg.Worker.AdminAddr != "" {
+		admin := newAdminServer(cfg.Worker.AdminAddr, heartbeat)
+
+		g.Go(func() error {
+			log.Printf("Admin server listening on %s", cfg.Worker.AdminAddr)
+			if err := admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				return fmt.Errorf("admin server failed: %w", err)
+			}
+			return nil
+		})
+
+		g.Go(func() error {
+			<-gCtx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return admin.Shutdown(shutdownCtx)
+		})
+	}
+
+	err = g.Wait()
+
+	stats := clients.Stats()
+	log.Printf("Client pool: %d hits, %d misses, %d refreshes, %d evictions",
+		stats.Hits, stats.Misses, stats.Refreshes, stats.Evictions)
+	for key, s := range limiters.Stats() {
+		if s.Throttled > 0 {
+			log.Printf("Rate limit %s: %d requests, %d throttled, %d retries", key, s.Requests, s.Throttled, s.Retries)
+		}
+	}
+
+	if cerr := queueClient.Close(); cerr != nil {
+		log.Printf("Failed to close queue client: %v", cerr)
+	}
+	if cerr := redisClient.Close(); cerr != nil {
+		log.Printf("Failed to close Redis client: %v", cerr)
+	}
+	if cerr := database.Close(db); cerr != nil {
+		log.Printf("Failed to close database: %v", cerr)
+	}
+
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 
 	log.Println("Worker exited properly")
 }
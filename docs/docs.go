@@ -24,9 +24,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/cleanup": {
-            "post": {
-                "description": "Queue a cleanup operation for specified resources",
+        "/analytics/tags": {
+            "get": {
+                "description": "Get the distribution of a tag key's values across active resources, with their monthly cost and the potential savings from unused ones. Resources without the tag are grouped under untagged.",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,25 +34,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cleanup"
+                    "Analytics"
                 ],
-                "summary": "Execute cleanup",
+                "summary": "Tag explorer",
                 "parameters": [
                     {
-                        "description": "Cleanup request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.ExecuteCleanupRequest"
-                        }
+                        "type": "string",
+                        "example": "env",
+                        "description": "Tag key",
+                        "name": "key",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Filter by organization",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum number of values",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.ExecuteCleanupResponse"
+                            "$ref": "#/definitions/handler.DataResponse-handler_TagDistributionResponse"
                         }
                     },
                     "400": {
@@ -70,9 +89,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/cleanup/preview": {
-            "post": {
-                "description": "Preview what resources would be affected by a cleanup operation",
+        "/audit/provider-calls": {
+            "get": {
+                "description": "Get a paginated list of the provider-mutating API calls made by cleanups, most recent first. Filter by task_id to get the calls behind a cleanup.",
                 "consumes": [
                     "application/json"
                 ],
@@ -80,25 +99,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cleanup"
+                    "Audit"
                 ],
-                "summary": "Preview cleanup",
+                "summary": "List provider calls",
                 "parameters": [
                     {
-                        "description": "Cleanup preview request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.ExecuteCleanupRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cleanup task ID",
+                        "name": "task_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, from next_cursor; offset is ignored",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.CleanupPreviewDTO"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.ProviderCallDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -116,53 +182,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/dashboard/carbon": {
+        "/auth/oidc/callback": {
             "get": {
-                "description": "Get carbon footprint breakdown by provider and region",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Redeem the authorization code sent back by the identity provider. Users are created on their first login and their role follows the group mapping of the connection.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Dashboard"
+                    "Auth"
+                ],
+                "summary": "Complete SSO login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Login state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Carbon footprint breakdown",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.CarbonResponse"
+                            "$ref": "#/definitions/handler.DataResponse-handler_SessionDTO"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/dashboard/savings": {
-            "get": {
-                "description": "Get potential savings breakdown by provider and resource type",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Dashboard"
-                ],
-                "summary": "Savings breakdown",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.SavingsResponse"
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "500": {
@@ -174,31 +242,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/dashboard/summary": {
+        "/auth/oidc/login": {
             "get": {
-                "description": "Get dashboard summary statistics including total resources, unused resources, costs and carbon footprint",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Redirect to the login page of the identity provider of an organization. The login state is kept in a short-lived cookie until the callback.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Dashboard"
+                    "Auth"
+                ],
+                "summary": "Start SSO login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug or ID",
+                        "name": "organization",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Dashboard summary",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/handler.SummaryStats"
-                            }
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -206,9 +286,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
-            "get": {
-                "description": "Basic health check endpoint",
+        "/cleanup": {
+            "post": {
+                "description": "Queue a cleanup operation for specified resources, or for the resources of a saved view. With backup, or by default when the organization enabled backup_before_delete, deletions snapshot volumes, disks and databases first, tagged with the cleanup task and the end of their retention, and delete them only once the snapshot completed; the savings are net of the cost of the snapshots. Cleanups other than dry runs and notifications are refused with the read_only code while the installation or the organization is in read-only mode.",
                 "consumes": [
                     "application/json"
                 ],
@@ -216,22 +296,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Cleanup"
+                ],
+                "summary": "Execute cleanup",
+                "parameters": [
+                    {
+                        "description": "Cleanup request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ExecuteCleanupRequest"
+                        }
+                    }
                 ],
-                "summary": "Health check",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/handler.HealthResponse"
+                            "$ref": "#/definitions/handler.ExecuteCleanupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/policies": {
+        "/cleanup/changes": {
             "get": {
-                "description": "Get a paginated list of cleanup policies",
+                "description": "Get a paginated list of the pull requests opened by cleanups to remove Terraform-managed resources from their configuration, most recent first. Filter by task_id to get the pull requests of a cleanup. States are refreshed periodically.",
                 "consumes": [
                     "application/json"
                 ],
@@ -239,30 +354,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Policies"
+                    "Cleanup"
                 ],
-                "summary": "List policies",
+                "summary": "List IaC changes",
                 "parameters": [
                     {
-                        "enum": [
-                            "aws",
-                            "azure",
-                            "gcp"
-                        ],
                         "type": "string",
-                        "description": "Filter by cloud provider",
-                        "name": "provider",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cleanup task ID",
+                        "name": "task_id",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "Filter by enabled status",
-                        "name": "is_enabled",
+                        "enum": [
+                            "open",
+                            "closed",
+                            "merged"
+                        ],
+                        "type": "string",
+                        "description": "Pull request state",
+                        "name": "state",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "default": 20,
+                        "default": 50,
                         "description": "Number of items per page",
                         "name": "limit",
                         "in": "query"
@@ -289,7 +412,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/handler.PolicyDTO"
+                                                "$ref": "#/definitions/handler.IaCChangeDTO"
                                             }
                                         }
                                     }
@@ -310,9 +433,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new cleanup policy",
+            }
+        },
+        "/cleanup/jobs/{id}/items": {
+            "get": {
+                "description": "Get the result of a cleanup job for each of its resources, skipped ones included, as recorded in their timelines once the job ran. recovery tells how to undo an action that succeeded: automated ones (start, unquarantine, untag, restore_snapshot) can be rolled back, the others come with instructions, such as the snapshot or the versioning state to recreate the resource from. Dry runs record no items.",
                 "consumes": [
                     "application/json"
                 ],
@@ -320,28 +445,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Policies"
+                    "Cleanup"
                 ],
-                "summary": "Create policy",
+                "summary": "List cleanup job items",
                 "parameters": [
                     {
-                        "description": "Policy request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.CreatePolicyRequest"
-                        }
-                    }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/handler.PolicyDTO"
-                            }
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_CleanupJobItemDTO"
                         }
                     },
                     "400": {
@@ -350,6 +471,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -359,9 +486,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/policies/{id}": {
-            "get": {
-                "description": "Get a single policy by its ID",
+        "/cleanup/jobs/{id}/items/{resource_id}/rollback": {
+            "post": {
+                "description": "Queue undoing the action a cleanup job ran on one of its resources, from the recovery recorded with it: stopped resources are started, quarantined ones restored, tags removed, and deleted databases recreated from their final snapshot. Actions that cannot be undone automatically, such as deleted buckets, are refused with their instructions, and so are actions that failed, were already rolled back or whose undo window has ended. The rollback is recorded in the timeline of the resource. Refused with the read_only code in read-only mode.",
                 "consumes": [
                     "application/json"
                 ],
@@ -369,27 +496,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Policies"
+                    "Cleanup"
                 ],
-                "summary": "Get policy by ID",
+                "summary": "Roll back a cleanup job item",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Policy ID",
+                        "description": "Job ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/handler.PolicyDTO"
-                            }
+                            "$ref": "#/definitions/handler.MessageResponse"
                         }
                     },
                     "400": {
@@ -398,12 +530,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -411,9 +555,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update an existing policy",
+            }
+        },
+        "/cleanup/preview": {
+            "post": {
+                "description": "Preview what resources would be affected by a cleanup operation, given as resource IDs or a saved view. dns_references lists the public IP addresses, load balancers and static website buckets DNS records still point at, as of their last scan: deleting or releasing them is refused while the records exist, and quarantining them only warns since the records are checked again before the purge. Resources whose last scan did not search the DNS zones are listed unchecked. preflight runs the checks of the cleanup on every resource and tells whether it will proceed, be skipped (already deleted, excluded or quarantined) or fail (denylisted region, action not applying to its type, infrastructure-as-code tool recreating it, dependencies such as images, attachments or DNS records), with the permissions the action needs. Checks that need the cloud, such as route tables and missing permissions, are only warned about and run again before the cleanup. The estimated savings are those of the resources that will proceed, net of estimated_backup_cost, the monthly cost of the snapshots taken before deleting volumes, disks and databases in backup-then-delete mode.",
                 "consumes": [
                     "application/json"
                 ],
@@ -421,25 +567,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Policies"
+                    "Cleanup"
                 ],
-                "summary": "Update policy",
+                "summary": "Preview cleanup",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Policy ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Policy update request",
+                        "description": "Cleanup preview request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreatePolicyRequest"
+                            "$ref": "#/definitions/handler.ExecuteCleanupRequest"
                         }
                     }
                 ],
@@ -447,10 +585,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/handler.PolicyDTO"
-                            }
+                            "$ref": "#/definitions/handler.CleanupPreviewDTO"
                         }
                     },
                     "400": {
@@ -472,9 +607,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a policy",
+            }
+        },
+        "/cleanup/sessions": {
+            "post": {
+                "description": "Start a guided cleanup session over the unused resources matching the filters and, when given, the saved view. Resources are snapshotted in order of monthly cost so pages stay stable while they are reviewed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -482,24 +619,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Policies"
+                    "Cleanup"
                 ],
-                "summary": "Delete policy",
+                "summary": "Create cleanup session",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Policy ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Session request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateCleanupSessionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handler.MessageResponse"
+                            "$ref": "#/definitions/handler.DataResponse-handler_CleanupSessionDTO"
                         }
                     },
                     "400": {
@@ -523,9 +661,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/policies/{id}/disable": {
-            "post": {
-                "description": "Disable a policy",
+        "/cleanup/sessions/{id}": {
+            "get": {
+                "description": "Get a cleanup session with its review progress. Sessions are shared by ID between teammates.",
                 "consumes": [
                     "application/json"
                 ],
@@ -533,14 +671,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Policies"
+                    "Cleanup"
                 ],
-                "summary": "Disable policy",
+                "summary": "Get cleanup session",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Policy ID",
+                        "description": "Session ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -550,7 +688,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.MessageResponse"
+                            "$ref": "#/definitions/handler.DataResponse-handler_CleanupSessionDTO"
                         }
                     },
                     "400": {
@@ -572,11 +710,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/policies/{id}/enable": {
-            "post": {
-                "description": "Enable a policy",
+            },
+            "delete": {
+                "description": "Close an open cleanup session without executing it",
                 "consumes": [
                     "application/json"
                 ],
@@ -584,14 +720,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Policies"
+                    "Cleanup"
                 ],
-                "summary": "Enable policy",
+                "summary": "Cancel cleanup session",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Policy ID",
+                        "description": "Session ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -616,6 +752,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -625,9 +767,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ready": {
-            "get": {
-                "description": "Readiness check with dependency verification",
+        "/cleanup/sessions/{id}/execute": {
+            "post": {
+                "description": "Queue one cleanup task for the accepted resources of a session and close it. Deletions back up volumes, disks and databases first when backup is set, or by default when the organization enabled backup_before_delete. Sessions are not executed, except as dry runs, while the installation or the organization is in read-only mode.",
                 "consumes": [
                     "application/json"
                 ],
@@ -635,18 +777,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Cleanup"
+                ],
+                "summary": "Execute cleanup session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Execution options",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ExecuteCleanupSessionRequest"
+                        }
+                    }
                 ],
-                "summary": "Readiness check",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/handler.ReadyResponse"
+                            "$ref": "#/definitions/handler.DataResponse-handler_CleanupSessionDTO"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -654,9 +838,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/resources": {
+        "/cleanup/sessions/{id}/items": {
             "get": {
-                "description": "Get a paginated list of cloud resources with optional filters",
+                "description": "Get a page of the resources of a cleanup session, in review order, with their decision",
                 "consumes": [
                     "application/json"
                 ],
@@ -664,43 +848,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Resources"
+                    "Cleanup"
                 ],
-                "summary": "List resources",
+                "summary": "List cleanup session resources",
                 "parameters": [
-                    {
-                        "enum": [
-                            "aws",
-                            "azure",
-                            "gcp"
-                        ],
-                        "type": "string",
-                        "description": "Filter by cloud provider",
-                        "name": "provider",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filter by resource type",
-                        "name": "type",
-                        "in": "query"
+                        "format": "uuid",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "enum": [
-                            "active",
-                            "unused",
-                            "deleted",
-                            "excluded"
+                            "pending",
+                            "accepted",
+                            "rejected"
                         ],
                         "type": "string",
-                        "description": "Filter by status",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by region",
-                        "name": "region",
+                        "description": "Filter by decision",
+                        "name": "decision",
                         "in": "query"
                     },
                     {
@@ -732,7 +900,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/handler.ResourceDTO"
+                                                "$ref": "#/definitions/handler.CleanupSessionItemDTO"
                                             }
                                         }
                                     }
@@ -746,6 +914,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -753,11 +927,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/resources/{id}": {
-            "get": {
-                "description": "Get a single cloud resource by its ID",
+            },
+            "put": {
+                "description": "Accept or reject resources of an open cleanup session. Decisions can be changed until the session is executed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -765,27 +937,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Resources"
+                    "Cleanup"
                 ],
-                "summary": "Get resource by ID",
+                "summary": "Review cleanup session resources",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Resource ID",
+                        "description": "Session ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Decisions",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DecideCleanupSessionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/handler.ResourceDTO"
-                            }
+                            "$ref": "#/definitions/handler.DataResponse-handler_CleanupSessionDTO"
                         }
                     },
                     "400": {
@@ -800,6 +978,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -807,9 +991,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Mark a resource as deleted",
+            }
+        },
+        "/cleanup/tickets": {
+            "get": {
+                "description": "Get a paginated list of the Jira issues and ServiceNow change requests opened by policies with the ticket action, most recent first. Filter by task_id to get the tickets of a policy run. States are refreshed periodically.",
                 "consumes": [
                     "application/json"
                 ],
@@ -817,88 +1003,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Resources"
+                    "Cleanup"
                 ],
-                "summary": "Delete resource",
+                "summary": "List tickets",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Resource ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/handler.MessageResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/scans": {
-            "get": {
-                "description": "Get a paginated list of scans with optional filters",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Scans"
-                ],
-                "summary": "List scans",
-                "parameters": [
                     {
-                        "enum": [
-                            "aws",
-                            "azure",
-                            "gcp"
-                        ],
                         "type": "string",
-                        "description": "Filter by cloud provider",
-                        "name": "provider",
+                        "description": "Task ID",
+                        "name": "task_id",
                         "in": "query"
                     },
                     {
                         "enum": [
-                            "pending",
-                            "running",
-                            "completed",
-                            "failed",
-                            "cancelled"
+                            "open",
+                            "closed"
                         ],
                         "type": "string",
-                        "description": "Filter by status",
-                        "name": "status",
+                        "description": "Ticket state",
+                        "name": "state",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "default": 20,
+                        "default": 50,
                         "description": "Number of items per page",
                         "name": "limit",
                         "in": "query"
@@ -925,7 +1060,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/handler.ScanDTO"
+                                                "$ref": "#/definitions/handler.TicketDTO"
                                             }
                                         }
                                     }
@@ -946,9 +1081,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new cloud resource scan and queue it for processing",
+            }
+        },
+        "/dashboard/allocation": {
+            "get": {
+                "description": "Get the showback report of an organization: its monthly cost and waste per value of each cost-allocation tag (allocation_tag_keys in its settings), with the cost of the resources missing the tag. Reports are refreshed periodically during the month and kept for earlier months.",
                 "consumes": [
                     "application/json"
                 ],
@@ -956,25 +1093,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scans"
+                    "Dashboard"
                 ],
-                "summary": "Create a new scan",
+                "summary": "Cost allocation",
                 "parameters": [
                     {
-                        "description": "Scan request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.CreateScanRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month (YYYY-MM), defaults to the current month",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this cost-allocation tag",
+                        "name": "tag_key",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateScanResponse"
+                            "$ref": "#/definitions/handler.DataResponse-handler_AllocationResponse"
                         }
                     },
                     "400": {
@@ -983,6 +1130,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -992,9 +1151,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/scans/{id}": {
+        "/dashboard/carbon": {
             "get": {
-                "description": "Get a single scan by its ID",
+                "description": "Get the carbon footprint of the unused resources of an organization broken down by provider and region",
                 "consumes": [
                     "application/json"
                 ],
@@ -1002,27 +1161,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scans"
+                    "Dashboard"
                 ],
-                "summary": "Get scan by ID",
+                "summary": "Carbon footprint breakdown",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Scan ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period (date or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (date, inclusive, or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by provider account, subscription, project or cluster",
+                        "name": "account_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/handler.ScanDTO"
-                            }
+                            "$ref": "#/definitions/handler.CarbonResponse"
                         }
                     },
                     "400": {
@@ -1031,8 +1210,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -1045,629 +1224,10737 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.CarbonResponse": {
-            "type": "object",
-            "properties": {
-                "by_provider": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handler.ProviderCarbon"
+        },
+        "/dashboard/forecast": {
+            "get": {
+                "description": "Project the monthly spend and waste of an organization over the next 12 months, with their totals over 3, 6 and 12 months, from the trend of the daily cost snapshots of the last 90 days. Each figure comes in three scenarios: the trend alone, with the scheduled policies stopping, quarantining, releasing or deleting the resources they match today from their next run on, and with all the open recommendations applied on top of them. Without a week of snapshots the current spend is projected as it is.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Savings forecast",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
                     }
-                },
-                "by_region": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handler.RegionCarbon"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ForecastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "handler.CleanupPreviewDTO": {
-            "type": "object",
-            "properties": {
-                "action": {
-                    "type": "string",
-                    "example": "delete"
-                },
-                "count": {
-                    "type": "integer",
-                    "example": 5
-                },
-                "estimated_carbon_savings": {
-                    "type": "number",
-                    "example": 35.5
-                },
-                "estimated_monthly_savings": {
-                    "type": "number",
-                    "example": 250
-                },
-                "resources": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handler.ResourceDTO"
+        "/dashboard/savings": {
+            "get": {
+                "description": "Get the potential savings of an organization broken down by provider and resource type",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Savings breakdown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period (date or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (date, inclusive, or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by provider account, subscription, project or cluster",
+                        "name": "account_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SavingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "handler.CreatePolicyRequest": {
-            "type": "object",
-            "required": [
-                "actions",
+        "/dashboard/score": {
+            "get": {
+                "description": "Get the cloud hygiene score of an organization, from 0 to 100, combining the waste ratio, tag compliance, snapshot age and recommendation backlog, with its factor breakdown and its daily history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Cloud hygiene score",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 365,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Days of history",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ScoreResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/summary": {
+            "get": {
+                "description": "Get dashboard summary statistics of an organization including total resources, unused resources, costs and carbon footprint. The organization is the one of the session, or organization_id otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Dashboard summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period (date or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (date, inclusive, or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by provider account, subscription, project or cluster",
+                        "name": "account_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_SummaryStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/ticker": {
+            "get": {
+                "description": "Get the current waste burn rate: dollars and CO2e per hour wasted by the resources currently flagged unused, with the amounts wasted since the start of the month at that rate. Clients can extrapolate from as_of with the per-second rates for a live counter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Waste ticker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by provider account, subscription, project or cluster",
+                        "name": "account_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_TickerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/top-offenders": {
+            "get": {
+                "description": "Get the most expensive unused resources of an organization, or the teams (by tag), accounts or regions wasting the most, each with its share of the total unused cost. Resources without the team tag are grouped under untagged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Top waste offenders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "resource",
+                            "team",
+                            "account",
+                            "region"
+                        ],
+                        "type": "string",
+                        "default": "resource",
+                        "description": "Rank resources or groups",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "team",
+                        "description": "Tag holding the team, with group_by=team",
+                        "name": "tag_key",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of offenders",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period (date or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (date, inclusive, or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by provider account, subscription, project or cluster",
+                        "name": "account_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_TopOffendersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/digest/action": {
+            "get": {
+                "description": "Render the page confirming the action of a signed link sent in the owner digest email or the weekly summary email. Opening the link changes nothing, so that mail scanners and link prefetchers cannot snooze, approve or unsubscribe: the page posts the token to POST /digest/action once confirmed.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Digest"
+                ],
+                "summary": "Confirm a digest action",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signed action token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Snooze a resource or approve its cleanup from a signed link sent in the owner digest email, or stop the weekly summary of an organization from a link of the summary email, once confirmed on the page of GET /digest/action",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Digest"
+                ],
+                "summary": "Apply a digest action",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signed action token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Stream the live events of an organization as server-sent events, so that the dashboard does not poll: scan.progress (scan status and counts), resource.status (a resource changed status) and cleanup.result (a cleanup task finished). Each event has the type as its name and an Event as its JSON data. Events are not stored: a client only receives those published while connected. Requests must accept text/event-stream.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Event stream",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID, required unless signed in",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types",
+                        "name": "types",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports": {
+            "post": {
+                "description": "Create an asynchronous export job. Poll GET /exports/{id} until completed to obtain a download URL.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Create export",
+                "parameters": [
+                    {
+                        "description": "Export request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateExportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}": {
+            "get": {
+                "description": "Get the status of an export job. Completed exports include a time-limited download URL.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Get export by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ExportDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Basic health check endpoint",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/accept": {
+            "post": {
+                "description": "Accept an invitation with the token of its link. The user is created from the invited email when it does not exist yet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Accept invitation",
+                "parameters": [
+                    {
+                        "description": "Invitation token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AcceptInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_MemberDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "Get a paginated list of the scans, cleanups and exports of an organization running in the background, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "List jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "scan",
+                            "cleanup",
+                            "export"
+                        ],
+                        "type": "string",
+                        "description": "Filter by type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "running",
+                            "completed",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.JobDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Get the status, progress and error of a job, with a link to its result",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get job by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_JobDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "get": {
+                "description": "Get a paginated list of organizations, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List organizations",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Filter by active status",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.OrganizationDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create an organization. Slugs are unique, made of 3 to 100 lowercase letters, digits and single hyphens. The plan defaults to free.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create organization",
+                "parameters": [
+                    {
+                        "description": "Organization",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_OrganizationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/onboard": {
+            "post": {
+                "description": "Create an organization together with its first admin user and the invitations of the people joining it, in a single transaction. Existing users are made admin of the new organization, and the invitation links are emailed once it is created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Onboard organization",
+                "parameters": [
+                    {
+                        "description": "Onboarding",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.OnboardOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_OnboardingDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}": {
+            "get": {
+                "description": "Get an organization by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_OrganizationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Rename an organization, change its slug or its plan",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Organization",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_OrganizationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft-deactivate an organization: its data is kept, its policies are disabled and its pending or running scans are cancelled. New scans and enabled policies are refused until it is reactivated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Deactivate organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/cloud-accounts": {
+            "get": {
+                "description": "List the cloud accounts of an organization with their pricing source and the outcome of its last reconciliation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List cloud accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_CloudAccountDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/cloud-accounts/{account_id}": {
+            "delete": {
+                "description": "Delete a cloud account: it is no longer scanned nor counted in the plan quota. Its resources stay in the inventory. It can be restored with POST /organizations/{id}/cloud-accounts/{account_id}/restore.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete cloud account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Cloud account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/cloud-accounts/{account_id}/owner": {
+            "put": {
+                "description": "Set the default owner of the resources of a cloud account that neither the owner mapping file nor their tags attribute. The owners of the resources of the organization are resolved again in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Set account owner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Cloud account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Owner",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateOwnerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_CloudAccountDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/cloud-accounts/{account_id}/permissions-check": {
+            "post": {
+                "description": "Check the permissions granted to the credentials of a cloud account against those CloudSweep calls to scan it and for each cleanup action, and report those missing. AWS accounts are checked with the IAM policy simulator, which needs iam:SimulatePrincipalPolicy; Azure subscriptions with the permissions of the role assignments of the service principal; Google Cloud projects with testIamPermissions. Providers that cannot check their permissions get 400, and failed checks 502 with the error of the provider.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Check account permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Cloud account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_PermissionReportDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/cloud-accounts/{account_id}/pricing": {
+            "put": {
+                "description": "Set where the resource costs of a cloud account come from. With a billing pricing source, e.g. AWS Cost Explorer, the monthly cost of resources is replaced by their amortized cost over the billing window, right away and then on the billing schedule. Back to \"estimate\", the next scan estimates costs again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Configure account pricing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Cloud account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pricing source",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdatePricingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_CloudAccountDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/cloud-accounts/{account_id}/restore": {
+            "post": {
+                "description": "Restore a deleted cloud account. An active account must fit in the cloud account quota of the plan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Restore cloud account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Cloud account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_CloudAccountDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuotaErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/custom-resource-types": {
+            "get": {
+                "description": "List the resource types defined by an organization, by provider and name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List custom resource types",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_CustomResourceTypeDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Define a resource type for a service CloudSweep does not cover yet. Scans of the provider list its resources through the generic API of the provider (AWS Cloud Control) and report them unused when the metric of the detection (a CloudWatch metric whose dimension is the resource identifier), aggregated over the lookback window, is below the threshold, or above it with above set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create custom resource type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resource type",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateCustomResourceTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_CustomResourceTypeDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/custom-resource-types/{type_id}": {
+            "put": {
+                "description": "Change how the resources of a custom type are listed, detected and priced. The name and provider cannot change since they identify the resources already found; the next scan applies the new detection.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update custom resource type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Custom resource type ID",
+                        "name": "type_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resource type",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateCustomResourceTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_CustomResourceTypeDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop scanning a custom resource type. The resources already found are marked deleted by the next scan of their regions covering every resource type.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete custom resource type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Custom resource type ID",
+                        "name": "type_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/integrations": {
+            "get": {
+                "description": "List the organization-level integrations of an organization with the outcome of their last sync",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List integrations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_AccountIntegrationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/integrations/{provider}": {
+            "get": {
+                "description": "Get the integration of a provider. Secret settings are never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get integration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_AccountIntegrationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the integration of a provider, e.g. AWS Organizations: CloudSweep assumes the management role to list the member accounts, and creates a cloud account for each that assumes the member role. Accounts are synced right away, then on the integrations schedule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Configure integration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Integration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateIntegrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_AccountIntegrationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the integration of a provider. The cloud accounts it discovered are kept and no longer synced.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete integration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/integrations/{provider}/sync": {
+            "post": {
+                "description": "Queue the discovery of the accounts of an integration without waiting for its schedule. The outcome is reported by the last_sync fields of the integration.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Sync integration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members": {
+            "get": {
+                "description": "Get the members of an organization with their role, admins first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_MemberDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Invite someone to join an organization. The invitation link is emailed and can be accepted until it expires; inviting the same email again replaces the pending invitation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Invite member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_InvitationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members/{user_id}": {
+            "put": {
+                "description": "Change the role of a member. The last admin of an organization cannot be demoted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Change member role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_MemberDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a member from an organization. The last admin of an organization cannot be removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Remove member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members/{user_id}/preferences": {
+            "put": {
+                "description": "Subscribe or unsubscribe a member from the weekly summary of the organization, emailed to admins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Change member preferences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Preferences",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateMemberPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_MemberDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/owner-mappings": {
+            "get": {
+                "description": "List the rows of the owner mapping file of an organization, in file order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List owner mappings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_OwnerMappingDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the owner mapping file of an organization with a CSV of \"resource,owner\" rows, with an optional header. Resources are cloud resource IDs; those ending with \"*\" match by prefix. The first matching row wins, ahead of owner tags and default owners. An empty file removes the mappings.",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Upload owner mapping file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Owner mapping file",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_OwnerMappingDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/reactivate": {
+            "post": {
+                "description": "Reactivate a deactivated organization. Its policies stay disabled until enabled again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Reactivate organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/settings": {
+            "get": {
+                "description": "Get the default scan regions, region denylist, owner attribution rules and audit log retention of an organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get organization settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_OrganizationSettingsDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the settings of an organization. Denylist entries ending with \"*\" match by prefix.\nOwner tag keys are tried in order to attribute resources to an owner; values are mapped through owner aliases or used as-is when they are email addresses. owner_notifications sets how owners are told about the resources of policies with the notify action: by email, Slack direct message or both, at once or in a daily digest.\nweekly_summary sets the day, hour and timezone of the weekly summary emailed to admins (Monday 8:00 UTC by default); it is kept when omitted. backup_before_delete makes deletions snapshot volumes, disks and databases first and keep the snapshots retention_days (30 by default); it is kept when omitted.\nNotifications route events (scan.finished, cost.anomaly, policy.matched) to the teams and pagerduty channels; they are kept when omitted, as are the webhook URL and routing key when left empty.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update organization settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateOrganizationSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_OrganizationSettingsDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/slack": {
+            "get": {
+                "description": "Get the Slack workspace and channel an organization installed the app in",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get Slack installation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_SlackInstallationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Forget the Slack installation of an organization: its commands are no longer answered and scan results no longer posted. The app stays in the workspace until removed there.",
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Uninstall the Slack app",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/slack/install": {
+            "get": {
+                "description": "Redirect to the Slack page installing the app in a workspace for an organization. Slack redirects back to /slack/oauth/callback, which replaces any previous installation of the organization.",
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Install the Slack app",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/sso": {
+            "get": {
+                "description": "Get the OIDC identity provider of an organization. The client secret is never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get SSO connection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_SSOConnectionDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the OIDC identity provider (Okta, Azure AD, Google...) users of an organization sign in with. Groups of the ID token are mapped to roles; users in no mapped group get the default role, or are refused when it is empty.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Configure SSO connection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Connection",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateSSOConnectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_SSOConnectionDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the OIDC identity provider of an organization. Members keep their access through invitations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete SSO connection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/ticketing": {
+            "get": {
+                "description": "Get the Jira or ServiceNow tracker of an organization. The API token is never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get ticket tracker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_TicketIntegrationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the tracker policies with the ticket action open tickets in: Jira issues in a project, or ServiceNow change requests assigned to a group. Tickets are closed once their resource is deleted or in use again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Configure ticket tracker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tracker",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateTicketIntegrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_TicketIntegrationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the tracker of an organization. Policies with the ticket action fail until another one is configured; the open tickets are closed in CloudSweep only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete ticket tracker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/usage": {
+            "get": {
+                "description": "Get the consumption of an organization against the quotas of its plan: cloud accounts, scans today (reset at midnight UTC), resources tracked and history retention",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get plan usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_UsageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies": {
+            "get": {
+                "description": "Get a paginated list of cleanup policies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "List policies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by enabled status",
+                        "name": "is_enabled",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List the deleted policies, which can be restored",
+                        "name": "deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.PolicyDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new cleanup policy. Conditions must only use known keys with values of the right type, the schedule must be a standard cron expression and actions must apply to the selected resource types (e.g. stop only applies to instances). The schedule action stops instances in the off_hours stop window and restarts them in its start window, evaluated in its timezone. view_id narrows the policy down to the resources of a saved view of the organization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Create policy",
+                "parameters": [
+                    {
+                        "description": "Policy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreatePolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_PolicyDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies/apply": {
+            "put": {
+                "description": "Converge the declaratively managed policies of an organization to a desired set keyed by external_id: missing policies are created, differing ones updated, and managed policies absent from the set deleted. Applying the same set twice changes nothing. Policies created without external_id are never touched. The whole set is validated before any change and applied in a single transaction; dry_run reports the changes without making them. Also served as PUT /policies:apply.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Apply policies",
+                "parameters": [
+                    {
+                        "description": "Desired policies",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ApplyPoliciesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ApplyPoliciesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies/{id}": {
+            "get": {
+                "description": "Get a single policy by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Get policy by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_PolicyDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing policy. With an If-Match header (the ETag of GET /policies/{id}) or a version in the body, the update is rejected with a 409 giving the current version if the policy was updated since.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Update policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the policy the update was made from",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Policy update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreatePolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_PolicyDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a policy. It stops applying right away and can be restored with POST /policies/{id}/restore.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Delete policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies/{id}/disable": {
+            "post": {
+                "description": "Disable a policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Disable policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies/{id}/enable": {
+            "post": {
+                "description": "Enable a policy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Enable policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies/{id}/restore": {
+            "post": {
+                "description": "Restore a deleted policy as it was when deleted, enabled or not",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Restore policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_PolicyDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies/{id}/simulate": {
+            "post": {
+                "description": "Evaluate a policy against the current resources (those of its view, when it has one) without taking any action. Returns the matched resources, the potential savings and, for each condition, how many resources it matched.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "Simulate policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_PolicySimulationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policy-templates": {
+            "get": {
+                "description": "List ready-made policies for the most common kinds of waste, such as idle load balancers and NAT gateways or detached network interfaces. A policy is created from a template by posting its fields to /policies with the organization.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Policies"
+                ],
+                "summary": "List policy templates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider of the templates",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_PolicyTemplateDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/providers": {
+            "get": {
+                "description": "List the supported cloud providers with their resource types, the cleanup actions of each type, the fields of their credentials, of their organization-level integration and of their pricing sources, for clients to build their forms from rather than hard-coding providers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Providers"
+                ],
+                "summary": "List providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_ProviderDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/stats": {
+            "get": {
+                "description": "Get pending, active, scheduled, retry and archived task counts for each queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Queue"
+                ],
+                "summary": "Get queue statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_QueueStatsDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/tasks": {
+            "get": {
+                "description": "List tasks of a queue in a given state. The task_type filter applies to the requested page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Queue"
+                ],
+                "summary": "List queued tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "default",
+                        "description": "Queue name",
+                        "name": "queue",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "active",
+                            "scheduled",
+                            "retry",
+                            "archived",
+                            "completed"
+                        ],
+                        "type": "string",
+                        "default": "pending",
+                        "description": "Task state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by task type",
+                        "name": "task_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_QueueTaskDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/tasks/{id}/cancel": {
+            "post": {
+                "description": "Cancel an active task or remove a pending, scheduled or retrying task from its queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Queue"
+                ],
+                "summary": "Cancel a queued task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Queue name",
+                        "name": "queue",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Readiness check with dependency verification",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReadyResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recommendations": {
+            "get": {
+                "description": "Get a paginated list of the recommendations on resources in use, highest estimated savings first. Recommendations are regenerated daily from resource metrics.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recommendations"
+                ],
+                "summary": "List recommendations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "rightsize",
+                            "gp3_migration",
+                            "commitment"
+                        ],
+                        "type": "string",
+                        "description": "Recommendation type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "dismissed"
+                        ],
+                        "type": "string",
+                        "default": "open",
+                        "description": "Recommendation status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.RecommendationDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recommendations/{id}": {
+            "put": {
+                "description": "Dismiss a recommendation, or reopen a dismissed one. Dismissed recommendations stay dismissed when regenerated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recommendations"
+                ],
+                "summary": "Update recommendation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Recommendation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateRecommendationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RecommendationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resource-types": {
+            "get": {
+                "description": "List the resource types of the registered providers, whether they are scanned and the heuristics reporting their resources unused. Each type is detected by its own plugin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Providers"
+                ],
+                "summary": "List resource types",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider of the types",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-array_handler_ResourceTypeDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resource-views": {
+            "get": {
+                "description": "Get the saved resource views of an organization, by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resource Views"
+                ],
+                "summary": "List resource views",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.ResourceViewDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Save a named filter of the resource list. Views apply to the resource list with view_id, and can select the resources of policies and cleanups.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resource Views"
+                ],
+                "summary": "Create resource view",
+                "parameters": [
+                    {
+                        "description": "View request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateResourceViewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ResourceViewDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resource-views/{id}": {
+            "get": {
+                "description": "Get a saved resource view by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resource Views"
+                ],
+                "summary": "Get resource view",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "View ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ResourceViewDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Rename a saved resource view or replace its filters. Policies and cleanups referencing the view use the new filters from then on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resource Views"
+                ],
+                "summary": "Update resource view",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "View ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "View update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateResourceViewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ResourceViewDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a saved resource view. Views selecting the resources of a policy cannot be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resource Views"
+                ],
+                "summary": "Delete resource view",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "View ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources": {
+            "get": {
+                "description": "Get a paginated list of cloud resources with optional filters.\nq matches names, cloud IDs and tag values (every term must match) and accepts tag selectors: tag:env=prod, tag:owner!=platform (also matches untagged resources) and tag:env (tag set). Use double quotes for values with spaces.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "List resources",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by resource type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "unused",
+                            "deleted",
+                            "removed",
+                            "excluded"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by region",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by provider account, subscription, project or cluster",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by owner email",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search terms and tag selectors",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum monthly cost",
+                        "name": "min_cost",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Saved view to apply, other filters override it",
+                        "name": "view_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created",
+                            "cost",
+                            "carbon",
+                            "age"
+                        ],
+                        "type": "string",
+                        "default": "created",
+                        "description": "Sort by",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, from next_cursor; offset is ignored",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Count the matching resources; total is -1 when false, unless the page is the last one",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.ResourceDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources/import": {
+            "post": {
+                "description": "Add resources to the inventory of an organization from a CMDB export, without waiting for a scan. The body is a CSV file with a header row (provider, resource_id, type, region, name, account_id, tags, monthly_cost or cost; other columns, e.g. those of resource exports, are ignored) or a JSON array of resources. Tags are a JSON object or \"key=value;key=value\" in CSV files. Resources are matched on provider and resource_id: new ones are created active, known ones updated with the fields given, their status left to scans. Invalid rows are left out and reported; the others are imported. New resources count towards the resources quota of the plan.",
+                "consumes": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "Import inventory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Resources, as CSV or JSON",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.ResourceImportRow"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ImportResourcesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuotaErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources/{id}": {
+            "get": {
+                "description": "Get a single cloud resource by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "Get resource by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ResourceDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a resource from the inventory without acting on it in the cloud. Its status becomes \"removed\", distinct from \"deleted\" for resources gone from the cloud, and scans keep it removed until it is reinstated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "Remove resource from inventory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources/{id}/carbon-schedule": {
+            "get": {
+                "description": "Suggest a daily schedule for a resource that can be stopped: it runs during the run_hours hours of lowest carbon intensity of its region's grid over the next 24 hours and is stopped the rest of the day. Savings compare to running around the clock, and to running as many hours at the day's average intensity. Without a live forecast for the region, a typical day of its average intensity is used.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "Carbon-aware schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 23,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Hours the resource runs per day",
+                        "name": "run_hours",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_CarbonScheduleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources/{id}/history": {
+            "get": {
+                "description": "Get the timeline of a resource, newest first: when it was discovered, the scans that found it, the changes of its status and cost, the policies that matched it, the cleanups attempted on it, with their result and how to undo them, their rollbacks, and its exclusions and cleanup approvals. actor is the user ID or email that caused the event, or what recorded it: scan, billing, policy, cleanup or ci, and api for API clients without a session. Events older than the history retention of the organization are purged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "Get resource history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "discovered",
+                                "scanned",
+                                "status_changed",
+                                "cost_changed",
+                                "policy_matched",
+                                "cleanup_attempted",
+                                "cleanup_rolled_back",
+                                "cleanup_approved",
+                                "excluded"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by event type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, from next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of events per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ResourceHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources/{id}/reinstate": {
+            "post": {
+                "description": "Add a resource removed from the inventory back to it. It is active until the next scan sets its status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "Reinstate removed resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources/{id}/restore": {
+            "post": {
+                "description": "Cancel the deletion of a quarantined resource and queue its restore: stopped resources are started again and the quarantine tag is removed. Refused while the installation or the organization is in read-only mode, which keeps the deletion on hold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Resources"
+                ],
+                "summary": "Restore quarantined resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scans": {
+            "get": {
+                "description": "Get a paginated list of scans with optional filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scans"
+                ],
+                "summary": "List scans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by cloud provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "running",
+                            "completed",
+                            "partial",
+                            "failed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, from next_cursor; offset is ignored",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.ScanDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new cloud resource scan and queue it for processing.\nIf an identical scan (same organization, provider, regions and resource types) is already pending or running, it is returned with status 200 instead. Set force to queue a new scan anyway.\nSet incremental to only refresh the resources the provider reports as changed since the last scan; the scan runs in full when the scope was not scanned in full recently or the provider does not track changes.\nWhen regions are omitted, the organization's default regions are scanned. Regions on the organization's denylist are rejected.\nScans beyond the daily quota of the organization's plan are rejected with 429; organizations over their cloud accounts or resources quota get 402.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scans"
+                ],
+                "summary": "Create a new scan",
+                "parameters": [
+                    {
+                        "description": "Scan request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateScanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateScanResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateScanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuotaErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scans/{id}": {
+            "get": {
+                "description": "Get a single scan by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scans"
+                ],
+                "summary": "Get scan by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Scan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_ScanDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slack/commands": {
+            "post": {
+                "description": "Request URL of the /cloudsweep command, signed by Slack. \"waste top [N]\" lists the N most expensive unused resources of the organization of the workspace.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Slack"
+                ],
+                "summary": "Answer a slash command",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slack/interactions": {
+            "post": {
+                "description": "Request URL of the interactivity of the app, signed by Slack. \"Preview cleanup\" lists the unused resources found by the scan of the message. \"Approve\" approves their cleanup in the name of the user, who must be an admin or member of the organization with the email of their Slack profile, as the approval links of the owner digest do.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "Slack"
+                ],
+                "summary": "Handle a message button",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/slack/oauth/callback": {
+            "get": {
+                "description": "Called by Slack once the app is installed: stores the bot token and the channel picked for scan results",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Slack"
+                ],
+                "summary": "Finish the Slack installation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Installation state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DataResponse-handler_SlackInstallationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/failures": {
+            "get": {
+                "description": "Get a paginated list of failed background tasks, including those moved to the dead-letter queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "List task failures",
+                "parameters": [
+                    {
+                        "enum": [
+                            "retrying",
+                            "dead",
+                            "requeued"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by task type",
+                        "name": "task_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.TaskFailureDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/failures/{id}/retry": {
+            "post": {
+                "description": "Move a dead-lettered task back to its queue. If the task is no longer archived, it is re-enqueued from the recorded payload.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Retry a failed task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Task failure ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apierror.Detail": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "oneof"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "policies[0].provider"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must be one of aws, azure, gcp, kubernetes"
+                }
+            }
+        },
+        "entity.ConditionNode": {
+            "type": "object",
+            "properties": {
+                "and": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ConditionNode"
+                    }
+                },
+                "field": {
+                    "type": "string"
+                },
+                "not": {
+                    "$ref": "#/definitions/entity.ConditionNode"
+                },
+                "op": {
+                    "$ref": "#/definitions/entity.ConditionOperator"
+                },
+                "or": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ConditionNode"
+                    }
+                },
+                "value": {}
+            }
+        },
+        "entity.ConditionOperator": {
+            "type": "string",
+            "enum": [
+                "eq",
+                "ne",
+                "gt",
+                "gte",
+                "lt",
+                "lte",
+                "in",
+                "regex",
+                "exists"
+            ],
+            "x-enum-varnames": [
+                "OperatorEq",
+                "OperatorNe",
+                "OperatorGt",
+                "OperatorGte",
+                "OperatorLt",
+                "OperatorLte",
+                "OperatorIn",
+                "OperatorRegex",
+                "OperatorExists"
+            ]
+        },
+        "entity.PolicyConditions": {
+            "type": "object",
+            "properties": {
+                "excluded_tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "filter": {
+                    "description": "Filter is an optional condition tree, evaluated with the flat\nconditions above",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.ConditionNode"
+                        }
+                    ]
+                },
+                "include_iac_managed": {
+                    "description": "IncludeIaCManaged lets the policy match resources managed by an\ninfrastructure-as-code tool, which are left out by default",
+                    "type": "boolean"
+                },
+                "max_monthly_cost": {
+                    "type": "number"
+                },
+                "min_monthly_cost": {
+                    "type": "number"
+                },
+                "name_pattern": {
+                    "type": "string"
+                },
+                "regions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required_tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "unused_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AcceptInvitationRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "John Smith"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "q1w2e3r4t5y6u7i8o9p0"
+                }
+            }
+        },
+        "handler.AccountIntegrationDTO": {
+            "type": "object",
+            "properties": {
+                "account_count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "last_sync_at": {
+                    "type": "string"
+                },
+                "last_sync_error": {
+                    "type": "string",
+                    "example": "AccessDenied: User is not authorized to perform: sts:AssumeRole"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "secrets_set": {
+                    "description": "SecretsSet lists the secret settings, whose values are never returned",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "external_id"
+                    ]
+                },
+                "settings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AllocationResponse": {
+            "type": "object",
+            "properties": {
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.TagAllocation"
+                    }
+                },
+                "computed_at": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "string",
+                    "example": "2024-05"
+                }
+            }
+        },
+        "handler.AllocationValue": {
+            "type": "object",
+            "properties": {
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 3120
+                },
+                "resource_count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "share_percent": {
+                    "description": "of the total cost",
+                    "type": "number",
+                    "example": 18.2
+                },
+                "value": {
+                    "type": "string",
+                    "example": "data-platform"
+                },
+                "waste": {
+                    "type": "number",
+                    "example": 410.5
+                }
+            }
+        },
+        "handler.ApplyPoliciesRequest": {
+            "type": "object",
+            "required": [
+                "organization_id",
+                "policies"
+            ],
+            "properties": {
+                "dry_run": {
+                    "description": "DryRun reports the changes without making them",
+                    "type": "boolean",
+                    "example": false
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "policies": {
+                    "description": "Policies is the full desired set: managed policies missing from it are\ndeleted. An empty list deletes them all; it cannot be omitted.",
+                    "type": "array",
+                    "maxItems": 500,
+                    "items": {
+                        "$ref": "#/definitions/handler.PolicySpec"
+                    }
+                }
+            }
+        },
+        "handler.ApplyPoliciesResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ebs-unused-prod"
+                    ]
+                },
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "old-snapshots"
+                    ]
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "policies": {
+                    "description": "Policies is the set of managed policies after the apply, or as they\nare on a dry run",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.PolicyDTO"
+                    }
+                },
+                "unchanged": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k8s-orphans"
+                    ]
+                },
+                "updated": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "idle-vms"
+                    ]
+                }
+            }
+        },
+        "handler.BackupSettingsDTO": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "retention_days": {
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "handler.BackupSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "retention_days": {
+                    "description": "RetentionDays is how long backups are kept; zero uses the default of\n30 days",
+                    "type": "integer",
+                    "maximum": 3650,
+                    "minimum": 0,
+                    "example": 30
+                }
+            }
+        },
+        "handler.CarbonResponse": {
+            "type": "object",
+            "properties": {
+                "by_provider": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ProviderCarbon"
+                    }
+                },
+                "by_region": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.RegionCarbon"
+                    }
+                }
+            }
+        },
+        "handler.CarbonScheduleDTO": {
+            "type": "object",
+            "properties": {
+                "average_intensity_g_per_kwh": {
+                    "type": "number",
+                    "example": 62.5
+                },
+                "energy_kwh_per_hour": {
+                    "type": "number",
+                    "example": 0.12
+                },
+                "forecast": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.IntensityPointDTO"
+                    }
+                },
+                "monthly_carbon_savings_kg": {
+                    "type": "number",
+                    "example": 3.4
+                },
+                "monthly_shift_savings_kg": {
+                    "type": "number",
+                    "example": 0.6
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "run_hours": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "run_intensity_g_per_kwh": {
+                    "type": "number",
+                    "example": 48.1
+                },
+                "run_windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CarbonWindowDTO"
+                    }
+                },
+                "source": {
+                    "type": "string",
+                    "enum": [
+                        "forecast",
+                        "profile"
+                    ],
+                    "example": "forecast"
+                },
+                "stop_windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CarbonWindowDTO"
+                    }
+                },
+                "zone": {
+                    "type": "string",
+                    "example": "FR"
+                }
+            }
+        },
+        "handler.CarbonWindowDTO": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CleanupDNSReferenceDTO": {
+            "type": "object",
+            "properties": {
+                "blocking": {
+                    "description": "Blocking is true when the action is refused while the records exist.\nQuarantine only warns, the records being searched again before the\npurge.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "checked": {
+                    "description": "Checked is false when the last scan did not search the DNS zones;\nthey are then searched before deletion, which is refused if they\ncannot be",
+                    "type": "boolean",
+                    "example": true
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "www.example.com CNAME"
+                    ]
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                }
+            }
+        },
+        "handler.CleanupJobItemDTO": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "delete"
+                },
+                "attempted_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "recovery": {
+                    "description": "Recovery tells how to undo the action, for those that succeeded: its\nkind, whether it is automated, the snapshot, instance or bucket\nversioning recorded, the end of its undo window and instructions",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "rollback_at": {
+                    "type": "string"
+                },
+                "rollback_error": {
+                    "type": "string"
+                },
+                "rolled_back": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handler.CleanupPreflightDTO": {
+            "type": "object",
+            "properties": {
+                "findings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CleanupPreflightFindingDTO"
+                    }
+                },
+                "missing_permissions": {
+                    "description": "MissingPermissions are those the credentials lack, when the cleaner\ncould check them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required_permissions": {
+                    "description": "RequiredPermissions are the provider permissions the action calls",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ec2:DeleteVolume"
+                    ]
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "verdict": {
+                    "description": "Verdict is whether the resource will be cleaned up, left untouched or\nrefused",
+                    "type": "string",
+                    "enum": [
+                        "proceed",
+                        "skip",
+                        "fail"
+                    ],
+                    "example": "proceed"
+                }
+            }
+        },
+        "handler.CleanupPreflightFindingDTO": {
+            "type": "object",
+            "properties": {
+                "blocking": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "check": {
+                    "type": "string",
+                    "enum": [
+                        "status",
+                        "region",
+                        "action",
+                        "iac",
+                        "snooze",
+                        "dependency",
+                        "dns",
+                        "permissions"
+                    ],
+                    "example": "dependency"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "still referenced by DNS records [www.example.com CNAME]"
+                }
+            }
+        },
+        "handler.CleanupPreviewDTO": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "delete"
+                },
+                "backup": {
+                    "description": "Backup is true in backup-then-delete mode, whose snapshots cost\nEstimatedBackupCost per month",
+                    "type": "boolean",
+                    "example": false
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "dns_references": {
+                    "description": "DNSReferences are the resources DNS records may still point at",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CleanupDNSReferenceDTO"
+                    }
+                },
+                "estimated_backup_cost": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "estimated_carbon_savings": {
+                    "type": "number",
+                    "example": 35.5
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "example": 250
+                },
+                "preflight": {
+                    "description": "Preflight are the checks of the cleanup on each resource",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CleanupPreflightDTO"
+                    }
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ResourceDTO"
+                    }
+                },
+                "verdicts": {
+                    "description": "Verdicts count the resources by verdict",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handler.CleanupSessionDTO": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer",
+                    "example": 35
+                },
+                "action": {
+                    "type": "string",
+                    "example": "delete"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "estimated_carbon_savings": {
+                    "type": "number",
+                    "example": 85.5
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "example": 1250
+                },
+                "executed_at": {
+                    "type": "string"
+                },
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "rejected": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "executed",
+                        "cancelled"
+                    ],
+                    "example": "open"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "task_12345"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CleanupSessionDecision": {
+            "type": "object",
+            "required": [
+                "decision",
+                "resource_id"
+            ],
+            "properties": {
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "accepted",
+                        "rejected",
+                        "pending"
+                    ],
+                    "example": "accepted"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                }
+            }
+        },
+        "handler.CleanupSessionItemDTO": {
+            "type": "object",
+            "properties": {
+                "decided_at": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "accepted",
+                        "rejected"
+                    ],
+                    "example": "accepted"
+                },
+                "resource": {
+                    "$ref": "#/definitions/handler.ResourceDTO"
+                }
+            }
+        },
+        "handler.CloudAccountDTO": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "123456789012"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_owner": {
+                    "description": "owner of the resources no mapping or tag attributes",
+                    "type": "string",
+                    "example": "platform@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "integration_id": {
+                    "description": "IntegrationID is the integration that discovered the account",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "is_active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_sync_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "production"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "pricing_error": {
+                    "type": "string",
+                    "example": "AccessDeniedException: User is not authorized to perform: ce:GetCostAndUsageWithResources"
+                },
+                "pricing_settings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "pricing_source": {
+                    "description": "PricingSource is where resource costs come from: \"estimate\" or a\npricing source of the provider, see GET /providers",
+                    "type": "string",
+                    "example": "cost_explorer"
+                },
+                "pricing_synced_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                }
+            }
+        },
+        "handler.CreateCleanupSessionRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "organization_id"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "delete",
+                        "stop",
+                        "tag",
+                        "notify",
+                        "quarantine",
+                        "release",
+                        "ticket"
+                    ],
+                    "example": "delete"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "filters": {
+                    "description": "provider, type, region",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "view_id": {
+                    "description": "ViewID narrows the session down to the unused resources of a saved\nview",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                }
+            }
+        },
+        "handler.CreateCustomResourceTypeRequest": {
+            "type": "object",
+            "required": [
+                "cloud_type",
+                "name",
+                "provider"
+            ],
+            "properties": {
+                "cloud_type": {
+                    "description": "CloudType is the type name of the provider, e.g. the CloudFormation\ntype listed with Cloud Control for AWS",
+                    "type": "string",
+                    "example": "AWS::DynamoDB::Table"
+                },
+                "detection": {
+                    "$ref": "#/definitions/handler.MetricDetectionDTO"
+                },
+                "monthly_cost": {
+                    "description": "MonthlyCost is the estimated cost of a resource in USD, used for the\nsavings of unused ones",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 25
+                },
+                "name": {
+                    "description": "Name is the resource type of the resources found, which must not be a\nbuilt-in type",
+                    "type": "string",
+                    "example": "dynamodb_table"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                }
+            }
+        },
+        "handler.CreateExportRequest": {
+            "type": "object",
+            "required": [
+                "format",
+                "organization_id",
+                "type"
+            ],
+            "properties": {
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "json"
+                    ],
+                    "example": "csv"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "resources",
+                        "scans"
+                    ],
+                    "example": "resources"
+                }
+            }
+        },
+        "handler.CreateExportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ExportDTO"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "export queued for generation"
+                }
+            }
+        },
+        "handler.CreateInvitationRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@acme.com"
+                },
+                "invited_by": {
+                    "type": "string",
+                    "example": "jane@acme.com"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member",
+                        "viewer"
+                    ],
+                    "example": "member"
+                }
+            }
+        },
+        "handler.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Acme"
+                },
+                "plan": {
+                    "type": "string",
+                    "enum": [
+                        "free",
+                        "pro",
+                        "enterprise"
+                    ],
+                    "example": "pro"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme"
+                }
+            }
+        },
+        "handler.CreatePolicyRequest": {
+            "type": "object",
+            "required": [
+                "actions",
+                "name",
+                "organization_id",
+                "provider"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "notify",
+                        "delete"
+                    ]
+                },
+                "conditions": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Automatically delete EBS volumes unused for 30 days"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Delete unused EBS volumes"
+                },
+                "off_hours": {
+                    "description": "OffHours is required by the \"schedule\" action",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.OffHoursRequest"
+                        }
+                    ]
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ebs_volume",
+                        "ebs_snapshot"
+                    ]
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "0 0 * * *"
+                },
+                "version": {
+                    "description": "Version is the version of the policy an update was made from,\nrejected with a 409 if it was updated since. The If-Match header may\nbe used instead; without either the update is unconditional.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "view_id": {
+                    "description": "ViewID narrows the policy down to the resources of a saved view",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                }
+            }
+        },
+        "handler.CreateResourceViewRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "organization_id"
+            ],
+            "properties": {
+                "created_by": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Production EBS volumes left unattached"
+                },
+                "filters": {
+                    "$ref": "#/definitions/handler.ResourceFilter"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Unused prod EBS \u003e $50"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "handler.CreateScanRequest": {
+            "type": "object",
+            "required": [
+                "organization_id",
+                "provider"
+            ],
+            "properties": {
+                "force": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "incremental": {
+                    "description": "Incremental only refreshes the resources changed since the last scan",
+                    "type": "boolean",
+                    "example": false
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "regions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "us-east-1",
+                        "eu-west-1"
+                    ]
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ec2_instance",
+                        "ebs_volume"
+                    ]
+                }
+            }
+        },
+        "handler.CreateScanResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ScanDTO"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "scan created and queued for processing"
+                }
+            }
+        },
+        "handler.CustomResourceTypeDTO": {
+            "type": "object",
+            "properties": {
+                "cloud_type": {
+                    "type": "string",
+                    "example": "AWS::DynamoDB::Table"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detection": {
+                    "$ref": "#/definitions/handler.MetricDetectionDTO"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "dynamodb_table"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_AccountIntegrationDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.AccountIntegrationDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_CleanupJobItemDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CleanupJobItemDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_CloudAccountDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CloudAccountDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_CustomResourceTypeDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CustomResourceTypeDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_MemberDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.MemberDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_OwnerMappingDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.OwnerMappingDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_PolicyTemplateDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.PolicyTemplateDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_ProviderDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ProviderDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_QueueStatsDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.QueueStatsDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_QueueTaskDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.QueueTaskDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-array_handler_ResourceTypeDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ResourceTypeDTO"
+                    }
+                }
+            }
+        },
+        "handler.DataResponse-handler_AccountIntegrationDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.AccountIntegrationDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_AllocationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.AllocationResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ApplyPoliciesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ApplyPoliciesResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_CarbonScheduleDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.CarbonScheduleDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_CleanupSessionDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.CleanupSessionDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_CloudAccountDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.CloudAccountDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_CustomResourceTypeDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.CustomResourceTypeDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ExportDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ExportDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ForecastResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ForecastResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ImportResourcesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ImportResourcesResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_InvitationDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.InvitationDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_JobDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.JobDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_MemberDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.MemberDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_OnboardingDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.OnboardingDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_OrganizationDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.OrganizationDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_OrganizationSettingsDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.OrganizationSettingsDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_PermissionReportDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.PermissionReportDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_PolicyDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.PolicyDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_PolicySimulationDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.PolicySimulationDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ResourceDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ResourceDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ResourceViewDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ResourceViewDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_SSOConnectionDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.SSOConnectionDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ScanDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ScanDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_ScoreResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.ScoreResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_SessionDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.SessionDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_SlackInstallationDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.SlackInstallationDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_SummaryStats": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.SummaryStats"
+                }
+            }
+        },
+        "handler.DataResponse-handler_TagDistributionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.TagDistributionResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_TickerResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.TickerResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_TicketIntegrationDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.TicketIntegrationDTO"
+                }
+            }
+        },
+        "handler.DataResponse-handler_TopOffendersResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.TopOffendersResponse"
+                }
+            }
+        },
+        "handler.DataResponse-handler_UsageDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handler.UsageDTO"
+                }
+            }
+        },
+        "handler.DecideCleanupSessionRequest": {
+            "type": "object",
+            "required": [
+                "decisions"
+            ],
+            "properties": {
+                "decided_by": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "decisions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handler.CleanupSessionDecision"
+                    }
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apierror.Detail"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "invalid request"
+                },
+                "request_id": {
+                    "description": "RequestID is also returned in the X-Request-ID header",
+                    "type": "string",
+                    "example": "4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"
+                }
+            }
+        },
+        "handler.ExecuteCleanupRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "organization_id"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "delete",
+                        "stop",
+                        "tag",
+                        "notify",
+                        "quarantine",
+                        "release",
+                        "ticket"
+                    ],
+                    "example": "delete"
+                },
+                "backup": {
+                    "description": "Backup snapshots volumes, disks and databases before deleting them\nand waits for the snapshots to complete; the backup_before_delete\nsetting of the organization when omitted",
+                    "type": "boolean",
+                    "example": true
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "resource_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "550e8400-e29b-41d4-a716-446655440001",
+                        "550e8400-e29b-41d4-a716-446655440002"
+                    ]
+                },
+                "view_id": {
+                    "description": "ViewID selects the resources of a saved view in place of resource_ids",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                }
+            }
+        },
+        "handler.ExecuteCleanupResponse": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "job_id": {
+                    "description": "JobID follows the cleanup in the jobs API",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440004"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "cleanup task queued"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "task_12345"
+                }
+            }
+        },
+        "handler.ExecuteCleanupSessionRequest": {
+            "type": "object",
+            "properties": {
+                "backup": {
+                    "description": "Backup snapshots volumes, disks and databases before deleting them;\nthe backup_before_delete setting of the organization when omitted",
+                    "type": "boolean",
+                    "example": true
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "handler.ExportDTO": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "download_expires_at": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "json"
+                    ],
+                    "example": "csv"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "row_count": {
+                    "type": "integer",
+                    "example": 12500
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "example": 2048576
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "completed"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "resources",
+                        "scans"
+                    ],
+                    "example": "resources"
+                }
+            }
+        },
+        "handler.ForecastResponse": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "type": "string"
+                },
+                "history_days": {
+                    "type": "integer"
+                },
+                "horizons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ForecastHorizon"
+                    }
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 15000
+                },
+                "monthly_waste": {
+                    "type": "number",
+                    "example": 2500
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ForecastMonth"
+                    }
+                },
+                "open_recommendations": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "recommendation_monthly_savings": {
+                    "type": "number",
+                    "example": 840
+                },
+                "scheduled_policies": {
+                    "description": "ScheduledPolicies are the scheduled policies with savings, soonest\nfirst",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ScheduledSaving"
+                    }
+                },
+                "spend_trend_per_month": {
+                    "description": "SpendTrend and WasteTrend are the fitted changes of the monthly\nspend and waste per month",
+                    "type": "number"
+                },
+                "waste_trend_per_month": {
+                    "type": "number"
+                }
+            }
+        },
+        "handler.GitOpsRepoDTO": {
+            "type": "object",
+            "required": [
+                "branch",
+                "provider",
+                "repo"
+            ],
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "prod"
+                },
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "github",
+                        "gitlab"
+                    ],
+                    "example": "github"
+                },
+                "repo": {
+                    "type": "string",
+                    "example": "acme/infra"
+                }
+            }
+        },
+        "handler.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "service": {
+                    "type": "string",
+                    "example": "cloudsweep-api"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "handler.IaCChangeDTO": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "aws_instance.web"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "file_path": {
+                    "type": "string",
+                    "example": "prod/main.tf"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "github",
+                        "gitlab"
+                    ],
+                    "example": "github"
+                },
+                "repo": {
+                    "type": "string",
+                    "example": "acme/infra"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "closed",
+                        "merged"
+                    ],
+                    "example": "open"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://github.com/acme/infra/pull/42"
+                }
+            }
+        },
+        "handler.ImportResourcesResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "errors": {
+                    "description": "Errors lists the rows left out, in file order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ImportRowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 18
+                }
+            }
+        },
+        "handler.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "unknown resource type \"ebs\" for provider aws"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "vol-0123456789abcdef0"
+                },
+                "row": {
+                    "description": "Row is the line of CSV files and the position, from 1, in JSON arrays",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "handler.IntensityPointDTO": {
+            "type": "object",
+            "properties": {
+                "intensity_g_per_kwh": {
+                    "type": "number",
+                    "example": 54.2
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.InvitationDTO": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@acme.com"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440004"
+                },
+                "invited_by": {
+                    "type": "string",
+                    "example": "jane@acme.com"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member",
+                        "viewer"
+                    ],
+                    "example": "member"
+                }
+            }
+        },
+        "handler.InviteRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@acme.com"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member",
+                        "viewer"
+                    ],
+                    "example": "member"
+                }
+            }
+        },
+        "handler.JobDTO": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440004"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "progress": {
+                    "description": "percent",
+                    "type": "integer",
+                    "example": 40
+                },
+                "result_url": {
+                    "description": "ResultURL is the API path of what the job produced: the scan, the\nexport, or the provider calls of the cleanup",
+                    "type": "string",
+                    "example": "/api/v1/scans/550e8400-e29b-41d4-a716-446655440001"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "running"
+                },
+                "target_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440004"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "scan",
+                        "cleanup",
+                        "export"
+                    ],
+                    "example": "scan"
+                }
+            }
+        },
+        "handler.MemberDTO": {
+            "type": "object",
+            "properties": {
+                "joined_at": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member",
+                        "viewer"
+                    ],
+                    "example": "admin"
+                },
+                "user": {
+                    "$ref": "#/definitions/handler.UserDTO"
+                },
+                "weekly_summary": {
+                    "description": "admins only receive it",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handler.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "operation successful"
+                }
+            }
+        },
+        "handler.MetricDetectionDTO": {
+            "type": "object",
+            "properties": {
+                "above": {
+                    "description": "Above reports resources unused above the threshold rather than below",
+                    "type": "boolean",
+                    "example": false
+                },
+                "dimension": {
+                    "description": "Dimension is the dimension of the metric valued with the resource ID",
+                    "type": "string",
+                    "example": "TableName"
+                },
+                "lookback_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "metric_name": {
+                    "type": "string",
+                    "example": "ConsumedReadCapacityUnits"
+                },
+                "namespace": {
+                    "type": "string",
+                    "example": "AWS/DynamoDB"
+                },
+                "statistic": {
+                    "type": "string",
+                    "enum": [
+                        "Average",
+                        "Sum",
+                        "Minimum",
+                        "Maximum",
+                        "SampleCount"
+                    ],
+                    "example": "Sum"
+                },
+                "threshold": {
+                    "type": "number",
+                    "example": 1
+                }
+            }
+        },
+        "handler.NotificationSettingsDTO": {
+            "type": "object",
+            "properties": {
+                "anomaly_min_increase": {
+                    "type": "number",
+                    "example": 100
+                },
+                "anomaly_threshold_percent": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "pagerduty_configured": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "routes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "teams_configured": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handler.NotificationSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "anomaly_min_increase": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
+                },
+                "anomaly_threshold_percent": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "pagerduty_routing_key": {
+                    "type": "string",
+                    "example": "e93facc04764012d7bfb002500d5d1a6"
+                },
+                "routes": {
+                    "description": "Routes map events (scan.finished, cost.anomaly, policy.matched) to\nchannels (teams, pagerduty)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "teams_webhook_url": {
+                    "type": "string",
+                    "example": "https://acme.webhook.office.com/webhookb2/..."
+                }
+            }
+        },
+        "handler.OffHoursRequest": {
+            "type": "object",
+            "properties": {
+                "start": {
+                    "type": "string",
+                    "example": "0 8 * * 1-5"
+                },
+                "stop": {
+                    "type": "string",
+                    "example": "0 19 * * 1-5"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Paris"
+                }
+            }
+        },
+        "handler.OnboardOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "admin",
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "admin": {
+                    "$ref": "#/definitions/handler.OnboardingAdminRequest"
+                },
+                "invites": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "$ref": "#/definitions/handler.InviteRequest"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Acme"
+                },
+                "plan": {
+                    "type": "string",
+                    "enum": [
+                        "free",
+                        "pro",
+                        "enterprise"
+                    ],
+                    "example": "pro"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme"
+                }
+            }
+        },
+        "handler.OnboardingAdminRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jane@acme.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Jane Doe"
+                }
+            }
+        },
+        "handler.OnboardingDTO": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "$ref": "#/definitions/handler.UserDTO"
+                },
+                "invitations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.InvitationDTO"
+                    }
+                },
+                "organization": {
+                    "$ref": "#/definitions/handler.OrganizationDTO"
+                }
+            }
+        },
+        "handler.OrganizationDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deactivated_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "is_active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "plan": {
+                    "type": "string",
+                    "enum": [
+                        "free",
+                        "pro",
+                        "enterprise"
+                    ],
+                    "example": "pro"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.OrganizationSettingsDTO": {
+            "type": "object",
+            "properties": {
+                "allocation_tag_keys": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team",
+                        "project"
+                    ]
+                },
+                "audit_retention_days": {
+                    "type": "integer",
+                    "example": 365
+                },
+                "backup_before_delete": {
+                    "$ref": "#/definitions/handler.BackupSettingsDTO"
+                },
+                "default_owner": {
+                    "type": "string",
+                    "example": "finops@example.com"
+                },
+                "default_regions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "eu-west-1",
+                        "eu-central-1"
+                    ]
+                },
+                "gitops_repos": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handler.GitOpsRepoDTO"
+                    }
+                },
+                "history_retention_days": {
+                    "description": "HistoryRetentionDays is the retention in effect, zero when the\nhistory is kept forever",
+                    "type": "integer",
+                    "example": 90
+                },
+                "notifications": {
+                    "$ref": "#/definitions/handler.NotificationSettingsDTO"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "owner_aliases": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "owner_notifications": {
+                    "$ref": "#/definitions/handler.OwnerNotificationsDTO"
+                },
+                "owner_tag_keys": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "owner",
+                        "team"
+                    ]
+                },
+                "region_denylist": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "cn-*",
+                        "us-gov-*"
+                    ]
+                },
+                "terraform_states": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tfstate/prod.tfstate"
+                    ]
+                },
+                "weekly_summary": {
+                    "$ref": "#/definitions/handler.WeeklySummaryDTO"
+                }
+            }
+        },
+        "handler.OwnerMappingDTO": {
+            "type": "object",
+            "properties": {
+                "owner": {
+                    "type": "string",
+                    "example": "jane@acme.com"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "i-0abc*"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handler.OwnerNotificationsDTO": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "email",
+                            "slack"
+                        ]
+                    },
+                    "example": [
+                        "email",
+                        "slack"
+                    ]
+                },
+                "digest": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "handler.OwnerNotificationsRequest": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "description": "Channels are email, slack or both; email when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "email",
+                        "slack"
+                    ]
+                },
+                "digest": {
+                    "description": "Digest sends one message per owner and day",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handler.PaginatedResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page when passed as cursor, empty on the\nlast page. Lists that support it return it in offset mode too.",
+                    "type": "string",
+                    "example": "eyJzIjoiY3JlYXRlZF9hdCIsInYiOiIyMDI0LTAxLTE1VDEwOjMwOjAwWiIsImkiOiI1NTBlODQwMCJ9"
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "handler.PermissionCheckDTO": {
+            "type": "object",
+            "properties": {
+                "granted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ec2:DeleteVolume"
+                    ]
+                },
+                "purpose": {
+                    "type": "string",
+                    "enum": [
+                        "scan",
+                        "stop",
+                        "tag",
+                        "quarantine",
+                        "delete",
+                        "release"
+                    ],
+                    "example": "delete"
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ec2:DeleteVolume"
+                    ]
+                }
+            }
+        },
+        "handler.PermissionReportDTO": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.PermissionCheckDTO"
+                    }
+                },
+                "complete": {
+                    "description": "Complete is true when no permission is missing",
+                    "type": "boolean",
+                    "example": false
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                }
+            }
+        },
+        "handler.PolicyDTO": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "notify",
+                            "tag",
+                            "stop",
+                            "delete",
+                            "schedule_offhours",
+                            "schedule",
+                            "quarantine",
+                            "release",
+                            "ticket"
+                        ]
+                    },
+                    "example": [
+                        "notify",
+                        "delete"
+                    ]
+                },
+                "conditions": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Automatically delete EBS volumes unused for 30 days"
+                },
+                "external_id": {
+                    "type": "string",
+                    "example": "ebs-unused-prod"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "is_enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Delete unused EBS volumes"
+                },
+                "off_hours": {
+                    "$ref": "#/definitions/handler.OffHoursRequest"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ebs_volume"
+                    ]
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "0 0 * * *"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented by every update, and returned in the ETag\nheader",
+                    "type": "integer",
+                    "example": 3
+                },
+                "view_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                }
+            }
+        },
+        "handler.PolicySimulationDTO": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "notify",
+                        "delete"
+                    ]
+                },
+                "condition_matches": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "estimated_carbon_savings": {
+                    "type": "number",
+                    "example": 35.5
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "example": 250
+                },
+                "evaluated": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "policy_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.SimulatedResourceDTO"
+                    }
+                }
+            }
+        },
+        "handler.PolicySpec": {
+            "type": "object",
+            "required": [
+                "actions",
+                "external_id",
+                "name",
+                "provider"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "notify",
+                        "delete"
+                    ]
+                },
+                "conditions": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Automatically delete EBS volumes unused for 30 days"
+                },
+                "enabled": {
+                    "description": "Enabled defaults to true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "external_id": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "ebs-unused-prod"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Delete unused EBS volumes"
+                },
+                "off_hours": {
+                    "$ref": "#/definitions/handler.OffHoursRequest"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ebs_volume",
+                        "ebs_snapshot"
+                    ]
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "0 0 * * *"
+                },
+                "view_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                }
+            }
+        },
+        "handler.PolicyTemplateDTO": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "notify",
+                        "quarantine"
+                    ]
+                },
+                "conditions": {
+                    "$ref": "#/definitions/entity.PolicyConditions"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Quarantine NAT gateways that processed almost no traffic for 14 days"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aws-idle-nat-gateways"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Idle NAT gateways"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "nat_gateway"
+                    ]
+                }
+            }
+        },
+        "handler.ProviderCallDTO": {
+            "type": "object",
+            "properties": {
+                "api": {
+                    "type": "string",
+                    "example": "ec2:DeleteVolume"
+                },
+                "called_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 184
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "params_hash": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"
+                }
+            }
+        },
+        "handler.ProviderCarbon": {
+            "type": "object",
+            "properties": {
+                "carbon_kg": {
+                    "type": "number",
+                    "example": 450.25
+                },
+                "potential_savings_kg": {
+                    "type": "number",
+                    "example": 75.5
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                }
+            }
+        },
+        "handler.ProviderDTO": {
+            "type": "object",
+            "properties": {
+                "credentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/provider.CredentialField"
+                    }
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Amazon Web Services"
+                },
+                "integration": {
+                    "description": "Integration is set for providers whose accounts can be discovered,\nsee /organizations/{id}/integrations/{provider}",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.ProviderIntegrationDTO"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "pricing_sources": {
+                    "description": "PricingSources are the billing data resource costs can be reconciled\nwith, see /organizations/{id}/cloud-accounts/{account_id}/pricing",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/provider.PricingSource"
+                    }
+                },
+                "region_label": {
+                    "description": "RegionLabel names what the regions of scans are for the provider",
+                    "type": "string",
+                    "example": "region"
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ProviderResourceTypeDTO"
+                    }
+                }
+            }
+        },
+        "handler.ProviderIntegrationDTO": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string",
+                    "example": "AWS Organizations"
+                },
+                "settings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/provider.CredentialField"
+                    }
+                }
+            }
+        },
+        "handler.ProviderResourceTypeDTO": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "description": "Actions are the cleanup actions the resource type supports",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "delete",
+                        "stop",
+                        "tag",
+                        "notify",
+                        "quarantine"
+                    ]
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ec2_instance"
+                }
+            }
+        },
+        "handler.ProviderSavings": {
+            "type": "object",
+            "properties": {
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 1500
+                },
+                "potential_savings": {
+                    "type": "number",
+                    "example": 250
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "unused_count": {
+                    "type": "integer",
+                    "example": 25
+                }
+            }
+        },
+        "handler.QueueStatsDTO": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "archived": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "completed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "failed_today": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "latency_seconds": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "memory_bytes": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "paused": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "processed_today": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "queue": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "retry": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "scheduled": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "handler.QueueTaskDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_failed_at": {
+                    "type": "string"
+                },
+                "max_retry": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "next_process_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "queue": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "retried": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "state": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "scan:resources"
+                }
+            }
+        },
+        "handler.QuotaErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apierror.Detail"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "message": {
+                    "type": "string",
+                    "example": "invalid request"
+                },
+                "plan": {
+                    "type": "string",
+                    "example": "free"
+                },
+                "quota": {
+                    "type": "string",
+                    "enum": [
+                        "cloud_accounts",
+                        "scans_per_day",
+                        "resources",
+                        "retention_days"
+                    ],
+                    "example": "scans_per_day"
+                },
+                "request_id": {
+                    "description": "RequestID is also returned in the X-Request-ID header",
+                    "type": "string",
+                    "example": "4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"
+                },
+                "upgrade": {
+                    "type": "string",
+                    "example": "pro"
+                },
+                "used": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "handler.QuotaUsageDTO": {
+            "type": "object",
+            "properties": {
+                "exceeded": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "quota": {
+                    "type": "string",
+                    "enum": [
+                        "cloud_accounts",
+                        "scans_per_day",
+                        "resources",
+                        "retention_days"
+                    ],
+                    "example": "resources"
+                },
+                "unlimited": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "used": {
+                    "type": "integer",
+                    "example": 320
+                }
+            }
+        },
+        "handler.ReadyResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
+        "handler.RecommendationDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "string",
+                    "example": "m5.2xlarge"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "estimated_monthly_savings": {
+                    "type": "number",
+                    "example": 140.16
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "recommended": {
+                    "type": "string",
+                    "example": "m5.xlarge"
+                },
+                "resource": {
+                    "$ref": "#/definitions/handler.ResourceDTO"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "dismissed"
+                    ],
+                    "example": "open"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "rightsize",
+                        "gp3_migration",
+                        "commitment"
+                    ],
+                    "example": "rightsize"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RegionCarbon": {
+            "type": "object",
+            "properties": {
+                "carbon_kg": {
+                    "type": "number",
+                    "example": 250
+                },
+                "region": {
+                    "type": "string",
+                    "example": "us-east-1"
+                }
+            }
+        },
+        "handler.ResourceDTO": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "123456789012"
+                },
+                "carbon_footprint_kg": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "cleanup_approved_at": {
+                    "type": "string"
+                },
+                "cleanup_approved_by": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "iac_managed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "iac_tool": {
+                    "type": "string",
+                    "example": "terraform"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 45.5
+                },
+                "name": {
+                    "type": "string",
+                    "example": "my-instance"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "owner_source": {
+                    "type": "string",
+                    "enum": [
+                        "mapping",
+                        "tag",
+                        "account",
+                        "default"
+                    ],
+                    "example": "tag"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "quarantine_until": {
+                    "type": "string"
+                },
+                "quarantined_at": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "us-east-1"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "i-1234567890abcdef0"
+                },
+                "snoozed_until": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "unused",
+                        "deleted",
+                        "removed",
+                        "excluded"
+                    ],
+                    "example": "unused"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ec2_instance"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ResourceEventDTO": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "scan"
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440005"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "policy_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                },
+                "policy_name": {
+                    "type": "string",
+                    "example": "Unattached volumes"
+                },
+                "scan_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "task_id": {
+                    "description": "TaskID is the cleanup task of attempts and rollbacks",
+                    "type": "string",
+                    "example": "0f4c1d3e-8a2b-4c5d-9e6f-7a8b9c0d1e2f"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "discovered",
+                        "scanned",
+                        "status_changed",
+                        "cost_changed",
+                        "policy_matched",
+                        "cleanup_attempted",
+                        "cleanup_rolled_back",
+                        "cleanup_approved",
+                        "excluded"
+                    ],
+                    "example": "status_changed"
+                }
+            }
+        },
+        "handler.ResourceFilter": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "AccountID is the provider account, subscription, project or cluster",
+                    "type": "string",
+                    "example": "123456789012"
+                },
+                "min_cost": {
+                    "description": "MinCost keeps resources costing at least this much per month",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "order": {
+                    "type": "string",
+                    "enum": [
+                        "asc",
+                        "desc"
+                    ],
+                    "example": "desc"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "q": {
+                    "description": "Q searches names, cloud IDs and tag values, with tag:key=value and\ntag:key!=value selectors",
+                    "type": "string",
+                    "example": "tag:env=prod"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "us-east-1"
+                },
+                "sort": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "cost",
+                        "carbon",
+                        "age"
+                    ],
+                    "example": "cost"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "unused"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ebs_volume"
+                }
+            }
+        },
+        "handler.ResourceHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ResourceEventDTO"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the older events when passed as cursor, empty on\nthe last page",
+                    "type": "string",
+                    "example": "eyJzIjoiaGlzdG9yeSJ9"
+                }
+            }
+        },
+        "handler.ResourceImportRow": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "123456789012"
+                },
+                "cost": {
+                    "description": "Cost is accepted in place of monthly_cost",
+                    "type": "number",
+                    "example": 12.5
+                },
+                "monthly_cost": {
+                    "description": "MonthlyCost is in USD per month",
+                    "type": "number",
+                    "example": 12.5
+                },
+                "name": {
+                    "type": "string",
+                    "example": "data-volume"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "eu-west-1"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "vol-0123456789abcdef0"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ebs_volume"
+                }
+            }
+        },
+        "handler.ResourceTypeDTO": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "description": "Actions are the cleanup actions the resource type supports",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "delete",
+                        "tag",
+                        "notify",
+                        "quarantine"
+                    ]
+                },
+                "heuristics": {
+                    "description": "Heuristics tell when resources of the type are reported unused",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Not mounted by any running pod"
+                    ]
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "kubernetes"
+                },
+                "scannable": {
+                    "description": "Scannable is false for the types of providers described but not\nscanned yet",
+                    "type": "boolean",
+                    "example": true
+                },
+                "type": {
+                    "type": "string",
+                    "example": "k8s_pvc"
+                }
+            }
+        },
+        "handler.ResourceViewDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Production EBS volumes left unattached"
+                },
+                "filters": {
+                    "$ref": "#/definitions/handler.ResourceFilter"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Unused prod EBS \u003e $50"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SSOConnectionDTO": {
+            "type": "object",
+            "properties": {
+                "allowed_domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "acme.com"
+                    ]
+                },
+                "client_id": {
+                    "type": "string",
+                    "example": "0oa1b2c3d4e5f6g7h8i9"
+                },
+                "client_secret_set": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "default_role": {
+                    "type": "string",
+                    "example": "viewer"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "group_roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "groups_claim": {
+                    "type": "string",
+                    "example": "groups"
+                },
+                "issuer": {
+                    "type": "string",
+                    "example": "https://acme.okta.com"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SavingsResponse": {
+            "type": "object",
+            "properties": {
+                "by_provider": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ProviderSavings"
+                    }
+                },
+                "by_resource_type": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.TypeSavings"
+                    }
+                }
+            }
+        },
+        "handler.ScanDTO": {
+            "type": "object",
+            "properties": {
+                "carbon_savings_kg": {
+                    "type": "number",
+                    "example": 45.5
+                },
+                "changes_since": {
+                    "type": "string"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "estimated_savings": {
+                    "type": "number",
+                    "example": 1250
+                },
+                "failed_regions": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "full",
+                        "incremental"
+                    ],
+                    "example": "full"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "regions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "us-east-1",
+                        "eu-west-1"
+                    ]
+                },
+                "resource_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ec2_instance",
+                        "ebs_volume"
+                    ]
+                },
+                "resources_changed": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "resources_found": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "resources_new": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "resources_removed": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "completed",
+                        "partial",
+                        "failed",
+                        "cancelled"
+                    ],
+                    "example": "completed"
+                },
+                "unused_found": {
+                    "type": "integer",
+                    "example": 23
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "handler.ScorePoint": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "factors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "score": {
+                    "type": "number",
+                    "example": 72.5
+                }
+            }
+        },
+        "handler.ScoreResponse": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "since the oldest point of the history",
+                    "type": "number",
+                    "example": 1.7
+                },
+                "factors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.HygieneFactor"
+                    }
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ScorePoint"
+                    }
+                },
+                "score": {
+                    "type": "number",
+                    "example": 74.2
+                }
+            }
+        },
+        "handler.SessionDTO": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "member": {
+                    "$ref": "#/definitions/handler.MemberDTO"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SimulatedResourceDTO": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "123456789012"
+                },
+                "carbon_footprint_kg": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "cleanup_approved_at": {
+                    "type": "string"
+                },
+                "cleanup_approved_by": {
+                    "type": "string"
+                },
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ConditionResult"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "iac_managed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "iac_tool": {
+                    "type": "string",
+                    "example": "terraform"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 45.5
+                },
+                "name": {
+                    "type": "string",
+                    "example": "my-instance"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "owner_source": {
+                    "type": "string",
+                    "enum": [
+                        "mapping",
+                        "tag",
+                        "account",
+                        "default"
+                    ],
+                    "example": "tag"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "quarantine_until": {
+                    "type": "string"
+                },
+                "quarantined_at": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "us-east-1"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "i-1234567890abcdef0"
+                },
+                "snoozed_until": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "unused",
+                        "deleted",
+                        "removed",
+                        "excluded"
+                    ],
+                    "example": "unused"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ec2_instance"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SlackInstallationDTO": {
+            "type": "object",
+            "properties": {
+                "channel_id": {
+                    "type": "string",
+                    "example": "C0123ABCD"
+                },
+                "channel_name": {
+                    "type": "string",
+                    "example": "#cloud-costs"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "installed_by": {
+                    "type": "string",
+                    "example": "U0123ABCD"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "team_id": {
+                    "type": "string",
+                    "example": "T0123ABCD"
+                },
+                "team_name": {
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SummaryStats": {
+            "type": "object",
+            "properties": {
+                "potential_carbon_savings_kg": {
+                    "type": "number",
+                    "example": 180.25
+                },
+                "potential_monthly_savings": {
+                    "type": "number",
+                    "example": 2500
+                },
+                "total_carbon_kg": {
+                    "type": "number",
+                    "example": 1200.5
+                },
+                "total_monthly_cost": {
+                    "type": "number",
+                    "example": 15000
+                },
+                "total_resources": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "unused_resources": {
+                    "type": "integer",
+                    "example": 75
+                }
+            }
+        },
+        "handler.TagAllocation": {
+            "type": "object",
+            "properties": {
+                "allocated_percent": {
+                    "type": "number",
+                    "example": 86.4
+                },
+                "tag_key": {
+                    "type": "string",
+                    "example": "team"
+                },
+                "total_monthly_cost": {
+                    "type": "number",
+                    "example": 17140
+                },
+                "total_waste": {
+                    "type": "number",
+                    "example": 2310
+                },
+                "unallocated": {
+                    "description": "resources without the tag",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.AllocationValue"
+                        }
+                    ]
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.AllocationValue"
+                    }
+                }
+            }
+        },
+        "handler.TagDistributionResponse": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "example": "env"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.TagValueStats"
+                    }
+                }
+            }
+        },
+        "handler.TagValueStats": {
+            "type": "object",
+            "properties": {
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 4200
+                },
+                "potential_savings": {
+                    "type": "number",
+                    "example": 310.5
+                },
+                "resource_count": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "untagged": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "unused_count": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "value": {
+                    "type": "string",
+                    "example": "production"
+                }
+            }
+        },
+        "handler.TaskFailureDTO": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "failed to create scanner: invalid credentials"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "last_failed_at": {
+                    "type": "string"
+                },
+                "max_retry": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "payload": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "queue": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "retrying",
+                        "dead",
+                        "requeued"
+                    ],
+                    "example": "dead"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"
+                },
+                "task_type": {
+                    "type": "string",
+                    "example": "scan:resources"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TickerResponse": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "type": "string"
+                },
+                "carbon_kg_per_hour": {
+                    "type": "number",
+                    "example": 0.25
+                },
+                "carbon_kg_per_second": {
+                    "type": "number",
+                    "example": 0.00007
+                },
+                "cost_per_hour": {
+                    "type": "number",
+                    "example": 3.42
+                },
+                "cost_per_second": {
+                    "type": "number",
+                    "example": 0.00095
+                },
+                "emitted_this_month_kg": {
+                    "type": "number",
+                    "example": 90
+                },
+                "unused_resources": {
+                    "type": "integer",
+                    "example": 75
+                },
+                "wasted_this_month": {
+                    "type": "number",
+                    "example": 1231.2
+                }
+            }
+        },
+        "handler.TicketDTO": {
+            "type": "object",
+            "properties": {
+                "closed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "OPS-123"
+                },
+                "resource_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                },
+                "resource_status": {
+                    "type": "string",
+                    "example": "unused"
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "closed"
+                    ],
+                    "example": "open"
+                },
+                "system": {
+                    "type": "string",
+                    "enum": [
+                        "jira",
+                        "servicenow"
+                    ],
+                    "example": "jira"
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://acme.atlassian.net/browse/OPS-123"
+                }
+            }
+        },
+        "handler.TicketIntegrationDTO": {
+            "type": "object",
+            "properties": {
+                "api_token_set": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "assignment_group": {
+                    "type": "string",
+                    "example": "Cloud Operations"
+                },
+                "base_url": {
+                    "type": "string",
+                    "example": "https://acme.atlassian.net"
+                },
+                "done_transition": {
+                    "type": "string",
+                    "example": "Done"
+                },
+                "issue_type": {
+                    "type": "string",
+                    "example": "Task"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "project": {
+                    "type": "string",
+                    "example": "OPS"
+                },
+                "system": {
+                    "type": "string",
+                    "enum": [
+                        "jira",
+                        "servicenow"
+                    ],
+                    "example": "jira"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "cloudsweep@acme.com"
+                }
+            }
+        },
+        "handler.TopOffendersResponse": {
+            "type": "object",
+            "properties": {
+                "group_by": {
+                    "type": "string",
+                    "example": "team"
+                },
+                "offenders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.WasteOffender"
+                    }
+                },
+                "tag_key": {
+                    "type": "string",
+                    "example": "team"
+                },
+                "total_monthly_waste": {
+                    "type": "number",
+                    "example": 3590
+                }
+            }
+        },
+        "handler.TypeSavings": {
+            "type": "object",
+            "properties": {
+                "monthly_cost": {
+                    "type": "number",
+                    "example": 800
+                },
+                "resource_type": {
+                    "type": "string",
+                    "example": "ec2_instance"
+                },
+                "unused_count": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "handler.UpdateCustomResourceTypeRequest": {
+            "type": "object",
+            "required": [
+                "cloud_type"
+            ],
+            "properties": {
+                "cloud_type": {
+                    "description": "CloudType is the type name of the provider, e.g. the CloudFormation\ntype listed with Cloud Control for AWS",
+                    "type": "string",
+                    "example": "AWS::DynamoDB::Table"
+                },
+                "detection": {
+                    "$ref": "#/definitions/handler.MetricDetectionDTO"
+                },
+                "monthly_cost": {
+                    "description": "MonthlyCost is the estimated cost of a resource in USD, used for the\nsavings of unused ones",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 25
+                }
+            }
+        },
+        "handler.UpdateIntegrationRequest": {
+            "type": "object",
+            "required": [
+                "settings"
+            ],
+            "properties": {
+                "settings": {
+                    "description": "Secret settings are kept when left empty on an existing integration",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "management_role_arn": "arn:aws:iam::123456789012:role/CloudSweepOrganizationsRole",
+                        "member_role_name": "CloudSweepAuditRole"
+                    }
+                }
+            }
+        },
+        "handler.UpdateMemberPreferencesRequest": {
+            "type": "object",
+            "required": [
+                "weekly_summary"
+            ],
+            "properties": {
+                "weekly_summary": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "handler.UpdateMemberRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member",
+                        "viewer"
+                    ],
+                    "example": "admin"
+                }
+            }
+        },
+        "handler.UpdateOrganizationRequest": {
+            "type": "object",
+            "required": [
                 "name",
-                "organization_id",
-                "provider"
+                "plan",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Acme"
+                },
+                "plan": {
+                    "type": "string",
+                    "enum": [
+                        "free",
+                        "pro",
+                        "enterprise"
+                    ],
+                    "example": "enterprise"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme"
+                }
+            }
+        },
+        "handler.UpdateOrganizationSettingsRequest": {
+            "type": "object",
+            "required": [
+                "allocation_tag_keys"
             ],
             "properties": {
-                "actions": {
+                "allocation_tag_keys": {
+                    "description": "AllocationTagKeys are the tags costs are allocated by in showback",
                     "type": "array",
-                    "minItems": 1,
+                    "maxItems": 10,
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "notify",
-                        "delete"
+                        "team",
+                        "project"
                     ]
                 },
-                "conditions": {
-                    "type": "object",
-                    "additionalProperties": {}
-                },
-                "description": {
-                    "type": "string",
-                    "example": "Automatically delete EBS volumes unused for 30 days"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "Delete unused EBS volumes"
+                "audit_retention_days": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 365
                 },
-                "organization_id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                "backup_before_delete": {
+                    "description": "BackupBeforeDelete is kept unchanged when omitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.BackupSettingsRequest"
+                        }
+                    ]
                 },
-                "provider": {
+                "default_owner": {
                     "type": "string",
-                    "enum": [
-                        "aws",
-                        "azure",
-                        "gcp"
-                    ],
-                    "example": "aws"
+                    "example": "finops@example.com"
                 },
-                "resource_types": {
+                "default_regions": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "ebs_volume",
-                        "ebs_snapshot"
+                        "eu-west-1",
+                        "eu-central-1"
                     ]
                 },
-                "schedule": {
-                    "type": "string",
-                    "example": "0 0 * * *"
-                }
-            }
-        },
-        "handler.CreateScanRequest": {
-            "type": "object",
-            "required": [
-                "organization_id",
-                "provider",
-                "regions"
-            ],
-            "properties": {
-                "organization_id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                "gitops_repos": {
+                    "description": "GitOpsRepos map state files to the repository where pull requests\nremoving their resources are opened",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handler.GitOpsRepoDTO"
+                    }
                 },
-                "provider": {
-                    "type": "string",
-                    "enum": [
-                        "aws",
-                        "azure",
-                        "gcp"
-                    ],
-                    "example": "aws"
+                "history_retention_days": {
+                    "description": "HistoryRetentionDays shortens the retention of the plan; zero uses it",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 90
                 },
-                "regions": {
+                "notifications": {
+                    "description": "Notifications are kept unchanged when omitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.NotificationSettingsRequest"
+                        }
+                    ]
+                },
+                "owner_aliases": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "owner_notifications": {
+                    "description": "OwnerNotifications are kept unchanged when omitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.OwnerNotificationsRequest"
+                        }
+                    ]
+                },
+                "owner_tag_keys": {
                     "type": "array",
-                    "minItems": 1,
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "us-east-1",
-                        "eu-west-1"
+                        "owner",
+                        "team"
                     ]
                 },
-                "resource_types": {
+                "region_denylist": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "ec2_instance",
-                        "ebs_volume"
+                        "cn-*",
+                        "us-gov-*"
+                    ]
+                },
+                "terraform_states": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tfstate/prod.tfstate"
+                    ]
+                },
+                "weekly_summary": {
+                    "description": "WeeklySummary is kept unchanged when omitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.WeeklySummaryRequest"
+                        }
                     ]
                 }
             }
         },
-        "handler.CreateScanResponse": {
+        "handler.UpdateOwnerRequest": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/handler.ScanDTO"
-                },
-                "message": {
+                "owner": {
+                    "description": "Owner is empty to remove the default owner",
                     "type": "string",
-                    "example": "scan created and queued for processing"
+                    "example": "platform@example.com"
                 }
             }
         },
-        "handler.ErrorResponse": {
+        "handler.UpdatePricingRequest": {
             "type": "object",
+            "required": [
+                "source"
+            ],
             "properties": {
-                "error": {
+                "settings": {
+                    "description": "Settings are the fields of the pricing source. Secret settings are\nkept when left empty and the source is unchanged.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "bucket": "acme-billing",
+                        "report_name": "cloudsweep"
+                    }
+                },
+                "source": {
+                    "description": "Source is \"estimate\" or a pricing source of the provider, see GET\n/providers",
                     "type": "string",
-                    "example": "invalid request"
+                    "example": "cur"
                 }
             }
         },
-        "handler.ExecuteCleanupRequest": {
+        "handler.UpdateRecommendationRequest": {
             "type": "object",
             "required": [
-                "action",
-                "organization_id",
-                "resource_ids"
+                "status"
             ],
             "properties": {
-                "action": {
+                "status": {
                     "type": "string",
                     "enum": [
-                        "delete",
-                        "stop",
-                        "tag",
-                        "notify"
+                        "open",
+                        "dismissed"
                     ],
-                    "example": "delete"
-                },
-                "dry_run": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "organization_id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
-                },
-                "resource_ids": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "550e8400-e29b-41d4-a716-446655440001",
-                        "550e8400-e29b-41d4-a716-446655440002"
-                    ]
+                    "example": "dismissed"
                 }
             }
         },
-        "handler.ExecuteCleanupResponse": {
+        "handler.UpdateResourceViewRequest": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "dry_run": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "message": {
+                "description": {
                     "type": "string",
-                    "example": "cleanup task queued"
+                    "example": "Production EBS volumes left unattached"
                 },
-                "task_id": {
+                "filters": {
+                    "$ref": "#/definitions/handler.ResourceFilter"
+                },
+                "name": {
                     "type": "string",
-                    "example": "task_12345"
+                    "maxLength": 255,
+                    "example": "Unused prod EBS \u003e $50"
                 }
             }
         },
-        "handler.HealthResponse": {
+        "handler.UpdateSSOConnectionRequest": {
             "type": "object",
+            "required": [
+                "client_id",
+                "issuer"
+            ],
             "properties": {
-                "service": {
+                "allowed_domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "acme.com"
+                    ]
+                },
+                "client_id": {
                     "type": "string",
-                    "example": "cloudsweep-api"
+                    "example": "0oa1b2c3d4e5f6g7h8i9"
                 },
-                "status": {
+                "client_secret": {
+                    "description": "ClientSecret is kept when left empty on an existing connection",
                     "type": "string",
-                    "example": "ok"
-                }
-            }
-        },
-        "handler.MessageResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
+                    "example": "s3cr3t"
+                },
+                "default_role": {
                     "type": "string",
-                    "example": "operation successful"
-                }
-            }
-        },
-        "handler.PaginatedResponse": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "limit": {
-                    "type": "integer",
-                    "example": 50
+                    "enum": [
+                        "admin",
+                        "member",
+                        "viewer"
+                    ],
+                    "example": "viewer"
                 },
-                "offset": {
-                    "type": "integer",
-                    "example": 0
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "total": {
-                    "type": "integer",
-                    "example": 100
+                "group_roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "groups_claim": {
+                    "type": "string",
+                    "example": "groups"
+                },
+                "issuer": {
+                    "type": "string",
+                    "example": "https://acme.okta.com"
                 }
             }
         },
-        "handler.PolicyDTO": {
+        "handler.UpdateTicketIntegrationRequest": {
             "type": "object",
+            "required": [
+                "base_url",
+                "system",
+                "username"
+            ],
             "properties": {
-                "actions": {
-                    "type": "array",
-                    "items": {
-                        "type": "string",
-                        "enum": [
-                            "notify",
-                            "tag",
-                            "stop",
-                            "delete"
-                        ]
-                    },
-                    "example": [
-                        "notify",
-                        "delete"
-                    ]
+                "api_token": {
+                    "description": "APIToken is kept when left empty on an existing integration",
+                    "type": "string",
+                    "example": "ATATT3xFfGF0"
                 },
-                "conditions": {
-                    "type": "object",
-                    "additionalProperties": {}
+                "assignment_group": {
+                    "type": "string",
+                    "example": "Cloud Operations"
                 },
-                "created_at": {
-                    "type": "string"
+                "base_url": {
+                    "type": "string",
+                    "example": "https://acme.atlassian.net"
                 },
-                "description": {
+                "done_transition": {
                     "type": "string",
-                    "example": "Automatically delete EBS volumes unused for 30 days"
+                    "example": "Done"
                 },
-                "id": {
+                "issue_type": {
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                    "example": "Task"
                 },
-                "is_enabled": {
-                    "type": "boolean",
-                    "example": true
+                "project": {
+                    "description": "Project is the key of the Jira project issues are opened in",
+                    "type": "string",
+                    "example": "OPS"
                 },
-                "name": {
+                "system": {
                     "type": "string",
-                    "example": "Delete unused EBS volumes"
+                    "enum": [
+                        "jira",
+                        "servicenow"
+                    ],
+                    "example": "jira"
                 },
+                "username": {
+                    "type": "string",
+                    "example": "cloudsweep@acme.com"
+                }
+            }
+        },
+        "handler.UsageDTO": {
+            "type": "object",
+            "properties": {
                 "organization_id": {
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
-                "provider": {
+                "plan": {
                     "type": "string",
-                    "enum": [
-                        "aws",
-                        "azure",
-                        "gcp"
-                    ],
-                    "example": "aws"
+                    "example": "free"
                 },
-                "resource_types": {
+                "quotas": {
                     "type": "array",
                     "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "ebs_volume"
-                    ]
-                },
-                "schedule": {
-                    "type": "string",
-                    "example": "0 0 * * *"
+                        "$ref": "#/definitions/handler.QuotaUsageDTO"
+                    }
                 },
-                "updated_at": {
+                "scans_reset_at": {
                     "type": "string"
+                },
+                "upgrade": {
+                    "type": "string",
+                    "example": "pro"
                 }
             }
         },
-        "handler.ProviderCarbon": {
+        "handler.UserDTO": {
             "type": "object",
             "properties": {
-                "carbon_kg": {
-                    "type": "number",
-                    "example": 450.25
+                "email": {
+                    "type": "string",
+                    "example": "jane@acme.com"
                 },
-                "potential_savings_kg": {
-                    "type": "number",
-                    "example": 75.5
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
                 },
-                "provider": {
+                "name": {
                     "type": "string",
-                    "example": "aws"
+                    "example": "Jane Doe"
                 }
             }
         },
-        "handler.ProviderSavings": {
+        "handler.WasteOffender": {
             "type": "object",
             "properties": {
+                "carbon_kg": {
+                    "type": "number",
+                    "example": 35.2
+                },
+                "group": {
+                    "description": "resource ID, tag value, account or region",
+                    "type": "string",
+                    "example": "platform"
+                },
                 "monthly_cost": {
                     "type": "number",
-                    "example": 1500
+                    "example": 840
                 },
-                "potential_savings": {
+                "resource": {
+                    "description": "group_by=resource",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.ResourceDTO"
+                        }
+                    ]
+                },
+                "share_percent": {
+                    "description": "Share is the percentage of the unused cost of the scope",
                     "type": "number",
-                    "example": 250
+                    "example": 23.4
                 },
-                "provider": {
-                    "type": "string",
-                    "example": "aws"
+                "untagged": {
+                    "type": "boolean",
+                    "example": false
                 },
                 "unused_count": {
                     "type": "integer",
-                    "example": 25
+                    "example": 12
                 }
             }
         },
-        "handler.ReadyResponse": {
+        "handler.WeeklySummaryDTO": {
             "type": "object",
             "properties": {
-                "checks": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "status": {
+                "hour": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "timezone": {
                     "type": "string",
-                    "example": "ready"
-                }
-            }
-        },
-        "handler.RegionCarbon": {
-            "type": "object",
-            "properties": {
-                "carbon_kg": {
-                    "type": "number",
-                    "example": 250
+                    "example": "Europe/Paris"
                 },
-                "region": {
+                "weekday": {
                     "type": "string",
-                    "example": "us-east-1"
+                    "enum": [
+                        "monday",
+                        "tuesday",
+                        "wednesday",
+                        "thursday",
+                        "friday",
+                        "saturday",
+                        "sunday"
+                    ],
+                    "example": "monday"
                 }
             }
         },
-        "handler.ResourceDTO": {
+        "handler.WeeklySummaryRequest": {
             "type": "object",
             "properties": {
-                "carbon_footprint_kg": {
-                    "type": "number",
-                    "example": 12.5
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
-                },
-                "last_seen_at": {
-                    "type": "string"
-                },
-                "monthly_cost": {
-                    "type": "number",
-                    "example": 45.5
+                "disabled": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "name": {
-                    "type": "string",
-                    "example": "my-instance"
+                "hour": {
+                    "type": "integer",
+                    "maximum": 23,
+                    "minimum": 0,
+                    "example": 8
                 },
-                "organization_id": {
+                "timezone": {
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                    "example": "Europe/Paris"
                 },
-                "provider": {
+                "weekday": {
                     "type": "string",
                     "enum": [
-                        "aws",
-                        "azure",
-                        "gcp"
+                        "monday",
+                        "tuesday",
+                        "wednesday",
+                        "thursday",
+                        "friday",
+                        "saturday",
+                        "sunday"
                     ],
-                    "example": "aws"
-                },
-                "region": {
-                    "type": "string",
-                    "example": "us-east-1"
-                },
-                "resource_id": {
+                    "example": "monday"
+                }
+            }
+        },
+        "provider.CredentialField": {
+            "type": "object",
+            "properties": {
+                "description": {
                     "type": "string",
-                    "example": "i-1234567890abcdef0"
+                    "example": "Secret of the access key"
                 },
-                "status": {
+                "name": {
                     "type": "string",
-                    "enum": [
-                        "active",
-                        "unused",
-                        "deleted",
-                        "excluded"
-                    ],
-                    "example": "unused"
-                },
-                "tags": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                    "example": "secret_access_key"
                 },
-                "type": {
-                    "type": "string",
-                    "example": "ec2_instance"
+                "required": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "updated_at": {
-                    "type": "string"
+                "secret": {
+                    "description": "Secret fields are never returned once saved",
+                    "type": "boolean",
+                    "example": true
                 }
             }
         },
-        "handler.SavingsResponse": {
+        "provider.PricingSource": {
             "type": "object",
             "properties": {
-                "by_provider": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handler.ProviderSavings"
-                    }
+                "display_name": {
+                    "type": "string",
+                    "example": "AWS Cost Explorer"
                 },
-                "by_resource_type": {
+                "name": {
+                    "type": "string",
+                    "example": "cost_explorer"
+                },
+                "settings": {
+                    "description": "Settings are the fields configured per account, validated like\ncredentials",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/handler.TypeSavings"
+                        "$ref": "#/definitions/provider.CredentialField"
                     }
                 }
             }
         },
-        "handler.ScanDTO": {
+        "service.ConditionResult": {
             "type": "object",
             "properties": {
-                "carbon_savings_kg": {
-                    "type": "number",
-                    "example": 45.5
-                },
-                "completed_at": {
-                    "type": "string"
-                },
-                "created_at": {
+                "condition": {
                     "type": "string"
                 },
-                "error_message": {
+                "detail": {
                     "type": "string"
                 },
-                "estimated_savings": {
-                    "type": "number",
-                    "example": 1250
-                },
-                "id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
-                },
-                "organization_id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440001"
-                },
-                "provider": {
-                    "type": "string",
-                    "enum": [
-                        "aws",
-                        "azure",
-                        "gcp"
-                    ],
-                    "example": "aws"
+                "matched": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.ForecastHorizon": {
+            "type": "object",
+            "properties": {
+                "months": {
+                    "type": "integer"
                 },
-                "regions": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "us-east-1",
-                        "eu-west-1"
-                    ]
+                "savings_with_policies": {
+                    "description": "Savings against the baseline spend",
+                    "type": "number"
                 },
-                "resource_types": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "ec2_instance",
-                        "ebs_volume"
-                    ]
+                "savings_with_recommendations": {
+                    "type": "number"
                 },
-                "resources_found": {
-                    "type": "integer",
-                    "example": 150
+                "spend": {
+                    "$ref": "#/definitions/service.Scenarios"
                 },
-                "started_at": {
+                "waste": {
+                    "$ref": "#/definitions/service.Scenarios"
+                }
+            }
+        },
+        "service.ForecastMonth": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "description": "2006-01",
                     "type": "string"
                 },
-                "status": {
-                    "type": "string",
-                    "enum": [
-                        "pending",
-                        "running",
-                        "completed",
-                        "failed",
-                        "cancelled"
-                    ],
-                    "example": "completed"
-                },
-                "unused_found": {
-                    "type": "integer",
-                    "example": 23
+                "spend": {
+                    "$ref": "#/definitions/service.Scenarios"
                 },
-                "updated_at": {
-                    "type": "string"
+                "waste": {
+                    "$ref": "#/definitions/service.Scenarios"
                 }
             }
         },
-        "handler.SummaryStats": {
+        "service.HygieneFactor": {
             "type": "object",
             "properties": {
-                "potential_carbon_savings_kg": {
-                    "type": "number",
-                    "example": 180.25
+                "detail": {
+                    "type": "string"
                 },
-                "potential_monthly_savings": {
-                    "type": "number",
-                    "example": 2500
+                "name": {
+                    "type": "string"
                 },
-                "total_carbon_kg": {
-                    "type": "number",
-                    "example": 1200.5
+                "score": {
+                    "type": "number"
                 },
-                "total_monthly_cost": {
-                    "type": "number",
-                    "example": 15000
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.Scenarios": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "type": "number"
                 },
-                "total_resources": {
-                    "type": "integer",
-                    "example": 500
+                "with_policies": {
+                    "type": "number"
                 },
-                "unused_resources": {
-                    "type": "integer",
-                    "example": 75
+                "with_recommendations": {
+                    "type": "number"
                 }
             }
         },
-        "handler.TypeSavings": {
+        "service.ScheduledSaving": {
             "type": "object",
             "properties": {
-                "monthly_cost": {
-                    "type": "number",
-                    "example": 800
+                "monthly_savings": {
+                    "type": "number"
                 },
-                "resource_type": {
-                    "type": "string",
-                    "example": "ec2_instance"
+                "next_run": {
+                    "type": "string"
                 },
-                "unused_count": {
-                    "type": "integer",
-                    "example": 10
+                "policy": {
+                    "type": "string"
+                },
+                "policy_id": {
+                    "type": "string"
+                },
+                "resources": {
+                    "type": "integer"
+                },
+                "waste_savings": {
+                    "description": "WasteSavings is the part of the savings on resources flagged unused",
+                    "type": "number"
                 }
             }
         }
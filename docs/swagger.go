@@ -23,6 +23,9 @@
 //	@tag.name					Health
 //	@tag.description			Health check endpoints
 //
+//	@tag.name					Organizations
+//	@tag.description			Organization settings
+//
 //	@tag.name					Resources
 //	@tag.description			Cloud resources management
 //
@@ -37,4 +40,22 @@
 //
 //	@tag.name					Dashboard
 //	@tag.description			Dashboard and analytics
+//
+//	@tag.name					Analytics
+//	@tag.description			Ad-hoc resource analytics
+//
+//	@tag.name					Digest
+//	@tag.description			Owner digest email actions
+//
+//	@tag.name					Tasks
+//	@tag.description			Background task monitoring
+//
+//	@tag.name					Exports
+//	@tag.description			Asynchronous data exports
+//
+//	@tag.name					Queue
+//	@tag.description			Task queue monitoring
+//
+//	@tag.name					Audit
+//	@tag.description			Provider call audit log
 package docs
@@ -0,0 +1,18 @@
+package docs
+
+import "github.com/swaggo/swag"
+
+// SwaggerInfoV2 is the Swagger document of /api/v2. Both API versions are
+// documented from the same handler annotations: the document differs by its
+// base path.
+var SwaggerInfoV2 = func() *swag.Spec {
+	spec := *SwaggerInfo
+	spec.Version = "2.0"
+	spec.BasePath = "/api/v2"
+	spec.InfoInstanceName = "v2"
+	return &spec
+}()
+
+func init() {
+	swag.Register(SwaggerInfoV2.InstanceName(), SwaggerInfoV2)
+}
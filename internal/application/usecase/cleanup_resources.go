@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
 	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
@@ -13,20 +15,42 @@ import (
 // CleanupResourcesUseCase handles resource cleanup operations
 type CleanupResourcesUseCase struct {
 	resourceRepo   repository.ResourceRepository
+	events         repository.ResourceEventRepository
 	policyRepo     repository.PolicyRepository
 	cleanerFactory service.ResourceCleanerFactory
+	iacChanges     service.IaCChangeProposer
+	readOnly       service.ReadOnlyGuard
+	tickets        service.TicketTracker
+	owners         service.OwnerNotifier
 }
 
-// NewCleanupResourcesUseCase creates a new CleanupResourcesUseCase
+// NewCleanupResourcesUseCase creates a new CleanupResourcesUseCase.
+// iacChanges may be nil, in which case resources managed by
+// infrastructure-as-code are never deleted, readOnly may be nil when there
+// is no read-only mode, and tickets may be nil when no tracker is
+// configured, failing the ticket action. owners notifies the owners of the
+// resources of the notify action; nil fails it. The attempts of cleanups
+// other than dry runs are recorded in the timelines of their resources by
+// events, which may be nil.
 func NewCleanupResourcesUseCase(
 	resourceRepo repository.ResourceRepository,
+	events repository.ResourceEventRepository,
 	policyRepo repository.PolicyRepository,
 	cleanerFactory service.ResourceCleanerFactory,
+	iacChanges service.IaCChangeProposer,
+	readOnly service.ReadOnlyGuard,
+	tickets service.TicketTracker,
+	owners service.OwnerNotifier,
 ) *CleanupResourcesUseCase {
 	return &CleanupResourcesUseCase{
 		resourceRepo:   resourceRepo,
+		events:         events,
 		policyRepo:     policyRepo,
 		cleanerFactory: cleanerFactory,
+		iacChanges:     iacChanges,
+		readOnly:       readOnly,
+		tickets:        tickets,
+		owners:         owners,
 	}
 }
 
@@ -37,6 +61,23 @@ type CleanupResourcesInput struct {
 	Action         entity.PolicyAction
 	Credentials    []byte
 	DryRun         bool
+	// QuarantineWindow is how long quarantined resources are kept before
+	// deletion, service.DefaultQuarantineWindow when zero
+	QuarantineWindow time.Duration
+	// TaskID is the cleanup task, recorded on the pull requests it opens
+	// and on the attempts, which the rollbacks of its job find by it
+	TaskID string
+	// DeniedRegions are the regions the organization denylisted, where
+	// resources are never cleaned up
+	DeniedRegions []string
+	// Actor requested the cleanup, e.g. a user ID, and is recorded in the
+	// timelines of the resources; entity.ResourceEventActorCleanup when
+	// empty
+	Actor string
+	// Backup snapshots volumes, disks and databases before deleting them,
+	// keeping the snapshots for BackupRetention
+	Backup          bool
+	BackupRetention time.Duration
 }
 
 // CleanupResourcesOutput represents output from cleaning up resources
@@ -46,10 +87,27 @@ type CleanupResourcesOutput struct {
 	TotalCarbonSaved float64
 	SuccessCount     int
 	FailureCount     int
+	// ProposedCount is the number of resources left to a pull request
+	// removing them from their infrastructure-as-code configuration
+	ProposedCount int
+	// SkippedCount is the number of resources left untouched because they
+	// were already deleted, excluded or quarantined
+	SkippedCount int
+	// TotalBackupCost is the monthly cost of the backups taken before
+	// deleting resources, already deducted from TotalCostSaved
+	TotalBackupCost float64
 }
 
-// Execute executes the cleanup resources use case
+// Execute executes the cleanup resources use case. Cleanups changing
+// resources, other than dry runs, notifications and tickets, fail with a
+// *service.ReadOnlyError in read-only mode.
 func (uc *CleanupResourcesUseCase) Execute(ctx context.Context, input CleanupResourcesInput) (*CleanupResourcesOutput, error) {
+	if !input.DryRun && input.Action != entity.PolicyActionNotify && input.Action != entity.PolicyActionTicket {
+		if err := checkWritable(ctx, uc.readOnly, input.OrganizationID); err != nil {
+			return nil, err
+		}
+	}
+
 	output := &CleanupResourcesOutput{
 		Results: make([]*service.CleanupResult, 0, len(input.ResourceIDs)),
 	}
@@ -80,6 +138,12 @@ func (uc *CleanupResourcesUseCase) Execute(ctx context.Context, input CleanupRes
 		resourcesByProvider[r.Provider] = append(resourcesByProvider[r.Provider], r)
 	}
 
+	// Resources of the notify action, whose owners are notified at once
+	var notify []*entity.Resource
+	// Previous status of the resources whose status changed, recorded with
+	// the attempts
+	changed := make(map[uuid.UUID]entity.ResourceStatus)
+
 	// Process each provider
 	for provider, providerResources := range resourcesByProvider {
 		cleaner, err := uc.cleanerFactory.Create(provider, input.Credentials)
@@ -97,30 +161,115 @@ func (uc *CleanupResourcesUseCase) Execute(ctx context.Context, input CleanupRes
 
 		// Process each resource
 		for _, resource := range providerResources {
-			if input.DryRun {
+			preflight := service.RunPreflight(ctx, cleaner, resource, input.Action, service.PreflightOptions{
+				Now:           time.Now(),
+				DeniedRegions: input.DeniedRegions,
+				IaCChanges:    uc.iacChanges != nil,
+			})
+			if preflight.Verdict == service.PreflightSkip {
 				output.Results = append(output.Results, &service.CleanupResult{
+					ResourceID:   resource.ID.String(),
+					Success:      false,
+					Action:       input.Action,
+					ErrorMessage: "skipped: " + preflight.Findings[0].Message,
+				})
+				output.SkippedCount++
+				continue
+			}
+			// Never act on a resource in a denylisted region, without the
+			// permissions the action needs, nor delete one something still
+			// depends on: routes, images in use, attachments or DNS records
+			var blockers []string
+			for _, f := range preflight.Findings {
+				if f.Blocking && f.Check != service.PreflightCheckIaC {
+					blockers = append(blockers, f.Message)
+				}
+			}
+			if len(blockers) > 0 {
+				output.Results = append(output.Results, &service.CleanupResult{
+					ResourceID:   resource.ID.String(),
+					Success:      false,
+					Action:       input.Action,
+					ErrorMessage: "preflight failed: " + strings.Join(blockers, "; "),
+				})
+				output.FailureCount++
+				continue
+			}
+			// Never delete a resource its infrastructure-as-code tool would
+			// recreate: it is removed from its configuration instead, when
+			// pull requests can be opened
+			if input.Action == entity.PolicyActionDelete || input.Action == entity.PolicyActionQuarantine || input.Action == entity.PolicyActionRelease {
+				if err := service.CheckIaCSafeDelete(resource); err != nil {
+					if uc.iacChanges != nil {
+						err = uc.proposeRemoval(ctx, resource, input, output)
+					}
+					if err != nil {
+						output.Results = append(output.Results, &service.CleanupResult{
+							ResourceID:   resource.ID.String(),
+							Success:      false,
+							Action:       input.Action,
+							ErrorMessage: fmt.Sprintf("unsafe to delete: %v", err),
+						})
+						output.FailureCount++
+					}
+					continue
+				}
+			}
+
+			if input.DryRun {
+				result := &service.CleanupResult{
 					ResourceID:  resource.ID.String(),
 					Success:     true,
 					Action:      input.Action,
 					CostSaved:   resource.MonthlyCost,
 					CarbonSaved: resource.CarbonFootprint,
-				})
-				output.TotalCostSaved += resource.MonthlyCost
-				output.TotalCarbonSaved += resource.CarbonFootprint
+				}
+				if backsUp(input, resource) {
+					result.BackupCost = service.BackupCost(resource)
+					result.CostSaved -= result.BackupCost
+				}
+				output.Results = append(output.Results, result)
+				output.TotalCostSaved += result.CostSaved
+				output.TotalCarbonSaved += result.CarbonSaved
+				output.TotalBackupCost += result.BackupCost
 				output.SuccessCount++
 				continue
 			}
 
+			if input.Action == entity.PolicyActionNotify {
+				notify = append(notify, resource)
+				continue
+			}
+
 			var result *service.CleanupResult
 			switch input.Action {
 			case entity.PolicyActionDelete:
-				result, err = cleaner.Delete(ctx, resource)
+				if backsUp(input, resource) {
+					result, err = service.BackupThenDelete(ctx, cleaner, resource, service.BackupOptions{
+						TaskID:    input.TaskID,
+						Retention: input.BackupRetention,
+					})
+				} else if resource.Type.IsManagedDatabase() {
+					result, err = service.DeleteDatabase(ctx, cleaner, resource)
+				} else if resource.Type.IsSnapshot() {
+					result, err = service.DeleteSnapshot(ctx, cleaner, resource)
+				} else if resource.Type.IsObjectStorage() {
+					result, err = service.DeleteBucket(ctx, cleaner, resource)
+				} else {
+					result, err = cleaner.Delete(ctx, resource)
+				}
+			case entity.PolicyActionRelease:
+				result, err = service.ReleaseIP(ctx, cleaner, resource)
 			case entity.PolicyActionStop:
 				result, err = cleaner.Stop(ctx, resource)
+			case entity.PolicyActionQuarantine:
+				result, err = service.QuarantineResource(ctx, cleaner, resource, time.Now().Add(quarantineWindow(input)))
 			case entity.PolicyActionTag:
 				result, err = cleaner.Tag(ctx, resource, map[string]string{
-					"cloudsweep:marked-for-deletion": "true",
+					service.MarkedForDeletionTagKey: "true",
 				})
+			case entity.PolicyActionTicket:
+				result, err = uc.openTicket(ctx, resource, input)
 			default:
 				result = &service.CleanupResult{
 					ResourceID:   resource.ID.String(),
@@ -139,18 +288,205 @@ func (uc *CleanupResourcesUseCase) Execute(ctx context.Context, input CleanupRes
 
 			output.Results = append(output.Results, result)
 			if result.Success {
+				// Record how to undo what was done, for rollbacks
+				if result.Recovery == nil {
+					result.Recovery = service.RecoveryFor(resource, input.Action)
+				}
 				output.TotalCostSaved += result.CostSaved
 				output.TotalCarbonSaved += result.CarbonSaved
+				output.TotalBackupCost += result.BackupCost
 				output.SuccessCount++
 
-				// Update resource status
-				resource.MarkAsDeleted()
+				// Update resource status; a ticket leaves the resource
+				// to whoever handles it
+				if input.Action == entity.PolicyActionTicket {
+					continue
+				}
+				from := resource.Status
+				if input.Action == entity.PolicyActionQuarantine {
+					resource.Quarantine(quarantineWindow(input))
+				} else {
+					resource.MarkAsDeleted()
+				}
 				uc.resourceRepo.Update(ctx, resource)
+				if resource.Status != from {
+					changed[resource.ID] = from
+				}
 			} else {
 				output.FailureCount++
 			}
 		}
 	}
 
+	if len(notify) > 0 {
+		uc.notifyOwners(ctx, notify, input, output)
+	}
+
+	if !input.DryRun {
+		uc.recordAttempts(ctx, resources, input, output, changed)
+	}
+
 	return output, nil
 }
+
+// recordAttempts records the results of a cleanup, skipped resources
+// included, and the changes of status it made in the timelines of the
+// resources. The cleanup is done whether they are recorded or not: like the
+// status of the resources, a failure is not reported, so that the cleanup
+// is not retried.
+func (uc *CleanupResourcesUseCase) recordAttempts(ctx context.Context, resources []*entity.Resource, input CleanupResourcesInput, output *CleanupResourcesOutput, changed map[uuid.UUID]entity.ResourceStatus) {
+	if uc.events == nil {
+		return
+	}
+	// Cleaners may name resources by their ID in the cloud
+	byID := make(map[string]*entity.Resource, 2*len(resources))
+	for _, r := range resources {
+		byID[r.ID.String()] = r
+		byID[r.ResourceID] = r
+	}
+
+	actor := cleanupActor(input)
+	var events []*entity.ResourceEvent
+	for _, result := range output.Results {
+		r, ok := byID[result.ResourceID]
+		if !ok {
+			continue
+		}
+		data := map[string]any{
+			"action":  string(input.Action),
+			"success": result.Success,
+		}
+		if result.ErrorMessage != "" {
+			data["error"] = result.ErrorMessage
+		}
+		if result.BackupID != "" {
+			data["backup_id"] = result.BackupID
+			data["backup_cost"] = result.BackupCost
+		}
+		if result.Recovery != nil {
+			data["recovery"] = result.Recovery
+		}
+		if result.ChangeURL != "" {
+			data["change_url"] = result.ChangeURL
+		}
+		if result.TicketURL != "" {
+			data["ticket_url"] = result.TicketURL
+		}
+		if result.Owner != "" {
+			data["owner"] = result.Owner
+		}
+		attempt := entity.NewResourceEvent(r.OrganizationID, r.ID, entity.ResourceEventCleanupAttempted, actor, data)
+		attempt.TaskID = input.TaskID
+		events = append(events, attempt)
+		if from, ok := changed[r.ID]; ok {
+			events = append(events, entity.NewStatusChangedEvent(r, from, actor))
+		}
+	}
+	uc.events.Record(ctx, events...)
+}
+
+// backsUp returns true when a resource is backed up before being deleted
+func backsUp(input CleanupResourcesInput, resource *entity.Resource) bool {
+	return input.Backup && input.Action == entity.PolicyActionDelete && service.NeedsBackup(resource.Type)
+}
+
+// cleanupActor returns who a cleanup is recorded as run by
+func cleanupActor(input CleanupResourcesInput) string {
+	if input.Actor != "" {
+		return input.Actor
+	}
+	return entity.ResourceEventActorCleanup
+}
+
+// proposeRemoval opens a pull request removing an infrastructure-as-code
+// managed resource from its configuration. The resource is left as is: the
+// tool deletes it once the pull request is merged and applied.
+func (uc *CleanupResourcesUseCase) proposeRemoval(ctx context.Context, resource *entity.Resource, input CleanupResourcesInput, output *CleanupResourcesOutput) error {
+	result := &service.CleanupResult{
+		ResourceID: resource.ID.String(),
+		Success:    true,
+		Action:     input.Action,
+	}
+	if !input.DryRun {
+		change, err := uc.iacChanges.ProposeRemoval(ctx, resource, input.TaskID)
+		if err != nil {
+			return fmt.Errorf("failed to open pull request: %w", err)
+		}
+		result.ChangeURL = change.URL
+	}
+	output.Results = append(output.Results, result)
+	output.ProposedCount++
+	return nil
+}
+
+// openTicket opens a ticket for the resource in the tracker of its
+// organization. The resource is left as is: the ticket is closed once it is
+// remediated.
+func (uc *CleanupResourcesUseCase) openTicket(ctx context.Context, resource *entity.Resource, input CleanupResourcesInput) (*service.CleanupResult, error) {
+	if uc.tickets == nil {
+		return nil, fmt.Errorf("no ticket tracker is configured")
+	}
+	ticket, err := uc.tickets.OpenTicket(ctx, resource, input.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ticket: %w", err)
+	}
+	return &service.CleanupResult{
+		ResourceID: resource.ID.String(),
+		Success:    true,
+		Action:     input.Action,
+		TicketURL:  ticket.URL,
+	}, nil
+}
+
+// notifyOwners sends the owners of the resources one message each listing
+// theirs. Resources without an owner, or whose owner could not be
+// notified, fail.
+func (uc *CleanupResourcesUseCase) notifyOwners(ctx context.Context, resources []*entity.Resource, input CleanupResourcesInput, output *CleanupResourcesOutput) {
+	var owners map[uuid.UUID]string
+	err := fmt.Errorf("no owner notifier is configured")
+	if uc.owners != nil {
+		owners, err = uc.owners.NotifyOwners(ctx, input.OrganizationID, resources, input.TaskID)
+		if err != nil {
+			err = fmt.Errorf("failed to notify owners: %w", err)
+		}
+	}
+
+	for _, resource := range resources {
+		result := &service.CleanupResult{
+			ResourceID: resource.ID.String(),
+			Action:     input.Action,
+			Owner:      owners[resource.ID],
+		}
+		switch {
+		case result.Owner != "":
+			result.Success = true
+		case err != nil:
+			result.ErrorMessage = err.Error()
+		default:
+			result.ErrorMessage = "no owner found for the resource"
+		}
+		output.Results = append(output.Results, result)
+		if result.Success {
+			output.SuccessCount++
+		} else {
+			output.FailureCount++
+		}
+	}
+}
+
+// checkWritable returns the error of the read-only mode, if the
+// organization is in it
+func checkWritable(ctx context.Context, guard service.ReadOnlyGuard, orgID uuid.UUID) error {
+	if guard == nil {
+		return nil
+	}
+	return guard.CheckWritable(ctx, orgID)
+}
+
+// quarantineWindow returns the quarantine window of a cleanup
+func quarantineWindow(input CleanupResourcesInput) time.Duration {
+	if input.QuarantineWindow > 0 {
+		return input.QuarantineWindow
+	}
+	return service.DefaultQuarantineWindow
+}
@@ -0,0 +1,815 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// maxCleanupResources caps the number of resources a cleanup selects from a
+// view, and the number reviewed in one session
+const maxCleanupResources = 10000
+
+// CleanupService queues the cleanups requested through the API, previews
+// them, rolls them back and runs the guided cleanup sessions. Its errors
+// carry the code and message to return to clients.
+type CleanupService interface {
+	// Execute queues a cleanup and returns its job
+	Execute(ctx context.Context, input CleanupInput) (*entity.Job, error)
+
+	// Preview runs the checks of a cleanup on its resources and estimates
+	// its savings, without queueing it
+	Preview(ctx context.Context, input CleanupInput) (*CleanupPreview, error)
+
+	// JobItems returns the result of a cleanup job for each of its
+	// resources, in the order they were attempted
+	JobItems(ctx context.Context, jobID uuid.UUID) ([]*CleanupJobItem, error)
+
+	// Rollback queues undoing the action a cleanup job ran on one of its
+	// resources
+	Rollback(ctx context.Context, input RollbackCleanupJobItemInput) error
+
+	// CreateSession starts a cleanup session over the unused resources
+	// matching filters
+	CreateSession(ctx context.Context, input CreateCleanupSessionInput) (*CleanupSessionOutput, error)
+
+	// GetSession retrieves a session with its review progress
+	GetSession(ctx context.Context, id uuid.UUID) (*CleanupSessionOutput, error)
+
+	// ListSessionItems retrieves a page of the resources of a session, and
+	// their number
+	ListSessionItems(ctx context.Context, input ListCleanupSessionItemsInput) (*ListCleanupSessionItemsOutput, error)
+
+	// DecideSession records decisions on resources of an open session
+	DecideSession(ctx context.Context, input DecideCleanupSessionInput) (*CleanupSessionOutput, error)
+
+	// ExecuteSession queues the cleanup of the accepted resources of an
+	// open session and closes it
+	ExecuteSession(ctx context.Context, input ExecuteCleanupSessionInput) (*CleanupSessionOutput, error)
+
+	// CancelSession closes an open session without executing it
+	CancelSession(ctx context.Context, id uuid.UUID) error
+}
+
+// CleanupUseCase implements CleanupService
+type CleanupUseCase struct {
+	resourceRepo repository.ResourceRepository
+	events       repository.ResourceEventRepository
+	jobRepo      repository.JobRepository
+	sessions     repository.CleanupSessionRepository
+	viewRepo     repository.ResourceViewRepository
+	orgRepo      repository.OrganizationRepository
+	uow          repository.UnitOfWork
+	queue        service.CleanupQueue
+	readOnly     service.ReadOnlyGuard
+}
+
+// NewCleanupUseCase creates a new CleanupUseCase. Cleanups changing
+// resources are refused in read-only mode; readOnly may be nil when there
+// is none.
+func NewCleanupUseCase(
+	resourceRepo repository.ResourceRepository,
+	events repository.ResourceEventRepository,
+	jobRepo repository.JobRepository,
+	sessions repository.CleanupSessionRepository,
+	viewRepo repository.ResourceViewRepository,
+	orgRepo repository.OrganizationRepository,
+	uow repository.UnitOfWork,
+	queue service.CleanupQueue,
+	readOnly service.ReadOnlyGuard,
+) *CleanupUseCase {
+	return &CleanupUseCase{
+		resourceRepo: resourceRepo,
+		events:       events,
+		jobRepo:      jobRepo,
+		sessions:     sessions,
+		viewRepo:     viewRepo,
+		orgRepo:      orgRepo,
+		uow:          uow,
+		queue:        queue,
+		readOnly:     readOnly,
+	}
+}
+
+var _ CleanupService = (*CleanupUseCase)(nil)
+
+// CleanupInput represents a cleanup of resources of an organization
+type CleanupInput struct {
+	OrganizationID uuid.UUID
+	// ResourceIDs are the resources to clean up, unless ViewID selects
+	// them
+	ResourceIDs []uuid.UUID
+	ViewID      *uuid.UUID
+	Action      entity.PolicyAction
+	DryRun      bool
+	// Backup is whether deletions back up volumes, disks and databases
+	// first; the backup_before_delete setting of the organization when nil
+	Backup *bool
+}
+
+// CleanupPreview represents the checks and the estimated savings of a
+// cleanup. Preflights are those of Resources, in the same order.
+type CleanupPreview struct {
+	Resources      []*entity.Resource
+	Preflights     []*service.Preflight
+	Verdicts       map[service.PreflightVerdict]int
+	MonthlySavings float64
+	CarbonSavings  float64
+	// Backup is true in backup-then-delete mode, whose snapshots cost
+	// BackupCost per month
+	Backup        bool
+	BackupCost    float64
+	DNSReferences []CleanupDNSReference
+}
+
+// CleanupDNSReference represents the DNS records pointing at a resource of
+// a cleanup, as of its last scan
+type CleanupDNSReference struct {
+	ResourceID uuid.UUID
+	Records    []string
+	// Checked is false when the last scan did not search the DNS zones
+	Checked bool
+	// Blocking is true when the action is refused while the records exist
+	Blocking bool
+}
+
+// CleanupJobItem represents the result of a cleanup job for one of its
+// resources, and its rollback
+type CleanupJobItem struct {
+	ResourceID    uuid.UUID
+	Action        string
+	Success       bool
+	Error         string
+	AttemptedAt   time.Time
+	Recovery      map[string]any
+	RolledBack    bool
+	RollbackAt    *time.Time
+	RollbackError string
+}
+
+// RollbackCleanupJobItemInput represents a request to undo the action a
+// cleanup job ran on a resource
+type RollbackCleanupJobItemInput struct {
+	JobID      uuid.UUID
+	ResourceID uuid.UUID
+	// Actor requested the rollback and is recorded in the timeline of the
+	// resource
+	Actor string
+}
+
+// CreateCleanupSessionInput represents a cleanup session to start
+type CreateCleanupSessionInput struct {
+	OrganizationID uuid.UUID
+	Action         entity.PolicyAction
+	// Filters narrow the resources down by provider, type or region
+	Filters map[string]string
+	// ViewID narrows the resources down to those of a saved view
+	ViewID    *uuid.UUID
+	CreatedBy string
+}
+
+// CleanupSessionOutput represents a session and its review progress
+type CleanupSessionOutput struct {
+	Session *entity.CleanupSession
+	Tallies map[entity.CleanupDecision]entity.CleanupSessionTally
+}
+
+// ListCleanupSessionItemsInput represents a page of the resources of a
+// session, with the given decision unless it is nil
+type ListCleanupSessionItemsInput struct {
+	SessionID uuid.UUID
+	Decision  *entity.CleanupDecision
+	Limit     int
+	Offset    int
+}
+
+// ListCleanupSessionItemsOutput represents a page of the resources of a
+// session
+type ListCleanupSessionItemsOutput struct {
+	Items []*entity.CleanupSessionItem
+	Total int64
+}
+
+// DecideCleanupSessionInput represents review decisions on resources of a
+// session
+type DecideCleanupSessionInput struct {
+	SessionID uuid.UUID
+	Decisions map[entity.CleanupDecision][]uuid.UUID
+	DecidedBy string
+}
+
+// ExecuteCleanupSessionInput represents the execution of a session
+type ExecuteCleanupSessionInput struct {
+	SessionID uuid.UUID
+	DryRun    bool
+	Backup    *bool
+}
+
+// Execute implements CleanupService. Cleanups other than dry runs and
+// notifications are refused while the installation or the organization is
+// in read-only mode.
+func (uc *CleanupUseCase) Execute(ctx context.Context, input CleanupInput) (*entity.Job, error) {
+	ids, err := uc.targets(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	org, err := organization(ctx, uc.orgRepo, input.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never act in regions the organization has denylisted
+	if len(org.Settings.RegionDenylist) > 0 {
+		resources, err := uc.resourceRepo.List(ctx, repository.ResourceFilter{OrganizationID: &org.ID, IDs: ids})
+		if err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resources")
+		}
+		if err := checkRegions(org.Settings, resources); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refuse early what the worker would refuse in read-only mode
+	if !input.DryRun && input.Action != entity.PolicyActionNotify {
+		if err := writable(ctx, uc.readOnly, org.ID); err != nil {
+			return nil, err
+		}
+	}
+	return uc.enqueue(ctx, org, ids, input.Action, input.DryRun, input.Backup)
+}
+
+// Preview implements CleanupService. The savings are those of the
+// resources that will proceed, net of the cost of their backups.
+func (uc *CleanupUseCase) Preview(ctx context.Context, input CleanupInput) (*CleanupPreview, error) {
+	ids, err := uc.targets(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := uc.resourceRepo.List(ctx, repository.ResourceFilter{OrganizationID: &input.OrganizationID, IDs: ids})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resources")
+	}
+	org, err := organization(ctx, uc.orgRepo, input.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make([]string, len(resources))
+	for i, r := range resources {
+		regions[i] = r.Region
+	}
+	opts := service.PreflightOptions{
+		Now:           time.Now(),
+		DeniedRegions: org.Settings.DeniedRegions(regions),
+		IaCChanges:    len(org.Settings.GitOpsRepos) > 0,
+	}
+
+	backup, _ := cleanupBackup(org.Settings, input.Action, input.Backup)
+	preview := &CleanupPreview{
+		Resources:     resources,
+		Preflights:    make([]*service.Preflight, len(resources)),
+		Verdicts:      map[service.PreflightVerdict]int{},
+		Backup:        backup,
+		DNSReferences: dnsReferences(resources, input.Action),
+	}
+	for i, r := range resources {
+		preflight := service.RunPreflight(ctx, nil, r, input.Action, opts)
+		preview.Preflights[i] = preflight
+		preview.Verdicts[preflight.Verdict]++
+		if preflight.Verdict != service.PreflightProceed {
+			continue
+		}
+		preview.MonthlySavings += r.MonthlyCost
+		preview.CarbonSavings += r.CarbonFootprint
+		if backup && service.NeedsBackup(r.Type) {
+			cost := service.BackupCost(r)
+			preview.MonthlySavings -= cost
+			preview.BackupCost += cost
+		}
+	}
+	return preview, nil
+}
+
+// dnsReferences returns the DNS records pointing at the resources of a
+// cleanup removing them, as recorded by their last scan
+func dnsReferences(resources []*entity.Resource, action entity.PolicyAction) []CleanupDNSReference {
+	out := []CleanupDNSReference{}
+	switch action {
+	case entity.PolicyActionDelete, entity.PolicyActionRelease, entity.PolicyActionQuarantine:
+	default:
+		return out
+	}
+	for _, r := range resources {
+		if !service.NeedsDNSCheck(r) {
+			continue
+		}
+		records, checked := service.RecordedDNSReferences(r)
+		if checked && len(records) == 0 {
+			continue
+		}
+		out = append(out, CleanupDNSReference{
+			ResourceID: r.ID,
+			Records:    records,
+			Checked:    checked,
+			Blocking:   len(records) > 0 && action != entity.PolicyActionQuarantine,
+		})
+	}
+	return out
+}
+
+// cleanupBackup returns whether a cleanup backs up volumes, disks and
+// databases before deleting them, as requested or else as the organization
+// set, and how long the backups are kept in days
+func cleanupBackup(settings entity.OrganizationSettings, action entity.PolicyAction, requested *bool) (bool, int) {
+	if action != entity.PolicyActionDelete {
+		return false, 0
+	}
+	backup := settings.BackupBeforeDelete.Enabled
+	if requested != nil {
+		backup = *requested
+	}
+	if !backup {
+		return false, 0
+	}
+	return true, int(settings.BackupBeforeDelete.Retention() / (24 * time.Hour))
+}
+
+// targets returns the resources a cleanup applies to, given by ID or
+// selected by a saved view of the organization
+func (uc *CleanupUseCase) targets(ctx context.Context, input CleanupInput) ([]uuid.UUID, error) {
+	if input.ViewID == nil {
+		return input.ResourceIDs, nil
+	}
+
+	view, err := loadView(ctx, uc.viewRepo, input.OrganizationID, *input.ViewID)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := viewFilter(view)
+	if err != nil {
+		return nil, err
+	}
+	filter.Sort = repository.ResourceSort{Field: repository.ResourceSortCost}
+	filter.Limit = maxCleanupResources + 1
+	resources, err := uc.resourceRepo.List(ctx, filter)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resources")
+	}
+	if len(resources) == 0 {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "no resources match the view")
+	}
+	if len(resources) > maxCleanupResources {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "too many resources match the view, narrow it down")
+	}
+	return resourceIDs(resources), nil
+}
+
+// checkRegions refuses cleanups of resources in regions the organization
+// has denylisted
+func checkRegions(settings entity.OrganizationSettings, resources []*entity.Resource) error {
+	var regions []string
+	for _, r := range resources {
+		if !slices.Contains(regions, r.Region) {
+			regions = append(regions, r.Region)
+		}
+	}
+	if denied := settings.DeniedRegions(regions); len(denied) > 0 {
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "resources are in denylisted regions: "+strings.Join(denied, ", "))
+	}
+	return nil
+}
+
+// enqueue queues the cleanup of resources of an organization, backing them
+// up as requested or as the organization set
+func (uc *CleanupUseCase) enqueue(ctx context.Context, org *entity.Organization, ids []uuid.UUID, action entity.PolicyAction, dryRun bool, requestedBackup *bool) (*entity.Job, error) {
+	backup, backupDays := cleanupBackup(org.Settings, action, requestedBackup)
+	job, err := uc.queue.Enqueue(ctx, org.ID, service.CleanupRequest{
+		ResourceIDs:         ids,
+		Action:              action,
+		DryRun:              dryRun,
+		Backup:              backup,
+		BackupRetentionDays: backupDays,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to enqueue cleanup task")
+	}
+	return job, nil
+}
+
+// JobItems implements CleanupService. Dry runs record no items.
+func (uc *CleanupUseCase) JobItems(ctx context.Context, jobID uuid.UUID) ([]*CleanupJobItem, error) {
+	job, err := uc.cleanupJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	items := []*CleanupJobItem{}
+	if job.TaskID == "" {
+		return items, nil
+	}
+
+	events, err := uc.events.List(ctx, repository.ResourceEventFilter{
+		TaskID: job.TaskID,
+		Types:  []entity.ResourceEventType{entity.ResourceEventCleanupAttempted, entity.ResourceEventCleanupRolledBack},
+	})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch cleanup job items")
+	}
+
+	// Attempts come first; the rollbacks of a resource update its item
+	index := make(map[uuid.UUID]int)
+	for k := len(events) - 1; k >= 0; k-- {
+		e := events[k]
+		i, seen := index[e.ResourceID]
+		if e.Type == entity.ResourceEventCleanupAttempted {
+			item := &CleanupJobItem{ResourceID: e.ResourceID, AttemptedAt: e.OccurredAt}
+			item.Action, _ = e.Data["action"].(string)
+			item.Success, _ = e.Data["success"].(bool)
+			item.Error, _ = e.Data["error"].(string)
+			item.Recovery, _ = e.Data["recovery"].(map[string]any)
+			if seen {
+				items[i] = item
+			} else {
+				index[e.ResourceID] = len(items)
+				items = append(items, item)
+			}
+			continue
+		}
+		if !seen {
+			continue
+		}
+		at := e.OccurredAt
+		items[i].RollbackAt = &at
+		items[i].RolledBack, _ = e.Data["success"].(bool)
+		items[i].RollbackError, _ = e.Data["error"].(string)
+	}
+	return items, nil
+}
+
+// Rollback implements CleanupService. Actions that cannot be undone
+// automatically are refused with their instructions, and so are actions
+// that failed, were already rolled back or whose undo window has ended.
+// Rollbacks are refused in read-only mode.
+func (uc *CleanupUseCase) Rollback(ctx context.Context, input RollbackCleanupJobItemInput) error {
+	job, err := uc.cleanupJob(ctx, input.JobID)
+	if err != nil {
+		return err
+	}
+	if err := writable(ctx, uc.readOnly, job.OrganizationID); err != nil {
+		return err
+	}
+	notCleanedUp := apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "resource was not cleaned up by the job")
+	if job.TaskID == "" {
+		return notCleanedUp
+	}
+
+	events, err := uc.events.List(ctx, repository.ResourceEventFilter{
+		ResourceID: input.ResourceID,
+		TaskID:     job.TaskID,
+		Types:      []entity.ResourceEventType{entity.ResourceEventCleanupAttempted, entity.ResourceEventCleanupRolledBack},
+	})
+	if err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch cleanup job item")
+	}
+	i := slices.IndexFunc(events, func(e *entity.ResourceEvent) bool { return e.Type == entity.ResourceEventCleanupAttempted })
+	if i < 0 {
+		return notCleanedUp
+	}
+	attempt := events[i]
+	if success, _ := attempt.Data["success"].(bool); !success {
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "the cleanup of the resource did not succeed, there is nothing to undo")
+	}
+	recovery, ok := attemptRecovery(attempt)
+	if !ok {
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "the action of the cleanup changed nothing to undo")
+	}
+	if !recovery.Automated {
+		manual := &service.ManualRecoveryError{Instructions: recovery.Instructions}
+		return apperrors.NewWithCode(manual, apperrors.CodeConflict, manual.Error())
+	}
+	if recovery.UndoUntil != nil && time.Now().After(*recovery.UndoUntil) {
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "the action can no longer be undone since "+recovery.UndoUntil.UTC().Format(time.RFC3339))
+	}
+	for _, e := range events {
+		if success, _ := e.Data["success"].(bool); success && e.Type == entity.ResourceEventCleanupRolledBack {
+			return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "the action was already rolled back")
+		}
+	}
+
+	if err := uc.queue.EnqueueRollback(ctx, job.OrganizationID, input.ResourceID, job.TaskID, recovery, input.Actor); err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to enqueue rollback task")
+	}
+	return nil
+}
+
+// cleanupJob retrieves a job, which must be a cleanup
+func (uc *CleanupUseCase) cleanupJob(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "job not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch job")
+	}
+	if job.Type != entity.JobTypeCleanup {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "job is not a cleanup")
+	}
+	return job, nil
+}
+
+// attemptRecovery returns the recovery recorded with a cleanup attempt
+func attemptRecovery(attempt *entity.ResourceEvent) (*service.Recovery, bool) {
+	raw, ok := attempt.Data["recovery"]
+	if !ok {
+		return nil, false
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false
+	}
+	var recovery service.Recovery
+	if err := json.Unmarshal(b, &recovery); err != nil || recovery.Kind == "" {
+		return nil, false
+	}
+	return &recovery, true
+}
+
+// CreateSession implements CleanupService. The unused resources are
+// snapshotted in order of monthly cost so pages stay stable while they are
+// reviewed.
+func (uc *CleanupUseCase) CreateSession(ctx context.Context, input CreateCleanupSessionInput) (*CleanupSessionOutput, error) {
+	org, err := organization(ctx, uc.orgRepo, input.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The filters of the session narrow those of the view down; filters
+	// keeping other values than the view leave no resource
+	filter := repository.ResourceFilter{OrganizationID: &org.ID}
+	filters := make(map[string]string, len(input.Filters)+1)
+	var view *entity.ResourceView
+	if input.ViewID != nil {
+		if view, err = loadView(ctx, uc.viewRepo, org.ID, *input.ViewID); err != nil {
+			return nil, err
+		}
+		if filter, err = viewFilter(view); err != nil {
+			return nil, err
+		}
+		filters["view_id"] = view.ID.String()
+	}
+	matching := narrow(&filter.Status, entity.ResourceStatusUnused)
+	for k, v := range input.Filters {
+		switch k {
+		case "provider":
+			matching = narrow(&filter.Provider, entity.CloudProvider(v)) && matching
+		case "type":
+			matching = narrow(&filter.Type, entity.ResourceType(v)) && matching
+		case "region":
+			matching = narrow(&filter.Region, v) && matching
+		default:
+			return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "unknown filter: "+k)
+		}
+		filters[k] = v
+	}
+
+	var resources []*entity.Resource
+	if matching {
+		filter.Sort = repository.ResourceSort{Field: repository.ResourceSortCost}
+		filter.Limit = maxCleanupResources + 1
+		if resources, err = uc.resourceRepo.List(ctx, filter); err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resources")
+		}
+	}
+	if len(resources) == 0 {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "no unused resources match the filters")
+	}
+	if len(resources) > maxCleanupResources {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "too many resources match the filters, narrow them down")
+	}
+
+	session := &entity.CleanupSession{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Action:         input.Action,
+		Filters:        filters,
+		Status:         entity.CleanupSessionStatusOpen,
+		CreatedBy:      input.CreatedBy,
+	}
+	if err := uc.sessions.Create(ctx, session, resourceIDs(resources)); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to create cleanup session")
+	}
+	return uc.sessionOutput(ctx, session)
+}
+
+// narrow sets a filter field to v. It returns false when the field keeps
+// another value already, leaving no resource to match.
+func narrow[T comparable](field **T, v T) bool {
+	if *field != nil && **field != v {
+		return false
+	}
+	*field = &v
+	return true
+}
+
+// GetSession implements CleanupService
+func (uc *CleanupUseCase) GetSession(ctx context.Context, id uuid.UUID) (*CleanupSessionOutput, error) {
+	session, err := uc.session(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.sessionOutput(ctx, session)
+}
+
+// ListSessionItems implements CleanupService
+func (uc *CleanupUseCase) ListSessionItems(ctx context.Context, input ListCleanupSessionItemsInput) (*ListCleanupSessionItemsOutput, error) {
+	session, err := uc.session(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.CleanupSessionItemFilter{
+		SessionID: session.ID,
+		Decision:  input.Decision,
+		Limit:     input.Limit,
+		Offset:    input.Offset,
+	}
+	total, err := uc.sessions.CountItems(ctx, filter)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch session resources")
+	}
+	items, err := uc.sessions.ListItems(ctx, filter)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch session resources")
+	}
+	return &ListCleanupSessionItemsOutput{Items: items, Total: total}, nil
+}
+
+// DecideSession implements CleanupService. Decisions can be changed until
+// the session is executed.
+func (uc *CleanupUseCase) DecideSession(ctx context.Context, input DecideCleanupSessionInput) (*CleanupSessionOutput, error) {
+	if _, err := uc.session(ctx, input.SessionID); err != nil {
+		return nil, err
+	}
+
+	var session *entity.CleanupSession
+	now := time.Now()
+	err := uc.uow.Do(ctx, func(ctx context.Context) error {
+		// Lock the session so decisions cannot land after its execution
+		var err error
+		if session, err = uc.sessions.Lock(ctx, input.SessionID); err != nil {
+			return err
+		}
+		if session.Status != entity.CleanupSessionStatusOpen {
+			return sessionClosed(session)
+		}
+
+		for decision, ids := range input.Decisions {
+			decidedBy, decidedAt := input.DecidedBy, &now
+			if decision == entity.CleanupDecisionPending {
+				decidedBy, decidedAt = "", nil
+			}
+			if err := uc.sessions.Decide(ctx, session.ID, ids, decision, decidedBy, decidedAt); err != nil {
+				return err
+			}
+		}
+		session.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		var appErr *apperrors.AppError
+		if apperrors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to record decisions")
+	}
+	return uc.sessionOutput(ctx, session)
+}
+
+// ExecuteSession implements CleanupService. Deletions back up volumes,
+// disks and databases first when asked, or by default when the
+// organization enabled backup_before_delete. Sessions are not executed,
+// except as dry runs, in read-only mode.
+func (uc *CleanupUseCase) ExecuteSession(ctx context.Context, input ExecuteCleanupSessionInput) (*CleanupSessionOutput, error) {
+	session, err := uc.session(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != entity.CleanupSessionStatusOpen {
+		return nil, sessionClosed(session)
+	}
+
+	accepted := entity.CleanupDecisionAccepted
+	items, err := uc.sessions.ListItems(ctx, repository.CleanupSessionItemFilter{SessionID: session.ID, Decision: &accepted})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch accepted resources")
+	}
+	if len(items) == 0 {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "no resource accepted in this session")
+	}
+	ids := make([]uuid.UUID, len(items))
+	resources := make([]*entity.Resource, len(items))
+	for i, item := range items {
+		ids[i], resources[i] = item.ResourceID, item.Resource
+	}
+
+	org, err := organization(ctx, uc.orgRepo, session.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	// Never act in regions the organization has denylisted
+	if err := checkRegions(org.Settings, resources); err != nil {
+		return nil, err
+	}
+	if !input.DryRun && session.Action != entity.PolicyActionNotify {
+		if err := writable(ctx, uc.readOnly, org.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Claim the session first so concurrent executions queue a single task
+	now := time.Now()
+	session.Status, session.DryRun, session.ExecutedAt = entity.CleanupSessionStatusExecuted, input.DryRun, &now
+	claimed, err := uc.sessions.UpdateStatus(ctx, session, entity.CleanupSessionStatusOpen)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to execute cleanup session")
+	}
+	if !claimed {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "cleanup session is no longer open")
+	}
+
+	job, err := uc.enqueue(ctx, org, ids, session.Action, input.DryRun, input.Backup)
+	if err != nil {
+		session.Status, session.ExecutedAt = entity.CleanupSessionStatusOpen, nil
+		if _, reopenErr := uc.sessions.UpdateStatus(ctx, session, entity.CleanupSessionStatusExecuted); reopenErr != nil {
+			log.Printf("Failed to reopen cleanup session %s: %v", session.ID, reopenErr)
+		}
+		return nil, err
+	}
+	session.TaskID = job.TaskID
+	if _, err := uc.sessions.UpdateStatus(ctx, session, entity.CleanupSessionStatusExecuted); err != nil {
+		log.Printf("Failed to record the task of cleanup session %s: %v", session.ID, err)
+	}
+	return uc.sessionOutput(ctx, session)
+}
+
+// CancelSession implements CleanupService
+func (uc *CleanupUseCase) CancelSession(ctx context.Context, id uuid.UUID) error {
+	session, err := uc.session(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	cancelled := *session
+	cancelled.Status = entity.CleanupSessionStatusCancelled
+	ok, err := uc.sessions.UpdateStatus(ctx, &cancelled, entity.CleanupSessionStatusOpen)
+	if err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to cancel cleanup session")
+	}
+	if !ok {
+		return sessionClosed(session)
+	}
+	return nil
+}
+
+// session retrieves a cleanup session
+func (uc *CleanupUseCase) session(ctx context.Context, id uuid.UUID) (*entity.CleanupSession, error) {
+	session, err := uc.sessions.GetByID(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "cleanup session not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch cleanup session")
+	}
+	return session, nil
+}
+
+// sessionOutput totals the resources of a session by decision
+func (uc *CleanupUseCase) sessionOutput(ctx context.Context, session *entity.CleanupSession) (*CleanupSessionOutput, error) {
+	tallies, err := uc.sessions.Tally(ctx, session.ID)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to summarize cleanup session")
+	}
+	return &CleanupSessionOutput{Session: session, Tallies: tallies}, nil
+}
+
+// sessionClosed is the error of changes to a session that is not open
+func sessionClosed(session *entity.CleanupSession) error {
+	return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "cleanup session is "+string(session.Status))
+}
+
+// resourceIDs returns the IDs of resources
+func resourceIDs(resources []*entity.Resource) []uuid.UUID {
+	ids := make([]uuid.UUID, len(resources))
+	for i, r := range resources {
+		ids[i] = r.ID
+	}
+	return ids
+}
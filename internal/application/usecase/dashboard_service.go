@@ -0,0 +1,550 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// savingPolicyActions are the policy actions removing the cost of the
+// resources they run on. Off-hours schedules only save part of it and are
+// left out of forecasts.
+var savingPolicyActions = []entity.PolicyAction{
+	entity.PolicyActionStop,
+	entity.PolicyActionDelete,
+	entity.PolicyActionQuarantine,
+	entity.PolicyActionRelease,
+}
+
+// DashboardService computes the dashboard of organizations. Its errors
+// carry the code and message to return to clients.
+type DashboardService interface {
+	// Summary totals the resources of a scope
+	Summary(ctx context.Context, scope repository.DashboardScope) (*repository.InventoryTotals, error)
+
+	// AccountSummaries totals the resources of cloud accounts at once.
+	// Accounts without resources have zero totals.
+	AccountSummaries(ctx context.Context, accounts []repository.AccountKey) (map[repository.AccountKey]repository.InventoryTotals, error)
+
+	// Savings breaks the waste of a scope down by provider and resource
+	// type
+	Savings(ctx context.Context, scope repository.DashboardScope) (*DashboardSavings, error)
+
+	// Carbon breaks the carbon footprint of the unused resources of a
+	// scope down by provider and region
+	Carbon(ctx context.Context, scope repository.DashboardScope) (*DashboardCarbon, error)
+
+	// TopOffenders ranks the unused resources of a scope, or their groups,
+	// by monthly cost
+	TopOffenders(ctx context.Context, input TopOffendersInput) (*TopOffendersOutput, error)
+
+	// Allocation returns the showback report of an organization for a
+	// month, computing it when the current month was not refreshed yet
+	Allocation(ctx context.Context, input AllocationInput) (*AllocationOutput, error)
+
+	// Score returns the current hygiene score of an organization with its
+	// recent history
+	Score(ctx context.Context, orgID uuid.UUID, days int) (*ScoreOutput, error)
+
+	// Ticker returns the rate at which the unused resources of a scope
+	// waste money and emit CO2e
+	Ticker(ctx context.Context, scope repository.DashboardScope) (*TickerOutput, error)
+
+	// Forecast projects the spend and waste of an organization
+	Forecast(ctx context.Context, orgID uuid.UUID) (*ForecastOutput, error)
+}
+
+// DashboardUseCase implements DashboardService
+type DashboardUseCase struct {
+	dashboard       repository.DashboardRepository
+	resourceRepo    repository.ResourceRepository
+	policyRepo      repository.PolicyRepository
+	viewRepo        repository.ResourceViewRepository
+	recommendations repository.RecommendationRepository
+	orgRepo         repository.OrganizationRepository
+	scores          service.HygieneScorer
+	allocations     service.CostAllocator
+}
+
+// NewDashboardUseCase creates a new DashboardUseCase
+func NewDashboardUseCase(
+	dashboard repository.DashboardRepository,
+	resourceRepo repository.ResourceRepository,
+	policyRepo repository.PolicyRepository,
+	viewRepo repository.ResourceViewRepository,
+	recommendations repository.RecommendationRepository,
+	orgRepo repository.OrganizationRepository,
+	scores service.HygieneScorer,
+	allocations service.CostAllocator,
+) *DashboardUseCase {
+	return &DashboardUseCase{
+		dashboard:       dashboard,
+		resourceRepo:    resourceRepo,
+		policyRepo:      policyRepo,
+		viewRepo:        viewRepo,
+		recommendations: recommendations,
+		orgRepo:         orgRepo,
+		scores:          scores,
+		allocations:     allocations,
+	}
+}
+
+var _ DashboardService = (*DashboardUseCase)(nil)
+
+// DashboardSavings represents the waste of a scope by provider, and by
+// resource type for the 10 most wasteful types
+type DashboardSavings struct {
+	ByProvider []repository.WasteTotal
+	ByType     []repository.WasteTotal
+}
+
+// DashboardCarbon represents the carbon footprint of the unused resources
+// of a scope by provider, and by region for the 10 most emitting regions
+type DashboardCarbon struct {
+	ByProvider []repository.WasteTotal
+	ByRegion   []repository.WasteTotal
+}
+
+// TopOffendersInput represents a ranking of waste offenders
+type TopOffendersInput struct {
+	Scope repository.DashboardScope
+	// GroupBy ranks groups of resources, or single resources when empty
+	GroupBy repository.WasteGroupBy
+	TagKey  string // with repository.WasteByTag
+	Limit   int
+}
+
+// TopOffendersOutput represents the biggest sources of waste of a scope
+type TopOffendersOutput struct {
+	TotalWaste float64
+	Offenders  []WasteOffender
+}
+
+// WasteOffender is an unused resource, or a group of them, with its share
+// of the unused cost of the scope
+type WasteOffender struct {
+	repository.WasteTotal
+	// Share is a percentage, rounded to a tenth
+	Share float64
+	// Resource is set when ranking single resources
+	Resource *entity.Resource
+}
+
+// AllocationInput represents a request for the showback report of a month
+type AllocationInput struct {
+	OrganizationID uuid.UUID
+	// Month is any time in the month, the current month when zero
+	Month time.Time
+	// TagKey keeps only this cost-allocation tag when set
+	TagKey string
+}
+
+// AllocationOutput represents the costs allocated by tag during a month
+type AllocationOutput struct {
+	Month time.Time
+	// ComputedAt is when the report was last refreshed, nil without rows
+	ComputedAt  *time.Time
+	Allocations []TagAllocation
+}
+
+// TagAllocation represents the costs allocated by one tag key
+type TagAllocation struct {
+	TagKey           string
+	TotalCost        float64
+	TotalWaste       float64
+	AllocatedPercent float64
+	Values           []AllocationShare
+	// Unallocated holds the resources without the tag
+	Unallocated AllocationShare
+}
+
+// AllocationShare represents the resources sharing a value of a
+// cost-allocation tag, with their share of the total cost of the tag
+type AllocationShare struct {
+	entity.CostAllocation
+	Share float64
+}
+
+// ScoreOutput represents the hygiene score of an organization
+type ScoreOutput struct {
+	service.HygieneScore
+	// Change is the change since the oldest point of the history, nil
+	// without history
+	Change  *float64
+	History []service.HygieneRecord
+}
+
+// TickerOutput represents the waste burn rate of a scope. Monthly figures
+// are spread over the same 730 hour month used to normalize provider
+// prices.
+type TickerOutput struct {
+	UnusedResources int64
+	CostPerHour     float64
+	CarbonPerHour   float64
+	// WastedThisMonth and EmittedThisMonth are the amounts since the start
+	// of the month at the current rate
+	WastedThisMonth  float64
+	EmittedThisMonth float64
+	AsOf             time.Time
+}
+
+// ForecastOutput represents the projected spend and waste of an
+// organization
+type ForecastOutput struct {
+	service.Forecast
+	MonthlyCost  float64
+	MonthlyWaste float64
+	// ScheduledPolicies are the scheduled policies with savings, soonest
+	// first
+	ScheduledPolicies     []service.ScheduledSaving
+	Recommendations       int
+	RecommendationSavings float64
+	AsOf                  time.Time
+}
+
+// Summary implements DashboardService
+func (uc *DashboardUseCase) Summary(ctx context.Context, scope repository.DashboardScope) (*repository.InventoryTotals, error) {
+	totals, err := uc.dashboard.Totals(ctx, scope)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute summary")
+	}
+	return &totals, nil
+}
+
+// AccountSummaries implements DashboardService
+func (uc *DashboardUseCase) AccountSummaries(ctx context.Context, accounts []repository.AccountKey) (map[repository.AccountKey]repository.InventoryTotals, error) {
+	totals, err := uc.dashboard.TotalsByAccount(ctx, accounts)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute account summaries")
+	}
+	return totals, nil
+}
+
+// Savings implements DashboardService
+func (uc *DashboardUseCase) Savings(ctx context.Context, scope repository.DashboardScope) (*DashboardSavings, error) {
+	var savings DashboardSavings
+	var err error
+	if savings.ByProvider, err = uc.dashboard.Waste(ctx, scope, repository.WasteGrouping{By: repository.WasteByProvider}); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute savings")
+	}
+	if savings.ByType, err = uc.dashboard.Waste(ctx, scope, repository.WasteGrouping{By: repository.WasteByType, Limit: 10}); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute savings")
+	}
+	return &savings, nil
+}
+
+// Carbon implements DashboardService
+func (uc *DashboardUseCase) Carbon(ctx context.Context, scope repository.DashboardScope) (*DashboardCarbon, error) {
+	var carbon DashboardCarbon
+	var err error
+	if carbon.ByProvider, err = uc.dashboard.Waste(ctx, scope, repository.WasteGrouping{By: repository.WasteByProvider, ByCarbon: true}); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute carbon footprint")
+	}
+	if carbon.ByRegion, err = uc.dashboard.Waste(ctx, scope, repository.WasteGrouping{By: repository.WasteByRegion, ByCarbon: true, Limit: 10}); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute carbon footprint")
+	}
+	return &carbon, nil
+}
+
+// TopOffenders implements DashboardService
+func (uc *DashboardUseCase) TopOffenders(ctx context.Context, input TopOffendersInput) (*TopOffendersOutput, error) {
+	totals, err := uc.dashboard.Totals(ctx, input.Scope)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to rank waste offenders")
+	}
+
+	out := &TopOffendersOutput{TotalWaste: totals.WasteCost, Offenders: []WasteOffender{}}
+	if input.GroupBy == "" {
+		resources, err := uc.dashboard.Unused(ctx, input.Scope, input.Limit)
+		if err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to rank waste offenders")
+		}
+		for _, r := range resources {
+			out.Offenders = append(out.Offenders, WasteOffender{
+				WasteTotal: repository.WasteTotal{
+					Group:           r.ID.String(),
+					Count:           1,
+					MonthlyCost:     r.MonthlyCost,
+					CarbonFootprint: r.CarbonFootprint,
+				},
+				Resource: r,
+			})
+		}
+	} else {
+		groups, err := uc.dashboard.Waste(ctx, input.Scope, repository.WasteGrouping{By: input.GroupBy, TagKey: input.TagKey, Limit: input.Limit})
+		if err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to rank waste offenders")
+		}
+		for _, g := range groups {
+			out.Offenders = append(out.Offenders, WasteOffender{WasteTotal: g})
+		}
+	}
+
+	if out.TotalWaste > 0 {
+		for i := range out.Offenders {
+			out.Offenders[i].Share = percent(out.Offenders[i].MonthlyCost, out.TotalWaste)
+		}
+	}
+	return out, nil
+}
+
+// Allocation implements DashboardService
+func (uc *DashboardUseCase) Allocation(ctx context.Context, input AllocationInput) (*AllocationOutput, error) {
+	org, err := organization(ctx, uc.orgRepo, input.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(org.Settings.AllocationTagKeys) == 0 {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput,
+			"no cost allocation tags, set allocation_tag_keys in the organization settings")
+	}
+
+	now := time.Now()
+	month := input.Month
+	if month.IsZero() {
+		month = now
+	}
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	current := month.Year() == now.UTC().Year() && month.Month() == now.UTC().Month()
+
+	rows, err := uc.allocations.Month(ctx, org.ID, month)
+	if err == nil && len(rows) == 0 && current {
+		// Not refreshed yet this month
+		if err = uc.allocations.Refresh(ctx, org, now); err == nil {
+			rows, err = uc.allocations.Month(ctx, org.ID, month)
+		}
+	}
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch cost allocation")
+	}
+
+	out := &AllocationOutput{Month: month, Allocations: []TagAllocation{}}
+	byKey := map[string]*TagAllocation{}
+	for _, row := range rows {
+		if input.TagKey != "" && row.TagKey != input.TagKey {
+			continue
+		}
+		if out.ComputedAt == nil || row.ComputedAt.After(*out.ComputedAt) {
+			computed := row.ComputedAt
+			out.ComputedAt = &computed
+		}
+		alloc, ok := byKey[row.TagKey]
+		if !ok {
+			out.Allocations = append(out.Allocations, TagAllocation{TagKey: row.TagKey, Values: []AllocationShare{}})
+			alloc = &out.Allocations[len(out.Allocations)-1]
+			byKey[row.TagKey] = alloc
+		}
+		alloc.TotalCost += row.MonthlyCost
+		alloc.TotalWaste += row.Waste
+		if row.TagValue == "" {
+			alloc.Unallocated = AllocationShare{CostAllocation: row}
+		} else {
+			alloc.Values = append(alloc.Values, AllocationShare{CostAllocation: row})
+		}
+	}
+	for i := range out.Allocations {
+		alloc := &out.Allocations[i]
+		if alloc.TotalCost == 0 {
+			continue
+		}
+		alloc.AllocatedPercent = percent(alloc.TotalCost-alloc.Unallocated.MonthlyCost, alloc.TotalCost)
+		alloc.Unallocated.Share = percent(alloc.Unallocated.MonthlyCost, alloc.TotalCost)
+		for j := range alloc.Values {
+			alloc.Values[j].Share = percent(alloc.Values[j].MonthlyCost, alloc.TotalCost)
+		}
+	}
+	return out, nil
+}
+
+// Score implements DashboardService
+func (uc *DashboardUseCase) Score(ctx context.Context, orgID uuid.UUID, days int) (*ScoreOutput, error) {
+	org, err := organization(ctx, uc.orgRepo, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	score, err := uc.scores.Compute(ctx, org, now)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute hygiene score")
+	}
+	history, err := uc.scores.History(ctx, orgID, now.AddDate(0, 0, -days))
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch hygiene score history")
+	}
+
+	out := &ScoreOutput{HygieneScore: score, History: history}
+	if len(history) > 0 {
+		change := score.Score - history[0].Score
+		out.Change = &change
+	}
+	return out, nil
+}
+
+// Ticker implements DashboardService
+func (uc *DashboardUseCase) Ticker(ctx context.Context, scope repository.DashboardScope) (*TickerOutput, error) {
+	totals, err := uc.dashboard.Totals(ctx, scope)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to compute waste rate")
+	}
+
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	elapsed := now.Sub(monthStart).Hours()
+
+	out := &TickerOutput{
+		UnusedResources: totals.Unused,
+		CostPerHour:     totals.WasteCost / entity.HoursPerMonth,
+		CarbonPerHour:   totals.WasteCarbon / entity.HoursPerMonth,
+		AsOf:            now,
+	}
+	out.WastedThisMonth = out.CostPerHour * elapsed
+	out.EmittedThisMonth = out.CarbonPerHour * elapsed
+	return out, nil
+}
+
+// Forecast implements DashboardService. It projects from the cost
+// snapshots recorded with the hygiene scores, the scheduled policies and
+// the open recommendations.
+func (uc *DashboardUseCase) Forecast(ctx context.Context, orgID uuid.UUID) (*ForecastOutput, error) {
+	now := time.Now()
+	totals, err := uc.dashboard.Totals(ctx, repository.DashboardScope{OrganizationID: orgID})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to sum costs")
+	}
+	out := &ForecastOutput{MonthlyCost: totals.MonthlyCost, MonthlyWaste: totals.WasteCost, AsOf: now.UTC()}
+
+	// Scores recorded before the costs were kept have none
+	history, err := uc.scores.History(ctx, orgID, now.Add(-service.ForecastTrendWindow))
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch cost snapshots")
+	}
+	in := service.ForecastInputs{Now: now, TotalCost: out.MonthlyCost, WasteCost: out.MonthlyWaste}
+	for _, p := range history {
+		if p.TotalCost > 0 || p.WasteCost > 0 {
+			in.History = append(in.History, service.CostPoint{Day: p.Day, TotalCost: p.TotalCost, WasteCost: p.WasteCost})
+		}
+	}
+
+	covered := make(map[uuid.UUID]bool)
+	if in.Scheduled, err = uc.scheduledSavings(ctx, orgID, now, covered); err != nil {
+		return nil, err
+	}
+
+	open := entity.RecommendationStatusOpen
+	recommendations, err := uc.recommendations.List(ctx, repository.RecommendationFilter{OrganizationID: &orgID, Status: &open})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch recommendations")
+	}
+	for _, r := range recommendations {
+		out.Recommendations++
+		if r.ResourceID != nil && covered[*r.ResourceID] {
+			continue
+		}
+		in.RecommendationSavings += r.MonthlySavings
+		if r.Resource != nil && r.Resource.Status == entity.ResourceStatusUnused {
+			in.RecommendationWaste += r.MonthlySavings
+		}
+	}
+
+	out.Forecast = service.ForecastSpend(in)
+	out.ScheduledPolicies = in.Scheduled
+	out.RecommendationSavings = in.RecommendationSavings
+	return out, nil
+}
+
+// scheduledSavings returns the savings of the enabled scheduled policies of
+// an organization on the resources they match now, soonest run first. Each
+// resource counts once, for the first policy matching it, and is added to
+// covered. Policies whose schedule, conditions or view are invalid are
+// skipped.
+func (uc *DashboardUseCase) scheduledSavings(ctx context.Context, orgID uuid.UUID, now time.Time, covered map[uuid.UUID]bool) ([]service.ScheduledSaving, error) {
+	enabled := true
+	policies, err := uc.policyRepo.List(ctx, repository.PolicyFilter{OrganizationID: &orgID, IsEnabled: &enabled})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch policies")
+	}
+
+	type scheduled struct {
+		policy *entity.Policy
+		next   time.Time
+	}
+	var runs []scheduled
+	for _, p := range policies {
+		if p.Schedule == "" || !slices.ContainsFunc(p.Actions, func(a entity.PolicyAction) bool { return slices.Contains(savingPolicyActions, a) }) {
+			continue
+		}
+		schedule, err := cron.ParseStandard(p.Schedule)
+		if err != nil {
+			log.Printf("Forecast: skipping policy %s with invalid schedule %q", p.ID, p.Schedule)
+			continue
+		}
+		if next := schedule.Next(now.UTC()); !next.IsZero() {
+			runs = append(runs, scheduled{policy: p, next: next})
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].next.Before(runs[j].next) })
+
+	savings := []service.ScheduledSaving{}
+	for _, run := range runs {
+		policy := run.policy
+		evaluator, err := service.NewPolicyEvaluator(policy)
+		if err != nil {
+			log.Printf("Forecast: skipping policy %s: %v", policy.ID, err)
+			continue
+		}
+
+		// The resources of the policy are found as when simulating it
+		var filter repository.ResourceFilter
+		if policy.ViewID != nil {
+			view, err := uc.viewRepo.GetByID(ctx, *policy.ViewID)
+			if err != nil {
+				return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resource view of policy "+policy.ID.String())
+			}
+			if filter, err = viewFilter(view); err != nil {
+				log.Printf("Forecast: skipping policy %s with invalid resource view: %v", policy.ID, err)
+				continue
+			}
+		}
+		filter.OrganizationID = &policy.OrganizationID
+		filter.Provider = &policy.Provider
+		filter.Types = policy.ResourceTypes
+		filter.Tracked = true
+		resources, err := uc.resourceRepo.List(ctx, filter)
+		if err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resources of policy "+policy.ID.String())
+		}
+
+		saving := service.ScheduledSaving{PolicyID: policy.ID.String(), Policy: policy.Name, NextRun: run.next}
+		for _, r := range resources {
+			if covered[r.ID] || !evaluator.Evaluate(r, now).Matched {
+				continue
+			}
+			covered[r.ID] = true
+			saving.Resources++
+			saving.MonthlySavings += r.MonthlyCost
+			if r.Status == entity.ResourceStatusUnused {
+				saving.WasteSavings += r.MonthlyCost
+			}
+		}
+		if saving.Resources > 0 {
+			savings = append(savings, saving)
+		}
+	}
+	return savings, nil
+}
+
+// percent returns part as a percentage of total, rounded to a tenth
+func percent(part, total float64) float64 {
+	return math.Round(part/total*1000) / 10
+}
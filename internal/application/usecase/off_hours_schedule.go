@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/google/uuid"
+)
+
+// OffHoursScheduleUseCase stops the resources of a "schedule" policy when
+// its stop window opens and starts them again when its start window opens
+type OffHoursScheduleUseCase struct {
+	resourceRepo   repository.ResourceRepository
+	policyRepo     repository.PolicyRepository
+	cleanerFactory service.ResourceCleanerFactory
+	readOnly       service.ReadOnlyGuard
+}
+
+// NewOffHoursScheduleUseCase creates a new OffHoursScheduleUseCase.
+// readOnly may be nil when there is no read-only mode.
+func NewOffHoursScheduleUseCase(
+	resourceRepo repository.ResourceRepository,
+	policyRepo repository.PolicyRepository,
+	cleanerFactory service.ResourceCleanerFactory,
+	readOnly service.ReadOnlyGuard,
+) *OffHoursScheduleUseCase {
+	return &OffHoursScheduleUseCase{
+		resourceRepo:   resourceRepo,
+		policyRepo:     policyRepo,
+		cleanerFactory: cleanerFactory,
+		readOnly:       readOnly,
+	}
+}
+
+// OffHoursScheduleInput represents input for running a schedule policy
+type OffHoursScheduleInput struct {
+	OrganizationID uuid.UUID
+	PolicyID       uuid.UUID
+	// AccountID restricts the run to the resources of a cloud account, the
+	// one Credentials are for; every account of the policy when empty
+	AccountID   string
+	Credentials []byte
+}
+
+// OffHoursScheduleOutput represents output from running a schedule policy
+type OffHoursScheduleOutput struct {
+	Results      []*service.CleanupResult
+	SuccessCount int
+	FailureCount int
+	SkippedCount int
+}
+
+// Stop stops the running resources the policy matches and marks them so
+// the start window only restarts those
+func (uc *OffHoursScheduleUseCase) Stop(ctx context.Context, input OffHoursScheduleInput) (*OffHoursScheduleOutput, error) {
+	policy, resources, cleaner, err := uc.load(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator, err := service.NewPolicyEvaluator(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &OffHoursScheduleOutput{}
+	now := time.Now()
+	for _, resource := range resources {
+		if !resource.Status.IsTracked() || resource.Status == entity.ResourceStatusExcluded ||
+			service.IsStopped(resource) || service.StoppedOffHoursBy(resource) != "" ||
+			!evaluator.Evaluate(resource, now).Matched {
+			output.SkippedCount++
+			continue
+		}
+
+		result, err := cleaner.Stop(ctx, resource)
+		if err != nil {
+			result = &service.CleanupResult{
+				ResourceID:   resource.ID.String(),
+				Success:      false,
+				ErrorMessage: err.Error(),
+			}
+		}
+		result.Action = entity.PolicyActionSchedule
+		output.Results = append(output.Results, result)
+		if !result.Success {
+			output.FailureCount++
+			continue
+		}
+		output.SuccessCount++
+
+		service.MarkStoppedOffHours(resource, policy.ID.String(), now)
+		if err := uc.resourceRepo.Update(ctx, resource); err != nil {
+			return output, fmt.Errorf("failed to mark resource %s stopped: %w", resource.ID, err)
+		}
+	}
+
+	return output, nil
+}
+
+// Start starts the resources the policy stopped, skipping those that are
+// no longer safe to restart
+func (uc *OffHoursScheduleUseCase) Start(ctx context.Context, input OffHoursScheduleInput) (*OffHoursScheduleOutput, error) {
+	policy, resources, cleaner, err := uc.load(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &OffHoursScheduleOutput{}
+	for _, resource := range resources {
+		if service.StoppedOffHoursBy(resource) != policy.ID.String() {
+			continue
+		}
+		if err := service.CheckSafeRestart(resource, policy.ID.String()); err != nil {
+			output.Results = append(output.Results, &service.CleanupResult{
+				ResourceID:   resource.ID.String(),
+				Success:      false,
+				Action:       entity.PolicyActionSchedule,
+				ErrorMessage: fmt.Sprintf("unsafe to restart: %v", err),
+			})
+			output.SkippedCount++
+			continue
+		}
+
+		result, err := service.StartResource(ctx, cleaner, resource)
+		if err != nil {
+			result = &service.CleanupResult{
+				ResourceID:   resource.ID.String(),
+				Success:      false,
+				ErrorMessage: err.Error(),
+			}
+		}
+		result.Action = entity.PolicyActionSchedule
+		output.Results = append(output.Results, result)
+		if !result.Success {
+			output.FailureCount++
+			continue
+		}
+		output.SuccessCount++
+
+		delete(resource.Metadata, service.OffHoursMetadataKey)
+		if err := uc.resourceRepo.Update(ctx, resource); err != nil {
+			return output, fmt.Errorf("failed to clear stop marker of resource %s: %w", resource.ID, err)
+		}
+	}
+
+	return output, nil
+}
+
+// load returns the schedule policy, the resources in its scope and a
+// cleaner for its provider. Nothing is stopped nor started in read-only mode.
+func (uc *OffHoursScheduleUseCase) load(ctx context.Context, input OffHoursScheduleInput) (*entity.Policy, []*entity.Resource, service.ResourceCleaner, error) {
+	if err := checkWritable(ctx, uc.readOnly, input.OrganizationID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	policy, err := uc.policyRepo.GetByID(ctx, input.PolicyID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+	if policy.OrganizationID != input.OrganizationID {
+		return nil, nil, nil, fmt.Errorf("policy %s does not belong to organization %s", policy.ID, input.OrganizationID)
+	}
+	if !policy.IsEnabled || !policy.HasAction(entity.PolicyActionSchedule) {
+		return nil, nil, nil, fmt.Errorf("policy %s is not an enabled schedule policy", policy.ID)
+	}
+
+	resources, err := uc.resourceRepo.ListByScope(ctx, policy.OrganizationID, policy.Provider, policy.Conditions.Regions, policy.ResourceTypes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	if input.AccountID != "" {
+		resources = slices.DeleteFunc(resources, func(r *entity.Resource) bool {
+			return r.AccountID != input.AccountID
+		})
+	}
+
+	cleaner, err := uc.cleanerFactory.Create(policy.Provider, input.Credentials)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create cleaner: %w", err)
+	}
+	return policy, resources, cleaner, nil
+}
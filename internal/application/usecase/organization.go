@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// activeOrganization retrieves an organization, which must not be
+// deactivated
+func activeOrganization(ctx context.Context, orgRepo repository.OrganizationRepository, id uuid.UUID) (*entity.Organization, error) {
+	org, err := organization(ctx, orgRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsActive {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "organization is deactivated")
+	}
+	return org, nil
+}
+
+// organization retrieves an organization
+func organization(ctx context.Context, orgRepo repository.OrganizationRepository, id uuid.UUID) (*entity.Organization, error) {
+	org, err := orgRepo.GetByID(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "organization not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch organization")
+	}
+	return org, nil
+}
+
+// writable returns an error carrying the scope and reason of the read-only
+// mode when the cloud resources of an organization may not be changed
+func writable(ctx context.Context, guard service.ReadOnlyGuard, orgID uuid.UUID) error {
+	err := checkWritable(ctx, guard, orgID)
+	var readOnly *service.ReadOnlyError
+	switch {
+	case err == nil:
+		return nil
+	case apperrors.As(err, &readOnly):
+		return &apperrors.AppError{Err: err, Code: apperrors.CodeReadOnly, Message: readOnly.Error(),
+			Details: map[string]any{"scope": readOnly.Scope, "reason": readOnly.Reason}}
+	case apperrors.Is(err, apperrors.ErrNotFound):
+		return apperrors.NewWithCode(err, apperrors.CodeNotFound, "organization not found")
+	}
+	return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to check the read-only mode")
+}
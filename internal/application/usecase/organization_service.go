@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// OrganizationService reads organizations and their cloud accounts. Its
+// errors carry the code and message to return to clients.
+type OrganizationService interface {
+	// Get retrieves an organization by ID
+	Get(ctx context.Context, id uuid.UUID) (*entity.Organization, error)
+
+	// GetMany retrieves organizations by ID, leaving out the IDs of no
+	// organization
+	GetMany(ctx context.Context, ids []uuid.UUID) ([]*entity.Organization, error)
+
+	// Accounts retrieves the cloud accounts of organizations, by provider
+	// and account ID
+	Accounts(ctx context.Context, orgIDs []uuid.UUID) ([]*entity.CloudAccount, error)
+}
+
+// OrganizationUseCase implements OrganizationService
+type OrganizationUseCase struct {
+	orgRepo     repository.OrganizationRepository
+	accountRepo repository.CloudAccountRepository
+}
+
+// NewOrganizationUseCase creates a new OrganizationUseCase
+func NewOrganizationUseCase(
+	orgRepo repository.OrganizationRepository,
+	accountRepo repository.CloudAccountRepository,
+) *OrganizationUseCase {
+	return &OrganizationUseCase{
+		orgRepo:     orgRepo,
+		accountRepo: accountRepo,
+	}
+}
+
+var _ OrganizationService = (*OrganizationUseCase)(nil)
+
+// Get implements OrganizationService
+func (uc *OrganizationUseCase) Get(ctx context.Context, id uuid.UUID) (*entity.Organization, error) {
+	return organization(ctx, uc.orgRepo, id)
+}
+
+// GetMany implements OrganizationService
+func (uc *OrganizationUseCase) GetMany(ctx context.Context, ids []uuid.UUID) ([]*entity.Organization, error) {
+	orgs, err := uc.orgRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch organizations")
+	}
+	return orgs, nil
+}
+
+// Accounts implements OrganizationService
+func (uc *OrganizationUseCase) Accounts(ctx context.Context, orgIDs []uuid.UUID) ([]*entity.CloudAccount, error) {
+	accounts, err := uc.accountRepo.List(ctx, repository.CloudAccountFilter{OrganizationIDs: orgIDs})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to list cloud accounts")
+	}
+	return accounts, nil
+}
@@ -0,0 +1,489 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// PolicyService manages the cleanup policies of organizations through the
+// API. Policies given to it are validated already; its errors carry the
+// code and message to return to clients.
+type PolicyService interface {
+	// Create creates an enabled policy
+	Create(ctx context.Context, policy *entity.Policy) (*entity.Policy, error)
+
+	// Get retrieves a policy by ID
+	Get(ctx context.Context, id uuid.UUID) (*entity.Policy, error)
+
+	// List retrieves a page of policies and the number of policies
+	// matching the filter
+	List(ctx context.Context, filter repository.PolicyFilter) ([]*entity.Policy, int64, error)
+
+	// Update replaces the definition of a policy. With a version, the
+	// update fails with a conflict giving the current version in its
+	// "version" detail if the policy was updated since.
+	Update(ctx context.Context, id uuid.UUID, policy *entity.Policy) (*entity.Policy, error)
+
+	// Delete deletes a policy, which can be restored
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Restore restores a deleted policy as it was when deleted
+	Restore(ctx context.Context, id uuid.UUID) (*entity.Policy, error)
+
+	// SetEnabled enables or disables a policy
+	SetEnabled(ctx context.Context, id uuid.UUID, enabled bool) error
+
+	// Simulate evaluates a policy against the current resources without
+	// acting on them
+	Simulate(ctx context.Context, id uuid.UUID) (*PolicySimulation, error)
+
+	// Apply converges the declaratively managed policies of an
+	// organization to a desired set
+	Apply(ctx context.Context, input ApplyPoliciesInput) (*ApplyPoliciesOutput, error)
+}
+
+// PolicyUseCase implements PolicyService
+type PolicyUseCase struct {
+	policyRepo   repository.PolicyRepository
+	resourceRepo repository.ResourceRepository
+	viewRepo     repository.ResourceViewRepository
+	orgRepo      repository.OrganizationRepository
+	uow          repository.UnitOfWork
+}
+
+// NewPolicyUseCase creates a new PolicyUseCase
+func NewPolicyUseCase(
+	policyRepo repository.PolicyRepository,
+	resourceRepo repository.ResourceRepository,
+	viewRepo repository.ResourceViewRepository,
+	orgRepo repository.OrganizationRepository,
+	uow repository.UnitOfWork,
+) *PolicyUseCase {
+	return &PolicyUseCase{
+		policyRepo:   policyRepo,
+		resourceRepo: resourceRepo,
+		viewRepo:     viewRepo,
+		orgRepo:      orgRepo,
+		uow:          uow,
+	}
+}
+
+var _ PolicyService = (*PolicyUseCase)(nil)
+
+// PolicySimulation represents the outcome of a policy simulation
+type PolicySimulation struct {
+	Policy *entity.Policy
+	// Evaluated is the number of resources the policy was evaluated on
+	Evaluated int
+	// ConditionMatches counts the resources matching each condition
+	ConditionMatches map[string]int
+	// Matches are the resources the policy matches, most expensive first
+	Matches                 []PolicyMatch
+	EstimatedMonthlySavings float64
+	EstimatedCarbonSavings  float64
+}
+
+// PolicyMatch is a resource matched by a policy, with the outcome of each
+// of its conditions
+type PolicyMatch struct {
+	Resource   *entity.Resource
+	Conditions []service.ConditionResult
+}
+
+// ApplyPoliciesInput represents the desired set of the managed policies of
+// an organization, each with its external ID and enabled state
+type ApplyPoliciesInput struct {
+	OrganizationID uuid.UUID
+	Policies       []*entity.Policy
+	// DryRun reports the changes without making them
+	DryRun bool
+}
+
+// ApplyPoliciesOutput represents the outcome of an apply, by external ID
+type ApplyPoliciesOutput struct {
+	Created   []string
+	Updated   []string
+	Deleted   []string
+	Unchanged []string
+	// Policies are the managed policies after the apply, or as they are on
+	// a dry run, by external ID
+	Policies []*entity.Policy
+}
+
+// Create implements PolicyService
+func (uc *PolicyUseCase) Create(ctx context.Context, policy *entity.Policy) (*entity.Policy, error) {
+	org, err := activeOrganization(ctx, uc.orgRepo, policy.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.check(ctx, org, policy); err != nil {
+		return nil, err
+	}
+
+	policy.ID = uuid.New()
+	policy.IsEnabled = true
+	policy.ExternalID = ""
+	if err := uc.policyRepo.Create(ctx, policy); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to create policy")
+	}
+	return policy, nil
+}
+
+// Get implements PolicyService
+func (uc *PolicyUseCase) Get(ctx context.Context, id uuid.UUID) (*entity.Policy, error) {
+	policy, err := uc.policyRepo.GetByID(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "policy not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch policy")
+	}
+	return policy, nil
+}
+
+// List implements PolicyService
+func (uc *PolicyUseCase) List(ctx context.Context, filter repository.PolicyFilter) ([]*entity.Policy, int64, error) {
+	total, err := uc.policyRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to count policies")
+	}
+	policies, err := uc.policyRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch policies")
+	}
+	return policies, total, nil
+}
+
+// Update implements PolicyService
+func (uc *PolicyUseCase) Update(ctx context.Context, id uuid.UUID, policy *entity.Policy) (*entity.Policy, error) {
+	org, err := organization(ctx, uc.orgRepo, policy.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.check(ctx, org, policy); err != nil {
+		return nil, err
+	}
+
+	policy.ID = id
+	if err := uc.policyRepo.Update(ctx, policy); err != nil {
+		if !apperrors.Is(err, apperrors.ErrConflict) {
+			if apperrors.Is(err, apperrors.ErrNotFound) {
+				return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "policy not found")
+			}
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to update policy")
+		}
+		current, ferr := uc.Get(ctx, id)
+		if ferr != nil {
+			return nil, ferr
+		}
+		return nil, &apperrors.AppError{
+			Err:     err,
+			Code:    apperrors.CodeConflict,
+			Message: fmt.Sprintf("policy was updated since version %d, it is at version %d", policy.Version, current.Version),
+			Details: map[string]any{"version": current.Version},
+		}
+	}
+	return uc.Get(ctx, id)
+}
+
+// Delete implements PolicyService
+func (uc *PolicyUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := uc.policyRepo.Delete(ctx, id); err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.NewWithCode(err, apperrors.CodeNotFound, "policy not found")
+		}
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to delete policy")
+	}
+	return nil
+}
+
+// Restore implements PolicyService. Policies of deactivated organizations
+// are restored disabled.
+func (uc *PolicyUseCase) Restore(ctx context.Context, id uuid.UUID) (*entity.Policy, error) {
+	policy, err := uc.policyRepo.GetDeleted(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "deleted policy not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch policy")
+	}
+
+	// The apply endpoint may have created a policy with the same key since
+	if policy.ExternalID != "" {
+		n, err := uc.policyRepo.Count(ctx, repository.PolicyFilter{OrganizationID: &policy.OrganizationID, ExternalID: &policy.ExternalID})
+		if err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to restore policy")
+		}
+		if n > 0 {
+			return nil, apperrors.NewWithCode(apperrors.ErrAlreadyExists, apperrors.CodeConflict, "a policy with external_id "+policy.ExternalID+" exists")
+		}
+	}
+	if policy.ViewID != nil {
+		if _, err := uc.viewRepo.GetByID(ctx, *policy.ViewID); err != nil {
+			if apperrors.Is(err, apperrors.ErrNotFound) {
+				return nil, apperrors.NewWithCode(err, apperrors.CodeConflict, "the resource view of the policy was deleted")
+			}
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to restore policy")
+		}
+	}
+
+	if org, err := uc.orgRepo.GetByID(ctx, policy.OrganizationID); err == nil && !org.IsActive {
+		policy.IsEnabled = false
+	}
+	if err := uc.policyRepo.Restore(ctx, policy); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to restore policy")
+	}
+	return uc.Get(ctx, id)
+}
+
+// SetEnabled implements PolicyService. Policies of deactivated
+// organizations stay disabled.
+func (uc *PolicyUseCase) SetEnabled(ctx context.Context, id uuid.UUID, enabled bool) error {
+	if enabled {
+		policy, err := uc.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if org, err := uc.orgRepo.GetByID(ctx, policy.OrganizationID); err == nil && !org.IsActive {
+			return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "organization is deactivated")
+		}
+	}
+
+	if err := uc.policyRepo.SetEnabled(ctx, id, enabled); err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.NewWithCode(err, apperrors.CodeNotFound, "policy not found")
+		}
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to update policy")
+	}
+	return nil
+}
+
+// Simulate implements PolicyService. The resources evaluated are the
+// tracked resources of the provider and types of the policy, narrowed down
+// to those of its view when it has one.
+func (uc *PolicyUseCase) Simulate(ctx context.Context, id uuid.UUID) (*PolicySimulation, error) {
+	policy, err := uc.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	evaluator, err := service.NewPolicyEvaluator(policy)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInvalidInput, err.Error())
+	}
+
+	var filter repository.ResourceFilter
+	if policy.ViewID != nil {
+		view, err := uc.viewRepo.GetByID(ctx, *policy.ViewID)
+		if err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resource view")
+		}
+		if filter, err = viewFilter(view); err != nil {
+			return nil, err
+		}
+	}
+	filter.OrganizationID = &policy.OrganizationID
+	filter.Provider = &policy.Provider
+	filter.Types = policy.ResourceTypes
+	filter.Tracked = true
+	filter.Sort = repository.ResourceSort{Field: repository.ResourceSortCost}
+
+	resources, err := uc.resourceRepo.List(ctx, filter)
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resources")
+	}
+
+	sim := &PolicySimulation{
+		Policy:           policy,
+		Evaluated:        len(resources),
+		ConditionMatches: make(map[string]int),
+		Matches:          []PolicyMatch{},
+	}
+	now := time.Now()
+	for _, r := range resources {
+		eval := evaluator.Evaluate(r, now)
+		for _, cond := range eval.Conditions {
+			if cond.Matched {
+				sim.ConditionMatches[cond.Condition]++
+			}
+		}
+		if !eval.Matched {
+			continue
+		}
+		sim.EstimatedMonthlySavings += r.MonthlyCost
+		sim.EstimatedCarbonSavings += r.CarbonFootprint
+		sim.Matches = append(sim.Matches, PolicyMatch{Resource: r, Conditions: eval.Conditions})
+	}
+	return sim, nil
+}
+
+// Apply implements PolicyService. Missing policies are created, differing
+// ones updated and managed policies absent from the set deleted; policies
+// without an external ID are never touched. The whole set is checked
+// before any change and applied in a single transaction.
+func (uc *PolicyUseCase) Apply(ctx context.Context, input ApplyPoliciesInput) (*ApplyPoliciesOutput, error) {
+	org, err := activeOrganization(ctx, uc.orgRepo, input.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	desired := make(map[string]*entity.Policy, len(input.Policies))
+	for _, policy := range input.Policies {
+		policy.OrganizationID = org.ID
+		if err := uc.check(ctx, org, policy); err != nil {
+			return nil, err
+		}
+		desired[policy.ExternalID] = policy
+	}
+
+	out := &ApplyPoliciesOutput{
+		Created:   []string{},
+		Updated:   []string{},
+		Deleted:   []string{},
+		Unchanged: []string{},
+	}
+	managed := repository.PolicyFilter{OrganizationID: &org.ID, Managed: true}
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		// Concurrent applies of an organization run one after the other
+		if err := uc.orgRepo.Lock(ctx, org.ID); err != nil {
+			return err
+		}
+
+		existing, err := uc.policyRepo.List(ctx, managed)
+		if err != nil {
+			return err
+		}
+		current := make(map[string]*entity.Policy, len(existing))
+		for _, p := range existing {
+			current[p.ExternalID] = p
+		}
+
+		for _, externalID := range sortedKeys(desired) {
+			policy := desired[externalID]
+			p, ok := current[externalID]
+			switch {
+			case !ok:
+				out.Created = append(out.Created, externalID)
+				if input.DryRun {
+					continue
+				}
+				policy.ID = uuid.New()
+				if err := uc.policyRepo.Create(ctx, policy); err != nil {
+					return err
+				}
+			case sameDefinition(p, policy):
+				out.Unchanged = append(out.Unchanged, externalID)
+			default:
+				out.Updated = append(out.Updated, externalID)
+				if input.DryRun {
+					continue
+				}
+				policy.ID, policy.Version = p.ID, 0
+				if err := uc.policyRepo.Update(ctx, policy); err != nil {
+					return err
+				}
+				if policy.IsEnabled != p.IsEnabled {
+					if err := uc.policyRepo.SetEnabled(ctx, p.ID, policy.IsEnabled); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		for _, externalID := range sortedKeys(current) {
+			if _, ok := desired[externalID]; ok {
+				continue
+			}
+			out.Deleted = append(out.Deleted, externalID)
+			if input.DryRun {
+				continue
+			}
+			if err := uc.policyRepo.Delete(ctx, current[externalID].ID); err != nil {
+				return err
+			}
+		}
+
+		out.Policies, err = uc.policyRepo.List(ctx, managed)
+		return err
+	})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to apply policies")
+	}
+	sort.Slice(out.Policies, func(i, j int) bool {
+		return out.Policies[i].ExternalID < out.Policies[j].ExternalID
+	})
+	return out, nil
+}
+
+// check rejects policies scoped to regions on the denylist of their
+// organization, and resolves their view, which must belong to it
+func (uc *PolicyUseCase) check(ctx context.Context, org *entity.Organization, policy *entity.Policy) error {
+	if denied := org.Settings.DeniedRegions(policy.Conditions.Regions); len(denied) > 0 {
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput,
+			"policy regions are denylisted for this organization: "+strings.Join(denied, ", "))
+	}
+	if policy.ViewID != nil {
+		if _, err := loadView(ctx, uc.viewRepo, org.ID, *policy.ViewID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// policyDefinition is what apply compares to tell whether a policy changed
+type policyDefinition struct {
+	Name          string
+	Description   string
+	Provider      entity.CloudProvider
+	ResourceTypes []entity.ResourceType
+	Conditions    entity.PolicyConditions
+	Actions       []entity.PolicyAction
+	Schedule      string
+	OffHours      *entity.OffHoursSchedule
+	ViewID        *uuid.UUID
+	IsEnabled     bool
+}
+
+// sameDefinition compares policies through the JSON form of their
+// definitions, so that empty and missing lists or maps are equal
+func sameDefinition(a, b *entity.Policy) bool {
+	definition := func(p *entity.Policy) policyDefinition {
+		d := policyDefinition{
+			Name:          p.Name,
+			Description:   p.Description,
+			Provider:      p.Provider,
+			ResourceTypes: p.ResourceTypes,
+			Conditions:    p.Conditions,
+			Actions:       p.Actions,
+			Schedule:      p.Schedule,
+			OffHours:      p.OffHours,
+			ViewID:        p.ViewID,
+			IsEnabled:     p.IsEnabled,
+		}
+		if len(d.ResourceTypes) == 0 {
+			d.ResourceTypes = nil
+		}
+		return d
+	}
+	ja, errA := json.Marshal(definition(a))
+	jb, errB := json.Marshal(definition(b))
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
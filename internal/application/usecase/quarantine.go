@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/google/uuid"
+)
+
+// QuarantineUseCase deletes quarantined resources once their window has
+// ended and restores those brought back before
+type QuarantineUseCase struct {
+	resourceRepo   repository.ResourceRepository
+	cleanerFactory service.ResourceCleanerFactory
+	readOnly       service.ReadOnlyGuard
+}
+
+// NewQuarantineUseCase creates a new QuarantineUseCase. readOnly may be nil
+// when there is no read-only mode.
+func NewQuarantineUseCase(
+	resourceRepo repository.ResourceRepository,
+	cleanerFactory service.ResourceCleanerFactory,
+	readOnly service.ReadOnlyGuard,
+) *QuarantineUseCase {
+	return &QuarantineUseCase{
+		resourceRepo:   resourceRepo,
+		cleanerFactory: cleanerFactory,
+		readOnly:       readOnly,
+	}
+}
+
+// QuarantineInput represents input for purging or restoring quarantined
+// resources
+type QuarantineInput struct {
+	OrganizationID uuid.UUID
+	ResourceIDs    []uuid.UUID
+	Credentials    []byte
+}
+
+// Purge deletes the given resources whose quarantine window has ended.
+// Resources restored or deleted since they were selected are skipped.
+func (uc *QuarantineUseCase) Purge(ctx context.Context, input QuarantineInput) (*CleanupResourcesOutput, error) {
+	now := time.Now()
+	return uc.run(ctx, input, func(r *entity.Resource) error {
+		if !r.QuarantineExpired(now) {
+			return fmt.Errorf("resource is not in an ended quarantine")
+		}
+		return nil
+	}, func(ctx context.Context, cleaner service.ResourceCleaner, r *entity.Resource) (*service.CleanupResult, error) {
+		// Routes or DNS records may have been pointed at the resource
+		// during its window
+		err := service.CheckNetworkSafeDelete(ctx, cleaner, r)
+		if err == nil {
+			err = service.CheckInterfaceSafeDelete(r)
+		}
+		if err == nil {
+			err = service.CheckDNSSafeDelete(ctx, cleaner, r)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unsafe to delete: %w", err)
+		}
+		if r.Type.IsPublicIP() {
+			return service.ReleaseIP(ctx, cleaner, r)
+		}
+		if r.Type.IsManagedDatabase() {
+			return service.DeleteDatabase(ctx, cleaner, r)
+		}
+		if r.Type.IsSnapshot() {
+			return service.DeleteSnapshot(ctx, cleaner, r)
+		}
+		return cleaner.Delete(ctx, r)
+	}, (*entity.Resource).MarkAsDeleted)
+}
+
+// Restore brings the given quarantined resources back into service
+func (uc *QuarantineUseCase) Restore(ctx context.Context, input QuarantineInput) (*CleanupResourcesOutput, error) {
+	return uc.run(ctx, input, func(r *entity.Resource) error {
+		if !r.IsQuarantined() {
+			return fmt.Errorf("resource is not quarantined")
+		}
+		return nil
+	}, service.RestoreResource, (*entity.Resource).Restore)
+}
+
+// run applies an action to the resources passing check and updates those
+// it succeeded on with done. Nothing is done in read-only mode.
+func (uc *QuarantineUseCase) run(
+	ctx context.Context,
+	input QuarantineInput,
+	check func(*entity.Resource) error,
+	action func(context.Context, service.ResourceCleaner, *entity.Resource) (*service.CleanupResult, error),
+	done func(*entity.Resource),
+) (*CleanupResourcesOutput, error) {
+	if err := checkWritable(ctx, uc.readOnly, input.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	output := &CleanupResourcesOutput{}
+	fail := func(id uuid.UUID, format string, args ...any) {
+		output.Results = append(output.Results, &service.CleanupResult{
+			ResourceID:   id.String(),
+			Success:      false,
+			Action:       entity.PolicyActionQuarantine,
+			ErrorMessage: fmt.Sprintf(format, args...),
+		})
+		output.FailureCount++
+	}
+
+	cleaners := make(map[entity.CloudProvider]service.ResourceCleaner)
+	for _, id := range input.ResourceIDs {
+		resource, err := uc.resourceRepo.GetByID(ctx, id)
+		if err != nil {
+			fail(id, "resource not found: %v", err)
+			continue
+		}
+		if resource.OrganizationID != input.OrganizationID {
+			fail(id, "resource does not belong to organization %s", input.OrganizationID)
+			continue
+		}
+		if err := check(resource); err != nil {
+			fail(id, "%v", err)
+			continue
+		}
+
+		cleaner, ok := cleaners[resource.Provider]
+		if !ok {
+			cleaner, err = uc.cleanerFactory.Create(resource.Provider, input.Credentials)
+			if err != nil {
+				fail(id, "failed to create cleaner: %v", err)
+				continue
+			}
+			cleaners[resource.Provider] = cleaner
+		}
+
+		result, err := action(ctx, cleaner, resource)
+		if err != nil {
+			fail(id, "%v", err)
+			continue
+		}
+		output.Results = append(output.Results, result)
+		if !result.Success {
+			output.FailureCount++
+			continue
+		}
+		output.SuccessCount++
+		output.TotalCostSaved += result.CostSaved
+		output.TotalCarbonSaved += result.CarbonSaved
+
+		done(resource)
+		if err := uc.resourceRepo.Update(ctx, resource); err != nil {
+			return output, fmt.Errorf("failed to update resource %s: %w", resource.ID, err)
+		}
+	}
+
+	return output, nil
+}
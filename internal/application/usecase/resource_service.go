@@ -0,0 +1,512 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// ResourceService reads the inventory of organizations and makes the
+// changes to it requested through the API. Its errors carry the code and
+// message to return to clients, except plan quota errors, which are
+// *entity.QuotaExceededError.
+type ResourceService interface {
+	// List retrieves a page of resources, and their number unless it is not
+	// asked for
+	List(ctx context.Context, input ListResourcesInput) (*ListResourcesOutput, error)
+
+	// View retrieves a saved resource view, whose filters apply to lists
+	View(ctx context.Context, id uuid.UUID) (*entity.ResourceView, error)
+
+	// Get retrieves a resource by ID
+	Get(ctx context.Context, id uuid.UUID) (*entity.Resource, error)
+
+	// Remove removes a resource from the inventory, without acting on it in
+	// the cloud
+	Remove(ctx context.Context, id uuid.UUID, actor string) error
+
+	// Reinstate adds a removed resource back to the inventory
+	Reinstate(ctx context.Context, id uuid.UUID, actor string) error
+
+	// Restore cancels the deletion of a quarantined resource and queues its
+	// restore
+	Restore(ctx context.Context, id uuid.UUID) error
+
+	// History retrieves a page of the timeline of a resource
+	History(ctx context.Context, input ResourceHistoryInput) (*ResourceHistoryOutput, error)
+
+	// Import adds resources to the inventory of an organization, or updates
+	// those already in it
+	Import(ctx context.Context, input ImportResourcesInput) (*ImportResourcesOutput, error)
+}
+
+// ResourceUseCase implements ResourceService
+type ResourceUseCase struct {
+	resourceRepo repository.ResourceRepository
+	events       repository.ResourceEventRepository
+	policyRepo   repository.PolicyRepository
+	viewRepo     repository.ResourceViewRepository
+	orgRepo      repository.OrganizationRepository
+	customTypes  repository.CustomResourceTypeRepository
+	uow          repository.UnitOfWork
+	notifier     service.InventoryNotifier
+	queue        service.InventoryQueue
+	readOnly     service.ReadOnlyGuard
+}
+
+// NewResourceUseCase creates a new ResourceUseCase. Status changes and
+// imports are reported to notifier; restores are refused in read-only mode,
+// and readOnly may be nil when there is none.
+func NewResourceUseCase(
+	resourceRepo repository.ResourceRepository,
+	events repository.ResourceEventRepository,
+	policyRepo repository.PolicyRepository,
+	viewRepo repository.ResourceViewRepository,
+	orgRepo repository.OrganizationRepository,
+	customTypes repository.CustomResourceTypeRepository,
+	uow repository.UnitOfWork,
+	notifier service.InventoryNotifier,
+	queue service.InventoryQueue,
+	readOnly service.ReadOnlyGuard,
+) *ResourceUseCase {
+	return &ResourceUseCase{
+		resourceRepo: resourceRepo,
+		events:       events,
+		policyRepo:   policyRepo,
+		viewRepo:     viewRepo,
+		orgRepo:      orgRepo,
+		customTypes:  customTypes,
+		uow:          uow,
+		notifier:     notifier,
+		queue:        queue,
+		readOnly:     readOnly,
+	}
+}
+
+var _ ResourceService = (*ResourceUseCase)(nil)
+
+// ListResourcesInput represents input for listing resources
+type ListResourcesInput struct {
+	// OrganizationID, when set, keeps the resources of an organization
+	OrganizationID *uuid.UUID
+	Filter         entity.ResourceViewFilter
+	// After continues the list after this resource, in place of Offset
+	After  *repository.ResourceKey
+	Limit  int
+	Offset int
+	// IncludeTotal counts the matching resources when the page does not
+	// tell their number
+	IncludeTotal bool
+}
+
+// ListResourcesOutput represents output from listing resources
+type ListResourcesOutput struct {
+	// Resources holds up to Limit+1 resources: the one after the page, if
+	// any, tells that another page follows
+	Resources []*entity.Resource
+	// Total is the number of matching resources, -1 when it was not counted
+	Total int64
+}
+
+// List implements ResourceService. The last page of an offset listing gives
+// the total without scanning the matching resources a second time.
+func (uc *ResourceUseCase) List(ctx context.Context, input ListResourcesInput) (*ListResourcesOutput, error) {
+	filter, err := resourceFilter(input.Filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.OrganizationID = input.OrganizationID
+
+	// The count ignores the cursor, limit and offset of the page
+	count := filter
+	offset := input.Offset
+	if input.After != nil {
+		offset = 0
+	}
+	filter.After, filter.Limit, filter.Offset = input.After, input.Limit+1, offset
+
+	// One more resource than the page is fetched to know whether another
+	// page follows
+	resources, err := uc.resourceRepo.List(ctx, filter)
+	if err != nil {
+		return nil, listError(err, "failed to fetch resources")
+	}
+
+	total := int64(-1)
+	switch {
+	case input.After == nil && len(resources) <= input.Limit && (len(resources) > 0 || offset == 0):
+		total = int64(offset + len(resources))
+	case input.IncludeTotal:
+		if total, err = uc.resourceRepo.Count(ctx, count); err != nil {
+			return nil, listError(err, "failed to count resources")
+		}
+	}
+
+	return &ListResourcesOutput{Resources: resources, Total: total}, nil
+}
+
+// listError returns the error of a failed list, a client error when its
+// filter is invalid
+func listError(err error, message string) error {
+	if apperrors.Is(err, apperrors.ErrInvalidInput) {
+		return apperrors.NewWithCode(err, apperrors.CodeInvalidInput, err.Error())
+	}
+	return apperrors.NewWithCode(err, apperrors.CodeInternal, message)
+}
+
+// View implements ResourceService
+func (uc *ResourceUseCase) View(ctx context.Context, id uuid.UUID) (*entity.ResourceView, error) {
+	return loadView(ctx, uc.viewRepo, uuid.Nil, id)
+}
+
+// Get implements ResourceService
+func (uc *ResourceUseCase) Get(ctx context.Context, id uuid.UUID) (*entity.Resource, error) {
+	resource, err := uc.resourceRepo.GetByID(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "resource not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resource")
+	}
+	return resource, nil
+}
+
+// Remove implements ResourceService. Its status becomes removed, which
+// scans keep until it is reinstated.
+func (uc *ResourceUseCase) Remove(ctx context.Context, id uuid.UUID, actor string) error {
+	resource, err := uc.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	changed, err := uc.changeStatus(ctx, resource, actor, entity.ResourceStatusRemoved)
+	if err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to remove resource")
+	}
+	if !changed {
+		return apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "resource not found")
+	}
+	uc.notifier.StatusChanged(ctx, resource.OrganizationID, id, entity.ResourceStatusRemoved)
+	return nil
+}
+
+// Reinstate implements ResourceService. The resource is active until the
+// next scan sets its status.
+func (uc *ResourceUseCase) Reinstate(ctx context.Context, id uuid.UUID, actor string) error {
+	resource, err := uc.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	changed, err := uc.changeStatus(ctx, resource, actor, entity.ResourceStatusActive, entity.ResourceStatusRemoved)
+	if err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to reinstate resource")
+	}
+	if !changed {
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "resource is not removed from inventory")
+	}
+	uc.notifier.StatusChanged(ctx, resource.OrganizationID, id, entity.ResourceStatusActive)
+	return nil
+}
+
+// changeStatus sets the status of a resource and records the change in its
+// timeline, in a unit of work. It returns false when the resource does not
+// have one of the statuses from.
+func (uc *ResourceUseCase) changeStatus(ctx context.Context, resource *entity.Resource, actor string, to entity.ResourceStatus, from ...entity.ResourceStatus) (bool, error) {
+	changed := false
+	err := uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if changed, err = uc.resourceRepo.SetStatus(ctx, resource.ID, to, from...); err != nil || !changed {
+			return err
+		}
+		if resource.Status == to {
+			return nil
+		}
+		return uc.events.Record(ctx, entity.NewResourceEvent(resource.OrganizationID, resource.ID, entity.ResourceEventStatusChanged, actor, map[string]any{
+			"from": string(resource.Status),
+			"to":   string(to),
+		}))
+	})
+	return changed, err
+}
+
+// Restore implements ResourceService. It is refused while the
+// installation or the organization is in read-only mode.
+func (uc *ResourceUseCase) Restore(ctx context.Context, id uuid.UUID) error {
+	resource, err := uc.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := writable(ctx, uc.readOnly, resource.OrganizationID); err != nil {
+		return err
+	}
+
+	// Clearing the end of the window first keeps the purge from deleting the
+	// resource while it is being restored
+	cancelled, err := uc.resourceRepo.CancelQuarantine(ctx, id)
+	if err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to update resource")
+	}
+	if !cancelled {
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "resource is not quarantined")
+	}
+
+	if err := uc.queue.EnqueueRestore(ctx, resource.OrganizationID, id); err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to enqueue restore task")
+	}
+	return nil
+}
+
+// ResourceHistoryInput represents input for reading the timeline of a
+// resource
+type ResourceHistoryInput struct {
+	ResourceID uuid.UUID
+	// Types keeps the events of these types, all of them when empty
+	Types []entity.ResourceEventType
+	// After continues the timeline with the events older than this one
+	After *repository.ResourceEventKey
+	Limit int
+}
+
+// ResourceHistoryOutput represents a page of the timeline of a resource
+type ResourceHistoryOutput struct {
+	Events []*entity.ResourceEvent
+	// PolicyNames are the names of the policies of the events, as they are
+	// now, deleted ones included
+	PolicyNames map[uuid.UUID]string
+}
+
+// History implements ResourceService
+func (uc *ResourceUseCase) History(ctx context.Context, input ResourceHistoryInput) (*ResourceHistoryOutput, error) {
+	for _, t := range input.Types {
+		if !slices.Contains(entity.ResourceEventTypes, t) {
+			return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "unknown event type "+string(t))
+		}
+	}
+	if _, err := uc.Get(ctx, input.ResourceID); err != nil {
+		return nil, err
+	}
+
+	events, err := uc.events.List(ctx, repository.ResourceEventFilter{
+		ResourceID: input.ResourceID,
+		Types:      input.Types,
+		After:      input.After,
+		Limit:      input.Limit,
+	})
+	if err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resource history")
+	}
+	out := &ResourceHistoryOutput{Events: events}
+
+	var policyIDs []uuid.UUID
+	for _, e := range events {
+		if e.PolicyID != nil {
+			policyIDs = append(policyIDs, *e.PolicyID)
+		}
+	}
+	if out.PolicyNames, err = uc.policyRepo.Names(ctx, policyIDs); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch policies")
+	}
+	return out, nil
+}
+
+// ResourceImportRow is a resource of an inventory import. Fields left empty
+// keep the value of a resource already in the inventory.
+type ResourceImportRow struct {
+	// Line is the position of the row in the import, which errors refer to
+	Line       int
+	Provider   entity.CloudProvider
+	ResourceID string
+	Type       entity.ResourceType
+	Region     string
+	Name       string
+	AccountID  string
+	// Tags, when not nil, replace those of a known resource
+	Tags        map[string]string
+	MonthlyCost *float64
+	// Err leaves the row out of the import
+	Err error
+}
+
+// ImportResourcesInput represents input for importing resources
+type ImportResourcesInput struct {
+	OrganizationID uuid.UUID
+	// Rows whose type is unknown to the organization, or which repeat an
+	// earlier row, get an Err and are left out
+	Rows  []*ResourceImportRow
+	Actor string
+}
+
+// ImportResourcesOutput represents output from importing resources
+type ImportResourcesOutput struct {
+	Created int
+	Updated int
+}
+
+// Import implements ResourceService. Resources are matched on provider and
+// cloud resource ID: new ones are created active, known ones updated with
+// the fields of their row, their status left to scans. New resources count
+// towards the resources quota of the plan. The rows are saved in a single
+// unit of work.
+func (uc *ResourceUseCase) Import(ctx context.Context, input ImportResourcesInput) (*ImportResourcesOutput, error) {
+	org, err := activeOrganization(ctx, uc.orgRepo, input.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.validateImportRows(ctx, org.ID, input.Rows); err != nil {
+		return nil, err
+	}
+
+	out := &ImportResourcesOutput{}
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		var refs []repository.CloudResourceRef
+		for _, row := range input.Rows {
+			if row.Err == nil {
+				refs = append(refs, repository.CloudResourceRef{Provider: row.Provider, ResourceID: row.ResourceID})
+			}
+		}
+		known, err := uc.resourceRepo.GetByResourceIDs(ctx, org.ID, refs)
+		if err != nil {
+			return err
+		}
+		existing := make(map[repository.CloudResourceRef]*entity.Resource, len(known))
+		for _, r := range known {
+			existing[repository.CloudResourceRef{Provider: r.Provider, ResourceID: r.ResourceID}] = r
+		}
+
+		var created, updated []*entity.Resource
+		for _, row := range input.Rows {
+			if row.Err != nil {
+				continue
+			}
+			if r, ok := existing[repository.CloudResourceRef{Provider: row.Provider, ResourceID: row.ResourceID}]; ok {
+				row.update(r)
+				updated = append(updated, r)
+				continue
+			}
+			created = append(created, row.resource(org.ID))
+		}
+
+		if len(created) > 0 {
+			usage, err := uc.orgRepo.Usage(ctx, org.ID, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := entity.CheckQuota(org.Plan, entity.QuotaResources, usage[entity.QuotaResources], len(created)); err != nil {
+				return err
+			}
+			if err := uc.resourceRepo.BulkCreate(ctx, created, nil); err != nil {
+				return err
+			}
+			events := make([]*entity.ResourceEvent, len(created))
+			for i, r := range created {
+				events[i] = entity.NewResourceEvent(org.ID, r.ID, entity.ResourceEventDiscovered, input.Actor, map[string]any{
+					"source":       "import",
+					"status":       string(r.Status),
+					"monthly_cost": r.MonthlyCost,
+				})
+			}
+			if err := uc.events.Record(ctx, events...); err != nil {
+				return err
+			}
+		}
+		if err := uc.resourceRepo.BulkUpdate(ctx, updated); err != nil {
+			return err
+		}
+		out.Created, out.Updated = len(created), len(updated)
+		return nil
+	})
+	if err != nil {
+		var quotaErr *entity.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return nil, err
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to import resources")
+	}
+
+	if out.Created+out.Updated > 0 {
+		uc.notifier.InventoryChanged(ctx, org.ID)
+		if err := uc.queue.EnqueueOwnerAssignment(ctx, org.ID); err != nil {
+			log.Printf("Failed to enqueue owner assignment of org %s: %v", org.ID, err)
+		}
+	}
+	return out, nil
+}
+
+// validateImportRows sets the error of the rows whose type is neither a
+// built-in type nor a custom type of the organization for their provider,
+// and of those listing a resource twice
+func (uc *ResourceUseCase) validateImportRows(ctx context.Context, orgID uuid.UUID, rows []*ResourceImportRow) error {
+	custom := make(map[entity.CloudProvider][]*entity.CustomResourceType)
+	seen := make(map[repository.CloudResourceRef]int, len(rows))
+	for _, row := range rows {
+		if row.Err != nil {
+			continue
+		}
+		typeProvider, ok := row.Type.Provider()
+		if !ok {
+			types, loaded := custom[row.Provider]
+			if !loaded {
+				var err error
+				if types, err = uc.customTypes.ListByProvider(ctx, orgID, row.Provider); err != nil {
+					return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch custom resource types")
+				}
+				custom[row.Provider] = types
+			}
+			ok = slices.ContainsFunc(types, func(t *entity.CustomResourceType) bool { return t.Name == row.Type })
+			typeProvider = row.Provider
+		}
+		if !ok || typeProvider != row.Provider {
+			row.Err = fmt.Errorf("unknown resource type %q for provider %s", row.Type, row.Provider)
+			continue
+		}
+
+		ref := repository.CloudResourceRef{Provider: row.Provider, ResourceID: row.ResourceID}
+		if line, ok := seen[ref]; ok {
+			row.Err = fmt.Errorf("duplicate of row %d", line)
+			continue
+		}
+		seen[ref] = row.Line
+	}
+	return nil
+}
+
+// resource returns the resource a row creates
+func (r *ResourceImportRow) resource(orgID uuid.UUID) *entity.Resource {
+	resource := entity.NewResource(orgID, r.Provider, r.Type, r.ResourceID, r.Region, r.Name)
+	resource.AccountID = r.AccountID
+	if r.Tags != nil {
+		resource.Tags = r.Tags
+	}
+	if r.MonthlyCost != nil {
+		resource.MonthlyCost = *r.MonthlyCost
+	}
+	return resource
+}
+
+// update sets the type of a known resource and the fields the row gives
+func (r *ResourceImportRow) update(resource *entity.Resource) {
+	resource.Type = r.Type
+	if r.Region != "" {
+		resource.Region = r.Region
+	}
+	if r.Name != "" {
+		resource.Name = r.Name
+	}
+	if r.AccountID != "" {
+		resource.AccountID = r.AccountID
+	}
+	if r.Tags != nil {
+		resource.Tags = r.Tags
+	}
+	if r.MonthlyCost != nil {
+		resource.MonthlyCost = *r.MonthlyCost
+	}
+}
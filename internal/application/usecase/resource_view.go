@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// resourceSorts are the sorts of the resource list by name. Age lists the
+// oldest resources first.
+var resourceSorts = map[string]repository.ResourceSort{
+	"created": {Field: repository.ResourceSortCreated},
+	"cost":    {Field: repository.ResourceSortCost},
+	"carbon":  {Field: repository.ResourceSortCarbon},
+	"age":     {Field: repository.ResourceSortCreated, Asc: true},
+}
+
+// resourceFilter returns the repository filter of a filter of the resource
+// list, with its sort, reversed when its order is asc
+func resourceFilter(f entity.ResourceViewFilter) (repository.ResourceFilter, error) {
+	if f.Q != "" {
+		if _, err := service.ParseResourceSearch(f.Q); err != nil {
+			return repository.ResourceFilter{}, apperrors.NewWithCode(err, apperrors.CodeInvalidInput, err.Error())
+		}
+	}
+
+	filter := repository.ResourceFilter{MinCost: f.MinCost, Search: f.Q}
+	if f.Provider != "" {
+		provider := entity.CloudProvider(f.Provider)
+		filter.Provider = &provider
+	}
+	if f.Type != "" {
+		resourceType := entity.ResourceType(f.Type)
+		filter.Type = &resourceType
+	}
+	if f.Status != "" {
+		status := entity.ResourceStatus(f.Status)
+		filter.Status = &status
+	}
+	if f.Region != "" {
+		filter.Region = &f.Region
+	}
+	if f.AccountID != "" {
+		filter.AccountID = &f.AccountID
+	}
+	if f.Owner != "" {
+		filter.Owner = &f.Owner
+	}
+
+	filter.Sort = resourceSorts[f.Sort]
+	if f.Order == "asc" {
+		filter.Sort.Asc = !filter.Sort.Asc
+	}
+	return filter, nil
+}
+
+// viewFilter returns the filter of the resources a view selects, leaving
+// deleted and removed resources out unless the view asks for them
+func viewFilter(view *entity.ResourceView) (repository.ResourceFilter, error) {
+	filter, err := resourceFilter(view.Filters)
+	if err != nil {
+		return filter, apperrors.NewWithCode(err, apperrors.CodeInvalidInput, "invalid resource view: "+err.Error())
+	}
+	filter.OrganizationID = &view.OrganizationID
+	filter.Tracked = view.Filters.Status == ""
+	return filter, nil
+}
+
+// loadView retrieves a view of an organization, or of any organization
+// when orgID is nil
+func loadView(ctx context.Context, viewRepo repository.ResourceViewRepository, orgID, id uuid.UUID) (*entity.ResourceView, error) {
+	view, err := viewRepo.GetByID(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "resource view not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch resource view")
+	}
+	if orgID != uuid.Nil && view.OrganizationID != orgID {
+		return nil, apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "resource view not found")
+	}
+	return view, nil
+}
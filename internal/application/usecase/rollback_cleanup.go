@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/google/uuid"
+)
+
+// RollbackCleanupUseCase undoes the action a cleanup ran on a resource, from
+// the recovery recorded with the attempt
+type RollbackCleanupUseCase struct {
+	resourceRepo   repository.ResourceRepository
+	events         repository.ResourceEventRepository
+	cleanerFactory service.ResourceCleanerFactory
+	readOnly       service.ReadOnlyGuard
+}
+
+// NewRollbackCleanupUseCase creates a new RollbackCleanupUseCase. readOnly
+// may be nil when there is no read-only mode, and events nil when
+// rollbacks are not recorded.
+func NewRollbackCleanupUseCase(
+	resourceRepo repository.ResourceRepository,
+	events repository.ResourceEventRepository,
+	cleanerFactory service.ResourceCleanerFactory,
+	readOnly service.ReadOnlyGuard,
+) *RollbackCleanupUseCase {
+	return &RollbackCleanupUseCase{
+		resourceRepo:   resourceRepo,
+		events:         events,
+		cleanerFactory: cleanerFactory,
+		readOnly:       readOnly,
+	}
+}
+
+// RollbackCleanupInput represents input for undoing a cleanup action
+type RollbackCleanupInput struct {
+	OrganizationID uuid.UUID
+	ResourceID     uuid.UUID
+	Credentials    []byte
+	// TaskID is the cleanup task whose action is undone
+	TaskID   string
+	Recovery *service.Recovery
+	// Actor requested the rollback and is recorded in the timeline of the
+	// resource; entity.ResourceEventActorCleanup when empty
+	Actor string
+}
+
+// Execute undoes the action. The resource is back in the inventory once it
+// is, except disks recreated from snapshots, which get a new ID found by the
+// next scan. The rollback is recorded whether it succeeded or not. It fails
+// with a *service.ReadOnlyError in read-only mode and with a
+// *service.ManualRecoveryError when the action cannot be undone
+// automatically.
+func (uc *RollbackCleanupUseCase) Execute(ctx context.Context, input RollbackCleanupInput) (*service.CleanupResult, error) {
+	if err := checkWritable(ctx, uc.readOnly, input.OrganizationID); err != nil {
+		return nil, err
+	}
+	resource, err := uc.resourceRepo.GetByID(ctx, input.ResourceID)
+	if err != nil {
+		return nil, fmt.Errorf("resource not found: %w", err)
+	}
+	if resource.OrganizationID != input.OrganizationID {
+		return nil, fmt.Errorf("resource %s is not in organization %s", resource.ID, input.OrganizationID)
+	}
+
+	cleaner, err := uc.cleanerFactory.Create(resource.Provider, input.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cleaner: %w", err)
+	}
+	result, err := service.RollbackResource(ctx, cleaner, resource, input.Recovery)
+	if err != nil {
+		result = &service.CleanupResult{
+			ResourceID:   resource.ID.String(),
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}
+	}
+
+	actor := input.Actor
+	if actor == "" {
+		actor = entity.ResourceEventActorCleanup
+	}
+	data := map[string]any{
+		"kind":    string(input.Recovery.Kind),
+		"success": result.Success,
+	}
+	if result.ErrorMessage != "" {
+		data["error"] = result.ErrorMessage
+	}
+	rollback := entity.NewResourceEvent(resource.OrganizationID, resource.ID, entity.ResourceEventCleanupRolledBack, actor, data)
+	rollback.TaskID = input.TaskID
+	events := []*entity.ResourceEvent{rollback}
+
+	if result.Success {
+		from := resource.Status
+		if input.Recovery.Kind == service.RecoveryUnquarantine {
+			resource.Restore()
+		}
+		if input.Recovery.Kind != service.RecoveryRestoreSnapshot || resource.Type.IsManagedDatabase() {
+			resource.MarkAsActive()
+		}
+		if err := uc.resourceRepo.Update(ctx, resource); err != nil {
+			return result, fmt.Errorf("failed to update resource %s: %w", resource.ID, err)
+		}
+		if resource.Status != from {
+			events = append(events, entity.NewStatusChangedEvent(resource, from, actor))
+		}
+	}
+	if uc.events != nil {
+		uc.events.Record(ctx, events...)
+	}
+	return result, err
+}
@@ -2,58 +2,152 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
 	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
 	"github.com/cloudsweep/cloudsweep/internal/domain/service"
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 )
 
 // ScanResourcesUseCase handles resource scanning operations
 type ScanResourcesUseCase struct {
-	scanRepo       repository.ScanRepository
-	resourceRepo   repository.ResourceRepository
-	scannerFactory service.CloudScannerFactory
+	scanRepo          repository.ScanRepository
+	resourceRepo      repository.ResourceRepository
+	events            repository.ResourceEventRepository
+	uow               repository.UnitOfWork
+	scannerFactory    service.CloudScannerFactory
+	costs             *service.CostNormalizer
+	catalog           *service.CatalogEstimator
+	carbon            *service.CarbonEstimator
+	iacStates         service.IaCStateSource
+	customTypes       repository.CustomResourceTypeRepository
+	regionConcurrency int
+	fullScanInterval  time.Duration
 }
 
-// NewScanResourcesUseCase creates a new ScanResourcesUseCase
+// NewScanResourcesUseCase creates a new ScanResourcesUseCase. Up to
+// regionConcurrency regions of a scan are scanned at the same time. iacStates
+// may be nil, in which case only tags flag resources managed by
+// infrastructure-as-code. catalog prices the resources scanners cannot
+// estimate, e.g. when the pricing API of their provider is unavailable; it
+// may be nil. customTypes, which may be nil, holds the resource types
+// organizations define, scanned along with the built-in ones when the
+// scanner factory supports them. The resources of a scan, the events of
+// their timelines and its completion are saved in a single unit of work;
+// events may be nil, in which case no timeline is recorded. Incremental scans run in full when the
+// last full scan of their scope is older than fullScanInterval; zero never
+// forces them.
 func NewScanResourcesUseCase(
 	scanRepo repository.ScanRepository,
 	resourceRepo repository.ResourceRepository,
+	events repository.ResourceEventRepository,
+	uow repository.UnitOfWork,
 	scannerFactory service.CloudScannerFactory,
+	costs *service.CostNormalizer,
+	catalog *service.CatalogEstimator,
+	carbon *service.CarbonEstimator,
+	iacStates service.IaCStateSource,
+	customTypes repository.CustomResourceTypeRepository,
+	regionConcurrency int,
+	fullScanInterval time.Duration,
 ) *ScanResourcesUseCase {
+	if regionConcurrency < 1 {
+		regionConcurrency = 1
+	}
 	return &ScanResourcesUseCase{
-		scanRepo:       scanRepo,
-		resourceRepo:   resourceRepo,
-		scannerFactory: scannerFactory,
+		scanRepo:          scanRepo,
+		resourceRepo:      resourceRepo,
+		events:            events,
+		uow:               uow,
+		scannerFactory:    scannerFactory,
+		costs:             costs,
+		catalog:           catalog,
+		carbon:            carbon,
+		iacStates:         iacStates,
+		customTypes:       customTypes,
+		regionConcurrency: regionConcurrency,
+		fullScanInterval:  fullScanInterval,
 	}
 }
 
+// ErrScanFinished is returned when running a scan that already completed,
+// failed or was cancelled, e.g. a task delivered twice or a pending scan
+// given up on before a worker picked it up
+var ErrScanFinished = errors.New("scan already finished")
+
 // ScanResourcesInput represents input for scanning resources
 type ScanResourcesInput struct {
-	OrganizationID uuid.UUID
-	Provider       entity.CloudProvider
-	Regions        []string
-	ResourceTypes  []entity.ResourceType
-	Credentials    []byte
+	// ScanID is the scan to run, created when it was requested. Its
+	// organization, provider, regions and resource types are scanned.
+	// Incremental scans only refresh the resources the provider reports as
+	// changed since the last completed scan of the same scope, in the
+	// regions whose scanner can tell.
+	ScanID      uuid.UUID
+	Credentials []byte
+	Plan        string // plan of the organization, whose quotas apply
+
+	// Progress, which may be nil, reports the scanned resources saved so
+	// far, so that the progress of large scans covers their persistence
+	Progress repository.ProgressFunc
 }
 
 // ScanResourcesOutput represents output from scanning resources
 type ScanResourcesOutput struct {
 	ScanID           uuid.UUID
+	Mode             entity.ScanMode
 	ResourcesFound   int
 	UnusedFound      int
+	ResourcesNew     int
+	ResourcesChanged int
+	ResourcesRemoved int
+	FailedRegions    map[string]string
 	EstimatedSavings float64
 	CarbonSavings    float64
 }
 
-// Execute executes the scan resources use case
+// scanBatchSize is the number of scanned resources enriched and saved at
+// once
+const scanBatchSize = 500
+
+// Execute executes the scan resources use case, running the scan and
+// updating its row with the outcome. Resources flow through a pipeline as
+// scanners find them: regions are scanned concurrently and the resources
+// found are enriched (unused detection, costs, carbon footprint,
+// reconciliation with the inventory) by batch while the next ones are
+// scanned. Once every region is scanned, the batches, the resources gone
+// since the last scan and the completion of the scan are saved in a single
+// unit of work, so that a failure, an exceeded quota included, saves none
+// of them. The unit of work is only opened for these writes, not while
+// providers are called.
 func (uc *ScanResourcesUseCase) Execute(ctx context.Context, input ScanResourcesInput) (*ScanResourcesOutput, error) {
-	// Create scan record
-	scan := entity.NewScan(input.OrganizationID, input.Provider, input.Regions, input.ResourceTypes)
-	if err := uc.scanRepo.Create(ctx, scan); err != nil {
-		return nil, fmt.Errorf("failed to create scan: %w", err)
+	// The scan counted against the scans of the day of its organization
+	// when it was requested
+	scan, err := uc.scanRepo.GetByID(ctx, input.ScanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scan: %w", err)
+	}
+	switch scan.Status {
+	case entity.ScanStatusCompleted, entity.ScanStatusPartial, entity.ScanStatusFailed, entity.ScanStatusCancelled:
+		return nil, fmt.Errorf("%w: scan %s is %s", ErrScanFinished, scan.ID, scan.Status)
+	}
+	if scan.Mode == entity.ScanModeIncremental {
+		since, err := uc.changesSince(ctx, scan)
+		if err != nil {
+			return nil, err
+		}
+		if since.IsZero() {
+			scan.RunFully()
+		} else {
+			scan.RunIncrementally(since)
+		}
 	}
 
 	// Start scan
@@ -63,67 +157,691 @@ func (uc *ScanResourcesUseCase) Execute(ctx context.Context, input ScanResources
 	}
 
 	// Create scanner
-	scanner, err := uc.scannerFactory.Create(input.Provider, input.Credentials)
+	scanner, err := uc.createScanner(ctx, scan, input.Credentials)
 	if err != nil {
 		scan.Fail(err.Error())
 		uc.scanRepo.Update(ctx, scan)
 		return nil, fmt.Errorf("failed to create scanner: %w", err)
 	}
 
-	// Scan resources
-	resources, err := scanner.ScanResources(ctx, input.Regions, input.ResourceTypes)
+	// Load the resources known from previous scans. Only those of the
+	// regions scanned successfully are reported as removed when missing.
+	existing, err := uc.resourceRepo.ListByScope(ctx, scan.OrganizationID, scan.Provider, scan.Regions, scan.ResourceTypes)
 	if err != nil {
 		scan.Fail(err.Error())
 		uc.scanRepo.Update(ctx, scan)
-		return nil, fmt.Errorf("failed to scan resources: %w", err)
+		return nil, fmt.Errorf("failed to load existing resources: %w", err)
 	}
-
-	// Set organization ID for all resources
-	for _, r := range resources {
-		r.OrganizationID = input.OrganizationID
+	quota, err := uc.resourceQuota(ctx, scan.OrganizationID, input.Plan, existing)
+	if err != nil {
+		scan.Fail(err.Error())
+		uc.scanRepo.Update(ctx, scan)
+		return nil, err
+	}
+	p := &scanPipeline{
+		uc:       uc,
+		input:    input,
+		scan:     scan,
+		scanner:  scanner,
+		declared: uc.iacDeclarations(ctx, scan.OrganizationID, existing),
+		rec:      newReconciler(existing),
+		quota:    quota,
+		seenAt:   time.Now(),
+	}
+	if scan.ChangesSince != nil {
+		p.since = *scan.ChangesSince
 	}
 
-	// Detect unused resources
-	if err := scanner.DetectUnused(ctx, resources); err != nil {
+	version := scan.Version
+	removed, err := p.reconcile(ctx)
+	if err == nil {
+		err = uc.uow.Do(ctx, func(ctx context.Context) error {
+			return uc.save(ctx, scan, p, removed)
+		})
+	}
+	if err != nil {
+		// The completion was rolled back with the resources
+		scan.Version = version
+		scan.FailedRegions = p.failed
 		scan.Fail(err.Error())
 		uc.scanRepo.Update(ctx, scan)
+		return nil, err
+	}
+
+	return &ScanResourcesOutput{
+		ScanID:           scan.ID,
+		Mode:             scan.Mode,
+		ResourcesFound:   p.found,
+		UnusedFound:      p.unused,
+		ResourcesNew:     p.rec.newCount,
+		ResourcesChanged: p.rec.changedCount,
+		ResourcesRemoved: len(removed),
+		FailedRegions:    p.failed,
+		EstimatedSavings: p.savings,
+		CarbonSavings:    p.carbon,
+	}, nil
+}
+
+// save saves the resources of a scan, marks the removed ones as deleted and
+// completes the scan
+func (uc *ScanResourcesUseCase) save(ctx context.Context, scan *entity.Scan, p *scanPipeline, removed []*entity.Resource) error {
+	if err := p.save(ctx); err != nil {
+		return err
+	}
+	if len(removed) > 0 {
+		if err := uc.resourceRepo.BulkUpdate(ctx, removed); err != nil {
+			return fmt.Errorf("failed to mark removed resources: %w", err)
+		}
+		if err := uc.recordEvents(ctx, scan.ID, p.rec.removals); err != nil {
+			return err
+		}
+	}
+
+	if len(p.incremental) == 0 {
+		// Every region fell back to a full scan
+		scan.RunFully()
+	}
+	scan.RecordChanges(p.rec.newCount, p.rec.changedCount, len(removed))
+	if len(p.failed) > 0 {
+		scan.CompletePartially(p.found, p.unused, p.savings, p.carbon, p.failed)
+	} else {
+		scan.Complete(p.found, p.unused, p.savings, p.carbon)
+	}
+	if err := uc.scanRepo.Update(ctx, scan); err != nil {
+		return fmt.Errorf("failed to complete scan: %w", err)
+	}
+	return nil
+}
+
+// scanPipeline streams the resources of a scan from its scanner through
+// their enrichment, keeping the enriched batches until they are saved
+type scanPipeline struct {
+	uc       *ScanResourcesUseCase
+	input    ScanResourcesInput
+	scan     *entity.Scan // scope of the scan, read only while it runs
+	scanner  service.CloudScanner
+	declared map[string]service.IaCDeclaration
+	rec      *reconciler
+	quota    *resourceQuota
+	seenAt   time.Time
+	since    time.Time // of the changes scanned, zero for a full scan
+
+	// Outcome, set once run returns
+	mu              sync.Mutex
+	batches         []scanBatch
+	scanned         []string
+	failed          map[string]string
+	incremental     []string // regions scanned for their changes only
+	deleted         []string // resources reported deleted in them
+	found, unused   int
+	savings, carbon float64
+}
+
+// scanBatch is a batch of enriched resources, with the known resources
+// they replace, nil for new ones, and the number of resources found when it
+// was sent
+type scanBatch struct {
+	resources []*entity.Resource
+	previous  []*entity.Resource
+	found     int
+}
+
+// run scans and enriches the resources in two concurrent stages. The first
+// failing stage stops the other.
+func (p *scanPipeline) run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	found := make(chan *entity.Resource, scanBatchSize)
+
+	g.Go(func() error {
+		defer close(found)
+		p.scanned, p.failed = p.uc.scanRegions(ctx, p.scan.Regions, func(region string) error {
+			return p.scanRegion(ctx, region, found)
+		})
+		return nil
+	})
+	g.Go(func() error {
+		return p.enrich(ctx, found)
+	})
+	return g.Wait()
+}
+
+// reconcile runs the pipeline and returns the known resources the scan
+// found gone. It fails when every region failed, or when the resources of
+// the scan exceed the quota of the plan.
+func (p *scanPipeline) reconcile(ctx context.Context) ([]*entity.Resource, error) {
+	if err := p.run(ctx); err != nil {
+		return nil, err
+	}
+	if len(p.scanned) == 0 {
+		return nil, fmt.Errorf("failed to scan resources: all %d regions failed", len(p.failed))
+	}
+	// Regions scanned incrementally only lose the resources reported
+	// deleted, and keep the others
+	full := slices.DeleteFunc(slices.Clone(p.scanned), func(region string) bool {
+		return slices.Contains(p.incremental, region)
+	})
+	removed := p.rec.missing(full)
+	removed = append(removed, p.rec.deleted(p.incremental, p.deleted)...)
+	kept := p.keepUnchanged()
+	if err := p.quota.check(p.rec.trackedOutside(p.scanned) + kept); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// scanRegion sends the resources of a region to emit. Incremental scans
+// only send the resources changed since the scan they follow, unless the
+// scanner cannot tell them, in which case the region is scanned in full.
+func (p *scanPipeline) scanRegion(ctx context.Context, region string, emit chan<- *entity.Resource) error {
+	if scanner, ok := p.scanner.(service.IncrementalScanner); ok && !p.since.IsZero() {
+		changed, deleted, err := scanner.ScanChanges(ctx, region, p.scan.ResourceTypes, p.since)
+		switch {
+		case err == nil:
+			if err := service.Emit(ctx, emit, changed); err != nil {
+				return err
+			}
+			p.mu.Lock()
+			p.incremental = append(p.incremental, region)
+			p.deleted = append(p.deleted, deleted...)
+			p.mu.Unlock()
+			return nil
+		case !errors.Is(err, service.ErrChangesUnavailable):
+			return err
+		}
+	}
+	return service.StreamRegion(ctx, p.scanner, region, p.scan.ResourceTypes, emit)
+}
+
+// keepUnchanged counts the known resources of the regions scanned
+// incrementally that did not change in the totals of the scan, as a full
+// scan would have found them. It returns the number of them that are
+// tracked.
+func (p *scanPipeline) keepUnchanged() int {
+	tracked := 0
+	for _, r := range p.rec.unchanged(p.incremental) {
+		p.found++
+		if r.IsUnused() {
+			p.unused++
+			p.savings += r.MonthlyCost
+			p.carbon += r.CarbonFootprint
+		}
+		if r.Status.IsTracked() {
+			tracked++
+		}
+	}
+	return tracked
+}
+
+// enrich batches the resources found and enriches each batch, keeping it to
+// be saved
+func (p *scanPipeline) enrich(ctx context.Context, found <-chan *entity.Resource) error {
+	batch := make([]*entity.Resource, 0, scanBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		previous, err := p.enrichBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		p.batches = append(p.batches, scanBatch{resources: batch, previous: previous, found: p.found})
+		batch = make([]*entity.Resource, 0, scanBatchSize)
+		return nil
+	}
+
+	for r := range found {
+		batch = append(batch, r)
+		if len(batch) == scanBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// enrichBatch detects the unused resources of a batch, estimates their
+// costs and carbon footprint, flags those managed by infrastructure-as-code
+// and reconciles them with the inventory. It returns the known resources
+// the batch replaces.
+func (p *scanPipeline) enrichBatch(ctx context.Context, batch []*entity.Resource) ([]*entity.Resource, error) {
+	for _, r := range batch {
+		r.OrganizationID = p.scan.OrganizationID
+	}
+
+	if err := p.scanner.DetectUnused(ctx, batch); err != nil {
 		return nil, fmt.Errorf("failed to detect unused resources: %w", err)
 	}
 
-	// Calculate costs and carbon footprint
-	var totalSavings, totalCarbon float64
-	unusedCount := 0
-	for _, r := range resources {
-		cost, _ := scanner.EstimateCost(ctx, r)
-		carbon, _ := scanner.EstimateCarbonFootprint(ctx, r)
-		r.MonthlyCost = cost
-		r.CarbonFootprint = carbon
+	// Costs are normalized to monthly USD and footprints estimated with the
+	// same model for every provider so they can be summed across providers
+	// and regions
+	for _, r := range batch {
+		r.MonthlyCost = p.uc.monthlyCost(ctx, p.scanner, r)
+		r.CarbonFootprint = p.uc.carbon.Estimate(r)
+	}
+
+	// Flag resources managed by infrastructure-as-code so policies leave them
+	// to their tool
+	service.DetectIaCOwnership(batch, p.declared)
+	previous := p.rec.match(batch, p.seenAt)
 
+	// Savings use the billed costs reconciliation kept over the estimates
+	p.found += len(batch)
+	for _, r := range batch {
 		if r.IsUnused() {
-			unusedCount++
-			totalSavings += cost
-			totalCarbon += carbon
+			p.unused++
+			p.savings += r.MonthlyCost
+			p.carbon += r.CarbonFootprint
 		}
 	}
+	return previous, p.quota.add(batch)
+}
 
-	// Save resources
-	if err := uc.resourceRepo.BulkCreate(ctx, resources); err != nil {
-		scan.Fail(err.Error())
-		uc.scanRepo.Update(ctx, scan)
-		return nil, fmt.Errorf("failed to save resources: %w", err)
+// save saves the enriched batches and the events of their resources,
+// reporting the progress of the scan
+func (p *scanPipeline) save(ctx context.Context) error {
+	saved := 0
+	for _, batch := range p.batches {
+		if err := p.uc.resourceRepo.BulkUpsert(ctx, batch.resources, nil); err != nil {
+			return fmt.Errorf("failed to save resources: %w", err)
+		}
+		// New resources have their ID once saved
+		if err := p.uc.recordEvents(ctx, p.scan.ID, scanEvents(batch)); err != nil {
+			return err
+		}
+		saved += len(batch.resources)
+		if p.input.Progress != nil {
+			p.input.Progress(saved, batch.found)
+		}
 	}
+	return nil
+}
 
-	// Complete scan
-	scan.Complete(len(resources), unusedCount, totalSavings, totalCarbon)
-	if err := uc.scanRepo.Update(ctx, scan); err != nil {
-		return nil, fmt.Errorf("failed to complete scan: %w", err)
+// resourceQuota checks that the scanned resources do not track more
+// resources than the plan allows. The resources of the scope are replaced
+// by the ones scanned. A nil resourceQuota allows any number of resources.
+type resourceQuota struct {
+	plan    string
+	outside int64           // tracked resources outside the scope
+	removed map[string]bool // resources of the scope removed from the inventory
+	adding  int
+}
+
+// resourceQuota returns the quota of the scan, or nil when the plan does not
+// limit resources
+func (uc *ScanResourcesUseCase) resourceQuota(ctx context.Context, orgID uuid.UUID, plan string, existing []*entity.Resource) (*resourceQuota, error) {
+	if entity.QuotasFor(plan).Resources == 0 {
+		return nil, nil
 	}
+	tracked, err := uc.resourceRepo.CountTracked(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tracked resources: %w", err)
+	}
+	q := &resourceQuota{plan: plan, outside: tracked, removed: make(map[string]bool)}
+	for _, r := range existing {
+		switch {
+		case r.Status == entity.ResourceStatusRemoved:
+			// Removed resources scanned again stay out of the inventory
+			q.removed[r.ResourceID] = true
+		case r.Status.IsTracked():
+			q.outside--
+		}
+	}
+	return q, nil
+}
 
-	return &ScanResourcesOutput{
-		ScanID:           scan.ID,
-		ResourcesFound:   len(resources),
-		UnusedFound:      unusedCount,
-		EstimatedSavings: totalSavings,
-		CarbonSavings:    totalCarbon,
-	}, nil
+// add counts a batch of scanned resources. It fails as soon as the
+// resources scanned so far exceed the quota, without waiting for the end of
+// the scan.
+func (q *resourceQuota) add(batch []*entity.Resource) error {
+	if q == nil {
+		return nil
+	}
+	for _, r := range batch {
+		if !q.removed[r.ResourceID] {
+			q.adding++
+		}
+	}
+	return q.check(0)
+}
+
+// check returns a QuotaExceededError when the resources scanned, with
+// kept tracked resources of the scope that were not scanned, exceed the
+// quota
+func (q *resourceQuota) check(kept int) error {
+	if q == nil {
+		return nil
+	}
+	return entity.CheckQuota(q.plan, entity.QuotaResources, q.outside+int64(kept), q.adding)
+}
+
+// monthlyCost estimates the cost of a resource in monthly USD and records
+// the conversion details in its metadata. Resources the scanner cannot price
+// fall back to the price catalog. Costs that cannot be estimated or
+// converted are left at zero, with the reason in metadata.
+func (uc *ScanResourcesUseCase) monthlyCost(ctx context.Context, scanner service.CloudScanner, r *entity.Resource) float64 {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]any)
+	}
+
+	cost, err := scanner.EstimateCost(ctx, r)
+	fromCatalog := false
+	if err != nil && uc.catalog != nil {
+		cost, err = uc.catalog.EstimateCost(r)
+		fromCatalog = err == nil
+	}
+	if err != nil {
+		return 0
+	}
+	normalized, err := uc.costs.Normalize(cost)
+	if err != nil {
+		r.Metadata[service.CostMetadataKey] = map[string]any{
+			"amount":   cost.Amount,
+			"currency": cost.Currency,
+			"period":   string(cost.Period),
+			"error":    err.Error(),
+		}
+		return 0
+	}
+	metadata := normalized.Metadata()
+	if fromCatalog {
+		metadata["estimated_by"] = "catalog"
+	}
+	r.Metadata[service.CostMetadataKey] = metadata
+	return normalized.MonthlyUSD
+}
+
+// createScanner creates the scanner of the provider, detecting the custom
+// resource types of the organization too when there are any
+func (uc *ScanResourcesUseCase) createScanner(ctx context.Context, scan *entity.Scan, credentials []byte) (service.CloudScanner, error) {
+	factory, ok := uc.scannerFactory.(service.CustomScannerFactory)
+	if !ok || uc.customTypes == nil {
+		return uc.scannerFactory.Create(scan.Provider, credentials)
+	}
+	types, err := uc.customTypes.ListByProvider(ctx, scan.OrganizationID, scan.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom resource types: %w", err)
+	}
+	if len(types) == 0 {
+		return uc.scannerFactory.Create(scan.Provider, credentials)
+	}
+	return factory.CreateWithCustomTypes(scan.Provider, credentials, types)
+}
+
+// iacDeclarations returns the resources declared in the organization's state
+// files. When they cannot be read, resources found in them by the previous
+// scan stay flagged rather than becoming candidates for deletion.
+func (uc *ScanResourcesUseCase) iacDeclarations(ctx context.Context, orgID uuid.UUID, existing []*entity.Resource) map[string]service.IaCDeclaration {
+	if uc.iacStates == nil {
+		return nil
+	}
+	declared, err := uc.iacStates.ManagedResources(ctx, orgID)
+	if err == nil {
+		return declared
+	}
+
+	declared = make(map[string]service.IaCDeclaration)
+	for _, r := range existing {
+		state, _ := r.Metadata[service.IaCMetadataState].(string)
+		if !r.IaCManaged || state == "" {
+			continue
+		}
+		address, _ := r.Metadata[service.IaCMetadataAddress].(string)
+		declared[r.ResourceID] = service.IaCDeclaration{Tool: r.IaCTool, State: state, Address: address}
+	}
+	return declared
+}
+
+// scanRegions scans regions concurrently with scan, with at most
+// regionConcurrency in flight. A failing region does not stop the others;
+// its error is reported in failed, keyed by region. Resources it emitted
+// before failing are kept.
+func (uc *ScanResourcesUseCase) scanRegions(
+	ctx context.Context,
+	regions []string,
+	scan func(region string) error,
+) (scanned []string, failed map[string]string) {
+	var (
+		mu sync.Mutex
+		g  errgroup.Group
+	)
+	g.SetLimit(uc.regionConcurrency)
+
+	for _, region := range regions {
+		region := region
+		g.Go(func() error {
+			err := scan(region)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if failed == nil {
+					failed = make(map[string]string)
+				}
+				failed[region] = err.Error()
+				return nil
+			}
+			scanned = append(scanned, region)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return scanned, failed
+}
+
+// reconciler compares the resources of a scan with the known inventory, as
+// batches of the scan come
+type reconciler struct {
+	known        map[string]*entity.Resource // existing resources not scanned yet
+	newCount     int
+	changedCount int
+	removals     []*entity.ResourceEvent // of the resources marked as deleted
+}
+
+func newReconciler(existing []*entity.Resource) *reconciler {
+	known := make(map[string]*entity.Resource, len(existing))
+	for _, r := range existing {
+		known[r.ResourceID] = r
+	}
+	return &reconciler{known: known}
+}
+
+// match matches scanned resources with existing ones by cloud resource ID.
+// Matched resources keep their ID, creation date, manual exclusion or
+// removal from the inventory and billed cost. It returns the existing
+// resource each scanned one replaces, nil for new ones.
+func (rec *reconciler) match(scanned []*entity.Resource, seenAt time.Time) []*entity.Resource {
+	previous := make([]*entity.Resource, len(scanned))
+	for i, r := range scanned {
+		r.LastSeenAt = seenAt
+		r.UpdatedAt = seenAt
+
+		old, ok := rec.known[r.ResourceID]
+		if !ok {
+			rec.newCount++
+			continue
+		}
+		delete(rec.known, r.ResourceID)
+		previous[i] = old
+
+		r.ID = old.ID
+		r.CreatedAt = old.CreatedAt
+		if old.Status == entity.ResourceStatusExcluded || old.Status == entity.ResourceStatusRemoved {
+			r.Status = old.Status
+		}
+		// Costs reconciled with the billing data of the account are more
+		// accurate than the estimate until the next reconciliation
+		if cost, ok := old.Metadata[service.CostMetadataKey].(map[string]any); ok && cost["source"] != nil && cost["source"] != "" {
+			r.MonthlyCost = old.MonthlyCost
+			if r.Metadata == nil {
+				r.Metadata = make(map[string]any)
+			}
+			r.Metadata[service.CostMetadataKey] = cost
+		}
+
+		switch {
+		case old.Status == entity.ResourceStatusDeleted:
+			// Reappeared after being reported gone
+			rec.newCount++
+		case resourceChanged(old, r):
+			rec.changedCount++
+		}
+	}
+	return previous
+}
+
+// missing marks the tracked resources of the scanned regions that the scan
+// did not find as deleted, and returns them
+func (rec *reconciler) missing(scannedRegions []string) []*entity.Resource {
+	var removed []*entity.Resource
+	for _, r := range rec.known {
+		if !r.Status.IsTracked() || !slices.Contains(scannedRegions, r.Region) {
+			continue
+		}
+		rec.markDeleted(r)
+		removed = append(removed, r)
+	}
+	return removed
+}
+
+// deleted marks the tracked resources of regions that a feed reported
+// deleted as such, and returns them
+func (rec *reconciler) deleted(regions []string, ids []string) []*entity.Resource {
+	if len(ids) == 0 {
+		return nil
+	}
+	gone := service.NewResourceIDs(ids)
+	var removed []*entity.Resource
+	for _, r := range rec.known {
+		if !r.Status.IsTracked() || !slices.Contains(regions, r.Region) || !gone.Has(r.ResourceID) {
+			continue
+		}
+		rec.markDeleted(r)
+		removed = append(removed, r)
+	}
+	return removed
+}
+
+// markDeleted marks a known resource as deleted, recording the change of
+// its status
+func (rec *reconciler) markDeleted(r *entity.Resource) {
+	from := r.Status
+	r.MarkAsDeleted()
+	if r.Status != from {
+		rec.removals = append(rec.removals, entity.NewStatusChangedEvent(r, from, entity.ResourceEventActorScan))
+	}
+}
+
+// unchanged returns the resources of regions that were not scanned again
+// and are still in the cloud, as far as the inventory knows
+func (rec *reconciler) unchanged(regions []string) []*entity.Resource {
+	var out []*entity.Resource
+	for _, r := range rec.known {
+		if r.Status != entity.ResourceStatusDeleted && slices.Contains(regions, r.Region) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// trackedOutside counts the tracked resources of the regions that failed,
+// which stay in the inventory since the scan could not tell whether they
+// are gone
+func (rec *reconciler) trackedOutside(scannedRegions []string) int {
+	n := 0
+	for _, r := range rec.known {
+		if r.Status.IsTracked() && !slices.Contains(scannedRegions, r.Region) {
+			n++
+		}
+	}
+	return n
+}
+
+// changesSince returns the start of the last completed scan of the scope of
+// an incremental scan, whose changes it scans. It returns the zero time
+// when the scan has to run in full: the scope was never scanned in full, or
+// not for fullScanInterval.
+func (uc *ScanResourcesUseCase) changesSince(ctx context.Context, scan *entity.Scan) (time.Time, error) {
+	lastFull, err := uc.scanRepo.LastCompleted(ctx, scan.Fingerprint, entity.ScanModeFull)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find the last full scan: %w", err)
+	}
+	if lastFull == nil || lastFull.StartedAt == nil {
+		return time.Time{}, nil
+	}
+	if uc.fullScanInterval > 0 && time.Since(*lastFull.StartedAt) >= uc.fullScanInterval {
+		return time.Time{}, nil
+	}
+
+	last, err := uc.scanRepo.LastCompleted(ctx, scan.Fingerprint, "")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find the last scan: %w", err)
+	}
+	if last == nil || last.StartedAt == nil {
+		return *lastFull.StartedAt, nil
+	}
+	return *last.StartedAt, nil
+}
+
+// resourceChanged reports whether a rescan changed anything users act on
+func resourceChanged(old, current *entity.Resource) bool {
+	return old.Name != current.Name ||
+		old.Status != current.Status ||
+		old.Type != current.Type ||
+		old.Region != current.Region ||
+		old.MonthlyCost != current.MonthlyCost ||
+		old.CarbonFootprint != current.CarbonFootprint ||
+		old.IaCManaged != current.IaCManaged ||
+		!maps.Equal(old.Tags, current.Tags)
+}
+
+// scanEvents returns the events of the timelines of a saved batch: every
+// resource was scanned, and new ones discovered; known ones record the
+// changes of their status and cost
+func scanEvents(batch scanBatch) []*entity.ResourceEvent {
+	events := make([]*entity.ResourceEvent, 0, len(batch.resources))
+	for i, r := range batch.resources {
+		old := batch.previous[i]
+		if old == nil {
+			events = append(events, entity.NewResourceEvent(r.OrganizationID, r.ID, entity.ResourceEventDiscovered, entity.ResourceEventActorScan, map[string]any{
+				"status":       string(r.Status),
+				"monthly_cost": r.MonthlyCost,
+			}))
+			continue
+		}
+		events = append(events, entity.NewResourceEvent(r.OrganizationID, r.ID, entity.ResourceEventScanned, entity.ResourceEventActorScan, nil))
+		if old.Status != r.Status {
+			events = append(events, entity.NewStatusChangedEvent(r, old.Status, entity.ResourceEventActorScan))
+		}
+		if costChanged(old.MonthlyCost, r.MonthlyCost) {
+			events = append(events, entity.NewCostChangedEvent(r, old.MonthlyCost, entity.ResourceEventActorScan))
+		}
+	}
+	return events
+}
+
+// costChanged reports whether a monthly cost changed to the cent, the
+// precision costs are stored with
+func costChanged(from, to float64) bool {
+	return math.Round(from*100) != math.Round(to*100)
+}
+
+// recordEvents records the events of a scan in the timelines of their
+// resources
+func (uc *ScanResourcesUseCase) recordEvents(ctx context.Context, scanID uuid.UUID, events []*entity.ResourceEvent) error {
+	if uc.events == nil || len(events) == 0 {
+		return nil
+	}
+	for _, e := range events {
+		e.ScanID = &scanID
+	}
+	if err := uc.events.Record(ctx, events...); err != nil {
+		return fmt.Errorf("failed to record resource events: %w", err)
+	}
+	return nil
 }
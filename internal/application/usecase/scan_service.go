@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// ScanService creates and reads the scans requested through the API. Its
+// errors carry the code and message to return to clients, except plan
+// quota errors, which are *entity.QuotaExceededError.
+type ScanService interface {
+	// Create queues a scan, or returns the identical scan already pending
+	// or running unless input.Force is set
+	Create(ctx context.Context, input CreateScanInput) (*CreateScanOutput, error)
+
+	// Get retrieves a scan by ID
+	Get(ctx context.Context, id uuid.UUID) (*entity.Scan, error)
+
+	// List retrieves a page of scans and the number of scans matching the
+	// filter
+	List(ctx context.Context, filter repository.ScanFilter) ([]*entity.Scan, int64, error)
+}
+
+// ScanUseCase implements ScanService
+type ScanUseCase struct {
+	scanRepo repository.ScanRepository
+	orgRepo  repository.OrganizationRepository
+	queue    service.ScanQueue
+}
+
+// NewScanUseCase creates a new ScanUseCase
+func NewScanUseCase(
+	scanRepo repository.ScanRepository,
+	orgRepo repository.OrganizationRepository,
+	queue service.ScanQueue,
+) *ScanUseCase {
+	return &ScanUseCase{
+		scanRepo: scanRepo,
+		orgRepo:  orgRepo,
+		queue:    queue,
+	}
+}
+
+var _ ScanService = (*ScanUseCase)(nil)
+
+// CreateScanInput represents input for creating a scan
+type CreateScanInput struct {
+	OrganizationID uuid.UUID
+	Provider       entity.CloudProvider
+	// Regions default to those of the organization settings
+	Regions       []string
+	ResourceTypes []entity.ResourceType
+	// Force queues a new scan even if an identical one is in progress
+	Force bool
+	// Scheduled is set for scans run on a policy schedule rather than
+	// requested by a user, which are queued at a lower priority
+	Scheduled bool
+	// Incremental requests a scan of the resources changed since the last
+	// scan; the worker runs it in full when it cannot be incremental
+	Incremental bool
+}
+
+// CreateScanOutput represents output from creating a scan
+type CreateScanOutput struct {
+	Scan *entity.Scan
+	// Existing is true when Scan is an identical scan already in progress
+	// rather than a new one
+	Existing bool
+}
+
+// Create implements ScanService
+func (uc *ScanUseCase) Create(ctx context.Context, input CreateScanInput) (*CreateScanOutput, error) {
+	org, err := uc.orgRepo.GetByID(ctx, input.OrganizationID)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "organization not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch organization settings")
+	}
+	if !org.IsActive {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "organization is deactivated")
+	}
+
+	regions := input.Regions
+	if len(regions) == 0 {
+		if len(org.Settings.DefaultRegions) == 0 {
+			return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput,
+				"regions are required: the organization has no default regions")
+		}
+		regions = org.Settings.DefaultRegions
+	}
+	if denied := org.Settings.DeniedRegions(regions); len(denied) > 0 {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput,
+			"regions are denylisted for this organization: "+strings.Join(denied, ", "))
+	}
+
+	scan := entity.NewScan(org.ID, input.Provider, regions, input.ResourceTypes)
+	if input.Incremental {
+		scan.Mode = entity.ScanModeIncremental
+	}
+
+	if !input.Force {
+		existing, err := uc.activeScan(ctx, scan.Fingerprint, uuid.Nil)
+		if err != nil {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to check for running scans")
+		}
+		if existing != nil {
+			return &CreateScanOutput{Scan: existing, Existing: true}, nil
+		}
+	}
+
+	if err := uc.checkQuotas(ctx, org); err != nil {
+		return nil, err
+	}
+
+	if err := uc.scanRepo.Create(ctx, scan); err != nil {
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to create scan")
+	}
+
+	err = uc.queue.Enqueue(ctx, scan, org.Plan, input.Scheduled, !input.Force)
+	if apperrors.Is(err, service.ErrScanAlreadyQueued) {
+		// Either a concurrent request won the race, or the queue still holds
+		// a dead task for this fingerprint after its retries ran out
+		existing, ferr := uc.activeScan(ctx, scan.Fingerprint, scan.ID)
+		if ferr == nil && existing != nil {
+			_ = uc.scanRepo.Delete(context.WithoutCancel(ctx), scan.ID)
+			return &CreateScanOutput{Scan: existing, Existing: true}, nil
+		}
+		err = uc.queue.Enqueue(ctx, scan, org.Plan, input.Scheduled, false)
+	}
+	if err != nil {
+		scan.Fail("failed to enqueue scan task")
+		_ = uc.scanRepo.Update(context.WithoutCancel(ctx), scan)
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to enqueue scan task")
+	}
+
+	return &CreateScanOutput{Scan: scan}, nil
+}
+
+// stalePendingScan is how long a scan can stay pending before it is
+// considered lost, e.g. with the Redis data holding its task. Identical
+// scans stop reusing it.
+const stalePendingScan = time.Hour
+
+// activeScan returns the most recent pending or running scan with the
+// given fingerprint, ignoring the scan with ID exclude, or nil when there is
+// none. A pending scan no worker picked up within stalePendingScan is marked
+// as failed rather than returned.
+func (uc *ScanUseCase) activeScan(ctx context.Context, fingerprint string, exclude uuid.UUID) (*entity.Scan, error) {
+	existing, err := uc.scanRepo.FindActive(ctx, fingerprint, exclude)
+	if err != nil || existing == nil {
+		return existing, err
+	}
+	if existing.Status != entity.ScanStatusPending || time.Since(existing.CreatedAt) < stalePendingScan {
+		return existing, nil
+	}
+
+	existing.Fail("never picked up by a worker")
+	err = uc.scanRepo.Update(ctx, existing)
+	if apperrors.Is(err, apperrors.ErrConflict) {
+		// A worker started it meanwhile
+		return uc.scanRepo.FindActive(ctx, fingerprint, exclude)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// checkQuotas returns a QuotaExceededError when an organization cannot
+// start another scan today, or is over the cloud accounts or resources of
+// its plan, e.g. after a downgrade
+func (uc *ScanUseCase) checkQuotas(ctx context.Context, org *entity.Organization) error {
+	usage, err := uc.orgRepo.Usage(ctx, org.ID, time.Now())
+	if err != nil {
+		return apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to check plan quotas")
+	}
+	if err := entity.CheckQuota(org.Plan, entity.QuotaScansPerDay, usage[entity.QuotaScansPerDay], 1); err != nil {
+		return err
+	}
+	if err := entity.CheckQuota(org.Plan, entity.QuotaCloudAccounts, usage[entity.QuotaCloudAccounts], 0); err != nil {
+		return err
+	}
+	return entity.CheckQuota(org.Plan, entity.QuotaResources, usage[entity.QuotaResources], 0)
+}
+
+// Get implements ScanService
+func (uc *ScanUseCase) Get(ctx context.Context, id uuid.UUID) (*entity.Scan, error) {
+	scan, err := uc.scanRepo.GetByID(ctx, id)
+	if err != nil {
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NewWithCode(err, apperrors.CodeNotFound, "scan not found")
+		}
+		return nil, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch scan")
+	}
+	return scan, nil
+}
+
+// List implements ScanService
+func (uc *ScanUseCase) List(ctx context.Context, filter repository.ScanFilter) ([]*entity.Scan, int64, error) {
+	total, err := uc.scanRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to count scans")
+	}
+	scans, err := uc.scanRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, apperrors.NewWithCode(err, apperrors.CodeInternal, "failed to fetch scans")
+	}
+	return scans, total, nil
+}
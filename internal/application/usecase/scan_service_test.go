@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+)
+
+// fakeScans is a ScanRepository keeping scans in memory
+type fakeScans struct {
+	repository.ScanRepository
+	scans map[uuid.UUID]*entity.Scan
+}
+
+func (f *fakeScans) Create(ctx context.Context, scan *entity.Scan) error {
+	f.scans[scan.ID] = scan
+	return nil
+}
+
+func (f *fakeScans) Update(ctx context.Context, scan *entity.Scan) error {
+	f.scans[scan.ID] = scan
+	return nil
+}
+
+func (f *fakeScans) FindActive(ctx context.Context, fingerprint string, exclude uuid.UUID) (*entity.Scan, error) {
+	var found *entity.Scan
+	for _, s := range f.scans {
+		active := s.Status == entity.ScanStatusPending || s.Status == entity.ScanStatusRunning
+		if s.Fingerprint != fingerprint || !active || s.ID == exclude {
+			continue
+		}
+		if found == nil || s.CreatedAt.After(found.CreatedAt) {
+			found = s
+		}
+	}
+	return found, nil
+}
+
+// fakeOrgs is an OrganizationRepository serving a single organization
+type fakeOrgs struct {
+	repository.OrganizationRepository
+	org *entity.Organization
+}
+
+func (f *fakeOrgs) GetByID(ctx context.Context, id uuid.UUID) (*entity.Organization, error) {
+	return f.org, nil
+}
+
+func (f *fakeOrgs) Usage(ctx context.Context, id uuid.UUID, now time.Time) (map[entity.Quota]int64, error) {
+	return map[entity.Quota]int64{}, nil
+}
+
+// fakeScanQueue records the scans queued
+type fakeScanQueue struct {
+	queued []*entity.Scan
+}
+
+func (f *fakeScanQueue) Enqueue(ctx context.Context, scan *entity.Scan, plan string, scheduled, dedupe bool) error {
+	f.queued = append(f.queued, scan)
+	return nil
+}
+
+func TestScanUseCaseCreateDedupe(t *testing.T) {
+	org := &entity.Organization{ID: uuid.New(), Plan: "enterprise", IsActive: true}
+	input := CreateScanInput{OrganizationID: org.ID, Provider: entity.CloudProviderAWS, Regions: []string{"eu-west-1"}}
+
+	tests := []struct {
+		name     string
+		status   entity.ScanStatus
+		age      time.Duration
+		existing bool
+	}{
+		{name: "pending scan waiting for a worker", status: entity.ScanStatusPending, age: time.Minute, existing: true},
+		{name: "running scan", status: entity.ScanStatusRunning, age: 3 * time.Hour, existing: true},
+		{name: "pending scan never picked up", status: entity.ScanStatusPending, age: 3 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := entity.NewScan(org.ID, input.Provider, input.Regions, nil)
+			previous.Status = tt.status
+			previous.CreatedAt = time.Now().Add(-tt.age)
+			scans := &fakeScans{scans: map[uuid.UUID]*entity.Scan{previous.ID: previous}}
+			queue := &fakeScanQueue{}
+			uc := NewScanUseCase(scans, &fakeOrgs{org: org}, queue)
+
+			out, err := uc.Create(context.Background(), input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.Existing != tt.existing {
+				t.Fatalf("existing = %v, want %v", out.Existing, tt.existing)
+			}
+			if tt.existing {
+				if out.Scan.ID != previous.ID || len(queue.queued) != 0 {
+					t.Errorf("scan = %s with %d queued, want %s reused", out.Scan.ID, len(queue.queued), previous.ID)
+				}
+				return
+			}
+			if out.Scan.ID == previous.ID || len(queue.queued) != 1 || queue.queued[0].ID != out.Scan.ID {
+				t.Errorf("scan = %s with %d queued, want a new scan queued", out.Scan.ID, len(queue.queued))
+			}
+			if previous.Status != entity.ScanStatusFailed {
+				t.Errorf("abandoned scan status = %s, want failed", previous.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CleanupSessionStatus represents the status of a guided cleanup session
+type CleanupSessionStatus string
+
+const (
+	CleanupSessionStatusOpen      CleanupSessionStatus = "open"
+	CleanupSessionStatusExecuted  CleanupSessionStatus = "executed"
+	CleanupSessionStatusCancelled CleanupSessionStatus = "cancelled"
+)
+
+// CleanupDecision is the review decision on a resource of a cleanup session
+type CleanupDecision string
+
+const (
+	CleanupDecisionPending  CleanupDecision = "pending"
+	CleanupDecisionAccepted CleanupDecision = "accepted"
+	CleanupDecisionRejected CleanupDecision = "rejected"
+)
+
+// CleanupSession is a guided review of flagged resources, executed as a
+// single cleanup once reviewed
+type CleanupSession struct {
+	ID             uuid.UUID            `json:"id"`
+	OrganizationID uuid.UUID            `json:"organization_id"`
+	Action         PolicyAction         `json:"action"`
+	Filters        map[string]string    `json:"filters"`
+	Status         CleanupSessionStatus `json:"status"`
+	CreatedBy      string               `json:"created_by"`
+	// TaskID is the cleanup task queued on execution
+	TaskID     string     `json:"task_id"`
+	DryRun     bool       `json:"dry_run"`
+	ExecutedAt *time.Time `json:"executed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CleanupSessionItem is a resource of a cleanup session with its decision
+type CleanupSessionItem struct {
+	SessionID  uuid.UUID       `json:"session_id"`
+	ResourceID uuid.UUID       `json:"resource_id"`
+	Position   int             `json:"position"`
+	Decision   CleanupDecision `json:"decision"`
+	DecidedBy  string          `json:"decided_by"`
+	DecidedAt  *time.Time      `json:"decided_at,omitempty"`
+	Resource   *Resource       `json:"resource,omitempty"`
+}
+
+// CleanupSessionTally totals the resources of a session with one decision
+type CleanupSessionTally struct {
+	Count           int     `json:"count"`
+	MonthlyCost     float64 `json:"monthly_cost"`
+	CarbonFootprint float64 `json:"carbon_footprint"`
+}
@@ -0,0 +1,243 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConditionOperator compares a resource field with a value
+type ConditionOperator string
+
+const (
+	OperatorEq     ConditionOperator = "eq"
+	OperatorNe     ConditionOperator = "ne"
+	OperatorGt     ConditionOperator = "gt"
+	OperatorGte    ConditionOperator = "gte"
+	OperatorLt     ConditionOperator = "lt"
+	OperatorLte    ConditionOperator = "lte"
+	OperatorIn     ConditionOperator = "in"
+	OperatorRegex  ConditionOperator = "regex"
+	OperatorExists ConditionOperator = "exists"
+)
+
+// Condition fields. Tags and metadata are addressed with a prefix, e.g.
+// "tags.env" or "metadata.attachment.state".
+const (
+	FieldMonthlyCost     = "monthly_cost"
+	FieldCarbonFootprint = "carbon_footprint"
+	FieldAge             = "age"       // time since the resource was first inventoried
+	FieldLastSeen        = "last_seen" // time since the resource was last scanned
+	FieldRegion          = "region"
+	FieldName            = "name"
+	FieldType            = "type"
+	FieldStatus          = "status"
+	FieldResourceID      = "resource_id"
+	FieldIaCManaged      = "iac_managed" // "true" or "false"
+	FieldIaCTool         = "iac_tool"
+	FieldTagPrefix       = "tags."
+	FieldMetadataPrefix  = "metadata."
+)
+
+// numericFields are compared as numbers; age fields are compared in days
+var numericFields = map[string]bool{
+	FieldMonthlyCost:     true,
+	FieldCarbonFootprint: true,
+	FieldAge:             true,
+	FieldLastSeen:        true,
+}
+
+var stringFields = map[string]bool{
+	FieldRegion:     true,
+	FieldName:       true,
+	FieldType:       true,
+	FieldStatus:     true,
+	FieldResourceID: true,
+	FieldIaCManaged: true,
+	FieldIaCTool:    true,
+}
+
+// ConditionNode is a node of a policy filter: either a group combining other
+// nodes with and, or or not, or a comparison of a resource field with a
+// value. For example, unused volumes older than 30 days outside production:
+//
+//	{"and": [
+//	  {"field": "status", "op": "eq", "value": "unused"},
+//	  {"field": "age", "op": "gt", "value": "30d"},
+//	  {"not": {"field": "tags.env", "op": "in", "value": ["prod", "production"]}}
+//	]}
+type ConditionNode struct {
+	And []ConditionNode `json:"and,omitempty"`
+	Or  []ConditionNode `json:"or,omitempty"`
+	Not *ConditionNode  `json:"not,omitempty"`
+
+	Field string            `json:"field,omitempty"`
+	Op    ConditionOperator `json:"op,omitempty"`
+	Value any               `json:"value,omitempty"`
+}
+
+// IsGroup returns true if the node combines other nodes
+func (n *ConditionNode) IsGroup() bool {
+	return n.And != nil || n.Or != nil || n.Not != nil
+}
+
+// Validate checks the filter rooted at n. Errors name the offending node by
+// its path, e.g. "filter.and[1].value".
+func (n *ConditionNode) Validate() error {
+	return n.validate("filter")
+}
+
+func (n *ConditionNode) validate(path string) error {
+	kinds := 0
+	for _, set := range []bool{n.And != nil, n.Or != nil, n.Not != nil, n.Field != "" || n.Op != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("%s: must have exactly one of and, or, not or a field comparison", path)
+	}
+
+	switch {
+	case n.And != nil:
+		return validateGroup(path+".and", n.And)
+	case n.Or != nil:
+		return validateGroup(path+".or", n.Or)
+	case n.Not != nil:
+		return n.Not.validate(path + ".not")
+	}
+
+	if !IsConditionField(n.Field) {
+		return fmt.Errorf("%s.field: unknown field %q", path, n.Field)
+	}
+	if err := validateComparison(n.Field, n.Op, n.Value); err != nil {
+		return fmt.Errorf("%s.%w", path, err)
+	}
+	return nil
+}
+
+func validateGroup(path string, nodes []ConditionNode) error {
+	if len(nodes) == 0 {
+		return fmt.Errorf("%s: must not be empty", path)
+	}
+	for i := range nodes {
+		if err := nodes[i].validate(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateComparison(field string, op ConditionOperator, value any) error {
+	numeric := numericFields[field]
+
+	switch op {
+	case OperatorExists:
+		if value != nil {
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("value: must be a boolean")
+			}
+		}
+		return nil
+	case OperatorEq, OperatorNe:
+		if value == nil {
+			return fmt.Errorf("value: is required")
+		}
+		if numeric {
+			_, err := ConditionNumber(field, value)
+			return err
+		}
+		return nil
+	case OperatorGt, OperatorGte, OperatorLt, OperatorLte:
+		if !numeric && !strings.HasPrefix(field, FieldMetadataPrefix) {
+			return fmt.Errorf("op: %s does not apply to field %q", op, field)
+		}
+		_, err := ConditionNumber(field, value)
+		return err
+	case OperatorIn:
+		list, ok := value.([]any)
+		if !ok || len(list) == 0 {
+			return fmt.Errorf("value: must be a non-empty list")
+		}
+		return nil
+	case OperatorRegex:
+		if numeric {
+			return fmt.Errorf("op: regex does not apply to field %q", field)
+		}
+		pattern, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("value: must be a string")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("value: invalid regular expression: %w", err)
+		}
+		return nil
+	case "":
+		return fmt.Errorf("op: is required")
+	}
+	return fmt.Errorf("op: unknown operator %q", op)
+}
+
+// IsConditionField returns true if field can be used in a comparison
+func IsConditionField(field string) bool {
+	if numericFields[field] || stringFields[field] {
+		return true
+	}
+	for _, prefix := range []string{FieldTagPrefix, FieldMetadataPrefix} {
+		if key, ok := strings.CutPrefix(field, prefix); ok && key != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNumericField returns true for fields compared as numbers
+func IsNumericField(field string) bool {
+	return numericFields[field]
+}
+
+// ConditionNumber converts a comparison value to a number. Age fields also
+// accept age expressions such as "30d", "2w" or "12h", converted to days.
+func ConditionNumber(field string, value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		if field == FieldAge || field == FieldLastSeen {
+			d, err := ParseAge(v)
+			if err != nil {
+				return 0, fmt.Errorf("value: %w", err)
+			}
+			return d.Hours() / 24, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value: must be a number")
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("value: must be a number")
+}
+
+// ParseAge parses an age expression: a number of days ("30d") or weeks
+// ("2w"), or a Go duration ("12h", "90m")
+func ParseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			f, err := strconv.ParseFloat(n, 64)
+			if err != nil || f < 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(f * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
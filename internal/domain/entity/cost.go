@@ -0,0 +1,66 @@
+package entity
+
+import "time"
+
+// BillingPeriod is the period a provider-reported price applies to
+type BillingPeriod string
+
+const (
+	BillingPeriodHourly  BillingPeriod = "hourly"
+	BillingPeriodDaily   BillingPeriod = "daily"
+	BillingPeriodMonthly BillingPeriod = "monthly"
+	BillingPeriodYearly  BillingPeriod = "yearly"
+)
+
+// HoursPerMonth is the average number of hours in a month used by cloud
+// providers to turn hourly prices into monthly ones
+const HoursPerMonth = 730
+
+// CurrencyUSD is the canonical currency costs are normalized to
+const CurrencyUSD = "USD"
+
+// Cost is a price as reported by a provider, in its own currency and period
+type Cost struct {
+	Amount   float64       `json:"amount"`
+	Currency string        `json:"currency"`
+	Period   BillingPeriod `json:"period"`
+}
+
+// MonthlyUSDCost returns a Cost already expressed in monthly USD
+func MonthlyUSDCost(amount float64) Cost {
+	return Cost{Amount: amount, Currency: CurrencyUSD, Period: BillingPeriodMonthly}
+}
+
+// Monthly returns the amount converted to a monthly figure, in the original
+// currency. ok is false for an unknown period.
+func (c Cost) Monthly() (amount float64, ok bool) {
+	switch c.Period {
+	case BillingPeriodHourly:
+		return c.Amount * HoursPerMonth, true
+	case BillingPeriodDaily:
+		return c.Amount * HoursPerMonth / 24, true
+	case BillingPeriodMonthly, "":
+		return c.Amount, true
+	case BillingPeriodYearly:
+		return c.Amount / 12, true
+	}
+	return 0, false
+}
+
+// PricingSourceEstimate is the pricing source of accounts whose resource
+// costs are estimated by the scanner from the resource configuration. Other
+// pricing sources reconcile costs with the billing data of the provider.
+const PricingSourceEstimate = "estimate"
+
+// CostAllocation is the monthly cost and waste of the resources of an
+// organization sharing a value of a cost-allocation tag during a month.
+// An empty TagValue holds the resources without the tag.
+type CostAllocation struct {
+	Month         time.Time `json:"month"` // first day of the month, in UTC
+	TagKey        string    `json:"tag_key"`
+	TagValue      string    `json:"tag_value"`
+	ResourceCount int64     `json:"resource_count"`
+	MonthlyCost   float64   `json:"monthly_cost"`
+	Waste         float64   `json:"waste"` // cost of the unused resources
+	ComputedAt    time.Time `json:"computed_at"`
+}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// customTypeNamePattern matches the names of custom resource types, e.g.
+// dynamodb_table
+var customTypeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,49}$`)
+
+// MetricStatistics are the statistics a metric detection can aggregate
+var MetricStatistics = []string{"Average", "Sum", "Minimum", "Maximum", "SampleCount"}
+
+// MaxDetectionLookbackDays bounds the window of metric detections to the
+// retention of daily metric data
+const MaxDetectionLookbackDays = 90
+
+// CustomResourceType is a resource type an organization defines for a
+// service CloudSweep does not cover yet. Its resources are listed through
+// the generic API of the provider, e.g. AWS Cloud Control for
+// AWS::DynamoDB::Table, and are unused when a metric stays below (or above)
+// a threshold over the lookback window.
+type CustomResourceType struct {
+	ID             uuid.UUID       `json:"id"`
+	OrganizationID uuid.UUID       `json:"organization_id"`
+	Name           ResourceType    `json:"name"`
+	Provider       CloudProvider   `json:"provider"`
+	CloudType      string          `json:"cloud_type"` // type name of the provider, e.g. "AWS::DynamoDB::Table"
+	Detection      MetricDetection `json:"detection"`
+	MonthlyCost    float64         `json:"monthly_cost"` // estimate per resource in USD, 0 when unknown
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// MetricDetection reports a resource unused from a metric of the provider,
+// e.g. the CloudWatch metric ConsumedReadCapacityUnits of namespace
+// AWS/DynamoDB whose TableName dimension is the resource ID
+type MetricDetection struct {
+	Namespace    string  `json:"namespace"`
+	MetricName   string  `json:"metric_name"`
+	Dimension    string  `json:"dimension"` // dimension valued with the resource ID
+	Statistic    string  `json:"statistic"`
+	Threshold    float64 `json:"threshold"`
+	Above        bool    `json:"above"` // unused above the threshold rather than below
+	LookbackDays int     `json:"lookback_days"`
+}
+
+// Validate checks the name of the type and its detection. The name must not
+// be a built-in resource type.
+func (t *CustomResourceType) Validate() error {
+	if !customTypeNamePattern.MatchString(string(t.Name)) {
+		return fmt.Errorf("name must be 3 to 50 lowercase letters, digits or underscores, starting with a letter")
+	}
+	if _, ok := t.Name.Provider(); ok {
+		return fmt.Errorf("name %q is a built-in resource type", t.Name)
+	}
+	if t.CloudType == "" {
+		return fmt.Errorf("cloud_type is required")
+	}
+	if t.MonthlyCost < 0 {
+		return fmt.Errorf("monthly_cost must not be negative")
+	}
+	return t.Detection.Validate()
+}
+
+// Validate checks that the metric is fully named and its window supported
+func (d MetricDetection) Validate() error {
+	if d.Namespace == "" || d.MetricName == "" || d.Dimension == "" {
+		return fmt.Errorf("detection namespace, metric_name and dimension are required")
+	}
+	if !slices.Contains(MetricStatistics, d.Statistic) {
+		return fmt.Errorf("detection statistic %q must be one of %v", d.Statistic, MetricStatistics)
+	}
+	if d.LookbackDays < 1 || d.LookbackDays > MaxDetectionLookbackDays {
+		return fmt.Errorf("detection lookback_days must be between 1 and %d", MaxDetectionLookbackDays)
+	}
+	return nil
+}
+
+// Unused reports whether a resource whose metric aggregated to value over
+// the window is unused
+func (d MetricDetection) Unused(value float64) bool {
+	if d.Above {
+		return value > d.Threshold
+	}
+	return value < d.Threshold
+}
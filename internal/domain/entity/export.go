@@ -0,0 +1,35 @@
+package entity
+
+// ExportStatus represents the status of an export job
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+// ExportType represents the dataset being exported
+type ExportType string
+
+const (
+	ExportTypeResources ExportType = "resources"
+	ExportTypeScans     ExportType = "scans"
+)
+
+// ExportFormat represents the file format of an export
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// ContentType returns the MIME type of the format
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatJSON {
+		return "application/json"
+	}
+	return "text/csv"
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobType is the kind of operation a job runs in the background
+type JobType string
+
+const (
+	JobTypeScan    JobType = "scan"
+	JobTypeCleanup JobType = "cleanup"
+	JobTypeExport  JobType = "export"
+)
+
+// JobStatus represents the status of a job
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending" // queued, or waiting for a retry
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// Finished reports whether the status is final
+func (s JobStatus) Finished() bool {
+	return s == JobStatusCompleted || s == JobStatusFailed
+}
+
+// Job is a long-running operation of an organization, whatever its kind
+type Job struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Type           JobType   `json:"type"`
+	// TaskID is the task running the job
+	TaskID string `json:"task_id"`
+	// TargetID is the scan or export the job runs
+	TargetID    *uuid.UUID `json:"target_id,omitempty"`
+	Status      JobStatus  `json:"status"`
+	Progress    int        `json:"progress"`
+	Attempts    int        `json:"attempts"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,116 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Notification events organizations route to their channels
+const (
+	// NotificationEventScanFinished summarizes a completed, partial, failed
+	// or cancelled scan
+	NotificationEventScanFinished = "scan.finished"
+	// NotificationEventCostAnomaly reports a scan whose monthly waste rose
+	// well above that of the previous scan of the provider
+	NotificationEventCostAnomaly = "cost.anomaly"
+	// NotificationEventPolicyMatched lists the resources a policy run
+	// matched, those matched within a short delay being sent together
+	NotificationEventPolicyMatched = "policy.matched"
+)
+
+// NotificationEvents lists the events notifications can be routed for
+var NotificationEvents = []string{NotificationEventScanFinished, NotificationEventCostAnomaly, NotificationEventPolicyMatched}
+
+// Notification channels
+const (
+	NotificationChannelTeams     = "teams"     // Microsoft Teams incoming webhook
+	NotificationChannelPagerDuty = "pagerduty" // PagerDuty Events API v2
+)
+
+// NotificationChannels lists the supported notification channels
+var NotificationChannels = []string{NotificationChannelTeams, NotificationChannelPagerDuty}
+
+// Defaults of the cost anomaly detection
+const (
+	DefaultAnomalyThresholdPercent = 50
+	DefaultAnomalyMinIncrease      = 100 // USD per month
+)
+
+// NotificationSettings configure the channels of an organization and the
+// events sent to each of them
+type NotificationSettings struct {
+	// TeamsWebhookURL is the incoming webhook of the Teams channel
+	TeamsWebhookURL string `json:"teams_webhook_url,omitempty"`
+	// PagerDutyRoutingKey is the integration key of the PagerDuty service
+	PagerDutyRoutingKey string `json:"pagerduty_routing_key,omitempty"`
+	// Routes map events to the channels they are sent to
+	Routes map[string][]string `json:"routes,omitempty"`
+	// AnomalyThresholdPercent is how much the monthly waste of a scan must
+	// rise over the previous one to be an anomaly; zero uses the default
+	AnomalyThresholdPercent int `json:"anomaly_threshold_percent,omitempty"`
+	// AnomalyMinIncrease is the smallest monthly increase, in USD, reported
+	// as an anomaly; zero uses the default
+	AnomalyMinIncrease float64 `json:"anomaly_min_increase,omitempty"`
+}
+
+// ChannelsFor returns the configured channels an event is routed to
+func (s NotificationSettings) ChannelsFor(event string) []string {
+	var channels []string
+	for _, channel := range s.Routes[event] {
+		if s.Target(channel) != "" && !slices.Contains(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// Target returns the webhook URL or routing key of a channel, empty when
+// it is not configured
+func (s NotificationSettings) Target(channel string) string {
+	switch channel {
+	case NotificationChannelTeams:
+		return s.TeamsWebhookURL
+	case NotificationChannelPagerDuty:
+		return s.PagerDutyRoutingKey
+	}
+	return ""
+}
+
+// Validate checks that routes name known events and configured channels
+func (s NotificationSettings) Validate() error {
+	events := make([]string, 0, len(s.Routes))
+	for event := range s.Routes {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	for _, event := range events {
+		if !slices.Contains(NotificationEvents, event) {
+			return fmt.Errorf("unknown notification event %q", event)
+		}
+		for _, channel := range s.Routes[event] {
+			if !slices.Contains(NotificationChannels, channel) {
+				return fmt.Errorf("unknown notification channel %q", channel)
+			}
+			if s.Target(channel) == "" {
+				return fmt.Errorf("notification channel %s is not configured", channel)
+			}
+		}
+	}
+	return nil
+}
+
+// IsCostAnomaly reports whether the monthly waste rising from previous to
+// current is an anomaly
+func (s NotificationSettings) IsCostAnomaly(previous, current float64) bool {
+	threshold := s.AnomalyThresholdPercent
+	if threshold <= 0 {
+		threshold = DefaultAnomalyThresholdPercent
+	}
+	minIncrease := s.AnomalyMinIncrease
+	if minIncrease <= 0 {
+		minIncrease = DefaultAnomalyMinIncrease
+	}
+	increase := current - previous
+	return increase >= minIncrease && increase >= previous*float64(threshold)/100
+}
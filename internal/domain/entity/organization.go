@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,13 +11,283 @@ import (
 
 // Organization represents a customer organization
 type Organization struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Slug      string    `json:"slug"`
-	Plan      string    `json:"plan"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID            `json:"id"`
+	Name      string               `json:"name"`
+	Slug      string               `json:"slug"`
+	Plan      string               `json:"plan"`
+	IsActive  bool                 `json:"is_active"`
+	Settings  OrganizationSettings `json:"settings"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// OrganizationSettings holds organization-wide scan and cleanup defaults
+type OrganizationSettings struct {
+	// DefaultRegions are scanned when a scan request omits regions
+	DefaultRegions []string `json:"default_regions"`
+	// RegionDenylist lists regions that are never scanned or acted on.
+	// Entries ending with "*" match by prefix (e.g. "cn-*", "us-gov-*").
+	RegionDenylist []string `json:"region_denylist"`
+	// OwnerRules attribute resources to the people receiving owner digests
+	OwnerRules OwnerRules `json:"owner_rules"`
+	// OwnerNotifications are how owners are told about the resources of
+	// policies with the notify action
+	OwnerNotifications OwnerNotifications `json:"owner_notifications"`
+	// AuditRetentionDays is how long provider calls made by cleanups are
+	// kept; zero uses the platform default
+	AuditRetentionDays int `json:"audit_retention_days"`
+	// HistoryRetentionDays is how long finished scans, hygiene scores and
+	// deleted resources are kept, at most the retention of the plan; zero
+	// uses the plan retention
+	HistoryRetentionDays int `json:"history_retention_days"`
+	// TerraformStates are the object storage keys of the organization's
+	// Terraform state files, read to flag the resources they declare
+	TerraformStates []string `json:"terraform_states"`
+	// GitOpsRepos map Terraform state keys to the repository holding their
+	// configuration, where pull requests removing resources are opened
+	GitOpsRepos map[string]GitOpsRepo `json:"gitops_repos"`
+	// AllocationTagKeys are the tags costs are allocated by in showback
+	// reports (e.g. team, project)
+	AllocationTagKeys []string `json:"allocation_tag_keys"`
+	// Notifications route events to the Teams and PagerDuty channels of
+	// the organization
+	Notifications NotificationSettings `json:"notifications"`
+	// WeeklySummary schedules the weekly summary emailed to the admins of
+	// the organization
+	WeeklySummary WeeklySummary `json:"weekly_summary"`
+	// BackupBeforeDelete snapshots volumes, disks and databases before
+	// cleanups delete them
+	BackupBeforeDelete BackupSettings `json:"backup_before_delete"`
+}
+
+// GitOpsRepo is the repository holding the Terraform configuration of a
+// state file
+type GitOpsRepo struct {
+	Provider string `json:"provider"` // github or gitlab
+	Repo     string `json:"repo"`     // "owner/name", or the GitLab project path
+	Branch   string `json:"branch"`   // base branch of pull requests
+	Path     string `json:"path"`     // directory of the root module, "" for the repository root
+}
+
+// DefaultOwnerTagKeys are the tags read when an organization defines none.
+// Team names resolve through the owner aliases.
+var DefaultOwnerTagKeys = []string{"owner", "Owner", "created-by", "CreatedBy", "team", "Team"}
+
+// OwnerSource is where the owner of a resource comes from
+type OwnerSource string
+
+// Owner sources, from the most to the least specific
+const (
+	OwnerSourceMapping OwnerSource = "mapping" // uploaded mapping file
+	OwnerSourceTag     OwnerSource = "tag"
+	OwnerSourceAccount OwnerSource = "account" // default owner of the cloud account
+	OwnerSourceDefault OwnerSource = "default" // default owner of the organization
+)
+
+// OwnerRules derive the owner email of a resource from its tags
+type OwnerRules struct {
+	// TagKeys are tried in order; the first present tag wins
+	TagKeys []string `json:"tag_keys"`
+	// Aliases map tag values (e.g. team names) to an email address
+	Aliases map[string]string `json:"aliases"`
+	// DefaultOwner receives resources no rule could attribute
+	DefaultOwner string `json:"default_owner"`
+}
+
+// ResolveOwner returns the owner email for the given tags, or an empty
+// string when the resource cannot be attributed
+func (r OwnerRules) ResolveOwner(tags map[string]string) string {
+	if owner := r.TagOwner(tags); owner != "" {
+		return owner
+	}
+	return r.DefaultOwner
+}
+
+// TagOwner returns the owner email read from the given tags, without
+// falling back to the default owner
+func (r OwnerRules) TagOwner(tags map[string]string) string {
+	keys := r.TagKeys
+	if len(keys) == 0 {
+		keys = DefaultOwnerTagKeys
+	}
+
+	for _, key := range keys {
+		value := strings.TrimSpace(tags[key])
+		if value == "" {
+			continue
+		}
+		for alias, email := range r.Aliases {
+			if strings.EqualFold(alias, value) {
+				return email
+			}
+		}
+		if strings.Contains(value, "@") {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// Channels owners are notified on
+const (
+	OwnerChannelEmail = "email"
+	OwnerChannelSlack = "slack" // direct message from the Slack app
+)
+
+// OwnerNotifications configure the messages sent to the owners of the
+// resources of policies with the notify action
+type OwnerNotifications struct {
+	// Channels are the channels owners are notified on, email when empty.
+	// Slack messages go to the workspace user with the owner email.
+	Channels []string `json:"channels"`
+	// Digest sends one message per owner and day instead of one per
+	// policy run
+	Digest bool `json:"digest"`
+}
+
+// NotifyChannels returns the channels owners are notified on
+func (n OwnerNotifications) NotifyChannels() []string {
+	if len(n.Channels) == 0 {
+		return []string{OwnerChannelEmail}
+	}
+	return n.Channels
+}
+
+// Weekly summary defaults: Monday 8:00 UTC
+const (
+	DefaultWeeklySummaryWeekday  = "monday"
+	DefaultWeeklySummaryHour     = 8
+	DefaultWeeklySummaryTimezone = "UTC"
+)
+
+// DefaultBackupRetentionDays is how long the backups taken before deleting
+// resources are kept when an organization sets no retention
+const DefaultBackupRetentionDays = 30
+
+// BackupSettings configure the backup-then-delete mode, where cleanups
+// snapshot volumes, disks and databases and wait for the snapshot to
+// complete before deleting them
+type BackupSettings struct {
+	Enabled       bool `json:"enabled"`
+	RetentionDays int  `json:"retention_days"` // zero uses DefaultBackupRetentionDays
+}
+
+// Retention returns how long backups are kept
+func (s BackupSettings) Retention() time.Duration {
+	days := s.RetentionDays
+	if days <= 0 {
+		days = DefaultBackupRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// WeeklySummary configures when the weekly summary of an organization is
+// sent, in the local time of the organization
+type WeeklySummary struct {
+	Disabled bool   `json:"disabled"`
+	Weekday  string `json:"weekday"`  // lowercase English day name
+	Hour     *int   `json:"hour"`     // 0 to 23
+	Timezone string `json:"timezone"` // IANA name, e.g. Europe/Paris
+}
+
+// weekdays map day names to their time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// Validate checks the day, hour and timezone of the summary
+func (s WeeklySummary) Validate() error {
+	if _, ok := weekdays[s.Weekday]; s.Weekday != "" && !ok {
+		return fmt.Errorf("invalid weekly_summary weekday %q", s.Weekday)
+	}
+	if s.Hour != nil && (*s.Hour < 0 || *s.Hour > 23) {
+		return fmt.Errorf("invalid weekly_summary hour %d", *s.Hour)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid weekly_summary timezone %q: %w", s.Timezone, err)
+	}
+	return nil
+}
+
+// Location returns the timezone the summary is scheduled in, UTC when it
+// is unset or unknown
+func (s WeeklySummary) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Due reports whether the summary is to be sent in the hour of now, given
+// when the last one was sent
+func (s WeeklySummary) Due(now time.Time, lastSent *time.Time) bool {
+	if s.Disabled {
+		return false
+	}
+	day, ok := weekdays[s.Weekday]
+	if !ok {
+		day = weekdays[DefaultWeeklySummaryWeekday]
+	}
+	hour := DefaultWeeklySummaryHour
+	if s.Hour != nil {
+		hour = *s.Hour
+	}
+	local := now.In(s.Location())
+	if local.Weekday() != day || local.Hour() != hour {
+		return false
+	}
+	// Once a day at most, should a timezone change move the hour later
+	return lastSent == nil || now.Sub(*lastSent) > 24*time.Hour
+}
+
+// RegionAllowed returns false if the region matches the denylist
+func (s OrganizationSettings) RegionAllowed(region string) bool {
+	for _, pattern := range s.RegionDenylist {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(region, prefix) {
+				return false
+			}
+		} else if region == pattern {
+			return false
+		}
+	}
+	return true
+}
+
+// DeniedRegions returns the regions that match the denylist
+func (s OrganizationSettings) DeniedRegions(regions []string) []string {
+	var denied []string
+	for _, region := range regions {
+		if !s.RegionAllowed(region) {
+			denied = append(denied, region)
+		}
+	}
+	return denied
+}
+
+// Plans organizations can subscribe to
+const (
+	PlanFree       = "free"
+	PlanPro        = "pro"
+	PlanEnterprise = "enterprise"
+)
+
+// slugPattern matches organization slugs: lowercase letters, digits and
+// inner hyphens, 3 to 100 characters
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,98}[a-z0-9]$`)
+
+// ValidateSlug checks an organization slug can be used in URLs
+func ValidateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) || strings.Contains(slug, "--") {
+		return fmt.Errorf("invalid slug %q: use 3 to 100 lowercase letters, digits and single hyphens", slug)
+	}
+	return nil
 }
 
 // NewOrganization creates a new Organization
@@ -24,7 +297,7 @@ func NewOrganization(name, slug string) *Organization {
 		ID:        uuid.New(),
 		Name:      name,
 		Slug:      slug,
-		Plan:      "free",
+		Plan:      PlanFree,
 		IsActive:  true,
 		CreatedAt: now,
 		UpdatedAt: now,
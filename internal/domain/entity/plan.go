@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Quota is a limit of a plan
+type Quota string
+
+const (
+	QuotaCloudAccounts Quota = "cloud_accounts" // active cloud accounts
+	QuotaScansPerDay   Quota = "scans_per_day"  // scans created since midnight UTC
+	QuotaResources     Quota = "resources"      // resources tracked, deleted ones excluded
+	QuotaRetentionDays Quota = "retention_days" // days scan history is kept for
+)
+
+// PlanQuotas are the limits of a plan. Zero means unlimited.
+type PlanQuotas struct {
+	CloudAccounts int
+	ScansPerDay   int
+	Resources     int
+	RetentionDays int
+}
+
+// planQuotas are the limits of each plan. Unknown plans get the limits of
+// the free plan.
+var planQuotas = map[string]PlanQuotas{
+	PlanFree:       {CloudAccounts: 1, ScansPerDay: 5, Resources: 500, RetentionDays: 30},
+	PlanPro:        {CloudAccounts: 10, ScansPerDay: 50, Resources: 10000, RetentionDays: 365},
+	PlanEnterprise: {RetentionDays: 730},
+}
+
+// planUpgrades is the next plan of each plan
+var planUpgrades = map[string]string{
+	PlanFree: PlanPro,
+	PlanPro:  PlanEnterprise,
+}
+
+// QuotasFor returns the limits of a plan
+func QuotasFor(plan string) PlanQuotas {
+	if q, ok := planQuotas[plan]; ok {
+		return q
+	}
+	return planQuotas[PlanFree]
+}
+
+// UpgradeFor returns the plan to upgrade to for higher limits, or "" for the
+// highest plan
+func UpgradeFor(plan string) string {
+	if _, ok := planQuotas[plan]; !ok {
+		return PlanPro
+	}
+	return planUpgrades[plan]
+}
+
+// Limit returns the limit of a quota, zero when unlimited
+func (q PlanQuotas) Limit(quota Quota) int {
+	switch quota {
+	case QuotaCloudAccounts:
+		return q.CloudAccounts
+	case QuotaScansPerDay:
+		return q.ScansPerDay
+	case QuotaResources:
+		return q.Resources
+	case QuotaRetentionDays:
+		return q.RetentionDays
+	}
+	return 0
+}
+
+// HistoryRetentionDays returns the days the history of an organization is
+// kept for: its own retention when shorter than the one of its plan, zero
+// when kept forever
+func HistoryRetentionDays(plan string, days int) int {
+	limit := QuotasFor(plan).RetentionDays
+	if days > 0 && (limit == 0 || days < limit) {
+		return days
+	}
+	return limit
+}
+
+// QuotaExceededError is returned when an organization reaches a limit of
+// its plan
+type QuotaExceededError struct {
+	Quota   Quota
+	Plan    string
+	Limit   int
+	Used    int64  // usage before the operation
+	Needed  int64  // usage the operation would reach
+	Upgrade string // plan with a higher limit, empty for the highest plan
+}
+
+func (e *QuotaExceededError) Error() string {
+	msg := fmt.Sprintf("%s plan allows %d %s, %d needed", e.Plan, e.Limit, e.Quota, e.Needed)
+	if e.Upgrade != "" {
+		msg += fmt.Sprintf(", upgrade to %s for more", e.Upgrade)
+	}
+	return msg
+}
+
+// CheckQuota returns a QuotaExceededError when adding to the usage would
+// go over the limit of the plan
+func CheckQuota(plan string, quota Quota, used int64, adding int) error {
+	limit := QuotasFor(plan).Limit(quota)
+	if limit == 0 || used+int64(adding) <= int64(limit) {
+		return nil
+	}
+	return &QuotaExceededError{
+		Quota:   quota,
+		Plan:    plan,
+		Limit:   limit,
+		Used:    used,
+		Needed:  used + int64(adding),
+		Upgrade: UpgradeFor(plan),
+	}
+}
+
+// StartOfQuotaDay returns the start of the day daily quotas are counted
+// from, midnight UTC
+func StartOfQuotaDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
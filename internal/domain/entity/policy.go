@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,8 +16,39 @@ const (
 	PolicyActionTag     PolicyAction = "tag"
 	PolicyActionStop    PolicyAction = "stop"
 	PolicyActionDelete  PolicyAction = "delete"
+	// PolicyActionScheduleOffHours stops resources outside the low-carbon
+	// hours of their carbon schedule
+	PolicyActionScheduleOffHours PolicyAction = "schedule_offhours"
+	// PolicyActionSchedule stops resources outside working hours and
+	// restarts them, following the policy's OffHours schedule
+	PolicyActionSchedule PolicyAction = "schedule"
+	// PolicyActionQuarantine stops or snapshots resources and tags them,
+	// deleting them only once the quarantine window has ended
+	PolicyActionQuarantine PolicyAction = "quarantine"
+	// PolicyActionRelease releases reserved public IP addresses back to
+	// their provider
+	PolicyActionRelease PolicyAction = "release"
+	// PolicyActionTicket opens a Jira issue or ServiceNow change request
+	// for resources, closed once they are remediated
+	PolicyActionTicket PolicyAction = "ticket"
 )
 
+// Default off-hours windows: stopped on weekday evenings, through the
+// weekend, and restarted on weekday mornings
+const (
+	DefaultOffHoursTimezone = "UTC"
+	DefaultOffHoursStop     = "0 19 * * 1-5"
+	DefaultOffHoursStart    = "0 8 * * 1-5"
+)
+
+// OffHoursSchedule is when a "schedule" policy stops and restarts its
+// resources, as cron expressions evaluated in Timezone
+type OffHoursSchedule struct {
+	Timezone string `json:"timezone"`
+	Stop     string `json:"stop"`
+	Start    string `json:"start"`
+}
+
 // Policy represents a cleanup policy
 type Policy struct {
 	ID             uuid.UUID       `json:"id"`
@@ -28,8 +61,16 @@ type Policy struct {
 	Actions        []PolicyAction  `json:"actions"`
 	IsEnabled      bool            `json:"is_enabled"`
 	Schedule       string          `json:"schedule"` // Cron expression
+	OffHours       *OffHoursSchedule `json:"off_hours,omitempty"`
+	// ViewID is the saved resource view the policy is scoped to, in
+	// addition to its conditions
+	ViewID         *uuid.UUID      `json:"view_id,omitempty"`
+	// ExternalID is the key of a policy managed declaratively
+	ExternalID     string          `json:"external_id,omitempty"`
 	CreatedAt      time.Time       `json:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at"`
+	// Version is incremented by every update of the policy
+	Version        int             `json:"version"`
 }
 
 // PolicyConditions defines when a policy should apply
@@ -41,6 +82,12 @@ type PolicyConditions struct {
 	ExcludedTags     map[string]string `json:"excluded_tags,omitempty"`
 	Regions          []string          `json:"regions,omitempty"`
 	NamePattern      string            `json:"name_pattern,omitempty"`
+	// IncludeIaCManaged lets the policy match resources managed by an
+	// infrastructure-as-code tool, which are left out by default
+	IncludeIaCManaged bool             `json:"include_iac_managed,omitempty"`
+	// Filter is an optional condition tree, evaluated with the flat
+	// conditions above
+	Filter           *ConditionNode    `json:"filter,omitempty"`
 }
 
 // NewPolicy creates a new Policy
@@ -82,3 +129,100 @@ func (p *Policy) HasDeleteAction() bool {
 	}
 	return false
 }
+
+// Validate checks that the conditions are consistent
+func (c PolicyConditions) Validate() error {
+	if c.UnusedDays < 0 {
+		return fmt.Errorf("unused_days must not be negative")
+	}
+	if c.MinMonthlyCost < 0 || c.MaxMonthlyCost < 0 {
+		return fmt.Errorf("monthly cost bounds must not be negative")
+	}
+	if c.MaxMonthlyCost > 0 && c.MinMonthlyCost > c.MaxMonthlyCost {
+		return fmt.Errorf("min_monthly_cost must not exceed max_monthly_cost")
+	}
+	if c.NamePattern != "" {
+		if _, err := regexp.Compile(c.NamePattern); err != nil {
+			return fmt.Errorf("invalid name_pattern: %w", err)
+		}
+	}
+	if c.Filter != nil {
+		return c.Filter.Validate()
+	}
+	return nil
+}
+
+// Validate checks that the policy targets resource types of its provider,
+// that its actions are known and apply to those types, and that its
+// conditions are consistent
+func (p *Policy) Validate() error {
+	for _, t := range p.ResourceTypes {
+		provider, ok := t.Provider()
+		if !ok {
+			return fmt.Errorf("unknown resource type %q", t)
+		}
+		if provider != p.Provider {
+			return fmt.Errorf("resource type %q is not a %s resource", t, p.Provider)
+		}
+	}
+
+	for _, action := range p.Actions {
+		switch action {
+		case PolicyActionNotify, PolicyActionTag, PolicyActionDelete, PolicyActionQuarantine, PolicyActionTicket:
+		case PolicyActionStop, PolicyActionScheduleOffHours, PolicyActionSchedule:
+			// Without resource types the policy targets every type, most of
+			// which cannot be stopped
+			if len(p.ResourceTypes) == 0 {
+				return fmt.Errorf("action %q requires resource types that can be stopped", action)
+			}
+			for _, t := range p.ResourceTypes {
+				if !t.IsStoppable() {
+					return fmt.Errorf("action %q does not apply to resource type %q", action, t)
+				}
+			}
+		case PolicyActionRelease:
+			if len(p.ResourceTypes) == 0 {
+				return fmt.Errorf("action %q requires public IP resource types", action)
+			}
+			for _, t := range p.ResourceTypes {
+				if !t.IsPublicIP() {
+					return fmt.Errorf("action %q does not apply to resource type %q", action, t)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown action %q", action)
+		}
+		if action == PolicyActionSchedule {
+			if err := p.OffHours.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return p.Conditions.Validate()
+}
+
+// HasAction returns true if the policy includes the given action
+func (p *Policy) HasAction(action PolicyAction) bool {
+	for _, a := range p.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the schedule has both windows and a known timezone.
+// Cron expressions are checked by the scheduler parsing them.
+func (s *OffHoursSchedule) Validate() error {
+	if s == nil {
+		return fmt.Errorf("action %q requires off_hours", PolicyActionSchedule)
+	}
+	if s.Stop == "" || s.Start == "" {
+		return fmt.Errorf("off_hours requires stop and start cron expressions")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid off_hours timezone %q: %w", s.Timezone, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package entity
+
+// PolicyTemplate is a ready-made policy for a common kind of waste, that
+// organizations create policies from
+type PolicyTemplate struct {
+	ID            string           `json:"id"`
+	Name          string           `json:"name"`
+	Description   string           `json:"description"`
+	Provider      CloudProvider    `json:"provider"`
+	ResourceTypes []ResourceType   `json:"resource_types"`
+	Conditions    PolicyConditions `json:"conditions"`
+	Actions       []PolicyAction   `json:"actions"`
+}
+
+// policyTemplates are the built-in templates. Idle load balancers and NAT
+// gateways are quarantined first, as their traffic may be seasonal; detached
+// interfaces hold no state and are deleted, and unassociated IP addresses
+// are released.
+var policyTemplates = []PolicyTemplate{
+	{
+		ID:            "aws-idle-load-balancers",
+		Name:          "Idle load balancers",
+		Description:   "Quarantine load balancers without healthy targets or requests for 14 days",
+		Provider:      CloudProviderAWS,
+		ResourceTypes: []ResourceType{ResourceTypeLoadBalancer},
+		Conditions:    PolicyConditions{UnusedDays: 14},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionQuarantine},
+	},
+	{
+		ID:            "aws-detached-network-interfaces",
+		Name:          "Detached network interfaces",
+		Description:   "Delete network interfaces detached for 7 days",
+		Provider:      CloudProviderAWS,
+		ResourceTypes: []ResourceType{ResourceTypeNetworkInterface},
+		Conditions:    PolicyConditions{UnusedDays: 7},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionDelete},
+	},
+	{
+		ID:            "aws-idle-nat-gateways",
+		Name:          "Idle NAT gateways",
+		Description:   "Quarantine NAT gateways that processed almost no traffic for 14 days",
+		Provider:      CloudProviderAWS,
+		ResourceTypes: []ResourceType{ResourceTypeNATGateway},
+		Conditions:    PolicyConditions{UnusedDays: 14},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionQuarantine},
+	},
+	{
+		ID:            "aws-unassociated-ips",
+		Name:          "Unassociated Elastic IPs",
+		Description:   "Release Elastic IP addresses associated with nothing for 7 days",
+		Provider:      CloudProviderAWS,
+		ResourceTypes: []ResourceType{ResourceTypeElasticIP},
+		Conditions:    PolicyConditions{UnusedDays: 7},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionRelease},
+	},
+	{
+		ID:            "azure-idle-load-balancers",
+		Name:          "Idle load balancers",
+		Description:   "Quarantine load balancers without healthy backends or traffic for 14 days",
+		Provider:      CloudProviderAzure,
+		ResourceTypes: []ResourceType{ResourceTypeAzureLoadBalancer},
+		Conditions:    PolicyConditions{UnusedDays: 14},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionQuarantine},
+	},
+	{
+		ID:            "azure-detached-network-interfaces",
+		Name:          "Detached network interfaces",
+		Description:   "Delete network interfaces detached from any virtual machine for 7 days",
+		Provider:      CloudProviderAzure,
+		ResourceTypes: []ResourceType{ResourceTypeAzureNetworkInterface},
+		Conditions:    PolicyConditions{UnusedDays: 7},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionDelete},
+	},
+	{
+		ID:            "azure-idle-nat-gateways",
+		Name:          "Idle NAT gateways",
+		Description:   "Quarantine NAT gateways that processed almost no traffic for 14 days",
+		Provider:      CloudProviderAzure,
+		ResourceTypes: []ResourceType{ResourceTypeAzureNATGateway},
+		Conditions:    PolicyConditions{UnusedDays: 14},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionQuarantine},
+	},
+	{
+		ID:            "azure-unassociated-ips",
+		Name:          "Unassociated public IPs",
+		Description:   "Release public IP addresses associated with nothing for 7 days",
+		Provider:      CloudProviderAzure,
+		ResourceTypes: []ResourceType{ResourceTypeAzurePublicIP},
+		Conditions:    PolicyConditions{UnusedDays: 7},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionRelease},
+	},
+	{
+		ID:            "gcp-idle-load-balancers",
+		Name:          "Idle load balancers",
+		Description:   "Quarantine forwarding rules without healthy backends or requests for 14 days",
+		Provider:      CloudProviderGCP,
+		ResourceTypes: []ResourceType{ResourceTypeGCPLoadBalancer},
+		Conditions:    PolicyConditions{UnusedDays: 14},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionQuarantine},
+	},
+	{
+		ID:            "gcp-idle-cloud-nat",
+		Name:          "Idle Cloud NAT gateways",
+		Description:   "Quarantine Cloud NAT gateways that processed almost no traffic for 14 days",
+		Provider:      CloudProviderGCP,
+		ResourceTypes: []ResourceType{ResourceTypeCloudNAT},
+		Conditions:    PolicyConditions{UnusedDays: 14},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionQuarantine},
+	},
+	{
+		ID:            "gcp-unassociated-ips",
+		Name:          "Unused static IPs",
+		Description:   "Release static external IP addresses used by nothing for 7 days",
+		Provider:      CloudProviderGCP,
+		ResourceTypes: []ResourceType{ResourceTypeGCPStaticIP},
+		Conditions:    PolicyConditions{UnusedDays: 7},
+		Actions:       []PolicyAction{PolicyActionNotify, PolicyActionRelease},
+	},
+}
+
+// PolicyTemplates returns the built-in policy templates
+func PolicyTemplates() []PolicyTemplate {
+	return policyTemplates
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecommendationType represents the kind of saving a recommendation brings
+type RecommendationType string
+
+const (
+	RecommendationTypeRightsize    RecommendationType = "rightsize"
+	RecommendationTypeGP3Migration RecommendationType = "gp3_migration"
+	RecommendationTypeCommitment   RecommendationType = "commitment"
+)
+
+// RecommendationStatus represents the status of a recommendation
+type RecommendationStatus string
+
+const (
+	RecommendationStatusOpen      RecommendationStatus = "open"
+	RecommendationStatusDismissed RecommendationStatus = "dismissed"
+)
+
+// Recommendation represents a saving found on the inventory of an
+// organization, such as rightsizing a resource or buying a commitment
+type Recommendation struct {
+	ID             uuid.UUID            `json:"id"`
+	OrganizationID uuid.UUID            `json:"organization_id"`
+	ResourceID     *uuid.UUID           `json:"resource_id,omitempty"` // nil for commitments
+	Provider       CloudProvider        `json:"provider"`
+	Type           RecommendationType   `json:"type"`
+	Current        string               `json:"current"`
+	Recommended    string               `json:"recommended"`
+	MonthlySavings float64              `json:"monthly_savings"`
+	Status         RecommendationStatus `json:"status"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	// Resource is the resource of the recommendation, when loaded
+	Resource *Resource `json:"resource,omitempty"`
+}
@@ -10,9 +10,10 @@ import (
 type CloudProvider string
 
 const (
-	CloudProviderAWS   CloudProvider = "aws"
-	CloudProviderAzure CloudProvider = "azure"
-	CloudProviderGCP   CloudProvider = "gcp"
+	CloudProviderAWS        CloudProvider = "aws"
+	CloudProviderAzure      CloudProvider = "azure"
+	CloudProviderGCP        CloudProvider = "gcp"
+	CloudProviderKubernetes CloudProvider = "kubernetes"
 )
 
 // ResourceType represents a type of cloud resource
@@ -30,18 +31,187 @@ const (
 	ResourceTypeAzureDisk     ResourceType = "azure_disk"
 	ResourceTypeGCEInstance   ResourceType = "gce_instance"
 	ResourceTypeGCEDisk       ResourceType = "gce_disk"
+	ResourceTypeVPCPeering    ResourceType = "vpc_peering_connection"
+	ResourceTypeVPNConnection ResourceType = "vpn_connection"
+	ResourceTypeTransitGatewayAttachment ResourceType = "transit_gateway_attachment"
+	ResourceTypeAzureBlobContainer       ResourceType = "azure_blob_container"
+	ResourceTypeGCSBucket                ResourceType = "gcs_bucket"
+	ResourceTypeK8sDeployment            ResourceType = "k8s_deployment"
+	ResourceTypeK8sPVC                   ResourceType = "k8s_pvc"
+	ResourceTypeK8sLoadBalancer          ResourceType = "k8s_load_balancer"
+	ResourceTypeAzureSQL                 ResourceType = "azure_sql"
+	ResourceTypeCloudSQL                 ResourceType = "cloud_sql"
+	ResourceTypeAzureSnapshot            ResourceType = "azure_snapshot"
+	ResourceTypeNetworkInterface         ResourceType = "network_interface"
+	ResourceTypeNATGateway               ResourceType = "nat_gateway"
+	ResourceTypeAzureLoadBalancer        ResourceType = "azure_load_balancer"
+	ResourceTypeAzureNetworkInterface    ResourceType = "azure_network_interface"
+	ResourceTypeAzureNATGateway          ResourceType = "azure_nat_gateway"
+	ResourceTypeGCPLoadBalancer          ResourceType = "gcp_load_balancer"
+	ResourceTypeCloudNAT                 ResourceType = "cloud_nat"
+	ResourceTypeAzurePublicIP            ResourceType = "azure_public_ip"
+	ResourceTypeGCPStaticIP              ResourceType = "gcp_static_ip"
 )
 
+// resourceTypeProviders maps each supported resource type to its provider
+var resourceTypeProviders = map[ResourceType]CloudProvider{
+	ResourceTypeEC2Instance:              CloudProviderAWS,
+	ResourceTypeEBSVolume:                CloudProviderAWS,
+	ResourceTypeEBSSnapshot:              CloudProviderAWS,
+	ResourceTypeElasticIP:                CloudProviderAWS,
+	ResourceTypeLoadBalancer:             CloudProviderAWS,
+	ResourceTypeS3Bucket:                 CloudProviderAWS,
+	ResourceTypeRDSInstance:              CloudProviderAWS,
+	ResourceTypeVPCPeering:               CloudProviderAWS,
+	ResourceTypeVPNConnection:            CloudProviderAWS,
+	ResourceTypeTransitGatewayAttachment: CloudProviderAWS,
+	ResourceTypeNetworkInterface:         CloudProviderAWS,
+	ResourceTypeNATGateway:               CloudProviderAWS,
+	ResourceTypeAzureVM:                  CloudProviderAzure,
+	ResourceTypeAzureDisk:                CloudProviderAzure,
+	ResourceTypeAzureBlobContainer:       CloudProviderAzure,
+	ResourceTypeAzureSQL:                 CloudProviderAzure,
+	ResourceTypeAzureSnapshot:            CloudProviderAzure,
+	ResourceTypeAzureLoadBalancer:        CloudProviderAzure,
+	ResourceTypeAzureNetworkInterface:    CloudProviderAzure,
+	ResourceTypeAzureNATGateway:          CloudProviderAzure,
+	ResourceTypeAzurePublicIP:            CloudProviderAzure,
+	ResourceTypeGCEInstance:              CloudProviderGCP,
+	ResourceTypeGCEDisk:                  CloudProviderGCP,
+	ResourceTypeGCSBucket:                CloudProviderGCP,
+	ResourceTypeCloudSQL:                 CloudProviderGCP,
+	ResourceTypeGCPLoadBalancer:          CloudProviderGCP,
+	ResourceTypeCloudNAT:                 CloudProviderGCP,
+	ResourceTypeGCPStaticIP:              CloudProviderGCP,
+	ResourceTypeK8sDeployment:            CloudProviderKubernetes,
+	ResourceTypeK8sPVC:                   CloudProviderKubernetes,
+	ResourceTypeK8sLoadBalancer:          CloudProviderKubernetes,
+}
+
+// Provider returns the provider of the resource type. ok is false for
+// unknown types.
+func (t ResourceType) Provider() (provider CloudProvider, ok bool) {
+	provider, ok = resourceTypeProviders[t]
+	return provider, ok
+}
+
+// RegisterResourceType adds a resource type of a provider defined outside
+// this package. It must only be called during initialization.
+func RegisterResourceType(t ResourceType, provider CloudProvider) {
+	resourceTypeProviders[t] = provider
+}
+
+// IsStoppable returns true for resource types that can be stopped rather
+// than deleted
+func (t ResourceType) IsStoppable() bool {
+	switch t {
+	case ResourceTypeEC2Instance, ResourceTypeRDSInstance, ResourceTypeAzureVM, ResourceTypeGCEInstance,
+		ResourceTypeAzureSQL, ResourceTypeCloudSQL:
+		return true
+	}
+	return false
+}
+
+// IsNetworkAttachment returns true for resource types connecting networks,
+// which routes may point to
+func (t ResourceType) IsNetworkAttachment() bool {
+	switch t {
+	case ResourceTypeVPCPeering, ResourceTypeVPNConnection, ResourceTypeTransitGatewayAttachment:
+		return true
+	}
+	return false
+}
+
+// IsManagedDatabase returns true for managed database instances, which are
+// only deleted after a final snapshot
+func (t ResourceType) IsManagedDatabase() bool {
+	switch t {
+	case ResourceTypeRDSInstance, ResourceTypeAzureSQL, ResourceTypeCloudSQL:
+		return true
+	}
+	return false
+}
+
+// IsObjectStorage returns true for buckets and blob containers
+func (t ResourceType) IsObjectStorage() bool {
+	switch t {
+	case ResourceTypeS3Bucket, ResourceTypeAzureBlobContainer, ResourceTypeGCSBucket:
+		return true
+	}
+	return false
+}
+
+// IsSnapshot returns true for volume and disk snapshots, which may back
+// machine images
+func (t ResourceType) IsSnapshot() bool {
+	switch t {
+	case ResourceTypeEBSSnapshot, ResourceTypeAzureSnapshot:
+		return true
+	}
+	return false
+}
+
+// IsLoadBalancer returns true for the load balancers of cloud providers.
+// Those of Kubernetes services are not, as their cloud load balancer is
+// owned by the cluster.
+func (t ResourceType) IsLoadBalancer() bool {
+	switch t {
+	case ResourceTypeLoadBalancer, ResourceTypeAzureLoadBalancer, ResourceTypeGCPLoadBalancer:
+		return true
+	}
+	return false
+}
+
+// IsNetworkInterface returns true for network interfaces, which can exist
+// detached from any instance
+func (t ResourceType) IsNetworkInterface() bool {
+	switch t {
+	case ResourceTypeNetworkInterface, ResourceTypeAzureNetworkInterface:
+		return true
+	}
+	return false
+}
+
+// IsNATGateway returns true for NAT gateways, which routes may point to
+func (t ResourceType) IsNATGateway() bool {
+	switch t {
+	case ResourceTypeNATGateway, ResourceTypeAzureNATGateway, ResourceTypeCloudNAT:
+		return true
+	}
+	return false
+}
+
+// IsPublicIP returns true for reserved public IP addresses, which are
+// released rather than deleted
+func (t ResourceType) IsPublicIP() bool {
+	switch t {
+	case ResourceTypeElasticIP, ResourceTypeAzurePublicIP, ResourceTypeGCPStaticIP:
+		return true
+	}
+	return false
+}
+
 // ResourceStatus represents the status of a resource
 type ResourceStatus string
 
 const (
 	ResourceStatusActive   ResourceStatus = "active"
 	ResourceStatusUnused   ResourceStatus = "unused"
-	ResourceStatusDeleted  ResourceStatus = "deleted"
+	ResourceStatusDeleted  ResourceStatus = "deleted"  // gone from the cloud: deleted by a cleanup or missing from a scan
+	ResourceStatusRemoved  ResourceStatus = "removed"  // removed from the inventory by a user, whatever its state in the cloud
 	ResourceStatusExcluded ResourceStatus = "excluded"
 )
 
+// UntrackedResourceStatuses are the statuses of the resources left out of
+// the inventory: quotas, reports and policies ignore them
+var UntrackedResourceStatuses = []string{string(ResourceStatusDeleted), string(ResourceStatusRemoved)}
+
+// IsTracked returns true if resources of this status are part of the
+// inventory
+func (s ResourceStatus) IsTracked() bool {
+	return s != ResourceStatusDeleted && s != ResourceStatusRemoved
+}
+
 // Resource represents a cloud resource
 type Resource struct {
 	ID             uuid.UUID       `json:"id"`
@@ -50,6 +220,7 @@ type Resource struct {
 	Type           ResourceType    `json:"type"`
 	ResourceID     string          `json:"resource_id"`
 	Region         string          `json:"region"`
+	AccountID      string          `json:"account_id,omitempty"` // AWS account, Azure subscription, GCP project or cluster
 	Name           string          `json:"name"`
 	Status         ResourceStatus  `json:"status"`
 	Tags           map[string]string `json:"tags"`
@@ -57,6 +228,15 @@ type Resource struct {
 	MonthlyCost    float64         `json:"monthly_cost"`
 	CarbonFootprint float64        `json:"carbon_footprint_kg"`
 	LastSeenAt     time.Time       `json:"last_seen_at"`
+	SnoozedUntil   *time.Time      `json:"snoozed_until,omitempty"`
+	CleanupApprovedAt *time.Time   `json:"cleanup_approved_at,omitempty"`
+	CleanupApprovedBy string       `json:"cleanup_approved_by,omitempty"`
+	QuarantinedAt  *time.Time      `json:"quarantined_at,omitempty"`
+	QuarantineUntil *time.Time     `json:"quarantine_until,omitempty"`
+	IaCManaged     bool            `json:"iac_managed"`
+	IaCTool        string          `json:"iac_tool,omitempty"` // e.g. "terraform", "cloudformation"
+	Owner          string          `json:"owner,omitempty"` // email of the person or team owning the resource
+	OwnerSource    OwnerSource     `json:"owner_source,omitempty"`
 	CreatedAt      time.Time       `json:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at"`
 }
@@ -87,12 +267,79 @@ func (r *Resource) MarkAsUnused() {
 	r.UpdatedAt = time.Now()
 }
 
-// MarkAsDeleted marks the resource as deleted
+// MarkAsActive records that the resource is back in service, e.g. after a
+// cleanup was rolled back
+func (r *Resource) MarkAsActive() {
+	r.Status = ResourceStatusActive
+	r.UpdatedAt = time.Now()
+}
+
+// MarkAsDeleted records that the resource is gone from the cloud. A
+// resource removed from the inventory stays removed.
 func (r *Resource) MarkAsDeleted() {
+	if r.Status == ResourceStatusRemoved {
+		return
+	}
 	r.Status = ResourceStatusDeleted
 	r.UpdatedAt = time.Now()
 }
 
+// RemoveFromInventory drops the resource from the inventory without acting
+// on it in the cloud. Scans keep it removed until it is reinstated.
+func (r *Resource) RemoveFromInventory() {
+	r.Status = ResourceStatusRemoved
+	r.UpdatedAt = time.Now()
+}
+
+// Snooze hides the resource from owner digests until the given time
+func (r *Resource) Snooze(until time.Time) {
+	r.SnoozedUntil = &until
+	r.UpdatedAt = time.Now()
+}
+
+// IsSnoozed returns true if the resource is snoozed at the given time
+func (r *Resource) IsSnoozed(at time.Time) bool {
+	return r.SnoozedUntil != nil && at.Before(*r.SnoozedUntil)
+}
+
+// ApproveCleanup records that the owner approved cleaning up the resource
+func (r *Resource) ApproveCleanup(by string) {
+	now := time.Now()
+	r.CleanupApprovedAt = &now
+	r.CleanupApprovedBy = by
+	r.UpdatedAt = now
+}
+
+// Quarantine records that the resource was stopped or snapshotted instead
+// of deleted, and is deleted once the window ends unless restored before
+func (r *Resource) Quarantine(window time.Duration) {
+	now := time.Now()
+	until := now.Add(window)
+	r.QuarantinedAt = &now
+	r.QuarantineUntil = &until
+	r.UpdatedAt = now
+}
+
+// Restore ends the quarantine of the resource
+func (r *Resource) Restore() {
+	r.QuarantinedAt = nil
+	r.QuarantineUntil = nil
+	r.UpdatedAt = time.Now()
+}
+
+// IsQuarantined returns true if the resource is quarantined. Its
+// QuarantineUntil is cleared as soon as a restore is requested, so it is no
+// longer deleted while being restored.
+func (r *Resource) IsQuarantined() bool {
+	return r.QuarantinedAt != nil && r.Status != ResourceStatusDeleted
+}
+
+// QuarantineExpired returns true if the resource is quarantined and its
+// window has ended at the given time
+func (r *Resource) QuarantineExpired(at time.Time) bool {
+	return r.IsQuarantined() && r.QuarantineUntil != nil && !at.Before(*r.QuarantineUntil)
+}
+
 // IsUnused returns true if the resource is unused
 func (r *Resource) IsUnused() bool {
 	return r.Status == ResourceStatusUnused
@@ -0,0 +1,111 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResourceEventType is the kind of an event in the lifecycle of a resource
+type ResourceEventType string
+
+const (
+	// ResourceEventDiscovered is recorded when a resource first enters the
+	// inventory, found by a scan or imported
+	ResourceEventDiscovered ResourceEventType = "discovered"
+	// ResourceEventScanned is recorded for every scan that found the
+	// resource
+	ResourceEventScanned ResourceEventType = "scanned"
+	// ResourceEventStatusChanged records the previous and new status
+	ResourceEventStatusChanged ResourceEventType = "status_changed"
+	// ResourceEventCostChanged records the previous and new monthly cost
+	ResourceEventCostChanged ResourceEventType = "cost_changed"
+	// ResourceEventPolicyMatched is recorded when a policy run matched the
+	// resource
+	ResourceEventPolicyMatched ResourceEventType = "policy_matched"
+	// ResourceEventCleanupAttempted records a cleanup action run on the
+	// resource, whether it succeeded or not
+	ResourceEventCleanupAttempted ResourceEventType = "cleanup_attempted"
+	// ResourceEventCleanupRolledBack records an attempt to undo a cleanup
+	// action, whether it succeeded or not
+	ResourceEventCleanupRolledBack ResourceEventType = "cleanup_rolled_back"
+	// ResourceEventCleanupApproved records the approval of the cleanup of
+	// the resource by its owner
+	ResourceEventCleanupApproved ResourceEventType = "cleanup_approved"
+	// ResourceEventExcluded records the resource being left out of cleanups
+	// and digests, e.g. snoozed
+	ResourceEventExcluded ResourceEventType = "excluded"
+)
+
+// ResourceEventTypes lists the types of resource events
+var ResourceEventTypes = []ResourceEventType{
+	ResourceEventDiscovered,
+	ResourceEventScanned,
+	ResourceEventStatusChanged,
+	ResourceEventCostChanged,
+	ResourceEventPolicyMatched,
+	ResourceEventCleanupAttempted,
+	ResourceEventCleanupRolledBack,
+	ResourceEventCleanupApproved,
+	ResourceEventExcluded,
+}
+
+// Actors of the resource events recorded by CloudSweep itself. Events
+// requested by users name them by user ID, or by email for the links of
+// owner digests and Slack.
+const (
+	ResourceEventActorScan    = "scan"
+	ResourceEventActorBilling = "billing"
+	ResourceEventActorPolicy  = "policy"
+	ResourceEventActorCleanup = "cleanup"
+	ResourceEventActorCI      = "ci"
+	ResourceEventActorAPI     = "api" // API clients without a session
+)
+
+// ResourceEvent is an entry of the timeline of a resource. Events are only
+// ever added, so that the timeline tells why a resource is in its current
+// state, e.g. who deleted it and which policy matched it.
+type ResourceEvent struct {
+	ID             uuid.UUID         `json:"id"`
+	OrganizationID uuid.UUID         `json:"organization_id"`
+	ResourceID     uuid.UUID         `json:"resource_id"`
+	Type           ResourceEventType `json:"type"`
+	Actor          string            `json:"actor"`
+	ScanID         *uuid.UUID        `json:"scan_id,omitempty"`
+	PolicyID       *uuid.UUID        `json:"policy_id,omitempty"`
+	// TaskID is the cleanup task of attempts and rollbacks
+	TaskID     string         `json:"task_id,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+// NewResourceEvent creates an event of a resource occurring now
+func NewResourceEvent(orgID, resourceID uuid.UUID, eventType ResourceEventType, actor string, data map[string]any) *ResourceEvent {
+	return &ResourceEvent{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		ResourceID:     resourceID,
+		Type:           eventType,
+		Actor:          actor,
+		Data:           data,
+		OccurredAt:     time.Now(),
+	}
+}
+
+// NewStatusChangedEvent records the change of the status of a resource from
+// a previous one to its current one
+func NewStatusChangedEvent(r *Resource, from ResourceStatus, actor string) *ResourceEvent {
+	return NewResourceEvent(r.OrganizationID, r.ID, ResourceEventStatusChanged, actor, map[string]any{
+		"from": string(from),
+		"to":   string(r.Status),
+	})
+}
+
+// NewCostChangedEvent records the change of the monthly cost of a resource
+// from a previous one to its current one
+func NewCostChangedEvent(r *Resource, from float64, actor string) *ResourceEvent {
+	return NewResourceEvent(r.OrganizationID, r.ID, ResourceEventCostChanged, actor, map[string]any{
+		"from": from,
+		"to":   r.MonthlyCost,
+	})
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResourceView is a named filter of the resource list saved by an
+// organization. Policies and cleanups can select the resources of a view.
+type ResourceView struct {
+	ID             uuid.UUID          `json:"id"`
+	OrganizationID uuid.UUID          `json:"organization_id"`
+	Name           string             `json:"name"`
+	Description    string             `json:"description"`
+	Filters        ResourceViewFilter `json:"filters"`
+	CreatedBy      string             `json:"created_by"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// ResourceViewFilter filters the resource list, as given in the query of
+// the list or saved in a view. Empty fields do not filter.
+type ResourceViewFilter struct {
+	Provider  string `json:"provider,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Region    string `json:"region,omitempty"`
+	AccountID string `json:"account_id,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+	// Q searches names, cloud IDs and tag values, see
+	// service.ParseResourceSearch
+	Q       string  `json:"q,omitempty"`
+	MinCost float64 `json:"min_cost,omitempty"`
+	// Sort is created, cost, carbon or age, in the direction of Order, asc
+	// or desc
+	Sort  string `json:"sort,omitempty"`
+	Order string `json:"order,omitempty"`
+}
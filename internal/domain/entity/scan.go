@@ -1,6 +1,10 @@
 package entity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +17,7 @@ const (
 	ScanStatusPending    ScanStatus = "pending"
 	ScanStatusRunning    ScanStatus = "running"
 	ScanStatusCompleted  ScanStatus = "completed"
+	ScanStatusPartial    ScanStatus = "partial" // completed with some regions failing
 	ScanStatusFailed     ScanStatus = "failed"
 	ScanStatusCancelled  ScanStatus = "cancelled"
 )
@@ -24,16 +29,26 @@ type Scan struct {
 	Provider         CloudProvider   `json:"provider"`
 	Regions          []string        `json:"regions"`
 	ResourceTypes    []ResourceType  `json:"resource_types"`
+	Fingerprint      string          `json:"-"` // see ScanFingerprint
+	Mode             ScanMode        `json:"mode"`
+	ChangesSince     *time.Time      `json:"changes_since,omitempty"` // incremental scans only
 	Status           ScanStatus      `json:"status"`
 	ResourcesFound   int             `json:"resources_found"`
 	UnusedFound      int             `json:"unused_found"`
+	ResourcesNew     int             `json:"resources_new"`
+	ResourcesChanged int             `json:"resources_changed"`
+	ResourcesRemoved int             `json:"resources_removed"`
 	EstimatedSavings float64         `json:"estimated_savings"`
 	CarbonSavings    float64         `json:"carbon_savings_kg"`
 	ErrorMessage     string          `json:"error_message,omitempty"`
+	FailedRegions    map[string]string `json:"failed_regions,omitempty"` // region -> error
 	StartedAt        *time.Time      `json:"started_at,omitempty"`
 	CompletedAt      *time.Time      `json:"completed_at,omitempty"`
 	CreatedAt        time.Time       `json:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at"`
+	// Version is incremented by every update, which fails with
+	// errors.ErrConflict when the scan was updated since it was read
+	Version          int             `json:"version"`
 }
 
 // NewScan creates a new Scan
@@ -45,6 +60,8 @@ func NewScan(orgID uuid.UUID, provider CloudProvider, regions []string, resource
 		Provider:       provider,
 		Regions:        regions,
 		ResourceTypes:  resourceTypes,
+		Fingerprint:    ScanFingerprint(orgID, provider, regions, resourceTypes),
+		Mode:           ScanModeFull,
 		Status:         ScanStatusPending,
 		CreatedAt:      now,
 		UpdatedAt:      now,
@@ -71,6 +88,21 @@ func (s *Scan) Complete(resourcesFound, unusedFound int, estimatedSavings, carbo
 	s.UpdatedAt = now
 }
 
+// RecordChanges records how the inventory changed compared to the previous scan
+func (s *Scan) RecordChanges(newCount, changedCount, removedCount int) {
+	s.ResourcesNew = newCount
+	s.ResourcesChanged = changedCount
+	s.ResourcesRemoved = removedCount
+	s.UpdatedAt = time.Now()
+}
+
+// CompletePartially marks the scan as completed with the given regions failed
+func (s *Scan) CompletePartially(resourcesFound, unusedFound int, estimatedSavings, carbonSavings float64, failedRegions map[string]string) {
+	s.Complete(resourcesFound, unusedFound, estimatedSavings, carbonSavings)
+	s.Status = ScanStatusPartial
+	s.FailedRegions = failedRegions
+}
+
 // Fail marks the scan as failed
 func (s *Scan) Fail(errMsg string) {
 	now := time.Now()
@@ -89,3 +121,32 @@ func (s *Scan) IsRunning() bool {
 func (s *Scan) IsCompleted() bool {
 	return s.Status == ScanStatusCompleted
 }
+
+// ScanFingerprint identifies the scope of a scan. Regions and resource types
+// are compared as sets, so their order in the request does not matter.
+func ScanFingerprint(orgID uuid.UUID, provider CloudProvider, regions []string, resourceTypes []ResourceType) string {
+	types := make([]string, len(resourceTypes))
+	for i, t := range resourceTypes {
+		types[i] = string(t)
+	}
+	h := sha256.New()
+	h.Write([]byte(orgID.String() + "\n" + string(provider) + "\n"))
+	h.Write([]byte(strings.Join(normalizeSet(regions), ",") + "\n"))
+	h.Write([]byte(strings.Join(normalizeSet(types), ",")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func normalizeSet(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// ScanMode tells whether a scan lists every resource or only the ones the
+// provider reported as changed
+type ScanMode string
+
+const (
+	ScanModeFull        ScanMode = "full"
+	ScanModeIncremental ScanMode = "incremental"
+)
+
+// RunIncrementally makes the scan refresh only the resources changed since
+// the start of an earlier scan
+func (s *Scan) RunIncrementally(since time.Time) {
+	s.Mode = ScanModeIncremental
+	s.ChangesSince = &since
+}
+
+// RunFully makes the scan list every resource again
+func (s *Scan) RunFully() {
+	s.Mode = ScanModeFull
+	s.ChangesSince = nil
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultGroupsClaim is the ID token claim listing the groups of a user, as
+// sent by Okta and Azure AD
+const DefaultGroupsClaim = "groups"
+
+// SSOConnection is the OIDC identity provider users of an organization sign
+// in with
+type SSOConnection struct {
+	OrganizationID uuid.UUID             `json:"organization_id"`
+	Issuer         string                `json:"issuer"` // e.g. https://acme.okta.com or https://accounts.google.com
+	ClientID       string                `json:"client_id"`
+	ClientSecret   string                `json:"-"`
+	GroupsClaim    string                `json:"groups_claim"`
+	GroupRoles     map[string]MemberRole `json:"group_roles"`  // group name or ID to role
+	DefaultRole    MemberRole            `json:"default_role"` // role of users in no mapped group, empty to refuse them
+	AllowedDomains []string              `json:"allowed_domains"`
+	Enabled        bool                  `json:"enabled"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// roleRank orders roles from the least to the most privileged
+var roleRank = map[MemberRole]int{
+	MemberRoleViewer: 1,
+	MemberRoleMember: 2,
+	MemberRoleAdmin:  3,
+}
+
+// RoleForGroups returns the most privileged role mapped to one of the
+// groups. mapped is false when none of the groups is mapped, in which case
+// the default role is returned.
+func (c *SSOConnection) RoleForGroups(groups []string) (role MemberRole, mapped bool) {
+	for _, group := range groups {
+		if r, ok := c.GroupRoles[group]; ok && roleRank[r] > roleRank[role] {
+			role, mapped = r, true
+		}
+	}
+	if !mapped {
+		return c.DefaultRole, false
+	}
+	return role, true
+}
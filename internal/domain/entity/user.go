@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemberRole is the role of a user in an organization
+type MemberRole string
+
+const (
+	MemberRoleAdmin  MemberRole = "admin"  // manages settings and members
+	MemberRoleMember MemberRole = "member" // runs scans, cleanups and policies
+	MemberRoleViewer MemberRole = "viewer" // read-only access
+)
+
+// IsValid reports whether the role is known
+func (r MemberRole) IsValid() bool {
+	switch r {
+	case MemberRoleAdmin, MemberRoleMember, MemberRoleViewer:
+		return true
+	}
+	return false
+}
+
+// User represents a person with access to one or more organizations
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Membership grants a user a role in an organization
+type Membership struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Role           MemberRole `json:"role"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// DefaultInvitationTTL is how long an invitation can be accepted for
+const DefaultInvitationTTL = 7 * 24 * time.Hour
+
+// Invitation invites someone to join an organization with a role
+type Invitation struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           MemberRole `json:"role"`
+	InvitedBy      string     `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// IsPending reports whether the invitation can still be accepted
+func (i *Invitation) IsPending(at time.Time) bool {
+	return i.AcceptedAt == nil && at.Before(i.ExpiresAt)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// CleanupSessionRepository defines the interface for cleanup session
+// persistence
+type CleanupSessionRepository interface {
+	// Create creates a session with its resources, pending review in the
+	// order given
+	Create(ctx context.Context, session *entity.CleanupSession, resourceIDs []uuid.UUID) error
+
+	// GetByID retrieves a session by ID. It fails with errors.ErrNotFound
+	// when there is none.
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.CleanupSession, error)
+
+	// Lock retrieves a session and locks it until the end of the
+	// transaction of ctx. It fails with errors.ErrNotFound when there is
+	// none.
+	Lock(ctx context.Context, id uuid.UUID) (*entity.CleanupSession, error)
+
+	// UpdateStatus saves the status, dry run, execution date and task of a
+	// session when its stored status is from. It returns false when the
+	// session was not changed.
+	UpdateStatus(ctx context.Context, session *entity.CleanupSession, from entity.CleanupSessionStatus) (bool, error)
+
+	// Decide records a decision on resources of a session, made by
+	// decidedBy at decidedAt, both left empty for pending decisions
+	Decide(ctx context.Context, sessionID uuid.UUID, resourceIDs []uuid.UUID, decision entity.CleanupDecision, decidedBy string, decidedAt *time.Time) error
+
+	// ListItems retrieves resources of a session with filters, in review
+	// order, with the resource of each item
+	ListItems(ctx context.Context, filter CleanupSessionItemFilter) ([]*entity.CleanupSessionItem, error)
+
+	// CountItems counts resources of a session with filters, ignoring the
+	// limit and offset
+	CountItems(ctx context.Context, filter CleanupSessionItemFilter) (int64, error)
+
+	// Tally totals the resources of a session by decision
+	Tally(ctx context.Context, sessionID uuid.UUID) (map[entity.CleanupDecision]entity.CleanupSessionTally, error)
+}
+
+// CleanupSessionItemFilter defines filters for the resources of a session
+type CleanupSessionItemFilter struct {
+	SessionID uuid.UUID
+	Decision  *entity.CleanupDecision
+	Limit     int
+	Offset    int
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// CloudAccountRepository defines the interface for reading the cloud
+// accounts of organizations
+type CloudAccountRepository interface {
+	// List retrieves cloud accounts with filters, by provider and account
+	// ID
+	List(ctx context.Context, filter CloudAccountFilter) ([]*entity.CloudAccount, error)
+}
+
+// CloudAccountFilter defines filters for cloud account queries
+type CloudAccountFilter struct {
+	// OrganizationIDs keeps the accounts of these organizations
+	OrganizationIDs []uuid.UUID
+	Provider        *entity.CloudProvider
+}
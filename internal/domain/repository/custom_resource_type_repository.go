@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// CustomResourceTypeRepository defines the interface for reading the
+// resource types organizations define
+type CustomResourceTypeRepository interface {
+	// ListByProvider retrieves the custom resource types of an organization
+	// for a provider, sorted by name
+	ListByProvider(ctx context.Context, orgID uuid.UUID, provider entity.CloudProvider) ([]*entity.CustomResourceType, error)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// DashboardRepository defines the interface for the aggregates of the
+// resources of an organization shown on its dashboard
+type DashboardRepository interface {
+	// Totals sums the tracked resources of a scope, and those flagged
+	// unused among them
+	Totals(ctx context.Context, scope DashboardScope) (InventoryTotals, error)
+
+	// TotalsByAccount sums the tracked resources of cloud accounts, in one
+	// pass. Accounts without resources are left out.
+	TotalsByAccount(ctx context.Context, accounts []AccountKey) (map[AccountKey]InventoryTotals, error)
+
+	// Waste sums the unused resources of a scope per group
+	Waste(ctx context.Context, scope DashboardScope, grouping WasteGrouping) ([]WasteTotal, error)
+
+	// Unused lists the unused resources of a scope, most expensive first
+	Unused(ctx context.Context, scope DashboardScope, limit int) ([]*entity.Resource, error)
+}
+
+// DashboardScope is what dashboard aggregates are restricted to. Resources
+// count when they were discovered before To and still seen after From;
+// zero bounds leave the period open.
+type DashboardScope struct {
+	OrganizationID uuid.UUID
+	From, To       time.Time
+	Provider       *entity.CloudProvider
+	AccountID      *string
+}
+
+// AccountKey identifies a cloud account by the fields its resources carry
+type AccountKey struct {
+	OrganizationID uuid.UUID
+	Provider       entity.CloudProvider
+	AccountID      string
+}
+
+// InventoryTotals are the counts, monthly cost and carbon footprint of the
+// tracked resources of a scope, and of the unused ones among them
+type InventoryTotals struct {
+	Resources       int64
+	Unused          int64
+	MonthlyCost     float64
+	WasteCost       float64
+	CarbonFootprint float64
+	WasteCarbon     float64
+}
+
+// WasteGroupBy is what unused resources are grouped by
+type WasteGroupBy string
+
+const (
+	WasteByProvider WasteGroupBy = "provider"
+	WasteByType     WasteGroupBy = "type"
+	WasteByRegion   WasteGroupBy = "region"
+	WasteByAccount  WasteGroupBy = "account"
+	// WasteByTag groups by the value of a tag, resources without it
+	// together
+	WasteByTag WasteGroupBy = "tag"
+)
+
+// WasteGrouping defines how unused resources are summed
+type WasteGrouping struct {
+	By     WasteGroupBy
+	TagKey string // with WasteByTag
+	// ByCarbon ranks the groups by carbon footprint rather than cost
+	ByCarbon bool
+	Limit    int
+}
+
+// WasteTotal is the count, monthly cost and carbon footprint of a group of
+// unused resources
+type WasteTotal struct {
+	Group string
+	// Untagged is set on the group of the resources without the tag
+	Untagged        bool
+	Count           int64
+	MonthlyCost     float64
+	CarbonFootprint float64
+}
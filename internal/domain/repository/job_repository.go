@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// JobRepository defines the interface for reading the jobs of
+// organizations
+type JobRepository interface {
+	// GetByID retrieves a job by ID. It fails with errors.ErrNotFound when
+	// there is none.
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Job, error)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// OrganizationRepository defines the interface for organization persistence
+type OrganizationRepository interface {
+	// GetByID retrieves an organization by ID. It fails with
+	// errors.ErrNotFound when there is none.
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Organization, error)
+
+	// GetByIDs retrieves organizations by ID, leaving out the IDs of no
+	// organization
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Organization, error)
+
+	// Lock locks an organization until the end of the transaction of ctx,
+	// so that the changes made under the lock do not run concurrently. It
+	// fails with errors.ErrNotFound when there is none.
+	Lock(ctx context.Context, id uuid.UUID) error
+
+	// Usage returns the consumption of an organization, by quota. Daily
+	// quotas count from the start of the quota day of now.
+	Usage(ctx context.Context, orgID uuid.UUID, now time.Time) (map[entity.Quota]int64, error)
+}
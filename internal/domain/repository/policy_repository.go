@@ -12,20 +12,44 @@ type PolicyRepository interface {
 	// Create creates a new policy
 	Create(ctx context.Context, policy *entity.Policy) error
 
-	// Update updates an existing policy
+	// Update updates the definition of an existing policy and increments
+	// its version. Its organization, external ID and enabled state are
+	// left unchanged. A policy with a version is only updated at that
+	// version, failing with errors.ErrConflict if it was updated since; it
+	// fails with errors.ErrNotFound when there is none.
 	Update(ctx context.Context, policy *entity.Policy) error
 
-	// Delete deletes a policy by ID
+	// SetEnabled enables or disables a policy and increments its version.
+	// It fails with errors.ErrNotFound when there is none.
+	SetEnabled(ctx context.Context, id uuid.UUID, enabled bool) error
+
+	// Delete deletes a policy by ID, which can be restored. It fails with
+	// errors.ErrNotFound when there is none.
 	Delete(ctx context.Context, id uuid.UUID) error
 
-	// GetByID retrieves a policy by ID
+	// Restore restores a deleted policy with its enabled state and
+	// increments its version
+	Restore(ctx context.Context, policy *entity.Policy) error
+
+	// GetByID retrieves a policy by ID. It fails with errors.ErrNotFound
+	// when there is none.
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Policy, error)
 
-	// List retrieves policies with filters
+	// GetDeleted retrieves a deleted policy by ID. It fails with
+	// errors.ErrNotFound when there is none.
+	GetDeleted(ctx context.Context, id uuid.UUID) (*entity.Policy, error)
+
+	// List retrieves policies with filters, most recent first
 	List(ctx context.Context, filter PolicyFilter) ([]*entity.Policy, error)
 
+	// Count counts policies with filters, ignoring the limit and offset
+	Count(ctx context.Context, filter PolicyFilter) (int64, error)
+
 	// GetEnabledByOrg retrieves all enabled policies for an organization
 	GetEnabledByOrg(ctx context.Context, orgID uuid.UUID) ([]*entity.Policy, error)
+
+	// Names retrieves the names of policies by ID, deleted ones included
+	Names(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error)
 }
 
 // PolicyFilter defines filters for policy queries
@@ -33,6 +57,11 @@ type PolicyFilter struct {
 	OrganizationID *uuid.UUID
 	Provider       *entity.CloudProvider
 	IsEnabled      *bool
-	Limit          int
-	Offset         int
+	ExternalID     *string
+	// Managed keeps the policies with an external ID
+	Managed bool
+	// Deleted lists the deleted policies instead
+	Deleted bool
+	Limit   int
+	Offset  int
 }
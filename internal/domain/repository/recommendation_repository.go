@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// RecommendationRepository defines the interface for reading the
+// recommendations of organizations
+type RecommendationRepository interface {
+	// List retrieves recommendations with filters, with their resource
+	List(ctx context.Context, filter RecommendationFilter) ([]*entity.Recommendation, error)
+}
+
+// RecommendationFilter defines filters for recommendation queries
+type RecommendationFilter struct {
+	OrganizationID *uuid.UUID
+	Status         *entity.RecommendationStatus
+}
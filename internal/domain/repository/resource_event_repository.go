@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// ResourceEventRepository defines the interface for recording the timeline
+// of resources
+type ResourceEventRepository interface {
+	// Record adds events to the timelines of their resources. The resources
+	// must be stored already.
+	Record(ctx context.Context, events ...*entity.ResourceEvent) error
+
+	// List retrieves events with filters, newest first
+	List(ctx context.Context, filter ResourceEventFilter) ([]*entity.ResourceEvent, error)
+}
+
+// ResourceEventFilter defines filters for resource events
+type ResourceEventFilter struct {
+	// ResourceID keeps the timeline of this resource, unless it is nil
+	ResourceID uuid.UUID
+	// TaskID keeps the events of this cleanup task, unless it is empty
+	TaskID string
+	// Types keeps the events of these types, all of them when empty
+	Types []entity.ResourceEventType
+	// After keeps the events older than this one, for keyset pagination
+	After *ResourceEventKey
+	Limit int
+}
+
+// ResourceEventKey is the position of an event in a timeline
+type ResourceEventKey struct {
+	OccurredAt time.Time
+	ID         uuid.UUID
+}
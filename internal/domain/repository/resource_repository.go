@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
 	"github.com/google/uuid"
@@ -24,26 +25,112 @@ type ResourceRepository interface {
 	// GetByResourceID retrieves a resource by cloud resource ID
 	GetByResourceID(ctx context.Context, orgID uuid.UUID, provider entity.CloudProvider, resourceID string) (*entity.Resource, error)
 
-	// List retrieves resources with filters
+	// GetByResourceIDs retrieves the resources of an organization with these
+	// cloud resource IDs. Those not stored are left out.
+	GetByResourceIDs(ctx context.Context, orgID uuid.UUID, refs []CloudResourceRef) ([]*entity.Resource, error)
+
+	// SetStatus sets the status of a resource when it has one of the
+	// statuses from, or any status when from is empty. It returns false when
+	// the resource was not changed.
+	SetStatus(ctx context.Context, id uuid.UUID, to entity.ResourceStatus, from ...entity.ResourceStatus) (bool, error)
+
+	// CancelQuarantine clears the end of the quarantine of a quarantined
+	// resource that is not deleted, so that it is not purged. It returns
+	// false when the resource is not quarantined.
+	CancelQuarantine(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// List retrieves resources with filters, in the order of the filter
 	List(ctx context.Context, filter ResourceFilter) ([]*entity.Resource, error)
 
-	// Count counts resources with filters
+	// ListByScope retrieves every resource of an organization and provider in
+	// the given regions, including deleted ones. An empty resourceTypes
+	// matches all types.
+	ListByScope(ctx context.Context, orgID uuid.UUID, provider entity.CloudProvider, regions []string, resourceTypes []entity.ResourceType) ([]*entity.Resource, error)
+
+	// Count counts resources with filters, ignoring the limit, offset and
+	// cursor
 	Count(ctx context.Context, filter ResourceFilter) (int64, error)
 
-	// BulkCreate creates multiple resources
-	BulkCreate(ctx context.Context, resources []*entity.Resource) error
+	// CountTracked counts the resources of an organization that are not
+	// deleted
+	CountTracked(ctx context.Context, orgID uuid.UUID) (int64, error)
+
+	// BulkCreate creates multiple resources, a chunk of rows per statement.
+	// A resource with the same organization, provider and cloud resource ID
+	// as a stored one updates it instead, e.g. when a scan is retried.
+	// progress, which may be nil, is called after each chunk.
+	BulkCreate(ctx context.Context, resources []*entity.Resource, progress ProgressFunc) error
+
+	// BulkUpsert creates resources, or updates them when a resource with the
+	// same organization, provider and cloud resource ID already exists.
+	// progress, which may be nil, is called after each chunk.
+	BulkUpsert(ctx context.Context, resources []*entity.Resource, progress ProgressFunc) error
 
 	// BulkUpdate updates multiple resources
 	BulkUpdate(ctx context.Context, resources []*entity.Resource) error
 }
 
+// ProgressFunc reports the progress of a bulk operation: done of total
+// items are saved
+type ProgressFunc func(done, total int)
+
 // ResourceFilter defines filters for resource queries
 type ResourceFilter struct {
 	OrganizationID *uuid.UUID
-	Provider       *entity.CloudProvider
-	Type           *entity.ResourceType
-	Status         *entity.ResourceStatus
-	Region         *string
-	Limit          int
-	Offset         int
+	// IDs, when not nil, keeps these resources
+	IDs      []uuid.UUID
+	Provider *entity.CloudProvider
+	Type     *entity.ResourceType
+	// Types keeps the resources of any of these types
+	Types  []entity.ResourceType
+	Status *entity.ResourceStatus
+	// Tracked leaves out the resources deleted from the cloud or removed
+	// from the inventory
+	Tracked   bool
+	Region    *string
+	AccountID *string
+	// Owner is matched case-insensitively
+	Owner *string
+	// MinCost keeps the resources costing at least this much per month
+	MinCost float64
+	// Search is a search of names, cloud IDs and tags, in the syntax of
+	// service.ParseResourceSearch
+	Search string
+	Sort   ResourceSort
+	// After keeps the resources listed after this one, for keyset
+	// pagination
+	After  *ResourceKey
+	Limit  int
+	Offset int
+}
+
+// CloudResourceRef identifies a resource of an organization in its cloud
+type CloudResourceRef struct {
+	Provider   entity.CloudProvider
+	ResourceID string
+}
+
+// ResourceSortField is what resources are sorted by, then by ID
+type ResourceSortField string
+
+const (
+	ResourceSortCreated ResourceSortField = "created"
+	ResourceSortCost    ResourceSortField = "cost"
+	ResourceSortCarbon  ResourceSortField = "carbon"
+)
+
+// ResourceSort is the order of resource lists. The zero value lists the
+// most recent resources first.
+type ResourceSort struct {
+	Field ResourceSortField
+	Asc   bool
+}
+
+// ResourceKey is the position of a resource in lists: its creation date
+// in lists sorted by creation, or its cost or carbon footprint in Value,
+// then its ID
+type ResourceKey struct {
+	CreatedAt time.Time
+	Value     float64
+	ID        uuid.UUID
 }
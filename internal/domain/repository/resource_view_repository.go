@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// ResourceViewRepository defines the interface for reading the resource
+// views organizations save
+type ResourceViewRepository interface {
+	// GetByID retrieves a view by ID. It fails with errors.ErrNotFound when
+	// there is none.
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ResourceView, error)
+}
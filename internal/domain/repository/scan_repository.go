@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
 	"github.com/google/uuid"
@@ -15,14 +16,34 @@ type ScanRepository interface {
 	// Update updates an existing scan
 	Update(ctx context.Context, scan *entity.Scan) error
 
-	// GetByID retrieves a scan by ID
+	// Delete deletes a scan by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetByID retrieves a scan by ID. It fails with errors.ErrNotFound when
+	// there is none.
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Scan, error)
 
-	// List retrieves scans with filters
+	// List retrieves scans with filters, most recent first
 	List(ctx context.Context, filter ScanFilter) ([]*entity.Scan, error)
 
+	// Count counts scans with filters, ignoring the limit, offset and cursor
+	Count(ctx context.Context, filter ScanFilter) (int64, error)
+
+	// FindActive retrieves the most recent pending or running scan with the
+	// given fingerprint, ignoring the scan with ID exclude. It returns nil
+	// when there is none.
+	FindActive(ctx context.Context, fingerprint string, exclude uuid.UUID) (*entity.Scan, error)
+
 	// GetLatestByOrg retrieves the latest scan for an organization
 	GetLatestByOrg(ctx context.Context, orgID uuid.UUID) (*entity.Scan, error)
+
+	// CountSince counts the scans of an organization created since a time
+	CountSince(ctx context.Context, orgID uuid.UUID, since time.Time) (int64, error)
+
+	// LastCompleted retrieves the completed scan with the given fingerprint
+	// that started last, of any mode when mode is empty. Partial scans are
+	// left out. It returns nil when there is none.
+	LastCompleted(ctx context.Context, fingerprint string, mode entity.ScanMode) (*entity.Scan, error)
 }
 
 // ScanFilter defines filters for scan queries
@@ -30,6 +51,14 @@ type ScanFilter struct {
 	OrganizationID *uuid.UUID
 	Provider       *entity.CloudProvider
 	Status         *entity.ScanStatus
-	Limit          int
-	Offset         int
+	// After keeps the scans listed after this one, for keyset pagination
+	After  *ScanKey
+	Limit  int
+	Offset int
+}
+
+// ScanKey is the position of a scan in lists
+type ScanKey struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
 }
@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// UnitOfWork runs repository operations in a single transaction
+type UnitOfWork interface {
+	// Do runs fn in a transaction, committed when fn returns nil and rolled
+	// back when it returns an error or panics. Repository calls made with
+	// the context passed to fn take part in the transaction; calling Do
+	// again with that context nests a savepoint.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,22 @@
+package service
+
+import "context"
+
+// DiscoveredAccount is an account found under an organization-level
+// integration, e.g. a member account of an AWS Organization
+type DiscoveredAccount struct {
+	ID   string
+	Name string
+	// Active is false for accounts the provider reports suspended or being
+	// closed; they are kept but no longer scanned
+	Active bool
+	// Credentials are the cloud account credentials the scanners of the
+	// account use, in the format of the provider
+	Credentials []byte
+}
+
+// AccountDiscoverer lists the accounts reachable through an
+// organization-level integration
+type AccountDiscoverer interface {
+	Discover(ctx context.Context) ([]DiscoveredAccount, error)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// PermissionPurposeScan is the purpose of the permissions scans call; the
+// other purposes are cleanup actions
+const PermissionPurposeScan = "scan"
+
+// PermissionCheck is the outcome of checking the permissions of a cloud
+// account for a scan or a cleanup action
+type PermissionCheck struct {
+	Purpose  string
+	Required []string
+	Missing  []string
+}
+
+// accountActions are the cleanup actions whose permissions are checked, in
+// the order of the report
+var accountActions = []entity.PolicyAction{
+	entity.PolicyActionStop,
+	entity.PolicyActionTag,
+	entity.PolicyActionQuarantine,
+	entity.PolicyActionDelete,
+	entity.PolicyActionRelease,
+}
+
+// AccountPermissions returns the permissions a scan and each cleanup action
+// call on resource types, for the account of a provider. Purposes calling
+// none, e.g. stop on a provider without stoppable types, are left out.
+func AccountPermissions(provider entity.CloudProvider, types []entity.ResourceType) []PermissionCheck {
+	var scan []string
+	for _, t := range types {
+		scan = appendMissing(scan, resourcePermissions[t].scan...)
+	}
+	var checks []PermissionCheck
+	if len(scan) > 0 {
+		checks = append(checks, PermissionCheck{Purpose: PermissionPurposeScan, Required: scan})
+	}
+	for _, action := range accountActions {
+		var required []string
+		for _, t := range types {
+			if action == entity.PolicyActionRelease && !t.IsPublicIP() {
+				continue
+			}
+			// Any of these may have DNS records pointing at it
+			dns := t.IsPublicIP() || t.IsLoadBalancer() || t.IsObjectStorage()
+			required = appendMissing(required, actionPermissions(t, provider, action, dns)...)
+		}
+		if len(required) > 0 {
+			checks = append(checks, PermissionCheck{Purpose: string(action), Required: required})
+		}
+	}
+	return checks
+}
+
+// ScanPermissionsOverride is implemented by the permission checkers of
+// accounts whose scans may not call the permissions of AccountPermissions,
+// e.g. AWS accounts listed from an inventory
+type ScanPermissionsOverride interface {
+	// ScanPermissions returns the permissions the scans of the account
+	// call. ok is false when they are those of AccountPermissions.
+	ScanPermissions() (permissions []string, ok bool)
+}
+
+// CheckAccountPermissions checks with the checker of an account which of
+// the permissions of AccountPermissions its credentials lack. The checker
+// is asked once for all of them.
+func CheckAccountPermissions(ctx context.Context, checker PermissionChecker, provider entity.CloudProvider, types []entity.ResourceType) ([]PermissionCheck, error) {
+	checks := AccountPermissions(provider, types)
+	if override, ok := checker.(ScanPermissionsOverride); ok {
+		if scan, ok := override.ScanPermissions(); ok {
+			checks = slices.DeleteFunc(checks, func(c PermissionCheck) bool { return c.Purpose == PermissionPurposeScan })
+			checks = slices.Insert(checks, 0, PermissionCheck{Purpose: PermissionPurposeScan, Required: scan})
+		}
+	}
+	var all []string
+	for _, check := range checks {
+		all = appendMissing(all, check.Required...)
+	}
+	if len(all) == 0 {
+		return checks, nil
+	}
+	missing, err := checker.MissingPermissions(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+	for i, check := range checks {
+		for _, p := range check.Required {
+			if slices.Contains(missing, p) {
+				checks[i].Missing = append(checks[i].Missing, p)
+			}
+		}
+	}
+	return checks, nil
+}
+
+// appendMissing appends the values not in list yet
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
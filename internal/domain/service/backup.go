@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Tags set on the backups taken before deleting resources
+const (
+	BackupTagJob         = "cloudsweep:cleanup-job"  // task of the cleanup that took the backup
+	BackupTagDeleteAfter = "cloudsweep:delete-after" // end of the retention, as 2006-01-02
+)
+
+// IdleReasonBackupExpired is the reason a backup taken before deleting a
+// resource is reported as unused once its retention has ended
+const IdleReasonBackupExpired = "backup_expired"
+
+// Default waits for backups to complete
+const (
+	DefaultBackupPollInterval = 15 * time.Second
+	DefaultBackupTimeout      = 20 * time.Minute
+)
+
+// BackupState is the progress of a backup as reported by the provider
+type BackupState string
+
+const (
+	BackupStatePending   BackupState = "pending"
+	BackupStateCompleted BackupState = "completed"
+	BackupStateFailed    BackupState = "failed"
+)
+
+// BackupTaker is implemented by cleaners that can snapshot volumes, disks
+// and databases with tags, and tell when the snapshot has completed
+type BackupTaker interface {
+	Backup(ctx context.Context, resource *entity.Resource, snapshotID string, tags map[string]string) (*CleanupResult, error)
+	BackupState(ctx context.Context, resource *entity.Resource, snapshotID string) (BackupState, error)
+}
+
+// BackupOptions configure BackupThenDelete
+type BackupOptions struct {
+	// TaskID is the cleanup task, tagged on the backup
+	TaskID string
+	// Retention is how long the backup is kept,
+	// entity.DefaultBackupRetentionDays when zero
+	Retention time.Duration
+	// PollInterval and Timeout bound the wait for the backup to complete,
+	// DefaultBackupPollInterval and DefaultBackupTimeout when zero
+	PollInterval time.Duration
+	Timeout      time.Duration
+	Now          time.Time
+}
+
+// backupGBMonthPrices are the list prices of the snapshots of volumes,
+// disks and databases, in USD per GB-month
+var backupGBMonthPrices = map[entity.ResourceType]float64{
+	entity.ResourceTypeEBSVolume:   0.05,
+	entity.ResourceTypeAzureDisk:   0.05,
+	entity.ResourceTypeGCEDisk:     0.05,
+	entity.ResourceTypeRDSInstance: 0.095,
+	entity.ResourceTypeAzureSQL:    0.05,
+	entity.ResourceTypeCloudSQL:    0.08,
+}
+
+// NeedsBackup returns true for the resource types backed up before being
+// deleted in backup-then-delete mode
+func NeedsBackup(t entity.ResourceType) bool {
+	return snapshotTypes[t] || t.IsManagedDatabase()
+}
+
+// BackupCost returns the monthly cost of the backup of a resource: a full
+// snapshot of its allocated storage. It is zero when the size is unknown.
+func BackupCost(resource *entity.Resource) float64 {
+	size, _ := metadataFloat(resource.Metadata[VolumeMetadataSizeGB])
+	price, ok := backupGBMonthPrices[resource.Type]
+	if !ok {
+		price = snapshotGBMonthPrices[entity.ResourceTypeEBSSnapshot]["standard"]
+	}
+	return size * price
+}
+
+// BackupID returns the ID of the backup taken before deleting a resource
+func BackupID(resource *entity.Resource, now time.Time) string {
+	id := FinalSnapshotID(resource, now)
+	return "cloudsweep-backup-" + id[len("cloudsweep-final-"):]
+}
+
+// BackupThenDelete snapshots a volume, disk or database, tagged with the
+// cleanup task and the end of its retention, waits for the snapshot to
+// complete, then deletes the resource. Nothing is deleted when the backup
+// fails or does not complete in time. The savings of the result are net of
+// the cost of the backup, and its recovery restores from it until the
+// retention ends.
+func BackupThenDelete(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource, opts BackupOptions) (*CleanupResult, error) {
+	taker, ok := cleaner.(BackupTaker)
+	if !ok {
+		return nil, fmt.Errorf("cannot back up %s", resource.Type)
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = entity.BackupSettings{}.Retention()
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultBackupPollInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultBackupTimeout
+	}
+
+	id := BackupID(resource, opts.Now)
+	until := opts.Now.Add(opts.Retention)
+	tags := map[string]string{BackupTagDeleteAfter: until.UTC().Format("2006-01-02")}
+	if opts.TaskID != "" {
+		tags[BackupTagJob] = opts.TaskID
+	}
+	if result, err := taker.Backup(ctx, resource, id, tags); err != nil || !result.Success {
+		return result, err
+	}
+	if err := waitForBackup(ctx, taker, resource, id, opts); err != nil {
+		return nil, err
+	}
+
+	result, err := cleaner.Delete(ctx, resource)
+	if err != nil || !result.Success {
+		return result, err
+	}
+	cost := BackupCost(resource)
+	result.BackupID = id
+	result.BackupCost = cost
+	result.CostSaved = resource.MonthlyCost - cost
+	result.CarbonSaved = resource.CarbonFootprint
+	result.Recovery = snapshotRecovery(resource, id)
+	result.Recovery.UndoUntil = &until
+	return result, nil
+}
+
+// waitForBackup polls the state of a backup until it completes
+func waitForBackup(ctx context.Context, taker BackupTaker, resource *entity.Resource, id string, opts BackupOptions) error {
+	deadline := time.NewTimer(opts.Timeout)
+	defer deadline.Stop()
+	for {
+		state, err := taker.BackupState(ctx, resource, id)
+		if err != nil {
+			return fmt.Errorf("failed to check backup %s: %w", id, err)
+		}
+		switch state {
+		case BackupStateCompleted:
+			return nil
+		case BackupStateFailed:
+			return fmt.Errorf("backup %s failed, the resource was not deleted", id)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("backup %s did not complete within %s, the resource was not deleted", id, opts.Timeout)
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+// backupExpired returns true for the backups taken before deleting a
+// resource whose retention has ended
+func backupExpired(r *entity.Resource, now time.Time) bool {
+	after, ok := r.Tags[BackupTagDeleteAfter]
+	if !ok {
+		return false
+	}
+	until, err := time.Parse("2006-01-02", after)
+	return err == nil && !now.Before(until)
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// BilledCost is the actual cost of a resource over a period, as billed by
+// its provider
+type BilledCost struct {
+	// ResourceID is the resource as the bill names it: an ID, an ARN or a
+	// full resource path depending on the provider
+	ResourceID string
+	Amount     float64
+	Currency   string
+}
+
+// BillingSource reads the amortized costs of the resources of a cloud
+// account from the billing data of its provider
+type BillingSource interface {
+	// ResourceCosts returns the costs of the resources billed between start
+	// and end, one entry per resource and currency
+	ResourceCosts(ctx context.Context, start, end time.Time) ([]BilledCost, error)
+}
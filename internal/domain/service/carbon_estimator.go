@@ -0,0 +1,390 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys the carbon estimator reads on compute resources, besides the
+// instance type. Scanners set them when the type alone does not tell.
+const (
+	ComputeMetadataVCPUs    = "vcpus"
+	ComputeMetadataMemoryGB = "memory_gb"
+	ComputeMetadataCPUAvg   = "avg_cpu_percent" // average CPU utilization over the lookback window
+	ComputeMetadataState    = "state"           // provider state, e.g. "running", "stopped"
+)
+
+// The power model follows the Cloud Carbon Footprint methodology: compute
+// draws between the min and max watts per vCPU of its microarchitecture
+// depending on utilization, memory a fixed amount per GB and storage a fixed
+// amount per TB, multiplied by the replication of the service.
+const (
+	defaultCPUUtilization = 0.5
+	wattsPerMemoryGB      = 0.392
+	wattsPerSSDGB         = 0.0012  // 1.2 Wh per TB-hour
+	wattsPerHDDGB         = 0.00065 // 0.65 Wh per TB-hour
+	memoryGBPerVCPU       = 4.0     // general purpose ratio, used when memory is unknown
+)
+
+// DefaultGridIntensity is the world average carbon intensity of electricity,
+// in kgCO2e per kWh, used for regions missing from the grid table
+const DefaultGridIntensity = 0.475
+
+// DefaultPUE are the power usage effectiveness of the providers' data
+// centers. Kubernetes clusters can run anywhere and get the average.
+var DefaultPUE = map[entity.CloudProvider]float64{
+	entity.CloudProviderAWS:        1.135,
+	entity.CloudProviderAzure:      1.185,
+	entity.CloudProviderGCP:        1.1,
+	entity.CloudProviderKubernetes: 1.135,
+}
+
+// cpuPower is the power draw of one vCPU of a microarchitecture, idle and
+// at full load, in watts
+type cpuPower struct {
+	min, max float64
+}
+
+var microarchitectures = map[string]cpuPower{
+	"haswell":      {1.00, 4.74},
+	"broadwell":    {0.71, 3.69},
+	"skylake":      {0.65, 4.26},
+	"cascade_lake": {0.64, 3.97},
+	"ice_lake":     {0.69, 3.76},
+	"epyc_1":       {0.82, 2.55},
+	"epyc_2":       {0.47, 1.64},
+	"epyc_3":       {0.45, 2.02},
+	"graviton":     {0.47, 1.69},
+	"graviton2":    {0.47, 1.69},
+}
+
+// providerCPUPower is the average over the microarchitectures each provider
+// runs, for instance families missing from the family table
+var providerCPUPower = map[entity.CloudProvider]cpuPower{
+	entity.CloudProviderAWS:        {0.74, 3.5},
+	entity.CloudProviderAzure:      {0.78, 3.76},
+	entity.CloudProviderGCP:        {0.71, 4.26},
+	entity.CloudProviderKubernetes: {0.74, 3.5},
+}
+
+// instanceFamilies maps instance families to their microarchitecture
+var instanceFamilies = map[entity.CloudProvider]map[string]string{
+	entity.CloudProviderAWS: {
+		"t2": "haswell", "m4": "broadwell", "c4": "haswell", "r4": "broadwell",
+		"t3": "skylake", "m5": "cascade_lake", "c5": "cascade_lake", "r5": "cascade_lake",
+		"m6i": "ice_lake", "c6i": "ice_lake", "r6i": "ice_lake",
+		"t3a": "epyc_1", "m5a": "epyc_1", "c5a": "epyc_2", "r5a": "epyc_1",
+		"m6a": "epyc_3", "c6a": "epyc_3", "r6a": "epyc_3",
+		"a1": "graviton", "t4g": "graviton2", "m6g": "graviton2", "c6g": "graviton2", "r6g": "graviton2",
+		"m7g": "graviton2", "c7g": "graviton2", "r7g": "graviton2",
+	},
+	entity.CloudProviderAzure: {
+		"Dv2": "haswell", "Dv3": "broadwell", "Dsv3": "broadwell", "Ev3": "broadwell", "Esv3": "broadwell",
+		"Dv4": "cascade_lake", "Dsv4": "cascade_lake", "Ev4": "cascade_lake", "Esv4": "cascade_lake",
+		"Dv5": "ice_lake", "Dsv5": "ice_lake", "Ev5": "ice_lake", "Esv5": "ice_lake",
+		"Dasv4": "epyc_2", "Easv4": "epyc_2", "Dasv5": "epyc_3", "Easv5": "epyc_3",
+		"Fsv2": "cascade_lake", "B": "broadwell",
+	},
+	entity.CloudProviderGCP: {
+		"n1": "skylake", "n2": "cascade_lake", "c2": "cascade_lake", "m1": "skylake",
+		"n2d": "epyc_2", "c2d": "epyc_3", "t2d": "epyc_3", "e2": "skylake",
+	},
+}
+
+// awsSizeVCPUs are the vCPUs of EC2 and RDS sizes; "Nxlarge" sizes have
+// 4N vCPUs
+var awsSizeVCPUs = map[string]float64{
+	"nano": 2, "micro": 2, "small": 2, "medium": 2, "large": 2, "xlarge": 4, "metal": 96,
+}
+
+// awsMemoryPerVCPU is the memory of EC2 families relative to their vCPUs
+var awsMemoryPerVCPU = map[byte]float64{'c': 2, 'm': 4, 't': 2, 'r': 8, 'x': 16, 'z': 8}
+
+// gceMemoryPerVCPU is the memory of GCE machine types relative to their vCPUs
+var gceMemoryPerVCPU = map[string]float64{"standard": 4, "highmem": 8, "highcpu": 1, "megamem": 14, "ultramem": 24}
+
+var (
+	awsSizeMultiple  = regexp.MustCompile(`^(\d+)xlarge$`)
+	azureSizePattern = regexp.MustCompile(`^Standard_([A-Z]+)(\d+)([a-z]*)(?:_(v\d+))?$`)
+	gceSizePattern   = regexp.MustCompile(`^([a-z0-9]+)-([a-z]+)-(\d+)$`)
+	azureVCores      = regexp.MustCompile(`_(\d+)$`)
+)
+
+// gridIntensity is the carbon intensity of the electricity grid of each
+// provider region, in kgCO2e per kWh
+var gridIntensity = map[entity.CloudProvider]map[string]float64{
+	entity.CloudProviderAWS: {
+		"us-east-1": 0.379, "us-east-2": 0.411, "us-west-1": 0.322, "us-west-2": 0.322,
+		"ca-central-1": 0.00013, "sa-east-1": 0.0617,
+		"eu-west-1": 0.279, "eu-west-2": 0.225, "eu-west-3": 0.0511, "eu-central-1": 0.311,
+		"eu-north-1": 0.0088, "eu-south-1": 0.213,
+		"ap-south-1": 0.708, "ap-east-1": 0.71, "ap-northeast-1": 0.466, "ap-northeast-2": 0.416,
+		"ap-northeast-3": 0.466, "ap-southeast-1": 0.408, "ap-southeast-2": 0.79,
+		"me-south-1": 0.506, "af-south-1": 0.9,
+	},
+	entity.CloudProviderAzure: {
+		"eastus": 0.379, "eastus2": 0.379, "centralus": 0.426, "northcentralus": 0.426,
+		"southcentralus": 0.373, "westus": 0.322, "westus2": 0.322, "westus3": 0.322,
+		"canadacentral": 0.00013, "brazilsouth": 0.0617,
+		"northeurope": 0.279, "westeurope": 0.328, "uksouth": 0.225, "ukwest": 0.225,
+		"francecentral": 0.0511, "germanywestcentral": 0.311, "swedencentral": 0.0088,
+		"norwayeast": 0.0076, "switzerlandnorth": 0.0117,
+		"centralindia": 0.708, "eastasia": 0.71, "southeastasia": 0.408, "japaneast": 0.466,
+		"koreacentral": 0.416, "australiaeast": 0.79,
+	},
+	entity.CloudProviderGCP: {
+		"us-central1": 0.454, "us-east1": 0.48, "us-east4": 0.361, "us-west1": 0.078,
+		"us-west2": 0.253, "us-west3": 0.533, "us-west4": 0.455,
+		"northamerica-northeast1": 0.0, "southamerica-east1": 0.103,
+		"europe-west1": 0.127, "europe-west2": 0.172, "europe-west3": 0.311, "europe-west4": 0.39,
+		"europe-west6": 0.0111, "europe-north1": 0.133,
+		"asia-east1": 0.456, "asia-east2": 0.36, "asia-northeast1": 0.506, "asia-northeast3": 0.5,
+		"asia-south1": 0.67, "asia-southeast1": 0.372, "australia-southeast1": 0.598,
+	},
+}
+
+// stoppedInstanceStates are provider states of instances and databases
+// whose compute is not running
+var stoppedInstanceStates = map[string]bool{
+	"stopped":     true,
+	"stopping":    true,
+	"paused":      true,
+	"disabled":    true,
+	"deallocated": true, // Azure VMs
+	"terminated":  true, // GCE instances stopped
+}
+
+// storageReplication is how many copies providers keep of stored data
+var storageReplication = map[entity.ResourceType]float64{
+	entity.ResourceTypeEBSVolume:          2,
+	entity.ResourceTypeEBSSnapshot:        3,
+	entity.ResourceTypeAzureDisk:          3,
+	entity.ResourceTypeAzureSnapshot:      3,
+	entity.ResourceTypeGCEDisk:            2,
+	entity.ResourceTypeS3Bucket:           3,
+	entity.ResourceTypeAzureBlobContainer: 3,
+	entity.ResourceTypeGCSBucket:          2,
+	entity.ResourceTypeRDSInstance:        2,
+	entity.ResourceTypeAzureSQL:           3,
+	entity.ResourceTypeCloudSQL:           2,
+	entity.ResourceTypeK8sPVC:             1,
+}
+
+// hddVolumeTypes are block storage types backed by hard drives
+var hddVolumeTypes = map[string]bool{
+	"st1": true, "sc1": true, "standard": true, // EBS
+	"Standard_LRS": true, "Standard_ZRS": true, // Azure managed disks
+	"pd-standard": true, // GCE persistent disks
+}
+
+// CarbonEstimator estimates the monthly carbon footprint of resources from
+// their power draw and the carbon intensity of the grid of their region. It
+// is shared by all providers so footprints can be compared and summed.
+type CarbonEstimator struct {
+	pue       map[entity.CloudProvider]float64
+	intensity map[string]float64
+}
+
+// NewCarbonEstimator creates a CarbonEstimator. pue overrides the power
+// usage effectiveness of providers and intensity the grid intensity of
+// regions, in kgCO2e per kWh, keyed by region name.
+func NewCarbonEstimator(pue, intensity map[string]float64) *CarbonEstimator {
+	e := &CarbonEstimator{
+		pue:       make(map[entity.CloudProvider]float64, len(DefaultPUE)),
+		intensity: make(map[string]float64, len(intensity)),
+	}
+	for p, v := range DefaultPUE {
+		e.pue[p] = v
+	}
+	for p, v := range pue {
+		if v >= 1 {
+			e.pue[entity.CloudProvider(strings.ToLower(p))] = v
+		}
+	}
+	for region, v := range intensity {
+		if v >= 0 {
+			e.intensity[region] = v
+		}
+	}
+	return e
+}
+
+// Estimate returns the monthly carbon footprint of a resource, in kgCO2e.
+// Resources drawing no measurable power, such as IP addresses, get 0.
+func (e *CarbonEstimator) Estimate(r *entity.Resource) float64 {
+	kwh := e.Watts(r) * entity.HoursPerMonth / 1000 * e.PUE(r.Provider)
+	return kwh * e.GridIntensity(r.Provider, r.Region)
+}
+
+// PUE returns the power usage effectiveness of a provider's data centers
+func (e *CarbonEstimator) PUE(provider entity.CloudProvider) float64 {
+	if v, ok := e.pue[provider]; ok {
+		return v
+	}
+	return DefaultPUE[entity.CloudProviderKubernetes]
+}
+
+// GridIntensity returns the carbon intensity of the grid of a region, in
+// kgCO2e per kWh
+func (e *CarbonEstimator) GridIntensity(provider entity.CloudProvider, region string) float64 {
+	if v, ok := e.intensity[region]; ok {
+		return v
+	}
+	if v, ok := gridIntensity[provider][region]; ok {
+		return v
+	}
+	return DefaultGridIntensity
+}
+
+// Watts returns the average power drawn by a resource, before PUE
+func (e *CarbonEstimator) Watts(r *entity.Resource) float64 {
+	switch {
+	case r.Type.IsManagedDatabase():
+		class, _ := r.Metadata[DatabaseMetadataInstanceClass].(string)
+		return e.computeWatts(r, databaseShape(r.Type, class)) + storageWatts(r, false)
+	case r.Type.IsStoppable(), r.Type == entity.ResourceTypeK8sDeployment:
+		instanceType, _ := r.Metadata[ComputeMetadataInstanceType].(string)
+		return e.computeWatts(r, instanceShape(r.Provider, instanceType))
+	case r.Type.IsObjectStorage():
+		var bytes int64
+		for _, n := range StorageClassBytes(r.Metadata) {
+			bytes += n
+		}
+		return float64(bytes) / (1 << 30) * wattsPerHDDGB * storageReplication[r.Type]
+	case r.Type.IsSnapshot():
+		return storageWatts(r, true)
+	case storageReplication[r.Type] > 0:
+		volumeType, _ := r.Metadata[VolumeMetadataType].(string)
+		return storageWatts(r, hddVolumeTypes[volumeType])
+	}
+	return 0
+}
+
+// shape is the compute capacity of an instance type
+type shape struct {
+	vcpus, memoryGB float64
+	power           cpuPower
+}
+
+// computeWatts returns the power drawn by an instance. Metadata set by the
+// scanner takes precedence over the shape of the instance type, and stopped
+// instances draw nothing.
+func (e *CarbonEstimator) computeWatts(r *entity.Resource, s shape) float64 {
+	if state, _ := r.Metadata[ComputeMetadataState].(string); stoppedInstanceStates[strings.ToLower(state)] {
+		return 0
+	}
+	if v, ok := metadataFloat(r.Metadata[ComputeMetadataVCPUs]); ok {
+		s.vcpus = v
+	}
+	if v, ok := metadataFloat(r.Metadata[ComputeMetadataMemoryGB]); ok {
+		s.memoryGB = v
+	} else if s.memoryGB == 0 {
+		s.memoryGB = s.vcpus * memoryGBPerVCPU
+	}
+	if s.power == (cpuPower{}) {
+		s.power = providerCPUPower[r.Provider]
+		if s.power == (cpuPower{}) {
+			s.power = providerCPUPower[entity.CloudProviderKubernetes]
+		}
+	}
+
+	utilization := defaultCPUUtilization
+	if v, ok := metadataFloat(r.Metadata[ComputeMetadataCPUAvg]); ok {
+		utilization = min(max(v/100, 0), 1)
+	}
+	cpu := s.vcpus * (s.power.min + utilization*(s.power.max-s.power.min))
+	return cpu + s.memoryGB*wattsPerMemoryGB
+}
+
+// storageWatts returns the power drawn by the size_gb of a resource
+func storageWatts(r *entity.Resource, hdd bool) float64 {
+	size, _ := metadataFloat(r.Metadata[VolumeMetadataSizeGB])
+	watts := wattsPerSSDGB
+	if hdd {
+		watts = wattsPerHDDGB
+	}
+	replication := storageReplication[r.Type]
+	if replication == 0 {
+		replication = 1
+	}
+	return size * watts * replication
+}
+
+// instanceShape derives the capacity of an instance type from its name. It
+// is empty for unknown types, whose capacity must be in metadata.
+func instanceShape(provider entity.CloudProvider, instanceType string) shape {
+	switch provider {
+	case entity.CloudProviderAWS:
+		family, size, ok := strings.Cut(instanceType, ".")
+		if !ok || family == "" {
+			return shape{}
+		}
+		s := shape{vcpus: awsSizeVCPUs[size], power: microarchitectures[instanceFamilies[provider][family]]}
+		if m := awsSizeMultiple.FindStringSubmatch(size); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			s.vcpus = float64(4 * n)
+		}
+		if ratio, ok := awsMemoryPerVCPU[family[0]]; ok {
+			s.memoryGB = s.vcpus * ratio
+		}
+		return s
+	case entity.CloudProviderAzure:
+		m := azureSizePattern.FindStringSubmatch(instanceType)
+		if m == nil {
+			return shape{}
+		}
+		vcpus, _ := strconv.Atoi(m[2])
+		family := m[1] + m[3] + m[4]
+		if m[1] == "B" {
+			family = "B"
+		}
+		s := shape{vcpus: float64(vcpus), power: microarchitectures[instanceFamilies[provider][family]]}
+		if m[1] == "E" {
+			s.memoryGB = s.vcpus * 8
+		} else if m[1] == "F" {
+			s.memoryGB = s.vcpus * 2
+		}
+		return s
+	case entity.CloudProviderGCP:
+		m := gceSizePattern.FindStringSubmatch(instanceType)
+		if m == nil {
+			if strings.HasPrefix(instanceType, "e2-") || strings.HasPrefix(instanceType, "f1-") || strings.HasPrefix(instanceType, "g1-") {
+				return shape{vcpus: 2, memoryGB: 2, power: microarchitectures["skylake"]}
+			}
+			return shape{}
+		}
+		vcpus, _ := strconv.Atoi(m[3])
+		return shape{
+			vcpus:    float64(vcpus),
+			memoryGB: float64(vcpus) * gceMemoryPerVCPU[m[2]],
+			power:    microarchitectures[instanceFamilies[provider][m[1]]],
+		}
+	}
+	return shape{}
+}
+
+// databaseShape derives the capacity of a managed database instance class
+func databaseShape(resourceType entity.ResourceType, class string) shape {
+	switch resourceType {
+	case entity.ResourceTypeRDSInstance:
+		return instanceShape(entity.CloudProviderAWS, strings.TrimPrefix(class, "db."))
+	case entity.ResourceTypeAzureSQL:
+		if m := azureVCores.FindStringSubmatch(class); m != nil {
+			vcores, _ := strconv.Atoi(m[1])
+			return shape{vcpus: float64(vcores), memoryGB: float64(vcores) * 5.1}
+		}
+	case entity.ResourceTypeCloudSQL:
+		if m := cloudSQLCustom.FindStringSubmatch(class); m != nil {
+			cpus, _ := strconv.Atoi(m[1])
+			memoryMB, _ := strconv.Atoi(m[2])
+			return shape{vcpus: float64(cpus), memoryGB: float64(memoryMB) / 1024}
+		}
+		if m := cloudSQLPredefined.FindStringSubmatch(class); m != nil {
+			return instanceShape(entity.CloudProviderGCP, strings.TrimPrefix(m[1], "db-")+"-"+m[2])
+		}
+	}
+	return shape{}
+}
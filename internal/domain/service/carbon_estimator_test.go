@@ -0,0 +1,175 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestCarbonEstimatorGridIntensity(t *testing.T) {
+	e := NewCarbonEstimator(nil, map[string]float64{"eu-west-3": 0.02, "us-east-1": -1})
+
+	tests := []struct {
+		name     string
+		provider entity.CloudProvider
+		region   string
+		want     float64
+	}{
+		{"aws region", entity.CloudProviderAWS, "eu-north-1", 0.0088},
+		{"azure region", entity.CloudProviderAzure, "westeurope", 0.328},
+		{"gcp region", entity.CloudProviderGCP, "europe-west1", 0.127},
+		{"zero carbon region", entity.CloudProviderGCP, "northamerica-northeast1", 0},
+		{"overridden region", entity.CloudProviderAWS, "eu-west-3", 0.02},
+		{"negative override ignored", entity.CloudProviderAWS, "us-east-1", 0.379},
+		{"unknown region", entity.CloudProviderAWS, "mars-north-1", DefaultGridIntensity},
+		{"region of another provider", entity.CloudProviderAzure, "eu-north-1", DefaultGridIntensity},
+		{"kubernetes cluster", entity.CloudProviderKubernetes, "", DefaultGridIntensity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.GridIntensity(tt.provider, tt.region); !approxEqual(got, tt.want) {
+				t.Errorf("GridIntensity(%s, %q) = %v, want %v", tt.provider, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCarbonEstimatorPUE(t *testing.T) {
+	e := NewCarbonEstimator(map[string]float64{"GCP": 1.3, "azure": 0.9}, nil)
+
+	tests := []struct {
+		name     string
+		provider entity.CloudProvider
+		want     float64
+	}{
+		{"aws default", entity.CloudProviderAWS, 1.135},
+		{"override is case insensitive", entity.CloudProviderGCP, 1.3},
+		{"override below 1 ignored", entity.CloudProviderAzure, 1.185},
+		{"kubernetes", entity.CloudProviderKubernetes, 1.135},
+		{"unknown provider", entity.CloudProvider("oracle"), 1.135},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.PUE(tt.provider); !approxEqual(got, tt.want) {
+				t.Errorf("PUE(%s) = %v, want %v", tt.provider, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCarbonEstimatorWatts(t *testing.T) {
+	e := NewCarbonEstimator(nil, nil)
+
+	tests := []struct {
+		name     string
+		provider entity.CloudProvider
+		typ      entity.ResourceType
+		metadata map[string]any
+		want     float64
+	}{
+		{
+			// 2 vCPUs of cascade lake at 50% and 8 GB of memory
+			name: "ec2 instance", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEC2Instance,
+			metadata: map[string]any{ComputeMetadataInstanceType: "m5.large"},
+			want:     2*(0.64+0.5*(3.97-0.64)) + 8*0.392,
+		},
+		{
+			name: "ec2 instance at full load", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEC2Instance,
+			metadata: map[string]any{ComputeMetadataInstanceType: "m5.large", ComputeMetadataCPUAvg: 100.0},
+			want:     2*3.97 + 8*0.392,
+		},
+		{
+			// 16 vCPUs and 32 GB of memory for the compute family
+			name: "ec2 multiple of xlarge", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEC2Instance,
+			metadata: map[string]any{ComputeMetadataInstanceType: "c5.4xlarge", ComputeMetadataCPUAvg: 0.0},
+			want:     16*0.64 + 32*0.392,
+		},
+		{
+			name: "stopped instance", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEC2Instance,
+			metadata: map[string]any{ComputeMetadataInstanceType: "m5.large", ComputeMetadataState: "Stopped"},
+			want:     0,
+		},
+		{
+			name: "azure vm", provider: entity.CloudProviderAzure, typ: entity.ResourceTypeAzureVM,
+			metadata: map[string]any{ComputeMetadataInstanceType: "Standard_D4s_v5"},
+			want:     4*(0.69+0.5*(3.76-0.69)) + 16*0.392,
+		},
+		{
+			name: "gce instance", provider: entity.CloudProviderGCP, typ: entity.ResourceTypeGCEInstance,
+			metadata: map[string]any{ComputeMetadataInstanceType: "n2-standard-4"},
+			want:     4*(0.64+0.5*(3.97-0.64)) + 16*0.392,
+		},
+		{
+			// The provider average and the vCPUs set by the scanner
+			name: "unknown instance type", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEC2Instance,
+			metadata: map[string]any{ComputeMetadataInstanceType: "zz9.huge", ComputeMetadataVCPUs: 4},
+			want:     4*(0.74+0.5*(3.5-0.74)) + 16*0.392,
+		},
+		{
+			name: "ssd volume", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEBSVolume,
+			metadata: map[string]any{VolumeMetadataSizeGB: 100.0, VolumeMetadataType: "gp3"},
+			want:     100 * 0.0012 * 2,
+		},
+		{
+			name: "hdd volume", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEBSVolume,
+			metadata: map[string]any{VolumeMetadataSizeGB: 100.0, VolumeMetadataType: "st1"},
+			want:     100 * 0.00065 * 2,
+		},
+		{
+			name: "snapshot", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeEBSSnapshot,
+			metadata: map[string]any{VolumeMetadataSizeGB: 100.0},
+			want:     100 * 0.00065 * 3,
+		},
+		{
+			name: "ip address", provider: entity.CloudProviderAWS, typ: entity.ResourceTypeElasticIP,
+			want: 0,
+		},
+		{
+			name: "unknown type", provider: entity.CloudProviderAWS, typ: entity.ResourceType("quantum_computer"),
+			metadata: map[string]any{VolumeMetadataSizeGB: 100.0},
+			want:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &entity.Resource{Provider: tt.provider, Type: tt.typ, Metadata: tt.metadata}
+			if got := e.Watts(r); !approxEqual(got, tt.want) {
+				t.Errorf("Watts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCarbonEstimatorEstimate(t *testing.T) {
+	e := NewCarbonEstimator(nil, nil)
+	watts := 2*(0.64+0.5*(3.97-0.64)) + 8*0.392
+
+	tests := []struct {
+		name     string
+		provider entity.CloudProvider
+		region   string
+		want     float64
+	}{
+		{"high carbon region", entity.CloudProviderAWS, "ap-south-1", watts * 730 / 1000 * 1.135 * 0.708},
+		{"low carbon region", entity.CloudProviderAWS, "eu-north-1", watts * 730 / 1000 * 1.135 * 0.0088},
+		{"unknown region", entity.CloudProviderAWS, "unknown-1", watts * 730 / 1000 * 1.135 * DefaultGridIntensity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &entity.Resource{
+				Provider: tt.provider,
+				Type:     entity.ResourceTypeEC2Instance,
+				Region:   tt.region,
+				Metadata: map[string]any{ComputeMetadataInstanceType: "m5.large"},
+			}
+			if got := e.Estimate(r); !approxEqual(got, tt.want) {
+				t.Errorf("Estimate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
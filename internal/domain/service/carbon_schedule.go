@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// IntensityPoint is the carbon intensity of a grid over the hour starting at
+// Time, in gCO2e per kWh
+type IntensityPoint struct {
+	Time      time.Time
+	Intensity float64
+}
+
+// CarbonIntensitySource forecasts the carbon intensity of electricity grid
+// zones, such as the Electricity Maps API
+type CarbonIntensitySource interface {
+	// Forecast returns the hourly intensity of a zone from the current hour
+	Forecast(ctx context.Context, zone string) ([]IntensityPoint, error)
+}
+
+// Sources of the intensity a carbon schedule is computed from
+const (
+	IntensitySourceForecast = "forecast" // live forecast of the zone
+	IntensitySourceProfile  = "profile"  // typical day of the region's average intensity
+)
+
+// DefaultCarbonRunHours is how many hours a day scheduled resources run when
+// not specified
+const DefaultCarbonRunHours = 10
+
+// gridZone is the electricity grid zone of a region and its standard UTC
+// offset, in hours
+type gridZone struct {
+	zone   string
+	offset float64
+}
+
+// gridZones maps provider regions to Electricity Maps zones
+var gridZones = map[entity.CloudProvider]map[string]gridZone{
+	entity.CloudProviderAWS: {
+		"us-east-1": {"US-MIDA-PJM", -5}, "us-east-2": {"US-MIDA-PJM", -5},
+		"us-west-1": {"US-CAL-CISO", -8}, "us-west-2": {"US-NW-BPAT", -8},
+		"ca-central-1": {"CA-QC", -5}, "sa-east-1": {"BR-CS", -3},
+		"eu-west-1": {"IE", 0}, "eu-west-2": {"GB", 0}, "eu-west-3": {"FR", 1},
+		"eu-central-1": {"DE", 1}, "eu-north-1": {"SE-SE3", 1}, "eu-south-1": {"IT-NO", 1},
+		"ap-south-1": {"IN-WE", 5.5}, "ap-east-1": {"HK", 8}, "ap-northeast-1": {"JP-TK", 9},
+		"ap-northeast-2": {"KR", 9}, "ap-southeast-1": {"SG", 8}, "ap-southeast-2": {"AU-NSW", 10},
+	},
+	entity.CloudProviderAzure: {
+		"eastus": {"US-MIDA-PJM", -5}, "eastus2": {"US-MIDA-PJM", -5}, "centralus": {"US-MIDW-MISO", -6},
+		"westus": {"US-CAL-CISO", -8}, "westus2": {"US-NW-BPAT", -8},
+		"canadacentral": {"CA-ON", -5}, "brazilsouth": {"BR-CS", -3},
+		"northeurope": {"IE", 0}, "westeurope": {"NL", 1}, "uksouth": {"GB", 0},
+		"francecentral": {"FR", 1}, "germanywestcentral": {"DE", 1}, "swedencentral": {"SE-SE3", 1},
+		"norwayeast": {"NO-NO1", 1}, "switzerlandnorth": {"CH", 1},
+		"centralindia": {"IN-WE", 5.5}, "southeastasia": {"SG", 8}, "eastasia": {"HK", 8},
+		"japaneast": {"JP-TK", 9}, "koreacentral": {"KR", 9}, "australiaeast": {"AU-NSW", 10},
+	},
+	entity.CloudProviderGCP: {
+		"us-central1": {"US-MIDW-MISO", -6}, "us-east1": {"US-SE-SOCO", -5}, "us-east4": {"US-MIDA-PJM", -5},
+		"us-west1": {"US-NW-BPAT", -8}, "us-west2": {"US-CAL-LDWP", -8},
+		"northamerica-northeast1": {"CA-QC", -5}, "southamerica-east1": {"BR-CS", -3},
+		"europe-west1": {"BE", 1}, "europe-west2": {"GB", 0}, "europe-west3": {"DE", 1},
+		"europe-west4": {"NL", 1}, "europe-west6": {"CH", 1}, "europe-north1": {"FI", 2},
+		"asia-east1": {"TW", 8}, "asia-northeast1": {"JP-TK", 9}, "asia-south1": {"IN-WE", 5.5},
+		"asia-southeast1": {"SG", 8}, "australia-southeast1": {"AU-NSW", 10},
+	},
+}
+
+// GridZone returns the Electricity Maps zone of a provider region
+func GridZone(provider entity.CloudProvider, region string) (string, bool) {
+	z, ok := gridZones[provider][region]
+	return z.zone, ok
+}
+
+// dailyIntensityProfile is the typical intensity of a grid at each local
+// hour relative to its daily average: solar lowers it around noon and the
+// evening demand peak raises it
+var dailyIntensityProfile = [24]float64{
+	0.95, 0.92, 0.90, 0.89, 0.90, 0.94, 1.00, 1.06, 1.05, 1.00, 0.95, 0.91,
+	0.89, 0.89, 0.92, 0.97, 1.04, 1.11, 1.14, 1.12, 1.08, 1.03, 0.99, 0.97,
+}
+
+// ProfileForecast builds a 24 hour forecast from the current hour out of the
+// average intensity of a region, in gCO2e per kWh, for regions without a
+// live forecast
+func ProfileForecast(provider entity.CloudProvider, region string, average float64, now time.Time) []IntensityPoint {
+	offset := gridZones[provider][region].offset
+	start := now.UTC().Truncate(time.Hour)
+	points := make([]IntensityPoint, 24)
+	for i := range points {
+		t := start.Add(time.Duration(i) * time.Hour)
+		local := t.Add(time.Duration(offset * float64(time.Hour)))
+		points[i] = IntensityPoint{Time: t, Intensity: average * dailyIntensityProfile[local.Hour()]}
+	}
+	return points
+}
+
+// CarbonWindow is a period of a carbon schedule, end excluded
+type CarbonWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// CarbonSchedule is the suggested daily schedule of a resource that can be
+// stopped: it runs in the hours of lowest carbon intensity and is stopped
+// the rest of the day
+type CarbonSchedule struct {
+	Forecast    []IntensityPoint // the 24 hours the schedule was computed on
+	RunWindows  []CarbonWindow
+	StopWindows []CarbonWindow
+	// Average intensity of the day and of the run hours, in gCO2e per kWh
+	AverageIntensity    float64
+	RunIntensity        float64
+	MonthlySavingsKg    float64 // compared to running around the clock
+	MonthlyShiftSavings float64 // kg, compared to running as many hours at the average intensity
+}
+
+// SuggestCarbonSchedule picks the runHours hours of lowest intensity in the
+// first 24 hours of the forecast. kwhPerHour is the energy the resource
+// draws per hour it runs, PUE included.
+func SuggestCarbonSchedule(forecast []IntensityPoint, runHours int, kwhPerHour float64) CarbonSchedule {
+	if len(forecast) > 24 {
+		forecast = forecast[:24]
+	}
+	s := CarbonSchedule{Forecast: forecast}
+	if len(forecast) == 0 {
+		return s
+	}
+	runHours = min(max(runHours, 0), len(forecast))
+
+	order := make([]int, len(forecast))
+	var total float64
+	for i, p := range forecast {
+		order[i] = i
+		total += p.Intensity
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return forecast[order[a]].Intensity < forecast[order[b]].Intensity
+	})
+	run := make([]bool, len(forecast))
+	var runTotal float64
+	for _, i := range order[:runHours] {
+		run[i] = true
+		runTotal += forecast[i].Intensity
+	}
+
+	// Consecutive hours with the same decision form a window
+	for i := 0; i < len(forecast); {
+		j := i + 1
+		for j < len(forecast) && run[j] == run[i] {
+			j++
+		}
+		w := CarbonWindow{Start: forecast[i].Time, End: forecast[j-1].Time.Add(time.Hour)}
+		if run[i] {
+			s.RunWindows = append(s.RunWindows, w)
+		} else {
+			s.StopWindows = append(s.StopWindows, w)
+		}
+		i = j
+	}
+
+	s.AverageIntensity = total / float64(len(forecast))
+	if runHours > 0 {
+		s.RunIntensity = runTotal / float64(runHours)
+	}
+	// Daily grams to monthly kilograms
+	const daysPerMonth = entity.HoursPerMonth / 24
+	s.MonthlySavingsKg = round2(kwhPerHour * (total - runTotal) * daysPerMonth / 1000)
+	s.MonthlyShiftSavings = round2(kwhPerHour * (s.AverageIntensity*float64(runHours) - runTotal) * daysPerMonth / 1000)
+	return s
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// HourlyEnergy returns the energy a resource draws per hour, PUE included,
+// in kWh
+func (e *CarbonEstimator) HourlyEnergy(r *entity.Resource) float64 {
+	return e.Watts(r) / 1000 * e.PUE(r.Provider)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// ErrNoCatalogPrice is returned when the price catalog has no price for the
+// instance type of a resource
+var ErrNoCatalogPrice = errors.New("no catalog price for the instance type")
+
+// PriceCatalog holds the on-demand list prices of instance types
+type PriceCatalog interface {
+	// HourlyPrice returns the hourly USD price of an instance type in a
+	// region
+	HourlyPrice(provider entity.CloudProvider, region, instanceType string) (float64, bool)
+}
+
+// referenceRegions are the regions whose prices apply to instance types the
+// catalog has no price for in the region of a resource
+var referenceRegions = map[entity.CloudProvider]string{
+	entity.CloudProviderAWS:   "us-east-1",
+	entity.CloudProviderAzure: "eastus",
+	entity.CloudProviderGCP:   "us-central1",
+}
+
+// CatalogEstimator estimates the cost of instances and managed databases
+// from a price catalog, without calling the provider. Estimates are
+// deterministic: a resource is always priced the same with the same
+// catalog.
+type CatalogEstimator struct {
+	catalog PriceCatalog
+}
+
+// NewCatalogEstimator creates a CatalogEstimator
+func NewCatalogEstimator(catalog PriceCatalog) *CatalogEstimator {
+	return &CatalogEstimator{catalog: catalog}
+}
+
+// EstimateCost returns the hourly cost of a resource from the price of its
+// instance type or class, in its region or else the reference region of its
+// provider. Stopped instances cost nothing, their storage being priced as
+// separate resources.
+func (e *CatalogEstimator) EstimateCost(r *entity.Resource) (entity.Cost, error) {
+	var instanceType string
+	switch {
+	case r.Type.IsManagedDatabase():
+		instanceType, _ = r.Metadata[DatabaseMetadataInstanceClass].(string)
+	case r.Type.IsStoppable():
+		instanceType, _ = r.Metadata[ComputeMetadataInstanceType].(string)
+	default:
+		return entity.Cost{}, ErrNoCatalogPrice
+	}
+	if instanceType == "" {
+		return entity.Cost{}, ErrNoCatalogPrice
+	}
+	if state, _ := r.Metadata[ComputeMetadataState].(string); stoppedInstanceStates[strings.ToLower(state)] {
+		return entity.Cost{Currency: entity.CurrencyUSD, Period: entity.BillingPeriodHourly}, nil
+	}
+
+	price, ok := e.catalog.HourlyPrice(r.Provider, r.Region, instanceType)
+	if !ok {
+		if region, known := referenceRegions[r.Provider]; known && region != r.Region {
+			price, ok = e.catalog.HourlyPrice(r.Provider, region, instanceType)
+		}
+	}
+	if !ok {
+		return entity.Cost{}, ErrNoCatalogPrice
+	}
+	return entity.Cost{Amount: price, Currency: entity.CurrencyUSD, Period: entity.BillingPeriodHourly}, nil
+}
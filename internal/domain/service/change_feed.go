@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// ErrChangesUnavailable is returned when the changes of a region cannot be
+// known, e.g. change tracking is not enabled on the account or does not go
+// back far enough. The region has to be scanned in full.
+var ErrChangesUnavailable = errors.New("resource changes are unavailable")
+
+// ResourceChanges are the resources a provider reports as changed in a
+// region since a time, by provider ID
+type ResourceChanges struct {
+	Changed []string // created or modified
+	Deleted []string
+}
+
+// ChangeFeed reads the changes of the resources of a cloud account from the
+// change tracking of its provider, e.g. AWS Config
+type ChangeFeed interface {
+	// Changes returns the resources of a region changed since a time. It
+	// fails with ErrChangesUnavailable when they cannot be known.
+	Changes(ctx context.Context, region string, since time.Time) (ResourceChanges, error)
+}
+
+// IncrementalScanner is implemented by scanners that can scan only the
+// resources changed since an earlier scan
+type IncrementalScanner interface {
+	// ScanChanges returns the resources of specified types in a single
+	// region changed since a time, and the provider IDs of the resources
+	// deleted since then. It fails with ErrChangesUnavailable when the
+	// region has to be scanned in full.
+	ScanChanges(ctx context.Context, region string, resourceTypes []entity.ResourceType, since time.Time) (changed []*entity.Resource, deleted []string, err error)
+}
+
+// ResourceIDs is a set of provider resource IDs. Change feeds do not always
+// report IDs the way scanners do, so IDs are compared without case, and an
+// ID without a path matches the last segment of one with a path, e.g. a
+// disk name and its full resource name.
+type ResourceIDs map[string]bool
+
+// NewResourceIDs creates the set of ids
+func NewResourceIDs(ids []string) ResourceIDs {
+	set := make(ResourceIDs, 2*len(ids))
+	for _, id := range ids {
+		id = strings.ToLower(id)
+		set[id] = true
+		if i := strings.LastIndex(id, "/"); i >= 0 {
+			set["/"+id[i+1:]] = true
+		}
+	}
+	return set
+}
+
+// Has reports whether id is in the set
+func (s ResourceIDs) Has(id string) bool {
+	id = strings.ToLower(id)
+	if s[id] {
+		return true
+	}
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		return s[id[i+1:]]
+	}
+	return s["/"+id]
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// typePermissions are the provider permissions the scan and cleanup actions
+// of a resource type call: IAM actions for AWS, Azure RBAC operations, Google
+// Cloud IAM permissions and Kubernetes RBAC verbs on resources
+type typePermissions struct {
+	scan     []string
+	delete   []string
+	stop     []string
+	tag      []string
+	snapshot []string // taken when the resource is quarantined
+}
+
+// resourcePermissions are the permissions of the built-in resource types.
+// Public IP addresses are released with their delete permission.
+var resourcePermissions = map[entity.ResourceType]typePermissions{
+	entity.ResourceTypeEC2Instance: {
+		scan:   []string{"ec2:DescribeInstances", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"ec2:TerminateInstances"},
+		stop:   []string{"ec2:StopInstances"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeEBSVolume: {
+		scan:     []string{"ec2:DescribeVolumes"},
+		delete:   []string{"ec2:DeleteVolume"},
+		tag:      []string{"ec2:CreateTags"},
+		snapshot: []string{"ec2:CreateSnapshot"},
+	},
+	entity.ResourceTypeEBSSnapshot: {
+		scan:   []string{"ec2:DescribeSnapshots", "ec2:DescribeImages"},
+		delete: []string{"ec2:DeleteSnapshot", "ec2:DeregisterImage"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeElasticIP: {
+		scan:   []string{"ec2:DescribeAddresses"},
+		delete: []string{"ec2:ReleaseAddress"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeLoadBalancer: {
+		scan:   []string{"elasticloadbalancing:DescribeLoadBalancers", "elasticloadbalancing:DescribeTargetHealth", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"elasticloadbalancing:DeleteLoadBalancer"},
+		tag:    []string{"elasticloadbalancing:AddTags"},
+	},
+	entity.ResourceTypeS3Bucket: {
+		scan:   []string{"s3:ListAllMyBuckets", "s3:GetBucketLocation", "s3:GetBucketWebsite", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"s3:DeleteBucket"},
+		tag:    []string{"s3:PutBucketTagging"},
+	},
+	entity.ResourceTypeRDSInstance: {
+		scan:   []string{"rds:DescribeDBInstances", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"rds:DeleteDBInstance", "rds:CreateDBSnapshot"},
+		stop:   []string{"rds:StopDBInstance"},
+		tag:    []string{"rds:AddTagsToResource"},
+	},
+	entity.ResourceTypeVPCPeering: {
+		scan:   []string{"ec2:DescribeVpcPeeringConnections", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"ec2:DeleteVpcPeeringConnection"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeVPNConnection: {
+		scan:   []string{"ec2:DescribeVpnConnections", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"ec2:DeleteVpnConnection"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeTransitGatewayAttachment: {
+		scan:   []string{"ec2:DescribeTransitGatewayAttachments", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"ec2:DeleteTransitGatewayVpcAttachment"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeNetworkInterface: {
+		scan:   []string{"ec2:DescribeNetworkInterfaces"},
+		delete: []string{"ec2:DeleteNetworkInterface"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeNATGateway: {
+		scan:   []string{"ec2:DescribeNatGateways", "cloudwatch:GetMetricStatistics"},
+		delete: []string{"ec2:DeleteNatGateway"},
+		tag:    []string{"ec2:CreateTags"},
+	},
+	entity.ResourceTypeAzureVM: {
+		scan:   []string{"Microsoft.Compute/virtualMachines/read", "Microsoft.Insights/metrics/read"},
+		delete: []string{"Microsoft.Compute/virtualMachines/delete"},
+		stop:   []string{"Microsoft.Compute/virtualMachines/deallocate/action"},
+		tag:    []string{"Microsoft.Resources/tags/write"},
+	},
+	entity.ResourceTypeAzureDisk: {
+		scan:     []string{"Microsoft.Compute/disks/read"},
+		delete:   []string{"Microsoft.Compute/disks/delete"},
+		tag:      []string{"Microsoft.Resources/tags/write"},
+		snapshot: []string{"Microsoft.Compute/snapshots/write"},
+	},
+	entity.ResourceTypeAzureBlobContainer: {
+		scan:   []string{"Microsoft.Storage/storageAccounts/read", "Microsoft.Storage/storageAccounts/blobServices/containers/read"},
+		delete: []string{"Microsoft.Storage/storageAccounts/blobServices/containers/delete"},
+		tag:    []string{"Microsoft.Storage/storageAccounts/blobServices/containers/write"},
+	},
+	entity.ResourceTypeAzureSQL: {
+		scan:   []string{"Microsoft.Sql/servers/databases/read", "Microsoft.Insights/metrics/read"},
+		delete: []string{"Microsoft.Sql/servers/databases/delete", "Microsoft.Sql/servers/databases/write"},
+		stop:   []string{"Microsoft.Sql/servers/databases/pause/action"},
+		tag:    []string{"Microsoft.Resources/tags/write"},
+	},
+	entity.ResourceTypeAzureSnapshot: {
+		scan:   []string{"Microsoft.Compute/snapshots/read", "Microsoft.Compute/images/read"},
+		delete: []string{"Microsoft.Compute/snapshots/delete"},
+		tag:    []string{"Microsoft.Resources/tags/write"},
+	},
+	entity.ResourceTypeAzureLoadBalancer: {
+		scan:   []string{"Microsoft.Network/loadBalancers/read", "Microsoft.Insights/metrics/read"},
+		delete: []string{"Microsoft.Network/loadBalancers/delete"},
+		tag:    []string{"Microsoft.Resources/tags/write"},
+	},
+	entity.ResourceTypeAzureNetworkInterface: {
+		scan:   []string{"Microsoft.Network/networkInterfaces/read"},
+		delete: []string{"Microsoft.Network/networkInterfaces/delete"},
+		tag:    []string{"Microsoft.Resources/tags/write"},
+	},
+	entity.ResourceTypeAzureNATGateway: {
+		scan:   []string{"Microsoft.Network/natGateways/read", "Microsoft.Insights/metrics/read"},
+		delete: []string{"Microsoft.Network/natGateways/delete"},
+		tag:    []string{"Microsoft.Resources/tags/write"},
+	},
+	entity.ResourceTypeAzurePublicIP: {
+		scan:   []string{"Microsoft.Network/publicIPAddresses/read"},
+		delete: []string{"Microsoft.Network/publicIPAddresses/delete"},
+		tag:    []string{"Microsoft.Resources/tags/write"},
+	},
+	entity.ResourceTypeGCEInstance: {
+		scan:   []string{"compute.instances.list", "monitoring.timeSeries.list"},
+		delete: []string{"compute.instances.delete"},
+		stop:   []string{"compute.instances.stop"},
+		tag:    []string{"compute.instances.setLabels"},
+	},
+	entity.ResourceTypeGCEDisk: {
+		scan:     []string{"compute.disks.list"},
+		delete:   []string{"compute.disks.delete"},
+		tag:      []string{"compute.disks.setLabels"},
+		snapshot: []string{"compute.disks.createSnapshot", "compute.snapshots.create"},
+	},
+	entity.ResourceTypeGCSBucket: {
+		scan:   []string{"storage.buckets.list", "monitoring.timeSeries.list"},
+		delete: []string{"storage.buckets.delete"},
+		tag:    []string{"storage.buckets.update"},
+	},
+	entity.ResourceTypeCloudSQL: {
+		scan:   []string{"cloudsql.instances.list", "monitoring.timeSeries.list"},
+		delete: []string{"cloudsql.instances.delete", "cloudsql.backupRuns.create"},
+		stop:   []string{"cloudsql.instances.update"},
+		tag:    []string{"cloudsql.instances.update"},
+	},
+	entity.ResourceTypeGCPLoadBalancer: {
+		scan:   []string{"compute.forwardingRules.list", "compute.backendServices.getHealth", "monitoring.timeSeries.list"},
+		delete: []string{"compute.forwardingRules.delete"},
+		tag:    []string{"compute.forwardingRules.setLabels"},
+	},
+	entity.ResourceTypeCloudNAT: {
+		scan:   []string{"compute.routers.list", "monitoring.timeSeries.list"},
+		delete: []string{"compute.routers.update"},
+		tag:    []string{"compute.routers.update"},
+	},
+	entity.ResourceTypeGCPStaticIP: {
+		scan:   []string{"compute.addresses.list"},
+		delete: []string{"compute.addresses.delete"},
+		tag:    []string{"compute.addresses.setLabels"},
+	},
+	entity.ResourceTypeK8sDeployment: {
+		scan:   []string{"apps/deployments:list", "pods:list"},
+		delete: []string{"apps/deployments:delete"},
+		tag:    []string{"apps/deployments:patch"},
+	},
+	entity.ResourceTypeK8sPVC: {
+		scan:     []string{"persistentvolumeclaims:list", "pods:list"},
+		delete:   []string{"persistentvolumeclaims:delete"},
+		tag:      []string{"persistentvolumeclaims:patch"},
+		snapshot: []string{"snapshot.storage.k8s.io/volumesnapshots:create"},
+	},
+	entity.ResourceTypeK8sLoadBalancer: {
+		scan:   []string{"services:list", "endpoints:list"},
+		delete: []string{"services:delete"},
+		tag:    []string{"services:patch"},
+	},
+}
+
+// routeTablePermissions are read to check that no route points to a
+// network attachment or NAT gateway before it is deleted
+var routeTablePermissions = map[entity.CloudProvider][]string{
+	entity.CloudProviderAWS:   {"ec2:DescribeRouteTables"},
+	entity.CloudProviderAzure: {"Microsoft.Network/routeTables/read"},
+	entity.CloudProviderGCP:   {"compute.routes.list"},
+}
+
+// dnsPermissions are read to check that no DNS record points to a resource
+// before it is deleted
+var dnsPermissions = map[entity.CloudProvider][]string{
+	entity.CloudProviderAWS:   {"route53:ListHostedZones", "route53:ListResourceRecordSets"},
+	entity.CloudProviderAzure: {"Microsoft.Network/dnsZones/read", "Microsoft.Network/dnsZones/recordsets/read"},
+	entity.CloudProviderGCP:   {"dns.managedZones.list", "dns.resourceRecordSets.list"},
+}
+
+// RequiredPermissions returns the provider permissions a cleanup action
+// calls on a resource, including those of the checks run before deleting
+// it. It returns nil for notifications and tickets, and for resource types
+// without known permissions, such as custom types.
+func RequiredPermissions(r *entity.Resource, action entity.PolicyAction) []string {
+	return actionPermissions(r.Type, r.Provider, action, NeedsDNSCheck(r))
+}
+
+// actionPermissions returns the permissions of a cleanup action on a
+// resource type; dns adds those of searching the records pointing at it
+func actionPermissions(t entity.ResourceType, provider entity.CloudProvider, action entity.PolicyAction, dns bool) []string {
+	perms, ok := resourcePermissions[t]
+	if !ok {
+		return nil
+	}
+	var out []string
+	switch action {
+	case entity.PolicyActionDelete, entity.PolicyActionRelease:
+		out = append(out, perms.delete...)
+		if t.IsNetworkAttachment() || t.IsNATGateway() {
+			out = append(out, routeTablePermissions[provider]...)
+		}
+		if dns {
+			out = append(out, dnsPermissions[provider]...)
+		}
+	case entity.PolicyActionStop, entity.PolicyActionSchedule, entity.PolicyActionScheduleOffHours:
+		out = append(out, perms.stop...)
+	case entity.PolicyActionTag:
+		out = append(out, perms.tag...)
+	case entity.PolicyActionQuarantine:
+		// The purge deletes the resource once the window has ended
+		switch {
+		case t.IsStoppable():
+			out = append(out, perms.stop...)
+		case snapshotTypes[t]:
+			out = append(out, perms.snapshot...)
+		}
+		out = append(out, perms.tag...)
+		out = append(out, perms.delete...)
+	}
+	return out
+}
+
+// PermissionChecker is implemented by cleaners that can check the
+// permissions of their credentials, e.g. with the IAM policy simulator or
+// testIamPermissions. It returns the given permissions that are not
+// granted. Implementations cache the answers, as they are asked for every
+// resource of a cleanup.
+type PermissionChecker interface {
+	MissingPermissions(ctx context.Context, permissions []string) ([]string, error)
+}
+
+// ErrPermissionsUnsupported is returned by the PermissionChecker of cleaner
+// wrappers when the cleaner they wrap cannot check its permissions
+var ErrPermissionsUnsupported = errors.New("cannot check permissions")
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// PreflightVerdict is what a cleanup will do with a resource
+type PreflightVerdict string
+
+const (
+	PreflightProceed PreflightVerdict = "proceed"
+	PreflightSkip    PreflightVerdict = "skip"
+	PreflightFail    PreflightVerdict = "fail"
+)
+
+// Checks of a cleanup preflight, reported in its findings
+const (
+	PreflightCheckStatus      = "status"      // deleted, excluded or already quarantined resources
+	PreflightCheckRegion      = "region"      // regions the organization denylisted
+	PreflightCheckAction      = "action"      // actions that do not apply to the resource type
+	PreflightCheckIaC         = "iac"         // resources managed by infrastructure-as-code
+	PreflightCheckSnooze      = "snooze"      // resources their owner snoozed
+	PreflightCheckDependency  = "dependency"  // routes, images and attachments depending on the resource
+	PreflightCheckDNS         = "dns"         // DNS records pointing at the resource
+	PreflightCheckPermissions = "permissions" // permissions the credentials lack
+)
+
+// PreflightFinding is a check that did not pass. Blocking findings make the
+// cleanup fail on the resource; others are warnings.
+type PreflightFinding struct {
+	Check    string
+	Blocking bool
+	Message  string
+}
+
+// Preflight is the outcome of the checks run before a cleanup action on a
+// resource
+type Preflight struct {
+	Verdict             PreflightVerdict
+	Findings            []PreflightFinding
+	RequiredPermissions []string
+	MissingPermissions  []string
+}
+
+// PreflightOptions are the settings of the organization the checks depend on
+type PreflightOptions struct {
+	Now time.Time
+	// DeniedRegions are the regions of the resources the organization
+	// denylisted
+	DeniedRegions []string
+	// IaCChanges is true when resources managed by infrastructure-as-code
+	// are removed with pull requests rather than refused
+	IaCChanges bool
+}
+
+// RunPreflight runs the checks of a cleanup action on a resource. Resources
+// already deleted, excluded or quarantined are skipped, and those failing a
+// check fail without any change. Checks that need the cloud are run with
+// the cleaner, which may be nil to preview a cleanup: the dependencies and
+// DNS records recorded by the last scan are used instead, and permissions
+// are listed without being verified.
+func RunPreflight(ctx context.Context, cleaner ResourceCleaner, r *entity.Resource, action entity.PolicyAction, opts PreflightOptions) *Preflight {
+	p := &Preflight{Verdict: PreflightProceed, RequiredPermissions: RequiredPermissions(r, action)}
+
+	switch {
+	case r.Status == entity.ResourceStatusDeleted || r.Status == entity.ResourceStatusRemoved:
+		p.skip("resource is already deleted")
+	case r.Status == entity.ResourceStatusExcluded:
+		p.skip("resource is excluded from cleanup")
+	case action == entity.PolicyActionQuarantine && r.IsQuarantined():
+		p.skip("resource is already quarantined")
+	}
+	if p.Verdict == PreflightSkip {
+		return p
+	}
+
+	if slices.Contains(opts.DeniedRegions, r.Region) {
+		p.add(PreflightCheckRegion, true, fmt.Sprintf("region %s is denylisted", r.Region))
+	}
+	switch action {
+	case entity.PolicyActionStop, entity.PolicyActionSchedule, entity.PolicyActionScheduleOffHours:
+		if !r.Type.IsStoppable() {
+			p.add(PreflightCheckAction, true, fmt.Sprintf("%s cannot be stopped", r.Type))
+		}
+	case entity.PolicyActionRelease:
+		if !r.Type.IsPublicIP() {
+			p.add(PreflightCheckAction, true, fmt.Sprintf("%s is not a public IP address", r.Type))
+		}
+	}
+
+	removal := action == entity.PolicyActionDelete || action == entity.PolicyActionRelease || action == entity.PolicyActionQuarantine
+	if removal && r.IaCManaged {
+		if opts.IaCChanges {
+			p.add(PreflightCheckIaC, false, fmt.Sprintf("managed by %s: a pull request removing it is opened instead", r.IaCTool))
+		} else {
+			p.add(PreflightCheckIaC, true, fmt.Sprintf("managed by %s, which would recreate it", r.IaCTool))
+		}
+	}
+	if r.IsSnoozed(opts.Now) {
+		p.add(PreflightCheckSnooze, false, fmt.Sprintf("snoozed by its owner until %s", r.SnoozedUntil.Format(time.RFC3339)))
+	}
+
+	if action == entity.PolicyActionDelete || action == entity.PolicyActionRelease {
+		if cleaner == nil {
+			p.recordedDependencies(r)
+		} else {
+			p.dependencies(ctx, cleaner, r)
+		}
+	}
+
+	if checker, ok := cleaner.(PermissionChecker); ok && len(p.RequiredPermissions) > 0 {
+		missing, err := checker.MissingPermissions(ctx, p.RequiredPermissions)
+		switch {
+		case errors.Is(err, ErrPermissionsUnsupported):
+		case err != nil:
+			p.add(PreflightCheckPermissions, false, fmt.Sprintf("permissions could not be verified: %v", err))
+		case len(missing) > 0:
+			p.MissingPermissions = missing
+			p.add(PreflightCheckPermissions, true, "missing permissions "+strings.Join(missing, ", "))
+		}
+	}
+	return p
+}
+
+// dependencies checks with the cleaner that nothing depends on a resource
+// before it is deleted
+func (p *Preflight) dependencies(ctx context.Context, cleaner ResourceCleaner, r *entity.Resource) {
+	for _, check := range []func() error{
+		func() error { return CheckNetworkSafeDelete(ctx, cleaner, r) },
+		func() error { return CheckSnapshotSafeDelete(cleaner, r) },
+		func() error { return CheckInterfaceSafeDelete(r) },
+	} {
+		if err := check(); err != nil {
+			p.add(PreflightCheckDependency, true, err.Error())
+		}
+	}
+	if err := CheckDNSSafeDelete(ctx, cleaner, r); err != nil {
+		p.add(PreflightCheckDNS, true, err.Error())
+	}
+}
+
+// recordedDependencies checks the dependencies of a resource recorded by its
+// last scan. Those only known from the cloud are warned about, as they are
+// checked before deletion.
+func (p *Preflight) recordedDependencies(r *entity.Resource) {
+	if r.Type.IsNetworkAttachment() || r.Type.IsNATGateway() {
+		p.add(PreflightCheckDependency, false, "route tables are checked before deletion")
+	}
+	if inUse, _ := r.Metadata[SnapshotMetadataImageInUse].(bool); inUse && r.Type.IsSnapshot() {
+		p.add(PreflightCheckDependency, true, fmt.Sprintf("backs images in use %v", SnapshotImageIDs(r)))
+	}
+	if err := CheckInterfaceSafeDelete(r); err != nil {
+		p.add(PreflightCheckDependency, true, err.Error())
+	}
+	if NeedsDNSCheck(r) {
+		records, ok := RecordedDNSReferences(r)
+		switch {
+		case !ok:
+			p.add(PreflightCheckDNS, false, "DNS records are searched before deletion")
+		case len(records) > 0:
+			p.add(PreflightCheckDNS, true, fmt.Sprintf("still referenced by DNS records %v", records))
+		}
+	}
+}
+
+// Blockers returns the messages of the blocking findings
+func (p *Preflight) Blockers() []string {
+	var out []string
+	for _, f := range p.Findings {
+		if f.Blocking {
+			out = append(out, f.Message)
+		}
+	}
+	return out
+}
+
+func (p *Preflight) skip(message string) {
+	p.Verdict = PreflightSkip
+	p.Findings = append(p.Findings, PreflightFinding{Check: PreflightCheckStatus, Message: message})
+}
+
+func (p *Preflight) add(check string, blocking bool, message string) {
+	p.Findings = append(p.Findings, PreflightFinding{Check: check, Blocking: blocking, Message: message})
+	if blocking {
+		p.Verdict = PreflightFail
+	}
+}
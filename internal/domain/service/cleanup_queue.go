@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// CleanupQueue hands the cleanups requested through the API, and their
+// rollbacks, over to the workers
+type CleanupQueue interface {
+	// Enqueue queues a cleanup of resources of an organization and returns
+	// its job, whose task runs the cleanup
+	Enqueue(ctx context.Context, orgID uuid.UUID, cleanup CleanupRequest) (*entity.Job, error)
+
+	// EnqueueRollback queues undoing the action a cleanup task ran on a
+	// resource, from the recovery recorded with the attempt. A rollback
+	// already queued is not queued again.
+	EnqueueRollback(ctx context.Context, orgID, resourceID uuid.UUID, taskID string, recovery *Recovery, actor string) error
+}
+
+// CleanupRequest is a cleanup to queue
+type CleanupRequest struct {
+	ResourceIDs []uuid.UUID
+	Action      entity.PolicyAction
+	DryRun      bool
+	// Backup snapshots volumes, disks and databases before deleting them,
+	// kept for BackupRetentionDays
+	Backup              bool
+	BackupRetentionDays int
+}
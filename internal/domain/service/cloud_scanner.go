@@ -8,24 +8,85 @@ import (
 
 // CloudScanner defines the interface for scanning cloud resources
 type CloudScanner interface {
-	// ScanResources scans for resources of specified types in given regions
-	ScanResources(ctx context.Context, regions []string, resourceTypes []entity.ResourceType) ([]*entity.Resource, error)
+	// ScanRegion scans for resources of specified types in a single region.
+	// It is called concurrently for different regions and must be safe for
+	// concurrent use.
+	ScanRegion(ctx context.Context, region string, resourceTypes []entity.ResourceType) ([]*entity.Resource, error)
 
 	// DetectUnused analyzes resources and marks unused ones
 	DetectUnused(ctx context.Context, resources []*entity.Resource) error
 
-	// EstimateCost estimates the monthly cost of a resource
-	EstimateCost(ctx context.Context, resource *entity.Resource) (float64, error)
-
-	// EstimateCarbonFootprint estimates the carbon footprint of a resource
-	EstimateCarbonFootprint(ctx context.Context, resource *entity.Resource) (float64, error)
+	// EstimateCost estimates the cost of a resource, in the currency and
+	// billing period the provider prices it in
+	EstimateCost(ctx context.Context, resource *entity.Resource) (entity.Cost, error)
 
 	// Provider returns the cloud provider
 	Provider() entity.CloudProvider
 }
 
+// ResourceDetector scans a single resource type of a provider, detects its
+// unused resources and estimates their costs. The scanner of a provider can
+// be made of the detectors of its resource types, each resource being
+// handled by the detector of its type.
+type ResourceDetector interface {
+	// Scan finds the resources of the type in a single region. It is called
+	// concurrently for different regions and must be safe for concurrent
+	// use.
+	Scan(ctx context.Context, region string) ([]*entity.Resource, error)
+
+	// DetectUnused marks the unused resources among resources of the type
+	DetectUnused(ctx context.Context, resources []*entity.Resource) error
+
+	// EstimateCost estimates the cost of a resource of the type, in the
+	// currency and billing period the provider prices it in
+	EstimateCost(ctx context.Context, resource *entity.Resource) (entity.Cost, error)
+}
+
 // CloudScannerFactory creates cloud scanners based on provider
 type CloudScannerFactory interface {
 	// Create creates a scanner for the given provider and credentials
 	Create(provider entity.CloudProvider, credentials []byte) (CloudScanner, error)
 }
+
+// CustomScannerFactory is implemented by factories whose scanners can also
+// detect the resource types organizations define
+type CustomScannerFactory interface {
+	// CreateWithCustomTypes creates a scanner for the given provider and
+	// credentials that also scans the custom resource types
+	CreateWithCustomTypes(provider entity.CloudProvider, credentials []byte, types []*entity.CustomResourceType) (CloudScanner, error)
+}
+
+// ResourceStreamer is implemented by scanners that emit the resources of a
+// region as they find them, e.g. page by page, rather than returning them
+// all at once
+type ResourceStreamer interface {
+	// StreamRegion sends the resources of specified types in a single region
+	// to emit. It returns once the region is scanned, or with ctx.Err() when
+	// ctx is done while it waits for emit.
+	StreamRegion(ctx context.Context, region string, resourceTypes []entity.ResourceType, emit chan<- *entity.Resource) error
+}
+
+// StreamRegion streams the resources of a region with the scanner. Scanners
+// that are not ResourceStreamers scan the whole region before emitting.
+func StreamRegion(ctx context.Context, scanner CloudScanner, region string, resourceTypes []entity.ResourceType, emit chan<- *entity.Resource) error {
+	if streamer, ok := scanner.(ResourceStreamer); ok {
+		return streamer.StreamRegion(ctx, region, resourceTypes, emit)
+	}
+	found, err := scanner.ScanRegion(ctx, region, resourceTypes)
+	if err != nil {
+		return err
+	}
+	return Emit(ctx, emit, found)
+}
+
+// Emit sends resources to emit, until ctx is done
+func Emit(ctx context.Context, emit chan<- *entity.Resource, resources []*entity.Resource) error {
+	for _, r := range resources {
+		select {
+		case emit <- r:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
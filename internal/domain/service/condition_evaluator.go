@@ -0,0 +1,200 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// ConditionFilter is the name of the condition tree in evaluations
+const ConditionFilter = "filter"
+
+// conditionEvaluator evaluates a validated condition tree. Regular
+// expressions are compiled once per evaluator.
+type conditionEvaluator struct {
+	patterns map[string]*regexp.Regexp
+}
+
+func newConditionEvaluator(root *entity.ConditionNode) (*conditionEvaluator, error) {
+	if err := root.Validate(); err != nil {
+		return nil, err
+	}
+	e := &conditionEvaluator{patterns: make(map[string]*regexp.Regexp)}
+	e.compile(root)
+	return e, nil
+}
+
+func (e *conditionEvaluator) compile(n *entity.ConditionNode) {
+	for i := range n.And {
+		e.compile(&n.And[i])
+	}
+	for i := range n.Or {
+		e.compile(&n.Or[i])
+	}
+	if n.Not != nil {
+		e.compile(n.Not)
+	}
+	if n.Op == entity.OperatorRegex {
+		pattern := n.Value.(string)
+		e.patterns[pattern] = regexp.MustCompile(pattern)
+	}
+}
+
+// eval reports whether the resource matches the node, and explains the
+// outcome by the comparison that decided it
+func (e *conditionEvaluator) eval(n *entity.ConditionNode, r *entity.Resource, now time.Time) (bool, string) {
+	switch {
+	case n.And != nil:
+		var details []string
+		for i := range n.And {
+			ok, detail := e.eval(&n.And[i], r, now)
+			if !ok {
+				return false, detail
+			}
+			details = append(details, detail)
+		}
+		return true, strings.Join(details, " and ")
+	case n.Or != nil:
+		var details []string
+		for i := range n.Or {
+			ok, detail := e.eval(&n.Or[i], r, now)
+			if ok {
+				return true, detail
+			}
+			details = append(details, detail)
+		}
+		return false, strings.Join(details, " and ")
+	case n.Not != nil:
+		ok, detail := e.eval(n.Not, r, now)
+		return !ok, "not (" + detail + ")"
+	}
+	return e.compare(n, r, now)
+}
+
+func (e *conditionEvaluator) compare(n *entity.ConditionNode, r *entity.Resource, now time.Time) (bool, string) {
+	actual, found := conditionField(n.Field, r, now)
+	describe := func(ok bool) (bool, string) {
+		verb := "matches"
+		if !ok {
+			verb = "does not match"
+		}
+		if !found {
+			return ok, fmt.Sprintf("%s is not set, %s %s %v", n.Field, verb, n.Op, n.Value)
+		}
+		return ok, fmt.Sprintf("%s is %v, %s %s %v", n.Field, actual, verb, n.Op, n.Value)
+	}
+
+	if n.Op == entity.OperatorExists {
+		want := true
+		if b, ok := n.Value.(bool); ok {
+			want = b
+		}
+		return describe(found == want)
+	}
+	if !found {
+		// Missing fields only match negative comparisons
+		return describe(n.Op == entity.OperatorNe)
+	}
+
+	switch n.Op {
+	case entity.OperatorEq:
+		return describe(conditionEqual(n.Field, actual, n.Value))
+	case entity.OperatorNe:
+		return describe(!conditionEqual(n.Field, actual, n.Value))
+	case entity.OperatorIn:
+		for _, v := range n.Value.([]any) {
+			if conditionEqual(n.Field, actual, v) {
+				return describe(true)
+			}
+		}
+		return describe(false)
+	case entity.OperatorRegex:
+		return describe(e.patterns[n.Value.(string)].MatchString(fmt.Sprint(actual)))
+	}
+
+	a, err := entity.ConditionNumber(n.Field, actual)
+	if err != nil {
+		return describe(false)
+	}
+	b, err := entity.ConditionNumber(n.Field, n.Value)
+	if err != nil {
+		return describe(false)
+	}
+	switch n.Op {
+	case entity.OperatorGt:
+		return describe(a > b)
+	case entity.OperatorGte:
+		return describe(a >= b)
+	case entity.OperatorLt:
+		return describe(a < b)
+	case entity.OperatorLte:
+		return describe(a <= b)
+	}
+	return describe(false)
+}
+
+// conditionField returns the value of a field of the resource. Ages are
+// returned in days.
+func conditionField(field string, r *entity.Resource, now time.Time) (any, bool) {
+	switch field {
+	case entity.FieldMonthlyCost:
+		return r.MonthlyCost, true
+	case entity.FieldCarbonFootprint:
+		return r.CarbonFootprint, true
+	case entity.FieldAge:
+		return days(now.Sub(r.CreatedAt)), true
+	case entity.FieldLastSeen:
+		return days(now.Sub(r.LastSeenAt)), true
+	case entity.FieldRegion:
+		return r.Region, true
+	case entity.FieldName:
+		return r.Name, true
+	case entity.FieldType:
+		return string(r.Type), true
+	case entity.FieldStatus:
+		return string(r.Status), true
+	case entity.FieldResourceID:
+		return r.ResourceID, true
+	case entity.FieldIaCManaged:
+		return r.IaCManaged, true
+	case entity.FieldIaCTool:
+		return r.IaCTool, r.IaCTool != ""
+	}
+
+	if key, ok := strings.CutPrefix(field, entity.FieldTagPrefix); ok {
+		v, ok := r.Tags[key]
+		return v, ok
+	}
+	if path, ok := strings.CutPrefix(field, entity.FieldMetadataPrefix); ok {
+		var v any = r.Metadata
+		for _, key := range strings.Split(path, ".") {
+			m, ok := v.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if v, ok = m[key]; !ok {
+				return nil, false
+			}
+		}
+		return v, v != nil
+	}
+	return nil, false
+}
+
+// conditionEqual compares numerically when both sides are numbers, as text
+// otherwise
+func conditionEqual(field string, actual, want any) bool {
+	if entity.IsNumericField(field) {
+		a, errA := entity.ConditionNumber(field, actual)
+		b, errB := entity.ConditionNumber(field, want)
+		return errA == nil && errB == nil && a == b
+	}
+	return fmt.Sprint(actual) == fmt.Sprint(want)
+}
+
+func days(d time.Duration) float64 {
+	return float64(int(d.Hours()/24*10)) / 10
+}
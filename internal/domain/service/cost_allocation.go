@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// CostAllocator materializes the cost allocation of organizations by their
+// cost-allocation tags
+type CostAllocator interface {
+	// Refresh recomputes the allocation of the current month of an
+	// organization from its resources
+	Refresh(ctx context.Context, org *entity.Organization, now time.Time) error
+
+	// Month returns the stored allocation of an organization for the month
+	// of the given time, by tag key and decreasing cost
+	Month(ctx context.Context, orgID uuid.UUID, month time.Time) ([]entity.CostAllocation, error)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// CostMetadataKey is the resource metadata key holding the details of the
+// conversion of the provider-reported cost to monthly USD
+const CostMetadataKey = "cost"
+
+// NormalizedCost is a provider-reported cost converted to monthly USD
+type NormalizedCost struct {
+	MonthlyUSD   float64
+	Original     entity.Cost
+	ExchangeRate float64 // USD per unit of the original currency
+}
+
+// Metadata returns the conversion details to store on the resource
+func (n NormalizedCost) Metadata() map[string]any {
+	return map[string]any{
+		"amount":          n.Original.Amount,
+		"currency":        n.Original.Currency,
+		"period":          string(n.Original.Period),
+		"exchange_rate":   n.ExchangeRate,
+		"hours_per_month": entity.HoursPerMonth,
+		"monthly_usd":     n.MonthlyUSD,
+	}
+}
+
+// CostNormalizer converts provider-reported costs into monthly USD so costs
+// from different providers and regions can be summed
+type CostNormalizer struct {
+	rates map[string]float64
+}
+
+// NewCostNormalizer creates a CostNormalizer from exchange rates expressed in
+// USD per unit of each currency. USD is always known.
+func NewCostNormalizer(rates map[string]float64) *CostNormalizer {
+	n := &CostNormalizer{rates: map[string]float64{entity.CurrencyUSD: 1}}
+	for currency, rate := range rates {
+		if rate > 0 {
+			n.rates[strings.ToUpper(currency)] = rate
+		}
+	}
+	return n
+}
+
+// Normalize converts a cost to monthly USD, rounded to the cent. An empty
+// currency is taken as USD and an empty period as monthly.
+func (n *CostNormalizer) Normalize(c entity.Cost) (NormalizedCost, error) {
+	currency := strings.ToUpper(c.Currency)
+	if currency == "" {
+		currency = entity.CurrencyUSD
+	}
+	rate, ok := n.rates[currency]
+	if !ok {
+		return NormalizedCost{}, fmt.Errorf("no exchange rate for currency %q", c.Currency)
+	}
+	monthly, ok := c.Monthly()
+	if !ok {
+		return NormalizedCost{}, fmt.Errorf("unknown billing period %q", c.Period)
+	}
+
+	c.Currency = currency
+	if c.Period == "" {
+		c.Period = entity.BillingPeriodMonthly
+	}
+	return NormalizedCost{
+		MonthlyUSD:   math.Round(monthly*rate*100) / 100,
+		Original:     c,
+		ExchangeRate: rate,
+	}, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys scanners set on managed databases for the detector
+const (
+	DatabaseMetadataState         = "state"                    // provider state, e.g. "available", "stopped", "Paused"
+	DatabaseMetadataConnections   = "max_connections"          // highest connection count over the lookback window
+	DatabaseMetadataCPUMax        = "max_cpu_percent"          // highest CPU utilization over the lookback window
+	DatabaseMetadataInstanceClass = "instance_class"           // e.g. "db.m5.2xlarge", "GP_Gen5_8", "db-custom-8-32768"
+	DatabaseMetadataIdleReason    = "idle_reason"              // set by the detector on unused databases
+	DatabaseMetadataSuggestion    = "suggested_instance_class" // set by the detector on oversized databases
+)
+
+// Reasons a managed database is reported as unused
+const (
+	IdleReasonNoConnections = "no_connections"
+	IdleReasonStoppedBilled = "stopped_billed"
+)
+
+// DefaultDatabaseLookback is the window scanners read connection and CPU
+// metrics over
+const DefaultDatabaseLookback = 14 * 24 * time.Hour
+
+// DefaultDatabaseOversizedCPU is the peak CPU utilization, in percent, under
+// which a database is considered oversized for its instance class
+const DefaultDatabaseOversizedCPU = 10.0
+
+// stoppedDatabaseStates are provider states of databases that serve no
+// traffic while their storage (and for some, their compute) is still billed
+var stoppedDatabaseStates = map[string]bool{
+	"stopped":  true, // RDS, Cloud SQL with activation policy NEVER
+	"stopping": true,
+	"paused":   true, // Azure SQL serverless
+	"disabled": true,
+}
+
+// DetectIdleDatabases marks managed databases unused when they are stopped
+// but still billed or had no connection over the lookback window. Databases
+// in use whose CPU peaked under oversizedCPU percent get the next smaller
+// instance class suggested. Scanners call it from DetectUnused once the
+// metadata above is filled in; other resource types are left untouched.
+func DetectIdleDatabases(resources []*entity.Resource, oversizedCPU float64) {
+	for _, r := range resources {
+		if !r.Type.IsManagedDatabase() || r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+
+		reason := ""
+		if state, _ := r.Metadata[DatabaseMetadataState].(string); stoppedDatabaseStates[strings.ToLower(state)] {
+			reason = IdleReasonStoppedBilled
+		} else if conns, ok := metadataInt(r.Metadata[DatabaseMetadataConnections]); ok && conns == 0 {
+			reason = IdleReasonNoConnections
+		}
+		if reason != "" {
+			r.MarkAsUnused()
+			r.Metadata[DatabaseMetadataIdleReason] = reason
+			continue
+		}
+
+		cpu, ok := metadataFloat(r.Metadata[DatabaseMetadataCPUMax])
+		if !ok || cpu >= oversizedCPU {
+			continue
+		}
+		class, _ := r.Metadata[DatabaseMetadataInstanceClass].(string)
+		if smaller, ok := SmallerInstanceClass(r.Type, class); ok {
+			r.Metadata[DatabaseMetadataSuggestion] = smaller
+		}
+	}
+}
+
+// awsDatabaseSizes are the RDS instance sizes, smallest first
+var awsDatabaseSizes = []string{"micro", "small", "medium", "large", "xlarge", "2xlarge", "4xlarge", "8xlarge", "12xlarge", "16xlarge", "24xlarge"}
+
+var (
+	azureSKUPattern     = regexp.MustCompile(`^(.*_)(\d+)$`)
+	cloudSQLCustom      = regexp.MustCompile(`^db-custom-(\d+)-(\d+)$`)
+	cloudSQLPredefined  = regexp.MustCompile(`^(db-n1-(?:standard|highmem))-(\d+)$`)
+	snapshotUnsafeChars = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// SmallerInstanceClass returns the instance class with half the capacity of
+// class, or the previous size for RDS. ok is false when class is already
+// the smallest or not recognized.
+func SmallerInstanceClass(resourceType entity.ResourceType, class string) (string, bool) {
+	switch resourceType {
+	case entity.ResourceTypeRDSInstance:
+		i := strings.LastIndex(class, ".")
+		if i < 0 {
+			return "", false
+		}
+		for j, size := range awsDatabaseSizes {
+			if size == class[i+1:] && j > 0 {
+				return class[:i+1] + awsDatabaseSizes[j-1], true
+			}
+		}
+	case entity.ResourceTypeAzureSQL:
+		if m := azureSKUPattern.FindStringSubmatch(class); m != nil {
+			if vcores, _ := strconv.Atoi(m[2]); vcores >= 4 && vcores%2 == 0 {
+				return m[1] + strconv.Itoa(vcores/2), true
+			}
+		}
+	case entity.ResourceTypeCloudSQL:
+		if m := cloudSQLCustom.FindStringSubmatch(class); m != nil {
+			cpus, _ := strconv.Atoi(m[1])
+			memory, _ := strconv.Atoi(m[2])
+			if cpus >= 2 && cpus%2 == 0 {
+				return fmt.Sprintf("db-custom-%d-%d", cpus/2, memory/2), true
+			}
+		}
+		if m := cloudSQLPredefined.FindStringSubmatch(class); m != nil {
+			if cpus, _ := strconv.Atoi(m[2]); cpus >= 2 && cpus%2 == 0 {
+				return fmt.Sprintf("%s-%d", m[1], cpus/2), true
+			}
+		}
+	}
+	return "", false
+}
+
+// metadataFloat reads a number stored in metadata
+func metadataFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// DatabaseSnapshotter is implemented by cleaners of managed databases. It
+// deletes a database after taking a final snapshot with the given ID, and
+// fails without deleting anything if the snapshot cannot be taken.
+type DatabaseSnapshotter interface {
+	DeleteWithFinalSnapshot(ctx context.Context, resource *entity.Resource, snapshotID string) (*CleanupResult, error)
+}
+
+// FinalSnapshotID returns the ID of the final snapshot taken before deleting
+// a database, unique per deletion attempt
+func FinalSnapshotID(resource *entity.Resource, now time.Time) string {
+	name := strings.ToLower(resource.Name)
+	if name == "" {
+		name = strings.ToLower(resource.ResourceID)
+	}
+	name = snapshotUnsafeChars.ReplaceAllString(name, "-")
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	return fmt.Sprintf("cloudsweep-final-%s-%s", strings.Trim(name, "-"), now.UTC().Format("20060102150405"))
+}
+
+// DeleteDatabase deletes a managed database keeping a final snapshot, which
+// the recovery of the result restores from. Cleaners that cannot take one
+// are refused, as the data would be lost.
+func DeleteDatabase(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) (*CleanupResult, error) {
+	snapshotter, ok := cleaner.(DatabaseSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("cannot take a final snapshot of %s", resource.Type)
+	}
+	id := FinalSnapshotID(resource, time.Now())
+	result, err := snapshotter.DeleteWithFinalSnapshot(ctx, resource, id)
+	if err != nil || !result.Success {
+		return result, err
+	}
+	result.Recovery = snapshotRecovery(resource, id)
+	return result, nil
+}
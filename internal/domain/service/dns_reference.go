@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// DNSMetadataRecords is the metadata key of the DNS records pointing at a
+// resource, set by AnnotateDNSReferences. Its absence means the zones of the
+// account were not searched.
+const DNSMetadataRecords = "dns_records"
+
+// DNSRecord is a record of a DNS zone (Route 53, Azure DNS, Cloud DNS).
+// Values are the addresses of A and AAAA records, the target of CNAME
+// records, or the target of provider aliases, typed ALIAS.
+type DNSRecord struct {
+	Zone   string
+	Name   string
+	Type   string
+	Values []string
+}
+
+// String identifies the record in messages, e.g. "www.example.com CNAME"
+func (r DNSRecord) String() string {
+	return normalizeDNSName(r.Name) + " " + r.Type
+}
+
+// NeedsDNSCheck returns true for the resources DNS records may point at:
+// public IP addresses, load balancers and buckets serving a static website
+func NeedsDNSCheck(r *entity.Resource) bool {
+	if r.Type.IsObjectStorage() {
+		website, _ := r.Metadata[StorageMetadataWebsite].(string)
+		return website != ""
+	}
+	return r.Type.IsPublicIP() || r.Type.IsLoadBalancer()
+}
+
+// DNSTargets returns the addresses and hostnames of a resource that DNS
+// records point at
+func DNSTargets(r *entity.Resource) []string {
+	var targets []string
+	for _, key := range []string{IPMetadataAddress, LoadBalancerMetadataDNSName, StorageMetadataWebsite} {
+		if v, _ := r.Metadata[key].(string); v != "" {
+			targets = append(targets, normalizeDNSName(v))
+		}
+	}
+	return targets
+}
+
+// DNSRecordsPointingAt returns the A, AAAA, CNAME and alias records pointing
+// at a resource
+func DNSRecordsPointingAt(r *entity.Resource, records []DNSRecord) []string {
+	targets := DNSTargets(r)
+	if len(targets) == 0 {
+		return nil
+	}
+	var out []string
+	for _, record := range records {
+		switch strings.ToUpper(record.Type) {
+		case "A", "AAAA", "CNAME", "ALIAS":
+		default:
+			continue
+		}
+		for _, value := range record.Values {
+			if slices.Contains(targets, normalizeDNSName(value)) {
+				out = append(out, record.String())
+				break
+			}
+		}
+	}
+	return out
+}
+
+// DNSZoneReader reads the DNS zones of a cloud account (Route 53 hosted
+// zones, Cloud DNS managed zones)
+type DNSZoneReader interface {
+	// Records returns the records of every zone the account can read
+	Records(ctx context.Context) ([]DNSRecord, error)
+}
+
+// AnnotateDNSReferences records on resources the DNS records pointing at
+// them. Scanners call it from their scan with the records of every zone
+// the account can read, so that cleanup previews can show them; the records
+// are searched again before deletion.
+func AnnotateDNSReferences(resources []*entity.Resource, records []DNSRecord) {
+	for _, r := range resources {
+		if !NeedsDNSCheck(r) {
+			continue
+		}
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+		pointing := DNSRecordsPointingAt(r, records)
+		if pointing == nil {
+			pointing = []string{}
+		}
+		r.Metadata[DNSMetadataRecords] = pointing
+	}
+}
+
+// RecordedDNSReferences returns the DNS records pointing at a resource as of
+// its last scan. ok is false when the scan did not search DNS zones.
+func RecordedDNSReferences(r *entity.Resource) (records []string, ok bool) {
+	v, ok := r.Metadata[DNSMetadataRecords]
+	if !ok {
+		return nil, false
+	}
+	return metadataStrings(v), true
+}
+
+// normalizeDNSName lowercases a hostname and removes its trailing dot and
+// the "dualstack." prefix of Route 53 aliases to load balancers
+func normalizeDNSName(name string) string {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	return strings.TrimPrefix(name, "dualstack.")
+}
+
+// DNSInspector is implemented by cleaners that can search the DNS zones of
+// the account (Route 53, Azure DNS, Cloud DNS). It lists the records pointing
+// at a resource, which DNSRecordsPointingAt finds among the records of the
+// zones.
+type DNSInspector interface {
+	DNSRecordsReferencing(ctx context.Context, resource *entity.Resource) ([]string, error)
+}
+
+// ErrDNSUnsupported is returned by the DNSInspector of cleaner wrappers when
+// the cleaner they wrap cannot search DNS zones
+var ErrDNSUnsupported = errors.New("cannot inspect DNS records")
+
+// CheckDNSSafeDelete verifies that no DNS record points at a public IP
+// address, load balancer or static website bucket before it is released or
+// deleted: the records would dangle, and a released address or bucket name
+// may be taken by someone else, who would then receive their traffic. The
+// zones are searched with the cleaner, or the records of the last scan are
+// used when it cannot; resources are refused when neither searched them.
+func CheckDNSSafeDelete(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) error {
+	if !NeedsDNSCheck(resource) {
+		return nil
+	}
+	err := ErrDNSUnsupported
+	var records []string
+	if inspector, ok := cleaner.(DNSInspector); ok {
+		records, err = inspector.DNSRecordsReferencing(ctx, resource)
+	}
+	if errors.Is(err, ErrDNSUnsupported) {
+		var ok bool
+		if records, ok = RecordedDNSReferences(resource); !ok {
+			return fmt.Errorf("cannot verify DNS records for %s", resource.Type)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to check DNS records: %w", err)
+	}
+	if len(records) > 0 {
+		return fmt.Errorf("still referenced by DNS records %v", records)
+	}
+	return nil
+}
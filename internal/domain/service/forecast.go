@@ -0,0 +1,210 @@
+package service
+
+import (
+	"sort"
+	"time"
+)
+
+// Forecast horizons, in months
+var ForecastHorizons = []int{3, 6, 12}
+
+// Trends are fitted on the cost snapshots of the last ForecastTrendWindow,
+// and only once they span ForecastMinTrendSpan: shorter histories project
+// the current spend as it is
+const (
+	ForecastTrendWindow  = 90 * 24 * time.Hour
+	ForecastMinTrendSpan = 7 * 24 * time.Hour
+)
+
+// CostPoint is the monthly spend and waste of an organization as recorded on
+// a day
+type CostPoint struct {
+	Day       time.Time
+	TotalCost float64
+	WasteCost float64
+}
+
+// ScheduledSaving is the monthly cost a scheduled policy removes from its
+// next run on, by stopping, quarantining, releasing or deleting the
+// resources it currently matches
+type ScheduledSaving struct {
+	PolicyID       string    `json:"policy_id"`
+	Policy         string    `json:"policy"`
+	NextRun        time.Time `json:"next_run"`
+	Resources      int       `json:"resources"`
+	MonthlySavings float64   `json:"monthly_savings"`
+	// WasteSavings is the part of the savings on resources flagged unused
+	WasteSavings float64 `json:"waste_savings"`
+}
+
+// ForecastInputs are what a savings forecast projects from
+type ForecastInputs struct {
+	Now time.Time
+	// TotalCost and WasteCost are the current monthly spend and waste
+	TotalCost float64
+	WasteCost float64
+	// History is the recorded cost snapshots, in any order
+	History []CostPoint
+	// Scheduled is the savings of the scheduled policies, each resource
+	// counted once, at the earliest run matching it
+	Scheduled []ScheduledSaving
+	// RecommendationSavings and RecommendationWaste are the monthly savings
+	// of the open recommendations not already covered by a scheduled
+	// policy, and the part of them on unused resources
+	RecommendationSavings float64
+	RecommendationWaste   float64
+	// Months projected, 12 when zero
+	Months int
+}
+
+// Scenarios is a figure under the three scenarios of a forecast: the trend
+// alone, with the effects of the scheduled policies, and with all the open
+// recommendations applied on top of them
+type Scenarios struct {
+	Baseline            float64 `json:"baseline"`
+	WithPolicies        float64 `json:"with_policies"`
+	WithRecommendations float64 `json:"with_recommendations"`
+}
+
+// ForecastMonth is the projected spend and waste of a calendar month
+type ForecastMonth struct {
+	Month string    `json:"month"` // 2006-01
+	Spend Scenarios `json:"spend"`
+	Waste Scenarios `json:"waste"`
+}
+
+// ForecastHorizon sums the projected months up to a horizon
+type ForecastHorizon struct {
+	Months int       `json:"months"`
+	Spend  Scenarios `json:"spend"`
+	Waste  Scenarios `json:"waste"`
+	// Savings against the baseline spend
+	SavingsWithPolicies        float64 `json:"savings_with_policies"`
+	SavingsWithRecommendations float64 `json:"savings_with_recommendations"`
+}
+
+// Forecast projects the spend and waste of an organization
+type Forecast struct {
+	// SpendTrend and WasteTrend are the fitted changes of the monthly
+	// spend and waste per month
+	SpendTrend  float64           `json:"spend_trend_per_month"`
+	WasteTrend  float64           `json:"waste_trend_per_month"`
+	HistoryDays int               `json:"history_days"`
+	Months      []ForecastMonth   `json:"months"`
+	Horizons    []ForecastHorizon `json:"horizons"`
+}
+
+// ForecastSpend projects the monthly spend and waste of the calendar months
+// after now. Each month is projected at its middle from the current figures
+// and the linear trends of the recorded snapshots, never below zero. The
+// savings of a scheduled policy count from its next run, prorated over the
+// month it falls in; the open recommendations count from the first month.
+func ForecastSpend(in ForecastInputs) Forecast {
+	months := in.Months
+	if months <= 0 {
+		months = 12
+	}
+	now := in.Now.UTC()
+	spendSlope, wasteSlope, span := costTrends(in.History, now)
+
+	f := Forecast{
+		SpendTrend:  spendSlope * 24 * 30,
+		WasteTrend:  wasteSlope * 24 * 30,
+		HistoryDays: int(span.Hours() / 24),
+		Months:      make([]ForecastMonth, 0, months),
+	}
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= months; i++ {
+		from := start.AddDate(0, i, 0)
+		to := from.AddDate(0, 1, 0)
+		hours := from.Add(to.Sub(from) / 2).Sub(now).Hours()
+
+		m := ForecastMonth{Month: from.Format("2006-01")}
+		m.Spend.Baseline = max(in.TotalCost+spendSlope*hours, 0)
+		m.Waste.Baseline = max(in.WasteCost+wasteSlope*hours, 0)
+
+		var saved, wasteSaved float64
+		for _, s := range in.Scheduled {
+			share := activeShare(s.NextRun, from, to)
+			saved += s.MonthlySavings * share
+			wasteSaved += s.WasteSavings * share
+		}
+		m.Spend.WithPolicies = max(m.Spend.Baseline-saved, 0)
+		m.Waste.WithPolicies = max(m.Waste.Baseline-wasteSaved, 0)
+		m.Spend.WithRecommendations = max(m.Spend.WithPolicies-in.RecommendationSavings, 0)
+		m.Waste.WithRecommendations = max(m.Waste.WithPolicies-in.RecommendationWaste, 0)
+		f.Months = append(f.Months, m)
+	}
+
+	for _, h := range ForecastHorizons {
+		if h > months {
+			break
+		}
+		horizon := ForecastHorizon{Months: h}
+		for _, m := range f.Months[:h] {
+			horizon.Spend = horizon.Spend.add(m.Spend)
+			horizon.Waste = horizon.Waste.add(m.Waste)
+		}
+		horizon.SavingsWithPolicies = horizon.Spend.Baseline - horizon.Spend.WithPolicies
+		horizon.SavingsWithRecommendations = horizon.Spend.Baseline - horizon.Spend.WithRecommendations
+		f.Horizons = append(f.Horizons, horizon)
+	}
+	return f
+}
+
+func (s Scenarios) add(o Scenarios) Scenarios {
+	return Scenarios{
+		Baseline:            s.Baseline + o.Baseline,
+		WithPolicies:        s.WithPolicies + o.WithPolicies,
+		WithRecommendations: s.WithRecommendations + o.WithRecommendations,
+	}
+}
+
+// activeShare returns the share of a month after a policy run
+func activeShare(run, from, to time.Time) float64 {
+	switch {
+	case !run.After(from):
+		return 1
+	case !run.Before(to):
+		return 0
+	}
+	return to.Sub(run).Hours() / to.Sub(from).Hours()
+}
+
+// costTrends fits the spend and waste of the snapshots of the trend window
+// by least squares, returning their slopes per hour and the span of the
+// snapshots fitted. The slopes are zero when the span is too short.
+func costTrends(history []CostPoint, now time.Time) (spend, waste float64, span time.Duration) {
+	var points []CostPoint
+	for _, p := range history {
+		if now.Sub(p.Day) <= ForecastTrendWindow {
+			points = append(points, p)
+		}
+	}
+	if len(points) < 2 {
+		return 0, 0, 0
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Day.Before(points[j].Day) })
+	span = points[len(points)-1].Day.Sub(points[0].Day)
+	if span < ForecastMinTrendSpan {
+		return 0, 0, span
+	}
+
+	// Hours are counted from the first snapshot to keep the sums small
+	var sumX, sumXX, sumSpend, sumXSpend, sumWaste, sumXWaste float64
+	for _, p := range points {
+		x := p.Day.Sub(points[0].Day).Hours()
+		sumX += x
+		sumXX += x * x
+		sumSpend += p.TotalCost
+		sumXSpend += x * p.TotalCost
+		sumWaste += p.WasteCost
+		sumXWaste += x * p.WasteCost
+	}
+	n := float64(len(points))
+	d := n*sumXX - sumX*sumX
+	if d == 0 {
+		return 0, 0, span
+	}
+	return (n*sumXSpend - sumX*sumSpend) / d, (n*sumXWaste - sumX*sumWaste) / d, span
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// Hygiene score factors
+const (
+	HygieneFactorWaste         = "waste"
+	HygieneFactorTagCompliance = "tag_compliance"
+	HygieneFactorSnapshotAge   = "snapshot_age"
+	HygieneFactorBacklog       = "backlog"
+)
+
+const (
+	// StaleSnapshotAge is the age after which a snapshot counts as stale
+	StaleSnapshotAge = 90 * 24 * time.Hour
+	// BacklogAge is how long an unused resource can wait for a decision
+	// (cleanup approval or snooze) before it counts as backlog
+	BacklogAge = 30 * 24 * time.Hour
+)
+
+// hygieneWeights weigh each factor in the overall score; they sum to 1
+var hygieneWeights = map[string]float64{
+	HygieneFactorWaste:         0.4,
+	HygieneFactorTagCompliance: 0.25,
+	HygieneFactorSnapshotAge:   0.15,
+	HygieneFactorBacklog:       0.2,
+}
+
+// HygieneInputs are the inventory figures of an organization a hygiene score
+// is computed from
+type HygieneInputs struct {
+	Resources      int64   // resources not deleted
+	Unused         int64   // resources flagged unused
+	TotalCost      float64 // monthly cost of resources not deleted
+	WasteCost      float64 // monthly cost of unused resources
+	Owned          int64   // resources attributed to an owner by their tags
+	Snapshots      int64
+	StaleSnapshots int64 // snapshots older than StaleSnapshotAge
+	Backlog        int64 // unused resources undecided for more than BacklogAge
+}
+
+// HygieneFactor is one component of a hygiene score, scored from 0 to 100
+type HygieneFactor struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail"`
+}
+
+// HygieneScore is the cloud hygiene score of an organization, from 0 (worst)
+// to 100, with the factors it combines
+type HygieneScore struct {
+	Score   float64         `json:"score"`
+	Factors []HygieneFactor `json:"factors"`
+}
+
+// HygieneScorer computes the hygiene score of organizations and reads the
+// scores recorded daily
+type HygieneScorer interface {
+	// Compute returns the current hygiene score of an organization
+	Compute(ctx context.Context, org *entity.Organization, now time.Time) (HygieneScore, error)
+
+	// History returns the recorded daily scores of an organization since
+	// the given time, oldest first
+	History(ctx context.Context, orgID uuid.UUID, since time.Time) ([]HygieneRecord, error)
+}
+
+// HygieneRecord is the hygiene score of an organization recorded on a day,
+// with its monthly spend and waste that day. Scores recorded before the
+// costs were kept have none.
+type HygieneRecord struct {
+	Day       time.Time
+	Score     float64
+	Factors   map[string]float64 // factor name to score
+	TotalCost float64
+	WasteCost float64
+}
+
+// ComputeHygieneScore combines the waste ratio, tag compliance, snapshot age
+// and recommendation backlog into a single score. A factor with nothing to
+// measure (e.g. no snapshots) scores 100.
+func ComputeHygieneScore(in HygieneInputs) HygieneScore {
+	waste := HygieneFactor{Name: HygieneFactorWaste, Score: 100, Detail: "no resources"}
+	switch {
+	case in.TotalCost > 0:
+		waste.Score = 100 * (1 - in.WasteCost/in.TotalCost)
+		waste.Detail = fmt.Sprintf("%.2f of %.2f monthly cost is wasted", in.WasteCost, in.TotalCost)
+	case in.Resources > 0:
+		waste.Score = 100 * (1 - ratio(in.Unused, in.Resources))
+		waste.Detail = fmt.Sprintf("%d of %d resources are unused", in.Unused, in.Resources)
+	}
+
+	tags := HygieneFactor{Name: HygieneFactorTagCompliance, Score: 100, Detail: "no resources"}
+	if in.Resources > 0 {
+		tags.Score = 100 * ratio(in.Owned, in.Resources)
+		tags.Detail = fmt.Sprintf("%d of %d resources have an owner", in.Owned, in.Resources)
+	}
+
+	snapshots := HygieneFactor{Name: HygieneFactorSnapshotAge, Score: 100, Detail: "no snapshots"}
+	if in.Snapshots > 0 {
+		snapshots.Score = 100 * (1 - ratio(in.StaleSnapshots, in.Snapshots))
+		snapshots.Detail = fmt.Sprintf("%d of %d snapshots are older than %d days", in.StaleSnapshots, in.Snapshots, int(StaleSnapshotAge.Hours()/24))
+	}
+
+	backlog := HygieneFactor{Name: HygieneFactorBacklog, Score: 100, Detail: "no unused resources"}
+	if in.Unused > 0 {
+		backlog.Score = 100 * (1 - ratio(in.Backlog, in.Unused))
+		backlog.Detail = fmt.Sprintf("%d of %d unused resources undecided for more than %d days", in.Backlog, in.Unused, int(BacklogAge.Hours()/24))
+	}
+
+	score := HygieneScore{Factors: []HygieneFactor{waste, tags, snapshots, backlog}}
+	for i := range score.Factors {
+		f := &score.Factors[i]
+		f.Score = round1(math.Max(0, math.Min(100, f.Score)))
+		f.Weight = hygieneWeights[f.Name]
+		score.Score += f.Score * f.Weight
+	}
+	score.Score = round1(score.Score)
+	return score
+}
+
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// IaCChange is a pull request changing the infrastructure-as-code
+// configuration of a resource
+type IaCChange struct {
+	Provider string // github or gitlab
+	Repo     string
+	Number   int
+	URL      string
+}
+
+// IaCChangeProposer opens pull requests removing resources from their
+// infrastructure-as-code configuration, so the tool deletes them on its next
+// run instead of recreating them
+type IaCChangeProposer interface {
+	// ProposeRemoval opens a pull request removing the resource, or returns
+	// the one still open for it. taskID is the cleanup task it is opened
+	// for.
+	ProposeRemoval(ctx context.Context, resource *entity.Resource, taskID string) (*IaCChange, error)
+}
+
+// terraformAddress matches the address of a resource of the root module,
+// e.g. "aws_instance.web"
+var terraformAddress = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9_-]*)\.([a-zA-Z_][a-zA-Z0-9_-]*)$`)
+
+// ParseTerraformAddress returns the type and name of the resource block at a
+// Terraform address. Resources of modules, or created with count or
+// for_each, are refused: removing their block would change other resources.
+func ParseTerraformAddress(address string) (typ, name string, err error) {
+	switch {
+	case strings.HasPrefix(address, "module."):
+		return "", "", fmt.Errorf("resource %s is declared in a module", address)
+	case strings.HasSuffix(address, "]"):
+		return "", "", fmt.Errorf("resource %s is one of several instances of its block", address)
+	}
+	m := terraformAddress.FindStringSubmatch(address)
+	if m == nil {
+		return "", "", fmt.Errorf("invalid terraform address %q", address)
+	}
+	return m[1], m[2], nil
+}
+
+// RemoveTerraformBlock removes a resource block from a Terraform
+// configuration file. Braces in strings, comments and heredocs are ignored
+// while looking for the end of the block. ok is false when the file does not
+// declare the resource.
+func RemoveTerraformBlock(src []byte, typ, name string) (out []byte, ok bool) {
+	header := regexp.MustCompile(`^resource\s+"` + regexp.QuoteMeta(typ) + `"\s+"` + regexp.QuoteMeta(name) + `"\s*\{`)
+
+	depth, start := 0, -1
+	lineStart := true
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if lineStart && depth == 0 && c != ' ' && c != '\t' {
+			if loc := header.FindIndex(src[i:]); loc != nil {
+				start = bytes.LastIndexByte(src[:i], '\n') + 1
+				i += loc[1] - 1
+				depth = 1
+				lineStart = false
+				continue
+			}
+		}
+		if c != ' ' && c != '\t' {
+			lineStart = c == '\n'
+		}
+
+		switch {
+		case c == '#' || (c == '/' && nextByte(src, i) == '/'):
+			i = endOfLine(src, i) - 1
+		case c == '/' && nextByte(src, i) == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return src, false
+			}
+			i += end + 3
+		case c == '"':
+			i = endOfString(src, i+1)
+		case c == '<' && nextByte(src, i) == '<':
+			i = endOfHeredoc(src, i)
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 && start >= 0 {
+				end := endOfLine(src, i+1)
+				if end < len(src) {
+					end++
+				}
+				out = append(append([]byte{}, src[:start]...), src[end:]...)
+				out = bytes.ReplaceAll(out, []byte("\n\n\n"), []byte("\n\n"))
+				if bytes.HasSuffix(out, []byte("\n\n")) {
+					out = out[:len(out)-1]
+				}
+				return out, true
+			}
+		}
+	}
+	return src, false
+}
+
+func nextByte(src []byte, i int) byte {
+	if i+1 < len(src) {
+		return src[i+1]
+	}
+	return 0
+}
+
+// endOfLine returns the index of the newline ending the line at i
+func endOfLine(src []byte, i int) int {
+	if n := bytes.IndexByte(src[i:], '\n'); n >= 0 {
+		return i + n
+	}
+	return len(src)
+}
+
+// endOfString returns the index of the quote closing the string starting at
+// i. Quotes of interpolations inside the string open strings of their own,
+// whose braces are balanced.
+func endOfString(src []byte, i int) int {
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"', '\n':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// heredocMarker matches the opening of a heredoc, e.g. "<<EOF" or "<<-EOT"
+var heredocMarker = regexp.MustCompile(`^<<-?([A-Za-z_][A-Za-z0-9_]*)[ \t]*\r?\n`)
+
+// endOfHeredoc returns the index of the last character of the heredoc
+// starting at i, or i when the "<<" does not open one
+func endOfHeredoc(src []byte, i int) int {
+	m := heredocMarker.FindSubmatchIndex(src[i:])
+	if m == nil {
+		return i
+	}
+	marker := string(src[i+m[2] : i+m[3]])
+	for line := i + m[1]; line < len(src); {
+		end := endOfLine(src, line)
+		if strings.TrimSpace(string(src[line:end])) == marker {
+			return end - 1
+		}
+		line = end + 1
+	}
+	return len(src)
+}
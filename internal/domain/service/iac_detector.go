@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// Infrastructure-as-code tools resources can be managed by
+const (
+	IaCToolTerraform         = "terraform"
+	IaCToolCloudFormation    = "cloudformation"
+	IaCToolPulumi            = "pulumi"
+	IaCToolDeploymentManager = "deployment_manager"
+	IaCToolConfigConnector   = "config_connector"
+	IaCToolHelm              = "helm"
+	IaCToolArgoCD            = "argocd"
+)
+
+// Metadata keys set on resources managed by infrastructure-as-code
+const (
+	IaCMetadataSource  = "iac_source"  // tag or state file the tool was detected from
+	IaCMetadataState   = "iac_state"   // state file declaring the resource
+	IaCMetadataAddress = "iac_address" // Terraform address, e.g. "aws_instance.web"
+)
+
+const iacSourceTerraformState = "terraform_state"
+
+// minTerraformStateVersion is the oldest state format that can be parsed,
+// written since Terraform 0.12
+const minTerraformStateVersion = 4
+
+// iacTagRules detect the tool managing a resource from a tag or label key,
+// and value when not empty (compared case-insensitively). Provider tags such
+// as aws:cloudformation:stack-name are set by the tool itself; the others
+// are common conventions.
+var iacTagRules = []struct {
+	key   string
+	value string
+	tool  string
+}{
+	{"aws:cloudformation:stack-name", "", IaCToolCloudFormation},
+	{"aws:cloudformation:stack-id", "", IaCToolCloudFormation},
+	{"pulumi:project", "", IaCToolPulumi},
+	{"pulumi:stack", "", IaCToolPulumi},
+	{"goog-dm", "", IaCToolDeploymentManager},
+	{"managed-by-cnrm", "", IaCToolConfigConnector},
+	{"app.kubernetes.io/managed-by", "helm", IaCToolHelm},
+	{"argocd.argoproj.io/instance", "", IaCToolArgoCD},
+	{"terraform", "true", IaCToolTerraform},
+	{"managed-by", "terraform", IaCToolTerraform},
+	{"managed_by", "terraform", IaCToolTerraform},
+	{"ManagedBy", "terraform", IaCToolTerraform},
+	{"managed-by", "pulumi", IaCToolPulumi},
+	{"ManagedBy", "pulumi", IaCToolPulumi},
+}
+
+// IaCDeclaration is where a resource is declared in infrastructure-as-code
+type IaCDeclaration struct {
+	Tool    string
+	State   string // state file, as listed in the organization settings
+	Address string // address of the resource in the state
+}
+
+// IaCStateSource lists the resources declared in the infrastructure-as-code
+// state files of an organization
+type IaCStateSource interface {
+	// ManagedResources returns the declarations of the resources, by cloud
+	// ID
+	ManagedResources(ctx context.Context, orgID uuid.UUID) (map[string]IaCDeclaration, error)
+}
+
+// DetectIaCOwnership flags the resources managed by an infrastructure-as-code
+// tool, from their tags or from the IDs found in state files. Deleting them
+// outside the tool would make it recreate them, or fail its next run.
+func DetectIaCOwnership(resources []*entity.Resource, declared map[string]IaCDeclaration) {
+	for _, r := range resources {
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+		delete(r.Metadata, IaCMetadataSource)
+		delete(r.Metadata, IaCMetadataState)
+		delete(r.Metadata, IaCMetadataAddress)
+
+		tool, source := iacToolFromTags(r.Tags)
+		// The state gives the address of the resource, needed to change its
+		// configuration, even when a tag already flags it
+		if d, ok := declared[r.ResourceID]; ok && (tool == "" || tool == d.Tool) {
+			tool, source = d.Tool, iacSourceTerraformState
+			r.Metadata[IaCMetadataState] = d.State
+			r.Metadata[IaCMetadataAddress] = d.Address
+		}
+
+		r.IaCManaged = tool != ""
+		r.IaCTool = tool
+		if source != "" {
+			r.Metadata[IaCMetadataSource] = source
+		}
+	}
+}
+
+// CheckIaCSafeDelete returns an error when a resource is managed by an
+// infrastructure-as-code tool: it has to be removed from its configuration,
+// or the tool would recreate it on its next run
+func CheckIaCSafeDelete(resource *entity.Resource) error {
+	if resource.IaCManaged {
+		return fmt.Errorf("resource is managed by %s", resource.IaCTool)
+	}
+	return nil
+}
+
+func iacToolFromTags(tags map[string]string) (tool, source string) {
+	for _, rule := range iacTagRules {
+		value, ok := tags[rule.key]
+		if !ok || (rule.value != "" && !strings.EqualFold(value, rule.value)) {
+			continue
+		}
+		return rule.tool, "tag:" + rule.key
+	}
+	return "", ""
+}
+
+// ParseTerraformState returns the addresses of the resources declared in a
+// Terraform state file (format version 4), by cloud ID. IDs, ARNs and self
+// links are all collected so resources match whichever form a scanner
+// reports.
+func ParseTerraformState(data []byte) (map[string]string, error) {
+	var state struct {
+		Version   int `json:"version"`
+		Resources []struct {
+			Module    string `json:"module"`
+			Mode      string `json:"mode"`
+			Type      string `json:"type"`
+			Name      string `json:"name"`
+			Instances []struct {
+				IndexKey   any            `json:"index_key"`
+				Attributes map[string]any `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid terraform state: %w", err)
+	}
+	if state.Version < minTerraformStateVersion {
+		return nil, fmt.Errorf("unsupported terraform state version %d", state.Version)
+	}
+
+	ids := make(map[string]string)
+	for _, res := range state.Resources {
+		// Data sources are read by Terraform, not managed by it
+		if res.Mode != "managed" {
+			continue
+		}
+		address := res.Type + "." + res.Name
+		if res.Module != "" {
+			address = res.Module + "." + address
+		}
+		for _, inst := range res.Instances {
+			instance := address
+			switch key := inst.IndexKey.(type) {
+			case float64:
+				instance = fmt.Sprintf("%s[%d]", address, int(key))
+			case string:
+				instance = fmt.Sprintf("%s[%q]", address, key)
+			}
+			for _, attr := range []string{"id", "arn", "self_link"} {
+				if id, ok := inst.Attributes[attr].(string); ok && id != "" {
+					ids[id] = instance
+				}
+			}
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// InventoryNotifier is told about the changes made to the inventory of an
+// organization outside of scans, to drop the results cached for it and
+// keep the clients following its events up to date. Failures are only
+// logged, since they must not fail the change.
+type InventoryNotifier interface {
+	// InventoryChanged reports that resources of an organization were
+	// created or updated
+	InventoryChanged(ctx context.Context, orgID uuid.UUID)
+
+	// StatusChanged reports the new status of a resource
+	StatusChanged(ctx context.Context, orgID, resourceID uuid.UUID, status entity.ResourceStatus)
+}
+
+// InventoryQueue hands the work following changes of the inventory over to
+// the workers
+type InventoryQueue interface {
+	// EnqueueRestore queues the restore of a quarantined resource
+	EnqueueRestore(ctx context.Context, orgID, resourceID uuid.UUID) error
+
+	// EnqueueOwnerAssignment queues the assignment of owners to the
+	// resources of an organization
+	EnqueueOwnerAssignment(ctx context.Context, orgID uuid.UUID) error
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys scanners set on public IP addresses for the detector
+const (
+	IPMetadataAddress     = "address"        // the IP address itself
+	IPMetadataAssociation = "association_id" // instance, interface, load balancer or NAT gateway using the address, empty when unassociated
+	IPMetadataIdleReason  = "idle_reason"    // set by the detector on unassociated addresses
+)
+
+// IdleReasonUnassociated is the reason a public IP address is reported as
+// unused
+const IdleReasonUnassociated = "unassociated"
+
+// publicIPHourlyCosts are the list prices of reserved public IPv4 addresses
+// per hour, when idle and when in use. Providers bill them whether they are
+// used or not, and Google Cloud doubles the price of idle ones.
+var publicIPHourlyCosts = map[entity.ResourceType]struct{ idle, inUse float64 }{
+	entity.ResourceTypeElasticIP:     {idle: 0.005, inUse: 0.005},
+	entity.ResourceTypeAzurePublicIP: {idle: 0.005, inUse: 0.005},
+	entity.ResourceTypeGCPStaticIP:   {idle: 0.01, inUse: 0.005},
+}
+
+// PublicIPCost returns the hourly cost of a public IP address, depending on
+// whether it is associated. ok is false for other resource types.
+func PublicIPCost(r *entity.Resource) (cost entity.Cost, ok bool) {
+	prices, ok := publicIPHourlyCosts[r.Type]
+	if !ok {
+		return entity.Cost{}, false
+	}
+	price := prices.inUse
+	if !publicIPAssociated(r) {
+		price = prices.idle
+	}
+	return entity.Cost{Amount: price, Currency: entity.CurrencyUSD, Period: entity.BillingPeriodHourly}, true
+}
+
+// DetectIdlePublicIPs marks unused the public IP addresses associated with
+// nothing. Scanners call it from DetectUnused once the metadata above is
+// filled in; other resource types are left untouched.
+func DetectIdlePublicIPs(resources []*entity.Resource) {
+	for _, r := range resources {
+		if !r.Type.IsPublicIP() || r.Status == entity.ResourceStatusExcluded || publicIPAssociated(r) {
+			continue
+		}
+		r.MarkAsUnused()
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+		r.Metadata[IPMetadataIdleReason] = IdleReasonUnassociated
+	}
+}
+
+func publicIPAssociated(r *entity.Resource) bool {
+	association, _ := r.Metadata[IPMetadataAssociation].(string)
+	return association != ""
+}
+
+// IPReleaser is implemented by cleaners of public IP addresses. It releases
+// an address back to the provider, after which it may be allocated to
+// someone else and cannot be recovered.
+type IPReleaser interface {
+	Release(ctx context.Context, resource *entity.Resource) (*CleanupResult, error)
+}
+
+// ReleaseIP releases an unassociated public IP address. Callers check that
+// no DNS record points to it first, with CheckDNSSafeDelete.
+func ReleaseIP(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) (*CleanupResult, error) {
+	if !resource.Type.IsPublicIP() {
+		return nil, fmt.Errorf("cannot release %s, which is not a public IP address", resource.Type)
+	}
+	if publicIPAssociated(resource) {
+		return nil, fmt.Errorf("still associated with %s", resource.Metadata[IPMetadataAssociation])
+	}
+	releaser, ok := cleaner.(IPReleaser)
+	if !ok {
+		return nil, fmt.Errorf("cannot release %s", resource.Type)
+	}
+	result, err := releaser.Release(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	result.Action = entity.PolicyActionRelease
+	result.CostSaved = resource.MonthlyCost
+	result.CarbonSaved = resource.CarbonFootprint
+	return result, nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys scanners set on network attachments for the detector
+const (
+	NetworkMetadataState      = "state"             // provider state, e.g. "active", "expired"
+	NetworkMetadataBytes      = "bytes_transferred" // bytes in and out over the lookback window
+	NetworkMetadataPeerExists = "peer_exists"       // false when the remote VPC or gateway is gone
+	NetworkMetadataPeerOwner  = "peer_account_id"   // account owning the other side
+	NetworkMetadataIdleReason = "idle_reason"       // set by the detector on unused attachments
+)
+
+// Reasons a network attachment is reported as unused
+const (
+	IdleReasonOrphaned  = "orphaned"
+	IdleReasonNoTraffic = "no_traffic"
+)
+
+// DefaultNetworkIdleBytes is the traffic over the lookback window under which
+// a network attachment is considered idle
+const DefaultNetworkIdleBytes int64 = 1 << 20
+
+// DefaultNetworkLookback is the window scanners read the traffic of network
+// attachments over
+const DefaultNetworkLookback = 14 * 24 * time.Hour
+
+// orphanedStates are provider states of attachments that can no longer carry
+// traffic but may still be billed or clutter route tables
+var orphanedStates = map[string]bool{
+	"pending-acceptance": true,
+	"expired":            true,
+	"rejected":           true,
+	"failed":             true,
+	"down":               true,
+	"rejected-by-peer":   true,
+}
+
+// networkHourlyCosts are the AWS list prices of network attachments, per hour
+// and per attachment. Peering connections are only billed for data transfer.
+var networkHourlyCosts = map[entity.ResourceType]float64{
+	entity.ResourceTypeVPCPeering:               0,
+	entity.ResourceTypeVPNConnection:            0.05,
+	entity.ResourceTypeTransitGatewayAttachment: 0.05,
+}
+
+// NetworkAttachmentCost returns the hourly cost of a network attachment. ok
+// is false for other resource types.
+func NetworkAttachmentCost(resourceType entity.ResourceType) (cost entity.Cost, ok bool) {
+	price, ok := networkHourlyCosts[resourceType]
+	if !ok {
+		return entity.Cost{}, false
+	}
+	return entity.Cost{Amount: price, Currency: entity.CurrencyUSD, Period: entity.BillingPeriodHourly}, true
+}
+
+// DetectIdleNetworkAttachments marks network attachments unused when they
+// are orphaned (rejected, expired or pointing to a peer that no longer
+// exists) or carried at most idleBytes over the lookback window. Scanners
+// call it from DetectUnused once the metadata above is filled in; other
+// resource types are left untouched.
+func DetectIdleNetworkAttachments(resources []*entity.Resource, idleBytes int64) {
+	for _, r := range resources {
+		if !r.Type.IsNetworkAttachment() || r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		reason := networkIdleReason(r, idleBytes)
+		if reason == "" {
+			continue
+		}
+		r.MarkAsUnused()
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+		r.Metadata[NetworkMetadataIdleReason] = reason
+	}
+}
+
+func networkIdleReason(r *entity.Resource, idleBytes int64) string {
+	if state, _ := r.Metadata[NetworkMetadataState].(string); orphanedStates[state] {
+		return IdleReasonOrphaned
+	}
+	if exists, ok := r.Metadata[NetworkMetadataPeerExists].(bool); ok && !exists {
+		return IdleReasonOrphaned
+	}
+	if bytes, ok := metadataInt(r.Metadata[NetworkMetadataBytes]); ok && bytes <= idleBytes {
+		return IdleReasonNoTraffic
+	}
+	return ""
+}
+
+// metadataInt reads an integer stored in metadata, which may have been
+// decoded from JSON as a float64
+func metadataInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// RouteTableInspector is implemented by cleaners of network attachments and
+// NAT gateways. It lists the route tables that still route traffic to a
+// resource.
+type RouteTableInspector interface {
+	RouteTablesReferencing(ctx context.Context, resource *entity.Resource) ([]string, error)
+}
+
+// CheckNetworkSafeDelete verifies that no route table references a network
+// attachment or NAT gateway before it is deleted. Cleaners that cannot
+// inspect route tables are refused, as deleting a routed resource blackholes
+// traffic.
+func CheckNetworkSafeDelete(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) error {
+	if !resource.Type.IsNetworkAttachment() && !resource.Type.IsNATGateway() {
+		return nil
+	}
+	inspector, ok := cleaner.(RouteTableInspector)
+	if !ok {
+		return fmt.Errorf("cannot verify route tables for %s", resource.Type)
+	}
+	tables, err := inspector.RouteTablesReferencing(ctx, resource)
+	if err != nil {
+		return fmt.Errorf("failed to check route tables: %w", err)
+	}
+	if len(tables) > 0 {
+		return fmt.Errorf("still referenced by route tables %v", tables)
+	}
+	return nil
+}
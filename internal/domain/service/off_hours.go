@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// OffHoursMetadataKey is the metadata key marking a resource stopped by an
+// off-hours schedule, holding the policy ID and when it was stopped
+const OffHoursMetadataKey = "off_hours"
+
+// ResourceStarter is implemented by cleaners that can start a stopped
+// resource again
+type ResourceStarter interface {
+	Start(ctx context.Context, resource *entity.Resource) (*CleanupResult, error)
+}
+
+// MarkStoppedOffHours records on a resource that the given schedule policy
+// stopped it
+func MarkStoppedOffHours(resource *entity.Resource, policyID string, at time.Time) {
+	if resource.Metadata == nil {
+		resource.Metadata = make(map[string]any)
+	}
+	resource.Metadata[OffHoursMetadataKey] = map[string]any{
+		"policy_id":  policyID,
+		"stopped_at": at.UTC().Format(time.RFC3339),
+	}
+}
+
+// StoppedOffHoursBy returns the ID of the schedule policy that stopped a
+// resource, or "" when it was not stopped by one
+func StoppedOffHoursBy(resource *entity.Resource) string {
+	marker, _ := resource.Metadata[OffHoursMetadataKey].(map[string]any)
+	id, _ := marker["policy_id"].(string)
+	return id
+}
+
+// IsStopped reports whether the provider state of an instance scanners
+// recorded is a stopped one
+func IsStopped(resource *entity.Resource) bool {
+	state, _ := resource.Metadata[ComputeMetadataState].(string)
+	return stoppedInstanceStates[strings.ToLower(state)]
+}
+
+// CheckSafeRestart returns an error when a resource must not be started by
+// the schedule policy: only resources the policy stopped itself are started,
+// so an instance stopped by hand or by a cleanup stays stopped, and never
+// once it was deleted, excluded or approved for cleanup.
+func CheckSafeRestart(resource *entity.Resource, policyID string) error {
+	switch by := StoppedOffHoursBy(resource); {
+	case by == "":
+		return fmt.Errorf("resource was not stopped by an off-hours schedule")
+	case by != policyID:
+		return fmt.Errorf("resource was stopped by policy %s", by)
+	}
+	switch resource.Status {
+	case entity.ResourceStatusDeleted, entity.ResourceStatusRemoved, entity.ResourceStatusExcluded:
+		return fmt.Errorf("resource is %s", resource.Status)
+	}
+	if resource.CleanupApprovedAt != nil {
+		return fmt.Errorf("resource is approved for cleanup")
+	}
+	return nil
+}
+
+// StartResource starts a resource stopped off-hours. Cleaners that cannot
+// start resources are refused.
+func StartResource(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) (*CleanupResult, error) {
+	starter, ok := cleaner.(ResourceStarter)
+	if !ok {
+		return nil, fmt.Errorf("cannot start %s", resource.Type)
+	}
+	return starter.Start(ctx, resource)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// OwnerMapping assigns an owner to the resources whose cloud ID matches
+// Pattern. Patterns ending with "*" match by prefix.
+type OwnerMapping struct {
+	Pattern string
+	Owner   string
+}
+
+// Matches reports whether the mapping applies to a cloud resource ID
+func (m OwnerMapping) Matches(resourceID string) bool {
+	if prefix, ok := strings.CutSuffix(m.Pattern, "*"); ok {
+		return strings.HasPrefix(resourceID, prefix)
+	}
+	return resourceID == m.Pattern
+}
+
+// OwnerResolver maps the resources of an organization to their owner, from
+// the most to the least specific source: the uploaded mapping file, the
+// owner tags, the default owner of the cloud account and the default owner
+// of the organization
+type OwnerResolver struct {
+	Rules entity.OwnerRules
+	// Mappings are tried in order; the first matching entry wins
+	Mappings []OwnerMapping
+	// AccountOwners map provider account IDs to their default owner
+	AccountOwners map[string]string
+}
+
+// Resolve returns the owner email of a resource and where it comes from,
+// or an empty owner when the resource cannot be attributed
+func (r *OwnerResolver) Resolve(resource *entity.Resource) (string, entity.OwnerSource) {
+	for _, m := range r.Mappings {
+		if m.Matches(resource.ResourceID) {
+			return strings.ToLower(m.Owner), entity.OwnerSourceMapping
+		}
+	}
+	if owner := r.Rules.TagOwner(resource.Tags); owner != "" {
+		return strings.ToLower(owner), entity.OwnerSourceTag
+	}
+	if owner := r.AccountOwners[resource.AccountID]; resource.AccountID != "" && owner != "" {
+		return strings.ToLower(owner), entity.OwnerSourceAccount
+	}
+	if r.Rules.DefaultOwner != "" {
+		return strings.ToLower(r.Rules.DefaultOwner), entity.OwnerSourceDefault
+	}
+	return "", ""
+}
+
+// OwnerNotifier tells the owners of the resources matched by policies with
+// the notify action about them, in place of a central channel
+type OwnerNotifier interface {
+	// NotifyOwners sends each owner one message listing their resources,
+	// or adds them to the daily digest of the owner when the organization
+	// asks for it. It returns the owner notified of each resource, by
+	// resource ID; resources without an owner, or whose owner could not be
+	// notified, are left out and the errors are joined.
+	NotifyOwners(ctx context.Context, orgID uuid.UUID, resources []*entity.Resource, taskID string) (map[uuid.UUID]string, error)
+}
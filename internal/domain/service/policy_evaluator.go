@@ -0,0 +1,204 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Policy condition names reported in evaluations
+const (
+	ConditionProvider       = "provider"
+	ConditionResourceTypes  = "resource_types"
+	ConditionUnusedDays     = "unused_days"
+	ConditionMinMonthlyCost = "min_monthly_cost"
+	ConditionMaxMonthlyCost = "max_monthly_cost"
+	ConditionRequiredTags   = "required_tags"
+	ConditionExcludedTags   = "excluded_tags"
+	ConditionRegions        = "regions"
+	ConditionNamePattern    = "name_pattern"
+	ConditionIaCManaged     = "iac_managed"
+)
+
+// ConditionResult explains how a single policy condition applied to a resource
+type ConditionResult struct {
+	Condition string `json:"condition"`
+	Matched   bool   `json:"matched"`
+	Detail    string `json:"detail"`
+}
+
+// Evaluation is the outcome of evaluating a policy against a resource
+type Evaluation struct {
+	Matched    bool              `json:"matched"`
+	Conditions []ConditionResult `json:"conditions"`
+}
+
+// PolicyEvaluator decides which resources a policy applies to. Only the
+// conditions set on the policy are evaluated; a resource matches when all of
+// them match. Resources managed by infrastructure-as-code only match
+// policies including them.
+type PolicyEvaluator struct {
+	policy  *entity.Policy
+	pattern *regexp.Regexp
+	filter  *conditionEvaluator
+}
+
+// NewPolicyEvaluator creates a PolicyEvaluator for the given policy. It fails
+// when the policy name pattern is not a valid regular expression or its
+// condition tree is invalid.
+func NewPolicyEvaluator(policy *entity.Policy) (*PolicyEvaluator, error) {
+	e := &PolicyEvaluator{policy: policy}
+	if p := policy.Conditions.NamePattern; p != "" {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern: %w", err)
+		}
+		e.pattern = re
+	}
+	if root := policy.Conditions.Filter; root != nil {
+		filter, err := newConditionEvaluator(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+		e.filter = filter
+	}
+	return e, nil
+}
+
+// Evaluate evaluates the policy against a resource at the given time.
+// Unused resources are considered unused since they were first inventoried.
+func (e *PolicyEvaluator) Evaluate(r *entity.Resource, now time.Time) Evaluation {
+	p := e.policy
+	cond := p.Conditions
+	var results []ConditionResult
+
+	results = append(results, ConditionResult{
+		Condition: ConditionProvider,
+		Matched:   r.Provider == p.Provider,
+		Detail:    fmt.Sprintf("provider %s, policy targets %s", r.Provider, p.Provider),
+	})
+
+	if len(p.ResourceTypes) > 0 {
+		results = append(results, ConditionResult{
+			Condition: ConditionResourceTypes,
+			Matched:   slices.Contains(p.ResourceTypes, r.Type),
+			Detail:    fmt.Sprintf("type %s", r.Type),
+		})
+	}
+
+	if cond.UnusedDays > 0 {
+		res := ConditionResult{Condition: ConditionUnusedDays}
+		if r.IsUnused() {
+			days := int(now.Sub(r.CreatedAt).Hours() / 24)
+			res.Matched = days >= cond.UnusedDays
+			res.Detail = fmt.Sprintf("unused for %d days, needs %d", days, cond.UnusedDays)
+		} else {
+			res.Detail = fmt.Sprintf("status is %s", r.Status)
+		}
+		results = append(results, res)
+	}
+
+	if cond.MinMonthlyCost > 0 {
+		results = append(results, ConditionResult{
+			Condition: ConditionMinMonthlyCost,
+			Matched:   r.MonthlyCost >= cond.MinMonthlyCost,
+			Detail:    fmt.Sprintf("costs %.2f/month, minimum %.2f", r.MonthlyCost, cond.MinMonthlyCost),
+		})
+	}
+
+	if cond.MaxMonthlyCost > 0 {
+		results = append(results, ConditionResult{
+			Condition: ConditionMaxMonthlyCost,
+			Matched:   r.MonthlyCost <= cond.MaxMonthlyCost,
+			Detail:    fmt.Sprintf("costs %.2f/month, maximum %.2f", r.MonthlyCost, cond.MaxMonthlyCost),
+		})
+	}
+
+	if len(cond.RequiredTags) > 0 {
+		missing := tagMismatches(r.Tags, cond.RequiredTags, false)
+		res := ConditionResult{Condition: ConditionRequiredTags, Matched: len(missing) == 0, Detail: "all required tags present"}
+		if len(missing) > 0 {
+			res.Detail = "missing " + strings.Join(missing, ", ")
+		}
+		results = append(results, res)
+	}
+
+	if len(cond.ExcludedTags) > 0 {
+		found := tagMismatches(r.Tags, cond.ExcludedTags, true)
+		res := ConditionResult{Condition: ConditionExcludedTags, Matched: len(found) == 0, Detail: "no excluded tags"}
+		if len(found) > 0 {
+			res.Detail = "has " + strings.Join(found, ", ")
+		}
+		results = append(results, res)
+	}
+
+	if len(cond.Regions) > 0 {
+		results = append(results, ConditionResult{
+			Condition: ConditionRegions,
+			Matched:   slices.Contains(cond.Regions, r.Region),
+			Detail:    fmt.Sprintf("region %s", r.Region),
+		})
+	}
+
+	if e.pattern != nil {
+		results = append(results, ConditionResult{
+			Condition: ConditionNamePattern,
+			Matched:   e.pattern.MatchString(r.Name),
+			Detail:    fmt.Sprintf("name %q against %q", r.Name, cond.NamePattern),
+		})
+	}
+
+	// Resources managed by infrastructure-as-code must be removed from their
+	// configuration, unless the policy explicitly targets them
+	if r.IaCManaged {
+		res := ConditionResult{Condition: ConditionIaCManaged, Matched: cond.IncludeIaCManaged}
+		if cond.IncludeIaCManaged {
+			res.Detail = fmt.Sprintf("managed by %s, included by the policy", r.IaCTool)
+		} else {
+			res.Detail = fmt.Sprintf("managed by %s, set include_iac_managed to target it", r.IaCTool)
+		}
+		results = append(results, res)
+	}
+
+	if e.filter != nil {
+		matched, detail := e.filter.eval(cond.Filter, r, now)
+		results = append(results, ConditionResult{
+			Condition: ConditionFilter,
+			Matched:   matched,
+			Detail:    detail,
+		})
+	}
+
+	eval := Evaluation{Matched: true, Conditions: results}
+	for _, res := range results {
+		if !res.Matched {
+			eval.Matched = false
+			break
+		}
+	}
+	return eval
+}
+
+// tagMismatches compares resource tags with policy tags, where an empty
+// policy value matches any value. It returns the policy tags that are missing
+// from the resource, or those present on it when present is true.
+func tagMismatches(tags, want map[string]string, present bool) []string {
+	var out []string
+	for k, v := range want {
+		got, ok := tags[k]
+		hit := ok && (v == "" || got == v)
+		if hit == present {
+			if v == "" {
+				out = append(out, k)
+			} else {
+				out = append(out, k+"="+v)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
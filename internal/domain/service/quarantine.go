@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// DefaultQuarantineWindow is how long quarantined resources are kept before
+// being deleted
+const DefaultQuarantineWindow = 7 * 24 * time.Hour
+
+// QuarantineTagKey is the tag set on quarantined resources, holding the date
+// they will be deleted on
+const QuarantineTagKey = "cloudsweep:quarantined-until"
+
+// QuarantineMetadataSnapshot is the metadata key holding the ID of the
+// snapshot taken when a resource that cannot be stopped was quarantined
+const QuarantineMetadataSnapshot = "quarantine_snapshot_id"
+
+// ResourceSnapshotter is implemented by cleaners that can snapshot volumes
+// and databases without deleting them
+type ResourceSnapshotter interface {
+	Snapshot(ctx context.Context, resource *entity.Resource, snapshotID string) (*CleanupResult, error)
+}
+
+// TagRemover is implemented by cleaners that can remove tags from a resource
+type TagRemover interface {
+	Untag(ctx context.Context, resource *entity.Resource, keys []string) (*CleanupResult, error)
+}
+
+// snapshotTypes are the disks snapshotted when quarantined, as they cannot
+// be stopped and their data would be lost on deletion
+var snapshotTypes = map[entity.ResourceType]bool{
+	entity.ResourceTypeEBSVolume: true,
+	entity.ResourceTypeAzureDisk: true,
+	entity.ResourceTypeGCEDisk:   true,
+	entity.ResourceTypeK8sPVC:    true,
+}
+
+// QuarantineResource takes a resource out of service without deleting it:
+// instances and databases are stopped, disks are snapshotted, and the
+// resource is tagged with the end of its window. Disks are refused when the
+// cleaner cannot snapshot them.
+func QuarantineResource(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource, until time.Time) (*CleanupResult, error) {
+	switch {
+	case resource.Type.IsStoppable():
+		if result, err := cleaner.Stop(ctx, resource); err != nil || !result.Success {
+			return result, err
+		}
+	case snapshotTypes[resource.Type]:
+		snapshotter, ok := cleaner.(ResourceSnapshotter)
+		if !ok {
+			return nil, fmt.Errorf("cannot snapshot %s", resource.Type)
+		}
+		id := QuarantineSnapshotID(resource, time.Now())
+		if result, err := snapshotter.Snapshot(ctx, resource, id); err != nil || !result.Success {
+			return result, err
+		}
+		if resource.Metadata == nil {
+			resource.Metadata = make(map[string]any)
+		}
+		resource.Metadata[QuarantineMetadataSnapshot] = id
+	}
+
+	result, err := cleaner.Tag(ctx, resource, map[string]string{
+		QuarantineTagKey: until.UTC().Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Action = entity.PolicyActionQuarantine
+	result.CostSaved = resource.MonthlyCost
+	result.CarbonSaved = resource.CarbonFootprint
+	result.Recovery = quarantineRecovery(resource, until)
+	return result, nil
+}
+
+// RestoreResource brings a quarantined resource back into service: stopped
+// resources are started and the quarantine tag is removed. Snapshots taken
+// on quarantine are kept.
+func RestoreResource(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) (*CleanupResult, error) {
+	remover, ok := cleaner.(TagRemover)
+	if !ok {
+		return nil, fmt.Errorf("cannot remove tags from %s", resource.Type)
+	}
+	if resource.Type.IsStoppable() {
+		if result, err := StartResource(ctx, cleaner, resource); err != nil || !result.Success {
+			return result, err
+		}
+	}
+	return remover.Untag(ctx, resource, []string{QuarantineTagKey})
+}
+
+// QuarantineSnapshotID returns the ID of the snapshot taken when a resource
+// is quarantined
+func QuarantineSnapshotID(resource *entity.Resource, now time.Time) string {
+	id := FinalSnapshotID(resource, now)
+	return "cloudsweep-quarantine-" + id[len("cloudsweep-final-"):]
+}
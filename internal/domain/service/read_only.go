@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Scopes of the read-only mode
+const (
+	ReadOnlyInstallation = "installation"
+	ReadOnlyOrganization = "organization"
+)
+
+// ErrReadOnly is wrapped by the errors of the operations refused because of
+// the read-only mode
+var ErrReadOnly = errors.New("read-only mode")
+
+// ReadOnlyError is returned when cloud changes are refused because the
+// installation or the organization is in read-only mode
+type ReadOnlyError struct {
+	Scope  string // ReadOnlyInstallation or ReadOnlyOrganization
+	Reason string // given when the mode was enabled, may be empty
+}
+
+func (e *ReadOnlyError) Error() string {
+	msg := "cloud changes are disabled: the " + e.Scope + " is in read-only mode"
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	return msg
+}
+
+// Is makes ReadOnlyError match ErrReadOnly
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+// ReadOnlyGuard tells whether the cloud resources of an organization may be
+// changed. In read-only mode, set for the whole installation or for an
+// organization during an incident or while trust is being built, scans and
+// dry runs go on but stopping, tagging, quarantining, deleting, releasing,
+// restoring and starting resources are refused. CheckWritable returns a
+// *ReadOnlyError when they are.
+type ReadOnlyGuard interface {
+	CheckWritable(ctx context.Context, orgID uuid.UUID) error
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys scanners set on instances and volumes for recommendations
+const (
+	ComputeMetadataInstanceType = "instance_type"   // e.g. "m5.2xlarge", "Standard_D8s_v3", "n2-standard-8"
+	ComputeMetadataCPUMax       = "max_cpu_percent" // highest CPU utilization over the lookback window
+	VolumeMetadataType          = "volume_type"     // EBS volume type, e.g. "gp2"
+	VolumeMetadataSizeGB        = "size_gb"
+)
+
+// DefaultRightsizeCPU is the peak CPU utilization, in percent, under which an
+// instance is recommended the next smaller size
+const DefaultRightsizeCPU = 20.0
+
+// SteadyUsageAge is how long an instance must have been running for its cost
+// to count towards a commitment
+const SteadyUsageAge = 30 * 24 * time.Hour
+
+// commitmentCoverage is the share of steady compute spend recommended for a
+// commitment, leaving room for instances being cleaned up or resized
+const commitmentCoverage = 0.8
+
+// minCommitmentSpend is the steady monthly compute spend, in USD, under which
+// no commitment is recommended
+const minCommitmentSpend = 100.0
+
+// commitmentOffers are the one-year, no upfront commitments of each provider
+// and their average discount on on-demand prices
+var commitmentOffers = map[entity.CloudProvider]struct {
+	name     string
+	discount float64
+}{
+	entity.CloudProviderAWS:   {"Compute Savings Plan (1 year, no upfront)", 0.27},
+	entity.CloudProviderAzure: {"Azure savings plan for compute (1 year)", 0.25},
+	entity.CloudProviderGCP:   {"Committed use discount (1 year)", 0.37},
+}
+
+// gp3Discount is the price difference between gp2 ($0.10/GB-month) and gp3
+// ($0.08/GB-month) EBS volumes
+const gp3Discount = 0.2
+
+// Recommendation is a saving opportunity on a resource or, for commitments,
+// on an organization's steady spend of a provider
+type Recommendation struct {
+	Type           entity.RecommendationType
+	Key            string           // identifies the recommendation across runs
+	Resource       *entity.Resource // nil for commitments
+	Provider       entity.CloudProvider
+	Current        string
+	Recommended    string
+	MonthlySavings float64 // USD
+	Details        map[string]any
+}
+
+// RecommendResource returns the recommendations for a resource in use:
+// rightsizing of low-utilization instances and databases, and migration of
+// gp2 volumes to gp3. Unused and deleted resources are cleanup candidates
+// and get none.
+func RecommendResource(r *entity.Resource, rightsizeCPU float64) []Recommendation {
+	if r.Status != entity.ResourceStatusActive {
+		return nil
+	}
+
+	var recs []Recommendation
+	add := func(t entity.RecommendationType, current, recommended string, savings float64, details map[string]any) {
+		if savings <= 0 {
+			return
+		}
+		recs = append(recs, Recommendation{
+			Type:           t,
+			Key:            fmt.Sprintf("%s:%s", t, r.ID),
+			Resource:       r,
+			Provider:       r.Provider,
+			Current:        current,
+			Recommended:    recommended,
+			MonthlySavings: savings,
+			Details:        details,
+		})
+	}
+
+	switch {
+	case r.Type.IsManagedDatabase():
+		current, _ := r.Metadata[DatabaseMetadataInstanceClass].(string)
+		if smaller, ok := r.Metadata[DatabaseMetadataSuggestion].(string); ok {
+			add(entity.RecommendationTypeRightsize, current, smaller, r.MonthlyCost/2, map[string]any{
+				"max_cpu_percent": r.Metadata[DatabaseMetadataCPUMax],
+			})
+		}
+	case r.Type == entity.ResourceTypeK8sDeployment:
+		if rs, ok := r.Metadata["rightsizing"].(map[string]any); ok {
+			savings, _ := metadataFloat(rs["monthly_savings"])
+			add(entity.RecommendationTypeRightsize, "current requests", "lower requests", savings, rs)
+		}
+	case r.Type.IsStoppable():
+		cpu, ok := metadataFloat(r.Metadata[ComputeMetadataCPUMax])
+		if !ok || cpu >= rightsizeCPU {
+			break
+		}
+		current, _ := r.Metadata[ComputeMetadataInstanceType].(string)
+		if smaller, ok := SmallerInstanceType(r.Type, current); ok {
+			add(entity.RecommendationTypeRightsize, current, smaller, r.MonthlyCost/2, map[string]any{
+				"max_cpu_percent": cpu,
+			})
+		}
+	case r.Type == entity.ResourceTypeEBSVolume:
+		if t, _ := r.Metadata[VolumeMetadataType].(string); t == "gp2" {
+			savings := r.MonthlyCost * gp3Discount
+			if savings == 0 {
+				size, _ := metadataFloat(r.Metadata[VolumeMetadataSizeGB])
+				savings = size * 0.10 * gp3Discount
+			}
+			add(entity.RecommendationTypeGP3Migration, "gp2", "gp3", savings, map[string]any{
+				"size_gb": r.Metadata[VolumeMetadataSizeGB],
+			})
+		}
+	}
+	return recs
+}
+
+// RecommendCommitment recommends committing to part of the steady compute
+// spend of a provider, i.e. the monthly cost of instances running for more
+// than SteadyUsageAge. ok is false when the spend is too low or the provider
+// has no commitment offer.
+func RecommendCommitment(provider entity.CloudProvider, steadyMonthlySpend float64) (Recommendation, bool) {
+	offer, ok := commitmentOffers[provider]
+	if !ok || steadyMonthlySpend < minCommitmentSpend {
+		return Recommendation{}, false
+	}
+	commitment := steadyMonthlySpend * commitmentCoverage
+	hourly := commitment / entity.HoursPerMonth
+	return Recommendation{
+		Type:           entity.RecommendationTypeCommitment,
+		Key:            fmt.Sprintf("%s:%s", entity.RecommendationTypeCommitment, provider),
+		Provider:       provider,
+		Current:        "on-demand",
+		Recommended:    fmt.Sprintf("%s, $%.2f/hour", offer.name, hourly),
+		MonthlySavings: commitment * offer.discount,
+		Details: map[string]any{
+			"steady_monthly_spend": steadyMonthlySpend,
+			"hourly_commitment":    hourly,
+			"discount":             offer.discount,
+		},
+	}, true
+}
+
+// awsInstanceSizes are the EC2 instance sizes, smallest first
+var awsInstanceSizes = []string{"nano", "micro", "small", "medium", "large", "xlarge", "2xlarge", "4xlarge", "8xlarge", "12xlarge", "16xlarge", "24xlarge"}
+
+var (
+	azureVMSizePattern = regexp.MustCompile(`^(Standard_[A-Z]+)(\d+)(.*)$`)
+	gceTypePattern     = regexp.MustCompile(`^([a-z0-9]+-[a-z]+)-(\d+)$`)
+)
+
+// SmallerInstanceType returns the instance type with half the vCPUs of
+// instanceType in the same family. ok is false when it is already the
+// smallest or not recognized.
+func SmallerInstanceType(resourceType entity.ResourceType, instanceType string) (string, bool) {
+	switch resourceType {
+	case entity.ResourceTypeEC2Instance:
+		family, size, ok := strings.Cut(instanceType, ".")
+		if !ok {
+			return "", false
+		}
+		for i, s := range awsInstanceSizes {
+			if s == size && i > 0 {
+				return family + "." + awsInstanceSizes[i-1], true
+			}
+		}
+	case entity.ResourceTypeAzureVM:
+		if m := azureVMSizePattern.FindStringSubmatch(instanceType); m != nil {
+			if vcpus, _ := strconv.Atoi(m[2]); vcpus >= 2 && vcpus%2 == 0 {
+				return m[1] + strconv.Itoa(vcpus/2) + m[3], true
+			}
+		}
+	case entity.ResourceTypeGCEInstance:
+		if m := gceTypePattern.FindStringSubmatch(instanceType); m != nil {
+			if vcpus, _ := strconv.Atoi(m[2]); vcpus >= 4 && vcpus%2 == 0 {
+				return fmt.Sprintf("%s-%d", m[1], vcpus/2), true
+			}
+		}
+	case entity.ResourceTypeRDSInstance, entity.ResourceTypeAzureSQL, entity.ResourceTypeCloudSQL:
+		return SmallerInstanceClass(resourceType, instanceType)
+	}
+	return "", false
+}
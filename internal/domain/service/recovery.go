@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// MarkedForDeletionTagKey is the tag set on resources by the tag action
+const MarkedForDeletionTagKey = "cloudsweep:marked-for-deletion"
+
+// RecoveryKind is how the effect of a cleanup action can be undone
+type RecoveryKind string
+
+const (
+	// RecoveryStart starts a stopped resource again
+	RecoveryStart RecoveryKind = "start"
+	// RecoveryUnquarantine starts a quarantined resource again and removes
+	// its quarantine tag, before its window ends
+	RecoveryUnquarantine RecoveryKind = "unquarantine"
+	// RecoveryUntag removes the tag marking a resource for deletion
+	RecoveryUntag RecoveryKind = "untag"
+	// RecoveryRestoreSnapshot recreates a deleted database or disk from the
+	// snapshot taken before it was deleted
+	RecoveryRestoreSnapshot RecoveryKind = "restore_snapshot"
+	// RecoveryManual cannot be done by CloudSweep: the instructions tell
+	// what is left to recreate the resource, if anything
+	RecoveryManual RecoveryKind = "manual"
+)
+
+// Bucket versioning states, as reported by the providers
+const (
+	BucketVersioningEnabled   = "Enabled"
+	BucketVersioningSuspended = "Suspended"
+	BucketVersioningDisabled  = "Disabled"
+)
+
+// Recovery tells how to undo a cleanup action that succeeded, from what was
+// recorded while running it. It is kept with the attempt in the timeline of
+// the resource.
+type Recovery struct {
+	Kind RecoveryKind `json:"kind"`
+	// Automated is true when a rollback can undo the action, given a
+	// cleaner supporting it
+	Automated bool `json:"automated"`
+	// InstanceID is the ID in the cloud of the stopped or quarantined
+	// resource
+	InstanceID string `json:"instance_id,omitempty"`
+	// SnapshotID is the snapshot taken before the action: the final
+	// snapshot of a database or the quarantine snapshot of a disk
+	SnapshotID string `json:"snapshot_id,omitempty"`
+	// BucketVersioning is the versioning state of a bucket before it was
+	// deleted, to recreate it alike
+	BucketVersioning string `json:"bucket_versioning,omitempty"`
+	// UndoUntil is when the action can no longer be undone, e.g. the end of
+	// a quarantine window; nil when it is not bounded
+	UndoUntil    *time.Time `json:"undo_until,omitempty"`
+	Instructions string     `json:"instructions"`
+}
+
+// ManualRecoveryError is returned when rolling back an action that cannot be
+// undone automatically
+type ManualRecoveryError struct {
+	Instructions string
+}
+
+func (e *ManualRecoveryError) Error() string {
+	return "the action cannot be undone automatically: " + e.Instructions
+}
+
+// SnapshotRestorer is implemented by cleaners that can recreate a deleted
+// database or disk from a snapshot. Databases are restored under their
+// former name; disks get a new ID, found by the next scan.
+type SnapshotRestorer interface {
+	RestoreSnapshot(ctx context.Context, resource *entity.Resource, snapshotID string) (*CleanupResult, error)
+}
+
+// BucketVersioningReader is implemented by cleaners that can read the
+// versioning state of a bucket before deleting it
+type BucketVersioningReader interface {
+	BucketVersioning(ctx context.Context, resource *entity.Resource) (string, error)
+}
+
+// RecoveryFor describes how to undo an action that succeeded on a resource.
+// The final snapshots of databases, the quarantine windows and the
+// versioning of buckets are recorded by DeleteDatabase, QuarantineResource
+// and DeleteBucket on their results; RecoveryFor covers the other actions.
+// It returns nil for the actions leaving resources as they are.
+func RecoveryFor(resource *entity.Resource, action entity.PolicyAction) *Recovery {
+	switch action {
+	case entity.PolicyActionStop:
+		return &Recovery{
+			Kind:         RecoveryStart,
+			Automated:    true,
+			InstanceID:   resource.ResourceID,
+			Instructions: fmt.Sprintf("Start %s %s in %s again.", resource.Type, resource.ResourceID, resource.Region),
+		}
+	case entity.PolicyActionTag:
+		return &Recovery{
+			Kind:         RecoveryUntag,
+			Automated:    true,
+			InstanceID:   resource.ResourceID,
+			Instructions: fmt.Sprintf("Remove the tag %s from %s.", MarkedForDeletionTagKey, resource.ResourceID),
+		}
+	case entity.PolicyActionDelete, entity.PolicyActionRelease:
+		if id, _ := resource.Metadata[QuarantineMetadataSnapshot].(string); id != "" && snapshotTypes[resource.Type] {
+			return snapshotRecovery(resource, id)
+		}
+		return &Recovery{Kind: RecoveryManual, Instructions: manualInstructions(resource)}
+	}
+	return nil
+}
+
+// quarantineRecovery describes how to bring back a resource quarantined
+// until the given time
+func quarantineRecovery(resource *entity.Resource, until time.Time) *Recovery {
+	recovery := &Recovery{
+		Kind:       RecoveryUnquarantine,
+		Automated:  true,
+		InstanceID: resource.ResourceID,
+		UndoUntil:  &until,
+		Instructions: fmt.Sprintf("Restore %s before %s, when it is deleted: remove the tag %s and start it again if it was stopped.",
+			resource.ResourceID, until.UTC().Format(time.RFC3339), QuarantineTagKey),
+	}
+	recovery.SnapshotID, _ = resource.Metadata[QuarantineMetadataSnapshot].(string)
+	return recovery
+}
+
+// snapshotRecovery describes how to recreate a database or disk from the
+// snapshot taken before deleting it
+func snapshotRecovery(resource *entity.Resource, snapshotID string) *Recovery {
+	name := resource.Name
+	if name == "" {
+		name = resource.ResourceID
+	}
+	var instructions string
+	switch resource.Type {
+	case entity.ResourceTypeRDSInstance:
+		instructions = fmt.Sprintf("Restore the DB snapshot: aws rds restore-db-instance-from-db-snapshot --region %s --db-instance-identifier %s --db-snapshot-identifier %s",
+			resource.Region, name, snapshotID)
+	case entity.ResourceTypeCloudSQL:
+		instructions = fmt.Sprintf("Create the instance %s again and restore the backup %s into it: gcloud sql backups restore %s --restore-instance=%s",
+			name, snapshotID, snapshotID, name)
+	case entity.ResourceTypeAzureSQL:
+		instructions = fmt.Sprintf("Restore the database %s from the backup %s of its server, within the backup retention of the server.", name, snapshotID)
+	case entity.ResourceTypeEBSVolume:
+		instructions = fmt.Sprintf("Create a volume from the snapshot: aws ec2 create-volume --region %s --availability-zone <zone> --snapshot-id %s", resource.Region, snapshotID)
+	case entity.ResourceTypeAzureDisk:
+		instructions = fmt.Sprintf("Create a managed disk from the snapshot %s: az disk create --name %s --source %s", snapshotID, name, snapshotID)
+	case entity.ResourceTypeGCEDisk:
+		instructions = fmt.Sprintf("Create a disk from the snapshot: gcloud compute disks create %s --source-snapshot=%s", name, snapshotID)
+	default:
+		instructions = fmt.Sprintf("Recreate %s from the snapshot %s.", name, snapshotID)
+	}
+	return &Recovery{
+		Kind:         RecoveryRestoreSnapshot,
+		Automated:    true,
+		SnapshotID:   snapshotID,
+		Instructions: instructions,
+	}
+}
+
+// bucketRecovery describes what is left to recreate a deleted bucket, whose
+// objects are gone with it
+func bucketRecovery(resource *entity.Resource, versioning string) *Recovery {
+	instructions := fmt.Sprintf("Deleted buckets cannot be restored: create the bucket %s in %s again and copy its objects back from a replica or a backup.",
+		resource.Name, resource.Region)
+	if versioning == BucketVersioningEnabled || versioning == BucketVersioningSuspended {
+		instructions += fmt.Sprintf(" Its versioning was %s: set it again on the new bucket.", versioning)
+	}
+	return &Recovery{Kind: RecoveryManual, BucketVersioning: versioning, Instructions: instructions}
+}
+
+// manualInstructions tells what is left to recreate a resource deleted
+// without a snapshot
+func manualInstructions(resource *entity.Resource) string {
+	t := resource.Type
+	switch {
+	case t == entity.ResourceTypeElasticIP:
+		address, _ := resource.Metadata[IPMetadataAddress].(string)
+		return fmt.Sprintf("The address %s can be claimed back while no other account allocated it: aws ec2 allocate-address --region %s --address %s",
+			address, resource.Region, address)
+	case t.IsPublicIP():
+		return "Released addresses cannot be claimed back: reserve a new one and update the records and rules using the former."
+	case t.IsSnapshot():
+		return "Deleted snapshots cannot be restored."
+	case t.IsStoppable():
+		return fmt.Sprintf("Deleted %s cannot be restored: launch %s again from an image or a backup.", t, resource.Name)
+	case snapshotTypes[t]:
+		return "The data of the disk is lost unless it was backed up: create it again from a backup snapshot, if there is one."
+	case t.IsObjectStorage():
+		return bucketRecovery(resource, "").Instructions
+	}
+	return fmt.Sprintf("Create %s %s in %s again with its former configuration.", t, resource.Name, resource.Region)
+}
+
+// DeleteBucket deletes a bucket, recording its versioning on the result
+// when the cleaner can read it
+func DeleteBucket(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) (*CleanupResult, error) {
+	var versioning string
+	if reader, ok := cleaner.(BucketVersioningReader); ok {
+		if v, err := reader.BucketVersioning(ctx, resource); err == nil {
+			versioning = v
+		}
+	}
+	result, err := cleaner.Delete(ctx, resource)
+	if err != nil || !result.Success {
+		return result, err
+	}
+	result.Recovery = bucketRecovery(resource, versioning)
+	return result, nil
+}
+
+// RollbackResource undoes an action that succeeded on a resource, as told
+// by its recovery. Actions that cannot be undone automatically fail with a
+// *ManualRecoveryError holding the instructions.
+func RollbackResource(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource, recovery *Recovery) (*CleanupResult, error) {
+	if recovery.UndoUntil != nil && time.Now().After(*recovery.UndoUntil) {
+		return nil, fmt.Errorf("the action can no longer be undone since %s", recovery.UndoUntil.UTC().Format(time.RFC3339))
+	}
+	switch recovery.Kind {
+	case RecoveryStart:
+		return StartResource(ctx, cleaner, resource)
+	case RecoveryUnquarantine:
+		return RestoreResource(ctx, cleaner, resource)
+	case RecoveryUntag:
+		remover, ok := cleaner.(TagRemover)
+		if !ok {
+			return nil, fmt.Errorf("cannot remove tags from %s", resource.Type)
+		}
+		return remover.Untag(ctx, resource, []string{MarkedForDeletionTagKey})
+	case RecoveryRestoreSnapshot:
+		restorer, ok := cleaner.(SnapshotRestorer)
+		if !ok {
+			return nil, &ManualRecoveryError{Instructions: recovery.Instructions}
+		}
+		return restorer.RestoreSnapshot(ctx, resource, recovery.SnapshotID)
+	}
+	return nil, &ManualRecoveryError{Instructions: recovery.Instructions}
+}
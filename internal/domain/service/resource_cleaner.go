@@ -12,8 +12,14 @@ type CleanupResult struct {
 	Success       bool
 	Action        entity.PolicyAction
 	ErrorMessage  string
+	ChangeURL     string // pull request removing the resource from its IaC configuration
+	TicketURL     string // issue or change request opened for the resource
+	Owner         string // owner notified of the resource
 	CostSaved     float64
 	CarbonSaved   float64
+	BackupID      string    // snapshot taken before deleting, in backup-then-delete mode
+	BackupCost    float64   // monthly cost of that snapshot, deducted from CostSaved
+	Recovery      *Recovery // how to undo the action, for those that succeeded
 }
 
 // ResourceCleaner defines the interface for cleaning up cloud resources
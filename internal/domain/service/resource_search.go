@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxSearchTokens bounds the terms and tag selectors of a search, each of
+// which adds a condition to the query
+const maxSearchTokens = 10
+
+// TagOp is the comparison of a tag selector
+type TagOp string
+
+const (
+	TagOpEquals    TagOp = "="  // tag:env=prod
+	TagOpNotEquals TagOp = "!=" // tag:owner!=platform, also matches resources without the tag
+	TagOpExists    TagOp = ""   // tag:env
+)
+
+// TagSelector selects resources by a tag
+type TagSelector struct {
+	Key   string
+	Op    TagOp
+	Value string
+}
+
+// ResourceSearch is a parsed resource search. Resources match when they
+// match every term and every tag selector.
+type ResourceSearch struct {
+	Terms []string // matched case-insensitively against names, cloud IDs and tag values
+	Tags  []TagSelector
+}
+
+// IsEmpty reports whether the search matches every resource
+func (s ResourceSearch) IsEmpty() bool {
+	return len(s.Terms) == 0 && len(s.Tags) == 0
+}
+
+// ParseResourceSearch parses a search such as
+// `web tag:env=prod tag:owner!=platform`. Whitespace separates tokens;
+// double quotes keep spaces, e.g. `"web server"` or `tag:team="data eng"`.
+func ParseResourceSearch(q string) (ResourceSearch, error) {
+	tokens, err := splitSearch(q)
+	if err != nil {
+		return ResourceSearch{}, err
+	}
+	if len(tokens) > maxSearchTokens {
+		return ResourceSearch{}, fmt.Errorf("search has %d terms, at most %d are allowed", len(tokens), maxSearchTokens)
+	}
+
+	var search ResourceSearch
+	for _, token := range tokens {
+		if len(token) < 4 || !strings.EqualFold(token[:4], "tag:") {
+			search.Terms = append(search.Terms, token)
+			continue
+		}
+
+		selector := TagSelector{Key: token[4:], Op: TagOpExists}
+		if key, value, ok := strings.Cut(selector.Key, "!="); ok {
+			selector = TagSelector{Key: key, Op: TagOpNotEquals, Value: value}
+		} else if key, value, ok := strings.Cut(selector.Key, "="); ok {
+			selector = TagSelector{Key: key, Op: TagOpEquals, Value: value}
+		}
+		if selector.Key == "" {
+			return ResourceSearch{}, fmt.Errorf("invalid tag selector %q: the tag key is missing", token)
+		}
+		search.Tags = append(search.Tags, selector)
+	}
+	return search, nil
+}
+
+// splitSearch splits a search on whitespace outside double quotes and
+// removes the quotes
+func splitSearch(q string) ([]string, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in search %q", q)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// ErrScanAlreadyQueued is returned when a task for a scan with the same
+// fingerprint is already queued
+var ErrScanAlreadyQueued = errors.New("an identical scan is already queued")
+
+// ScanQueue hands scans over to the workers
+type ScanQueue interface {
+	// Enqueue queues a scan. plan is that of the organization, whose share
+	// of the workers it uses. Scheduled scans yield to those users asked
+	// for. With dedupe, it fails with ErrScanAlreadyQueued when a task for
+	// an identical scan is queued.
+	Enqueue(ctx context.Context, scan *entity.Scan, plan string, scheduled, dedupe bool) error
+}
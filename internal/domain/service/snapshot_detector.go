@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys scanners set on snapshots for the detector. The size is
+// VolumeMetadataSizeGB, the size of the source volume.
+const (
+	SnapshotMetadataSourceVolume = "source_volume_id"     // volume or disk the snapshot was taken from
+	SnapshotMetadataSourceExists = "source_volume_exists" // false when the source volume is gone
+	SnapshotMetadataImageIDs     = "image_ids"            // images (AMIs) backed by the snapshot
+	SnapshotMetadataImageInUse   = "image_in_use"         // whether an instance or launch template uses one of the images
+	SnapshotMetadataManagedBy    = "managed_by"           // lifecycle tool owning the snapshot, e.g. "aws-backup", "dlm"
+	SnapshotMetadataUniqueGB     = "unique_gb"            // data only this snapshot references, when the provider reports it
+	SnapshotMetadataTier         = "storage_tier"         // "standard" or "archive"
+	SnapshotMetadataNewer        = "newer_snapshots"      // set by AnnotateSnapshotChains
+	SnapshotMetadataChainOldest  = "chain_oldest"         // set by AnnotateSnapshotChains
+	SnapshotMetadataIdleReason   = "idle_reason"          // set by the detector on unused snapshots
+	SnapshotMetadataReclaimGB    = "reclaimable_gb"       // set by the detector, storage freed by deleting the snapshot
+)
+
+// Reasons a snapshot is reported as unused
+const (
+	IdleReasonSourceDeleted = "source_deleted"
+	IdleReasonImageUnused   = "image_unused"
+	IdleReasonRedundant     = "redundant"
+)
+
+// SnapshotRetention is how many snapshots of a volume are worth keeping: the
+// Keep most recent ones, and every snapshot younger than Days
+type SnapshotRetention struct {
+	Days int
+	Keep int
+}
+
+// DefaultSnapshotRetention keeps the last snapshot of a volume and those of
+// the last 30 days
+var DefaultSnapshotRetention = SnapshotRetention{Days: 30, Keep: 1}
+
+// DefaultSnapshotChangeRate is the share of a volume assumed to change
+// between two snapshots of a chain, when the provider does not report the
+// data unique to a snapshot
+const DefaultSnapshotChangeRate = 0.1
+
+// snapshotGBMonthPrices are the list prices of snapshot storage by type and
+// tier, in USD per GB-month
+var snapshotGBMonthPrices = map[entity.ResourceType]map[string]float64{
+	entity.ResourceTypeEBSSnapshot:   {"standard": 0.05, "archive": 0.0125},
+	entity.ResourceTypeAzureSnapshot: {"standard": 0.05},
+}
+
+// AnnotateSnapshotChains records the position of snapshots in the chain of
+// their source volume. Snapshots are incremental: the oldest holds every
+// block of the volume, later ones only the blocks changed since. Scanners
+// call it from their scan with every snapshot of a region, so that chains
+// are complete whatever batches DetectIdleSnapshots is then called with.
+func AnnotateSnapshotChains(resources []*entity.Resource) {
+	chains := map[string][]*entity.Resource{}
+	for _, r := range resources {
+		if !r.Type.IsSnapshot() {
+			continue
+		}
+		if volume, _ := r.Metadata[SnapshotMetadataSourceVolume].(string); volume != "" {
+			chains[volume] = append(chains[volume], r)
+		}
+	}
+	for _, chain := range chains {
+		sort.SliceStable(chain, func(i, j int) bool { return chain[i].CreatedAt.Before(chain[j].CreatedAt) })
+		for i, r := range chain {
+			r.Metadata[SnapshotMetadataNewer] = len(chain) - 1 - i
+			r.Metadata[SnapshotMetadataChainOldest] = i == 0
+		}
+	}
+}
+
+// DetectIdleSnapshots marks snapshots unused when their images are no longer
+// used, their source volume is deleted, they are backups taken before a
+// deletion whose retention has ended, or they are redundant: older than
+// the retention with at least retention.Keep newer snapshots of the same
+// volume. Snapshots backing images in use are never reported, nor are those
+// of a lifecycle tool for redundancy, since the tool applies its own
+// retention. Unused snapshots get the storage deleting them frees. Scanners
+// call it from DetectUnused once the metadata above is filled in; other
+// resource types are left untouched.
+func DetectIdleSnapshots(resources []*entity.Resource, retention SnapshotRetention, now time.Time) {
+	for _, r := range resources {
+		if !r.Type.IsSnapshot() || r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+		reason := snapshotIdleReason(r, retention, now)
+		if reason == "" {
+			continue
+		}
+		r.MarkAsUnused()
+		r.Metadata[SnapshotMetadataIdleReason] = reason
+		r.Metadata[SnapshotMetadataReclaimGB] = reclaimableGB(r, reason)
+	}
+}
+
+func snapshotIdleReason(r *entity.Resource, retention SnapshotRetention, now time.Time) string {
+	if inUse, _ := r.Metadata[SnapshotMetadataImageInUse].(bool); inUse {
+		return ""
+	}
+	if len(SnapshotImageIDs(r)) > 0 {
+		return IdleReasonImageUnused
+	}
+	// Backups taken before deleting their source are kept until the end of
+	// their retention
+	if _, ok := r.Tags[BackupTagDeleteAfter]; ok {
+		if backupExpired(r, now) {
+			return IdleReasonBackupExpired
+		}
+		return ""
+	}
+	if exists, ok := r.Metadata[SnapshotMetadataSourceExists].(bool); ok && !exists {
+		return IdleReasonSourceDeleted
+	}
+	if managedBy, _ := r.Metadata[SnapshotMetadataManagedBy].(string); managedBy != "" {
+		return ""
+	}
+	newer, ok := metadataInt(r.Metadata[SnapshotMetadataNewer])
+	if ok && newer >= int64(retention.Keep) && now.Sub(r.CreatedAt) > time.Duration(retention.Days)*24*time.Hour {
+		return IdleReasonRedundant
+	}
+	return ""
+}
+
+// SnapshotStoredGB returns the storage a snapshot is billed for: the data
+// unique to it when the provider reports it, otherwise the whole volume for
+// the oldest snapshot of a chain and DefaultSnapshotChangeRate of it for the
+// others
+func SnapshotStoredGB(r *entity.Resource) float64 {
+	if unique, ok := metadataFloat(r.Metadata[SnapshotMetadataUniqueGB]); ok {
+		return unique
+	}
+	size, _ := metadataFloat(r.Metadata[VolumeMetadataSizeGB])
+	if oldest, ok := r.Metadata[SnapshotMetadataChainOldest].(bool); ok && !oldest {
+		return size * DefaultSnapshotChangeRate
+	}
+	return size
+}
+
+// reclaimableGB is the storage freed by deleting a snapshot. The blocks of
+// the oldest snapshot of a chain move to the next one when it is deleted
+// alone, so a redundant oldest snapshot only frees the blocks changed since.
+func reclaimableGB(r *entity.Resource, reason string) float64 {
+	if _, ok := metadataFloat(r.Metadata[SnapshotMetadataUniqueGB]); ok || reason != IdleReasonRedundant {
+		return SnapshotStoredGB(r)
+	}
+	size, _ := metadataFloat(r.Metadata[VolumeMetadataSizeGB])
+	return size * DefaultSnapshotChangeRate
+}
+
+// SnapshotCost returns the monthly cost of a snapshot: the storage deleting
+// it frees for unused snapshots, so that savings are not overstated, and its
+// stored data otherwise. ok is false for other resource types.
+func SnapshotCost(r *entity.Resource) (cost entity.Cost, ok bool) {
+	prices, ok := snapshotGBMonthPrices[r.Type]
+	if !ok {
+		return entity.Cost{}, false
+	}
+	tier, _ := r.Metadata[SnapshotMetadataTier].(string)
+	price, ok := prices[tier]
+	if !ok {
+		price = prices["standard"]
+	}
+	gb, ok := metadataFloat(r.Metadata[SnapshotMetadataReclaimGB])
+	if !ok {
+		gb = SnapshotStoredGB(r)
+	}
+	return entity.MonthlyUSDCost(gb * price), true
+}
+
+// SnapshotImageIDs returns the images backed by a snapshot
+func SnapshotImageIDs(r *entity.Resource) []string {
+	return metadataStrings(r.Metadata[SnapshotMetadataImageIDs])
+}
+
+// metadataStrings reads a list of strings stored in metadata, which may have
+// been decoded from JSON as a []any
+func metadataStrings(v any) []string {
+	switch values := v.(type) {
+	case []string:
+		return values
+	case []any:
+		out := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// ImageDeregisterer is implemented by cleaners of snapshots. It deregisters
+// an image backed by a snapshot, which providers require before the
+// snapshot can be deleted.
+type ImageDeregisterer interface {
+	DeregisterImage(ctx context.Context, snapshot *entity.Resource, imageID string) error
+}
+
+// CheckSnapshotSafeDelete verifies that a snapshot backs no image in use,
+// and that the cleaner can deregister the images it backs. Cleaners that
+// cannot are refused, as the provider would reject the deletion.
+func CheckSnapshotSafeDelete(cleaner ResourceCleaner, resource *entity.Resource) error {
+	if !resource.Type.IsSnapshot() {
+		return nil
+	}
+	images := SnapshotImageIDs(resource)
+	if inUse, _ := resource.Metadata[SnapshotMetadataImageInUse].(bool); inUse {
+		return fmt.Errorf("backs images in use %v", images)
+	}
+	if _, ok := cleaner.(ImageDeregisterer); len(images) > 0 && !ok {
+		return fmt.Errorf("cannot deregister the images of %s", resource.Type)
+	}
+	return nil
+}
+
+// DeleteSnapshot deletes a snapshot after deregistering the images it
+// backs. A failed deregistration leaves the snapshot in place.
+func DeleteSnapshot(ctx context.Context, cleaner ResourceCleaner, resource *entity.Resource) (*CleanupResult, error) {
+	if err := CheckSnapshotSafeDelete(cleaner, resource); err != nil {
+		return nil, err
+	}
+	for _, image := range SnapshotImageIDs(resource) {
+		if err := cleaner.(ImageDeregisterer).DeregisterImage(ctx, resource, image); err != nil {
+			return nil, fmt.Errorf("failed to deregister image %s: %w", image, err)
+		}
+	}
+	return cleaner.Delete(ctx, resource)
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys scanners set on buckets and blob containers for the detector
+const (
+	StorageMetadataLastReadAt     = "last_read_at"          // RFC 3339 time of the last read, from access metrics or storage analytics
+	StorageMetadataClassBytes     = "storage_class_bytes"   // bytes stored per storage class, e.g. {"STANDARD": 1073741824}
+	StorageMetadataLifecycleRules = "lifecycle_rules"       // number of lifecycle rules configured on the bucket
+	StorageMetadataWebsite        = "website_endpoint"      // hostname of the static website served from the bucket, when enabled
+	StorageMetadataIdleReason     = "idle_reason"           // set by the detector on unused buckets
+	StorageMetadataSuggestions    = "lifecycle_suggestions" // set by the detector, see LifecycleSuggestion
+)
+
+// IdleReasonNoReads is the reason a bucket is reported as unused when it had
+// no read over the idle window
+const IdleReasonNoReads = "no_reads"
+
+// DefaultBucketIdleDays is the number of days without reads after which a
+// bucket is considered idle
+const DefaultBucketIdleDays = 90
+
+// Days without reads after which data is worth moving to a colder class
+const (
+	infrequentAccessAfterDays = 30
+	archiveAfterDays          = 90
+)
+
+// storageTier is a storage class and its list price, in USD per GB-month
+type storageTier struct {
+	class string
+	price float64
+}
+
+// storageTiers are the storage classes of each provider, from the hottest to
+// the coldest, with their list prices in the cheapest region. The first tier
+// is also the price of classes missing from the list.
+var storageTiers = map[entity.ResourceType][]storageTier{
+	entity.ResourceTypeS3Bucket: {
+		{"STANDARD", 0.023},
+		{"INTELLIGENT_TIERING", 0.023},
+		{"STANDARD_IA", 0.0125},
+		{"ONEZONE_IA", 0.01},
+		{"GLACIER_IR", 0.004},
+		{"GLACIER", 0.0036},
+		{"DEEP_ARCHIVE", 0.00099},
+	},
+	entity.ResourceTypeAzureBlobContainer: {
+		{"Hot", 0.0184},
+		{"Cool", 0.01},
+		{"Cold", 0.0036},
+		{"Archive", 0.00099},
+	},
+	entity.ResourceTypeGCSBucket: {
+		{"STANDARD", 0.02},
+		{"NEARLINE", 0.01},
+		{"COLDLINE", 0.004},
+		{"ARCHIVE", 0.0012},
+	},
+}
+
+// Classes suggested for data that is rarely read and for data that is no
+// longer read
+var (
+	infrequentAccessClasses = map[entity.ResourceType]string{
+		entity.ResourceTypeS3Bucket:           "STANDARD_IA",
+		entity.ResourceTypeAzureBlobContainer: "Cool",
+		entity.ResourceTypeGCSBucket:          "NEARLINE",
+	}
+	archiveClasses = map[entity.ResourceType]string{
+		entity.ResourceTypeS3Bucket:           "GLACIER",
+		entity.ResourceTypeAzureBlobContainer: "Archive",
+		entity.ResourceTypeGCSBucket:          "ARCHIVE",
+	}
+)
+
+const bytesPerGB = 1 << 30
+
+// LifecycleSuggestion is a lifecycle rule suggested for a bucket without one.
+// Suggestions of a bucket are alternatives: their savings do not add up.
+type LifecycleSuggestion struct {
+	StorageClass   string  `json:"storage_class"` // target class; empty to expire objects
+	AfterDays      int     `json:"after_days"`
+	MonthlySavings float64 `json:"monthly_savings"` // USD
+}
+
+// ObjectStorageCost returns the monthly cost of a bucket from the bytes it
+// stores per storage class. ok is false for other resource types.
+func ObjectStorageCost(resourceType entity.ResourceType, classBytes map[string]int64) (cost entity.Cost, ok bool) {
+	tiers, ok := storageTiers[resourceType]
+	if !ok {
+		return entity.Cost{}, false
+	}
+	var amount float64
+	for class, bytes := range classBytes {
+		amount += float64(bytes) / bytesPerGB * tierPrice(tiers, class)
+	}
+	return entity.MonthlyUSDCost(amount), true
+}
+
+// DetectIdleBuckets marks buckets unused when they had no read over the last
+// idleDays, and suggests lifecycle rules for buckets without any. Buckets
+// with no recorded read are judged on their age. Scanners call it from
+// DetectUnused once the metadata above is filled in; other resource types
+// are left untouched.
+func DetectIdleBuckets(resources []*entity.Resource, idleDays int, now time.Time) {
+	for _, r := range resources {
+		if !r.Type.IsObjectStorage() || r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+
+		lastRead := r.CreatedAt
+		if s, ok := r.Metadata[StorageMetadataLastReadAt].(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				lastRead = t
+			}
+		}
+		unreadDays := int(now.Sub(lastRead).Hours() / 24)
+
+		if unreadDays >= idleDays {
+			r.MarkAsUnused()
+			r.Metadata[StorageMetadataIdleReason] = IdleReasonNoReads
+		}
+		if rules, _ := metadataInt(r.Metadata[StorageMetadataLifecycleRules]); rules == 0 {
+			if suggestions := suggestLifecycle(r.Type, StorageClassBytes(r.Metadata), unreadDays, idleDays); len(suggestions) > 0 {
+				r.Metadata[StorageMetadataSuggestions] = suggestions
+			}
+		}
+	}
+}
+
+// suggestLifecycle suggests moving hot data to a colder class once it is no
+// longer read, and expiring idle buckets
+func suggestLifecycle(resourceType entity.ResourceType, classBytes map[string]int64, unreadDays, idleDays int) []LifecycleSuggestion {
+	tiers := storageTiers[resourceType]
+	var suggestions []LifecycleSuggestion
+	add := func(class string, afterDays int) {
+		target := tierPrice(tiers, class)
+		var savings float64
+		for c, bytes := range classBytes {
+			if price := tierPrice(tiers, c); price > target {
+				savings += float64(bytes) / bytesPerGB * (price - target)
+			}
+		}
+		if savings > 0 {
+			suggestions = append(suggestions, LifecycleSuggestion{StorageClass: class, AfterDays: afterDays, MonthlySavings: savings})
+		}
+	}
+
+	switch {
+	case unreadDays >= idleDays:
+		add(archiveClasses[resourceType], archiveAfterDays)
+		var total float64
+		for c, bytes := range classBytes {
+			total += float64(bytes) / bytesPerGB * tierPrice(tiers, c)
+		}
+		if total > 0 {
+			suggestions = append(suggestions, LifecycleSuggestion{AfterDays: idleDays, MonthlySavings: total})
+		}
+	case unreadDays >= archiveAfterDays:
+		add(archiveClasses[resourceType], archiveAfterDays)
+	case unreadDays >= infrequentAccessAfterDays:
+		add(infrequentAccessClasses[resourceType], infrequentAccessAfterDays)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].AfterDays < suggestions[j].AfterDays })
+	return suggestions
+}
+
+func tierPrice(tiers []storageTier, class string) float64 {
+	for _, t := range tiers {
+		if t.class == class {
+			return t.price
+		}
+	}
+	if len(tiers) == 0 {
+		return 0
+	}
+	return tiers[0].price
+}
+
+// StorageClassBytes reads the storage class breakdown of a bucket, which
+// may have been decoded from JSON with float64 values
+func StorageClassBytes(metadata map[string]any) map[string]int64 {
+	out := make(map[string]int64)
+	switch m := metadata[StorageMetadataClassBytes].(type) {
+	case map[string]int64:
+		for k, v := range m {
+			out[k] = v
+		}
+	case map[string]any:
+		for k, v := range m {
+			if n, ok := metadataInt(v); ok {
+				out[k] = n
+			}
+		}
+	}
+	return out
+}
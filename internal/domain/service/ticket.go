@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Issue trackers tickets are opened in
+const (
+	TicketSystemJira       = "jira"       // Jira issue
+	TicketSystemServiceNow = "servicenow" // ServiceNow change request
+)
+
+// Ticket is an issue or change request opened for a resource
+type Ticket struct {
+	System string // jira or servicenow
+	Key    string // e.g. OPS-123 or CHG0030001
+	URL    string
+}
+
+// TicketTracker opens tickets for the resources matched by policies with
+// the ticket action. Tickets are updated and closed by the tracker once
+// their resource is remediated.
+type TicketTracker interface {
+	// OpenTicket opens a ticket for the resource in the tracker of its
+	// organization, or returns the one still open for it. taskID is the
+	// cleanup task it is opened for.
+	OpenTicket(ctx context.Context, resource *entity.Resource, taskID string) (*Ticket, error)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+// Metadata keys scanners set on load balancers, network interfaces and NAT
+// gateways for the detector. The traffic of NAT gateways is
+// NetworkMetadataBytes, and the detector sets NetworkMetadataIdleReason on
+// all of them.
+const (
+	LoadBalancerMetadataHealthyTargets = "healthy_targets"   // targets passing health checks, across target groups or backend pools
+	LoadBalancerMetadataRequests       = "request_count"     // requests, or new flows for network load balancers, over the lookback window
+	LoadBalancerMetadataDNSName        = "dns_name"          // hostname of the load balancer, which DNS records alias
+	InterfaceMetadataStatus            = "interface_status"  // "available" when detached, "in-use" otherwise
+	InterfaceMetadataRequesterManaged  = "requester_managed" // created and owned by a service, e.g. ELB, Lambda or EKS
+	InterfaceMetadataPublicIP          = "public_ip"         // public IPv4 address associated with the interface
+)
+
+// Reasons a load balancer or network interface is reported as unused. NAT
+// gateways are reported with IdleReasonNoTraffic.
+const (
+	IdleReasonNoHealthyTargets = "no_healthy_targets"
+	IdleReasonNoRequests       = "no_requests"
+	IdleReasonDetached         = "detached"
+)
+
+// TrafficThresholds are the traffic over the lookback window under which
+// load balancers and NAT gateways are considered idle
+type TrafficThresholds struct {
+	Requests int64
+	Bytes    int64
+}
+
+// DefaultTrafficThresholds tolerate the health checks and update traffic
+// reaching otherwise unused load balancers and NAT gateways
+var DefaultTrafficThresholds = TrafficThresholds{Requests: 100, Bytes: 1 << 30}
+
+// trafficHourlyCosts are the list prices of load balancers and NAT gateways,
+// per hour and excluding the traffic they process, which idle ones barely
+// have. Cloud NAT is billed by VM up to 32, the price used here.
+var trafficHourlyCosts = map[entity.ResourceType]float64{
+	entity.ResourceTypeLoadBalancer:      0.0225,
+	entity.ResourceTypeAzureLoadBalancer: 0.025,
+	entity.ResourceTypeGCPLoadBalancer:   0.025,
+	entity.ResourceTypeNATGateway:        0.045,
+	entity.ResourceTypeAzureNATGateway:   0.045,
+	entity.ResourceTypeCloudNAT:          0.044,
+}
+
+// interfacePublicIPHourlyCost is the AWS price of the public IPv4 address of
+// a network interface. Interfaces are free otherwise, and Azure bills public
+// addresses as resources of their own.
+const interfacePublicIPHourlyCost = 0.005
+
+// TrafficResourceCost returns the hourly cost of a load balancer, network
+// interface or NAT gateway. ok is false for other resource types.
+func TrafficResourceCost(r *entity.Resource) (cost entity.Cost, ok bool) {
+	price, ok := trafficHourlyCosts[r.Type]
+	switch {
+	case r.Type == entity.ResourceTypeNetworkInterface:
+		price, ok = 0, true
+		if ip, _ := r.Metadata[InterfaceMetadataPublicIP].(string); ip != "" {
+			price = interfacePublicIPHourlyCost
+		}
+	case r.Type.IsNetworkInterface():
+		price, ok = 0, true
+	case !ok:
+		return entity.Cost{}, false
+	}
+	return entity.Cost{Amount: price, Currency: entity.CurrencyUSD, Period: entity.BillingPeriodHourly}, true
+}
+
+// DetectIdleTrafficResources marks unused the load balancers without healthy
+// targets or serving at most thresholds.Requests, the detached network
+// interfaces, and the NAT gateways that processed at most thresholds.Bytes
+// over the lookback window. Interfaces owned by a service are never
+// reported, since the service deletes them itself. Scanners call it from
+// DetectUnused once the metadata above is filled in; other resource types
+// are left untouched.
+func DetectIdleTrafficResources(resources []*entity.Resource, thresholds TrafficThresholds) {
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		var reason string
+		switch {
+		case r.Type.IsLoadBalancer():
+			reason = loadBalancerIdleReason(r, thresholds.Requests)
+		case r.Type.IsNetworkInterface():
+			reason = interfaceIdleReason(r)
+		case r.Type.IsNATGateway():
+			if bytes, ok := metadataInt(r.Metadata[NetworkMetadataBytes]); ok && bytes <= thresholds.Bytes {
+				reason = IdleReasonNoTraffic
+			}
+		}
+		if reason == "" {
+			continue
+		}
+		r.MarkAsUnused()
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]any)
+		}
+		r.Metadata[NetworkMetadataIdleReason] = reason
+	}
+}
+
+func loadBalancerIdleReason(r *entity.Resource, idleRequests int64) string {
+	if healthy, ok := metadataInt(r.Metadata[LoadBalancerMetadataHealthyTargets]); ok && healthy == 0 {
+		return IdleReasonNoHealthyTargets
+	}
+	if requests, ok := metadataInt(r.Metadata[LoadBalancerMetadataRequests]); ok && requests <= idleRequests {
+		return IdleReasonNoRequests
+	}
+	return ""
+}
+
+func interfaceIdleReason(r *entity.Resource) string {
+	if managed, _ := r.Metadata[InterfaceMetadataRequesterManaged].(bool); managed {
+		return ""
+	}
+	if status, _ := r.Metadata[InterfaceMetadataStatus].(string); status == "available" {
+		return IdleReasonDetached
+	}
+	return ""
+}
+
+// CheckInterfaceSafeDelete verifies that a network interface is detached and
+// not owned by a service before it is deleted. Providers refuse to delete
+// attached interfaces, and deleting those of a service breaks it.
+func CheckInterfaceSafeDelete(resource *entity.Resource) error {
+	if !resource.Type.IsNetworkInterface() {
+		return nil
+	}
+	if managed, _ := resource.Metadata[InterfaceMetadataRequesterManaged].(bool); managed {
+		return fmt.Errorf("owned by a service")
+	}
+	if status, _ := resource.Metadata[InterfaceMetadataStatus].(string); status != "available" {
+		return fmt.Errorf("not detached (status %q)", status)
+	}
+	return nil
+}
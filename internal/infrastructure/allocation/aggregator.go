@@ -0,0 +1,142 @@
+package allocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Aggregator materializes the cost allocation of organizations by their
+// cost-allocation tags
+type Aggregator struct {
+	db *gorm.DB
+}
+
+// NewAggregator creates an Aggregator
+func NewAggregator(db *gorm.DB) *Aggregator {
+	return &Aggregator{db: db}
+}
+
+var _ service.CostAllocator = (*Aggregator)(nil)
+
+// MonthOf returns the first day of the month of t, in UTC
+func MonthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Refresh implements service.CostAllocator. It replaces the previous
+// refresh of the month; earlier months are kept as they were last computed.
+func (a *Aggregator) Refresh(ctx context.Context, org *entity.Organization, now time.Time) error {
+	return a.refresh(ctx, org.ID, org.Settings.AllocationTagKeys, now)
+}
+
+// refresh recomputes the allocation of the current month of an organization
+// by the given tag keys
+func (a *Aggregator) refresh(ctx context.Context, orgID uuid.UUID, keys []string, now time.Time) error {
+	month := MonthOf(now)
+	var rows []model.CostAllocation
+	for _, key := range keys {
+		var values []struct {
+			Value string
+			Count int64
+			Cost  float64
+			Waste float64
+		}
+		err := a.db.WithContext(ctx).Model(&model.Resource{}).
+			Select(`COALESCE(tags->>?, '') AS value,
+				COUNT(*) AS count,
+				COALESCE(SUM(monthly_cost), 0) AS cost,
+				COALESCE(SUM(monthly_cost) FILTER (WHERE status = ?), 0) AS waste`, key, string(entity.ResourceStatusUnused)).
+			Where("organization_id = ? AND status NOT IN ?", orgID, entity.UntrackedResourceStatuses).
+			Group("value").
+			Scan(&values).Error
+		if err != nil {
+			return fmt.Errorf("failed to aggregate %s costs of organization %s: %w", key, orgID, err)
+		}
+		for _, v := range values {
+			rows = append(rows, model.CostAllocation{
+				OrganizationID: orgID,
+				Month:          month,
+				TagKey:         key,
+				TagValue:       v.Value,
+				ResourceCount:  v.Count,
+				MonthlyCost:    v.Cost,
+				Waste:          v.Waste,
+				ComputedAt:     now,
+			})
+		}
+	}
+
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ? AND month = ?", orgID, month).Delete(&model.CostAllocation{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Omit("Organization").CreateInBatches(rows, 500).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store cost allocation of organization %s: %w", orgID, err)
+	}
+	return nil
+}
+
+// RefreshAll refreshes the allocation of every active organization with
+// cost-allocation tags. It returns the number of organizations refreshed.
+func (a *Aggregator) RefreshAll(ctx context.Context, now time.Time) (int, error) {
+	var orgs []model.Organization
+	err := a.db.WithContext(ctx).
+		Where("is_active = ? AND jsonb_array_length(COALESCE(allocation_tag_keys, '[]')) > 0", true).
+		Find(&orgs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load organizations: %w", err)
+	}
+
+	var (
+		refreshed int
+		errs      []error
+	)
+	for i := range orgs {
+		if err := a.refresh(ctx, orgs[i].ID, orgs[i].AllocationTagKeys, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, errors.Join(errs...)
+}
+
+// Month implements service.CostAllocator
+func (a *Aggregator) Month(ctx context.Context, orgID uuid.UUID, month time.Time) ([]entity.CostAllocation, error) {
+	var rows []model.CostAllocation
+	err := a.db.WithContext(ctx).
+		Where("organization_id = ? AND month = ?", orgID, MonthOf(month)).
+		Order("tag_key, monthly_cost DESC, tag_value").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make([]entity.CostAllocation, len(rows))
+	for i, row := range rows {
+		allocations[i] = entity.CostAllocation{
+			Month:         row.Month,
+			TagKey:        row.TagKey,
+			TagValue:      row.TagValue,
+			ResourceCount: row.ResourceCount,
+			MonthlyCost:   row.MonthlyCost,
+			Waste:         row.Waste,
+			ComputedAt:    row.ComputedAt,
+		}
+	}
+	return allocations, nil
+}
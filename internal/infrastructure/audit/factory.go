@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/hibiken/asynq"
+)
+
+// CleanerFactory scopes every call made on the cleaners created by the
+// wrapped factory to the resource being cleaned, so the provider calls they
+// record are linked to it and to the cleanup task
+type CleanerFactory struct {
+	next     service.ResourceCleanerFactory
+	recorder *Recorder
+}
+
+// NewCleanerFactory wraps a ResourceCleanerFactory with the recorder
+func NewCleanerFactory(next service.ResourceCleanerFactory, recorder *Recorder) *CleanerFactory {
+	return &CleanerFactory{next: next, recorder: recorder}
+}
+
+// Create returns a recording cleaner
+func (f *CleanerFactory) Create(provider entity.CloudProvider, credentials []byte) (service.ResourceCleaner, error) {
+	cleaner, err := f.next.Create(provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &recordedCleaner{ResourceCleaner: cleaner, recorder: f.recorder}, nil
+}
+
+type recordedCleaner struct {
+	service.ResourceCleaner
+	recorder *Recorder
+}
+
+func (c *recordedCleaner) scope(ctx context.Context, resource *entity.Resource) context.Context {
+	id := resource.ID
+	taskID, _ := asynq.GetTaskID(ctx)
+	return WithScope(ctx, c.recorder, Scope{
+		OrganizationID: resource.OrganizationID,
+		ResourceID:     &id,
+		TaskID:         taskID,
+		Provider:       string(resource.Provider),
+	})
+}
+
+func (c *recordedCleaner) Delete(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	return c.ResourceCleaner.Delete(c.scope(ctx, resource), resource)
+}
+
+func (c *recordedCleaner) Stop(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	return c.ResourceCleaner.Stop(c.scope(ctx, resource), resource)
+}
+
+func (c *recordedCleaner) Tag(ctx context.Context, resource *entity.Resource, tags map[string]string) (*service.CleanupResult, error) {
+	return c.ResourceCleaner.Tag(c.scope(ctx, resource), resource, tags)
+}
+
+// RouteTablesReferencing forwards to the wrapped cleaner so network
+// attachments can still be checked before deletion
+func (c *recordedCleaner) RouteTablesReferencing(ctx context.Context, resource *entity.Resource) ([]string, error) {
+	inspector, ok := c.ResourceCleaner.(service.RouteTableInspector)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot inspect route tables", c.Provider())
+	}
+	return inspector.RouteTablesReferencing(ctx, resource)
+}
+
+// DeleteWithFinalSnapshot forwards to the wrapped cleaner so managed
+// databases can still be deleted with a final snapshot
+func (c *recordedCleaner) DeleteWithFinalSnapshot(ctx context.Context, resource *entity.Resource, snapshotID string) (*service.CleanupResult, error) {
+	snapshotter, ok := c.ResourceCleaner.(service.DatabaseSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot take final snapshots", c.Provider())
+	}
+	return snapshotter.DeleteWithFinalSnapshot(c.scope(ctx, resource), resource, snapshotID)
+}
+
+// Backup forwards to the wrapped cleaner so volumes, disks and databases
+// can still be backed up before deletion
+func (c *recordedCleaner) Backup(ctx context.Context, resource *entity.Resource, snapshotID string, tags map[string]string) (*service.CleanupResult, error) {
+	taker, ok := c.ResourceCleaner.(service.BackupTaker)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot take backups", c.Provider())
+	}
+	return taker.Backup(c.scope(ctx, resource), resource, snapshotID, tags)
+}
+
+// BackupState forwards to the wrapped cleaner so the backups taken before
+// deletion can still be waited for
+func (c *recordedCleaner) BackupState(ctx context.Context, resource *entity.Resource, snapshotID string) (service.BackupState, error) {
+	taker, ok := c.ResourceCleaner.(service.BackupTaker)
+	if !ok {
+		return "", fmt.Errorf("cleaner for %s cannot take backups", c.Provider())
+	}
+	return taker.BackupState(c.scope(ctx, resource), resource, snapshotID)
+}
+
+// DeregisterImage forwards to the wrapped cleaner so snapshots backing
+// images can still be deleted
+func (c *recordedCleaner) DeregisterImage(ctx context.Context, snapshot *entity.Resource, imageID string) error {
+	deregisterer, ok := c.ResourceCleaner.(service.ImageDeregisterer)
+	if !ok {
+		return fmt.Errorf("cleaner for %s cannot deregister images", c.Provider())
+	}
+	return deregisterer.DeregisterImage(c.scope(ctx, snapshot), snapshot, imageID)
+}
+
+// Release forwards to the wrapped cleaner so public IP addresses can still
+// be released
+func (c *recordedCleaner) Release(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	releaser, ok := c.ResourceCleaner.(service.IPReleaser)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot release IP addresses", c.Provider())
+	}
+	return releaser.Release(c.scope(ctx, resource), resource)
+}
+
+// DNSRecordsReferencing forwards to the wrapped cleaner so the resources
+// DNS records may point at can still be checked before deletion
+func (c *recordedCleaner) DNSRecordsReferencing(ctx context.Context, resource *entity.Resource) ([]string, error) {
+	inspector, ok := c.ResourceCleaner.(service.DNSInspector)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s: %w", c.Provider(), service.ErrDNSUnsupported)
+	}
+	return inspector.DNSRecordsReferencing(ctx, resource)
+}
+
+// MissingPermissions forwards to the wrapped cleaner so the permissions of
+// a cleanup can still be checked beforehand
+func (c *recordedCleaner) MissingPermissions(ctx context.Context, permissions []string) ([]string, error) {
+	checker, ok := c.ResourceCleaner.(service.PermissionChecker)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s: %w", c.Provider(), service.ErrPermissionsUnsupported)
+	}
+	return checker.MissingPermissions(ctx, permissions)
+}
+
+// Start forwards to the wrapped cleaner so resources stopped off-hours can
+// still be started
+func (c *recordedCleaner) Start(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	starter, ok := c.ResourceCleaner.(service.ResourceStarter)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot start resources", c.Provider())
+	}
+	return starter.Start(c.scope(ctx, resource), resource)
+}
+
+// Snapshot forwards to the wrapped cleaner so resources can still be
+// snapshotted when quarantined
+func (c *recordedCleaner) Snapshot(ctx context.Context, resource *entity.Resource, snapshotID string) (*service.CleanupResult, error) {
+	snapshotter, ok := c.ResourceCleaner.(service.ResourceSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot take snapshots", c.Provider())
+	}
+	return snapshotter.Snapshot(c.scope(ctx, resource), resource, snapshotID)
+}
+
+// Untag forwards to the wrapped cleaner so the quarantine tag can still be
+// removed on restore
+func (c *recordedCleaner) Untag(ctx context.Context, resource *entity.Resource, keys []string) (*service.CleanupResult, error) {
+	remover, ok := c.ResourceCleaner.(service.TagRemover)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot remove tags", c.Provider())
+	}
+	return remover.Untag(c.scope(ctx, resource), resource, keys)
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recorder writes provider-mutating calls to the provider_calls table
+type Recorder struct {
+	db *gorm.DB
+}
+
+// NewRecorder creates a Recorder
+func NewRecorder(db *gorm.DB) *Recorder {
+	return &Recorder{db: db}
+}
+
+// Scope identifies what a provider call was made for
+type Scope struct {
+	OrganizationID uuid.UUID
+	ResourceID     *uuid.UUID
+	TaskID         string // cleanup task, links calls to cleanup results
+	Provider       string
+}
+
+type scopeKey struct{}
+
+type recorderKey struct{}
+
+// WithScope returns a context recording provider calls with rec under scope
+func WithScope(ctx context.Context, rec *Recorder, scope Scope) context.Context {
+	ctx = context.WithValue(ctx, recorderKey{}, rec)
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// Mutation runs a single provider-mutating API call and records it, with
+// the hash of its parameters, its outcome and duration. Cleaner
+// implementations wrap every SDK call that changes provider state with it;
+// without a recorder in ctx the call runs directly. Read-only calls are not
+// recorded.
+func Mutation(ctx context.Context, api string, params any, fn func(context.Context) error) error {
+	rec, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return fn(ctx)
+	}
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+
+	start := time.Now()
+	err := fn(ctx)
+
+	call := model.ProviderCall{
+		ID:             uuid.New(),
+		OrganizationID: scope.OrganizationID,
+		TaskID:         scope.TaskID,
+		ResourceID:     scope.ResourceID,
+		Provider:       scope.Provider,
+		API:            api,
+		ParamsHash:     hashParams(params),
+		Status:         callStatus(err),
+		DurationMs:     time.Since(start).Milliseconds(),
+		CalledAt:       start,
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+
+	// The call already happened: record it even if the task is cancelled
+	if dbErr := rec.db.WithContext(context.WithoutCancel(ctx)).Create(&call).Error; dbErr != nil {
+		log.Printf("Failed to record provider call %s for resource %v: %v", api, scope.ResourceID, dbErr)
+	}
+	return err
+}
+
+// hashParams returns the sha256 of the JSON-encoded parameters, so calls can
+// be matched against customer records without storing their content
+func hashParams(params any) string {
+	if params == nil {
+		return ""
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// callStatus summarizes the provider response: "ok", the HTTP status code or
+// the provider error code when the SDK error exposes one, or "error"
+func callStatus(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var status interface{ HTTPStatusCode() int }
+	if errors.As(err, &status) {
+		return strconv.Itoa(status.HTTPStatusCode())
+	}
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	return "error"
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// Purge deletes the provider calls older than each organization's retention.
+// Organizations without their own retention keep calls for defaultRetention.
+// It returns the number of calls deleted.
+func Purge(ctx context.Context, db *gorm.DB, defaultRetention time.Duration, now time.Time) (int64, error) {
+	var orgs []model.Organization
+	if err := db.WithContext(ctx).Select("id", "audit_retention_days").Find(&orgs).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch organizations: %w", err)
+	}
+
+	var deleted int64
+	for _, org := range orgs {
+		retention := defaultRetention
+		if days := org.Settings().AuditRetentionDays; days > 0 {
+			retention = time.Duration(days) * 24 * time.Hour
+		}
+		if retention <= 0 {
+			continue
+		}
+
+		result := db.WithContext(ctx).
+			Where("organization_id = ? AND called_at < ?", org.ID, now.Add(-retention)).
+			Delete(&model.ProviderCall{})
+		if result.Error != nil {
+			return deleted, fmt.Errorf("failed to purge provider calls of organization %s: %w", org.ID, result.Error)
+		}
+		deleted += result.RowsAffected
+	}
+	return deleted, nil
+}
@@ -0,0 +1,332 @@
+// Package aws calls the AWS APIs CloudSweep needs beyond scanning: STS to
+// assume roles, Organizations to list member accounts, Cost Explorer and
+// S3 Cost and Usage Reports for billed costs, Secrets Manager and the SSM
+// Parameter Store for the settings referencing them, Cloud Control and
+// CloudWatch for the custom resource types of organizations, AWS Config and
+// Resource Explorer for the inventory and changes of accounts, Route 53 for
+// the DNS records pointing at their resources, and the IAM policy simulator
+// to check the permissions of accounts. Requests are signed with SigV4
+// directly, so no SDK is required.
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// sessionDuration is the lifetime requested for assumed role credentials,
+// the maximum AWS allows when chaining roles
+const sessionDuration = time.Hour
+
+// refreshMargin renews cached credentials before they expire, so that a
+// long scan does not start with credentials about to become invalid
+const refreshMargin = 5 * time.Minute
+
+// ErrNoPlatformCredentials is returned when a role has to be assumed and no
+// AWS credentials are configured for CloudSweep itself
+var ErrNoPlatformCredentials = errors.New("aws: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required to assume roles")
+
+// Credentials are AWS credentials, temporary when Expires is set
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// AccountCredentials are the credentials stored on an AWS cloud account:
+// either the access key of an IAM user, or a role CloudSweep assumes with
+// its own credentials
+type AccountCredentials struct {
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	RoleARN         string `json:"role_arn,omitempty"`
+	ExternalID      string `json:"external_id,omitempty"`
+	// Inventory, when set, lists the resources of the account from an
+	// inventory (InventoryConfig or InventoryResourceExplorer) rather than
+	// with the describe calls of each service
+	Inventory string `json:"inventory,omitempty"`
+	// ConfigAggregator, when set, is the AWS Config aggregator the inventory
+	// and incremental scans of the account read, in ConfigAggregatorRegion
+	ConfigAggregator       string `json:"config_aggregator,omitempty"`
+	ConfigAggregatorRegion string `json:"config_aggregator_region,omitempty"`
+}
+
+// Client calls the AWS APIs. It is safe for concurrent use.
+type Client struct {
+	http     *http.Client
+	platform Credentials
+	region   string
+
+	stsEndpoint           string
+	iamEndpoint           string
+	organizationsEndpoint string
+	costExplorerEndpoint  string
+	route53Endpoint       string
+	s3Endpoint            string // format of the endpoint of a bucket and region
+	regionalEndpoint      string // format of the endpoint of a service and region
+
+	mu    sync.Mutex
+	cache map[string]Credentials // assumed role credentials by role and external ID
+}
+
+// NewClient creates a new Client authenticating with the AWS credentials
+// of CloudSweep
+func NewClient(cfg config.AWSConfig) *Client {
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &Client{
+		http: &http.Client{Timeout: 30 * time.Second},
+		platform: Credentials{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			SessionToken:    cfg.SessionToken,
+		},
+		region:                region,
+		stsEndpoint:           fmt.Sprintf("https://sts.%s.amazonaws.com", region),
+		iamEndpoint:           "https://iam.amazonaws.com",
+		organizationsEndpoint: "https://organizations.us-east-1.amazonaws.com",
+		costExplorerEndpoint:  "https://ce.us-east-1.amazonaws.com",
+		route53Endpoint:       "https://route53.amazonaws.com",
+		s3Endpoint:            "https://%s.s3.%s.amazonaws.com",
+		regionalEndpoint:      "https://%s.%s.amazonaws.com",
+		cache:                 map[string]Credentials{},
+	}
+}
+
+// Resolve returns the credentials scanners use for a cloud account. Roles
+// are assumed with the CloudSweep credentials and cached until shortly
+// before they expire.
+func (c *Client) Resolve(ctx context.Context, credentials []byte) (Credentials, error) {
+	var account AccountCredentials
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return Credentials{}, fmt.Errorf("invalid aws credentials: %w", err)
+	}
+	switch {
+	case account.RoleARN != "":
+		return c.AssumeRole(ctx, account.RoleARN, account.ExternalID)
+	case account.AccessKeyID != "" && account.SecretAccessKey != "":
+		return Credentials{AccessKeyID: account.AccessKeyID, SecretAccessKey: account.SecretAccessKey}, nil
+	default:
+		return Credentials{}, errors.New("invalid aws credentials: role_arn or an access key is required")
+	}
+}
+
+// AssumeRole returns temporary credentials of a role, assumed with the
+// CloudSweep credentials
+func (c *Client) AssumeRole(ctx context.Context, roleARN, externalID string) (Credentials, error) {
+	key := roleARN + "\x00" + externalID
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && time.Until(cached.Expires) > refreshMargin {
+		return cached, nil
+	}
+
+	if c.platform.AccessKeyID == "" || c.platform.SecretAccessKey == "" {
+		return Credentials{}, ErrNoPlatformCredentials
+	}
+	creds, err := c.assumeRole(ctx, c.platform, roleARN, externalID)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	c.mu.Lock()
+	c.cache[key] = creds
+	c.mu.Unlock()
+	return creds, nil
+}
+
+type assumeRoleResponse struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"AssumeRoleResult>Credentials"`
+}
+
+// queryErrorResponse is the error of a query protocol API, e.g. STS
+type queryErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+func (c *Client) assumeRole(ctx context.Context, creds Credentials, roleARN, externalID string) (Credentials, error) {
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {"2011-06-15"},
+		"RoleArn":         {roleARN},
+		"RoleSessionName": {"cloudsweep"},
+		"DurationSeconds": {strconv.Itoa(int(sessionDuration.Seconds()))},
+	}
+	if externalID != "" {
+		form.Set("ExternalId", externalID)
+	}
+	payload := []byte(form.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.stsEndpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	sign(req, payload, creds, c.region, "sts", time.Now())
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("sts AssumeRole %s: %w", roleARN, err)
+	}
+	if status >= 300 {
+		var e queryErrorResponse
+		if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+			return Credentials{}, fmt.Errorf("sts AssumeRole %s: %s: %s", roleARN, e.Code, e.Message)
+		}
+		return Credentials{}, fmt.Errorf("sts AssumeRole %s returned %d", roleARN, status)
+	}
+
+	var resp assumeRoleResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return Credentials{}, fmt.Errorf("sts AssumeRole %s: invalid response: %w", roleARN, err)
+	}
+	return Credentials{
+		AccessKeyID:     resp.Credentials.AccessKeyID,
+		SecretAccessKey: resp.Credentials.SecretAccessKey,
+		SessionToken:    resp.Credentials.SessionToken,
+		Expires:         resp.Credentials.Expiration,
+	}, nil
+}
+
+// Account is a member account of an AWS Organization
+type Account struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Email  string `json:"Email"`
+	Status string `json:"Status"` // ACTIVE, SUSPENDED or PENDING_CLOSURE
+}
+
+// ListAccounts lists the accounts of the organization of the management
+// account creds belong to
+func (c *Client) ListAccounts(ctx context.Context, creds Credentials) ([]Account, error) {
+	var accounts []Account
+	nextToken := ""
+	for {
+		input := map[string]string{}
+		if nextToken != "" {
+			input["NextToken"] = nextToken
+		}
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.organizationsEndpoint+"/", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "AWSOrganizationsV20161128.ListAccounts")
+		// Organizations is a global service served from us-east-1
+		sign(req, payload, creds, "us-east-1", "organizations", time.Now())
+
+		body, status, err := c.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("organizations ListAccounts: %w", err)
+		}
+		if status >= 300 {
+			return nil, fmt.Errorf("organizations ListAccounts: %s", jsonError(body, status))
+		}
+
+		var page struct {
+			Accounts  []Account `json:"Accounts"`
+			NextToken string    `json:"NextToken"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("organizations ListAccounts: invalid response: %w", err)
+		}
+		accounts = append(accounts, page.Accounts...)
+		if page.NextToken == "" {
+			return accounts, nil
+		}
+		nextToken = page.NextToken
+	}
+}
+
+// callQuery calls an action of a query protocol API, e.g. CloudWatch, with
+// creds. The action and version are part of form.
+func (c *Client) callQuery(ctx context.Context, creds Credentials, region, service string, form url.Values) ([]byte, error) {
+	return c.postQuery(ctx, creds, fmt.Sprintf(c.regionalEndpoint, service, region), region, service, form)
+}
+
+// postQuery calls an action of a query protocol API at an endpoint, for the
+// global services that are not served by region, e.g. IAM
+func (c *Client) postQuery(ctx context.Context, creds Credentials, endpoint, region, service string, form url.Values) ([]byte, error) {
+	payload := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	sign(req, payload, creds, region, service, time.Now())
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		var e queryErrorResponse
+		if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+			return nil, fmt.Errorf("%s: %s", e.Code, e.Message)
+		}
+		return nil, fmt.Errorf("returned %d", status)
+	}
+	return body, nil
+}
+
+// jsonError describes the error of a JSON protocol API
+func jsonError(body []byte, status int) string {
+	var e struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	if json.Unmarshal(body, &e) != nil || e.Type == "" {
+		return fmt.Sprintf("returned %d", status)
+	}
+	msg := e.Message
+	if msg == "" {
+		msg = e.MessageUpper
+	}
+	// The type may be prefixed with its namespace, e.g. "aws.api#AccessDeniedException"
+	if i := strings.LastIndex(e.Type, "#"); i >= 0 {
+		e.Type = e.Type[i+1:]
+	}
+	return e.Type + ": " + msg
+}
+
+func (c *Client) do(req *http.Request) ([]byte, int, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
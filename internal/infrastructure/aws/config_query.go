@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+)
+
+// selectResourceConfig runs an AWS Config advanced query selecting fields
+// of the configuration items of an account matching where, and returns its
+// rows. The account queries its own recorder in region, or its aggregator
+// when it has one.
+func (c *Client) selectResourceConfig(ctx context.Context, creds Credentials, account AccountCredentials, region, fields, where string) ([]string, error) {
+	target, input, callRegion := "StarlingDoveService.SelectResourceConfig", map[string]any{}, region
+	if account.ConfigAggregator != "" {
+		arn, err := c.CallerIdentity(ctx, creds)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.SplitN(arn, ":", 6)
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("unexpected caller identity %s", arn)
+		}
+		// The aggregator holds the items of every account it collects
+		where = fmt.Sprintf("accountId = '%s' AND %s", quoteConfig(parts[4]), where)
+		target, callRegion = "StarlingDoveService.SelectAggregateResourceConfig", account.ConfigAggregatorRegion
+		if callRegion == "" {
+			callRegion = c.region
+		}
+		input["ConfigurationAggregatorName"] = account.ConfigAggregator
+	}
+	input["Expression"] = "SELECT " + fields + " WHERE " + where
+	input["Limit"] = 100
+	action := strings.TrimPrefix(target, "StarlingDoveService.")
+
+	var rows []string
+	for {
+		var body []byte
+		err := ratelimit.Call(ctx, func(ctx context.Context) error {
+			var err error
+			body, err = c.callJSONAs(ctx, creds, "1.1", callRegion, "config", target, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %w", action, err)
+		}
+
+		var page struct {
+			Results   []string `json:"Results"`
+			NextToken string   `json:"NextToken"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("config %s: invalid response: %w", action, err)
+		}
+		rows = append(rows, page.Results...)
+		if page.NextToken == "" {
+			return rows, nil
+		}
+		input["NextToken"] = page.NextToken
+	}
+}
+
+// quoteConfig escapes a value quoted in an advanced query
+func quoteConfig(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// CostExplorer reads the amortized costs of the resources of an account
+// from Cost Explorer. Resource-level data has to be enabled in the Cost
+// Explorer preferences of the management account and only covers the last
+// 14 days; each request is charged by AWS.
+type CostExplorer struct {
+	client      *Client
+	accountID   string
+	credentials []byte
+}
+
+var _ service.BillingSource = (*CostExplorer)(nil)
+
+// NewCostExplorer creates a CostExplorer for an account, called with its
+// credentials
+func NewCostExplorer(client *Client, accountID string, credentials []byte) *CostExplorer {
+	return &CostExplorer{client: client, accountID: accountID, credentials: credentials}
+}
+
+// ResourceCosts implements service.BillingSource
+func (s *CostExplorer) ResourceCosts(ctx context.Context, start, end time.Time) ([]service.BilledCost, error) {
+	creds, err := s.client.Resolve(ctx, s.credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ resource, currency string }
+	totals := map[key]float64{}
+	nextToken := ""
+	for {
+		input := map[string]any{
+			"TimePeriod":  map[string]string{"Start": start.UTC().Format("2006-01-02"), "End": end.UTC().Format("2006-01-02")},
+			"Granularity": "DAILY",
+			"Metrics":     []string{"AmortizedCost"},
+			"GroupBy":     []map[string]string{{"Type": "DIMENSION", "Key": "RESOURCE_ID"}},
+			"Filter":      map[string]any{"Dimensions": map[string]any{"Key": "LINKED_ACCOUNT", "Values": []string{s.accountID}}},
+		}
+		if nextToken != "" {
+			input["NextPageToken"] = nextToken
+		}
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.client.costExplorerEndpoint+"/", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "AWSInsightsIndexService.GetCostAndUsageWithResources")
+		// Cost Explorer is a global service served from us-east-1
+		sign(req, payload, creds, "us-east-1", "ce", time.Now())
+
+		body, status, err := s.client.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("cost explorer GetCostAndUsageWithResources: %w", err)
+		}
+		if status >= 300 {
+			return nil, fmt.Errorf("cost explorer GetCostAndUsageWithResources: %s", jsonError(body, status))
+		}
+
+		var page struct {
+			ResultsByTime []struct {
+				Groups []struct {
+					Keys    []string `json:"Keys"`
+					Metrics map[string]struct {
+						Amount string `json:"Amount"`
+						Unit   string `json:"Unit"`
+					} `json:"Metrics"`
+				} `json:"Groups"`
+			} `json:"ResultsByTime"`
+			NextPageToken string `json:"NextPageToken"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("cost explorer GetCostAndUsageWithResources: invalid response: %w", err)
+		}
+		for _, day := range page.ResultsByTime {
+			for _, g := range day.Groups {
+				metric, ok := g.Metrics["AmortizedCost"]
+				// Costs not attributed to a resource (support, data transfer
+				// of some services...) are grouped under "NoResourceId"
+				if !ok || len(g.Keys) == 0 || g.Keys[0] == "" || g.Keys[0] == "NoResourceId" {
+					continue
+				}
+				amount, err := strconv.ParseFloat(metric.Amount, 64)
+				if err != nil {
+					continue
+				}
+				totals[key{g.Keys[0], metric.Unit}] += amount
+			}
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		nextToken = page.NextPageToken
+	}
+
+	costs := make([]service.BilledCost, 0, len(totals))
+	for k, amount := range totals {
+		costs = append(costs, service.BilledCost{ResourceID: k.resource, Amount: amount, Currency: k.currency})
+	}
+	return costs, nil
+}
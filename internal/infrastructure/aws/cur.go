@@ -0,0 +1,233 @@
+package aws
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/sigv4"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body, sent to S3 in
+// X-Amz-Content-Sha256 with GET requests
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// CostAndUsageReport reads the amortized costs of the resources of an
+// account from a legacy Cost and Usage Report delivered to S3, generated
+// with resource IDs in CSV format. Unlike Cost Explorer it covers any
+// period the report has been delivered for.
+type CostAndUsageReport struct {
+	client      *Client
+	accountID   string
+	credentials []byte
+
+	bucket     string
+	region     string
+	prefix     string
+	reportName string
+}
+
+var _ service.BillingSource = (*CostAndUsageReport)(nil)
+
+// NewCostAndUsageReport creates a CostAndUsageReport reading the report
+// reportName from the S3 path prefix of bucket with the credentials of an
+// account, usually the management account of its organization
+func NewCostAndUsageReport(client *Client, accountID string, credentials []byte, bucket, region, prefix, reportName string) (*CostAndUsageReport, error) {
+	if bucket == "" || reportName == "" {
+		return nil, errors.New("bucket and report_name are required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &CostAndUsageReport{
+		client:      client,
+		accountID:   accountID,
+		credentials: credentials,
+		bucket:      bucket,
+		region:      region,
+		prefix:      strings.Trim(prefix, "/"),
+		reportName:  reportName,
+	}, nil
+}
+
+// ResourceCosts implements service.BillingSource
+func (s *CostAndUsageReport) ResourceCosts(ctx context.Context, start, end time.Time) ([]service.BilledCost, error) {
+	creds, err := s.client.Resolve(ctx, s.credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ resource, currency string }
+	totals := map[key]float64{}
+	start, end = start.UTC(), end.UTC()
+	// Reports are delivered per billing month, the latest manifest of a
+	// month listing the files of its current version
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(end); month = month.AddDate(0, 1, 0) {
+		period := month.Format("20060102") + "-" + month.AddDate(0, 1, 0).Format("20060102")
+		manifestKey := strings.TrimPrefix(s.prefix+"/"+s.reportName+"/"+period+"/"+s.reportName+"-Manifest.json", "/")
+
+		body, err := s.getObject(ctx, creds, manifestKey)
+		if errors.Is(err, errObjectNotFound) {
+			continue // not delivered yet
+		}
+		if err != nil {
+			return nil, err
+		}
+		var manifest struct {
+			ReportKeys []string `json:"reportKeys"`
+		}
+		err = json.NewDecoder(body).Decode(&manifest)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cur manifest %s: %w", manifestKey, err)
+		}
+
+		for _, reportKey := range manifest.ReportKeys {
+			err := s.readReport(ctx, creds, reportKey, start, end, func(resource, currency string, amount float64) {
+				totals[key{resource, currency}] += amount
+			})
+			if err != nil {
+				return nil, fmt.Errorf("cur report %s: %w", reportKey, err)
+			}
+		}
+	}
+
+	costs := make([]service.BilledCost, 0, len(totals))
+	for k, amount := range totals {
+		costs = append(costs, service.BilledCost{ResourceID: k.resource, Amount: amount, Currency: k.currency})
+	}
+	return costs, nil
+}
+
+// readReport calls add with the amortized cost of every line of a report
+// file billed to the account for a resource between start and end
+func (s *CostAndUsageReport) readReport(ctx context.Context, creds Credentials, reportKey string, start, end time.Time, add func(resource, currency string, amount float64)) error {
+	body, err := s.getObject(ctx, creds, reportKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var r io.Reader = body
+	if strings.HasSuffix(reportKey, ".gz") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"lineItem/UsageAccountId", "lineItem/ResourceId", "lineItem/LineItemType", "lineItem/UsageStartDate", "lineItem/UnblendedCost"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("missing column %s, the report must include resource IDs", name)
+		}
+	}
+	column := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resource := column(record, "lineItem/ResourceId")
+		if resource == "" || column(record, "lineItem/UsageAccountId") != s.accountID {
+			continue
+		}
+		usageStart, err := time.Parse(time.RFC3339, column(record, "lineItem/UsageStartDate"))
+		if err != nil || usageStart.Before(start) || !usageStart.Before(end) {
+			continue
+		}
+
+		// Amortized cost: usage covered by a reservation or a savings plan
+		// costs its share of the commitment, whose fees are left out
+		var cost string
+		switch column(record, "lineItem/LineItemType") {
+		case "Usage", "Credit", "Refund":
+			cost = column(record, "lineItem/UnblendedCost")
+		case "DiscountedUsage":
+			cost = column(record, "reservation/EffectiveCost")
+		case "SavingsPlanCoveredUsage":
+			cost = column(record, "savingsPlan/SavingsPlanEffectiveCost")
+		default:
+			continue
+		}
+		amount, err := strconv.ParseFloat(cost, 64)
+		if err != nil {
+			continue
+		}
+		currency := column(record, "lineItem/CurrencyCode")
+		if currency == "" {
+			currency = "USD"
+		}
+		add(resource, currency, amount)
+	}
+}
+
+var errObjectNotFound = errors.New("s3 object not found")
+
+// getObject downloads an object of the report bucket; the caller closes
+// the body
+func (s *CostAndUsageReport) getObject(ctx context.Context, creds Credentials, key string) (io.ReadCloser, error) {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = sigv4.URIEncode(segment, true)
+	}
+	u := fmt.Sprintf(s.client.s3Endpoint, s.bucket, s.region) + "/" + strings.Join(segments, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	sign(req, nil, creds, s.region, "s3", time.Now())
+
+	resp, err := s.client.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 GetObject %s: %w", key, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var e struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+			return nil, fmt.Errorf("s3 GetObject %s: %s: %s", key, e.Code, e.Message)
+		}
+		return nil, fmt.Errorf("s3 GetObject %s returned %d", key, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,161 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/google/uuid"
+)
+
+// Metadata keys set on the resources of custom types
+const (
+	MetadataCloudType   = "cloud_type"   // type name of the resource, e.g. "AWS::DynamoDB::Table"
+	MetadataMetricValue = "metric_value" // metric of the detection over its lookback window
+	MetadataIdleReason  = "idle_reason"  // set by DetectUnused on unused resources
+)
+
+// Reasons a resource of a custom type is reported as unused
+const (
+	IdleReasonBelowThreshold = "metric_below_threshold"
+	IdleReasonAboveThreshold = "metric_above_threshold"
+)
+
+// CustomDetector detects the resources of a custom resource type: they are
+// listed with Cloud Control, which covers most resource types of
+// CloudFormation, and reported unused from a CloudWatch metric whose
+// dimension is valued with their identifier.
+type CustomDetector struct {
+	client      *Client
+	credentials []byte
+	t           *entity.CustomResourceType
+	now         func() time.Time
+}
+
+var _ service.ResourceDetector = (*CustomDetector)(nil)
+
+// NewCustomDetector creates the detector of a custom resource type for an
+// account, called with its credentials
+func NewCustomDetector(client *Client, t *entity.CustomResourceType, credentials []byte) (*CustomDetector, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &CustomDetector{client: client, credentials: credentials, t: t, now: time.Now}, nil
+}
+
+// Scan implements service.ResourceDetector
+func (d *CustomDetector) Scan(ctx context.Context, region string) ([]*entity.Resource, error) {
+	creds, err := d.client.Resolve(ctx, d.credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*entity.Resource
+	nextToken := ""
+	for {
+		input := map[string]any{"TypeName": d.t.CloudType, "MaxResults": 100}
+		if nextToken != "" {
+			input["NextToken"] = nextToken
+		}
+		var body []byte
+		err := ratelimit.Call(ctx, func(ctx context.Context) error {
+			var err error
+			body, err = d.client.callJSONAs(ctx, creds, "1.0", region, "cloudcontrolapi", "CloudApiService.ListResources", input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cloudcontrol ListResources %s: %w", d.t.CloudType, err)
+		}
+
+		var page struct {
+			ResourceDescriptions []struct {
+				Identifier string `json:"Identifier"`
+				Properties string `json:"Properties"`
+			} `json:"ResourceDescriptions"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("cloudcontrol ListResources %s: invalid response: %w", d.t.CloudType, err)
+		}
+		for _, desc := range page.ResourceDescriptions {
+			r := entity.NewResource(uuid.Nil, entity.CloudProviderAWS, d.t.Name, desc.Identifier, region, desc.Identifier)
+			r.Metadata[MetadataCloudType] = d.t.CloudType
+			// Most types tag their resources with a list of key-value pairs
+			var props struct {
+				Tags []struct {
+					Key   string `json:"Key"`
+					Value string `json:"Value"`
+				} `json:"Tags"`
+			}
+			if json.Unmarshal([]byte(desc.Properties), &props) == nil {
+				for _, tag := range props.Tags {
+					r.Tags[tag.Key] = tag.Value
+				}
+			}
+			resources = append(resources, r)
+		}
+		if page.NextToken == "" {
+			return resources, nil
+		}
+		nextToken = page.NextToken
+	}
+}
+
+// DetectUnused implements service.ResourceDetector, aggregating the metric
+// of each resource over the lookback window. Resources without datapoints
+// count as 0.
+func (d *CustomDetector) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	creds, err := d.client.Resolve(ctx, d.credentials)
+	if err != nil {
+		return err
+	}
+	detection := d.t.Detection
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		value, err := d.metric(ctx, creds, r)
+		if err != nil {
+			return err
+		}
+		r.Metadata[MetadataMetricValue] = value
+		if !detection.Unused(value) {
+			continue
+		}
+		r.MarkAsUnused()
+		if detection.Above {
+			r.Metadata[MetadataIdleReason] = IdleReasonAboveThreshold
+		} else {
+			r.Metadata[MetadataIdleReason] = IdleReasonBelowThreshold
+		}
+	}
+	return nil
+}
+
+// EstimateCost implements service.ResourceDetector with the monthly cost
+// set on the type
+func (d *CustomDetector) EstimateCost(ctx context.Context, r *entity.Resource) (entity.Cost, error) {
+	if d.t.MonthlyCost == 0 {
+		return entity.Cost{}, errors.New("no monthly cost set on custom resource type " + string(d.t.Name))
+	}
+	return entity.MonthlyUSDCost(d.t.MonthlyCost), nil
+}
+
+// metric returns the statistic of the detection over its lookback window,
+// from daily datapoints
+func (d *CustomDetector) metric(ctx context.Context, creds Credentials, r *entity.Resource) (float64, error) {
+	detection := d.t.Detection
+	end := d.now().UTC()
+	start := end.AddDate(0, 0, -detection.LookbackDays)
+	points, err := d.client.metricStatistics(ctx, creds, r.Region, detection.Namespace, detection.MetricName, detection.Statistic,
+		[]metricDimension{{Name: detection.Dimension, Value: r.ResourceID}}, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("resource %s: %w", r.ResourceID, err)
+	}
+	return aggregate(points, detection.Statistic), nil
+}
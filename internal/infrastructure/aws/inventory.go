@@ -0,0 +1,783 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/google/uuid"
+)
+
+// Inventory modes of an AWS cloud account, set in the inventory field of
+// its credentials. Accounts without one are scanned with the describe calls
+// of each service.
+const (
+	InventoryConfig           = "config"            // AWS Config advanced queries, on the recorder of each region or the aggregator
+	InventoryResourceExplorer = "resource_explorer" // Resource Explorer searches, on the index of each region
+)
+
+// Metadata keys set on the resources listed by an inventory
+const (
+	MetadataInventory = "inventory" // inventory mode the resource was listed with
+	MetadataState     = "state"     // state of instances, volumes, databases and network attachments, from AWS Config only
+	MetadataPeerID    = "peer_id"   // VPC or transit gateway on the other side of a network attachment, from AWS Config only
+)
+
+// Reasons a resource listed by an inventory is reported as unused, besides
+// those of the service detectors
+const (
+	IdleReasonStopped    = "stopped"
+	IdleReasonUnattached = "unattached"
+)
+
+// inventoryType is how the inventories name a built-in resource type
+type inventoryType struct {
+	config   string   // AWS Config resource type, empty when it is not recorded
+	explorer []string // Resource Explorer resource types
+	arnID    bool     // resources are identified by their ARN rather than their ID
+}
+
+var inventoryTypes = map[entity.ResourceType]inventoryType{
+	entity.ResourceTypeEC2Instance:              {config: "AWS::EC2::Instance", explorer: []string{"ec2:instance"}},
+	entity.ResourceTypeEBSVolume:                {config: "AWS::EC2::Volume", explorer: []string{"ec2:volume"}},
+	entity.ResourceTypeEBSSnapshot:              {explorer: []string{"ec2:snapshot"}},
+	entity.ResourceTypeElasticIP:                {config: "AWS::EC2::EIP", explorer: []string{"ec2:elastic-ip"}},
+	entity.ResourceTypeLoadBalancer:             {config: "AWS::ElasticLoadBalancingV2::LoadBalancer", explorer: []string{"elasticloadbalancing:loadbalancer/app", "elasticloadbalancing:loadbalancer/net"}, arnID: true},
+	entity.ResourceTypeS3Bucket:                 {config: "AWS::S3::Bucket", explorer: []string{"s3:bucket"}},
+	entity.ResourceTypeRDSInstance:              {config: "AWS::RDS::DBInstance", explorer: []string{"rds:db"}},
+	entity.ResourceTypeVPCPeering:               {config: "AWS::EC2::VPCPeeringConnection", explorer: []string{"ec2:vpc-peering-connection"}},
+	entity.ResourceTypeVPNConnection:            {config: "AWS::EC2::VPNConnection", explorer: []string{"ec2:vpn-connection"}},
+	entity.ResourceTypeTransitGatewayAttachment: {config: "AWS::EC2::TransitGatewayAttachment", explorer: []string{"ec2:transit-gateway-attachment"}},
+	entity.ResourceTypeNetworkInterface:         {config: "AWS::EC2::NetworkInterface", explorer: []string{"ec2:network-interface"}},
+	entity.ResourceTypeNATGateway:               {config: "AWS::EC2::NatGateway", explorer: []string{"ec2:natgateway"}},
+}
+
+// InventoryHeuristics returns when the resources of a type listed by an
+// inventory are reported unused
+func InventoryHeuristics(t entity.ResourceType) []string {
+	switch t {
+	case entity.ResourceTypeEC2Instance:
+		return []string{"Stopped, with the AWS Config inventory"}
+	case entity.ResourceTypeRDSInstance:
+		return []string{"Stopped, with the AWS Config inventory", "No connection over 14 days", "Smaller instance class suggested when CPU peaked under 10% over 14 days"}
+	case entity.ResourceTypeEBSVolume:
+		return []string{"Not attached to an instance, with the AWS Config inventory"}
+	case entity.ResourceTypeEBSSnapshot:
+		return []string{"Backing only AMIs no instance uses", "Source volume deleted", "Older than 30 days with a newer snapshot of the same volume, unless taken by AWS Backup or DLM", "Backup taken before a cleanup past its retention"}
+	case entity.ResourceTypeElasticIP:
+		return []string{"Not associated, with the AWS Config inventory"}
+	case entity.ResourceTypeNetworkInterface:
+		return []string{"Detached and not owned by a service, with the AWS Config inventory"}
+	case entity.ResourceTypeVPCPeering:
+		return []string{"Rejected, expired or failed, or its peer VPC deleted, with the AWS Config inventory"}
+	case entity.ResourceTypeVPNConnection:
+		return []string{"Every tunnel down, with the AWS Config inventory", "Under 1 MiB of tunnel traffic over 14 days"}
+	case entity.ResourceTypeTransitGatewayAttachment:
+		return []string{"Rejected or failed, or its transit gateway deleted, with the AWS Config inventory", "Under 1 MiB of traffic over 14 days"}
+	case entity.ResourceTypeS3Bucket:
+		return []string{"No GET request over 90 days, from the EntireBucket request metrics filter", "Lifecycle rules suggested for buckets without any, with the AWS Config inventory"}
+	}
+	return nil
+}
+
+// InventoryDetector lists the resources of a built-in type from the
+// inventory of an account instead of the describe calls of their service,
+// which takes a single read permission and one paginated call per type and
+// region. Resource Explorer only reports that resources exist, so they are
+// never reported unused but for snapshots, described with the EC2 API; AWS
+// Config records their configuration, from which stopped instances and
+// databases, detached volumes, interfaces and addresses are.
+type InventoryDetector struct {
+	client      *Client
+	credentials []byte
+	account     AccountCredentials
+	t           entity.ResourceType
+	now         func() time.Time
+}
+
+var _ service.ResourceDetector = (*InventoryDetector)(nil)
+
+// NewInventoryDetector creates the detector of a resource type for an
+// account, called with its credentials. It returns nil when the account has
+// no inventory mode, or its inventory cannot list the type.
+func NewInventoryDetector(client *Client, t entity.ResourceType, credentials []byte) (*InventoryDetector, error) {
+	var account AccountCredentials
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("invalid aws credentials: %w", err)
+	}
+	it := inventoryTypes[t]
+	switch account.Inventory {
+	case "":
+		return nil, nil
+	case InventoryConfig:
+		if it.config == "" {
+			return nil, nil
+		}
+	case InventoryResourceExplorer:
+		if len(it.explorer) == 0 {
+			return nil, nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid aws credentials: inventory must be %s or %s", InventoryConfig, InventoryResourceExplorer)
+	}
+	return &InventoryDetector{client: client, credentials: credentials, account: account, t: t, now: time.Now}, nil
+}
+
+// ScanPermissions returns the permissions the scans of an account call
+// when it has an inventory mode, CloudWatch reads for the traffic and
+// activity of resources and Route 53 reads for the DNS records pointing at
+// them included. ok is false when it is scanned with the describe calls of
+// each service.
+func ScanPermissions(account AccountCredentials) (permissions []string, ok bool) {
+	switch {
+	case account.Inventory == InventoryConfig && account.ConfigAggregator != "":
+		return []string{"config:SelectAggregateResourceConfig", "cloudwatch:GetMetricStatistics", "s3:GetMetricsConfiguration",
+			"route53:ListHostedZones", "route53:ListResourceRecordSets"}, true
+	case account.Inventory == InventoryConfig:
+		return []string{"config:SelectResourceConfig", "cloudwatch:GetMetricStatistics", "s3:GetMetricsConfiguration",
+			"route53:ListHostedZones", "route53:ListResourceRecordSets"}, true
+	case account.Inventory == InventoryResourceExplorer:
+		return []string{"resource-explorer-2:Search", "cloudwatch:GetMetricStatistics", "s3:GetMetricsConfiguration",
+			"route53:ListHostedZones", "route53:ListResourceRecordSets",
+			"ec2:DescribeSnapshots", "ec2:DescribeVolumes", "ec2:DescribeImages", "ec2:DescribeInstances", "ec2:DescribeLaunchTemplateVersions"}, true
+	}
+	return nil, false
+}
+
+// Scan implements service.ResourceDetector
+func (d *InventoryDetector) Scan(ctx context.Context, region string) ([]*entity.Resource, error) {
+	creds, err := d.client.Resolve(ctx, d.credentials)
+	if err != nil {
+		return nil, err
+	}
+	if d.account.Inventory == InventoryResourceExplorer {
+		return d.search(ctx, creds, region)
+	}
+	return d.selectConfig(ctx, creds, region)
+}
+
+// configResource is a configuration item selected by an advanced query
+type configResource struct {
+	AccountID    string    `json:"accountId"`
+	ResourceID   string    `json:"resourceId"`
+	ResourceName string    `json:"resourceName"`
+	ARN          string    `json:"arn"`
+	CreatedAt    time.Time `json:"resourceCreationTime"`
+	Tags         []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"tags"`
+	Configuration json.RawMessage `json:"configuration"`
+	// Supplementary configuration of buckets, e.g. their lifecycle rules
+	Supplementary map[string]json.RawMessage `json:"supplementaryConfiguration"`
+}
+
+// selectConfig lists the resources of the type recorded by AWS Config
+func (d *InventoryDetector) selectConfig(ctx context.Context, creds Credentials, region string) ([]*entity.Resource, error) {
+	it := inventoryTypes[d.t]
+	where := fmt.Sprintf("resourceType = '%s' AND awsRegion = '%s'", it.config, quoteConfig(region))
+	fields := "accountId, resourceId, resourceName, arn, resourceCreationTime, tags, configuration"
+	if d.t == entity.ResourceTypeS3Bucket {
+		fields += ", supplementaryConfiguration"
+	}
+	rows, err := d.client.selectResourceConfig(ctx, creds, d.account, region, fields, where)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*entity.Resource, 0, len(rows))
+	for _, row := range rows {
+		var item configResource
+		if err := json.Unmarshal([]byte(row), &item); err != nil {
+			return nil, fmt.Errorf("config query %s: invalid result: %w", it.config, err)
+		}
+		id := item.ResourceID
+		if it.arnID && item.ARN != "" {
+			id = item.ARN
+		}
+		r := entity.NewResource(uuid.Nil, entity.CloudProviderAWS, d.t, id, region, item.ResourceName)
+		r.Metadata[MetadataCloudType] = it.config
+		r.Metadata[MetadataInventory] = InventoryConfig
+		for _, tag := range item.Tags {
+			r.Tags[tag.Key] = tag.Value
+		}
+		if r.Name == "" {
+			r.Name = r.Tags["Name"]
+		}
+		setConfigMetadata(r, item.AccountID, item.Configuration)
+		if d.t == entity.ResourceTypeS3Bucket {
+			setBucketConfig(r, item)
+		}
+		resources = append(resources, r)
+	}
+
+	if d.t == entity.ResourceTypeVPCPeering || d.t == entity.ResourceTypeTransitGatewayAttachment {
+		if err := d.setPeerExists(ctx, creds, region, resources); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// setPeerExists records whether the VPC or transit gateway on the other side
+// of network attachments still exists. Only peers of the account in the
+// region are looked up, from the items AWS Config recorded.
+func (d *InventoryDetector) setPeerExists(ctx context.Context, creds Credentials, region string, resources []*entity.Resource) error {
+	peerType := "AWS::EC2::VPC"
+	if d.t == entity.ResourceTypeTransitGatewayAttachment {
+		peerType = "AWS::EC2::TransitGateway"
+	}
+	var existing map[string]bool
+	for _, r := range resources {
+		peer, _ := r.Metadata[MetadataPeerID].(string)
+		if peer == "" {
+			continue
+		}
+		if existing == nil {
+			where := fmt.Sprintf("resourceType = '%s' AND awsRegion = '%s'", peerType, quoteConfig(region))
+			rows, err := d.client.selectResourceConfig(ctx, creds, d.account, region, "resourceId", where)
+			if err != nil {
+				return err
+			}
+			existing = make(map[string]bool, len(rows))
+			for _, row := range rows {
+				var item configResource
+				if json.Unmarshal([]byte(row), &item) == nil {
+					existing[item.ResourceID] = true
+				}
+			}
+		}
+		r.Metadata[service.NetworkMetadataPeerExists] = existing[peer]
+	}
+	return nil
+}
+
+// setConfigMetadata fills in the metadata the unused detection of the type
+// needs from its recorded configuration, in the account accountID
+func setConfigMetadata(r *entity.Resource, accountID string, raw json.RawMessage) {
+	var cfg struct {
+		State json.RawMessage `json:"state"` // {"name": ...} for instances, a string for volumes
+		// Databases
+		DBInstanceStatus string `json:"dBInstanceStatus"`
+		DBInstanceClass  string `json:"dBInstanceClass"`
+		// Elastic IPs
+		PublicIP      string `json:"publicIp"`
+		AssociationID string `json:"associationId"`
+		// Network interfaces and VPC peering connections, {"code": ...}
+		Status           json.RawMessage `json:"status"`
+		RequesterManaged bool            `json:"requesterManaged"`
+		Association      struct {
+			PublicIP string `json:"publicIp"`
+		} `json:"association"`
+		// VPC peering connections
+		AccepterVPCInfo  peeringVPCInfo `json:"accepterVpcInfo"`
+		RequesterVPCInfo peeringVPCInfo `json:"requesterVpcInfo"`
+		// VPN connections
+		VGWTelemetry []struct {
+			Status string `json:"status"`
+		} `json:"vgwTelemetry"`
+		// Transit gateway attachments
+		TransitGatewayID      string `json:"transitGatewayId"`
+		TransitGatewayOwnerID string `json:"transitGatewayOwnerId"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &cfg) != nil {
+		return
+	}
+
+	switch r.Type {
+	case entity.ResourceTypeEC2Instance:
+		var state struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(cfg.State, &state) == nil && state.Name != "" {
+			r.Metadata[MetadataState] = state.Name
+		}
+	case entity.ResourceTypeEBSVolume:
+		var state string
+		if json.Unmarshal(cfg.State, &state) == nil && state != "" {
+			r.Metadata[MetadataState] = state
+		}
+	case entity.ResourceTypeRDSInstance:
+		if cfg.DBInstanceStatus != "" {
+			r.Metadata[service.DatabaseMetadataState] = cfg.DBInstanceStatus
+		}
+		if cfg.DBInstanceClass != "" {
+			r.Metadata[service.DatabaseMetadataInstanceClass] = cfg.DBInstanceClass
+		}
+	case entity.ResourceTypeElasticIP:
+		r.Metadata[service.IPMetadataAddress] = cfg.PublicIP
+		r.Metadata[service.IPMetadataAssociation] = cfg.AssociationID
+	case entity.ResourceTypeNetworkInterface:
+		var status string
+		_ = json.Unmarshal(cfg.Status, &status)
+		r.Metadata[service.InterfaceMetadataStatus] = status
+		r.Metadata[service.InterfaceMetadataRequesterManaged] = cfg.RequesterManaged
+		if cfg.Association.PublicIP != "" {
+			r.Metadata[service.InterfaceMetadataPublicIP] = cfg.Association.PublicIP
+		}
+	case entity.ResourceTypeVPCPeering:
+		var status struct {
+			Code string `json:"code"`
+		}
+		_ = json.Unmarshal(cfg.Status, &status)
+		r.Metadata[service.NetworkMetadataState] = status.Code
+		// The peer is the side of another account, or the accepter when
+		// both VPCs are in the account
+		peer := cfg.AccepterVPCInfo
+		if peer.OwnerID == accountID && cfg.RequesterVPCInfo.OwnerID != accountID {
+			peer = cfg.RequesterVPCInfo
+		}
+		r.Metadata[service.NetworkMetadataPeerOwner] = peer.OwnerID
+		if peer.OwnerID == accountID && (peer.Region == "" || peer.Region == r.Region) {
+			r.Metadata[MetadataPeerID] = peer.VPCID
+		}
+	case entity.ResourceTypeVPNConnection:
+		var state string
+		if json.Unmarshal(cfg.State, &state) == nil && state != "" {
+			r.Metadata[service.NetworkMetadataState] = state
+		}
+		// An available connection whose tunnels are all down carries no
+		// traffic
+		down := len(cfg.VGWTelemetry) > 0
+		for _, t := range cfg.VGWTelemetry {
+			down = down && strings.EqualFold(t.Status, "DOWN")
+		}
+		if down && state == "available" {
+			r.Metadata[service.NetworkMetadataState] = "down"
+		}
+	case entity.ResourceTypeTransitGatewayAttachment:
+		var state string
+		if json.Unmarshal(cfg.State, &state) == nil && state != "" {
+			r.Metadata[service.NetworkMetadataState] = state
+		}
+		r.Metadata[service.NetworkMetadataPeerOwner] = cfg.TransitGatewayOwnerID
+		if cfg.TransitGatewayOwnerID == accountID {
+			r.Metadata[MetadataPeerID] = cfg.TransitGatewayID
+		}
+	}
+}
+
+// setBucketConfig records the lifecycle rules and website of a bucket, from
+// its supplementary configuration. Buckets without a recorded read are
+// judged on their age, which is their creation date.
+func setBucketConfig(r *entity.Resource, item configResource) {
+	if !item.CreatedAt.IsZero() {
+		r.CreatedAt = item.CreatedAt
+	}
+
+	var lifecycle struct {
+		Rules []json.RawMessage `json:"rules"`
+	}
+	if raw, ok := item.Supplementary["BucketLifecycleConfiguration"]; ok {
+		// Supplementary items are recorded as JSON strings
+		var encoded string
+		if json.Unmarshal(raw, &encoded) == nil {
+			raw = json.RawMessage(encoded)
+		}
+		_ = json.Unmarshal(raw, &lifecycle)
+	}
+	r.Metadata[service.StorageMetadataLifecycleRules] = len(lifecycle.Rules)
+
+	if raw, ok := item.Supplementary["BucketWebsiteConfiguration"]; ok && string(raw) != "null" {
+		r.Metadata[service.StorageMetadataWebsite] = fmt.Sprintf("%s.s3-website-%s.amazonaws.com", r.ResourceID, r.Region)
+	}
+}
+
+// peeringVPCInfo is a side of a VPC peering connection
+type peeringVPCInfo struct {
+	OwnerID string `json:"ownerId"`
+	VPCID   string `json:"vpcId"`
+	Region  string `json:"region"`
+}
+
+// search lists the resources of the type indexed by Resource Explorer. The
+// region needs an index, local or the aggregator index of the account.
+func (d *InventoryDetector) search(ctx context.Context, creds Credentials, region string) ([]*entity.Resource, error) {
+	it := inventoryTypes[d.t]
+	var query []string
+	for _, t := range it.explorer {
+		query = append(query, "resourcetype:"+t)
+	}
+	query = append(query, "region:"+region)
+	input := map[string]any{"QueryString": strings.Join(query, " "), "MaxResults": 1000}
+
+	var resources []*entity.Resource
+	for {
+		var body []byte
+		err := ratelimit.Call(ctx, func(ctx context.Context) error {
+			var err error
+			body, err = d.client.callRESTJSON(ctx, creds, region, "resource-explorer-2", "/Search", input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("resource explorer Search %s: %w", d.t, err)
+		}
+
+		var page struct {
+			Resources []struct {
+				ARN          string `json:"Arn"`
+				ResourceType string `json:"ResourceType"`
+				Properties   []struct {
+					Name string          `json:"Name"`
+					Data json.RawMessage `json:"Data"`
+				} `json:"Properties"`
+			} `json:"Resources"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("resource explorer Search %s: invalid response: %w", d.t, err)
+		}
+		for _, found := range page.Resources {
+			id := found.ARN
+			if !it.arnID {
+				id = arnResourceID(found.ARN)
+			}
+			r := entity.NewResource(uuid.Nil, entity.CloudProviderAWS, d.t, id, region, "")
+			r.Metadata[MetadataCloudType] = found.ResourceType
+			r.Metadata[MetadataInventory] = InventoryResourceExplorer
+			for _, p := range found.Properties {
+				if p.Name != "tags" {
+					continue
+				}
+				var tags []struct {
+					Key   string `json:"Key"`
+					Value string `json:"Value"`
+				}
+				if json.Unmarshal(p.Data, &tags) == nil {
+					for _, tag := range tags {
+						r.Tags[tag.Key] = tag.Value
+					}
+				}
+			}
+			r.Name = r.Tags["Name"]
+			resources = append(resources, r)
+		}
+		if page.NextToken != "" {
+			input["NextToken"] = page.NextToken
+			continue
+		}
+		if d.t == entity.ResourceTypeEBSSnapshot {
+			if err := d.setSnapshotMetadata(ctx, creds, region, resources); err != nil {
+				return nil, err
+			}
+		}
+		return resources, nil
+	}
+}
+
+// arnResourceID returns the ID of the resource of an ARN, e.g. vol-123 for
+// arn:aws:ec2:us-east-1:123456789012:volume/vol-123, or the bucket of an S3
+// ARN
+func arnResourceID(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 {
+		return arn
+	}
+	resource := parts[5]
+	if i := strings.LastIndex(resource, "/"); i >= 0 {
+		return resource[i+1:]
+	}
+	return resource
+}
+
+// DetectUnused implements service.ResourceDetector
+func (d *InventoryDetector) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		state, _ := r.Metadata[MetadataState].(string)
+		var reason string
+		switch {
+		case state == "stopped" && r.Type == entity.ResourceTypeEC2Instance:
+			reason = IdleReasonStopped
+		case state == "available" && r.Type == entity.ResourceTypeEBSVolume:
+			reason = IdleReasonUnattached
+		}
+		if reason != "" {
+			r.MarkAsUnused()
+			r.Metadata[MetadataIdleReason] = reason
+		}
+	}
+	// Resource Explorer does not report whether addresses and interfaces
+	// are attached
+	var attachable []*entity.Resource
+	for _, r := range resources {
+		if r.Metadata[MetadataInventory] == InventoryConfig {
+			attachable = append(attachable, r)
+		}
+	}
+	service.DetectIdlePublicIPs(attachable)
+	service.DetectIdleTrafficResources(attachable, service.DefaultTrafficThresholds)
+
+	if d.t.IsNetworkAttachment() {
+		if err := d.setNetworkBytes(ctx, resources); err != nil {
+			return err
+		}
+		service.DetectIdleNetworkAttachments(resources, service.DefaultNetworkIdleBytes)
+	}
+	if d.t.IsObjectStorage() {
+		measured, err := d.setBucketActivity(ctx, resources)
+		if err != nil {
+			return err
+		}
+		service.DetectIdleBuckets(measured, service.DefaultBucketIdleDays, d.now())
+	}
+	if d.t.IsManagedDatabase() {
+		if err := d.setDatabaseActivity(ctx, resources); err != nil {
+			return err
+		}
+		service.DetectIdleDatabases(resources, service.DefaultDatabaseOversizedCPU)
+	}
+	if d.t.IsSnapshot() {
+		service.DetectIdleSnapshots(resources, service.DefaultSnapshotRetention, d.now())
+	}
+	return nil
+}
+
+// setDatabaseActivity records the peak connection count and CPU utilization
+// of running databases over the lookback window. Stopped databases report
+// no metrics and are judged on their state.
+func (d *InventoryDetector) setDatabaseActivity(ctx context.Context, resources []*entity.Resource) error {
+	creds, err := d.client.Resolve(ctx, d.credentials)
+	if err != nil {
+		return err
+	}
+	end := d.now().UTC()
+	start := end.Add(-service.DefaultDatabaseLookback)
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		if state, _ := r.Metadata[service.DatabaseMetadataState].(string); state != "" && state != "available" {
+			continue
+		}
+		// Metrics are dimensioned by the identifier of the instance, the
+		// name AWS Config records; Resource Explorer only reports its ARN,
+		// ending in db:<identifier>
+		identifier := r.Name
+		if r.Metadata[MetadataInventory] == InventoryResourceExplorer {
+			identifier = strings.TrimPrefix(r.ResourceID, "db:")
+		}
+		dimensions := []metricDimension{{Name: "DBInstanceIdentifier", Value: identifier}}
+
+		connections, err := d.client.metricStatistics(ctx, creds, r.Region, "AWS/RDS", "DatabaseConnections", "Maximum", dimensions, start, end)
+		if err != nil {
+			return fmt.Errorf("database %s: %w", identifier, err)
+		}
+		cpu, err := d.client.metricStatistics(ctx, creds, r.Region, "AWS/RDS", "CPUUtilization", "Maximum", dimensions, start, end)
+		if err != nil {
+			return fmt.Errorf("database %s: %w", identifier, err)
+		}
+		// A database without datapoints was not running long enough to
+		// tell
+		if len(connections) > 0 {
+			r.Metadata[service.DatabaseMetadataConnections] = int64(aggregate(connections, "Maximum"))
+		}
+		if len(cpu) > 0 {
+			r.Metadata[service.DatabaseMetadataCPUMax] = aggregate(cpu, "Maximum")
+		}
+	}
+	return nil
+}
+
+// s3StorageClasses are the storage classes of the StorageType dimension of
+// the BucketSizeBytes metric
+var s3StorageClasses = map[string]string{
+	"StandardStorage":                "STANDARD",
+	"IntelligentTieringFAStorage":    "INTELLIGENT_TIERING",
+	"IntelligentTieringIAStorage":    "INTELLIGENT_TIERING",
+	"IntelligentTieringAIAStorage":   "INTELLIGENT_TIERING",
+	"StandardIAStorage":              "STANDARD_IA",
+	"OneZoneIAStorage":               "ONEZONE_IA",
+	"GlacierInstantRetrievalStorage": "GLACIER_IR",
+	"GlacierStorage":                 "GLACIER",
+	"DeepArchiveStorage":             "DEEP_ARCHIVE",
+}
+
+// setBucketActivity records the bytes buckets store per storage class, from
+// the daily storage metrics, and the day of their last read, from the
+// request metrics of their EntireBucket filter. It returns the buckets with
+// request metrics: the others cannot be told unread, and are left out of
+// the detection.
+func (d *InventoryDetector) setBucketActivity(ctx context.Context, resources []*entity.Resource) ([]*entity.Resource, error) {
+	creds, err := d.client.Resolve(ctx, d.credentials)
+	if err != nil {
+		return nil, err
+	}
+	end := d.now().UTC()
+	var measured []*entity.Resource
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+
+		// Storage metrics are reported once a day
+		classBytes := make(map[string]int64)
+		for storageType, class := range s3StorageClasses {
+			points, err := d.client.metricStatistics(ctx, creds, r.Region, "AWS/S3", "BucketSizeBytes", "Average",
+				[]metricDimension{{Name: "BucketName", Value: r.ResourceID}, {Name: "StorageType", Value: storageType}},
+				end.Add(-2*metricPeriod), end)
+			if err != nil {
+				return nil, fmt.Errorf("bucket %s: %w", r.ResourceID, err)
+			}
+			if latest, ok := latestDatapoint(points); ok && latest.Average > 0 {
+				classBytes[class] += int64(latest.Average)
+			}
+		}
+		r.Metadata[service.StorageMetadataClassBytes] = classBytes
+
+		ok, err := d.client.hasRequestMetrics(ctx, creds, r.ResourceID, r.Region)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		measured = append(measured, r)
+		points, err := d.client.metricStatistics(ctx, creds, r.Region, "AWS/S3", "GetRequests", "Sum",
+			[]metricDimension{{Name: "BucketName", Value: r.ResourceID}, {Name: "FilterId", Value: "EntireBucket"}},
+			end.AddDate(0, 0, -service.DefaultBucketIdleDays), end)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %s: %w", r.ResourceID, err)
+		}
+		var read []metricDatapoint
+		for _, p := range points {
+			if p.Sum > 0 {
+				read = append(read, p)
+			}
+		}
+		if latest, ok := latestDatapoint(read); ok {
+			r.Metadata[service.StorageMetadataLastReadAt] = latest.Timestamp.UTC().Format(time.RFC3339)
+		}
+	}
+	return measured, nil
+}
+
+// hasRequestMetrics tells whether a bucket has the EntireBucket request
+// metrics filter, without which CloudWatch reports none of its reads
+func (c *Client) hasRequestMetrics(ctx context.Context, creds Credentials, bucket, region string) (bool, error) {
+	u := fmt.Sprintf(c.s3Endpoint, bucket, region) + "/?id=EntireBucket&metrics="
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	sign(req, nil, creds, region, "s3", time.Now())
+
+	var status int
+	err = ratelimit.Call(ctx, func(ctx context.Context) error {
+		var err error
+		_, status, err = c.do(req)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("s3 GetBucketMetricsConfiguration %s: %w", bucket, err)
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return false, nil
+	case status >= 300:
+		return false, fmt.Errorf("s3 GetBucketMetricsConfiguration %s returned %d", bucket, status)
+	}
+	return true, nil
+}
+
+// networkMetrics are the CloudWatch metrics of the traffic of network
+// attachments, in and out. Peering connections have none.
+var networkMetrics = map[entity.ResourceType]struct {
+	namespace string
+	metrics   []string
+	dimension string
+}{
+	entity.ResourceTypeVPNConnection:            {"AWS/VPN", []string{"TunnelDataIn", "TunnelDataOut"}, "VpnId"},
+	entity.ResourceTypeTransitGatewayAttachment: {"AWS/TransitGateway", []string{"BytesIn", "BytesOut"}, "TransitGatewayAttachment"},
+}
+
+// setNetworkBytes records the bytes network attachments carried over the
+// lookback window
+func (d *InventoryDetector) setNetworkBytes(ctx context.Context, resources []*entity.Resource) error {
+	nm, ok := networkMetrics[d.t]
+	if !ok {
+		return nil
+	}
+	creds, err := d.client.Resolve(ctx, d.credentials)
+	if err != nil {
+		return err
+	}
+	end := d.now().UTC()
+	start := end.Add(-service.DefaultNetworkLookback)
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		var bytes float64
+		for _, metric := range nm.metrics {
+			points, err := d.client.metricStatistics(ctx, creds, r.Region, nm.namespace, metric, "Sum",
+				[]metricDimension{{Name: nm.dimension, Value: r.ResourceID}}, start, end)
+			if err != nil {
+				return fmt.Errorf("resource %s: %w", r.ResourceID, err)
+			}
+			bytes += aggregate(points, "Sum")
+		}
+		r.Metadata[service.NetworkMetadataBytes] = int64(bytes)
+	}
+	return nil
+}
+
+// EstimateCost implements service.ResourceDetector. Only the resources
+// priced at a flat rate, buckets from the bytes of their storage classes and
+// snapshots from the data they store, are estimated; the others are left to
+// the price catalog.
+func (d *InventoryDetector) EstimateCost(ctx context.Context, r *entity.Resource) (entity.Cost, error) {
+	if classBytes := service.StorageClassBytes(r.Metadata); len(classBytes) > 0 {
+		if cost, ok := service.ObjectStorageCost(r.Type, classBytes); ok {
+			return cost, nil
+		}
+	}
+	if cost, ok := service.SnapshotCost(r); ok {
+		return cost, nil
+	}
+	if cost, ok := service.PublicIPCost(r); ok {
+		return cost, nil
+	}
+	if cost, ok := service.TrafficResourceCost(r); ok {
+		return cost, nil
+	}
+	if cost, ok := service.NetworkAttachmentCost(r.Type); ok {
+		return cost, nil
+	}
+	return entity.Cost{}, fmt.Errorf("no inventory price for %s", r.Type)
+}
+
+// callRESTJSON calls a REST JSON API, e.g. Resource Explorer, with creds
+func (c *Client) callRESTJSON(ctx context.Context, creds Credentials, region, service, path string, input any) ([]byte, error) {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf(c.regionalEndpoint, service, region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	sign(req, payload, creds, region, service, time.Now())
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, errors.New(jsonError(body, status))
+	}
+	return body, nil
+}
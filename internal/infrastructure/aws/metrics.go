@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+)
+
+// metricPeriod is the period of the datapoints read from CloudWatch
+const metricPeriod = 24 * time.Hour
+
+// metricDimension is a dimension of a CloudWatch metric and its value
+type metricDimension struct {
+	Name  string
+	Value string
+}
+
+// metricDatapoint is a daily datapoint of a CloudWatch metric
+type metricDatapoint struct {
+	Timestamp   time.Time `xml:"Timestamp"`
+	Average     float64   `xml:"Average"`
+	Sum         float64   `xml:"Sum"`
+	Minimum     float64   `xml:"Minimum"`
+	Maximum     float64   `xml:"Maximum"`
+	SampleCount float64   `xml:"SampleCount"`
+}
+
+type metricStatisticsResponse struct {
+	Datapoints []metricDatapoint `xml:"GetMetricStatisticsResult>Datapoints>member"`
+}
+
+// metricStatistics returns the daily datapoints of a statistic of a
+// CloudWatch metric between start and end. Metrics without datapoints, e.g.
+// of resources that had no activity, return none.
+func (c *Client) metricStatistics(ctx context.Context, creds Credentials, region, namespace, metric, statistic string, dimensions []metricDimension, start, end time.Time) ([]metricDatapoint, error) {
+	form := url.Values{
+		"Action":              {"GetMetricStatistics"},
+		"Version":             {"2010-08-01"},
+		"Namespace":           {namespace},
+		"MetricName":          {metric},
+		"StartTime":           {start.UTC().Format(time.RFC3339)},
+		"EndTime":             {end.UTC().Format(time.RFC3339)},
+		"Period":              {strconv.Itoa(int(metricPeriod.Seconds()))},
+		"Statistics.member.1": {statistic},
+	}
+	for i, d := range dimensions {
+		form.Set(fmt.Sprintf("Dimensions.member.%d.Name", i+1), d.Name)
+		form.Set(fmt.Sprintf("Dimensions.member.%d.Value", i+1), d.Value)
+	}
+
+	var body []byte
+	err := ratelimit.Call(ctx, func(ctx context.Context) error {
+		var err error
+		body, err = c.callQuery(ctx, creds, region, "monitoring", form)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cloudwatch GetMetricStatistics %s/%s: %w", namespace, metric, err)
+	}
+	var resp metricStatisticsResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("cloudwatch GetMetricStatistics %s/%s: invalid response: %w", namespace, metric, err)
+	}
+	return resp.Datapoints, nil
+}
+
+// aggregate reduces datapoints to a single value of statistic: the mean of
+// averages, the total of sums and sample counts, or the extreme of minimums
+// and maximums. Without datapoints it is 0.
+func aggregate(points []metricDatapoint, statistic string) float64 {
+	var value float64
+	for i, p := range points {
+		switch statistic {
+		case "Average":
+			value += p.Average / float64(len(points))
+		case "Sum":
+			value += p.Sum
+		case "SampleCount":
+			value += p.SampleCount
+		case "Minimum":
+			if i == 0 || p.Minimum < value {
+				value = p.Minimum
+			}
+		case "Maximum":
+			if i == 0 || p.Maximum > value {
+				value = p.Maximum
+			}
+		}
+	}
+	return value
+}
+
+// latestDatapoint returns the most recent of datapoints, which CloudWatch
+// does not return in order. ok is false when there are none.
+func latestDatapoint(points []metricDatapoint) (latest metricDatapoint, ok bool) {
+	for _, p := range points {
+		if !ok || p.Timestamp.After(latest.Timestamp) {
+			latest, ok = p, true
+		}
+	}
+	return latest, ok
+}
@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// DefaultMemberRoleName is the role assumed in member accounts when the
+// integration does not name one
+const DefaultMemberRoleName = "CloudSweepAuditRole"
+
+// OrganizationDiscoverer discovers the member accounts of an AWS
+// Organization. It assumes a role of the management account to list them,
+// and gives each account the ARN of the role to assume in it.
+type OrganizationDiscoverer struct {
+	client            *Client
+	managementRoleARN string
+	memberRoleName    string
+	externalID        string
+}
+
+var _ service.AccountDiscoverer = (*OrganizationDiscoverer)(nil)
+
+// NewOrganizationDiscoverer creates a new OrganizationDiscoverer. The
+// external ID, when set, is required by the management and member roles.
+func NewOrganizationDiscoverer(client *Client, managementRoleARN, memberRoleName, externalID string) (*OrganizationDiscoverer, error) {
+	if _, err := partition(managementRoleARN); err != nil {
+		return nil, err
+	}
+	if memberRoleName == "" {
+		memberRoleName = DefaultMemberRoleName
+	}
+	return &OrganizationDiscoverer{
+		client:            client,
+		managementRoleARN: managementRoleARN,
+		memberRoleName:    memberRoleName,
+		externalID:        externalID,
+	}, nil
+}
+
+// Discover implements service.AccountDiscoverer
+func (d *OrganizationDiscoverer) Discover(ctx context.Context) ([]service.DiscoveredAccount, error) {
+	creds, err := d.client.AssumeRole(ctx, d.managementRoleARN, d.externalID)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := d.client.ListAccounts(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	part, _ := partition(d.managementRoleARN)
+	out := make([]service.DiscoveredAccount, 0, len(accounts))
+	for _, a := range accounts {
+		credentials, err := json.Marshal(AccountCredentials{
+			RoleARN:    fmt.Sprintf("arn:%s:iam::%s:role/%s", part, a.ID, d.memberRoleName),
+			ExternalID: d.externalID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, service.DiscoveredAccount{
+			ID:          a.ID,
+			Name:        a.Name,
+			Active:      a.Status == "ACTIVE",
+			Credentials: credentials,
+		})
+	}
+	return out, nil
+}
+
+// partition returns the partition of a role ARN, e.g. "aws" or "aws-cn"
+func partition(roleARN string) (string, error) {
+	parts := strings.SplitN(roleARN, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || !strings.HasPrefix(parts[5], "role/") {
+		return "", errors.New("invalid role ARN, expected arn:aws:iam::<account>:role/<name>")
+	}
+	return parts[1], nil
+}
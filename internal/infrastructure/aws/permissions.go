@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// simulationBatch is the number of actions simulated per request, keeping
+// the request small
+const simulationBatch = 100
+
+// PolicySimulator checks the IAM actions granted to the credentials of an
+// account with the IAM policy simulator. The principal needs
+// iam:SimulatePrincipalPolicy on itself, and iam:GetUser or the role ARN
+// of the credentials to be identified. Resource-based policies and service
+// control policies are not simulated.
+type PolicySimulator struct {
+	client      *Client
+	credentials []byte
+
+	mu      sync.Mutex
+	granted map[string]bool // answers by action
+}
+
+var (
+	_ service.PermissionChecker       = (*PolicySimulator)(nil)
+	_ service.ScanPermissionsOverride = (*PolicySimulator)(nil)
+)
+
+// NewPolicySimulator creates a PolicySimulator for an account, called with
+// its credentials
+func NewPolicySimulator(client *Client, credentials []byte) *PolicySimulator {
+	return &PolicySimulator{client: client, credentials: credentials, granted: map[string]bool{}}
+}
+
+// MissingPermissions implements service.PermissionChecker
+func (s *PolicySimulator) MissingPermissions(ctx context.Context, permissions []string) ([]string, error) {
+	s.mu.Lock()
+	var unknown []string
+	for _, p := range permissions {
+		if _, ok := s.granted[p]; !ok {
+			unknown = append(unknown, p)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(unknown) > 0 {
+		creds, err := s.client.Resolve(ctx, s.credentials)
+		if err != nil {
+			return nil, err
+		}
+		principal, err := s.principal(ctx, creds)
+		if err != nil {
+			return nil, err
+		}
+		for start := 0; start < len(unknown); start += simulationBatch {
+			batch := unknown[start:min(start+simulationBatch, len(unknown))]
+			decisions, err := s.client.SimulatePrincipalPolicy(ctx, creds, principal, batch)
+			if err != nil {
+				return nil, err
+			}
+			s.mu.Lock()
+			for _, action := range batch {
+				s.granted[action] = decisions[action] == "allowed"
+			}
+			s.mu.Unlock()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var missing []string
+	for _, p := range permissions {
+		if !s.granted[p] {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+// ScanPermissions implements service.ScanPermissionsOverride for accounts
+// listed from an inventory
+func (s *PolicySimulator) ScanPermissions() ([]string, bool) {
+	var account AccountCredentials
+	if err := json.Unmarshal(s.credentials, &account); err != nil {
+		return nil, false
+	}
+	return ScanPermissions(account)
+}
+
+// principal returns the ARN of the user or role of the credentials
+func (s *PolicySimulator) principal(ctx context.Context, creds Credentials) (string, error) {
+	var account AccountCredentials
+	if err := json.Unmarshal(s.credentials, &account); err == nil && account.RoleARN != "" {
+		return account.RoleARN, nil
+	}
+	arn, err := s.client.CallerIdentity(ctx, creds)
+	if err != nil {
+		return "", err
+	}
+	return principalARN(arn), nil
+}
+
+// principalARN returns the IAM ARN of the principal of a caller identity:
+// the role of an assumed role session, whose path is unknown, or the user
+func principalARN(arn string) string {
+	// arn:aws:sts::123456789012:assumed-role/name/session
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[2] != "sts" || !strings.HasPrefix(parts[5], "assumed-role/") {
+		return arn
+	}
+	role := strings.Split(strings.TrimPrefix(parts[5], "assumed-role/"), "/")[0]
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], role)
+}
+
+type callerIdentityResponse struct {
+	Arn string `xml:"GetCallerIdentityResult>Arn"`
+}
+
+// CallerIdentity returns the ARN of the principal of creds
+func (c *Client) CallerIdentity(ctx context.Context, creds Credentials) (string, error) {
+	form := url.Values{"Action": {"GetCallerIdentity"}, "Version": {"2011-06-15"}}
+	body, err := c.postQuery(ctx, creds, c.stsEndpoint, c.region, "sts", form)
+	if err != nil {
+		return "", fmt.Errorf("sts GetCallerIdentity: %w", err)
+	}
+	var resp callerIdentityResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("sts GetCallerIdentity: invalid response: %w", err)
+	}
+	return resp.Arn, nil
+}
+
+type simulationResponse struct {
+	Results []struct {
+		Action   string `xml:"EvalActionName"`
+		Decision string `xml:"EvalDecision"`
+	} `xml:"SimulatePrincipalPolicyResult>EvaluationResults>member"`
+	IsTruncated bool   `xml:"SimulatePrincipalPolicyResult>IsTruncated"`
+	Marker      string `xml:"SimulatePrincipalPolicyResult>Marker"`
+}
+
+// SimulatePrincipalPolicy simulates IAM actions with the identity-based
+// policies of a user or role, on all resources. It returns the decision of
+// each action: "allowed", "explicitDeny" or "implicitDeny".
+func (c *Client) SimulatePrincipalPolicy(ctx context.Context, creds Credentials, principal string, actions []string) (map[string]string, error) {
+	decisions := make(map[string]string, len(actions))
+	marker := ""
+	for {
+		form := url.Values{
+			"Action":          {"SimulatePrincipalPolicy"},
+			"Version":         {"2010-05-08"},
+			"PolicySourceArn": {principal},
+		}
+		for i, action := range actions {
+			form.Set("ActionNames.member."+strconv.Itoa(i+1), action)
+		}
+		if marker != "" {
+			form.Set("Marker", marker)
+		}
+		// IAM is a global service served from us-east-1
+		body, err := c.postQuery(ctx, creds, c.iamEndpoint, "us-east-1", "iam", form)
+		if err != nil {
+			return nil, fmt.Errorf("iam SimulatePrincipalPolicy %s: %w", principal, err)
+		}
+		var resp simulationResponse
+		if err := xml.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("iam SimulatePrincipalPolicy %s: invalid response: %w", principal, err)
+		}
+		for _, r := range resp.Results {
+			decisions[r.Action] = r.Decision
+		}
+		if !resp.IsTruncated || resp.Marker == "" {
+			return decisions, nil
+		}
+		marker = resp.Marker
+	}
+}
@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+)
+
+// Route53Zones reads the records of the hosted zones of an account, public
+// and private, to find those pointing at its addresses, load balancers and
+// website buckets
+type Route53Zones struct {
+	client      *Client
+	credentials []byte
+}
+
+var (
+	_ service.DNSZoneReader = (*Route53Zones)(nil)
+	_ service.DNSInspector  = (*Route53Zones)(nil)
+)
+
+// NewRoute53Zones creates the reader of the hosted zones of an account,
+// called with its credentials
+func NewRoute53Zones(client *Client, credentials []byte) *Route53Zones {
+	return &Route53Zones{client: client, credentials: credentials}
+}
+
+type hostedZonesResponse struct {
+	Zones []struct {
+		ID   string `xml:"Id"` // e.g. /hostedzone/Z123
+		Name string `xml:"Name"`
+	} `xml:"HostedZones>HostedZone"`
+	IsTruncated bool   `xml:"IsTruncated"`
+	NextMarker  string `xml:"NextMarker"`
+}
+
+type recordSetsResponse struct {
+	RecordSets []struct {
+		Name        string   `xml:"Name"`
+		Type        string   `xml:"Type"`
+		Values      []string `xml:"ResourceRecords>ResourceRecord>Value"`
+		AliasTarget *struct {
+			DNSName string `xml:"DNSName"`
+		} `xml:"AliasTarget"`
+	} `xml:"ResourceRecordSets>ResourceRecordSet"`
+	IsTruncated          bool   `xml:"IsTruncated"`
+	NextRecordName       string `xml:"NextRecordName"`
+	NextRecordType       string `xml:"NextRecordType"`
+	NextRecordIdentifier string `xml:"NextRecordIdentifier"`
+}
+
+// Records implements service.DNSZoneReader
+func (z *Route53Zones) Records(ctx context.Context) ([]service.DNSRecord, error) {
+	creds, err := z.client.Resolve(ctx, z.credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []service.DNSRecord
+	query := url.Values{"maxitems": {"100"}}
+	for {
+		var zones hostedZonesResponse
+		if err := z.client.getRoute53(ctx, creds, "/2013-04-01/hostedzone", query, &zones); err != nil {
+			return nil, fmt.Errorf("route53 ListHostedZones: %w", err)
+		}
+		for _, zone := range zones.Zones {
+			found, err := z.zoneRecords(ctx, creds, strings.TrimPrefix(zone.ID, "/hostedzone/"), zone.Name)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, found...)
+		}
+		if !zones.IsTruncated {
+			return records, nil
+		}
+		query.Set("marker", zones.NextMarker)
+	}
+}
+
+// zoneRecords lists the records of a hosted zone. Aliases are typed ALIAS
+// with their target; those to S3 website endpoints target the bucket named
+// like the record, the endpoint only naming the region.
+func (z *Route53Zones) zoneRecords(ctx context.Context, creds Credentials, zoneID, zoneName string) ([]service.DNSRecord, error) {
+	var records []service.DNSRecord
+	path := "/2013-04-01/hostedzone/" + url.PathEscape(zoneID) + "/rrset"
+	query := url.Values{"maxitems": {"300"}}
+	for {
+		var page recordSetsResponse
+		if err := z.client.getRoute53(ctx, creds, path, query, &page); err != nil {
+			return nil, fmt.Errorf("route53 ListResourceRecordSets %s: %w", zoneName, err)
+		}
+		for _, set := range page.RecordSets {
+			record := service.DNSRecord{Zone: zoneName, Name: set.Name, Type: set.Type, Values: set.Values}
+			if set.AliasTarget != nil {
+				record.Type = "ALIAS"
+				record.Values = []string{aliasTarget(set.Name, set.AliasTarget.DNSName)}
+			}
+			records = append(records, record)
+		}
+		if !page.IsTruncated {
+			return records, nil
+		}
+		query.Set("name", page.NextRecordName)
+		query.Set("type", page.NextRecordType)
+		if page.NextRecordIdentifier != "" {
+			query.Set("identifier", page.NextRecordIdentifier)
+		} else {
+			query.Del("identifier")
+		}
+	}
+}
+
+// aliasTarget returns the hostname an alias record named name resolves to:
+// its target, or the website endpoint of the bucket named like the record
+// for aliases to the S3 website endpoint of a region, e.g.
+// s3-website-eu-west-1.amazonaws.com or s3-website.eu-west-3.amazonaws.com
+func aliasTarget(name, target string) string {
+	target = strings.TrimSuffix(strings.ToLower(target), ".")
+	for _, prefix := range []string{"s3-website-", "s3-website."} {
+		if region, ok := strings.CutPrefix(target, prefix); ok {
+			region = strings.TrimSuffix(region, ".amazonaws.com")
+			return fmt.Sprintf("%s.s3-website-%s.amazonaws.com", strings.TrimSuffix(strings.ToLower(name), "."), region)
+		}
+	}
+	return target
+}
+
+// DNSRecordsReferencing implements service.DNSInspector for the cleaners of
+// the account
+func (z *Route53Zones) DNSRecordsReferencing(ctx context.Context, resource *entity.Resource) ([]string, error) {
+	records, err := z.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return service.DNSRecordsPointingAt(resource, records), nil
+}
+
+// getRoute53 calls a read action of the Route 53 REST API, which is global
+// and signed for us-east-1, and decodes its XML response into out
+func (c *Client) getRoute53(ctx context.Context, creds Credentials, path string, query url.Values, out any) error {
+	return ratelimit.Call(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.route53Endpoint+path+"?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		sign(req, nil, creds, "us-east-1", "route53", time.Now())
+
+		body, status, err := c.do(req)
+		if err != nil {
+			return err
+		}
+		if status >= 300 {
+			var e queryErrorResponse
+			if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+				return fmt.Errorf("%s: %s", e.Code, e.Message)
+			}
+			return fmt.Errorf("returned %d", status)
+		}
+		if err := xml.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		return nil
+	})
+}
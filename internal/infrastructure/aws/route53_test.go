@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// route53Server serves a hosted zone whose records are split over two pages
+func route53Server(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Errorf("%s is not signed", r.URL)
+		}
+		switch {
+		case r.URL.Path == "/2013-04-01/hostedzone":
+			w.Write([]byte(`<ListHostedZonesResponse>
+				<HostedZones><HostedZone><Id>/hostedzone/Z1</Id><Name>example.com.</Name></HostedZone></HostedZones>
+				<IsTruncated>false</IsTruncated>
+			</ListHostedZonesResponse>`))
+		case r.URL.Path == "/2013-04-01/hostedzone/Z1/rrset" && r.URL.Query().Get("name") == "":
+			w.Write([]byte(`<ListResourceRecordSetsResponse>
+				<ResourceRecordSets>
+					<ResourceRecordSet><Name>api.example.com.</Name><Type>A</Type>
+						<ResourceRecords><ResourceRecord><Value>203.0.113.10</Value></ResourceRecord></ResourceRecords>
+					</ResourceRecordSet>
+				</ResourceRecordSets>
+				<IsTruncated>true</IsTruncated>
+				<NextRecordName>www.example.com.</NextRecordName><NextRecordType>A</NextRecordType>
+			</ListResourceRecordSetsResponse>`))
+		case r.URL.Path == "/2013-04-01/hostedzone/Z1/rrset" && r.URL.Query().Get("name") == "www.example.com.":
+			w.Write([]byte(`<ListResourceRecordSetsResponse>
+				<ResourceRecordSets>
+					<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type>
+						<AliasTarget><HostedZoneId>Z3</HostedZoneId><DNSName>s3-website.eu-west-3.amazonaws.com.</DNSName></AliasTarget>
+					</ResourceRecordSet>
+				</ResourceRecordSets>
+				<IsTruncated>false</IsTruncated>
+			</ListResourceRecordSetsResponse>`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRoute53ZonesRecords(t *testing.T) {
+	srv := route53Server(t)
+	defer srv.Close()
+	client := &Client{http: srv.Client(), route53Endpoint: srv.URL}
+	zones := NewRoute53Zones(client, []byte(`{"access_key_id":"AKID","secret_access_key":"secret"}`))
+
+	records, err := zones.Records(context.Background())
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	want := []service.DNSRecord{
+		{Zone: "example.com.", Name: "api.example.com.", Type: "A", Values: []string{"203.0.113.10"}},
+		{Zone: "example.com.", Name: "www.example.com.", Type: "ALIAS", Values: []string{"www.example.com.s3-website-eu-west-3.amazonaws.com"}},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Records() = %+v, want %+v", records, want)
+	}
+}
+
+func TestRoute53ZonesDNSRecordsReferencing(t *testing.T) {
+	srv := route53Server(t)
+	defer srv.Close()
+	client := &Client{http: srv.Client(), route53Endpoint: srv.URL}
+	zones := NewRoute53Zones(client, []byte(`{"access_key_id":"AKID","secret_access_key":"secret"}`))
+
+	address := func(ip string) *entity.Resource {
+		r := entity.NewResource(uuid.Nil, entity.CloudProviderAWS, entity.ResourceTypeElasticIP, "eipalloc-1", "eu-west-3", "")
+		r.Metadata[service.IPMetadataAddress] = ip
+		return r
+	}
+	bucket := func(name string) *entity.Resource {
+		r := entity.NewResource(uuid.Nil, entity.CloudProviderAWS, entity.ResourceTypeS3Bucket, name, "eu-west-3", "")
+		r.Metadata[service.StorageMetadataWebsite] = name + ".s3-website-eu-west-3.amazonaws.com"
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		resource *entity.Resource
+		want     []string
+	}{
+		{"referenced address", address("203.0.113.10"), []string{"api.example.com A"}},
+		{"unreferenced address", address("203.0.113.99"), nil},
+		{"bucket aliased by its name", bucket("www.example.com"), []string{"www.example.com ALIAS"}},
+		{"unreferenced bucket", bucket("assets.example.com"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := zones.DNSRecordsReferencing(context.Background(), tt.resource)
+			if err != nil {
+				t.Fatalf("DNSRecordsReferencing() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DNSRecordsReferencing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
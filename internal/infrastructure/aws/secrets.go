@@ -0,0 +1,141 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// Settings may reference secrets of Secrets Manager, e.g.
+// secret-ref://aws-sm/cloudsweep/db#password, and parameters of the SSM
+// Parameter Store, e.g. secret-ref://ssm/cloudsweep/db-password
+func init() {
+	config.RegisterSecretBackend("aws-sm", newSecretBackend(secretsManager))
+	config.RegisterSecretBackend("ssm", newSecretBackend(parameterStore))
+}
+
+type secretService int
+
+const (
+	secretsManager secretService = iota
+	parameterStore
+)
+
+// secretBackend reads the secrets referenced by settings with the
+// CloudSweep credentials
+type secretBackend struct {
+	client  *Client
+	service secretService
+}
+
+func newSecretBackend(service secretService) config.SecretBackendFactory {
+	return func(cfg *config.Config) (config.SecretBackend, error) {
+		if cfg.AWS.AccessKeyID == "" || cfg.AWS.SecretAccessKey == "" {
+			return nil, ErrNoPlatformCredentials
+		}
+		if strings.HasPrefix(cfg.AWS.AccessKeyID, config.SecretRefPrefix) || strings.HasPrefix(cfg.AWS.SecretAccessKey, config.SecretRefPrefix) {
+			return nil, errors.New("aws: the AWS credentials cannot be read from AWS")
+		}
+		return &secretBackend{client: NewClient(cfg.AWS), service: service}, nil
+	}
+}
+
+// Fetch implements config.SecretBackend
+func (b *secretBackend) Fetch(ctx context.Context, ref config.SecretRef) (string, error) {
+	if b.service == parameterStore {
+		return b.client.GetParameter(ctx, ref.Path)
+	}
+	return b.client.GetSecretValue(ctx, ref.Path)
+}
+
+// GetSecretValue returns the current value of a Secrets Manager secret,
+// named by its name or ARN
+func (c *Client) GetSecretValue(ctx context.Context, secretID string) (string, error) {
+	// Secrets of other regions are named by their ARN
+	region := c.region
+	if parts := strings.Split(secretID, ":"); len(parts) > 3 && parts[0] == "arn" {
+		region = parts[3]
+	}
+
+	body, err := c.callJSON(ctx, region, "secretsmanager", "secretsmanager.GetSecretValue", map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", fmt.Errorf("secretsmanager GetSecretValue %s: %w", secretID, err)
+	}
+	var resp struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("secretsmanager GetSecretValue %s: invalid response: %w", secretID, err)
+	}
+	if resp.SecretString == "" && resp.SecretBinary != "" {
+		value, err := base64.StdEncoding.DecodeString(resp.SecretBinary)
+		if err != nil {
+			return "", fmt.Errorf("secretsmanager GetSecretValue %s: invalid binary secret: %w", secretID, err)
+		}
+		return string(value), nil
+	}
+	return resp.SecretString, nil
+}
+
+// GetParameter returns the decrypted value of an SSM parameter. Names of
+// hierarchical parameters may omit their leading slash.
+func (c *Client) GetParameter(ctx context.Context, name string) (string, error) {
+	if strings.Contains(name, "/") && !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+
+	body, err := c.callJSON(ctx, c.region, "ssm", "AmazonSSM.GetParameter", map[string]any{"Name": name, "WithDecryption": true})
+	if err != nil {
+		return "", fmt.Errorf("ssm GetParameter %s: %w", name, err)
+	}
+	var resp struct {
+		Parameter struct {
+			Value string `json:"Value"`
+		} `json:"Parameter"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("ssm GetParameter %s: invalid response: %w", name, err)
+	}
+	return resp.Parameter.Value, nil
+}
+
+// callJSON calls an action of a JSON protocol API with the CloudSweep
+// credentials
+func (c *Client) callJSON(ctx context.Context, region, service, target string, input any) ([]byte, error) {
+	return c.callJSONAs(ctx, c.platform, "1.1", region, service, target, input)
+}
+
+// callJSONAs calls an action of a JSON protocol API of the given version,
+// "1.0" or "1.1", with creds
+func (c *Client) callJSONAs(ctx context.Context, creds Credentials, version, region, service, target string, input any) ([]byte, error) {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf(c.regionalEndpoint, service, region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-"+version)
+	req.Header.Set("X-Amz-Target", target)
+	sign(req, payload, creds, region, service, time.Now())
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, errors.New(jsonError(body, status))
+	}
+	return body, nil
+}
@@ -0,0 +1,18 @@
+package aws
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/sigv4"
+)
+
+// sign adds the SigV4 Authorization header to req, whose body is payload.
+// Every header already set on req is signed along with the host.
+func sign(req *http.Request, payload []byte, creds Credentials, region, service string, now time.Time) {
+	sigv4.Sign(req, payload, sigv4.Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+	}, region, service, now)
+}
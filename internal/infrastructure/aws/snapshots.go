@@ -0,0 +1,251 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+)
+
+// ec2Version is the version of the EC2 query API
+const ec2Version = "2016-11-15"
+
+// Tags the lifecycle tools set on the snapshots they create
+var snapshotManagerTags = map[string]string{
+	"aws:backup:source-resource":  "aws-backup",
+	"aws:dlm:lifecycle-policy-id": "dlm",
+}
+
+// ec2Tag is a tag of an EC2 resource
+type ec2Tag struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+// ec2Snapshot is a snapshot returned by DescribeSnapshots
+type ec2Snapshot struct {
+	SnapshotID  string    `xml:"snapshotId"`
+	VolumeID    string    `xml:"volumeId"`
+	VolumeSize  int       `xml:"volumeSize"`
+	StartTime   time.Time `xml:"startTime"`
+	StorageTier string    `xml:"storageTier"`
+	Tags        []ec2Tag  `xml:"tagSet>item"`
+}
+
+// ec2Image is an image returned by DescribeImages, with the snapshots of its
+// block devices
+type ec2Image struct {
+	ImageID   string   `xml:"imageId"`
+	Snapshots []string `xml:"blockDeviceMapping>item>ebs>snapshotId"`
+}
+
+// describeEC2 calls a paginated Describe action of the EC2 query API with
+// params, passing the body of each page to decode, which returns its next
+// token
+func (c *Client) describeEC2(ctx context.Context, creds Credentials, region, action string, params url.Values, decode func(body []byte) (next string, err error)) error {
+	form := url.Values{"Action": {action}, "Version": {ec2Version}}
+	for k, v := range params {
+		form[k] = v
+	}
+	for {
+		var body []byte
+		err := ratelimit.Call(ctx, func(ctx context.Context) error {
+			var err error
+			body, err = c.callQuery(ctx, creds, region, "ec2", form)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("ec2 %s: %w", action, err)
+		}
+		next, err := decode(body)
+		if err != nil {
+			return fmt.Errorf("ec2 %s: invalid response: %w", action, err)
+		}
+		if next == "" {
+			return nil
+		}
+		form.Set("NextToken", next)
+	}
+}
+
+// ownedSnapshots lists the snapshots the account owns in a region
+func (c *Client) ownedSnapshots(ctx context.Context, creds Credentials, region string) ([]ec2Snapshot, error) {
+	var snapshots []ec2Snapshot
+	params := url.Values{"Owner.1": {"self"}, "MaxResults": {"1000"}}
+	err := c.describeEC2(ctx, creds, region, "DescribeSnapshots", params, func(body []byte) (string, error) {
+		var page struct {
+			Snapshots []ec2Snapshot `xml:"snapshotSet>item"`
+			NextToken string        `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return "", err
+		}
+		snapshots = append(snapshots, page.Snapshots...)
+		return page.NextToken, nil
+	})
+	return snapshots, err
+}
+
+// volumeIDs returns the IDs of the volumes of a region
+func (c *Client) volumeIDs(ctx context.Context, creds Credentials, region string) (map[string]bool, error) {
+	volumes := map[string]bool{}
+	params := url.Values{"MaxResults": {"500"}}
+	err := c.describeEC2(ctx, creds, region, "DescribeVolumes", params, func(body []byte) (string, error) {
+		var page struct {
+			VolumeIDs []string `xml:"volumeSet>item>volumeId"`
+			NextToken string   `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return "", err
+		}
+		for _, id := range page.VolumeIDs {
+			volumes[id] = true
+		}
+		return page.NextToken, nil
+	})
+	return volumes, err
+}
+
+// ownedImages lists the images the account owns in a region
+func (c *Client) ownedImages(ctx context.Context, creds Credentials, region string) ([]ec2Image, error) {
+	var images []ec2Image
+	params := url.Values{"Owner.1": {"self"}, "MaxResults": {"1000"}}
+	err := c.describeEC2(ctx, creds, region, "DescribeImages", params, func(body []byte) (string, error) {
+		var page struct {
+			Images    []ec2Image `xml:"imagesSet>item"`
+			NextToken string     `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return "", err
+		}
+		images = append(images, page.Images...)
+		return page.NextToken, nil
+	})
+	return images, err
+}
+
+// usedImageIDs returns the images the instances of a region that are not
+// terminated were launched from, and those their launch templates launch
+func (c *Client) usedImageIDs(ctx context.Context, creds Credentials, region string) (map[string]bool, error) {
+	images := map[string]bool{}
+	params := url.Values{
+		"Filter.1.Name":    {"instance-state-name"},
+		"Filter.1.Value.1": {"pending"},
+		"Filter.1.Value.2": {"running"},
+		"Filter.1.Value.3": {"stopping"},
+		"Filter.1.Value.4": {"stopped"},
+		"MaxResults":       {"1000"},
+	}
+	err := c.describeEC2(ctx, creds, region, "DescribeInstances", params, func(body []byte) (string, error) {
+		var page struct {
+			ImageIDs  []string `xml:"reservationSet>item>instancesSet>item>imageId"`
+			NextToken string   `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return "", err
+		}
+		for _, id := range page.ImageIDs {
+			images[id] = true
+		}
+		return page.NextToken, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The latest and default versions of launch templates launch the next
+	// instances
+	params = url.Values{
+		"LaunchTemplateVersion.1": {"$Latest"},
+		"LaunchTemplateVersion.2": {"$Default"},
+		"MaxResults":              {"200"},
+	}
+	err = c.describeEC2(ctx, creds, region, "DescribeLaunchTemplateVersions", params, func(body []byte) (string, error) {
+		var page struct {
+			ImageIDs  []string `xml:"launchTemplateVersionSet>item>launchTemplateData>imageId"`
+			NextToken string   `xml:"nextToken"`
+		}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return "", err
+		}
+		for _, id := range page.ImageIDs {
+			images[id] = true
+		}
+		return page.NextToken, nil
+	})
+	return images, err
+}
+
+// setSnapshotMetadata fills in the metadata the snapshot detector needs
+// from the EC2 API, which Resource Explorer does not report: the source
+// volume, whether it still exists, the images backed by the snapshot and
+// whether an instance or launch template uses them, the lifecycle tool
+// owning it, its size, tier and creation date. The chains of the region are
+// then annotated.
+func (d *InventoryDetector) setSnapshotMetadata(ctx context.Context, creds Credentials, region string, resources []*entity.Resource) error {
+	snapshots, err := d.client.ownedSnapshots(ctx, creds, region)
+	if err != nil {
+		return err
+	}
+	volumes, err := d.client.volumeIDs(ctx, creds, region)
+	if err != nil {
+		return err
+	}
+	images, err := d.client.ownedImages(ctx, creds, region)
+	if err != nil {
+		return err
+	}
+	launched, err := d.client.usedImageIDs(ctx, creds, region)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]ec2Snapshot, len(snapshots))
+	for _, s := range snapshots {
+		byID[s.SnapshotID] = s
+	}
+	imagesBySnapshot := map[string][]string{}
+	for _, image := range images {
+		for _, snapshot := range image.Snapshots {
+			imagesBySnapshot[snapshot] = append(imagesBySnapshot[snapshot], image.ImageID)
+		}
+	}
+
+	for _, r := range resources {
+		s, ok := byID[r.ResourceID]
+		if !ok {
+			continue
+		}
+		r.CreatedAt = s.StartTime
+		r.Metadata[service.VolumeMetadataSizeGB] = float64(s.VolumeSize)
+		if s.StorageTier != "" {
+			r.Metadata[service.SnapshotMetadataTier] = s.StorageTier
+		}
+		// Snapshots copied or created from an image have the placeholder
+		// vol-ffffffff as their source
+		if s.VolumeID != "" && s.VolumeID != "vol-ffffffff" {
+			r.Metadata[service.SnapshotMetadataSourceVolume] = s.VolumeID
+			r.Metadata[service.SnapshotMetadataSourceExists] = volumes[s.VolumeID]
+		}
+		for _, tag := range s.Tags {
+			if tool, ok := snapshotManagerTags[tag.Key]; ok {
+				r.Metadata[service.SnapshotMetadataManagedBy] = tool
+			}
+		}
+		if ids := imagesBySnapshot[r.ResourceID]; len(ids) > 0 {
+			inUse := false
+			for _, id := range ids {
+				inUse = inUse || launched[id]
+			}
+			r.Metadata[service.SnapshotMetadataImageIDs] = ids
+			r.Metadata[service.SnapshotMetadataImageInUse] = inUse
+		}
+	}
+	service.AnnotateSnapshotChains(resources)
+	return nil
+}
@@ -0,0 +1,127 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+)
+
+// changeRetention is how long Resource Graph keeps the changes of resources
+const changeRetention = 14 * 24 * time.Hour
+
+// changesQuery selects the resources of a subscription changed since a
+// time from the change history of Resource Graph
+const changesQuery = `resourcechanges
+| extend changeTime = todatetime(properties.changeAttributes.timestamp)
+| where changeTime > datetime(%s)
+| project targetResourceId = tostring(properties.targetResourceId), changeType = tostring(properties.changeType), changeTime
+| order by changeTime asc`
+
+// subscriptionCredentials are the credentials of an Azure cloud account
+type subscriptionCredentials struct {
+	TenantID       string `json:"tenant_id"`
+	ClientID       string `json:"client_id"`
+	ClientSecret   string `json:"client_secret"`
+	SubscriptionID string `json:"subscription_id"`
+}
+
+func parseCredentials(credentials []byte) (subscriptionCredentials, error) {
+	var creds subscriptionCredentials
+	if err := json.Unmarshal(credentials, &creds); err != nil {
+		return creds, fmt.Errorf("invalid azure credentials: %w", err)
+	}
+	if creds.TenantID == "" || creds.ClientID == "" || creds.ClientSecret == "" || creds.SubscriptionID == "" {
+		return creds, errors.New("invalid azure credentials: tenant_id, client_id, client_secret and subscription_id are required")
+	}
+	return creds, nil
+}
+
+// ResourceGraphChangeFeed reads the resources of a subscription changed
+// since a time from the change history of Resource Graph, which goes back
+// 14 days. Changes are not located, so each region gets the changes of the
+// whole subscription.
+type ResourceGraphChangeFeed struct {
+	client *Client
+	creds  subscriptionCredentials
+	now    func() time.Time
+}
+
+var _ service.ChangeFeed = (*ResourceGraphChangeFeed)(nil)
+
+// NewResourceGraphChangeFeed creates a ResourceGraphChangeFeed from the
+// credentials of an Azure cloud account
+func NewResourceGraphChangeFeed(client *Client, credentials []byte) (*ResourceGraphChangeFeed, error) {
+	creds, err := parseCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &ResourceGraphChangeFeed{client: client, creds: creds, now: time.Now}, nil
+}
+
+// Changes implements service.ChangeFeed
+func (f *ResourceGraphChangeFeed) Changes(ctx context.Context, region string, since time.Time) (service.ResourceChanges, error) {
+	if f.now().Sub(since) >= changeRetention {
+		return service.ResourceChanges{}, service.ErrChangesUnavailable
+	}
+	token, err := f.client.Token(ctx, f.creds.TenantID, f.creds.ClientID, f.creds.ClientSecret)
+	if err != nil {
+		return service.ResourceChanges{}, err
+	}
+
+	u := f.client.managementEndpoint + "/providers/Microsoft.ResourceGraph/resources?api-version=2022-10-01"
+	query := fmt.Sprintf(changesQuery, since.UTC().Format(time.RFC3339))
+	options := map[string]any{"resultFormat": "objectArray"}
+	// The last change of each resource tells whether it still exists
+	deleted := map[string]bool{}
+	var order []string
+	for {
+		payload, err := json.Marshal(map[string]any{
+			"subscriptions": []string{f.creds.SubscriptionID},
+			"query":         query,
+			"options":       options,
+		})
+		if err != nil {
+			return service.ResourceChanges{}, err
+		}
+		var page struct {
+			Data []struct {
+				TargetResourceID string `json:"targetResourceId"`
+				ChangeType       string `json:"changeType"`
+			} `json:"data"`
+			SkipToken string `json:"$skipToken"`
+		}
+		err = ratelimit.Call(ctx, func(ctx context.Context) error {
+			return f.client.postJSON(ctx, token, u, payload, &page)
+		})
+		if err != nil {
+			return service.ResourceChanges{}, fmt.Errorf("azure resource graph changes of subscription %s: %w", f.creds.SubscriptionID, err)
+		}
+		for _, c := range page.Data {
+			id := strings.ToLower(c.TargetResourceID)
+			if _, seen := deleted[id]; !seen {
+				order = append(order, c.TargetResourceID)
+			}
+			deleted[id] = c.ChangeType == "Delete"
+		}
+		if page.SkipToken == "" {
+			break
+		}
+		options["$skipToken"] = page.SkipToken
+	}
+
+	var changes service.ResourceChanges
+	for _, id := range order {
+		if deleted[strings.ToLower(id)] {
+			changes.Deleted = append(changes.Deleted, id)
+		} else {
+			changes.Changed = append(changes.Changed, id)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,186 @@
+// Package azure calls the Azure Resource Manager APIs CloudSweep needs to
+// discover the subscriptions of a management group, to read their costs
+// from Cost Management and to check the permissions granted on them.
+// Service principals are authenticated with the client credentials grant,
+// so no SDK is required.
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the Azure APIs. It is safe for concurrent use.
+type Client struct {
+	http *http.Client
+
+	loginEndpoint      string
+	managementEndpoint string
+}
+
+// NewClient creates a new Client for the Azure public cloud
+func NewClient() *Client {
+	return &Client{
+		http:               &http.Client{Timeout: 30 * time.Second},
+		loginEndpoint:      "https://login.microsoftonline.com",
+		managementEndpoint: "https://management.azure.com",
+	}
+}
+
+// Token returns an access token of a service principal for Azure Resource
+// Manager
+func (c *Client) Token(ctx context.Context, tenantID, clientID, clientSecret string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"scope":         {c.managementEndpoint + "/.default"},
+	}
+	u := fmt.Sprintf("%s/%s/oauth2/v2.0/token", c.loginEndpoint, url.PathEscape(tenantID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("azure token: %w", err)
+	}
+	var resp struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("azure token returned %d", status)
+	}
+	if status >= 300 || resp.AccessToken == "" {
+		// Descriptions continue with trace IDs on the following lines
+		desc, _, _ := strings.Cut(resp.ErrorDescription, "\r\n")
+		return "", fmt.Errorf("azure token: %s: %s", resp.Error, desc)
+	}
+	return resp.AccessToken, nil
+}
+
+// Subscription is an Azure subscription
+type Subscription struct {
+	ID    string
+	Name  string
+	State string // Enabled, Warned, PastDue, Disabled or Deleted
+}
+
+// ManagementGroupSubscriptions lists the subscriptions under a management
+// group, including those of its child groups. States are those of the
+// subscriptions the token can read; the others have an empty state.
+func (c *Client) ManagementGroupSubscriptions(ctx context.Context, token, groupID string) ([]Subscription, error) {
+	states := map[string]string{}
+	next := c.managementEndpoint + "/subscriptions?api-version=2020-01-01"
+	for next != "" {
+		var page struct {
+			Value []struct {
+				SubscriptionID string `json:"subscriptionId"`
+				State          string `json:"state"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := c.getJSON(ctx, token, next, &page); err != nil {
+			return nil, fmt.Errorf("azure list subscriptions: %w", err)
+		}
+		for _, s := range page.Value {
+			states[s.SubscriptionID] = s.State
+		}
+		next = page.NextLink
+	}
+
+	var subscriptions []Subscription
+	next = fmt.Sprintf("%s/providers/Microsoft.Management/managementGroups/%s/descendants?api-version=2020-05-01",
+		c.managementEndpoint, url.PathEscape(groupID))
+	for next != "" {
+		var page struct {
+			Value []struct {
+				Name       string `json:"name"`
+				Type       string `json:"type"`
+				Properties struct {
+					DisplayName string `json:"displayName"`
+				} `json:"properties"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := c.getJSON(ctx, token, next, &page); err != nil {
+			return nil, fmt.Errorf("azure list management group %s: %w", groupID, err)
+		}
+		for _, d := range page.Value {
+			if !strings.EqualFold(d.Type, "Microsoft.Management/managementGroups/subscriptions") {
+				continue
+			}
+			subscriptions = append(subscriptions, Subscription{
+				ID:    d.Name,
+				Name:  d.Properties.DisplayName,
+				State: states[d.Name],
+			})
+		}
+		next = page.NextLink
+	}
+	return subscriptions, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, token, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	return c.doJSON(req, token, out)
+}
+
+func (c *Client) postJSON(ctx context.Context, token, u string, payload []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.doJSON(req, token, out)
+}
+
+func (c *Client) doJSON(req *http.Request, token string, out any) error {
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		var e struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Error.Code != "" {
+			return fmt.Errorf("%s: %s", e.Error.Code, e.Error.Message)
+		}
+		return fmt.Errorf("returned %d", status)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (c *Client) do(req *http.Request) ([]byte, int, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
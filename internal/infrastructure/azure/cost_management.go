@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// CostManagement reads the amortized costs of the resources of a
+// subscription from Azure Cost Management. The service principal needs
+// Cost Management Reader on the subscription.
+type CostManagement struct {
+	client         *Client
+	subscriptionID string
+	tenantID       string
+	clientID       string
+	clientSecret   string
+}
+
+var _ service.BillingSource = (*CostManagement)(nil)
+
+// NewCostManagement creates a CostManagement from the credentials of an
+// Azure cloud account
+func NewCostManagement(client *Client, credentials []byte) (*CostManagement, error) {
+	var creds struct {
+		TenantID       string `json:"tenant_id"`
+		ClientID       string `json:"client_id"`
+		ClientSecret   string `json:"client_secret"`
+		SubscriptionID string `json:"subscription_id"`
+	}
+	if err := json.Unmarshal(credentials, &creds); err != nil {
+		return nil, fmt.Errorf("invalid azure credentials: %w", err)
+	}
+	if creds.TenantID == "" || creds.ClientID == "" || creds.ClientSecret == "" || creds.SubscriptionID == "" {
+		return nil, errors.New("invalid azure credentials: tenant_id, client_id, client_secret and subscription_id are required")
+	}
+	return &CostManagement{
+		client:         client,
+		subscriptionID: creds.SubscriptionID,
+		tenantID:       creds.TenantID,
+		clientID:       creds.ClientID,
+		clientSecret:   creds.ClientSecret,
+	}, nil
+}
+
+// ResourceCosts implements service.BillingSource
+func (s *CostManagement) ResourceCosts(ctx context.Context, start, end time.Time) ([]service.BilledCost, error) {
+	token, err := s.client.Token(ctx, s.tenantID, s.clientID, s.clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := json.Marshal(map[string]any{
+		"type":      "AmortizedCost",
+		"timeframe": "Custom",
+		"timePeriod": map[string]string{
+			"from": start.UTC().Format(time.RFC3339),
+			// The end of the period is inclusive
+			"to": end.UTC().Add(-time.Second).Format(time.RFC3339),
+		},
+		"dataset": map[string]any{
+			"granularity": "None",
+			"aggregation": map[string]any{
+				"totalCost": map[string]string{"name": "Cost", "function": "Sum"},
+			},
+			"grouping": []map[string]string{{"type": "Dimension", "name": "ResourceId"}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var costs []service.BilledCost
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.CostManagement/query?api-version=2023-03-01",
+		s.client.managementEndpoint, url.PathEscape(s.subscriptionID))
+	for next != "" {
+		var page struct {
+			Properties struct {
+				NextLink string `json:"nextLink"`
+				Columns  []struct {
+					Name string `json:"name"`
+				} `json:"columns"`
+				Rows [][]any `json:"rows"`
+			} `json:"properties"`
+		}
+		if err := s.client.postJSON(ctx, token, next, query, &page); err != nil {
+			return nil, fmt.Errorf("azure cost query of %s: %w", s.subscriptionID, err)
+		}
+
+		columns := map[string]int{}
+		for i, c := range page.Properties.Columns {
+			columns[strings.ToLower(c.Name)] = i
+		}
+		costColumn, ok1 := columns["cost"]
+		resourceColumn, ok2 := columns["resourceid"]
+		currencyColumn, ok3 := columns["currency"]
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("azure cost query of %s: unexpected columns", s.subscriptionID)
+		}
+		for _, row := range page.Properties.Rows {
+			if len(row) <= costColumn || len(row) <= resourceColumn || len(row) <= currencyColumn {
+				continue
+			}
+			amount, _ := row[costColumn].(float64)
+			resource, _ := row[resourceColumn].(string)
+			currency, _ := row[currencyColumn].(string)
+			if resource == "" {
+				continue
+			}
+			costs = append(costs, service.BilledCost{ResourceID: resource, Amount: amount, Currency: currency})
+		}
+		next = page.Properties.NextLink
+	}
+	return costs, nil
+}
@@ -0,0 +1,80 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// ManagementGroupDiscoverer discovers the subscriptions under an Azure
+// management group. Every subscription is scanned with the service
+// principal of the integration, which needs Reader on the group.
+type ManagementGroupDiscoverer struct {
+	client       *Client
+	groupID      string
+	tenantID     string
+	clientID     string
+	clientSecret string
+}
+
+var _ service.AccountDiscoverer = (*ManagementGroupDiscoverer)(nil)
+
+// NewManagementGroupDiscoverer creates a new ManagementGroupDiscoverer
+func NewManagementGroupDiscoverer(client *Client, groupID, tenantID, clientID, clientSecret string) (*ManagementGroupDiscoverer, error) {
+	if groupID == "" || tenantID == "" || clientID == "" || clientSecret == "" {
+		return nil, errors.New("management_group_id, tenant_id, client_id and client_secret are required")
+	}
+	return &ManagementGroupDiscoverer{
+		client:       client,
+		groupID:      groupID,
+		tenantID:     tenantID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}, nil
+}
+
+// Discover implements service.AccountDiscoverer
+func (d *ManagementGroupDiscoverer) Discover(ctx context.Context) ([]service.DiscoveredAccount, error) {
+	token, err := d.client.Token(ctx, d.tenantID, d.clientID, d.clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := d.client.ManagementGroupSubscriptions(ctx, token, d.groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]service.DiscoveredAccount, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		// Same fields as the credentials of an Azure cloud account
+		credentials, err := json.Marshal(map[string]string{
+			"tenant_id":       d.tenantID,
+			"client_id":       d.clientID,
+			"client_secret":   d.clientSecret,
+			"subscription_id": s.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, service.DiscoveredAccount{
+			ID:          s.ID,
+			Name:        s.Name,
+			Active:      active(s.State),
+			Credentials: credentials,
+		})
+	}
+	return out, nil
+}
+
+// active reports whether a subscription in a state can be scanned: warned
+// and past due subscriptions still run resources, disabled ones do not.
+// Subscriptions the service principal cannot read have no state.
+func active(state string) bool {
+	switch state {
+	case "Enabled", "Warned", "PastDue":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// RoleAssignmentCheck checks the operations granted to the service
+// principal of a subscription by its role assignments, as listed by the
+// permissions API of Azure RBAC. Deny assignments are not taken into
+// account.
+type RoleAssignmentCheck struct {
+	client         *Client
+	subscriptionID string
+	tenantID       string
+	clientID       string
+	clientSecret   string
+
+	mu    sync.Mutex
+	rules []permissionRule // nil until listed
+}
+
+var _ service.PermissionChecker = (*RoleAssignmentCheck)(nil)
+
+// permissionRule is a permission of a role assigned to the principal: the
+// operations of Actions are granted, except those of NotActions. Both may
+// contain wildcards.
+type permissionRule struct {
+	Actions    []string `json:"actions"`
+	NotActions []string `json:"notActions"`
+}
+
+// NewRoleAssignmentCheck creates a RoleAssignmentCheck from the credentials
+// of an Azure cloud account
+func NewRoleAssignmentCheck(client *Client, credentials []byte) (*RoleAssignmentCheck, error) {
+	creds, err := parseCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &RoleAssignmentCheck{
+		client:         client,
+		subscriptionID: creds.SubscriptionID,
+		tenantID:       creds.TenantID,
+		clientID:       creds.ClientID,
+		clientSecret:   creds.ClientSecret,
+	}, nil
+}
+
+// MissingPermissions implements service.PermissionChecker
+func (s *RoleAssignmentCheck) MissingPermissions(ctx context.Context, permissions []string) ([]string, error) {
+	rules, err := s.permissionRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, p := range permissions {
+		if !grants(rules, p) {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+// permissionRules lists the permissions of the principal on the
+// subscription once
+func (s *RoleAssignmentCheck) permissionRules(ctx context.Context) ([]permissionRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rules != nil {
+		return s.rules, nil
+	}
+
+	token, err := s.client.Token(ctx, s.tenantID, s.clientID, s.clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	rules := []permissionRule{}
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Authorization/permissions?api-version=2022-04-01",
+		s.client.managementEndpoint, url.PathEscape(s.subscriptionID))
+	for next != "" {
+		var page struct {
+			Value    []permissionRule `json:"value"`
+			NextLink string           `json:"nextLink"`
+		}
+		if err := s.client.getJSON(ctx, token, next, &page); err != nil {
+			return nil, fmt.Errorf("azure list permissions of subscription %s: %w", s.subscriptionID, err)
+		}
+		rules = append(rules, page.Value...)
+		next = page.NextLink
+	}
+	s.rules = rules
+	return rules, nil
+}
+
+// grants returns true when a rule grants an operation without excluding it
+func grants(rules []permissionRule, operation string) bool {
+	for _, r := range rules {
+		if matchesAny(r.Actions, operation) && !matchesAny(r.NotActions, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny returns true when an operation matches one of the patterns,
+// where "*" matches any characters. Operations are case-insensitive.
+func matchesAny(patterns []string, operation string) bool {
+	for _, p := range patterns {
+		expr := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*") + "$"
+		if ok, _ := regexp.MatchString(expr, operation); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+// Package billing reconciles the estimated costs of resources with the
+// amortized costs billed by their provider, for the cloud accounts whose
+// pricing source is not the estimate.
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reconciler replaces the monthly cost of resources with the one billed
+// over the configured window
+type Reconciler struct {
+	db    *gorm.DB
+	cfg   *config.Config
+	costs *service.CostNormalizer
+}
+
+// NewReconciler creates a new Reconciler
+func NewReconciler(db *gorm.DB, cfg *config.Config) *Reconciler {
+	return &Reconciler{db: db, cfg: cfg, costs: service.NewCostNormalizer(cfg.Costs.ExchangeRates)}
+}
+
+// Reconcile reconciles the resources of a cloud account with its billing
+// data and returns the number of resources updated. Resources missing from
+// the bill keep their estimate. The outcome is recorded on the account.
+func (r *Reconciler) Reconcile(ctx context.Context, accountID uuid.UUID, now time.Time) (int, error) {
+	var account model.CloudAccount
+	if err := r.db.WithContext(ctx).First(&account, "id = ?", accountID).Error; err != nil {
+		return 0, fmt.Errorf("failed to get cloud account %s: %w", accountID, err)
+	}
+	if account.PricingSource == entity.PricingSourceEstimate {
+		return 0, nil
+	}
+
+	updated, err := r.reconcile(ctx, &account, now)
+	updates := map[string]any{"pricing_synced_at": now, "pricing_error": ""}
+	if err != nil {
+		updates["pricing_error"] = err.Error()
+	}
+	if uerr := r.db.WithContext(ctx).Model(&account).Updates(updates).Error; uerr != nil {
+		return updated, errors.Join(err, fmt.Errorf("failed to update cloud account %s: %w", account.ID, uerr))
+	}
+	return updated, err
+}
+
+// ReconcileAll reconciles every active account with a billing pricing
+// source and returns the number of resources updated. A failing account
+// does not prevent the others from being reconciled.
+func (r *Reconciler) ReconcileAll(ctx context.Context, now time.Time) (int, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&model.CloudAccount{}).
+		Where("is_active = ? AND pricing_source <> ?", true, entity.PricingSourceEstimate).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load cloud accounts: %w", err)
+	}
+
+	total := 0
+	var errs []error
+	for _, id := range ids {
+		updated, err := r.Reconcile(ctx, id, now)
+		total += updated
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, account *model.CloudAccount, now time.Time) (int, error) {
+	p, ok := provider.Lookup(account.Provider)
+	if !ok {
+		return 0, fmt.Errorf("unknown provider %s", account.Provider)
+	}
+	pricing, ok := p.PricingSource(account.PricingSource)
+	if !ok {
+		return 0, fmt.Errorf("%s does not support the %s pricing source", account.Provider, account.PricingSource)
+	}
+	source, err := pricing.New(account.AccountID, account.Credentials, account.PricingSettingValues(), r.cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	// Billing data of the current day is incomplete
+	window := r.cfg.Billing.Window
+	if window < 24*time.Hour {
+		return 0, fmt.Errorf("billing window %s is shorter than a day", window)
+	}
+	end := time.Date(now.UTC().Year(), now.UTC().Month(), now.UTC().Day(), 0, 0, 0, 0, time.UTC)
+	start := end.Add(-window)
+	billed, err := source.ResourceCosts(ctx, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	var resources []model.Resource
+	err = r.db.WithContext(ctx).
+		Where("organization_id = ? AND provider = ? AND account_id = ? AND status NOT IN ?",
+			account.OrganizationID, account.Provider, account.AccountID, entity.UntrackedResourceStatuses).
+		Find(&resources).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load resources: %w", err)
+	}
+	match := newMatcher(resources)
+
+	// A resource billed in several currencies sums their monthly USD costs
+	type total struct {
+		monthly float64
+		billed  []map[string]any
+	}
+	totals := map[int]*total{}
+	var errs []error
+	for _, b := range billed {
+		i, ok := match(b.ResourceID)
+		if !ok {
+			continue
+		}
+		normalized, err := r.costs.Normalize(entity.Cost{
+			Amount:   b.Amount * entity.HoursPerMonth / window.Hours(),
+			Currency: b.Currency,
+			Period:   entity.BillingPeriodMonthly,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", b.ResourceID, err))
+			continue
+		}
+		t, ok := totals[i]
+		if !ok {
+			t = &total{}
+			totals[i] = t
+		}
+		t.monthly += normalized.MonthlyUSD
+		t.billed = append(t.billed, map[string]any{"amount": b.Amount, "currency": normalized.Original.Currency})
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []*entity.ResourceEvent
+		for i, t := range totals {
+			res := &resources[i]
+			metadata := res.Metadata
+			if metadata == nil {
+				metadata = model.JSONB{}
+			}
+			metadata[service.CostMetadataKey] = map[string]any{
+				"source":      account.PricingSource,
+				"billed":      t.billed,
+				"from":        start.Format(time.RFC3339),
+				"to":          end.Format(time.RFC3339),
+				"monthly_usd": t.monthly,
+			}
+			err := tx.Model(&model.Resource{}).Where("id = ?", res.ID).
+				Updates(map[string]any{"monthly_cost": t.monthly, "metadata": metadata}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update resource %s: %w", res.ResourceID, err)
+			}
+			// Costs are stored to the cent
+			if math.Round(res.MonthlyCost*100) != math.Round(t.monthly*100) {
+				events = append(events, entity.NewResourceEvent(res.OrganizationID, res.ID, entity.ResourceEventCostChanged, entity.ResourceEventActorBilling, map[string]any{
+					"from":   res.MonthlyCost,
+					"to":     t.monthly,
+					"source": account.PricingSource,
+				}))
+			}
+		}
+		return database.RecordResourceEvents(tx, events...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(totals), errors.Join(errs...)
+}
+
+// newMatcher returns a function finding the resource a bill line is for.
+// Bills name resources by ID, ARN or full path depending on the service:
+// the whole ID is compared first, then its last segment when it identifies
+// a single resource.
+func newMatcher(resources []model.Resource) func(billedID string) (int, bool) {
+	byID := make(map[string]int, len(resources))
+	bySegment := make(map[string][]int, len(resources))
+	for i, r := range resources {
+		byID[strings.ToLower(r.ResourceID)] = i
+		segment := lastSegment(r.ResourceID)
+		bySegment[segment] = append(bySegment[segment], i)
+	}
+	return func(billedID string) (int, bool) {
+		if i, ok := byID[strings.ToLower(billedID)]; ok {
+			return i, true
+		}
+		if matches := bySegment[lastSegment(billedID)]; len(matches) == 1 {
+			return matches[0], true
+		}
+		return 0, false
+	}
+}
+
+// lastSegment returns the last segment of a path or ARN, lower-cased
+func lastSegment(id string) string {
+	if i := strings.LastIndexAny(id, "/:"); i >= 0 {
+		id = id[i+1:]
+	}
+	return strings.ToLower(id)
+}
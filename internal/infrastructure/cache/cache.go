@@ -0,0 +1,145 @@
+// Package cache stores computed values, such as dashboard aggregates, in
+// Redis so that every API instance shares them. Values are scoped to an
+// organization and dropped together when its resources or scans change.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix prefixes the keys of cached values and of organization
+// generations
+const keyPrefix = "cloudsweep:cache:"
+
+// Stats reports the use of a kind of cached value
+type Stats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Errors   int64   `json:"errors"` // Redis failures, served from the database
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+type counters struct {
+	hits, misses, errors atomic.Int64
+}
+
+// Cache stores values in Redis with a TTL. Each organization has a
+// generation, part of the keys of its values: invalidating the organization
+// bumps it, which leaves the values of the previous generation to expire
+// unread. A nil Cache caches nothing, so that caching stays optional for
+// callers.
+type Cache struct {
+	rdb *redis.Client
+
+	mu    sync.Mutex
+	stats map[string]*counters
+}
+
+// New creates a Cache over a Redis client
+func New(rdb *redis.Client) *Cache {
+	return &Cache{rdb: rdb, stats: map[string]*counters{}}
+}
+
+// Fetch returns the value cached for the organization under name and params
+// (e.g. the filters of a request), or the value returned by load, cached for
+// ttl. Redis failures fall back to load: they slow requests down but do not
+// fail them.
+func Fetch[T any](ctx context.Context, c *Cache, orgID, name, params string, ttl time.Duration, load func() (T, error)) (T, error) {
+	if c == nil || ttl <= 0 {
+		return load()
+	}
+	stats := c.counters(name)
+
+	key, err := c.key(ctx, orgID, name, params)
+	if err == nil {
+		var raw []byte
+		if raw, err = c.rdb.Get(ctx, key).Bytes(); err == nil {
+			var value T
+			if err = json.Unmarshal(raw, &value); err == nil {
+				stats.hits.Add(1)
+				return value, nil
+			}
+		}
+	}
+	if errors.Is(err, redis.Nil) {
+		stats.misses.Add(1)
+	} else {
+		stats.errors.Add(1)
+	}
+
+	value, err := load()
+	if err != nil || key == "" {
+		return value, err
+	}
+	if raw, err := json.Marshal(value); err == nil {
+		if err := c.rdb.Set(ctx, key, raw, ttl).Err(); err != nil {
+			stats.errors.Add(1)
+		}
+	}
+	return value, nil
+}
+
+// Invalidate drops the values cached for the organization. Failures are only
+// logged since they must not fail the write that invalidates: the values
+// then expire with their TTL.
+func (c *Cache) Invalidate(ctx context.Context, orgID string) {
+	if c == nil || orgID == "" {
+		return
+	}
+	if err := c.rdb.Incr(ctx, keyPrefix+orgID+":gen").Err(); err != nil {
+		log.Printf("Failed to invalidate cache of org %s: %v", orgID, err)
+	}
+}
+
+// Stats returns the stats of this process by kind of cached value
+func (c *Cache) Stats() map[string]Stats {
+	if c == nil {
+		return map[string]Stats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make(map[string]Stats, len(c.stats))
+	for name, s := range c.stats {
+		out := Stats{Hits: s.hits.Load(), Misses: s.misses.Load(), Errors: s.errors.Load()}
+		if total := out.Hits + out.Misses; total > 0 {
+			out.HitRatio = float64(out.Hits) / float64(total)
+		}
+		stats[name] = out
+	}
+	return stats
+}
+
+// key returns the key of a value in the current generation of the
+// organization
+func (c *Cache) key(ctx context.Context, orgID, name, params string) (string, error) {
+	gen, err := c.rdb.Get(ctx, keyPrefix+orgID+":gen").Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(params))
+	return fmt.Sprintf("%s%s:%d:%s:%s", keyPrefix, orgID, gen, name, hex.EncodeToString(sum[:8])), nil
+}
+
+func (c *Cache) counters(name string) *counters {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.stats[name]
+	if !ok {
+		s = &counters{}
+		c.stats[name] = s
+	}
+	return s
+}
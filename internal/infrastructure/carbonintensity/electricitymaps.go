@@ -0,0 +1,73 @@
+package carbonintensity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// Client reads carbon intensity forecasts from the Electricity Maps API
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates an Electricity Maps client. It returns nil when no token
+// is configured, in which case schedules use the regions' typical day.
+func NewClient(cfg config.CarbonIntensityConfig) *Client {
+	if cfg.Token == "" {
+		return nil
+	}
+	return &Client{
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		token:   cfg.Token,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+var _ service.CarbonIntensitySource = (*Client)(nil)
+
+type forecastResponse struct {
+	Zone     string `json:"zone"`
+	Forecast []struct {
+		CarbonIntensity float64   `json:"carbonIntensity"`
+		Datetime        time.Time `json:"datetime"`
+	} `json:"forecast"`
+}
+
+// Forecast returns the hourly carbon intensity forecast of a zone
+func (c *Client) Forecast(ctx context.Context, zone string) ([]service.IntensityPoint, error) {
+	path := "/carbon-intensity/forecast?" + url.Values{"zone": {zone}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("auth-token", c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("electricity maps forecast of %s returned %d", zone, resp.StatusCode)
+	}
+
+	var body forecastResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid electricity maps forecast: %w", err)
+	}
+	points := make([]service.IntensityPoint, 0, len(body.Forecast))
+	for _, f := range body.Forecast {
+		points = append(points, service.IntensityPoint{Time: f.Datetime.UTC(), Intensity: f.CarbonIntensity})
+	}
+	return points, nil
+}
@@ -0,0 +1,84 @@
+package ci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PRState is the state of a pull or merge request
+type PRState string
+
+const (
+	PRStateOpen   PRState = "open"
+	PRStateClosed PRState = "closed"
+	PRStateMerged PRState = "merged"
+)
+
+// PullRequest is a pull request (GitHub) or merge request (GitLab)
+type PullRequest struct {
+	Number   int
+	Branch   string
+	State    PRState
+	ClosedAt *time.Time // merge or close time, nil while open
+	URL      string
+}
+
+// ErrNotFound is returned when a repository has no such pull request
+var ErrNotFound = errors.New("pull request not found")
+
+// Client reads pull requests from a CI provider
+type Client interface {
+	// PullRequest returns a pull request of a repository by number
+	PullRequest(ctx context.Context, repo string, number int) (*PullRequest, error)
+
+	// BranchPullRequest returns the most recently updated pull request
+	// opened from a branch
+	BranchPullRequest(ctx context.Context, repo, branch string) (*PullRequest, error)
+}
+
+// FileChange is a single-file commit proposed through a pull request
+type FileChange struct {
+	Base    string // branch the pull request targets
+	Branch  string // branch created for the change
+	Path    string
+	Content []byte
+	Title   string // commit message and pull request title
+	Body    string
+}
+
+// Committer proposes changes to a repository through pull requests. Its
+// token needs write access to the repository.
+type Committer interface {
+	// ListFiles returns the paths of the files directly in a directory
+	ListFiles(ctx context.Context, repo, ref, dir string) ([]string, error)
+
+	// ReadFile returns the content of a file
+	ReadFile(ctx context.Context, repo, ref, path string) ([]byte, error)
+
+	// ProposeChange commits a change on a new branch and opens a pull
+	// request from it
+	ProposeChange(ctx context.Context, repo string, change FileChange) (*PullRequest, error)
+}
+
+// StatusError is returned when the provider API answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %d", e.URL, e.StatusCode)
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode, URL: resp.Request.URL.Redacted()}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package ci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// Detector flags preview environments whose pull request was closed or
+// merged more than the grace period ago
+type Detector struct {
+	db      *gorm.DB
+	cfg     config.CIConfig
+	clients map[string]Client
+}
+
+// NewDetector creates a Detector with the GitHub and GitLab clients
+func NewDetector(db *gorm.DB, cfg config.CIConfig) *Detector {
+	return &Detector{
+		db:  db,
+		cfg: cfg,
+		clients: map[string]Client{
+			"github": NewGitHubClient(cfg.GitHub),
+			"gitlab": NewGitLabClient(cfg.GitLab),
+		},
+	}
+}
+
+// previewRef identifies the pull request a resource was deployed from
+type previewRef struct {
+	provider string
+	repo     string
+	number   int
+	branch   string
+}
+
+func (r previewRef) key() string {
+	return fmt.Sprintf("%s:%s#%d@%s", r.provider, r.repo, r.number, r.branch)
+}
+
+// Detect checks the pull request of every active resource tagged with a
+// repository and a pull request or branch, and marks the resource unused
+// once its pull request has been closed for longer than the grace period.
+// It returns the number of resources flagged.
+func (d *Detector) Detect(ctx context.Context, now time.Time) (int, error) {
+	var resources []model.Resource
+	err := d.db.WithContext(ctx).
+		Where("status = ?", string(entity.ResourceStatusActive)).
+		Where("jsonb_exists_any(tags, ?::text[])", pgArray(d.cfg.RepoTagKeys)).
+		Find(&resources).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load tagged resources: %w", err)
+	}
+
+	// Preview environments usually span several resources of the same
+	// pull request, which is looked up once per run
+	pulls := make(map[string]*PullRequest)
+	var (
+		flagged int
+		errs    []error
+	)
+	for i := range resources {
+		res := &resources[i]
+		ref, ok := d.previewRef(res.Tags)
+		if !ok {
+			continue
+		}
+
+		pr, seen := pulls[ref.key()]
+		if !seen {
+			pr, err = d.pullRequest(ctx, ref)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				errs = append(errs, fmt.Errorf("failed to check %s for resource %s: %w", ref.repo, res.ID, err))
+				continue
+			}
+			pulls[ref.key()] = pr
+		}
+		if pr == nil || pr.ClosedAt == nil || now.Sub(*pr.ClosedAt) < d.cfg.GracePeriod {
+			continue
+		}
+
+		metadata := res.Metadata
+		if metadata == nil {
+			metadata = model.JSONB{}
+		}
+		metadata["preview_environment"] = map[string]any{
+			"provider":     ref.provider,
+			"repository":   ref.repo,
+			"pull_request": pr.Number,
+			"branch":       pr.Branch,
+			"state":        string(pr.State),
+			"closed_at":    pr.ClosedAt.UTC(),
+			"url":          pr.URL,
+		}
+		from := res.Status
+		err = d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := tx.Model(res).Updates(map[string]any{
+				"status":   string(entity.ResourceStatusUnused),
+				"metadata": metadata,
+			}).Error
+			if err != nil || from == string(entity.ResourceStatusUnused) {
+				return err
+			}
+			return database.RecordResourceEvents(tx, entity.NewResourceEvent(res.OrganizationID, res.ID, entity.ResourceEventStatusChanged, entity.ResourceEventActorCI, map[string]any{
+				"from":   from,
+				"to":     string(entity.ResourceStatusUnused),
+				"reason": "preview environment of a closed pull request",
+			}))
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to flag resource %s: %w", res.ID, err))
+			continue
+		}
+		flagged++
+	}
+	return flagged, errors.Join(errs...)
+}
+
+func (d *Detector) pullRequest(ctx context.Context, ref previewRef) (*PullRequest, error) {
+	client := d.clients[ref.provider]
+	if ref.number > 0 {
+		return client.PullRequest(ctx, ref.repo, ref.number)
+	}
+	return client.BranchPullRequest(ctx, ref.repo, ref.branch)
+}
+
+// previewRef reads the repository and pull request of a resource from its
+// tags. Repositories may be prefixed with their host, e.g.
+// "gitlab.com/group/project"; others use the default provider.
+func (d *Detector) previewRef(tags model.JSONB) (previewRef, bool) {
+	repo := firstTag(tags, d.cfg.RepoTagKeys)
+	if repo == "" {
+		return previewRef{}, false
+	}
+	repo = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(repo, "https://"), "http://"), ".git")
+
+	ref := previewRef{provider: d.cfg.DefaultProvider, repo: strings.Trim(repo, "/")}
+	if host, path, ok := strings.Cut(ref.repo, "/"); ok && strings.Contains(host, ".") {
+		switch {
+		case strings.Contains(host, "github"):
+			ref.provider = "github"
+		case strings.Contains(host, "gitlab"):
+			ref.provider = "gitlab"
+		}
+		ref.repo = path
+	}
+	if _, ok := d.clients[ref.provider]; !ok || !strings.Contains(ref.repo, "/") {
+		return previewRef{}, false
+	}
+
+	if pr := strings.TrimPrefix(firstTag(tags, d.cfg.PRTagKeys), "#"); pr != "" {
+		n, err := strconv.Atoi(pr)
+		if err != nil || n <= 0 {
+			return previewRef{}, false
+		}
+		ref.number = n
+		return ref, true
+	}
+	ref.branch = firstTag(tags, d.cfg.BranchTagKeys)
+	return ref, ref.branch != ""
+}
+
+func firstTag(tags model.JSONB, keys []string) string {
+	for _, k := range keys {
+		if s, ok := tags[k].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// pgArray formats keys as a PostgreSQL text array literal for jsonb_exists_any
+func pgArray(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(k) + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
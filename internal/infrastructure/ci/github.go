@@ -0,0 +1,211 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// GitHubClient reads and opens pull requests with the GitHub REST API
+type GitHubClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewGitHubClient creates a GitHub client. BaseURL points to
+// https://api.github.com or to a GitHub Enterprise API.
+func NewGitHubClient(cfg config.CIProviderConfig) *GitHubClient {
+	return &GitHubClient{
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		token:   cfg.Token,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type githubPull struct {
+	Number   int        `json:"number"`
+	State    string     `json:"state"`
+	HTMLURL  string     `json:"html_url"`
+	ClosedAt *time.Time `json:"closed_at"`
+	MergedAt *time.Time `json:"merged_at"`
+	Head     struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+func (p *githubPull) toPullRequest() *PullRequest {
+	pr := &PullRequest{Number: p.Number, Branch: p.Head.Ref, State: PRStateOpen, URL: p.HTMLURL}
+	switch {
+	case p.MergedAt != nil:
+		pr.State, pr.ClosedAt = PRStateMerged, p.MergedAt
+	case p.State == "closed":
+		pr.State, pr.ClosedAt = PRStateClosed, p.ClosedAt
+	}
+	return pr
+}
+
+// PullRequest returns a pull request by number
+func (c *GitHubClient) PullRequest(ctx context.Context, repo string, number int) (*PullRequest, error) {
+	var pull githubPull
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", repo, number), &pull); err != nil {
+		return nil, err
+	}
+	return pull.toPullRequest(), nil
+}
+
+// BranchPullRequest returns the most recently updated pull request of a branch
+func (c *GitHubClient) BranchPullRequest(ctx context.Context, repo, branch string) (*PullRequest, error) {
+	owner, _, _ := strings.Cut(repo, "/")
+	query := url.Values{
+		"head":      {owner + ":" + branch},
+		"state":     {"all"},
+		"sort":      {"updated"},
+		"direction": {"desc"},
+		"per_page":  {"1"},
+	}
+	var pulls []githubPull
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/pulls?%s", repo, query.Encode()), &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, ErrNotFound
+	}
+	return pulls[0].toPullRequest(), nil
+}
+
+// ListFiles returns the paths of the files directly in a directory
+func (c *GitHubClient) ListFiles(ctx context.Context, repo, ref, dir string) ([]string, error) {
+	var entries []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+	}
+	if err := c.get(ctx, c.contentsPath(repo, dir, ref), &entries); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type == "file" {
+			files = append(files, e.Path)
+		}
+	}
+	return files, nil
+}
+
+// ReadFile returns the content of a file
+func (c *GitHubClient) ReadFile(ctx context.Context, repo, ref, path string) ([]byte, error) {
+	var file githubContent
+	if err := c.get(ctx, c.contentsPath(repo, path, ref), &file); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+}
+
+// ProposeChange creates a branch from the base branch, updates the file on
+// it and opens a pull request
+func (c *GitHubClient) ProposeChange(ctx context.Context, repo string, change FileChange) (*PullRequest, error) {
+	var base struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/git/ref/heads/%s", repo, change.Base), &base); err != nil {
+		return nil, fmt.Errorf("failed to read branch %s: %w", change.Base, err)
+	}
+	var file githubContent
+	if err := c.get(ctx, c.contentsPath(repo, change.Path, change.Base), &file); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", change.Path, err)
+	}
+
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/git/refs", repo), map[string]any{
+		"ref": "refs/heads/" + change.Branch,
+		"sha": base.Object.SHA,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch %s: %w", change.Branch, err)
+	}
+	err = c.do(ctx, http.MethodPut, c.contentsPath(repo, change.Path, ""), map[string]any{
+		"message": change.Title,
+		"content": base64.StdEncoding.EncodeToString(change.Content),
+		"sha":     file.SHA,
+		"branch":  change.Branch,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit %s: %w", change.Path, err)
+	}
+
+	var pull githubPull
+	err = c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", repo), map[string]any{
+		"title": change.Title,
+		"body":  change.Body,
+		"head":  change.Branch,
+		"base":  change.Base,
+	}, &pull)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return pull.toPullRequest(), nil
+}
+
+type githubContent struct {
+	SHA     string `json:"sha"`
+	Content string `json:"content"`
+}
+
+func (c *GitHubClient) contentsPath(repo, path, ref string) string {
+	p := fmt.Sprintf("/repos/%s/contents/%s", repo, (&url.URL{Path: strings.Trim(path, "/")}).EscapedPath())
+	if ref != "" {
+		p += "?ref=" + url.QueryEscape(ref)
+	}
+	return p
+}
+
+func (c *GitHubClient) get(ctx context.Context, path string, out any) error {
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+// do sends a request with an optional JSON body and decodes the response
+// into out when not nil
+func (c *GitHubClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
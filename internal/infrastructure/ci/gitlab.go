@@ -0,0 +1,187 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// GitLabClient reads and opens merge requests with the GitLab REST API
+type GitLabClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewGitLabClient creates a GitLab client. BaseURL points to the v4 API,
+// e.g. https://gitlab.com/api/v4.
+func NewGitLabClient(cfg config.CIProviderConfig) *GitLabClient {
+	return &GitLabClient{
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		token:   cfg.Token,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type gitlabMergeRequest struct {
+	IID          int        `json:"iid"`
+	State        string     `json:"state"`
+	WebURL       string     `json:"web_url"`
+	SourceBranch string     `json:"source_branch"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	MergedAt     *time.Time `json:"merged_at"`
+}
+
+func (m *gitlabMergeRequest) toPullRequest() *PullRequest {
+	pr := &PullRequest{Number: m.IID, Branch: m.SourceBranch, State: PRStateOpen, URL: m.WebURL}
+	switch m.State {
+	case "merged":
+		pr.State, pr.ClosedAt = PRStateMerged, m.MergedAt
+	case "closed":
+		pr.State, pr.ClosedAt = PRStateClosed, m.ClosedAt
+	}
+	return pr
+}
+
+// PullRequest returns a merge request by its project-level ID
+func (c *GitLabClient) PullRequest(ctx context.Context, repo string, number int) (*PullRequest, error) {
+	var mr gitlabMergeRequest
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/merge_requests/%d", url.PathEscape(repo), number), &mr); err != nil {
+		return nil, err
+	}
+	return mr.toPullRequest(), nil
+}
+
+// BranchPullRequest returns the most recently updated merge request of a branch
+func (c *GitLabClient) BranchPullRequest(ctx context.Context, repo, branch string) (*PullRequest, error) {
+	query := url.Values{
+		"source_branch": {branch},
+		"order_by":      {"updated_at"},
+		"sort":          {"desc"},
+		"per_page":      {"1"},
+	}
+	var mrs []gitlabMergeRequest
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/merge_requests?%s", url.PathEscape(repo), query.Encode()), &mrs); err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, ErrNotFound
+	}
+	return mrs[0].toPullRequest(), nil
+}
+
+// ListFiles returns the paths of the files directly in a directory
+func (c *GitLabClient) ListFiles(ctx context.Context, repo, ref, dir string) ([]string, error) {
+	query := url.Values{"ref": {ref}, "per_page": {"100"}}
+	if dir = strings.Trim(dir, "/"); dir != "" {
+		query.Set("path", dir)
+	}
+	var entries []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/repository/tree?%s", url.PathEscape(repo), query.Encode()), &entries); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type == "blob" {
+			files = append(files, e.Path)
+		}
+	}
+	return files, nil
+}
+
+// ReadFile returns the content of a file
+func (c *GitLabClient) ReadFile(ctx context.Context, repo, ref, path string) ([]byte, error) {
+	var file struct {
+		Content string `json:"content"`
+	}
+	query := url.Values{"ref": {ref}}
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/repository/files/%s?%s", url.PathEscape(repo), url.PathEscape(strings.Trim(path, "/")), query.Encode()), &file); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(file.Content)
+}
+
+// ProposeChange commits the file on a new branch started from the base
+// branch and opens a merge request, deleting the branch once merged
+func (c *GitLabClient) ProposeChange(ctx context.Context, repo string, change FileChange) (*PullRequest, error) {
+	project := url.PathEscape(repo)
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/repository/commits", project), map[string]any{
+		"branch":         change.Branch,
+		"start_branch":   change.Base,
+		"commit_message": change.Title,
+		"actions": []map[string]any{{
+			"action":    "update",
+			"file_path": strings.Trim(change.Path, "/"),
+			"content":   base64.StdEncoding.EncodeToString(change.Content),
+			"encoding":  "base64",
+		}},
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit %s: %w", change.Path, err)
+	}
+
+	var mr gitlabMergeRequest
+	err = c.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests", project), map[string]any{
+		"source_branch":        change.Branch,
+		"target_branch":        change.Base,
+		"title":                change.Title,
+		"description":          change.Body,
+		"remove_source_branch": true,
+	}, &mr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open merge request: %w", err)
+	}
+	return mr.toPullRequest(), nil
+}
+
+func (c *GitLabClient) get(ctx context.Context, path string, out any) error {
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+// do sends a request with an optional JSON body and decodes the response
+// into out when not nil
+func (c *GitLabClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
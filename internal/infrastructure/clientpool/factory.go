@@ -0,0 +1,70 @@
+package clientpool
+
+import (
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// ScannerFactory reuses scanners created by the wrapped factory for the same
+// provider and credentials
+type ScannerFactory struct {
+	next service.CloudScannerFactory
+	pool *Pool
+}
+
+// NewScannerFactory wraps a CloudScannerFactory with the pool
+func NewScannerFactory(next service.CloudScannerFactory, pool *Pool) *ScannerFactory {
+	return &ScannerFactory{next: next, pool: pool}
+}
+
+// Create returns a pooled scanner, creating one on first use
+func (f *ScannerFactory) Create(provider entity.CloudProvider, credentials []byte) (service.CloudScanner, error) {
+	key := Key{Kind: "scanner", Provider: provider, Account: AccountKey(credentials)}
+	client, err := f.pool.Get(key, func() (any, error) {
+		return f.next.Create(provider, credentials)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.(service.CloudScanner), nil
+}
+
+// CreateWithCustomTypes returns a pooled scanner without custom types.
+// Scanners of custom types are created for each call, since organizations
+// may change their definitions between scans.
+func (f *ScannerFactory) CreateWithCustomTypes(provider entity.CloudProvider, credentials []byte, types []*entity.CustomResourceType) (service.CloudScanner, error) {
+	if len(types) == 0 {
+		return f.Create(provider, credentials)
+	}
+	next, ok := f.next.(service.CustomScannerFactory)
+	if !ok {
+		return nil, fmt.Errorf("custom resource types are not supported for %s", provider)
+	}
+	return next.CreateWithCustomTypes(provider, credentials, types)
+}
+
+// CleanerFactory reuses cleaners created by the wrapped factory for the same
+// provider and credentials
+type CleanerFactory struct {
+	next service.ResourceCleanerFactory
+	pool *Pool
+}
+
+// NewCleanerFactory wraps a ResourceCleanerFactory with the pool
+func NewCleanerFactory(next service.ResourceCleanerFactory, pool *Pool) *CleanerFactory {
+	return &CleanerFactory{next: next, pool: pool}
+}
+
+// Create returns a pooled cleaner, creating one on first use
+func (f *CleanerFactory) Create(provider entity.CloudProvider, credentials []byte) (service.ResourceCleaner, error) {
+	key := Key{Kind: "cleaner", Provider: provider, Account: AccountKey(credentials)}
+	client, err := f.pool.Get(key, func() (any, error) {
+		return f.next.Create(provider, credentials)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.(service.ResourceCleaner), nil
+}
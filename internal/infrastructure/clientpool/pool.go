@@ -0,0 +1,173 @@
+package clientpool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshWindow is how long before credential expiry a client is rebuilt,
+// so a task never starts with credentials about to lapse mid-scan
+const refreshWindow = 5 * time.Minute
+
+// Key identifies a pooled client. Kind distinguishes clients built for the
+// same account (e.g. "scanner", "cleaner", "ec2"); Region is empty for
+// clients that are not bound to a single region.
+type Key struct {
+	Kind     string
+	Provider entity.CloudProvider
+	Account  string
+	Region   string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s/%s/%s", k.Kind, k.Provider, k.Account, k.Region)
+}
+
+// AccountKey derives a stable, non-reversible account identifier from raw
+// credentials so they are never kept as map keys
+func AccountKey(credentials []byte) string {
+	sum := sha256.Sum256(credentials)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Expirer is implemented by clients holding temporary credentials (STS,
+// OAuth tokens). The pool rebuilds them shortly before they expire.
+type Expirer interface {
+	CredentialsExpireAt() time.Time
+}
+
+// Stats reports pool usage
+type Stats struct {
+	Size      int   `json:"size"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Refreshes int64 `json:"refreshes"`
+	Evictions int64 `json:"evictions"`
+}
+
+type entry struct {
+	client    any
+	expiresAt time.Time
+	lastUsed  time.Time
+}
+
+// Pool caches provider clients across tasks so authentication is done once
+// per account and region instead of once per task. Pooled clients are
+// shared between concurrent tasks and must be safe for concurrent use.
+type Pool struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[Key]*entry
+	group   singleflight.Group
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	refreshes atomic.Int64
+	evictions atomic.Int64
+}
+
+// New creates a pool keeping clients for at most ttl and holding at most
+// maxEntries clients, evicting the least recently used beyond that
+func New(ttl time.Duration, maxEntries int) *Pool {
+	return &Pool{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[Key]*entry),
+	}
+}
+
+// Get returns the pooled client for key, calling create when there is none
+// or the pooled one has expired. Concurrent misses on the same key share a
+// single create call.
+func (p *Pool) Get(key Key, create func() (any, error)) (any, error) {
+	now := time.Now()
+
+	p.mu.Lock()
+	e, ok := p.entries[key]
+	if ok && now.Before(e.expiresAt) {
+		e.lastUsed = now
+		p.mu.Unlock()
+		p.hits.Add(1)
+		return e.client, nil
+	}
+	p.mu.Unlock()
+
+	if ok {
+		p.refreshes.Add(1)
+	} else {
+		p.misses.Add(1)
+	}
+
+	client, err, _ := p.group.Do(key.String(), func() (any, error) {
+		client, err := create()
+		if err != nil {
+			return nil, err
+		}
+		p.store(key, client)
+		return client, nil
+	})
+	return client, err
+}
+
+// Invalidate drops the client for key, e.g. after an authentication error
+func (p *Pool) Invalidate(key Key) {
+	p.mu.Lock()
+	delete(p.entries, key)
+	p.mu.Unlock()
+}
+
+// Stats returns a snapshot of the pool counters
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	size := len(p.entries)
+	p.mu.Unlock()
+
+	return Stats{
+		Size:      size,
+		Hits:      p.hits.Load(),
+		Misses:    p.misses.Load(),
+		Refreshes: p.refreshes.Load(),
+		Evictions: p.evictions.Load(),
+	}
+}
+
+func (p *Pool) store(key Key, client any) {
+	now := time.Now()
+	expiresAt := now.Add(p.ttl)
+	if exp, ok := client.(Expirer); ok {
+		if t := exp.CredentialsExpireAt(); !t.IsZero() && t.Add(-refreshWindow).Before(expiresAt) {
+			expiresAt = t.Add(-refreshWindow)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.entries[key]; !exists && p.maxEntries > 0 && len(p.entries) >= p.maxEntries {
+		p.evictOldest()
+	}
+	p.entries[key] = &entry{client: client, expiresAt: expiresAt, lastUsed: now}
+}
+
+// evictOldest removes the least recently used entry. The pool is small
+// enough that a linear scan is cheaper than maintaining a list.
+func (p *Pool) evictOldest() {
+	var oldestKey Key
+	var oldest time.Time
+	for k, e := range p.entries {
+		if oldest.IsZero() || e.lastUsed.Before(oldest) {
+			oldestKey, oldest = k, e.lastUsed
+		}
+	}
+	delete(p.entries, oldestKey)
+	p.evictions.Add(1)
+}
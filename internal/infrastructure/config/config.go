@@ -1,26 +1,120 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	AWS      AWSConfig
-	Azure    AzureConfig
-	GCP      GCPConfig
+	Server          ServerConfig
+	Log             LogConfig
+	Database        DatabaseConfig
+	Redis           RedisConfig
+	Worker          WorkerConfig
+	RateLimit       RateLimitConfig
+	Concurrency     ConcurrencyConfig
+	Fairness        FairnessConfig
+	Costs           CostConfig
+	Carbon          CarbonConfig
+	Storage         StorageConfig
+	SMTP            SMTPConfig
+	Digest          DigestConfig
+	Summary         SummaryConfig
+	Webhook         WebhookConfig
+	Notifications   NotificationsConfig
+	Audit           AuditConfig
+	Hygiene         HygieneConfig
+	Recommendations RecommendationsConfig
+	Quarantine      QuarantineConfig
+	ReadOnly        ReadOnlyConfig
+	CI              CIConfig
+	GitOps          GitOpsConfig
+	Invitations     InvitationConfig
+	OIDC            OIDCConfig
+	Slack           SlackConfig
+	Ticketing       TicketingConfig
+	Owners          OwnersConfig
+	Plans           PlansConfig
+	Allocation      AllocationConfig
+	Integrations    IntegrationsConfig
+	Billing         BillingConfig
+	Pricing         PricingConfig
+	Cache           CacheConfig
+	AWS             AWSConfig
+	Azure           AzureConfig
+	GCP             GCPConfig
+	Kubernetes      KubernetesConfig
+	SecretStore     SecretStoreConfig
+
+	// secrets are the settings read from secret stores, by setting
+	secrets *secretSet
+	// file is the configuration file read, if any
+	file string
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port        string
-	Environment string
-	Debug       bool
+	Port            string
+	Environment     string
+	Debug           bool
+	ShutdownTimeout time.Duration
+	RequestTimeout  time.Duration // deadline of API requests, 0 disables it
+	RateLimit       HTTPRateLimitConfig
+	APIV1           APIVersionConfig
+	AdminToken      string // bearer token of the admin endpoints, disabled when empty
+}
+
+// LogConfig holds the logging configuration
+type LogConfig struct {
+	Level string // debug, info, warn or error
+}
+
+// APIVersionConfig announces the retirement of an API version to its
+// clients. Zero dates are not announced.
+type APIVersionConfig struct {
+	Deprecated time.Time // from when the version is deprecated
+	Sunset     time.Time // when the version stops being served
+}
+
+// HTTPRateLimitConfig holds the per-client limits of the HTTP API. Clients
+// are identified by their credentials, or by IP address when anonymous.
+type HTTPRateLimitConfig struct {
+	Enabled bool
+	Default RouteLimit // every API request
+	Scans   RouteLimit // scan creation
+	Cleanup RouteLimit // cleanup execution and preview
+	Exports RouteLimit // export generation
+}
+
+// Route returns the limit of a route group (default, scans, cleanup or
+// exports), zero when rate limiting is disabled
+func (c HTTPRateLimitConfig) Route(group string) RouteLimit {
+	if !c.Enabled {
+		return RouteLimit{}
+	}
+	switch group {
+	case "scans":
+		return c.Scans
+	case "cleanup":
+		return c.Cleanup
+	case "exports":
+		return c.Exports
+	default:
+		return c.Default
+	}
+}
+
+// RouteLimit allows Requests per Period, refilled continuously
+type RouteLimit struct {
+	Requests int
+	Period   time.Duration
 }
 
 // DatabaseConfig holds database configuration
@@ -31,6 +125,9 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+	// MigrateOnStart applies pending migrations when the API starts instead
+	// of requiring the migrate subcommand
+	MigrateOnStart bool
 }
 
 // RedisConfig holds Redis configuration
@@ -40,11 +137,307 @@ type RedisConfig struct {
 	DB       int
 }
 
-// AWSConfig holds AWS configuration
+// WorkerConfig holds background worker configuration
+type WorkerConfig struct {
+	Concurrency       int
+	ShutdownTimeout   time.Duration
+	ClientPoolTTL     time.Duration // how long provider clients are reused
+	ClientPoolSize    int           // max pooled provider clients
+	ScanConcurrency   int           // regions scanned in parallel within a scan
+	FullScanInterval  time.Duration // incremental scans run in full once the last full scan is this old
+	AdminAddr         string        // admin HTTP listener (health, metrics, status), disabled when empty
+	HeartbeatInterval time.Duration // how often the worker publishes its status to Redis
+	// QueueWeights are the shares of the workers given to the critical,
+	// default and low queues
+	QueueWeights map[string]int
+	// PlanWeights multiply the queue weights of the scans of each plan, so
+	// that bulk scans of a plan do not starve those of the others. Plans
+	// missing from the map weigh 1.
+	PlanWeights map[string]int
+}
+
+// RateLimitConfig holds the client-side limits applied to cloud API calls,
+// shared by all tasks using the same provider account
+type RateLimitConfig struct {
+	AWS         ProviderRateLimit
+	Azure       ProviderRateLimit
+	GCP         ProviderRateLimit
+	Kubernetes  ProviderRateLimit
+	MaxRetries  int           // retries of a throttled call
+	BaseBackoff time.Duration // first retry delay, doubled on each retry
+	MaxBackoff  time.Duration
+}
+
+// ProviderRateLimit holds the token bucket settings of a provider
+type ProviderRateLimit struct {
+	QPS   float64
+	Burst int
+}
+
+// ConcurrencyConfig caps the scans and cleanups running at once across
+// every worker replica. Zero means unlimited.
+type ConcurrencyConfig struct {
+	Enabled    bool
+	PerAccount int // scans and cleanups of the same cloud account
+	Global     int // scans and cleanups of the whole fleet
+	AWS        int // scans and cleanups of each provider
+	Azure      int
+	GCP        int
+	Kubernetes int
+	// LeaseTTL frees the slots of a worker that died while holding them
+	LeaseTTL time.Duration
+	// RetryDelay is how long a task waits for a slot before trying again
+	RetryDelay time.Duration
+}
+
+// FairnessConfig holds the fair scheduling of scans across organizations
+type FairnessConfig struct {
+	Enabled bool
+	// Slot is the worker time given to one scan of a weight 1 organization.
+	// It should be close to the average scan duration divided by the number
+	// of scans the workers run in parallel.
+	Slot        time.Duration
+	PlanWeights map[string]float64 // plans missing from the map weigh 1
+}
+
+// CostConfig holds the normalization of provider-reported costs
+type CostConfig struct {
+	// ExchangeRates converts provider currencies to USD, in USD per unit.
+	// Resources priced in a currency missing from the map keep a zero cost.
+	ExchangeRates map[string]float64
+}
+
+// CarbonConfig holds the carbon estimation model shared by all providers
+type CarbonConfig struct {
+	// PUE overrides the power usage effectiveness of providers' data
+	// centers, keyed by provider
+	PUE map[string]float64
+	// GridIntensity overrides the carbon intensity of regions' electricity,
+	// in kgCO2e per kWh, keyed by region
+	GridIntensity map[string]float64
+	// Intensity is the live forecast source of carbon schedules
+	Intensity CarbonIntensityConfig
+}
+
+// CarbonIntensityConfig holds the access to an Electricity Maps compatible
+// carbon intensity API. Without a token, schedules use a typical day.
+type CarbonIntensityConfig struct {
+	BaseURL string
+	Token   string
+}
+
+// StorageConfig holds object storage configuration for generated files
+type StorageConfig struct {
+	Backend    string // "local" or "s3"
+	LocalPath  string
+	PublicURL  string // base URL of the API, used for local download links
+	SigningKey string // HMAC key for local download links
+	URLTTL     time.Duration
+	S3         S3Config
+}
+
+// S3Config holds S3-compatible storage configuration
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// SMTPConfig holds outgoing email configuration
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// DigestConfig holds the weekly owner digest configuration
+type DigestConfig struct {
+	Enabled    bool
+	Schedule   string // cron expression, evaluated in UTC
+	PublicURL  string // base URL of the API, used for one-click action links
+	SigningKey string // HMAC key for action links
+	LinkTTL    time.Duration
+	SnoozeFor  time.Duration
+}
+
+// SummaryConfig holds the weekly summary emailed to the admins of each
+// organization, on the day and hour set by the organization. Its links are
+// signed like those of owner digests.
+type SummaryConfig struct {
+	Enabled      bool
+	DashboardURL string // dashboard linked from summaries
+}
+
+// AuditConfig holds the retention of the provider call audit log
+type AuditConfig struct {
+	Retention     time.Duration // default retention, organizations may override it
+	PurgeSchedule string        // cron expression, evaluated in UTC
+}
+
+// HygieneConfig holds the daily recording of organization hygiene scores
+type HygieneConfig struct {
+	Schedule string // cron expression, evaluated in UTC; empty disables it
+}
+
+// RecommendationsConfig holds the daily generation of recommendations
+type RecommendationsConfig struct {
+	Schedule string // cron expression, evaluated in UTC; empty disables it
+}
+
+// QuarantineConfig holds the window quarantined resources are kept for and
+// the purge deleting them once it has ended
+type QuarantineConfig struct {
+	Window        time.Duration
+	PurgeSchedule string // cron expression, evaluated in UTC; empty disables purges
+}
+
+// ReadOnlyConfig puts the whole installation in read-only mode: scans go on
+// but no cloud resource is changed. The mode can also be enabled at runtime
+// with the admin API, for the installation or an organization.
+type ReadOnlyConfig struct {
+	Enabled bool
+	Reason  string // shown in the errors of the refused operations
+}
+
+// CIConfig holds the detection of preview environments left behind by
+// closed pull requests
+type CIConfig struct {
+	Schedule        string        // cron expression, evaluated in UTC; empty disables detection
+	GracePeriod     time.Duration // time after a pull request closes before its resources are flagged
+	DefaultProvider string        // "github" or "gitlab", for repository tags without a host
+	GitHub          CIProviderConfig
+	GitLab          CIProviderConfig
+	RepoTagKeys     []string // tags holding the repository, e.g. "owner/repo"
+	PRTagKeys       []string // tags holding the pull/merge request number
+	BranchTagKeys   []string // tags holding the source branch, used without a PR number
+}
+
+// GitOpsConfig holds the pull requests removing Terraform-managed resources
+// from their configuration. They are opened with the CI provider tokens,
+// which need write access to the repositories.
+type GitOpsConfig struct {
+	BranchPrefix string // prefix of the branches pull requests are opened from
+	SyncSchedule string // cron expression, evaluated in UTC; empty disables status tracking
+}
+
+// InvitationConfig holds the invitations to join an organization
+type InvitationConfig struct {
+	AcceptURL string        // page accepting invitations, the token is added as the "token" query parameter
+	TTL       time.Duration // time an invitation can be accepted for
+}
+
+// OIDCConfig holds the single sign-on of organizations with an OIDC
+// identity provider, configured per organization
+type OIDCConfig struct {
+	RedirectURL string        // public URL of /api/v1/auth/oidc/callback, registered with the providers
+	SigningKey  string        // HMAC key for login state cookies and session tokens
+	StateTTL    time.Duration // time a login can take at the provider
+	SessionTTL  time.Duration
+}
+
+// SlackConfig holds the Slack app organizations install with OAuth, which
+// answers the /cloudsweep command and posts scan results with cleanup
+// buttons. The app is disabled while ClientID is empty.
+type SlackConfig struct {
+	ClientID      string
+	ClientSecret  string
+	SigningSecret string        // verifies the requests Slack sends to the command and interaction endpoints
+	RedirectURL   string        // public URL of /api/v1/slack/oauth/callback, registered with the app
+	StateTTL      time.Duration // time an installation can take at Slack
+}
+
+// TicketingConfig holds the Jira issues and ServiceNow change requests
+// opened by the ticket action in the tracker of each organization
+type TicketingConfig struct {
+	ResourceURL  string // page of a resource linked from tickets, "{id}" is replaced by the resource ID
+	SyncSchedule string // cron expression of the update of open tickets, evaluated in UTC; empty disables it
+}
+
+// OwnersConfig holds the messages sent to the owners of the resources of
+// the notify action
+type OwnersConfig struct {
+	ResourceURL    string // page of a resource linked from messages, "{id}" is replaced by the resource ID
+	DigestSchedule string // cron expression of the daily digests of owners, evaluated in UTC; empty disables them
+}
+
+// PlansConfig holds the enforcement of plan quotas that runs in the
+// background
+type PlansConfig struct {
+	RetentionSchedule  string // cron expression of the purge of history older than the plan retention, evaluated in UTC; empty disables it
+	ArchiveBeforePurge bool   // write the purged history to the object storage first
+}
+
+// AllocationConfig holds the refresh of the showback cost allocation by tag
+type AllocationConfig struct {
+	Schedule string // cron expression evaluated in UTC; empty disables the refresh
+}
+
+// IntegrationsConfig holds the organization-level integrations, e.g. AWS
+// Organizations, whose accounts are discovered in the background
+type IntegrationsConfig struct {
+	SyncSchedule string // cron expression evaluated in UTC; empty disables the periodic sync
+}
+
+// BillingConfig holds the reconciliation of resource costs with the
+// billing data of the accounts whose pricing source is not the estimate
+type BillingConfig struct {
+	Schedule string        // cron expression evaluated in UTC; empty disables the reconciliation
+	Window   time.Duration // billing period averaged into monthly costs, at most 14 days for AWS Cost Explorer
+}
+
+// PricingConfig holds the price catalog used to estimate costs without
+// calling the providers
+type PricingConfig struct {
+	// CatalogURL serves a CSV catalog (provider,region,instance_type,hourly_usd)
+	// replacing the prices embedded in the binary; empty keeps the embedded ones
+	CatalogURL      string
+	RefreshSchedule string // cron expression evaluated in UTC; empty disables the refresh
+}
+
+// CacheConfig holds the Redis cache of dashboard aggregates and
+// organization settings. Cached values are dropped when the resources or
+// scans of their organization change; the TTLs bound their staleness after
+// other writes, e.g. cost reconciliations.
+type CacheConfig struct {
+	Enabled      bool
+	DashboardTTL time.Duration
+	SettingsTTL  time.Duration
+}
+
+// CIProviderConfig holds the API access to a CI provider
+type CIProviderConfig struct {
+	BaseURL string
+	Token   string
+}
+
+// WebhookConfig holds the post-scan webhook configuration
+type WebhookConfig struct {
+	ScanURL string        // receives a POST after every scan; empty disables it
+	Secret  string        // HMAC key signing the request body
+	Timeout time.Duration // per delivery attempt
+	LinkTTL time.Duration // validity of the result export link
+}
+
+// NotificationsConfig holds the deduplication of the alerts and webhook
+// events sent by the worker
+type NotificationsConfig struct {
+	DedupWindow   time.Duration // identical alerts and webhook events are sent once per window; zero disables it
+	CoalesceDelay time.Duration // resources matched by the same policy run within the delay are sent in one alert
+}
+
+// AWSConfig holds AWS configuration. The credentials are also the ones
+// assuming the roles of AWS Organizations integrations and of cloud
+// accounts configured with a role.
 type AWSConfig struct {
 	Region          string
 	AccessKeyID     string
 	SecretAccessKey string
+	SessionToken    string
 }
 
 // AzureConfig holds Azure configuration
@@ -61,6 +454,32 @@ type GCPConfig struct {
 	CredentialsFile string
 }
 
+// KubernetesConfig holds the scanning of Kubernetes clusters. Clusters are
+// reached with the kubeconfig stored as account credentials, or with the
+// in-cluster service account when there is none.
+type KubernetesConfig struct {
+	ScaledDownAge       time.Duration // time a deployment must stay at 0 replicas to be flagged
+	IdleUtilization     float64       // usage/requests ratio under which requests are over-provisioned
+	CPUHourPrice        float64       // USD per requested vCPU-hour
+	MemoryGBHourPrice   float64       // USD per requested GiB-hour
+	StorageGBMonthPrice float64       // USD per GiB-month of persistent volume
+	LoadBalancerPrice   float64       // USD per hour per load balancer Service
+}
+
+// SecretStoreConfig holds the secret stores settings may reference with a
+// secret-ref:// value. Its own settings cannot be references.
+type SecretStoreConfig struct {
+	RefreshInterval time.Duration // how often references are read again to detect rotations; 0 disables it
+	Vault           VaultConfig
+}
+
+// VaultConfig holds the access to HashiCorp Vault
+type VaultConfig struct {
+	Addr      string
+	Token     string
+	Namespace string // Vault Enterprise namespace
+}
+
 // Load loads configuration from environment variables and config files
 func Load() (*Config, error) {
 	v := viper.New()
@@ -69,6 +488,19 @@ func Load() (*Config, error) {
 	v.SetDefault("server.port", "8080")
 	v.SetDefault("server.environment", "development")
 	v.SetDefault("server.debug", true)
+	v.SetDefault("server.shutdowntimeout", "30s")
+	v.SetDefault("server.requesttimeout", "10s")
+	v.SetDefault("server.ratelimit.enabled", true)
+	v.SetDefault("server.ratelimit.default.requests", 300)
+	v.SetDefault("server.ratelimit.default.period", "1m")
+	v.SetDefault("server.ratelimit.scans.requests", 30)
+	v.SetDefault("server.ratelimit.scans.period", "1m")
+	v.SetDefault("server.ratelimit.cleanup.requests", 10)
+	v.SetDefault("server.ratelimit.cleanup.period", "1m")
+	v.SetDefault("server.ratelimit.exports.requests", 20)
+	v.SetDefault("server.ratelimit.exports.period", "1m")
+
+	v.SetDefault("log.level", "info")
 
 	v.SetDefault("database.host", "localhost")
 	v.SetDefault("database.port", "5432")
@@ -76,13 +508,156 @@ func Load() (*Config, error) {
 	v.SetDefault("database.password", "cloudsweep_secret")
 	v.SetDefault("database.name", "cloudsweep")
 	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.migrateOnStart", false)
 
 	v.SetDefault("redis.addr", "localhost:6379")
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.db", 0)
 
+	v.SetDefault("worker.concurrency", 10)
+	v.SetDefault("worker.shutdowntimeout", "60s")
+	v.SetDefault("worker.clientpoolttl", "45m")
+	v.SetDefault("worker.clientpoolsize", 256)
+	v.SetDefault("worker.scanconcurrency", 5)
+	v.SetDefault("worker.fullscaninterval", "24h")
+	v.SetDefault("worker.adminaddr", ":9090")
+	v.SetDefault("worker.heartbeatinterval", "15s")
+	v.SetDefault("worker.queueweights", map[string]any{"critical": 6, "default": 3, "low": 1})
+	v.SetDefault("worker.planweights", map[string]any{"free": 1, "pro": 2, "enterprise": 4})
+
+	v.SetDefault("ratelimit.aws.qps", 10)
+	v.SetDefault("ratelimit.aws.burst", 20)
+	v.SetDefault("ratelimit.azure.qps", 3)
+	v.SetDefault("ratelimit.azure.burst", 10)
+	v.SetDefault("ratelimit.gcp.qps", 10)
+	v.SetDefault("ratelimit.gcp.burst", 20)
+	v.SetDefault("ratelimit.kubernetes.qps", 20)
+	v.SetDefault("ratelimit.kubernetes.burst", 40)
+	v.SetDefault("ratelimit.maxretries", 5)
+	v.SetDefault("ratelimit.basebackoff", "500ms")
+	v.SetDefault("ratelimit.maxbackoff", "30s")
+
+	v.SetDefault("concurrency.enabled", true)
+	v.SetDefault("concurrency.peraccount", 1)
+	v.SetDefault("concurrency.global", 0)
+	v.SetDefault("concurrency.aws", 0)
+	v.SetDefault("concurrency.azure", 0)
+	v.SetDefault("concurrency.gcp", 0)
+	v.SetDefault("concurrency.kubernetes", 0)
+	v.SetDefault("concurrency.leasettl", "2m")
+	v.SetDefault("concurrency.retrydelay", "30s")
+
+	v.SetDefault("fairness.enabled", true)
+	v.SetDefault("fairness.slot", "10s")
+	v.SetDefault("fairness.planweights", map[string]any{"free": 1, "pro": 2, "enterprise": 4})
+
+	v.SetDefault("costs.exchangerates", map[string]any{"EUR": 1.08, "GBP": 1.27, "JPY": 0.0067, "INR": 0.012, "BRL": 0.18, "AUD": 0.66, "CAD": 0.73})
+
+	v.SetDefault("storage.backend", "local")
+	v.SetDefault("storage.localpath", "./data/storage")
+	v.SetDefault("storage.publicurl", "http://localhost:8080")
+	v.SetDefault("storage.signingkey", "cloudsweep-dev-signing-key")
+	v.SetDefault("storage.urlttl", "1h")
+
+	v.SetDefault("smtp.host", "localhost")
+	v.SetDefault("smtp.port", "25")
+	v.SetDefault("smtp.from", "CloudSweep <noreply@cloudsweep.local>")
+
+	v.SetDefault("digest.enabled", false)
+	v.SetDefault("digest.schedule", "0 8 * * 1")
+	v.SetDefault("digest.publicurl", "http://localhost:8080")
+	v.SetDefault("digest.signingkey", "cloudsweep-dev-digest-key")
+	v.SetDefault("digest.linkttl", "336h")
+	v.SetDefault("digest.snoozefor", "720h")
+
+	v.SetDefault("summary.enabled", false)
+	v.SetDefault("summary.dashboardurl", "http://localhost:3000")
+
+	v.SetDefault("audit.retention", "8760h")
+	v.SetDefault("audit.purgeschedule", "0 3 * * *")
+
+	v.SetDefault("hygiene.schedule", "0 2 * * *")
+
+	v.SetDefault("recommendations.schedule", "0 4 * * *")
+
+	v.SetDefault("quarantine.window", "168h")
+	v.SetDefault("quarantine.purgeschedule", "30 * * * *")
+	v.SetDefault("readonly.enabled", false)
+
+	v.SetDefault("gitops.branchprefix", "cloudsweep/remove-")
+	v.SetDefault("gitops.syncschedule", "*/15 * * * *")
+
+	v.SetDefault("invitations.accepturl", "http://localhost:3000/invitations/accept")
+	v.SetDefault("invitations.ttl", "168h")
+
+	// OIDC defaults
+	v.SetDefault("oidc.redirecturl", "http://localhost:8080/api/v1/auth/oidc/callback")
+	v.SetDefault("oidc.signingkey", "cloudsweep-dev-oidc-key")
+	v.SetDefault("oidc.statettl", "10m")
+	v.SetDefault("oidc.sessionttl", "12h")
+
+	// Slack defaults
+	v.SetDefault("slack.redirecturl", "http://localhost:8080/api/v1/slack/oauth/callback")
+	v.SetDefault("slack.statettl", "10m")
+
+	// Ticketing defaults
+	v.SetDefault("ticketing.resourceurl", "http://localhost:3000/resources/{id}")
+	v.SetDefault("ticketing.syncschedule", "*/30 * * * *")
+
+	// Owner notification defaults
+	v.SetDefault("owners.resourceurl", "http://localhost:3000/resources/{id}")
+	v.SetDefault("owners.digestschedule", "0 8 * * *")
+
+	// Plans defaults
+	v.SetDefault("plans.retentionschedule", "0 4 * * *")
+	v.SetDefault("plans.archivebeforepurge", false)
+
+	// Allocation defaults
+	v.SetDefault("allocation.schedule", "0 * * * *")
+
+	// Integrations defaults
+	v.SetDefault("integrations.syncschedule", "0 */6 * * *")
+
+	// Billing defaults
+	v.SetDefault("billing.schedule", "0 7 * * *")
+	v.SetDefault("billing.window", "336h")
+
+	// Pricing defaults
+	v.SetDefault("pricing.refreshschedule", "0 4 * * *")
+
+	v.SetDefault("cache.enabled", true)
+	v.SetDefault("cache.dashboardttl", "1m")
+	v.SetDefault("cache.settingsttl", "5m")
+
+	v.SetDefault("carbon.intensity.baseurl", "https://api.electricitymap.org/v3")
+
+	v.SetDefault("ci.schedule", "")
+	v.SetDefault("ci.graceperiod", "72h")
+	v.SetDefault("ci.defaultprovider", "github")
+	v.SetDefault("ci.github.baseurl", "https://api.github.com")
+	v.SetDefault("ci.gitlab.baseurl", "https://gitlab.com/api/v4")
+	v.SetDefault("ci.repotagkeys", []string{"repository", "repo", "ci:repository"})
+	v.SetDefault("ci.prtagkeys", []string{"pull_request", "pr", "merge_request", "ci:pr"})
+	v.SetDefault("ci.branchtagkeys", []string{"branch", "ci:branch"})
+
+	v.SetDefault("webhook.timeout", "10s")
+	v.SetDefault("webhook.linkttl", "24h")
+
+	// Notification deduplication defaults
+	v.SetDefault("notifications.dedupwindow", "1h")
+	v.SetDefault("notifications.coalescedelay", "1m")
+
 	v.SetDefault("aws.region", "us-east-1")
 
+	v.SetDefault("secretstore.refreshinterval", "5m")
+
+	v.SetDefault("kubernetes.scaleddownage", "336h")
+	v.SetDefault("kubernetes.idleutilization", 0.2)
+	v.SetDefault("kubernetes.cpuhourprice", 0.0316)
+	v.SetDefault("kubernetes.memorygbhourprice", 0.0042)
+	v.SetDefault("kubernetes.storagegbmonthprice", 0.10)
+	v.SetDefault("kubernetes.loadbalancerprice", 0.025)
+
 	// Config file
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
@@ -105,6 +680,21 @@ func Load() (*Config, error) {
 	v.BindEnv("server.port", "SERVER_PORT")
 	v.BindEnv("server.environment", "SERVER_ENV")
 	v.BindEnv("server.debug", "SERVER_DEBUG")
+	v.BindEnv("server.shutdowntimeout", "SERVER_SHUTDOWN_TIMEOUT")
+	v.BindEnv("server.requesttimeout", "SERVER_REQUEST_TIMEOUT")
+	v.BindEnv("server.ratelimit.enabled", "SERVER_RATE_LIMIT_ENABLED")
+	v.BindEnv("server.ratelimit.default.requests", "SERVER_RATE_LIMIT_DEFAULT_REQUESTS")
+	v.BindEnv("server.ratelimit.default.period", "SERVER_RATE_LIMIT_DEFAULT_PERIOD")
+	v.BindEnv("server.ratelimit.scans.requests", "SERVER_RATE_LIMIT_SCANS_REQUESTS")
+	v.BindEnv("server.ratelimit.scans.period", "SERVER_RATE_LIMIT_SCANS_PERIOD")
+	v.BindEnv("server.ratelimit.cleanup.requests", "SERVER_RATE_LIMIT_CLEANUP_REQUESTS")
+	v.BindEnv("server.ratelimit.cleanup.period", "SERVER_RATE_LIMIT_CLEANUP_PERIOD")
+	v.BindEnv("server.ratelimit.exports.requests", "SERVER_RATE_LIMIT_EXPORTS_REQUESTS")
+	v.BindEnv("server.ratelimit.exports.period", "SERVER_RATE_LIMIT_EXPORTS_PERIOD")
+	v.BindEnv("server.apiv1.deprecated", "API_V1_DEPRECATED")
+	v.BindEnv("server.apiv1.sunset", "API_V1_SUNSET")
+	v.BindEnv("server.admintoken", "ADMIN_TOKEN")
+	v.BindEnv("log.level", "LOG_LEVEL")
 
 	v.BindEnv("database.host", "DB_HOST")
 	v.BindEnv("database.port", "DB_PORT")
@@ -112,20 +702,152 @@ func Load() (*Config, error) {
 	v.BindEnv("database.password", "DB_PASSWORD")
 	v.BindEnv("database.name", "DB_NAME")
 	v.BindEnv("database.sslmode", "DB_SSLMODE")
+	v.BindEnv("database.migrateOnStart", "DB_MIGRATE_ON_START")
 
 	v.BindEnv("redis.addr", "REDIS_ADDR")
 	v.BindEnv("redis.password", "REDIS_PASSWORD")
 	v.BindEnv("redis.db", "REDIS_DB")
 
+	v.BindEnv("worker.concurrency", "WORKER_CONCURRENCY")
+	v.BindEnv("worker.shutdowntimeout", "WORKER_SHUTDOWN_TIMEOUT")
+	v.BindEnv("worker.clientpoolttl", "WORKER_CLIENT_POOL_TTL")
+	v.BindEnv("worker.clientpoolsize", "WORKER_CLIENT_POOL_SIZE")
+	v.BindEnv("worker.scanconcurrency", "WORKER_SCAN_CONCURRENCY")
+	v.BindEnv("worker.fullscaninterval", "WORKER_FULL_SCAN_INTERVAL")
+	v.BindEnv("worker.adminaddr", "WORKER_ADMIN_ADDR")
+	v.BindEnv("worker.heartbeatinterval", "WORKER_HEARTBEAT_INTERVAL")
+
+	v.BindEnv("ratelimit.aws.qps", "RATELIMIT_AWS_QPS")
+	v.BindEnv("ratelimit.aws.burst", "RATELIMIT_AWS_BURST")
+	v.BindEnv("ratelimit.azure.qps", "RATELIMIT_AZURE_QPS")
+	v.BindEnv("ratelimit.azure.burst", "RATELIMIT_AZURE_BURST")
+	v.BindEnv("ratelimit.gcp.qps", "RATELIMIT_GCP_QPS")
+	v.BindEnv("ratelimit.gcp.burst", "RATELIMIT_GCP_BURST")
+	v.BindEnv("ratelimit.kubernetes.qps", "RATELIMIT_KUBERNETES_QPS")
+	v.BindEnv("ratelimit.kubernetes.burst", "RATELIMIT_KUBERNETES_BURST")
+	v.BindEnv("ratelimit.maxretries", "RATELIMIT_MAX_RETRIES")
+	v.BindEnv("ratelimit.basebackoff", "RATELIMIT_BASE_BACKOFF")
+	v.BindEnv("ratelimit.maxbackoff", "RATELIMIT_MAX_BACKOFF")
+
+	v.BindEnv("concurrency.enabled", "CONCURRENCY_ENABLED")
+	v.BindEnv("concurrency.peraccount", "CONCURRENCY_PER_ACCOUNT")
+	v.BindEnv("concurrency.global", "CONCURRENCY_GLOBAL")
+	v.BindEnv("concurrency.aws", "CONCURRENCY_AWS")
+	v.BindEnv("concurrency.azure", "CONCURRENCY_AZURE")
+	v.BindEnv("concurrency.gcp", "CONCURRENCY_GCP")
+	v.BindEnv("concurrency.kubernetes", "CONCURRENCY_KUBERNETES")
+	v.BindEnv("concurrency.leasettl", "CONCURRENCY_LEASE_TTL")
+	v.BindEnv("concurrency.retrydelay", "CONCURRENCY_RETRY_DELAY")
+
+	v.BindEnv("fairness.enabled", "FAIRNESS_ENABLED")
+	v.BindEnv("fairness.slot", "FAIRNESS_SLOT")
+
+	v.BindEnv("storage.backend", "STORAGE_BACKEND")
+	v.BindEnv("storage.localpath", "STORAGE_LOCAL_PATH")
+	v.BindEnv("storage.publicurl", "STORAGE_PUBLIC_URL")
+	v.BindEnv("storage.signingkey", "STORAGE_SIGNING_KEY")
+	v.BindEnv("storage.urlttl", "STORAGE_URL_TTL")
+	v.BindEnv("storage.s3.bucket", "STORAGE_S3_BUCKET")
+	v.BindEnv("storage.s3.region", "STORAGE_S3_REGION")
+	v.BindEnv("storage.s3.endpoint", "STORAGE_S3_ENDPOINT")
+
+	v.BindEnv("smtp.host", "SMTP_HOST")
+	v.BindEnv("smtp.port", "SMTP_PORT")
+	v.BindEnv("smtp.username", "SMTP_USERNAME")
+	v.BindEnv("smtp.password", "SMTP_PASSWORD")
+	v.BindEnv("smtp.from", "SMTP_FROM")
+
+	v.BindEnv("digest.enabled", "DIGEST_ENABLED")
+	v.BindEnv("digest.schedule", "DIGEST_SCHEDULE")
+	v.BindEnv("digest.publicurl", "DIGEST_PUBLIC_URL")
+	v.BindEnv("digest.signingkey", "DIGEST_SIGNING_KEY")
+	v.BindEnv("digest.linkttl", "DIGEST_LINK_TTL")
+	v.BindEnv("digest.snoozefor", "DIGEST_SNOOZE_FOR")
+	v.BindEnv("summary.enabled", "SUMMARY_ENABLED")
+	v.BindEnv("summary.dashboardurl", "SUMMARY_DASHBOARD_URL")
+	v.BindEnv("audit.retention", "AUDIT_RETENTION")
+	v.BindEnv("audit.purgeschedule", "AUDIT_PURGE_SCHEDULE")
+	v.BindEnv("hygiene.schedule", "HYGIENE_SCHEDULE")
+	v.BindEnv("recommendations.schedule", "RECOMMENDATIONS_SCHEDULE")
+	v.BindEnv("quarantine.window", "QUARANTINE_WINDOW")
+	v.BindEnv("quarantine.purgeschedule", "QUARANTINE_PURGE_SCHEDULE")
+	v.BindEnv("readonly.enabled", "READ_ONLY")
+	v.BindEnv("readonly.reason", "READ_ONLY_REASON")
+	v.BindEnv("gitops.branchprefix", "GITOPS_BRANCH_PREFIX")
+	v.BindEnv("gitops.syncschedule", "GITOPS_SYNC_SCHEDULE")
+	v.BindEnv("invitations.accepturl", "INVITATION_ACCEPT_URL")
+	v.BindEnv("invitations.ttl", "INVITATION_TTL")
+	v.BindEnv("oidc.redirecturl", "OIDC_REDIRECT_URL")
+	v.BindEnv("oidc.signingkey", "OIDC_SIGNING_KEY")
+	v.BindEnv("oidc.statettl", "OIDC_STATE_TTL")
+	v.BindEnv("oidc.sessionttl", "OIDC_SESSION_TTL")
+	v.BindEnv("slack.clientid", "SLACK_CLIENT_ID")
+	v.BindEnv("slack.clientsecret", "SLACK_CLIENT_SECRET")
+	v.BindEnv("slack.signingsecret", "SLACK_SIGNING_SECRET")
+	v.BindEnv("slack.redirecturl", "SLACK_REDIRECT_URL")
+	v.BindEnv("slack.statettl", "SLACK_STATE_TTL")
+	v.BindEnv("ticketing.resourceurl", "TICKETING_RESOURCE_URL")
+	v.BindEnv("ticketing.syncschedule", "TICKETING_SYNC_SCHEDULE")
+	v.BindEnv("owners.resourceurl", "OWNERS_RESOURCE_URL")
+	v.BindEnv("owners.digestschedule", "OWNERS_DIGEST_SCHEDULE")
+	v.BindEnv("plans.retentionschedule", "PLAN_RETENTION_SCHEDULE")
+	v.BindEnv("plans.archivebeforepurge", "PLAN_RETENTION_ARCHIVE")
+	v.BindEnv("allocation.schedule", "ALLOCATION_SCHEDULE")
+	v.BindEnv("integrations.syncschedule", "INTEGRATIONS_SYNC_SCHEDULE")
+	v.BindEnv("billing.schedule", "BILLING_SCHEDULE")
+	v.BindEnv("billing.window", "BILLING_WINDOW")
+	v.BindEnv("pricing.catalogurl", "PRICING_CATALOG_URL")
+	v.BindEnv("pricing.refreshschedule", "PRICING_REFRESH_SCHEDULE")
+	v.BindEnv("cache.enabled", "CACHE_ENABLED")
+	v.BindEnv("cache.dashboardttl", "CACHE_DASHBOARD_TTL")
+	v.BindEnv("cache.settingsttl", "CACHE_SETTINGS_TTL")
+	v.BindEnv("carbon.intensity.baseurl", "CARBON_INTENSITY_URL")
+	v.BindEnv("carbon.intensity.token", "CARBON_INTENSITY_TOKEN")
+	v.BindEnv("ci.schedule", "CI_SCHEDULE")
+	v.BindEnv("ci.graceperiod", "CI_GRACE_PERIOD")
+	v.BindEnv("ci.github.baseurl", "CI_GITHUB_URL")
+	v.BindEnv("ci.github.token", "CI_GITHUB_TOKEN")
+	v.BindEnv("ci.gitlab.baseurl", "CI_GITLAB_URL")
+	v.BindEnv("ci.gitlab.token", "CI_GITLAB_TOKEN")
+
+	v.BindEnv("webhook.scanurl", "WEBHOOK_SCAN_URL")
+	v.BindEnv("webhook.secret", "WEBHOOK_SECRET")
+	v.BindEnv("webhook.timeout", "WEBHOOK_TIMEOUT")
+	v.BindEnv("webhook.linkttl", "WEBHOOK_LINK_TTL")
+	v.BindEnv("notifications.dedupwindow", "NOTIFICATIONS_DEDUP_WINDOW")
+	v.BindEnv("notifications.coalescedelay", "NOTIFICATIONS_COALESCE_DELAY")
+
 	v.BindEnv("aws.region", "AWS_REGION")
+	v.BindEnv("secretstore.refreshinterval", "SECRETS_REFRESH_INTERVAL")
+	v.BindEnv("secretstore.vault.addr", "VAULT_ADDR")
+	v.BindEnv("secretstore.vault.token", "VAULT_TOKEN")
+	v.BindEnv("secretstore.vault.namespace", "VAULT_NAMESPACE")
 	v.BindEnv("aws.accesskeyid", "AWS_ACCESS_KEY_ID")
 	v.BindEnv("aws.secretaccesskey", "AWS_SECRET_ACCESS_KEY")
+	v.BindEnv("aws.sessiontoken", "AWS_SESSION_TOKEN")
 
 	config := &Config{
 		Server: ServerConfig{
-			Port:        v.GetString("server.port"),
-			Environment: v.GetString("server.environment"),
-			Debug:       v.GetBool("server.debug"),
+			Port:            v.GetString("server.port"),
+			Environment:     v.GetString("server.environment"),
+			Debug:           v.GetBool("server.debug"),
+			ShutdownTimeout: v.GetDuration("server.shutdowntimeout"),
+			RequestTimeout:  v.GetDuration("server.requesttimeout"),
+			RateLimit: HTTPRateLimitConfig{
+				Enabled: v.GetBool("server.ratelimit.enabled"),
+				Default: routeLimit(v, "server.ratelimit.default"),
+				Scans:   routeLimit(v, "server.ratelimit.scans"),
+				Cleanup: routeLimit(v, "server.ratelimit.cleanup"),
+				Exports: routeLimit(v, "server.ratelimit.exports"),
+			},
+			APIV1: APIVersionConfig{
+				Deprecated: v.GetTime("server.apiv1.deprecated"),
+				Sunset:     v.GetTime("server.apiv1.sunset"),
+			},
+			AdminToken: v.GetString("server.admintoken"),
+		},
+		Log: LogConfig{
+			Level: v.GetString("log.level"),
 		},
 		Database: DatabaseConfig{
 			Host:     v.GetString("database.host"),
@@ -134,16 +856,208 @@ func Load() (*Config, error) {
 			Password: v.GetString("database.password"),
 			Name:     v.GetString("database.name"),
 			SSLMode:  v.GetString("database.sslmode"),
+
+			MigrateOnStart: v.GetBool("database.migrateOnStart"),
 		},
 		Redis: RedisConfig{
 			Addr:     v.GetString("redis.addr"),
 			Password: v.GetString("redis.password"),
 			DB:       v.GetInt("redis.db"),
 		},
+		Worker: WorkerConfig{
+			Concurrency:       v.GetInt("worker.concurrency"),
+			ShutdownTimeout:   v.GetDuration("worker.shutdowntimeout"),
+			ClientPoolTTL:     v.GetDuration("worker.clientpoolttl"),
+			ClientPoolSize:    v.GetInt("worker.clientpoolsize"),
+			ScanConcurrency:   v.GetInt("worker.scanconcurrency"),
+			FullScanInterval:  v.GetDuration("worker.fullscaninterval"),
+			AdminAddr:         v.GetString("worker.adminaddr"),
+			HeartbeatInterval: v.GetDuration("worker.heartbeatinterval"),
+			QueueWeights:      intMap(v.GetStringMap("worker.queueweights")),
+			PlanWeights:       intMap(v.GetStringMap("worker.planweights")),
+		},
+		RateLimit: RateLimitConfig{
+			AWS: ProviderRateLimit{
+				QPS:   v.GetFloat64("ratelimit.aws.qps"),
+				Burst: v.GetInt("ratelimit.aws.burst"),
+			},
+			Azure: ProviderRateLimit{
+				QPS:   v.GetFloat64("ratelimit.azure.qps"),
+				Burst: v.GetInt("ratelimit.azure.burst"),
+			},
+			GCP: ProviderRateLimit{
+				QPS:   v.GetFloat64("ratelimit.gcp.qps"),
+				Burst: v.GetInt("ratelimit.gcp.burst"),
+			},
+			Kubernetes: ProviderRateLimit{
+				QPS:   v.GetFloat64("ratelimit.kubernetes.qps"),
+				Burst: v.GetInt("ratelimit.kubernetes.burst"),
+			},
+			MaxRetries:  v.GetInt("ratelimit.maxretries"),
+			BaseBackoff: v.GetDuration("ratelimit.basebackoff"),
+			MaxBackoff:  v.GetDuration("ratelimit.maxbackoff"),
+		},
+		Concurrency: ConcurrencyConfig{
+			Enabled:    v.GetBool("concurrency.enabled"),
+			PerAccount: v.GetInt("concurrency.peraccount"),
+			Global:     v.GetInt("concurrency.global"),
+			AWS:        v.GetInt("concurrency.aws"),
+			Azure:      v.GetInt("concurrency.azure"),
+			GCP:        v.GetInt("concurrency.gcp"),
+			Kubernetes: v.GetInt("concurrency.kubernetes"),
+			LeaseTTL:   v.GetDuration("concurrency.leasettl"),
+			RetryDelay: v.GetDuration("concurrency.retrydelay"),
+		},
+		Fairness: FairnessConfig{
+			Enabled:     v.GetBool("fairness.enabled"),
+			Slot:        v.GetDuration("fairness.slot"),
+			PlanWeights: floatMap(v.GetStringMap("fairness.planweights")),
+		},
+		Costs: CostConfig{
+			ExchangeRates: floatMap(v.GetStringMap("costs.exchangerates")),
+		},
+		Carbon: CarbonConfig{
+			PUE:           floatMap(v.GetStringMap("carbon.pue")),
+			GridIntensity: floatMap(v.GetStringMap("carbon.gridintensity")),
+			Intensity: CarbonIntensityConfig{
+				BaseURL: v.GetString("carbon.intensity.baseurl"),
+				Token:   v.GetString("carbon.intensity.token"),
+			},
+		},
+		Storage: StorageConfig{
+			Backend:    v.GetString("storage.backend"),
+			LocalPath:  v.GetString("storage.localpath"),
+			PublicURL:  v.GetString("storage.publicurl"),
+			SigningKey: v.GetString("storage.signingkey"),
+			URLTTL:     v.GetDuration("storage.urlttl"),
+			S3: S3Config{
+				Bucket:          v.GetString("storage.s3.bucket"),
+				Region:          v.GetString("storage.s3.region"),
+				Endpoint:        v.GetString("storage.s3.endpoint"),
+				AccessKeyID:     v.GetString("storage.s3.accesskeyid"),
+				SecretAccessKey: v.GetString("storage.s3.secretaccesskey"),
+			},
+		},
+		SMTP: SMTPConfig{
+			Host:     v.GetString("smtp.host"),
+			Port:     v.GetString("smtp.port"),
+			Username: v.GetString("smtp.username"),
+			Password: v.GetString("smtp.password"),
+			From:     v.GetString("smtp.from"),
+		},
+		Digest: DigestConfig{
+			Enabled:    v.GetBool("digest.enabled"),
+			Schedule:   v.GetString("digest.schedule"),
+			PublicURL:  v.GetString("digest.publicurl"),
+			SigningKey: v.GetString("digest.signingkey"),
+			LinkTTL:    v.GetDuration("digest.linkttl"),
+			SnoozeFor:  v.GetDuration("digest.snoozefor"),
+		},
+		Summary: SummaryConfig{
+			Enabled:      v.GetBool("summary.enabled"),
+			DashboardURL: v.GetString("summary.dashboardurl"),
+		},
+		Audit: AuditConfig{
+			Retention:     v.GetDuration("audit.retention"),
+			PurgeSchedule: v.GetString("audit.purgeschedule"),
+		},
+		Hygiene: HygieneConfig{
+			Schedule: v.GetString("hygiene.schedule"),
+		},
+		Recommendations: RecommendationsConfig{
+			Schedule: v.GetString("recommendations.schedule"),
+		},
+		Quarantine: QuarantineConfig{
+			Window:        v.GetDuration("quarantine.window"),
+			PurgeSchedule: v.GetString("quarantine.purgeschedule"),
+		},
+		ReadOnly: ReadOnlyConfig{
+			Enabled: v.GetBool("readonly.enabled"),
+			Reason:  v.GetString("readonly.reason"),
+		},
+		CI: CIConfig{
+			Schedule:        v.GetString("ci.schedule"),
+			GracePeriod:     v.GetDuration("ci.graceperiod"),
+			DefaultProvider: v.GetString("ci.defaultprovider"),
+			GitHub: CIProviderConfig{
+				BaseURL: v.GetString("ci.github.baseurl"),
+				Token:   v.GetString("ci.github.token"),
+			},
+			GitLab: CIProviderConfig{
+				BaseURL: v.GetString("ci.gitlab.baseurl"),
+				Token:   v.GetString("ci.gitlab.token"),
+			},
+			RepoTagKeys:   v.GetStringSlice("ci.repotagkeys"),
+			PRTagKeys:     v.GetStringSlice("ci.prtagkeys"),
+			BranchTagKeys: v.GetStringSlice("ci.branchtagkeys"),
+		},
+		GitOps: GitOpsConfig{
+			BranchPrefix: v.GetString("gitops.branchprefix"),
+			SyncSchedule: v.GetString("gitops.syncschedule"),
+		},
+		Invitations: InvitationConfig{
+			AcceptURL: v.GetString("invitations.accepturl"),
+			TTL:       v.GetDuration("invitations.ttl"),
+		},
+		OIDC: OIDCConfig{
+			RedirectURL: v.GetString("oidc.redirecturl"),
+			SigningKey:  v.GetString("oidc.signingkey"),
+			StateTTL:    v.GetDuration("oidc.statettl"),
+			SessionTTL:  v.GetDuration("oidc.sessionttl"),
+		},
+		Slack: SlackConfig{
+			ClientID:      v.GetString("slack.clientid"),
+			ClientSecret:  v.GetString("slack.clientsecret"),
+			SigningSecret: v.GetString("slack.signingsecret"),
+			RedirectURL:   v.GetString("slack.redirecturl"),
+			StateTTL:      v.GetDuration("slack.statettl"),
+		},
+		Ticketing: TicketingConfig{
+			ResourceURL:  v.GetString("ticketing.resourceurl"),
+			SyncSchedule: v.GetString("ticketing.syncschedule"),
+		},
+		Owners: OwnersConfig{
+			ResourceURL:    v.GetString("owners.resourceurl"),
+			DigestSchedule: v.GetString("owners.digestschedule"),
+		},
+		Plans: PlansConfig{
+			RetentionSchedule:  v.GetString("plans.retentionschedule"),
+			ArchiveBeforePurge: v.GetBool("plans.archivebeforepurge"),
+		},
+		Allocation: AllocationConfig{
+			Schedule: v.GetString("allocation.schedule"),
+		},
+		Integrations: IntegrationsConfig{
+			SyncSchedule: v.GetString("integrations.syncschedule"),
+		},
+		Billing: BillingConfig{
+			Schedule: v.GetString("billing.schedule"),
+			Window:   v.GetDuration("billing.window"),
+		},
+		Pricing: PricingConfig{
+			CatalogURL:      v.GetString("pricing.catalogurl"),
+			RefreshSchedule: v.GetString("pricing.refreshschedule"),
+		},
+		Cache: CacheConfig{
+			Enabled:      v.GetBool("cache.enabled"),
+			DashboardTTL: v.GetDuration("cache.dashboardttl"),
+			SettingsTTL:  v.GetDuration("cache.settingsttl"),
+		},
+		Webhook: WebhookConfig{
+			ScanURL: v.GetString("webhook.scanurl"),
+			Secret:  v.GetString("webhook.secret"),
+			Timeout: v.GetDuration("webhook.timeout"),
+			LinkTTL: v.GetDuration("webhook.linkttl"),
+		},
+		Notifications: NotificationsConfig{
+			DedupWindow:   v.GetDuration("notifications.dedupwindow"),
+			CoalesceDelay: v.GetDuration("notifications.coalescedelay"),
+		},
 		AWS: AWSConfig{
 			Region:          v.GetString("aws.region"),
 			AccessKeyID:     v.GetString("aws.accesskeyid"),
 			SecretAccessKey: v.GetString("aws.secretaccesskey"),
+			SessionToken:    v.GetString("aws.sessiontoken"),
 		},
 		Azure: AzureConfig{
 			TenantID:       v.GetString("azure.tenantid"),
@@ -155,7 +1069,153 @@ func Load() (*Config, error) {
 			ProjectID:       v.GetString("gcp.projectid"),
 			CredentialsFile: v.GetString("gcp.credentialsfile"),
 		},
+		Kubernetes: KubernetesConfig{
+			ScaledDownAge:       v.GetDuration("kubernetes.scaleddownage"),
+			IdleUtilization:     v.GetFloat64("kubernetes.idleutilization"),
+			CPUHourPrice:        v.GetFloat64("kubernetes.cpuhourprice"),
+			MemoryGBHourPrice:   v.GetFloat64("kubernetes.memorygbhourprice"),
+			StorageGBMonthPrice: v.GetFloat64("kubernetes.storagegbmonthprice"),
+			LoadBalancerPrice:   v.GetFloat64("kubernetes.loadbalancerprice"),
+		},
+		SecretStore: SecretStoreConfig{
+			RefreshInterval: v.GetDuration("secretstore.refreshinterval"),
+			Vault: VaultConfig{
+				Addr:      v.GetString("secretstore.vault.addr"),
+				Token:     v.GetString("secretstore.vault.token"),
+				Namespace: v.GetString("secretstore.vault.namespace"),
+			},
+		},
+	}
+
+	config.file = v.ConfigFileUsed()
+
+	// Settings referencing secret stores
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+	if err := config.resolveSecrets(ctx); err != nil {
+		return nil, err
+	}
+
+	// S3 storage falls back to the main AWS credentials and region
+	if config.Storage.S3.AccessKeyID == "" {
+		config.Storage.S3.AccessKeyID = config.AWS.AccessKeyID
+		config.Storage.S3.SecretAccessKey = config.AWS.SecretAccessKey
+		config.Storage.S3.SessionToken = config.AWS.SessionToken
+	}
+	if config.Storage.S3.Region == "" {
+		config.Storage.S3.Region = config.AWS.Region
 	}
 
 	return config, nil
 }
+
+func routeLimit(v *viper.Viper, key string) RouteLimit {
+	return RouteLimit{
+		Requests: v.GetInt(key + ".requests"),
+		Period:   v.GetDuration(key + ".period"),
+	}
+}
+
+func floatMap(m map[string]any) map[string]float64 {
+	out := make(map[string]float64, len(m))
+	for k, v := range m {
+		if f, err := strconv.ParseFloat(fmt.Sprint(v), 64); err == nil {
+			out[k] = f
+		}
+	}
+	return out
+}
+
+func intMap(m map[string]any) map[string]int {
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		if n, err := strconv.Atoi(fmt.Sprint(v)); err == nil {
+			out[k] = n
+		}
+	}
+	return out
+}
+
+const redactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the configuration with secrets masked,
+// safe to log or share in support bundles
+func (c *Config) Redacted() Config {
+	r := *c
+	r.Database.Password = redact(r.Database.Password)
+	r.Redis.Password = redact(r.Redis.Password)
+	r.AWS.AccessKeyID = redact(r.AWS.AccessKeyID)
+	r.AWS.SecretAccessKey = redact(r.AWS.SecretAccessKey)
+	r.AWS.SessionToken = redact(r.AWS.SessionToken)
+	r.Azure.ClientSecret = redact(r.Azure.ClientSecret)
+	r.Storage.SigningKey = redact(r.Storage.SigningKey)
+	r.Storage.S3.AccessKeyID = redact(r.Storage.S3.AccessKeyID)
+	r.Storage.S3.SecretAccessKey = redact(r.Storage.S3.SecretAccessKey)
+	r.Storage.S3.SessionToken = redact(r.Storage.S3.SessionToken)
+	r.SMTP.Password = redact(r.SMTP.Password)
+	r.Digest.SigningKey = redact(r.Digest.SigningKey)
+	r.OIDC.SigningKey = redact(r.OIDC.SigningKey)
+	r.Slack.ClientSecret = redact(r.Slack.ClientSecret)
+	r.Slack.SigningSecret = redact(r.Slack.SigningSecret)
+	r.Webhook.Secret = redact(r.Webhook.Secret)
+	r.CI.GitHub.Token = redact(r.CI.GitHub.Token)
+	r.CI.GitLab.Token = redact(r.CI.GitLab.Token)
+	r.Carbon.Intensity.Token = redact(r.Carbon.Intensity.Token)
+	r.SecretStore.Vault.Token = redact(r.SecretStore.Vault.Token)
+	r.Server.AdminToken = redact(r.Server.AdminToken)
+	// Settings read from secret stores show their reference
+	if c.secrets != nil {
+		walkSettings(reflect.ValueOf(&r).Elem(), "", func(setting string, field reflect.Value) {
+			if ref, ok := c.secrets.refs[setting]; ok {
+				field.SetString(ref.String())
+			}
+		})
+	}
+	return r
+}
+
+// Secrets returns the non-empty secret values of the configuration, used to
+// scrub them from free-form text such as log files
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for _, s := range []string{
+		c.Database.Password,
+		c.Redis.Password,
+		c.AWS.AccessKeyID,
+		c.AWS.SecretAccessKey,
+		c.AWS.SessionToken,
+		c.Azure.ClientSecret,
+		c.Storage.SigningKey,
+		c.Storage.S3.SecretAccessKey,
+		c.SMTP.Password,
+		c.Digest.SigningKey,
+		c.OIDC.SigningKey,
+		c.Slack.ClientSecret,
+		c.Slack.SigningSecret,
+		c.Webhook.Secret,
+		c.CI.GitHub.Token,
+		c.CI.GitLab.Token,
+		c.Carbon.Intensity.Token,
+		c.SecretStore.Vault.Token,
+		c.Server.AdminToken,
+	} {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	if c.secrets != nil {
+		for _, s := range c.secrets.values {
+			if s != "" {
+				secrets = append(secrets, s)
+			}
+		}
+	}
+	return secrets
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redactedValue
+}
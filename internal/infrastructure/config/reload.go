@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/cloudsweep/cloudsweep/pkg/logger"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// reloadable are the settings a reload applies, by prefix: the operational
+// ones. Other settings are read once at startup.
+var reloadable = []string{
+	"log.",
+	"worker.concurrency",
+	"server.ratelimit.",
+	"ratelimit.",
+	"kubernetes.",
+	"readonly.",
+}
+
+// Live holds the configuration of a running process, whose operational
+// settings are reloaded without restarting it. It is safe for concurrent
+// use.
+type Live struct {
+	current atomic.Pointer[Config]
+
+	mu       sync.Mutex // serializes reloads
+	handlers []func(cfg *Config)
+}
+
+// NewLive creates a Live configuration starting from cfg, and sets the
+// log level of the process
+func NewLive(cfg *Config) *Live {
+	l := &Live{}
+	l.current.Store(cfg)
+	setLogLevel(cfg)
+	return l
+}
+
+// Get returns the current configuration. It must not be modified.
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// OnReload registers fn to be called with the new configuration after each
+// reload changing settings
+func (l *Live) OnReload(fn func(cfg *Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers = append(l.handlers, fn)
+}
+
+// ReloadResult lists the settings changed by a reload
+type ReloadResult struct {
+	Applied []string `json:"applied"`          // settings now in effect
+	Ignored []string `json:"restart_required"` // changed settings only read at startup
+}
+
+// Reload reads the configuration again and applies its operational
+// settings. An invalid configuration is rejected as a whole and the current
+// one is kept.
+func (l *Live) Reload(reason string) (ReloadResult, error) {
+	next, err := Load()
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		log.Printf("Configuration not reloaded (%s): %v", reason, err)
+		return ReloadResult{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := l.current.Load()
+	result := ReloadResult{Applied: []string{}, Ignored: []string{}}
+	before := current.settings()
+	for setting, value := range next.settings() {
+		if before[setting] == value {
+			continue
+		}
+		if isReloadable(setting) {
+			result.Applied = append(result.Applied, setting)
+		} else {
+			result.Ignored = append(result.Ignored, setting)
+		}
+	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.Ignored)
+
+	if len(result.Ignored) > 0 {
+		log.Printf("Configuration changes requiring a restart (%s): %s", reason, strings.Join(result.Ignored, ", "))
+	}
+	if len(result.Applied) == 0 {
+		return result, nil
+	}
+
+	merged := *current
+	merged.Log = next.Log
+	merged.Worker.Concurrency = next.Worker.Concurrency
+	merged.Server.RateLimit = next.Server.RateLimit
+	merged.RateLimit = next.RateLimit
+	merged.Kubernetes = next.Kubernetes
+	merged.ReadOnly = next.ReadOnly
+	l.current.Store(&merged)
+	setLogLevel(&merged)
+	for _, fn := range l.handlers {
+		fn(&merged)
+	}
+	log.Printf("Configuration reloaded (%s): %s", reason, strings.Join(result.Applied, ", "))
+	return result, nil
+}
+
+// Watch reloads the configuration when its file changes or the process
+// receives SIGHUP, until ctx is done
+func (l *Live) Watch(ctx context.Context) {
+	if file := l.Get().file; file != "" {
+		v := viper.New()
+		v.SetConfigFile(file)
+		v.OnConfigChange(func(fsnotify.Event) {
+			_, _ = l.Reload("config file changed")
+		})
+		v.WatchConfig()
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			_, _ = l.Reload("SIGHUP")
+		}
+	}
+}
+
+func setLogLevel(cfg *Config) {
+	if level, err := logger.ParseLevel(cfg.Log.Level); err == nil {
+		logger.SetLevel(level)
+	}
+}
+
+func isReloadable(setting string) bool {
+	for _, prefix := range reloadable {
+		if setting == prefix || strings.HasPrefix(setting, prefix) {
+			return true
+		}
+	}
+	return false
+}
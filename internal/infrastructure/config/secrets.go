@@ -0,0 +1,253 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretRefPrefix starts the value of a setting read from a secret store,
+// e.g. secret-ref://vault/secret/data/cloudsweep#db_password
+const SecretRefPrefix = "secret-ref://"
+
+// secretTimeout bounds the reading of all the secrets of the configuration
+const secretTimeout = 30 * time.Second
+
+// SecretRef references a secret of a secret store
+type SecretRef struct {
+	Backend string // registered backend, e.g. vault, aws-sm or ssm
+	Path    string // location of the secret, interpreted by the backend
+	Key     string // field of a JSON secret; empty uses the whole secret
+}
+
+// ParseSecretRef parses a secret-ref://<backend>/<path>[#<key>] value
+func ParseSecretRef(s string) (SecretRef, error) {
+	rest, ok := strings.CutPrefix(s, SecretRefPrefix)
+	if !ok {
+		return SecretRef{}, fmt.Errorf("secret reference must start with %s", SecretRefPrefix)
+	}
+	var ref SecretRef
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		rest, ref.Key = rest[:i], rest[i+1:]
+	}
+	ref.Backend, ref.Path, _ = strings.Cut(rest, "/")
+	if ref.Backend == "" || ref.Path == "" {
+		return SecretRef{}, fmt.Errorf("secret reference %q must be %s<backend>/<path>[#<key>]", s, SecretRefPrefix)
+	}
+	return ref, nil
+}
+
+// String returns the secret-ref:// form of the reference
+func (r SecretRef) String() string {
+	s := SecretRefPrefix + r.Backend + "/" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// SecretBackend reads secrets from a secret store
+type SecretBackend interface {
+	// Fetch returns the value of a secret; the key of the reference is
+	// applied by the caller
+	Fetch(ctx context.Context, ref SecretRef) (string, error)
+}
+
+// SecretBackendFactory creates a secret backend from the configuration. It
+// is called once the AWS settings are resolved.
+type SecretBackendFactory func(cfg *Config) (SecretBackend, error)
+
+var (
+	backendsMu     sync.RWMutex
+	secretBackends = map[string]SecretBackendFactory{
+		"vault": newVaultBackend,
+	}
+)
+
+// RegisterSecretBackend makes a secret backend available to secret
+// references. It panics when the name is already registered.
+func RegisterSecretBackend(name string, factory SecretBackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if _, ok := secretBackends[name]; ok {
+		panic(fmt.Sprintf("config: RegisterSecretBackend called twice for %s", name))
+	}
+	secretBackends[name] = factory
+}
+
+// secretSet holds the settings of a configuration read from secret stores
+type secretSet struct {
+	refs   map[string]SecretRef // by setting
+	values map[string]string    // values read at startup, by setting
+
+	mu       sync.Mutex
+	backends map[string]SecretBackend
+}
+
+// resolveSecrets replaces the secret references of the settings with their
+// value. The AWS settings are resolved first, since the AWS secret
+// backends authenticate with them.
+func (c *Config) resolveSecrets(ctx context.Context) error {
+	set := &secretSet{refs: map[string]SecretRef{}, values: map[string]string{}, backends: map[string]SecretBackend{}}
+	var errs []error
+	for _, aws := range []bool{true, false} {
+		walkSettings(reflect.ValueOf(c).Elem(), "", func(setting string, field reflect.Value) {
+			if strings.HasPrefix(setting, "aws.") != aws || !strings.HasPrefix(field.String(), SecretRefPrefix) {
+				return
+			}
+			if strings.HasPrefix(setting, "secretstore.") {
+				errs = append(errs, fmt.Errorf("%s: secret store settings cannot be secret references", setting))
+				return
+			}
+			ref, err := ParseSecretRef(field.String())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", setting, err))
+				return
+			}
+			value, err := set.fetch(ctx, c, ref)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", setting, err))
+				return
+			}
+			field.SetString(value)
+			set.refs[setting] = ref
+			set.values[setting] = value
+		})
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to read secrets: %w", errors.Join(errs...))
+	}
+	c.secrets = set
+	return nil
+}
+
+// fetch reads the value of a reference, creating its backend on first use
+func (s *secretSet) fetch(ctx context.Context, cfg *Config, ref SecretRef) (string, error) {
+	s.mu.Lock()
+	backend, ok := s.backends[ref.Backend]
+	if !ok {
+		backendsMu.RLock()
+		factory, known := secretBackends[ref.Backend]
+		backendsMu.RUnlock()
+		if !known {
+			s.mu.Unlock()
+			return "", fmt.Errorf("unknown secret backend %q", ref.Backend)
+		}
+		var err error
+		if backend, err = factory(cfg); err != nil {
+			s.mu.Unlock()
+			return "", err
+		}
+		s.backends[ref.Backend] = backend
+	}
+	s.mu.Unlock()
+
+	value, err := backend.Fetch(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if ref.Key == "" {
+		return value, nil
+	}
+
+	var fields map[string]any
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return "", fmt.Errorf("%s/%s is not a JSON object, it has no key %q", ref.Backend, ref.Path, ref.Key)
+	}
+	field, ok := fields[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("%s/%s has no key %q", ref.Backend, ref.Path, ref.Key)
+	}
+	switch field := field.(type) {
+	case string:
+		return field, nil
+	case json.Number, bool:
+		return fmt.Sprint(field), nil
+	default:
+		return "", fmt.Errorf("%s/%s: key %q is not a string", ref.Backend, ref.Path, ref.Key)
+	}
+}
+
+// RotatedSecrets reads the secret references again and returns the
+// settings whose secret changed since startup, sorted
+func (c *Config) RotatedSecrets(ctx context.Context) ([]string, error) {
+	if c.secrets == nil {
+		return nil, nil
+	}
+	var rotated []string
+	var errs []error
+	for setting, ref := range c.secrets.refs {
+		value, err := c.secrets.fetch(ctx, c, ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", setting, err))
+			continue
+		}
+		if value != c.secrets.values[setting] {
+			rotated = append(rotated, setting)
+		}
+	}
+	sort.Strings(rotated)
+	return rotated, errors.Join(errs...)
+}
+
+// WatchSecrets reads the secret references every refresh interval until ctx
+// is done or a secret was rotated, in which case onRotate is called with
+// the rotated settings. Settings are only read at startup, so callers
+// restart to apply rotated secrets. It returns at once when no setting is
+// a reference or the refresh is disabled.
+func (c *Config) WatchSecrets(ctx context.Context, onRotate func(settings []string)) {
+	if c.secrets == nil || len(c.secrets.refs) == 0 || c.SecretStore.RefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.SecretStore.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fetchCtx, cancel := context.WithTimeout(ctx, secretTimeout)
+		rotated, err := c.RotatedSecrets(fetchCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			// Keep the current secrets until the store can be read again
+			log.Printf("Failed to read secrets: %v", err)
+		}
+		if len(rotated) > 0 {
+			onRotate(rotated)
+			return
+		}
+	}
+}
+
+// walkSettings calls fn with every exported string setting of v, named by
+// its lower-cased path, e.g. database.password
+func walkSettings(v reflect.Value, prefix string, fn func(setting string, field reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.ToLower(f.Name)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		switch field := v.Field(i); {
+		case field.Kind() == reflect.String:
+			fn(name, field)
+		case field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Time{}):
+			walkSettings(field, name, fn)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/pkg/logger"
+)
+
+// devDefaults are the secrets Load defaults to, which must be replaced in
+// production
+var devDefaults = map[string]string{
+	"DB_PASSWORD":         "cloudsweep_secret",
+	"STORAGE_SIGNING_KEY": "cloudsweep-dev-signing-key",
+	"DIGEST_SIGNING_KEY":  "cloudsweep-dev-digest-key",
+	"OIDC_SIGNING_KEY":    "cloudsweep-dev-oidc-key",
+}
+
+// awsRegionPattern matches AWS region names such as eu-west-1,
+// us-gov-west-1 or cn-north-1
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]$`)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// ValidationError lists every problem found in a configuration, so that
+// they can all be fixed at once
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the configuration and returns a *ValidationError listing
+// every invalid value. In production, secrets must be set and must not be
+// the development defaults.
+func (c *Config) Validate() error {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !validPort(c.Server.Port) {
+		fail("SERVER_PORT %q is not a valid port", c.Server.Port)
+	}
+	if c.Server.RequestTimeout < 0 {
+		fail("SERVER_REQUEST_TIMEOUT must not be negative")
+	}
+	if c.Server.RateLimit.Enabled {
+		for name, limit := range map[string]RouteLimit{
+			"DEFAULT": c.Server.RateLimit.Default,
+			"SCANS":   c.Server.RateLimit.Scans,
+			"CLEANUP": c.Server.RateLimit.Cleanup,
+			"EXPORTS": c.Server.RateLimit.Exports,
+		} {
+			if limit.Requests <= 0 || limit.Period <= 0 {
+				fail("SERVER_RATE_LIMIT_%s_REQUESTS and SERVER_RATE_LIMIT_%s_PERIOD must be positive", name, name)
+			}
+		}
+	}
+
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		fail("LOG_LEVEL %q must be debug, info, warn or error", c.Log.Level)
+	}
+
+	if c.Database.Host == "" {
+		fail("DB_HOST is required")
+	}
+	if !validPort(c.Database.Port) {
+		fail("DB_PORT %q is not a valid port", c.Database.Port)
+	}
+	if c.Database.Name == "" {
+		fail("DB_NAME is required")
+	}
+	if !slices.Contains(sslModes, c.Database.SSLMode) {
+		fail("DB_SSLMODE %q must be one of %s", c.Database.SSLMode, strings.Join(sslModes, ", "))
+	}
+
+	if host, port, err := net.SplitHostPort(c.Redis.Addr); err != nil || host == "" || !validPort(port) {
+		fail("REDIS_ADDR %q must be host:port", c.Redis.Addr)
+	}
+	if c.Redis.DB < 0 || c.Redis.DB > 15 {
+		fail("REDIS_DB %d must be between 0 and 15", c.Redis.DB)
+	}
+
+	if c.Worker.Concurrency < 1 {
+		fail("WORKER_CONCURRENCY must be at least 1")
+	}
+	if c.Worker.ScanConcurrency < 1 {
+		fail("WORKER_SCAN_CONCURRENCY must be at least 1")
+	}
+	if c.Worker.FullScanInterval < 0 {
+		fail("WORKER_FULL_SCAN_INTERVAL must not be negative")
+	}
+	for _, name := range []string{"critical", "default", "low"} {
+		if c.Worker.QueueWeights[name] < 1 {
+			fail("worker.queueWeights.%s must be at least 1", name)
+		}
+	}
+	plans := make([]string, 0, len(c.Worker.PlanWeights))
+	for plan := range c.Worker.PlanWeights {
+		plans = append(plans, plan)
+	}
+	sort.Strings(plans)
+	for _, plan := range plans {
+		if c.Worker.PlanWeights[plan] < 1 {
+			fail("worker.planWeights.%s must be at least 1", plan)
+		}
+	}
+	if c.Worker.AdminAddr != "" {
+		if _, port, err := net.SplitHostPort(c.Worker.AdminAddr); err != nil || !validPort(port) {
+			fail("WORKER_ADMIN_ADDR %q must be [host]:port", c.Worker.AdminAddr)
+		}
+	}
+
+	if c.Concurrency.Enabled {
+		for name, n := range map[string]int{
+			"PER_ACCOUNT": c.Concurrency.PerAccount,
+			"GLOBAL":      c.Concurrency.Global,
+			"AWS":         c.Concurrency.AWS,
+			"AZURE":       c.Concurrency.Azure,
+			"GCP":         c.Concurrency.GCP,
+			"KUBERNETES":  c.Concurrency.Kubernetes,
+		} {
+			if n < 0 {
+				fail("CONCURRENCY_%s must not be negative", name)
+			}
+		}
+		if c.Concurrency.LeaseTTL < 3*time.Second {
+			fail("CONCURRENCY_LEASE_TTL must be at least 3s")
+		}
+		if c.Concurrency.RetryDelay <= 0 {
+			fail("CONCURRENCY_RETRY_DELAY must be positive")
+		}
+	}
+
+	if c.AWS.Region != "" && !awsRegionPattern.MatchString(c.AWS.Region) {
+		fail("AWS_REGION %q is not a valid AWS region", c.AWS.Region)
+	}
+
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalPath == "" {
+			fail("STORAGE_LOCAL_PATH is required with the local storage backend")
+		}
+	case "s3":
+		if c.Storage.S3.Bucket == "" {
+			fail("STORAGE_S3_BUCKET is required with the s3 storage backend")
+		}
+		if c.Storage.S3.Endpoint == "" && !awsRegionPattern.MatchString(c.Storage.S3.Region) {
+			fail("STORAGE_S3_REGION %q is not a valid AWS region", c.Storage.S3.Region)
+		}
+	default:
+		fail("STORAGE_BACKEND %q must be local or s3", c.Storage.Backend)
+	}
+
+	for env, value := range map[string]string{
+		"STORAGE_PUBLIC_URL":     c.Storage.PublicURL,
+		"WEBHOOK_SCAN_URL":       c.Webhook.ScanURL,
+		"PRICING_CATALOG_URL":    c.Pricing.CatalogURL,
+		"TICKETING_RESOURCE_URL": c.Ticketing.ResourceURL,
+		"OWNERS_RESOURCE_URL":    c.Owners.ResourceURL,
+		"SUMMARY_DASHBOARD_URL":  c.Summary.DashboardURL,
+	} {
+		if value != "" && !validURL(value) {
+			fail("%s %q is not an absolute http(s) URL", env, value)
+		}
+	}
+
+	if c.Slack.ClientID != "" {
+		if c.Slack.ClientSecret == "" || c.Slack.SigningSecret == "" {
+			fail("SLACK_CLIENT_SECRET and SLACK_SIGNING_SECRET are required with SLACK_CLIENT_ID")
+		}
+		if !validURL(c.Slack.RedirectURL) {
+			fail("SLACK_REDIRECT_URL %q is not an absolute http(s) URL", c.Slack.RedirectURL)
+		}
+	}
+
+	if c.Notifications.DedupWindow < 0 {
+		fail("NOTIFICATIONS_DEDUP_WINDOW must not be negative")
+	}
+	if c.Notifications.CoalesceDelay <= 0 {
+		fail("NOTIFICATIONS_COALESCE_DELAY must be positive")
+	}
+	if c.Cache.DashboardTTL < 0 || c.Cache.SettingsTTL < 0 {
+		fail("CACHE_DASHBOARD_TTL and CACHE_SETTINGS_TTL must not be negative")
+	}
+
+	if c.Billing.Window < 24*time.Hour {
+		fail("BILLING_WINDOW %s must be at least a day", c.Billing.Window)
+	}
+
+	if c.Server.Environment == "production" {
+		for env, value := range map[string]string{
+			"DB_PASSWORD":         c.Database.Password,
+			"STORAGE_SIGNING_KEY": c.Storage.SigningKey,
+			"DIGEST_SIGNING_KEY":  c.Digest.SigningKey,
+			"OIDC_SIGNING_KEY":    c.OIDC.SigningKey,
+		} {
+			switch value {
+			case "":
+				fail("%s is required in production", env)
+			case devDefaults[env]:
+				fail("%s must not be the development default in production", env)
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	// Maps are iterated in random order
+	sort.Strings(problems)
+	return &ValidationError{Problems: problems}
+}
+
+// Dump returns the redacted configuration, one setting per line sorted by
+// key, to be logged at startup
+func (c *Config) Dump() string {
+	redacted := c.Redacted()
+	var lines []string
+	for setting, value := range redacted.settings() {
+		lines = append(lines, setting+"="+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// settings returns the value of every setting, by lower-cased path
+func (c *Config) settings() map[string]string {
+	settings := map[string]string{}
+	flatten(reflect.ValueOf(*c), "", settings)
+	return settings
+}
+
+func flatten(v reflect.Value, prefix string, settings map[string]string) {
+	if v.Kind() == reflect.Struct && v.Type() != reflect.TypeOf(time.Time{}) {
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			name := strings.ToLower(v.Type().Field(i).Name)
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			flatten(v.Field(i), name, settings)
+		}
+		return
+	}
+	settings[prefix] = fmt.Sprintf("%v", v.Interface())
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
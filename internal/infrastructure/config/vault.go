@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// vaultBackend reads secrets from the HTTP API of HashiCorp Vault. The path
+// of a reference is the API path of the secret, e.g. secret/data/cloudsweep
+// for the cloudsweep secret of a KV version 2 engine mounted at secret/.
+type vaultBackend struct {
+	cfg  VaultConfig
+	http *http.Client
+}
+
+func newVaultBackend(cfg *Config) (SecretBackend, error) {
+	vault := cfg.SecretStore.Vault
+	if vault.Addr == "" || vault.Token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN are required to read vault secrets")
+	}
+	return &vaultBackend{cfg: vault, http: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Fetch returns the data of the secret as a JSON object
+func (b *vaultBackend) Fetch(ctx context.Context, ref SecretRef) (string, error) {
+	url := strings.TrimSuffix(b.cfg.Addr, "/") + "/v1/" + strings.TrimPrefix(ref.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", b.cfg.Token)
+	if b.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.cfg.Namespace)
+	}
+
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault %s: %w", ref.Path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("vault %s: %w", ref.Path, err)
+	}
+
+	var secret struct {
+		Data   map[string]any `json:"data"`
+		Errors []string       `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("vault %s: invalid response: %w", ref.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(secret.Errors) > 0 {
+			return "", fmt.Errorf("vault %s returned %d: %s", ref.Path, resp.StatusCode, strings.Join(secret.Errors, "; "))
+		}
+		return "", fmt.Errorf("vault %s returned %d", ref.Path, resp.StatusCode)
+	}
+
+	// KV version 2 engines nest the secret under data, next to its metadata
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = inner
+		}
+	}
+	out, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CleanupSessionRepository stores cleanup sessions in PostgreSQL
+type CleanupSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewCleanupSessionRepository creates a new CleanupSessionRepository
+func NewCleanupSessionRepository(db *gorm.DB) *CleanupSessionRepository {
+	return &CleanupSessionRepository{db: db}
+}
+
+var _ repository.CleanupSessionRepository = (*CleanupSessionRepository)(nil)
+
+// Create implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) Create(ctx context.Context, session *entity.CleanupSession, resourceIDs []uuid.UUID) error {
+	filters := make(model.JSONB, len(session.Filters))
+	for k, v := range session.Filters {
+		filters[k] = v
+	}
+	m := model.CleanupSession{
+		ID:             session.ID,
+		OrganizationID: session.OrganizationID,
+		Action:         string(session.Action),
+		Filters:        filters,
+		Status:         string(session.Status),
+		CreatedBy:      session.CreatedBy,
+	}
+	items := make([]model.CleanupSessionItem, len(resourceIDs))
+	for i, id := range resourceIDs {
+		items[i] = model.CleanupSessionItem{
+			SessionID:  session.ID,
+			ResourceID: id,
+			Position:   i,
+			Decision:   string(entity.CleanupDecisionPending),
+		}
+	}
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Resource").CreateInBatches(items, bulkChunkSize).Error
+	})
+	if err != nil {
+		return err
+	}
+	session.CreatedAt, session.UpdatedAt = m.CreatedAt, m.UpdatedAt
+	return nil
+}
+
+// GetByID implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.CleanupSession, error) {
+	return r.first(conn(ctx, r.db), id)
+}
+
+// Lock implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) Lock(ctx context.Context, id uuid.UUID) (*entity.CleanupSession, error) {
+	return r.first(conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (r *CleanupSessionRepository) first(query *gorm.DB, id uuid.UUID) (*entity.CleanupSession, error) {
+	var m model.CleanupSession
+	if err := query.First(&m, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return cleanupSessionEntity(&m), nil
+}
+
+// UpdateStatus implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) UpdateStatus(ctx context.Context, session *entity.CleanupSession, from entity.CleanupSessionStatus) (bool, error) {
+	result := conn(ctx, r.db).Model(&model.CleanupSession{}).
+		Where("id = ? AND status = ?", session.ID, string(from)).
+		Updates(map[string]any{
+			"status":      string(session.Status),
+			"dry_run":     session.DryRun,
+			"executed_at": session.ExecutedAt,
+			"task_id":     session.TaskID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Decide implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) Decide(ctx context.Context, sessionID uuid.UUID, resourceIDs []uuid.UUID, decision entity.CleanupDecision, decidedBy string, decidedAt *time.Time) error {
+	db := conn(ctx, r.db)
+	err := db.Model(&model.CleanupSessionItem{}).
+		Where("session_id = ? AND resource_id IN ?", sessionID, resourceIDs).
+		Updates(map[string]any{"decision": string(decision), "decided_by": decidedBy, "decided_at": decidedAt}).Error
+	if err != nil {
+		return err
+	}
+	return db.Model(&model.CleanupSession{}).Where("id = ?", sessionID).Update("updated_at", time.Now()).Error
+}
+
+// ListItems implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) ListItems(ctx context.Context, filter repository.CleanupSessionItemFilter) ([]*entity.CleanupSessionItem, error) {
+	query := r.itemQuery(ctx, filter).Preload("Resource").Order("position").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var items []model.CleanupSessionItem
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.CleanupSessionItem, len(items))
+	for i := range items {
+		m := &items[i]
+		out[i] = &entity.CleanupSessionItem{
+			SessionID:  m.SessionID,
+			ResourceID: m.ResourceID,
+			Position:   m.Position,
+			Decision:   entity.CleanupDecision(m.Decision),
+			DecidedBy:  m.DecidedBy,
+			DecidedAt:  m.DecidedAt,
+			Resource:   ResourceEntity(&m.Resource),
+		}
+	}
+	return out, nil
+}
+
+// CountItems implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) CountItems(ctx context.Context, filter repository.CleanupSessionItemFilter) (int64, error) {
+	var total int64
+	err := r.itemQuery(ctx, filter).Count(&total).Error
+	return total, err
+}
+
+func (r *CleanupSessionRepository) itemQuery(ctx context.Context, filter repository.CleanupSessionItemFilter) *gorm.DB {
+	query := conn(ctx, r.db).Model(&model.CleanupSessionItem{}).Where("session_id = ?", filter.SessionID)
+	if filter.Decision != nil {
+		query = query.Where("decision = ?", string(*filter.Decision))
+	}
+	return query
+}
+
+// Tally implements repository.CleanupSessionRepository
+func (r *CleanupSessionRepository) Tally(ctx context.Context, sessionID uuid.UUID) (map[entity.CleanupDecision]entity.CleanupSessionTally, error) {
+	var rows []struct {
+		Decision string
+		Count    int
+		Cost     float64
+		Carbon   float64
+	}
+	err := conn(ctx, r.db).Table("cleanup_session_items AS i").
+		Select("i.decision, COUNT(*) AS count, COALESCE(SUM(r.monthly_cost), 0) AS cost, COALESCE(SUM(r.carbon_footprint), 0) AS carbon").
+		Joins("JOIN resources r ON r.id = i.resource_id").
+		Where("i.session_id = ?", sessionID).
+		Group("i.decision").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[entity.CleanupDecision]entity.CleanupSessionTally, len(rows))
+	for _, row := range rows {
+		out[entity.CleanupDecision(row.Decision)] = entity.CleanupSessionTally{Count: row.Count, MonthlyCost: row.Cost, CarbonFootprint: row.Carbon}
+	}
+	return out, nil
+}
+
+// cleanupSessionEntity converts a stored cleanup session to its domain
+// entity
+func cleanupSessionEntity(m *model.CleanupSession) *entity.CleanupSession {
+	filters := make(map[string]string, len(m.Filters))
+	for k, v := range m.Filters {
+		if str, ok := v.(string); ok {
+			filters[k] = str
+		}
+	}
+	return &entity.CleanupSession{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Action:         entity.PolicyAction(m.Action),
+		Filters:        filters,
+		Status:         entity.CleanupSessionStatus(m.Status),
+		CreatedBy:      m.CreatedBy,
+		TaskID:         m.TaskID,
+		DryRun:         m.DryRun,
+		ExecutedAt:     m.ExecutedAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// CloudAccountRepository reads cloud accounts from PostgreSQL
+type CloudAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewCloudAccountRepository creates a new CloudAccountRepository
+func NewCloudAccountRepository(db *gorm.DB) *CloudAccountRepository {
+	return &CloudAccountRepository{db: db}
+}
+
+var _ repository.CloudAccountRepository = (*CloudAccountRepository)(nil)
+
+// List implements repository.CloudAccountRepository
+func (r *CloudAccountRepository) List(ctx context.Context, filter repository.CloudAccountFilter) ([]*entity.CloudAccount, error) {
+	query := conn(ctx, r.db)
+	if filter.OrganizationIDs != nil {
+		query = query.Where("organization_id IN ?", filter.OrganizationIDs)
+	}
+	if filter.Provider != nil {
+		query = query.Where("provider = ?", string(*filter.Provider))
+	}
+
+	var models []model.CloudAccount
+	if err := query.Order("provider, account_id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*entity.CloudAccount, len(models))
+	for i, m := range models {
+		accounts[i] = &entity.CloudAccount{
+			ID:             m.ID,
+			OrganizationID: m.OrganizationID,
+			Provider:       entity.CloudProvider(m.Provider),
+			AccountID:      m.AccountID,
+			Name:           m.Name,
+			Credentials:    m.Credentials,
+			IsActive:       m.IsActive,
+			LastSyncAt:     m.LastSyncAt,
+			CreatedAt:      m.CreatedAt,
+			UpdatedAt:      m.UpdatedAt,
+		}
+	}
+	return accounts, nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CustomResourceTypeRepository stores custom resource types in PostgreSQL
+type CustomResourceTypeRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomResourceTypeRepository creates a new CustomResourceTypeRepository
+func NewCustomResourceTypeRepository(db *gorm.DB) *CustomResourceTypeRepository {
+	return &CustomResourceTypeRepository{db: db}
+}
+
+var _ repository.CustomResourceTypeRepository = (*CustomResourceTypeRepository)(nil)
+
+// ListByProvider implements repository.CustomResourceTypeRepository
+func (r *CustomResourceTypeRepository) ListByProvider(ctx context.Context, orgID uuid.UUID, provider entity.CloudProvider) ([]*entity.CustomResourceType, error) {
+	var models []model.CustomResourceType
+	if err := conn(ctx, r.db).Where("organization_id = ? AND provider = ?", orgID, string(provider)).Order("name").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	types := make([]*entity.CustomResourceType, len(models))
+	for i := range models {
+		types[i] = CustomResourceTypeEntity(&models[i])
+	}
+	return types, nil
+}
+
+// CustomResourceTypeEntity converts a stored custom resource type to its
+// domain entity
+func CustomResourceTypeEntity(m *model.CustomResourceType) *entity.CustomResourceType {
+	return &entity.CustomResourceType{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Name:           entity.ResourceType(m.Name),
+		Provider:       entity.CloudProvider(m.Provider),
+		CloudType:      m.CloudType,
+		Detection: entity.MetricDetection{
+			Namespace:    m.MetricNamespace,
+			MetricName:   m.MetricName,
+			Dimension:    m.MetricDimension,
+			Statistic:    m.MetricStatistic,
+			Threshold:    m.MetricThreshold,
+			Above:        m.MetricAbove,
+			LookbackDays: m.MetricLookbackDays,
+		},
+		MonthlyCost: m.MonthlyCost,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// wasteGroupColumns are the columns unused resources are grouped by
+var wasteGroupColumns = map[repository.WasteGroupBy]string{
+	repository.WasteByProvider: "provider",
+	repository.WasteByType:     "type",
+	repository.WasteByRegion:   "region",
+	repository.WasteByAccount:  "account_id",
+}
+
+// DashboardRepository computes dashboard aggregates in PostgreSQL
+type DashboardRepository struct {
+	db *gorm.DB
+}
+
+// NewDashboardRepository creates a new DashboardRepository
+func NewDashboardRepository(db *gorm.DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+var _ repository.DashboardRepository = (*DashboardRepository)(nil)
+
+// resources returns a query of the resources in a scope
+func (r *DashboardRepository) resources(ctx context.Context, scope repository.DashboardScope) *gorm.DB {
+	query := conn(ctx, r.db).Model(&model.Resource{}).Where("organization_id = ?", scope.OrganizationID)
+	if scope.Provider != nil {
+		query = query.Where("provider = ?", string(*scope.Provider))
+	}
+	if scope.AccountID != nil {
+		query = query.Where("account_id = ?", *scope.AccountID)
+	}
+	if !scope.From.IsZero() {
+		query = query.Where("last_seen_at >= ?", scope.From)
+	}
+	if !scope.To.IsZero() {
+		query = query.Where("created_at < ?", scope.To)
+	}
+	return query
+}
+
+// inventoryTotals selects the columns of repository.InventoryTotals
+const inventoryTotals = `COUNT(*) FILTER (WHERE status NOT IN ('deleted', 'removed')) AS resources,
+	COUNT(*) FILTER (WHERE status = 'unused') AS unused,
+	COALESCE(SUM(monthly_cost) FILTER (WHERE status NOT IN ('deleted', 'removed')), 0) AS monthly_cost,
+	COALESCE(SUM(monthly_cost) FILTER (WHERE status = 'unused'), 0) AS waste_cost,
+	COALESCE(SUM(carbon_footprint) FILTER (WHERE status NOT IN ('deleted', 'removed')), 0) AS carbon_footprint,
+	COALESCE(SUM(carbon_footprint) FILTER (WHERE status = 'unused'), 0) AS waste_carbon`
+
+// Totals implements repository.DashboardRepository. All figures are
+// computed in one pass over the resources of the scope.
+func (r *DashboardRepository) Totals(ctx context.Context, scope repository.DashboardScope) (repository.InventoryTotals, error) {
+	var totals repository.InventoryTotals
+	err := r.resources(ctx, scope).Select(inventoryTotals).Scan(&totals).Error
+	return totals, err
+}
+
+// TotalsByAccount implements repository.DashboardRepository
+func (r *DashboardRepository) TotalsByAccount(ctx context.Context, accounts []repository.AccountKey) (map[repository.AccountKey]repository.InventoryTotals, error) {
+	totals := make(map[repository.AccountKey]repository.InventoryTotals, len(accounts))
+	if len(accounts) == 0 {
+		return totals, nil
+	}
+
+	keys := make([][]any, len(accounts))
+	for i, account := range accounts {
+		keys[i] = []any{account.OrganizationID, string(account.Provider), account.AccountID}
+	}
+	var rows []struct {
+		repository.AccountKey
+		repository.InventoryTotals
+	}
+	err := conn(ctx, r.db).Model(&model.Resource{}).
+		Select("organization_id, provider, account_id, "+inventoryTotals).
+		Where("(organization_id, provider, account_id) IN ?", keys).
+		Group("organization_id, provider, account_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		totals[row.AccountKey] = row.InventoryTotals
+	}
+	return totals, nil
+}
+
+// Waste implements repository.DashboardRepository. Groups are ranked by
+// decreasing cost, or carbon footprint.
+func (r *DashboardRepository) Waste(ctx context.Context, scope repository.DashboardScope, grouping repository.WasteGrouping) ([]repository.WasteTotal, error) {
+	query := r.resources(ctx, scope).Where("status = ?", string(entity.ResourceStatusUnused))
+	sums := `COUNT(*) AS count,
+		COALESCE(SUM(monthly_cost), 0) AS monthly_cost,
+		COALESCE(SUM(carbon_footprint), 0) AS carbon_footprint`
+	if grouping.By == repository.WasteByTag {
+		query = query.
+			Select(`COALESCE(tags->>?, '') AS "group",
+				NOT COALESCE(jsonb_exists(tags, ?), false) AS untagged, `+sums, grouping.TagKey, grouping.TagKey).
+			Group(`"group", untagged`)
+	} else {
+		query = query.Select(wasteGroupColumns[grouping.By] + ` AS "group", ` + sums).Group(`"group"`)
+	}
+
+	if grouping.ByCarbon {
+		query = query.Order("carbon_footprint DESC")
+	} else {
+		query = query.Order("monthly_cost DESC")
+	}
+	if grouping.Limit > 0 {
+		query = query.Limit(grouping.Limit)
+	}
+
+	var totals []repository.WasteTotal
+	err := query.Scan(&totals).Error
+	return totals, err
+}
+
+// Unused implements repository.DashboardRepository
+func (r *DashboardRepository) Unused(ctx context.Context, scope repository.DashboardScope, limit int) ([]*entity.Resource, error) {
+	var models []model.Resource
+	err := r.resources(ctx, scope).
+		Where("status = ?", string(entity.ResourceStatusUnused)).
+		Order("monthly_cost DESC, id").Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*entity.Resource, len(models))
+	for i := range models {
+		resources[i] = ResourceEntity(&models[i])
+	}
+	return resources, nil
+}
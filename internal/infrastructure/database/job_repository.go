@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobRepository reads jobs from PostgreSQL
+type JobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository creates a new JobRepository
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+var _ repository.JobRepository = (*JobRepository)(nil)
+
+// GetByID implements repository.JobRepository
+func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	var m model.Job
+	if err := conn(ctx, r.db).First(&m, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return JobEntity(&m), nil
+}
+
+// JobEntity converts a stored job to its domain entity
+func JobEntity(m *model.Job) *entity.Job {
+	return &entity.Job{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Type:           entity.JobType(m.Type),
+		TaskID:         m.TaskID,
+		TargetID:       m.TargetID,
+		Status:         entity.JobStatus(m.Status),
+		Progress:       m.Progress,
+		Attempts:       m.Attempts,
+		Error:          m.Error,
+		StartedAt:      m.StartedAt,
+		CompletedAt:    m.CompletedAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
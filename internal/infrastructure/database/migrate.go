@@ -0,0 +1,478 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/migrations"
+	"gorm.io/gorm"
+)
+
+// migrationLockID is the advisory lock serializing migrations across
+// instances started together
+const migrationLockID = 4173290563
+
+// baselineVersion is the schema created by AutoMigrate just before
+// versioned migrations
+const baselineVersion = 1
+
+// ErrSchemaVersion is returned when the database schema is not at the
+// version expected by the binary
+var ErrSchemaVersion = errors.New("database schema version mismatch")
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// noTransaction starts the migration files whose statements cannot run in a
+// transaction, e.g. CREATE INDEX CONCURRENTLY
+const noTransaction = "-- migrate:no-transaction"
+
+// Migration is a versioned schema change and its rollback
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies the embedded SQL migrations. The schema version is kept in
+// the schema_migrations table the way golang-migrate keeps it, so that
+// either can manage the schema.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a Migrator of the embedded migrations
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	list, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: list}, nil
+}
+
+// loadMigrations reads the migrations of a directory, by version
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, name := range names {
+		match := migrationFile.FindStringSubmatch(name)
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", name)
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[m.Version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", m.Version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Latest returns the version of the last migration, the one the binary
+// expects
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Migrations returns the migrations, by version
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Version returns the current schema version, 0 when no migration has run,
+// and whether a migration failed halfway
+func (m *Migrator) Version(ctx context.Context) (uint, bool, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable("schema_migrations") {
+		return 0, false, nil
+	}
+	return currentVersion(db)
+}
+
+// Check returns ErrSchemaVersion unless the schema is at the latest version
+func (m *Migrator) Check(ctx context.Context) error {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("%w: migration %d failed halfway, fix the schema then force the version", ErrSchemaVersion, version)
+	}
+	if version != m.Latest() {
+		return fmt.Errorf("%w: database is at %d, binary expects %d", ErrSchemaVersion, version, m.Latest())
+	}
+	return nil
+}
+
+// Up applies the pending migrations. It returns the number of migrations
+// applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.Goto(ctx, m.Latest())
+}
+
+// Down rolls back the last steps migrations. It returns the number of
+// migrations rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	var target uint
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		version, _, err := currentVersion(conn)
+		if err != nil {
+			return err
+		}
+		target = version
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			if m.migrations[i].Version > version {
+				continue
+			}
+			target = 0
+			if i > 0 {
+				target = m.migrations[i-1].Version
+			}
+			steps--
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return m.Goto(ctx, target)
+}
+
+// Goto migrates the schema up or down to a version. It returns the number of
+// migrations applied or rolled back.
+func (m *Migrator) Goto(ctx context.Context, target uint) (int, error) {
+	if target != 0 && m.find(target) < 0 {
+		return 0, fmt.Errorf("unknown migration version %d", target)
+	}
+
+	count := 0
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		version, dirty, err := currentVersion(conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: migration %d failed halfway, fix the schema then force the version", ErrSchemaVersion, version)
+		}
+		if version == 0 && target > 0 {
+			if version, err = m.adopt(conn); err != nil {
+				return err
+			}
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version <= version || mig.Version > target {
+				continue
+			}
+			log.Printf("Applying migration %d_%s", mig.Version, mig.Name)
+			if err := m.run(conn, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			count++
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version > version || mig.Version <= target {
+				continue
+			}
+			previous := uint(0)
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			log.Printf("Rolling back migration %d_%s", mig.Version, mig.Name)
+			if err := m.run(conn, mig.Down, previous); err != nil {
+				return fmt.Errorf("rollback of migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Force sets the schema version without running any migration, to recover
+// from a failed migration once the schema has been fixed by hand
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if version != 0 && m.find(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.locked(ctx, func(conn *gorm.DB) error {
+		return setVersion(conn, version, false)
+	})
+}
+
+// adopt brings databases created by AutoMigrate to the baseline schema and
+// records its version. Releases before versioned migrations created fewer
+// tables and columns than the baseline migration, so the missing ones are
+// added from it; the version is only recorded once every baseline table and
+// column exists. It returns the resulting version.
+func (m *Migrator) adopt(conn *gorm.DB) (uint, error) {
+	if !conn.Migrator().HasTable(autoMigrateTables[0]) {
+		return 0, nil
+	}
+	for _, table := range autoMigrateTables[1:] {
+		if !conn.Migrator().HasTable(table) {
+			return 0, fmt.Errorf("%w: existing schema without version has no %s table, it was not created by CloudSweep", ErrSchemaVersion, table)
+		}
+	}
+	i := m.find(baselineVersion)
+	if i < 0 {
+		return 0, fmt.Errorf("baseline migration %d is missing", baselineVersion)
+	}
+	baseline := m.migrations[i].Up
+
+	log.Printf("Existing schema without version, bringing it to baseline migration %d", baselineVersion)
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range adoptStatements(baseline) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to bring existing schema to baseline migration %d: %w", baselineVersion, err)
+			}
+		}
+		missing, err := missingColumns(tx, parseTables(baseline))
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: existing schema is missing %s of baseline migration %d", ErrSchemaVersion, strings.Join(missing, ", "), baselineVersion)
+		}
+		return setVersion(tx, baselineVersion, false)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return baselineVersion, nil
+}
+
+// autoMigrateTables are the tables created by AutoMigrate in every release
+// before versioned migrations, organizations first
+var autoMigrateTables = []string{"organizations", "cloud_accounts", "resources", "scans", "policies"}
+
+var (
+	createTable = regexp.MustCompile(`^CREATE TABLE "(\w+)" \(`)
+	tableColumn = regexp.MustCompile(`^\s+"(\w+)" (.+?),?$`)
+	createIndex = regexp.MustCompile(`^CREATE (UNIQUE )?INDEX "`)
+)
+
+// schemaTable is a table of a migration and the definitions of its columns
+type schemaTable struct {
+	name    string
+	columns [][2]string // name, definition
+}
+
+// parseTables returns the tables created by a migration
+func parseTables(sql string) []schemaTable {
+	var tables []schemaTable
+	for _, statement := range splitStatements(sql) {
+		lines := strings.Split(statement, "\n")
+		match := createTable.FindStringSubmatch(lines[0])
+		if match == nil {
+			continue
+		}
+		table := schemaTable{name: match[1]}
+		for _, line := range lines[1:] {
+			if column := tableColumn.FindStringSubmatch(line); column != nil {
+				table.columns = append(table.columns, [2]string{column[1], column[2]})
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// adoptStatements rewrites the baseline migration to run on a schema that
+// has part of it: tables and indexes are created if missing, and the columns
+// of existing tables added if missing
+func adoptStatements(sql string) []string {
+	tables := map[string]schemaTable{}
+	for _, t := range parseTables(sql) {
+		tables[t.name] = t
+	}
+
+	var statements []string
+	for _, statement := range splitStatements(sql) {
+		if match := createTable.FindStringSubmatch(statement); match != nil {
+			statements = append(statements, strings.Replace(statement, "CREATE TABLE ", "CREATE TABLE IF NOT EXISTS ", 1))
+			for _, column := range tables[match[1]].columns {
+				statements = append(statements, fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN IF NOT EXISTS "%s" %s;`, match[1], column[0], column[1]))
+			}
+			continue
+		}
+		if loc := createIndex.FindStringIndex(statement); loc != nil {
+			statement = statement[:loc[1]-1] + "IF NOT EXISTS " + statement[loc[1]-1:]
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// missingColumns returns the columns of tables missing from the current
+// schema, as table.column
+func missingColumns(db *gorm.DB, tables []schemaTable) ([]string, error) {
+	var rows []struct {
+		TableName  string
+		ColumnName string
+	}
+	err := db.Raw("SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = current_schema()").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema columns: %w", err)
+	}
+	existing := make(map[string]bool, len(rows))
+	for _, r := range rows {
+		existing[r.TableName+"."+r.ColumnName] = true
+	}
+
+	var missing []string
+	for _, t := range tables {
+		for _, column := range t.columns {
+			if name := t.name + "." + column[0]; !existing[name] {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// run executes a migration and records the version it leads to, in a
+// transaction so that a failure leaves the schema unchanged. Migrations
+// starting with the no-transaction comment run statement by statement
+// instead, with the version dirty until the last one succeeds.
+func (m *Migrator) run(conn *gorm.DB, sql string, version uint) error {
+	if strings.HasPrefix(sql, noTransaction) {
+		if err := setVersion(conn, version, true); err != nil {
+			return err
+		}
+		for _, statement := range splitStatements(sql) {
+			if err := conn.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return setVersion(conn, version, false)
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+		return setVersion(tx, version, false)
+	})
+}
+
+// splitStatements splits a migration into its statements, which end with a
+// semicolon at the end of a line. Comment lines are dropped.
+func splitStatements(sql string) []string {
+	var statements []string
+	var current []string
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, strings.Join(current, "\n"))
+	}
+	return statements
+}
+
+// locked runs fn on a single connection holding the migration lock, once
+// the version table exists
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+
+		err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`).Error
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+// find returns the index of a version, or -1
+func (m *Migrator) find(version uint) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// currentVersion reads schema_migrations, which holds a single row once a
+// migration has run
+func currentVersion(db *gorm.DB) (uint, bool, error) {
+	var rows []struct {
+		Version uint
+		Dirty   bool
+	}
+	if err := db.Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&rows).Error; err != nil {
+		return 0, false, err
+	}
+	if len(rows) == 0 {
+		return 0, false, nil
+	}
+	return rows[0].Version, rows[0].Dirty, nil
+}
+
+// setVersion replaces the recorded version; version 0 leaves no row. Only
+// migrations running outside a transaction leave a version dirty, when they
+// fail halfway.
+func setVersion(db *gorm.DB, version uint, dirty bool) error {
+	if err := db.Exec("DELETE FROM schema_migrations").Error; err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	return db.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)", version, dirty).Error
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/migrations"
+)
+
+func TestAdoptStatements(t *testing.T) {
+	list, err := loadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+	if list[0].Version != baselineVersion {
+		t.Fatalf("first migration = %d, want baseline %d", list[0].Version, baselineVersion)
+	}
+	statements := adoptStatements(list[0].Up)
+
+	want := []string{
+		`ALTER TABLE "organizations" ADD COLUMN IF NOT EXISTS "default_regions" jsonb;`,
+		`ALTER TABLE "resources" ADD COLUMN IF NOT EXISTS "iac_managed" boolean DEFAULT false;`,
+		`ALTER TABLE "scans" ADD COLUMN IF NOT EXISTS "fingerprint" varchar(64);`,
+		`ALTER TABLE "policies" ADD COLUMN IF NOT EXISTS "view_id" uuid;`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS "idx_resources_identity" ON "resources" ("organization_id","provider","resource_id");`,
+		`CREATE INDEX IF NOT EXISTS "idx_resources_name_trgm" ON "resources" USING gin ("name" gin_trgm_ops);`,
+	}
+	for _, w := range want {
+		found := false
+		for _, s := range statements {
+			found = found || s == w
+		}
+		if !found {
+			t.Errorf("adoptStatements() is missing %s", w)
+		}
+	}
+
+	for _, s := range statements {
+		if strings.HasPrefix(s, "CREATE") && !strings.Contains(s, "IF NOT EXISTS") {
+			t.Errorf("adoptStatements() statement is not idempotent: %s", s)
+		}
+		if strings.Contains(s, "PRIMARY KEY") && strings.HasPrefix(s, "ALTER") {
+			t.Errorf("adoptStatements() adds a constraint as a column: %s", s)
+		}
+	}
+}
+
+func TestParseTables(t *testing.T) {
+	sql := `CREATE TABLE "things" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "name" varchar(255) NOT NULL,
+    "owner_id" uuid,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_things_owner" FOREIGN KEY ("owner_id") REFERENCES "owners"("id")
+);
+CREATE INDEX "idx_things_name" ON "things" ("name");`
+
+	tables := parseTables(sql)
+	if len(tables) != 1 || tables[0].name != "things" {
+		t.Fatalf("parseTables() = %v, want the things table", tables)
+	}
+	want := [][2]string{
+		{"id", "uuid DEFAULT gen_random_uuid()"},
+		{"name", "varchar(255) NOT NULL"},
+		{"owner_id", "uuid"},
+	}
+	if len(tables[0].columns) != len(want) {
+		t.Fatalf("parseTables() columns = %v, want %v", tables[0].columns, want)
+	}
+	for i, c := range tables[0].columns {
+		if c != want[i] {
+			t.Errorf("parseTables() column %d = %v, want %v", i, c, want[i])
+		}
+	}
+}
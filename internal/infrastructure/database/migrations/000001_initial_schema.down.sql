@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS "cost_allocations";
+DROP TABLE IF EXISTS "resource_views";
+DROP TABLE IF EXISTS "sso_connections";
+DROP TABLE IF EXISTS "invitations";
+DROP TABLE IF EXISTS "memberships";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "iac_changes";
+DROP TABLE IF EXISTS "recommendations";
+DROP TABLE IF EXISTS "cleanup_session_items";
+DROP TABLE IF EXISTS "cleanup_sessions";
+DROP TABLE IF EXISTS "hygiene_scores";
+DROP TABLE IF EXISTS "provider_calls";
+DROP TABLE IF EXISTS "exports";
+DROP TABLE IF EXISTS "task_failures";
+DROP TABLE IF EXISTS "policies";
+DROP TABLE IF EXISTS "scans";
+DROP TABLE IF EXISTS "resources";
+DROP TABLE IF EXISTS "cloud_accounts";
+DROP TABLE IF EXISTS "organizations";
@@ -0,0 +1,368 @@
+-- Baseline schema, as created by GORM AutoMigrate just before versioned
+-- migrations. Databases created by AutoMigrate, in that or an earlier
+-- release, get its missing tables, columns and indexes and are recorded at
+-- this version (see Migrator.adopt): keep one column per line.
+
+CREATE TABLE "organizations" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "name" varchar(255) NOT NULL,
+    "slug" varchar(100) NOT NULL,
+    "plan" varchar(50) DEFAULT 'free',
+    "is_active" boolean DEFAULT true,
+    "deactivated_at" timestamptz,
+    "default_regions" jsonb,
+    "region_denylist" jsonb,
+    "owner_tag_keys" jsonb,
+    "owner_aliases" jsonb,
+    "default_owner" varchar(255),
+    "audit_retention_days" bigint DEFAULT 0,
+    "terraform_states" jsonb,
+    "gitops_repos" jsonb,
+    "allocation_tag_keys" jsonb,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_organizations_slug" ON "organizations" ("slug");
+
+CREATE TABLE "cloud_accounts" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "provider" varchar(20) NOT NULL,
+    "account_id" varchar(255) NOT NULL,
+    "name" varchar(255),
+    "credentials" bytea,
+    "is_active" boolean DEFAULT true,
+    "last_sync_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cloud_accounts_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_cloud_accounts_organization_id" ON "cloud_accounts" ("organization_id");
+
+CREATE TABLE "resources" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "provider" varchar(20) NOT NULL,
+    "type" varchar(50) NOT NULL,
+    "resource_id" varchar(255) NOT NULL,
+    "region" varchar(50),
+    "account_id" varchar(255),
+    "name" varchar(255),
+    "status" varchar(20) DEFAULT 'active',
+    "tags" jsonb,
+    "metadata" jsonb,
+    "monthly_cost" decimal(10,2) DEFAULT 0,
+    "carbon_footprint" decimal(10,4) DEFAULT 0,
+    "last_seen_at" timestamptz,
+    "snoozed_until" timestamptz,
+    "cleanup_approved_at" timestamptz,
+    "cleanup_approved_by" varchar(255),
+    "quarantined_at" timestamptz,
+    "quarantine_until" timestamptz,
+    "iac_managed" boolean DEFAULT false,
+    "iac_tool" varchar(50),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_resources_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_resources_ia_c_managed" ON "resources" ("iac_managed");
+CREATE INDEX "idx_resources_region" ON "resources" ("region");
+CREATE INDEX "idx_resources_type" ON "resources" ("type");
+CREATE UNIQUE INDEX "idx_resources_identity" ON "resources" ("organization_id","provider","resource_id");
+CREATE INDEX "idx_resources_created_id" ON "resources" ("created_at","id");
+CREATE INDEX "idx_resources_quarantine_until" ON "resources" ("quarantine_until");
+CREATE INDEX "idx_resources_tags" ON "resources" USING gin("tags");
+CREATE INDEX "idx_resources_status" ON "resources" ("status");
+CREATE INDEX "idx_resources_account_id" ON "resources" ("account_id");
+CREATE INDEX "idx_resources_resource_id" ON "resources" ("resource_id");
+CREATE INDEX "idx_resources_provider" ON "resources" ("provider");
+CREATE INDEX "idx_resources_org_status" ON "resources" ("organization_id","status");
+CREATE INDEX "idx_resources_organization_id" ON "resources" ("organization_id");
+
+CREATE TABLE "scans" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "provider" varchar(20) NOT NULL,
+    "regions" jsonb,
+    "resource_types" jsonb,
+    "fingerprint" varchar(64),
+    "status" varchar(20) DEFAULT 'pending',
+    "resources_found" bigint DEFAULT 0,
+    "unused_found" bigint DEFAULT 0,
+    "resources_new" bigint DEFAULT 0,
+    "resources_changed" bigint DEFAULT 0,
+    "resources_removed" bigint DEFAULT 0,
+    "estimated_savings" decimal(10,2) DEFAULT 0,
+    "carbon_savings" decimal(10,4) DEFAULT 0,
+    "error_message" text,
+    "failed_regions" jsonb,
+    "started_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_scans_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_scans_status" ON "scans" ("status");
+CREATE INDEX "idx_scans_fingerprint" ON "scans" ("fingerprint");
+CREATE INDEX "idx_scans_organization_id" ON "scans" ("organization_id");
+CREATE INDEX "idx_scans_created_id" ON "scans" ("created_at","id");
+
+CREATE TABLE "policies" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "provider" varchar(20) NOT NULL,
+    "resource_types" jsonb,
+    "conditions" jsonb,
+    "actions" jsonb,
+    "is_enabled" boolean DEFAULT true,
+    "schedule" varchar(100),
+    "off_hours" jsonb,
+    "view_id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_policies_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_policies_organization_id" ON "policies" ("organization_id");
+CREATE INDEX "idx_policies_view_id" ON "policies" ("view_id");
+
+CREATE TABLE "task_failures" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "task_id" varchar(255) NOT NULL,
+    "task_type" varchar(100) NOT NULL,
+    "queue" varchar(50),
+    "payload" jsonb,
+    "error" text,
+    "attempts" bigint DEFAULT 0,
+    "max_retry" bigint DEFAULT 0,
+    "status" varchar(20),
+    "last_failed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_task_failures_task_type" ON "task_failures" ("task_type");
+CREATE UNIQUE INDEX "idx_task_failures_task_id" ON "task_failures" ("task_id");
+CREATE INDEX "idx_task_failures_status" ON "task_failures" ("status");
+
+CREATE TABLE "exports" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "scan_id" uuid,
+    "type" varchar(20) NOT NULL,
+    "format" varchar(10) NOT NULL,
+    "filters" jsonb,
+    "status" varchar(20) DEFAULT 'pending',
+    "object_key" varchar(500),
+    "row_count" bigint DEFAULT 0,
+    "size_bytes" bigint DEFAULT 0,
+    "error_message" text,
+    "started_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_exports_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_exports_status" ON "exports" ("status");
+CREATE INDEX "idx_exports_scan_id" ON "exports" ("scan_id");
+CREATE INDEX "idx_exports_organization_id" ON "exports" ("organization_id");
+
+CREATE TABLE "provider_calls" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "task_id" varchar(255),
+    "resource_id" uuid,
+    "provider" varchar(20) NOT NULL,
+    "api" varchar(255) NOT NULL,
+    "params_hash" varchar(64),
+    "status" varchar(100),
+    "error" text,
+    "duration_ms" bigint,
+    "called_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_provider_calls_resource_id" ON "provider_calls" ("resource_id");
+CREATE INDEX "idx_provider_calls_task_id" ON "provider_calls" ("task_id");
+CREATE INDEX "idx_provider_calls_org_called" ON "provider_calls" ("organization_id","called_at");
+
+CREATE TABLE "hygiene_scores" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "day" date NOT NULL,
+    "score" decimal(5,1) NOT NULL,
+    "factors" jsonb,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_hygiene_scores_org_day" ON "hygiene_scores" ("organization_id","day");
+
+CREATE TABLE "cleanup_sessions" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "filters" jsonb,
+    "status" varchar(20) DEFAULT 'open',
+    "created_by" varchar(255),
+    "task_id" varchar(255),
+    "dry_run" boolean,
+    "executed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cleanup_sessions_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_cleanup_sessions_status" ON "cleanup_sessions" ("status");
+CREATE INDEX "idx_cleanup_sessions_organization_id" ON "cleanup_sessions" ("organization_id");
+
+CREATE TABLE "cleanup_session_items" (
+    "session_id" uuid,
+    "resource_id" uuid,
+    "position" bigint NOT NULL,
+    "decision" varchar(20) DEFAULT 'pending',
+    "decided_by" varchar(255),
+    "decided_at" timestamptz,
+    PRIMARY KEY ("session_id","resource_id"),
+    CONSTRAINT "fk_cleanup_session_items_resource" FOREIGN KEY ("resource_id") REFERENCES "resources"("id")
+);
+CREATE INDEX "idx_cleanup_session_items_decision" ON "cleanup_session_items" ("decision");
+
+CREATE TABLE "recommendations" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "resource_id" uuid,
+    "provider" varchar(20) NOT NULL,
+    "type" varchar(30) NOT NULL,
+    "key" varchar(255) NOT NULL,
+    "current" varchar(255),
+    "recommended" varchar(255),
+    "monthly_savings" decimal(10,2) DEFAULT 0,
+    "details" jsonb,
+    "status" varchar(20) DEFAULT 'open',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_recommendations_type" ON "recommendations" ("type");
+CREATE INDEX "idx_recommendations_resource_id" ON "recommendations" ("resource_id");
+CREATE UNIQUE INDEX "idx_recommendations_org_key" ON "recommendations" ("organization_id","key");
+CREATE INDEX "idx_recommendations_status" ON "recommendations" ("status");
+
+CREATE TABLE "iac_changes" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "resource_id" uuid NOT NULL,
+    "task_id" varchar(255),
+    "address" varchar(512),
+    "provider" varchar(20) NOT NULL,
+    "repo" varchar(255) NOT NULL,
+    "file_path" varchar(512),
+    "branch" varchar(255),
+    "number" bigint NOT NULL,
+    "url" varchar(512),
+    "state" varchar(20) DEFAULT 'open',
+    "closed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_iac_changes_state" ON "iac_changes" ("state");
+CREATE INDEX "idx_iac_changes_task_id" ON "iac_changes" ("task_id");
+CREATE INDEX "idx_iac_changes_resource_id" ON "iac_changes" ("resource_id");
+CREATE INDEX "idx_iac_changes_organization_id" ON "iac_changes" ("organization_id");
+
+CREATE TABLE "users" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "email" varchar(255) NOT NULL,
+    "name" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email");
+
+CREATE TABLE "memberships" (
+    "organization_id" uuid,
+    "user_id" uuid,
+    "role" varchar(20) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("organization_id","user_id"),
+    CONSTRAINT "fk_memberships_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id"),
+    CONSTRAINT "fk_memberships_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX "idx_memberships_user_id" ON "memberships" ("user_id");
+
+CREATE TABLE "invitations" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "email" varchar(255) NOT NULL,
+    "role" varchar(20) NOT NULL,
+    "invited_by" varchar(255),
+    "token_hash" varchar(64),
+    "expires_at" timestamptz NOT NULL,
+    "accepted_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_invitations_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_invitations_token_hash" ON "invitations" ("token_hash");
+CREATE INDEX "idx_invitations_organization_id" ON "invitations" ("organization_id");
+
+CREATE TABLE "sso_connections" (
+    "organization_id" uuid,
+    "issuer" varchar(500) NOT NULL,
+    "client_id" varchar(255) NOT NULL,
+    "client_secret" varchar(500),
+    "groups_claim" varchar(100),
+    "group_roles" jsonb,
+    "default_role" varchar(20),
+    "allowed_domains" text[],
+    "enabled" boolean NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("organization_id"),
+    CONSTRAINT "fk_sso_connections_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+
+CREATE TABLE "resource_views" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "filters" jsonb,
+    "created_by" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_resource_views_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE UNIQUE INDEX "idx_resource_views_org_name" ON "resource_views" ("organization_id","name");
+
+CREATE TABLE "cost_allocations" (
+    "organization_id" uuid,
+    "month" date,
+    "tag_key" varchar(128),
+    "tag_value" varchar(255),
+    "resource_count" bigint NOT NULL,
+    "monthly_cost" decimal(12,2) NOT NULL,
+    "waste" decimal(12,2) NOT NULL,
+    "computed_at" timestamptz NOT NULL,
+    PRIMARY KEY ("organization_id","month","tag_key","tag_value"),
+    CONSTRAINT "fk_cost_allocations_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+
+-- Resource search (pg_trgm ships with PostgreSQL; creating it needs the
+-- CREATE privilege on the database)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX "idx_resources_name_trgm" ON "resources" USING gin ("name" gin_trgm_ops);
+CREATE INDEX "idx_resources_resource_id_trgm" ON "resources" USING gin ("resource_id" gin_trgm_ops);
+CREATE INDEX "idx_resources_tags_trgm" ON "resources" USING gin (("tags"::text) gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_policies_external";
+ALTER TABLE "policies" DROP COLUMN IF EXISTS "external_id";
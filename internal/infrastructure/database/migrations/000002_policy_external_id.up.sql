@@ -0,0 +1,3 @@
+-- Key of the policies managed declaratively through PUT /policies:apply
+ALTER TABLE "policies" ADD COLUMN "external_id" varchar(255);
+CREATE UNIQUE INDEX "idx_policies_external" ON "policies" ("organization_id","external_id");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_cloud_accounts_integration_id";
+ALTER TABLE "cloud_accounts" DROP COLUMN IF EXISTS "integration_id";
+DROP TABLE IF EXISTS "account_integrations";
@@ -0,0 +1,19 @@
+-- Organization-level integrations (e.g. AWS Organizations) discovering cloud accounts
+CREATE TABLE "account_integrations" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "provider" varchar(20) NOT NULL,
+    "settings" jsonb,
+    "account_count" bigint NOT NULL DEFAULT 0,
+    "last_sync_at" timestamptz,
+    "last_sync_error" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_account_integrations_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE UNIQUE INDEX "idx_account_integrations_org_provider" ON "account_integrations" ("organization_id","provider");
+
+-- Integration that discovered a cloud account, kept when the integration is removed
+ALTER TABLE "cloud_accounts" ADD COLUMN "integration_id" uuid;
+CREATE INDEX "idx_cloud_accounts_integration_id" ON "cloud_accounts" ("integration_id");
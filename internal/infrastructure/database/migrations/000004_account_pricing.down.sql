@@ -0,0 +1,4 @@
+ALTER TABLE "cloud_accounts" DROP COLUMN IF EXISTS "pricing_error";
+ALTER TABLE "cloud_accounts" DROP COLUMN IF EXISTS "pricing_synced_at";
+ALTER TABLE "cloud_accounts" DROP COLUMN IF EXISTS "pricing_settings";
+ALTER TABLE "cloud_accounts" DROP COLUMN IF EXISTS "pricing_source";
@@ -0,0 +1,7 @@
+-- Source of the resource costs of an account: the scanner estimate or billing data of the provider
+ALTER TABLE "cloud_accounts" ADD COLUMN "pricing_source" varchar(30) NOT NULL DEFAULT 'estimate';
+-- Settings of the billing data, e.g. the bucket of an AWS Cost and Usage Report
+ALTER TABLE "cloud_accounts" ADD COLUMN "pricing_settings" jsonb;
+-- Outcome of the last reconciliation with billing data
+ALTER TABLE "cloud_accounts" ADD COLUMN "pricing_synced_at" timestamptz;
+ALTER TABLE "cloud_accounts" ADD COLUMN "pricing_error" text;
@@ -0,0 +1,9 @@
+-- Price catalog of instance types, refreshed in the background, estimating costs without calling the providers
+CREATE TABLE "instance_prices" (
+    "provider" varchar(20),
+    "region" varchar(50),
+    "instance_type" varchar(100),
+    "hourly_usd" decimal(12,6) NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("provider","region","instance_type")
+);
@@ -0,0 +1,6 @@
+-- migrate:no-transaction
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_resources_org_status" ON "resources" ("organization_id","status");
+DROP INDEX CONCURRENTLY IF EXISTS "idx_resources_tags_path";
+DROP INDEX CONCURRENTLY IF EXISTS "idx_resources_org_region";
+DROP INDEX CONCURRENTLY IF EXISTS "idx_resources_org_provider_type";
+DROP INDEX CONCURRENTLY IF EXISTS "idx_resources_org_status_created";
@@ -0,0 +1,10 @@
+-- migrate:no-transaction
+-- Indexes of the common filters of the resource list, built without blocking writes to the table
+-- Organization and status, in the default order of the list
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_resources_org_status_created" ON "resources" ("organization_id","status","created_at","id");
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_resources_org_provider_type" ON "resources" ("organization_id","provider","type");
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_resources_org_region" ON "resources" ("organization_id","region");
+-- Tag selectors (tags @> ...), smaller and faster than the default operator class of idx_resources_tags
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_resources_tags_path" ON "resources" USING gin ("tags" jsonb_path_ops);
+-- Covered by idx_resources_org_status_created
+DROP INDEX CONCURRENTLY IF EXISTS "idx_resources_org_status";
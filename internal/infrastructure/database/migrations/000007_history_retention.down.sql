@@ -0,0 +1 @@
+ALTER TABLE "organizations" DROP COLUMN IF EXISTS "history_retention_days";
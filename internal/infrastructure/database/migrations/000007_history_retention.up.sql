@@ -0,0 +1,2 @@
+-- Retention of the history of an organization, shorter than the one of its plan
+ALTER TABLE "organizations" ADD COLUMN "history_retention_days" bigint DEFAULT 0;
@@ -0,0 +1,6 @@
+DELETE FROM "policies" WHERE "deleted_at" IS NOT NULL;
+DELETE FROM "cloud_accounts" WHERE "deleted_at" IS NOT NULL;
+DROP INDEX IF EXISTS "idx_policies_external";
+CREATE UNIQUE INDEX "idx_policies_external" ON "policies" ("organization_id","external_id");
+ALTER TABLE "policies" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "cloud_accounts" DROP COLUMN IF EXISTS "deleted_at";
@@ -0,0 +1,7 @@
+-- Deleted policies and cloud accounts are kept to be restored
+ALTER TABLE "policies" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX "idx_policies_deleted_at" ON "policies" ("deleted_at");
+DROP INDEX IF EXISTS "idx_policies_external";
+CREATE UNIQUE INDEX "idx_policies_external" ON "policies" ("organization_id","external_id") WHERE deleted_at IS NULL;
+ALTER TABLE "cloud_accounts" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX "idx_cloud_accounts_deleted_at" ON "cloud_accounts" ("deleted_at");
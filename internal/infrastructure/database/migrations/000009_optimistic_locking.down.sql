@@ -0,0 +1,2 @@
+ALTER TABLE "policies" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "scans" DROP COLUMN IF EXISTS "version";
@@ -0,0 +1,4 @@
+-- Versions of policies and scans, incremented by every update so that
+-- concurrent updates are detected
+ALTER TABLE "policies" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "scans" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
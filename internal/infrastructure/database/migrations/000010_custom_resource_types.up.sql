@@ -0,0 +1,21 @@
+-- Resource types defined by organizations, detected from a metric
+CREATE TABLE "custom_resource_types" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "name" varchar(50) NOT NULL,
+    "provider" varchar(20) NOT NULL,
+    "cloud_type" varchar(255) NOT NULL,
+    "metric_namespace" varchar(255) NOT NULL,
+    "metric_name" varchar(255) NOT NULL,
+    "metric_dimension" varchar(255) NOT NULL,
+    "metric_statistic" varchar(20) NOT NULL,
+    "metric_threshold" numeric NOT NULL DEFAULT 0,
+    "metric_above" boolean NOT NULL DEFAULT false,
+    "metric_lookback_days" bigint NOT NULL,
+    "monthly_cost" decimal(10,2) DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_custom_resource_types_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE UNIQUE INDEX "idx_custom_resource_types_org_name" ON "custom_resource_types" ("organization_id","name");
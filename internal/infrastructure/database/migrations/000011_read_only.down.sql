@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "installation_settings";
+ALTER TABLE "organizations" DROP COLUMN IF EXISTS "read_only_reason";
+ALTER TABLE "organizations" DROP COLUMN IF EXISTS "read_only";
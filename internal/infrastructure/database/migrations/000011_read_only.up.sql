@@ -0,0 +1,15 @@
+-- Read-only mode, set by an administrator for an organization or for the
+-- whole installation: scans go on but cloud resources are not changed
+ALTER TABLE "organizations" ADD COLUMN "read_only" boolean NOT NULL DEFAULT false;
+ALTER TABLE "organizations" ADD COLUMN "read_only_reason" text;
+
+-- Settings of the installation, in a single row
+CREATE TABLE "installation_settings" (
+    "id" bigint NOT NULL DEFAULT 1,
+    "read_only" boolean NOT NULL DEFAULT false,
+    "read_only_reason" text,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_installation_settings_single_row" CHECK ("id" = 1)
+);
+INSERT INTO "installation_settings" ("id") VALUES (1);
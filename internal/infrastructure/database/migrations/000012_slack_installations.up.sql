@@ -0,0 +1,16 @@
+-- Slack workspaces organizations installed the app in, one per organization
+CREATE TABLE "slack_installations" (
+    "organization_id" uuid NOT NULL,
+    "team_id" varchar(50) NOT NULL,
+    "team_name" varchar(255),
+    "bot_user_id" varchar(50),
+    "access_token" varchar(500) NOT NULL,
+    "channel_id" varchar(50),
+    "channel_name" varchar(255),
+    "installed_by" varchar(50),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("organization_id"),
+    CONSTRAINT "fk_slack_installations_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE UNIQUE INDEX "idx_slack_installations_team" ON "slack_installations" ("team_id");
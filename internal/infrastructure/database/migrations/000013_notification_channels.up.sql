@@ -0,0 +1,3 @@
+-- Notification channels of organizations (Teams webhook, PagerDuty routing
+-- key) and the events routed to each of them
+ALTER TABLE "organizations" ADD COLUMN "notifications" jsonb;
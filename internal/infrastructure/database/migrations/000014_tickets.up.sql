@@ -0,0 +1,39 @@
+-- Issue trackers organizations open tickets in, one per organization
+CREATE TABLE "ticket_integrations" (
+    "organization_id" uuid NOT NULL,
+    "system" varchar(20) NOT NULL,
+    "base_url" varchar(500) NOT NULL,
+    "username" varchar(255) NOT NULL,
+    "api_token" varchar(500) NOT NULL,
+    "project" varchar(100),
+    "issue_type" varchar(100),
+    "done_transition" varchar(100),
+    "assignment_group" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("organization_id"),
+    CONSTRAINT "fk_ticket_integrations_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+
+-- Tickets opened for resources by the ticket action, closed once the
+-- resource is remediated
+CREATE TABLE "tickets" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "resource_id" uuid NOT NULL,
+    "task_id" varchar(255),
+    "system" varchar(20) NOT NULL,
+    "external_id" varchar(100) NOT NULL,
+    "key" varchar(100) NOT NULL,
+    "url" varchar(512),
+    "state" varchar(20) DEFAULT 'open',
+    "resource_status" varchar(20),
+    "closed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_tickets_state" ON "tickets" ("state");
+CREATE INDEX "idx_tickets_task_id" ON "tickets" ("task_id");
+CREATE INDEX "idx_tickets_resource_id" ON "tickets" ("resource_id");
+CREATE INDEX "idx_tickets_organization_id" ON "tickets" ("organization_id");
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "owner_notifications";
+DROP TABLE IF EXISTS "owner_mappings";
+ALTER TABLE "organizations" DROP COLUMN IF EXISTS "owner_notifications";
+ALTER TABLE "cloud_accounts" DROP COLUMN IF EXISTS "default_owner";
+DROP INDEX IF EXISTS "idx_resources_owner";
+ALTER TABLE "resources" DROP COLUMN IF EXISTS "owner_source";
+ALTER TABLE "resources" DROP COLUMN IF EXISTS "owner";
@@ -0,0 +1,37 @@
+-- Owner of each resource, resolved from the owner mapping file, its tags,
+-- the default owner of its cloud account or of its organization
+ALTER TABLE "resources" ADD COLUMN "owner" varchar(255);
+ALTER TABLE "resources" ADD COLUMN "owner_source" varchar(20);
+CREATE INDEX "idx_resources_owner" ON "resources" ("owner");
+
+ALTER TABLE "cloud_accounts" ADD COLUMN "default_owner" varchar(255);
+
+-- How owners are notified of the resources of the notify action
+ALTER TABLE "organizations" ADD COLUMN "owner_notifications" jsonb;
+
+-- Rows of the owner mapping file of each organization
+CREATE TABLE "owner_mappings" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "position" bigint NOT NULL,
+    "pattern" varchar(255) NOT NULL,
+    "owner" varchar(255) NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_owner_mappings_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_owner_mappings_organization_id" ON "owner_mappings" ("organization_id");
+
+-- Resources waiting for the daily digest of their owner
+CREATE TABLE "owner_notifications" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "organization_id" uuid NOT NULL,
+    "resource_id" uuid NOT NULL,
+    "owner" varchar(255) NOT NULL,
+    "task_id" varchar(255),
+    "sent_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_owner_notifications_organization_id" ON "owner_notifications" ("organization_id");
+CREATE INDEX "idx_owner_notifications_sent_at" ON "owner_notifications" ("sent_at");
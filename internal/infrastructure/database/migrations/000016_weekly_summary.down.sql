@@ -0,0 +1,3 @@
+ALTER TABLE "memberships" DROP COLUMN IF EXISTS "weekly_summary_opt_out";
+ALTER TABLE "organizations" DROP COLUMN IF EXISTS "weekly_summary_sent_at";
+ALTER TABLE "organizations" DROP COLUMN IF EXISTS "weekly_summary";
@@ -0,0 +1,6 @@
+-- When the weekly summary of each organization is sent, in its local time
+ALTER TABLE "organizations" ADD COLUMN "weekly_summary" jsonb;
+ALTER TABLE "organizations" ADD COLUMN "weekly_summary_sent_at" timestamptz;
+
+-- Admins who no longer want the weekly summary of an organization
+ALTER TABLE "memberships" ADD COLUMN "weekly_summary_opt_out" boolean NOT NULL DEFAULT false;
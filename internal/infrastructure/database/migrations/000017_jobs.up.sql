@@ -0,0 +1,23 @@
+-- Status of the long-running operations of organizations: scans, cleanups
+-- and exports
+CREATE TABLE "jobs" (
+    "id" uuid NOT NULL,
+    "organization_id" uuid NOT NULL,
+    "type" varchar(30) NOT NULL,
+    "task_id" varchar(255) NOT NULL,
+    "target_id" uuid,
+    "status" varchar(20) DEFAULT 'pending',
+    "progress" bigint DEFAULT 0,
+    "attempts" bigint DEFAULT 0,
+    "error" text,
+    "started_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_jobs_organization" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id")
+);
+CREATE INDEX "idx_jobs_org_created" ON "jobs" ("organization_id", "created_at");
+CREATE INDEX "idx_jobs_type" ON "jobs" ("type");
+CREATE INDEX "idx_jobs_task_id" ON "jobs" ("task_id");
+CREATE INDEX "idx_jobs_status" ON "jobs" ("status");
@@ -0,0 +1,2 @@
+ALTER TABLE "scans" DROP COLUMN IF EXISTS "changes_since";
+ALTER TABLE "scans" DROP COLUMN IF EXISTS "mode";
@@ -0,0 +1,4 @@
+-- Incremental scans only refresh the resources changed since ChangesSince,
+-- the start of an earlier scan
+ALTER TABLE "scans" ADD COLUMN "mode" varchar(20) NOT NULL DEFAULT 'full';
+ALTER TABLE "scans" ADD COLUMN "changes_since" timestamptz;
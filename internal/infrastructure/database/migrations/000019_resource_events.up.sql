@@ -0,0 +1,18 @@
+-- Timeline of every resource: the scans that found it, the changes of its
+-- status and cost, the policies that matched it, its exclusions and the
+-- cleanups run on it. Events go with their resource when it is purged.
+CREATE TABLE "resource_events" (
+    "id" uuid NOT NULL,
+    "organization_id" uuid NOT NULL,
+    "resource_id" uuid NOT NULL,
+    "type" varchar(30) NOT NULL,
+    "actor" varchar(255),
+    "scan_id" uuid,
+    "policy_id" uuid,
+    "data" jsonb,
+    "occurred_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_resource_events_resource" FOREIGN KEY ("resource_id") REFERENCES "resources"("id") ON DELETE CASCADE
+);
+CREATE INDEX "idx_resource_events_resource_occurred" ON "resource_events" ("resource_id", "occurred_at");
+CREATE INDEX "idx_resource_events_organization_id" ON "resource_events" ("organization_id");
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_resource_events_task_id";
+ALTER TABLE "resource_events" DROP COLUMN IF EXISTS "task_id";
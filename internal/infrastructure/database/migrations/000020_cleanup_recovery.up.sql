@@ -0,0 +1,4 @@
+-- Attempts and rollbacks of cleanups are linked to their task, so that the
+-- job of a cleanup finds its items and how to undo them
+ALTER TABLE "resource_events" ADD COLUMN "task_id" varchar(255);
+CREATE INDEX "idx_resource_events_task_id" ON "resource_events" ("task_id");
@@ -0,0 +1,3 @@
+-- Whether cleanups snapshot volumes, disks and databases before deleting
+-- them, and how long the snapshots are kept
+ALTER TABLE "organizations" ADD COLUMN "backup_before_delete" jsonb;
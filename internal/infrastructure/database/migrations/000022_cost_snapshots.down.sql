@@ -0,0 +1,2 @@
+ALTER TABLE "hygiene_scores" DROP COLUMN IF EXISTS "waste_cost";
+ALTER TABLE "hygiene_scores" DROP COLUMN IF EXISTS "total_cost";
@@ -0,0 +1,4 @@
+-- The daily hygiene scores keep the monthly spend and waste of the day, the
+-- cost snapshots the savings forecast projects from
+ALTER TABLE "hygiene_scores" ADD COLUMN "total_cost" decimal(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE "hygiene_scores" ADD COLUMN "waste_cost" decimal(12,2) NOT NULL DEFAULT 0;
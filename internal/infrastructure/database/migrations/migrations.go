@@ -0,0 +1,14 @@
+// Package migrations holds the versioned SQL migrations of the database
+// schema, embedded in the binaries. Files are named
+// <version>_<name>.up.sql and <version>_<name>.down.sql, as golang-migrate
+// expects. Files starting with "-- migrate:no-transaction" run statement by
+// statement outside a transaction, for CREATE INDEX CONCURRENTLY; with
+// golang-migrate, they need the x-multi-statement option.
+package migrations
+
+import "embed"
+
+// FS contains the migration files
+//
+//go:embed *.sql
+var FS embed.FS
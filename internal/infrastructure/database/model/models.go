@@ -6,7 +6,9 @@ import (
 	"errors"
 	"time"
 
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // JSONB represents a JSONB field
@@ -59,13 +61,127 @@ func (a *StringArray) Scan(value any) error {
 
 // Organization represents the organizations table
 type Organization struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Name      string    `gorm:"type:varchar(255);not null"`
-	Slug      string    `gorm:"type:varchar(100);uniqueIndex;not null"`
-	Plan      string    `gorm:"type:varchar(50);default:'free'"`
-	IsActive  bool      `gorm:"default:true"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID                   uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name                 string    `gorm:"type:varchar(255);not null"`
+	Slug                 string    `gorm:"type:varchar(100);uniqueIndex;not null"`
+	Plan                 string    `gorm:"type:varchar(50);default:'free'"`
+	IsActive             bool      `gorm:"default:true"`
+	DeactivatedAt        *time.Time
+	DefaultRegions       StringArray `gorm:"type:jsonb"`
+	RegionDenylist       StringArray `gorm:"type:jsonb"`
+	OwnerTagKeys         StringArray `gorm:"type:jsonb"`
+	OwnerAliases         JSONB       `gorm:"type:jsonb"`
+	DefaultOwner         string      `gorm:"type:varchar(255)"`
+	OwnerNotifications   JSONB       `gorm:"type:jsonb"`
+	AuditRetentionDays   int         `gorm:"default:0"`
+	HistoryRetentionDays int         `gorm:"default:0"`
+	TerraformStates      StringArray `gorm:"type:jsonb"`
+	GitOpsRepos          JSONB       `gorm:"column:gitops_repos;type:jsonb"`
+	AllocationTagKeys    StringArray `gorm:"type:jsonb"`
+	Notifications        JSONB       `gorm:"type:jsonb"`
+	WeeklySummary        JSONB       `gorm:"type:jsonb"`
+	BackupBeforeDelete   JSONB       `gorm:"type:jsonb"`
+	ReadOnly             bool        `gorm:"not null;default:false"`
+	ReadOnlyReason       string      `gorm:"type:text"`
+	CreatedAt            time.Time   `gorm:"autoCreateTime"`
+	UpdatedAt            time.Time   `gorm:"autoUpdateTime"`
+	WeeklySummarySentAt  *time.Time
+}
+
+// InstallationSettings represents the installation_settings table, the
+// settings of the whole installation in a single row of ID 1
+type InstallationSettings struct {
+	ID             int64  `gorm:"primaryKey;default:1"`
+	ReadOnly       bool   `gorm:"not null;default:false"`
+	ReadOnlyReason string `gorm:"type:text"`
+	UpdatedAt      time.Time
+}
+
+// Settings returns the organization settings as a domain value
+func (o *Organization) Settings() entity.OrganizationSettings {
+	var aliases map[string]string
+	if len(o.OwnerAliases) > 0 {
+		aliases = make(map[string]string, len(o.OwnerAliases))
+		for value, email := range o.OwnerAliases {
+			if s, ok := email.(string); ok {
+				aliases[value] = s
+			}
+		}
+	}
+	return entity.OrganizationSettings{
+		DefaultRegions: o.DefaultRegions,
+		RegionDenylist: o.RegionDenylist,
+		OwnerRules: entity.OwnerRules{
+			TagKeys:      o.OwnerTagKeys,
+			Aliases:      aliases,
+			DefaultOwner: o.DefaultOwner,
+		},
+		AuditRetentionDays:   o.AuditRetentionDays,
+		HistoryRetentionDays: o.HistoryRetentionDays,
+		TerraformStates:      o.TerraformStates,
+		GitOpsRepos:          o.gitOpsRepos(),
+		AllocationTagKeys:    o.AllocationTagKeys,
+		Notifications:        o.notificationSettings(),
+		OwnerNotifications:   o.ownerNotifications(),
+		WeeklySummary:        o.weeklySummary(),
+		BackupBeforeDelete:   o.backupSettings(),
+	}
+}
+
+func (o *Organization) backupSettings() entity.BackupSettings {
+	var settings entity.BackupSettings
+	if len(o.BackupBeforeDelete) > 0 {
+		data, _ := json.Marshal(o.BackupBeforeDelete)
+		_ = json.Unmarshal(data, &settings)
+	}
+	return settings
+}
+
+func (o *Organization) weeklySummary() entity.WeeklySummary {
+	var settings entity.WeeklySummary
+	if len(o.WeeklySummary) > 0 {
+		data, _ := json.Marshal(o.WeeklySummary)
+		_ = json.Unmarshal(data, &settings)
+	}
+	return settings
+}
+
+func (o *Organization) ownerNotifications() entity.OwnerNotifications {
+	var settings entity.OwnerNotifications
+	if len(o.OwnerNotifications) > 0 {
+		data, _ := json.Marshal(o.OwnerNotifications)
+		_ = json.Unmarshal(data, &settings)
+	}
+	return settings
+}
+
+func (o *Organization) notificationSettings() entity.NotificationSettings {
+	var settings entity.NotificationSettings
+	if len(o.Notifications) > 0 {
+		data, _ := json.Marshal(o.Notifications)
+		_ = json.Unmarshal(data, &settings)
+	}
+	return settings
+}
+
+func (o *Organization) gitOpsRepos() map[string]entity.GitOpsRepo {
+	if len(o.GitOpsRepos) == 0 {
+		return nil
+	}
+	repos := make(map[string]entity.GitOpsRepo, len(o.GitOpsRepos))
+	for state, v := range o.GitOpsRepos {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		var repo entity.GitOpsRepo
+		repo.Provider, _ = m["provider"].(string)
+		repo.Repo, _ = m["repo"].(string)
+		repo.Branch, _ = m["branch"].(string)
+		repo.Path, _ = m["path"].(string)
+		repos[state] = repo
+	}
+	return repos
 }
 
 // CloudAccount represents the cloud_accounts table
@@ -77,76 +193,582 @@ type CloudAccount struct {
 	Name           string    `gorm:"type:varchar(255)"`
 	Credentials    []byte    `gorm:"type:bytea"`
 	IsActive       bool      `gorm:"default:true"`
+	// IntegrationID is the integration that discovered the account, nil for
+	// accounts added one by one
+	IntegrationID *uuid.UUID `gorm:"type:uuid;index"`
+	// PricingSource is where resource costs come from, see
+	// entity.PricingSourceEstimate
+	PricingSource   string `gorm:"type:varchar(30);not null;default:'estimate'"`
+	PricingSettings JSONB  `gorm:"type:jsonb"`
+	PricingSyncedAt *time.Time
+	PricingError    string `gorm:"type:text"`
+	DefaultOwner    string `gorm:"type:varchar(255)"` // owner of the resources no mapping or tag attributes
+	LastSyncAt      *time.Time
+	CreatedAt       time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"` // deleted accounts can be restored
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// PricingSettingValues returns the pricing settings as strings
+func (m *CloudAccount) PricingSettingValues() map[string]string {
+	return stringValues(m.PricingSettings)
+}
+
+// AccountIntegration represents the account_integrations table, the
+// organization-level integration of a provider (e.g. AWS Organizations)
+// whose accounts are discovered and kept in sync as cloud accounts
+type AccountIntegration struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_account_integrations_org_provider,priority:1;not null"`
+	Provider       string    `gorm:"type:varchar(20);uniqueIndex:idx_account_integrations_org_provider,priority:2;not null"`
+	Settings       JSONB     `gorm:"type:jsonb"` // fields of the provider integration
+	AccountCount   int       `gorm:"not null;default:0"`
 	LastSyncAt     *time.Time
+	LastSyncError  string    `gorm:"type:text"`
 	CreatedAt      time.Time `gorm:"autoCreateTime"`
 	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
 
 	Organization Organization `gorm:"foreignKey:OrganizationID"`
 }
 
+// SettingValues returns the settings as strings
+func (m *AccountIntegration) SettingValues() map[string]string {
+	return stringValues(m.Settings)
+}
+
+// stringValues returns the string values of a JSONB object
+func stringValues(j JSONB) map[string]string {
+	out := make(map[string]string, len(j))
+	for k, v := range j {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
+
 // Resource represents the resources table
 type Resource struct {
-	ID              uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	OrganizationID  uuid.UUID `gorm:"type:uuid;index;not null"`
-	Provider        string    `gorm:"type:varchar(20);index;not null"`
-	Type            string    `gorm:"type:varchar(50);index;not null"`
-	ResourceID      string    `gorm:"type:varchar(255);index;not null"`
-	Region          string    `gorm:"type:varchar(50);index"`
-	Name            string    `gorm:"type:varchar(255)"`
-	Status          string    `gorm:"type:varchar(20);index;default:'active'"`
-	Tags            JSONB     `gorm:"type:jsonb"`
-	Metadata        JSONB     `gorm:"type:jsonb"`
-	MonthlyCost     float64   `gorm:"type:decimal(10,2);default:0"`
-	CarbonFootprint float64   `gorm:"type:decimal(10,4);default:0"`
-	LastSeenAt      time.Time
-	CreatedAt       time.Time `gorm:"autoCreateTime"`
-	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+	ID                uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid();index:idx_resources_created_id,priority:2;index:idx_resources_org_status_created,priority:4"`
+	OrganizationID    uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_resources_identity,priority:1;index:idx_resources_org_status_created,priority:1;index:idx_resources_org_provider_type,priority:1;index:idx_resources_org_region,priority:1;not null"`
+	Provider          string    `gorm:"type:varchar(20);index;uniqueIndex:idx_resources_identity,priority:2;index:idx_resources_org_provider_type,priority:2;not null"`
+	Type              string    `gorm:"type:varchar(50);index;index:idx_resources_org_provider_type,priority:3;not null"`
+	ResourceID        string    `gorm:"type:varchar(255);index;uniqueIndex:idx_resources_identity,priority:3;not null"`
+	Region            string    `gorm:"type:varchar(50);index;index:idx_resources_org_region,priority:2"`
+	AccountID         string    `gorm:"type:varchar(255);index"` // provider account, subscription, project or cluster
+	Name              string    `gorm:"type:varchar(255)"`
+	Status            string    `gorm:"type:varchar(20);index;index:idx_resources_org_status_created,priority:2;default:'active'"`
+	Tags              JSONB     `gorm:"type:jsonb;index:idx_resources_tags,type:gin"`
+	Metadata          JSONB     `gorm:"type:jsonb"`
+	MonthlyCost       float64   `gorm:"type:decimal(10,2);default:0"`
+	CarbonFootprint   float64   `gorm:"type:decimal(10,4);default:0"`
+	LastSeenAt        time.Time
+	SnoozedUntil      *time.Time
+	CleanupApprovedAt *time.Time
+	CleanupApprovedBy string `gorm:"type:varchar(255)"`
+	QuarantinedAt     *time.Time
+	QuarantineUntil   *time.Time `gorm:"index"`
+	IaCManaged        bool       `gorm:"column:iac_managed;index;default:false"`
+	IaCTool           string     `gorm:"column:iac_tool;type:varchar(50)"`
+	Owner             string     `gorm:"type:varchar(255);index"`
+	OwnerSource       string     `gorm:"type:varchar(20)"` // see entity.OwnerSource
+	CreatedAt         time.Time  `gorm:"autoCreateTime;index:idx_resources_created_id,priority:1;index:idx_resources_org_status_created,priority:3"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime"`
 
 	Organization Organization `gorm:"foreignKey:OrganizationID"`
 }
 
 // Scan represents the scans table
 type Scan struct {
-	ID               uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ID               uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid();index:idx_scans_created_id,priority:2"`
 	OrganizationID   uuid.UUID   `gorm:"type:uuid;index;not null"`
 	Provider         string      `gorm:"type:varchar(20);not null"`
 	Regions          StringArray `gorm:"type:jsonb"`
 	ResourceTypes    StringArray `gorm:"type:jsonb"`
+	Fingerprint      string      `gorm:"type:varchar(64);index"`
+	Mode             string      `gorm:"type:varchar(20);not null;default:'full'"`
 	Status           string      `gorm:"type:varchar(20);index;default:'pending'"`
 	ResourcesFound   int         `gorm:"default:0"`
 	UnusedFound      int         `gorm:"default:0"`
+	ResourcesNew     int         `gorm:"default:0"`
+	ResourcesChanged int         `gorm:"default:0"`
+	ResourcesRemoved int         `gorm:"default:0"`
 	EstimatedSavings float64     `gorm:"type:decimal(10,2);default:0"`
 	CarbonSavings    float64     `gorm:"type:decimal(10,4);default:0"`
 	ErrorMessage     string      `gorm:"type:text"`
+	FailedRegions    JSONB       `gorm:"type:jsonb"`
+	ChangesSince     *time.Time
 	StartedAt        *time.Time
 	CompletedAt      *time.Time
-	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	CreatedAt        time.Time `gorm:"autoCreateTime;index:idx_scans_created_id,priority:1"`
 	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+	Version          int       `gorm:"not null;default:1"` // incremented by every update, for optimistic locking
 
 	Organization Organization `gorm:"foreignKey:OrganizationID"`
 }
 
 // Policy represents the policies table
 type Policy struct {
-	ID             uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	OrganizationID uuid.UUID   `gorm:"type:uuid;index;not null"`
-	Name           string      `gorm:"type:varchar(255);not null"`
-	Description    string      `gorm:"type:text"`
-	Provider       string      `gorm:"type:varchar(20);not null"`
-	ResourceTypes  StringArray `gorm:"type:jsonb"`
-	Conditions     JSONB       `gorm:"type:jsonb"`
-	Actions        StringArray `gorm:"type:jsonb"`
-	IsEnabled      bool        `gorm:"default:true"`
-	Schedule       string      `gorm:"type:varchar(100)"`
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID      `gorm:"type:uuid;index;uniqueIndex:idx_policies_external,priority:1;not null"`
+	Name           string         `gorm:"type:varchar(255);not null"`
+	Description    string         `gorm:"type:text"`
+	Provider       string         `gorm:"type:varchar(20);not null"`
+	ResourceTypes  StringArray    `gorm:"type:jsonb"`
+	Conditions     JSONB          `gorm:"type:jsonb"`
+	Actions        StringArray    `gorm:"type:jsonb"`
+	IsEnabled      bool           `gorm:"default:true"`
+	Schedule       string         `gorm:"type:varchar(100)"`
+	OffHours       JSONB          `gorm:"type:jsonb"`
+	ViewID         *uuid.UUID     `gorm:"type:uuid;index"`                                                                         // saved resource view selecting the resources
+	ExternalID     *string        `gorm:"type:varchar(255);uniqueIndex:idx_policies_external,priority:2,where:deleted_at IS NULL"` // key of policies managed by the apply endpoint
+	CreatedAt      time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`              // deleted policies can be restored
+	Version        int            `gorm:"not null;default:1"` // incremented by every update, for optimistic locking
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// OffHoursSchedule returns the off-hours schedule of a "schedule" policy, or
+// nil when the policy has none
+func (p *Policy) OffHoursSchedule() *entity.OffHoursSchedule {
+	if len(p.OffHours) == 0 {
+		return nil
+	}
+	s := &entity.OffHoursSchedule{}
+	s.Timezone, _ = p.OffHours["timezone"].(string)
+	s.Stop, _ = p.OffHours["stop"].(string)
+	s.Start, _ = p.OffHours["start"].(string)
+	return s
+}
+
+// TaskFailure represents the task_failures table
+type TaskFailure struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TaskID       string    `gorm:"type:varchar(255);uniqueIndex;not null"`
+	TaskType     string    `gorm:"type:varchar(100);index;not null"`
+	Queue        string    `gorm:"type:varchar(50)"`
+	Payload      JSONB     `gorm:"type:jsonb"`
+	Error        string    `gorm:"type:text"`
+	Attempts     int       `gorm:"default:0"`
+	MaxRetry     int       `gorm:"default:0"`
+	Status       string    `gorm:"type:varchar(20);index"`
+	LastFailedAt time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+// Job represents the jobs table, the status of every long-running
+// operation of an organization, whatever its kind
+type Job struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;index:idx_jobs_org_created,priority:1;not null"`
+	Type           string     `gorm:"type:varchar(30);index;not null"`
+	TaskID         string     `gorm:"type:varchar(255);index;not null"` // task running the job, reused by deduplicated scans
+	TargetID       *uuid.UUID `gorm:"type:uuid"`                        // scan or export the job runs
+	Status         string     `gorm:"type:varchar(20);index;default:'pending'"`
+	Progress       int        `gorm:"default:0"` // percent
+	Attempts       int        `gorm:"default:0"`
+	Error          string     `gorm:"type:text"`
+	StartedAt      *time.Time
+	CompletedAt    *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime;index:idx_jobs_org_created,priority:2"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// Export represents the exports table
+type Export struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ScanID         *uuid.UUID `gorm:"type:uuid;index"` // set for exports generated for scan webhooks
+	Type           string     `gorm:"type:varchar(20);not null"`
+	Format         string     `gorm:"type:varchar(10);not null"`
+	Filters        JSONB      `gorm:"type:jsonb"`
+	Status         string     `gorm:"type:varchar(20);index;default:'pending'"`
+	ObjectKey      string     `gorm:"type:varchar(500)"`
+	RowCount       int        `gorm:"default:0"`
+	SizeBytes      int64      `gorm:"default:0"`
+	ErrorMessage   string     `gorm:"type:text"`
+	StartedAt      *time.Time
+	CompletedAt    *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// TableName overrides
+// ProviderCall represents the provider_calls table, an audit log of every
+// provider-mutating API call made by cleanups
+type ProviderCall struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;index:idx_provider_calls_org_called,priority:1;not null"`
+	TaskID         string     `gorm:"type:varchar(255);index"` // cleanup task the call was made for
+	ResourceID     *uuid.UUID `gorm:"type:uuid;index"`
+	Provider       string     `gorm:"type:varchar(20);not null"`
+	API            string     `gorm:"type:varchar(255);not null"`
+	ParamsHash     string     `gorm:"type:varchar(64)"` // sha256 of the JSON-encoded parameters
+	Status         string     `gorm:"type:varchar(100)"`
+	Error          string     `gorm:"type:text"`
+	DurationMs     int64
+	CalledAt       time.Time `gorm:"index:idx_provider_calls_org_called,priority:2;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+// HygieneScore represents the hygiene_scores table, one cloud hygiene score
+// per organization and day, with the monthly spend and waste of the day
+type HygieneScore struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_hygiene_scores_org_day,priority:1;not null"`
+	Day            time.Time `gorm:"type:date;uniqueIndex:idx_hygiene_scores_org_day,priority:2;not null"`
+	Score          float64   `gorm:"type:decimal(5,1);not null"`
+	Factors        JSONB     `gorm:"type:jsonb"` // factor name to score
+	TotalCost      float64   `gorm:"type:decimal(12,2);not null;default:0"`
+	WasteCost      float64   `gorm:"type:decimal(12,2);not null;default:0"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// CleanupSession represents the cleanup_sessions table, a guided review of
+// flagged resources executed as a single cleanup once reviewed
+type CleanupSession struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index;not null"`
+	Action         string    `gorm:"type:varchar(20);not null"`
+	Filters        JSONB     `gorm:"type:jsonb"`
+	Status         string    `gorm:"type:varchar(20);index;default:'open'"`
+	CreatedBy      string    `gorm:"type:varchar(255)"`
+	TaskID         string    `gorm:"type:varchar(255)"` // cleanup task queued on execution
+	DryRun         bool
+	ExecutedAt     *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// CleanupSessionItem represents the cleanup_session_items table, the
+// resources of a session in review order with their decision
+type CleanupSessionItem struct {
+	SessionID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ResourceID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Position   int       `gorm:"not null"`
+	Decision   string    `gorm:"type:varchar(20);index;default:'pending'"`
+	DecidedBy  string    `gorm:"type:varchar(255)"`
+	DecidedAt  *time.Time
+
+	Resource Resource `gorm:"foreignKey:ResourceID"`
+}
+
+// Recommendation represents the recommendations table, saving opportunities
+// on resources in use regenerated daily
+type Recommendation struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_recommendations_org_key,priority:1;not null"`
+	ResourceID     *uuid.UUID `gorm:"type:uuid;index"` // nil for commitments
+	Provider       string     `gorm:"type:varchar(20);not null"`
+	Type           string     `gorm:"type:varchar(30);index;not null"`
+	Key            string     `gorm:"type:varchar(255);uniqueIndex:idx_recommendations_org_key,priority:2;not null"`
+	Current        string     `gorm:"type:varchar(255)"`
+	Recommended    string     `gorm:"type:varchar(255)"`
+	MonthlySavings float64    `gorm:"type:decimal(10,2);default:0"`
+	Details        JSONB      `gorm:"type:jsonb"`
+	Status         string     `gorm:"type:varchar(20);index;default:'open'"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+
+	Resource *Resource `gorm:"foreignKey:ResourceID"`
+}
+
+// User represents the users table
+type User struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Email     string    `gorm:"type:varchar(255);uniqueIndex;not null"`
+	Name      string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Membership represents the memberships table, the role of a user in an
+// organization
+type Membership struct {
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Role           string    `gorm:"type:varchar(20);not null"`
+	// WeeklySummaryOptOut stops the weekly summary of the organization
+	// from being emailed to the user
+	WeeklySummaryOptOut bool      `gorm:"not null;default:false"`
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+	User         User         `gorm:"foreignKey:UserID"`
+}
+
+// Invitation represents the invitations table, people invited to join an
+// organization
+type Invitation struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index;not null"`
+	Email          string    `gorm:"type:varchar(255);not null"`
+	Role           string    `gorm:"type:varchar(20);not null"`
+	InvitedBy      string    `gorm:"type:varchar(255)"`
+	TokenHash      string    `gorm:"type:varchar(64);index"` // SHA-256 of the token sent in the invitation link
+	ExpiresAt      time.Time `gorm:"not null"`
+	AcceptedAt     *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// SSOConnection represents the sso_connections table, the OIDC identity
+// provider of an organization
+type SSOConnection struct {
+	OrganizationID uuid.UUID   `gorm:"type:uuid;primaryKey"`
+	Issuer         string      `gorm:"type:varchar(500);not null"`
+	ClientID       string      `gorm:"type:varchar(255);not null"`
+	ClientSecret   string      `gorm:"type:varchar(500)"`
+	GroupsClaim    string      `gorm:"type:varchar(100)"`
+	GroupRoles     JSONB       `gorm:"type:jsonb"`
+	DefaultRole    string      `gorm:"type:varchar(20)"`
+	AllowedDomains StringArray `gorm:"type:text[]"`
+	Enabled        bool        `gorm:"not null"`
 	CreatedAt      time.Time   `gorm:"autoCreateTime"`
 	UpdatedAt      time.Time   `gorm:"autoUpdateTime"`
 
 	Organization Organization `gorm:"foreignKey:OrganizationID"`
 }
 
-// TableName overrides
-func (Organization) TableName() string  { return "organizations" }
-func (CloudAccount) TableName() string  { return "cloud_accounts" }
-func (Resource) TableName() string      { return "resources" }
-func (Scan) TableName() string          { return "scans" }
-func (Policy) TableName() string        { return "policies" }
+// Connection returns the connection with its group roles
+func (m *SSOConnection) Connection() *entity.SSOConnection {
+	roles := make(map[string]entity.MemberRole, len(m.GroupRoles))
+	for group, v := range m.GroupRoles {
+		if role, ok := v.(string); ok {
+			roles[group] = entity.MemberRole(role)
+		}
+	}
+	return &entity.SSOConnection{
+		OrganizationID: m.OrganizationID,
+		Issuer:         m.Issuer,
+		ClientID:       m.ClientID,
+		ClientSecret:   m.ClientSecret,
+		GroupsClaim:    m.GroupsClaim,
+		GroupRoles:     roles,
+		DefaultRole:    entity.MemberRole(m.DefaultRole),
+		AllowedDomains: m.AllowedDomains,
+		Enabled:        m.Enabled,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// SlackInstallation represents the slack_installations table, the Slack
+// workspace an organization installed the app in. A workspace serves a
+// single organization.
+type SlackInstallation struct {
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TeamID         string    `gorm:"type:varchar(50);uniqueIndex:idx_slack_installations_team;not null"`
+	TeamName       string    `gorm:"type:varchar(255)"`
+	BotUserID      string    `gorm:"type:varchar(50)"`
+	AccessToken    string    `gorm:"type:varchar(500);not null"` // bot token, never returned by the API
+	ChannelID      string    `gorm:"type:varchar(50)"`           // channel scan results are posted to
+	ChannelName    string    `gorm:"type:varchar(255)"`
+	InstalledBy    string    `gorm:"type:varchar(50)"` // Slack user ID of the installer
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// TicketIntegration represents the ticket_integrations table, the Jira
+// project or ServiceNow instance an organization opens tickets in
+type TicketIntegration struct {
+	OrganizationID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	System          string    `gorm:"type:varchar(20);not null"` // jira or servicenow
+	BaseURL         string    `gorm:"type:varchar(500);not null"`
+	Username        string    `gorm:"type:varchar(255);not null"` // Jira account email or ServiceNow user
+	APIToken        string    `gorm:"type:varchar(500);not null"` // Jira API token or ServiceNow password, never returned by the API
+	Project         string    `gorm:"type:varchar(100)"`          // Jira project key
+	IssueType       string    `gorm:"type:varchar(100)"`          // Jira issue type
+	DoneTransition  string    `gorm:"type:varchar(100)"`          // Jira transition closing issues
+	AssignmentGroup string    `gorm:"type:varchar(255)"`          // ServiceNow group change requests are assigned to
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// Ticket represents the tickets table, the issues and change requests
+// opened for resources matched by policies with the ticket action
+type Ticket struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index;not null"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index;not null"`
+	TaskID         string    `gorm:"type:varchar(255);index"` // cleanup task the ticket was opened for
+	System         string    `gorm:"type:varchar(20);not null"`
+	ExternalID     string    `gorm:"type:varchar(100);not null"` // Jira issue ID or ServiceNow sys_id
+	Key            string    `gorm:"type:varchar(100);not null"` // e.g. OPS-123 or CHG0030001
+	URL            string    `gorm:"type:varchar(512)"`
+	State          string    `gorm:"type:varchar(20);index;default:'open'"` // open or closed
+	// ResourceStatus is the status of the resource last reported on the
+	// ticket
+	ResourceStatus string `gorm:"type:varchar(20)"`
+	ClosedAt       *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	Resource *Resource `gorm:"foreignKey:ResourceID"`
+}
+
+// OwnerMapping represents the owner_mappings table, the rows of the owner
+// mapping file of an organization
+type OwnerMapping struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index;not null"`
+	Position       int       `gorm:"not null"` // line of the file, the first matching row wins
+	Pattern        string    `gorm:"type:varchar(255);not null"`
+	Owner          string    `gorm:"type:varchar(255);not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+// OwnerNotification represents the owner_notifications table, the
+// resources of the notify action waiting for the daily digest of their
+// owner
+type OwnerNotification struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ResourceID     uuid.UUID  `gorm:"type:uuid;not null"`
+	Owner          string     `gorm:"type:varchar(255);not null"`
+	TaskID         string     `gorm:"type:varchar(255)"`
+	SentAt         *time.Time `gorm:"index"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+
+	Resource *Resource `gorm:"foreignKey:ResourceID"`
+}
+
+// IaCChange represents the iac_changes table, the pull requests opened to
+// remove infrastructure-as-code managed resources from their configuration
+type IaCChange struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index;not null"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index;not null"`
+	TaskID         string    `gorm:"type:varchar(255);index"` // cleanup task the change was opened for
+	Address        string    `gorm:"type:varchar(512)"`
+	Provider       string    `gorm:"type:varchar(20);not null"`
+	Repo           string    `gorm:"type:varchar(255);not null"`
+	FilePath       string    `gorm:"type:varchar(512)"`
+	Branch         string    `gorm:"type:varchar(255)"`
+	Number         int       `gorm:"not null"`
+	URL            string    `gorm:"type:varchar(512)"`
+	State          string    `gorm:"type:varchar(20);index;default:'open'"`
+	ClosedAt       *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	Resource *Resource `gorm:"foreignKey:ResourceID"`
+}
+
+// ResourceEvent represents the resource_events table, the timeline of
+// every resource: the scans that found it and the changes of its status,
+// cost, exclusions and cleanups
+type ResourceEvent struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ResourceID     uuid.UUID  `gorm:"type:uuid;index:idx_resource_events_resource_occurred,priority:1;not null"`
+	Type           string     `gorm:"type:varchar(30);not null"`
+	Actor          string     `gorm:"type:varchar(255)"` // user ID or email, or what recorded the event, e.g. scan
+	ScanID         *uuid.UUID `gorm:"type:uuid"`
+	PolicyID       *uuid.UUID `gorm:"type:uuid"`
+	TaskID         string     `gorm:"type:varchar(255);index"` // cleanup task of attempts and rollbacks
+	Data           JSONB      `gorm:"type:jsonb"`
+	OccurredAt     time.Time  `gorm:"index:idx_resource_events_resource_occurred,priority:2;not null"`
+}
+
+func (Organization) TableName() string       { return "organizations" }
+func (CloudAccount) TableName() string       { return "cloud_accounts" }
+func (Resource) TableName() string           { return "resources" }
+func (Scan) TableName() string               { return "scans" }
+func (Policy) TableName() string             { return "policies" }
+func (TaskFailure) TableName() string        { return "task_failures" }
+func (Export) TableName() string             { return "exports" }
+func (Job) TableName() string                { return "jobs" }
+func (ProviderCall) TableName() string       { return "provider_calls" }
+func (HygieneScore) TableName() string       { return "hygiene_scores" }
+func (CleanupSession) TableName() string     { return "cleanup_sessions" }
+func (CleanupSessionItem) TableName() string { return "cleanup_session_items" }
+func (Recommendation) TableName() string     { return "recommendations" }
+func (IaCChange) TableName() string          { return "iac_changes" }
+func (ResourceEvent) TableName() string      { return "resource_events" }
+func (Ticket) TableName() string             { return "tickets" }
+func (OwnerMapping) TableName() string       { return "owner_mappings" }
+func (OwnerNotification) TableName() string  { return "owner_notifications" }
+func (User) TableName() string               { return "users" }
+func (Membership) TableName() string         { return "memberships" }
+func (Invitation) TableName() string         { return "invitations" }
+func (AccountIntegration) TableName() string { return "account_integrations" }
+
+// ResourceView represents the resource_views table, named filters of the
+// resource list saved by an organization
+type ResourceView struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_resource_views_org_name,priority:1;not null"`
+	Name           string    `gorm:"type:varchar(255);uniqueIndex:idx_resource_views_org_name,priority:2;not null"`
+	Description    string    `gorm:"type:text"`
+	Filters        JSONB     `gorm:"type:jsonb"`
+	CreatedBy      string    `gorm:"type:varchar(255)"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// CustomResourceType represents the custom_resource_types table, the
+// resource types organizations define with their detection
+type CustomResourceType struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrganizationID     uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_custom_resource_types_org_name,priority:1;not null"`
+	Name               string    `gorm:"type:varchar(50);uniqueIndex:idx_custom_resource_types_org_name,priority:2;not null"`
+	Provider           string    `gorm:"type:varchar(20);not null"`
+	CloudType          string    `gorm:"type:varchar(255);not null"`
+	MetricNamespace    string    `gorm:"type:varchar(255);not null"`
+	MetricName         string    `gorm:"type:varchar(255);not null"`
+	MetricDimension    string    `gorm:"type:varchar(255);not null"`
+	MetricStatistic    string    `gorm:"type:varchar(20);not null"`
+	MetricThreshold    float64   `gorm:"not null;default:0"`
+	MetricAbove        bool      `gorm:"not null;default:false"`
+	MetricLookbackDays int       `gorm:"not null"`
+	MonthlyCost        float64   `gorm:"type:decimal(10,2);default:0"`
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
+
+// InstancePrice represents the instance_prices table, the price catalog
+// estimating costs without calling the providers
+type InstancePrice struct {
+	Provider     string    `gorm:"type:varchar(20);primaryKey"`
+	Region       string    `gorm:"type:varchar(50);primaryKey"`
+	InstanceType string    `gorm:"type:varchar(100);primaryKey"`
+	HourlyUSD    float64   `gorm:"column:hourly_usd;type:decimal(12,6);not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+// CostAllocation represents the cost_allocations table, the cost and waste
+// of an organization per value of its cost-allocation tags, refreshed
+// during the month. Resources without the tag have an empty value.
+type CostAllocation struct {
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Month          time.Time `gorm:"type:date;primaryKey"` // first day of the month
+	TagKey         string    `gorm:"type:varchar(128);primaryKey"`
+	TagValue       string    `gorm:"type:varchar(255);primaryKey"`
+	ResourceCount  int64     `gorm:"not null"`
+	MonthlyCost    float64   `gorm:"type:decimal(12,2);not null"`
+	Waste          float64   `gorm:"type:decimal(12,2);not null"` // cost of the unused resources
+	ComputedAt     time.Time `gorm:"not null"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID"`
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationRepository stores organizations in PostgreSQL
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a new OrganizationRepository
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+var _ repository.OrganizationRepository = (*OrganizationRepository)(nil)
+
+// GetByID implements repository.OrganizationRepository
+func (r *OrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Organization, error) {
+	var m model.Organization
+	if err := conn(ctx, r.db).First(&m, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return organizationEntity(&m), nil
+}
+
+// GetByIDs implements repository.OrganizationRepository
+func (r *OrganizationRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Organization, error) {
+	var models []model.Organization
+	if err := conn(ctx, r.db).Where("id IN ?", ids).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	orgs := make([]*entity.Organization, len(models))
+	for i := range models {
+		orgs[i] = organizationEntity(&models[i])
+	}
+	return orgs, nil
+}
+
+// organizationEntity converts an organization model to its entity
+func organizationEntity(m *model.Organization) *entity.Organization {
+	return &entity.Organization{
+		ID:        m.ID,
+		Name:      m.Name,
+		Slug:      m.Slug,
+		Plan:      m.Plan,
+		IsActive:  m.IsActive,
+		Settings:  m.Settings(),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// Lock implements repository.OrganizationRepository
+func (r *OrganizationRepository) Lock(ctx context.Context, id uuid.UUID) error {
+	var m model.Organization
+	err := conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&m, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.ErrNotFound
+	}
+	return err
+}
+
+// Usage implements repository.OrganizationRepository
+func (r *OrganizationRepository) Usage(ctx context.Context, orgID uuid.UUID, now time.Time) (map[entity.Quota]int64, error) {
+	db := conn(ctx, r.db)
+	usage := map[entity.Quota]int64{}
+	var n int64
+	if err := db.Model(&model.CloudAccount{}).Where("organization_id = ? AND is_active", orgID).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	usage[entity.QuotaCloudAccounts] = n
+	if err := db.Model(&model.Scan{}).Where("organization_id = ? AND created_at >= ?", orgID, entity.StartOfQuotaDay(now)).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	usage[entity.QuotaScansPerDay] = n
+	if err := db.Model(&model.Resource{}).Where("organization_id = ? AND status NOT IN ?", orgID, entity.UntrackedResourceStatuses).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	usage[entity.QuotaResources] = n
+	return usage, nil
+}
@@ -0,0 +1,298 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PolicyRepository stores policies in PostgreSQL
+type PolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewPolicyRepository creates a new PolicyRepository
+func NewPolicyRepository(db *gorm.DB) *PolicyRepository {
+	return &PolicyRepository{db: db}
+}
+
+var _ repository.PolicyRepository = (*PolicyRepository)(nil)
+
+// Create implements repository.PolicyRepository
+func (r *PolicyRepository) Create(ctx context.Context, policy *entity.Policy) error {
+	m, err := policyModel(policy)
+	if err != nil {
+		return err
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		// A false IsEnabled is left to the column default on create
+		if !policy.IsEnabled {
+			if err := tx.Model(m).Update("is_enabled", false).Error; err != nil {
+				return err
+			}
+		}
+		policy.ID, policy.CreatedAt, policy.UpdatedAt, policy.Version = m.ID, m.CreatedAt, m.UpdatedAt, m.Version
+		return nil
+	})
+}
+
+// Update implements repository.PolicyRepository. The row stays locked from
+// the update to the version increment, so that a concurrent update at the
+// same version finds it changed.
+func (r *PolicyRepository) Update(ctx context.Context, policy *entity.Policy) error {
+	m, err := policyModel(policy)
+	if err != nil {
+		return err
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.Policy{}).Where("id = ?", policy.ID)
+		if policy.Version > 0 {
+			query = query.Where("version = ?", policy.Version)
+		}
+		result := query.
+			Select("*").Omit("id", "organization_id", "external_id", "is_enabled", "created_at", "deleted_at", "version", "Organization").
+			Updates(m)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var n int64
+			if err := tx.Model(&model.Policy{}).Where("id = ?", policy.ID).Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				return apperrors.ErrConflict
+			}
+			return apperrors.ErrNotFound
+		}
+		policy.UpdatedAt = m.UpdatedAt
+		return tx.Model(&model.Policy{}).Where("id = ?", policy.ID).
+			UpdateColumn("version", gorm.Expr("version + 1")).Error
+	})
+}
+
+// SetEnabled implements repository.PolicyRepository
+func (r *PolicyRepository) SetEnabled(ctx context.Context, id uuid.UUID, enabled bool) error {
+	result := conn(ctx, r.db).Model(&model.Policy{}).Where("id = ?", id).
+		Updates(map[string]any{"is_enabled": enabled, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// Delete implements repository.PolicyRepository
+func (r *PolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Delete(&model.Policy{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// Restore implements repository.PolicyRepository
+func (r *PolicyRepository) Restore(ctx context.Context, policy *entity.Policy) error {
+	result := conn(ctx, r.db).Unscoped().Model(&model.Policy{}).
+		Where("id = ? AND deleted_at IS NOT NULL", policy.ID).
+		Updates(map[string]any{"deleted_at": nil, "is_enabled": policy.IsEnabled, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// GetByID implements repository.PolicyRepository
+func (r *PolicyRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Policy, error) {
+	return r.first(conn(ctx, r.db).Where("id = ?", id))
+}
+
+// GetDeleted implements repository.PolicyRepository
+func (r *PolicyRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*entity.Policy, error) {
+	return r.first(conn(ctx, r.db).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id))
+}
+
+// List implements repository.PolicyRepository
+func (r *PolicyRepository) List(ctx context.Context, filter repository.PolicyFilter) ([]*entity.Policy, error) {
+	query := r.filter(ctx, filter)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return r.find(query.Offset(filter.Offset).Order("created_at DESC, id DESC"))
+}
+
+// Count implements repository.PolicyRepository
+func (r *PolicyRepository) Count(ctx context.Context, filter repository.PolicyFilter) (int64, error) {
+	var n int64
+	err := r.filter(ctx, filter).Count(&n).Error
+	return n, err
+}
+
+// Names implements repository.PolicyRepository
+func (r *PolicyRepository) Names(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := make(map[uuid.UUID]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var policies []model.Policy
+	if err := conn(ctx, r.db).Unscoped().Select("id", "name").Where("id IN ?", ids).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range policies {
+		names[p.ID] = p.Name
+	}
+	return names, nil
+}
+
+// filter returns a policies query with the conditions of a filter, its
+// limit and offset left out
+func (r *PolicyRepository) filter(ctx context.Context, filter repository.PolicyFilter) *gorm.DB {
+	query := conn(ctx, r.db).Model(&model.Policy{})
+	if filter.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.OrganizationID != nil {
+		query = query.Where("organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.Provider != nil {
+		query = query.Where("provider = ?", *filter.Provider)
+	}
+	if filter.IsEnabled != nil {
+		query = query.Where("is_enabled = ?", *filter.IsEnabled)
+	}
+	if filter.ExternalID != nil {
+		query = query.Where("external_id = ?", *filter.ExternalID)
+	}
+	if filter.Managed {
+		query = query.Where("external_id IS NOT NULL")
+	}
+	return query
+}
+
+// GetEnabledByOrg implements repository.PolicyRepository
+func (r *PolicyRepository) GetEnabledByOrg(ctx context.Context, orgID uuid.UUID) ([]*entity.Policy, error) {
+	return r.find(conn(ctx, r.db).Where("organization_id = ? AND is_enabled = ?", orgID, true).Order("created_at, id"))
+}
+
+func (r *PolicyRepository) first(query *gorm.DB) (*entity.Policy, error) {
+	var m model.Policy
+	if err := query.First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return PolicyEntity(m)
+}
+
+func (r *PolicyRepository) find(query *gorm.DB) ([]*entity.Policy, error) {
+	var models []model.Policy
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+	policies := make([]*entity.Policy, 0, len(models))
+	for _, m := range models {
+		p, err := PolicyEntity(m)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// PolicyEntity converts a stored policy to its domain entity
+func PolicyEntity(m model.Policy) (*entity.Policy, error) {
+	var conditions entity.PolicyConditions
+	raw, err := json.Marshal(m.Conditions)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &conditions); err != nil {
+		return nil, err
+	}
+
+	p := &entity.Policy{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Name:           m.Name,
+		Description:    m.Description,
+		Provider:       entity.CloudProvider(m.Provider),
+		Conditions:     conditions,
+		IsEnabled:      m.IsEnabled,
+		Schedule:       m.Schedule,
+		OffHours:       m.OffHoursSchedule(),
+		ViewID:         m.ViewID,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+		Version:        m.Version,
+	}
+	if m.ExternalID != nil {
+		p.ExternalID = *m.ExternalID
+	}
+	for _, t := range m.ResourceTypes {
+		p.ResourceTypes = append(p.ResourceTypes, entity.ResourceType(t))
+	}
+	for _, a := range m.Actions {
+		p.Actions = append(p.Actions, entity.PolicyAction(a))
+	}
+	return p, nil
+}
+
+// policyModel converts a policy entity to its stored model
+func policyModel(p *entity.Policy) (*model.Policy, error) {
+	var conditions model.JSONB
+	raw, err := json.Marshal(p.Conditions)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &conditions); err != nil {
+		return nil, err
+	}
+
+	m := &model.Policy{
+		ID:             p.ID,
+		OrganizationID: p.OrganizationID,
+		Name:           p.Name,
+		Description:    p.Description,
+		Provider:       string(p.Provider),
+		Conditions:     conditions,
+		IsEnabled:      p.IsEnabled,
+		Schedule:       p.Schedule,
+		ViewID:         p.ViewID,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+		Version:        p.Version,
+	}
+	if p.ExternalID != "" {
+		m.ExternalID = &p.ExternalID
+	}
+	if s := p.OffHours; s != nil {
+		m.OffHours = model.JSONB{"timezone": s.Timezone, "stop": s.Stop, "start": s.Start}
+	}
+	for _, t := range p.ResourceTypes {
+		m.ResourceTypes = append(m.ResourceTypes, string(t))
+	}
+	for _, a := range p.Actions {
+		m.Actions = append(m.Actions, string(a))
+	}
+	return m, nil
+}
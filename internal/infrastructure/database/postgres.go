@@ -1,12 +1,13 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	applog "github.com/cloudsweep/cloudsweep/pkg/logger"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -20,7 +21,7 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	)
 
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: levelLogger{},
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
@@ -42,21 +43,56 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
-// AutoMigrate runs database migrations
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
-
-	err := db.AutoMigrate(
-		&model.Organization{},
-		&model.CloudAccount{},
-		&model.Resource{},
-		&model.Scan{},
-		&model.Policy{},
-	)
+// Close closes the underlying connection pool
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// levelLogger logs SQL statements at the debug log level and only slow
+// queries and errors above it, following the level as it is reloaded
+type levelLogger struct{}
+
+var (
+	sqlLogger  = logger.Default.LogMode(logger.Info)
+	slowLogger = logger.Default.LogMode(logger.Warn)
+	errLogger  = logger.Default.LogMode(logger.Error)
+)
+
+func (levelLogger) current() logger.Interface {
+	switch {
+	case applog.Enabled(applog.LevelDebug):
+		return sqlLogger
+	case applog.Enabled(applog.LevelWarn):
+		return slowLogger
+	default:
+		return errLogger
 	}
+}
+
+// LogMode implements logger.Interface; the level follows the log level of
+// the process instead
+func (l levelLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+// Info implements logger.Interface
+func (l levelLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.current().Info(ctx, msg, args...)
+}
+
+// Warn implements logger.Interface
+func (l levelLogger) Warn(ctx context.Context, msg string, args ...any) {
+	l.current().Warn(ctx, msg, args...)
+}
+
+// Error implements logger.Interface
+func (l levelLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.current().Error(ctx, msg, args...)
+}
 
-	log.Println("Database migrations completed")
-	return nil
+// Trace implements logger.Interface
+func (l levelLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.current().Trace(ctx, begin, fc, err)
 }
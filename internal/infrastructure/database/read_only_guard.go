@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReadOnlyGuard refuses cloud changes when the configuration, the
+// installation settings or the organization enable the read-only mode
+type ReadOnlyGuard struct {
+	db     *gorm.DB
+	config func() config.ReadOnlyConfig
+}
+
+var _ service.ReadOnlyGuard = (*ReadOnlyGuard)(nil)
+
+// NewReadOnlyGuard creates a new ReadOnlyGuard. cfg returns the current
+// configuration, so that reloads apply; it may be nil.
+func NewReadOnlyGuard(db *gorm.DB, cfg func() config.ReadOnlyConfig) *ReadOnlyGuard {
+	return &ReadOnlyGuard{db: db, config: cfg}
+}
+
+// CheckWritable implements service.ReadOnlyGuard
+func (g *ReadOnlyGuard) CheckWritable(ctx context.Context, orgID uuid.UUID) error {
+	installation, err := g.Installation(ctx)
+	if err != nil {
+		return err
+	}
+	if installation.ReadOnly {
+		return &service.ReadOnlyError{Scope: service.ReadOnlyInstallation, Reason: installation.ReadOnlyReason}
+	}
+	var org model.Organization
+	if err := conn(ctx, g.db).Select("read_only", "read_only_reason").First(&org, "id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.ErrNotFound
+		}
+		return err
+	}
+	if org.ReadOnly {
+		return &service.ReadOnlyError{Scope: service.ReadOnlyOrganization, Reason: org.ReadOnlyReason}
+	}
+	return nil
+}
+
+// Installation returns the read-only mode of the whole installation: that
+// of the configuration when enabled there, else that of the installation
+// settings
+func (g *ReadOnlyGuard) Installation(ctx context.Context) (model.InstallationSettings, error) {
+	if g.config != nil {
+		if cfg := g.config(); cfg.Enabled {
+			return model.InstallationSettings{ID: 1, ReadOnly: true, ReadOnlyReason: cfg.Reason}, nil
+		}
+	}
+	var settings model.InstallationSettings
+	err := conn(ctx, g.db).Limit(1).Find(&settings, "id = 1").Error
+	return settings, err
+}
+
+// SetInstallation enables or disables the read-only mode of the whole
+// installation in its settings. The configuration flag, when enabled,
+// takes precedence.
+func (g *ReadOnlyGuard) SetInstallation(ctx context.Context, enabled bool, reason string) error {
+	if !enabled {
+		reason = ""
+	}
+	settings := model.InstallationSettings{ID: 1, ReadOnly: enabled, ReadOnlyReason: reason}
+	return conn(ctx, g.db).Save(&settings).Error
+}
+
+// ConfigEnabled returns true when the configuration enables the read-only
+// mode, which the installation settings cannot disable
+func (g *ReadOnlyGuard) ConfigEnabled() bool {
+	return g.config != nil && g.config().Enabled
+}
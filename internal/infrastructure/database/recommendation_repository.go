@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// RecommendationRepository reads recommendations from PostgreSQL
+type RecommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository creates a new RecommendationRepository
+func NewRecommendationRepository(db *gorm.DB) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+var _ repository.RecommendationRepository = (*RecommendationRepository)(nil)
+
+// List implements repository.RecommendationRepository
+func (r *RecommendationRepository) List(ctx context.Context, filter repository.RecommendationFilter) ([]*entity.Recommendation, error) {
+	query := conn(ctx, r.db).Preload("Resource")
+	if filter.OrganizationID != nil {
+		query = query.Where("organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", string(*filter.Status))
+	}
+
+	var models []model.Recommendation
+	if err := query.Order("created_at, id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	recommendations := make([]*entity.Recommendation, len(models))
+	for i, m := range models {
+		recommendations[i] = &entity.Recommendation{
+			ID:             m.ID,
+			OrganizationID: m.OrganizationID,
+			ResourceID:     m.ResourceID,
+			Provider:       entity.CloudProvider(m.Provider),
+			Type:           entity.RecommendationType(m.Type),
+			Current:        m.Current,
+			Recommended:    m.Recommended,
+			MonthlySavings: m.MonthlySavings,
+			Status:         entity.RecommendationStatus(m.Status),
+			CreatedAt:      m.CreatedAt,
+			UpdatedAt:      m.UpdatedAt,
+		}
+		if m.Resource != nil {
+			recommendations[i].Resource = ResourceEntity(m.Resource)
+		}
+	}
+	return recommendations, nil
+}
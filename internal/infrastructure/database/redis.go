@@ -0,0 +1,16 @@
+package database
+
+import (
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisClient creates a Redis client for application state kept outside
+// the task queue (rate limits, scheduling)
+func NewRedisClient(cfg config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
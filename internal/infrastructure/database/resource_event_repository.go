@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ResourceEventRepository stores the timeline of resources in PostgreSQL
+type ResourceEventRepository struct {
+	db *gorm.DB
+}
+
+// NewResourceEventRepository creates a new ResourceEventRepository
+func NewResourceEventRepository(db *gorm.DB) *ResourceEventRepository {
+	return &ResourceEventRepository{db: db}
+}
+
+var _ repository.ResourceEventRepository = (*ResourceEventRepository)(nil)
+
+// Record implements repository.ResourceEventRepository
+func (r *ResourceEventRepository) Record(ctx context.Context, events ...*entity.ResourceEvent) error {
+	return RecordResourceEvents(conn(ctx, r.db), events...)
+}
+
+// List implements repository.ResourceEventRepository
+func (r *ResourceEventRepository) List(ctx context.Context, filter repository.ResourceEventFilter) ([]*entity.ResourceEvent, error) {
+	query := conn(ctx, r.db)
+	if filter.ResourceID != uuid.Nil {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.TaskID != "" {
+		query = query.Where("task_id = ?", filter.TaskID)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.After != nil {
+		query = query.Where("(occurred_at, id) < (?, ?)", filter.After.OccurredAt, filter.After.ID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var events []model.ResourceEvent
+	if err := query.Order("occurred_at DESC, id DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.ResourceEvent, len(events))
+	for i := range events {
+		out[i] = resourceEventEntity(&events[i])
+	}
+	return out, nil
+}
+
+// RecordResourceEvents adds events to the timelines of their resources with
+// db, for the code writing resources without repositories to record their
+// changes in the same transaction. Events already recorded, e.g. by a
+// retried task giving them the same IDs, are left as they are.
+func RecordResourceEvents(db *gorm.DB, events ...*entity.ResourceEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	models := make([]*model.ResourceEvent, len(events))
+	for i, e := range events {
+		models[i] = resourceEventModel(e)
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(models, bulkChunkSize).Error
+}
+
+func resourceEventModel(e *entity.ResourceEvent) *model.ResourceEvent {
+	return &model.ResourceEvent{
+		ID:             e.ID,
+		OrganizationID: e.OrganizationID,
+		ResourceID:     e.ResourceID,
+		Type:           string(e.Type),
+		Actor:          e.Actor,
+		ScanID:         e.ScanID,
+		PolicyID:       e.PolicyID,
+		TaskID:         e.TaskID,
+		Data:           e.Data,
+		OccurredAt:     e.OccurredAt,
+	}
+}
+
+func resourceEventEntity(m *model.ResourceEvent) *entity.ResourceEvent {
+	return &entity.ResourceEvent{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		ResourceID:     m.ResourceID,
+		Type:           entity.ResourceEventType(m.Type),
+		Actor:          m.Actor,
+		ScanID:         m.ScanID,
+		PolicyID:       m.PolicyID,
+		TaskID:         m.TaskID,
+		Data:           m.Data,
+		OccurredAt:     m.OccurredAt,
+	}
+}
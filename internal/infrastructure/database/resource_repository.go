@@ -0,0 +1,417 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bulkChunkSize is the number of resources written per statement by bulk
+// operations, well under the 65535 parameters PostgreSQL accepts
+const bulkChunkSize = 500
+
+// scannedResourceColumns are the columns a scan sets, updated when a scanned
+// resource is already stored. Snoozes, cleanup approvals and quarantines are
+// left to their users.
+var scannedResourceColumns = []string{
+	"type", "region", "account_id", "name", "status", "tags", "metadata",
+	"monthly_cost", "carbon_footprint", "last_seen_at", "iac_managed", "iac_tool", "updated_at",
+}
+
+// ResourceRepository stores resources in PostgreSQL
+type ResourceRepository struct {
+	db *gorm.DB
+}
+
+// NewResourceRepository creates a new ResourceRepository
+func NewResourceRepository(db *gorm.DB) *ResourceRepository {
+	return &ResourceRepository{db: db}
+}
+
+var _ repository.ResourceRepository = (*ResourceRepository)(nil)
+
+// Create implements repository.ResourceRepository
+func (r *ResourceRepository) Create(ctx context.Context, resource *entity.Resource) error {
+	m := resourceModel(resource)
+	if err := conn(ctx, r.db).Create(m).Error; err != nil {
+		return err
+	}
+	resource.CreatedAt, resource.UpdatedAt = m.CreatedAt, m.UpdatedAt
+	return nil
+}
+
+// Update implements repository.ResourceRepository
+func (r *ResourceRepository) Update(ctx context.Context, resource *entity.Resource) error {
+	m := resourceModel(resource)
+	result := conn(ctx, r.db).Model(&model.Resource{}).
+		Where("id = ?", resource.ID).
+		Select("*").Omit("id", "created_at", "Organization").
+		Updates(m)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	resource.UpdatedAt = m.UpdatedAt
+	return nil
+}
+
+// Delete implements repository.ResourceRepository
+func (r *ResourceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Resource{}, "id = ?", id).Error
+}
+
+// GetByID implements repository.ResourceRepository
+func (r *ResourceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Resource, error) {
+	return r.first(conn(ctx, r.db).Where("id = ?", id))
+}
+
+// GetByResourceID implements repository.ResourceRepository
+func (r *ResourceRepository) GetByResourceID(ctx context.Context, orgID uuid.UUID, provider entity.CloudProvider, resourceID string) (*entity.Resource, error) {
+	return r.first(conn(ctx, r.db).Where("organization_id = ? AND provider = ? AND resource_id = ?", orgID, provider, resourceID))
+}
+
+// GetByResourceIDs implements repository.ResourceRepository
+func (r *ResourceRepository) GetByResourceIDs(ctx context.Context, orgID uuid.UUID, refs []repository.CloudResourceRef) ([]*entity.Resource, error) {
+	var out []*entity.Resource
+	for start := 0; start < len(refs); start += bulkChunkSize {
+		chunk := refs[start:min(start+bulkChunkSize, len(refs))]
+		keys := make([][]any, len(chunk))
+		for i, ref := range chunk {
+			keys[i] = []any{ref.Provider, ref.ResourceID}
+		}
+		found, err := r.find(conn(ctx, r.db).Where("organization_id = ? AND (provider, resource_id) IN ?", orgID, keys))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, found...)
+	}
+	return out, nil
+}
+
+// SetStatus implements repository.ResourceRepository
+func (r *ResourceRepository) SetStatus(ctx context.Context, id uuid.UUID, to entity.ResourceStatus, from ...entity.ResourceStatus) (bool, error) {
+	query := conn(ctx, r.db).Model(&model.Resource{}).Where("id = ?", id)
+	if len(from) > 0 {
+		query = query.Where("status IN ?", from)
+	}
+	result := query.Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CancelQuarantine implements repository.ResourceRepository
+func (r *ResourceRepository) CancelQuarantine(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := conn(ctx, r.db).Model(&model.Resource{}).
+		Where("id = ? AND quarantined_at IS NOT NULL AND status <> ?", id, entity.ResourceStatusDeleted).
+		Update("quarantine_until", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// resourceSortColumns are the columns of the sorts of resource lists
+var resourceSortColumns = map[repository.ResourceSortField]string{
+	repository.ResourceSortCreated: "created_at",
+	repository.ResourceSortCost:    "monthly_cost",
+	repository.ResourceSortCarbon:  "carbon_footprint",
+}
+
+// List implements repository.ResourceRepository
+func (r *ResourceRepository) List(ctx context.Context, filter repository.ResourceFilter) ([]*entity.Resource, error) {
+	query, err := r.filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	column, ok := resourceSortColumns[filter.Sort.Field]
+	if !ok {
+		column = "created_at"
+	}
+	op, direction := "<", "DESC"
+	if filter.Sort.Asc {
+		op, direction = ">", "ASC"
+	}
+	if after := filter.After; after != nil {
+		var value any = after.Value
+		if column == "created_at" {
+			value = after.CreatedAt
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, op), value, after.ID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return r.find(query.Offset(filter.Offset).Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)))
+}
+
+// ListByScope implements repository.ResourceRepository
+func (r *ResourceRepository) ListByScope(ctx context.Context, orgID uuid.UUID, provider entity.CloudProvider, regions []string, resourceTypes []entity.ResourceType) ([]*entity.Resource, error) {
+	query := conn(ctx, r.db).Where("organization_id = ? AND provider = ? AND region IN ?", orgID, provider, regions)
+	if len(resourceTypes) > 0 {
+		query = query.Where("type IN ?", resourceTypes)
+	}
+	return r.find(query)
+}
+
+// Count implements repository.ResourceRepository
+func (r *ResourceRepository) Count(ctx context.Context, filter repository.ResourceFilter) (int64, error) {
+	query, err := r.filter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	err = query.Count(&n).Error
+	return n, err
+}
+
+// CountTracked implements repository.ResourceRepository
+func (r *ResourceRepository) CountTracked(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	var n int64
+	err := conn(ctx, r.db).Model(&model.Resource{}).
+		Where("organization_id = ? AND status NOT IN ?", orgID, entity.UntrackedResourceStatuses).
+		Count(&n).Error
+	return n, err
+}
+
+// BulkCreate implements repository.ResourceRepository
+func (r *ResourceRepository) BulkCreate(ctx context.Context, resources []*entity.Resource, progress repository.ProgressFunc) error {
+	return r.BulkUpsert(ctx, resources, progress)
+}
+
+// BulkUpsert implements repository.ResourceRepository. Chunks are written
+// in a single transaction, so that a failure saves none of them; resources
+// already stored keep their ID and creation date, which are copied back.
+func (r *ResourceRepository) BulkUpsert(ctx context.Context, resources []*entity.Resource, progress repository.ProgressFunc) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(resources); start += bulkChunkSize {
+			chunk := resources[start:min(start+bulkChunkSize, len(resources))]
+			models := make([]*model.Resource, len(chunk))
+			for i, resource := range chunk {
+				models[i] = resourceModel(resource)
+			}
+
+			err := tx.Clauses(
+				clause.OnConflict{
+					Columns:   []clause.Column{{Name: "organization_id"}, {Name: "provider"}, {Name: "resource_id"}},
+					DoUpdates: clause.AssignmentColumns(scannedResourceColumns),
+				},
+				clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "created_at"}}},
+			).Create(&models).Error
+			if err != nil {
+				return err
+			}
+
+			for i, m := range models {
+				chunk[i].ID, chunk[i].CreatedAt, chunk[i].UpdatedAt = m.ID, m.CreatedAt, m.UpdatedAt
+			}
+			if progress != nil {
+				progress(start+len(chunk), len(resources))
+			}
+		}
+		return nil
+	})
+}
+
+// BulkUpdate implements repository.ResourceRepository
+func (r *ResourceRepository) BulkUpdate(ctx context.Context, resources []*entity.Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		ctx := context.WithValue(ctx, txKey{}, tx)
+		for _, resource := range resources {
+			if err := r.Update(ctx, resource); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// filter returns a resources query with the conditions of a filter, its
+// sort, cursor, limit and offset left out. It fails when the search is
+// invalid.
+func (r *ResourceRepository) filter(ctx context.Context, filter repository.ResourceFilter) (*gorm.DB, error) {
+	query := conn(ctx, r.db).Model(&model.Resource{})
+	if filter.OrganizationID != nil {
+		query = query.Where("organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.IDs != nil {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.Provider != nil {
+		query = query.Where("provider = ?", *filter.Provider)
+	}
+	if filter.Type != nil {
+		query = query.Where("type = ?", *filter.Type)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Tracked {
+		query = query.Where("status NOT IN ?", entity.UntrackedResourceStatuses)
+	}
+	if filter.Region != nil {
+		query = query.Where("region = ?", *filter.Region)
+	}
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", *filter.AccountID)
+	}
+	if filter.Owner != nil {
+		query = query.Where("owner = ?", strings.ToLower(*filter.Owner))
+	}
+	if filter.MinCost > 0 {
+		query = query.Where("monthly_cost >= ?", filter.MinCost)
+	}
+	if filter.Search != "" {
+		search, err := service.ParseResourceSearch(filter.Search)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.ErrInvalidInput, err.Error())
+		}
+		query = applyResourceSearch(query, search)
+	}
+	return query, nil
+}
+
+// applyResourceSearch adds the conditions of a search to a resources query.
+// Terms use the trigram indexes of names, cloud IDs and tags, and tag
+// selectors the GIN index of tags.
+func applyResourceSearch(query *gorm.DB, search service.ResourceSearch) *gorm.DB {
+	for _, term := range search.Terms {
+		pattern := "%" + likeEscaper.Replace(term) + "%"
+		query = query.Where(
+			"(name ILIKE ? OR resource_id ILIKE ? OR (tags::text ILIKE ? AND EXISTS (SELECT 1 FROM jsonb_each_text(tags) t WHERE t.value ILIKE ?)))",
+			pattern, pattern, pattern, pattern,
+		)
+	}
+	for _, tag := range search.Tags {
+		switch tag.Op {
+		case service.TagOpEquals:
+			query = query.Where("tags @> ?", model.JSONB{tag.Key: tag.Value})
+		case service.TagOpNotEquals:
+			query = query.Where("NOT (COALESCE(tags, '{}') @> ?)", model.JSONB{tag.Key: tag.Value})
+		default:
+			query = query.Where("jsonb_exists(tags, ?)", tag.Key)
+		}
+	}
+	return query
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *ResourceRepository) first(query *gorm.DB) (*entity.Resource, error) {
+	var m model.Resource
+	if err := query.First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return ResourceEntity(&m), nil
+}
+
+func (r *ResourceRepository) find(query *gorm.DB) ([]*entity.Resource, error) {
+	var resources []model.Resource
+	if err := query.Find(&resources).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.Resource, len(resources))
+	for i := range resources {
+		out[i] = ResourceEntity(&resources[i])
+	}
+	return out, nil
+}
+
+func resourceModel(r *entity.Resource) *model.Resource {
+	tags := make(model.JSONB, len(r.Tags))
+	for k, v := range r.Tags {
+		tags[k] = v
+	}
+	return &model.Resource{
+		ID:                r.ID,
+		OrganizationID:    r.OrganizationID,
+		Provider:          string(r.Provider),
+		Type:              string(r.Type),
+		ResourceID:        r.ResourceID,
+		Region:            r.Region,
+		AccountID:         r.AccountID,
+		Name:              r.Name,
+		Status:            string(r.Status),
+		Tags:              tags,
+		Metadata:          r.Metadata,
+		MonthlyCost:       r.MonthlyCost,
+		CarbonFootprint:   r.CarbonFootprint,
+		LastSeenAt:        r.LastSeenAt,
+		SnoozedUntil:      r.SnoozedUntil,
+		CleanupApprovedAt: r.CleanupApprovedAt,
+		CleanupApprovedBy: r.CleanupApprovedBy,
+		QuarantinedAt:     r.QuarantinedAt,
+		QuarantineUntil:   r.QuarantineUntil,
+		IaCManaged:        r.IaCManaged,
+		IaCTool:           r.IaCTool,
+		Owner:             r.Owner,
+		OwnerSource:       string(r.OwnerSource),
+		CreatedAt:         r.CreatedAt,
+		UpdatedAt:         r.UpdatedAt,
+	}
+}
+
+// ResourceEntity converts a stored resource to its domain entity. It is the
+// one mapping of resources from the database, used wherever stored resources
+// are evaluated.
+func ResourceEntity(m *model.Resource) *entity.Resource {
+	return &entity.Resource{
+		ID:                m.ID,
+		OrganizationID:    m.OrganizationID,
+		Provider:          entity.CloudProvider(m.Provider),
+		Type:              entity.ResourceType(m.Type),
+		ResourceID:        m.ResourceID,
+		Region:            m.Region,
+		AccountID:         m.AccountID,
+		Name:              m.Name,
+		Status:            entity.ResourceStatus(m.Status),
+		Tags:              StringTags(m.Tags),
+		Metadata:          m.Metadata,
+		MonthlyCost:       m.MonthlyCost,
+		CarbonFootprint:   m.CarbonFootprint,
+		LastSeenAt:        m.LastSeenAt,
+		SnoozedUntil:      m.SnoozedUntil,
+		CleanupApprovedAt: m.CleanupApprovedAt,
+		CleanupApprovedBy: m.CleanupApprovedBy,
+		QuarantinedAt:     m.QuarantinedAt,
+		QuarantineUntil:   m.QuarantineUntil,
+		IaCManaged:        m.IaCManaged,
+		IaCTool:           m.IaCTool,
+		Owner:             m.Owner,
+		OwnerSource:       entity.OwnerSource(m.OwnerSource),
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+}
+
+// StringTags returns the string values of stored tags
+func StringTags(tags model.JSONB) map[string]string {
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ResourceViewRepository stores resource views in PostgreSQL
+type ResourceViewRepository struct {
+	db *gorm.DB
+}
+
+// NewResourceViewRepository creates a new ResourceViewRepository
+func NewResourceViewRepository(db *gorm.DB) *ResourceViewRepository {
+	return &ResourceViewRepository{db: db}
+}
+
+var _ repository.ResourceViewRepository = (*ResourceViewRepository)(nil)
+
+// GetByID implements repository.ResourceViewRepository
+func (r *ResourceViewRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ResourceView, error) {
+	var m model.ResourceView
+	if err := conn(ctx, r.db).First(&m, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return resourceViewEntity(&m), nil
+}
+
+// resourceViewEntity converts a stored resource view to its domain entity
+func resourceViewEntity(m *model.ResourceView) *entity.ResourceView {
+	var filters entity.ResourceViewFilter
+	raw, _ := json.Marshal(m.Filters)
+	_ = json.Unmarshal(raw, &filters)
+	return &entity.ResourceView{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Name:           m.Name,
+		Description:    m.Description,
+		Filters:        filters,
+		CreatedBy:      m.CreatedBy,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
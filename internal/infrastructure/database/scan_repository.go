@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// activeScanStatuses are the statuses of scans that are queued or running
+var activeScanStatuses = []string{string(entity.ScanStatusPending), string(entity.ScanStatusRunning)}
+
+// ScanRepository stores scans in PostgreSQL
+type ScanRepository struct {
+	db *gorm.DB
+}
+
+// NewScanRepository creates a new ScanRepository
+func NewScanRepository(db *gorm.DB) *ScanRepository {
+	return &ScanRepository{db: db}
+}
+
+var _ repository.ScanRepository = (*ScanRepository)(nil)
+
+// Create implements repository.ScanRepository
+func (r *ScanRepository) Create(ctx context.Context, scan *entity.Scan) error {
+	m := scanModel(scan)
+	if err := conn(ctx, r.db).Create(m).Error; err != nil {
+		return err
+	}
+	scan.CreatedAt, scan.UpdatedAt, scan.Version = m.CreatedAt, m.UpdatedAt, m.Version
+	return nil
+}
+
+// Update implements repository.ScanRepository. The update only applies to
+// the version of the scan that was read: the API cancels the scans of
+// deactivated organizations while the worker runs them.
+func (r *ScanRepository) Update(ctx context.Context, scan *entity.Scan) error {
+	m := scanModel(scan)
+	m.Version = scan.Version + 1
+	result := conn(ctx, r.db).Model(&model.Scan{}).
+		Where("id = ? AND version = ?", scan.ID, scan.Version).
+		Select("*").Omit("id", "created_at", "Organization").
+		Updates(m)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("scan %s: %w", scan.ID, apperrors.ErrConflict)
+	}
+	scan.UpdatedAt, scan.Version = m.UpdatedAt, m.Version
+	return nil
+}
+
+// Delete implements repository.ScanRepository
+func (r *ScanRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Scan{}, "id = ?", id).Error
+}
+
+// GetByID implements repository.ScanRepository
+func (r *ScanRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Scan, error) {
+	var m model.Scan
+	if err := conn(ctx, r.db).First(&m, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return scanEntity(&m), nil
+}
+
+// List implements repository.ScanRepository
+func (r *ScanRepository) List(ctx context.Context, filter repository.ScanFilter) ([]*entity.Scan, error) {
+	query := r.filter(ctx, filter)
+	if filter.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var scans []model.Scan
+	if err := query.Offset(filter.Offset).Order("created_at DESC, id DESC").Find(&scans).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.Scan, len(scans))
+	for i := range scans {
+		out[i] = scanEntity(&scans[i])
+	}
+	return out, nil
+}
+
+// Count implements repository.ScanRepository
+func (r *ScanRepository) Count(ctx context.Context, filter repository.ScanFilter) (int64, error) {
+	var n int64
+	err := r.filter(ctx, filter).Count(&n).Error
+	return n, err
+}
+
+// FindActive implements repository.ScanRepository
+func (r *ScanRepository) FindActive(ctx context.Context, fingerprint string, exclude uuid.UUID) (*entity.Scan, error) {
+	var m model.Scan
+	err := conn(ctx, r.db).
+		Where("fingerprint = ? AND status IN ? AND id <> ?", fingerprint, activeScanStatuses, exclude).
+		Order("created_at DESC").
+		First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scanEntity(&m), nil
+}
+
+// GetLatestByOrg implements repository.ScanRepository
+func (r *ScanRepository) GetLatestByOrg(ctx context.Context, orgID uuid.UUID) (*entity.Scan, error) {
+	var m model.Scan
+	if err := conn(ctx, r.db).Where("organization_id = ?", orgID).Order("created_at DESC").First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return scanEntity(&m), nil
+}
+
+// CountSince implements repository.ScanRepository
+func (r *ScanRepository) CountSince(ctx context.Context, orgID uuid.UUID, since time.Time) (int64, error) {
+	var n int64
+	err := conn(ctx, r.db).Model(&model.Scan{}).
+		Where("organization_id = ? AND created_at >= ?", orgID, since).
+		Count(&n).Error
+	return n, err
+}
+
+// LastCompleted implements repository.ScanRepository
+func (r *ScanRepository) LastCompleted(ctx context.Context, fingerprint string, mode entity.ScanMode) (*entity.Scan, error) {
+	query := conn(ctx, r.db).Where("fingerprint = ? AND status = ?", fingerprint, string(entity.ScanStatusCompleted))
+	if mode != "" {
+		query = query.Where("mode = ?", string(mode))
+	}
+	var m model.Scan
+	err := query.Order("started_at DESC").First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scanEntity(&m), nil
+}
+
+// filter returns a scans query with the conditions of a filter, its limit,
+// offset and cursor left out
+func (r *ScanRepository) filter(ctx context.Context, filter repository.ScanFilter) *gorm.DB {
+	query := conn(ctx, r.db).Model(&model.Scan{})
+	if filter.OrganizationID != nil {
+		query = query.Where("organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.Provider != nil {
+		query = query.Where("provider = ?", *filter.Provider)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
+
+func scanModel(s *entity.Scan) *model.Scan {
+	resourceTypes := make(model.StringArray, len(s.ResourceTypes))
+	for i, t := range s.ResourceTypes {
+		resourceTypes[i] = string(t)
+	}
+	var failedRegions model.JSONB
+	if len(s.FailedRegions) > 0 {
+		failedRegions = make(model.JSONB, len(s.FailedRegions))
+		for region, msg := range s.FailedRegions {
+			failedRegions[region] = msg
+		}
+	}
+	return &model.Scan{
+		ID:               s.ID,
+		OrganizationID:   s.OrganizationID,
+		Provider:         string(s.Provider),
+		Regions:          s.Regions,
+		ResourceTypes:    resourceTypes,
+		Fingerprint:      s.Fingerprint,
+		Mode:             string(s.Mode),
+		ChangesSince:     s.ChangesSince,
+		Status:           string(s.Status),
+		ResourcesFound:   s.ResourcesFound,
+		UnusedFound:      s.UnusedFound,
+		ResourcesNew:     s.ResourcesNew,
+		ResourcesChanged: s.ResourcesChanged,
+		ResourcesRemoved: s.ResourcesRemoved,
+		EstimatedSavings: s.EstimatedSavings,
+		CarbonSavings:    s.CarbonSavings,
+		ErrorMessage:     s.ErrorMessage,
+		FailedRegions:    failedRegions,
+		StartedAt:        s.StartedAt,
+		CompletedAt:      s.CompletedAt,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+		Version:          s.Version,
+	}
+}
+
+func scanEntity(m *model.Scan) *entity.Scan {
+	resourceTypes := make([]entity.ResourceType, len(m.ResourceTypes))
+	for i, t := range m.ResourceTypes {
+		resourceTypes[i] = entity.ResourceType(t)
+	}
+	var failedRegions map[string]string
+	if len(m.FailedRegions) > 0 {
+		failedRegions = make(map[string]string, len(m.FailedRegions))
+		for region, msg := range m.FailedRegions {
+			failedRegions[region] = fmt.Sprint(msg)
+		}
+	}
+	return &entity.Scan{
+		ID:               m.ID,
+		OrganizationID:   m.OrganizationID,
+		Provider:         entity.CloudProvider(m.Provider),
+		Regions:          m.Regions,
+		ResourceTypes:    resourceTypes,
+		Fingerprint:      m.Fingerprint,
+		Mode:             entity.ScanMode(m.Mode),
+		ChangesSince:     m.ChangesSince,
+		Status:           entity.ScanStatus(m.Status),
+		ResourcesFound:   m.ResourcesFound,
+		UnusedFound:      m.UnusedFound,
+		ResourcesNew:     m.ResourcesNew,
+		ResourcesChanged: m.ResourcesChanged,
+		ResourcesRemoved: m.ResourcesRemoved,
+		EstimatedSavings: m.EstimatedSavings,
+		CarbonSavings:    m.CarbonSavings,
+		ErrorMessage:     m.ErrorMessage,
+		FailedRegions:    failedRegions,
+		StartedAt:        m.StartedAt,
+		CompletedAt:      m.CompletedAt,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+		Version:          m.Version,
+	}
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// txKey is the context key of the transaction of a unit of work
+type txKey struct{}
+
+// UnitOfWork runs repository operations in a PostgreSQL transaction. The
+// transaction travels in the context, so repositories built over the same
+// connection take part in it without being rebuilt.
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a new UnitOfWork
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+var _ repository.UnitOfWork = (*UnitOfWork)(nil)
+
+// Do implements repository.UnitOfWork
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction of the unit of work running in ctx, or db
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
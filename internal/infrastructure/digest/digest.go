@@ -0,0 +1,189 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"gorm.io/gorm"
+)
+
+// Item is a resource listed in an owner digest
+type Item struct {
+	Name        string
+	ResourceID  string
+	Type        string
+	Region      string
+	MonthlyCost float64
+	SnoozeURL   string
+	ApproveURL  string
+}
+
+// OwnerDigest lists the unused resources attributed to one owner
+type OwnerDigest struct {
+	Owner            string
+	OrganizationName string
+	Items            []Item
+	TotalSavings     float64
+	SnoozeDays       int
+}
+
+// Result summarizes a digest run
+type Result struct {
+	Sent         int
+	Failed       int
+	Unattributed int
+}
+
+// Sender builds and emails the weekly owner digests
+type Sender struct {
+	db     *gorm.DB
+	mailer notification.Mailer
+	signer *Signer
+	cfg    config.DigestConfig
+}
+
+// NewSender creates a new Sender
+func NewSender(db *gorm.DB, mailer notification.Mailer, cfg config.DigestConfig) *Sender {
+	return &Sender{
+		db:     db,
+		mailer: mailer,
+		signer: NewSigner(cfg.SigningKey),
+		cfg:    cfg,
+	}
+}
+
+// Run sends one digest per owner and organization. A failed email does not
+// stop the run; the joined errors are returned with the result.
+func (s *Sender) Run(ctx context.Context) (Result, error) {
+	var res Result
+
+	var orgs []model.Organization
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&orgs).Error; err != nil {
+		return res, fmt.Errorf("failed to load organizations: %w", err)
+	}
+
+	var errs []error
+	for i := range orgs {
+		digests, unattributed, err := s.build(ctx, &orgs[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res.Unattributed += unattributed
+
+		for _, d := range digests {
+			if err := s.send(ctx, d); err != nil {
+				res.Failed++
+				errs = append(errs, fmt.Errorf("digest for %s: %w", d.Owner, err))
+				continue
+			}
+			res.Sent++
+		}
+	}
+
+	return res, errors.Join(errs...)
+}
+
+// build groups the organization's actionable unused resources by owner
+func (s *Sender) build(ctx context.Context, org *model.Organization) ([]*OwnerDigest, int, error) {
+	now := time.Now()
+
+	var resources []model.Resource
+	err := s.db.WithContext(ctx).
+		Where("organization_id = ? AND status = ?", org.ID, "unused").
+		Where("snoozed_until IS NULL OR snoozed_until < ?", now).
+		Where("cleanup_approved_at IS NULL").
+		Order("monthly_cost DESC").
+		Find(&resources).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load resources of org %s: %w", org.ID, err)
+	}
+
+	rules := org.Settings().OwnerRules
+	byOwner := make(map[string]*OwnerDigest)
+	unattributed := 0
+	expires := now.Add(s.cfg.LinkTTL).Unix()
+
+	for _, r := range resources {
+		// Owners stored on the resource also come from the owner mapping
+		// file and the default owners of cloud accounts
+		owner := r.Owner
+		if owner == "" {
+			owner = strings.ToLower(rules.ResolveOwner(tagsOf(r.Tags)))
+		}
+		if owner == "" {
+			unattributed++
+			continue
+		}
+
+		d, ok := byOwner[owner]
+		if !ok {
+			d = &OwnerDigest{
+				Owner:            owner,
+				OrganizationName: org.Name,
+				SnoozeDays:       int(s.cfg.SnoozeFor.Hours() / 24),
+			}
+			byOwner[owner] = d
+		}
+
+		id := r.ID.String()
+		d.Items = append(d.Items, Item{
+			Name:        r.Name,
+			ResourceID:  r.ResourceID,
+			Type:        r.Type,
+			Region:      r.Region,
+			MonthlyCost: r.MonthlyCost,
+			SnoozeURL:   s.actionURL(Action{Kind: ActionSnooze, ResourceID: id, Owner: owner, ExpiresAt: expires}),
+			ApproveURL:  s.actionURL(Action{Kind: ActionApprove, ResourceID: id, Owner: owner, ExpiresAt: expires}),
+		})
+		d.TotalSavings += r.MonthlyCost
+	}
+
+	if unattributed > 0 {
+		log.Printf("Owner digest: %d unused resources of org %s have no owner", unattributed, org.ID)
+	}
+
+	digests := make([]*OwnerDigest, 0, len(byOwner))
+	for _, d := range byOwner {
+		digests = append(digests, d)
+	}
+	sort.Slice(digests, func(i, j int) bool { return digests[i].Owner < digests[j].Owner })
+
+	return digests, unattributed, nil
+}
+
+func (s *Sender) send(ctx context.Context, d *OwnerDigest) error {
+	text, html, err := render(d)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, notification.Message{
+		To:      []string{d.Owner},
+		Subject: fmt.Sprintf("[CloudSweep] %d unused resources could save $%.2f/month", len(d.Items), d.TotalSavings),
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+func (s *Sender) actionURL(a Action) string {
+	return strings.TrimRight(s.cfg.PublicURL, "/") + "/api/v1/digest/action?token=" + url.QueryEscape(s.signer.Sign(a))
+}
+
+func tagsOf(tags model.JSONB) map[string]string {
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+var textTemplate = texttemplate.Must(texttemplate.New("digest").Parse(`Hello,
+
+CloudSweep found {{len .Items}} unused resources attributed to you in {{.OrganizationName}}.
+Cleaning them up would save ${{printf "%.2f" .TotalSavings}} per month.
+{{range .Items}}
+- {{if .Name}}{{.Name}} ({{.ResourceID}}){{else}}{{.ResourceID}}{{end}}, {{.Type}} in {{.Region}}: ${{printf "%.2f" .MonthlyCost}}/month
+  Approve cleanup: {{.ApproveURL}}
+  Snooze {{$.SnoozeDays}} days: {{.SnoozeURL}}
+{{end}}
+You receive this email because these resources are tagged with your name.
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<p>Hello,</p>
+<p>CloudSweep found <strong>{{len .Items}}</strong> unused resources attributed to you in {{.OrganizationName}}.
+Cleaning them up would save <strong>${{printf "%.2f" .TotalSavings}}</strong> per month.</p>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr><th align="left">Resource</th><th align="left">Type</th><th align="left">Region</th><th align="right">Monthly cost</th><th></th></tr>
+{{range .Items}}<tr>
+<td>{{if .Name}}{{.Name}}<br><small>{{.ResourceID}}</small>{{else}}{{.ResourceID}}{{end}}</td>
+<td>{{.Type}}</td>
+<td>{{.Region}}</td>
+<td align="right">${{printf "%.2f" .MonthlyCost}}</td>
+<td><a href="{{.ApproveURL}}">Approve cleanup</a> &middot; <a href="{{.SnoozeURL}}">Snooze {{$.SnoozeDays}} days</a></td>
+</tr>
+{{end}}</table>
+<p><small>You receive this email because these resources are tagged with your name.</small></p>
+</body>
+</html>
+`))
+
+func render(d *OwnerDigest) (text, html string, err error) {
+	var tb, hb bytes.Buffer
+	if err := textTemplate.Execute(&tb, d); err != nil {
+		return "", "", err
+	}
+	if err := htmlTemplate.Execute(&hb, d); err != nil {
+		return "", "", err
+	}
+	return tb.String(), hb.String(), nil
+}
+
+// confirmTemplate asks to confirm the action of a link before applying it,
+// so that mail scanners and link prefetchers opening the link apply nothing
+var confirmTemplate = htmltemplate.Must(htmltemplate.New("confirm").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>CloudSweep</title></head>
+<body style="font-family: sans-serif;">
+<form method="post">
+<p>{{.Question}}</p>
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Button}}</button>
+</form>
+</body>
+</html>
+`))
+
+// RenderConfirmation returns the HTML page confirming the action of a link,
+// posting token back to the link once confirmed. snoozeFor is how long the
+// snooze action snoozes resources.
+func RenderConfirmation(a Action, token string, snoozeFor time.Duration) ([]byte, error) {
+	page := struct {
+		Token, Question, Button string
+	}{Token: token}
+	switch a.Kind {
+	case ActionSnooze:
+		page.Question = fmt.Sprintf("Snooze resource %s for %d days? It will not be reported as unused until then.", a.ResourceID, int(snoozeFor/(24*time.Hour)))
+		page.Button = "Snooze"
+	case ActionApprove:
+		page.Question = fmt.Sprintf("Approve the cleanup of resource %s?", a.ResourceID)
+		page.Button = "Approve cleanup"
+	case ActionUnsubscribe:
+		page.Question = "Stop receiving the weekly summary of this organization?"
+		page.Button = "Unsubscribe"
+	default:
+		return nil, ErrInvalidToken
+	}
+	var b bytes.Buffer
+	if err := confirmTemplate.Execute(&b, page); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
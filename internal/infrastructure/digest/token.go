@@ -0,0 +1,55 @@
+package digest
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/signing"
+)
+
+// Action kinds available from digest links
+const (
+	ActionSnooze  = "snooze"
+	ActionApprove = "approve"
+	// ActionUnsubscribe stops the weekly summary of an organization from
+	// being emailed to a member
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// ErrInvalidToken is returned for tampered, malformed or expired tokens
+var ErrInvalidToken = errors.New("invalid or expired action link")
+
+// Action is a one-click action on a resource, or on the membership of a
+// user for unsubscribe links, carried by a signed token
+type Action struct {
+	Kind           string `json:"k"`
+	ResourceID     string `json:"r"`
+	Owner          string `json:"o"`
+	OrganizationID string `json:"g,omitempty"`
+	UserID         string `json:"u,omitempty"`
+	ExpiresAt      int64  `json:"e"`
+}
+
+// Signer signs and verifies action tokens
+type Signer struct {
+	tokens *signing.Signer
+}
+
+// NewSigner creates a new Signer
+func NewSigner(key string) *Signer {
+	return &Signer{tokens: signing.New(key)}
+}
+
+// Sign returns a URL-safe token for the action
+func (s *Signer) Sign(a Action) string {
+	return s.tokens.Sign("digest", a)
+}
+
+// Verify checks the token signature and expiry and returns its action
+func (s *Signer) Verify(token string) (Action, error) {
+	var a Action
+	if err := s.tokens.Verify("digest", token, &a); err != nil || time.Now().Unix() > a.ExpiresAt {
+		return Action{}, ErrInvalidToken
+	}
+	return a, nil
+}
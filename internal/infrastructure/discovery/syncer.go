@@ -0,0 +1,156 @@
+// Package discovery keeps the cloud accounts of organization-level
+// integrations, e.g. AWS Organizations, in sync with the accounts the
+// provider reports.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Syncer discovers the accounts of integrations and upserts them as cloud
+// accounts
+type Syncer struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewSyncer creates a new Syncer
+func NewSyncer(db *gorm.DB, cfg *config.Config) *Syncer {
+	return &Syncer{db: db, cfg: cfg}
+}
+
+// Sync discovers the accounts of an integration. New accounts are created,
+// known ones updated, and the ones no longer reported deactivated. Accounts
+// added by hand are left untouched. The outcome is recorded on the
+// integration.
+func (s *Syncer) Sync(ctx context.Context, integrationID uuid.UUID) error {
+	var integration model.AccountIntegration
+	if err := s.db.WithContext(ctx).First(&integration, "id = ?", integrationID).Error; err != nil {
+		return fmt.Errorf("failed to get integration %s: %w", integrationID, err)
+	}
+
+	count, err := s.sync(ctx, &integration)
+	now := time.Now()
+	updates := map[string]any{"last_sync_at": now, "last_sync_error": ""}
+	if err != nil {
+		updates["last_sync_error"] = err.Error()
+	} else {
+		updates["account_count"] = count
+	}
+	if uerr := s.db.WithContext(ctx).Model(&integration).Updates(updates).Error; uerr != nil {
+		return errors.Join(err, fmt.Errorf("failed to update integration %s: %w", integration.ID, uerr))
+	}
+	return err
+}
+
+// SyncAll syncs every integration and returns the number synced. A failing
+// integration does not prevent the others from being synced.
+func (s *Syncer) SyncAll(ctx context.Context) (int, error) {
+	var ids []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&model.AccountIntegration{}).Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to load integrations: %w", err)
+	}
+
+	synced := 0
+	var errs []error
+	for _, id := range ids {
+		if err := s.Sync(ctx, id); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		synced++
+	}
+	return synced, errors.Join(errs...)
+}
+
+func (s *Syncer) sync(ctx context.Context, integration *model.AccountIntegration) (int, error) {
+	p, ok := provider.Lookup(integration.Provider)
+	if !ok || p.Integration == nil {
+		return 0, fmt.Errorf("%s does not support integrations", integration.Provider)
+	}
+	discoverer, err := p.Integration.NewDiscoverer(integration.SettingValues(), s.cfg)
+	if err != nil {
+		return 0, err
+	}
+	accounts, err := discoverer.Discover(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deleted accounts are matched too, so that they stay deleted
+		var existing []model.CloudAccount
+		err := tx.Unscoped().Where("organization_id = ? AND provider = ?", integration.OrganizationID, integration.Provider).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+		byAccountID := make(map[string]*model.CloudAccount, len(existing))
+		for i := range existing {
+			byAccountID[existing[i].AccountID] = &existing[i]
+		}
+
+		now := time.Now()
+		seen := make(map[string]bool, len(accounts))
+		for _, a := range accounts {
+			seen[a.ID] = true
+			account, ok := byAccountID[a.ID]
+			if !ok {
+				account = &model.CloudAccount{
+					OrganizationID: integration.OrganizationID,
+					Provider:       integration.Provider,
+					AccountID:      a.ID,
+					Name:           a.Name,
+					Credentials:    a.Credentials,
+					IntegrationID:  &integration.ID,
+					LastSyncAt:     &now,
+				}
+				if err := tx.Create(account).Error; err != nil {
+					return fmt.Errorf("failed to create account %s: %w", a.ID, err)
+				}
+				// Create leaves is_active to its column default
+				if !a.Active {
+					if err := tx.Model(account).Update("is_active", false).Error; err != nil {
+						return fmt.Errorf("failed to deactivate account %s: %w", a.ID, err)
+					}
+				}
+				continue
+			}
+			if account.IntegrationID == nil || account.DeletedAt.Valid {
+				continue
+			}
+			err := tx.Model(account).Updates(map[string]any{
+				"name":           a.Name,
+				"credentials":    a.Credentials,
+				"is_active":      a.Active,
+				"integration_id": integration.ID,
+				"last_sync_at":   now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update account %s: %w", a.ID, err)
+			}
+		}
+
+		for _, account := range existing {
+			if seen[account.AccountID] || account.IntegrationID == nil || *account.IntegrationID != integration.ID {
+				continue
+			}
+			err := tx.Model(&model.CloudAccount{}).Where("id = ?", account.ID).
+				Updates(map[string]any{"is_active": false, "last_sync_at": now}).Error
+			if err != nil {
+				return fmt.Errorf("failed to deactivate account %s: %w", account.AccountID, err)
+			}
+		}
+		return nil
+	})
+	return len(accounts), err
+}
@@ -0,0 +1,163 @@
+// Package events publishes live organization events (scan progress,
+// resource status changes, cleanup results) over Redis pub/sub, for the
+// API to stream to the dashboard.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Event types
+const (
+	TypeScanProgress   = "scan.progress"
+	TypeResourceStatus = "resource.status"
+	TypeCleanupResult  = "cleanup.result"
+)
+
+// channelPrefix prefixes the pub/sub channel of each organization
+const channelPrefix = "cloudsweep:events:"
+
+// Event is something that happened in an organization
+type Event struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	OrganizationID string          `json:"organization_id"`
+	Data           json.RawMessage `json:"data"`
+	Time           time.Time       `json:"time"`
+}
+
+// ScanProgress is the data of scan.progress events
+type ScanProgress struct {
+	ScanID         string `json:"scan_id"`
+	Status         string `json:"status"`
+	ResourcesFound int    `json:"resources_found"`
+	UnusedFound    int    `json:"unused_found"`
+}
+
+// ResourceStatus is the data of resource.status events
+type ResourceStatus struct {
+	ResourceID string `json:"resource_id"`
+	Status     string `json:"status"`
+}
+
+// CleanupResult is the data of cleanup.result events
+type CleanupResult struct {
+	TaskID    string `json:"task_id,omitempty"`
+	Action    string `json:"action"`
+	DryRun    bool   `json:"dry_run"`
+	Resources int    `json:"resources"`
+}
+
+// Bus publishes and subscribes to organization events. A nil Bus publishes
+// nothing, so that events stay optional for callers.
+type Bus struct {
+	rdb      *redis.Client
+	done     chan struct{}
+	shutdown sync.Once
+}
+
+// NewBus creates a Bus over a Redis client
+func NewBus(rdb *redis.Client) *Bus {
+	return &Bus{rdb: rdb, done: make(chan struct{})}
+}
+
+// Shutdown ends all subscriptions, so that the event streams let the server
+// shut down
+func (b *Bus) Shutdown() {
+	b.shutdown.Do(func() { close(b.done) })
+}
+
+// Publish sends an event to the subscribers of an organization. Events are
+// fire-and-forget: nobody listening is not an error, and failures are only
+// logged since they must not fail the work that produced the event.
+func (b *Bus) Publish(ctx context.Context, orgID, typ string, data any) {
+	if b == nil || orgID == "" {
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", typ, err)
+		return
+	}
+	now := time.Now().UTC()
+	event, _ := json.Marshal(Event{
+		ID:             strconv.FormatInt(now.UnixNano(), 36),
+		Type:           typ,
+		OrganizationID: orgID,
+		Data:           raw,
+		Time:           now,
+	})
+	if err := b.rdb.Publish(ctx, channelPrefix+orgID, event).Err(); err != nil {
+		log.Printf("Failed to publish %s event for org %s: %v", typ, orgID, err)
+	}
+}
+
+// Subscription receives the events of an organization
+type Subscription struct {
+	pubsub *redis.PubSub
+	events chan Event
+}
+
+// Subscribe starts receiving the events of an organization, until ctx is
+// done or the subscription is closed
+func (b *Bus) Subscribe(ctx context.Context, orgID string) (*Subscription, error) {
+	pubsub := b.rdb.Subscribe(ctx, channelPrefix+orgID)
+	// Wait for the confirmation so that no event published after Subscribe
+	// returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	sub := &Subscription{pubsub: pubsub, events: make(chan Event, 64)}
+	go sub.run(ctx, b.done)
+	return sub, nil
+}
+
+// Events returns the events received, closed with the subscription
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() error {
+	return s.pubsub.Close()
+}
+
+func (s *Subscription) run(ctx context.Context, done <-chan struct{}) {
+	defer close(s.events)
+	messages := s.pubsub.Channel()
+	for {
+		var msg *redis.Message
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case m, ok := <-messages:
+			if !ok {
+				return
+			}
+			msg = m
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			continue
+		}
+		select {
+		case s.events <- event:
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/google/uuid"
+)
+
+// InventoryNotifier invalidates the results cached for organizations and
+// publishes the status changes of their resources on the bus
+type InventoryNotifier struct {
+	bus   *Bus
+	cache *cache.Cache
+}
+
+// NewInventoryNotifier creates an InventoryNotifier. bus and results may be
+// nil, in which case nothing is published or invalidated.
+func NewInventoryNotifier(bus *Bus, results *cache.Cache) *InventoryNotifier {
+	return &InventoryNotifier{bus: bus, cache: results}
+}
+
+var _ service.InventoryNotifier = (*InventoryNotifier)(nil)
+
+// InventoryChanged implements service.InventoryNotifier
+func (n *InventoryNotifier) InventoryChanged(ctx context.Context, orgID uuid.UUID) {
+	n.cache.Invalidate(ctx, orgID.String())
+}
+
+// StatusChanged implements service.InventoryNotifier
+func (n *InventoryNotifier) StatusChanged(ctx context.Context, orgID, resourceID uuid.UUID, status entity.ResourceStatus) {
+	n.cache.Invalidate(ctx, orgID.String())
+	n.bus.Publish(ctx, orgID.String(), TypeResourceStatus, ResourceStatus{ResourceID: resourceID.String(), Status: string(status)})
+}
@@ -0,0 +1,151 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// tablePattern matches the project.dataset.table name of a billing export
+// table, which is interpolated in the query
+var tablePattern = regexp.MustCompile(`^([a-z0-9.:-]+)\.([A-Za-z0-9_]+)\.([A-Za-z0-9_]+)$`)
+
+// BillingExport reads the costs of the resources of a project, credits
+// included, from the detailed Cloud Billing export to BigQuery. The service
+// account needs BigQuery Job User on the project of the table and BigQuery
+// Data Viewer on its dataset.
+type BillingExport struct {
+	client    *Client
+	projectID string
+	key       string
+	table     string
+}
+
+var _ service.BillingSource = (*BillingExport)(nil)
+
+// NewBillingExport creates a BillingExport of the resource-level export
+// table, "project.dataset.gcp_billing_export_resource_v1_<account>", from
+// the credentials of a GCP cloud account
+func NewBillingExport(client *Client, credentials []byte, table string) (*BillingExport, error) {
+	if !tablePattern.MatchString(table) {
+		return nil, errors.New("invalid table, expected project.dataset.table")
+	}
+	var creds struct {
+		ProjectID         string `json:"project_id"`
+		ServiceAccountKey string `json:"service_account_key"`
+	}
+	if err := json.Unmarshal(credentials, &creds); err != nil {
+		return nil, fmt.Errorf("invalid gcp credentials: %w", err)
+	}
+	if creds.ProjectID == "" || creds.ServiceAccountKey == "" {
+		return nil, errors.New("invalid gcp credentials: project_id and service_account_key are required")
+	}
+	return &BillingExport{client: client, projectID: creds.ProjectID, key: creds.ServiceAccountKey, table: table}, nil
+}
+
+// ResourceCosts implements service.BillingSource
+func (s *BillingExport) ResourceCosts(ctx context.Context, start, end time.Time) ([]service.BilledCost, error) {
+	token, err := s.client.Token(ctx, []byte(s.key), ScopeBigQuery)
+	if err != nil {
+		return nil, err
+	}
+	// The job runs in the project of the table
+	jobProject := tablePattern.FindStringSubmatch(s.table)[1]
+
+	param := func(name, typ, value string) map[string]any {
+		return map[string]any{
+			"name":           name,
+			"parameterType":  map[string]string{"type": typ},
+			"parameterValue": map[string]string{"value": value},
+		}
+	}
+	payload, err := json.Marshal(map[string]any{
+		"query": "SELECT resource.name, currency, SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0))" +
+			" FROM `" + s.table + "`" +
+			" WHERE project.id = @project AND usage_start_time >= @start AND usage_start_time < @end AND resource.name IS NOT NULL" +
+			" GROUP BY 1, 2",
+		"useLegacySql":  false,
+		"parameterMode": "NAMED",
+		"queryParameters": []map[string]any{
+			param("project", "STRING", s.projectID),
+			param("start", "TIMESTAMP", start.UTC().Format(time.RFC3339)),
+			param("end", "TIMESTAMP", end.UTC().Format(time.RFC3339)),
+		},
+		"timeoutMs": 60000,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/bigquery/v2/projects/%s/queries", s.client.bigQueryEndpoint, url.PathEscape(jobProject))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var costs []service.BilledCost
+	for {
+		var page queryResults
+		if err := s.client.doJSON(req, token, &page); err != nil {
+			return nil, fmt.Errorf("gcp billing export query of %s: %w", s.projectID, err)
+		}
+		for _, row := range page.Rows {
+			if len(row.F) < 3 {
+				continue
+			}
+			resource, _ := row.F[0].V.(string)
+			currency, _ := row.F[1].V.(string)
+			raw, _ := row.F[2].V.(string)
+			amount, err := strconv.ParseFloat(raw, 64)
+			if resource == "" || err != nil {
+				continue
+			}
+			costs = append(costs, service.BilledCost{ResourceID: resource, Amount: amount, Currency: currency})
+		}
+		// Rows are only returned once the job completes; until then, and
+		// for the following pages, the results are polled from the job
+		if page.JobComplete && page.PageToken == "" {
+			return costs, nil
+		}
+
+		query := url.Values{"timeoutMs": {"60000"}}
+		if page.JobReference.Location != "" {
+			query.Set("location", page.JobReference.Location)
+		}
+		if page.PageToken != "" {
+			query.Set("pageToken", page.PageToken)
+		}
+		u := fmt.Sprintf("%s/bigquery/v2/projects/%s/queries/%s?%s", s.client.bigQueryEndpoint,
+			url.PathEscape(page.JobReference.ProjectID), url.PathEscape(page.JobReference.JobID), query.Encode())
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// queryResults is a page of the results of a BigQuery query job
+type queryResults struct {
+	JobReference struct {
+		ProjectID string `json:"projectId"`
+		JobID     string `json:"jobId"`
+		Location  string `json:"location"`
+	} `json:"jobReference"`
+	JobComplete bool `json:"jobComplete"`
+	Rows        []struct {
+		F []struct {
+			V any `json:"v"`
+		} `json:"f"`
+	} `json:"rows"`
+	PageToken string `json:"pageToken"`
+}
@@ -0,0 +1,262 @@
+// Package gcp calls the Google Cloud APIs CloudSweep needs to discover the
+// projects of a folder or organization, to read their costs from the
+// BigQuery billing export, to test the permissions granted on them, to
+// follow the changes of their resources with Cloud Asset Inventory and to
+// read the Cloud DNS records pointing at them.
+// Service accounts are authenticated with a signed JWT assertion of their
+// key, so no SDK is required.
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// ScopeReadOnly grants read access to the projects and folders
+	ScopeReadOnly = "https://www.googleapis.com/auth/cloud-platform.read-only"
+	// ScopeBigQuery allows running queries, which read-only access cannot
+	ScopeBigQuery = "https://www.googleapis.com/auth/bigquery"
+)
+
+// Client calls the Google Cloud APIs. It is safe for concurrent use.
+type Client struct {
+	http *http.Client
+
+	resourceManagerEndpoint string
+	bigQueryEndpoint        string
+	assetEndpoint           string
+	dnsEndpoint             string
+}
+
+// NewClient creates a new Client
+func NewClient() *Client {
+	return &Client{
+		http:                    &http.Client{Timeout: 30 * time.Second},
+		resourceManagerEndpoint: "https://cloudresourcemanager.googleapis.com",
+		bigQueryEndpoint:        "https://bigquery.googleapis.com",
+		assetEndpoint:           "https://cloudasset.googleapis.com",
+		dnsEndpoint:             "https://dns.googleapis.com",
+	}
+}
+
+// serviceAccountKey is the JSON key file of a service account
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// Token returns an access token of the service account of a JSON key for a
+// scope
+func (c *Client) Token(ctx context.Context, key []byte, scope string) (string, error) {
+	var sa serviceAccountKey
+	if err := json.Unmarshal(key, &sa); err != nil {
+		return "", fmt.Errorf("invalid service account key: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return "", errors.New("invalid service account key: client_email and private_key are required")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	assertion, err := signAssertion(sa, scope, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sa.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("gcp token: %w", err)
+	}
+	var resp struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("gcp token returned %d", status)
+	}
+	if status >= 300 || resp.AccessToken == "" {
+		return "", fmt.Errorf("gcp token: %s: %s", resp.Error, resp.ErrorDescription)
+	}
+	return resp.AccessToken, nil
+}
+
+// signAssertion returns the RS256 JWT exchanged for an access token
+func signAssertion(sa serviceAccountKey, scope string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return "", errors.New("invalid service account key: private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid service account key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("invalid service account key: private_key is not an RSA key")
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   sa.ClientEmail,
+		"scope": scope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Project is a Google Cloud project
+type Project struct {
+	ProjectID   string `json:"projectId"`
+	DisplayName string `json:"displayName"`
+	State       string `json:"state"` // ACTIVE or DELETE_REQUESTED
+}
+
+// Projects lists the projects under a folder or organization ("folders/123"
+// or "organizations/456"), including those of its sub-folders
+func (c *Client) Projects(ctx context.Context, token, parent string) ([]Project, error) {
+	var projects []Project
+	parents := []string{parent}
+	for len(parents) > 0 {
+		p := parents[0]
+		parents = parents[1:]
+
+		err := c.list(ctx, token, "/v3/projects", p, func(body []byte) (string, error) {
+			var page struct {
+				Projects      []Project `json:"projects"`
+				NextPageToken string    `json:"nextPageToken"`
+			}
+			err := json.Unmarshal(body, &page)
+			projects = append(projects, page.Projects...)
+			return page.NextPageToken, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("gcp list projects of %s: %w", p, err)
+		}
+
+		err = c.list(ctx, token, "/v3/folders", p, func(body []byte) (string, error) {
+			var page struct {
+				Folders []struct {
+					Name  string `json:"name"`
+					State string `json:"state"`
+				} `json:"folders"`
+				NextPageToken string `json:"nextPageToken"`
+			}
+			err := json.Unmarshal(body, &page)
+			for _, f := range page.Folders {
+				if f.State == "ACTIVE" {
+					parents = append(parents, f.Name)
+				}
+			}
+			return page.NextPageToken, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("gcp list folders of %s: %w", p, err)
+		}
+	}
+	return projects, nil
+}
+
+// list calls a paginated Resource Manager list method; page decodes a page
+// and returns the token of the next one
+func (c *Client) list(ctx context.Context, token, path, parent string, page func([]byte) (string, error)) error {
+	pageToken := ""
+	for {
+		query := url.Values{"parent": {parent}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.resourceManagerEndpoint+path+"?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := c.doJSON(req, token, &raw); err != nil {
+			return err
+		}
+		pageToken, err = page(raw)
+		if err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		if pageToken == "" {
+			return nil
+		}
+	}
+}
+
+// doJSON sends an authenticated request and decodes its JSON response
+func (c *Client) doJSON(req *http.Request, token string, out any) error {
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	body, status, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		var e struct {
+			Error struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Error.Status != "" {
+			return fmt.Errorf("%s: %s", e.Error.Status, e.Error.Message)
+		}
+		return fmt.Errorf("returned %d", status)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) do(req *http.Request) ([]byte, int, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,113 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+)
+
+// CloudDNSZones reads the records of the Cloud DNS managed zones of a
+// project, public and private, to find those pointing at its addresses,
+// load balancers and website buckets
+type CloudDNSZones struct {
+	client *Client
+	creds  projectCredentials
+}
+
+var (
+	_ service.DNSZoneReader = (*CloudDNSZones)(nil)
+	_ service.DNSInspector  = (*CloudDNSZones)(nil)
+)
+
+// NewCloudDNSZones creates the reader of the managed zones of a project
+// from the credentials of a GCP cloud account
+func NewCloudDNSZones(client *Client, credentials []byte) (*CloudDNSZones, error) {
+	creds, err := parseCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudDNSZones{client: client, creds: creds}, nil
+}
+
+// Records implements service.DNSZoneReader
+func (z *CloudDNSZones) Records(ctx context.Context) ([]service.DNSRecord, error) {
+	token, err := z.client.Token(ctx, []byte(z.creds.ServiceAccountKey), ScopeReadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	base := fmt.Sprintf("%s/dns/v1/projects/%s/managedZones", z.client.dnsEndpoint, url.PathEscape(z.creds.ProjectID))
+	var zones []string
+	err = z.list(ctx, token, base, func(page *dnsPage) {
+		for _, zone := range page.ManagedZones {
+			zones = append(zones, zone.Name)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gcp list managed zones of project %s: %w", z.creds.ProjectID, err)
+	}
+
+	var records []service.DNSRecord
+	for _, zone := range zones {
+		err := z.list(ctx, token, base+"/"+url.PathEscape(zone)+"/rrsets", func(page *dnsPage) {
+			for _, set := range page.RRSets {
+				records = append(records, service.DNSRecord{Zone: zone, Name: set.Name, Type: set.Type, Values: set.RRDatas})
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("gcp list records of zone %s: %w", zone, err)
+		}
+	}
+	return records, nil
+}
+
+// dnsPage is a page of managed zones or of the record sets of a zone
+type dnsPage struct {
+	ManagedZones []struct {
+		Name string `json:"name"`
+	} `json:"managedZones"`
+	RRSets []struct {
+		Name    string   `json:"name"`
+		Type    string   `json:"type"`
+		RRDatas []string `json:"rrdatas"`
+	} `json:"rrsets"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// list calls a paginated Cloud DNS list method, passing each page to found
+func (z *CloudDNSZones) list(ctx context.Context, token, u string, found func(*dnsPage)) error {
+	query := url.Values{}
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		var page dnsPage
+		err = ratelimit.Call(ctx, func(ctx context.Context) error {
+			return z.client.doJSON(req.Clone(ctx), token, &page)
+		})
+		if err != nil {
+			return err
+		}
+		found(&page)
+		if page.NextPageToken == "" {
+			return nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// DNSRecordsReferencing implements service.DNSInspector for the cleaners of
+// the project
+func (z *CloudDNSZones) DNSRecordsReferencing(ctx context.Context, resource *entity.Resource) ([]string, error) {
+	records, err := z.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return service.DNSRecordsPointingAt(resource, records), nil
+}
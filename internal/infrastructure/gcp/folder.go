@@ -0,0 +1,69 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// FolderDiscoverer discovers the projects under a Google Cloud folder or
+// organization. Every project is scanned with the service account of the
+// integration, which needs Browser and Viewer on the parent.
+type FolderDiscoverer struct {
+	client *Client
+	parent string
+	key    string
+}
+
+var _ service.AccountDiscoverer = (*FolderDiscoverer)(nil)
+
+// NewFolderDiscoverer creates a new FolderDiscoverer. The parent is
+// "folders/<id>" or "organizations/<id>"; key is the JSON key of the
+// service account.
+func NewFolderDiscoverer(client *Client, parent, key string) (*FolderDiscoverer, error) {
+	if !strings.HasPrefix(parent, "folders/") && !strings.HasPrefix(parent, "organizations/") {
+		return nil, errors.New("invalid parent, expected folders/<id> or organizations/<id>")
+	}
+	if key == "" {
+		return nil, errors.New("service_account_key is required")
+	}
+	return &FolderDiscoverer{client: client, parent: parent, key: key}, nil
+}
+
+// Discover implements service.AccountDiscoverer
+func (d *FolderDiscoverer) Discover(ctx context.Context) ([]service.DiscoveredAccount, error) {
+	token, err := d.client.Token(ctx, []byte(d.key), ScopeReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := d.client.Projects(ctx, token, d.parent)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]service.DiscoveredAccount, 0, len(projects))
+	for _, p := range projects {
+		// Same fields as the credentials of a GCP cloud account
+		credentials, err := json.Marshal(map[string]string{
+			"project_id":          p.ProjectID,
+			"service_account_key": d.key,
+		})
+		if err != nil {
+			return nil, err
+		}
+		name := p.DisplayName
+		if name == "" {
+			name = p.ProjectID
+		}
+		out = append(out, service.DiscoveredAccount{
+			ID:          p.ProjectID,
+			Name:        name,
+			Active:      p.State == "ACTIVE",
+			Credentials: credentials,
+		})
+	}
+	return out, nil
+}
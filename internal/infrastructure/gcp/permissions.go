@@ -0,0 +1,105 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// testBatch is the maximum number of permissions testIamPermissions accepts
+// per request
+const testBatch = 100
+
+// IAMPermissionTest checks the permissions granted to the service account
+// of a project with testIamPermissions, which takes the roles granted on the
+// project and its ancestors into account.
+type IAMPermissionTest struct {
+	client    *Client
+	projectID string
+	key       string
+
+	mu      sync.Mutex
+	granted map[string]bool // answers by permission
+}
+
+var _ service.PermissionChecker = (*IAMPermissionTest)(nil)
+
+// NewIAMPermissionTest creates an IAMPermissionTest from the credentials of
+// a GCP cloud account
+func NewIAMPermissionTest(client *Client, credentials []byte) (*IAMPermissionTest, error) {
+	creds, err := parseCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &IAMPermissionTest{client: client, projectID: creds.ProjectID, key: creds.ServiceAccountKey, granted: map[string]bool{}}, nil
+}
+
+// MissingPermissions implements service.PermissionChecker
+func (s *IAMPermissionTest) MissingPermissions(ctx context.Context, permissions []string) ([]string, error) {
+	s.mu.Lock()
+	var unknown []string
+	for _, p := range permissions {
+		if _, ok := s.granted[p]; !ok {
+			unknown = append(unknown, p)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(unknown) > 0 {
+		token, err := s.client.Token(ctx, []byte(s.key), ScopeReadOnly)
+		if err != nil {
+			return nil, err
+		}
+		for start := 0; start < len(unknown); start += testBatch {
+			batch := unknown[start:min(start+testBatch, len(unknown))]
+			granted, err := s.client.TestProjectPermissions(ctx, token, s.projectID, batch)
+			if err != nil {
+				return nil, err
+			}
+			s.mu.Lock()
+			for _, p := range batch {
+				s.granted[p] = slices.Contains(granted, p)
+			}
+			s.mu.Unlock()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var missing []string
+	for _, p := range permissions {
+		if !s.granted[p] {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+// TestProjectPermissions returns the permissions granted on a project among
+// those given, at most 100
+func (c *Client) TestProjectPermissions(ctx context.Context, token, projectID string, permissions []string) ([]string, error) {
+	payload, err := json.Marshal(map[string][]string{"permissions": permissions})
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v1/projects/%s:testIamPermissions", c.resourceManagerEndpoint, url.PathEscape(projectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.doJSON(req, token, &resp); err != nil {
+		return nil, fmt.Errorf("gcp testIamPermissions on project %s: %w", projectID, err)
+	}
+	return resp.Permissions, nil
+}
@@ -0,0 +1,194 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ci"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// repoClient opens pull requests and follows their status
+type repoClient interface {
+	ci.Client
+	ci.Committer
+}
+
+// Proposer opens pull requests removing Terraform-managed resources from
+// the repository mapped to their state file, and tracks their status
+type Proposer struct {
+	db      *gorm.DB
+	cfg     config.GitOpsConfig
+	clients map[string]repoClient
+}
+
+// NewProposer creates a Proposer with the GitHub and GitLab clients
+func NewProposer(db *gorm.DB, ciCfg config.CIConfig, cfg config.GitOpsConfig) *Proposer {
+	return &Proposer{
+		db:  db,
+		cfg: cfg,
+		clients: map[string]repoClient{
+			"github": ci.NewGitHubClient(ciCfg.GitHub),
+			"gitlab": ci.NewGitLabClient(ciCfg.GitLab),
+		},
+	}
+}
+
+// ProposeRemoval opens a pull request removing the resource block from the
+// Terraform configuration, or returns the one still open for the resource.
+// Only resources found in a state file mapped to a repository can be
+// removed.
+func (p *Proposer) ProposeRemoval(ctx context.Context, resource *entity.Resource, taskID string) (*service.IaCChange, error) {
+	if resource.IaCTool != service.IaCToolTerraform {
+		return nil, fmt.Errorf("changes to %s configurations are not supported", resource.IaCTool)
+	}
+	state, _ := resource.Metadata[service.IaCMetadataState].(string)
+	address, _ := resource.Metadata[service.IaCMetadataAddress].(string)
+	if state == "" || address == "" {
+		return nil, fmt.Errorf("resource was not found in a terraform state file")
+	}
+	typ, name, err := service.ParseTerraformAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var open model.IaCChange
+	err = p.db.WithContext(ctx).
+		Where("resource_id = ? AND state = ?", resource.ID, string(ci.PRStateOpen)).
+		First(&open).Error
+	if err == nil {
+		return toIaCChange(&open), nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to look up open changes: %w", err)
+	}
+
+	var org model.Organization
+	if err := p.db.WithContext(ctx).First(&org, "id = ?", resource.OrganizationID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	repo, ok := org.Settings().GitOpsRepos[state]
+	if !ok {
+		return nil, fmt.Errorf("no repository is mapped to state %s", state)
+	}
+	client, ok := p.clients[repo.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported repository provider %q", repo.Provider)
+	}
+
+	file, content, err := p.removeBlock(ctx, client, repo, typ, name)
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf("Remove unused %s", address)
+	pr, err := client.ProposeChange(ctx, repo.Repo, ci.FileChange{
+		Base:    repo.Branch,
+		Branch:  fmt.Sprintf("%s%s-%s", p.cfg.BranchPrefix, strings.ReplaceAll(address, "_", "-"), resource.ID.String()[:8]),
+		Path:    file,
+		Content: content,
+		Title:   title,
+		Body: fmt.Sprintf("CloudSweep flagged %s (%s, %s) as unused.\n\n"+
+			"Estimated monthly cost: $%.2f. Merging this change lets Terraform delete it on the next apply.",
+			resource.ResourceID, resource.Type, resource.Region, resource.MonthlyCost),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	change := &model.IaCChange{
+		OrganizationID: resource.OrganizationID,
+		ResourceID:     resource.ID,
+		TaskID:         taskID,
+		Address:        address,
+		Provider:       repo.Provider,
+		Repo:           repo.Repo,
+		FilePath:       file,
+		Branch:         pr.Branch,
+		Number:         pr.Number,
+		URL:            pr.URL,
+		State:          string(pr.State),
+	}
+	if err := p.db.WithContext(ctx).Create(change).Error; err != nil {
+		return nil, fmt.Errorf("failed to save change: %w", err)
+	}
+	return toIaCChange(change), nil
+}
+
+// removeBlock looks for the resource block in the .tf files of the root
+// module and returns the file declaring it, without the block
+func (p *Proposer) removeBlock(ctx context.Context, client repoClient, repo entity.GitOpsRepo, typ, name string) (string, []byte, error) {
+	files, err := client.ListFiles(ctx, repo.Repo, repo.Branch, repo.Path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list %s of %s: %w", repo.Path, repo.Repo, err)
+	}
+	for _, file := range files {
+		if path.Ext(file) != ".tf" {
+			continue
+		}
+		src, err := client.ReadFile(ctx, repo.Repo, repo.Branch, file)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if out, ok := service.RemoveTerraformBlock(src, typ, name); ok {
+			return file, out, nil
+		}
+	}
+	return "", nil, fmt.Errorf("resource block %s.%s not found in %s", typ, name, repo.Repo)
+}
+
+// Sync refreshes the status of the open pull requests and returns the
+// number that were merged or closed since the last sync
+func (p *Proposer) Sync(ctx context.Context) (int, error) {
+	var changes []model.IaCChange
+	if err := p.db.WithContext(ctx).Where("state = ?", string(ci.PRStateOpen)).Find(&changes).Error; err != nil {
+		return 0, fmt.Errorf("failed to load open changes: %w", err)
+	}
+
+	updated := 0
+	for _, change := range changes {
+		client, ok := p.clients[change.Provider]
+		if !ok {
+			continue
+		}
+		pr, err := client.PullRequest(ctx, change.Repo, change.Number)
+		if errors.Is(err, ci.ErrNotFound) {
+			// Deleted pull requests and removed repositories are not
+			// coming back
+			now := time.Now()
+			pr = &ci.PullRequest{State: ci.PRStateClosed, ClosedAt: &now}
+		} else if err != nil {
+			return updated, fmt.Errorf("failed to get %s#%d: %w", change.Repo, change.Number, err)
+		}
+		if pr.State == ci.PRStateOpen {
+			continue
+		}
+
+		err = p.db.WithContext(ctx).Model(&change).Updates(map[string]any{
+			"state":     string(pr.State),
+			"closed_at": pr.ClosedAt,
+		}).Error
+		if err != nil {
+			return updated, fmt.Errorf("failed to update change %s: %w", change.ID, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func toIaCChange(c *model.IaCChange) *service.IaCChange {
+	return &service.IaCChange{
+		Provider: c.Provider,
+		Repo:     c.Repo,
+		Number:   c.Number,
+		URL:      c.URL,
+	}
+}
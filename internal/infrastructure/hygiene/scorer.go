@@ -0,0 +1,162 @@
+package hygiene
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scorer computes and records the cloud hygiene score of organizations
+type Scorer struct {
+	db *gorm.DB
+}
+
+// NewScorer creates a Scorer
+func NewScorer(db *gorm.DB) *Scorer {
+	return &Scorer{db: db}
+}
+
+var _ service.HygieneScorer = (*Scorer)(nil)
+
+// Compute implements service.HygieneScorer
+func (s *Scorer) Compute(ctx context.Context, org *entity.Organization, now time.Time) (service.HygieneScore, error) {
+	in, err := s.inputs(ctx, org.ID, org.Settings.OwnerRules, now)
+	if err != nil {
+		return service.HygieneScore{}, err
+	}
+	return service.ComputeHygieneScore(in), nil
+}
+
+// inputs measures the current inventory of an organization, resolving
+// owners with its rules
+func (s *Scorer) inputs(ctx context.Context, orgID uuid.UUID, rules entity.OwnerRules, now time.Time) (service.HygieneInputs, error) {
+	db := s.db.WithContext(ctx)
+	resources := func() *gorm.DB {
+		return db.Model(&model.Resource{}).Where("organization_id = ? AND status NOT IN ?", orgID, entity.UntrackedResourceStatuses)
+	}
+	unused := func() *gorm.DB {
+		return db.Model(&model.Resource{}).Where("organization_id = ? AND status = ?", orgID, string(entity.ResourceStatusUnused))
+	}
+	snapshots := func() *gorm.DB {
+		return resources().Where("type IN ?", []string{string(entity.ResourceTypeEBSSnapshot), string(entity.ResourceTypeAzureSnapshot)})
+	}
+
+	var in service.HygieneInputs
+	err := errors.Join(
+		resources().Count(&in.Resources).Error,
+		resources().Select("COALESCE(SUM(monthly_cost), 0)").Scan(&in.TotalCost).Error,
+		unused().Count(&in.Unused).Error,
+		unused().Select("COALESCE(SUM(monthly_cost), 0)").Scan(&in.WasteCost).Error,
+		snapshots().Count(&in.Snapshots).Error,
+		snapshots().Where("created_at < ?", now.Add(-service.StaleSnapshotAge)).Count(&in.StaleSnapshots).Error,
+		unused().
+			Where("created_at < ?", now.Add(-service.BacklogAge)).
+			Where("snoozed_until IS NULL OR snoozed_until < ?", now).
+			Where("cleanup_approved_at IS NULL").
+			Count(&in.Backlog).Error,
+	)
+	if err != nil {
+		return service.HygieneInputs{}, fmt.Errorf("failed to load inventory of organization %s: %w", orgID, err)
+	}
+
+	// Owners are resolved with the organization's rules, as for digests
+	var tags []model.JSONB
+	if err := resources().Pluck("tags", &tags).Error; err != nil {
+		return service.HygieneInputs{}, fmt.Errorf("failed to load tags of organization %s: %w", orgID, err)
+	}
+	for _, t := range tags {
+		if rules.ResolveOwner(database.StringTags(t)) != "" {
+			in.Owned++
+		}
+	}
+
+	return in, nil
+}
+
+// Record computes the score of every active organization and stores it as
+// the score of the day, with the monthly spend and waste of the day,
+// replacing any score already recorded that day. It returns the number of
+// organizations scored.
+func (s *Scorer) Record(ctx context.Context, now time.Time) (int, error) {
+	var orgs []model.Organization
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&orgs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load organizations: %w", err)
+	}
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	var (
+		recorded int
+		errs     []error
+	)
+	for i := range orgs {
+		in, err := s.inputs(ctx, orgs[i].ID, orgs[i].Settings().OwnerRules, now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		score := service.ComputeHygieneScore(in)
+
+		factors := model.JSONB{}
+		for _, f := range score.Factors {
+			factors[f.Name] = f.Score
+		}
+		row := model.HygieneScore{
+			ID:             uuid.New(),
+			OrganizationID: orgs[i].ID,
+			Day:            day,
+			Score:          score.Score,
+			Factors:        factors,
+			TotalCost:      in.TotalCost,
+			WasteCost:      in.WasteCost,
+		}
+		err = s.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "day"}},
+			DoUpdates: clause.AssignmentColumns([]string{"score", "factors", "total_cost", "waste_cost", "updated_at"}),
+		}).Create(&row).Error
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to record score of organization %s: %w", orgs[i].ID, err))
+			continue
+		}
+		recorded++
+	}
+	return recorded, errors.Join(errs...)
+}
+
+// History implements service.HygieneScorer
+func (s *Scorer) History(ctx context.Context, orgID uuid.UUID, since time.Time) ([]service.HygieneRecord, error) {
+	var scores []model.HygieneScore
+	err := s.db.WithContext(ctx).
+		Where("organization_id = ? AND day >= ?", orgID, since.UTC().Truncate(24*time.Hour)).
+		Order("day ASC").
+		Find(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]service.HygieneRecord, 0, len(scores))
+	for _, score := range scores {
+		record := service.HygieneRecord{
+			Day:       score.Day,
+			Score:     score.Score,
+			Factors:   make(map[string]float64, len(score.Factors)),
+			TotalCost: score.TotalCost,
+			WasteCost: score.WasteCost,
+		}
+		for name, v := range score.Factors {
+			if f, ok := v.(float64); ok {
+				record.Factors[name] = f
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
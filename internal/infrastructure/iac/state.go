@@ -0,0 +1,71 @@
+package iac
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxStateSize bounds the state files read, as they are loaded in memory
+const maxStateSize = 64 << 20
+
+// StateIndex reads the Terraform state files organizations list in their
+// settings from object storage
+type StateIndex struct {
+	db    *gorm.DB
+	store storage.ObjectStore
+}
+
+// NewStateIndex creates a StateIndex
+func NewStateIndex(db *gorm.DB, store storage.ObjectStore) *StateIndex {
+	return &StateIndex{db: db, store: store}
+}
+
+// ManagedResources returns the resources declared in the organization's
+// state files. A state file that cannot be read fails the lookup, so its
+// resources are not reported as unmanaged.
+func (i *StateIndex) ManagedResources(ctx context.Context, orgID uuid.UUID) (map[string]service.IaCDeclaration, error) {
+	var org model.Organization
+	if err := i.db.WithContext(ctx).Select("id", "terraform_states").First(&org, "id = ?", orgID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	declared := make(map[string]service.IaCDeclaration)
+	for _, key := range org.TerraformStates {
+		data, err := i.read(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read state %s: %w", key, err)
+		}
+		addresses, err := service.ParseTerraformState(data)
+		if err != nil {
+			return nil, fmt.Errorf("state %s: %w", key, err)
+		}
+		for id, address := range addresses {
+			declared[id] = service.IaCDeclaration{Tool: service.IaCToolTerraform, State: key, Address: address}
+		}
+	}
+	return declared, nil
+}
+
+func (i *StateIndex) read(ctx context.Context, key string) ([]byte, error) {
+	r, err := i.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, maxStateSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxStateSize {
+		return nil, fmt.Errorf("state file larger than %d MB", maxStateSize>>20)
+	}
+	return data, nil
+}
@@ -0,0 +1,158 @@
+// Package jobs records the long-running operations of organizations (scans,
+// cleanups and exports) in the jobs table, so their status, progress and
+// errors are reported the same way whatever their kind.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// Tracker registers jobs when their task is queued and follows the task
+// in the worker
+type Tracker struct {
+	db *gorm.DB
+}
+
+// NewTracker creates a new Tracker
+func NewTracker(db *gorm.DB) *Tracker {
+	return &Tracker{db: db}
+}
+
+// Enqueue records a pending job of an organization and queues its task.
+// The task is queued under the job ID unless opts set a task ID, as
+// deduplicated scans do. The job is dropped when the task cannot be queued.
+// A nil Tracker only queues the task.
+func (t *Tracker) Enqueue(ctx context.Context, client *asynq.Client, orgID uuid.UUID, jobType entity.JobType, targetID *uuid.UUID, task *asynq.Task, opts ...asynq.Option) (*model.Job, *asynq.TaskInfo, error) {
+	if t == nil {
+		info, err := client.EnqueueContext(ctx, task, opts...)
+		return nil, info, err
+	}
+
+	job := &model.Job{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Type:           string(jobType),
+		TargetID:       targetID,
+		Status:         string(entity.JobStatusPending),
+	}
+	job.TaskID = job.ID.String()
+	for _, opt := range opts {
+		if opt.Type() == asynq.TaskIDOpt {
+			job.TaskID, _ = opt.Value().(string)
+		}
+	}
+	if job.TaskID == job.ID.String() {
+		opts = append(opts, asynq.TaskID(job.TaskID))
+	}
+
+	// Recorded first so the worker finds the job when it picks the task up
+	if err := t.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, nil, err
+	}
+	info, err := client.EnqueueContext(ctx, task, opts...)
+	if err != nil {
+		if dropErr := t.db.WithContext(ctx).Delete(job).Error; dropErr != nil {
+			log.Printf("Failed to drop job %s of a task that was not queued: %v", job.ID, dropErr)
+		}
+		return nil, nil, err
+	}
+	return job, info, nil
+}
+
+// jobKey is the context key of the job of the running task
+type jobKey struct{}
+
+// running is the job of a task being processed
+type running struct {
+	tracker *Tracker
+	id      uuid.UUID
+}
+
+// Middleware follows the job of each task processed by the worker: it is
+// running while its handler runs, then completed, pending again when the
+// task is retried, or failed once retries are exhausted. Tasks without a
+// job are processed as is.
+func (t *Tracker) Middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		taskID, _ := asynq.GetTaskID(ctx)
+		var job model.Job
+		err := t.db.WithContext(ctx).Select("id").
+			Where("task_id = ? AND status IN ?", taskID, []string{string(entity.JobStatusPending), string(entity.JobStatusRunning)}).
+			Order("created_at DESC").
+			First(&job).Error
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Failed to load the job of task %s: %v", taskID, err)
+			}
+			return next.ProcessTask(ctx, task)
+		}
+
+		retried, _ := asynq.GetRetryCount(ctx)
+		now := time.Now()
+		updates := map[string]any{"status": string(entity.JobStatusRunning), "attempts": retried + 1}
+		if retried == 0 {
+			updates["started_at"] = &now
+		}
+		t.update(ctx, job.ID, updates)
+
+		err = next.ProcessTask(context.WithValue(ctx, jobKey{}, running{tracker: t, id: job.ID}), task)
+
+		now = time.Now()
+		switch {
+		case err == nil:
+			t.update(ctx, job.ID, map[string]any{
+				"status":       string(entity.JobStatusCompleted),
+				"progress":     100,
+				"error":        "",
+				"completed_at": &now,
+			})
+		case finalAttempt(ctx, err):
+			t.update(ctx, job.ID, map[string]any{
+				"status":       string(entity.JobStatusFailed),
+				"error":        err.Error(),
+				"completed_at": &now,
+			})
+		default:
+			t.update(ctx, job.ID, map[string]any{
+				"status": string(entity.JobStatusPending),
+				"error":  err.Error(),
+			})
+		}
+		return err
+	})
+}
+
+// Progress sets the progress of the job of the running task to the share
+// of done items out of total. It does nothing for tasks without a job.
+func Progress(ctx context.Context, done, total int) {
+	job, ok := ctx.Value(jobKey{}).(running)
+	if !ok || total <= 0 {
+		return
+	}
+	// 100 is left for the end of the task
+	percent := min(done*100/total, 99)
+	job.tracker.update(ctx, job.id, map[string]any{"progress": percent})
+}
+
+func (t *Tracker) update(ctx context.Context, id uuid.UUID, updates map[string]any) {
+	// A cancelled task context must not keep its job from being updated
+	if err := t.db.WithContext(context.WithoutCancel(ctx)).Model(&model.Job{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update job %s: %v", id, err)
+	}
+}
+
+// finalAttempt reports whether a failed task will not be retried
+func finalAttempt(ctx context.Context, err error) bool {
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return retried >= maxRetry || errors.Is(err, asynq.SkipRetry)
+}
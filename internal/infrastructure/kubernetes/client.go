@@ -0,0 +1,241 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"gopkg.in/yaml.v3"
+)
+
+// In-cluster service account files, mounted in every pod
+const (
+	serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCA    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// ErrNotFound is returned when the API server has no such resource, e.g.
+// the metrics API of a cluster without metrics-server
+var ErrNotFound = errors.New("kubernetes resource not found")
+
+// StatusError is returned when the API server answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Path       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("kubernetes API %s returned %d", e.Path, e.StatusCode)
+}
+
+// HTTPStatusCode lets the rate limiter recognize throttled calls
+func (e *StatusError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// client is a minimal read-only client of the Kubernetes REST API
+type client struct {
+	server   string
+	token    string
+	username string
+	password string
+	http     *http.Client
+}
+
+// kubeconfig is the subset of a kubeconfig file used to reach a cluster
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string    `yaml:"token"`
+			ClientCertificateData string    `yaml:"client-certificate-data"`
+			ClientKeyData         string    `yaml:"client-key-data"`
+			Username              string    `yaml:"username"`
+			Password              string    `yaml:"password"`
+			Exec                  *struct{} `yaml:"exec"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// newClient creates a client from kubeconfig content, or from the in-cluster
+// service account when it is empty. Only embedded credentials are supported:
+// kubeconfigs relying on files or exec plugins cannot be used by workers.
+func newClient(config []byte) (*client, error) {
+	if len(strings.TrimSpace(string(config))) == 0 {
+		return inClusterClient()
+	}
+
+	var kc kubeconfig
+	if err := yaml.Unmarshal(config, &kc); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	if kc.CurrentContext == "" && len(kc.Contexts) > 0 {
+		kc.CurrentContext = kc.Contexts[0].Name
+	}
+
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == kc.CurrentContext {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig context %q not found", kc.CurrentContext)
+	}
+
+	c := &client{}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	found = false
+	for _, cl := range kc.Clusters {
+		if cl.Name != clusterName {
+			continue
+		}
+		found = true
+		c.server = strings.TrimRight(cl.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = cl.Cluster.InsecureSkipTLSVerify
+		if cl.Cluster.CertificateAuthorityData != "" {
+			pool, err := certPool(cl.Cluster.CertificateAuthorityData)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	if !found || c.server == "" {
+		return nil, fmt.Errorf("kubeconfig cluster %q not found", clusterName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil {
+			return nil, errors.New("kubeconfig exec credential plugins are not supported, use a service account token")
+		}
+		c.token, c.username, c.password = u.User.Token, u.User.Username, u.User.Password
+		if u.User.ClientCertificateData != "" {
+			cert, err := clientCertificate(u.User.ClientCertificateData, u.User.ClientKeyData)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	c.http = httpClient(tlsConfig)
+	return c, nil
+}
+
+func inClusterClient() (*client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("no kubeconfig provided and not running in a cluster")
+	}
+	token, err := os.ReadFile(serviceAccountToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	ca, err := os.ReadFile(serviceAccountCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid service account CA")
+	}
+
+	return &client{
+		server: "https://" + net.JoinHostPort(host, port),
+		token:  strings.TrimSpace(string(token)),
+		http:   httpClient(&tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}),
+	}, nil
+}
+
+func httpClient(tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}
+}
+
+func certPool(data string) (*x509.CertPool, error) {
+	pem, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate authority data: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("invalid certificate authority data")
+	}
+	return pool, nil
+}
+
+func clientCertificate(certData, keyData string) (tls.Certificate, error) {
+	cert, err := base64.StdEncoding.DecodeString(certData)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid client certificate data: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(keyData)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid client key data: %w", err)
+	}
+	return tls.X509KeyPair(cert, key)
+}
+
+// get decodes the JSON answer of a GET on the API server. Calls go through
+// the account's rate limiter.
+func (c *client) get(ctx context.Context, path string, out any) error {
+	return ratelimit.Call(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+path, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		switch {
+		case c.token != "":
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		case c.username != "":
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrNotFound
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return &StatusError{StatusCode: resp.StatusCode, Path: path}
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	})
+}
@@ -0,0 +1,152 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// deployments detects deployments scaled to zero and over-provisioned ones
+type deployments struct {
+	*cluster
+}
+
+var _ service.ResourceDetector = deployments{}
+
+// Scan implements service.ResourceDetector
+func (s deployments) Scan(ctx context.Context, namespace string) ([]*entity.Resource, error) {
+	var deployments list[deployment]
+	if err := s.client.get(ctx, apiPath("/apis/apps/v1", namespace, "deployments"), &deployments); err != nil {
+		return nil, err
+	}
+
+	// Usage is optional: clusters without metrics-server are scanned
+	// without rightsizing
+	var metrics list[podMetrics]
+	var pods list[pod]
+	err := s.client.get(ctx, apiPath("/apis/metrics.k8s.io/v1beta1", namespace, "pods"), &metrics)
+	hasMetrics := err == nil
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to read pod metrics: %w", err)
+	}
+	if hasMetrics {
+		if err := s.client.get(ctx, apiPath("/api/v1", namespace, "pods"), &pods); err != nil {
+			return nil, err
+		}
+	}
+	podLabels := make(map[string]map[string]string, len(pods.Items))
+	for _, p := range pods.Items {
+		podLabels[p.Metadata.Namespace+"/"+p.Metadata.Name] = p.Metadata.Labels
+	}
+
+	resources := make([]*entity.Resource, 0, len(deployments.Items))
+	for _, d := range deployments.Items {
+		r := s.newResource(entity.ResourceTypeK8sDeployment, d.Metadata)
+
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		cpu, memory := podRequests(d.Spec.Template.Spec.Containers)
+		r.Metadata[MetadataReplicas] = int(replicas)
+		r.Metadata[MetadataCPURequests] = cpu
+		r.Metadata[MetadataMemoryRequests] = memory
+		// Capacity of all replicas, for the carbon estimator
+		r.Metadata[service.ComputeMetadataVCPUs] = float64(replicas) * cpu
+		r.Metadata[service.ComputeMetadataMemoryGB] = float64(replicas) * memory / bytesPerGiB
+
+		lastChange := d.Metadata.CreationTimestamp
+		for _, c := range d.Status.Conditions {
+			if c.LastUpdateTime.After(lastChange) {
+				lastChange = c.LastUpdateTime
+			}
+		}
+		r.Metadata[MetadataLastChange] = lastChange.UTC().Format(time.RFC3339)
+
+		if hasMetrics && replicas > 0 {
+			var cpuUsage, memoryUsage float64
+			for _, m := range metrics.Items {
+				if m.Metadata.Namespace != d.Metadata.Namespace || !matches(d.Spec.Selector.MatchLabels, podLabels[m.Metadata.Namespace+"/"+m.Metadata.Name]) {
+					continue
+				}
+				for _, c := range m.Containers {
+					cpuUsage += parseQuantity(c.Usage["cpu"])
+					memoryUsage += parseQuantity(c.Usage["memory"])
+				}
+			}
+			r.Metadata[MetadataCPUUsage] = cpuUsage
+			r.Metadata[MetadataMemoryUsage] = memoryUsage
+			if cpu > 0 {
+				r.Metadata[service.ComputeMetadataCPUAvg] = cpuUsage / (float64(replicas) * cpu) * 100
+			}
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// DetectUnused flags deployments scaled to zero for longer than the
+// configured age. Deployments using a small share of their requests are
+// kept active with a rightsizing suggestion.
+func (s deployments) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	now := s.now()
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		replicas := metadataFloat(r.Metadata[MetadataReplicas])
+		if replicas > 0 {
+			if suggestion, ok := s.rightsizing(r, replicas); ok {
+				r.Metadata[MetadataRightsizing] = suggestion
+			}
+			continue
+		}
+		lastChange, err := time.Parse(time.RFC3339, fmt.Sprint(r.Metadata[MetadataLastChange]))
+		if err == nil && now.Sub(lastChange) >= s.cfg.ScaledDownAge {
+			markUnused(r, IdleReasonScaledToZero)
+		}
+	}
+	return nil
+}
+
+// rightsizing suggests requests giving pods twice their current usage when
+// they use less than the idle utilization of what they request
+func (s deployments) rightsizing(r *entity.Resource, replicas float64) (map[string]any, bool) {
+	cpuUsage, ok := r.Metadata[MetadataCPUUsage]
+	if !ok {
+		return nil, false
+	}
+	cpuReq := metadataFloat(r.Metadata[MetadataCPURequests]) * replicas
+	memReq := metadataFloat(r.Metadata[MetadataMemoryRequests]) * replicas
+	cpuUsed := metadataFloat(cpuUsage)
+	memUsed := metadataFloat(r.Metadata[MetadataMemoryUsage])
+	if cpuReq == 0 || cpuUsed/cpuReq >= s.cfg.IdleUtilization {
+		return nil, false
+	}
+
+	suggestedCPU := 2 * cpuUsed / replicas
+	suggestedMem := memReq / replicas
+	if memReq > 0 && memUsed/memReq < s.cfg.IdleUtilization {
+		suggestedMem = 2 * memUsed / replicas
+	}
+	savings := replicas * entity.HoursPerMonth * ((cpuReq/replicas-suggestedCPU)*s.cfg.CPUHourPrice +
+		(memReq/replicas-suggestedMem)/bytesPerGiB*s.cfg.MemoryGBHourPrice)
+	return map[string]any{
+		"cpu_requests":    suggestedCPU,
+		"memory_requests": suggestedMem,
+		"monthly_savings": savings,
+	}, true
+}
+
+// EstimateCost prices deployments from their requests
+func (s deployments) EstimateCost(ctx context.Context, r *entity.Resource) (entity.Cost, error) {
+	replicas := metadataFloat(r.Metadata[MetadataReplicas])
+	cpu := metadataFloat(r.Metadata[MetadataCPURequests])
+	memory := metadataFloat(r.Metadata[MetadataMemoryRequests]) / bytesPerGiB
+	hourly := replicas * (cpu*s.cfg.CPUHourPrice + memory*s.cfg.MemoryGBHourPrice)
+	return entity.Cost{Amount: hourly, Currency: entity.CurrencyUSD, Period: entity.BillingPeriodHourly}, nil
+}
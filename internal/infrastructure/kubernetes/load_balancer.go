@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// loadBalancers detects LoadBalancer Services without ready backends
+type loadBalancers struct {
+	*cluster
+}
+
+var _ service.ResourceDetector = loadBalancers{}
+
+// Scan implements service.ResourceDetector
+func (s loadBalancers) Scan(ctx context.Context, namespace string) ([]*entity.Resource, error) {
+	var services list[kubeService]
+	if err := s.client.get(ctx, apiPath("/api/v1", namespace, "services"), &services); err != nil {
+		return nil, err
+	}
+	var eps list[endpoints]
+	if err := s.client.get(ctx, apiPath("/api/v1", namespace, "endpoints"), &eps); err != nil {
+		return nil, err
+	}
+	ready := make(map[string]int, len(eps.Items))
+	for _, e := range eps.Items {
+		for _, subset := range e.Subsets {
+			ready[e.Metadata.Namespace+"/"+e.Metadata.Name] += len(subset.Addresses)
+		}
+	}
+
+	var resources []*entity.Resource
+	for _, svc := range services.Items {
+		if svc.Spec.Type != "LoadBalancer" {
+			continue
+		}
+		r := s.newResource(entity.ResourceTypeK8sLoadBalancer, svc.Metadata)
+		r.Metadata[MetadataReadyEndpoints] = ready[svc.Metadata.Namespace+"/"+svc.Metadata.Name]
+		for _, ing := range svc.Status.LoadBalancer.Ingress {
+			if ing.Hostname != "" {
+				r.Metadata["ingress"] = ing.Hostname
+			} else if ing.IP != "" {
+				r.Metadata["ingress"] = ing.IP
+			}
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// DetectUnused flags load balancers without ready backends
+func (s loadBalancers) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	for _, r := range resources {
+		if r.Status != entity.ResourceStatusExcluded && metadataFloat(r.Metadata[MetadataReadyEndpoints]) == 0 {
+			markUnused(r, IdleReasonNoEndpoints)
+		}
+	}
+	return nil
+}
+
+// EstimateCost prices load balancers per hour
+func (s loadBalancers) EstimateCost(ctx context.Context, r *entity.Resource) (entity.Cost, error) {
+	return entity.Cost{Amount: s.cfg.LoadBalancerPrice, Currency: entity.CurrencyUSD, Period: entity.BillingPeriodHourly}, nil
+}
@@ -0,0 +1,163 @@
+package kubernetes
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The subset of Kubernetes API objects read by the scanner
+
+type objectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	UID               string            `json:"uid"`
+	Labels            map[string]string `json:"labels"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `json:"requests"`
+}
+
+type container struct {
+	Resources resourceRequirements `json:"resources"`
+}
+
+type deployment struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Replicas *int32 `json:"replicas"`
+		Selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		} `json:"selector"`
+		Template struct {
+			Spec struct {
+				Containers []container `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []struct {
+			Type           string    `json:"type"`
+			LastUpdateTime time.Time `json:"lastUpdateTime"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+type persistentVolumeClaim struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		StorageClassName *string              `json:"storageClassName"`
+		Resources        resourceRequirements `json:"resources"`
+	} `json:"spec"`
+	Status struct {
+		Phase    string            `json:"phase"`
+		Capacity map[string]string `json:"capacity"`
+	} `json:"status"`
+}
+
+type pod struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Volumes []struct {
+			PersistentVolumeClaim *struct {
+				ClaimName string `json:"claimName"`
+			} `json:"persistentVolumeClaim"`
+		} `json:"volumes"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+type kubeService struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Type string `json:"type"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP       string `json:"ip"`
+				Hostname string `json:"hostname"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+type endpoints struct {
+	Metadata objectMeta `json:"metadata"`
+	Subsets  []struct {
+		Addresses []struct {
+			IP string `json:"ip"`
+		} `json:"addresses"`
+	} `json:"subsets"`
+}
+
+type podMetrics struct {
+	Metadata   objectMeta `json:"metadata"`
+	Containers []struct {
+		Usage map[string]string `json:"usage"`
+	} `json:"containers"`
+}
+
+type list[T any] struct {
+	Items []T `json:"items"`
+}
+
+// quantitySuffixes are the multipliers of Kubernetes resource quantities
+var quantitySuffixes = []struct {
+	suffix string
+	factor float64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+	{"n", 1e-9}, {"u", 1e-6}, {"m", 1e-3},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+}
+
+// parseQuantity converts a resource quantity ("250m", "1Gi", "2") to a
+// number of cores or bytes. Invalid quantities count as 0.
+func parseQuantity(q string) float64 {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return 0
+	}
+	factor := 1.0
+	for _, s := range quantitySuffixes {
+		if strings.HasSuffix(q, s.suffix) {
+			q, factor = strings.TrimSuffix(q, s.suffix), s.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(q, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0
+	}
+	return n * factor
+}
+
+// podRequests sums the CPU (cores) and memory (bytes) requests of a pod
+// template
+func podRequests(containers []container) (cpu, memory float64) {
+	for _, c := range containers {
+		cpu += parseQuantity(c.Resources.Requests["cpu"])
+		memory += parseQuantity(c.Resources.Requests["memory"])
+	}
+	return cpu, memory
+}
+
+// matches reports whether labels satisfy a matchLabels selector. An empty
+// selector matches nothing, as for deployments.
+func matches(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package kubernetes
+
+import (
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+)
+
+// Kubernetes namespaces play the role of regions, "all" scanning the whole
+// cluster
+func init() {
+	provider.Register(provider.Provider{
+		Name:        entity.CloudProviderKubernetes,
+		DisplayName: "Kubernetes",
+		RegionLabel: "namespace",
+		ResourceTypes: []entity.ResourceType{
+			entity.ResourceTypeK8sDeployment,
+			entity.ResourceTypeK8sPVC,
+			entity.ResourceTypeK8sLoadBalancer,
+		},
+		Credentials: []provider.CredentialField{
+			{Name: "kubeconfig", Description: "Kubeconfig of the cluster; the in-cluster service account is used when empty", Secret: true},
+		},
+	})
+
+	for _, d := range []struct {
+		resourceType entity.ResourceType
+		heuristics   []string
+		new          func(c *cluster) service.ResourceDetector
+	}{
+		{
+			resourceType: entity.ResourceTypeK8sDeployment,
+			heuristics: []string{
+				"Scaled to zero replicas for longer than kubernetes.scaledDownAge",
+				"Pods using less than kubernetes.idleUtilization of their CPU requests get a rightsizing suggestion",
+			},
+			new: func(c *cluster) service.ResourceDetector { return deployments{c} },
+		},
+		{
+			resourceType: entity.ResourceTypeK8sPVC,
+			heuristics: []string{
+				"Not bound to a persistent volume",
+				"Not mounted by any running pod",
+			},
+			new: func(c *cluster) service.ResourceDetector { return persistentVolumeClaims{c} },
+		},
+		{
+			resourceType: entity.ResourceTypeK8sLoadBalancer,
+			heuristics:   []string{"No ready endpoint behind the Service"},
+			new:          func(c *cluster) service.ResourceDetector { return loadBalancers{c} },
+		},
+	} {
+		d := d
+		provider.RegisterDetector(provider.Detector{
+			Provider:     entity.CloudProviderKubernetes,
+			ResourceType: d.resourceType,
+			Heuristics:   d.heuristics,
+			New: func(credentials []byte, cfg *config.Config) (service.ResourceDetector, error) {
+				c, err := newCluster(credentials, cfg.Kubernetes)
+				if err != nil {
+					return nil, err
+				}
+				return d.new(c), nil
+			},
+		})
+	}
+}
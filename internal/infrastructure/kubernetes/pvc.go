@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// persistentVolumeClaims detects claims no running pod mounts
+type persistentVolumeClaims struct {
+	*cluster
+}
+
+var _ service.ResourceDetector = persistentVolumeClaims{}
+
+// Scan implements service.ResourceDetector
+func (s persistentVolumeClaims) Scan(ctx context.Context, namespace string) ([]*entity.Resource, error) {
+	var claims list[persistentVolumeClaim]
+	if err := s.client.get(ctx, apiPath("/api/v1", namespace, "persistentvolumeclaims"), &claims); err != nil {
+		return nil, err
+	}
+	var pods list[pod]
+	if err := s.client.get(ctx, apiPath("/api/v1", namespace, "pods"), &pods); err != nil {
+		return nil, err
+	}
+
+	// Claims mounted by a pod that has not terminated
+	mounted := make(map[string]bool)
+	for _, p := range pods.Items {
+		if p.Status.Phase == "Succeeded" || p.Status.Phase == "Failed" {
+			continue
+		}
+		for _, v := range p.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				mounted[p.Metadata.Namespace+"/"+v.PersistentVolumeClaim.ClaimName] = true
+			}
+		}
+	}
+
+	resources := make([]*entity.Resource, 0, len(claims.Items))
+	for _, c := range claims.Items {
+		r := s.newResource(entity.ResourceTypeK8sPVC, c.Metadata)
+		size := parseQuantity(c.Status.Capacity["storage"])
+		if size == 0 {
+			size = parseQuantity(c.Spec.Resources.Requests["storage"])
+		}
+		r.Metadata[MetadataPhase] = c.Status.Phase
+		r.Metadata[MetadataStorageBytes] = int64(size)
+		r.Metadata[service.VolumeMetadataSizeGB] = size / bytesPerGiB
+		r.Metadata[MetadataMounted] = mounted[c.Metadata.Namespace+"/"+c.Metadata.Name]
+		if c.Spec.StorageClassName != nil {
+			r.Metadata[MetadataStorageClass] = *c.Spec.StorageClassName
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// DetectUnused flags claims that are not bound or that no running pod
+// mounts
+func (s persistentVolumeClaims) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	for _, r := range resources {
+		if r.Status == entity.ResourceStatusExcluded {
+			continue
+		}
+		if phase, _ := r.Metadata[MetadataPhase].(string); phase != "Bound" {
+			markUnused(r, IdleReasonUnbound)
+		} else if mounted, _ := r.Metadata[MetadataMounted].(bool); !mounted {
+			markUnused(r, IdleReasonUnmounted)
+		}
+	}
+	return nil
+}
+
+// EstimateCost prices claims from their size
+func (s persistentVolumeClaims) EstimateCost(ctx context.Context, r *entity.Resource) (entity.Cost, error) {
+	size := metadataFloat(r.Metadata[MetadataStorageBytes]) / bytesPerGiB
+	return entity.MonthlyUSDCost(size * s.cfg.StorageGBMonthPrice), nil
+}
@@ -0,0 +1,102 @@
+package kubernetes
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/google/uuid"
+)
+
+// Metadata keys set on Kubernetes resources
+const (
+	MetadataReplicas       = "replicas"
+	MetadataCPURequests    = "cpu_requests"    // cores requested per pod
+	MetadataMemoryRequests = "memory_requests" // bytes requested per pod
+	MetadataCPUUsage       = "cpu_usage"       // cores used by all pods, from metrics-server
+	MetadataMemoryUsage    = "memory_usage"    // bytes used by all pods, from metrics-server
+	MetadataLastChange     = "last_change_at"  // last rollout or scaling of a deployment
+	MetadataPhase          = "phase"           // PVC phase, e.g. "Bound", "Pending", "Lost"
+	MetadataStorageBytes   = "storage_bytes"
+	MetadataStorageClass   = "storage_class"
+	MetadataMounted        = "mounted"         // whether a pod mounts the PVC
+	MetadataReadyEndpoints = "ready_endpoints" // ready backends of a Service
+	MetadataIdleReason     = "idle_reason"     // set by DetectUnused on unused resources
+	MetadataRightsizing    = "rightsizing"     // set by DetectUnused on over-provisioned deployments
+)
+
+// Reasons a Kubernetes resource is reported as unused
+const (
+	IdleReasonScaledToZero = "scaled_to_zero"
+	IdleReasonUnbound      = "unbound"
+	IdleReasonUnmounted    = "unmounted"
+	IdleReasonNoEndpoints  = "no_endpoints"
+)
+
+// allNamespaces is the region scanning every namespace of a cluster
+const allNamespaces = "all"
+
+const bytesPerGiB = 1 << 30
+
+// cluster is the connection of a detector to a Kubernetes cluster.
+// Namespaces play the role of regions; the "all"
+// region scans the whole cluster.
+type cluster struct {
+	client *client
+	cfg    config.KubernetesConfig
+	now    func() time.Time
+}
+
+// newCluster connects to a cluster from kubeconfig content, or from the
+// in-cluster service account when credentials are empty
+func newCluster(credentials []byte, cfg config.KubernetesConfig) (*cluster, error) {
+	c, err := newClient(credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &cluster{client: c, cfg: cfg, now: time.Now}, nil
+}
+
+func (s *cluster) newResource(resourceType entity.ResourceType, meta objectMeta) *entity.Resource {
+	r := entity.NewResource(uuid.Nil, entity.CloudProviderKubernetes, resourceType, meta.Namespace+"/"+meta.Name, meta.Namespace, meta.Name)
+	for k, v := range meta.Labels {
+		r.Tags[k] = v
+	}
+	r.Metadata["uid"] = meta.UID
+	if !meta.CreationTimestamp.IsZero() {
+		r.CreatedAt = meta.CreationTimestamp
+	}
+	return r
+}
+
+// apiPath builds the path listing a resource in a namespace, or in the whole
+// cluster for the "all" namespace
+func apiPath(group, namespace, resource string) string {
+	if namespace == "" || namespace == allNamespaces {
+		return group + "/" + resource
+	}
+	return group + "/namespaces/" + url.PathEscape(namespace) + "/" + resource
+}
+
+// metadataFloat reads a number stored in metadata, which may have been
+// decoded from JSON as a float64
+func metadataFloat(v any) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// markUnused marks a resource as unused for a reason
+func markUnused(r *entity.Resource, reason string) {
+	r.MarkAsUnused()
+	r.Metadata[MetadataIdleReason] = reason
+}
@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Severities of alerts, those of PagerDuty events
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// Alert is an event sent to the chat and paging channels of an
+// organization
+type Alert struct {
+	Event    string  `json:"event"` // e.g. entity.NotificationEventScanFinished
+	Title    string  `json:"title"`
+	Text     string  `json:"text,omitempty"`
+	Severity string  `json:"severity"`
+	Source   string  `json:"source"`    // affected system, e.g. "aws eu-west-1"
+	DedupKey string  `json:"dedup_key"` // groups the alerts of the same incident
+	Fields   []Field `json:"fields,omitempty"`
+}
+
+// Field is a labelled value of an alert
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Channel sends alerts to a target: a webhook URL or a routing key
+type Channel interface {
+	Send(ctx context.Context, target string, alert Alert) error
+}
+
+// StatusError is returned when a channel answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("notification endpoint returned %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether a failed alert may be sent later. Client errors
+// other than timeouts and rate limiting are permanent.
+func Retryable(err error) bool {
+	var status *StatusError
+	if !errors.As(err, &status) {
+		return true
+	}
+	return status.StatusCode >= 500 ||
+		status.StatusCode == http.StatusRequestTimeout ||
+		status.StatusCode == http.StatusTooManyRequests
+}
+
+// postJSON posts a JSON body and checks the response status
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CloudSweep-Notification")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(msg)}
+	}
+	return nil
+}
+
+// defaultHTTPClient bounds the calls to the notification channels
+func defaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+)
+
+// PagerDutyEventsURL is the endpoint of the PagerDuty Events API v2
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// maxPagerDutySummary is the longest summary PagerDuty accepts
+const maxPagerDutySummary = 1024
+
+// PagerDutyChannel triggers PagerDuty incidents through the Events API v2
+type PagerDutyChannel struct {
+	url  string
+	http *http.Client
+}
+
+var _ Channel = (*PagerDutyChannel)(nil)
+
+// NewPagerDutyChannel creates a new PagerDutyChannel
+func NewPagerDutyChannel() *PagerDutyChannel {
+	return &PagerDutyChannel{url: PagerDutyEventsURL, http: defaultHTTPClient()}
+}
+
+// Send triggers an event for the service of the routing key. Alerts with
+// the same dedup key are grouped in the same incident.
+func (p *PagerDutyChannel) Send(ctx context.Context, routingKey string, alert Alert) error {
+	return postJSON(ctx, p.http, p.url, pagerDutyEvent(routingKey, alert))
+}
+
+// pagerDutyEvent renders an alert as a trigger event
+func pagerDutyEvent(routingKey string, alert Alert) map[string]any {
+	summary := alert.Title
+	if len(summary) > maxPagerDutySummary {
+		summary = summary[:maxPagerDutySummary]
+	}
+	severity := alert.Severity
+	if severity == "" {
+		severity = SeverityInfo
+	}
+	details := map[string]string{}
+	if alert.Text != "" {
+		details["text"] = alert.Text
+	}
+	for _, f := range alert.Fields {
+		details[f.Name] = f.Value
+	}
+
+	return map[string]any{
+		"routing_key":  routingKey,
+		"event_action": "trigger",
+		"dedup_key":    alert.DedupKey,
+		"client":       "CloudSweep",
+		"payload": map[string]any{
+			"summary":        summary,
+			"source":         alert.Source,
+			"severity":       severity,
+			"class":          alert.Event,
+			"custom_details": details,
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// suppressPrefix prefixes the keys of the events sent within the window
+const suppressPrefix = "cloudsweep:suppress:"
+
+// Suppressor drops events identical to one sent within a window, across
+// every worker replica. A nil Suppressor, or one with no window, drops
+// nothing.
+type Suppressor struct {
+	client *redis.Client
+	window time.Duration
+}
+
+// NewSuppressor creates a suppressor remembering the events sent in Redis
+// for window
+func NewSuppressor(client *redis.Client, window time.Duration) *Suppressor {
+	return &Suppressor{client: client, window: window}
+}
+
+// Claim reports whether the event of fingerprint may be sent to scope, e.g.
+// an organization channel, and remembers it for the window when it may.
+// Events are sent when Redis fails: a duplicate is better than a lost alert.
+func (s *Suppressor) Claim(ctx context.Context, scope, fingerprint string) (bool, error) {
+	if s == nil || s.window <= 0 {
+		return true, nil
+	}
+	claimed, err := s.client.SetNX(ctx, suppressPrefix+scope+":"+fingerprint, 1, s.window).Result()
+	if err != nil {
+		return true, err
+	}
+	return claimed, nil
+}
+
+// Release forgets an event that could not be sent, so that its retries are
+// not dropped
+func (s *Suppressor) Release(ctx context.Context, scope, fingerprint string) {
+	if s == nil || s.window <= 0 {
+		return
+	}
+	s.client.Del(ctx, suppressPrefix+scope+":"+fingerprint)
+}
+
+// Fingerprint identifies the content of an event: the hex SHA-256 of its
+// JSON encoding
+func Fingerprint(v any) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint identifies alerts of identical content. Fields and the
+// deduplication key are left out: they name the scan or run the alert
+// comes from, which differ between identical alerts.
+func (a Alert) Fingerprint() string {
+	return Fingerprint([]string{a.Event, a.Severity, a.Source, a.Title, a.Text})
+}
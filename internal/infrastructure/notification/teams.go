@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+)
+
+// TeamsChannel posts alerts as Adaptive Cards to Microsoft Teams incoming
+// webhooks
+type TeamsChannel struct {
+	http *http.Client
+}
+
+var _ Channel = (*TeamsChannel)(nil)
+
+// NewTeamsChannel creates a new TeamsChannel
+func NewTeamsChannel() *TeamsChannel {
+	return &TeamsChannel{http: defaultHTTPClient()}
+}
+
+// Send posts the alert to the incoming webhook URL
+func (t *TeamsChannel) Send(ctx context.Context, webhookURL string, alert Alert) error {
+	return postJSON(ctx, t.http, webhookURL, teamsMessage(alert))
+}
+
+// teamsMessage renders an alert as a message holding an Adaptive Card
+func teamsMessage(alert Alert) map[string]any {
+	color := "Default"
+	switch alert.Severity {
+	case SeverityWarning:
+		color = "Warning"
+	case SeverityError, SeverityCritical:
+		color = "Attention"
+	}
+
+	body := []map[string]any{
+		{"type": "TextBlock", "text": alert.Title, "weight": "Bolder", "size": "Medium", "color": color, "wrap": true},
+	}
+	if alert.Text != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": alert.Text, "wrap": true})
+	}
+	if len(alert.Fields) > 0 {
+		facts := make([]map[string]string, len(alert.Fields))
+		for i, f := range alert.Fields {
+			facts[i] = map[string]string{"title": f.Name, "value": f.Value}
+		}
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	}
+}
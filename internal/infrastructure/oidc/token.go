@@ -0,0 +1,90 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/signing"
+)
+
+// ErrInvalidToken is returned for tampered, malformed or expired login
+// states and sessions
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// LoginState is what the login keeps in a cookie until the provider
+// redirects back to the callback
+type LoginState struct {
+	OrganizationID string `json:"o"`
+	State          string `json:"s"`
+	Nonce          string `json:"n"`
+	Verifier       string `json:"v"`
+	ExpiresAt      int64  `json:"e"`
+}
+
+// Session identifies a user signed in to an organization
+type Session struct {
+	UserID         string `json:"u"`
+	OrganizationID string `json:"o"`
+	Role           string `json:"r"`
+	ExpiresAt      int64  `json:"e"`
+}
+
+// Signer signs and verifies login states and sessions. Each kind of token
+// is signed with its own purpose, so one cannot be used as the other.
+type Signer struct {
+	tokens *signing.Signer
+}
+
+// NewSigner creates a new Signer
+func NewSigner(key string) *Signer {
+	return &Signer{tokens: signing.New(key)}
+}
+
+// SignState returns a token for the login state
+func (s *Signer) SignState(st LoginState) string {
+	return s.tokens.Sign("state", st)
+}
+
+// VerifyState checks the token signature and expiry and returns its login
+// state
+func (s *Signer) VerifyState(token string) (LoginState, error) {
+	var st LoginState
+	if err := s.tokens.Verify("state", token, &st); err != nil || time.Now().Unix() > st.ExpiresAt {
+		return LoginState{}, ErrInvalidToken
+	}
+	return st, nil
+}
+
+// SignSession returns a bearer token for the session
+func (s *Signer) SignSession(sess Session) string {
+	return s.tokens.Sign("session", sess)
+}
+
+// VerifySession checks the token signature and expiry and returns its
+// session
+func (s *Signer) VerifySession(token string) (Session, error) {
+	var sess Session
+	if err := s.tokens.Verify("session", token, &sess); err != nil || time.Now().Unix() > sess.ExpiresAt {
+		return Session{}, ErrInvalidToken
+	}
+	return sess, nil
+}
+
+// RandomString returns a URL-safe random string, for states, nonces and
+// PKCE verifiers
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge of a code verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
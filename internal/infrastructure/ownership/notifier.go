@@ -0,0 +1,309 @@
+package ownership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/slack"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// item is a resource listed in a message to its owner
+type item struct {
+	ID          uuid.UUID
+	ResourceID  string
+	Name        string
+	Provider    string
+	Type        string
+	Region      string
+	MonthlyCost float64
+}
+
+// Notifier emails or messages on Slack the owners of the resources of
+// policies with the notify action, right away or in a daily digest
+type Notifier struct {
+	db       *gorm.DB
+	resolver *Resolver
+	mailer   notification.Mailer
+	slack    *slack.Client
+	cfg      config.OwnersConfig
+}
+
+var _ service.OwnerNotifier = (*Notifier)(nil)
+
+// NewNotifier creates a new Notifier. slackClient is nil when the Slack app
+// is not configured, in which case owners are only emailed.
+func NewNotifier(db *gorm.DB, resolver *Resolver, mailer notification.Mailer, slackClient *slack.Client, cfg config.OwnersConfig) *Notifier {
+	return &Notifier{db: db, resolver: resolver, mailer: mailer, slack: slackClient, cfg: cfg}
+}
+
+// NotifyOwners implements service.OwnerNotifier. The owner of each resource
+// is resolved again and stored when it changed.
+func (n *Notifier) NotifyOwners(ctx context.Context, orgID uuid.UUID, resources []*entity.Resource, taskID string) (map[uuid.UUID]string, error) {
+	var org model.Organization
+	if err := n.db.WithContext(ctx).First(&org, "id = ?", orgID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load organization %s: %w", orgID, err)
+	}
+	resolver, err := n.resolver.Load(ctx, &org)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	byOwner := make(map[string][]item)
+	for _, r := range resources {
+		owner, source := resolver.Resolve(r)
+		if owner != r.Owner || source != r.OwnerSource {
+			if err := n.resolver.store(ctx, r.ID, owner, source); err != nil {
+				errs = append(errs, fmt.Errorf("failed to store owner of %s: %w", r.ResourceID, err))
+			}
+			r.Owner, r.OwnerSource = owner, source
+		}
+		if owner == "" {
+			continue
+		}
+		byOwner[owner] = append(byOwner[owner], item{
+			ID:          r.ID,
+			ResourceID:  r.ResourceID,
+			Name:        r.Name,
+			Provider:    string(r.Provider),
+			Type:        string(r.Type),
+			Region:      r.Region,
+			MonthlyCost: r.MonthlyCost,
+		})
+	}
+
+	notified := make(map[uuid.UUID]string)
+	digest := org.Settings().OwnerNotifications.Digest
+	for owner, items := range byOwner {
+		if digest {
+			err = n.queue(ctx, orgID, owner, items, taskID)
+		} else {
+			err = n.send(ctx, &org, owner, items)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", owner, err))
+			continue
+		}
+		for _, it := range items {
+			notified[it.ID] = owner
+		}
+	}
+	return notified, errors.Join(errs...)
+}
+
+// SendDigests sends each owner the resources queued for them, one message
+// per owner and organization. Resources cleaned up or excluded since are
+// left out. It returns the number of messages sent.
+func (n *Notifier) SendDigests(ctx context.Context) (int, error) {
+	var pending []model.OwnerNotification
+	err := n.db.WithContext(ctx).Preload("Resource").
+		Where("sent_at IS NULL").
+		Order("organization_id, owner, created_at").
+		Find(&pending).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load owner notifications: %w", err)
+	}
+
+	type key struct {
+		orgID uuid.UUID
+		owner string
+	}
+	groups := make(map[key][]model.OwnerNotification)
+	var keys []key
+	for _, p := range pending {
+		k := key{p.OrganizationID, p.Owner}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], p)
+	}
+
+	sent := 0
+	var errs []error
+	orgs := make(map[uuid.UUID]*model.Organization)
+	for _, k := range keys {
+		org, ok := orgs[k.orgID]
+		if !ok {
+			org = &model.Organization{}
+			if err := n.db.WithContext(ctx).First(org, "id = ?", k.orgID).Error; err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					errs = append(errs, fmt.Errorf("failed to load organization %s: %w", k.orgID, err))
+					continue
+				}
+				org = nil
+			}
+			orgs[k.orgID] = org
+		}
+
+		rows := groups[k]
+		ids := make([]uuid.UUID, len(rows))
+		var items []item
+		seen := make(map[uuid.UUID]bool)
+		for i, row := range rows {
+			ids[i] = row.ID
+			r := row.Resource
+			if r == nil || seen[r.ID] || !pendingStatus(r.Status) {
+				continue
+			}
+			seen[r.ID] = true
+			items = append(items, item{
+				ID:          r.ID,
+				ResourceID:  r.ResourceID,
+				Name:        r.Name,
+				Provider:    r.Provider,
+				Type:        r.Type,
+				Region:      r.Region,
+				MonthlyCost: r.MonthlyCost,
+			})
+		}
+
+		if org != nil && org.IsActive && len(items) > 0 {
+			if err := n.send(ctx, org, k.owner, items); err != nil {
+				errs = append(errs, fmt.Errorf("digest for %s: %w", k.owner, err))
+				continue
+			}
+			sent++
+		}
+		now := time.Now()
+		if err := n.db.WithContext(ctx).Model(&model.OwnerNotification{}).Where("id IN ?", ids).Update("sent_at", &now).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to mark digest for %s as sent: %w", k.owner, err))
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// queue adds the resources to the next digest of their owner, unless they
+// are already waiting in it
+func (n *Notifier) queue(ctx context.Context, orgID uuid.UUID, owner string, items []item, taskID string) error {
+	ids := make([]uuid.UUID, len(items))
+	for i, it := range items {
+		ids[i] = it.ID
+	}
+	var waiting []uuid.UUID
+	err := n.db.WithContext(ctx).Model(&model.OwnerNotification{}).
+		Where("owner = ? AND sent_at IS NULL AND resource_id IN ?", owner, ids).
+		Pluck("resource_id", &waiting).Error
+	if err != nil {
+		return err
+	}
+
+	rows := make([]model.OwnerNotification, 0, len(items))
+	for _, it := range items {
+		if slices.Contains(waiting, it.ID) {
+			continue
+		}
+		rows = append(rows, model.OwnerNotification{
+			OrganizationID: orgID,
+			ResourceID:     it.ID,
+			Owner:          owner,
+			TaskID:         taskID,
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return n.db.WithContext(ctx).Create(&rows).Error
+}
+
+// send tells an owner about their resources on the channels of the
+// organization. It fails only when no channel could reach the owner.
+func (n *Notifier) send(ctx context.Context, org *model.Organization, owner string, items []item) error {
+	sort.Slice(items, func(i, j int) bool { return items[i].MonthlyCost > items[j].MonthlyCost })
+	total := 0.0
+	for _, it := range items {
+		total += it.MonthlyCost
+	}
+
+	reached := false
+	var errs []error
+	for _, channel := range org.Settings().OwnerNotifications.NotifyChannels() {
+		var err error
+		switch channel {
+		case entity.OwnerChannelEmail:
+			err = n.mailer.Send(ctx, n.email(org, owner, items, total))
+		case entity.OwnerChannelSlack:
+			err = n.slackMessage(ctx, org.ID, owner, items, total)
+		default:
+			err = fmt.Errorf("unknown channel %q", channel)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+			continue
+		}
+		reached = true
+	}
+	if reached {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// slackMessage sends the owner a direct message from the Slack app of the
+// organization, to the workspace user with the owner email
+func (n *Notifier) slackMessage(ctx context.Context, orgID uuid.UUID, owner string, items []item, total float64) error {
+	if n.slack == nil {
+		return errors.New("the Slack app is not configured")
+	}
+	var installation model.SlackInstallation
+	if err := n.db.WithContext(ctx).First(&installation, "organization_id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("the Slack app is not installed")
+		}
+		return err
+	}
+	userID, err := n.slack.UserByEmail(ctx, installation.AccessToken, owner)
+	if err != nil {
+		return err
+	}
+
+	waste := make([]slack.WasteItem, len(items))
+	for i, it := range items {
+		waste[i] = slack.WasteItem{Name: it.Name, Type: it.Type, Provider: it.Provider, Region: it.Region, MonthlyCost: it.MonthlyCost}
+	}
+	return n.slack.PostMessage(ctx, installation.AccessToken, userID, slack.OwnerMessage(waste, total))
+}
+
+// email renders the message listing the resources of an owner
+func (n *Notifier) email(org *model.Organization, owner string, items []item, total float64) notification.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CloudSweep policies of %s found %d unused resources you own, costing $%.2f/month:\n\n", org.Name, len(items), total)
+	for _, it := range items {
+		name := it.Name
+		if name == "" {
+			name = it.ResourceID
+		}
+		fmt.Fprintf(&b, "- %s (%s %s, %s): $%.2f/month\n", name, it.Provider, it.Type, it.Region, it.MonthlyCost)
+		if n.cfg.ResourceURL != "" {
+			fmt.Fprintf(&b, "  %s\n", strings.ReplaceAll(n.cfg.ResourceURL, "{id}", it.ID.String()))
+		}
+	}
+	b.WriteString("\nDelete the resources you no longer need. Resources still in use can be excluded from cleanups in CloudSweep.\n")
+
+	return notification.Message{
+		To:      []string{owner},
+		Subject: fmt.Sprintf("[CloudSweep] You own %d unused resources costing $%.2f/month", len(items), total),
+		Text:    b.String(),
+	}
+}
+
+// pendingStatus reports whether a resource queued for a digest is still
+// worth telling its owner about
+func pendingStatus(status string) bool {
+	switch entity.ResourceStatus(status) {
+	case entity.ResourceStatusDeleted, entity.ResourceStatusRemoved, entity.ResourceStatusExcluded:
+		return false
+	}
+	return true
+}
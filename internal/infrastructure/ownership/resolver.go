@@ -0,0 +1,109 @@
+// Package ownership attributes resources to their owner, from the owner
+// mapping file, tags and default owners of each organization, and tells
+// owners about the resources of policies with the notify action.
+package ownership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// assignBatchSize is the number of resources read at once by Assign
+const assignBatchSize = 500
+
+// Resolver loads the owner rules of organizations and stores the owner of
+// their resources
+type Resolver struct {
+	db *gorm.DB
+}
+
+// NewResolver creates a new Resolver
+func NewResolver(db *gorm.DB) *Resolver {
+	return &Resolver{db: db}
+}
+
+// Load returns the owner resolver of an organization: its owner mapping
+// file, owner rules and the default owners of its cloud accounts
+func (r *Resolver) Load(ctx context.Context, org *model.Organization) (*service.OwnerResolver, error) {
+	var mappings []model.OwnerMapping
+	if err := r.db.WithContext(ctx).Where("organization_id = ?", org.ID).Order("position").Find(&mappings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load owner mappings: %w", err)
+	}
+	var accounts []model.CloudAccount
+	err := r.db.WithContext(ctx).
+		Select("account_id", "default_owner").
+		Where("organization_id = ? AND default_owner <> ''", org.ID).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account owners: %w", err)
+	}
+
+	resolver := &service.OwnerResolver{
+		Rules:         org.Settings().OwnerRules,
+		Mappings:      make([]service.OwnerMapping, len(mappings)),
+		AccountOwners: make(map[string]string, len(accounts)),
+	}
+	for i, m := range mappings {
+		resolver.Mappings[i] = service.OwnerMapping{Pattern: m.Pattern, Owner: m.Owner}
+	}
+	for _, a := range accounts {
+		resolver.AccountOwners[a.AccountID] = a.DefaultOwner
+	}
+	return resolver, nil
+}
+
+// Assign resolves the owner of the resources of an organization still in
+// the inventory and stores those that changed. It returns the number of
+// resources whose owner changed.
+func (r *Resolver) Assign(ctx context.Context, orgID uuid.UUID) (int, error) {
+	var org model.Organization
+	if err := r.db.WithContext(ctx).First(&org, "id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to load organization %s: %w", orgID, err)
+	}
+	resolver, err := r.Load(ctx, &org)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	var batch []model.Resource
+	err = r.db.WithContext(ctx).
+		Select("id", "resource_id", "account_id", "tags", "owner", "owner_source").
+		Where("organization_id = ? AND status NOT IN ?", orgID,
+			[]string{string(entity.ResourceStatusDeleted), string(entity.ResourceStatusRemoved)}).
+		FindInBatches(&batch, assignBatchSize, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				m := &batch[i]
+				owner, source := resolver.Resolve(database.ResourceEntity(m))
+				if owner == m.Owner && string(source) == m.OwnerSource {
+					continue
+				}
+				if err := r.store(ctx, m.ID, owner, source); err != nil {
+					return err
+				}
+				changed++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return changed, fmt.Errorf("failed to assign owners of org %s: %w", orgID, err)
+	}
+	return changed, nil
+}
+
+// store saves the owner of a resource, without touching its update date
+func (r *Resolver) store(ctx context.Context, id uuid.UUID, owner string, source entity.OwnerSource) error {
+	return r.db.WithContext(ctx).Model(&model.Resource{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"owner": owner, "owner_source": string(source)}).Error
+}
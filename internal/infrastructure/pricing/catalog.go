@@ -0,0 +1,141 @@
+// Package pricing maintains the catalog of instance type prices used to
+// estimate costs without calling the providers. A catalog is embedded in
+// the binary; the refresh task stores it, or the one served at
+// PRICING_CATALOG_URL, in Postgres.
+package pricing
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// embedded are the on-demand Linux prices, in USD per hour, the binary
+// ships with
+//
+//go:embed catalog.csv
+var embedded string
+
+// Catalog is the in-memory price catalog scans estimate costs from. It is
+// safe for concurrent use.
+type Catalog struct {
+	db *gorm.DB
+
+	mu     sync.RWMutex
+	prices map[priceKey]float64
+}
+
+var _ service.PriceCatalog = (*Catalog)(nil)
+
+type priceKey struct {
+	provider     entity.CloudProvider
+	region       string
+	instanceType string
+}
+
+// NewCatalog creates a Catalog holding the embedded prices until Load
+// reads the stored ones
+func NewCatalog(db *gorm.DB) *Catalog {
+	c := &Catalog{db: db}
+	prices, err := Parse(strings.NewReader(embedded))
+	if err != nil {
+		panic(fmt.Sprintf("pricing: invalid embedded catalog: %v", err))
+	}
+	c.set(prices)
+	return c
+}
+
+// Load replaces the prices with the ones stored by the last refresh. The
+// current prices are kept when none are stored or they cannot be read.
+func (c *Catalog) Load(ctx context.Context) error {
+	var prices []model.InstancePrice
+	if err := c.db.WithContext(ctx).Find(&prices).Error; err != nil {
+		return fmt.Errorf("failed to load instance prices: %w", err)
+	}
+	if len(prices) > 0 {
+		c.set(prices)
+	}
+	return nil
+}
+
+// HourlyPrice implements service.PriceCatalog
+func (c *Catalog) HourlyPrice(provider entity.CloudProvider, region, instanceType string) (float64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	price, ok := c.prices[priceKey{provider, strings.ToLower(region), strings.ToLower(instanceType)}]
+	return price, ok
+}
+
+// Len returns the number of prices of the catalog
+func (c *Catalog) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.prices)
+}
+
+func (c *Catalog) set(prices []model.InstancePrice) {
+	m := make(map[priceKey]float64, len(prices))
+	for _, p := range prices {
+		m[priceKey{entity.CloudProvider(p.Provider), strings.ToLower(p.Region), strings.ToLower(p.InstanceType)}] = p.HourlyUSD
+	}
+	c.mu.Lock()
+	c.prices = m
+	c.mu.Unlock()
+}
+
+// Parse reads a CSV catalog whose header is
+// provider,region,instance_type,hourly_usd
+func Parse(r io.Reader) ([]model.InstancePrice, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid catalog header: %w", err)
+	}
+	if strings.Join(header, ",") != "provider,region,instance_type,hourly_usd" {
+		return nil, errors.New("invalid catalog header, expected provider,region,instance_type,hourly_usd")
+	}
+
+	var prices []model.InstancePrice
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return prices, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		switch entity.CloudProvider(record[0]) {
+		case entity.CloudProviderAWS, entity.CloudProviderAzure, entity.CloudProviderGCP:
+		default:
+			return nil, fmt.Errorf("line %d: unknown provider %q", line, record[0])
+		}
+		if record[1] == "" || record[2] == "" {
+			return nil, fmt.Errorf("line %d: region and instance_type are required", line)
+		}
+		price, err := strconv.ParseFloat(record[3], 64)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("line %d: invalid hourly_usd %q", line, record[3])
+		}
+		prices = append(prices, model.InstancePrice{
+			Provider:     record[0],
+			Region:       record[1],
+			InstanceType: record[2],
+			HourlyUSD:    price,
+		})
+	}
+}
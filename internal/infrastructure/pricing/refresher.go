@@ -0,0 +1,87 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Refresher stores the latest price catalog in Postgres
+type Refresher struct {
+	db   *gorm.DB
+	cfg  config.PricingConfig
+	http *http.Client
+}
+
+// NewRefresher creates a new Refresher
+func NewRefresher(db *gorm.DB, cfg config.PricingConfig) *Refresher {
+	return &Refresher{db: db, cfg: cfg, http: &http.Client{Timeout: time.Minute}}
+}
+
+// Refresh replaces the stored prices with the catalog at the configured
+// URL, or the embedded one, and returns the number of prices stored. When
+// the catalog cannot be downloaded the stored prices are left untouched, so
+// scans keep estimating from the last refresh.
+func (r *Refresher) Refresh(ctx context.Context, now time.Time) (int, error) {
+	prices, err := r.fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("price catalog is empty")
+	}
+	for i := range prices {
+		prices[i].UpdatedAt = now
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "region"}, {Name: "instance_type"}},
+			DoUpdates: clause.AssignmentColumns([]string{"hourly_usd", "updated_at"}),
+		}).CreateInBatches(prices, 500).Error
+		if err != nil {
+			return fmt.Errorf("failed to store instance prices: %w", err)
+		}
+		// Instance types no longer in the catalog
+		if err := tx.Where("updated_at < ?", now).Delete(&model.InstancePrice{}).Error; err != nil {
+			return fmt.Errorf("failed to delete outdated instance prices: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(prices), nil
+}
+
+func (r *Refresher) fetch(ctx context.Context) ([]model.InstancePrice, error) {
+	if r.cfg.CatalogURL == "" {
+		return Parse(strings.NewReader(embedded))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.CatalogURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download price catalog: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download price catalog: %s returned %d", r.cfg.CatalogURL, resp.StatusCode)
+	}
+	prices, err := Parse(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, fmt.Errorf("invalid price catalog: %w", err)
+	}
+	return prices, nil
+}
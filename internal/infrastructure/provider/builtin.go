@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/aws"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/azure"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/gcp"
+)
+
+// The public clouds are described here; their scanners and cleaners
+// register through the same Provider once implemented in their own package.
+func init() {
+	Register(Provider{
+		Name:        entity.CloudProviderAWS,
+		DisplayName: "Amazon Web Services",
+		RegionLabel: "region",
+		ResourceTypes: []entity.ResourceType{
+			entity.ResourceTypeEC2Instance,
+			entity.ResourceTypeEBSVolume,
+			entity.ResourceTypeEBSSnapshot,
+			entity.ResourceTypeElasticIP,
+			entity.ResourceTypeLoadBalancer,
+			entity.ResourceTypeS3Bucket,
+			entity.ResourceTypeRDSInstance,
+			entity.ResourceTypeVPCPeering,
+			entity.ResourceTypeVPNConnection,
+			entity.ResourceTypeTransitGatewayAttachment,
+			entity.ResourceTypeNetworkInterface,
+			entity.ResourceTypeNATGateway,
+		},
+		Credentials: []CredentialField{
+			{Name: "access_key_id", Description: "Access key ID of an IAM user, unless role_arn is set"},
+			{Name: "secret_access_key", Description: "Secret of the access key", Secret: true},
+			{Name: "role_arn", Description: "Role assumed with the CloudSweep credentials instead of an access key"},
+			{Name: "external_id", Description: "External ID required by the role", Secret: true},
+			{Name: "inventory", Description: "Lists resources from AWS Config (config) or Resource Explorer (resource_explorer) instead of the describe calls of each service"},
+			{Name: "config_aggregator", Description: "AWS Config aggregator the inventory and incremental scans read, the recorder of each region otherwise"},
+			{Name: "config_aggregator_region", Description: "Region of the aggregator, the CloudSweep region by default"},
+		},
+		NewCustomDetector: func(t *entity.CustomResourceType, credentials []byte, cfg *config.Config) (service.ResourceDetector, error) {
+			return aws.NewCustomDetector(aws.NewClient(cfg.AWS), t, credentials)
+		},
+		NewPermissionChecker: func(credentials []byte, cfg *config.Config) (service.PermissionChecker, error) {
+			return aws.NewPolicySimulator(aws.NewClient(cfg.AWS), credentials), nil
+		},
+		NewChangeFeed: func(credentials []byte, cfg *config.Config) (service.ChangeFeed, error) {
+			return aws.NewConfigChangeFeed(aws.NewClient(cfg.AWS), credentials)
+		},
+		NewDNSZones: func(credentials []byte, cfg *config.Config) (service.DNSZoneReader, error) {
+			return aws.NewRoute53Zones(aws.NewClient(cfg.AWS), credentials), nil
+		},
+		Integration: &Integration{
+			DisplayName: "AWS Organizations",
+			Settings: []CredentialField{
+				{Name: "management_role_arn", Description: "Role of the management account allowed to list the accounts of the organization", Required: true},
+				{Name: "member_role_name", Description: "Role assumed in every member account, " + aws.DefaultMemberRoleName + " by default"},
+				{Name: "external_id", Description: "External ID required by the management and member roles", Secret: true},
+			},
+			NewDiscoverer: func(settings map[string]string, cfg *config.Config) (service.AccountDiscoverer, error) {
+				return aws.NewOrganizationDiscoverer(aws.NewClient(cfg.AWS), settings["management_role_arn"], settings["member_role_name"], settings["external_id"])
+			},
+		},
+		PricingSources: []PricingSource{
+			{
+				Name:        "cost_explorer",
+				DisplayName: "AWS Cost Explorer",
+				New: func(accountID string, credentials []byte, settings map[string]string, cfg *config.Config) (service.BillingSource, error) {
+					return aws.NewCostExplorer(aws.NewClient(cfg.AWS), accountID, credentials), nil
+				},
+			},
+			{
+				Name:        "cur",
+				DisplayName: "AWS Cost and Usage Report",
+				Settings: []CredentialField{
+					{Name: "bucket", Description: "S3 bucket the report is delivered to", Required: true},
+					{Name: "region", Description: "Region of the bucket, us-east-1 by default"},
+					{Name: "prefix", Description: "S3 path prefix of the report"},
+					{Name: "report_name", Description: "Name of the report, generated with resource IDs in CSV", Required: true},
+				},
+				New: func(accountID string, credentials []byte, settings map[string]string, cfg *config.Config) (service.BillingSource, error) {
+					return aws.NewCostAndUsageReport(aws.NewClient(cfg.AWS), accountID, credentials,
+						settings["bucket"], settings["region"], settings["prefix"], settings["report_name"])
+				},
+			},
+		},
+	})
+	Register(Provider{
+		Name:        entity.CloudProviderAzure,
+		DisplayName: "Microsoft Azure",
+		RegionLabel: "region",
+		ResourceTypes: []entity.ResourceType{
+			entity.ResourceTypeAzureVM,
+			entity.ResourceTypeAzureDisk,
+			entity.ResourceTypeAzureBlobContainer,
+			entity.ResourceTypeAzureSQL,
+			entity.ResourceTypeAzureSnapshot,
+			entity.ResourceTypeAzureLoadBalancer,
+			entity.ResourceTypeAzureNetworkInterface,
+			entity.ResourceTypeAzureNATGateway,
+			entity.ResourceTypeAzurePublicIP,
+		},
+		Credentials: []CredentialField{
+			{Name: "tenant_id", Description: "Directory (tenant) ID", Required: true},
+			{Name: "client_id", Description: "Application (client) ID of a service principal", Required: true},
+			{Name: "client_secret", Description: "Client secret of the service principal", Required: true, Secret: true},
+			{Name: "subscription_id", Description: "Subscription to scan", Required: true},
+		},
+		NewPermissionChecker: func(credentials []byte, cfg *config.Config) (service.PermissionChecker, error) {
+			return azure.NewRoleAssignmentCheck(azure.NewClient(), credentials)
+		},
+		NewChangeFeed: func(credentials []byte, cfg *config.Config) (service.ChangeFeed, error) {
+			return azure.NewResourceGraphChangeFeed(azure.NewClient(), credentials)
+		},
+		Integration: &Integration{
+			DisplayName: "Azure management group",
+			Settings: []CredentialField{
+				{Name: "management_group_id", Description: "Management group whose subscriptions are discovered, child groups included", Required: true},
+				{Name: "tenant_id", Description: "Directory (tenant) ID", Required: true},
+				{Name: "client_id", Description: "Application (client) ID of a service principal with Reader on the management group", Required: true},
+				{Name: "client_secret", Description: "Client secret of the service principal", Required: true, Secret: true},
+			},
+			NewDiscoverer: func(settings map[string]string, cfg *config.Config) (service.AccountDiscoverer, error) {
+				return azure.NewManagementGroupDiscoverer(azure.NewClient(), settings["management_group_id"], settings["tenant_id"], settings["client_id"], settings["client_secret"])
+			},
+		},
+		PricingSources: []PricingSource{
+			{
+				Name:        "cost_management",
+				DisplayName: "Azure Cost Management",
+				New: func(accountID string, credentials []byte, settings map[string]string, cfg *config.Config) (service.BillingSource, error) {
+					return azure.NewCostManagement(azure.NewClient(), credentials)
+				},
+			},
+		},
+	})
+	Register(Provider{
+		Name:        entity.CloudProviderGCP,
+		DisplayName: "Google Cloud",
+		RegionLabel: "region",
+		ResourceTypes: []entity.ResourceType{
+			entity.ResourceTypeGCEInstance,
+			entity.ResourceTypeGCEDisk,
+			entity.ResourceTypeGCSBucket,
+			entity.ResourceTypeCloudSQL,
+			entity.ResourceTypeGCPLoadBalancer,
+			entity.ResourceTypeCloudNAT,
+			entity.ResourceTypeGCPStaticIP,
+		},
+		Credentials: []CredentialField{
+			{Name: "project_id", Description: "Project to scan", Required: true},
+			{Name: "service_account_key", Description: "JSON key of a service account", Required: true, Secret: true},
+		},
+		NewPermissionChecker: func(credentials []byte, cfg *config.Config) (service.PermissionChecker, error) {
+			return gcp.NewIAMPermissionTest(gcp.NewClient(), credentials)
+		},
+		NewChangeFeed: func(credentials []byte, cfg *config.Config) (service.ChangeFeed, error) {
+			return gcp.NewAssetChangeFeed(gcp.NewClient(), credentials)
+		},
+		NewDNSZones: func(credentials []byte, cfg *config.Config) (service.DNSZoneReader, error) {
+			return gcp.NewCloudDNSZones(gcp.NewClient(), credentials)
+		},
+		Integration: &Integration{
+			DisplayName: "Google Cloud folder or organization",
+			Settings: []CredentialField{
+				{Name: "parent", Description: "folders/<id> or organizations/<id> whose projects are discovered, sub-folders included", Required: true},
+				{Name: "service_account_key", Description: "JSON key of a service account with Browser and Viewer on the parent", Required: true, Secret: true},
+			},
+			NewDiscoverer: func(settings map[string]string, cfg *config.Config) (service.AccountDiscoverer, error) {
+				return gcp.NewFolderDiscoverer(gcp.NewClient(), settings["parent"], settings["service_account_key"])
+			},
+		},
+		PricingSources: []PricingSource{
+			{
+				Name:        "billing_export",
+				DisplayName: "Cloud Billing export to BigQuery",
+				Settings: []CredentialField{
+					{Name: "table", Description: "Detailed usage cost table, project.dataset.gcp_billing_export_resource_v1_<account>", Required: true},
+				},
+				New: func(accountID string, credentials []byte, settings map[string]string, cfg *config.Config) (service.BillingSource, error) {
+					return gcp.NewBillingExport(gcp.NewClient(), credentials, settings["table"])
+				},
+			},
+		},
+	})
+	// AWS accounts with an inventory mode are listed from AWS Config or
+	// Resource Explorer; the others have no detector
+	p, _ := Lookup(string(entity.CloudProviderAWS))
+	for _, t := range p.ResourceTypes {
+		t := t
+		RegisterDetector(Detector{
+			Provider:     entity.CloudProviderAWS,
+			ResourceType: t,
+			Heuristics:   aws.InventoryHeuristics(t),
+			New: func(credentials []byte, cfg *config.Config) (service.ResourceDetector, error) {
+				d, err := aws.NewInventoryDetector(aws.NewClient(cfg.AWS), t, credentials)
+				if d == nil || err != nil {
+					return nil, err
+				}
+				return d, nil
+			},
+		})
+	}
+}
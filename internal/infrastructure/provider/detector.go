@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// Detector describes the detection of a resource type of a provider.
+// Providers without a NewScanner are scanned with the detectors of their
+// resource types.
+type Detector struct {
+	Provider     entity.CloudProvider
+	ResourceType entity.ResourceType
+	// Heuristics tell users when resources of the type are reported unused
+	Heuristics []string
+
+	// New creates the detector of a cloud account. It returns nil when the
+	// account cannot be scanned for the type, e.g. lacking a setting of its
+	// credentials, and the type is then skipped.
+	New func(credentials []byte, cfg *config.Config) (service.ResourceDetector, error)
+}
+
+var detectors = map[entity.CloudProvider]map[entity.ResourceType]Detector{}
+
+// RegisterDetector adds the detector of a resource type to the registry. It
+// panics when the provider is not registered, does not declare the
+// resource type, or already has a detector for it.
+func RegisterDetector(d Detector) {
+	mu.Lock()
+	defer mu.Unlock()
+	p, ok := providers[d.Provider]
+	if !ok {
+		panic(fmt.Sprintf("provider: RegisterDetector for unregistered provider %s", d.Provider))
+	}
+	if !slices.Contains(p.ResourceTypes, d.ResourceType) {
+		panic(fmt.Sprintf("provider: %s does not declare resource type %s", d.Provider, d.ResourceType))
+	}
+	if _, ok := detectors[d.Provider][d.ResourceType]; ok {
+		panic(fmt.Sprintf("provider: RegisterDetector called twice for %s", d.ResourceType))
+	}
+	if detectors[d.Provider] == nil {
+		detectors[d.Provider] = map[entity.ResourceType]Detector{}
+	}
+	detectors[d.Provider][d.ResourceType] = d
+}
+
+// LookupDetector returns the detector registered for a resource type
+func LookupDetector(t entity.ResourceType) (Detector, bool) {
+	provider, ok := t.Provider()
+	if !ok {
+		return Detector{}, false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	d, ok := detectors[provider][t]
+	return d, ok
+}
+
+// Detectors returns the detectors of a provider, in the order of its
+// resource types
+func (p Provider) Detectors() []Detector {
+	mu.RLock()
+	defer mu.RUnlock()
+	var out []Detector
+	for _, t := range p.ResourceTypes {
+		if d, ok := detectors[p.Name][t]; ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Scannable reports whether the provider can be scanned, by its own scanner
+// or by detectors
+func (p Provider) Scannable() bool {
+	return p.NewScanner != nil || len(p.Detectors()) > 0
+}
+
+// detectorScanner scans a provider with the detectors of its resource types
+type detectorScanner struct {
+	provider  entity.CloudProvider
+	types     []entity.ResourceType // in the order of the provider, then custom types
+	detectors map[entity.ResourceType]service.ResourceDetector
+	feed      service.ChangeFeed    // nil when the provider does not track changes
+	dns       service.DNSZoneReader // nil when the provider has no DNS zones
+
+	dnsOnce    sync.Once
+	dnsRecords []service.DNSRecord
+	dnsErr     error
+}
+
+// newDetectorScanner creates the detectors of the provider for an account,
+// along with those of the custom types of the provider
+func newDetectorScanner(p Provider, credentials []byte, cfg *config.Config, customTypes []*entity.CustomResourceType) (*detectorScanner, error) {
+	s := &detectorScanner{provider: p.Name, detectors: map[entity.ResourceType]service.ResourceDetector{}}
+	for _, d := range p.Detectors() {
+		detector, err := d.New(credentials, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if detector == nil {
+			continue
+		}
+		s.types = append(s.types, d.ResourceType)
+		s.detectors[d.ResourceType] = detector
+	}
+	for _, t := range customTypes {
+		if t.Provider != p.Name {
+			continue
+		}
+		if _, ok := s.detectors[t.Name]; ok {
+			return nil, fmt.Errorf("custom resource type %s is already scanned", t.Name)
+		}
+		detector, err := p.NewCustomDetector(t, credentials, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("custom resource type %s: %w", t.Name, err)
+		}
+		s.types = append(s.types, t.Name)
+		s.detectors[t.Name] = detector
+	}
+	if len(s.types) == 0 {
+		return nil, fmt.Errorf("provider %s cannot scan this account: no resource type has a detector for its credentials", p.Name)
+	}
+	if p.NewChangeFeed != nil {
+		feed, err := p.NewChangeFeed(credentials, cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.feed = feed
+	}
+	if p.NewDNSZones != nil {
+		zones, err := p.NewDNSZones(credentials, cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.dns = zones
+	}
+	return s, nil
+}
+
+var (
+	_ service.CloudScanner       = (*detectorScanner)(nil)
+	_ service.ResourceStreamer   = (*detectorScanner)(nil)
+	_ service.IncrementalScanner = (*detectorScanner)(nil)
+)
+
+// ScanRegion implements service.CloudScanner. An empty resourceTypes scans
+// every type with a detector; other types are skipped.
+func (s *detectorScanner) ScanRegion(ctx context.Context, region string, resourceTypes []entity.ResourceType) ([]*entity.Resource, error) {
+	var resources []*entity.Resource
+	err := s.scan(ctx, region, resourceTypes, func(found []*entity.Resource) error {
+		resources = append(resources, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// StreamRegion implements service.ResourceStreamer, emitting the resources
+// of each type once its detector scanned them
+func (s *detectorScanner) StreamRegion(ctx context.Context, region string, resourceTypes []entity.ResourceType, emit chan<- *entity.Resource) error {
+	return s.scan(ctx, region, resourceTypes, func(found []*entity.Resource) error {
+		return service.Emit(ctx, emit, found)
+	})
+}
+
+// ScanChanges implements service.IncrementalScanner. Detectors list every
+// resource of their type, so the region is only scanned when the feed
+// reports changes, keeping the changed resources.
+func (s *detectorScanner) ScanChanges(ctx context.Context, region string, resourceTypes []entity.ResourceType, since time.Time) ([]*entity.Resource, []string, error) {
+	if s.feed == nil {
+		return nil, nil, service.ErrChangesUnavailable
+	}
+	changes, err := s.feed.Changes(ctx, region, since)
+	if err != nil {
+		if errors.Is(err, service.ErrChangesUnavailable) || ctx.Err() != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%w: %v", service.ErrChangesUnavailable, err)
+	}
+	if len(changes.Changed) == 0 {
+		return nil, changes.Deleted, nil
+	}
+
+	ids := service.NewResourceIDs(changes.Changed)
+	var changed []*entity.Resource
+	err = s.scan(ctx, region, resourceTypes, func(found []*entity.Resource) error {
+		for _, r := range found {
+			if ids.Has(r.ResourceID) {
+				changed = append(changed, r)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return changed, changes.Deleted, nil
+}
+
+func (s *detectorScanner) scan(ctx context.Context, region string, resourceTypes []entity.ResourceType, found func([]*entity.Resource) error) error {
+	for _, t := range s.types {
+		if len(resourceTypes) > 0 && !slices.Contains(resourceTypes, t) {
+			continue
+		}
+		resources, err := s.detectors[t].Scan(ctx, region)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", t, err)
+		}
+		s.annotateDNS(ctx, resources)
+		if err := found(resources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotateDNS records the DNS records pointing at resources, reading the
+// zones of the account once per scanner. When they cannot be read, the
+// resources are left without records, and cleanups search the zones again
+// or refuse them.
+func (s *detectorScanner) annotateDNS(ctx context.Context, resources []*entity.Resource) {
+	if s.dns == nil || !slices.ContainsFunc(resources, service.NeedsDNSCheck) {
+		return
+	}
+	s.dnsOnce.Do(func() {
+		s.dnsRecords, s.dnsErr = s.dns.Records(ctx)
+		if s.dnsErr != nil {
+			log.Printf("Failed to read the DNS zones of the %s account: %v", s.provider, s.dnsErr)
+		}
+	})
+	if s.dnsErr == nil {
+		service.AnnotateDNSReferences(resources, s.dnsRecords)
+	}
+}
+
+// DetectUnused implements service.CloudScanner, passing each detector the
+// resources of its type
+func (s *detectorScanner) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	byType := map[entity.ResourceType][]*entity.Resource{}
+	for _, r := range resources {
+		byType[r.Type] = append(byType[r.Type], r)
+	}
+	for _, t := range s.types {
+		if len(byType[t]) == 0 {
+			continue
+		}
+		if err := s.detectors[t].DetectUnused(ctx, byType[t]); err != nil {
+			return fmt.Errorf("failed to detect unused %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// EstimateCost implements service.CloudScanner
+func (s *detectorScanner) EstimateCost(ctx context.Context, r *entity.Resource) (entity.Cost, error) {
+	detector, ok := s.detectors[r.Type]
+	if !ok {
+		return entity.Cost{}, fmt.Errorf("unsupported resource type %s", r.Type)
+	}
+	return detector.EstimateCost(ctx, r)
+}
+
+// Provider implements service.CloudScanner
+func (s *detectorScanner) Provider() entity.CloudProvider {
+	return s.provider
+}
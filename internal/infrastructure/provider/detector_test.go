@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// addressDetector finds an Elastic IP per address
+type addressDetector struct {
+	addresses []string
+}
+
+func (d addressDetector) Scan(ctx context.Context, region string) ([]*entity.Resource, error) {
+	var resources []*entity.Resource
+	for _, ip := range d.addresses {
+		r := entity.NewResource(uuid.Nil, entity.CloudProviderAWS, entity.ResourceTypeElasticIP, "eipalloc-"+ip, region, "")
+		r.Metadata[service.IPMetadataAddress] = ip
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+func (addressDetector) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	return nil
+}
+
+func (addressDetector) EstimateCost(ctx context.Context, r *entity.Resource) (entity.Cost, error) {
+	return entity.Cost{}, nil
+}
+
+// staticZones returns fixed records, counting the reads
+type staticZones struct {
+	records []service.DNSRecord
+	err     error
+	reads   int
+}
+
+func (z *staticZones) Records(ctx context.Context) ([]service.DNSRecord, error) {
+	z.reads++
+	return z.records, z.err
+}
+
+func TestDetectorScannerDNSReferences(t *testing.T) {
+	zones := &staticZones{records: []service.DNSRecord{
+		{Zone: "example.com.", Name: "api.example.com.", Type: "A", Values: []string{"203.0.113.10"}},
+	}}
+	s := &detectorScanner{
+		provider:  entity.CloudProviderAWS,
+		types:     []entity.ResourceType{entity.ResourceTypeElasticIP},
+		detectors: map[entity.ResourceType]service.ResourceDetector{entity.ResourceTypeElasticIP: addressDetector{addresses: []string{"203.0.113.10", "203.0.113.99"}}},
+		dns:       zones,
+	}
+
+	for _, region := range []string{"eu-west-3", "us-east-1"} {
+		resources, err := s.ScanRegion(context.Background(), region, nil)
+		if err != nil {
+			t.Fatalf("ScanRegion(%s) error = %v", region, err)
+		}
+		want := map[string][]string{
+			"eipalloc-203.0.113.10": {"api.example.com A"},
+			"eipalloc-203.0.113.99": {},
+		}
+		for _, r := range resources {
+			got, ok := service.RecordedDNSReferences(r)
+			if !ok {
+				t.Errorf("%s: DNS records not recorded", r.ResourceID)
+				continue
+			}
+			if !reflect.DeepEqual(append([]string{}, got...), want[r.ResourceID]) {
+				t.Errorf("%s: DNS records = %v, want %v", r.ResourceID, got, want[r.ResourceID])
+			}
+		}
+	}
+	if zones.reads != 1 {
+		t.Errorf("zones read %d times, want once per scanner", zones.reads)
+	}
+}
+
+func TestDetectorScannerDNSZonesUnreadable(t *testing.T) {
+	s := &detectorScanner{
+		provider:  entity.CloudProviderAWS,
+		types:     []entity.ResourceType{entity.ResourceTypeElasticIP},
+		detectors: map[entity.ResourceType]service.ResourceDetector{entity.ResourceTypeElasticIP: addressDetector{addresses: []string{"203.0.113.10"}}},
+		dns:       &staticZones{err: errors.New("AccessDenied")},
+	}
+
+	resources, err := s.ScanRegion(context.Background(), "eu-west-3", nil)
+	if err != nil {
+		t.Fatalf("ScanRegion() error = %v", err)
+	}
+	if _, ok := service.RecordedDNSReferences(resources[0]); ok {
+		t.Error("DNS records recorded although the zones could not be read")
+	}
+}
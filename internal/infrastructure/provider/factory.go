@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// ScannerFactory creates the scanners of registered providers
+type ScannerFactory struct {
+	cfg *config.Config
+}
+
+// NewScannerFactory creates a ScannerFactory passing cfg to the providers
+func NewScannerFactory(cfg *config.Config) *ScannerFactory {
+	return &ScannerFactory{cfg: cfg}
+}
+
+var (
+	_ service.CloudScannerFactory  = (*ScannerFactory)(nil)
+	_ service.CustomScannerFactory = (*ScannerFactory)(nil)
+)
+
+// Create implements service.CloudScannerFactory
+func (f *ScannerFactory) Create(name entity.CloudProvider, credentials []byte) (service.CloudScanner, error) {
+	return f.CreateWithCustomTypes(name, credentials, nil)
+}
+
+// CreateWithCustomTypes implements service.CustomScannerFactory
+func (f *ScannerFactory) CreateWithCustomTypes(name entity.CloudProvider, credentials []byte, types []*entity.CustomResourceType) (service.CloudScanner, error) {
+	p, ok := Lookup(string(name))
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
+	if len(types) > 0 && p.NewCustomDetector == nil {
+		return nil, fmt.Errorf("provider %s does not support custom resource types", name)
+	}
+	if p.NewScanner != nil {
+		return p.NewScanner(credentials, f.cfg)
+	}
+	if len(p.Detectors()) == 0 && len(types) == 0 {
+		return nil, fmt.Errorf("provider %s cannot be scanned", name)
+	}
+	return newDetectorScanner(p, credentials, f.cfg, types)
+}
+
+// CleanerFactory creates the cleaners of registered providers
+type CleanerFactory struct {
+	cfg *config.Config
+}
+
+// NewCleanerFactory creates a CleanerFactory passing cfg to the providers
+func NewCleanerFactory(cfg *config.Config) *CleanerFactory {
+	return &CleanerFactory{cfg: cfg}
+}
+
+var _ service.ResourceCleanerFactory = (*CleanerFactory)(nil)
+
+// Create implements service.ResourceCleanerFactory
+func (f *CleanerFactory) Create(name entity.CloudProvider, credentials []byte) (service.ResourceCleaner, error) {
+	p, ok := Lookup(string(name))
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
+	if p.NewCleaner == nil {
+		return nil, fmt.Errorf("provider %s cannot be cleaned up", name)
+	}
+	return p.NewCleaner(credentials, f.cfg)
+}
@@ -0,0 +1,156 @@
+// Package provider is the registry of the cloud providers CloudSweep
+// supports. Provider implementations register themselves from an init
+// function, so that supporting a new provider only takes importing its
+// package from the commands.
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// Provider describes a cloud provider and creates its clients
+type Provider struct {
+	Name        entity.CloudProvider
+	DisplayName string
+	// RegionLabel names what the regions of scans are for the provider,
+	// e.g. "namespace" for Kubernetes
+	RegionLabel   string
+	ResourceTypes []entity.ResourceType
+	// Credentials are the fields of the credentials of a cloud account
+	Credentials []CredentialField
+
+	// NewScanner creates a scanner. When nil, the provider is scanned with
+	// the detectors registered for its resource types, if any.
+	NewScanner func(credentials []byte, cfg *config.Config) (service.CloudScanner, error)
+	// NewCleaner creates a cleaner, nil when the provider cannot be cleaned
+	// up
+	NewCleaner func(credentials []byte, cfg *config.Config) (service.ResourceCleaner, error)
+	// NewCustomDetector creates the detector of a resource type defined by
+	// an organization, nil when the provider does not support custom types.
+	// Only providers scanned with detectors can support them.
+	NewCustomDetector func(t *entity.CustomResourceType, credentials []byte, cfg *config.Config) (service.ResourceDetector, error)
+	// NewPermissionChecker creates the checker of the permissions granted to
+	// the credentials of an account, nil when the provider cannot check them
+	NewPermissionChecker func(credentials []byte, cfg *config.Config) (service.PermissionChecker, error)
+	// NewChangeFeed creates the feed of the resource changes of an account,
+	// nil when the provider does not track them. Scanners made of detectors
+	// use it to scan only the resources changed since an earlier scan.
+	NewChangeFeed func(credentials []byte, cfg *config.Config) (service.ChangeFeed, error)
+	// NewDNSZones creates the reader of the DNS zones of an account, nil
+	// when the provider has none. Scanners made of detectors use it to
+	// record the DNS records pointing at the resources they find.
+	NewDNSZones func(credentials []byte, cfg *config.Config) (service.DNSZoneReader, error)
+
+	// Integration, when set, lets an organization connect all its accounts
+	// at once instead of adding them one by one
+	Integration *Integration
+	// PricingSources are the billing data accounts can take their costs
+	// from instead of the estimates of the scanner
+	PricingSources []PricingSource
+}
+
+// Integration describes the organization-level integration of a provider,
+// e.g. AWS Organizations, whose accounts are discovered and kept in sync
+type Integration struct {
+	DisplayName string
+	// Settings are the fields of the integration, validated like credentials
+	Settings []CredentialField
+
+	// NewDiscoverer creates the discoverer of the accounts of an integration
+	NewDiscoverer func(settings map[string]string, cfg *config.Config) (service.AccountDiscoverer, error)
+}
+
+// PricingSource describes billing data of a provider that resource costs
+// are reconciled with
+type PricingSource struct {
+	Name        string `json:"name" example:"cost_explorer"`
+	DisplayName string `json:"display_name" example:"AWS Cost Explorer"`
+	// Settings are the fields configured per account, validated like
+	// credentials
+	Settings []CredentialField `json:"settings"`
+
+	// New creates the billing source of an account from its provider ID
+	// and credentials
+	New func(accountID string, credentials []byte, settings map[string]string, cfg *config.Config) (service.BillingSource, error) `json:"-"`
+}
+
+// PricingSource returns the pricing source of a provider by name
+func (p Provider) PricingSource(name string) (PricingSource, bool) {
+	for _, s := range p.PricingSources {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return PricingSource{}, false
+}
+
+// CredentialField is a field of the credentials of a provider
+type CredentialField struct {
+	Name        string `json:"name" example:"secret_access_key"`
+	Description string `json:"description" example:"Secret of the access key"`
+	Required    bool   `json:"required" example:"true"`
+	// Secret fields are never returned once saved
+	Secret bool `json:"secret" example:"true"`
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[entity.CloudProvider]Provider{}
+)
+
+// Register adds a provider to the registry, along with its resource types.
+// It panics when the name is empty or already registered, or when the
+// provider has both a scanner and custom detectors.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	if p.Name == "" {
+		panic("provider: Register with an empty name")
+	}
+	if _, ok := providers[p.Name]; ok {
+		panic(fmt.Sprintf("provider: Register called twice for %s", p.Name))
+	}
+	if p.NewScanner != nil && p.NewCustomDetector != nil {
+		panic(fmt.Sprintf("provider: %s has both a scanner and custom detectors", p.Name))
+	}
+	for _, t := range p.ResourceTypes {
+		entity.RegisterResourceType(t, p.Name)
+	}
+	providers[p.Name] = p
+}
+
+// Lookup returns a registered provider
+func Lookup(name string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[entity.CloudProvider(name)]
+	return p, ok
+}
+
+// All returns the registered providers, sorted by name
+func All() []Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]Provider, 0, len(providers))
+	for _, p := range providers {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Names returns the names of the registered providers, sorted
+func Names() []string {
+	all := All()
+	names := make([]string, len(all))
+	for i, p := range all {
+		names[i] = string(p.Name)
+	}
+	return names
+}
@@ -0,0 +1,191 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// SendAlertPayload represents the payload for an alert sent to one channel
+// of an organization
+type SendAlertPayload struct {
+	OrganizationID string             `json:"organization_id"`
+	Channel        string             `json:"channel"`
+	Alert          notification.Alert `json:"alert"`
+}
+
+// EnqueueScanAlerts queues the alerts of a finished scan to the channels
+// its organization routes them to: the scan summary, and a cost anomaly
+// when its monthly waste rose well above that of the previous scan of the
+// same scope
+func EnqueueScanAlerts(ctx context.Context, db *gorm.DB, client *asynq.Client, scanID string) error {
+	var scan model.Scan
+	if err := db.WithContext(ctx).Preload("Organization").First(&scan, "id = ?", scanID).Error; err != nil {
+		return fmt.Errorf("failed to load scan %s: %w", scanID, err)
+	}
+	settings := scan.Organization.Settings().Notifications
+	if len(settings.Routes) == 0 {
+		return nil
+	}
+
+	errs := []error{enqueueAlert(ctx, client, scan.OrganizationID, settings, scanAlert(&scan))}
+
+	status := entity.ScanStatus(scan.Status)
+	if len(settings.ChannelsFor(entity.NotificationEventCostAnomaly)) > 0 &&
+		(status == entity.ScanStatusCompleted || status == entity.ScanStatusPartial) {
+		var previous model.Scan
+		err := db.WithContext(ctx).
+			Where("organization_id = ? AND fingerprint = ? AND id <> ? AND status IN ? AND created_at < ?",
+				scan.OrganizationID, scan.Fingerprint, scan.ID,
+				[]string{string(entity.ScanStatusCompleted), string(entity.ScanStatusPartial)}, scan.CreatedAt).
+			Order("created_at DESC").
+			First(&previous).Error
+		switch {
+		case err == nil:
+			if settings.IsCostAnomaly(previous.EstimatedSavings, scan.EstimatedSavings) {
+				errs = append(errs, enqueueAlert(ctx, client, scan.OrganizationID, settings, costAnomalyAlert(&scan, &previous)))
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			errs = append(errs, fmt.Errorf("failed to load previous scan: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enqueueAlert queues one task per channel the alert's event is routed to.
+// A task ID per channel and dedup key prevents duplicates.
+func enqueueAlert(ctx context.Context, client *asynq.Client, orgID uuid.UUID, settings entity.NotificationSettings, alert notification.Alert) error {
+	var errs []error
+	for _, channel := range settings.ChannelsFor(alert.Event) {
+		data, err := json.Marshal(SendAlertPayload{OrganizationID: orgID.String(), Channel: channel, Alert: alert})
+		if err != nil {
+			return err
+		}
+		taskID := TaskTypeSendAlert + ":" + channel + ":" + alert.DedupKey
+		_, err = client.EnqueueContext(ctx, NewTask(TaskTypeSendAlert, data, asynq.TaskID(taskID)))
+		if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			errs = append(errs, fmt.Errorf("failed to queue %s alert to %s: %w", alert.Event, channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleSendAlert handles alert tasks. The target of the channel is read
+// when sending, so that alerts to a channel removed since are dropped, as
+// are alerts identical to one the channel got within the window of
+// suppressor.
+func HandleSendAlert(db *gorm.DB, channels map[string]notification.Channel, suppressor *notification.Suppressor) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload SendAlertPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		channel, ok := channels[payload.Channel]
+		if !ok {
+			return fmt.Errorf("unknown notification channel %q: %w", payload.Channel, asynq.SkipRetry)
+		}
+
+		var org model.Organization
+		if err := db.WithContext(ctx).First(&org, "id = ?", payload.OrganizationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		target := org.Settings().Notifications.Target(payload.Channel)
+		if target == "" {
+			return nil
+		}
+
+		scope := payload.OrganizationID + ":" + payload.Channel
+		fingerprint := payload.Alert.Fingerprint()
+		first, err := suppressor.Claim(ctx, scope, fingerprint)
+		if err != nil {
+			log.Printf("Failed to check for duplicate alerts: %v", err)
+		}
+		if !first {
+			log.Printf("Dropping duplicate %s alert to %s for org %s", payload.Alert.Event, payload.Channel, payload.OrganizationID)
+			return nil
+		}
+
+		log.Printf("Sending %s alert to %s for org %s", payload.Alert.Event, payload.Channel, payload.OrganizationID)
+
+		if err := channel.Send(ctx, target, payload.Alert); err != nil {
+			suppressor.Release(ctx, scope, fingerprint)
+			if !notification.Retryable(err) {
+				return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// scanAlert summarizes a finished scan
+func scanAlert(s *model.Scan) notification.Alert {
+	severity := notification.SeverityInfo
+	switch entity.ScanStatus(s.Status) {
+	case entity.ScanStatusPartial:
+		severity = notification.SeverityWarning
+	case entity.ScanStatusFailed:
+		severity = notification.SeverityError
+	}
+	alert := notification.Alert{
+		Event: entity.NotificationEventScanFinished,
+		Title: fmt.Sprintf("%s scan %s: %d unused of %d resources, $%.2f/month to save",
+			strings.ToUpper(s.Provider), s.Status, s.UnusedFound, s.ResourcesFound, s.EstimatedSavings),
+		Text:     s.ErrorMessage,
+		Severity: severity,
+		Source:   scanSource(s),
+		DedupKey: "scan:" + s.ID.String(),
+		Fields: []notification.Field{
+			{Name: "Scan", Value: s.ID.String()},
+			{Name: "New", Value: strconv.Itoa(s.ResourcesNew)},
+			{Name: "Changed", Value: strconv.Itoa(s.ResourcesChanged)},
+			{Name: "Removed", Value: strconv.Itoa(s.ResourcesRemoved)},
+		},
+	}
+	for region, msg := range s.FailedRegions {
+		alert.Fields = append(alert.Fields, notification.Field{Name: "Failed " + region, Value: fmt.Sprint(msg)})
+	}
+	return alert
+}
+
+// costAnomalyAlert reports the rise of the monthly waste between two scans
+// of the same scope
+func costAnomalyAlert(s, previous *model.Scan) notification.Alert {
+	increase := s.EstimatedSavings - previous.EstimatedSavings
+	return notification.Alert{
+		Event: entity.NotificationEventCostAnomaly,
+		Title: fmt.Sprintf("%s waste rose by $%.2f/month to $%.2f/month",
+			strings.ToUpper(s.Provider), increase, s.EstimatedSavings),
+		Severity: notification.SeverityCritical,
+		Source:   scanSource(s),
+		DedupKey: "cost-anomaly:" + s.ID.String(),
+		Fields: []notification.Field{
+			{Name: "Scan", Value: s.ID.String()},
+			{Name: "Previous scan", Value: previous.ID.String()},
+			{Name: "Previous waste", Value: fmt.Sprintf("$%.2f/month", previous.EstimatedSavings)},
+			{Name: "Unused resources", Value: fmt.Sprintf("%d (was %d)", s.UnusedFound, previous.UnusedFound)},
+		},
+	}
+}
+
+// scanSource names the scope of a scan, e.g. "aws eu-west-1,us-east-1"
+func scanSource(s *model.Scan) string {
+	if len(s.Regions) == 0 {
+		return s.Provider
+	}
+	return s.Provider + " " + strings.Join(s.Regions, ",")
+}
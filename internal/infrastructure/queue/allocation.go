@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/allocation"
+	"github.com/hibiken/asynq"
+)
+
+// HandleRefreshAllocation handles the periodic refresh of the cost
+// allocation of organizations by tag
+func HandleRefreshAllocation(aggregator *allocation.Aggregator) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		refreshed, err := aggregator.RefreshAll(ctx, time.Now())
+		log.Printf("Cost allocation: %d organizations refreshed", refreshed)
+		return err
+	}
+}
@@ -1,59 +1,178 @@
 package queue
 
 import (
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/allocation"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/billing"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ci"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/digest"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/discovery"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/events"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/gitops"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/hygiene"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/iac"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ownership"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/pricing"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/recommendation"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/retention"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/slack"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/summary"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ticketing"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/webhook"
 	"github.com/hibiken/asynq"
 	"gorm.io/gorm"
 )
 
 // Task types
 const (
-	TaskTypeScanResources    = "scan:resources"
-	TaskTypeCleanupResources = "cleanup:resources"
-	TaskTypeApplyPolicy      = "policy:apply"
-	TaskTypeSendNotification = "notification:send"
+	TaskTypeScanResources           = "scan:resources"
+	TaskTypeCleanupResources        = "cleanup:resources"
+	TaskTypeApplyPolicy             = "policy:apply"
+	TaskTypeSendNotification        = "notification:send"
+	TaskTypeGenerateExport          = "export:generate"
+	TaskTypeSendOwnerDigest         = "digest:owners"
+	TaskTypeDeliverScanWebhook      = "webhook:scan"
+	TaskTypePurgeProviderCalls      = "audit:purge"
+	TaskTypeRecordHygiene           = "hygiene:record"
+	TaskTypeDetectPreviewEnvs       = "ci:preview"
+	TaskTypeGenerateRecommendations = "recommendations:generate"
+	TaskTypeStopSchedule            = "resource:stop_schedule"
+	TaskTypeStartSchedule           = "resource:start_schedule"
+	TaskTypePurgeQuarantine         = "quarantine:purge"
+	TaskTypeRestoreResource         = "resource:restore"
+	TaskTypeRollbackCleanup         = "cleanup:rollback"
+	TaskTypeSyncIaCChanges          = "gitops:sync"
+	TaskTypePurgeExpiredHistory     = "plans:retention"
+	TaskTypeRefreshAllocation       = "allocation:refresh"
+	TaskTypeSyncIntegrations        = "integrations:sync"
+	TaskTypeReconcileBillingCosts   = "billing:reconcile"
+	TaskTypeRefreshPricing          = "pricing:refresh"
+	TaskTypePostSlackScan           = "slack:scan"
+	TaskTypeSendAlert               = "notification:alert"
+	TaskTypeSendPolicyMatches       = "notification:matches"
+	TaskTypeSyncTickets             = "tickets:sync"
+	TaskTypeAssignOwners            = "owners:assign"
+	TaskTypeSendOwnerNotifications  = "owners:digest"
+	TaskTypeSendWeeklySummaries     = "summary:weekly"
 )
 
-// NewAsynqClient creates a new Asynq client
-func NewAsynqClient(cfg config.RedisConfig) (*asynq.Client, error) {
-	client := asynq.NewClient(asynq.RedisClientOpt{
+func redisOpt(cfg config.RedisConfig) asynq.RedisClientOpt {
+	return asynq.RedisClientOpt{
 		Addr:     cfg.Addr,
 		Password: cfg.Password,
 		DB:       cfg.DB,
-	})
+	}
+}
+
+// NewAsynqClient creates a new Asynq client
+func NewAsynqClient(cfg config.RedisConfig) (*asynq.Client, error) {
+	client := asynq.NewClient(redisOpt(cfg))
 	return client, nil
 }
 
-// NewWorkerServer creates a new Asynq server for processing tasks
-func NewWorkerServer(cfg config.RedisConfig, db *gorm.DB) (*asynq.Server, error) {
+// NewInspector creates a new Asynq inspector for queue introspection
+func NewInspector(cfg config.RedisConfig) *asynq.Inspector {
+	return asynq.NewInspector(redisOpt(cfg))
+}
+
+// NewWorkerServer creates a new Asynq server for processing tasks.
+// On Shutdown the server stops pulling new tasks and waits up to
+// workerCfg.ShutdownTimeout for in-flight tasks before re-queueing them.
+// Failed tasks are retried according to their RetryPolicy and recorded in
+// the task_failures table; once retries are exhausted asynq archives them,
+// which acts as the dead-letter queue. Tasks are pulled from the priority
+// queues and the scan queues of each plan according to their weights.
+func NewWorkerServer(cfg config.RedisConfig, workerCfg config.WorkerConfig, db *gorm.DB) (*asynq.Server, error) {
 	srv := asynq.NewServer(
-		asynq.RedisClientOpt{
-			Addr:     cfg.Addr,
-			Password: cfg.Password,
-			DB:       cfg.DB,
-		},
+		redisOpt(cfg),
 		asynq.Config{
-			Concurrency: 10,
-			Queues: map[string]int{
-				"critical": 6,
-				"default":  3,
-				"low":      1,
-			},
+			Concurrency:     workerCfg.Concurrency,
+			Queues:          Queues(workerCfg),
+			ShutdownTimeout: workerCfg.ShutdownTimeout,
+			RetryDelayFunc:  retryDelay,
+			// Waiting for a concurrency slot does not use up an attempt
+			IsFailure:    func(err error) bool { return !ratelimit.IsBusy(err) },
+			ErrorHandler: NewFailureRecorder(db),
 		},
 	)
 
 	return srv, nil
 }
 
-// NewServeMux creates a new Asynq ServeMux with handlers
-func NewServeMux(db *gorm.DB) *asynq.ServeMux {
+// NewServeMux creates a new Asynq ServeMux with handlers. The client is used
+// by handlers queueing follow-up tasks. Scans create their scanners with
+// scanners, price what they cannot with catalog and read the cost, carbon
+// and worker settings of cfg. Cleanups create their cleaners with cleaners
+// and change nothing while readOnly says so. Scans and cleanups drop the
+// values cached in results for their organization. Alerts and webhook
+// events identical to one sent recently are dropped by suppressor.
+func NewServeMux(db *gorm.DB, client *asynq.Client, store storage.ObjectStore, mailer notification.Mailer, digests *digest.Sender, hooks *webhook.Client, suppressor *notification.Suppressor, matches *MatchCoalescer, webhookLinkTTL, auditRetention time.Duration, previews *ci.Detector, iacChanges *gitops.Proposer, integrations *discovery.Syncer, billingCosts *billing.Reconciler, prices *pricing.Refresher, history *retention.Purger, bus *events.Bus, results *cache.Cache, slackClient *slack.Client, tickets *ticketing.Tracker, owners *ownership.Resolver, ownerNotices *ownership.Notifier, summaries *summary.Sender, cleaners service.ResourceCleanerFactory, readOnly service.ReadOnlyGuard, scanners service.CloudScannerFactory, catalog service.PriceCatalog, cfg *config.Config) *asynq.ServeMux {
 	mux := asynq.NewServeMux()
 
+	resources := database.NewResourceRepository(db)
+	resourceEvents := database.NewResourceEventRepository(db)
+	quarantine := usecase.NewQuarantineUseCase(resources, cleaners, readOnly)
+	schedules := usecase.NewOffHoursScheduleUseCase(resources, database.NewPolicyRepository(db), cleaners, readOnly)
+	rollbacks := usecase.NewRollbackCleanupUseCase(resources, resourceEvents, cleaners, readOnly)
+	cleanups := usecase.NewCleanupResourcesUseCase(resources, resourceEvents, database.NewPolicyRepository(db), cleaners, iacChanges, readOnly, tickets, ownerNotices)
+	scans := usecase.NewScanResourcesUseCase(
+		database.NewScanRepository(db),
+		resources,
+		resourceEvents,
+		database.NewUnitOfWork(db),
+		scanners,
+		service.NewCostNormalizer(cfg.Costs.ExchangeRates),
+		service.NewCatalogEstimator(catalog),
+		service.NewCarbonEstimator(cfg.Carbon.PUE, cfg.Carbon.GridIntensity),
+		iac.NewStateIndex(db, store),
+		database.NewCustomResourceTypeRepository(db),
+		cfg.Worker.ScanConcurrency,
+		cfg.Worker.FullScanInterval,
+	)
+
 	// Register handlers
-	mux.HandleFunc(TaskTypeScanResources, HandleScanResources(db))
-	mux.HandleFunc(TaskTypeCleanupResources, HandleCleanupResources(db))
+	mux.HandleFunc(TaskTypeScanResources, HandleScanResources(db, scans, client, hooks, slackClient, bus, results))
+	mux.HandleFunc(TaskTypeCleanupResources, HandleCleanupResources(database.NewOrganizationRepository(db), resources, database.NewCloudAccountRepository(db), cleanups, bus, results))
 	mux.HandleFunc(TaskTypeApplyPolicy, HandleApplyPolicy(db))
-	mux.HandleFunc(TaskTypeSendNotification, HandleSendNotification(db))
+	mux.HandleFunc(TaskTypeSendNotification, HandleSendNotification(db, mailer))
+	mux.HandleFunc(TaskTypeGenerateExport, HandleGenerateExport(db, store))
+	mux.HandleFunc(TaskTypeSendOwnerDigest, HandleSendOwnerDigest(digests))
+	mux.HandleFunc(TaskTypeSendWeeklySummaries, HandleSendWeeklySummaries(summaries))
+	mux.HandleFunc(TaskTypeDeliverScanWebhook, HandleDeliverScanWebhook(db, store, hooks, webhookLinkTTL, suppressor))
+	mux.HandleFunc(TaskTypePurgeProviderCalls, HandlePurgeProviderCalls(db, auditRetention))
+	mux.HandleFunc(TaskTypeRecordHygiene, HandleRecordHygiene(hygiene.NewScorer(db)))
+	mux.HandleFunc(TaskTypeGenerateRecommendations, HandleGenerateRecommendations(recommendation.NewGenerator(db)))
+	mux.HandleFunc(TaskTypeDetectPreviewEnvs, HandleDetectPreviewEnvironments(previews))
+	mux.HandleFunc(TaskTypeStopSchedule, HandleStopSchedule(db, schedules))
+	mux.HandleFunc(TaskTypeStartSchedule, HandleStartSchedule(db, schedules))
+	mux.HandleFunc(TaskTypePurgeQuarantine, HandlePurgeQuarantine(db, quarantine))
+	mux.HandleFunc(TaskTypeRestoreResource, HandleRestoreResource(db, quarantine))
+	mux.HandleFunc(TaskTypeRollbackCleanup, HandleRollbackCleanup(db, rollbacks))
+	mux.HandleFunc(TaskTypeSyncIaCChanges, HandleSyncIaCChanges(iacChanges))
+	mux.HandleFunc(TaskTypePurgeExpiredHistory, HandlePurgeExpiredHistory(history))
+	mux.HandleFunc(TaskTypeRefreshAllocation, HandleRefreshAllocation(allocation.NewAggregator(db)))
+	mux.HandleFunc(TaskTypeSyncIntegrations, HandleSyncIntegrations(integrations))
+	mux.HandleFunc(TaskTypeReconcileBillingCosts, HandleReconcileBillingCosts(billingCosts))
+	mux.HandleFunc(TaskTypeRefreshPricing, HandleRefreshPricing(prices))
+	mux.HandleFunc(TaskTypePostSlackScan, HandlePostSlackScanMessage(db, slackClient))
+	mux.HandleFunc(TaskTypeSendAlert, HandleSendAlert(db, map[string]notification.Channel{
+		entity.NotificationChannelTeams:     notification.NewTeamsChannel(),
+		entity.NotificationChannelPagerDuty: notification.NewPagerDutyChannel(),
+	}, suppressor))
+	mux.HandleFunc(TaskTypeSendPolicyMatches, HandleSendPolicyMatches(db, client, matches))
+	mux.HandleFunc(TaskTypeSyncTickets, HandleSyncTickets(tickets))
+	mux.HandleFunc(TaskTypeAssignOwners, HandleAssignOwners(owners))
+	mux.HandleFunc(TaskTypeSendOwnerNotifications, HandleSendOwnerNotifications(ownerNotices))
 
 	return mux
 }
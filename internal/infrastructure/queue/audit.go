@@ -0,0 +1,21 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/audit"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// HandlePurgeProviderCalls handles the daily purge of provider calls past
+// their organization's retention
+func HandlePurgeProviderCalls(db *gorm.DB, retention time.Duration) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		deleted, err := audit.Purge(ctx, db, retention, time.Now())
+		log.Printf("Provider call retention: %d calls purged", deleted)
+		return err
+	}
+}
@@ -0,0 +1,67 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/billing"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// ReconcileBillingCostsPayload represents the payload for a billing cost
+// reconciliation task. The periodic task has no account and reconciles all
+// of them.
+type ReconcileBillingCostsPayload struct {
+	CloudAccountID string `json:"cloud_account_id,omitempty"`
+}
+
+// EnqueueBillingReconcile queues the reconciliation of the resource costs of
+// a cloud account, unless one is already queued
+func EnqueueBillingReconcile(ctx context.Context, client *asynq.Client, cloudAccountID string) error {
+	payload, _ := json.Marshal(ReconcileBillingCostsPayload{CloudAccountID: cloudAccountID})
+	task := NewTask(TaskTypeReconcileBillingCosts, payload, asynq.Queue(QueueLow), asynq.Unique(10*time.Minute))
+	if _, err := client.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		return err
+	}
+	return nil
+}
+
+// HandleReconcileBillingCosts handles the reconciliation of resource costs
+// with the billing data of their accounts
+func HandleReconcileBillingCosts(reconciler *billing.Reconciler) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload ReconcileBillingCostsPayload
+		if len(t.Payload()) > 0 {
+			if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+				return fmt.Errorf("failed to unmarshal payload: %w", err)
+			}
+		}
+
+		if payload.CloudAccountID != "" {
+			id, err := uuid.Parse(payload.CloudAccountID)
+			if err != nil {
+				return fmt.Errorf("invalid cloud account ID %q: %w", payload.CloudAccountID, asynq.SkipRetry)
+			}
+			updated, err := reconciler.Reconcile(ctx, id, time.Now())
+			log.Printf("Billing: %d resource costs reconciled for account %s", updated, id)
+			if err != nil {
+				return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+			}
+			return nil
+		}
+
+		updated, err := reconciler.ReconcileAll(ctx, time.Now())
+		log.Printf("Billing: %d resource costs reconciled", updated)
+		if err != nil {
+			// Errors are recorded on the failing accounts, which the next
+			// run tries again
+			return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ci"
+	"github.com/hibiken/asynq"
+)
+
+// HandleDetectPreviewEnvironments handles the periodic detection of preview
+// environments left behind by closed pull requests
+func HandleDetectPreviewEnvironments(detector *ci.Detector) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		flagged, err := detector.Detect(ctx, time.Now())
+		log.Printf("Preview environments: %d resources flagged", flagged)
+		return err
+	}
+}
@@ -0,0 +1,60 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/jobs"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// CleanupTaskQueue queues the cleanups requested through the API, and their
+// rollbacks
+type CleanupTaskQueue struct {
+	client *asynq.Client
+	jobs   *jobs.Tracker
+}
+
+// NewCleanupTaskQueue creates a CleanupTaskQueue. Each queued cleanup is
+// registered as a job with tracker, unless it is nil.
+func NewCleanupTaskQueue(client *asynq.Client, tracker *jobs.Tracker) *CleanupTaskQueue {
+	return &CleanupTaskQueue{client: client, jobs: tracker}
+}
+
+var _ service.CleanupQueue = (*CleanupTaskQueue)(nil)
+
+// Enqueue implements service.CleanupQueue. Cleanups go to the queue
+// CleanupQueue picks for their action.
+func (q *CleanupTaskQueue) Enqueue(ctx context.Context, orgID uuid.UUID, cleanup service.CleanupRequest) (*entity.Job, error) {
+	resourceIDs := make([]string, len(cleanup.ResourceIDs))
+	for i, id := range cleanup.ResourceIDs {
+		resourceIDs[i] = id.String()
+	}
+	payload, _ := json.Marshal(CleanupResourcesPayload{
+		OrganizationID:      orgID.String(),
+		ResourceIDs:         resourceIDs,
+		Action:              string(cleanup.Action),
+		DryRun:              cleanup.DryRun,
+		Backup:              cleanup.Backup,
+		BackupRetentionDays: cleanup.BackupRetentionDays,
+	})
+
+	task := NewTask(TaskTypeCleanupResources, payload, asynq.Queue(CleanupQueue(string(cleanup.Action), cleanup.DryRun)))
+	job, info, err := q.jobs.Enqueue(ctx, q.client, orgID, entity.JobTypeCleanup, nil, task)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return &entity.Job{OrganizationID: orgID, Type: entity.JobTypeCleanup, TaskID: info.ID, Status: entity.JobStatusPending}, nil
+	}
+	return database.JobEntity(job), nil
+}
+
+// EnqueueRollback implements service.CleanupQueue
+func (q *CleanupTaskQueue) EnqueueRollback(ctx context.Context, orgID, resourceID uuid.UUID, taskID string, recovery *service.Recovery, actor string) error {
+	return EnqueueRollbackCleanup(ctx, q.client, orgID, resourceID, taskID, recovery, actor)
+}
@@ -0,0 +1,237 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// matchesPrefix prefixes the sets of resources matched by a policy run and
+// not sent yet
+const matchesPrefix = "cloudsweep:matches:"
+
+// maxListedMatches is the number of resources listed in a policy.matched
+// alert, the most expensive first
+const maxListedMatches = 10
+
+// SendPolicyMatchesPayload represents the payload for the alert listing the
+// resources a policy run matched
+type SendPolicyMatchesPayload struct {
+	OrganizationID string `json:"organization_id"`
+	PolicyID       string `json:"policy_id"`
+	RunID          string `json:"run_id"`
+}
+
+// MatchCoalescer gathers the resources matched by policy runs in Redis, so
+// that a run matching hundreds of resources sends one alert rather than one
+// per resource
+type MatchCoalescer struct {
+	rdb    *redis.Client
+	client *asynq.Client
+	delay  time.Duration
+}
+
+// NewMatchCoalescer creates a coalescer sending the resources matched by a
+// run delay after the first of them
+func NewMatchCoalescer(rdb *redis.Client, client *asynq.Client, delay time.Duration) *MatchCoalescer {
+	return &MatchCoalescer{rdb: rdb, client: client, delay: delay}
+}
+
+// Add records resources matched by a policy run. The first ones queue the
+// alert, which lists every resource of the run recorded until it is sent;
+// those recorded after queue another one.
+func (c *MatchCoalescer) Add(ctx context.Context, orgID, policyID, runID string, resourceIDs ...string) error {
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+	key := matchesKey(orgID, policyID, runID)
+	members := make([]any, len(resourceIDs))
+	for i, id := range resourceIDs {
+		members[i] = id
+	}
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, c.delay+24*time.Hour)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record policy matches: %w", err)
+	}
+
+	// The marker lasts longer than the delay so that an alert lost with
+	// its task does not hold back the next ones forever
+	queued, err := c.rdb.SetNX(ctx, key+":queued", 1, c.delay+10*time.Minute).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record policy matches: %w", err)
+	}
+	if !queued {
+		return nil
+	}
+	payload, _ := json.Marshal(SendPolicyMatchesPayload{OrganizationID: orgID, PolicyID: policyID, RunID: runID})
+	if _, err := c.client.EnqueueContext(ctx, NewTask(TaskTypeSendPolicyMatches, payload, asynq.ProcessIn(c.delay))); err != nil {
+		c.rdb.Del(ctx, key+":queued")
+		return fmt.Errorf("failed to queue policy matches alert: %w", err)
+	}
+	return nil
+}
+
+// take returns and forgets the resources recorded for a run. Resources
+// recorded from now on queue another alert.
+func (c *MatchCoalescer) take(ctx context.Context, payload SendPolicyMatchesPayload) ([]string, error) {
+	key := matchesKey(payload.OrganizationID, payload.PolicyID, payload.RunID)
+	var members *redis.StringSliceCmd
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key+":queued")
+		members = pipe.SMembers(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy matches: %w", err)
+	}
+	return members.Val(), nil
+}
+
+// putBack records again resources whose alert could not be queued, for
+// the retry to send them
+func (c *MatchCoalescer) putBack(ctx context.Context, payload SendPolicyMatchesPayload, resourceIDs []string) {
+	key := matchesKey(payload.OrganizationID, payload.PolicyID, payload.RunID)
+	members := make([]any, len(resourceIDs))
+	for i, id := range resourceIDs {
+		members[i] = id
+	}
+	c.rdb.SAdd(ctx, key, members...)
+	c.rdb.Expire(ctx, key, c.delay+24*time.Hour)
+}
+
+func matchesKey(orgID, policyID, runID string) string {
+	return matchesPrefix + orgID + ":" + policyID + ":" + runID
+}
+
+// HandleSendPolicyMatches handles policy matches tasks, recording the match
+// in the timeline of each resource recorded for the run and queueing one
+// alert per channel policy.matched is routed to with them
+func HandleSendPolicyMatches(db *gorm.DB, client *asynq.Client, matches *MatchCoalescer) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload SendPolicyMatchesPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		resourceIDs, err := matches.take(ctx, payload)
+		if err != nil || len(resourceIDs) == 0 {
+			return err
+		}
+
+		var policy model.Policy
+		if err := db.WithContext(ctx).Preload("Organization").First(&policy, "id = ? AND organization_id = ?", payload.PolicyID, payload.OrganizationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			matches.putBack(ctx, payload, resourceIDs)
+			return fmt.Errorf("failed to load policy %s: %w", payload.PolicyID, err)
+		}
+		var resources []model.Resource
+		if err := db.WithContext(ctx).
+			Where("organization_id = ? AND id IN ?", payload.OrganizationID, resourceIDs).
+			Find(&resources).Error; err != nil {
+			matches.putBack(ctx, payload, resourceIDs)
+			return fmt.Errorf("failed to load matched resources: %w", err)
+		}
+		if len(resources) == 0 {
+			return nil
+		}
+		if err := database.RecordResourceEvents(db.WithContext(ctx), policyMatchedEvents(&policy, payload.RunID, resources)...); err != nil {
+			matches.putBack(ctx, payload, resourceIDs)
+			return fmt.Errorf("failed to record policy matches: %w", err)
+		}
+
+		settings := policy.Organization.Settings().Notifications
+		if len(settings.ChannelsFor(entity.NotificationEventPolicyMatched)) == 0 {
+			return nil
+		}
+
+		taskID, _ := asynq.GetTaskID(ctx)
+		alert := policyMatchedAlert(&policy, payload.RunID, taskID, resources)
+		log.Printf("Policy %s run %s matched %d resources", payload.PolicyID, payload.RunID, len(resources))
+		if err := enqueueAlert(ctx, client, policy.OrganizationID, settings, alert); err != nil {
+			matches.putBack(ctx, payload, resourceIDs)
+			return err
+		}
+		return nil
+	}
+}
+
+// policyMatchedEvents returns the events recording that a policy run
+// matched resources. Their IDs derive from the run and the resource, so
+// that retries do not record them twice.
+func policyMatchedEvents(p *model.Policy, runID string, resources []model.Resource) []*entity.ResourceEvent {
+	events := make([]*entity.ResourceEvent, len(resources))
+	for i, r := range resources {
+		e := entity.NewResourceEvent(r.OrganizationID, r.ID, entity.ResourceEventPolicyMatched, entity.ResourceEventActorPolicy, map[string]any{
+			"run_id":       runID,
+			"status":       r.Status,
+			"monthly_cost": r.MonthlyCost,
+		})
+		e.ID = uuid.NewSHA1(p.ID, []byte(runID+":"+r.ID.String()))
+		e.PolicyID = &p.ID
+		events[i] = e
+	}
+	return events
+}
+
+// policyMatchedAlert lists the resources a policy run matched, the most
+// expensive first. taskID tells apart the alerts of a run matching
+// resources over more than the coalescing delay.
+func policyMatchedAlert(p *model.Policy, runID, taskID string, resources []model.Resource) notification.Alert {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].MonthlyCost != resources[j].MonthlyCost {
+			return resources[i].MonthlyCost > resources[j].MonthlyCost
+		}
+		return resources[i].ResourceID < resources[j].ResourceID
+	})
+	var total float64
+	for _, r := range resources {
+		total += r.MonthlyCost
+	}
+
+	lines := make([]string, 0, maxListedMatches+1)
+	for _, r := range resources[:min(len(resources), maxListedMatches)] {
+		name := r.Name
+		if name == "" {
+			name = r.ResourceID
+		}
+		lines = append(lines, fmt.Sprintf("- %s (%s, %s): $%.2f/month", name, r.Type, r.Region, r.MonthlyCost))
+	}
+	if more := len(resources) - maxListedMatches; more > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more", more))
+	}
+
+	return notification.Alert{
+		Event:    entity.NotificationEventPolicyMatched,
+		Title:    fmt.Sprintf("Policy %s matched %d resources, $%.2f/month", p.Name, len(resources), total),
+		Text:     strings.Join(lines, "\n"),
+		Severity: notification.SeverityWarning,
+		Source:   p.Provider,
+		DedupKey: "policy-matched:" + p.ID.String() + ":" + runID + ":" + taskID,
+		Fields: []notification.Field{
+			{Name: "Policy", Value: p.ID.String()},
+			{Name: "Run", Value: runID},
+			{Name: "Resources", Value: strconv.Itoa(len(resources))},
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// AccountLimits caps the scans and cleanups running at once against each
+// cloud account, each provider and the whole fleet, across every worker
+// replica. A task finding a limit reached is retried after
+// cfg.RetryDelay without counting as a failed attempt.
+type AccountLimits struct {
+	db  *gorm.DB
+	sem *ratelimit.Semaphore
+	cfg config.ConcurrencyConfig
+}
+
+// NewAccountLimits creates the limits of the worker fleet. It returns nil
+// when they are disabled.
+func NewAccountLimits(db *gorm.DB, sem *ratelimit.Semaphore, cfg config.ConcurrencyConfig) *AccountLimits {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &AccountLimits{db: db, sem: sem, cfg: cfg}
+}
+
+// account is a cloud account tasks run against
+type account struct {
+	Provider  string
+	AccountID string
+}
+
+// Middleware holds the slots of the accounts of scan and cleanup tasks
+// while they run. Other tasks, and every task of nil limits, run as is.
+func (l *AccountLimits) Middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		if l == nil {
+			return next.ProcessTask(ctx, task)
+		}
+
+		var accounts []account
+		var err error
+		switch task.Type() {
+		case TaskTypeScanResources:
+			accounts, err = l.scanAccounts(ctx, task)
+		case TaskTypeCleanupResources:
+			accounts, err = l.cleanupAccounts(ctx, task)
+		default:
+			return next.ProcessTask(ctx, task)
+		}
+		if err != nil {
+			return err
+		}
+
+		limits := l.limits(accounts)
+		if len(limits) == 0 {
+			return next.ProcessTask(ctx, task)
+		}
+		holder, _ := asynq.GetTaskID(ctx)
+		slots, err := l.sem.Acquire(ctx, holder, limits, l.cfg.RetryDelay)
+		if err != nil {
+			return err
+		}
+		defer slots.Release(context.WithoutCancel(ctx))
+
+		return next.ProcessTask(ctx, task)
+	})
+}
+
+// limits returns the semaphores of the accounts, their providers and the
+// fleet, leaving out the unlimited ones
+func (l *AccountLimits) limits(accounts []account) []ratelimit.Limit {
+	var limits []ratelimit.Limit
+	if l.cfg.Global > 0 {
+		limits = append(limits, ratelimit.Limit{Key: "global", Slots: l.cfg.Global})
+	}
+
+	providers := make(map[string]bool)
+	for _, a := range accounts {
+		if !providers[a.Provider] {
+			providers[a.Provider] = true
+			if n := l.providerLimit(entity.CloudProvider(a.Provider)); n > 0 {
+				limits = append(limits, ratelimit.Limit{Key: "provider:" + a.Provider, Slots: n})
+			}
+		}
+		if a.AccountID != "" && l.cfg.PerAccount > 0 {
+			limits = append(limits, ratelimit.Limit{Key: "account:" + a.Provider + ":" + a.AccountID, Slots: l.cfg.PerAccount})
+		}
+	}
+
+	// Always taken in the same order, so two tasks sharing semaphores
+	// cannot each hold a part of what the other needs
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Key < limits[j].Key })
+	return limits
+}
+
+func (l *AccountLimits) providerLimit(provider entity.CloudProvider) int {
+	switch provider {
+	case entity.CloudProviderAWS:
+		return l.cfg.AWS
+	case entity.CloudProviderAzure:
+		return l.cfg.Azure
+	case entity.CloudProviderGCP:
+		return l.cfg.GCP
+	case entity.CloudProviderKubernetes:
+		return l.cfg.Kubernetes
+	}
+	return 0
+}
+
+// scanAccounts returns the active cloud accounts a scan covers. A scan of
+// an organization without accounts of its provider only counts against
+// the provider and fleet limits.
+func (l *AccountLimits) scanAccounts(ctx context.Context, task *asynq.Task) ([]account, error) {
+	var payload ScanResourcesPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	var accounts []account
+	err := l.db.WithContext(ctx).Model(&model.CloudAccount{}).
+		Select("DISTINCT provider, account_id").
+		Where("organization_id = ? AND provider = ? AND is_active = ?", payload.OrganizationID, payload.Provider, true).
+		Scan(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cloud accounts of org %s: %w", payload.OrganizationID, err)
+	}
+	if len(accounts) == 0 {
+		accounts = []account{{Provider: payload.Provider}}
+	}
+	return accounts, nil
+}
+
+// cleanupAccounts returns the accounts of the resources a cleanup acts on
+func (l *AccountLimits) cleanupAccounts(ctx context.Context, task *asynq.Task) ([]account, error) {
+	var payload CleanupResourcesPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	// Dry runs and notifications make no provider calls
+	if payload.DryRun || payload.Action == string(entity.PolicyActionNotify) || len(payload.ResourceIDs) == 0 {
+		return nil, nil
+	}
+
+	var accounts []account
+	err := l.db.WithContext(ctx).Model(&model.Resource{}).
+		Select("DISTINCT provider, account_id").
+		Where("organization_id = ? AND id IN ?", payload.OrganizationID, payload.ResourceIDs).
+		Scan(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts of cleanup resources: %w", err)
+	}
+	return accounts, nil
+}
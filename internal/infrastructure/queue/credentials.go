@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// accountCredentials returns the credentials of the active cloud account of
+// an organization resources were found in. A deleted or deactivated account
+// fails without retry.
+func accountCredentials(ctx context.Context, db *gorm.DB, orgID uuid.UUID, provider, accountID string) ([]byte, error) {
+	var account model.CloudAccount
+	err := db.WithContext(ctx).Select("credentials").
+		Where("organization_id = ? AND provider = ? AND account_id = ? AND is_active = ?", orgID, provider, accountID, true).
+		First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("no active %s account %s in org %s: %w", provider, accountID, orgID, asynq.SkipRetry)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s account %s: %w", provider, accountID, err)
+	}
+	return account.Credentials, nil
+}
+
+// scanCredentials returns the credentials of the active cloud account of a
+// provider an organization connected first. Organizations without one are
+// scanned with the credentials of the worker configuration.
+func scanCredentials(ctx context.Context, db *gorm.DB, orgID uuid.UUID, provider string) ([]byte, error) {
+	var accounts []model.CloudAccount
+	err := db.WithContext(ctx).Select("credentials").
+		Where("organization_id = ? AND provider = ? AND is_active = ?", orgID, provider, true).
+		Order("created_at").Limit(1).Find(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s accounts of org %s: %w", provider, orgID, err)
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+	return accounts[0].Credentials, nil
+}
@@ -0,0 +1,8 @@
+package queue
+
+// ScanTaskID returns the asynq task ID used for a scan fingerprint (see
+// entity.ScanFingerprint). asynq rejects a second task with the same ID while
+// the first one is still pending, active or retrying.
+func ScanTaskID(fingerprint string) string {
+	return TaskTypeScanResources + ":" + fingerprint
+}
@@ -0,0 +1,24 @@
+package queue
+
+import (
+	"context"
+	"log"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/digest"
+	"github.com/hibiken/asynq"
+)
+
+// HandleSendOwnerDigest handles the weekly owner digest task
+func HandleSendOwnerDigest(sender *digest.Sender) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		res, err := sender.Run(ctx)
+		log.Printf("Owner digest: %d sent, %d failed, %d unattributed resources", res.Sent, res.Failed, res.Unattributed)
+
+		// Retrying would email again the owners who already got their digest
+		if err != nil && res.Sent > 0 {
+			log.Printf("Owner digest completed with errors: %v", err)
+			return nil
+		}
+		return err
+	}
+}
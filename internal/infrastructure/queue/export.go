@@ -0,0 +1,284 @@
+package queue
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/jobs"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is the number of rows loaded per query while exporting
+const exportBatchSize = 1000
+
+// GenerateExportPayload represents the payload for an export task
+type GenerateExportPayload struct {
+	ExportID string `json:"export_id"`
+}
+
+// ExportObjectKey returns the storage key of an export file
+func ExportObjectKey(export *model.Export) string {
+	return fmt.Sprintf("exports/%s/%s.%s", export.OrganizationID, export.ID, export.Format)
+}
+
+// HandleGenerateExport handles export generation tasks
+func HandleGenerateExport(db *gorm.DB, store storage.ObjectStore) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload GenerateExportPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+
+		var export model.Export
+		if err := db.WithContext(ctx).First(&export, "id = ?", payload.ExportID).Error; err != nil {
+			return fmt.Errorf("failed to load export %s: %v: %w", payload.ExportID, err, asynq.SkipRetry)
+		}
+
+		log.Printf("Generating %s export %s for org %s", export.Type, export.ID, export.OrganizationID)
+
+		now := time.Now()
+		db.Model(&export).Updates(map[string]any{
+			"status":     string(entity.ExportStatusRunning),
+			"started_at": &now,
+		})
+
+		if err := generateExport(ctx, db, store, &export); err != nil {
+			db.Model(&export).Updates(map[string]any{
+				"status":        string(entity.ExportStatusFailed),
+				"error_message": err.Error(),
+			})
+			return err
+		}
+
+		completed := time.Now()
+		return db.Model(&export).Updates(map[string]any{
+			"status":        string(entity.ExportStatusCompleted),
+			"object_key":    export.ObjectKey,
+			"row_count":     export.RowCount,
+			"size_bytes":    export.SizeBytes,
+			"error_message": "",
+			"completed_at":  &completed,
+		}).Error
+	}
+}
+
+// generateExport writes the export to a temporary file, then uploads it so
+// the object store receives a known size and never a partial file
+func generateExport(ctx context.Context, db *gorm.DB, store storage.ObjectStore, export *model.Export) error {
+	tmp, err := os.CreateTemp("", "cloudsweep-export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	rows, err := writeExport(ctx, db, export, tmp)
+	if err != nil {
+		return err
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := ExportObjectKey(export)
+	contentType := entity.ExportFormat(export.Format).ContentType()
+	if err := store.Put(ctx, key, tmp, info.Size(), contentType); err != nil {
+		return fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	export.ObjectKey = key
+	export.RowCount = rows
+	export.SizeBytes = info.Size()
+	return nil
+}
+
+var (
+	resourceExportColumns = []string{
+		"id", "provider", "type", "resource_id", "region", "name", "status",
+		"monthly_cost", "carbon_footprint_kg", "tags", "last_seen_at",
+	}
+	scanExportColumns = []string{
+		"id", "provider", "regions", "resource_types", "status", "resources_found",
+		"unused_found", "estimated_savings", "carbon_savings_kg", "started_at", "completed_at",
+	}
+)
+
+func writeExport(ctx context.Context, db *gorm.DB, export *model.Export, w io.Writer) (int, error) {
+	rw := newRecordWriter(entity.ExportFormat(export.Format), w)
+	query := db.WithContext(ctx).Where("organization_id = ?", export.OrganizationID)
+	count := 0
+
+	switch entity.ExportType(export.Type) {
+	case entity.ExportTypeResources:
+		if err := rw.header(resourceExportColumns); err != nil {
+			return 0, err
+		}
+		query = applyExportFilters(query, export.Filters, "provider", "type", "status", "region")
+
+		total := exportTotal(query, &model.Resource{})
+		var batch []model.Resource
+		result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, r := range batch {
+				if err := rw.write([]any{
+					r.ID, r.Provider, r.Type, r.ResourceID, r.Region, r.Name, r.Status,
+					r.MonthlyCost, r.CarbonFootprint, map[string]any(r.Tags), r.LastSeenAt,
+				}); err != nil {
+					return err
+				}
+				count++
+			}
+			jobs.Progress(ctx, count, total)
+			return nil
+		})
+		if result.Error != nil {
+			return 0, fmt.Errorf("failed to export resources: %w", result.Error)
+		}
+
+	case entity.ExportTypeScans:
+		if err := rw.header(scanExportColumns); err != nil {
+			return 0, err
+		}
+		query = applyExportFilters(query, export.Filters, "provider", "status")
+
+		total := exportTotal(query, &model.Scan{})
+		var batch []model.Scan
+		result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, s := range batch {
+				if err := rw.write([]any{
+					s.ID, s.Provider, []string(s.Regions), []string(s.ResourceTypes), s.Status, s.ResourcesFound,
+					s.UnusedFound, s.EstimatedSavings, s.CarbonSavings, s.StartedAt, s.CompletedAt,
+				}); err != nil {
+					return err
+				}
+				count++
+			}
+			jobs.Progress(ctx, count, total)
+			return nil
+		})
+		if result.Error != nil {
+			return 0, fmt.Errorf("failed to export scans: %w", result.Error)
+		}
+
+	default:
+		return 0, fmt.Errorf("unsupported export type %q: %w", export.Type, asynq.SkipRetry)
+	}
+
+	return count, rw.close()
+}
+
+// exportTotal counts the records of an export to report its progress. The
+// export goes on without progress when they cannot be counted.
+func exportTotal(query *gorm.DB, m any) int {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Model(m).Count(&total).Error; err != nil {
+		return 0
+	}
+	return int(total)
+}
+
+// applyExportFilters adds equality filters for the allowed columns
+func applyExportFilters(query *gorm.DB, filters model.JSONB, columns ...string) *gorm.DB {
+	for _, column := range columns {
+		if v, ok := filters[column].(string); ok && v != "" {
+			query = query.Where(column+" = ?", v)
+		}
+	}
+	return query
+}
+
+// recordWriter streams rows as CSV or as a JSON array of objects
+type recordWriter struct {
+	format  entity.ExportFormat
+	w       io.Writer
+	csv     *csv.Writer
+	columns []string
+	rows    int
+}
+
+func newRecordWriter(format entity.ExportFormat, w io.Writer) *recordWriter {
+	rw := &recordWriter{format: format, w: w}
+	if format == entity.ExportFormatCSV {
+		rw.csv = csv.NewWriter(w)
+	}
+	return rw
+}
+
+func (rw *recordWriter) header(columns []string) error {
+	rw.columns = columns
+	if rw.csv != nil {
+		return rw.csv.Write(columns)
+	}
+	_, err := io.WriteString(rw.w, "[")
+	return err
+}
+
+func (rw *recordWriter) write(values []any) error {
+	defer func() { rw.rows++ }()
+
+	if rw.csv != nil {
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = csvValue(v)
+		}
+		return rw.csv.Write(record)
+	}
+
+	obj := make(map[string]any, len(values))
+	for i, v := range values {
+		obj[rw.columns[i]] = v
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	sep := "\n"
+	if rw.rows > 0 {
+		sep = ",\n"
+	}
+	_, err = io.WriteString(rw.w, sep+string(data))
+	return err
+}
+
+func (rw *recordWriter) close() error {
+	if rw.csv != nil {
+		rw.csv.Flush()
+		return rw.csv.Error()
+	}
+	_, err := io.WriteString(rw.w, "\n]\n")
+	return err
+}
+
+func csvValue(v any) string {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case *time.Time:
+		if val == nil {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	case []string:
+		return strings.Join(val, ";")
+	case map[string]any:
+		data, _ := json.Marshal(val)
+		return string(data)
+	default:
+		return fmt.Sprint(val)
+	}
+}
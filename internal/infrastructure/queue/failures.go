@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Task failure statuses
+const (
+	FailureStatusRetrying = "retrying"
+	FailureStatusDead     = "dead"
+	FailureStatusRequeued = "requeued"
+)
+
+// FailureRecorder is an asynq.ErrorHandler persisting task failures
+type FailureRecorder struct {
+	db *gorm.DB
+}
+
+// NewFailureRecorder creates a new FailureRecorder
+func NewFailureRecorder(db *gorm.DB) *FailureRecorder {
+	return &FailureRecorder{db: db}
+}
+
+// HandleError records a failed attempt. A task keeps a single row that is
+// updated on each attempt and flagged dead once asynq archives it.
+func (r *FailureRecorder) HandleError(ctx context.Context, task *asynq.Task, err error) {
+	// Tasks waiting for a concurrency slot have not failed
+	if ratelimit.IsBusy(err) {
+		return
+	}
+	taskID, _ := asynq.GetTaskID(ctx)
+	queueName, _ := asynq.GetQueueName(ctx)
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+
+	status := FailureStatusRetrying
+	if retried >= maxRetry || errors.Is(err, asynq.SkipRetry) {
+		status = FailureStatusDead
+	}
+
+	var payload model.JSONB
+	if jsonErr := json.Unmarshal(task.Payload(), &payload); jsonErr != nil {
+		payload = model.JSONB{"raw": string(task.Payload())}
+	}
+
+	failure := model.TaskFailure{
+		TaskID:       taskID,
+		TaskType:     task.Type(),
+		Queue:        queueName,
+		Payload:      payload,
+		Error:        err.Error(),
+		Attempts:     retried + 1,
+		MaxRetry:     maxRetry,
+		Status:       status,
+		LastFailedAt: time.Now(),
+	}
+
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"error", "attempts", "status", "last_failed_at", "updated_at"}),
+	}).Create(&failure)
+	if result.Error != nil {
+		log.Printf("Failed to record failure of task %s: %v", taskID, result.Error)
+	}
+
+	if status == FailureStatusDead {
+		log.Printf("Task %s (%s) moved to dead-letter queue after %d attempts: %v", taskID, task.Type(), retried+1, err)
+	}
+}
@@ -0,0 +1,143 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+// reserveSlot advances an organization's virtual clock by ARGV[2]
+// milliseconds and returns the start of the reserved slot in unix
+// milliseconds. The slot starts at max(now, clock) while other organizations
+// have slots reserved past now, i.e. work queued, and at the current time
+// otherwise: an organization scanning alone is never held back. KEYS[2]
+// holds the end of the last slot of every organization, ARGV[3] being this
+// organization.
+var reserveSlot = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local cost = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+local others = redis.call('ZCARD', KEYS[2])
+if redis.call('ZSCORE', KEYS[2], ARGV[3]) then
+  others = others - 1
+end
+local start = now
+if others > 0 then
+  start = math.max(now, tonumber(redis.call('GET', KEYS[1]) or now))
+end
+local finish = start + cost
+redis.call('SET', KEYS[1], finish, 'PX', finish - now + cost)
+redis.call('ZADD', KEYS[2], finish, ARGV[3])
+return start
+`)
+
+// FairScheduler interleaves scans across organizations with weighted fair
+// queuing: each scan reserves a slot on its organization's virtual clock and
+// is held back until that slot starts. An organization queueing a burst of
+// scans while others have work queued sees them spread over time, the other
+// organizations' scans being processed as soon as they are queued.
+type FairScheduler struct {
+	client *redis.Client
+	cfg    config.FairnessConfig
+}
+
+// NewFairScheduler creates a scheduler keeping its clocks in Redis
+func NewFairScheduler(client *redis.Client, cfg config.FairnessConfig) *FairScheduler {
+	return &FairScheduler{client: client, cfg: cfg}
+}
+
+// ScanOptions reserves the next scan slot of the organization and returns
+// the options delaying its task until then. If the slot cannot be reserved
+// the scan is not delayed.
+func (s *FairScheduler) ScanOptions(ctx context.Context, orgID, plan string) []asynq.Option {
+	if s == nil || !s.cfg.Enabled || s.cfg.Slot <= 0 {
+		return nil
+	}
+
+	weight := s.cfg.PlanWeights[plan]
+	if weight <= 0 {
+		weight = 1
+	}
+	cost := time.Duration(float64(s.cfg.Slot) / weight)
+
+	now := time.Now()
+	start, err := reserveSlot.Run(ctx, s.client, []string{"cloudsweep:fair:" + orgID, "cloudsweep:fair:busy"},
+		now.UnixMilli(), cost.Milliseconds(), orgID).Int64()
+	if err != nil {
+		log.Printf("Failed to reserve scan slot for org %s, scheduling immediately: %v", orgID, err)
+		return nil
+	}
+
+	processAt := time.UnixMilli(start)
+	if processAt.Sub(now) < time.Second {
+		return nil
+	}
+	return []asynq.Option{asynq.ProcessAt(processAt)}
+}
+
+// WaitStats summarizes how long an organization's scans waited between
+// being queued and starting
+type WaitStats struct {
+	Count      int64 `json:"count"`
+	AvgMillis  int64 `json:"avg_ms"`
+	MaxMillis  int64 `json:"max_ms"`
+	LastMillis int64 `json:"last_ms"`
+	total      int64
+	seen       time.Time
+}
+
+var scanWaits = struct {
+	sync.Mutex
+	byOrg map[string]*WaitStats
+}{byOrg: make(map[string]*WaitStats)}
+
+// recordScanWait records the queue wait of a scan starting now
+func recordScanWait(orgID string, queuedAt time.Time) {
+	wait := time.Since(queuedAt).Milliseconds()
+
+	scanWaits.Lock()
+	defer scanWaits.Unlock()
+
+	s, ok := scanWaits.byOrg[orgID]
+	if !ok {
+		s = &WaitStats{}
+		scanWaits.byOrg[orgID] = s
+	}
+	s.Count++
+	s.total += wait
+	s.AvgMillis = s.total / s.Count
+	s.MaxMillis = max(s.MaxMillis, wait)
+	s.LastMillis = wait
+	s.seen = time.Now()
+}
+
+// expireScanWaits drops the wait stats of the organizations that started no
+// scan on this worker since before
+func expireScanWaits(before time.Time) {
+	scanWaits.Lock()
+	defer scanWaits.Unlock()
+
+	for org, s := range scanWaits.byOrg {
+		if s.seen.Before(before) {
+			delete(scanWaits.byOrg, org)
+		}
+	}
+}
+
+// ScanWaitStats returns the scan queue wait stats of every organization
+// that started a scan on this worker within the last heartbeat window
+func ScanWaitStats() map[string]WaitStats {
+	scanWaits.Lock()
+	defer scanWaits.Unlock()
+
+	out := make(map[string]WaitStats, len(scanWaits.byOrg))
+	for org, s := range scanWaits.byOrg {
+		out[org] = *s
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package queue
+
+import (
+	"context"
+	"log"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/gitops"
+	"github.com/hibiken/asynq"
+)
+
+// HandleSyncIaCChanges handles the periodic refresh of the status of the
+// pull requests removing infrastructure-as-code managed resources
+func HandleSyncIaCChanges(proposer *gitops.Proposer) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		closed, err := proposer.Sync(ctx)
+		log.Printf("IaC changes: %d pull requests merged or closed", closed)
+		return err
+	}
+}
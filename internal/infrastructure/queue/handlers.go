@@ -3,19 +3,36 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/events"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/slack"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/webhook"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"gorm.io/gorm"
 )
 
 // ScanResourcesPayload represents the payload for a scan task
 type ScanResourcesPayload struct {
-	OrganizationID string   `json:"organization_id"`
-	Provider       string   `json:"provider"`
-	Regions        []string `json:"regions"`
-	ResourceTypes  []string `json:"resource_types"`
+	ScanID         string    `json:"scan_id"`
+	OrganizationID string    `json:"organization_id"`
+	Provider       string    `json:"provider"`
+	Regions        []string  `json:"regions"`
+	ResourceTypes  []string  `json:"resource_types"`
+	Incremental    bool      `json:"incremental,omitempty"`
+	QueuedAt       time.Time `json:"queued_at"`
 }
 
 // CleanupResourcesPayload represents the payload for a cleanup task
@@ -24,6 +41,10 @@ type CleanupResourcesPayload struct {
 	ResourceIDs    []string `json:"resource_ids"`
 	Action         string   `json:"action"`
 	DryRun         bool     `json:"dry_run"`
+	// Backup snapshots volumes, disks and databases before deleting them,
+	// keeping the snapshots for BackupRetentionDays
+	Backup              bool `json:"backup,omitempty"`
+	BackupRetentionDays int  `json:"backup_retention_days,omitempty"`
 }
 
 // ApplyPolicyPayload represents the payload for a policy application task
@@ -40,8 +61,13 @@ type SendNotificationPayload struct {
 	Data    map[string]any `json:"data"`
 }
 
-// HandleScanResources handles scan resource tasks
-func HandleScanResources(db *gorm.DB) func(ctx context.Context, t *asynq.Task) error {
+// HandleScanResources handles scan resource tasks, running the scan the API
+// created with the scan use case. Its progress is published to the live
+// event stream while it runs. Once the use case set its final status, its
+// webhook delivery, Slack message and alerts are queued and the values
+// cached for the organization are dropped, see finishScan. A nil Slack
+// client posts no message.
+func HandleScanResources(db *gorm.DB, scans *usecase.ScanResourcesUseCase, client *asynq.Client, hooks *webhook.Client, slackClient *slack.Client, bus *events.Bus, results *cache.Cache) func(ctx context.Context, t *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) error {
 		var payload ScanResourcesPayload
 		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
@@ -50,29 +76,249 @@ func HandleScanResources(db *gorm.DB) func(ctx context.Context, t *asynq.Task) e
 
 		log.Printf("Processing scan task for org %s, provider %s", payload.OrganizationID, payload.Provider)
 
-		// TODO: Implement actual scanning logic using use cases
-		// This is a placeholder that will be implemented later
+		if retried, _ := asynq.GetRetryCount(ctx); retried == 0 && !payload.QueuedAt.IsZero() {
+			recordScanWait(payload.OrganizationID, payload.QueuedAt)
+		}
 
+		scanID, err := uuid.Parse(payload.ScanID)
+		if err != nil {
+			return fmt.Errorf("invalid scan ID %q: %w", payload.ScanID, asynq.SkipRetry)
+		}
+		orgID, err := uuid.Parse(payload.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID %q: %w", payload.OrganizationID, asynq.SkipRetry)
+		}
+
+		// Scans queued before their organization was deactivated are dropped
+		var org model.Organization
+		if err := db.WithContext(ctx).Select("is_active", "plan").First(&org, "id = ?", orgID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("organization %s not found: %w", orgID, asynq.SkipRetry)
+			}
+			return fmt.Errorf("failed to load organization %s: %w", orgID, err)
+		}
+		if !org.IsActive {
+			log.Printf("Skipping scan %s: organization %s is deactivated", payload.ScanID, payload.OrganizationID)
+			return nil
+		}
+
+		credentials, err := scanCredentials(ctx, db, orgID, payload.Provider)
+		if err != nil {
+			return err
+		}
+		_, err = scans.Execute(ctx, usecase.ScanResourcesInput{
+			ScanID:      scanID,
+			Credentials: credentials,
+			Plan:        org.Plan,
+			Progress: func(done, total int) {
+				bus.Publish(ctx, payload.OrganizationID, events.TypeScanProgress, events.ScanProgress{
+					ScanID:         payload.ScanID,
+					Status:         string(entity.ScanStatusRunning),
+					ResourcesFound: done,
+				})
+			},
+		})
+		if errors.Is(err, usecase.ErrScanFinished) {
+			log.Printf("Skipping scan %s: %v", payload.ScanID, err)
+			return nil
+		}
+		if err != nil {
+			log.Printf("Scan %s failed: %v", payload.ScanID, err)
+		}
+
+		finished := finishScan(ctx, db, client, hooks, slackClient, bus, results, payload.OrganizationID, payload.ScanID)
+		switch {
+		case err != nil && finished:
+			// The scan was marked as failed, users start a new one
+			return fmt.Errorf("scan %s failed: %w", payload.ScanID, errors.Join(err, asynq.SkipRetry))
+		case err != nil:
+			return fmt.Errorf("scan %s failed before it ran: %w", payload.ScanID, err)
+		}
 		return nil
 	}
 }
 
-// HandleCleanupResources handles cleanup resource tasks
-func HandleCleanupResources(db *gorm.DB) func(ctx context.Context, t *asynq.Task) error {
+// finishScan publishes the status the scan use case left a scan in and
+// drops the values cached for its organization. Once the scan is final, its
+// webhook delivery, Slack message, alerts and the assignment of the owners
+// of its resources are queued. It reports whether the scan is final.
+func finishScan(ctx context.Context, db *gorm.DB, client *asynq.Client, hooks *webhook.Client, slackClient *slack.Client, bus *events.Bus, results *cache.Cache, orgID, scanID string) bool {
+	var scan model.Scan
+	if err := db.WithContext(ctx).Select("status", "resources_found", "unused_found").First(&scan, "id = ?", scanID).Error; err != nil {
+		log.Printf("Failed to load scan %s: %v", scanID, err)
+		return false
+	}
+	results.Invalidate(ctx, orgID)
+	bus.Publish(ctx, orgID, events.TypeScanProgress, events.ScanProgress{
+		ScanID:         scanID,
+		Status:         scan.Status,
+		ResourcesFound: scan.ResourcesFound,
+		UnusedFound:    scan.UnusedFound,
+	})
+	if !scanFinished(scan.Status) {
+		return false
+	}
+
+	if hooks.Enabled() {
+		if err := EnqueueScanWebhook(ctx, client, scanID); err != nil {
+			log.Printf("Failed to queue webhook for scan %s: %v", scanID, err)
+		}
+	}
+	if slackClient != nil {
+		var installed int64
+		db.WithContext(ctx).Model(&model.SlackInstallation{}).Where("organization_id = ?", orgID).Count(&installed)
+		if installed > 0 {
+			if err := EnqueueSlackScanMessage(ctx, client, scanID); err != nil {
+				log.Printf("Failed to queue Slack message for scan %s: %v", scanID, err)
+			}
+		}
+	}
+	if err := EnqueueScanAlerts(ctx, db, client, scanID); err != nil {
+		log.Printf("Failed to queue alerts for scan %s: %v", scanID, err)
+	}
+	if err := EnqueueOwnerAssignment(ctx, client, orgID); err != nil {
+		log.Printf("Failed to queue owner assignment for org %s: %v", orgID, err)
+	}
+	return true
+}
+
+// scanFinished reports whether a scan status is final
+func scanFinished(status string) bool {
+	switch entity.ScanStatus(status) {
+	case entity.ScanStatusCompleted, entity.ScanStatusPartial, entity.ScanStatusFailed, entity.ScanStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// HandleCleanupResources handles cleanup resource tasks with the cleanup use
+// case. Resources are cleaned up account by account with the credentials of
+// their cloud account, never in the regions their organization denylisted,
+// and deletions back volumes, disks and databases up first when the task
+// asks for it. The result is published to the live event stream and the
+// values cached for the organization are dropped. The task fails without
+// retry when any resource could not be cleaned up, so that a cleanup is never
+// run on the provider again unless requested.
+func HandleCleanupResources(orgs repository.OrganizationRepository, resources repository.ResourceRepository, accounts repository.CloudAccountRepository, cleanups *usecase.CleanupResourcesUseCase, bus *events.Bus, results *cache.Cache) func(ctx context.Context, t *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) error {
 		var payload CleanupResourcesPayload
 		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
 			return fmt.Errorf("failed to unmarshal payload: %w", err)
 		}
+		orgID, err := uuid.Parse(payload.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID %q: %w", payload.OrganizationID, asynq.SkipRetry)
+		}
+		ids := make([]uuid.UUID, len(payload.ResourceIDs))
+		for i, id := range payload.ResourceIDs {
+			if ids[i], err = uuid.Parse(id); err != nil {
+				return fmt.Errorf("invalid resource ID %q: %w", id, asynq.SkipRetry)
+			}
+		}
 
 		log.Printf("Processing cleanup task for org %s, %d resources", payload.OrganizationID, len(payload.ResourceIDs))
 
-		// TODO: Implement actual cleanup logic using use cases
+		org, err := orgs.GetByID(ctx, orgID)
+		if apperrors.Is(err, apperrors.ErrNotFound) {
+			return fmt.Errorf("organization %s not found: %w", orgID, asynq.SkipRetry)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+		found, err := resources.List(ctx, repository.ResourceFilter{OrganizationID: &orgID, IDs: ids})
+		if err != nil {
+			return fmt.Errorf("failed to list resources: %w", err)
+		}
+		orgAccounts, err := accounts.List(ctx, repository.CloudAccountFilter{OrganizationIDs: []uuid.UUID{orgID}})
+		if err != nil {
+			return fmt.Errorf("failed to list cloud accounts: %w", err)
+		}
+
+		type accountKey struct {
+			provider  entity.CloudProvider
+			accountID string
+		}
+		credentials := make(map[accountKey][]byte)
+		for _, a := range orgAccounts {
+			if a.IsActive {
+				credentials[accountKey{provider: a.Provider, accountID: a.AccountID}] = a.Credentials
+			}
+		}
+
+		var failed []*service.CleanupResult
+		byAccount := make(map[accountKey][]uuid.UUID)
+		var regions []string
+		for _, r := range found {
+			key := accountKey{provider: r.Provider, accountID: r.AccountID}
+			if _, ok := credentials[key]; !ok {
+				failed = append(failed, &service.CleanupResult{
+					ResourceID:   r.ID.String(),
+					ErrorMessage: fmt.Sprintf("no active %s account %s", r.Provider, r.AccountID),
+				})
+				continue
+			}
+			byAccount[key] = append(byAccount[key], r.ID)
+			regions = append(regions, r.Region)
+		}
+		for _, id := range ids {
+			if !containsResource(found, id) {
+				failed = append(failed, &service.CleanupResult{ResourceID: id.String(), ErrorMessage: "resource not found"})
+			}
+		}
 
+		taskID, _ := asynq.GetTaskID(ctx)
+		denied := org.Settings.DeniedRegions(regions)
+		outcomes := failed
+		var errs []error
+		for key, accountIDs := range byAccount {
+			out, err := cleanups.Execute(ctx, usecase.CleanupResourcesInput{
+				OrganizationID:  orgID,
+				ResourceIDs:     accountIDs,
+				Action:          entity.PolicyAction(payload.Action),
+				Credentials:     credentials[key],
+				DryRun:          payload.DryRun,
+				TaskID:          taskID,
+				DeniedRegions:   denied,
+				Backup:          payload.Backup,
+				BackupRetention: time.Duration(payload.BackupRetentionDays) * 24 * time.Hour,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to clean up resources of %s account %s: %w", key.provider, key.accountID, err))
+				continue
+			}
+			outcomes = append(outcomes, out.Results...)
+		}
+
+		if !payload.DryRun {
+			results.Invalidate(ctx, payload.OrganizationID)
+		}
+		bus.Publish(ctx, payload.OrganizationID, events.TypeCleanupResult, events.CleanupResult{
+			TaskID:    taskID,
+			Action:    payload.Action,
+			DryRun:    payload.DryRun,
+			Resources: len(payload.ResourceIDs),
+		})
+
+		if err := resultsError("clean up", outcomes); err != nil {
+			errs = append(errs, err)
+		}
+		if err := errors.Join(errs...); err != nil {
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
 		return nil
 	}
 }
 
+// containsResource returns whether the resource of an ID is among resources
+func containsResource(resources []*entity.Resource, id uuid.UUID) bool {
+	for _, r := range resources {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // HandleApplyPolicy handles policy application tasks
 func HandleApplyPolicy(db *gorm.DB) func(ctx context.Context, t *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) error {
@@ -89,8 +335,9 @@ func HandleApplyPolicy(db *gorm.DB) func(ctx context.Context, t *asynq.Task) err
 	}
 }
 
-// HandleSendNotification handles notification tasks
-func HandleSendNotification(db *gorm.DB) func(ctx context.Context, t *asynq.Task) error {
+// HandleSendNotification handles notification tasks. Invitations are
+// emailed through the mailer.
+func HandleSendNotification(db *gorm.DB, mailer notification.Mailer) func(ctx context.Context, t *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) error {
 		var payload SendNotificationPayload
 		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
@@ -99,7 +346,11 @@ func HandleSendNotification(db *gorm.DB) func(ctx context.Context, t *asynq.Task
 
 		log.Printf("Sending %s notification to %s", payload.Type, payload.To)
 
-		// TODO: Implement notification sending (email, Slack, etc.)
+		if payload.Type == NotificationTypeInvitation {
+			return mailer.Send(ctx, invitationMessage(payload))
+		}
+
+		// TODO: Implement the other notifications (Slack, etc.)
 
 		return nil
 	}
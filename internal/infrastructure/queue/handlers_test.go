@@ -0,0 +1,196 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+)
+
+// fakeOrgs is an OrganizationRepository serving a single organization
+type fakeOrgs struct {
+	repository.OrganizationRepository
+	org *entity.Organization
+}
+
+func (f *fakeOrgs) GetByID(ctx context.Context, id uuid.UUID) (*entity.Organization, error) {
+	return f.org, nil
+}
+
+// fakeResources is a ResourceRepository keeping resources in memory
+type fakeResources struct {
+	repository.ResourceRepository
+	resources map[uuid.UUID]*entity.Resource
+}
+
+func (f *fakeResources) GetByID(ctx context.Context, id uuid.UUID) (*entity.Resource, error) {
+	r, ok := f.resources[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return r, nil
+}
+
+func (f *fakeResources) List(ctx context.Context, filter repository.ResourceFilter) ([]*entity.Resource, error) {
+	var out []*entity.Resource
+	for _, id := range filter.IDs {
+		if r, ok := f.resources[id]; ok && r.OrganizationID == *filter.OrganizationID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeResources) Update(ctx context.Context, resource *entity.Resource) error {
+	f.resources[resource.ID] = resource
+	return nil
+}
+
+// fakeAccounts is a CloudAccountRepository listing fixed accounts
+type fakeAccounts struct {
+	accounts []*entity.CloudAccount
+}
+
+func (f *fakeAccounts) List(ctx context.Context, filter repository.CloudAccountFilter) ([]*entity.CloudAccount, error) {
+	return f.accounts, nil
+}
+
+// fakeCleaner records the calls made to it, its backups completing at once
+type fakeCleaner struct {
+	service.ResourceCleaner
+	credentials []byte
+	calls       []string
+	tags        map[string]string
+}
+
+func (c *fakeCleaner) Create(provider entity.CloudProvider, credentials []byte) (service.ResourceCleaner, error) {
+	c.credentials = credentials
+	return c, nil
+}
+
+func (c *fakeCleaner) Provider() entity.CloudProvider {
+	return entity.CloudProviderAWS
+}
+
+func (c *fakeCleaner) Delete(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	c.calls = append(c.calls, "delete")
+	return &service.CleanupResult{ResourceID: resource.ID.String(), Success: true, Action: entity.PolicyActionDelete}, nil
+}
+
+func (c *fakeCleaner) Backup(ctx context.Context, resource *entity.Resource, snapshotID string, tags map[string]string) (*service.CleanupResult, error) {
+	c.calls = append(c.calls, "backup")
+	c.tags = tags
+	return &service.CleanupResult{ResourceID: resource.ID.String(), Success: true}, nil
+}
+
+func (c *fakeCleaner) BackupState(ctx context.Context, resource *entity.Resource, snapshotID string) (service.BackupState, error) {
+	c.calls = append(c.calls, "backup state")
+	return service.BackupStateCompleted, nil
+}
+
+// cleanupFixture is an organization with an unused volume in eu-west-1 of
+// its AWS account
+type cleanupFixture struct {
+	org       *entity.Organization
+	volume    *entity.Resource
+	resources *fakeResources
+	cleaner   *fakeCleaner
+	handler   func(ctx context.Context, t *asynq.Task) error
+}
+
+func newCleanupFixture(settings entity.OrganizationSettings) *cleanupFixture {
+	org := &entity.Organization{ID: uuid.New(), Plan: "enterprise", IsActive: true, Settings: settings}
+	volume := &entity.Resource{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Provider:       entity.CloudProviderAWS,
+		AccountID:      "123456789012",
+		Region:         "eu-west-1",
+		Type:           entity.ResourceTypeEBSVolume,
+		Status:         entity.ResourceStatusUnused,
+		MonthlyCost:    8,
+	}
+	account := entity.NewCloudAccount(org.ID, entity.CloudProviderAWS, volume.AccountID, "production")
+	account.Credentials = []byte("production-credentials")
+
+	f := &cleanupFixture{
+		org:       org,
+		volume:    volume,
+		resources: &fakeResources{resources: map[uuid.UUID]*entity.Resource{volume.ID: volume}},
+		cleaner:   &fakeCleaner{},
+	}
+	cleanups := usecase.NewCleanupResourcesUseCase(f.resources, nil, nil, f.cleaner, nil, nil, nil, nil)
+	f.handler = HandleCleanupResources(&fakeOrgs{org: org}, f.resources, &fakeAccounts{accounts: []*entity.CloudAccount{account}}, cleanups, nil, nil)
+	return f
+}
+
+// run processes a cleanup task of the volume
+func (f *cleanupFixture) run(payload CleanupResourcesPayload) error {
+	payload.OrganizationID = f.org.ID.String()
+	payload.ResourceIDs = []string{f.volume.ID.String()}
+	data, _ := json.Marshal(payload)
+	return f.handler(context.Background(), asynq.NewTask(TaskTypeCleanupResources, data))
+}
+
+func TestHandleCleanupResourcesBackupThenDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		backup bool
+		calls  []string
+	}{
+		{name: "backup then delete", backup: true, calls: []string{"backup", "backup state", "delete"}},
+		{name: "delete", calls: []string{"delete"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCleanupFixture(entity.OrganizationSettings{})
+			deleteAfter := time.Now().AddDate(0, 0, 30).UTC().Format("2006-01-02")
+
+			err := f.run(CleanupResourcesPayload{Action: string(entity.PolicyActionDelete), Backup: tt.backup, BackupRetentionDays: 30})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(f.cleaner.calls, tt.calls) {
+				t.Errorf("calls = %v, want %v", f.cleaner.calls, tt.calls)
+			}
+			if string(f.cleaner.credentials) != "production-credentials" {
+				t.Errorf("credentials = %q, want those of the account", f.cleaner.credentials)
+			}
+			if f.volume.Status != entity.ResourceStatusDeleted {
+				t.Errorf("status = %s, want deleted", f.volume.Status)
+			}
+			if !tt.backup {
+				return
+			}
+			if got := f.cleaner.tags[service.BackupTagDeleteAfter]; got != deleteAfter {
+				t.Errorf("backup kept until %s, want %s", got, deleteAfter)
+			}
+		})
+	}
+}
+
+func TestHandleCleanupResourcesPreflight(t *testing.T) {
+	f := newCleanupFixture(entity.OrganizationSettings{RegionDenylist: []string{"eu-*"}})
+
+	err := f.run(CleanupResourcesPayload{Action: string(entity.PolicyActionDelete), Backup: true})
+	if !errors.Is(err, asynq.SkipRetry) || !strings.Contains(err.Error(), "preflight failed: region eu-west-1 is denylisted") {
+		t.Fatalf("err = %v, want the denylisted region failing without retry", err)
+	}
+	if len(f.cleaner.calls) != 0 {
+		t.Errorf("calls = %v, want none", f.cleaner.calls)
+	}
+	if f.volume.Status != entity.ResourceStatusUnused {
+		t.Errorf("status = %s, want unused", f.volume.Status)
+	}
+}
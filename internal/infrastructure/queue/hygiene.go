@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/hygiene"
+	"github.com/hibiken/asynq"
+)
+
+// HandleRecordHygiene handles the daily recording of organization hygiene
+// scores
+func HandleRecordHygiene(scorer *hygiene.Scorer) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		recorded, err := scorer.Record(ctx, time.Now())
+		log.Printf("Hygiene scores: %d organizations scored", recorded)
+		return err
+	}
+}
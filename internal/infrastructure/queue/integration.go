@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/discovery"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// SyncIntegrationsPayload represents the payload for an integration sync
+// task. The periodic task has no integration and syncs all of them.
+type SyncIntegrationsPayload struct {
+	IntegrationID string `json:"integration_id,omitempty"`
+}
+
+// EnqueueIntegrationSync queues the sync of an integration, unless one is
+// already queued
+func EnqueueIntegrationSync(ctx context.Context, client *asynq.Client, integrationID string) error {
+	payload, _ := json.Marshal(SyncIntegrationsPayload{IntegrationID: integrationID})
+	task := NewTask(TaskTypeSyncIntegrations, payload, asynq.Queue(QueueLow), asynq.Unique(10*time.Minute))
+	if _, err := client.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		return err
+	}
+	return nil
+}
+
+// HandleSyncIntegrations handles the discovery of the accounts of
+// organization-level integrations such as AWS Organizations
+func HandleSyncIntegrations(syncer *discovery.Syncer) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload SyncIntegrationsPayload
+		if len(t.Payload()) > 0 {
+			if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+				return fmt.Errorf("failed to unmarshal payload: %w", err)
+			}
+		}
+
+		if payload.IntegrationID != "" {
+			id, err := uuid.Parse(payload.IntegrationID)
+			if err != nil {
+				return fmt.Errorf("invalid integration ID %q: %w", payload.IntegrationID, asynq.SkipRetry)
+			}
+			return syncer.Sync(ctx, id)
+		}
+
+		synced, err := syncer.SyncAll(ctx)
+		log.Printf("Integrations: %d synced", synced)
+		if err != nil {
+			// Errors are recorded on the failing integrations, which the next
+			// run tries again
+			return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+		}
+		return nil
+	}
+}
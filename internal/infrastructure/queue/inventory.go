@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// InventoryQueue queues the restores and owner assignments following
+// changes of the inventory made through the API
+type InventoryQueue struct {
+	client *asynq.Client
+}
+
+// NewInventoryQueue creates an InventoryQueue
+func NewInventoryQueue(client *asynq.Client) *InventoryQueue {
+	return &InventoryQueue{client: client}
+}
+
+var _ service.InventoryQueue = (*InventoryQueue)(nil)
+
+// EnqueueRestore implements service.InventoryQueue
+func (q *InventoryQueue) EnqueueRestore(ctx context.Context, orgID, resourceID uuid.UUID) error {
+	return EnqueueRestoreResource(ctx, q.client, orgID, resourceID)
+}
+
+// EnqueueOwnerAssignment implements service.InventoryQueue
+func (q *InventoryQueue) EnqueueOwnerAssignment(ctx context.Context, orgID uuid.UUID) error {
+	return EnqueueOwnerAssignment(ctx, q.client, orgID.String())
+}
@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/hibiken/asynq"
+)
+
+// Notification types
+const (
+	// NotificationTypeInvitation invites someone to join an organization.
+	// Its data holds the organization, role, invited_by, link and
+	// expires_at of the invitation.
+	NotificationTypeInvitation = "invitation"
+)
+
+// EnqueueNotification queues a notification
+func EnqueueNotification(ctx context.Context, client *asynq.Client, payload SendNotificationPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = client.EnqueueContext(ctx, NewTask(TaskTypeSendNotification, data))
+	return err
+}
+
+// invitationMessage renders the email of an invitation
+func invitationMessage(payload SendNotificationPayload) notification.Message {
+	str := func(key string) string {
+		s, _ := payload.Data[key].(string)
+		return s
+	}
+	inviter := str("invited_by")
+	if inviter == "" {
+		inviter = "An administrator"
+	}
+
+	return notification.Message{
+		To:      []string{payload.To},
+		Subject: payload.Subject,
+		Text: fmt.Sprintf("%s invited you to join %s on CloudSweep as %s.\n\n"+
+			"Accept the invitation: %s\n\n"+
+			"This link expires on %s. If you were not expecting this invitation, you can ignore this email.\n",
+			inviter, str("organization"), str("role"), str("link"), str("expires_at")),
+	}
+}
@@ -0,0 +1,192 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// offHoursSyncInterval is how often policy changes reach the off-hours
+// schedule
+const offHoursSyncInterval = time.Minute
+
+// SchedulePolicyPayload represents the payload of the stop and start tasks
+// of a "schedule" policy
+type SchedulePolicyPayload struct {
+	OrganizationID string `json:"organization_id"`
+	PolicyID       string `json:"policy_id"`
+}
+
+// offHoursConfigs provides the stop and start tasks of every enabled
+// "schedule" policy, each in the policy's timezone
+type offHoursConfigs struct {
+	db *gorm.DB
+}
+
+func (p *offHoursConfigs) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	var policies []model.Policy
+	err := p.db.Where("is_enabled = ? AND jsonb_exists(actions, ?)", true, string(entity.PolicyActionSchedule)).
+		Find(&policies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule policies: %w", err)
+	}
+
+	var configs []*asynq.PeriodicTaskConfig
+	for _, policy := range policies {
+		s := policy.OffHoursSchedule()
+		if s == nil || s.Validate() != nil {
+			log.Printf("Skipping off-hours schedule of policy %s: invalid schedule", policy.ID)
+			continue
+		}
+		payload, err := json.Marshal(SchedulePolicyPayload{
+			OrganizationID: policy.OrganizationID.String(),
+			PolicyID:       policy.ID.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs,
+			&asynq.PeriodicTaskConfig{
+				Cronspec: fmt.Sprintf("CRON_TZ=%s %s", s.Timezone, s.Stop),
+				Task:     NewTask(TaskTypeStopSchedule, payload, asynq.Unique(time.Hour)),
+			},
+			&asynq.PeriodicTaskConfig{
+				Cronspec: fmt.Sprintf("CRON_TZ=%s %s", s.Timezone, s.Start),
+				Task:     NewTask(TaskTypeStartSchedule, payload, asynq.Unique(time.Hour)),
+			},
+		)
+	}
+	return configs, nil
+}
+
+// NewOffHoursManager creates the manager enqueuing the stop and start tasks
+// of "schedule" policies. Policies are re-read every minute, so created,
+// edited and disabled policies take effect without restarting the worker.
+func NewOffHoursManager(cfg config.RedisConfig, db *gorm.DB) (*asynq.PeriodicTaskManager, error) {
+	return asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		RedisConnOpt:               redisOpt(cfg),
+		PeriodicTaskConfigProvider: &offHoursConfigs{db: db},
+		SchedulerOpts:              &asynq.SchedulerOpts{Location: time.UTC},
+		SyncInterval:               offHoursSyncInterval,
+	})
+}
+
+// HandleStopSchedule handles the tasks stopping the resources of a
+// "schedule" policy when its stop window opens
+func HandleStopSchedule(db *gorm.DB, schedules *usecase.OffHoursScheduleUseCase) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		payload, ok, err := loadSchedulePolicy(ctx, db, t)
+		if err != nil || !ok {
+			return err
+		}
+
+		log.Printf("Stopping resources of schedule policy %s for org %s", payload.PolicyID, payload.OrganizationID)
+
+		return runSchedule(ctx, db, payload, "stop", schedules.Stop)
+	}
+}
+
+// HandleStartSchedule handles the tasks restarting the resources a
+// "schedule" policy stopped, once its start window opens. Only resources
+// passing the restart safety check are started.
+func HandleStartSchedule(db *gorm.DB, schedules *usecase.OffHoursScheduleUseCase) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		payload, ok, err := loadSchedulePolicy(ctx, db, t)
+		if err != nil || !ok {
+			return err
+		}
+
+		log.Printf("Starting resources of schedule policy %s for org %s", payload.PolicyID, payload.OrganizationID)
+
+		return runSchedule(ctx, db, payload, "start", schedules.Start)
+	}
+}
+
+// runSchedule runs the stop or start of a schedule policy on every active
+// cloud account of its provider, with the account's credentials. The task
+// fails when any resource could not be stopped or started; resources
+// skipped as unsafe to restart do not fail it.
+func runSchedule(ctx context.Context, db *gorm.DB, payload SchedulePolicyPayload, action string, run func(context.Context, usecase.OffHoursScheduleInput) (*usecase.OffHoursScheduleOutput, error)) error {
+	orgID, err := uuid.Parse(payload.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("invalid organization ID %q: %w", payload.OrganizationID, asynq.SkipRetry)
+	}
+	policyID, err := uuid.Parse(payload.PolicyID)
+	if err != nil {
+		return fmt.Errorf("invalid policy ID %q: %w", payload.PolicyID, asynq.SkipRetry)
+	}
+
+	var accounts []model.CloudAccount
+	err = db.WithContext(ctx).Select("account_id", "credentials").
+		Joins("JOIN policies ON policies.provider = cloud_accounts.provider AND policies.organization_id = cloud_accounts.organization_id").
+		Where("policies.id = ? AND cloud_accounts.organization_id = ? AND cloud_accounts.is_active = ?", policyID, orgID, true).
+		Find(&accounts).Error
+	if err != nil {
+		return fmt.Errorf("failed to list cloud accounts of org %s: %w", orgID, err)
+	}
+
+	var errs []error
+	for _, account := range accounts {
+		output, err := run(ctx, usecase.OffHoursScheduleInput{
+			OrganizationID: orgID,
+			PolicyID:       policyID,
+			AccountID:      account.AccountID,
+			Credentials:    account.Credentials,
+		})
+		if errors.Is(err, service.ErrReadOnly) {
+			log.Printf("Skipping %s of schedule policy %s: %v", action, policyID, err)
+			return nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to %s resources of account %s: %w", action, account.AccountID, err))
+			continue
+		}
+		log.Printf("Schedule policy %s %s on account %s: %d succeeded, %d failed, %d skipped",
+			policyID, action, account.AccountID, output.SuccessCount, output.FailureCount, output.SkippedCount)
+
+		// Resources skipped as unsafe to restart are listed with the
+		// failures, but do not fail the task alone
+		if output.FailureCount > 0 {
+			errs = append(errs, resultsError(action, output.Results))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadSchedulePolicy decodes a schedule task and checks its policy is still
+// an enabled "schedule" policy. ok is false when the policy was deleted,
+// disabled or changed since the task was queued, and the task is dropped.
+func loadSchedulePolicy(ctx context.Context, db *gorm.DB, t *asynq.Task) (SchedulePolicyPayload, bool, error) {
+	var payload SchedulePolicyPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return payload, false, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	var policy model.Policy
+	err := db.WithContext(ctx).First(&policy, "id = ? AND organization_id = ?", payload.PolicyID, payload.OrganizationID).Error
+	if err == gorm.ErrRecordNotFound {
+		log.Printf("Skipping %s: policy %s not found", t.Type(), payload.PolicyID)
+		return payload, false, nil
+	}
+	if err != nil {
+		return payload, false, fmt.Errorf("failed to get policy: %w", err)
+	}
+	if !policy.IsEnabled || !slices.Contains(policy.Actions, string(entity.PolicyActionSchedule)) {
+		log.Printf("Skipping %s: policy %s is no longer an enabled schedule policy", t.Type(), payload.PolicyID)
+		return payload, false, nil
+	}
+	return payload, true, nil
+}
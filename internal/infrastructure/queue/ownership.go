@@ -0,0 +1,60 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ownership"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// AssignOwnersPayload represents the payload for the owner assignment of
+// the resources of an organization
+type AssignOwnersPayload struct {
+	OrganizationID string `json:"organization_id"`
+}
+
+// EnqueueOwnerAssignment queues the owner assignment of the resources of an
+// organization, unless one is already queued
+func EnqueueOwnerAssignment(ctx context.Context, client *asynq.Client, orgID string) error {
+	payload, _ := json.Marshal(AssignOwnersPayload{OrganizationID: orgID})
+	task := NewTask(TaskTypeAssignOwners, payload, asynq.Queue(QueueLow), asynq.Unique(time.Minute))
+	if _, err := client.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		return err
+	}
+	return nil
+}
+
+// HandleAssignOwners handles owner assignment tasks, run after scans and
+// changes of the owner mapping file or account owners
+func HandleAssignOwners(resolver *ownership.Resolver) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload AssignOwnersPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		orgID, err := uuid.Parse(payload.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID %q: %w", payload.OrganizationID, asynq.SkipRetry)
+		}
+
+		changed, err := resolver.Assign(ctx, orgID)
+		log.Printf("Owners: %d resources of org %s changed owner", changed, orgID)
+		return err
+	}
+}
+
+// HandleSendOwnerNotifications handles the daily digests of the owners of
+// the resources of policies with the notify action
+func HandleSendOwnerNotifications(notifier *ownership.Notifier) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		sent, err := notifier.SendDigests(ctx)
+		log.Printf("Owner notifications: %d digests sent", sent)
+		return err
+	}
+}
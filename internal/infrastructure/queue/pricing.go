@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/pricing"
+	"github.com/hibiken/asynq"
+)
+
+// HandleRefreshPricing handles the periodic refresh of the price catalog
+// scans estimate costs from
+func HandleRefreshPricing(refresher *pricing.Refresher) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		stored, err := refresher.Refresh(ctx, time.Now())
+		log.Printf("Pricing: %d instance prices stored", stored)
+		return err
+	}
+}
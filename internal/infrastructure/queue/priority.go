@@ -0,0 +1,54 @@
+package queue
+
+import (
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// Priority queues. Workers pull from each queue in proportion to its
+// weight, so low tasks still run while critical ones are waiting.
+const (
+	QueueCritical = "critical" // approved deletions, manual scans, restores
+	QueueDefault  = "default"  // scheduled scans and other cleanups
+	QueueLow      = "low"      // digests, reports, exports and maintenance
+)
+
+var priorities = []string{QueueCritical, QueueDefault, QueueLow}
+
+// plans have their own scan queue at each priority
+var plans = []string{entity.PlanFree, entity.PlanPro, entity.PlanEnterprise}
+
+// PlanQueue returns the queue of the scans of a plan at a priority.
+// Unknown plans share the queues of the free plan.
+func PlanQueue(priority, plan string) string {
+	for _, p := range plans {
+		if p == plan {
+			return priority + ":" + plan
+		}
+	}
+	return priority + ":" + entity.PlanFree
+}
+
+// CleanupQueue returns the queue of a cleanup: deletions approved by a
+// user jump ahead of the other actions and of dry runs
+func CleanupQueue(action string, dryRun bool) string {
+	if action == string(entity.PolicyActionDelete) && !dryRun {
+		return QueueCritical
+	}
+	return QueueDefault
+}
+
+// Queues returns the queues a worker pulls from with their weights. The
+// scan queue of a plan weighs the weight of its priority multiplied by the
+// weight of the plan.
+func Queues(cfg config.WorkerConfig) map[string]int {
+	queues := make(map[string]int, len(priorities)*(len(plans)+1))
+	for _, priority := range priorities {
+		weight := max(cfg.QueueWeights[priority], 1)
+		queues[priority] = weight
+		for _, plan := range plans {
+			queues[PlanQueue(priority, plan)] = weight * max(cfg.PlanWeights[plan], 1)
+		}
+	}
+	return queues
+}
@@ -0,0 +1,159 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// RestoreResourcePayload represents the payload of a task restoring a
+// quarantined resource
+type RestoreResourcePayload struct {
+	OrganizationID string `json:"organization_id"`
+	ResourceID     string `json:"resource_id"`
+}
+
+// HandlePurgeQuarantine handles the periodic task deleting quarantined
+// resources whose window has ended. Resources are purged account by account
+// with the credentials of their cloud account; the task fails when any of
+// them could not be, and they are tried again by the next run.
+func HandlePurgeQuarantine(db *gorm.DB, quarantine *usecase.QuarantineUseCase) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var expired []model.Resource
+		err := db.WithContext(ctx).Select("id", "organization_id", "provider", "account_id").
+			Where("quarantine_until <= ? AND status <> ?", time.Now(), entity.ResourceStatusDeleted).
+			Find(&expired).Error
+		if err != nil {
+			return fmt.Errorf("failed to list expired quarantines: %w", err)
+		}
+
+		type accountKey struct {
+			orgID     uuid.UUID
+			provider  string
+			accountID string
+		}
+		byAccount := make(map[accountKey][]uuid.UUID)
+		for _, r := range expired {
+			key := accountKey{orgID: r.OrganizationID, provider: r.Provider, accountID: r.AccountID}
+			byAccount[key] = append(byAccount[key], r.ID)
+		}
+
+		var errs []error
+		for key, ids := range byAccount {
+			log.Printf("Purging %d quarantined resources of %s account %s for org %s", len(ids), key.provider, key.accountID, key.orgID)
+
+			credentials, err := accountCredentials(ctx, db, key.orgID, key.provider, key.accountID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			output, err := quarantine.Purge(ctx, usecase.QuarantineInput{
+				OrganizationID: key.orgID,
+				ResourceIDs:    ids,
+				Credentials:    credentials,
+			})
+			if errors.Is(err, service.ErrReadOnly) {
+				log.Printf("Skipping purge of quarantined resources for org %s: %v", key.orgID, err)
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to purge quarantined resources of org %s: %w", key.orgID, err))
+				continue
+			}
+			if err := resultsError("purge", output.Results); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+// HandleRestoreResource handles tasks bringing a quarantined resource back
+// into service, with the credentials of its cloud account
+func HandleRestoreResource(db *gorm.DB, quarantine *usecase.QuarantineUseCase) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload RestoreResourcePayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		orgID, err := uuid.Parse(payload.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID %q: %w", payload.OrganizationID, asynq.SkipRetry)
+		}
+		resourceID, err := uuid.Parse(payload.ResourceID)
+		if err != nil {
+			return fmt.Errorf("invalid resource ID %q: %w", payload.ResourceID, asynq.SkipRetry)
+		}
+
+		log.Printf("Restoring quarantined resource %s for org %s", resourceID, orgID)
+
+		var resource model.Resource
+		err = db.WithContext(ctx).Select("id", "provider", "account_id").
+			First(&resource, "id = ? AND organization_id = ?", resourceID, orgID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("resource %s not found in org %s: %w", resourceID, orgID, asynq.SkipRetry)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get resource: %w", err)
+		}
+
+		credentials, err := accountCredentials(ctx, db, orgID, resource.Provider, resource.AccountID)
+		if err != nil {
+			return err
+		}
+		output, err := quarantine.Restore(ctx, usecase.QuarantineInput{
+			OrganizationID: orgID,
+			ResourceIDs:    []uuid.UUID{resourceID},
+			Credentials:    credentials,
+		})
+		if err != nil {
+			if errors.Is(err, service.ErrReadOnly) {
+				return fmt.Errorf("failed to restore resource %s: %v: %w", resourceID, err, asynq.SkipRetry)
+			}
+			return fmt.Errorf("failed to restore resource %s: %w", resourceID, err)
+		}
+		return resultsError("restore", output.Results)
+	}
+}
+
+// resultsError returns an error listing the resources an action failed on,
+// nil when it succeeded on every one
+func resultsError(action string, results []*service.CleanupResult) error {
+	var failed []string
+	for _, r := range results {
+		if !r.Success {
+			failed = append(failed, r.ResourceID+": "+r.ErrorMessage)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to %s %d resources: %s", action, len(failed), strings.Join(failed, "; "))
+}
+
+// EnqueueRestoreResource queues the restore of a quarantined resource. A
+// restore already queued for the resource is kept.
+func EnqueueRestoreResource(ctx context.Context, client *asynq.Client, orgID, resourceID uuid.UUID) error {
+	payload, _ := json.Marshal(RestoreResourcePayload{
+		OrganizationID: orgID.String(),
+		ResourceID:     resourceID.String(),
+	})
+	task := NewTask(TaskTypeRestoreResource, payload, asynq.Queue(QueueCritical), asynq.TaskID(TaskTypeRestoreResource+":"+resourceID.String()))
+	if _, err := client.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+	return nil
+}
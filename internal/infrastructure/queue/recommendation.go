@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/recommendation"
+	"github.com/hibiken/asynq"
+)
+
+// HandleGenerateRecommendations handles the daily generation of
+// recommendations
+func HandleGenerateRecommendations(generator *recommendation.Generator) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		stored, err := generator.Generate(ctx, time.Now())
+		log.Printf("Recommendations: %d recommendations stored", stored)
+		return err
+	}
+}
@@ -0,0 +1,21 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/retention"
+	"github.com/hibiken/asynq"
+)
+
+// HandlePurgeExpiredHistory handles the daily purge of the history older
+// than the retention of each organization: finished scans, hygiene scores
+// and resources deleted since
+func HandlePurgeExpiredHistory(history *retention.Purger) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		purged, err := history.Purge(ctx, time.Now())
+		log.Printf("History retention: %d scans, %d hygiene scores and %d deleted resources purged", purged["scans"], purged["hygiene_scores"], purged["resources"])
+		return err
+	}
+}
@@ -0,0 +1,81 @@
+package queue
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/hibiken/asynq"
+)
+
+// RetryPolicy defines how a task type is retried on failure
+type RetryPolicy struct {
+	MaxRetry  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var defaultRetryPolicy = RetryPolicy{MaxRetry: 3, BaseDelay: 30 * time.Second, MaxDelay: 10 * time.Minute}
+
+// retryPolicies holds per-task-type retry settings. Scans are cheap to
+// retry, cleanups mutate cloud state and are retried conservatively, and
+// notifications and webhooks tolerate long outages of the receiving side.
+// Digests and summaries are not retried so nobody gets the same email twice.
+// Off-hours stops and starts are only worth retrying within their window.
+var retryPolicies = map[string]RetryPolicy{
+	TaskTypeScanResources:       {MaxRetry: 5, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute},
+	TaskTypeCleanupResources:    {MaxRetry: 3, BaseDelay: time.Minute, MaxDelay: 30 * time.Minute},
+	TaskTypeApplyPolicy:         {MaxRetry: 3, BaseDelay: time.Minute, MaxDelay: 30 * time.Minute},
+	TaskTypeSendNotification:    {MaxRetry: 10, BaseDelay: 10 * time.Second, MaxDelay: time.Hour},
+	TaskTypeGenerateExport:      {MaxRetry: 2, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+	TaskTypeSendOwnerDigest:     {MaxRetry: 0, BaseDelay: time.Minute, MaxDelay: time.Minute},
+	TaskTypeSendWeeklySummaries: {MaxRetry: 0, BaseDelay: time.Minute, MaxDelay: time.Minute},
+	TaskTypeDeliverScanWebhook:  {MaxRetry: 10, BaseDelay: 30 * time.Second, MaxDelay: time.Hour},
+	TaskTypePostSlackScan:       {MaxRetry: 10, BaseDelay: 30 * time.Second, MaxDelay: time.Hour},
+	TaskTypeSendAlert:           {MaxRetry: 10, BaseDelay: 10 * time.Second, MaxDelay: 30 * time.Minute},
+	TaskTypeSendPolicyMatches:   {MaxRetry: 10, BaseDelay: 10 * time.Second, MaxDelay: 30 * time.Minute},
+	TaskTypeStopSchedule:        {MaxRetry: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+	TaskTypeStartSchedule:       {MaxRetry: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+	TaskTypeRestoreResource:     {MaxRetry: 5, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute},
+	TaskTypeRollbackCleanup:     {MaxRetry: 5, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute},
+}
+
+// RetryPolicyFor returns the retry policy for a task type
+func RetryPolicyFor(taskType string) RetryPolicy {
+	if p, ok := retryPolicies[taskType]; ok {
+		return p
+	}
+	return defaultRetryPolicy
+}
+
+// NewTask creates a task with the retry policy of its type applied.
+// Additional options override the defaults.
+func NewTask(taskType string, payload []byte, opts ...asynq.Option) *asynq.Task {
+	policy := RetryPolicyFor(taskType)
+	opts = append([]asynq.Option{asynq.MaxRetry(policy.MaxRetry)}, opts...)
+	return asynq.NewTask(taskType, payload, opts...)
+}
+
+// retryDelay computes an exponential backoff with jitter for the n-th retry.
+// Tasks waiting for a concurrency slot try again after the delay of the
+// limit.
+func retryDelay(n int, err error, t *asynq.Task) time.Duration {
+	policy := RetryPolicyFor(t.Type())
+
+	var busy *ratelimit.BusyError
+	if errors.As(err, &busy) {
+		return busy.RetryAfter + time.Duration(rand.Int63n(int64(busy.RetryAfter)/5+1))
+	}
+
+	delay := policy.MaxDelay
+	if n < 30 {
+		if d := policy.BaseDelay << uint(n); d > 0 && d < policy.MaxDelay {
+			delay = d
+		}
+	}
+
+	// Up to 20% jitter to avoid retry storms after a shared outage
+	jitter := time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	return delay + jitter
+}
@@ -0,0 +1,105 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// RollbackCleanupPayload represents the payload of a task undoing the
+// action a cleanup ran on a resource
+type RollbackCleanupPayload struct {
+	OrganizationID string `json:"organization_id"`
+	ResourceID     string `json:"resource_id"`
+	// CleanupTaskID is the task of the cleanup whose action is undone
+	CleanupTaskID string            `json:"cleanup_task_id"`
+	Recovery      *service.Recovery `json:"recovery"`
+	Actor         string            `json:"actor,omitempty"`
+}
+
+// HandleRollbackCleanup handles tasks undoing the action a cleanup ran on a
+// resource, with the credentials of its cloud account. The rollback is
+// recorded in the timeline of the resource whether it succeeded or not, and
+// the task fails when it did not.
+func HandleRollbackCleanup(db *gorm.DB, rollbacks *usecase.RollbackCleanupUseCase) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload RollbackCleanupPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		orgID, err := uuid.Parse(payload.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID %q: %w", payload.OrganizationID, asynq.SkipRetry)
+		}
+		resourceID, err := uuid.Parse(payload.ResourceID)
+		if err != nil {
+			return fmt.Errorf("invalid resource ID %q: %w", payload.ResourceID, asynq.SkipRetry)
+		}
+		if payload.Recovery == nil {
+			return fmt.Errorf("no recovery recorded for cleanup %s of resource %s: %w", payload.CleanupTaskID, resourceID, asynq.SkipRetry)
+		}
+
+		log.Printf("Rolling back cleanup %s of resource %s for org %s", payload.CleanupTaskID, resourceID, orgID)
+
+		var resource model.Resource
+		err = db.WithContext(ctx).Select("id", "provider", "account_id").
+			First(&resource, "id = ? AND organization_id = ?", resourceID, orgID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("resource %s not found in org %s: %w", resourceID, orgID, asynq.SkipRetry)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get resource: %w", err)
+		}
+		credentials, err := accountCredentials(ctx, db, orgID, resource.Provider, resource.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result, err := rollbacks.Execute(ctx, usecase.RollbackCleanupInput{
+			OrganizationID: orgID,
+			ResourceID:     resourceID,
+			Credentials:    credentials,
+			TaskID:         payload.CleanupTaskID,
+			Recovery:       payload.Recovery,
+			Actor:          payload.Actor,
+		})
+		var manual *service.ManualRecoveryError
+		if errors.Is(err, service.ErrReadOnly) || errors.As(err, &manual) {
+			return fmt.Errorf("failed to roll back cleanup %s of resource %s: %v: %w", payload.CleanupTaskID, resourceID, err, asynq.SkipRetry)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to roll back cleanup %s of resource %s: %w", payload.CleanupTaskID, resourceID, err)
+		}
+		if !result.Success {
+			return fmt.Errorf("failed to roll back cleanup %s of resource %s: %s", payload.CleanupTaskID, resourceID, result.ErrorMessage)
+		}
+		return nil
+	}
+}
+
+// EnqueueRollbackCleanup queues the rollback of the action a cleanup task
+// ran on a resource. A rollback already queued for them is kept.
+func EnqueueRollbackCleanup(ctx context.Context, client *asynq.Client, orgID, resourceID uuid.UUID, cleanupTaskID string, recovery *service.Recovery, actor string) error {
+	payload, _ := json.Marshal(RollbackCleanupPayload{
+		OrganizationID: orgID.String(),
+		ResourceID:     resourceID.String(),
+		CleanupTaskID:  cleanupTaskID,
+		Recovery:       recovery,
+		Actor:          actor,
+	})
+	id := TaskTypeRollbackCleanup + ":" + cleanupTaskID + ":" + resourceID.String()
+	task := NewTask(TaskTypeRollbackCleanup, payload, asynq.Queue(QueueCritical), asynq.TaskID(id))
+	if _, err := client.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+	return nil
+}
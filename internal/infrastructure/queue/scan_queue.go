@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/jobs"
+	"github.com/hibiken/asynq"
+)
+
+// ScanQueue queues scan tasks, interleaving the scans of organizations
+type ScanQueue struct {
+	client *asynq.Client
+	fair   *FairScheduler
+	jobs   *jobs.Tracker
+}
+
+// NewScanQueue creates a ScanQueue. fair may be nil, in which case scans are
+// processed in the order they are queued. Each queued scan is registered as
+// a job with tracker, unless it is nil.
+func NewScanQueue(client *asynq.Client, fair *FairScheduler, tracker *jobs.Tracker) *ScanQueue {
+	return &ScanQueue{client: client, fair: fair, jobs: tracker}
+}
+
+var _ service.ScanQueue = (*ScanQueue)(nil)
+
+// Enqueue implements service.ScanQueue. Scans go to the queue of the plan,
+// critical for those users asked for and default for scheduled ones.
+// Deduplicated scans are queued under the task ID of their fingerprint.
+func (q *ScanQueue) Enqueue(ctx context.Context, scan *entity.Scan, plan string, scheduled, dedupe bool) error {
+	resourceTypes := make([]string, len(scan.ResourceTypes))
+	for i, t := range scan.ResourceTypes {
+		resourceTypes[i] = string(t)
+	}
+	payload, _ := json.Marshal(ScanResourcesPayload{
+		ScanID:         scan.ID.String(),
+		OrganizationID: scan.OrganizationID.String(),
+		Provider:       string(scan.Provider),
+		Regions:        scan.Regions,
+		ResourceTypes:  resourceTypes,
+		Incremental:    scan.Mode == entity.ScanModeIncremental,
+		QueuedAt:       time.Now(),
+	})
+
+	priority := QueueCritical
+	if scheduled {
+		priority = QueueDefault
+	}
+	opts := append(q.fair.ScanOptions(ctx, scan.OrganizationID.String(), plan), asynq.Queue(PlanQueue(priority, plan)))
+	if dedupe {
+		opts = append(opts, asynq.TaskID(ScanTaskID(scan.Fingerprint)))
+	}
+	_, _, err := q.jobs.Enqueue(ctx, q.client, scan.OrganizationID, entity.JobTypeScan, &scan.ID, NewTask(TaskTypeScanResources, payload), opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return service.ErrScanAlreadyQueued
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/hibiken/asynq"
+)
+
+// NewScheduler creates the scheduler enqueuing periodic tasks. Every worker
+// replica runs one; the Unique option keeps a single task per period.
+func NewScheduler(cfg config.RedisConfig, digestCfg config.DigestConfig, summaryCfg config.SummaryConfig, auditCfg config.AuditConfig, hygieneCfg config.HygieneConfig, recommendationsCfg config.RecommendationsConfig, quarantineCfg config.QuarantineConfig, ciCfg config.CIConfig, gitopsCfg config.GitOpsConfig, plansCfg config.PlansConfig, allocationCfg config.AllocationConfig, integrationsCfg config.IntegrationsConfig, billingCfg config.BillingConfig, pricingCfg config.PricingConfig, ticketingCfg config.TicketingConfig, ownersCfg config.OwnersConfig) (*asynq.Scheduler, error) {
+	scheduler := asynq.NewScheduler(redisOpt(cfg), &asynq.SchedulerOpts{Location: time.UTC})
+
+	if digestCfg.Enabled {
+		task := NewTask(TaskTypeSendOwnerDigest, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(digestCfg.Schedule, task); err != nil {
+			return nil, fmt.Errorf("invalid digest schedule %q: %w", digestCfg.Schedule, err)
+		}
+	}
+
+	if summaryCfg.Enabled {
+		task := NewTask(TaskTypeSendWeeklySummaries, nil, asynq.Queue(QueueLow), asynq.Unique(30*time.Minute))
+		if _, err := scheduler.Register(summarySchedule, task); err != nil {
+			return nil, err
+		}
+	}
+
+	if auditCfg.PurgeSchedule != "" {
+		task := NewTask(TaskTypePurgeProviderCalls, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(auditCfg.PurgeSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid audit purge schedule %q: %w", auditCfg.PurgeSchedule, err)
+		}
+	}
+
+	if hygieneCfg.Schedule != "" {
+		task := NewTask(TaskTypeRecordHygiene, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(hygieneCfg.Schedule, task); err != nil {
+			return nil, fmt.Errorf("invalid hygiene schedule %q: %w", hygieneCfg.Schedule, err)
+		}
+	}
+
+	if recommendationsCfg.Schedule != "" {
+		task := NewTask(TaskTypeGenerateRecommendations, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(recommendationsCfg.Schedule, task); err != nil {
+			return nil, fmt.Errorf("invalid recommendations schedule %q: %w", recommendationsCfg.Schedule, err)
+		}
+	}
+
+	if quarantineCfg.PurgeSchedule != "" {
+		task := NewTask(TaskTypePurgeQuarantine, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(quarantineCfg.PurgeSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid quarantine purge schedule %q: %w", quarantineCfg.PurgeSchedule, err)
+		}
+	}
+
+	if ciCfg.Schedule != "" {
+		task := NewTask(TaskTypeDetectPreviewEnvs, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(ciCfg.Schedule, task); err != nil {
+			return nil, fmt.Errorf("invalid ci schedule %q: %w", ciCfg.Schedule, err)
+		}
+	}
+
+	if gitopsCfg.SyncSchedule != "" {
+		task := NewTask(TaskTypeSyncIaCChanges, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(gitopsCfg.SyncSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid gitops sync schedule %q: %w", gitopsCfg.SyncSchedule, err)
+		}
+	}
+
+	if plansCfg.RetentionSchedule != "" {
+		task := NewTask(TaskTypePurgeExpiredHistory, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(plansCfg.RetentionSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid plan retention schedule %q: %w", plansCfg.RetentionSchedule, err)
+		}
+	}
+
+	if allocationCfg.Schedule != "" {
+		task := NewTask(TaskTypeRefreshAllocation, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(allocationCfg.Schedule, task); err != nil {
+			return nil, fmt.Errorf("invalid allocation schedule %q: %w", allocationCfg.Schedule, err)
+		}
+	}
+
+	if integrationsCfg.SyncSchedule != "" {
+		task := NewTask(TaskTypeSyncIntegrations, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(integrationsCfg.SyncSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid integrations sync schedule %q: %w", integrationsCfg.SyncSchedule, err)
+		}
+	}
+
+	if billingCfg.Schedule != "" {
+		task := NewTask(TaskTypeReconcileBillingCosts, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(billingCfg.Schedule, task); err != nil {
+			return nil, fmt.Errorf("invalid billing schedule %q: %w", billingCfg.Schedule, err)
+		}
+	}
+
+	if pricingCfg.RefreshSchedule != "" {
+		task := NewTask(TaskTypeRefreshPricing, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(pricingCfg.RefreshSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid pricing refresh schedule %q: %w", pricingCfg.RefreshSchedule, err)
+		}
+	}
+
+	if ticketingCfg.SyncSchedule != "" {
+		task := NewTask(TaskTypeSyncTickets, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(ticketingCfg.SyncSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid ticketing sync schedule %q: %w", ticketingCfg.SyncSchedule, err)
+		}
+	}
+
+	if ownersCfg.DigestSchedule != "" {
+		task := NewTask(TaskTypeSendOwnerNotifications, nil, asynq.Queue(QueueLow), asynq.Unique(time.Hour))
+		if _, err := scheduler.Register(ownersCfg.DigestSchedule, task); err != nil {
+			return nil, fmt.Errorf("invalid owners digest schedule %q: %w", ownersCfg.DigestSchedule, err)
+		}
+	}
+
+	return scheduler, nil
+}
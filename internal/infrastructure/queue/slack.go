@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/slack"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// PostSlackScanPayload represents the payload of a scan message task
+type PostSlackScanPayload struct {
+	ScanID string `json:"scan_id"`
+}
+
+// EnqueueSlackScanMessage queues the Slack message of a finished scan
+func EnqueueSlackScanMessage(ctx context.Context, client *asynq.Client, scanID string) error {
+	payload, _ := json.Marshal(PostSlackScanPayload{ScanID: scanID})
+	task := NewTask(TaskTypePostSlackScan, payload, asynq.TaskID(TaskTypePostSlackScan+":"+scanID))
+	if _, err := client.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+	return nil
+}
+
+// HandlePostSlackScanMessage handles scan message tasks: the outcome of the
+// scan is posted to the channel of the Slack installation of its
+// organization, with buttons to preview and approve its cleanup
+func HandlePostSlackScanMessage(db *gorm.DB, client *slack.Client) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload PostSlackScanPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		if client == nil {
+			return nil
+		}
+
+		var scan model.Scan
+		if err := db.WithContext(ctx).First(&scan, "id = ?", payload.ScanID).Error; err != nil {
+			return fmt.Errorf("failed to load scan %s: %v: %w", payload.ScanID, err, asynq.SkipRetry)
+		}
+		var installation model.SlackInstallation
+		if err := db.WithContext(ctx).First(&installation, "organization_id = ?", scan.OrganizationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if installation.ChannelID == "" {
+			return nil
+		}
+
+		msg := slack.ScanMessage(slack.ScanSummary{
+			ScanID:           scan.ID.String(),
+			Provider:         scan.Provider,
+			Status:           scan.Status,
+			ResourcesFound:   scan.ResourcesFound,
+			UnusedFound:      scan.UnusedFound,
+			EstimatedSavings: scan.EstimatedSavings,
+			ErrorMessage:     scan.ErrorMessage,
+		})
+		log.Printf("Posting scan %s to Slack channel %s of team %s", scan.ID, installation.ChannelID, installation.TeamID)
+		return client.PostMessage(ctx, installation.AccessToken, installation.ChannelID, msg)
+	}
+}
@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/summary"
+	"github.com/hibiken/asynq"
+)
+
+// summarySchedule is when the worker looks for organizations whose weekly
+// summary is due, each in its own timezone
+const summarySchedule = "0 * * * *"
+
+// HandleSendWeeklySummaries handles the hourly task sending the weekly
+// summaries due in the hour
+func HandleSendWeeklySummaries(sender *summary.Sender) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		res, err := sender.Run(ctx, time.Now())
+		if res.Organizations > 0 || err != nil {
+			log.Printf("Weekly summary: %d organizations, %d sent, %d failed", res.Organizations, res.Sent, res.Failed)
+		}
+
+		// Retrying would email again the admins who already got their summary
+		if err != nil && res.Sent > 0 {
+			log.Printf("Weekly summary completed with errors: %v", err)
+			return nil
+		}
+		return err
+	}
+}
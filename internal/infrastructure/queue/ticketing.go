@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"context"
+	"log"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ticketing"
+	"github.com/hibiken/asynq"
+)
+
+// HandleSyncTickets handles the periodic update of the Jira issues and
+// ServiceNow change requests opened for resources, closing those whose
+// resource was remediated
+func HandleSyncTickets(tracker *ticketing.Tracker) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		closed, err := tracker.Sync(ctx)
+		log.Printf("Tickets: %d closed", closed)
+		return err
+	}
+}
@@ -0,0 +1,241 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/webhook"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// EventScanFinished is sent once a scan is completed, partial, failed or
+// cancelled
+const EventScanFinished = "scan.finished"
+
+// DeliverScanWebhookPayload represents the payload for a scan webhook task
+type DeliverScanWebhookPayload struct {
+	ScanID string `json:"scan_id"`
+}
+
+// ScanWebhookEvent is the body POSTed to the scan webhook
+type ScanWebhookEvent struct {
+	Event  string             `json:"event"`
+	SentAt time.Time          `json:"sent_at"`
+	Scan   ScanWebhookSummary `json:"scan"`
+	Export *ScanWebhookExport `json:"export,omitempty"`
+}
+
+// ScanWebhookSummary summarizes a finished scan
+type ScanWebhookSummary struct {
+	ID               string            `json:"id"`
+	OrganizationID   string            `json:"organization_id"`
+	Provider         string            `json:"provider"`
+	Regions          []string          `json:"regions"`
+	ResourceTypes    []string          `json:"resource_types"`
+	Status           string            `json:"status"`
+	ResourcesFound   int               `json:"resources_found"`
+	UnusedFound      int               `json:"unused_found"`
+	ResourcesNew     int               `json:"resources_new"`
+	ResourcesChanged int               `json:"resources_changed"`
+	ResourcesRemoved int               `json:"resources_removed"`
+	EstimatedSavings float64           `json:"estimated_savings"`
+	CarbonSavings    float64           `json:"carbon_savings_kg"`
+	FailedRegions    map[string]string `json:"failed_regions,omitempty"`
+	ErrorMessage     string            `json:"error_message,omitempty"`
+	StartedAt        *time.Time        `json:"started_at"`
+	CompletedAt      *time.Time        `json:"completed_at"`
+}
+
+// ScanWebhookExport links to the full scan result
+type ScanWebhookExport struct {
+	ID        string    `json:"id"`
+	Format    string    `json:"format"`
+	RowCount  int       `json:"row_count"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EnqueueScanWebhook queues the webhook delivery of a finished scan
+func EnqueueScanWebhook(ctx context.Context, client *asynq.Client, scanID string) error {
+	payload, _ := json.Marshal(DeliverScanWebhookPayload{ScanID: scanID})
+	task := NewTask(TaskTypeDeliverScanWebhook, payload, asynq.TaskID(TaskTypeDeliverScanWebhook+":"+scanID))
+	if _, err := client.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+	return nil
+}
+
+// HandleDeliverScanWebhook handles scan webhook tasks. The result export is
+// generated once and reused by retries; only the link is signed again.
+// Events identical to one delivered within the window of suppressor, but
+// for the scan and its times, are dropped.
+func HandleDeliverScanWebhook(db *gorm.DB, store storage.ObjectStore, hooks *webhook.Client, linkTTL time.Duration, suppressor *notification.Suppressor) func(ctx context.Context, t *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload DeliverScanWebhookPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		if !hooks.Enabled() {
+			return nil
+		}
+
+		var scan model.Scan
+		if err := db.WithContext(ctx).First(&scan, "id = ?", payload.ScanID).Error; err != nil {
+			return fmt.Errorf("failed to load scan %s: %v: %w", payload.ScanID, err, asynq.SkipRetry)
+		}
+
+		event := ScanWebhookEvent{
+			Event:  EventScanFinished,
+			SentAt: time.Now().UTC(),
+			Scan:   toScanWebhookSummary(&scan),
+		}
+
+		fingerprint := scanWebhookFingerprint(event.Scan)
+		first, err := suppressor.Claim(ctx, "webhook", fingerprint)
+		if err != nil {
+			log.Printf("Failed to check for duplicate webhook events: %v", err)
+		}
+		if !first {
+			log.Printf("Dropping duplicate scan webhook for scan %s (%s)", scan.ID, scan.Status)
+			return nil
+		}
+		// An event that could not be delivered does not hold back retries
+		delivered := false
+		defer func() {
+			if !delivered {
+				suppressor.Release(ctx, "webhook", fingerprint)
+			}
+		}()
+
+		switch entity.ScanStatus(scan.Status) {
+		case entity.ScanStatusCompleted, entity.ScanStatusPartial:
+			export, err := scanResultExport(ctx, db, store, &scan)
+			if err != nil {
+				return err
+			}
+			url, err := store.SignedURL(ctx, export.ObjectKey, linkTTL)
+			if err != nil {
+				return fmt.Errorf("failed to sign export link: %w", err)
+			}
+			event.Export = &ScanWebhookExport{
+				ID:        export.ID.String(),
+				Format:    export.Format,
+				RowCount:  export.RowCount,
+				URL:       url,
+				ExpiresAt: time.Now().Add(linkTTL).UTC(),
+			}
+		case entity.ScanStatusFailed, entity.ScanStatusCancelled:
+		default:
+			return fmt.Errorf("scan %s is still %s: %w", scan.ID, scan.Status, asynq.SkipRetry)
+		}
+
+		log.Printf("Delivering scan webhook for scan %s (%s)", scan.ID, scan.Status)
+
+		if err := hooks.Post(ctx, EventScanFinished, event); err != nil {
+			if !webhook.Retryable(err) {
+				return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+			}
+			return err
+		}
+		delivered = true
+		return nil
+	}
+}
+
+// scanResultExport returns the JSON export of the resources found by the
+// scan, generating it on first use
+func scanResultExport(ctx context.Context, db *gorm.DB, store storage.ObjectStore, scan *model.Scan) (*model.Export, error) {
+	var export model.Export
+	err := db.WithContext(ctx).
+		Where("scan_id = ? AND status = ?", scan.ID, string(entity.ExportStatusCompleted)).
+		First(&export).Error
+	if err == nil {
+		return &export, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load scan export: %w", err)
+	}
+
+	scanID := scan.ID
+	now := time.Now()
+	export = model.Export{
+		ID:             uuid.New(),
+		OrganizationID: scan.OrganizationID,
+		ScanID:         &scanID,
+		Type:           string(entity.ExportTypeResources),
+		Format:         string(entity.ExportFormatJSON),
+		Filters:        model.JSONB{"provider": scan.Provider},
+		Status:         string(entity.ExportStatusRunning),
+		StartedAt:      &now,
+	}
+	if err := db.WithContext(ctx).Create(&export).Error; err != nil {
+		return nil, fmt.Errorf("failed to create scan export: %w", err)
+	}
+
+	if err := generateExport(ctx, db, store, &export); err != nil {
+		db.Model(&export).Updates(map[string]any{
+			"status":        string(entity.ExportStatusFailed),
+			"error_message": err.Error(),
+		})
+		return nil, err
+	}
+
+	completed := time.Now()
+	export.Status = string(entity.ExportStatusCompleted)
+	export.CompletedAt = &completed
+	err = db.Model(&export).Updates(map[string]any{
+		"status":       export.Status,
+		"object_key":   export.ObjectKey,
+		"row_count":    export.RowCount,
+		"size_bytes":   export.SizeBytes,
+		"completed_at": &completed,
+	}).Error
+	return &export, err
+}
+
+// scanWebhookFingerprint identifies the scan webhook events of identical
+// outcome, leaving out the scan and its times
+func scanWebhookFingerprint(summary ScanWebhookSummary) string {
+	summary.ID = ""
+	summary.StartedAt = nil
+	summary.CompletedAt = nil
+	return notification.Fingerprint(summary)
+}
+
+func toScanWebhookSummary(s *model.Scan) ScanWebhookSummary {
+	summary := ScanWebhookSummary{
+		ID:               s.ID.String(),
+		OrganizationID:   s.OrganizationID.String(),
+		Provider:         s.Provider,
+		Regions:          s.Regions,
+		ResourceTypes:    s.ResourceTypes,
+		Status:           s.Status,
+		ResourcesFound:   s.ResourcesFound,
+		UnusedFound:      s.UnusedFound,
+		ResourcesNew:     s.ResourcesNew,
+		ResourcesChanged: s.ResourcesChanged,
+		ResourcesRemoved: s.ResourcesRemoved,
+		EstimatedSavings: s.EstimatedSavings,
+		CarbonSavings:    s.CarbonSavings,
+		ErrorMessage:     s.ErrorMessage,
+		StartedAt:        s.StartedAt,
+		CompletedAt:      s.CompletedAt,
+	}
+	if len(s.FailedRegions) > 0 {
+		summary.FailedRegions = make(map[string]string, len(s.FailedRegions))
+		for region, msg := range s.FailedRegions {
+			summary.FailedRegions[region] = fmt.Sprint(msg)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,290 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	workersKey      = "cloudsweep:workers"
+	workerKeyPrefix = "cloudsweep:workers:"
+)
+
+// ActiveTask is a task a worker is processing
+type ActiveTask struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Queue     string    `json:"queue"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// WorkerStatus is the state of a worker process, as published in its
+// heartbeats
+type WorkerStatus struct {
+	ID            string       `json:"id"`
+	Hostname      string       `json:"hostname"`
+	PID           int          `json:"pid"`
+	Version       string       `json:"version"`
+	StartedAt     time.Time    `json:"started_at"`
+	LastHeartbeat time.Time    `json:"last_heartbeat"`
+	Concurrency   int          `json:"concurrency"`
+	Active        int          `json:"active"`
+	Tasks         []ActiveTask `json:"tasks"`
+	Processed     int64        `json:"processed"`
+	Failed        int64        `json:"failed"`
+	// ScanWaits is the scan queue wait of each organization whose scans
+	// this worker started within the last heartbeat window
+	ScanWaits map[string]WaitStats `json:"scan_queue_wait,omitempty"`
+}
+
+// TaskTracker records the tasks a worker is processing and how many it has
+// processed. It is safe for concurrent use.
+type TaskTracker struct {
+	mu        sync.Mutex
+	active    map[*ActiveTask]struct{}
+	processed atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewTaskTracker creates a new TaskTracker
+func NewTaskTracker() *TaskTracker {
+	return &TaskTracker{active: map[*ActiveTask]struct{}{}}
+}
+
+// Middleware tracks the tasks processed by the handlers of a ServeMux
+func (t *TaskTracker) Middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		id, _ := asynq.GetTaskID(ctx)
+		queue, _ := asynq.GetQueueName(ctx)
+		entry := &ActiveTask{ID: id, Type: task.Type(), Queue: queue, StartedAt: time.Now().UTC()}
+
+		t.mu.Lock()
+		t.active[entry] = struct{}{}
+		t.mu.Unlock()
+		defer func() {
+			t.mu.Lock()
+			delete(t.active, entry)
+			t.mu.Unlock()
+		}()
+
+		err := next.ProcessTask(ctx, task)
+		t.processed.Add(1)
+		if err != nil {
+			t.failed.Add(1)
+		}
+		return err
+	})
+}
+
+// Active returns the tasks being processed, oldest first
+func (t *TaskTracker) Active() []ActiveTask {
+	t.mu.Lock()
+	tasks := make([]ActiveTask, 0, len(t.active))
+	for entry := range t.active {
+		tasks = append(tasks, *entry)
+	}
+	t.mu.Unlock()
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].StartedAt.Before(tasks[j].StartedAt) })
+	return tasks
+}
+
+// WorkerRegistry keeps the status of the running workers in Redis. Each
+// worker's status expires unless it is refreshed by a heartbeat, so workers
+// that stopped without deregistering disappear from the fleet.
+type WorkerRegistry struct {
+	client *redis.Client
+}
+
+// NewWorkerRegistry creates a registry keeping worker statuses in Redis
+func NewWorkerRegistry(client *redis.Client) *WorkerRegistry {
+	return &WorkerRegistry{client: client}
+}
+
+// Publish stores the status of a worker for ttl
+func (r *WorkerRegistry) Publish(ctx context.Context, status WorkerStatus, ttl time.Duration) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, workerKeyPrefix+status.ID, data, ttl)
+		pipe.SAdd(ctx, workersKey, status.ID)
+		return nil
+	})
+	return err
+}
+
+// Remove deregisters a worker
+func (r *WorkerRegistry) Remove(ctx context.Context, id string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, workerKeyPrefix+id)
+		pipe.SRem(ctx, workersKey, id)
+		return nil
+	})
+	return err
+}
+
+// List returns the status of the running workers, by hostname. Workers
+// whose status expired are removed from the registry.
+func (r *WorkerRegistry) List(ctx context.Context) ([]WorkerStatus, error) {
+	ids, err := r.client.SMembers(ctx, workersKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workers: %w", err)
+	}
+	if len(ids) == 0 {
+		return []WorkerStatus{}, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = workerKeyPrefix + id
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worker statuses: %w", err)
+	}
+
+	workers := make([]WorkerStatus, 0, len(ids))
+	var expired []any
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		var status WorkerStatus
+		if err := json.Unmarshal([]byte(data), &status); err != nil {
+			log.Printf("Ignoring invalid status of worker %s: %v", ids[i], err)
+			continue
+		}
+		workers = append(workers, status)
+	}
+	if len(expired) > 0 {
+		if err := r.client.SRem(ctx, workersKey, expired...).Err(); err != nil {
+			log.Printf("Failed to remove expired workers: %v", err)
+		}
+	}
+
+	sort.Slice(workers, func(i, j int) bool {
+		if workers[i].Hostname != workers[j].Hostname {
+			return workers[i].Hostname < workers[j].Hostname
+		}
+		return workers[i].ID < workers[j].ID
+	})
+	return workers, nil
+}
+
+// Heartbeat periodically publishes the status of the worker process
+type Heartbeat struct {
+	registry *WorkerRegistry
+	tracker  *TaskTracker
+	interval time.Duration
+	status   WorkerStatus
+
+	concurrency atomic.Int64
+	last        atomic.Int64 // unix nanoseconds of the last published heartbeat
+}
+
+// NewHeartbeat creates the heartbeat of this worker process
+func NewHeartbeat(registry *WorkerRegistry, tracker *TaskTracker, concurrency int, interval time.Duration, version string) *Heartbeat {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now().UTC()
+	h := &Heartbeat{
+		registry: registry,
+		tracker:  tracker,
+		interval: interval,
+		status: WorkerStatus{
+			ID:        fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), now.Unix()),
+			Hostname:  hostname,
+			PID:       os.Getpid(),
+			Version:   version,
+			StartedAt: now,
+		},
+	}
+	h.concurrency.Store(int64(concurrency))
+	return h
+}
+
+// SetConcurrency changes the concurrency reported by the heartbeats, after
+// the task server was restarted with it
+func (h *Heartbeat) SetConcurrency(concurrency int) {
+	h.concurrency.Store(int64(concurrency))
+}
+
+// Status returns the current status of the worker
+func (h *Heartbeat) Status() WorkerStatus {
+	status := h.status
+	status.LastHeartbeat = h.LastBeat()
+	status.Concurrency = int(h.concurrency.Load())
+	status.Tasks = h.tracker.Active()
+	status.Active = len(status.Tasks)
+	status.Processed = h.tracker.processed.Load()
+	status.Failed = h.tracker.failed.Load()
+	status.ScanWaits = ScanWaitStats()
+	return status
+}
+
+// LastBeat returns when the last heartbeat was published, zero if none was
+func (h *Heartbeat) LastBeat() time.Time {
+	last := h.last.Load()
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last).UTC()
+}
+
+// Healthy reports whether a heartbeat was published recently: a worker
+// missing two heartbeats in a row is considered gone
+func (h *Heartbeat) Healthy() bool {
+	last := h.LastBeat()
+	return !last.IsZero() && time.Since(last) <= 3*h.interval
+}
+
+// Run publishes a heartbeat every interval until ctx is done, then
+// deregisters the worker. A heartbeat that cannot be published is retried
+// at the next interval.
+func (h *Heartbeat) Run(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.beat(ctx)
+		select {
+		case <-ctx.Done():
+			removeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := h.registry.Remove(removeCtx, h.status.ID); err != nil {
+				log.Printf("Failed to deregister worker %s: %v", h.status.ID, err)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Heartbeat) beat(ctx context.Context) {
+	now := time.Now().UTC()
+	status := h.Status()
+	status.LastHeartbeat = now
+	if err := h.registry.Publish(ctx, status, 3*h.interval); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to publish worker heartbeat: %v", err)
+		}
+		return
+	}
+	h.last.Store(now.UnixNano())
+	// Wait stats live as long as the status they are published in
+	expireScanWaits(now.Add(-3 * h.interval))
+}
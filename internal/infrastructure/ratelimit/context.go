@@ -0,0 +1,27 @@
+package ratelimit
+
+import "context"
+
+type limiterKey struct{}
+
+// WithLimiter returns a context carrying the limiter of the account being
+// worked on
+func WithLimiter(ctx context.Context, l *Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, l)
+}
+
+// FromContext returns the limiter carried by ctx, if any
+func FromContext(ctx context.Context) (*Limiter, bool) {
+	l, ok := ctx.Value(limiterKey{}).(*Limiter)
+	return l, ok
+}
+
+// Call runs a single cloud API call through the limiter carried by ctx.
+// Scanner and cleaner implementations wrap every SDK call with it; without a
+// limiter in ctx the call runs directly.
+func Call(ctx context.Context, fn func(context.Context) error) error {
+	if l, ok := FromContext(ctx); ok {
+		return l.Do(ctx, fn)
+	}
+	return fn(ctx)
+}
@@ -0,0 +1,223 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/clientpool"
+)
+
+// ScannerFactory attaches the account's limiter to the context of every
+// call made on the scanners created by the wrapped factory
+type ScannerFactory struct {
+	next     service.CloudScannerFactory
+	registry *Registry
+}
+
+// NewScannerFactory wraps a CloudScannerFactory with the registry
+func NewScannerFactory(next service.CloudScannerFactory, registry *Registry) *ScannerFactory {
+	return &ScannerFactory{next: next, registry: registry}
+}
+
+// Create returns a rate limited scanner
+func (f *ScannerFactory) Create(provider entity.CloudProvider, credentials []byte) (service.CloudScanner, error) {
+	scanner, err := f.next.Create(provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return f.limit(provider, credentials, scanner), nil
+}
+
+// CreateWithCustomTypes returns a rate limited scanner also detecting the
+// custom resource types, when the wrapped factory supports them
+func (f *ScannerFactory) CreateWithCustomTypes(provider entity.CloudProvider, credentials []byte, types []*entity.CustomResourceType) (service.CloudScanner, error) {
+	next, ok := f.next.(service.CustomScannerFactory)
+	if !ok {
+		if len(types) > 0 {
+			return nil, fmt.Errorf("custom resource types are not supported for %s", provider)
+		}
+		return f.Create(provider, credentials)
+	}
+	scanner, err := next.CreateWithCustomTypes(provider, credentials, types)
+	if err != nil {
+		return nil, err
+	}
+	return f.limit(provider, credentials, scanner), nil
+}
+
+func (f *ScannerFactory) limit(provider entity.CloudProvider, credentials []byte, scanner service.CloudScanner) service.CloudScanner {
+	key := Key{Provider: provider, Account: clientpool.AccountKey(credentials)}
+	return &limitedScanner{CloudScanner: scanner, limiter: f.registry.Get(key)}
+}
+
+type limitedScanner struct {
+	service.CloudScanner
+	limiter *Limiter
+}
+
+func (s *limitedScanner) ScanRegion(ctx context.Context, region string, resourceTypes []entity.ResourceType) ([]*entity.Resource, error) {
+	return s.CloudScanner.ScanRegion(WithLimiter(ctx, s.limiter), region, resourceTypes)
+}
+
+func (s *limitedScanner) StreamRegion(ctx context.Context, region string, resourceTypes []entity.ResourceType, emit chan<- *entity.Resource) error {
+	return service.StreamRegion(WithLimiter(ctx, s.limiter), s.CloudScanner, region, resourceTypes, emit)
+}
+
+func (s *limitedScanner) DetectUnused(ctx context.Context, resources []*entity.Resource) error {
+	return s.CloudScanner.DetectUnused(WithLimiter(ctx, s.limiter), resources)
+}
+
+func (s *limitedScanner) EstimateCost(ctx context.Context, resource *entity.Resource) (entity.Cost, error) {
+	return s.CloudScanner.EstimateCost(WithLimiter(ctx, s.limiter), resource)
+}
+
+// CleanerFactory attaches the account's limiter to the context of every
+// call made on the cleaners created by the wrapped factory
+type CleanerFactory struct {
+	next     service.ResourceCleanerFactory
+	registry *Registry
+}
+
+// NewCleanerFactory wraps a ResourceCleanerFactory with the registry
+func NewCleanerFactory(next service.ResourceCleanerFactory, registry *Registry) *CleanerFactory {
+	return &CleanerFactory{next: next, registry: registry}
+}
+
+// Create returns a rate limited cleaner
+func (f *CleanerFactory) Create(provider entity.CloudProvider, credentials []byte) (service.ResourceCleaner, error) {
+	cleaner, err := f.next.Create(provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+	key := Key{Provider: provider, Account: clientpool.AccountKey(credentials)}
+	return &limitedCleaner{ResourceCleaner: cleaner, limiter: f.registry.Get(key)}, nil
+}
+
+type limitedCleaner struct {
+	service.ResourceCleaner
+	limiter *Limiter
+}
+
+func (c *limitedCleaner) Delete(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	return c.ResourceCleaner.Delete(WithLimiter(ctx, c.limiter), resource)
+}
+
+func (c *limitedCleaner) Stop(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	return c.ResourceCleaner.Stop(WithLimiter(ctx, c.limiter), resource)
+}
+
+func (c *limitedCleaner) Tag(ctx context.Context, resource *entity.Resource, tags map[string]string) (*service.CleanupResult, error) {
+	return c.ResourceCleaner.Tag(WithLimiter(ctx, c.limiter), resource, tags)
+}
+
+// RouteTablesReferencing forwards to the wrapped cleaner so network
+// attachments can still be checked before deletion
+func (c *limitedCleaner) RouteTablesReferencing(ctx context.Context, resource *entity.Resource) ([]string, error) {
+	inspector, ok := c.ResourceCleaner.(service.RouteTableInspector)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot inspect route tables", c.Provider())
+	}
+	return inspector.RouteTablesReferencing(WithLimiter(ctx, c.limiter), resource)
+}
+
+// DeleteWithFinalSnapshot forwards to the wrapped cleaner so managed
+// databases can still be deleted with a final snapshot
+func (c *limitedCleaner) DeleteWithFinalSnapshot(ctx context.Context, resource *entity.Resource, snapshotID string) (*service.CleanupResult, error) {
+	snapshotter, ok := c.ResourceCleaner.(service.DatabaseSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot take final snapshots", c.Provider())
+	}
+	return snapshotter.DeleteWithFinalSnapshot(WithLimiter(ctx, c.limiter), resource, snapshotID)
+}
+
+// Backup forwards to the wrapped cleaner so volumes, disks and databases
+// can still be backed up before deletion
+func (c *limitedCleaner) Backup(ctx context.Context, resource *entity.Resource, snapshotID string, tags map[string]string) (*service.CleanupResult, error) {
+	taker, ok := c.ResourceCleaner.(service.BackupTaker)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot take backups", c.Provider())
+	}
+	return taker.Backup(WithLimiter(ctx, c.limiter), resource, snapshotID, tags)
+}
+
+// BackupState forwards to the wrapped cleaner so the backups taken before
+// deletion can still be waited for
+func (c *limitedCleaner) BackupState(ctx context.Context, resource *entity.Resource, snapshotID string) (service.BackupState, error) {
+	taker, ok := c.ResourceCleaner.(service.BackupTaker)
+	if !ok {
+		return "", fmt.Errorf("cleaner for %s cannot take backups", c.Provider())
+	}
+	return taker.BackupState(WithLimiter(ctx, c.limiter), resource, snapshotID)
+}
+
+// DeregisterImage forwards to the wrapped cleaner so snapshots backing
+// images can still be deleted
+func (c *limitedCleaner) DeregisterImage(ctx context.Context, snapshot *entity.Resource, imageID string) error {
+	deregisterer, ok := c.ResourceCleaner.(service.ImageDeregisterer)
+	if !ok {
+		return fmt.Errorf("cleaner for %s cannot deregister images", c.Provider())
+	}
+	return deregisterer.DeregisterImage(WithLimiter(ctx, c.limiter), snapshot, imageID)
+}
+
+// Release forwards to the wrapped cleaner so public IP addresses can still
+// be released
+func (c *limitedCleaner) Release(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	releaser, ok := c.ResourceCleaner.(service.IPReleaser)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot release IP addresses", c.Provider())
+	}
+	return releaser.Release(WithLimiter(ctx, c.limiter), resource)
+}
+
+// DNSRecordsReferencing forwards to the wrapped cleaner so the resources
+// DNS records may point at can still be checked before deletion
+func (c *limitedCleaner) DNSRecordsReferencing(ctx context.Context, resource *entity.Resource) ([]string, error) {
+	inspector, ok := c.ResourceCleaner.(service.DNSInspector)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s: %w", c.Provider(), service.ErrDNSUnsupported)
+	}
+	return inspector.DNSRecordsReferencing(WithLimiter(ctx, c.limiter), resource)
+}
+
+// MissingPermissions forwards to the wrapped cleaner so the permissions of
+// a cleanup can still be checked beforehand
+func (c *limitedCleaner) MissingPermissions(ctx context.Context, permissions []string) ([]string, error) {
+	checker, ok := c.ResourceCleaner.(service.PermissionChecker)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s: %w", c.Provider(), service.ErrPermissionsUnsupported)
+	}
+	return checker.MissingPermissions(WithLimiter(ctx, c.limiter), permissions)
+}
+
+// Start forwards to the wrapped cleaner so resources stopped off-hours can
+// still be started
+func (c *limitedCleaner) Start(ctx context.Context, resource *entity.Resource) (*service.CleanupResult, error) {
+	starter, ok := c.ResourceCleaner.(service.ResourceStarter)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot start resources", c.Provider())
+	}
+	return starter.Start(WithLimiter(ctx, c.limiter), resource)
+}
+
+// Snapshot forwards to the wrapped cleaner so resources can still be
+// snapshotted when quarantined
+func (c *limitedCleaner) Snapshot(ctx context.Context, resource *entity.Resource, snapshotID string) (*service.CleanupResult, error) {
+	snapshotter, ok := c.ResourceCleaner.(service.ResourceSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot take snapshots", c.Provider())
+	}
+	return snapshotter.Snapshot(WithLimiter(ctx, c.limiter), resource, snapshotID)
+}
+
+// Untag forwards to the wrapped cleaner so the quarantine tag can still be
+// removed on restore
+func (c *limitedCleaner) Untag(ctx context.Context, resource *entity.Resource, keys []string) (*service.CleanupResult, error) {
+	remover, ok := c.ResourceCleaner.(service.TagRemover)
+	if !ok {
+		return nil, fmt.Errorf("cleaner for %s cannot remove tags", c.Provider())
+	}
+	return remover.Untag(WithLimiter(ctx, c.limiter), resource, keys)
+}
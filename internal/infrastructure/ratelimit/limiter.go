@@ -0,0 +1,176 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// minRateFactor bounds how far throttling can lower the rate, as a
+	// fraction of the configured rate
+	minRateFactor = 0.05
+	// recoveryStep is the fraction of the configured rate regained after
+	// each successful call
+	recoveryStep = 0.01
+	// decreaseCooldown prevents a burst of concurrent throttled calls from
+	// halving the rate once per call
+	decreaseCooldown = time.Second
+)
+
+// Policy holds the retry behaviour for throttled calls
+type Policy struct {
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Stats reports limiter usage
+type Stats struct {
+	QPS        float64 `json:"qps"`
+	MaxQPS     float64 `json:"max_qps"`
+	Requests   int64   `json:"requests"`
+	Throttled  int64   `json:"throttled"`
+	Retries    int64   `json:"retries"`
+	WaitMillis int64   `json:"wait_ms"`
+}
+
+// Limiter is a token bucket shared by every call made with one provider
+// account. Its rate adapts to the provider: it is halved when a call is
+// throttled and grows back toward the configured rate as calls succeed.
+type Limiter struct {
+	limiter *rate.Limiter
+
+	mu           sync.Mutex // guards max, policy and lastDecrease
+	max          rate.Limit
+	policy       Policy
+	lastDecrease time.Time
+
+	requests  atomic.Int64
+	throttled atomic.Int64
+	retries   atomic.Int64
+	waitNanos atomic.Int64
+}
+
+// NewLimiter creates a limiter allowing qps calls per second with bursts of
+// up to burst calls
+func NewLimiter(qps float64, burst int, policy Policy) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		limiter: rate.NewLimiter(rate.Limit(qps), burst),
+		max:     rate.Limit(qps),
+		policy:  policy,
+	}
+}
+
+// Do waits for a token and calls fn, retrying with exponential backoff while
+// the provider throttles it. The last error is returned once retries are
+// exhausted.
+func (l *Limiter) Do(ctx context.Context, fn func(context.Context) error) error {
+	l.mu.Lock()
+	policy := l.policy
+	l.mu.Unlock()
+
+	backoff := policy.BaseBackoff
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		if err := l.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		l.waitNanos.Add(int64(time.Since(start)))
+		l.requests.Add(1)
+
+		err := fn(ctx)
+		if !IsThrottle(err) {
+			if err == nil {
+				l.speedUp()
+			}
+			return err
+		}
+
+		l.throttled.Add(1)
+		l.slowDown()
+		if attempt >= policy.MaxRetries {
+			return err
+		}
+		l.retries.Add(1)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jitter(backoff)):
+		}
+		backoff = min(backoff*2, policy.MaxBackoff)
+	}
+}
+
+// SetLimits changes the configured rate, burst and retry policy of the
+// limiter. The current rate is capped to the new one, and grows back to it
+// as calls succeed when it was raised.
+func (l *Limiter) SetLimits(qps float64, burst int, policy Policy) {
+	if burst < 1 {
+		burst = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.max = rate.Limit(qps)
+	l.policy = policy
+	l.limiter.SetBurst(burst)
+	if l.limiter.Limit() > l.max {
+		l.limiter.SetLimit(l.max)
+	}
+}
+
+// Stats returns the current rate and counters of the limiter
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	maxQPS := l.max
+	l.mu.Unlock()
+
+	return Stats{
+		QPS:        float64(l.limiter.Limit()),
+		MaxQPS:     float64(maxQPS),
+		Requests:   l.requests.Load(),
+		Throttled:  l.throttled.Load(),
+		Retries:    l.retries.Load(),
+		WaitMillis: time.Duration(l.waitNanos.Load()).Milliseconds(),
+	}
+}
+
+func (l *Limiter) slowDown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastDecrease) < decreaseCooldown {
+		return
+	}
+	l.lastDecrease = now
+	l.limiter.SetLimit(max(l.limiter.Limit()/2, l.max*minRateFactor))
+}
+
+func (l *Limiter) speedUp() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limiter.Limit() >= l.max {
+		return
+	}
+	l.limiter.SetLimit(min(l.limiter.Limit()+l.max*recoveryStep, l.max))
+}
+
+// jitter spreads retries of concurrent callers over [d/2, d)
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket refills a bucket of ARGV[1] tokens over ARGV[2] milliseconds
+// and takes one token at time ARGV[3]. It returns whether the request is
+// allowed, the tokens left and the milliseconds until the next token.
+var tokenBucket = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * capacity / period)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * period / capacity)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, math.floor(tokens), retry}
+`)
+
+// Decision is the outcome of a rate limit check
+type Decision struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// RedisLimiter enforces token buckets stored in Redis, so the limits hold
+// across every API instance
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter creates a limiter storing its buckets in Redis
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+// Allow takes a token from the bucket identified by key
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit config.RouteLimit) (Decision, error) {
+	res, err := tokenBucket.Run(ctx, l.client, []string{"cloudsweep:ratelimit:" + key},
+		limit.Requests, limit.Period.Milliseconds(), time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return Decision{}, err
+	}
+	return Decision{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// Key identifies the limiter of a provider account
+type Key struct {
+	Provider entity.CloudProvider
+	Account  string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s", k.Provider, k.Account)
+}
+
+// Registry hands out one limiter per provider account, so every scanner and
+// cleaner working on the same account shares its quota
+type Registry struct {
+	cfg config.RateLimitConfig
+
+	mu       sync.Mutex
+	limiters map[Key]*Limiter
+}
+
+// NewRegistry creates a registry using the configured limits
+func NewRegistry(cfg config.RateLimitConfig) *Registry {
+	return &Registry{
+		cfg:      cfg,
+		limiters: make(map[Key]*Limiter),
+	}
+}
+
+// Get returns the limiter of the account, creating it on first use
+func (r *Registry) Get(key Key) *Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.limiters[key]; ok {
+		return l
+	}
+
+	limits := r.limits(key.Provider)
+	l := NewLimiter(limits.QPS, limits.Burst, r.policy())
+	r.limiters[key] = l
+	return l
+}
+
+// Update applies new limits to the limiters handed out and to the ones
+// created afterwards
+func (r *Registry) Update(cfg config.RateLimitConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cfg = cfg
+	for key, l := range r.limiters {
+		limits := r.limits(key.Provider)
+		l.SetLimits(limits.QPS, limits.Burst, r.policy())
+	}
+}
+
+// Stats returns the stats of every limiter keyed by provider and account
+func (r *Registry) Stats() map[string]Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]Stats, len(r.limiters))
+	for key, l := range r.limiters {
+		stats[key.String()] = l.Stats()
+	}
+	return stats
+}
+
+func (r *Registry) policy() Policy {
+	return Policy{
+		MaxRetries:  r.cfg.MaxRetries,
+		BaseBackoff: r.cfg.BaseBackoff,
+		MaxBackoff:  r.cfg.MaxBackoff,
+	}
+}
+
+func (r *Registry) limits(provider entity.CloudProvider) config.ProviderRateLimit {
+	switch provider {
+	case entity.CloudProviderAzure:
+		return r.cfg.Azure
+	case entity.CloudProviderGCP:
+		return r.cfg.GCP
+	case entity.CloudProviderKubernetes:
+		return r.cfg.Kubernetes
+	default:
+		return r.cfg.AWS
+	}
+}
@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireSlots takes a slot of every semaphore in KEYS for holder ARGV[1]
+// until ARGV[3] (unix milliseconds), or none of them. Semaphores are sorted
+// sets of holders scored by the expiry of their lease, so the slots of a
+// worker that died are freed when its lease expires. ARGV[4..] are the
+// limits of the semaphores. It returns 0 on success, or the index of the
+// first full semaphore. A holder retaking its own slot always succeeds.
+var acquireSlots = redis.NewScript(`
+local holder = ARGV[1]
+local now = tonumber(ARGV[2])
+local expiry = tonumber(ARGV[3])
+for i, key in ipairs(KEYS) do
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+	if not redis.call('ZSCORE', key, holder) and redis.call('ZCARD', key) >= tonumber(ARGV[3 + i]) then
+		return i
+	end
+end
+for _, key in ipairs(KEYS) do
+	redis.call('ZADD', key, expiry, holder)
+	redis.call('PEXPIRE', key, expiry - now)
+end
+return 0
+`)
+
+// renewSlots extends the lease of holder ARGV[1] on the semaphores it
+// still holds to ARGV[3]
+var renewSlots = redis.NewScript(`
+local holder = ARGV[1]
+local now = tonumber(ARGV[2])
+local expiry = tonumber(ARGV[3])
+for _, key in ipairs(KEYS) do
+	if redis.call('ZSCORE', key, holder) then
+		redis.call('ZADD', key, expiry, holder)
+		redis.call('PEXPIRE', key, expiry - now)
+	end
+end
+return 0
+`)
+
+// Semaphore counts the slots taken across every worker replica, to cap how
+// many tasks run at once against the same account or provider
+type Semaphore struct {
+	client *redis.Client
+	lease  time.Duration
+}
+
+// NewSemaphore creates a semaphore keeping its slots in Redis. A slot whose
+// holder stops renewing it is freed after lease.
+func NewSemaphore(client *redis.Client, lease time.Duration) *Semaphore {
+	return &Semaphore{client: client, lease: lease}
+}
+
+// Limit is a semaphore and the number of slots it has
+type Limit struct {
+	Key   string
+	Slots int
+}
+
+// BusyError is returned when a semaphore has no slot left. The task should
+// be retried later without counting as a failure.
+type BusyError struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("concurrency limit %s reached, retrying in %s", e.Key, e.RetryAfter)
+}
+
+// IsBusy reports whether err is, or wraps, a *BusyError
+func IsBusy(err error) bool {
+	var busy *BusyError
+	return errors.As(err, &busy)
+}
+
+// Slots are the slots taken by a holder. Their lease is renewed until they
+// are released.
+type Slots struct {
+	sem    *Semaphore
+	keys   []string
+	holder string
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// Acquire takes a slot of every limit for holder, or none of them. It
+// returns a *BusyError naming the first full semaphore, with retryAfter.
+func (s *Semaphore) Acquire(ctx context.Context, holder string, limits []Limit, retryAfter time.Duration) (*Slots, error) {
+	keys := make([]string, len(limits))
+	args := []any{holder, 0, 0}
+	for i, l := range limits {
+		keys[i] = "cloudsweep:concurrency:" + l.Key
+		args = append(args, l.Slots)
+	}
+	now := time.Now()
+	args[1], args[2] = now.UnixMilli(), now.Add(s.lease).UnixMilli()
+
+	full, err := acquireSlots.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire concurrency slots: %w", err)
+	}
+	if full > 0 {
+		return nil, &BusyError{Key: limits[full-1].Key, RetryAfter: retryAfter}
+	}
+
+	slots := &Slots{sem: s, keys: keys, holder: holder, stop: make(chan struct{}), done: make(chan struct{})}
+	go slots.renew()
+	return slots, nil
+}
+
+// renew extends the lease of the slots until they are released
+func (sl *Slots) renew() {
+	defer close(sl.done)
+	ticker := time.NewTicker(sl.sem.lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sl.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			err := renewSlots.Run(context.Background(), sl.sem.client, sl.keys, sl.holder, now.UnixMilli(), now.Add(sl.sem.lease).UnixMilli()).Err()
+			if err != nil {
+				log.Printf("Failed to renew concurrency slots of %s: %v", sl.holder, err)
+			}
+		}
+	}
+}
+
+// Release frees the slots
+func (sl *Slots) Release(ctx context.Context) {
+	close(sl.stop)
+	<-sl.done
+
+	pipe := sl.sem.client.Pipeline()
+	for _, key := range sl.keys {
+		pipe.ZRem(ctx, key, sl.holder)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to release concurrency slots of %s: %v", sl.holder, err)
+	}
+}
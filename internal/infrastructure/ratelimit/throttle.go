@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrThrottled can be wrapped by provider implementations when throttling is
+// reported in a form IsThrottle does not recognize (e.g. Azure and GCP SDK
+// errors expose status codes as fields rather than methods)
+var ErrThrottled = errors.New("request throttled by cloud provider")
+
+// throttleCodes are the error codes providers return when throttling calls
+var throttleCodes = map[string]bool{
+	// AWS
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"RequestThrottled":                       true,
+	"RequestThrottledException":              true,
+	"RequestLimitExceeded":                   true,
+	"TooManyRequestsException":               true,
+	"ProvisionedThroughputExceededException": true,
+	"SlowDown":                               true,
+	// Azure
+	"TooManyRequests":               true,
+	"SubscriptionRequestsThrottled": true,
+	// GCP
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"RESOURCE_EXHAUSTED":    true,
+}
+
+// IsThrottle reports whether err means the provider rejected the call
+// because of rate limits
+func IsThrottle(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrThrottled) {
+		return true
+	}
+
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) && throttleCodes[coded.ErrorCode()] {
+		return true
+	}
+
+	var status interface{ HTTPStatusCode() int }
+	return errors.As(err, &status) && status.HTTPStatusCode() == http.StatusTooManyRequests
+}
@@ -0,0 +1,147 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recommendedTypes are the resource types recommendations are computed for
+var recommendedTypes = []string{
+	string(entity.ResourceTypeEC2Instance),
+	string(entity.ResourceTypeAzureVM),
+	string(entity.ResourceTypeGCEInstance),
+	string(entity.ResourceTypeRDSInstance),
+	string(entity.ResourceTypeAzureSQL),
+	string(entity.ResourceTypeCloudSQL),
+	string(entity.ResourceTypeEBSVolume),
+	string(entity.ResourceTypeK8sDeployment),
+}
+
+// commitmentTypes are the compute resource types covered by commitments
+var commitmentTypes = []string{
+	string(entity.ResourceTypeEC2Instance),
+	string(entity.ResourceTypeAzureVM),
+	string(entity.ResourceTypeGCEInstance),
+}
+
+// Generator computes and stores the recommendations of organizations
+type Generator struct {
+	db *gorm.DB
+}
+
+// NewGenerator creates a Generator
+func NewGenerator(db *gorm.DB) *Generator {
+	return &Generator{db: db}
+}
+
+// Compute returns the current recommendations of an organization
+func (g *Generator) Compute(ctx context.Context, orgID uuid.UUID, now time.Time) ([]service.Recommendation, error) {
+	db := g.db.WithContext(ctx)
+
+	var recs []service.Recommendation
+	var batch []model.Resource
+	err := db.Where("organization_id = ? AND status = ? AND type IN ?", orgID, string(entity.ResourceStatusActive), recommendedTypes).
+		FindInBatches(&batch, 500, func(*gorm.DB, int) error {
+			for _, m := range batch {
+				recs = append(recs, service.RecommendResource(database.ResourceEntity(&m), service.DefaultRightsizeCPU)...)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load resources of organization %s: %w", orgID, err)
+	}
+
+	var spends []struct {
+		Provider string
+		Spend    float64
+	}
+	err = db.Model(&model.Resource{}).
+		Select("provider, COALESCE(SUM(monthly_cost), 0) AS spend").
+		Where("organization_id = ? AND status = ? AND type IN ?", orgID, string(entity.ResourceStatusActive), commitmentTypes).
+		Where("created_at < ?", now.Add(-service.SteadyUsageAge)).
+		Group("provider").
+		Scan(&spends).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load compute spend of organization %s: %w", orgID, err)
+	}
+	for _, s := range spends {
+		if rec, ok := service.RecommendCommitment(entity.CloudProvider(s.Provider), s.Spend); ok {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, nil
+}
+
+// Generate computes the recommendations of every active organization and
+// stores them. Recommendations still applying keep their status, so
+// dismissed ones stay dismissed; those no longer applying are removed. It
+// returns the number of recommendations stored.
+func (g *Generator) Generate(ctx context.Context, now time.Time) (int, error) {
+	var orgs []model.Organization
+	if err := g.db.WithContext(ctx).Where("is_active = ?", true).Find(&orgs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load organizations: %w", err)
+	}
+
+	var (
+		stored int
+		errs   []error
+	)
+	for _, org := range orgs {
+		n, err := g.generate(ctx, org.ID, now)
+		stored += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stored, errors.Join(errs...)
+}
+
+func (g *Generator) generate(ctx context.Context, orgID uuid.UUID, now time.Time) (int, error) {
+	recs, err := g.Compute(ctx, orgID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	db := g.db.WithContext(ctx)
+	for _, rec := range recs {
+		row := model.Recommendation{
+			ID:             uuid.New(),
+			OrganizationID: orgID,
+			Provider:       string(rec.Provider),
+			Type:           string(rec.Type),
+			Key:            rec.Key,
+			Current:        rec.Current,
+			Recommended:    rec.Recommended,
+			MonthlySavings: rec.MonthlySavings,
+			Details:        model.JSONB(rec.Details),
+			Status:         string(entity.RecommendationStatusOpen),
+		}
+		if rec.Resource != nil {
+			row.ResourceID = &rec.Resource.ID
+		}
+		err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"resource_id", "provider", "current", "recommended", "monthly_savings", "details", "updated_at"}),
+		}).Create(&row).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to store recommendations of organization %s: %w", orgID, err)
+		}
+	}
+
+	err = db.Where("organization_id = ? AND updated_at < ?", orgID, start).Delete(&model.Recommendation{}).Error
+	if err != nil {
+		return len(recs), fmt.Errorf("failed to remove outdated recommendations of organization %s: %w", orgID, err)
+	}
+	return len(recs), nil
+}
@@ -0,0 +1,168 @@
+// Package retention purges the history older than the retention of each
+// organization: finished scans, hygiene scores, jobs, the events of
+// resources and resources deleted since.
+// Purged rows can first be archived to object storage.
+package retention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// batchSize is the number of rows archived and deleted at once, which keeps
+// the locks and the memory of a purge bounded on large installs
+const batchSize = 5000
+
+// history is a table purged by the retention, with the condition matching
+// the rows of an organization older than the cutoff, the last argument
+type history struct {
+	table string
+	where string
+	args  []any
+}
+
+var histories = []history{
+	{
+		table: "scans",
+		where: "status IN ? AND created_at < ?",
+		args: []any{[]string{
+			string(entity.ScanStatusCompleted),
+			string(entity.ScanStatusPartial),
+			string(entity.ScanStatusFailed),
+			string(entity.ScanStatusCancelled),
+		}},
+	},
+	{table: "hygiene_scores", where: "day < ?"},
+	{
+		table: "jobs",
+		where: "status IN ? AND created_at < ?",
+		args:  []any{[]string{string(entity.JobStatusCompleted), string(entity.JobStatusFailed)}},
+	},
+	{table: "resource_events", where: "occurred_at < ?"},
+	{
+		// Items of cleanup sessions reference their resources; their
+		// remaining events go with them
+		table: "resources",
+		where: "status = ? AND NOT EXISTS (SELECT 1 FROM cleanup_session_items WHERE resource_id = resources.id) AND updated_at < ?",
+		args:  []any{string(entity.ResourceStatusDeleted)},
+	},
+}
+
+// Result counts the rows purged, by table
+type Result map[string]int64
+
+// Purger purges the expired history. With an archive, each batch of rows is
+// written to it as gzipped JSON lines before being deleted, under
+// archives/<organization>/<day of the purge>/<table>-<batch>.jsonl.gz. A
+// batch whose deletion fails is archived again by the next purge.
+type Purger struct {
+	db      *gorm.DB
+	archive storage.ObjectStore
+}
+
+// NewPurger creates a Purger. A nil archive purges without archiving.
+func NewPurger(db *gorm.DB, archive storage.ObjectStore) *Purger {
+	return &Purger{db: db, archive: archive}
+}
+
+// Purge deletes the history older than the retention of each organization
+func (p *Purger) Purge(ctx context.Context, now time.Time) (Result, error) {
+	var orgs []model.Organization
+	if err := p.db.WithContext(ctx).Select("id", "plan", "history_retention_days").Find(&orgs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch organizations: %w", err)
+	}
+
+	result := Result{}
+	for _, org := range orgs {
+		days := entity.HistoryRetentionDays(org.Plan, org.HistoryRetentionDays)
+		if days == 0 {
+			continue
+		}
+		cutoff := now.AddDate(0, 0, -days)
+
+		for _, h := range histories {
+			purged, err := p.purge(ctx, org.ID, h, cutoff, now)
+			result[h.table] += purged
+			if err != nil {
+				return result, fmt.Errorf("failed to purge %s of organization %s: %w", h.table, org.ID, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (p *Purger) purge(ctx context.Context, orgID uuid.UUID, h history, cutoff, now time.Time) (int64, error) {
+	var purged int64
+	for batch := 1; ; batch++ {
+		query := p.db.WithContext(ctx).Table(h.table).
+			Where("organization_id = ?", orgID).
+			Where(h.where, append(append([]any{}, h.args...), cutoff)...).
+			Limit(batchSize)
+		if p.archive == nil {
+			query = query.Select("id")
+		}
+		var rows []map[string]any
+		if err := query.Find(&rows).Error; err != nil {
+			return purged, err
+		}
+		if len(rows) == 0 {
+			return purged, nil
+		}
+
+		if p.archive != nil {
+			key := fmt.Sprintf("archives/%s/%s/%s-%04d.jsonl.gz", orgID, now.UTC().Format("2006-01-02"), h.table, batch)
+			if err := p.store(ctx, key, rows); err != nil {
+				return purged, fmt.Errorf("failed to archive to %s: %w", key, err)
+			}
+		}
+
+		ids := make([]any, len(rows))
+		for i, row := range rows {
+			ids[i] = row["id"]
+		}
+		result := p.db.WithContext(ctx).Exec("DELETE FROM "+h.table+" WHERE id IN ?", ids)
+		if result.Error != nil {
+			return purged, result.Error
+		}
+		purged += result.RowsAffected
+		if len(rows) < batchSize {
+			return purged, nil
+		}
+	}
+}
+
+// store writes the rows to the archive, one JSON object per line
+func (p *Purger) store(ctx context.Context, key string, rows []map[string]any) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, row := range rows {
+		// JSON columns are read as raw bytes
+		for column, value := range row {
+			if raw, ok := value.([]byte); ok {
+				if json.Valid(raw) {
+					row[column] = json.RawMessage(raw)
+				} else {
+					row[column] = string(raw)
+				}
+			}
+		}
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return p.archive.Put(ctx, key, &buf, int64(buf.Len()), "application/gzip")
+}
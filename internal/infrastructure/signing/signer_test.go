@@ -0,0 +1,46 @@
+package signing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type value struct {
+	ID string `json:"i"`
+}
+
+func TestSignerVerify(t *testing.T) {
+	s := New("test-key")
+	token := s.Sign("session", value{ID: "42"})
+
+	var got value
+	if err := s.Verify("session", token, &got); err != nil || got.ID != "42" {
+		t.Fatalf("Verify() = %+v, %v, want the signed value", got, err)
+	}
+
+	encoded, sig, _ := strings.Cut(token, ".")
+	tampered := New("test-key").Sign("session", value{ID: "43"})
+	forged, _, _ := strings.Cut(tampered, ".")
+
+	tests := []struct {
+		name    string
+		signer  *Signer
+		purpose string
+		token   string
+	}{
+		{"other purpose", s, "state", token},
+		{"other key", New("other-key"), "session", token},
+		{"tampered value", s, "session", forged + "." + sig},
+		{"no signature", s, "session", encoded},
+		{"malformed signature", s, "session", encoded + ".%%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v value
+			if err := tt.signer.Verify(tt.purpose, tt.token, &v); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Verify() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
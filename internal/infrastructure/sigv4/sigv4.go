@@ -0,0 +1,176 @@
+// Package sigv4 signs requests to AWS and S3-compatible services with
+// Signature Version 4, either in the Authorization header or as a presigned
+// URL, so that no SDK is required.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm is the SigV4 signing algorithm
+const Algorithm = "AWS4-HMAC-SHA256"
+
+// UnsignedPayload is the payload hash of presigned requests, whose body is
+// not known when signing
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// Credentials are the access key signing requests, with the session token
+// of temporary credentials
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Sign adds the SigV4 Authorization header to req, whose body is payload.
+// Every header already set on req is signed along with the host.
+func Sign(req *http.Request, payload []byte, creds Credentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := credentialScope(now, region, service)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	signature := signature(creds.SecretAccessKey, now, region, service, amzDate, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		Algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// Presign returns the URL authenticating a request to the unescaped path of
+// endpoint in its query string, valid for ttl. Only the host is signed and
+// the payload is not.
+func Presign(method string, endpoint *url.URL, path string, creds Credentials, region, service string, ttl time.Duration, now time.Time) string {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := credentialScope(now, region, service)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     Algorithm,
+		"X-Amz-Credential":    creds.AccessKeyID + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(ttl.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if creds.SessionToken != "" {
+		query["X-Amz-Security-Token"] = creds.SessionToken
+	}
+	canonicalQuery := CanonicalQuery(query)
+	escapedPath := URIEncode(path, false)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		escapedPath,
+		canonicalQuery,
+		"host:" + endpoint.Host + "\n",
+		"host",
+		UnsignedPayload,
+	}, "\n")
+
+	signature := signature(creds.SecretAccessKey, now, region, service, amzDate, scope, canonicalRequest)
+	return fmt.Sprintf("%s://%s%s?%s&X-Amz-Signature=%s", endpoint.Scheme, endpoint.Host, escapedPath, canonicalQuery, signature)
+}
+
+// CanonicalQuery returns the canonical query string of params: sorted by
+// name, names and values URI-encoded
+func CanonicalQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, URIEncode(k, true)+"="+URIEncode(params[k], true))
+	}
+	return strings.Join(parts, "&")
+}
+
+// URIEncode percent-encodes s as required by SigV4: only unreserved
+// characters are kept, and "/" is encoded only when encodeSlash is set
+func URIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func credentialScope(now time.Time, region, service string) string {
+	return fmt.Sprintf("%s/%s/%s/aws4_request", now.Format("20060102"), region, service)
+}
+
+// signature signs a canonical request with the key derived from the secret
+// for the day, region and service of its scope
+func signature(secret string, now time.Time, region, service, amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secret), now.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,237 @@
+// Package slack is the Slack app organizations install with OAuth: the
+// /cloudsweep slash command, scan results posted with cleanup buttons and
+// the interactions of those buttons.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// DefaultAPIURL is the base URL of the Slack Web API
+const DefaultAPIURL = "https://slack.com/api"
+
+// AuthorizeURL is the page installing the app in a workspace
+const AuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+// Scopes are the bot scopes the app asks for: slash commands, posting to
+// the channel picked during the installation and reading the email of the
+// users clicking buttons, to match them with organization members, or of
+// the owners of resources, to message them
+var Scopes = []string{"commands", "chat:write", "incoming-webhook", "users:read", "users:read.email"}
+
+// Installation is the outcome of the OAuth installation of the app in a
+// workspace
+type Installation struct {
+	TeamID      string
+	TeamName    string
+	BotUserID   string
+	AccessToken string // bot token
+	ChannelID   string // channel picked during the installation
+	ChannelName string
+	InstalledBy string // Slack user ID of the installer
+}
+
+// Client calls the Slack Web API with the credentials of the app
+type Client struct {
+	apiURL       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	http         *http.Client
+}
+
+// NewClient creates a Slack client. It returns nil when the app is not
+// configured.
+func NewClient(cfg config.SlackConfig) *Client {
+	if cfg.ClientID == "" {
+		return nil
+	}
+	return &Client{
+		apiURL:       DefaultAPIURL,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		http:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithAPIURL returns a copy of the client calling another Web API, e.g. a
+// test server
+func (c *Client) WithAPIURL(apiURL string) *Client {
+	copied := *c
+	copied.apiURL = strings.TrimRight(apiURL, "/")
+	return &copied
+}
+
+// InstallURL returns the Slack page installing the app, which redirects to
+// the callback with state
+func (c *Client) InstallURL(state string) string {
+	return AuthorizeURL + "?" + url.Values{
+		"client_id":    {c.clientID},
+		"scope":        {strings.Join(Scopes, ",")},
+		"redirect_uri": {c.redirectURL},
+		"state":        {state},
+	}.Encode()
+}
+
+// Exchange trades the code of the OAuth callback for the installation
+func (c *Client) Exchange(ctx context.Context, code string) (*Installation, error) {
+	form := url.Values{
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+		"code":          {code},
+		"redirect_uri":  {c.redirectURL},
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		BotUserID   string `json:"bot_user_id"`
+		Team        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"team"`
+		AuthedUser struct {
+			ID string `json:"id"`
+		} `json:"authed_user"`
+		IncomingWebhook struct {
+			Channel   string `json:"channel"`
+			ChannelID string `json:"channel_id"`
+		} `json:"incoming_webhook"`
+	}
+	if err := c.call(ctx, "oauth.v2.access", "", form, &resp); err != nil {
+		return nil, err
+	}
+	return &Installation{
+		TeamID:      resp.Team.ID,
+		TeamName:    resp.Team.Name,
+		BotUserID:   resp.BotUserID,
+		AccessToken: resp.AccessToken,
+		ChannelID:   resp.IncomingWebhook.ChannelID,
+		ChannelName: resp.IncomingWebhook.Channel,
+		InstalledBy: resp.AuthedUser.ID,
+	}, nil
+}
+
+// PostMessage posts a message to a channel with a bot token
+func (c *Client) PostMessage(ctx context.Context, token, channel string, msg Message) error {
+	msg.Channel = channel
+	return c.call(ctx, "chat.postMessage", token, msg, nil)
+}
+
+// UserEmail returns the email of a user of the workspace of a bot token
+func (c *Client) UserEmail(ctx context.Context, token, userID string) (string, error) {
+	var resp struct {
+		User struct {
+			Profile struct {
+				Email string `json:"email"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	if err := c.call(ctx, "users.info", token, url.Values{"user": {userID}}, &resp); err != nil {
+		return "", err
+	}
+	return resp.User.Profile.Email, nil
+}
+
+// UserByEmail returns the ID of the user of the workspace of a bot token
+// with an email address, whose direct messages the bot can post to
+func (c *Client) UserByEmail(ctx context.Context, token, email string) (string, error) {
+	var resp struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	}
+	if err := c.call(ctx, "users.lookupByEmail", token, url.Values{"email": {email}}, &resp); err != nil {
+		return "", err
+	}
+	return resp.User.ID, nil
+}
+
+// Respond sends a message to the response URL of a command or an
+// interaction, valid for 30 minutes after it
+func (c *Client) Respond(ctx context.Context, responseURL string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack response URL returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// call calls a Web API method with a form or a JSON body. Slack answers
+// 200 with ok false on errors.
+func (c *Client) call(ctx context.Context, method, token string, body any, out any) error {
+	var req *http.Request
+	var err error
+	if form, ok := body.(url.Values); ok {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/"+method, strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		payload, merr := json.Marshal(body)
+		if merr != nil {
+			return merr
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/"+method, bytes.NewReader(payload))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack %s returned %d", method, resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("slack %s: invalid response: %w", method, err)
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("slack %s: invalid response: %w", method, err)
+	}
+	if !status.OK {
+		return fmt.Errorf("slack %s: %s", method, status.Error)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("slack %s: invalid response: %w", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Action IDs of the buttons of scan messages, whose value is the scan ID
+const (
+	ActionPreviewCleanup = "preview_cleanup"
+	ActionApproveCleanup = "approve_cleanup"
+)
+
+// Limits of the waste command
+const (
+	DefaultWasteLimit = 10
+	MaxWasteLimit     = 50
+)
+
+// Commands of /cloudsweep
+const (
+	CommandHelp  = "help"
+	CommandWaste = "waste"
+)
+
+// ErrUnknownCommand is returned for commands other than those of the help
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Message is a Slack message, posted to a channel or answering a command or
+// an interaction. Ephemeral messages are only shown to the user.
+type Message struct {
+	Channel         string  `json:"channel,omitempty"`
+	Text            string  `json:"text"` // fallback of notifications
+	Blocks          []Block `json:"blocks,omitempty"`
+	ResponseType    string  `json:"response_type,omitempty"` // "ephemeral" or "in_channel"
+	ReplaceOriginal bool    `json:"replace_original,omitempty"`
+}
+
+// Block is a Block Kit layout block
+type Block struct {
+	Type     string    `json:"type"`
+	Text     *Text     `json:"text,omitempty"`
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Text is a Block Kit text object
+type Text struct {
+	Type string `json:"type"` // "mrkdwn" or "plain_text"
+	Text string `json:"text"`
+}
+
+// Element is an interactive Block Kit element, here a button
+type Element struct {
+	Type     string   `json:"type"`
+	Text     *Text    `json:"text,omitempty"`
+	ActionID string   `json:"action_id,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Style    string   `json:"style,omitempty"`
+	Confirm  *Confirm `json:"confirm,omitempty"`
+}
+
+// Confirm is the dialog confirming a button
+type Confirm struct {
+	Title   Text `json:"title"`
+	Text    Text `json:"text"`
+	Confirm Text `json:"confirm"`
+	Deny    Text `json:"deny"`
+}
+
+// Command is a parsed /cloudsweep command
+type Command struct {
+	Name  string
+	Limit int // resources listed by waste
+}
+
+// ParseCommand parses the text of /cloudsweep: "waste top [N]" lists the
+// N most expensive unused resources, "help" or nothing lists the commands
+func ParseCommand(text string) (Command, error) {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 || words[0] == CommandHelp {
+		return Command{Name: CommandHelp}, nil
+	}
+	if words[0] != CommandWaste || len(words) > 3 || (len(words) > 1 && words[1] != "top") {
+		return Command{}, ErrUnknownCommand
+	}
+	cmd := Command{Name: CommandWaste, Limit: DefaultWasteLimit}
+	if len(words) == 3 {
+		n, err := strconv.Atoi(words[2])
+		if err != nil || n < 1 {
+			return Command{}, fmt.Errorf("%w: the number of resources must be a positive integer", ErrUnknownCommand)
+		}
+		cmd.Limit = min(n, MaxWasteLimit)
+	}
+	return cmd, nil
+}
+
+// Interaction is the payload Slack sends when a button is clicked
+type Interaction struct {
+	Type        string `json:"type"`
+	ResponseURL string `json:"response_url"`
+	Team        struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// ParseInteraction parses the payload form field of an interaction
+func ParseInteraction(payload string) (*Interaction, error) {
+	var i Interaction
+	if err := json.Unmarshal([]byte(payload), &i); err != nil {
+		return nil, fmt.Errorf("invalid interaction payload: %w", err)
+	}
+	return &i, nil
+}
+
+// WasteItem is an unused resource listed by a message
+type WasteItem struct {
+	Name        string
+	Type        string
+	Provider    string
+	Region      string
+	MonthlyCost float64
+}
+
+// ScanSummary is the outcome of a finished scan posted to the channel
+type ScanSummary struct {
+	ScanID           string
+	Provider         string
+	Status           string
+	ResourcesFound   int
+	UnusedFound      int
+	EstimatedSavings float64
+	ErrorMessage     string
+}
+
+// TextMessage returns an ephemeral text message
+func TextMessage(text string) Message {
+	return Message{Text: text, ResponseType: "ephemeral"}
+}
+
+// HelpMessage lists the commands of /cloudsweep
+func HelpMessage() Message {
+	return TextMessage(fmt.Sprintf("*/cloudsweep waste top [N]* lists the N most expensive unused resources (%d by default, at most %d).", DefaultWasteLimit, MaxWasteLimit))
+}
+
+// WasteMessage lists the most expensive unused resources of an
+// organization, for the user who ran the command
+func WasteMessage(items []WasteItem, total float64) Message {
+	if len(items) == 0 {
+		return TextMessage("No unused resource found by the last scans.")
+	}
+	title := fmt.Sprintf("Top %d unused resources, %s/month", len(items), money(total))
+	return Message{
+		Text:         title,
+		ResponseType: "ephemeral",
+		Blocks: []Block{
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: "*" + title + "*"}},
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: itemLines(items)}},
+		},
+	}
+}
+
+// ScanMessage summarizes a finished scan. Scans finding unused resources
+// get buttons to preview their cleanup and approve it.
+func ScanMessage(s ScanSummary) Message {
+	title := fmt.Sprintf("%s scan %s: %d unused of %d resources, %s/month to save",
+		strings.ToUpper(s.Provider), s.Status, s.UnusedFound, s.ResourcesFound, money(s.EstimatedSavings))
+	blocks := []Block{{Type: "section", Text: &Text{Type: "mrkdwn", Text: "*" + title + "*"}}}
+	if s.ErrorMessage != "" {
+		blocks = append(blocks, Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: "> " + s.ErrorMessage}})
+	}
+	if s.UnusedFound > 0 {
+		blocks = append(blocks, Block{Type: "actions", Elements: []Element{
+			{Type: "button", Text: &Text{Type: "plain_text", Text: "Preview cleanup"}, ActionID: ActionPreviewCleanup, Value: s.ScanID},
+			{
+				Type: "button", Text: &Text{Type: "plain_text", Text: "Approve"}, ActionID: ActionApproveCleanup, Value: s.ScanID, Style: "primary",
+				Confirm: &Confirm{
+					Title:   Text{Type: "plain_text", Text: "Approve the cleanup?"},
+					Text:    Text{Type: "mrkdwn", Text: fmt.Sprintf("The %d unused resources found by this scan will be approved for cleanup in your name.", s.UnusedFound)},
+					Confirm: Text{Type: "plain_text", Text: "Approve"},
+					Deny:    Text{Type: "plain_text", Text: "Cancel"},
+				},
+			},
+		}})
+	}
+	return Message{Text: title, Blocks: blocks}
+}
+
+// PreviewMessage lists the resources a scan cleanup would remove, for the
+// user who clicked the button
+func PreviewMessage(items []WasteItem, total float64, more int) Message {
+	if len(items) == 0 {
+		return TextMessage("No resource of this scan is waiting for a cleanup anymore.")
+	}
+	title := fmt.Sprintf("Cleanup preview: %d resources, %s/month", len(items)+more, money(total))
+	lines := itemLines(items)
+	if more > 0 {
+		lines += fmt.Sprintf("\n…and %d more", more)
+	}
+	return Message{
+		Text:         title,
+		ResponseType: "ephemeral",
+		Blocks: []Block{
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: "*" + title + "*"}},
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: lines}},
+		},
+	}
+}
+
+// OwnerMessage lists the resources of an owner matched by policies with the
+// notify action, sent to the owner as a direct message
+func OwnerMessage(items []WasteItem, total float64) Message {
+	title := fmt.Sprintf("You own %d unused resources costing %s/month", len(items), money(total))
+	return Message{
+		Text: title,
+		Blocks: []Block{
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: "*" + title + "*"}},
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: itemLines(items)}},
+			{Type: "section", Text: &Text{Type: "mrkdwn", Text: "_Delete the resources you no longer need; CloudSweep policies may clean them up._"}},
+		},
+	}
+}
+
+// itemLines lists resources, one per line
+func itemLines(items []WasteItem) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		name := item.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		lines[i] = fmt.Sprintf("%d. *%s* %s %s %s — %s/month", i+1, name, item.Provider, item.Type, item.Region, money(item.MonthlyCost))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
@@ -0,0 +1,76 @@
+package slack
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/signing"
+)
+
+// Headers of the requests Slack sends to the app
+const (
+	HeaderTimestamp = "X-Slack-Request-Timestamp"
+	HeaderSignature = "X-Slack-Signature"
+)
+
+// maxRequestAge is how old a request from Slack may be, preventing replays
+const maxRequestAge = 5 * time.Minute
+
+// ErrInvalidRequest is returned for requests not signed by Slack, or too old
+var ErrInvalidRequest = errors.New("invalid Slack request signature")
+
+// ErrInvalidState is returned for tampered, malformed or expired
+// installation states
+var ErrInvalidState = errors.New("invalid or expired installation state, start the installation again")
+
+// VerifyRequest checks the signature of a request sent by Slack: the hex
+// HMAC-SHA256 of "v0:<timestamp>:<body>" with the signing secret of the app
+func VerifyRequest(signingSecret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidRequest
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return ErrInvalidRequest
+	}
+	hexMAC, ok := strings.CutPrefix(signature, "v0=")
+	mac, err := hex.DecodeString(hexMAC)
+	if !ok || err != nil || !signing.New(signingSecret).Valid(mac, []byte("v0:"+timestamp+":"), body) {
+		return ErrInvalidRequest
+	}
+	return nil
+}
+
+// InstallState is carried through the Slack installation page to the
+// callback, which installs the app for its organization
+type InstallState struct {
+	OrganizationID string `json:"o"`
+	ExpiresAt      int64  `json:"e"`
+}
+
+// StateSigner signs and verifies installation states
+type StateSigner struct {
+	tokens *signing.Signer
+}
+
+// NewStateSigner creates a new StateSigner
+func NewStateSigner(key string) *StateSigner {
+	return &StateSigner{tokens: signing.New(key)}
+}
+
+// Sign returns the URL-safe state of an installation
+func (s *StateSigner) Sign(st InstallState) string {
+	return s.tokens.Sign("install", st)
+}
+
+// Verify checks the state signature and expiry and returns the state
+func (s *StateSigner) Verify(token string) (InstallState, error) {
+	var st InstallState
+	if err := s.tokens.Verify("install", token, &st); err != nil || time.Now().Unix() > st.ExpiresAt {
+		return InstallState{}, ErrInvalidState
+	}
+	return st, nil
+}
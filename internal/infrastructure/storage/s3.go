@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/sigv4"
+)
+
+// S3Store stores objects in an S3-compatible bucket. Every request, uploads
+// included, uses SigV4 presigned URLs so no SDK is required; Endpoint allows
+// targeting MinIO or other compatible services (path-style addressing).
+type S3Store struct {
+	cfg    config.S3Config
+	client *http.Client
+}
+
+// NewS3Store creates a new S3Store
+func NewS3Store(cfg config.S3Config) *S3Store {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &S3Store{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// Put implements ObjectStore
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	u, err := s.presign(http.MethodPut, key, 15*time.Minute)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	return s.do(req, nil)
+}
+
+// Get implements ObjectStore
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	u, err := s.presign(http.MethodGet, key, 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var body io.ReadCloser
+	if err := s.do(req, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Delete implements ObjectStore
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	u, err := s.presign(http.MethodDelete, key, 15*time.Minute)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, nil)
+}
+
+// SignedURL implements ObjectStore
+func (s *S3Store) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, ttl)
+}
+
+// do executes req; when body is non-nil the response body is handed over
+// to the caller instead of being closed
+func (s *S3Store) do(req *http.Request, body *io.ReadCloser) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 request failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return fmt.Errorf("s3 %s returned %d: %s", req.Method, resp.StatusCode, msg)
+	}
+	if body != nil {
+		*body = resp.Body
+		return nil
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// presign builds a SigV4 query-string authenticated URL
+func (s *S3Store) presign(method, key string, ttl time.Duration) (string, error) {
+	endpoint, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	creds := sigv4.Credentials{
+		AccessKeyID:     s.cfg.AccessKeyID,
+		SecretAccessKey: s.cfg.SecretAccessKey,
+		SessionToken:    s.cfg.SessionToken,
+	}
+	return sigv4.Presign(method, endpoint, "/"+s.cfg.Bucket+"/"+key, creds, s.cfg.Region, "s3", ttl, time.Now()), nil
+}
@@ -0,0 +1,42 @@
+// Package storage provides object storage backends for generated files
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// ErrObjectNotFound is returned when an object does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStore stores generated files and hands out time-limited download links
+type ObjectStore interface {
+	// Put uploads an object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get downloads an object
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes an object
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a download URL valid for ttl
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// New creates the object store configured by cfg
+func New(cfg config.StorageConfig) (ObjectStore, error) {
+	switch cfg.Backend {
+	case "local", "":
+		return NewLocalStore(cfg.LocalPath, cfg.PublicURL, []byte(cfg.SigningKey))
+	case "s3":
+		return NewS3Store(cfg.S3), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Backend)
+	}
+}
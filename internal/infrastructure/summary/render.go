@@ -0,0 +1,75 @@
+package summary
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+var textTemplate = texttemplate.Must(texttemplate.New("summary").Parse(`Hello,
+
+Here is the CloudSweep summary of {{.OrganizationName}} from {{.From.Format "Mon Jan 2"}} to {{.To.Format "Mon Jan 2"}}.
+
+Realized savings: ${{printf "%.2f" .RealizedSavings}}/month from {{.CleanedUp}} resources cleaned up
+Carbon saved: {{printf "%.1f" .CarbonSaved}} kg CO2e/month
+New unused resources: {{.NewUnusedCount}}, costing ${{printf "%.2f" .NewUnusedCost}}/month
+{{range .NewUnused}}
+- {{if .Name}}{{.Name}} ({{.ResourceID}}){{else}}{{.ResourceID}}{{end}}, {{.Type}} in {{.Region}}: ${{printf "%.2f" .MonthlyCost}}/month{{end}}
+
+Upcoming policy runs ({{.Timezone}}):
+{{range .Upcoming}}
+- {{.At.Format "Mon Jan 2 15:04"}}: {{.Policy}} ({{.Actions}}){{if gt .Count 1}}, {{.Count}} runs in the week{{end}}{{else}}
+No policy is scheduled in the coming week.{{end}}
+{{if .DashboardURL}}
+Open the dashboard: {{.DashboardURL}}
+{{end}}
+You receive this email as an admin of {{.OrganizationName}}. Unsubscribe: {{.UnsubscribeURL}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("summary").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<p>Hello,</p>
+<p>Here is the CloudSweep summary of {{.OrganizationName}} from {{.From.Format "Mon Jan 2"}} to {{.To.Format "Mon Jan 2"}}.</p>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr><td>Realized savings</td><td align="right"><strong>${{printf "%.2f" .RealizedSavings}}</strong>/month</td><td>{{.CleanedUp}} resources cleaned up</td></tr>
+<tr><td>Carbon saved</td><td align="right"><strong>{{printf "%.1f" .CarbonSaved}}</strong> kg CO2e/month</td><td></td></tr>
+<tr><td>New unused resources</td><td align="right"><strong>{{.NewUnusedCount}}</strong></td><td>${{printf "%.2f" .NewUnusedCost}}/month</td></tr>
+</table>
+{{if .NewUnused}}<h3>New unused resources</h3>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr><th align="left">Resource</th><th align="left">Type</th><th align="left">Region</th><th align="right">Monthly cost</th></tr>
+{{range .NewUnused}}<tr>
+<td>{{if .Name}}{{.Name}}<br><small>{{.ResourceID}}</small>{{else}}{{.ResourceID}}{{end}}</td>
+<td>{{.Type}}</td>
+<td>{{.Region}}</td>
+<td align="right">${{printf "%.2f" .MonthlyCost}}</td>
+</tr>
+{{end}}</table>
+{{end}}<h3>Upcoming policy runs</h3>
+{{if .Upcoming}}<table cellpadding="6" style="border-collapse: collapse;">
+<tr><th align="left">Next run ({{.Timezone}})</th><th align="left">Policy</th><th align="left">Actions</th><th align="right">Runs in the week</th></tr>
+{{range .Upcoming}}<tr>
+<td>{{.At.Format "Mon Jan 2 15:04"}}</td>
+<td>{{.Policy}}</td>
+<td>{{.Actions}}</td>
+<td align="right">{{.Count}}</td>
+</tr>
+{{end}}</table>
+{{else}}<p>No policy is scheduled in the coming week.</p>
+{{end}}{{if .DashboardURL}}<p><a href="{{.DashboardURL}}">Open the dashboard</a></p>
+{{end}}<p><small>You receive this email as an admin of {{.OrganizationName}}. <a href="{{.UnsubscribeURL}}">Unsubscribe</a></small></p>
+</body>
+</html>
+`))
+
+func render(r *Report) (text, html string, err error) {
+	var tb, hb bytes.Buffer
+	if err := textTemplate.Execute(&tb, r); err != nil {
+		return "", "", err
+	}
+	if err := htmlTemplate.Execute(&hb, r); err != nil {
+		return "", "", err
+	}
+	return tb.String(), hb.String(), nil
+}
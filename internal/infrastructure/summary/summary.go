@@ -0,0 +1,264 @@
+// Package summary emails the admins of each organization a weekly summary:
+// the unused resources found during the week, the policy runs of the week
+// to come, and the savings and carbon emissions cleanups realized.
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/digest"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/notification"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+// period is the week covered by a summary
+const period = 7 * 24 * time.Hour
+
+// maxItems is the number of new unused resources and policy runs listed
+const maxItems = 10
+
+// Resource is an unused resource found during the week
+type Resource struct {
+	Name        string
+	ResourceID  string
+	Type        string
+	Region      string
+	MonthlyCost float64
+}
+
+// Run is the next run of a scheduled policy
+type Run struct {
+	Policy  string
+	Actions string
+	At      time.Time // in the timezone of the summary
+	Count   int       // runs in the coming week
+}
+
+// Report is the weekly summary of an organization
+type Report struct {
+	OrganizationName string
+	From             time.Time // in the timezone of the summary
+	To               time.Time
+	Timezone         string
+
+	NewUnused      []Resource // most expensive first
+	NewUnusedCount int
+	NewUnusedCost  float64
+	Upcoming       []Run
+
+	CleanedUp       int
+	RealizedSavings float64 // monthly cost of the resources cleanups deleted
+	CarbonSaved     float64 // kg CO2e per month
+
+	DashboardURL   string
+	UnsubscribeURL string // set for each recipient
+}
+
+// Result summarizes a summary run
+type Result struct {
+	Organizations int
+	Sent          int
+	Failed        int
+}
+
+// Sender builds and emails the weekly summaries of organizations
+type Sender struct {
+	db        *gorm.DB
+	mailer    notification.Mailer
+	signer    *digest.Signer
+	cfg       config.SummaryConfig
+	digestCfg config.DigestConfig
+}
+
+// NewSender creates a new Sender. Unsubscribe links are signed with the key
+// of owner digest links and point to the digest action endpoint.
+func NewSender(db *gorm.DB, mailer notification.Mailer, cfg config.SummaryConfig, digestCfg config.DigestConfig) *Sender {
+	return &Sender{
+		db:        db,
+		mailer:    mailer,
+		signer:    digest.NewSigner(digestCfg.SigningKey),
+		cfg:       cfg,
+		digestCfg: digestCfg,
+	}
+}
+
+// Run sends the summary of every active organization whose summary is due
+// in the hour of now, to its admins who did not opt out. A failed email
+// does not stop the run; the joined errors are returned with the result.
+func (s *Sender) Run(ctx context.Context, now time.Time) (Result, error) {
+	var res Result
+
+	var orgs []model.Organization
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&orgs).Error; err != nil {
+		return res, fmt.Errorf("failed to load organizations: %w", err)
+	}
+
+	var errs []error
+	for i := range orgs {
+		org := &orgs[i]
+		settings := org.Settings().WeeklySummary
+		if !settings.Due(now, org.WeeklySummarySentAt) {
+			continue
+		}
+
+		var admins []model.Membership
+		err := s.db.WithContext(ctx).Preload("User").
+			Where("organization_id = ? AND role = ? AND weekly_summary_opt_out = ?", org.ID, string(entity.MemberRoleAdmin), false).
+			Find(&admins).Error
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load admins of org %s: %w", org.ID, err))
+			continue
+		}
+
+		if len(admins) > 0 {
+			report, err := s.build(ctx, org, settings, now)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			res.Organizations++
+
+			for _, admin := range admins {
+				if err := s.send(ctx, report, &admin); err != nil {
+					res.Failed++
+					errs = append(errs, fmt.Errorf("summary of org %s for %s: %w", org.ID, admin.User.Email, err))
+					continue
+				}
+				res.Sent++
+			}
+		}
+
+		// Admins who failed are not emailed again: they get next week's summary
+		if err := s.db.WithContext(ctx).Model(org).UpdateColumn("weekly_summary_sent_at", now).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to record summary of org %s: %w", org.ID, err))
+		}
+	}
+
+	return res, errors.Join(errs...)
+}
+
+// build compiles the summary of the week before now
+func (s *Sender) build(ctx context.Context, org *model.Organization, settings entity.WeeklySummary, now time.Time) (*Report, error) {
+	loc := settings.Location()
+	since := now.Add(-period)
+	report := &Report{
+		OrganizationName: org.Name,
+		From:             since.In(loc),
+		To:               now.In(loc),
+		Timezone:         loc.String(),
+		DashboardURL:     s.cfg.DashboardURL,
+	}
+
+	db := s.db.WithContext(ctx)
+	newUnused := db.Model(&model.Resource{}).
+		Where("organization_id = ? AND status = ? AND created_at >= ?", org.ID, string(entity.ResourceStatusUnused), since)
+
+	var totals struct {
+		Count int
+		Cost  float64
+	}
+	if err := newUnused.Session(&gorm.Session{}).Select("COUNT(*) AS count, COALESCE(SUM(monthly_cost), 0) AS cost").Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to count new unused resources of org %s: %w", org.ID, err)
+	}
+	report.NewUnusedCount, report.NewUnusedCost = totals.Count, totals.Cost
+
+	var resources []model.Resource
+	if err := newUnused.Session(&gorm.Session{}).Order("monthly_cost DESC").Limit(maxItems).Find(&resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to load new unused resources of org %s: %w", org.ID, err)
+	}
+	for _, r := range resources {
+		report.NewUnused = append(report.NewUnused, Resource{
+			Name:        r.Name,
+			ResourceID:  r.ResourceID,
+			Type:        r.Type,
+			Region:      r.Region,
+			MonthlyCost: r.MonthlyCost,
+		})
+	}
+
+	// Resources deleted by a successful cleanup call, not those missing
+	// from a scan
+	var realized struct {
+		Count  int
+		Cost   float64
+		Carbon float64
+	}
+	err := db.Model(&model.Resource{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(monthly_cost), 0) AS cost, COALESCE(SUM(carbon_footprint), 0) AS carbon").
+		Where("organization_id = ? AND status = ? AND updated_at >= ?", org.ID, string(entity.ResourceStatusDeleted), since).
+		Where("EXISTS (SELECT 1 FROM provider_calls pc WHERE pc.resource_id = resources.id AND COALESCE(pc.error, '') = '' AND pc.called_at >= ?)", since).
+		Scan(&realized).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum realized savings of org %s: %w", org.ID, err)
+	}
+	report.CleanedUp, report.RealizedSavings, report.CarbonSaved = realized.Count, realized.Cost, realized.Carbon
+
+	var policies []model.Policy
+	if err := db.Where("organization_id = ? AND is_enabled = ? AND schedule <> ''", org.ID, true).Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load policies of org %s: %w", org.ID, err)
+	}
+	report.Upcoming = upcomingRuns(policies, now, loc)
+
+	return report, nil
+}
+
+// upcomingRuns returns the next run of the scheduled policies running in
+// the week after now, soonest first. Policy schedules are evaluated in UTC.
+func upcomingRuns(policies []model.Policy, now time.Time, loc *time.Location) []Run {
+	var runs []Run
+	end := now.Add(period)
+	for _, p := range policies {
+		schedule, err := cron.ParseStandard(p.Schedule)
+		if err != nil {
+			log.Printf("Weekly summary: skipping policy %s with invalid schedule %q", p.ID, p.Schedule)
+			continue
+		}
+		next := schedule.Next(now.UTC())
+		if next.IsZero() || !next.Before(end) {
+			continue
+		}
+		run := Run{Policy: p.Name, Actions: strings.Join(p.Actions, ", "), At: next.In(loc)}
+		for at := next; !at.IsZero() && at.Before(end); at = schedule.Next(at) {
+			run.Count++
+		}
+		runs = append(runs, run)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].At.Before(runs[j].At) })
+	if len(runs) > maxItems {
+		runs = runs[:maxItems]
+	}
+	return runs
+}
+
+func (s *Sender) send(ctx context.Context, report *Report, admin *model.Membership) error {
+	personal := *report
+	personal.UnsubscribeURL = strings.TrimRight(s.digestCfg.PublicURL, "/") + "/api/v1/digest/action?token=" + url.QueryEscape(s.signer.Sign(digest.Action{
+		Kind:           digest.ActionUnsubscribe,
+		Owner:          admin.User.Email,
+		OrganizationID: admin.OrganizationID.String(),
+		UserID:         admin.UserID.String(),
+		ExpiresAt:      time.Now().Add(s.digestCfg.LinkTTL).Unix(),
+	}))
+
+	text, html, err := render(&personal)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, notification.Message{
+		To:      []string{admin.User.Email},
+		Subject: fmt.Sprintf("[CloudSweep] Weekly summary of %s: $%.2f/month saved, %d new unused resources", report.OrganizationName, report.RealizedSavings, report.NewUnusedCount),
+		Text:    text,
+		HTML:    html,
+	})
+}
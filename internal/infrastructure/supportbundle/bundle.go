@@ -0,0 +1,459 @@
+// Package supportbundle exports anonymized diagnostics to share with maintainers
+package supportbundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/pkg/anonymize"
+	"gorm.io/gorm"
+)
+
+// maxLogLines is the number of trailing lines kept from each log file
+const maxLogLines = 5000
+
+// minNameLength is the length under which known names are left in free-form
+// text, short values matching too many unrelated words
+const minNameLength = 4
+
+// Options controls the content of a bundle
+type Options struct {
+	Output     string
+	LogFiles   []string
+	SampleSize int
+	// Demo produces a single JSON export with readable pseudonyms instead of
+	// a diagnostic archive, for screenshots and demo environments
+	Demo bool
+}
+
+// Generator builds support bundles from the database and configuration
+type Generator struct {
+	db      *gorm.DB
+	cfg     *config.Config
+	version string
+	anon    *anonymize.Anonymizer
+	// names pseudonymizes the organization, account, user and resource
+	// names of the database found in free-form text
+	names *strings.Replacer
+}
+
+// NewGenerator creates a new Generator
+func NewGenerator(db *gorm.DB, cfg *config.Config, version string) (*Generator, error) {
+	anon, err := anonymize.New()
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{
+		db:      db,
+		cfg:     cfg,
+		version: version,
+		anon:    anon,
+	}, nil
+}
+
+// Manifest describes the content of a bundle
+type Manifest struct {
+	Version     string    `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Files       []string  `json:"files"`
+}
+
+// TableStats holds size information for a table
+type TableStats struct {
+	Table       string `json:"table"`
+	RowEstimate int64  `json:"row_estimate"`
+	TotalBytes  int64  `json:"total_bytes"`
+}
+
+// ResourceBreakdown counts resources per provider, type and status
+type ResourceBreakdown struct {
+	Provider string `json:"provider"`
+	Type     string `json:"type"`
+	Status   string `json:"status"`
+	Count    int64  `json:"count"`
+}
+
+// SchemaStats holds database statistics
+type SchemaStats struct {
+	Tables    []TableStats        `json:"tables"`
+	Resources []ResourceBreakdown `json:"resources"`
+}
+
+// SampleResource is an anonymized resource
+type SampleResource struct {
+	ID              string            `json:"id"`
+	OrganizationID  string            `json:"organization_id"`
+	Provider        string            `json:"provider"`
+	Type            string            `json:"type"`
+	ResourceID      string            `json:"resource_id"`
+	Region          string            `json:"region"`
+	Name            string            `json:"name"`
+	Status          string            `json:"status"`
+	Tags            map[string]string `json:"tags"`
+	Metadata        any               `json:"metadata"`
+	MonthlyCost     float64           `json:"monthly_cost"`
+	CarbonFootprint float64           `json:"carbon_footprint_kg"`
+	LastSeenAt      time.Time         `json:"last_seen_at"`
+}
+
+// SampleScan is an anonymized scan
+type SampleScan struct {
+	ID               string     `json:"id"`
+	OrganizationID   string     `json:"organization_id"`
+	Provider         string     `json:"provider"`
+	Regions          []string   `json:"regions"`
+	ResourceTypes    []string   `json:"resource_types"`
+	Status           string     `json:"status"`
+	ResourcesFound   int        `json:"resources_found"`
+	UnusedFound      int        `json:"unused_found"`
+	EstimatedSavings float64    `json:"estimated_savings"`
+	ErrorMessage     string     `json:"error_message,omitempty"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+}
+
+// DemoExport is the content written in demo mode
+type DemoExport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Resources   []SampleResource `json:"resources"`
+	Scans       []SampleScan     `json:"scans"`
+}
+
+// Generate writes the bundle to opts.Output
+func (g *Generator) Generate(ctx context.Context, opts Options) error {
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = 100
+	}
+	db := g.db.WithContext(ctx)
+	if !opts.Demo {
+		if err := g.loadNames(db); err != nil {
+			return err
+		}
+	}
+
+	resources, err := g.sampleResources(db, opts.SampleSize, opts.Demo)
+	if err != nil {
+		return err
+	}
+	scans, err := g.sampleScans(db, opts.SampleSize, opts.Demo)
+	if err != nil {
+		return err
+	}
+
+	if opts.Demo {
+		return writeJSONFile(opts.Output, DemoExport{
+			GeneratedAt: time.Now(),
+			Resources:   resources,
+			Scans:       scans,
+		})
+	}
+
+	stats, err := g.schemaStats(db)
+	if err != nil {
+		return err
+	}
+	cfg, err := g.config()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	return g.writeArchive(f, map[string]any{
+		"config.json":           cfg,
+		"schema.json":           stats,
+		"sample/resources.json": resources,
+		"sample/scans.json":     scans,
+	}, opts.LogFiles)
+}
+
+// writeArchive writes the entries encoded in JSON and the anonymized log
+// files as a gzipped tar archive
+func (g *Generator) writeArchive(w io.Writer, entries map[string]any, logFiles []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifest := Manifest{Version: g.version, GeneratedAt: time.Now()}
+
+	for name, v := range entries {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		if err := addFile(tw, name, data); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+
+	for _, path := range logFiles {
+		data, err := g.readLog(path)
+		if err != nil {
+			return err
+		}
+		name := "logs/" + filepath.Base(path)
+		if err := addFile(tw, name, data); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := addFile(tw, "manifest.json", data); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+// config returns the redacted configuration with the identifiers of its
+// settings (sender address, tenant, subscription...) anonymized
+func (g *Generator) config() (any, error) {
+	data, err := json.Marshal(g.cfg.Redacted())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	var cfg any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return g.anonymizeStrings(cfg), nil
+}
+
+// anonymizeStrings anonymizes the strings of a JSON-like value, walking maps
+// and slices recursively
+func (g *Generator) anonymizeStrings(v any) any {
+	switch val := v.(type) {
+	case string:
+		return g.anonymizeText(val)
+	case map[string]any:
+		for k, item := range val {
+			val[k] = g.anonymizeStrings(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = g.anonymizeStrings(item)
+		}
+	}
+	return v
+}
+
+// loadNames collects the names identifying organizations, accounts, users
+// and resources, which no pattern recognizes in free-form text
+func (g *Generator) loadNames(db *gorm.DB) error {
+	sources := []struct {
+		kind   string
+		model  any
+		column string
+	}{
+		{"org", &model.Organization{}, "name"},
+		{"org", &model.Organization{}, "slug"},
+		{"account", &model.CloudAccount{}, "name"},
+		{"account", &model.CloudAccount{}, "account_id"},
+		{"user", &model.User{}, "name"},
+		{"resource", &model.Resource{}, "name"},
+		{"resource", &model.Resource{}, "resource_id"},
+	}
+
+	pseudonyms := make(map[string]string)
+	for _, src := range sources {
+		var values []string
+		if err := db.Model(src.model).Distinct().Pluck(src.column, &values).Error; err != nil {
+			return fmt.Errorf("failed to read %s names: %w", src.kind, err)
+		}
+		for _, v := range values {
+			if len(v) >= minNameLength {
+				pseudonyms[v] = g.anon.Pseudonym(src.kind, v)
+			}
+		}
+	}
+	if g.cfg.GCP.ProjectID != "" {
+		pseudonyms[g.cfg.GCP.ProjectID] = g.anon.Pseudonym("project", g.cfg.GCP.ProjectID)
+	}
+
+	// The replacer tries names in order: longer names go first so a name is
+	// not partially replaced by one of its prefixes
+	names := make([]string, 0, len(pseudonyms))
+	for name := range pseudonyms {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	pairs := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		pairs = append(pairs, name, pseudonyms[name])
+	}
+	g.names = strings.NewReplacer(pairs...)
+	return nil
+}
+
+func (g *Generator) sampleResources(db *gorm.DB, limit int, demo bool) ([]SampleResource, error) {
+	var resources []model.Resource
+	if err := db.Order("updated_at DESC").Limit(limit).Find(&resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to sample resources: %w", err)
+	}
+
+	out := make([]SampleResource, 0, len(resources))
+	for _, r := range resources {
+		s := SampleResource{
+			ID:              g.anon.Hash(r.ID.String()),
+			OrganizationID:  g.anon.Hash(r.OrganizationID.String()),
+			Provider:        r.Provider,
+			Type:            r.Type,
+			ResourceID:      g.anon.Hash(r.ResourceID),
+			Region:          r.Region,
+			Name:            g.anon.Hash(r.Name),
+			Status:          r.Status,
+			Tags:            g.anon.Tags(r.Tags),
+			MonthlyCost:     r.MonthlyCost,
+			CarbonFootprint: r.CarbonFootprint,
+			LastSeenAt:      r.LastSeenAt,
+		}
+		if demo {
+			s.ResourceID = g.anon.Pseudonym(r.Type, r.ResourceID)
+			s.Name = g.anon.Pseudonym(r.Type, r.Name)
+		} else {
+			s.Metadata = g.anon.Value(map[string]any(r.Metadata))
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (g *Generator) sampleScans(db *gorm.DB, limit int, demo bool) ([]SampleScan, error) {
+	var scans []model.Scan
+	if err := db.Order("created_at DESC").Limit(limit).Find(&scans).Error; err != nil {
+		return nil, fmt.Errorf("failed to sample scans: %w", err)
+	}
+
+	out := make([]SampleScan, 0, len(scans))
+	for _, s := range scans {
+		sample := SampleScan{
+			ID:               g.anon.Hash(s.ID.String()),
+			OrganizationID:   g.anon.Hash(s.OrganizationID.String()),
+			Provider:         s.Provider,
+			Regions:          s.Regions,
+			ResourceTypes:    s.ResourceTypes,
+			Status:           s.Status,
+			ResourcesFound:   s.ResourcesFound,
+			UnusedFound:      s.UnusedFound,
+			EstimatedSavings: s.EstimatedSavings,
+			StartedAt:        s.StartedAt,
+			CompletedAt:      s.CompletedAt,
+		}
+		// Error messages help debugging but may embed account details
+		if !demo {
+			sample.ErrorMessage = g.anonymizeText(s.ErrorMessage)
+		}
+		out = append(out, sample)
+	}
+	return out, nil
+}
+
+func (g *Generator) schemaStats(db *gorm.DB) (*SchemaStats, error) {
+	stats := &SchemaStats{}
+
+	err := db.Raw(`SELECT relname AS "table", n_live_tup AS row_estimate,
+		pg_total_relation_size(relid) AS total_bytes
+		FROM pg_stat_user_tables ORDER BY relname`).Scan(&stats.Tables).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table statistics: %w", err)
+	}
+
+	err = db.Model(&model.Resource{}).
+		Select("provider, type, status, COUNT(*) as count").
+		Group("provider, type, status").
+		Scan(&stats.Resources).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count resources: %w", err)
+	}
+
+	return stats, nil
+}
+
+// readLog returns the last maxLogLines lines of a log file with secrets
+// scrubbed and identifiers anonymized
+func (g *Generator) readLog(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer f.Close()
+
+	lines := make([]string, 0, maxLogLines)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(lines) == maxLogLines {
+			lines = lines[1:]
+		}
+		lines = append(lines, g.anonymizeText(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// scrub removes configured secret values from free-form text
+func (g *Generator) scrub(s string) string {
+	for _, secret := range g.cfg.Secrets() {
+		s = strings.ReplaceAll(s, secret, "[REDACTED]")
+	}
+	return s
+}
+
+// anonymizeText scrubs secrets from free-form text, then pseudonymizes the
+// known names and the identifiers it contains
+func (g *Generator) anonymizeText(s string) string {
+	s = g.scrub(s)
+	if g.names != nil {
+		s = g.names.Replace(s)
+	}
+	return g.anon.Text(s)
+}
+
+func addFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
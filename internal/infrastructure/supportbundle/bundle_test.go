@@ -0,0 +1,97 @@
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/pkg/anonymize"
+)
+
+func TestWriteArchiveAnonymizesLogsAndConfig(t *testing.T) {
+	const (
+		email     = "alice@example.com"
+		accountID = "123456789012"
+		orgName   = "Acme Corp"
+		password  = "s3cr3t-password"
+	)
+
+	logFile := filepath.Join(t.TempDir(), "worker.log")
+	lines := []string{
+		"2026/10/16 09:12:03 Scan requested by " + email + " for " + orgName,
+		"2026/10/16 09:12:04 Deleting arn:aws:ec2:eu-west-1:" + accountID + ":volume/vol-0abc1234def567890",
+		"2026/10/16 09:12:05 Assuming role in account " + accountID,
+		"2026/10/16 09:12:06 Connecting with password " + password,
+	}
+	if err := os.WriteFile(logFile, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.SMTP.From = email
+	cfg.SMTP.Password = password
+	cfg.Database.Password = password
+
+	anon, err := anonymize.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Generator{cfg: cfg, version: "test", anon: anon, names: strings.NewReplacer(orgName, anon.Pseudonym("org", orgName))}
+
+	configJSON, err := g.config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := g.writeArchive(&buf, map[string]any{"config.json": configJSON}, []string{logFile}); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readArchive(t, &buf)
+	for _, name := range []string{"config.json", "logs/worker.log", "manifest.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle misses %s", name)
+		}
+	}
+	for name, content := range files {
+		for _, leak := range []string{email, accountID, orgName, password} {
+			if strings.Contains(content, leak) {
+				t.Errorf("%s contains %q:\n%s", name, leak, content)
+			}
+		}
+	}
+	if log := files["logs/worker.log"]; !strings.Contains(log, "Deleting arn-") || !strings.Contains(log, "in account account-") {
+		t.Errorf("log identifiers are not pseudonymized:\n%s", log)
+	}
+}
+
+// readArchive returns the content of each file of a gzipped tar archive
+func readArchive(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(data)
+	}
+}
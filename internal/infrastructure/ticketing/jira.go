@@ -0,0 +1,138 @@
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+)
+
+// Jira issue defaults
+const (
+	DefaultJiraIssueType      = "Task"
+	DefaultJiraDoneTransition = "Done"
+)
+
+// jiraClient calls the REST API v2 of Jira Cloud or Data Center with basic
+// authentication: an account email and API token, or a username and
+// password
+type jiraClient struct {
+	http *http.Client
+}
+
+// Create opens an issue in the project of the integration
+func (c *jiraClient) Create(ctx context.Context, integration *model.TicketIntegration, t content) (*created, error) {
+	issueType := integration.IssueType
+	if issueType == "" {
+		issueType = DefaultJiraIssueType
+	}
+	body := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": integration.Project},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     t.Summary,
+			"description": t.Description,
+			"labels":      t.Labels,
+		},
+	}
+	var resp struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, integration, http.MethodPost, "/rest/api/2/issue", body, &resp); err != nil {
+		return nil, err
+	}
+	return &created{
+		ID:  resp.ID,
+		Key: resp.Key,
+		URL: strings.TrimRight(integration.BaseURL, "/") + "/browse/" + resp.Key,
+	}, nil
+}
+
+// Comment adds a comment to an issue
+func (c *jiraClient) Comment(ctx context.Context, integration *model.TicketIntegration, id, text string) error {
+	return c.do(ctx, integration, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(id)+"/comment", map[string]string{"body": text}, nil)
+}
+
+// Close comments an issue and moves it through the done transition of the
+// integration, the first transition to a done status when it is not
+// available
+func (c *jiraClient) Close(ctx context.Context, integration *model.TicketIntegration, id, text string) error {
+	if err := c.Comment(ctx, integration, id, text); err != nil {
+		return err
+	}
+
+	var resp struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(id) + "/transitions"
+	if err := c.do(ctx, integration, http.MethodGet, path, nil, &resp); err != nil {
+		return err
+	}
+	name := integration.DoneTransition
+	if name == "" {
+		name = DefaultJiraDoneTransition
+	}
+	transition := ""
+	for _, t := range resp.Transitions {
+		if strings.EqualFold(t.Name, name) {
+			transition = t.ID
+			break
+		}
+		if transition == "" && t.To.StatusCategory.Key == "done" {
+			transition = t.ID
+		}
+	}
+	if transition == "" {
+		// Issues already done have no transition to done left
+		return nil
+	}
+	return c.do(ctx, integration, http.MethodPost, path, map[string]any{"transition": map[string]string{"id": transition}}, nil)
+}
+
+func (c *jiraClient) do(ctx context.Context, integration *model.TicketIntegration, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(integration.BaseURL, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(integration.Username, integration.APIToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError("jira", resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("jira %s %s: invalid response: %w", method, path, err)
+		}
+	}
+	return nil
+}
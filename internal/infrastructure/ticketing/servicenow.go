@@ -0,0 +1,94 @@
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+)
+
+// serviceNowStateClosed is the state of closed change requests
+const serviceNowStateClosed = "3"
+
+// serviceNowClient opens change requests through the Table API of a
+// ServiceNow instance, with basic authentication
+type serviceNowClient struct {
+	http *http.Client
+}
+
+// Create opens a normal change request, assigned to the group of the
+// integration
+func (c *serviceNowClient) Create(ctx context.Context, integration *model.TicketIntegration, t content) (*created, error) {
+	body := map[string]string{
+		"type":              "normal",
+		"short_description": t.Summary,
+		"description":       t.Description,
+		"category":          "Cloud",
+	}
+	if integration.AssignmentGroup != "" {
+		body["assignment_group"] = integration.AssignmentGroup
+	}
+	var resp struct {
+		Result struct {
+			SysID  string `json:"sys_id"`
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	if err := c.do(ctx, integration, http.MethodPost, "/api/now/table/change_request", body, &resp); err != nil {
+		return nil, err
+	}
+	return &created{
+		ID:  resp.Result.SysID,
+		Key: resp.Result.Number,
+		URL: strings.TrimRight(integration.BaseURL, "/") + "/nav_to.do?uri=" +
+			url.QueryEscape("change_request.do?sys_id="+resp.Result.SysID),
+	}, nil
+}
+
+// Comment adds a work note to a change request
+func (c *serviceNowClient) Comment(ctx context.Context, integration *model.TicketIntegration, id, text string) error {
+	return c.do(ctx, integration, http.MethodPatch, "/api/now/table/change_request/"+url.PathEscape(id), map[string]string{"work_notes": text}, nil)
+}
+
+// Close closes a change request as successful
+func (c *serviceNowClient) Close(ctx context.Context, integration *model.TicketIntegration, id, text string) error {
+	return c.do(ctx, integration, http.MethodPatch, "/api/now/table/change_request/"+url.PathEscape(id), map[string]string{
+		"state":       serviceNowStateClosed,
+		"close_code":  "successful",
+		"close_notes": text,
+	}, nil)
+}
+
+func (c *serviceNowClient) do(ctx context.Context, integration *model.TicketIntegration, method, path string, body map[string]string, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(integration.BaseURL, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(integration.Username, integration.APIToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("servicenow %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError("servicenow", resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("servicenow %s %s: invalid response: %w", method, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Package ticketing opens Jira issues and ServiceNow change requests for the
+// resources matched by policies with the ticket action, and updates and
+// closes them once the resources are remediated.
+package ticketing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"gorm.io/gorm"
+)
+
+// Ticket states
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+)
+
+// content is the ticket opened for a resource
+type content struct {
+	Summary     string
+	Description string
+	Labels      []string
+}
+
+// created is a ticket opened in a tracker
+type created struct {
+	ID  string // Jira issue ID or ServiceNow sys_id
+	Key string
+	URL string
+}
+
+// client opens, comments and closes the tickets of a tracker
+type client interface {
+	Create(ctx context.Context, integration *model.TicketIntegration, c content) (*created, error)
+	Comment(ctx context.Context, integration *model.TicketIntegration, id, text string) error
+	Close(ctx context.Context, integration *model.TicketIntegration, id, text string) error
+}
+
+// Tracker opens tickets in the tracker of each organization and follows
+// the resources they were opened for
+type Tracker struct {
+	db      *gorm.DB
+	cfg     config.TicketingConfig
+	clients map[string]client
+}
+
+var _ service.TicketTracker = (*Tracker)(nil)
+
+// NewTracker creates a Tracker with the Jira and ServiceNow clients
+func NewTracker(db *gorm.DB, cfg config.TicketingConfig) *Tracker {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	return &Tracker{
+		db:  db,
+		cfg: cfg,
+		clients: map[string]client{
+			service.TicketSystemJira:       &jiraClient{http: httpClient},
+			service.TicketSystemServiceNow: &serviceNowClient{http: httpClient},
+		},
+	}
+}
+
+// OpenTicket opens a ticket with the details of the resource, its owner and
+// the savings of its cleanup, or returns the one still open for it
+func (t *Tracker) OpenTicket(ctx context.Context, resource *entity.Resource, taskID string) (*service.Ticket, error) {
+	var open model.Ticket
+	err := t.db.WithContext(ctx).
+		Where("resource_id = ? AND state = ?", resource.ID, StateOpen).
+		First(&open).Error
+	if err == nil {
+		return toTicket(&open), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up open tickets: %w", err)
+	}
+
+	var integration model.TicketIntegration
+	err = t.db.WithContext(ctx).Preload("Organization").First(&integration, "organization_id = ?", resource.OrganizationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("no ticket tracker is configured for the organization")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket integration: %w", err)
+	}
+	c, ok := t.clients[integration.System]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ticket system %q", integration.System)
+	}
+
+	owner := resource.Owner
+	if owner == "" {
+		owner = integration.Organization.Settings().OwnerRules.ResolveOwner(resource.Tags)
+	}
+	ticket, err := c.Create(ctx, &integration, t.content(resource, owner))
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.Ticket{
+		OrganizationID: resource.OrganizationID,
+		ResourceID:     resource.ID,
+		TaskID:         taskID,
+		System:         integration.System,
+		ExternalID:     ticket.ID,
+		Key:            ticket.Key,
+		URL:            ticket.URL,
+		State:          StateOpen,
+		ResourceStatus: string(resource.Status),
+	}
+	if err := t.db.WithContext(ctx).Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to save ticket %s: %w", ticket.Key, err)
+	}
+	return toTicket(record), nil
+}
+
+// Sync reports the changes of the resources of open tickets: quarantined
+// resources are commented, and tickets are closed once their resource is
+// deleted, removed from the inventory, excluded or back in use. It returns
+// the number of tickets closed. Tickets whose tracker was removed are
+// closed without calling it.
+func (t *Tracker) Sync(ctx context.Context) (int, error) {
+	var tickets []model.Ticket
+	if err := t.db.WithContext(ctx).Preload("Resource").Where("state = ?", StateOpen).Find(&tickets).Error; err != nil {
+		return 0, fmt.Errorf("failed to load open tickets: %w", err)
+	}
+
+	integrations := map[string]*model.TicketIntegration{}
+	closed := 0
+	var errs []error
+	for i := range tickets {
+		ticket := &tickets[i]
+		status, note, done := remediation(ticket.Resource, ticket.ResourceStatus)
+		if status == ticket.ResourceStatus && !done {
+			continue
+		}
+
+		orgID := ticket.OrganizationID.String()
+		integration, ok := integrations[orgID]
+		if !ok {
+			var found model.TicketIntegration
+			err := t.db.WithContext(ctx).First(&found, "organization_id = ?", ticket.OrganizationID).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				errs = append(errs, fmt.Errorf("failed to get ticket integration of %s: %w", orgID, err))
+				continue
+			}
+			if err == nil && found.System == ticket.System {
+				integration = &found
+			}
+			integrations[orgID] = integration
+		}
+
+		if integration != nil {
+			c := t.clients[ticket.System]
+			var err error
+			if done {
+				err = c.Close(ctx, integration, ticket.ExternalID, note)
+			} else if note != "" {
+				err = c.Comment(ctx, integration, ticket.ExternalID, note)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to update ticket %s: %w", ticket.Key, err))
+				continue
+			}
+		}
+
+		updates := map[string]any{"resource_status": status}
+		if done {
+			now := time.Now()
+			updates["state"] = StateClosed
+			updates["closed_at"] = &now
+			closed++
+		}
+		if err := t.db.WithContext(ctx).Model(ticket).Updates(updates).Error; err != nil {
+			errs = append(errs, fmt.Errorf("failed to update ticket %s: %w", ticket.ID, err))
+		}
+	}
+	return closed, errors.Join(errs...)
+}
+
+// remediation returns the status of the resource of a ticket, the note
+// reporting its change since the previous status and whether the ticket is
+// done with
+func remediation(r *model.Resource, previous string) (status, note string, done bool) {
+	if r == nil {
+		return string(entity.ResourceStatusRemoved), "The resource was removed from CloudSweep.", true
+	}
+	status = r.Status
+	switch entity.ResourceStatus(r.Status) {
+	case entity.ResourceStatusDeleted:
+		return status, "The resource was deleted. Closed by CloudSweep.", true
+	case entity.ResourceStatusRemoved:
+		return status, "The resource was removed from the CloudSweep inventory. Closed by CloudSweep.", true
+	case entity.ResourceStatusExcluded:
+		return status, "The resource was excluded from cleanups. Closed by CloudSweep.", true
+	case entity.ResourceStatusActive:
+		if previous != status {
+			return status, "The resource is in use again. Closed by CloudSweep.", true
+		}
+		return status, "", false
+	}
+	if r.QuarantinedAt != nil && r.QuarantineUntil != nil {
+		status = "quarantined"
+		note = fmt.Sprintf("The resource was quarantined; it will be deleted after %s.", r.QuarantineUntil.UTC().Format(time.RFC3339))
+	}
+	return status, note, false
+}
+
+// content renders the ticket of a resource
+func (t *Tracker) content(r *entity.Resource, owner string) content {
+	name := r.Name
+	if name == "" {
+		name = r.ResourceID
+	}
+	lines := []string{
+		fmt.Sprintf("CloudSweep flagged this %s %s as unused.", r.Provider, r.Type),
+		"",
+		"Resource: " + r.ResourceID,
+		"Name: " + name,
+		"Type: " + string(r.Type),
+		"Region: " + r.Region,
+	}
+	if r.AccountID != "" {
+		lines = append(lines, "Account: "+r.AccountID)
+	}
+	if owner == "" {
+		owner = "unknown"
+	}
+	lines = append(lines,
+		"Owner: "+owner,
+		fmt.Sprintf("Estimated savings: $%.2f/month", r.MonthlyCost),
+	)
+	if link := t.resourceLink(r); link != "" {
+		lines = append(lines, "", "Open in CloudSweep: "+link)
+	}
+	lines = append(lines, "", "This ticket is closed automatically once the resource is deleted or in use again.")
+
+	return content{
+		Summary:     fmt.Sprintf("Unused %s %s in %s ($%.2f/month)", r.Type, name, r.Region, r.MonthlyCost),
+		Description: strings.Join(lines, "\n"),
+		Labels:      []string{"cloudsweep", string(r.Provider)},
+	}
+}
+
+// resourceLink returns the page of a resource in CloudSweep
+func (t *Tracker) resourceLink(r *entity.Resource) string {
+	if t.cfg.ResourceURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(t.cfg.ResourceURL, "{id}", r.ID.String())
+}
+
+func toTicket(m *model.Ticket) *service.Ticket {
+	return &service.Ticket{System: m.System, Key: m.Key, URL: m.URL}
+}
+
+// statusError returns the error of a non-2xx response
+func statusError(system string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s returned %d: %s", system, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+)
+
+// Request headers sent with every event. Receivers recompute the signature,
+// the hex HMAC-SHA256 of "<timestamp>.<body>" with the shared secret, and
+// should reject stale timestamps to prevent replays.
+const (
+	HeaderEvent     = "X-CloudSweep-Event"
+	HeaderTimestamp = "X-CloudSweep-Timestamp"
+	HeaderSignature = "X-CloudSweep-Signature"
+)
+
+// StatusError is returned when the endpoint answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook endpoint returned %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether a failed delivery may succeed later. Client
+// errors other than timeouts and rate limiting are permanent.
+func Retryable(err error) bool {
+	var status *StatusError
+	if !errors.As(err, &status) {
+		return true
+	}
+	return status.StatusCode >= 500 ||
+		status.StatusCode == http.StatusRequestTimeout ||
+		status.StatusCode == http.StatusTooManyRequests
+}
+
+// Client posts signed JSON events to a webhook endpoint
+type Client struct {
+	url    string
+	secret []byte
+	http   *http.Client
+}
+
+// NewClient creates a client for the configured scan webhook
+func NewClient(cfg config.WebhookConfig) *Client {
+	return &Client{
+		url:    cfg.ScanURL,
+		secret: []byte(cfg.Secret),
+		http:   &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Enabled reports whether an endpoint is configured
+func (c *Client) Enabled() bool {
+	return c.url != ""
+}
+
+// Post sends the payload as the given event
+func (c *Client) Post(ctx context.Context, event string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CloudSweep-Webhook")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(c.secret, timestamp, body))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(msg)}
+	}
+	return nil
+}
+
+// Sign returns the hex signature of a request body sent at timestamp
+func Sign(secret []byte, timestamp string, body []byte) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(timestamp))
+	m.Write([]byte("."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
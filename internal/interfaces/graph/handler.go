@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/middleware"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// maxComplexity bounds the number of fields a query selects, nested
+// selections included
+const maxComplexity = 1000
+
+// NewHandler returns the gin handler of GraphQL queries, sent with GET or
+// POST. Sessions scope queries to their organization, as on the dashboard
+// routes.
+func NewHandler(resolver *Resolver) gin.HandlerFunc {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(maxComplexity))
+	srv.SetErrorPresenter(presentError)
+	srv.SetRecoverFunc(recoverPanic)
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if sess, ok := middleware.CurrentSession(c); ok {
+			orgID, err := uuid.Parse(sess.OrganizationID)
+			if err != nil {
+				apierror.Respond(c, http.StatusForbidden, "invalid session")
+				return
+			}
+			ctx = withSession(ctx, orgID)
+		}
+		ctx = withLoaders(ctx, NewLoaders(resolver.organizations, resolver.dashboards))
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// presentError adds the code of the errors of resolvers to their
+// extensions, with the codes of the REST API (see pkg/errors.CodeOf).
+// Syntax, validation and argument errors are worded by gqlgen.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return gqlErr
+	}
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]any{}
+	}
+	gqlErr.Extensions["code"] = apperrors.CodeOf(appErr)
+	return gqlErr
+}
+
+// recoverPanic reports the panic of a resolver as an internal error
+func recoverPanic(ctx context.Context, p any) error {
+	log.Printf("GraphQL resolver panic at %s: %v", graphql.GetPath(ctx), p)
+	return apperrors.NewWithCode(apperrors.ErrInternalError, apperrors.CodeInternal, "internal error")
+}
@@ -0,0 +1,356 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/oidc"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/middleware"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+)
+
+// signer signs the sessions of test requests
+var signer = oidc.NewSigner("test-signing-key")
+
+// errDatabase is a failure of the database
+var errDatabase = errors.New("connection refused")
+
+// fakeOrganizations is an OrganizationService recording its batches
+type fakeOrganizations struct {
+	usecase.OrganizationService
+	orgs     map[uuid.UUID]*entity.Organization
+	accounts []*entity.CloudAccount
+
+	mu             sync.Mutex
+	orgBatches     [][]uuid.UUID
+	accountBatches [][]uuid.UUID
+}
+
+func (f *fakeOrganizations) Get(ctx context.Context, id uuid.UUID) (*entity.Organization, error) {
+	if org, ok := f.orgs[id]; ok {
+		return org, nil
+	}
+	return nil, apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "organization not found")
+}
+
+func (f *fakeOrganizations) GetMany(ctx context.Context, ids []uuid.UUID) ([]*entity.Organization, error) {
+	f.mu.Lock()
+	f.orgBatches = append(f.orgBatches, ids)
+	f.mu.Unlock()
+	var orgs []*entity.Organization
+	for _, id := range ids {
+		if org, ok := f.orgs[id]; ok {
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs, nil
+}
+
+func (f *fakeOrganizations) Accounts(ctx context.Context, orgIDs []uuid.UUID) ([]*entity.CloudAccount, error) {
+	f.mu.Lock()
+	f.accountBatches = append(f.accountBatches, orgIDs)
+	f.mu.Unlock()
+	var accounts []*entity.CloudAccount
+	for _, account := range f.accounts {
+		if slices.Contains(orgIDs, account.OrganizationID) {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+// fakeResources is a ResourceService whose methods are set by each test
+type fakeResources struct {
+	usecase.ResourceService
+	list func(usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error)
+	get  func(uuid.UUID) (*entity.Resource, error)
+}
+
+func (f *fakeResources) List(ctx context.Context, input usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error) {
+	return f.list(input)
+}
+
+func (f *fakeResources) Get(ctx context.Context, id uuid.UUID) (*entity.Resource, error) {
+	return f.get(id)
+}
+
+// fakeDashboards is a DashboardService recording the batches of account
+// summaries
+type fakeDashboards struct {
+	usecase.DashboardService
+	mu      sync.Mutex
+	batches [][]repository.AccountKey
+}
+
+func (f *fakeDashboards) AccountSummaries(ctx context.Context, accounts []repository.AccountKey) (map[repository.AccountKey]repository.InventoryTotals, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, accounts)
+	f.mu.Unlock()
+	totals := make(map[repository.AccountKey]repository.InventoryTotals, len(accounts))
+	for _, account := range accounts {
+		if account.AccountID == "111111111111" {
+			totals[account] = repository.InventoryTotals{Resources: 3, Unused: 1, MonthlyCost: 270, WasteCost: 80}
+		}
+	}
+	return totals, nil
+}
+
+// response is the body of a GraphQL response
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// query posts a GraphQL query, signed in to orgID unless it is empty
+func query(t *testing.T, h gin.HandlerFunc, orgID, q string) response {
+	t.Helper()
+	r := gin.New()
+	r.Use(middleware.Session(signer))
+	r.POST("/graphql", h)
+
+	body, _ := json.Marshal(map[string]string{"query": q})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if orgID != "" {
+		token := signer.SignSession(oidc.Session{UserID: "u1", OrganizationID: orgID, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	var resp response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+	return resp
+}
+
+// inventory returns an organization with two AWS accounts and a volume in
+// each, and one in an account that is not connected
+func inventory() (*entity.Organization, []*entity.CloudAccount, []*entity.Resource) {
+	org := entity.NewOrganization("Acme", "acme")
+	accounts := []*entity.CloudAccount{
+		entity.NewCloudAccount(org.ID, entity.CloudProviderAWS, "111111111111", "prod"),
+		entity.NewCloudAccount(org.ID, entity.CloudProviderAWS, "222222222222", "staging"),
+	}
+	var resources []*entity.Resource
+	for i, accountID := range []string{"111111111111", "222222222222", "333333333333"} {
+		r := entity.NewResource(org.ID, entity.CloudProviderAWS, entity.ResourceTypeEBSVolume, "vol-"+accountID, "eu-west-3", "")
+		r.AccountID = accountID
+		r.MonthlyCost = float64(100 - 10*i)
+		r.Tags = map[string]string{"team": "data", "env": "prod"}
+		resources = append(resources, r)
+	}
+	return org, accounts, resources
+}
+
+func TestQueryOrganizationBatchesLookups(t *testing.T) {
+	org, accounts, resources := inventory()
+	orgs := &fakeOrganizations{orgs: map[uuid.UUID]*entity.Organization{org.ID: org}, accounts: accounts}
+	dashboards := &fakeDashboards{}
+	var got usecase.ListResourcesInput
+	h := NewHandler(NewResolver(orgs, &fakeResources{
+		list: func(input usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error) {
+			got = input
+			return &usecase.ListResourcesOutput{Resources: resources, Total: -1}, nil
+		},
+	}, nil, nil, dashboards))
+
+	resp := query(t, h, org.ID.String(), `{
+		organization {
+			name
+			accounts { accountId summary { resources wasteCost } }
+			resources(filter: {status: "unused", sort: "cost"}, first: 2) {
+				nodes { resourceId tags { key value } organization { slug } account { name } }
+				totalCount
+				hasNextPage
+			}
+		}
+	}`)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+
+	if got.OrganizationID == nil || *got.OrganizationID != org.ID || got.Limit != 2 || got.Filter.Status != "unused" || got.Filter.Sort != "cost" {
+		t.Errorf("list input = %+v, want 2 unused resources of the session organization by cost", got)
+	}
+	var data struct {
+		Organization struct {
+			Name     string
+			Accounts []struct {
+				AccountID string
+				Summary   struct {
+					Resources int
+					WasteCost float64
+				}
+			}
+			Resources struct {
+				Nodes []struct {
+					ResourceID   string
+					Tags         []struct{ Key, Value string }
+					Organization struct{ Slug string }
+					Account      *struct{ Name string }
+				}
+				TotalCount  int
+				HasNextPage bool
+			}
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	o := data.Organization
+	if o.Name != "Acme" || len(o.Accounts) != 2 || o.Accounts[0].Summary.Resources != 3 || o.Accounts[0].Summary.WasteCost != 80 || o.Accounts[1].Summary.Resources != 0 {
+		t.Errorf("organization = %+v, want its two accounts with their summaries", o)
+	}
+	nodes := o.Resources.Nodes
+	if len(nodes) != 2 || !o.Resources.HasNextPage || o.Resources.TotalCount != -1 {
+		t.Fatalf("resources = %+v, want a page of 2 followed by another", o.Resources)
+	}
+	if nodes[0].Account == nil || nodes[0].Account.Name != "prod" || nodes[1].Account == nil || nodes[1].Account.Name != "staging" || nodes[0].Organization.Slug != "acme" {
+		t.Errorf("resources = %+v, want their account and organization", nodes)
+	}
+	if tags := nodes[0].Tags; len(tags) != 2 || tags[0].Key != "env" || tags[1].Key != "team" {
+		t.Errorf("tags = %+v, want them sorted by key", tags)
+	}
+
+	// The organization was loaded by the root field; the accounts of every
+	// resource and their summaries are fetched in one batch each
+	if len(orgs.orgBatches) != 0 {
+		t.Errorf("organization batches = %v, want none", orgs.orgBatches)
+	}
+	if len(orgs.accountBatches) != 1 {
+		t.Errorf("account batches = %v, want 1", orgs.accountBatches)
+	}
+	if len(dashboards.batches) != 1 || len(dashboards.batches[0]) != 2 {
+		t.Errorf("summary batches = %v, want the 2 accounts at once", dashboards.batches)
+	}
+}
+
+func TestQueryResourcesBatchOrganizations(t *testing.T) {
+	acme, _, acmeResources := inventory()
+	globex, _, globexResources := inventory()
+	orgs := &fakeOrganizations{orgs: map[uuid.UUID]*entity.Organization{acme.ID: acme, globex.ID: globex}}
+	byID := map[uuid.UUID]*entity.Resource{acmeResources[0].ID: acmeResources[0], globexResources[0].ID: globexResources[0]}
+	h := NewHandler(NewResolver(orgs, &fakeResources{
+		get: func(id uuid.UUID) (*entity.Resource, error) { return byID[id], nil },
+	}, nil, nil, &fakeDashboards{}))
+
+	resp := query(t, h, "", `{
+		a: resource(id: "`+acmeResources[0].ID.String()+`") { organization { name } }
+		b: resource(id: "`+globexResources[0].ID.String()+`") { organization { name } }
+	}`)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	if len(orgs.orgBatches) != 1 || len(orgs.orgBatches[0]) != 2 {
+		t.Errorf("organization batches = %v, want both organizations at once", orgs.orgBatches)
+	}
+}
+
+func TestQueryOrganizationSession(t *testing.T) {
+	org, _, _ := inventory()
+	orgs := &fakeOrganizations{orgs: map[uuid.UUID]*entity.Organization{org.ID: org}}
+	h := NewHandler(NewResolver(orgs, nil, nil, nil, &fakeDashboards{}))
+
+	tests := []struct {
+		name    string
+		session string
+		query   string
+		code    string
+	}{
+		{name: "another organization", session: org.ID.String(), query: `{ organization(id: "` + uuid.NewString() + `") { name } }`, code: apperrors.CodeForbidden},
+		{name: "no session or ID", query: `{ organization { name } }`, code: apperrors.CodeInvalidInput},
+		{name: "unknown organization", query: `{ organization(id: "` + uuid.NewString() + `") { name } }`, code: apperrors.CodeNotFound},
+		{name: "session", session: org.ID.String(), query: `{ organization { name } }`},
+		{name: "ID", query: `{ organization(id: "` + org.ID.String() + `") { name } }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := query(t, h, tt.session, tt.query)
+			if tt.code == "" {
+				if len(resp.Errors) > 0 || !strings.Contains(string(resp.Data), "Acme") {
+					t.Errorf("response = %s %+v, want the organization", resp.Data, resp.Errors)
+				}
+				return
+			}
+			if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != tt.code {
+				t.Errorf("errors = %+v, want code %s", resp.Errors, tt.code)
+			}
+		})
+	}
+}
+
+func TestQueryResourceVisibility(t *testing.T) {
+	org, _, resources := inventory()
+	failing := uuid.New()
+	h := NewHandler(NewResolver(&fakeOrganizations{}, &fakeResources{
+		get: func(id uuid.UUID) (*entity.Resource, error) {
+			switch id {
+			case resources[0].ID:
+				return resources[0], nil
+			case failing:
+				return nil, apperrors.NewWithCode(errDatabase, apperrors.CodeInternal, "failed to fetch resource")
+			}
+			return nil, apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "resource not found")
+		},
+	}, nil, nil, &fakeDashboards{}))
+
+	// The resource of another organization is hidden like a missing one
+	for _, session := range []string{uuid.NewString(), ""} {
+		resp := query(t, h, session, `{ resource(id: "`+resources[0].ID.String()+`") { id } missing: resource(id: "`+uuid.NewString()+`") { id } }`)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("errors = %+v", resp.Errors)
+		}
+		visible := session == "" || session == org.ID.String()
+		if want := `{"resource":null,"missing":null}`; !visible && string(resp.Data) != want {
+			t.Errorf("data = %s, want %s", resp.Data, want)
+		}
+		if visible && !strings.Contains(string(resp.Data), resources[0].ID.String()) {
+			t.Errorf("data = %s, want the resource", resp.Data)
+		}
+	}
+
+	// Other errors are reported with their code
+	resp := query(t, h, "", `{ resource(id: "`+failing.String()+`") { id } }`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "failed to fetch resource" || resp.Errors[0].Extensions["code"] != apperrors.CodeInternal {
+		t.Errorf("errors = %+v, want the internal error", resp.Errors)
+	}
+}
+
+func TestQueryInvalidPage(t *testing.T) {
+	org, _, _ := inventory()
+	orgs := &fakeOrganizations{orgs: map[uuid.UUID]*entity.Organization{org.ID: org}}
+	h := NewHandler(NewResolver(orgs, &fakeResources{
+		list: func(usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error) {
+			t.Error("resources listed with an invalid page")
+			return nil, nil
+		},
+	}, nil, nil, &fakeDashboards{}))
+
+	resp := query(t, h, org.ID.String(), `{ organization { resources(first: 501) { totalCount } } }`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "first must be between 1 and 500" || resp.Errors[0].Extensions["code"] != apperrors.CodeInvalidInput {
+		t.Errorf("errors = %+v, want an invalid first", resp.Errors)
+	}
+}
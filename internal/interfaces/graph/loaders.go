@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// loaderWait is how long loaders collect keys before fetching them in one
+// batch
+const loaderWait = 2 * time.Millisecond
+
+// loadersKey is the context key of the loaders of a request
+type loadersKey struct{}
+
+// Loaders batch the lookups made by the fields of a query, such as the
+// organization or cloud account of each resource of a list, and cache them
+// for the rest of the request
+type Loaders struct {
+	organizations *dataloader.Loader[uuid.UUID, *entity.Organization]
+	// accounts are the cloud accounts of organizations
+	accounts  *dataloader.Loader[uuid.UUID, []*entity.CloudAccount]
+	summaries *dataloader.Loader[repository.AccountKey, repository.InventoryTotals]
+}
+
+// NewLoaders creates the loaders of a request
+func NewLoaders(organizations usecase.OrganizationService, dashboards usecase.DashboardService) *Loaders {
+	return &Loaders{
+		organizations: dataloader.NewBatchedLoader(func(ctx context.Context, ids []uuid.UUID) []*dataloader.Result[*entity.Organization] {
+			orgs, err := organizations.GetMany(ctx, ids)
+			if err != nil {
+				return failed[*entity.Organization](len(ids), err)
+			}
+			byID := make(map[uuid.UUID]*entity.Organization, len(orgs))
+			for _, org := range orgs {
+				byID[org.ID] = org
+			}
+			results := make([]*dataloader.Result[*entity.Organization], len(ids))
+			for i, id := range ids {
+				if org, ok := byID[id]; ok {
+					results[i] = &dataloader.Result[*entity.Organization]{Data: org}
+				} else {
+					results[i] = &dataloader.Result[*entity.Organization]{
+						Error: apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "organization not found"),
+					}
+				}
+			}
+			return results
+		}, dataloader.WithWait[uuid.UUID, *entity.Organization](loaderWait)),
+
+		accounts: dataloader.NewBatchedLoader(func(ctx context.Context, orgIDs []uuid.UUID) []*dataloader.Result[[]*entity.CloudAccount] {
+			accounts, err := organizations.Accounts(ctx, orgIDs)
+			if err != nil {
+				return failed[[]*entity.CloudAccount](len(orgIDs), err)
+			}
+			byOrg := make(map[uuid.UUID][]*entity.CloudAccount, len(orgIDs))
+			for _, account := range accounts {
+				byOrg[account.OrganizationID] = append(byOrg[account.OrganizationID], account)
+			}
+			results := make([]*dataloader.Result[[]*entity.CloudAccount], len(orgIDs))
+			for i, id := range orgIDs {
+				results[i] = &dataloader.Result[[]*entity.CloudAccount]{Data: byOrg[id]}
+			}
+			return results
+		}, dataloader.WithWait[uuid.UUID, []*entity.CloudAccount](loaderWait)),
+
+		summaries: dataloader.NewBatchedLoader(func(ctx context.Context, keys []repository.AccountKey) []*dataloader.Result[repository.InventoryTotals] {
+			totals, err := dashboards.AccountSummaries(ctx, keys)
+			if err != nil {
+				return failed[repository.InventoryTotals](len(keys), err)
+			}
+			results := make([]*dataloader.Result[repository.InventoryTotals], len(keys))
+			for i, key := range keys {
+				results[i] = &dataloader.Result[repository.InventoryTotals]{Data: totals[key]}
+			}
+			return results
+		}, dataloader.WithWait[repository.AccountKey, repository.InventoryTotals](loaderWait)),
+	}
+}
+
+// failed returns the results of a batch that could not be fetched
+func failed[V any](n int, err error) []*dataloader.Result[V] {
+	results := make([]*dataloader.Result[V], n)
+	for i := range results {
+		results[i] = &dataloader.Result[V]{Error: err}
+	}
+	return results
+}
+
+// withLoaders returns a context carrying the loaders of a request
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFrom returns the loaders of the request of ctx
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+// organization loads an organization by ID
+func (l *Loaders) organization(ctx context.Context, id uuid.UUID) (*entity.Organization, error) {
+	return l.organizations.Load(ctx, id)()
+}
+
+// account loads the cloud account of an organization with a provider and
+// account ID, nil when it is not connected
+func (l *Loaders) account(ctx context.Context, orgID uuid.UUID, provider entity.CloudProvider, accountID string) (*entity.CloudAccount, error) {
+	accounts, err := l.accounts.Load(ctx, orgID)()
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		if account.Provider == provider && account.AccountID == accountID {
+			return account, nil
+		}
+	}
+	return nil, nil
+}
+
+// summary loads the totals of the resources of a cloud account
+func (l *Loaders) summary(ctx context.Context, account *entity.CloudAccount) (repository.InventoryTotals, error) {
+	return l.summaries.Load(ctx, repository.AccountKey{
+		OrganizationID: account.OrganizationID,
+		Provider:       account.Provider,
+		AccountID:      account.AccountID,
+	})()
+}
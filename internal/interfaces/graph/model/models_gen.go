@@ -0,0 +1,88 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+)
+
+type Query struct {
+}
+
+type ResourceConnection struct {
+	Nodes []*entity.Resource `json:"nodes"`
+	// Number of matching resources, -1 when it was not counted
+	TotalCount  int  `json:"totalCount"`
+	HasNextPage bool `json:"hasNextPage"`
+}
+
+// Filters of resource lists, as the query parameters of GET /resources
+type ResourceFilter struct {
+	Provider  *string `json:"provider,omitempty"`
+	Type      *string `json:"type,omitempty"`
+	Status    *string `json:"status,omitempty"`
+	Region    *string `json:"region,omitempty"`
+	AccountID *string `json:"accountId,omitempty"`
+	Owner     *string `json:"owner,omitempty"`
+	// Search terms and tag selectors, e.g. tag:env=prod
+	Q       *string  `json:"q,omitempty"`
+	MinCost *float64 `json:"minCost,omitempty"`
+	// created, cost, carbon or age
+	Sort *string `json:"sort,omitempty"`
+	// asc or desc
+	Order *string `json:"order,omitempty"`
+}
+
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type OffenderGroup string
+
+const (
+	OffenderGroupResource OffenderGroup = "RESOURCE"
+	OffenderGroupTeam     OffenderGroup = "TEAM"
+	OffenderGroupAccount  OffenderGroup = "ACCOUNT"
+	OffenderGroupRegion   OffenderGroup = "REGION"
+)
+
+var AllOffenderGroup = []OffenderGroup{
+	OffenderGroupResource,
+	OffenderGroupTeam,
+	OffenderGroupAccount,
+	OffenderGroupRegion,
+}
+
+func (e OffenderGroup) IsValid() bool {
+	switch e {
+	case OffenderGroupResource, OffenderGroupTeam, OffenderGroupAccount, OffenderGroupRegion:
+		return true
+	}
+	return false
+}
+
+func (e OffenderGroup) String() string {
+	return string(e)
+}
+
+func (e *OffenderGroup) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OffenderGroup(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OffenderGroup", str)
+	}
+	return nil
+}
+
+func (e OffenderGroup) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,190 @@
+// Package graph serves the GraphQL API of the dashboard frontend, whose
+// nested queries (organization, accounts, resources, aggregates) would take
+// many round trips to the REST API. Resolvers read through the same use
+// cases as the REST handlers; the lookups made for each item of a list are
+// batched by the loaders of the request.
+//
+// The schema is in schema.graphqls. After changing it, regenerate the
+// executable schema with make graphql.
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/graph/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// maxPageSize is the largest page of lists, as in the REST API
+const maxPageSize = 500
+
+// maxOffenders is the largest ranking of waste offenders
+const maxOffenders = 100
+
+// offenderGroups are what the top offenders are grouped by, single
+// resources for RESOURCE
+var offenderGroups = map[model.OffenderGroup]repository.WasteGroupBy{
+	model.OffenderGroupResource: "",
+	model.OffenderGroupTeam:     repository.WasteByTag,
+	model.OffenderGroupAccount:  repository.WasteByAccount,
+	model.OffenderGroupRegion:   repository.WasteByRegion,
+}
+
+// sessionKey is the context key of the organization of the session
+type sessionKey struct{}
+
+// Resolver resolves the fields of the schema
+type Resolver struct {
+	organizations usecase.OrganizationService
+	resources     usecase.ResourceService
+	scans         usecase.ScanService
+	policies      usecase.PolicyService
+	dashboards    usecase.DashboardService
+}
+
+// NewResolver creates a new Resolver
+func NewResolver(
+	organizations usecase.OrganizationService,
+	resources usecase.ResourceService,
+	scans usecase.ScanService,
+	policies usecase.PolicyService,
+	dashboards usecase.DashboardService,
+) *Resolver {
+	return &Resolver{
+		organizations: organizations,
+		resources:     resources,
+		scans:         scans,
+		policies:      policies,
+		dashboards:    dashboards,
+	}
+}
+
+// withSession returns a context scoped to the organization of a session
+func withSession(ctx context.Context, orgID uuid.UUID) context.Context {
+	return context.WithValue(ctx, sessionKey{}, orgID)
+}
+
+// sessionOrganization returns the organization of the session of ctx, if
+// signed in
+func sessionOrganization(ctx context.Context) (uuid.UUID, bool) {
+	orgID, ok := ctx.Value(sessionKey{}).(uuid.UUID)
+	return orgID, ok
+}
+
+// visible reports whether the session of ctx may read the data of an
+// organization. Requests without a session read any organization, as with
+// the REST API.
+func visible(ctx context.Context, orgID uuid.UUID) bool {
+	sessionOrg, ok := sessionOrganization(ctx)
+	return !ok || sessionOrg == orgID
+}
+
+// missing returns the error of a lookup whose result is null: nil when it
+// found nothing, or nothing the session may read
+func missing(err error) error {
+	if err == nil || apperrors.CodeOf(err) == apperrors.CodeNotFound {
+		return nil
+	}
+	return err
+}
+
+// invalid returns the error of an invalid argument
+func invalid(message string) error {
+	return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, message)
+}
+
+// page returns the limit and offset of a list, first defaulting to def
+func page(first, offset *int, def int) (int, int, error) {
+	limit, skip := def, 0
+	if first != nil {
+		limit = *first
+	}
+	if offset != nil {
+		skip = *offset
+	}
+	if limit < 1 || limit > maxPageSize {
+		return 0, 0, invalid(fmt.Sprintf("first must be between 1 and %d", maxPageSize))
+	}
+	if skip < 0 {
+		return 0, 0, invalid("offset must not be negative")
+	}
+	return limit, skip, nil
+}
+
+// value returns the value of an optional argument, its zero value when
+// omitted
+func value[T any](arg *T) T {
+	if arg == nil {
+		var zero T
+		return zero
+	}
+	return *arg
+}
+
+// providerOf returns the provider of an optional argument
+func providerOf(arg *string) *entity.CloudProvider {
+	if arg == nil {
+		return nil
+	}
+	p := entity.CloudProvider(*arg)
+	return &p
+}
+
+// resourceFilter converts the filter of a resource list
+func resourceFilter(filter *model.ResourceFilter) entity.ResourceViewFilter {
+	if filter == nil {
+		return entity.ResourceViewFilter{}
+	}
+	return entity.ResourceViewFilter{
+		Provider:  value(filter.Provider),
+		Type:      value(filter.Type),
+		Status:    value(filter.Status),
+		Region:    value(filter.Region),
+		AccountID: value(filter.AccountID),
+		Owner:     value(filter.Owner),
+		Q:         value(filter.Q),
+		MinCost:   value(filter.MinCost),
+		Sort:      value(filter.Sort),
+		Order:     value(filter.Order),
+	}
+}
+
+// listResources returns a page of the resources of an organization
+func (r *Resolver) listResources(ctx context.Context, orgID uuid.UUID, filter entity.ResourceViewFilter, first, offset *int, includeTotal *bool) (*model.ResourceConnection, error) {
+	limit, skip, err := page(first, offset, 50)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.resources.List(ctx, usecase.ListResourcesInput{
+		OrganizationID: &orgID,
+		Filter:         filter,
+		Limit:          limit,
+		Offset:         skip,
+		IncludeTotal:   value(includeTotal),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := out.Resources
+	hasNext := len(nodes) > limit
+	if hasNext {
+		nodes = nodes[:limit]
+	}
+	return &model.ResourceConnection{Nodes: nodes, TotalCount: int(out.Total), HasNextPage: hasNext}, nil
+}
+
+// dashboardScope returns the dashboard scope of an organization, optionally
+// narrowed to a provider and account
+func dashboardScope(orgID uuid.UUID, provider, accountID *string) repository.DashboardScope {
+	return repository.DashboardScope{
+		OrganizationID: orgID,
+		Provider:       providerOf(provider),
+		AccountID:      accountID,
+	}
+}
@@ -0,0 +1,217 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/graph/model"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// Organization is the resolver for the organization field.
+func (r *cloudAccountResolver) Organization(ctx context.Context, obj *entity.CloudAccount) (*entity.Organization, error) {
+	return loadersFrom(ctx).organization(ctx, obj.OrganizationID)
+}
+
+// Resources is the resolver for the resources field.
+func (r *cloudAccountResolver) Resources(ctx context.Context, obj *entity.CloudAccount, filter *model.ResourceFilter, first *int, offset *int, includeTotal *bool) (*model.ResourceConnection, error) {
+	// The filter cannot leave the account
+	f := resourceFilter(filter)
+	f.Provider, f.AccountID = string(obj.Provider), obj.AccountID
+	return r.listResources(ctx, obj.OrganizationID, f, first, offset, includeTotal)
+}
+
+// Summary is the resolver for the summary field.
+func (r *cloudAccountResolver) Summary(ctx context.Context, obj *entity.CloudAccount) (*repository.InventoryTotals, error) {
+	totals, err := loadersFrom(ctx).summary(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// Accounts is the resolver for the accounts field.
+func (r *organizationResolver) Accounts(ctx context.Context, obj *entity.Organization, provider *string) ([]*entity.CloudAccount, error) {
+	all, err := loadersFrom(ctx).accounts.Load(ctx, obj.ID)()
+	if err != nil {
+		return nil, err
+	}
+	accounts := []*entity.CloudAccount{}
+	for _, account := range all {
+		if provider == nil || string(account.Provider) == *provider {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+// Resources is the resolver for the resources field.
+func (r *organizationResolver) Resources(ctx context.Context, obj *entity.Organization, filter *model.ResourceFilter, first *int, offset *int, includeTotal *bool) (*model.ResourceConnection, error) {
+	return r.listResources(ctx, obj.ID, resourceFilter(filter), first, offset, includeTotal)
+}
+
+// Scans is the resolver for the scans field.
+func (r *organizationResolver) Scans(ctx context.Context, obj *entity.Organization, provider *string, status *string, first *int, offset *int) ([]*entity.Scan, error) {
+	limit, skip, err := page(first, offset, 20)
+	if err != nil {
+		return nil, err
+	}
+	filter := repository.ScanFilter{OrganizationID: &obj.ID, Provider: providerOf(provider), Limit: limit, Offset: skip}
+	if status != nil {
+		s := entity.ScanStatus(*status)
+		filter.Status = &s
+	}
+	scans, _, err := r.scans.List(ctx, filter)
+	return scans, err
+}
+
+// Policies is the resolver for the policies field.
+func (r *organizationResolver) Policies(ctx context.Context, obj *entity.Organization, provider *string, enabled *bool, first *int, offset *int) ([]*entity.Policy, error) {
+	limit, skip, err := page(first, offset, 50)
+	if err != nil {
+		return nil, err
+	}
+	policies, _, err := r.policies.List(ctx, repository.PolicyFilter{
+		OrganizationID: &obj.ID,
+		Provider:       providerOf(provider),
+		IsEnabled:      enabled,
+		Limit:          limit,
+		Offset:         skip,
+	})
+	return policies, err
+}
+
+// Summary is the resolver for the summary field.
+func (r *organizationResolver) Summary(ctx context.Context, obj *entity.Organization, provider *string, accountID *string) (*repository.InventoryTotals, error) {
+	return r.dashboards.Summary(ctx, dashboardScope(obj.ID, provider, accountID))
+}
+
+// TopOffenders is the resolver for the topOffenders field.
+func (r *organizationResolver) TopOffenders(ctx context.Context, obj *entity.Organization, groupBy *model.OffenderGroup, tagKey *string, provider *string, accountID *string, limit *int) (*usecase.TopOffendersOutput, error) {
+	n := 10
+	if limit != nil {
+		n = *limit
+	}
+	if n < 1 || n > maxOffenders {
+		return nil, invalid(fmt.Sprintf("limit must be between 1 and %d", maxOffenders))
+	}
+	key := "team"
+	if tagKey != nil {
+		key = *tagKey
+	}
+	if len(key) > 128 {
+		return nil, invalid("tagKey must be at most 128 characters")
+	}
+	return r.dashboards.TopOffenders(ctx, usecase.TopOffendersInput{
+		Scope:   dashboardScope(obj.ID, provider, accountID),
+		GroupBy: offenderGroups[value(groupBy)],
+		TagKey:  key,
+		Limit:   n,
+	})
+}
+
+// Organization is the resolver for the organization field.
+func (r *policyResolver) Organization(ctx context.Context, obj *entity.Policy) (*entity.Organization, error) {
+	return loadersFrom(ctx).organization(ctx, obj.OrganizationID)
+}
+
+// Organization is the resolver for the organization field.
+func (r *queryResolver) Organization(ctx context.Context, id *uuid.UUID) (*entity.Organization, error) {
+	// Sessions read their organization; without one, the ID is required
+	orgID, ok := sessionOrganization(ctx)
+	switch {
+	case ok && id != nil && *id != orgID:
+		return nil, apperrors.NewWithCode(apperrors.ErrForbidden, apperrors.CodeForbidden, "session is not signed in to this organization")
+	case !ok && id == nil:
+		return nil, invalid("id is required")
+	case !ok:
+		orgID = *id
+	}
+
+	org, err := r.organizations.Get(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	loadersFrom(ctx).organizations.Prime(ctx, org.ID, org)
+	return org, nil
+}
+
+// Resource is the resolver for the resource field.
+func (r *queryResolver) Resource(ctx context.Context, id uuid.UUID) (*entity.Resource, error) {
+	resource, err := r.resources.Get(ctx, id)
+	if err != nil || !visible(ctx, resource.OrganizationID) {
+		return nil, missing(err)
+	}
+	return resource, nil
+}
+
+// Scan is the resolver for the scan field.
+func (r *queryResolver) Scan(ctx context.Context, id uuid.UUID) (*entity.Scan, error) {
+	scan, err := r.scans.Get(ctx, id)
+	if err != nil || !visible(ctx, scan.OrganizationID) {
+		return nil, missing(err)
+	}
+	return scan, nil
+}
+
+// Policy is the resolver for the policy field.
+func (r *queryResolver) Policy(ctx context.Context, id uuid.UUID) (*entity.Policy, error) {
+	policy, err := r.policies.Get(ctx, id)
+	if err != nil || !visible(ctx, policy.OrganizationID) {
+		return nil, missing(err)
+	}
+	return policy, nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *resourceResolver) Tags(ctx context.Context, obj *entity.Resource) ([]*model.Tag, error) {
+	// Sorted by key, for stable responses
+	tags := make([]*model.Tag, 0, len(obj.Tags))
+	for k, v := range obj.Tags {
+		tags = append(tags, &model.Tag{Key: k, Value: v})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	return tags, nil
+}
+
+// Organization is the resolver for the organization field.
+func (r *resourceResolver) Organization(ctx context.Context, obj *entity.Resource) (*entity.Organization, error) {
+	return loadersFrom(ctx).organization(ctx, obj.OrganizationID)
+}
+
+// Account is the resolver for the account field.
+func (r *resourceResolver) Account(ctx context.Context, obj *entity.Resource) (*entity.CloudAccount, error) {
+	if obj.AccountID == "" {
+		return nil, nil
+	}
+	return loadersFrom(ctx).account(ctx, obj.OrganizationID, obj.Provider, obj.AccountID)
+}
+
+// Organization is the resolver for the organization field.
+func (r *scanResolver) Organization(ctx context.Context, obj *entity.Scan) (*entity.Organization, error) {
+	return loadersFrom(ctx).organization(ctx, obj.OrganizationID)
+}
+
+func (r *Resolver) CloudAccount() CloudAccountResolver { return &cloudAccountResolver{r} }
+
+func (r *Resolver) Organization() OrganizationResolver { return &organizationResolver{r} }
+
+func (r *Resolver) Policy() PolicyResolver { return &policyResolver{r} }
+
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+func (r *Resolver) Resource() ResourceResolver { return &resourceResolver{r} }
+
+func (r *Resolver) Scan() ScanResolver { return &scanResolver{r} }
+
+type cloudAccountResolver struct{ *Resolver }
+type organizationResolver struct{ *Resolver }
+type policyResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type resourceResolver struct{ *Resolver }
+type scanResolver struct{ *Resolver }
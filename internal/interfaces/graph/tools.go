@@ -0,0 +1,7 @@
+//go:build tools
+
+package graph
+
+// The code generator is a dependency of the module, so that regenerating
+// the schema uses the version of the runtime
+import _ "github.com/99designs/gqlgen"
@@ -0,0 +1,134 @@
+// Package apierror writes the error responses of the API. Every error has
+// the same envelope: a stable code clients can match on, a message, details
+// on the invalid fields of the request, if any, and the ID of the request to
+// quote when reporting a problem.
+package apierror
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Response is the body of every error response
+type Response struct {
+	Code    string   `json:"code" example:"validation_failed"`
+	Message string   `json:"message" example:"invalid request"`
+	Details []Detail `json:"details"`
+	// RequestID is also returned in the X-Request-ID header
+	RequestID string `json:"request_id,omitempty" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+}
+
+// Detail is an error on one field of a request
+type Detail struct {
+	Field   string `json:"field,omitempty" example:"policies[0].provider"`
+	Code    string `json:"code,omitempty" example:"oneof"`
+	Message string `json:"message" example:"must be one of aws, azure, gcp, kubernetes"`
+}
+
+// statuses maps error codes to HTTP statuses
+var statuses = map[string]int{
+	apperrors.CodeInvalidInput:       http.StatusBadRequest,
+	apperrors.CodeValidationFailed:   http.StatusBadRequest,
+	apperrors.CodeUnauthorized:       http.StatusUnauthorized,
+	apperrors.CodeQuotaExceeded:      http.StatusPaymentRequired,
+	apperrors.CodeForbidden:          http.StatusForbidden,
+	apperrors.CodeReadOnly:           http.StatusForbidden,
+	apperrors.CodeNotFound:           http.StatusNotFound,
+	apperrors.CodeAlreadyExists:      http.StatusConflict,
+	apperrors.CodeConflict:           http.StatusConflict,
+	apperrors.CodeGone:               http.StatusGone,
+	apperrors.CodeRateLimited:        http.StatusTooManyRequests,
+	apperrors.CodeInternal:           http.StatusInternalServerError,
+	apperrors.CodeUpstream:           http.StatusBadGateway,
+	apperrors.CodeServiceUnavailable: http.StatusServiceUnavailable,
+	apperrors.CodeTimeout:            http.StatusGatewayTimeout,
+}
+
+// codes maps HTTP statuses to the code of errors responded by status
+var codes = map[int]string{
+	http.StatusBadRequest:          apperrors.CodeInvalidInput,
+	http.StatusUnauthorized:        apperrors.CodeUnauthorized,
+	http.StatusPaymentRequired:     apperrors.CodeQuotaExceeded,
+	http.StatusForbidden:           apperrors.CodeForbidden,
+	http.StatusNotFound:            apperrors.CodeNotFound,
+	http.StatusConflict:            apperrors.CodeConflict,
+	http.StatusGone:                apperrors.CodeGone,
+	http.StatusTooManyRequests:     apperrors.CodeRateLimited,
+	http.StatusInternalServerError: apperrors.CodeInternal,
+	http.StatusBadGateway:          apperrors.CodeUpstream,
+	http.StatusServiceUnavailable:  apperrors.CodeServiceUnavailable,
+	http.StatusGatewayTimeout:      apperrors.CodeTimeout,
+}
+
+// Status returns the HTTP status of an error code, 500 for unknown codes
+func Status(code string) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Code returns the error code of an HTTP status
+func Code(status int) string {
+	if code, ok := codes[status]; ok {
+		return code
+	}
+	if status < http.StatusInternalServerError {
+		return apperrors.CodeInvalidInput
+	}
+	return apperrors.CodeInternal
+}
+
+// New returns the error response of a request
+func New(c *gin.Context, code, message string, details ...Detail) Response {
+	if details == nil {
+		details = []Detail{}
+	}
+	return Response{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: c.GetString("request_id"),
+	}
+}
+
+// Respond writes an error response with the code of its status
+func Respond(c *gin.Context, status int, message string) {
+	c.JSON(status, New(c, Code(status), message))
+}
+
+// Abort writes an error response with the code of its status and stops the
+// handler chain, for middlewares
+func Abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, New(c, Code(status), message))
+}
+
+// RespondError writes the response of an error, with the status of its code
+// (see pkg/errors.CodeOf). The message of internal errors is not exposed.
+func RespondError(c *gin.Context, err error) {
+	code := apperrors.CodeOf(err)
+	status := Status(code)
+	message := err.Error()
+	var appErr *apperrors.AppError
+	isAppErr := apperrors.As(err, &appErr)
+	if status >= http.StatusInternalServerError && !isAppErr {
+		message = "internal error"
+	}
+
+	var details []Detail
+	if isAppErr && len(appErr.Details) > 0 {
+		keys := make([]string, 0, len(appErr.Details))
+		for k := range appErr.Details {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			details = append(details, Detail{Field: k, Message: fmt.Sprint(appErr.Details[k])})
+		}
+	}
+	c.JSON(status, New(c, code, message, details...))
+}
@@ -0,0 +1,155 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// embedded names the embedded structs of requests in validation errors, to
+// leave them out of field paths
+const embedded = "-"
+
+// Validation errors name fields as clients send them: by their JSON name, or
+// their query parameter name
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(f reflect.StructField) string {
+	if f.Anonymous {
+		return embedded
+	}
+	for _, tag := range []string{"json", "form", "uri"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// RespondInvalid writes the 400 of a request that could not be bound, with
+// a detail for each invalid field instead of the internals of the validator
+// or JSON decoder
+func RespondInvalid(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &validationErrs):
+		details := make([]Detail, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, Detail{Field: fieldPath(fe), Code: fe.Tag(), Message: fieldMessage(fe)})
+		}
+		message := "invalid request"
+		if len(details) == 1 {
+			message = details[0].Field + " " + details[0].Message
+		}
+		c.JSON(http.StatusBadRequest, New(c, apperrors.CodeValidationFailed, message, details...))
+	case errors.As(err, &typeErr):
+		detail := Detail{Field: typeErr.Field, Code: "type", Message: "must be " + jsonType(typeErr.Type)}
+		c.JSON(http.StatusBadRequest, New(c, apperrors.CodeValidationFailed, detail.Field+" "+detail.Message, detail))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		c.JSON(http.StatusBadRequest, New(c, apperrors.CodeInvalidInput, "malformed JSON body"))
+	case errors.Is(err, io.EOF):
+		c.JSON(http.StatusBadRequest, New(c, apperrors.CodeInvalidInput, "request body is required"))
+	case errors.As(err, &numErr):
+		c.JSON(http.StatusBadRequest, New(c, apperrors.CodeInvalidInput, fmt.Sprintf("invalid number %q", numErr.Num)))
+	default:
+		Respond(c, http.StatusBadRequest, err.Error())
+	}
+}
+
+// fieldPath returns the path of a field from the request root, such as
+// policies[0].provider
+func fieldPath(fe validator.FieldError) string {
+	segments := strings.Split(fe.Namespace(), ".")[1:]
+	path := make([]string, 0, len(segments))
+	for _, s := range segments {
+		if s != embedded {
+			path = append(path, s)
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// fieldMessage translates the failed rule of a field
+func fieldMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required when " + param + " is not set"
+	case "excluded_with":
+		return "must not be set with " + param
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		return bound(fe, "at least", param)
+	case "max", "lte":
+		return bound(fe, "at most", param)
+	case "gt":
+		return bound(fe, "more than", param)
+	case "lt":
+		return bound(fe, "less than", param)
+	case "len":
+		return bound(fe, "exactly", param)
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "datetime":
+		return "must be a date in the format " + param
+	case "provider":
+		return "must be a supported provider, see GET /providers"
+	}
+	return "is invalid"
+}
+
+// bound describes a size rule: a length for strings, a number of items for
+// lists and maps, and a value for numbers
+func bound(fe validator.FieldError, limit, param string) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("must be %s %s characters long", limit, param)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("must have %s %s items", limit, param)
+	}
+	return fmt.Sprintf("must be %s %s", limit, param)
+}
+
+// jsonType names the JSON type expected for a Go type
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
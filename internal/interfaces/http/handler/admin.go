@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminHandler handles the operations on the API process and the
+// installation
+type AdminHandler struct {
+	live     *config.Live
+	db       *gorm.DB
+	readOnly *database.ReadOnlyGuard
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(live *config.Live, db *gorm.DB, readOnly *database.ReadOnlyGuard) *AdminHandler {
+	return &AdminHandler{live: live, db: db, readOnly: readOnly}
+}
+
+// SetReadOnlyRequest enables or disables the read-only mode
+type SetReadOnlyRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Reason  string `json:"reason" binding:"max=500"`
+}
+
+// ReadOnlyStatusDTO is the read-only mode of the installation and of the
+// organizations in it
+type ReadOnlyStatusDTO struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+	// Source is "config" when the configuration enables the mode, which the
+	// API cannot disable, else "settings"
+	Source        string                 `json:"source"`
+	Organizations []ReadOnlyOrganization `json:"organizations"`
+}
+
+// ReadOnlyOrganization is an organization in read-only mode
+type ReadOnlyOrganization struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// OrganizationReadOnlyDTO is the read-only mode set on an organization
+type OrganizationReadOnlyDTO struct {
+	ID       string `json:"id"`
+	ReadOnly bool   `json:"read_only"`
+	Reason   string `json:"reason"`
+}
+
+// Reload reads the configuration again and applies its operational
+// settings, returning the settings changed. An invalid configuration is
+// rejected and the current one kept.
+func (h *AdminHandler) Reload(c *gin.Context) {
+	result, err := h.live.Reload("POST /admin/reload")
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		apierror.RespondError(c, &apperrors.AppError{Code: apperrors.CodeValidationFailed, Message: "invalid configuration",
+			Details: map[string]any{"problems": invalid.Problems}})
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(result))
+}
+
+// ReadOnly returns the read-only mode of the installation and the
+// organizations in read-only mode
+func (h *AdminHandler) ReadOnly(c *gin.Context) {
+	ctx := c.Request.Context()
+	installation, err := h.readOnly.Installation(ctx)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch the read-only mode")
+		return
+	}
+	var orgs []model.Organization
+	if err := h.db.WithContext(ctx).Select("id", "name", "read_only_reason").
+		Where("read_only").Order("name").Find(&orgs).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch organizations")
+		return
+	}
+
+	status := ReadOnlyStatusDTO{
+		Enabled:       installation.ReadOnly,
+		Reason:        installation.ReadOnlyReason,
+		Source:        "settings",
+		Organizations: make([]ReadOnlyOrganization, len(orgs)),
+	}
+	if h.readOnly.ConfigEnabled() {
+		status.Source = "config"
+	}
+	for i, org := range orgs {
+		status.Organizations[i] = ReadOnlyOrganization{ID: org.ID.String(), Name: org.Name, Reason: org.ReadOnlyReason}
+	}
+	c.JSON(http.StatusOK, dataResponse(status))
+}
+
+// SetReadOnly enables or disables the read-only mode of the whole
+// installation. The mode enabled by the configuration cannot be disabled
+// here.
+func (h *AdminHandler) SetReadOnly(c *gin.Context) {
+	var req SetReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if !*req.Enabled && h.readOnly.ConfigEnabled() {
+		apierror.Respond(c, http.StatusConflict, "the read-only mode is enabled by the configuration")
+		return
+	}
+	if err := h.readOnly.SetInstallation(c.Request.Context(), *req.Enabled, req.Reason); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update the read-only mode")
+		return
+	}
+	h.ReadOnly(c)
+}
+
+// SetOrganizationReadOnly enables or disables the read-only mode of an
+// organization
+func (h *AdminHandler) SetOrganizationReadOnly(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	var req SetReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	reason := req.Reason
+	if !*req.Enabled {
+		reason = ""
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Model(&model.Organization{}).Where("id = ?", id).
+		Updates(map[string]any{"read_only": *req.Enabled, "read_only_reason": reason})
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update organization")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "organization not found")
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(OrganizationReadOnlyDTO{ID: id.String(), ReadOnly: *req.Enabled, Reason: reason}))
+}
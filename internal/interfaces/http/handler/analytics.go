@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AnalyticsHandler handles ad-hoc analytics endpoints
+type AnalyticsHandler struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler
+func NewAnalyticsHandler(db *gorm.DB) *AnalyticsHandler {
+	return &AnalyticsHandler{db: db}
+}
+
+// TagDistributionRequest represents query parameters for the tag explorer
+type TagDistributionRequest struct {
+	Key            string `form:"key" binding:"required,max=128" example:"env"`
+	OrganizationID string `form:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider       string `form:"provider" binding:"omitempty,provider" example:"aws"`
+	Limit          int    `form:"limit,default=50" binding:"min=1,max=500" example:"50"`
+}
+
+// TagValueStats represents resources sharing a tag value
+type TagValueStats struct {
+	Value       string  `json:"value" example:"production"`
+	Untagged    bool    `json:"untagged" example:"false"`
+	Count       int64   `json:"resource_count" example:"120"`
+	UnusedCount int64   `json:"unused_count" example:"8"`
+	Cost        float64 `json:"monthly_cost" example:"4200.00"`
+	Waste       float64 `json:"potential_savings" example:"310.50"`
+}
+
+// TagDistributionResponse represents the distribution of a tag key's values
+type TagDistributionResponse struct {
+	Key    string          `json:"key" example:"env"`
+	Values []TagValueStats `json:"values"`
+}
+
+// Tags godoc
+//
+//	@Summary		Tag explorer
+//	@Description	Get the distribution of a tag key's values across active resources, with their monthly cost and the potential savings from unused ones. Resources without the tag are grouped under untagged.
+//	@Tags			Analytics
+//	@Accept			json
+//	@Produce		json
+//	@Param			key				query		string	true	"Tag key"	example(env)
+//	@Param			organization_id	query		string	false	"Filter by organization"	format(uuid)
+//	@Param			provider		query		string	false	"Filter by cloud provider"
+//	@Param			limit			query		int		false	"Maximum number of values"	default(50)
+//	@Success		200				{object}	DataResponse[TagDistributionResponse]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/analytics/tags [get]
+func (h *AnalyticsHandler) Tags(c *gin.Context) {
+	var req TagDistributionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.Resource{}).
+		Select(`COALESCE(tags->>?, '') AS value,
+			NOT COALESCE(jsonb_exists(tags, ?), false) AS untagged,
+			COUNT(*) AS count,
+			COUNT(*) FILTER (WHERE status = 'unused') AS unused_count,
+			COALESCE(SUM(monthly_cost), 0) AS cost,
+			COALESCE(SUM(monthly_cost) FILTER (WHERE status = 'unused'), 0) AS waste`, req.Key, req.Key).
+		Where("status NOT IN ?", entity.UntrackedResourceStatuses)
+
+	if req.OrganizationID != "" {
+		orgID, err := uuid.Parse(req.OrganizationID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+			return
+		}
+		query = query.Where("organization_id = ?", orgID)
+	}
+	if req.Provider != "" {
+		query = query.Where("provider = ?", req.Provider)
+	}
+
+	var values []TagValueStats
+	if err := query.Group("value, untagged").Order("cost DESC").Limit(req.Limit).Scan(&values).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to aggregate tags")
+		return
+	}
+	if values == nil {
+		values = []TagValueStats{}
+	}
+
+	c.JSON(http.StatusOK, dataResponse(TagDistributionResponse{
+		Key:    req.Key,
+		Values: values,
+	}))
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditHandler handles the provider call audit log endpoints
+type AuditHandler struct {
+	db *gorm.DB
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{db: db}
+}
+
+// ListProviderCallsRequest represents query parameters for listing provider calls
+type ListProviderCallsRequest struct {
+	OrganizationID string `form:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID         string `form:"task_id" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	ResourceID     string `form:"resource_id" example:"550e8400-e29b-41d4-a716-446655440002"`
+	Cursor         string `form:"cursor" example:"eyJzIjoiY2FsbGVkIn0"`
+	Limit          int    `form:"limit,default=50" example:"50"`
+	Offset         int    `form:"offset,default=0" example:"0"`
+}
+
+// providerCallSort is the order of the audit log, most recent first
+var providerCallSort = keysetSort{Name: "called", Column: "called_at", Desc: true}
+
+// ListProviderCalls godoc
+//
+//	@Summary		List provider calls
+//	@Description	Get a paginated list of the provider-mutating API calls made by cleanups, most recent first. Filter by task_id to get the calls behind a cleanup.
+//	@Tags			Audit
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	true	"Organization ID"	format(uuid)
+//	@Param			task_id			query		string	false	"Cleanup task ID"
+//	@Param			resource_id		query		string	false	"Resource ID"	format(uuid)
+//	@Param			limit			query		int		false	"Number of items per page"	default(50)
+//	@Param			offset			query		int		false	"Number of items to skip"	default(0)
+//	@Param			cursor			query		string	false	"Cursor of the next page, from next_cursor; offset is ignored"
+//	@Success		200				{object}	PaginatedResponse{data=[]ProviderCallDTO}
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/audit/provider-calls [get]
+func (h *AuditHandler) ListProviderCalls(c *gin.Context) {
+	var req ListProviderCallsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.ProviderCall{}).Where("organization_id = ?", orgID)
+
+	if req.TaskID != "" {
+		query = query.Where("task_id = ?", req.TaskID)
+	}
+	if req.ResourceID != "" {
+		resourceID, err := uuid.Parse(req.ResourceID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
+			return
+		}
+		query = query.Where("resource_id = ?", resourceID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	page, err := providerCallSort.apply(query, req.Cursor)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Cursor != "" {
+		req.Offset = 0
+	}
+	var calls []model.ProviderCall
+	if err := page.Limit(req.Limit + 1).Offset(req.Offset).Order(providerCallSort.Order()).Find(&calls).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch provider calls")
+		return
+	}
+	calls, next := keysetPage(providerCallSort, calls, req.Limit, func(call *model.ProviderCall) (string, string) {
+		return call.CalledAt.Format(time.RFC3339Nano), call.ID.String()
+	})
+
+	data := make([]ProviderCallDTO, 0, len(calls))
+	for _, call := range calls {
+		dto := ProviderCallDTO{
+			ID:             call.ID.String(),
+			OrganizationID: call.OrganizationID.String(),
+			TaskID:         call.TaskID,
+			Provider:       call.Provider,
+			API:            call.API,
+			ParamsHash:     call.ParamsHash,
+			Status:         call.Status,
+			Error:          call.Error,
+			DurationMs:     call.DurationMs,
+			CalledAt:       call.CalledAt,
+		}
+		if call.ResourceID != nil {
+			dto.ResourceID = call.ResourceID.String()
+		}
+		data = append(data, dto)
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:       data,
+		Total:      total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+		NextCursor: next,
+	})
+}
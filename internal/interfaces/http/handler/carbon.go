@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CarbonHandler handles carbon-aware scheduling endpoints
+type CarbonHandler struct {
+	db        *gorm.DB
+	estimator *service.CarbonEstimator
+	source    service.CarbonIntensitySource // nil without a live forecast
+}
+
+// NewCarbonHandler creates a new CarbonHandler. A nil source builds
+// schedules from the typical day of each region.
+func NewCarbonHandler(db *gorm.DB, estimator *service.CarbonEstimator, source service.CarbonIntensitySource) *CarbonHandler {
+	return &CarbonHandler{db: db, estimator: estimator, source: source}
+}
+
+// CarbonScheduleRequest represents query parameters for a carbon schedule
+type CarbonScheduleRequest struct {
+	RunHours int `form:"run_hours,default=10" binding:"min=1,max=23" example:"10"`
+}
+
+// IntensityPointDTO is the carbon intensity of an hour
+type IntensityPointDTO struct {
+	Time      time.Time `json:"time"`
+	Intensity float64   `json:"intensity_g_per_kwh" example:"54.2"`
+}
+
+// CarbonWindowDTO is a period of a carbon schedule, end excluded
+type CarbonWindowDTO struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// CarbonScheduleDTO is the suggested daily schedule of a resource that can
+// be stopped, aligned with the hours of lowest carbon intensity
+type CarbonScheduleDTO struct {
+	ResourceID           string              `json:"resource_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Zone                 string              `json:"zone,omitempty" example:"FR"`
+	Source               string              `json:"source" example:"forecast" enums:"forecast,profile"`
+	RunHours             int                 `json:"run_hours" example:"10"`
+	RunWindows           []CarbonWindowDTO   `json:"run_windows"`
+	StopWindows          []CarbonWindowDTO   `json:"stop_windows"`
+	AverageIntensity     float64             `json:"average_intensity_g_per_kwh" example:"62.5"`
+	RunIntensity         float64             `json:"run_intensity_g_per_kwh" example:"48.1"`
+	EnergyPerHour        float64             `json:"energy_kwh_per_hour" example:"0.12"`
+	MonthlyCarbonSavings float64             `json:"monthly_carbon_savings_kg" example:"3.4"`
+	MonthlyShiftSavings  float64             `json:"monthly_shift_savings_kg" example:"0.6"`
+	Forecast             []IntensityPointDTO `json:"forecast"`
+}
+
+// Schedule godoc
+//
+//	@Summary		Carbon-aware schedule
+//	@Description	Suggest a daily schedule for a resource that can be stopped: it runs during the run_hours hours of lowest carbon intensity of its region's grid over the next 24 hours and is stopped the rest of the day. Savings compare to running around the clock, and to running as many hours at the day's average intensity. Without a live forecast for the region, a typical day of its average intensity is used.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Resource ID"	format(uuid)
+//	@Param			run_hours	query		int		false	"Hours the resource runs per day"	default(10)	minimum(1)	maximum(23)
+//	@Success		200			{object}	DataResponse[CarbonScheduleDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/resources/{id}/carbon-schedule [get]
+func (h *CarbonHandler) Schedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+
+	var req CarbonScheduleRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	var m model.Resource
+	if err := h.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "resource not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch resource")
+		return
+	}
+	r := database.ResourceEntity(&m)
+	if !r.Type.IsStoppable() {
+		apierror.Respond(c, http.StatusBadRequest, "resource type "+m.Type+" cannot be stopped")
+		return
+	}
+
+	dto := CarbonScheduleDTO{ResourceID: m.ID.String(), RunHours: req.RunHours}
+	var forecast []service.IntensityPoint
+	if zone, ok := service.GridZone(r.Provider, r.Region); ok {
+		dto.Zone = zone
+		if h.source != nil {
+			forecast, err = h.source.Forecast(ctx, zone)
+			if err != nil {
+				log.Printf("Failed to get carbon intensity forecast of %s: %v", zone, err)
+			}
+		}
+	}
+	dto.Source = service.IntensitySourceForecast
+	if len(forecast) < 24 {
+		dto.Source = service.IntensitySourceProfile
+		average := h.estimator.GridIntensity(r.Provider, r.Region) * 1000
+		forecast = service.ProfileForecast(r.Provider, r.Region, average, time.Now())
+	}
+
+	dto.EnergyPerHour = h.estimator.HourlyEnergy(r)
+	schedule := service.SuggestCarbonSchedule(forecast, req.RunHours, dto.EnergyPerHour)
+	dto.AverageIntensity = schedule.AverageIntensity
+	dto.RunIntensity = schedule.RunIntensity
+	dto.MonthlyCarbonSavings = schedule.MonthlySavingsKg
+	dto.MonthlyShiftSavings = schedule.MonthlyShiftSavings
+	dto.RunWindows = carbonWindowDTOs(schedule.RunWindows)
+	dto.StopWindows = carbonWindowDTOs(schedule.StopWindows)
+	for _, p := range schedule.Forecast {
+		dto.Forecast = append(dto.Forecast, IntensityPointDTO{Time: p.Time, Intensity: p.Intensity})
+	}
+
+	c.JSON(http.StatusOK, dataResponse(dto))
+}
+
+func carbonWindowDTOs(windows []service.CarbonWindow) []CarbonWindowDTO {
+	out := make([]CarbonWindowDTO, 0, len(windows))
+	for _, w := range windows {
+		out = append(out, CarbonWindowDTO{Start: w.Start, End: w.End})
+	}
+	return out
+}
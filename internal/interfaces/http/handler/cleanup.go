@@ -1,91 +1,84 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
-	"gorm.io/gorm"
 )
 
 // CleanupHandler handles cleanup endpoints
 type CleanupHandler struct {
-	db          *gorm.DB
-	queueClient *asynq.Client
+	cleanups usecase.CleanupService
 }
 
 // NewCleanupHandler creates a new CleanupHandler
-func NewCleanupHandler(db *gorm.DB, queueClient *asynq.Client) *CleanupHandler {
-	return &CleanupHandler{
-		db:          db,
-		queueClient: queueClient,
-	}
+func NewCleanupHandler(cleanups usecase.CleanupService) *CleanupHandler {
+	return &CleanupHandler{cleanups: cleanups}
 }
 
 // ExecuteCleanupRequest represents a request to execute cleanup
 type ExecuteCleanupRequest struct {
 	OrganizationID string   `json:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
-	ResourceIDs    []string `json:"resource_ids" binding:"required,min=1" example:"550e8400-e29b-41d4-a716-446655440001,550e8400-e29b-41d4-a716-446655440002"`
-	Action         string   `json:"action" binding:"required,oneof=delete stop tag notify" example:"delete"`
+	ResourceIDs    []string `json:"resource_ids" binding:"required_without=ViewID,omitempty,min=1" example:"550e8400-e29b-41d4-a716-446655440001,550e8400-e29b-41d4-a716-446655440002"`
+	Action         string   `json:"action" binding:"required,oneof=delete stop tag notify quarantine release ticket" example:"delete"`
 	DryRun         bool     `json:"dry_run" example:"false"`
+	// Backup snapshots volumes, disks and databases before deleting them
+	// and waits for the snapshots to complete; the backup_before_delete
+	// setting of the organization when omitted
+	Backup *bool `json:"backup" example:"true"`
+	// ViewID selects the resources of a saved view in place of resource_ids
+	ViewID string `json:"view_id" binding:"excluded_with=ResourceIDs" example:"550e8400-e29b-41d4-a716-446655440003"`
 }
 
 // ExecuteCleanupResponse represents the response after queueing cleanup
 type ExecuteCleanupResponse struct {
 	Message string `json:"message" example:"cleanup task queued"`
 	TaskID  string `json:"task_id" example:"task_12345"`
-	DryRun  bool   `json:"dry_run" example:"false"`
+	// JobID follows the cleanup in the jobs API
+	JobID  string `json:"job_id" example:"550e8400-e29b-41d4-a716-446655440004"`
+	DryRun bool   `json:"dry_run" example:"false"`
 }
 
 // Execute godoc
 //
 //	@Summary		Execute cleanup
-//	@Description	Queue a cleanup operation for specified resources
+//	@Description	Queue a cleanup operation for specified resources, or for the resources of a saved view. With backup, or by default when the organization enabled backup_before_delete, deletions snapshot volumes, disks and databases first, tagged with the cleanup task and the end of their retention, and delete them only once the snapshot completed; the savings are net of the cost of the snapshots. Cleanups other than dry runs and notifications are refused with the read_only code while the installation or the organization is in read-only mode.
 //	@Tags			Cleanup
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		ExecuteCleanupRequest	true	"Cleanup request"
 //	@Success		202		{object}	ExecuteCleanupResponse
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/cleanup [post]
 func (h *CleanupHandler) Execute(c *gin.Context) {
 	var req ExecuteCleanupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
-
-	// Validate resource IDs
-	for _, id := range req.ResourceIDs {
-		if _, err := uuid.Parse(id); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid resource ID: " + id})
-			return
-		}
+	input, ok := req.input(c)
+	if !ok {
+		return
 	}
 
-	// Enqueue cleanup task
-	payload, _ := json.Marshal(queue.CleanupResourcesPayload{
-		OrganizationID: req.OrganizationID,
-		ResourceIDs:    req.ResourceIDs,
-		Action:         req.Action,
-		DryRun:         req.DryRun,
-	})
-
-	task := asynq.NewTask(queue.TaskTypeCleanupResources, payload)
-	info, err := h.queueClient.Enqueue(task)
+	job, err := h.cleanups.Execute(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to enqueue cleanup task"})
+		apierror.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusAccepted, ExecuteCleanupResponse{
 		Message: "cleanup task queued",
-		TaskID:  info.ID,
+		TaskID:  job.TaskID,
+		JobID:   job.ID.String(),
 		DryRun:  req.DryRun,
 	})
 }
@@ -93,52 +86,107 @@ func (h *CleanupHandler) Execute(c *gin.Context) {
 // Preview godoc
 //
 //	@Summary		Preview cleanup
-//	@Description	Preview what resources would be affected by a cleanup operation
+//	@Description	Preview what resources would be affected by a cleanup operation, given as resource IDs or a saved view. dns_references lists the public IP addresses, load balancers and static website buckets DNS records still point at, as of their last scan: deleting or releasing them is refused while the records exist, and quarantining them only warns since the records are checked again before the purge. Resources whose last scan did not search the DNS zones are listed unchecked. preflight runs the checks of the cleanup on every resource and tells whether it will proceed, be skipped (already deleted, excluded or quarantined) or fail (denylisted region, action not applying to its type, infrastructure-as-code tool recreating it, dependencies such as images, attachments or DNS records), with the permissions the action needs. Checks that need the cloud, such as route tables and missing permissions, are only warned about and run again before the cleanup. The estimated savings are those of the resources that will proceed, net of estimated_backup_cost, the monthly cost of the snapshots taken before deleting volumes, disks and databases in backup-then-delete mode.
 //	@Tags			Cleanup
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		ExecuteCleanupRequest	true	"Cleanup preview request"
 //	@Success		200		{object}	CleanupPreviewDTO
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/cleanup/preview [post]
 func (h *CleanupHandler) Preview(c *gin.Context) {
 	var req ExecuteCleanupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
+	input, ok := req.input(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.cleanups.Preview(c.Request.Context(), input)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	preview := CleanupPreviewDTO{
+		Resources:               make([]ResourceDTO, len(out.Resources)),
+		Count:                   len(out.Resources),
+		EstimatedMonthlySavings: out.MonthlySavings,
+		EstimatedCarbonSavings:  out.CarbonSavings,
+		Backup:                  out.Backup,
+		EstimatedBackupCost:     out.BackupCost,
+		Action:                  req.Action,
+		DNSReferences:           make([]CleanupDNSReferenceDTO, len(out.DNSReferences)),
+		Preflight:               make([]CleanupPreflightDTO, len(out.Resources)),
+		Verdicts:                make(map[string]int, len(out.Verdicts)),
+	}
+	for i, r := range out.Resources {
+		preview.Resources[i] = toResourceDTO(r)
+		preview.Preflight[i] = toCleanupPreflightDTO(r.ID.String(), out.Preflights[i])
+	}
+	for i, ref := range out.DNSReferences {
+		preview.DNSReferences[i] = CleanupDNSReferenceDTO{
+			ResourceID: ref.ResourceID.String(),
+			Records:    ref.Records,
+			Checked:    ref.Checked,
+			Blocking:   ref.Blocking,
+		}
+	}
+	for verdict, n := range out.Verdicts {
+		preview.Verdicts[string(verdict)] = n
+	}
+	c.JSON(http.StatusOK, preview)
+}
 
-	// Convert string IDs to UUIDs
-	var uuids []uuid.UUID
+// input returns the use-case input of a cleanup request. It writes the
+// error response and returns false when the request is invalid.
+func (req *ExecuteCleanupRequest) input(c *gin.Context) (usecase.CleanupInput, bool) {
+	input := usecase.CleanupInput{
+		Action: entity.PolicyAction(req.Action),
+		DryRun: req.DryRun,
+		Backup: req.Backup,
+	}
+	var err error
+	if input.OrganizationID, err = uuid.Parse(req.OrganizationID); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return input, false
+	}
+	if req.ViewID != "" {
+		viewID, err := uuid.Parse(req.ViewID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid view ID")
+			return input, false
+		}
+		input.ViewID = &viewID
+		return input, true
+	}
+	input.ResourceIDs = make([]uuid.UUID, 0, len(req.ResourceIDs))
 	for _, id := range req.ResourceIDs {
 		u, err := uuid.Parse(id)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid resource ID: " + id})
-			return
+			apierror.Respond(c, http.StatusBadRequest, "invalid resource ID: "+id)
+			return input, false
 		}
-		uuids = append(uuids, u)
+		input.ResourceIDs = append(input.ResourceIDs, u)
 	}
+	return input, true
+}
 
-	// Fetch resources
-	var resources []model.Resource
-	if err := h.db.Where("id IN ?", uuids).Find(&resources).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch resources"})
-		return
+func toCleanupPreflightDTO(resourceID string, p *service.Preflight) CleanupPreflightDTO {
+	dto := CleanupPreflightDTO{
+		ResourceID:          resourceID,
+		Verdict:             string(p.Verdict),
+		Findings:            make([]CleanupPreflightFindingDTO, len(p.Findings)),
+		RequiredPermissions: p.RequiredPermissions,
+		MissingPermissions:  p.MissingPermissions,
 	}
-
-	// Calculate totals
-	var totalCost, totalCarbon float64
-	for _, r := range resources {
-		totalCost += r.MonthlyCost
-		totalCarbon += r.CarbonFootprint
+	for i, f := range p.Findings {
+		dto.Findings[i] = CleanupPreflightFindingDTO{Check: f.Check, Blocking: f.Blocking, Message: f.Message}
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"resources":                 resources,
-		"count":                     len(resources),
-		"estimated_monthly_savings": totalCost,
-		"estimated_carbon_savings":  totalCarbon,
-		"action":                    req.Action,
-	})
+	return dto
 }
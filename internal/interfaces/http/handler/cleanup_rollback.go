@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListJobItems godoc
+//
+//	@Summary		List cleanup job items
+//	@Description	Get the result of a cleanup job for each of its resources, skipped ones included, as recorded in their timelines once the job ran. recovery tells how to undo an action that succeeded: automated ones (start, unquarantine, untag, restore_snapshot) can be rolled back, the others come with instructions, such as the snapshot or the versioning state to recreate the resource from. Dry runs record no items.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Job ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[[]CleanupJobItemDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/cleanup/jobs/{id}/items [get]
+func (h *CleanupHandler) ListJobItems(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid job ID")
+		return
+	}
+
+	items, err := h.cleanups.JobItems(c.Request.Context(), id)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	data := make([]CleanupJobItemDTO, len(items))
+	for i, item := range items {
+		data[i] = CleanupJobItemDTO{
+			ResourceID:    item.ResourceID.String(),
+			Action:        item.Action,
+			Success:       item.Success,
+			Error:         item.Error,
+			AttemptedAt:   item.AttemptedAt,
+			Recovery:      item.Recovery,
+			RolledBack:    item.RolledBack,
+			RollbackAt:    item.RollbackAt,
+			RollbackError: item.RollbackError,
+		}
+	}
+	c.JSON(http.StatusOK, dataResponse(data))
+}
+
+// RollbackJobItem godoc
+//
+//	@Summary		Roll back a cleanup job item
+//	@Description	Queue undoing the action a cleanup job ran on one of its resources, from the recovery recorded with it: stopped resources are started, quarantined ones restored, tags removed, and deleted databases recreated from their final snapshot. Actions that cannot be undone automatically, such as deleted buckets, are refused with their instructions, and so are actions that failed, were already rolled back or whose undo window has ended. The rollback is recorded in the timeline of the resource. Refused with the read_only code in read-only mode.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Job ID"	format(uuid)
+//	@Param			resource_id	path		string	true	"Resource ID"	format(uuid)
+//	@Success		202			{object}	MessageResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/cleanup/jobs/{id}/items/{resource_id}/rollback [post]
+func (h *CleanupHandler) RollbackJobItem(c *gin.Context) {
+	resourceID, err := uuid.Parse(c.Param("resource_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid job ID")
+		return
+	}
+
+	err = h.cleanups.Rollback(c.Request.Context(), usecase.RollbackCleanupJobItemInput{
+		JobID:      jobID,
+		ResourceID: resourceID,
+		Actor:      requestActor(c),
+	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "cleanup rollback queued"})
+}
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateCleanupSessionRequest represents a request to start a guided cleanup
+// session over the flagged resources matching the filters
+type CreateCleanupSessionRequest struct {
+	OrganizationID string            `json:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Action         string            `json:"action" binding:"required,oneof=delete stop tag notify quarantine release ticket" example:"delete"`
+	Filters        map[string]string `json:"filters"` // provider, type, region
+	CreatedBy      string            `json:"created_by" example:"alice@example.com"`
+	// ViewID narrows the session down to the unused resources of a saved
+	// view
+	ViewID string `json:"view_id" example:"550e8400-e29b-41d4-a716-446655440003"`
+}
+
+// DecideCleanupSessionRequest represents review decisions on resources of a
+// session
+type DecideCleanupSessionRequest struct {
+	Decisions []CleanupSessionDecision `json:"decisions" binding:"required,min=1,dive"`
+	DecidedBy string                   `json:"decided_by" example:"alice@example.com"`
+}
+
+// CleanupSessionDecision is the decision on one resource
+type CleanupSessionDecision struct {
+	ResourceID string `json:"resource_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Decision   string `json:"decision" binding:"required,oneof=accepted rejected pending" example:"accepted"`
+}
+
+// ExecuteCleanupSessionRequest represents a request to execute the accepted
+// resources of a session
+type ExecuteCleanupSessionRequest struct {
+	DryRun bool `json:"dry_run" example:"false"`
+	// Backup snapshots volumes, disks and databases before deleting them;
+	// the backup_before_delete setting of the organization when omitted
+	Backup *bool `json:"backup" example:"true"`
+}
+
+// ListCleanupSessionItemsRequest represents query parameters for reviewing
+// the resources of a session
+type ListCleanupSessionItemsRequest struct {
+	Decision string `form:"decision" example:"pending"`
+	Limit    int    `form:"limit,default=50" example:"50"`
+	Offset   int    `form:"offset,default=0" example:"0"`
+}
+
+// CreateSession godoc
+//
+//	@Summary		Create cleanup session
+//	@Description	Start a guided cleanup session over the unused resources matching the filters and, when given, the saved view. Resources are snapshotted in order of monthly cost so pages stay stable while they are reviewed.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateCleanupSessionRequest	true	"Session request"
+//	@Success		201		{object}	DataResponse[CleanupSessionDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/cleanup/sessions [post]
+func (h *CleanupHandler) CreateSession(c *gin.Context) {
+	var req CreateCleanupSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	input := usecase.CreateCleanupSessionInput{
+		OrganizationID: orgID,
+		Action:         entity.PolicyAction(req.Action),
+		Filters:        req.Filters,
+		CreatedBy:      req.CreatedBy,
+	}
+	if req.ViewID != "" {
+		viewID, err := uuid.Parse(req.ViewID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid view ID")
+			return
+		}
+		input.ViewID = &viewID
+	}
+
+	out, err := h.cleanups.CreateSession(c.Request.Context(), input)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dataResponse(toCleanupSessionDTO(out)))
+}
+
+// GetSession godoc
+//
+//	@Summary		Get cleanup session
+//	@Description	Get a cleanup session with its review progress. Sessions are shared by ID between teammates.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Session ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[CleanupSessionDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/cleanup/sessions/{id} [get]
+func (h *CleanupHandler) GetSession(c *gin.Context) {
+	id, ok := sessionID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.cleanups.GetSession(c.Request.Context(), id)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(toCleanupSessionDTO(out)))
+}
+
+// ListSessionItems godoc
+//
+//	@Summary		List cleanup session resources
+//	@Description	Get a page of the resources of a cleanup session, in review order, with their decision
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Session ID"	format(uuid)
+//	@Param			decision	query		string	false	"Filter by decision"	Enums(pending, accepted, rejected)
+//	@Param			limit		query		int		false	"Number of items per page"	default(50)
+//	@Param			offset		query		int		false	"Number of items to skip"	default(0)
+//	@Success		200			{object}	PaginatedResponse{data=[]CleanupSessionItemDTO}
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/cleanup/sessions/{id}/items [get]
+func (h *CleanupHandler) ListSessionItems(c *gin.Context) {
+	var req ListCleanupSessionItemsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	id, ok := sessionID(c)
+	if !ok {
+		return
+	}
+
+	input := usecase.ListCleanupSessionItemsInput{SessionID: id, Limit: req.Limit, Offset: req.Offset}
+	if req.Decision != "" {
+		decision := entity.CleanupDecision(req.Decision)
+		input.Decision = &decision
+	}
+	out, err := h.cleanups.ListSessionItems(c.Request.Context(), input)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	data := make([]CleanupSessionItemDTO, len(out.Items))
+	for i, item := range out.Items {
+		data[i] = CleanupSessionItemDTO{
+			Resource:  toResourceDTO(item.Resource),
+			Decision:  string(item.Decision),
+			DecidedBy: item.DecidedBy,
+			DecidedAt: item.DecidedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   data,
+		Total:  out.Total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+// DecideSession godoc
+//
+//	@Summary		Review cleanup session resources
+//	@Description	Accept or reject resources of an open cleanup session. Decisions can be changed until the session is executed.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Session ID"	format(uuid)
+//	@Param			request	body		DecideCleanupSessionRequest	true	"Decisions"
+//	@Success		200		{object}	DataResponse[CleanupSessionDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/cleanup/sessions/{id}/items [put]
+func (h *CleanupHandler) DecideSession(c *gin.Context) {
+	var req DecideCleanupSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	byDecision := make(map[entity.CleanupDecision][]uuid.UUID)
+	for _, d := range req.Decisions {
+		id, err := uuid.Parse(d.ResourceID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid resource ID: "+d.ResourceID)
+			return
+		}
+		decision := entity.CleanupDecision(d.Decision)
+		byDecision[decision] = append(byDecision[decision], id)
+	}
+	id, ok := sessionID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.cleanups.DecideSession(c.Request.Context(), usecase.DecideCleanupSessionInput{
+		SessionID: id,
+		Decisions: byDecision,
+		DecidedBy: req.DecidedBy,
+	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(toCleanupSessionDTO(out)))
+}
+
+// ExecuteSession godoc
+//
+//	@Summary		Execute cleanup session
+//	@Description	Queue one cleanup task for the accepted resources of a session and close it. Deletions back up volumes, disks and databases first when backup is set, or by default when the organization enabled backup_before_delete. Sessions are not executed, except as dry runs, while the installation or the organization is in read-only mode.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Session ID"	format(uuid)
+//	@Param			request	body		ExecuteCleanupSessionRequest	false	"Execution options"
+//	@Success		202		{object}	DataResponse[CleanupSessionDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/cleanup/sessions/{id}/execute [post]
+func (h *CleanupHandler) ExecuteSession(c *gin.Context) {
+	var req ExecuteCleanupSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.RespondInvalid(c, err)
+			return
+		}
+	}
+	id, ok := sessionID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.cleanups.ExecuteSession(c.Request.Context(), usecase.ExecuteCleanupSessionInput{
+		SessionID: id,
+		DryRun:    req.DryRun,
+		Backup:    req.Backup,
+	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, dataResponse(toCleanupSessionDTO(out)))
+}
+
+// CancelSession godoc
+//
+//	@Summary		Cancel cleanup session
+//	@Description	Close an open cleanup session without executing it
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Session ID"	format(uuid)
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/cleanup/sessions/{id} [delete]
+func (h *CleanupHandler) CancelSession(c *gin.Context) {
+	id, ok := sessionID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cleanups.CancelSession(c.Request.Context(), id); err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "cleanup session cancelled"})
+}
+
+// sessionID parses the session ID of the request path. It writes the error
+// response and returns false when it is invalid.
+func sessionID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid session ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// toCleanupSessionDTO summarizes the review progress of a session
+func toCleanupSessionDTO(out *usecase.CleanupSessionOutput) CleanupSessionDTO {
+	s := out.Session
+	dto := CleanupSessionDTO{
+		ID:             s.ID.String(),
+		OrganizationID: s.OrganizationID.String(),
+		Action:         string(s.Action),
+		Filters:        s.Filters,
+		Status:         string(s.Status),
+		CreatedBy:      s.CreatedBy,
+		TaskID:         s.TaskID,
+		DryRun:         s.DryRun,
+		ExecutedAt:     s.ExecutedAt,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+	for decision, tally := range out.Tallies {
+		dto.Total += tally.Count
+		switch decision {
+		case entity.CleanupDecisionPending:
+			dto.Pending = tally.Count
+		case entity.CleanupDecisionAccepted:
+			dto.Accepted = tally.Count
+			dto.EstimatedMonthlySavings = tally.MonthlyCost
+			dto.EstimatedCarbonSavings = tally.CarbonFootprint
+		case entity.CleanupDecisionRejected:
+			dto.Rejected = tally.Count
+		}
+	}
+	return dto
+}
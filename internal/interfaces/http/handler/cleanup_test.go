@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+)
+
+// fakeCleanups is a CleanupService whose methods are set by each test
+type fakeCleanups struct {
+	usecase.CleanupService
+	execute        func(usecase.CleanupInput) (*entity.Job, error)
+	preview        func(usecase.CleanupInput) (*usecase.CleanupPreview, error)
+	jobItems       func(uuid.UUID) ([]*usecase.CleanupJobItem, error)
+	rollback       func(usecase.RollbackCleanupJobItemInput) error
+	createSession  func(usecase.CreateCleanupSessionInput) (*usecase.CleanupSessionOutput, error)
+	listItems      func(usecase.ListCleanupSessionItemsInput) (*usecase.ListCleanupSessionItemsOutput, error)
+	decideSession  func(usecase.DecideCleanupSessionInput) (*usecase.CleanupSessionOutput, error)
+	executeSession func(usecase.ExecuteCleanupSessionInput) (*usecase.CleanupSessionOutput, error)
+	cancelSession  func(uuid.UUID) error
+}
+
+func (f *fakeCleanups) Execute(ctx context.Context, input usecase.CleanupInput) (*entity.Job, error) {
+	return f.execute(input)
+}
+
+func (f *fakeCleanups) Preview(ctx context.Context, input usecase.CleanupInput) (*usecase.CleanupPreview, error) {
+	return f.preview(input)
+}
+
+func (f *fakeCleanups) JobItems(ctx context.Context, id uuid.UUID) ([]*usecase.CleanupJobItem, error) {
+	return f.jobItems(id)
+}
+
+func (f *fakeCleanups) Rollback(ctx context.Context, input usecase.RollbackCleanupJobItemInput) error {
+	return f.rollback(input)
+}
+
+func (f *fakeCleanups) CreateSession(ctx context.Context, input usecase.CreateCleanupSessionInput) (*usecase.CleanupSessionOutput, error) {
+	return f.createSession(input)
+}
+
+func (f *fakeCleanups) ListSessionItems(ctx context.Context, input usecase.ListCleanupSessionItemsInput) (*usecase.ListCleanupSessionItemsOutput, error) {
+	return f.listItems(input)
+}
+
+func (f *fakeCleanups) DecideSession(ctx context.Context, input usecase.DecideCleanupSessionInput) (*usecase.CleanupSessionOutput, error) {
+	return f.decideSession(input)
+}
+
+func (f *fakeCleanups) ExecuteSession(ctx context.Context, input usecase.ExecuteCleanupSessionInput) (*usecase.CleanupSessionOutput, error) {
+	return f.executeSession(input)
+}
+
+func (f *fakeCleanups) CancelSession(ctx context.Context, id uuid.UUID) error {
+	return f.cancelSession(id)
+}
+
+// reviewedSession returns an open session with 3 pending resources, 2
+// accepted and 1 rejected
+func reviewedSession() *usecase.CleanupSessionOutput {
+	return &usecase.CleanupSessionOutput{
+		Session: &entity.CleanupSession{
+			ID:             uuid.New(),
+			OrganizationID: goldenOrgID,
+			Action:         entity.PolicyActionDelete,
+			Filters:        map[string]string{"provider": "aws"},
+			Status:         entity.CleanupSessionStatusOpen,
+			CreatedAt:      goldenUpdated,
+			UpdatedAt:      goldenUpdated,
+		},
+		Tallies: map[entity.CleanupDecision]entity.CleanupSessionTally{
+			entity.CleanupDecisionPending:  {Count: 3, MonthlyCost: 30},
+			entity.CleanupDecisionAccepted: {Count: 2, MonthlyCost: 120, CarbonFootprint: 1.5},
+			entity.CleanupDecisionRejected: {Count: 1, MonthlyCost: 8},
+		},
+	}
+}
+
+func TestCleanupHandlerExecute(t *testing.T) {
+	viewID := uuid.New()
+	job := &entity.Job{ID: uuid.New(), TaskID: "task-1", Type: entity.JobTypeCleanup}
+	var got usecase.CleanupInput
+	h := NewCleanupHandler(&fakeCleanups{execute: func(input usecase.CleanupInput) (*entity.Job, error) {
+		got = input
+		return job, nil
+	}})
+
+	body := `{"organization_id":"` + goldenOrgID.String() + `","view_id":"` + viewID.String() + `","action":"delete","backup":false}`
+	w := serve(http.MethodPost, "/cleanup", "/cleanup", body, h.Execute)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", w.Code, w.Body)
+	}
+	if got.OrganizationID != goldenOrgID || got.ViewID == nil || *got.ViewID != viewID || got.Action != entity.PolicyActionDelete ||
+		got.Backup == nil || *got.Backup || got.ResourceIDs != nil {
+		t.Errorf("input = %+v", got)
+	}
+	resp := decode[ExecuteCleanupResponse](t, w)
+	if resp.TaskID != "task-1" || resp.JobID != job.ID.String() {
+		t.Errorf("response = %+v, want the task and job", resp)
+	}
+
+	body = `{"organization_id":"` + goldenOrgID.String() + `","resource_ids":["vol-1"],"action":"delete"}`
+	if w := serve(http.MethodPost, "/cleanup", "/cleanup", body, h.Execute); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid resource ID status = %d, want 400: %s", w.Code, w.Body)
+	}
+}
+
+func TestCleanupHandlerPreview(t *testing.T) {
+	resources := costlyVolumes(2)
+	h := NewCleanupHandler(&fakeCleanups{preview: func(input usecase.CleanupInput) (*usecase.CleanupPreview, error) {
+		if len(input.ResourceIDs) != 2 || input.ResourceIDs[0] != resources[0].ID {
+			t.Errorf("resource IDs = %v", input.ResourceIDs)
+		}
+		return &usecase.CleanupPreview{
+			Resources: resources,
+			Preflights: []*service.Preflight{
+				{Verdict: service.PreflightProceed},
+				{Verdict: service.PreflightFail, Findings: []service.PreflightFinding{{Check: service.PreflightCheckDNS, Blocking: true, Message: "still referenced"}}},
+			},
+			Verdicts:       map[service.PreflightVerdict]int{service.PreflightProceed: 1, service.PreflightFail: 1},
+			MonthlySavings: 100,
+			DNSReferences:  []usecase.CleanupDNSReference{{ResourceID: resources[1].ID, Records: []string{"www.example.com A"}, Checked: true, Blocking: true}},
+		}, nil
+	}})
+
+	body := `{"organization_id":"` + goldenOrgID.String() + `","resource_ids":["` + resources[0].ID.String() + `","` + resources[1].ID.String() + `"],"action":"delete"}`
+	w := serve(http.MethodPost, "/cleanup/preview", "/cleanup/preview", body, h.Preview)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	preview := decode[CleanupPreviewDTO](t, w)
+	if preview.Count != 2 || preview.EstimatedMonthlySavings != 100 || preview.Verdicts["fail"] != 1 {
+		t.Errorf("preview = %+v", preview)
+	}
+	if len(preview.Preflight) != 2 || preview.Preflight[1].ResourceID != resources[1].ID.String() || preview.Preflight[1].Findings[0].Check != "dns" {
+		t.Errorf("preflight = %+v, want the findings of each resource", preview.Preflight)
+	}
+	if len(preview.DNSReferences) != 1 || preview.DNSReferences[0].ResourceID != resources[1].ID.String() || !preview.DNSReferences[0].Blocking {
+		t.Errorf("DNS references = %+v", preview.DNSReferences)
+	}
+}
+
+func TestCleanupHandlerJobItems(t *testing.T) {
+	jobID, resourceID := uuid.New(), uuid.New()
+	var got usecase.RollbackCleanupJobItemInput
+	h := NewCleanupHandler(&fakeCleanups{
+		jobItems: func(id uuid.UUID) ([]*usecase.CleanupJobItem, error) {
+			if id != jobID {
+				return nil, apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "job not found")
+			}
+			rolledBackAt := goldenUpdated
+			return []*usecase.CleanupJobItem{{
+				ResourceID:  resourceID,
+				Action:      "stop",
+				Success:     true,
+				AttemptedAt: goldenCreated,
+				Recovery:    map[string]any{"kind": "start", "automated": true},
+				RolledBack:  true,
+				RollbackAt:  &rolledBackAt,
+			}}, nil
+		},
+		rollback: func(input usecase.RollbackCleanupJobItemInput) error {
+			got = input
+			return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "the action was already rolled back")
+		},
+	})
+
+	w := serve(http.MethodGet, "/cleanup/jobs/:id/items", "/cleanup/jobs/"+jobID.String()+"/items", "", h.ListJobItems)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	items := decode[DataResponse[[]CleanupJobItemDTO]](t, w).Data
+	if len(items) != 1 || items[0].ResourceID != resourceID.String() || !items[0].RolledBack || items[0].Recovery["kind"] != "start" {
+		t.Errorf("items = %+v", items)
+	}
+	if w := serve(http.MethodGet, "/cleanup/jobs/:id/items", "/cleanup/jobs/"+uuid.NewString()+"/items", "", h.ListJobItems); w.Code != http.StatusNotFound {
+		t.Errorf("unknown job status = %d, want 404", w.Code)
+	}
+
+	target := "/cleanup/jobs/" + jobID.String() + "/items/" + resourceID.String() + "/rollback"
+	w = serve(http.MethodPost, "/cleanup/jobs/:id/items/:resource_id/rollback", target, "", h.RollbackJobItem)
+	if w.Code != http.StatusConflict {
+		t.Errorf("rollback status = %d, want 409: %s", w.Code, w.Body)
+	}
+	if got.JobID != jobID || got.ResourceID != resourceID || got.Actor != entity.ResourceEventActorAPI {
+		t.Errorf("rollback input = %+v", got)
+	}
+}
+
+func TestCleanupHandlerSessions(t *testing.T) {
+	session := reviewedSession()
+	id := session.Session.ID
+	var (
+		created usecase.CreateCleanupSessionInput
+		decided usecase.DecideCleanupSessionInput
+		listed  usecase.ListCleanupSessionItemsInput
+	)
+	h := NewCleanupHandler(&fakeCleanups{
+		createSession: func(input usecase.CreateCleanupSessionInput) (*usecase.CleanupSessionOutput, error) {
+			created = input
+			return session, nil
+		},
+		listItems: func(input usecase.ListCleanupSessionItemsInput) (*usecase.ListCleanupSessionItemsOutput, error) {
+			listed = input
+			items := []*entity.CleanupSessionItem{{SessionID: id, Decision: entity.CleanupDecisionAccepted, Resource: costlyVolumes(1)[0]}}
+			return &usecase.ListCleanupSessionItemsOutput{Items: items, Total: 2}, nil
+		},
+		decideSession: func(input usecase.DecideCleanupSessionInput) (*usecase.CleanupSessionOutput, error) {
+			decided = input
+			return session, nil
+		},
+		executeSession: func(usecase.ExecuteCleanupSessionInput) (*usecase.CleanupSessionOutput, error) {
+			return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "cleanup session is executed")
+		},
+	})
+
+	body := `{"organization_id":"` + goldenOrgID.String() + `","action":"delete","filters":{"provider":"aws"},"created_by":"alice@example.com"}`
+	w := serve(http.MethodPost, "/cleanup/sessions", "/cleanup/sessions", body, h.CreateSession)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201: %s", w.Code, w.Body)
+	}
+	if created.OrganizationID != goldenOrgID || created.Filters["provider"] != "aws" || created.CreatedBy != "alice@example.com" || created.ViewID != nil {
+		t.Errorf("create input = %+v", created)
+	}
+	dto := decode[DataResponse[CleanupSessionDTO]](t, w).Data
+	if dto.Total != 6 || dto.Pending != 3 || dto.Accepted != 2 || dto.Rejected != 1 || dto.EstimatedMonthlySavings != 120 {
+		t.Errorf("session = %+v, want the savings of the accepted resources", dto)
+	}
+
+	w = serve(http.MethodGet, "/cleanup/sessions/:id/items", "/cleanup/sessions/"+id.String()+"/items?decision=accepted&limit=1", "", h.ListSessionItems)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if listed.SessionID != id || listed.Decision == nil || *listed.Decision != entity.CleanupDecisionAccepted || listed.Limit != 1 {
+		t.Errorf("list input = %+v", listed)
+	}
+	page := decode[struct {
+		Data  []CleanupSessionItemDTO `json:"data"`
+		Total int64                   `json:"total"`
+	}](t, w)
+	if page.Total != 2 || len(page.Data) != 1 || page.Data[0].Resource.ResourceID != "vol-a" {
+		t.Errorf("page = %+v", page)
+	}
+
+	accepted, rejected := uuid.New(), uuid.New()
+	body = `{"decisions":[{"resource_id":"` + accepted.String() + `","decision":"accepted"},{"resource_id":"` + rejected.String() + `","decision":"rejected"}],"decided_by":"bob@example.com"}`
+	if w := serve(http.MethodPut, "/cleanup/sessions/:id/items", "/cleanup/sessions/"+id.String()+"/items", body, h.DecideSession); w.Code != http.StatusOK {
+		t.Fatalf("decide status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if decided.SessionID != id || decided.DecidedBy != "bob@example.com" ||
+		len(decided.Decisions[entity.CleanupDecisionAccepted]) != 1 || decided.Decisions[entity.CleanupDecisionRejected][0] != rejected {
+		t.Errorf("decide input = %+v", decided)
+	}
+
+	if w := serve(http.MethodPost, "/cleanup/sessions/:id/execute", "/cleanup/sessions/"+id.String()+"/execute", "", h.ExecuteSession); w.Code != http.StatusConflict {
+		t.Errorf("execute status = %d, want 409: %s", w.Code, w.Body)
+	}
+	if w := serve(http.MethodDelete, "/cleanup/sessions/:id", "/cleanup/sessions/not-a-session", "", h.CancelSession); w.Code != http.StatusBadRequest {
+		t.Errorf("cancel status = %d, want 400: %s", w.Code, w.Body)
+	}
+}
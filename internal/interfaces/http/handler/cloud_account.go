@@ -0,0 +1,471 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// CloudAccountHandler handles the cloud accounts of organizations
+type CloudAccountHandler struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+	cfg         *config.Config
+}
+
+// NewCloudAccountHandler creates a new CloudAccountHandler
+func NewCloudAccountHandler(db *gorm.DB, queueClient *asynq.Client, cfg *config.Config) *CloudAccountHandler {
+	return &CloudAccountHandler{db: db, queueClient: queueClient, cfg: cfg}
+}
+
+// UpdatePricingRequest represents a request to change where the resource
+// costs of a cloud account come from
+type UpdatePricingRequest struct {
+	// Source is "estimate" or a pricing source of the provider, see GET
+	// /providers
+	Source string `json:"source" binding:"required" example:"cur"`
+	// Settings are the fields of the pricing source. Secret settings are
+	// kept when left empty and the source is unchanged.
+	Settings map[string]string `json:"settings" example:"bucket:acme-billing,report_name:cloudsweep"`
+}
+
+// List godoc
+//
+//	@Summary		List cloud accounts
+//	@Description	List the cloud accounts of an organization with their pricing source and the outcome of its last reconciliation
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[[]CloudAccountDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/cloud-accounts [get]
+func (h *CloudAccountHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var accounts []model.CloudAccount
+	if err := h.db.WithContext(c.Request.Context()).Where("organization_id = ?", orgID).Order("provider, account_id").Find(&accounts).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to list cloud accounts")
+		return
+	}
+
+	out := make([]CloudAccountDTO, len(accounts))
+	for i := range accounts {
+		out[i] = toCloudAccountDTO(&accounts[i])
+	}
+	c.JSON(http.StatusOK, dataResponse(out))
+}
+
+// UpdatePricing godoc
+//
+//	@Summary		Configure account pricing
+//	@Description	Set where the resource costs of a cloud account come from. With a billing pricing source, e.g. AWS Cost Explorer, the monthly cost of resources is replaced by their amortized cost over the billing window, right away and then on the billing schedule. Back to "estimate", the next scan estimates costs again.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string					true	"Organization ID"	format(uuid)
+//	@Param			account_id	path		string					true	"Cloud account ID"	format(uuid)
+//	@Param			request		body		UpdatePricingRequest	true	"Pricing source"
+//	@Success		200			{object}	DataResponse[CloudAccountDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/cloud-accounts/{account_id}/pricing [put]
+func (h *CloudAccountHandler) UpdatePricing(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	accountID, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid cloud account ID")
+		return
+	}
+
+	var req UpdatePricingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if !requireActiveOrganization(c, h.db, orgID) {
+		return
+	}
+
+	var account model.CloudAccount
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&account, "id = ? AND organization_id = ?", accountID, orgID).Error; err != nil {
+			return err
+		}
+
+		if req.Source == entity.PricingSourceEstimate {
+			if len(req.Settings) > 0 {
+				return &apperrors.AppError{Code: apperrors.CodeValidationFailed, Message: "settings are not supported by the estimate",
+					Details: map[string]any{"settings": "are not supported by the estimate"}}
+			}
+			account.PricingSource = entity.PricingSourceEstimate
+			account.PricingSettings = nil
+			account.PricingSyncedAt = nil
+			account.PricingError = ""
+			err := tx.Model(&account).Updates(map[string]any{
+				"pricing_source":    entity.PricingSourceEstimate,
+				"pricing_settings":  nil,
+				"pricing_synced_at": nil,
+				"pricing_error":     "",
+			}).Error
+			if err != nil {
+				return err
+			}
+			// Billed costs stay until the next scan, which estimates them
+			// again
+			return tx.Model(&model.Resource{}).
+				Where("organization_id = ? AND provider = ? AND account_id = ?", orgID, account.Provider, account.AccountID).
+				Update("metadata", gorm.Expr("metadata #- ?", "{"+service.CostMetadataKey+",source}")).Error
+		}
+
+		p, _ := provider.Lookup(account.Provider)
+		source, ok := p.PricingSource(req.Source)
+		if !ok {
+			message := "source is not a pricing source of " + account.Provider + ", see GET /providers"
+			return &apperrors.AppError{Code: apperrors.CodeValidationFailed, Message: message,
+				Details: map[string]any{"source": "is not a pricing source of " + account.Provider}}
+		}
+		current := map[string]string{}
+		if account.PricingSource == req.Source {
+			current = account.PricingSettingValues()
+		}
+		settings, err := validateSettings(source.Settings, req.Settings, current)
+		if err != nil {
+			return err
+		}
+		account.PricingSource = req.Source
+		account.PricingSettings = settings
+		account.PricingError = ""
+		return tx.Model(&account).Updates(map[string]any{
+			"pricing_source":   req.Source,
+			"pricing_settings": settings,
+			"pricing_error":    "",
+		}).Error
+	})
+	if err != nil {
+		var appErr *apperrors.AppError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			apierror.Respond(c, http.StatusNotFound, "cloud account not found")
+		case errors.As(err, &appErr):
+			apierror.RespondError(c, err)
+		default:
+			apierror.Respond(c, http.StatusInternalServerError, "failed to update pricing")
+		}
+		return
+	}
+
+	if account.PricingSource != entity.PricingSourceEstimate {
+		if err := queue.EnqueueBillingReconcile(c.Request.Context(), h.queueClient, account.ID.String()); err != nil {
+			log.Printf("Failed to enqueue billing reconciliation of cloud account %s: %v", account.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toCloudAccountDTO(&account)))
+}
+
+// UpdateOwnerRequest represents a request to set the default owner of the
+// resources of a cloud account
+type UpdateOwnerRequest struct {
+	// Owner is empty to remove the default owner
+	Owner string `json:"owner" binding:"omitempty,email" example:"platform@example.com"`
+}
+
+// UpdateOwner godoc
+//
+//	@Summary		Set account owner
+//	@Description	Set the default owner of the resources of a cloud account that neither the owner mapping file nor their tags attribute. The owners of the resources of the organization are resolved again in the background.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"Organization ID"	format(uuid)
+//	@Param			account_id	path		string				true	"Cloud account ID"	format(uuid)
+//	@Param			request		body		UpdateOwnerRequest	true	"Owner"
+//	@Success		200			{object}	DataResponse[CloudAccountDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/cloud-accounts/{account_id}/owner [put]
+func (h *CloudAccountHandler) UpdateOwner(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	accountID, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid cloud account ID")
+		return
+	}
+
+	var req UpdateOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	var account model.CloudAccount
+	if err := h.db.WithContext(c.Request.Context()).First(&account, "id = ? AND organization_id = ?", accountID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Respond(c, http.StatusNotFound, "cloud account not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch cloud account")
+		return
+	}
+	account.DefaultOwner = strings.ToLower(req.Owner)
+	if err := h.db.WithContext(c.Request.Context()).Model(&account).Update("default_owner", account.DefaultOwner).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update owner")
+		return
+	}
+
+	if err := queue.EnqueueOwnerAssignment(c.Request.Context(), h.queueClient, orgID.String()); err != nil {
+		log.Printf("Failed to enqueue owner assignment of org %s: %v", orgID, err)
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toCloudAccountDTO(&account)))
+}
+
+// Delete godoc
+//
+//	@Summary		Delete cloud account
+//	@Description	Delete a cloud account: it is no longer scanned nor counted in the plan quota. Its resources stay in the inventory. It can be restored with POST /organizations/{id}/cloud-accounts/{account_id}/restore.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Organization ID"	format(uuid)
+//	@Param			account_id	path		string	true	"Cloud account ID"	format(uuid)
+//	@Success		200			{object}	MessageResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/cloud-accounts/{account_id} [delete]
+func (h *CloudAccountHandler) Delete(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	accountID, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid cloud account ID")
+		return
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Delete(&model.CloudAccount{}, "id = ? AND organization_id = ?", accountID, orgID)
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to delete cloud account")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "cloud account not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "cloud account deleted"})
+}
+
+// Restore godoc
+//
+//	@Summary		Restore cloud account
+//	@Description	Restore a deleted cloud account. An active account must fit in the cloud account quota of the plan.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Organization ID"	format(uuid)
+//	@Param			account_id	path		string	true	"Cloud account ID"	format(uuid)
+//	@Success		200			{object}	DataResponse[CloudAccountDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		402			{object}	QuotaErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/cloud-accounts/{account_id}/restore [post]
+func (h *CloudAccountHandler) Restore(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	accountID, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid cloud account ID")
+		return
+	}
+	if !requireActiveOrganization(c, h.db, orgID) {
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	var account model.CloudAccount
+	if err := db.Unscoped().First(&account, "id = ? AND organization_id = ? AND deleted_at IS NOT NULL", accountID, orgID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "deleted cloud account not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch cloud account")
+		return
+	}
+
+	if account.IsActive {
+		var org model.Organization
+		if err := db.Select("plan").First(&org, "id = ?", orgID).Error; err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, "failed to fetch organization")
+			return
+		}
+		usage, err := loadUsage(c.Request.Context(), h.db, orgID, time.Now())
+		if err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, "failed to count cloud accounts")
+			return
+		}
+		if err := entity.CheckQuota(org.Plan, entity.QuotaCloudAccounts, usage[entity.QuotaCloudAccounts], 1); err != nil {
+			respondQuotaError(c, err)
+			return
+		}
+	}
+
+	if err := db.Unscoped().Model(&account).Update("deleted_at", nil).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to restore cloud account")
+		return
+	}
+	account.DeletedAt = gorm.DeletedAt{}
+
+	c.JSON(http.StatusOK, dataResponse(toCloudAccountDTO(&account)))
+}
+
+// CheckPermissions godoc
+//
+//	@Summary		Check account permissions
+//	@Description	Check the permissions granted to the credentials of a cloud account against those CloudSweep calls to scan it and for each cleanup action, and report those missing. AWS accounts are checked with the IAM policy simulator, which needs iam:SimulatePrincipalPolicy; Azure subscriptions with the permissions of the role assignments of the service principal; Google Cloud projects with testIamPermissions. Providers that cannot check their permissions get 400, and failed checks 502 with the error of the provider.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Organization ID"	format(uuid)
+//	@Param			account_id	path		string	true	"Cloud account ID"	format(uuid)
+//	@Success		200			{object}	DataResponse[PermissionReportDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Failure		502			{object}	ErrorResponse
+//	@Router			/organizations/{id}/cloud-accounts/{account_id}/permissions-check [post]
+func (h *CloudAccountHandler) CheckPermissions(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	accountID, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid cloud account ID")
+		return
+	}
+
+	var account model.CloudAccount
+	if err := h.db.WithContext(c.Request.Context()).First(&account, "id = ? AND organization_id = ?", accountID, orgID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "cloud account not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch cloud account")
+		return
+	}
+
+	p, ok := provider.Lookup(account.Provider)
+	if !ok || p.NewPermissionChecker == nil {
+		apierror.Respond(c, http.StatusBadRequest, account.Provider+" cannot check the permissions of its accounts")
+		return
+	}
+	checker, err := p.NewPermissionChecker(account.Credentials, h.cfg)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	checks, err := service.CheckAccountPermissions(c.Request.Context(), checker, p.Name, p.ResourceTypes)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadGateway, "failed to check permissions: "+err.Error())
+		return
+	}
+
+	report := PermissionReportDTO{
+		AccountID: account.ID.String(),
+		Provider:  account.Provider,
+		Complete:  true,
+		Checks:    make([]PermissionCheckDTO, len(checks)),
+		CheckedAt: time.Now(),
+	}
+	for i, check := range checks {
+		report.Checks[i] = PermissionCheckDTO{
+			Purpose:  check.Purpose,
+			Granted:  len(check.Missing) == 0,
+			Required: check.Required,
+			Missing:  check.Missing,
+		}
+		if len(check.Missing) > 0 {
+			report.Complete = false
+		}
+	}
+	c.JSON(http.StatusOK, dataResponse(report))
+}
+
+func toCloudAccountDTO(m *model.CloudAccount) CloudAccountDTO {
+	secret := map[string]bool{}
+	if p, ok := provider.Lookup(m.Provider); ok {
+		if source, ok := p.PricingSource(m.PricingSource); ok {
+			for _, f := range source.Settings {
+				secret[f.Name] = f.Secret
+			}
+		}
+	}
+	settings := map[string]string{}
+	for name, value := range m.PricingSettingValues() {
+		if !secret[name] {
+			settings[name] = value
+		}
+	}
+
+	dto := CloudAccountDTO{
+		ID:              m.ID.String(),
+		OrganizationID:  m.OrganizationID.String(),
+		Provider:        m.Provider,
+		AccountID:       m.AccountID,
+		Name:            m.Name,
+		IsActive:        m.IsActive,
+		PricingSource:   m.PricingSource,
+		PricingSettings: settings,
+		PricingSyncedAt: m.PricingSyncedAt,
+		DefaultOwner:    m.DefaultOwner,
+		PricingError:    m.PricingError,
+		LastSyncAt:      m.LastSyncAt,
+		CreatedAt:       m.CreatedAt,
+	}
+	if m.IntegrationID != nil {
+		id := m.IntegrationID.String()
+		dto.IntegrationID = &id
+	}
+	return dto
+}
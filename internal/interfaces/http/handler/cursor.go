@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// errInvalidCursor is returned for cursors that were tampered with or come
+// from a list with another sort
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the position after the last item of a page in keyset
+// pagination: the sort value and ID of that item. Unlike offsets, cursors
+// cost the same on every page and do not skip or repeat items inserted in
+// between.
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"i"`
+}
+
+// keysetSort is the order of a list paginated by cursor: a column, then the
+// ID to break ties, in the same direction
+type keysetSort struct {
+	Name   string // identifies the sort in cursors
+	Column string
+	Desc   bool
+}
+
+// Order returns the ORDER BY clause of the sort
+func (s keysetSort) Order() string {
+	if s.Desc {
+		return s.Column + " DESC, id DESC"
+	}
+	return s.Column + " ASC, id ASC"
+}
+
+func (s keysetSort) encode(value, id string) string {
+	data, _ := json.Marshal(pageCursor{Sort: s.Name, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// apply restricts a query to the items after the cursor. An empty cursor
+// leaves the query unchanged.
+func (s keysetSort) apply(query *gorm.DB, cursor string) (*gorm.DB, error) {
+	if cursor == "" {
+		return query, nil
+	}
+	cur, err := s.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	op := ">"
+	if s.Desc {
+		op = "<"
+	}
+	return query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", s.Column, op), cur.Value, cur.ID), nil
+}
+
+// decode returns the position of a cursor of the sort
+func (s keysetSort) decode(cursor string) (pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	var cur pageCursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.Sort != s.Name || cur.ID == "" {
+		return pageCursor{}, errInvalidCursor
+	}
+	return cur, nil
+}
+
+// keysetPage trims the limit+1 items fetched for a page to limit, and
+// returns the cursor of the next page, empty on the last page
+func keysetPage[T any](s keysetSort, items []T, limit int, key func(*T) (value, id string)) ([]T, string) {
+	if limit <= 0 || len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	value, id := key(&items[limit-1])
+	return items, s.encode(value, id)
+}
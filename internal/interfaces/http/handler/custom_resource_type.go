@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CustomResourceTypeHandler handles the endpoints of the resource types
+// organizations define
+type CustomResourceTypeHandler struct {
+	db *gorm.DB
+}
+
+// NewCustomResourceTypeHandler creates a new CustomResourceTypeHandler
+func NewCustomResourceTypeHandler(db *gorm.DB) *CustomResourceTypeHandler {
+	return &CustomResourceTypeHandler{db: db}
+}
+
+// CreateCustomResourceTypeRequest represents a request to define a resource
+// type
+type CreateCustomResourceTypeRequest struct {
+	// Name is the resource type of the resources found, which must not be a
+	// built-in type
+	Name     string `json:"name" binding:"required" example:"dynamodb_table"`
+	Provider string `json:"provider" binding:"required,provider" example:"aws"`
+	UpdateCustomResourceTypeRequest
+}
+
+// UpdateCustomResourceTypeRequest represents a request to change how the
+// resources of a custom type are found and detected
+type UpdateCustomResourceTypeRequest struct {
+	// CloudType is the type name of the provider, e.g. the CloudFormation
+	// type listed with Cloud Control for AWS
+	CloudType string             `json:"cloud_type" binding:"required" example:"AWS::DynamoDB::Table"`
+	Detection MetricDetectionDTO `json:"detection"`
+	// MonthlyCost is the estimated cost of a resource in USD, used for the
+	// savings of unused ones
+	MonthlyCost float64 `json:"monthly_cost" binding:"gte=0" example:"25"`
+}
+
+// List godoc
+//
+//	@Summary		List custom resource types
+//	@Description	List the resource types defined by an organization, by provider and name
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[[]CustomResourceTypeDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/custom-resource-types [get]
+func (h *CustomResourceTypeHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var types []model.CustomResourceType
+	if err := h.db.WithContext(c.Request.Context()).Where("organization_id = ?", orgID).Order("provider, name").Find(&types).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to list custom resource types")
+		return
+	}
+
+	out := make([]CustomResourceTypeDTO, len(types))
+	for i := range types {
+		out[i] = toCustomResourceTypeDTO(&types[i])
+	}
+	c.JSON(http.StatusOK, dataResponse(out))
+}
+
+// Create godoc
+//
+//	@Summary		Create custom resource type
+//	@Description	Define a resource type for a service CloudSweep does not cover yet. Scans of the provider list its resources through the generic API of the provider (AWS Cloud Control) and report them unused when the metric of the detection (a CloudWatch metric whose dimension is the resource identifier), aggregated over the lookback window, is below the threshold, or above it with above set.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Organization ID"	format(uuid)
+//	@Param			request	body		CreateCustomResourceTypeRequest	true	"Resource type"
+//	@Success		201		{object}	DataResponse[CustomResourceTypeDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/custom-resource-types [post]
+func (h *CustomResourceTypeHandler) Create(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req CreateCustomResourceTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if p, _ := provider.Lookup(req.Provider); p.NewCustomDetector == nil {
+		apierror.Respond(c, http.StatusBadRequest, "provider "+req.Provider+" does not support custom resource types")
+		return
+	}
+
+	t := model.CustomResourceType{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Provider:       req.Provider,
+	}
+	req.UpdateCustomResourceTypeRequest.apply(&t)
+	if err := database.CustomResourceTypeEntity(&t).Validate(); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !requireActiveOrganization(c, h.db, orgID) {
+		return
+	}
+	if !h.checkName(c, orgID, req.Name) {
+		return
+	}
+
+	if err := h.db.WithContext(c.Request.Context()).Create(&t).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to create custom resource type")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dataResponse(toCustomResourceTypeDTO(&t)))
+}
+
+// Update godoc
+//
+//	@Summary		Update custom resource type
+//	@Description	Change how the resources of a custom type are listed, detected and priced. The name and provider cannot change since they identify the resources already found; the next scan applies the new detection.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Organization ID"	format(uuid)
+//	@Param			type_id	path		string							true	"Custom resource type ID"	format(uuid)
+//	@Param			request	body		UpdateCustomResourceTypeRequest	true	"Resource type"
+//	@Success		200		{object}	DataResponse[CustomResourceTypeDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/custom-resource-types/{type_id} [put]
+func (h *CustomResourceTypeHandler) Update(c *gin.Context) {
+	t, ok := h.findType(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateCustomResourceTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	req.apply(t)
+	if err := database.CustomResourceTypeEntity(t).Validate(); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.db.WithContext(c.Request.Context()).Save(t).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update custom resource type")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toCustomResourceTypeDTO(t)))
+}
+
+// Delete godoc
+//
+//	@Summary		Delete custom resource type
+//	@Description	Stop scanning a custom resource type. The resources already found are marked deleted by the next scan of their regions covering every resource type.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"Organization ID"	format(uuid)
+//	@Param			type_id	path		string	true	"Custom resource type ID"	format(uuid)
+//	@Success		200		{object}	MessageResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/custom-resource-types/{type_id} [delete]
+func (h *CustomResourceTypeHandler) Delete(c *gin.Context) {
+	t, ok := h.findType(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.WithContext(c.Request.Context()).Delete(&model.CustomResourceType{}, "id = ?", t.ID).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to delete custom resource type")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "custom resource type deleted"})
+}
+
+// findType loads the custom resource type of the request path. It writes the
+// error response and returns false when there is none.
+func (h *CustomResourceTypeHandler) findType(c *gin.Context) (*model.CustomResourceType, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return nil, false
+	}
+	id, err := uuid.Parse(c.Param("type_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid custom resource type ID")
+		return nil, false
+	}
+	var t model.CustomResourceType
+	if err := h.db.WithContext(c.Request.Context()).First(&t, "id = ? AND organization_id = ?", id, orgID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "custom resource type not found")
+			return nil, false
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch custom resource type")
+		return nil, false
+	}
+	return &t, true
+}
+
+// checkName refuses a name already used by a custom type of the
+// organization. It writes the error response and returns false when the
+// type must not be created.
+func (h *CustomResourceTypeHandler) checkName(c *gin.Context, orgID uuid.UUID, name string) bool {
+	var count int64
+	err := h.db.WithContext(c.Request.Context()).Model(&model.CustomResourceType{}).
+		Where("organization_id = ? AND name = ?", orgID, name).
+		Count(&count).Error
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch custom resource types")
+		return false
+	}
+	if count > 0 {
+		apierror.Respond(c, http.StatusConflict, "a custom resource type with this name already exists")
+		return false
+	}
+	return true
+}
+
+// apply sets the fields of the request on a custom resource type
+func (r UpdateCustomResourceTypeRequest) apply(t *model.CustomResourceType) {
+	t.CloudType = r.CloudType
+	t.MetricNamespace = r.Detection.Namespace
+	t.MetricName = r.Detection.MetricName
+	t.MetricDimension = r.Detection.Dimension
+	t.MetricStatistic = r.Detection.Statistic
+	t.MetricThreshold = r.Detection.Threshold
+	t.MetricAbove = r.Detection.Above
+	t.MetricLookbackDays = r.Detection.LookbackDays
+	t.MonthlyCost = r.MonthlyCost
+}
+
+func toCustomResourceTypeDTO(t *model.CustomResourceType) CustomResourceTypeDTO {
+	return CustomResourceTypeDTO{
+		ID:             t.ID.String(),
+		OrganizationID: t.OrganizationID.String(),
+		Name:           t.Name,
+		Provider:       t.Provider,
+		CloudType:      t.CloudType,
+		Detection: MetricDetectionDTO{
+			Namespace:    t.MetricNamespace,
+			MetricName:   t.MetricName,
+			Dimension:    t.MetricDimension,
+			Statistic:    t.MetricStatistic,
+			Threshold:    t.MetricThreshold,
+			Above:        t.MetricAbove,
+			LookbackDays: t.MetricLookbackDays,
+		},
+		MonthlyCost: t.MonthlyCost,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
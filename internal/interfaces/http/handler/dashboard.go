@@ -1,21 +1,36 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/middleware"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"github.com/google/uuid"
 )
 
-// DashboardHandler handles dashboard endpoints
+// DashboardHandler handles dashboard endpoints. The aggregates of the
+// summary, savings, carbon, top offenders and forecast endpoints are cached
+// for ttl.
 type DashboardHandler struct {
-	db *gorm.DB
+	dashboards usecase.DashboardService
+	cache      *cache.Cache
+	ttl        time.Duration
 }
 
-// NewDashboardHandler creates a new DashboardHandler
-func NewDashboardHandler(db *gorm.DB) *DashboardHandler {
-	return &DashboardHandler{db: db}
+// NewDashboardHandler creates a new DashboardHandler. A nil cache computes
+// the aggregates on every request.
+func NewDashboardHandler(dashboards usecase.DashboardService, results *cache.Cache, ttl time.Duration) *DashboardHandler {
+	return &DashboardHandler{dashboards: dashboards, cache: results, ttl: ttl}
 }
 
 // SummaryStats represents dashboard summary statistics
@@ -68,122 +83,647 @@ type CarbonResponse struct {
 	ByRegion   []RegionCarbon   `json:"by_region"`
 }
 
+// ScorePoint represents the hygiene score recorded on a given day
+type ScorePoint struct {
+	Date    string             `json:"date" example:"2024-05-01"`
+	Score   float64            `json:"score" example:"72.5"`
+	Factors map[string]float64 `json:"factors"`
+}
+
+// ScoreResponse represents the hygiene score of an organization
+type ScoreResponse struct {
+	Score   float64                 `json:"score" example:"74.2"`
+	Factors []service.HygieneFactor `json:"factors"`
+	Change  *float64                `json:"change,omitempty" example:"1.7"` // since the oldest point of the history
+	History []ScorePoint            `json:"history"`
+}
+
+// TopOffendersRequest represents query parameters for the top waste
+// offenders
+type TopOffendersRequest struct {
+	DashboardRequest
+	// GroupBy ranks single resources, or their totals per team tag value,
+	// account or region
+	GroupBy string `form:"group_by,default=resource" binding:"oneof=resource team account region" example:"team"`
+	TagKey  string `form:"tag_key,default=team" binding:"max=128" example:"team"`
+	Limit   int    `form:"limit,default=10" binding:"min=1,max=100" example:"10"`
+}
+
+// WasteOffender represents an unused resource, or a group of them, ranked by
+// monthly cost
+type WasteOffender struct {
+	Group    string  `json:"group" example:"platform"` // resource ID, tag value, account or region
+	Untagged bool    `json:"untagged,omitempty" example:"false"`
+	Count    int64   `json:"unused_count" example:"12"`
+	Cost     float64 `json:"monthly_cost" example:"840.00"`
+	Carbon   float64 `json:"carbon_kg" example:"35.2"`
+	// Share is the percentage of the unused cost of the scope
+	Share    float64      `json:"share_percent" example:"23.4"`
+	Resource *ResourceDTO `json:"resource,omitempty"` // group_by=resource
+}
+
+// TopOffendersResponse represents the biggest sources of waste
+type TopOffendersResponse struct {
+	GroupBy    string          `json:"group_by" example:"team"`
+	TagKey     string          `json:"tag_key,omitempty" example:"team"`
+	TotalWaste float64         `json:"total_monthly_waste" example:"3590.00"`
+	Offenders  []WasteOffender `json:"offenders"`
+}
+
+// offenderGroups are what the top offenders are grouped by, single
+// resources for "resource"
+var offenderGroups = map[string]repository.WasteGroupBy{
+	"resource": "",
+	"team":     repository.WasteByTag,
+	"account":  repository.WasteByAccount,
+	"region":   repository.WasteByRegion,
+}
+
+// AllocationRequest represents query parameters for the showback report
+type AllocationRequest struct {
+	// OrganizationID is required unless signed in
+	OrganizationID string `form:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Month defaults to the current month
+	Month  string `form:"month" example:"2024-05"`
+	TagKey string `form:"tag_key" example:"team"`
+}
+
+// AllocationValue represents the resources sharing a value of a
+// cost-allocation tag
+type AllocationValue struct {
+	Value         string  `json:"value,omitempty" example:"data-platform"`
+	ResourceCount int64   `json:"resource_count" example:"42"`
+	MonthlyCost   float64 `json:"monthly_cost" example:"3120.00"`
+	Waste         float64 `json:"waste" example:"410.50"`
+	Share         float64 `json:"share_percent" example:"18.2"` // of the total cost
+}
+
+// TagAllocation represents the costs allocated by one tag key
+type TagAllocation struct {
+	TagKey           string            `json:"tag_key" example:"team"`
+	TotalCost        float64           `json:"total_monthly_cost" example:"17140.00"`
+	TotalWaste       float64           `json:"total_waste" example:"2310.00"`
+	AllocatedPercent float64           `json:"allocated_percent" example:"86.4"`
+	Values           []AllocationValue `json:"values"`
+	Unallocated      AllocationValue   `json:"unallocated"` // resources without the tag
+}
+
+// AllocationResponse represents the showback report of a month
+type AllocationResponse struct {
+	Month       string          `json:"month" example:"2024-05"`
+	ComputedAt  *time.Time      `json:"computed_at,omitempty"`
+	Allocations []TagAllocation `json:"allocations"`
+}
+
+// ScoreRequest represents query parameters for the hygiene score
+type ScoreRequest struct {
+	// OrganizationID is required unless signed in
+	OrganizationID string `form:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Days           int    `form:"days,default=30" binding:"min=1,max=365" example:"30"`
+}
+
+// TickerResponse represents the rate at which unused resources waste money
+// and emit CO2e
+type TickerResponse struct {
+	UnusedResources    int64     `json:"unused_resources" example:"75"`
+	CostPerHour        float64   `json:"cost_per_hour" example:"3.42"`
+	CostPerSecond      float64   `json:"cost_per_second" example:"0.00095"`
+	CarbonPerHour      float64   `json:"carbon_kg_per_hour" example:"0.25"`
+	CarbonPerSecond    float64   `json:"carbon_kg_per_second" example:"0.00007"`
+	WastedThisMonth    float64   `json:"wasted_this_month" example:"1231.20"`
+	EmittedThisMonthKg float64   `json:"emitted_this_month_kg" example:"90.0"`
+	AsOf               time.Time `json:"as_of"`
+}
+
+// TickerRequest represents query parameters for the waste ticker
+type TickerRequest struct {
+	// OrganizationID is required unless signed in
+	OrganizationID string `form:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider       string `form:"provider" binding:"omitempty,provider" example:"aws"`
+	AccountID      string `form:"account_id" example:"123456789012"`
+}
+
+// DashboardRequest represents query parameters scoping dashboard aggregates
+type DashboardRequest struct {
+	// OrganizationID is required unless signed in
+	OrganizationID string `form:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// From and To bound the period, as dates or RFC 3339 times; a date To
+	// includes the whole day. Resources count when they were discovered
+	// before To and still seen after From.
+	From      string `form:"from" example:"2024-05-01"`
+	To        string `form:"to" example:"2024-05-31"`
+	Provider  string `form:"provider" binding:"omitempty,provider" example:"aws"`
+	AccountID string `form:"account_id" example:"123456789012"`
+}
+
+// scopeKey identifies a scope among the cached aggregates of its
+// organization
+func scopeKey(scope repository.DashboardScope) string {
+	var provider, accountID string
+	if scope.Provider != nil {
+		provider = string(*scope.Provider)
+	}
+	if scope.AccountID != nil {
+		accountID = *scope.AccountID
+	}
+	return strings.Join([]string{provider, accountID, scope.From.Format(time.RFC3339Nano), scope.To.Format(time.RFC3339Nano)}, "|")
+}
+
 // Summary godoc
 //
 //	@Summary		Dashboard summary
-//	@Description	Get dashboard summary statistics including total resources, unused resources, costs and carbon footprint
+//	@Description	Get dashboard summary statistics of an organization including total resources, unused resources, costs and carbon footprint. The organization is the one of the session, or organization_id otherwise.
 //	@Tags			Dashboard
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	map[string]SummaryStats
-//	@Failure		500	{object}	ErrorResponse
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			from			query		string	false	"Start of the period (date or RFC 3339)"
+//	@Param			to				query		string	false	"End of the period (date, inclusive, or RFC 3339)"
+//	@Param			provider		query		string	false	"Filter by cloud provider"
+//	@Param			account_id		query		string	false	"Filter by provider account, subscription, project or cluster"
+//	@Success		200				{object}	DataResponse[SummaryStats]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
 //	@Router			/dashboard/summary [get]
 func (h *DashboardHandler) Summary(c *gin.Context) {
-	var stats SummaryStats
-
-	// Total resources
-	h.db.Model(&model.Resource{}).Where("status != ?", "deleted").Count(&stats.TotalResources)
-
-	// Unused resources
-	h.db.Model(&model.Resource{}).Where("status = ?", "unused").Count(&stats.UnusedResources)
-
-	// Total cost
-	h.db.Model(&model.Resource{}).
-		Where("status != ?", "deleted").
-		Select("COALESCE(SUM(monthly_cost), 0)").
-		Scan(&stats.TotalCost)
-
-	// Potential savings (unused resources cost)
-	h.db.Model(&model.Resource{}).
-		Where("status = ?", "unused").
-		Select("COALESCE(SUM(monthly_cost), 0)").
-		Scan(&stats.PotentialSavings)
+	scope, ok := bindDashboardScope(c)
+	if !ok {
+		return
+	}
 
-	// Total carbon
-	h.db.Model(&model.Resource{}).
-		Where("status != ?", "deleted").
-		Select("COALESCE(SUM(carbon_footprint), 0)").
-		Scan(&stats.TotalCarbon)
-
-	// Carbon savings
-	h.db.Model(&model.Resource{}).
-		Where("status = ?", "unused").
-		Select("COALESCE(SUM(carbon_footprint), 0)").
-		Scan(&stats.CarbonSavings)
+	ctx := c.Request.Context()
+	stats, err := cache.Fetch(ctx, h.cache, scope.OrganizationID.String(), "dashboard.summary", scopeKey(scope), h.ttl, func() (SummaryStats, error) {
+		totals, err := h.dashboards.Summary(ctx, scope)
+		if err != nil {
+			return SummaryStats{}, err
+		}
+		return SummaryStats{
+			TotalResources:   totals.Resources,
+			UnusedResources:  totals.Unused,
+			TotalCost:        totals.MonthlyCost,
+			PotentialSavings: totals.WasteCost,
+			TotalCarbon:      totals.CarbonFootprint,
+			CarbonSavings:    totals.WasteCarbon,
+		}, nil
+	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"data": stats})
+	c.JSON(http.StatusOK, dataResponse(stats))
 }
 
 // Savings godoc
 //
 //	@Summary		Savings breakdown
-//	@Description	Get potential savings breakdown by provider and resource type
+//	@Description	Get the potential savings of an organization broken down by provider and resource type
 //	@Tags			Dashboard
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	SavingsResponse
-//	@Failure		500	{object}	ErrorResponse
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			from			query		string	false	"Start of the period (date or RFC 3339)"
+//	@Param			to				query		string	false	"End of the period (date, inclusive, or RFC 3339)"
+//	@Param			provider		query		string	false	"Filter by cloud provider"
+//	@Param			account_id		query		string	false	"Filter by provider account, subscription, project or cluster"
+//	@Success		200				{object}	SavingsResponse
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
 //	@Router			/dashboard/savings [get]
 func (h *DashboardHandler) Savings(c *gin.Context) {
-	// By provider
-	var byProvider []ProviderSavings
-
-	h.db.Model(&model.Resource{}).
-		Select("provider, SUM(monthly_cost) as cost, COUNT(*) as count").
-		Where("status = ?", "unused").
-		Group("provider").
-		Scan(&byProvider)
-
-	// By resource type
-	var byType []TypeSavings
-
-	h.db.Model(&model.Resource{}).
-		Select("type, SUM(monthly_cost) as cost, COUNT(*) as count").
-		Where("status = ?", "unused").
-		Group("type").
-		Order("cost DESC").
-		Limit(10).
-		Scan(&byType)
-
-	c.JSON(http.StatusOK, SavingsResponse{
-		ByProvider:     byProvider,
-		ByResourceType: byType,
+	scope, ok := bindDashboardScope(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	resp, err := cache.Fetch(ctx, h.cache, scope.OrganizationID.String(), "dashboard.savings", scopeKey(scope), h.ttl, func() (SavingsResponse, error) {
+		savings, err := h.dashboards.Savings(ctx, scope)
+		if err != nil {
+			return SavingsResponse{}, err
+		}
+		resp := SavingsResponse{
+			ByProvider:     make([]ProviderSavings, 0, len(savings.ByProvider)),
+			ByResourceType: make([]TypeSavings, 0, len(savings.ByType)),
+		}
+		for _, t := range savings.ByProvider {
+			resp.ByProvider = append(resp.ByProvider, ProviderSavings{Provider: t.Group, Cost: t.MonthlyCost, Count: t.Count})
+		}
+		for _, t := range savings.ByType {
+			resp.ByResourceType = append(resp.ByResourceType, TypeSavings{Type: t.Group, Cost: t.MonthlyCost, Count: t.Count})
+		}
+		return resp, nil
 	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
 // Carbon godoc
 //
 //	@Summary		Carbon footprint breakdown
-//	@Description	Get carbon footprint breakdown by provider and region
+//	@Description	Get the carbon footprint of the unused resources of an organization broken down by provider and region
 //	@Tags			Dashboard
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	CarbonResponse
-//	@Failure		500	{object}	ErrorResponse
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			from			query		string	false	"Start of the period (date or RFC 3339)"
+//	@Param			to				query		string	false	"End of the period (date, inclusive, or RFC 3339)"
+//	@Param			provider		query		string	false	"Filter by cloud provider"
+//	@Param			account_id		query		string	false	"Filter by provider account, subscription, project or cluster"
+//	@Success		200				{object}	CarbonResponse
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
 //	@Router			/dashboard/carbon [get]
 func (h *DashboardHandler) Carbon(c *gin.Context) {
-	// By provider
-	var byProvider []ProviderCarbon
-
-	h.db.Model(&model.Resource{}).
-		Select("provider, SUM(carbon_footprint) as carbon").
-		Where("status = ?", "unused").
-		Group("provider").
-		Scan(&byProvider)
-
-	// By region
-	var byRegion []RegionCarbon
-
-	h.db.Model(&model.Resource{}).
-		Select("region, SUM(carbon_footprint) as carbon").
-		Where("status = ?", "unused").
-		Group("region").
-		Order("carbon DESC").
-		Limit(10).
-		Scan(&byRegion)
-
-	c.JSON(http.StatusOK, CarbonResponse{
-		ByProvider: byProvider,
-		ByRegion:   byRegion,
+	scope, ok := bindDashboardScope(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	resp, err := cache.Fetch(ctx, h.cache, scope.OrganizationID.String(), "dashboard.carbon", scopeKey(scope), h.ttl, func() (CarbonResponse, error) {
+		carbon, err := h.dashboards.Carbon(ctx, scope)
+		if err != nil {
+			return CarbonResponse{}, err
+		}
+		resp := CarbonResponse{
+			ByProvider: make([]ProviderCarbon, 0, len(carbon.ByProvider)),
+			ByRegion:   make([]RegionCarbon, 0, len(carbon.ByRegion)),
+		}
+		for _, t := range carbon.ByProvider {
+			resp.ByProvider = append(resp.ByProvider, ProviderCarbon{Provider: t.Group, Carbon: t.CarbonFootprint})
+		}
+		for _, t := range carbon.ByRegion {
+			resp.ByRegion = append(resp.ByRegion, RegionCarbon{Region: t.Group, Carbon: t.CarbonFootprint})
+		}
+		return resp, nil
+	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// TopOffenders godoc
+//
+//	@Summary		Top waste offenders
+//	@Description	Get the most expensive unused resources of an organization, or the teams (by tag), accounts or regions wasting the most, each with its share of the total unused cost. Resources without the team tag are grouped under untagged.
+//	@Tags			Dashboard
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			group_by		query		string	false	"Rank resources or groups"	Enums(resource, team, account, region)	default(resource)
+//	@Param			tag_key			query		string	false	"Tag holding the team, with group_by=team"	default(team)
+//	@Param			limit			query		int		false	"Number of offenders"	default(10)	minimum(1)	maximum(100)
+//	@Param			from			query		string	false	"Start of the period (date or RFC 3339)"
+//	@Param			to				query		string	false	"End of the period (date, inclusive, or RFC 3339)"
+//	@Param			provider		query		string	false	"Filter by cloud provider"
+//	@Param			account_id		query		string	false	"Filter by provider account, subscription, project or cluster"
+//	@Success		200				{object}	DataResponse[TopOffendersResponse]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/dashboard/top-offenders [get]
+func (h *DashboardHandler) TopOffenders(c *gin.Context) {
+	var req TopOffendersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	scope, ok := req.scope(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	params := fmt.Sprintf("%s|%s|%s|%d", scopeKey(scope), req.GroupBy, req.TagKey, req.Limit)
+	resp, err := cache.Fetch(ctx, h.cache, scope.OrganizationID.String(), "dashboard.top_offenders", params, h.ttl, func() (TopOffendersResponse, error) {
+		out, err := h.dashboards.TopOffenders(ctx, usecase.TopOffendersInput{
+			Scope:   scope,
+			GroupBy: offenderGroups[req.GroupBy],
+			TagKey:  req.TagKey,
+			Limit:   req.Limit,
+		})
+		if err != nil {
+			return TopOffendersResponse{}, err
+		}
+		return toTopOffendersResponse(req, out), nil
 	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(resp))
+}
+
+// toTopOffendersResponse converts a ranking of waste offenders to its
+// response
+func toTopOffendersResponse(req TopOffendersRequest, out *usecase.TopOffendersOutput) TopOffendersResponse {
+	resp := TopOffendersResponse{
+		GroupBy:    req.GroupBy,
+		TotalWaste: out.TotalWaste,
+		Offenders:  make([]WasteOffender, 0, len(out.Offenders)),
+	}
+	if req.GroupBy == "team" {
+		resp.TagKey = req.TagKey
+	}
+	for _, o := range out.Offenders {
+		offender := WasteOffender{
+			Group:    o.Group,
+			Untagged: o.Untagged,
+			Count:    o.Count,
+			Cost:     o.MonthlyCost,
+			Carbon:   o.CarbonFootprint,
+			Share:    o.Share,
+		}
+		if o.Resource != nil {
+			dto := toResourceDTO(o.Resource)
+			offender.Resource = &dto
+		}
+		resp.Offenders = append(resp.Offenders, offender)
+	}
+	return resp
+}
+
+// Allocation godoc
+//
+//	@Summary		Cost allocation
+//	@Description	Get the showback report of an organization: its monthly cost and waste per value of each cost-allocation tag (allocation_tag_keys in its settings), with the cost of the resources missing the tag. Reports are refreshed periodically during the month and kept for earlier months.
+//	@Tags			Dashboard
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			month			query		string	false	"Month (YYYY-MM), defaults to the current month"
+//	@Param			tag_key			query		string	false	"Only this cost-allocation tag"
+//	@Success		200				{object}	DataResponse[AllocationResponse]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/dashboard/allocation [get]
+func (h *DashboardHandler) Allocation(c *gin.Context) {
+	var req AllocationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	orgID, ok := dashboardOrganization(c, req.OrganizationID)
+	if !ok {
+		return
+	}
+
+	input := usecase.AllocationInput{OrganizationID: orgID, TagKey: req.TagKey}
+	if req.Month != "" {
+		month, err := time.Parse("2006-01", req.Month)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid month, expected YYYY-MM")
+			return
+		}
+		input.Month = month
+	}
+
+	out, err := h.dashboards.Allocation(c.Request.Context(), input)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	resp := AllocationResponse{
+		Month:       out.Month.Format("2006-01"),
+		ComputedAt:  out.ComputedAt,
+		Allocations: make([]TagAllocation, 0, len(out.Allocations)),
+	}
+	for _, alloc := range out.Allocations {
+		tag := TagAllocation{
+			TagKey:           alloc.TagKey,
+			TotalCost:        alloc.TotalCost,
+			TotalWaste:       alloc.TotalWaste,
+			AllocatedPercent: alloc.AllocatedPercent,
+			Values:           make([]AllocationValue, 0, len(alloc.Values)),
+			Unallocated:      toAllocationValue(alloc.Unallocated),
+		}
+		for _, v := range alloc.Values {
+			tag.Values = append(tag.Values, toAllocationValue(v))
+		}
+		resp.Allocations = append(resp.Allocations, tag)
+	}
+
+	c.JSON(http.StatusOK, dataResponse(resp))
+}
+
+// toAllocationValue converts the share of a tag value to its DTO
+func toAllocationValue(share usecase.AllocationShare) AllocationValue {
+	return AllocationValue{
+		Value:         share.TagValue,
+		ResourceCount: share.ResourceCount,
+		MonthlyCost:   share.MonthlyCost,
+		Waste:         share.Waste,
+		Share:         share.Share,
+	}
+}
+
+// Score godoc
+//
+//	@Summary		Cloud hygiene score
+//	@Description	Get the cloud hygiene score of an organization, from 0 to 100, combining the waste ratio, tag compliance, snapshot age and recommendation backlog, with its factor breakdown and its daily history
+//	@Tags			Dashboard
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			days			query		int		false	"Days of history"	default(30)	minimum(1)	maximum(365)
+//	@Success		200				{object}	DataResponse[ScoreResponse]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/dashboard/score [get]
+func (h *DashboardHandler) Score(c *gin.Context) {
+	var req ScoreRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, ok := dashboardOrganization(c, req.OrganizationID)
+	if !ok {
+		return
+	}
+
+	out, err := h.dashboards.Score(c.Request.Context(), orgID, req.Days)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	resp := ScoreResponse{
+		Score:   out.Score,
+		Factors: out.Factors,
+		Change:  out.Change,
+		History: make([]ScorePoint, 0, len(out.History)),
+	}
+	for _, p := range out.History {
+		resp.History = append(resp.History, ScorePoint{
+			Date:    p.Day.Format("2006-01-02"),
+			Score:   p.Score,
+			Factors: p.Factors,
+		})
+	}
+
+	c.JSON(http.StatusOK, dataResponse(resp))
+}
+
+// Ticker godoc
+//
+//	@Summary		Waste ticker
+//	@Description	Get the current waste burn rate: dollars and CO2e per hour wasted by the resources currently flagged unused, with the amounts wasted since the start of the month at that rate. Clients can extrapolate from as_of with the per-second rates for a live counter.
+//	@Tags			Dashboard
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			provider		query		string	false	"Filter by cloud provider"
+//	@Param			account_id		query		string	false	"Filter by provider account, subscription, project or cluster"
+//	@Success		200				{object}	DataResponse[TickerResponse]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/dashboard/ticker [get]
+func (h *DashboardHandler) Ticker(c *gin.Context) {
+	var req TickerRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, ok := dashboardOrganization(c, req.OrganizationID)
+	if !ok {
+		return
+	}
+	scope := repository.DashboardScope{OrganizationID: orgID}
+	if req.Provider != "" {
+		provider := entity.CloudProvider(req.Provider)
+		scope.Provider = &provider
+	}
+	if req.AccountID != "" {
+		scope.AccountID = &req.AccountID
+	}
+
+	out, err := h.dashboards.Ticker(c.Request.Context(), scope)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	resp := TickerResponse{
+		UnusedResources:    out.UnusedResources,
+		CostPerHour:        out.CostPerHour,
+		CostPerSecond:      out.CostPerHour / 3600,
+		CarbonPerHour:      out.CarbonPerHour,
+		CarbonPerSecond:    out.CarbonPerHour / 3600,
+		WastedThisMonth:    out.WastedThisMonth,
+		EmittedThisMonthKg: out.EmittedThisMonth,
+		AsOf:               out.AsOf,
+	}
+
+	c.JSON(http.StatusOK, dataResponse(resp))
+}
+
+// bindDashboardScope reads the scope of a dashboard request. It writes the
+// error response and returns false when the request is invalid.
+func bindDashboardScope(c *gin.Context) (repository.DashboardScope, bool) {
+	var req DashboardRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return repository.DashboardScope{}, false
+	}
+	return req.scope(c)
+}
+
+// scope returns the scope of the request. It writes the error response and
+// returns false when the request is invalid.
+func (req DashboardRequest) scope(c *gin.Context) (repository.DashboardScope, bool) {
+	orgID, ok := dashboardOrganization(c, req.OrganizationID)
+	if !ok {
+		return repository.DashboardScope{}, false
+	}
+
+	scope := repository.DashboardScope{OrganizationID: orgID}
+	if req.Provider != "" {
+		provider := entity.CloudProvider(req.Provider)
+		scope.Provider = &provider
+	}
+	if req.AccountID != "" {
+		scope.AccountID = &req.AccountID
+	}
+	var err error
+	if req.From != "" {
+		if scope.From, _, err = parseDashboardTime(req.From); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid from: "+err.Error())
+			return repository.DashboardScope{}, false
+		}
+	}
+	if req.To != "" {
+		var date bool
+		if scope.To, date, err = parseDashboardTime(req.To); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid to: "+err.Error())
+			return repository.DashboardScope{}, false
+		}
+		if date {
+			scope.To = scope.To.AddDate(0, 0, 1)
+		}
+	}
+	if !scope.From.IsZero() && !scope.To.IsZero() && !scope.From.Before(scope.To) {
+		apierror.Respond(c, http.StatusBadRequest, "from must be before to")
+		return repository.DashboardScope{}, false
+	}
+	return scope, true
+}
+
+// dashboardOrganization returns the organization of a dashboard request:
+// the one of the session when signed in, or the organization_id parameter.
+// It writes the error response and returns false when there is none, or when
+// the parameter names another organization than the session.
+func dashboardOrganization(c *gin.Context, param string) (uuid.UUID, bool) {
+	if sess, ok := middleware.CurrentSession(c); ok {
+		orgID, err := uuid.Parse(sess.OrganizationID)
+		if err != nil {
+			apierror.Respond(c, http.StatusForbidden, "invalid session")
+			return uuid.Nil, false
+		}
+		if param != "" && param != orgID.String() {
+			apierror.Respond(c, http.StatusForbidden, "session is not signed in to this organization")
+			return uuid.Nil, false
+		}
+		return orgID, true
+	}
+
+	if param == "" {
+		apierror.Respond(c, http.StatusBadRequest, "organization_id is required")
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(param)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// parseDashboardTime parses a date or an RFC 3339 time, reporting whether it
+// was a date
+func parseDashboardTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, errors.New("expected a date (2006-01-02) or an RFC 3339 time")
+	}
+	return t, false, nil
 }
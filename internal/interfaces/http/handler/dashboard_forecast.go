@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// ForecastRequest represents query parameters for the savings forecast
+type ForecastRequest struct {
+	// OrganizationID is required unless signed in
+	OrganizationID string `form:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// ForecastResponse represents the projected spend and waste of an
+// organization
+type ForecastResponse struct {
+	service.Forecast
+	MonthlyCost  float64 `json:"monthly_cost" example:"15000.00"`
+	MonthlyWaste float64 `json:"monthly_waste" example:"2500.00"`
+	// ScheduledPolicies are the scheduled policies with savings, soonest
+	// first
+	ScheduledPolicies     []service.ScheduledSaving `json:"scheduled_policies"`
+	Recommendations       int                       `json:"open_recommendations" example:"12"`
+	RecommendationSavings float64                   `json:"recommendation_monthly_savings" example:"840.00"`
+	AsOf                  time.Time                 `json:"as_of"`
+}
+
+// Forecast godoc
+//
+//	@Summary		Savings forecast
+//	@Description	Project the monthly spend and waste of an organization over the next 12 months, with their totals over 3, 6 and 12 months, from the trend of the daily cost snapshots of the last 90 days. Each figure comes in three scenarios: the trend alone, with the scheduled policies stopping, quarantining, releasing or deleting the resources they match today from their next run on, and with all the open recommendations applied on top of them. Without a week of snapshots the current spend is projected as it is.
+//	@Tags			Dashboard
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Success		200				{object}	DataResponse[ForecastResponse]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/dashboard/forecast [get]
+func (h *DashboardHandler) Forecast(c *gin.Context) {
+	var req ForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	orgID, ok := dashboardOrganization(c, req.OrganizationID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := cache.Fetch(ctx, h.cache, orgID.String(), "dashboard.forecast", "", h.ttl, func() (ForecastResponse, error) {
+		out, err := h.dashboards.Forecast(ctx, orgID)
+		if err != nil {
+			return ForecastResponse{}, err
+		}
+		return ForecastResponse{
+			Forecast:              out.Forecast,
+			MonthlyCost:           out.MonthlyCost,
+			MonthlyWaste:          out.MonthlyWaste,
+			ScheduledPolicies:     out.ScheduledPolicies,
+			Recommendations:       out.Recommendations,
+			RecommendationSavings: out.RecommendationSavings,
+			AsOf:                  out.AsOf,
+		}, nil
+	})
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(resp))
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+)
+
+// fakeDashboards is a DashboardService whose methods are set by each test
+type fakeDashboards struct {
+	usecase.DashboardService
+	summary      func(repository.DashboardScope) (*repository.InventoryTotals, error)
+	savings      func(repository.DashboardScope) (*usecase.DashboardSavings, error)
+	topOffenders func(usecase.TopOffendersInput) (*usecase.TopOffendersOutput, error)
+	allocation   func(usecase.AllocationInput) (*usecase.AllocationOutput, error)
+	score        func(uuid.UUID, int) (*usecase.ScoreOutput, error)
+	ticker       func(repository.DashboardScope) (*usecase.TickerOutput, error)
+	forecast     func(uuid.UUID) (*usecase.ForecastOutput, error)
+}
+
+func (f *fakeDashboards) Summary(ctx context.Context, scope repository.DashboardScope) (*repository.InventoryTotals, error) {
+	return f.summary(scope)
+}
+
+func (f *fakeDashboards) Savings(ctx context.Context, scope repository.DashboardScope) (*usecase.DashboardSavings, error) {
+	return f.savings(scope)
+}
+
+func (f *fakeDashboards) TopOffenders(ctx context.Context, input usecase.TopOffendersInput) (*usecase.TopOffendersOutput, error) {
+	return f.topOffenders(input)
+}
+
+func (f *fakeDashboards) Allocation(ctx context.Context, input usecase.AllocationInput) (*usecase.AllocationOutput, error) {
+	return f.allocation(input)
+}
+
+func (f *fakeDashboards) Score(ctx context.Context, orgID uuid.UUID, days int) (*usecase.ScoreOutput, error) {
+	return f.score(orgID, days)
+}
+
+func (f *fakeDashboards) Ticker(ctx context.Context, scope repository.DashboardScope) (*usecase.TickerOutput, error) {
+	return f.ticker(scope)
+}
+
+func (f *fakeDashboards) Forecast(ctx context.Context, orgID uuid.UUID) (*usecase.ForecastOutput, error) {
+	return f.forecast(orgID)
+}
+
+func TestDashboardHandlerSummary(t *testing.T) {
+	var got repository.DashboardScope
+	h := NewDashboardHandler(&fakeDashboards{summary: func(scope repository.DashboardScope) (*repository.InventoryTotals, error) {
+		got = scope
+		return &repository.InventoryTotals{Resources: 500, Unused: 75, MonthlyCost: 15000, WasteCost: 2500, CarbonFootprint: 1200.5, WasteCarbon: 180.25}, nil
+	}}, nil, 0)
+
+	target := "/dashboard/summary?organization_id=" + goldenOrgID.String() + "&provider=aws&account_id=123&from=2024-05-01&to=2024-05-31"
+	w := serve(http.MethodGet, "/dashboard/summary", target, "", h.Summary)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.OrganizationID != goldenOrgID || got.Provider == nil || *got.Provider != entity.CloudProviderAWS ||
+		got.AccountID == nil || *got.AccountID != "123" {
+		t.Errorf("scope = %+v", got)
+	}
+	if want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC); !got.From.Equal(want) {
+		t.Errorf("from = %s, want %s", got.From, want)
+	}
+	if want := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC); !got.To.Equal(want) {
+		t.Errorf("to = %s, want the day after the date %s", got.To, want)
+	}
+	resp := decode[DataResponse[SummaryStats]](t, w).Data
+	if resp.TotalResources != 500 || resp.UnusedResources != 75 || resp.PotentialSavings != 2500 || resp.CarbonSavings != 180.25 {
+		t.Errorf("summary = %+v", resp)
+	}
+
+	for name, query := range map[string]string{
+		"no organization":  "",
+		"invalid from":     "?organization_id=" + goldenOrgID.String() + "&from=may",
+		"from after to":    "?organization_id=" + goldenOrgID.String() + "&from=2024-06-01&to=2024-05-01",
+		"invalid provider": "?organization_id=" + goldenOrgID.String() + "&provider=ovh",
+	} {
+		if w := serve(http.MethodGet, "/dashboard/summary", "/dashboard/summary"+query, "", h.Summary); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400: %s", name, w.Code, w.Body)
+		}
+	}
+}
+
+func TestDashboardHandlerSavings(t *testing.T) {
+	h := NewDashboardHandler(&fakeDashboards{savings: func(repository.DashboardScope) (*usecase.DashboardSavings, error) {
+		return &usecase.DashboardSavings{
+			ByProvider: []repository.WasteTotal{{Group: "aws", Count: 25, MonthlyCost: 1500}},
+			ByType:     []repository.WasteTotal{{Group: "ebs_volume", Count: 10, MonthlyCost: 800}},
+		}, nil
+	}}, nil, 0)
+
+	w := serve(http.MethodGet, "/dashboard/savings", "/dashboard/savings?organization_id="+goldenOrgID.String(), "", h.Savings)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	resp := decode[SavingsResponse](t, w)
+	if len(resp.ByProvider) != 1 || resp.ByProvider[0] != (ProviderSavings{Provider: "aws", Cost: 1500, Count: 25}) {
+		t.Errorf("by provider = %+v", resp.ByProvider)
+	}
+	if len(resp.ByResourceType) != 1 || resp.ByResourceType[0] != (TypeSavings{Type: "ebs_volume", Cost: 800, Count: 10}) {
+		t.Errorf("by type = %+v", resp.ByResourceType)
+	}
+}
+
+func TestDashboardHandlerTopOffenders(t *testing.T) {
+	resource := costlyVolumes(1)[0]
+	var got usecase.TopOffendersInput
+	h := NewDashboardHandler(&fakeDashboards{topOffenders: func(input usecase.TopOffendersInput) (*usecase.TopOffendersOutput, error) {
+		got = input
+		if input.GroupBy == "" {
+			return &usecase.TopOffendersOutput{TotalWaste: 400, Offenders: []usecase.WasteOffender{{
+				WasteTotal: repository.WasteTotal{Group: resource.ID.String(), Count: 1, MonthlyCost: 100},
+				Share:      25,
+				Resource:   resource,
+			}}}, nil
+		}
+		return &usecase.TopOffendersOutput{TotalWaste: 400, Offenders: []usecase.WasteOffender{
+			{WasteTotal: repository.WasteTotal{Group: "platform", Count: 3, MonthlyCost: 300}, Share: 75},
+			{WasteTotal: repository.WasteTotal{Untagged: true, Count: 1, MonthlyCost: 100}, Share: 25},
+		}}, nil
+	}}, nil, 0)
+
+	target := "/dashboard/top-offenders?organization_id=" + goldenOrgID.String() + "&group_by=team&tag_key=squad&limit=5"
+	w := serve(http.MethodGet, "/dashboard/top-offenders", target, "", h.TopOffenders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.GroupBy != repository.WasteByTag || got.TagKey != "squad" || got.Limit != 5 {
+		t.Errorf("input = %+v", got)
+	}
+	resp := decode[DataResponse[TopOffendersResponse]](t, w).Data
+	if resp.GroupBy != "team" || resp.TagKey != "squad" || resp.TotalWaste != 400 || len(resp.Offenders) != 2 ||
+		resp.Offenders[0].Group != "platform" || resp.Offenders[0].Share != 75 || !resp.Offenders[1].Untagged {
+		t.Errorf("response = %+v", resp)
+	}
+
+	w = serve(http.MethodGet, "/dashboard/top-offenders", "/dashboard/top-offenders?organization_id="+goldenOrgID.String(), "", h.TopOffenders)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.GroupBy != "" || got.Limit != 10 {
+		t.Errorf("default input = %+v, want single resources", got)
+	}
+	resp = decode[DataResponse[TopOffendersResponse]](t, w).Data
+	if resp.TagKey != "" || len(resp.Offenders) != 1 || resp.Offenders[0].Resource == nil || resp.Offenders[0].Resource.ID != resource.ID.String() {
+		t.Errorf("response = %+v, want the resource", resp)
+	}
+}
+
+func TestDashboardHandlerAllocation(t *testing.T) {
+	computed := goldenUpdated
+	var got usecase.AllocationInput
+	h := NewDashboardHandler(&fakeDashboards{allocation: func(input usecase.AllocationInput) (*usecase.AllocationOutput, error) {
+		got = input
+		if input.TagKey == "missing" {
+			return nil, apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "organization not found")
+		}
+		return &usecase.AllocationOutput{
+			Month:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			ComputedAt: &computed,
+			Allocations: []usecase.TagAllocation{{
+				TagKey:           "team",
+				TotalCost:        1000,
+				AllocatedPercent: 80,
+				Values: []usecase.AllocationShare{{
+					CostAllocation: entity.CostAllocation{TagKey: "team", TagValue: "data", ResourceCount: 4, MonthlyCost: 800},
+					Share:          80,
+				}},
+				Unallocated: usecase.AllocationShare{CostAllocation: entity.CostAllocation{TagKey: "team", ResourceCount: 1, MonthlyCost: 200}, Share: 20},
+			}},
+		}, nil
+	}}, nil, 0)
+
+	target := "/dashboard/allocation?organization_id=" + goldenOrgID.String() + "&month=2024-05"
+	w := serve(http.MethodGet, "/dashboard/allocation", target, "", h.Allocation)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.OrganizationID != goldenOrgID || got.Month.Format("2006-01") != "2024-05" {
+		t.Errorf("input = %+v", got)
+	}
+	resp := decode[DataResponse[AllocationResponse]](t, w).Data
+	if resp.Month != "2024-05" || resp.ComputedAt == nil || len(resp.Allocations) != 1 {
+		t.Fatalf("response = %+v", resp)
+	}
+	alloc := resp.Allocations[0]
+	if alloc.AllocatedPercent != 80 || len(alloc.Values) != 1 || alloc.Values[0].Value != "data" || alloc.Values[0].Share != 80 ||
+		alloc.Unallocated.MonthlyCost != 200 || alloc.Unallocated.Value != "" {
+		t.Errorf("allocation = %+v", alloc)
+	}
+
+	if w := serve(http.MethodGet, "/dashboard/allocation", target+"&tag_key=missing", "", h.Allocation); w.Code != http.StatusNotFound {
+		t.Errorf("missing organization status = %d, want 404: %s", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/dashboard/allocation", "/dashboard/allocation?organization_id="+goldenOrgID.String()+"&month=05-2024", "", h.Allocation); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid month status = %d, want 400: %s", w.Code, w.Body)
+	}
+}
+
+func TestDashboardHandlerScore(t *testing.T) {
+	change := 2.5
+	h := NewDashboardHandler(&fakeDashboards{score: func(orgID uuid.UUID, days int) (*usecase.ScoreOutput, error) {
+		if orgID != goldenOrgID || days != 7 {
+			t.Errorf("score of %s over %d days", orgID, days)
+		}
+		return &usecase.ScoreOutput{
+			HygieneScore: service.HygieneScore{Score: 74.5, Factors: []service.HygieneFactor{{Name: service.HygieneFactorWaste, Weight: 0.4, Score: 80}}},
+			Change:       &change,
+			History:      []service.HygieneRecord{{Day: goldenCreated, Score: 72, Factors: map[string]float64{service.HygieneFactorWaste: 75}}},
+		}, nil
+	}}, nil, 0)
+
+	w := serve(http.MethodGet, "/dashboard/score", "/dashboard/score?days=7&organization_id="+goldenOrgID.String(), "", h.Score)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	resp := decode[DataResponse[ScoreResponse]](t, w).Data
+	if resp.Score != 74.5 || resp.Change == nil || *resp.Change != 2.5 || len(resp.Factors) != 1 {
+		t.Errorf("score = %+v", resp)
+	}
+	if len(resp.History) != 1 || resp.History[0].Date != goldenCreated.Format("2006-01-02") || resp.History[0].Factors[service.HygieneFactorWaste] != 75 {
+		t.Errorf("history = %+v", resp.History)
+	}
+}
+
+func TestDashboardHandlerTicker(t *testing.T) {
+	var got repository.DashboardScope
+	h := NewDashboardHandler(&fakeDashboards{ticker: func(scope repository.DashboardScope) (*usecase.TickerOutput, error) {
+		got = scope
+		return &usecase.TickerOutput{UnusedResources: 3, CostPerHour: 1800, CarbonPerHour: 900, WastedThisMonth: 36, AsOf: goldenUpdated}, nil
+	}}, nil, 0)
+
+	w := serve(http.MethodGet, "/dashboard/ticker", "/dashboard/ticker?provider=gcp&organization_id="+goldenOrgID.String(), "", h.Ticker)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.Provider == nil || *got.Provider != entity.CloudProviderGCP || got.AccountID != nil {
+		t.Errorf("scope = %+v", got)
+	}
+	resp := decode[DataResponse[TickerResponse]](t, w).Data
+	if resp.UnusedResources != 3 || resp.CostPerSecond != 0.5 || resp.CarbonPerSecond != 0.25 || resp.WastedThisMonth != 36 {
+		t.Errorf("ticker = %+v", resp)
+	}
+}
+
+func TestDashboardHandlerForecast(t *testing.T) {
+	h := NewDashboardHandler(&fakeDashboards{forecast: func(uuid.UUID) (*usecase.ForecastOutput, error) {
+		return nil, apperrors.NewWithCode(apperrors.ErrInternalError, apperrors.CodeInternal, "failed to fetch policies")
+	}}, nil, 0)
+
+	w := serve(http.MethodGet, "/dashboard/forecast", "/dashboard/forecast?organization_id="+goldenOrgID.String(), "", h.Forecast)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500: %s", w.Code, w.Body)
+	}
+	if resp := decode[ErrorResponse](t, w); resp.Message != "failed to fetch policies" {
+		t.Errorf("message = %q", resp.Message)
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/digest"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DigestHandler handles the actions of the links of owner digest and weekly
+// summary emails
+type DigestHandler struct {
+	db        *gorm.DB
+	signer    *digest.Signer
+	snoozeFor time.Duration
+}
+
+// NewDigestHandler creates a new DigestHandler
+func NewDigestHandler(db *gorm.DB, signingKey string, snoozeFor time.Duration) *DigestHandler {
+	return &DigestHandler{
+		db:        db,
+		signer:    digest.NewSigner(signingKey),
+		snoozeFor: snoozeFor,
+	}
+}
+
+// Confirm godoc
+//
+//	@Summary		Confirm a digest action
+//	@Description	Render the page confirming the action of a signed link sent in the owner digest email or the weekly summary email. Opening the link changes nothing, so that mail scanners and link prefetchers cannot snooze, approve or unsubscribe: the page posts the token to POST /digest/action once confirmed.
+//	@Tags			Digest
+//	@Produce		html
+//	@Param			token	query		string	true	"Signed action token"
+//	@Success		200		{string}	string	"Confirmation page"
+//	@Failure		403		{object}	ErrorResponse
+//	@Router			/digest/action [get]
+func (h *DigestHandler) Confirm(c *gin.Context) {
+	token := c.Query("token")
+	action, err := h.signer.Verify(token)
+	if err != nil {
+		apierror.Respond(c, http.StatusForbidden, err.Error())
+		return
+	}
+	page, err := digest.RenderConfirmation(action, token, h.snoozeFor)
+	if err != nil {
+		apierror.Respond(c, http.StatusForbidden, err.Error())
+		return
+	}
+	// The token is in the URL: keep it out of caches and referrers
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// Action godoc
+//
+//	@Summary		Apply a digest action
+//	@Description	Snooze a resource or approve its cleanup from a signed link sent in the owner digest email, or stop the weekly summary of an organization from a link of the summary email, once confirmed on the page of GET /digest/action
+//	@Tags			Digest
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			token	formData	string	true	"Signed action token"
+//	@Success		200		{object}	MessageResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/digest/action [post]
+func (h *DigestHandler) Action(c *gin.Context) {
+	action, err := h.signer.Verify(c.PostForm("token"))
+	if err != nil {
+		apierror.Respond(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if action.Kind == digest.ActionUnsubscribe {
+		h.unsubscribe(c, action)
+		return
+	}
+
+	var updates map[string]any
+	var message string
+	var eventType entity.ResourceEventType
+	var data map[string]any
+	switch action.Kind {
+	case digest.ActionSnooze:
+		until := time.Now().Add(h.snoozeFor)
+		updates = map[string]any{"snoozed_until": &until}
+		message = "resource snoozed until " + until.Format("2006-01-02")
+		eventType, data = entity.ResourceEventExcluded, map[string]any{"reason": "snoozed", "until": until}
+	case digest.ActionApprove:
+		now := time.Now()
+		updates = map[string]any{"cleanup_approved_at": &now, "cleanup_approved_by": action.Owner}
+		message = "cleanup approved"
+		eventType, data = entity.ResourceEventCleanupApproved, map[string]any{"channel": "digest"}
+	default:
+		apierror.Respond(c, http.StatusForbidden, digest.ErrInvalidToken.Error())
+		return
+	}
+
+	// The action is recorded in the timeline of the resource with it
+	var resource model.Resource
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&resource).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "organization_id"}}}).
+			Where("id = ?", action.ResourceID).Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return database.RecordResourceEvents(tx, entity.NewResourceEvent(resource.OrganizationID, resource.ID, eventType, action.Owner, data))
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update resource")
+		return
+	}
+	if resource.ID == uuid.Nil {
+		apierror.Respond(c, http.StatusNotFound, "resource not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: message})
+}
+
+// unsubscribe opts the member of an unsubscribe link out of the weekly
+// summary of the organization
+func (h *DigestHandler) unsubscribe(c *gin.Context, action digest.Action) {
+	result := h.db.WithContext(c.Request.Context()).Model(&model.Membership{}).
+		Where("organization_id = ? AND user_id = ?", action.OrganizationID, action.UserID).
+		Update("weekly_summary_opt_out", true)
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update membership")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "member not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "unsubscribed from the weekly summary"})
+}
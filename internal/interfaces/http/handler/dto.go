@@ -1,10 +1,35 @@
 package handler
 
-import "time"
+import (
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+)
 
 // ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error string `json:"error" example:"invalid request"`
+type ErrorResponse = apierror.Response
+
+// QuotaErrorResponse represents an error returned when a plan limit is
+// reached, with the plan to upgrade to
+type QuotaErrorResponse struct {
+	apierror.Response
+	Quota   string `json:"quota" example:"scans_per_day" enums:"cloud_accounts,scans_per_day,resources,retention_days"`
+	Plan    string `json:"plan" example:"free"`
+	Limit   int    `json:"limit" example:"5"`
+	Used    int64  `json:"used" example:"5"`
+	Upgrade string `json:"upgrade,omitempty" example:"pro"`
+}
+
+// DataResponse represents a response wrapping its result in data
+type DataResponse[T any] struct {
+	Data T `json:"data"`
+}
+
+// dataResponse wraps a result in data
+func dataResponse[T any](data T) DataResponse[T] {
+	return DataResponse[T]{Data: data}
 }
 
 // MessageResponse represents a simple message response
@@ -18,59 +43,85 @@ type PaginatedResponse struct {
 	Total  int64 `json:"total" example:"100"`
 	Limit  int   `json:"limit" example:"50"`
 	Offset int   `json:"offset" example:"0"`
+	// NextCursor fetches the next page when passed as cursor, empty on the
+	// last page. Lists that support it return it in offset mode too.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiY3JlYXRlZF9hdCIsInYiOiIyMDI0LTAxLTE1VDEwOjMwOjAwWiIsImkiOiI1NTBlODQwMCJ9"`
 }
 
 // ResourceDTO represents a cloud resource
 type ResourceDTO struct {
 	ID              string            `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	OrganizationID  string            `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
-	Provider        string            `json:"provider" example:"aws" enums:"aws,azure,gcp"`
+	Provider        string            `json:"provider" example:"aws"`
 	Type            string            `json:"type" example:"ec2_instance"`
 	ResourceID      string            `json:"resource_id" example:"i-1234567890abcdef0"`
 	Region          string            `json:"region" example:"us-east-1"`
+	AccountID       string            `json:"account_id,omitempty" example:"123456789012"`
 	Name            string            `json:"name" example:"my-instance"`
-	Status          string            `json:"status" example:"unused" enums:"active,unused,deleted,excluded"`
+	Status          string            `json:"status" example:"unused" enums:"active,unused,deleted,removed,excluded"`
 	Tags            map[string]string `json:"tags"`
 	MonthlyCost     float64           `json:"monthly_cost" example:"45.50"`
 	CarbonFootprint float64           `json:"carbon_footprint_kg" example:"12.5"`
 	LastSeenAt      time.Time         `json:"last_seen_at"`
+	SnoozedUntil    *time.Time        `json:"snoozed_until,omitempty"`
+	ApprovedAt      *time.Time        `json:"cleanup_approved_at,omitempty"`
+	ApprovedBy      string            `json:"cleanup_approved_by,omitempty"`
+	QuarantinedAt   *time.Time        `json:"quarantined_at,omitempty"`
+	QuarantineUntil *time.Time        `json:"quarantine_until,omitempty"`
+	IaCManaged      bool              `json:"iac_managed" example:"false"`
+	IaCTool         string            `json:"iac_tool,omitempty" example:"terraform"`
+	Owner           string            `json:"owner,omitempty" example:"alice@example.com"`
+	OwnerSource     string            `json:"owner_source,omitempty" example:"tag" enums:"mapping,tag,account,default"`
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 }
 
 // ScanDTO represents a scan
 type ScanDTO struct {
-	ID               string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	OrganizationID   string    `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
-	Provider         string    `json:"provider" example:"aws" enums:"aws,azure,gcp"`
-	Regions          []string  `json:"regions" example:"us-east-1,eu-west-1"`
-	ResourceTypes    []string  `json:"resource_types" example:"ec2_instance,ebs_volume"`
-	Status           string    `json:"status" example:"completed" enums:"pending,running,completed,failed,cancelled"`
-	ResourcesFound   int       `json:"resources_found" example:"150"`
-	UnusedFound      int       `json:"unused_found" example:"23"`
-	EstimatedSavings float64   `json:"estimated_savings" example:"1250.00"`
-	CarbonSavings    float64   `json:"carbon_savings_kg" example:"45.5"`
-	ErrorMessage     string    `json:"error_message,omitempty"`
-	StartedAt        *time.Time `json:"started_at,omitempty"`
-	CompletedAt      *time.Time `json:"completed_at,omitempty"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID   string         `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Provider         string         `json:"provider" example:"aws"`
+	Regions          []string       `json:"regions" example:"us-east-1,eu-west-1"`
+	ResourceTypes    []string       `json:"resource_types" example:"ec2_instance,ebs_volume"`
+	Status           string         `json:"status" example:"completed" enums:"pending,running,completed,partial,failed,cancelled"`
+	Mode             string         `json:"mode" example:"full" enums:"full,incremental"`
+	ChangesSince     *time.Time     `json:"changes_since,omitempty"`
+	ResourcesFound   int            `json:"resources_found" example:"150"`
+	UnusedFound      int            `json:"unused_found" example:"23"`
+	ResourcesNew     int            `json:"resources_new" example:"12"`
+	ResourcesChanged int            `json:"resources_changed" example:"4"`
+	ResourcesRemoved int            `json:"resources_removed" example:"3"`
+	EstimatedSavings float64        `json:"estimated_savings" example:"1250.00"`
+	CarbonSavings    float64        `json:"carbon_savings_kg" example:"45.5"`
+	ErrorMessage     string         `json:"error_message,omitempty"`
+	FailedRegions    map[string]any `json:"failed_regions,omitempty"`
+	StartedAt        *time.Time     `json:"started_at,omitempty"`
+	CompletedAt      *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	Version          int            `json:"version" example:"3"`
 }
 
 // PolicyDTO represents a cleanup policy
 type PolicyDTO struct {
-	ID             string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	OrganizationID string         `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
-	Name           string         `json:"name" example:"Delete unused EBS volumes"`
-	Description    string         `json:"description" example:"Automatically delete EBS volumes unused for 30 days"`
-	Provider       string         `json:"provider" example:"aws" enums:"aws,azure,gcp"`
-	ResourceTypes  []string       `json:"resource_types" example:"ebs_volume"`
-	Conditions     map[string]any `json:"conditions"`
-	Actions        []string       `json:"actions" example:"notify,delete" enums:"notify,tag,stop,delete"`
-	IsEnabled      bool           `json:"is_enabled" example:"true"`
-	Schedule       string         `json:"schedule" example:"0 0 * * *"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
+	ID             string           `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string           `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name           string           `json:"name" example:"Delete unused EBS volumes"`
+	Description    string           `json:"description" example:"Automatically delete EBS volumes unused for 30 days"`
+	Provider       string           `json:"provider" example:"aws"`
+	ResourceTypes  []string         `json:"resource_types" example:"ebs_volume"`
+	Conditions     map[string]any   `json:"conditions"`
+	Actions        []string         `json:"actions" example:"notify,delete" enums:"notify,tag,stop,delete,schedule_offhours,schedule,quarantine,release,ticket"`
+	IsEnabled      bool             `json:"is_enabled" example:"true"`
+	Schedule       string           `json:"schedule" example:"0 0 * * *"`
+	OffHours       *OffHoursRequest `json:"off_hours,omitempty"`
+	ViewID         *string          `json:"view_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	ExternalID     *string          `json:"external_id,omitempty" example:"ebs-unused-prod"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	// Version is incremented by every update, and returned in the ETag
+	// header
+	Version int `json:"version" example:"3"`
 }
 
 // DashboardSummaryDTO represents dashboard summary
@@ -85,9 +136,616 @@ type DashboardSummaryDTO struct {
 
 // CleanupPreviewDTO represents a cleanup preview response
 type CleanupPreviewDTO struct {
-	Resources             []ResourceDTO `json:"resources"`
-	Count                 int           `json:"count" example:"5"`
-	EstimatedMonthlySavings float64     `json:"estimated_monthly_savings" example:"250.00"`
-	EstimatedCarbonSavings  float64     `json:"estimated_carbon_savings" example:"35.5"`
-	Action                string        `json:"action" example:"delete"`
+	Resources               []ResourceDTO `json:"resources"`
+	Count                   int           `json:"count" example:"5"`
+	EstimatedMonthlySavings float64       `json:"estimated_monthly_savings" example:"250.00"`
+	EstimatedCarbonSavings  float64       `json:"estimated_carbon_savings" example:"35.5"`
+	// Backup is true in backup-then-delete mode, whose snapshots cost
+	// EstimatedBackupCost per month
+	Backup              bool    `json:"backup" example:"false"`
+	EstimatedBackupCost float64 `json:"estimated_backup_cost" example:"12.50"`
+	Action              string  `json:"action" example:"delete"`
+	// DNSReferences are the resources DNS records may still point at
+	DNSReferences []CleanupDNSReferenceDTO `json:"dns_references"`
+	// Preflight are the checks of the cleanup on each resource
+	Preflight []CleanupPreflightDTO `json:"preflight"`
+	// Verdicts count the resources by verdict
+	Verdicts map[string]int `json:"verdicts"`
+}
+
+// CleanupPreflightDTO represents the checks of a cleanup on a resource
+type CleanupPreflightDTO struct {
+	ResourceID string `json:"resource_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// Verdict is whether the resource will be cleaned up, left untouched or
+	// refused
+	Verdict  string                       `json:"verdict" example:"proceed" enums:"proceed,skip,fail"`
+	Findings []CleanupPreflightFindingDTO `json:"findings"`
+	// RequiredPermissions are the provider permissions the action calls
+	RequiredPermissions []string `json:"required_permissions,omitempty" example:"ec2:DeleteVolume"`
+	// MissingPermissions are those the credentials lack, when the cleaner
+	// could check them
+	MissingPermissions []string `json:"missing_permissions,omitempty"`
+}
+
+// CleanupPreflightFindingDTO represents a check of a cleanup that did not
+// pass. Blocking findings make the cleanup fail on the resource; others are
+// warnings.
+type CleanupPreflightFindingDTO struct {
+	Check    string `json:"check" example:"dependency" enums:"status,region,action,iac,snooze,dependency,dns,permissions"`
+	Blocking bool   `json:"blocking" example:"true"`
+	Message  string `json:"message" example:"still referenced by DNS records [www.example.com CNAME]"`
+}
+
+// CleanupDNSReferenceDTO represents the DNS records pointing at a resource
+// of a cleanup, as of its last scan
+type CleanupDNSReferenceDTO struct {
+	ResourceID string   `json:"resource_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Records    []string `json:"records" example:"www.example.com CNAME"`
+	// Checked is false when the last scan did not search the DNS zones;
+	// they are then searched before deletion, which is refused if they
+	// cannot be
+	Checked bool `json:"checked" example:"true"`
+	// Blocking is true when the action is refused while the records exist.
+	// Quarantine only warns, the records being searched again before the
+	// purge.
+	Blocking bool `json:"blocking" example:"true"`
+}
+
+// CleanupSessionDTO represents a guided cleanup session and its review
+// progress. Savings are those of the accepted resources.
+type CleanupSessionDTO struct {
+	ID                      string            `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID          string            `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Action                  string            `json:"action" example:"delete"`
+	Filters                 map[string]string `json:"filters"`
+	Status                  string            `json:"status" example:"open" enums:"open,executed,cancelled"`
+	CreatedBy               string            `json:"created_by,omitempty" example:"alice@example.com"`
+	Total                   int               `json:"total" example:"120"`
+	Pending                 int               `json:"pending" example:"80"`
+	Accepted                int               `json:"accepted" example:"35"`
+	Rejected                int               `json:"rejected" example:"5"`
+	EstimatedMonthlySavings float64           `json:"estimated_monthly_savings" example:"1250.00"`
+	EstimatedCarbonSavings  float64           `json:"estimated_carbon_savings" example:"85.5"`
+	TaskID                  string            `json:"task_id,omitempty" example:"task_12345"`
+	DryRun                  bool              `json:"dry_run" example:"false"`
+	ExecutedAt              *time.Time        `json:"executed_at,omitempty"`
+	CreatedAt               time.Time         `json:"created_at"`
+	UpdatedAt               time.Time         `json:"updated_at"`
+}
+
+// RecommendationDTO represents a saving opportunity. Resource is omitted for
+// commitments, which apply to an organization's steady spend of a provider.
+type RecommendationDTO struct {
+	ID             string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string         `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider       string         `json:"provider" example:"aws"`
+	Type           string         `json:"type" example:"rightsize" enums:"rightsize,gp3_migration,commitment"`
+	Resource       *ResourceDTO   `json:"resource,omitempty"`
+	Current        string         `json:"current" example:"m5.2xlarge"`
+	Recommended    string         `json:"recommended" example:"m5.xlarge"`
+	MonthlySavings float64        `json:"estimated_monthly_savings" example:"140.16"`
+	Details        map[string]any `json:"details,omitempty"`
+	Status         string         `json:"status" example:"open" enums:"open,dismissed"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// CleanupSessionItemDTO represents a resource of a cleanup session with its
+// review decision
+type CleanupSessionItemDTO struct {
+	Resource  ResourceDTO `json:"resource"`
+	Decision  string      `json:"decision" example:"accepted" enums:"pending,accepted,rejected"`
+	DecidedBy string      `json:"decided_by,omitempty" example:"alice@example.com"`
+	DecidedAt *time.Time  `json:"decided_at,omitempty"`
+}
+
+// CleanupJobItemDTO represents the result of a cleanup job for one of its
+// resources, and its rollback
+type CleanupJobItemDTO struct {
+	ResourceID  string    `json:"resource_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Action      string    `json:"action" example:"delete"`
+	Success     bool      `json:"success" example:"true"`
+	Error       string    `json:"error,omitempty"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	// Recovery tells how to undo the action, for those that succeeded: its
+	// kind, whether it is automated, the snapshot, instance or bucket
+	// versioning recorded, the end of its undo window and instructions
+	Recovery      map[string]any `json:"recovery,omitempty"`
+	RolledBack    bool           `json:"rolled_back" example:"false"`
+	RollbackAt    *time.Time     `json:"rollback_at,omitempty"`
+	RollbackError string         `json:"rollback_error,omitempty"`
+}
+
+// PolicySimulationDTO represents the resources a policy would act on now
+type PolicySimulationDTO struct {
+	PolicyID                string                 `json:"policy_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Evaluated               int                    `json:"evaluated" example:"120"`
+	Count                   int                    `json:"count" example:"5"`
+	EstimatedMonthlySavings float64                `json:"estimated_monthly_savings" example:"250.00"`
+	EstimatedCarbonSavings  float64                `json:"estimated_carbon_savings" example:"35.5"`
+	Actions                 []string               `json:"actions" example:"notify,delete"`
+	ConditionMatches        map[string]int         `json:"condition_matches"`
+	Resources               []SimulatedResourceDTO `json:"resources"`
+}
+
+// SimulatedResourceDTO represents a resource matched by a policy simulation
+type SimulatedResourceDTO struct {
+	ResourceDTO
+	Conditions []service.ConditionResult `json:"conditions"`
+}
+
+// TaskFailureDTO represents a failed background task
+type TaskFailureDTO struct {
+	ID           string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID       string         `json:"task_id" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	TaskType     string         `json:"task_type" example:"scan:resources"`
+	Queue        string         `json:"queue" example:"default"`
+	Payload      map[string]any `json:"payload"`
+	Error        string         `json:"error" example:"failed to create scanner: invalid credentials"`
+	Attempts     int            `json:"attempts" example:"4"`
+	MaxRetry     int            `json:"max_retry" example:"3"`
+	Status       string         `json:"status" example:"dead" enums:"retrying,dead,requeued"`
+	LastFailedAt time.Time      `json:"last_failed_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// ExportDTO represents an export job
+type ExportDTO struct {
+	ID                   string            `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID       string            `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type                 string            `json:"type" example:"resources" enums:"resources,scans"`
+	Format               string            `json:"format" example:"csv" enums:"csv,json"`
+	Filters              map[string]string `json:"filters"`
+	Status               string            `json:"status" example:"completed" enums:"pending,running,completed,failed"`
+	RowCount             int               `json:"row_count" example:"12500"`
+	SizeBytes            int64             `json:"size_bytes" example:"2048576"`
+	ErrorMessage         string            `json:"error_message,omitempty"`
+	DownloadURL          string            `json:"download_url,omitempty"`
+	DownloadURLExpiresAt *time.Time        `json:"download_expires_at,omitempty"`
+	StartedAt            *time.Time        `json:"started_at,omitempty"`
+	CompletedAt          *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt            time.Time         `json:"created_at"`
+}
+
+// JobDTO represents a long-running operation of an organization
+type JobDTO struct {
+	ID             string `json:"id" example:"550e8400-e29b-41d4-a716-446655440004"`
+	OrganizationID string `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type           string `json:"type" example:"scan" enums:"scan,cleanup,export"`
+	Status         string `json:"status" example:"running" enums:"pending,running,completed,failed"`
+	Finished       bool   `json:"finished" example:"false"`
+	Progress       int    `json:"progress" example:"40"` // percent
+	TaskID         string `json:"task_id" example:"550e8400-e29b-41d4-a716-446655440004"`
+	TargetID       string `json:"target_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// ResultURL is the API path of what the job produced: the scan, the
+	// export, or the provider calls of the cleanup
+	ResultURL   string     `json:"result_url" example:"/api/v1/scans/550e8400-e29b-41d4-a716-446655440001"`
+	Error       string     `json:"error,omitempty"`
+	Attempts    int        `json:"attempts" example:"1"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ResourceEventDTO represents an event of the timeline of a resource. data
+// depends on the type: from and to for changes of status and cost, action,
+// success and error for cleanup attempts, until for exclusions.
+type ResourceEventDTO struct {
+	ID         string `json:"id" example:"550e8400-e29b-41d4-a716-446655440005"`
+	Type       string `json:"type" example:"status_changed" enums:"discovered,scanned,status_changed,cost_changed,policy_matched,cleanup_attempted,cleanup_rolled_back,cleanup_approved,excluded"`
+	Actor      string `json:"actor" example:"scan"`
+	ScanID     string `json:"scan_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+	PolicyID   string `json:"policy_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	PolicyName string `json:"policy_name,omitempty" example:"Unattached volumes"`
+	// TaskID is the cleanup task of attempts and rollbacks
+	TaskID     string         `json:"task_id,omitempty" example:"0f4c1d3e-8a2b-4c5d-9e6f-7a8b9c0d1e2f"`
+	Data       map[string]any `json:"data,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+// QueueStatsDTO represents task counts of a queue
+type QueueStatsDTO struct {
+	Queue          string  `json:"queue" example:"default"`
+	Size           int     `json:"size" example:"42"`
+	Pending        int     `json:"pending" example:"30"`
+	Active         int     `json:"active" example:"5"`
+	Scheduled      int     `json:"scheduled" example:"2"`
+	Retry          int     `json:"retry" example:"3"`
+	Archived       int     `json:"archived" example:"2"`
+	Completed      int     `json:"completed" example:"0"`
+	ProcessedToday int     `json:"processed_today" example:"1250"`
+	FailedToday    int     `json:"failed_today" example:"12"`
+	LatencySeconds float64 `json:"latency_seconds" example:"1.5"`
+	MemoryBytes    int64   `json:"memory_bytes" example:"1048576"`
+	Paused         bool    `json:"paused" example:"false"`
+}
+
+// QueueTaskDTO represents a task held in a queue
+type QueueTaskDTO struct {
+	ID            string         `json:"id" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	Queue         string         `json:"queue" example:"default"`
+	Type          string         `json:"type" example:"scan:resources"`
+	State         string         `json:"state" example:"pending"`
+	Payload       map[string]any `json:"payload"`
+	MaxRetry      int            `json:"max_retry" example:"5"`
+	Retried       int            `json:"retried" example:"0"`
+	LastErr       string         `json:"last_error,omitempty"`
+	LastFailedAt  *time.Time     `json:"last_failed_at,omitempty"`
+	NextProcessAt *time.Time     `json:"next_process_at,omitempty"`
+}
+
+// WorkerDTO represents a running worker process
+type WorkerDTO struct {
+	ID            string          `json:"id" example:"worker-7d9f:1:1760601600"`
+	Hostname      string          `json:"hostname" example:"worker-7d9f"`
+	PID           int             `json:"pid" example:"1"`
+	Version       string          `json:"version" example:"1.4.0"`
+	StartedAt     time.Time       `json:"started_at"`
+	LastHeartbeat time.Time       `json:"last_heartbeat"`
+	Concurrency   int             `json:"concurrency" example:"10"`
+	Active        int             `json:"active" example:"2"`
+	Tasks         []WorkerTaskDTO `json:"tasks"`
+	Processed     int64           `json:"processed" example:"1250"`
+	Failed        int64           `json:"failed" example:"3"`
+	// ScanQueueWait is how long the scans this worker started waited in
+	// queue, by organization ID
+	ScanQueueWait map[string]ScanQueueWaitDTO `json:"scan_queue_wait"`
+}
+
+// ScanQueueWaitDTO represents how long the scans of an organization waited
+// between being queued and starting
+type ScanQueueWaitDTO struct {
+	Count      int64 `json:"count" example:"12"`
+	AvgMillis  int64 `json:"avg_ms" example:"4200"`
+	MaxMillis  int64 `json:"max_ms" example:"30000"`
+	LastMillis int64 `json:"last_ms" example:"1500"`
+}
+
+// WorkerTaskDTO represents a task a worker is processing
+type WorkerTaskDTO struct {
+	ID        string    `json:"id" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	Type      string    `json:"type" example:"scan:resources"`
+	Queue     string    `json:"queue" example:"critical"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ProviderCallDTO represents a provider-mutating API call made by a cleanup
+type ProviderCallDTO struct {
+	ID             string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string    `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	TaskID         string    `json:"task_id" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	ResourceID     string    `json:"resource_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	Provider       string    `json:"provider" example:"aws"`
+	API            string    `json:"api" example:"ec2:DeleteVolume"`
+	ParamsHash     string    `json:"params_hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Status         string    `json:"status" example:"ok"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms" example:"184"`
+	CalledAt       time.Time `json:"called_at"`
+}
+
+// IaCChangeDTO represents a pull request removing a Terraform-managed
+// resource from its configuration
+type IaCChangeDTO struct {
+	ID         string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ResourceID string     `json:"resource_id" example:"550e8400-e29b-41d4-a716-446655440002"`
+	TaskID     string     `json:"task_id,omitempty" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	Address    string     `json:"address" example:"aws_instance.web"`
+	Provider   string     `json:"provider" example:"github" enums:"github,gitlab"`
+	Repo       string     `json:"repo" example:"acme/infra"`
+	FilePath   string     `json:"file_path" example:"prod/main.tf"`
+	Number     int        `json:"number" example:"42"`
+	URL        string     `json:"url" example:"https://github.com/acme/infra/pull/42"`
+	State      string     `json:"state" example:"open" enums:"open,closed,merged"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TicketDTO represents a Jira issue or ServiceNow change request opened
+// for a resource by a policy
+type TicketDTO struct {
+	ID             string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ResourceID     string     `json:"resource_id" example:"550e8400-e29b-41d4-a716-446655440002"`
+	TaskID         string     `json:"task_id,omitempty" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	System         string     `json:"system" example:"jira" enums:"jira,servicenow"`
+	Key            string     `json:"key" example:"OPS-123"`
+	URL            string     `json:"url" example:"https://acme.atlassian.net/browse/OPS-123"`
+	State          string     `json:"state" example:"open" enums:"open,closed"`
+	ResourceStatus string     `json:"resource_status" example:"unused"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TicketIntegrationDTO represents the ticket tracker of an organization.
+// The API token is never returned.
+type TicketIntegrationDTO struct {
+	OrganizationID  string    `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	System          string    `json:"system" example:"jira" enums:"jira,servicenow"`
+	BaseURL         string    `json:"base_url" example:"https://acme.atlassian.net"`
+	Username        string    `json:"username" example:"cloudsweep@acme.com"`
+	APITokenSet     bool      `json:"api_token_set" example:"true"`
+	Project         string    `json:"project,omitempty" example:"OPS"`
+	IssueType       string    `json:"issue_type,omitempty" example:"Task"`
+	DoneTransition  string    `json:"done_transition,omitempty" example:"Done"`
+	AssignmentGroup string    `json:"assignment_group,omitempty" example:"Cloud Operations"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// OwnerMappingDTO represents a row of the owner mapping file of an
+// organization
+type OwnerMappingDTO struct {
+	Position int    `json:"position" example:"1"`
+	Pattern  string `json:"pattern" example:"i-0abc*"`
+	Owner    string `json:"owner" example:"jane@acme.com"`
+}
+
+// OrganizationDTO represents an organization
+type OrganizationDTO struct {
+	ID            string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name          string     `json:"name" example:"Acme"`
+	Slug          string     `json:"slug" example:"acme"`
+	Plan          string     `json:"plan" example:"pro" enums:"free,pro,enterprise"`
+	IsActive      bool       `json:"is_active" example:"true"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// UserDTO represents a user
+type UserDTO struct {
+	ID    string `json:"id" example:"550e8400-e29b-41d4-a716-446655440003"`
+	Email string `json:"email" example:"jane@acme.com"`
+	Name  string `json:"name,omitempty" example:"Jane Doe"`
+}
+
+// InvitationDTO represents an invitation to join an organization
+type InvitationDTO struct {
+	ID         string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440004"`
+	Email      string     `json:"email" example:"john@acme.com"`
+	Role       string     `json:"role" example:"member" enums:"admin,member,viewer"`
+	InvitedBy  string     `json:"invited_by,omitempty" example:"jane@acme.com"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// MemberDTO represents a member of an organization
+type MemberDTO struct {
+	OrganizationID string    `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	User           UserDTO   `json:"user"`
+	Role           string    `json:"role" example:"admin" enums:"admin,member,viewer"`
+	WeeklySummary  bool      `json:"weekly_summary" example:"true"` // admins only receive it
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// SSOConnectionDTO represents the OIDC identity provider of an organization
+type SSOConnectionDTO struct {
+	OrganizationID  string            `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Issuer          string            `json:"issuer" example:"https://acme.okta.com"`
+	ClientID        string            `json:"client_id" example:"0oa1b2c3d4e5f6g7h8i9"`
+	ClientSecretSet bool              `json:"client_secret_set" example:"true"`
+	GroupsClaim     string            `json:"groups_claim" example:"groups"`
+	GroupRoles      map[string]string `json:"group_roles"`
+	DefaultRole     string            `json:"default_role,omitempty" example:"viewer"`
+	AllowedDomains  []string          `json:"allowed_domains" example:"acme.com"`
+	Enabled         bool              `json:"enabled" example:"true"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// SlackInstallationDTO represents the Slack workspace an organization
+// installed the app in. The bot token is never returned.
+type SlackInstallationDTO struct {
+	OrganizationID string    `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TeamID         string    `json:"team_id" example:"T0123ABCD"`
+	TeamName       string    `json:"team_name" example:"Acme"`
+	ChannelID      string    `json:"channel_id,omitempty" example:"C0123ABCD"`
+	ChannelName    string    `json:"channel_name,omitempty" example:"#cloud-costs"`
+	InstalledBy    string    `json:"installed_by,omitempty" example:"U0123ABCD"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SessionDTO represents a user signed in with single sign-on
+type SessionDTO struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Member    MemberDTO `json:"member"`
+}
+
+// UsageDTO represents the consumption of an organization against the limits
+// of its plan
+type UsageDTO struct {
+	OrganizationID string          `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Plan           string          `json:"plan" example:"free"`
+	Upgrade        string          `json:"upgrade,omitempty" example:"pro"`
+	Quotas         []QuotaUsageDTO `json:"quotas"`
+	ScansResetAt   time.Time       `json:"scans_reset_at"`
+}
+
+// QuotaUsageDTO represents the consumption of one limit. Limit is 0 when
+// unlimited.
+type QuotaUsageDTO struct {
+	Quota     string `json:"quota" example:"resources" enums:"cloud_accounts,scans_per_day,resources,retention_days"`
+	Used      int64  `json:"used" example:"320"`
+	Limit     int    `json:"limit" example:"500"`
+	Exceeded  bool   `json:"exceeded" example:"false"`
+	Unlimited bool   `json:"unlimited" example:"false"`
+}
+
+// OnboardingDTO represents an organization created with its first admin
+type OnboardingDTO struct {
+	Organization OrganizationDTO `json:"organization"`
+	Admin        UserDTO         `json:"admin"`
+	Invitations  []InvitationDTO `json:"invitations"`
+}
+
+// OrganizationSettingsDTO represents organization-wide settings
+type OrganizationSettingsDTO struct {
+	OrganizationID     string            `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	DefaultRegions     []string          `json:"default_regions" example:"eu-west-1,eu-central-1"`
+	RegionDenylist     []string          `json:"region_denylist" example:"cn-*,us-gov-*"`
+	OwnerTagKeys       []string          `json:"owner_tag_keys" example:"owner,team"`
+	OwnerAliases       map[string]string `json:"owner_aliases,omitempty"`
+	DefaultOwner       string            `json:"default_owner,omitempty" example:"finops@example.com"`
+	AuditRetentionDays int               `json:"audit_retention_days" example:"365"`
+	// HistoryRetentionDays is the retention in effect, zero when the
+	// history is kept forever
+	HistoryRetentionDays int                      `json:"history_retention_days" example:"90"`
+	TerraformStates      []string                 `json:"terraform_states" example:"tfstate/prod.tfstate"`
+	GitOpsRepos          map[string]GitOpsRepoDTO `json:"gitops_repos,omitempty"`
+	AllocationTagKeys    []string                 `json:"allocation_tag_keys" example:"team,project"`
+	Notifications        NotificationSettingsDTO  `json:"notifications"`
+	OwnerNotifications   OwnerNotificationsDTO    `json:"owner_notifications"`
+	WeeklySummary        WeeklySummaryDTO         `json:"weekly_summary"`
+	BackupBeforeDelete   BackupSettingsDTO        `json:"backup_before_delete"`
+}
+
+// BackupSettingsDTO represents whether cleanups back up volumes, disks and
+// databases before deleting them, and the retention in effect
+type BackupSettingsDTO struct {
+	Enabled       bool `json:"enabled" example:"true"`
+	RetentionDays int  `json:"retention_days" example:"30"`
+}
+
+// WeeklySummaryDTO represents when the weekly summary of an organization is
+// emailed to its admins
+type WeeklySummaryDTO struct {
+	Enabled  bool   `json:"enabled" example:"true"`
+	Weekday  string `json:"weekday" example:"monday" enums:"monday,tuesday,wednesday,thursday,friday,saturday,sunday"`
+	Hour     int    `json:"hour" example:"8"`
+	Timezone string `json:"timezone" example:"Europe/Paris"`
+}
+
+// OwnerNotificationsDTO represents how the owners of the resources of the
+// notify action are told about them
+type OwnerNotificationsDTO struct {
+	Channels []string `json:"channels" example:"email,slack" enums:"email,slack"`
+	Digest   bool     `json:"digest" example:"false"`
+}
+
+// NotificationSettingsDTO represents the notification channels of an
+// organization, without their webhook URL and routing key
+type NotificationSettingsDTO struct {
+	TeamsConfigured         bool                `json:"teams_configured" example:"true"`
+	PagerDutyConfigured     bool                `json:"pagerduty_configured" example:"true"`
+	Routes                  map[string][]string `json:"routes"`
+	AnomalyThresholdPercent int                 `json:"anomaly_threshold_percent" example:"50"`
+	AnomalyMinIncrease      float64             `json:"anomaly_min_increase" example:"100"`
+}
+
+// GitOpsRepoDTO represents the repository holding the Terraform
+// configuration of a state file
+type GitOpsRepoDTO struct {
+	Provider string `json:"provider" binding:"required,oneof=github gitlab" example:"github"`
+	Repo     string `json:"repo" binding:"required" example:"acme/infra"`
+	Branch   string `json:"branch" binding:"required" example:"main"`
+	Path     string `json:"path" example:"prod"`
+}
+
+// ResourceViewDTO represents a saved filter of the resource list
+type ResourceViewDTO struct {
+	ID             string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string         `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name           string         `json:"name" example:"Unused prod EBS > $50"`
+	Description    string         `json:"description,omitempty" example:"Production EBS volumes left unattached"`
+	Filters        ResourceFilter `json:"filters"`
+	CreatedBy      string         `json:"created_by,omitempty" example:"alice@example.com"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// CustomResourceTypeDTO represents a resource type defined by an
+// organization
+type CustomResourceTypeDTO struct {
+	ID             string             `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string             `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name           string             `json:"name" example:"dynamodb_table"`
+	Provider       string             `json:"provider" example:"aws"`
+	CloudType      string             `json:"cloud_type" example:"AWS::DynamoDB::Table"`
+	Detection      MetricDetectionDTO `json:"detection"`
+	MonthlyCost    float64            `json:"monthly_cost" example:"25"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// MetricDetectionDTO represents the metric reporting the resources of a
+// custom type unused
+type MetricDetectionDTO struct {
+	Namespace  string `json:"namespace" example:"AWS/DynamoDB"`
+	MetricName string `json:"metric_name" example:"ConsumedReadCapacityUnits"`
+	// Dimension is the dimension of the metric valued with the resource ID
+	Dimension string  `json:"dimension" example:"TableName"`
+	Statistic string  `json:"statistic" example:"Sum" enums:"Average,Sum,Minimum,Maximum,SampleCount"`
+	Threshold float64 `json:"threshold" example:"1"`
+	// Above reports resources unused above the threshold rather than below
+	Above        bool `json:"above" example:"false"`
+	LookbackDays int  `json:"lookback_days" example:"30"`
+}
+
+// CloudAccountDTO represents a cloud account of an organization
+type CloudAccountDTO struct {
+	ID             string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider       string `json:"provider" example:"aws"`
+	AccountID      string `json:"account_id" example:"123456789012"`
+	Name           string `json:"name" example:"production"`
+	IsActive       bool   `json:"is_active" example:"true"`
+	// IntegrationID is the integration that discovered the account
+	IntegrationID *string `json:"integration_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// PricingSource is where resource costs come from: "estimate" or a
+	// pricing source of the provider, see GET /providers
+	PricingSource   string            `json:"pricing_source" example:"cost_explorer"`
+	PricingSettings map[string]string `json:"pricing_settings"`
+	PricingSyncedAt *time.Time        `json:"pricing_synced_at,omitempty"`
+	PricingError    string            `json:"pricing_error,omitempty" example:"AccessDeniedException: User is not authorized to perform: ce:GetCostAndUsageWithResources"`
+	DefaultOwner    string            `json:"default_owner,omitempty" example:"platform@example.com"` // owner of the resources no mapping or tag attributes
+	LastSyncAt      *time.Time        `json:"last_sync_at,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+// PermissionReportDTO represents the permissions of a cloud account
+// checked against those CloudSweep calls
+type PermissionReportDTO struct {
+	AccountID string `json:"account_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider  string `json:"provider" example:"aws"`
+	// Complete is true when no permission is missing
+	Complete  bool                 `json:"complete" example:"false"`
+	Checks    []PermissionCheckDTO `json:"checks"`
+	CheckedAt time.Time            `json:"checked_at"`
+}
+
+// PermissionCheckDTO represents the permissions of a scan or a cleanup
+// action
+type PermissionCheckDTO struct {
+	Purpose  string   `json:"purpose" example:"delete" enums:"scan,stop,tag,quarantine,delete,release"`
+	Granted  bool     `json:"granted" example:"false"`
+	Required []string `json:"required" example:"ec2:DeleteVolume"`
+	Missing  []string `json:"missing,omitempty" example:"ec2:DeleteVolume"`
+}
+
+// AccountIntegrationDTO represents the organization-level integration of a
+// provider, e.g. AWS Organizations
+type AccountIntegrationDTO struct {
+	ID             string            `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OrganizationID string            `json:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider       string            `json:"provider" example:"aws"`
+	Settings       map[string]string `json:"settings"`
+	// SecretsSet lists the secret settings, whose values are never returned
+	SecretsSet    []string   `json:"secrets_set" example:"external_id"`
+	AccountCount  int        `json:"account_count" example:"42"`
+	LastSyncAt    *time.Time `json:"last_sync_at,omitempty"`
+	LastSyncError string     `json:"last_sync_error,omitempty" example:"AccessDenied: User is not authorized to perform: sts:AssumeRole"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// PolicyTemplateDTO represents a ready-made policy. Its fields are those of
+// a policy creation request, without the organization.
+type PolicyTemplateDTO struct {
+	ID            string                  `json:"id" example:"aws-idle-nat-gateways"`
+	Name          string                  `json:"name" example:"Idle NAT gateways"`
+	Description   string                  `json:"description" example:"Quarantine NAT gateways that processed almost no traffic for 14 days"`
+	Provider      string                  `json:"provider" example:"aws"`
+	ResourceTypes []string                `json:"resource_types" example:"nat_gateway"`
+	Conditions    entity.PolicyConditions `json:"conditions"`
+	Actions       []string                `json:"actions" example:"notify,quarantine"`
 }
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+// assertGolden compares the JSON encoding of v with testdata/<name>.golden,
+// which is rewritten instead when the tests run with -update
+func assertGolden(t *testing.T, name string, v any) {
+	t.Helper()
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("update %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match %s:\n%s", name, path, got)
+	}
+}
+
+var (
+	goldenOrgID   = uuid.MustParse("6f1c2b9e-0d4a-4c8e-9a51-3b7d2e8f4a10")
+	goldenCreated = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	goldenUpdated = time.Date(2024, 3, 15, 17, 45, 12, 0, time.UTC)
+)
+
+func TestToPolicyDTOGolden(t *testing.T) {
+	viewID := uuid.MustParse("1d9e4c3a-7b2f-4e6d-8c5a-0f3b9a2d7e41")
+	externalID := "platform/stopped-instances"
+
+	tests := []struct {
+		name   string
+		policy model.Policy
+	}{
+		{
+			name: "policy",
+			policy: model.Policy{
+				ID:             uuid.MustParse("c2a7f0e1-5b3d-4f9a-8e6c-2d1b0a9f8e37"),
+				OrganizationID: goldenOrgID,
+				Name:           "Delete unattached volumes",
+				Description:    "Volumes detached for more than 30 days",
+				Provider:       "aws",
+				ResourceTypes:  model.StringArray{"ebs_volume"},
+				Conditions:     model.JSONB{"unused_days": float64(30), "min_monthly_cost": float64(5)},
+				Actions:        model.StringArray{"notify", "delete"},
+				IsEnabled:      true,
+				Schedule:       "0 6 * * 1",
+				CreatedAt:      goldenCreated,
+				UpdatedAt:      goldenUpdated,
+				Version:        3,
+			},
+		},
+		{
+			name: "policy_off_hours",
+			policy: model.Policy{
+				ID:             uuid.MustParse("9b8e2d1c-4a6f-4b3e-a7d5-8c0f1e2a3b49"),
+				OrganizationID: goldenOrgID,
+				Name:           "Stop dev instances at night",
+				Provider:       "aws",
+				ResourceTypes:  model.StringArray{"ec2_instance"},
+				Conditions:     model.JSONB{"required_tags": map[string]any{"env": "dev"}},
+				Actions:        model.StringArray{"schedule"},
+				IsEnabled:      false,
+				OffHours:       model.JSONB{"timezone": "Europe/Paris", "stop": "0 20 * * 1-5", "start": "0 8 * * 1-5"},
+				ViewID:         &viewID,
+				ExternalID:     &externalID,
+				CreatedAt:      goldenCreated,
+				UpdatedAt:      goldenUpdated,
+				Version:        1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := database.PolicyEntity(tt.policy)
+			if err != nil {
+				t.Fatalf("PolicyEntity() error = %v", err)
+			}
+			assertGolden(t, tt.name, toPolicyDTO(policy))
+		})
+	}
+}
+
+func TestToTaskFailureDTOGolden(t *testing.T) {
+	failure := model.TaskFailure{
+		ID:           uuid.MustParse("4e3d2c1b-0a9f-4e8d-b7c6-5a4b3c2d1e0f"),
+		TaskID:       "b5c1e0a2-3d4f-4a6b-9c8d-7e6f5a4b3c2d",
+		TaskType:     "scan:resources",
+		Queue:        "default",
+		Payload:      model.JSONB{"scan_id": "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d", "regions": []any{"eu-west-3"}},
+		Error:        "failed to scan ebs_volume: AccessDenied: not authorized to perform config:SelectResourceConfig",
+		Attempts:     5,
+		MaxRetry:     5,
+		Status:       "failed",
+		LastFailedAt: goldenUpdated,
+		CreatedAt:    goldenCreated,
+		UpdatedAt:    goldenUpdated,
+	}
+	assertGolden(t, "task_failure", toTaskFailureDTO(&failure))
+}
+
+func TestResourceDTOGolden(t *testing.T) {
+	snoozedUntil := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	approvedAt := time.Date(2024, 3, 16, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		resource model.Resource
+	}{
+		{
+			name: "resource",
+			resource: model.Resource{
+				ID:              uuid.MustParse("7a6b5c4d-3e2f-4a1b-8c9d-0e1f2a3b4c5d"),
+				OrganizationID:  goldenOrgID,
+				Provider:        "aws",
+				Type:            "ebs_volume",
+				ResourceID:      "vol-0abc123def4567890",
+				Region:          "eu-west-3",
+				AccountID:       "123456789012",
+				Name:            "build-cache",
+				Status:          "unused",
+				Tags:            model.JSONB{"team": "platform", "env": "ci", "cost-center": float64(42)},
+				MonthlyCost:     8,
+				CarbonFootprint: 0.0123,
+				LastSeenAt:      goldenUpdated,
+				Owner:           "platform@example.com",
+				OwnerSource:     "tag",
+				CreatedAt:       goldenCreated,
+				UpdatedAt:       goldenUpdated,
+			},
+		},
+		{
+			name: "resource_approved",
+			resource: model.Resource{
+				ID:                uuid.MustParse("2f3e4d5c-6b7a-4891-a0b1-c2d3e4f5a6b7"),
+				OrganizationID:    goldenOrgID,
+				Provider:          "gcp",
+				Type:              "gcp_static_ip",
+				ResourceID:        "projects/demo/regions/europe-west1/addresses/legacy-ingress",
+				Region:            "europe-west1",
+				AccountID:         "demo",
+				Status:            "unused",
+				MonthlyCost:       7.3,
+				LastSeenAt:        goldenUpdated,
+				SnoozedUntil:      &snoozedUntil,
+				CleanupApprovedAt: &approvedAt,
+				CleanupApprovedBy: "admin@example.com",
+				CreatedAt:         goldenCreated,
+				UpdatedAt:         goldenUpdated,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, toResourceDTO(database.ResourceEntity(&tt.resource)))
+		})
+	}
+}
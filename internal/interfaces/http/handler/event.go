@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/events"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// eventHeartbeat is how often an idle stream sends a comment, so that
+// proxies keep the connection open
+const eventHeartbeat = 25 * time.Second
+
+// EventHandler handles the live event stream
+type EventHandler struct {
+	bus *events.Bus
+}
+
+// NewEventHandler creates a new EventHandler
+func NewEventHandler(bus *events.Bus) *EventHandler {
+	return &EventHandler{bus: bus}
+}
+
+// EventsRequest represents query parameters of the event stream
+type EventsRequest struct {
+	// OrganizationID is required unless signed in
+	OrganizationID string `form:"organization_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Types is a comma-separated list of event types, all by default
+	Types string `form:"types" example:"scan.progress,cleanup.result"`
+}
+
+// Stream godoc
+//
+//	@Summary		Event stream
+//	@Description	Stream the live events of an organization as server-sent events, so that the dashboard does not poll: scan.progress (scan status and counts), resource.status (a resource changed status) and cleanup.result (a cleanup task finished). Each event has the type as its name and an Event as its JSON data. Events are not stored: a client only receives those published while connected. Requests must accept text/event-stream.
+//	@Tags			Events
+//	@Produce		text/event-stream
+//	@Param			organization_id	query		string	false	"Organization ID, required unless signed in"	format(uuid)
+//	@Param			types			query		string	false	"Comma-separated event types"
+//	@Success		200				{object}	events.Event
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		403				{object}	ErrorResponse
+//	@Failure		503				{object}	ErrorResponse
+//	@Router			/events [get]
+func (h *EventHandler) Stream(c *gin.Context) {
+	var req EventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	orgID, ok := dashboardOrganization(c, req.OrganizationID)
+	if !ok {
+		return
+	}
+	types := map[string]bool{}
+	for _, t := range strings.Split(req.Types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types[t] = true
+		}
+	}
+
+	ctx := c.Request.Context()
+	sub, err := h.bus.Subscribe(ctx, orgID.String())
+	if err != nil {
+		log.Printf("Failed to subscribe to events of org %s: %v", orgID, err)
+		apierror.Respond(c, http.StatusServiceUnavailable, "event stream unavailable")
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Failed to clear write deadline of event stream: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			return true
+		case event, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+			if len(types) > 0 && !types[event.Type] {
+				return true
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/jobs"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// ExportHandler handles export endpoints
+type ExportHandler struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+	store       storage.ObjectStore
+	urlTTL      time.Duration
+	jobs        *jobs.Tracker
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(db *gorm.DB, queueClient *asynq.Client, store storage.ObjectStore, urlTTL time.Duration) *ExportHandler {
+	return &ExportHandler{
+		db:          db,
+		queueClient: queueClient,
+		store:       store,
+		urlTTL:      urlTTL,
+		jobs:        jobs.NewTracker(db),
+	}
+}
+
+// CreateExportRequest represents a request to create an export
+type CreateExportRequest struct {
+	OrganizationID string            `json:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type           string            `json:"type" binding:"required,oneof=resources scans" example:"resources"`
+	Format         string            `json:"format" binding:"required,oneof=csv json" example:"csv"`
+	Filters        map[string]string `json:"filters"`
+}
+
+// CreateExportResponse represents the response after creating an export
+type CreateExportResponse struct {
+	Data    ExportDTO `json:"data"`
+	Message string    `json:"message" example:"export queued for generation"`
+}
+
+// Create godoc
+//
+//	@Summary		Create export
+//	@Description	Create an asynchronous export job. Poll GET /exports/{id} until completed to obtain a download URL.
+//	@Tags			Exports
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateExportRequest	true	"Export request"
+//	@Success		202		{object}	CreateExportResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/exports [post]
+func (h *ExportHandler) Create(c *gin.Context) {
+	var req CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	filters := model.JSONB{}
+	for k, v := range req.Filters {
+		filters[k] = v
+	}
+
+	export := model.Export{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Type:           req.Type,
+		Format:         req.Format,
+		Filters:        filters,
+		Status:         string(entity.ExportStatusPending),
+	}
+
+	if err := h.db.WithContext(c.Request.Context()).Create(&export).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to create export")
+		return
+	}
+
+	payload, _ := json.Marshal(queue.GenerateExportPayload{ExportID: export.ID.String()})
+	task := queue.NewTask(queue.TaskTypeGenerateExport, payload, asynq.Queue(queue.QueueLow))
+	if _, _, err := h.jobs.Enqueue(c.Request.Context(), h.queueClient, orgID, entity.JobTypeExport, &export.ID, task); err != nil {
+		h.db.Model(&export).Update("status", string(entity.ExportStatusFailed))
+		apierror.Respond(c, http.StatusInternalServerError, "failed to enqueue export task")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, CreateExportResponse{
+		Data:    toExportDTO(&export),
+		Message: "export queued for generation",
+	})
+}
+
+// Get godoc
+//
+//	@Summary		Get export by ID
+//	@Description	Get the status of an export job. Completed exports include a time-limited download URL.
+//	@Tags			Exports
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Export ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[ExportDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/exports/{id} [get]
+func (h *ExportHandler) Get(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid export ID")
+		return
+	}
+
+	var export model.Export
+	if err := h.db.WithContext(c.Request.Context()).First(&export, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "export not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch export")
+		return
+	}
+
+	dto := toExportDTO(&export)
+	if export.Status == string(entity.ExportStatusCompleted) && export.ObjectKey != "" {
+		url, err := h.store.SignedURL(c.Request.Context(), export.ObjectKey, h.urlTTL)
+		if err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, "failed to sign download URL")
+			return
+		}
+		expiresAt := time.Now().Add(h.urlTTL)
+		dto.DownloadURL = url
+		dto.DownloadURLExpiresAt = &expiresAt
+	}
+
+	c.JSON(http.StatusOK, dataResponse(dto))
+}
+
+func toExportDTO(e *model.Export) ExportDTO {
+	filters := make(map[string]string, len(e.Filters))
+	for k, v := range e.Filters {
+		if s, ok := v.(string); ok {
+			filters[k] = s
+		}
+	}
+	return ExportDTO{
+		ID:             e.ID.String(),
+		OrganizationID: e.OrganizationID.String(),
+		Type:           e.Type,
+		Format:         e.Format,
+		Filters:        filters,
+		Status:         e.Status,
+		RowCount:       e.RowCount,
+		SizeBytes:      e.SizeBytes,
+		ErrorMessage:   e.ErrorMessage,
+		StartedAt:      e.StartedAt,
+		CompletedAt:    e.CompletedAt,
+		CreatedAt:      e.CreatedAt,
+	}
+}
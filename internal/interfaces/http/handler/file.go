@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler serves files from the local object store through signed URLs
+type FileHandler struct {
+	store *storage.LocalStore
+}
+
+// NewFileHandler creates a new FileHandler
+func NewFileHandler(store *storage.LocalStore) *FileHandler {
+	return &FileHandler{store: store}
+}
+
+// Download serves a stored file after verifying its URL signature
+func (h *FileHandler) Download(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !h.store.Verify(key, c.Query("expires"), c.Query("signature")) {
+		apierror.Respond(c, http.StatusForbidden, "invalid or expired download link")
+		return
+	}
+
+	rc, err := h.store.Get(c.Request.Context(), key)
+	if err != nil {
+		if err == storage.ErrObjectNotFound {
+			apierror.Respond(c, http.StatusNotFound, "file not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to read file")
+		return
+	}
+	defer rc.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "application/octet-stream", rc, map[string]string{
+		"Content-Disposition": `attachment; filename="` + path.Base(key) + `"`,
+	})
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -59,16 +60,12 @@ func (h *HealthHandler) Ready(c *gin.Context) {
 	// Check database connection
 	sqlDB, err := h.db.DB()
 	if err != nil {
-		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
-			Error: "database connection unavailable",
-		})
+		apierror.Respond(c, http.StatusServiceUnavailable, "database connection unavailable")
 		return
 	}
 
-	if err := sqlDB.Ping(); err != nil {
-		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
-			Error: "database ping failed",
-		})
+	if err := sqlDB.PingContext(c.Request.Context()); err != nil {
+		apierror.Respond(c, http.StatusServiceUnavailable, "database ping failed")
 		return
 	}
 
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IaCChangeHandler handles the pull requests opened to remove resources
+// managed by infrastructure-as-code
+type IaCChangeHandler struct {
+	db *gorm.DB
+}
+
+// NewIaCChangeHandler creates a new IaCChangeHandler
+func NewIaCChangeHandler(db *gorm.DB) *IaCChangeHandler {
+	return &IaCChangeHandler{db: db}
+}
+
+// ListIaCChangesRequest represents query parameters for listing IaC changes
+type ListIaCChangesRequest struct {
+	OrganizationID string `form:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID         string `form:"task_id" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	State          string `form:"state" binding:"omitempty,oneof=open closed merged" example:"open"`
+	Limit          int    `form:"limit,default=50" example:"50"`
+	Offset         int    `form:"offset,default=0" example:"0"`
+}
+
+// List godoc
+//
+//	@Summary		List IaC changes
+//	@Description	Get a paginated list of the pull requests opened by cleanups to remove Terraform-managed resources from their configuration, most recent first. Filter by task_id to get the pull requests of a cleanup. States are refreshed periodically.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	true	"Organization ID"	format(uuid)
+//	@Param			task_id			query		string	false	"Cleanup task ID"
+//	@Param			state			query		string	false	"Pull request state"	Enums(open, closed, merged)
+//	@Param			limit			query		int		false	"Number of items per page"	default(50)
+//	@Param			offset			query		int		false	"Number of items to skip"	default(0)
+//	@Success		200				{object}	PaginatedResponse{data=[]IaCChangeDTO}
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/cleanup/changes [get]
+func (h *IaCChangeHandler) List(c *gin.Context) {
+	var req ListIaCChangesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.IaCChange{}).Where("organization_id = ?", orgID)
+
+	if req.TaskID != "" {
+		query = query.Where("task_id = ?", req.TaskID)
+	}
+	if req.State != "" {
+		query = query.Where("state = ?", req.State)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var changes []model.IaCChange
+	if err := query.Limit(req.Limit).Offset(req.Offset).Order("created_at DESC").Find(&changes).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch IaC changes")
+		return
+	}
+
+	data := make([]IaCChangeDTO, 0, len(changes))
+	for _, change := range changes {
+		data = append(data, IaCChangeDTO{
+			ID:         change.ID.String(),
+			ResourceID: change.ResourceID.String(),
+			TaskID:     change.TaskID,
+			Address:    change.Address,
+			Provider:   change.Provider,
+			Repo:       change.Repo,
+			FilePath:   change.FilePath,
+			Number:     change.Number,
+			URL:        change.URL,
+			State:      change.State,
+			ClosedAt:   change.ClosedAt,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   data,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// IntegrationHandler handles the organization-level integrations of cloud
+// providers, e.g. AWS Organizations, which discover cloud accounts
+type IntegrationHandler struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+}
+
+// NewIntegrationHandler creates a new IntegrationHandler
+func NewIntegrationHandler(db *gorm.DB, queueClient *asynq.Client) *IntegrationHandler {
+	return &IntegrationHandler{db: db, queueClient: queueClient}
+}
+
+// UpdateIntegrationRequest represents a request to configure the integration
+// of a provider. The settings are the fields listed by GET /providers.
+type UpdateIntegrationRequest struct {
+	// Secret settings are kept when left empty on an existing integration
+	Settings map[string]string `json:"settings" binding:"required" example:"management_role_arn:arn:aws:iam::123456789012:role/CloudSweepOrganizationsRole,member_role_name:CloudSweepAuditRole"`
+}
+
+// List godoc
+//
+//	@Summary		List integrations
+//	@Description	List the organization-level integrations of an organization with the outcome of their last sync
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[[]AccountIntegrationDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/integrations [get]
+func (h *IntegrationHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var integrations []model.AccountIntegration
+	if err := h.db.WithContext(c.Request.Context()).Where("organization_id = ?", orgID).Order("provider").Find(&integrations).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to list integrations")
+		return
+	}
+
+	out := make([]AccountIntegrationDTO, len(integrations))
+	for i := range integrations {
+		out[i] = toAccountIntegrationDTO(&integrations[i])
+	}
+	c.JSON(http.StatusOK, dataResponse(out))
+}
+
+// Get godoc
+//
+//	@Summary		Get integration
+//	@Description	Get the integration of a provider. Secret settings are never returned.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Organization ID"	format(uuid)
+//	@Param			provider	path		string	true	"Provider"
+//	@Success		200			{object}	DataResponse[AccountIntegrationDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/integrations/{provider} [get]
+func (h *IntegrationHandler) Get(c *gin.Context) {
+	integration, ok := h.load(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(toAccountIntegrationDTO(integration)))
+}
+
+// Update godoc
+//
+//	@Summary		Configure integration
+//	@Description	Create or replace the integration of a provider, e.g. AWS Organizations: CloudSweep assumes the management role to list the member accounts, and creates a cloud account for each that assumes the member role. Accounts are synced right away, then on the integrations schedule.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string						true	"Organization ID"	format(uuid)
+//	@Param			provider	path		string						true	"Provider"
+//	@Param			request		body		UpdateIntegrationRequest	true	"Integration"
+//	@Success		200			{object}	DataResponse[AccountIntegrationDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/integrations/{provider} [put]
+func (h *IntegrationHandler) Update(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	spec, ok := integrationSpec(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if !requireActiveOrganization(c, h.db, orgID) {
+		return
+	}
+
+	integration := model.AccountIntegration{OrganizationID: orgID, Provider: c.Param("provider")}
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&integration, "organization_id = ? AND provider = ?", orgID, integration.Provider).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		exists := err == nil
+
+		settings, err := validateSettings(spec.Settings, req.Settings, integration.SettingValues())
+		if err != nil {
+			return err
+		}
+		integration.Settings = settings
+		if exists {
+			return tx.Save(&integration).Error
+		}
+		return tx.Create(&integration).Error
+	})
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) {
+			apierror.RespondError(c, err)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to save integration")
+		return
+	}
+
+	if err := queue.EnqueueIntegrationSync(c.Request.Context(), h.queueClient, integration.ID.String()); err != nil {
+		log.Printf("Failed to enqueue sync of integration %s: %v", integration.ID, err)
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toAccountIntegrationDTO(&integration)))
+}
+
+// Delete godoc
+//
+//	@Summary		Delete integration
+//	@Description	Remove the integration of a provider. The cloud accounts it discovered are kept and no longer synced.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Organization ID"	format(uuid)
+//	@Param			provider	path		string	true	"Provider"
+//	@Success		200			{object}	MessageResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/integrations/{provider} [delete]
+func (h *IntegrationHandler) Delete(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	result := h.db.WithContext(c.Request.Context()).
+		Delete(&model.AccountIntegration{}, "organization_id = ? AND provider = ?", orgID, c.Param("provider"))
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to delete integration")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "integration not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "integration deleted"})
+}
+
+// Sync godoc
+//
+//	@Summary		Sync integration
+//	@Description	Queue the discovery of the accounts of an integration without waiting for its schedule. The outcome is reported by the last_sync fields of the integration.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Organization ID"	format(uuid)
+//	@Param			provider	path		string	true	"Provider"
+//	@Success		202			{object}	MessageResponse
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations/{id}/integrations/{provider}/sync [post]
+func (h *IntegrationHandler) Sync(c *gin.Context) {
+	integration, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	if err := queue.EnqueueIntegrationSync(c.Request.Context(), h.queueClient, integration.ID.String()); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to enqueue integration sync")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "integration sync queued"})
+}
+
+// load responds with an error and returns false when the integration of the
+// request does not exist
+func (h *IntegrationHandler) load(c *gin.Context) (*model.AccountIntegration, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return nil, false
+	}
+
+	var integration model.AccountIntegration
+	err = h.db.WithContext(c.Request.Context()).
+		First(&integration, "organization_id = ? AND provider = ?", orgID, c.Param("provider")).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "integration not found")
+			return nil, false
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch integration")
+		return nil, false
+	}
+	return &integration, true
+}
+
+// integrationSpec responds with an error and returns false when the
+// provider of the request has no integration
+func integrationSpec(c *gin.Context) (*provider.Integration, bool) {
+	p, ok := provider.Lookup(c.Param("provider"))
+	if !ok || p.Integration == nil {
+		apierror.Respond(c, http.StatusBadRequest, "provider does not support integrations, see GET /providers")
+		return nil, false
+	}
+	return p.Integration, true
+}
+
+// validateSettings validates the settings of a request against the fields
+// of an integration or pricing source. Secret settings left empty keep their
+// current value.
+func validateSettings(spec []provider.CredentialField, settings, current map[string]string) (model.JSONB, error) {
+	fields := make(map[string]provider.CredentialField, len(spec))
+	for _, f := range spec {
+		fields[f.Name] = f
+	}
+
+	details := map[string]any{}
+	out := model.JSONB{}
+	for name, value := range settings {
+		f, ok := fields[name]
+		if !ok {
+			details["settings."+name] = "is not a known setting"
+			continue
+		}
+		if value = strings.TrimSpace(value); value == "" && f.Secret {
+			value = current[name]
+		}
+		if value != "" {
+			out[name] = value
+		}
+	}
+	for _, f := range spec {
+		if _, ok := out[f.Name]; !ok && f.Required {
+			details["settings."+f.Name] = "is required"
+		}
+	}
+
+	if len(details) > 0 {
+		message := "invalid request"
+		if len(details) == 1 {
+			for field, msg := range details {
+				message = field + " " + msg.(string)
+			}
+		}
+		return nil, &apperrors.AppError{Code: apperrors.CodeValidationFailed, Message: message, Details: details}
+	}
+	return out, nil
+}
+
+func toAccountIntegrationDTO(m *model.AccountIntegration) AccountIntegrationDTO {
+	secret := map[string]bool{}
+	if p, ok := provider.Lookup(m.Provider); ok && p.Integration != nil {
+		for _, f := range p.Integration.Settings {
+			secret[f.Name] = f.Secret
+		}
+	}
+
+	settings := map[string]string{}
+	secretsSet := []string{}
+	for name, value := range m.SettingValues() {
+		if secret[name] {
+			secretsSet = append(secretsSet, name)
+			continue
+		}
+		settings[name] = value
+	}
+	sort.Strings(secretsSet)
+
+	return AccountIntegrationDTO{
+		ID:             m.ID.String(),
+		OrganizationID: m.OrganizationID.String(),
+		Provider:       m.Provider,
+		Settings:       settings,
+		SecretsSet:     secretsSet,
+		AccountCount:   m.AccountCount,
+		LastSyncAt:     m.LastSyncAt,
+		LastSyncError:  m.LastSyncError,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
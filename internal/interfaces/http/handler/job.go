@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobHandler handles the endpoints of the long-running operations of
+// organizations
+type JobHandler struct {
+	db *gorm.DB
+}
+
+// NewJobHandler creates a new JobHandler
+func NewJobHandler(db *gorm.DB) *JobHandler {
+	return &JobHandler{db: db}
+}
+
+// ListJobsRequest represents query parameters for listing jobs
+type ListJobsRequest struct {
+	OrganizationID string `form:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type           string `form:"type" binding:"omitempty,oneof=scan cleanup export" example:"scan"`
+	Status         string `form:"status" binding:"omitempty,oneof=pending running completed failed" example:"running"`
+	Limit          int    `form:"limit,default=50" example:"50"`
+	Offset         int    `form:"offset,default=0" example:"0"`
+}
+
+// List godoc
+//
+//	@Summary		List jobs
+//	@Description	Get a paginated list of the scans, cleanups and exports of an organization running in the background, newest first
+//	@Tags			Jobs
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	true	"Organization ID"	format(uuid)
+//	@Param			type			query		string	false	"Filter by type"	Enums(scan, cleanup, export)
+//	@Param			status			query		string	false	"Filter by status"	Enums(pending, running, completed, failed)
+//	@Param			limit			query		int		false	"Number of items per page"	default(50)
+//	@Param			offset			query		int		false	"Number of items to skip"	default(0)
+//	@Success		200				{object}	PaginatedResponse{data=[]JobDTO}
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/jobs [get]
+func (h *JobHandler) List(c *gin.Context) {
+	var req ListJobsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.Job{}).Where("organization_id = ?", orgID)
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var jobs []model.Job
+	if err := query.Limit(req.Limit).Offset(req.Offset).Order("created_at DESC").Find(&jobs).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch jobs")
+		return
+	}
+
+	dtos := make([]JobDTO, len(jobs))
+	for i := range jobs {
+		dtos[i] = toJobDTO(&jobs[i])
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   dtos,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+// Get godoc
+//
+//	@Summary		Get job by ID
+//	@Description	Get the status, progress and error of a job, with a link to its result
+//	@Tags			Jobs
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Job ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[JobDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/jobs/{id} [get]
+func (h *JobHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid job ID")
+		return
+	}
+
+	var job model.Job
+	if err := h.db.WithContext(c.Request.Context()).First(&job, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "job not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch job")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toJobDTO(&job)))
+}
+
+func toJobDTO(j *model.Job) JobDTO {
+	dto := JobDTO{
+		ID:             j.ID.String(),
+		OrganizationID: j.OrganizationID.String(),
+		Type:           j.Type,
+		Status:         j.Status,
+		Finished:       entity.JobStatus(j.Status).Finished(),
+		Progress:       j.Progress,
+		TaskID:         j.TaskID,
+		Error:          j.Error,
+		Attempts:       j.Attempts,
+		StartedAt:      j.StartedAt,
+		CompletedAt:    j.CompletedAt,
+		CreatedAt:      j.CreatedAt,
+	}
+	if j.TargetID != nil {
+		dto.TargetID = j.TargetID.String()
+	}
+
+	switch entity.JobType(j.Type) {
+	case entity.JobTypeScan:
+		dto.ResultURL = "/api/v1/scans/" + dto.TargetID
+	case entity.JobTypeExport:
+		dto.ResultURL = "/api/v1/exports/" + dto.TargetID
+	case entity.JobTypeCleanup:
+		q := url.Values{"organization_id": {dto.OrganizationID}, "task_id": {j.TaskID}}
+		dto.ResultURL = "/api/v1/audit/provider-calls?" + q.Encode()
+	}
+	return dto
+}
@@ -0,0 +1,512 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MemberHandler handles organization membership and invitation endpoints
+type MemberHandler struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+	cfg         config.InvitationConfig
+}
+
+// NewMemberHandler creates a new MemberHandler
+func NewMemberHandler(db *gorm.DB, queueClient *asynq.Client, cfg config.InvitationConfig) *MemberHandler {
+	return &MemberHandler{db: db, queueClient: queueClient, cfg: cfg}
+}
+
+// CreateInvitationRequest represents a request to invite someone to join an
+// organization
+type CreateInvitationRequest struct {
+	InviteRequest
+	InvitedBy string `json:"invited_by" binding:"omitempty,email" example:"jane@acme.com"`
+}
+
+// AcceptInvitationRequest represents a request to accept an invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required" example:"q1w2e3r4t5y6u7i8o9p0"`
+	Name  string `json:"name" binding:"max=255" example:"John Smith"`
+}
+
+// UpdateMemberRequest represents a request to change the role of a member
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member viewer" example:"admin"`
+}
+
+// UpdateMemberPreferencesRequest represents a request to change the emails
+// a member receives
+type UpdateMemberPreferencesRequest struct {
+	WeeklySummary *bool `json:"weekly_summary" binding:"required" example:"false"`
+}
+
+var (
+	errAlreadyMember = apperrors.NewWithCode(apperrors.ErrAlreadyExists, apperrors.CodeAlreadyExists, "user is already a member of the organization")
+	errLastAdmin     = apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "the organization must keep at least one admin")
+)
+
+// List godoc
+//
+//	@Summary		List members
+//	@Description	Get the members of an organization with their role, admins first
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[[]MemberDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/members [get]
+func (h *MemberHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var memberships []model.Membership
+	err = h.db.WithContext(c.Request.Context()).Preload("User").
+		Where("organization_id = ?", orgID).
+		Order("CASE role WHEN 'admin' THEN 0 WHEN 'member' THEN 1 ELSE 2 END, created_at").
+		Find(&memberships).Error
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch members")
+		return
+	}
+
+	data := make([]MemberDTO, 0, len(memberships))
+	for i := range memberships {
+		data = append(data, toMemberDTO(&memberships[i]))
+	}
+	c.JSON(http.StatusOK, dataResponse(data))
+}
+
+// Invite godoc
+//
+//	@Summary		Invite member
+//	@Description	Invite someone to join an organization. The invitation link is emailed and can be accepted until it expires; inviting the same email again replaces the pending invitation.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"Organization ID"	format(uuid)
+//	@Param			request	body		CreateInvitationRequest	true	"Invitation"
+//	@Success		201		{object}	DataResponse[InvitationDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/members [post]
+func (h *MemberHandler) Invite(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	var org model.Organization
+	if err := h.db.WithContext(c.Request.Context()).First(&org, "id = ?", orgID).Error; err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	if !org.IsActive {
+		apierror.Respond(c, http.StatusConflict, "organization is deactivated")
+		return
+	}
+
+	email := strings.ToLower(req.Email)
+	var issued issuedInvitation
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var members int64
+		err := tx.Model(&model.Membership{}).
+			Joins("JOIN users ON users.id = memberships.user_id").
+			Where("memberships.organization_id = ? AND users.email = ?", orgID, email).
+			Count(&members).Error
+		if err != nil {
+			return err
+		}
+		if members > 0 {
+			return errAlreadyMember
+		}
+
+		issued, err = issueInvitation(tx, orgID, email, entity.MemberRole(req.Role), req.InvitedBy, h.cfg.TTL)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyMember) {
+			apierror.RespondError(c, err)
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to create invitation")
+		return
+	}
+
+	if err := sendInvitation(c.Request.Context(), h.queueClient, h.cfg, org.Name, issued); err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to send invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dataResponse(toInvitationDTO(&issued.Invitation)))
+}
+
+// Accept godoc
+//
+//	@Summary		Accept invitation
+//	@Description	Accept an invitation with the token of its link. The user is created from the invited email when it does not exist yet.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		AcceptInvitationRequest	true	"Invitation token"
+//	@Success		200		{object}	DataResponse[MemberDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		410		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/invitations/accept [post]
+func (h *MemberHandler) Accept(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	var inv model.Invitation
+	if err := h.db.WithContext(c.Request.Context()).Preload("Organization").First(&inv, "token_hash = ?", hashInvitationToken(req.Token)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "invitation not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch invitation")
+		return
+	}
+	if inv.AcceptedAt != nil {
+		apierror.Respond(c, http.StatusConflict, "invitation was already accepted")
+		return
+	}
+	if !time.Now().Before(inv.ExpiresAt) {
+		apierror.Respond(c, http.StatusGone, "invitation has expired")
+		return
+	}
+	if !inv.Organization.IsActive {
+		apierror.Respond(c, http.StatusConflict, "organization is deactivated")
+		return
+	}
+
+	var membership model.Membership
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		// Accepting twice at the same time only adds the member once
+		result := tx.Model(&model.Invitation{}).Where("id = ? AND accepted_at IS NULL", inv.ID).Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyMember
+		}
+
+		var user model.User
+		if err := tx.Where(model.User{Email: inv.Email}).Attrs(model.User{Name: req.Name}).FirstOrCreate(&user).Error; err != nil {
+			return err
+		}
+		// Members keep their current role when accepting another invitation
+		err := tx.Where(model.Membership{OrganizationID: inv.OrganizationID, UserID: user.ID}).
+			Attrs(model.Membership{Role: inv.Role}).
+			FirstOrCreate(&membership).Error
+		if err != nil {
+			return err
+		}
+		membership.User = user
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyMember) {
+			apierror.Respond(c, http.StatusConflict, "invitation was already accepted")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to accept invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toMemberDTO(&membership)))
+}
+
+// UpdateRole godoc
+//
+//	@Summary		Change member role
+//	@Description	Change the role of a member. The last admin of an organization cannot be demoted.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Organization ID"	format(uuid)
+//	@Param			user_id	path		string				true	"User ID"			format(uuid)
+//	@Param			request	body		UpdateMemberRequest	true	"Role"
+//	@Success		200		{object}	DataResponse[MemberDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/members/{user_id} [put]
+func (h *MemberHandler) UpdateRole(c *gin.Context) {
+	orgID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	var membership model.Membership
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("User").First(&membership, "organization_id = ? AND user_id = ?", orgID, userID).Error; err != nil {
+			return err
+		}
+		if req.Role != string(entity.MemberRoleAdmin) {
+			if err := checkOtherAdmins(tx, &membership); err != nil {
+				return err
+			}
+		}
+		membership.Role = req.Role
+		return tx.Model(&membership).Update("role", req.Role).Error
+	})
+	if err != nil {
+		respondMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toMemberDTO(&membership)))
+}
+
+// UpdatePreferences godoc
+//
+//	@Summary		Change member preferences
+//	@Description	Subscribe or unsubscribe a member from the weekly summary of the organization, emailed to admins
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Organization ID"	format(uuid)
+//	@Param			user_id	path		string							true	"User ID"			format(uuid)
+//	@Param			request	body		UpdateMemberPreferencesRequest	true	"Preferences"
+//	@Success		200		{object}	DataResponse[MemberDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/members/{user_id}/preferences [put]
+func (h *MemberHandler) UpdatePreferences(c *gin.Context) {
+	orgID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMemberPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	var membership model.Membership
+	if err := h.db.WithContext(c.Request.Context()).Preload("User").First(&membership, "organization_id = ? AND user_id = ?", orgID, userID).Error; err != nil {
+		respondMemberError(c, err)
+		return
+	}
+	membership.WeeklySummaryOptOut = !*req.WeeklySummary
+	if err := h.db.WithContext(c.Request.Context()).Model(&membership).Update("weekly_summary_opt_out", membership.WeeklySummaryOptOut).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toMemberDTO(&membership)))
+}
+
+// Remove godoc
+//
+//	@Summary		Remove member
+//	@Description	Remove a member from an organization. The last admin of an organization cannot be removed.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"Organization ID"	format(uuid)
+//	@Param			user_id	path		string	true	"User ID"			format(uuid)
+//	@Success		200		{object}	MessageResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/members/{user_id} [delete]
+func (h *MemberHandler) Remove(c *gin.Context) {
+	orgID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var membership model.Membership
+		if err := tx.First(&membership, "organization_id = ? AND user_id = ?", orgID, userID).Error; err != nil {
+			return err
+		}
+		if err := checkOtherAdmins(tx, &membership); err != nil {
+			return err
+		}
+		return tx.Delete(&membership).Error
+	})
+	if err != nil {
+		respondMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "member removed"})
+}
+
+func memberParams(c *gin.Context) (orgID, userID uuid.UUID, ok bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return orgID, userID, false
+	}
+	userID, err = uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid user ID")
+		return orgID, userID, false
+	}
+	return orgID, userID, true
+}
+
+// checkOtherAdmins returns errLastAdmin when an admin membership is the
+// only one of its organization. The admins are locked until the
+// transaction ends, so concurrent demotions cannot remove them all.
+func checkOtherAdmins(tx *gorm.DB, membership *model.Membership) error {
+	if membership.Role != string(entity.MemberRoleAdmin) {
+		return nil
+	}
+	var admins []model.Membership
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ?", membership.OrganizationID, string(entity.MemberRoleAdmin)).
+		Find(&admins).Error
+	if err != nil {
+		return err
+	}
+	if len(admins) <= 1 {
+		return errLastAdmin
+	}
+	return nil
+}
+
+func respondMemberError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Respond(c, http.StatusNotFound, "member not found")
+	case errors.Is(err, errLastAdmin):
+		apierror.RespondError(c, err)
+	default:
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update member")
+	}
+}
+
+// issuedInvitation is an invitation with the token of its link, only known
+// when it is created
+type issuedInvitation struct {
+	model.Invitation
+	Token string
+}
+
+// issueInvitation creates an invitation with a new token, replacing the
+// pending invitations of the same email
+func issueInvitation(tx *gorm.DB, orgID uuid.UUID, email string, role entity.MemberRole, invitedBy string, ttl time.Duration) (issuedInvitation, error) {
+	if ttl <= 0 {
+		ttl = entity.DefaultInvitationTTL
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return issuedInvitation{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err := tx.Where("organization_id = ? AND email = ? AND accepted_at IS NULL", orgID, email).Delete(&model.Invitation{}).Error
+	if err != nil {
+		return issuedInvitation{}, err
+	}
+	inv := model.Invitation{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           string(role),
+		InvitedBy:      invitedBy,
+		TokenHash:      hashInvitationToken(token),
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+	if err := tx.Create(&inv).Error; err != nil {
+		return issuedInvitation{}, err
+	}
+	return issuedInvitation{Invitation: inv, Token: token}, nil
+}
+
+// hashInvitationToken returns the hash invitations are looked up by, so
+// tokens are never stored
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendInvitation queues the email holding the invitation link
+func sendInvitation(ctx context.Context, client *asynq.Client, cfg config.InvitationConfig, orgName string, inv issuedInvitation) error {
+	link := cfg.AcceptURL
+	if strings.Contains(link, "?") {
+		link += "&token=" + url.QueryEscape(inv.Token)
+	} else {
+		link += "?token=" + url.QueryEscape(inv.Token)
+	}
+	err := queue.EnqueueNotification(ctx, client, queue.SendNotificationPayload{
+		Type:    queue.NotificationTypeInvitation,
+		To:      inv.Email,
+		Subject: "[CloudSweep] You are invited to join " + orgName,
+		Data: map[string]any{
+			"organization": orgName,
+			"role":         inv.Role,
+			"invited_by":   inv.InvitedBy,
+			"link":         link,
+			"expires_at":   inv.ExpiresAt.UTC().Format("2006-01-02 15:04 MST"),
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to queue invitation %s: %v", inv.ID, err)
+	}
+	return err
+}
+
+func toMemberDTO(m *model.Membership) MemberDTO {
+	return MemberDTO{
+		OrganizationID: m.OrganizationID.String(),
+		User:           toUserDTO(&m.User),
+		Role:           m.Role,
+		WeeklySummary:  !m.WeeklySummaryOptOut,
+		JoinedAt:       m.CreatedAt,
+	}
+}
@@ -0,0 +1,952 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// OrganizationHandler handles organization endpoints. Organization settings
+// are cached for settingsTTL.
+type OrganizationHandler struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+	invitations config.InvitationConfig
+	cache       *cache.Cache
+	settingsTTL time.Duration
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler. A nil cache
+// reads the settings on every request.
+func NewOrganizationHandler(db *gorm.DB, queueClient *asynq.Client, invitations config.InvitationConfig, results *cache.Cache, settingsTTL time.Duration) *OrganizationHandler {
+	return &OrganizationHandler{db: db, queueClient: queueClient, invitations: invitations, cache: results, settingsTTL: settingsTTL}
+}
+
+// UpdateOrganizationSettingsRequest represents a request to update organization settings
+type UpdateOrganizationSettingsRequest struct {
+	DefaultRegions     []string          `json:"default_regions" example:"eu-west-1,eu-central-1"`
+	RegionDenylist     []string          `json:"region_denylist" example:"cn-*,us-gov-*"`
+	OwnerTagKeys       []string          `json:"owner_tag_keys" example:"owner,team"`
+	OwnerAliases       map[string]string `json:"owner_aliases"`
+	DefaultOwner       string            `json:"default_owner" binding:"omitempty,email" example:"finops@example.com"`
+	AuditRetentionDays int               `json:"audit_retention_days" binding:"min=0" example:"365"`
+	// HistoryRetentionDays shortens the retention of the plan; zero uses it
+	HistoryRetentionDays int      `json:"history_retention_days" binding:"min=0" example:"90"`
+	TerraformStates      []string `json:"terraform_states" example:"tfstate/prod.tfstate"`
+	// GitOpsRepos map state files to the repository where pull requests
+	// removing their resources are opened
+	GitOpsRepos map[string]GitOpsRepoDTO `json:"gitops_repos" binding:"omitempty,dive"`
+	// AllocationTagKeys are the tags costs are allocated by in showback
+	AllocationTagKeys []string `json:"allocation_tag_keys" binding:"max=10,dive,required,max=128" example:"team,project"`
+	// Notifications are kept unchanged when omitted
+	Notifications *NotificationSettingsRequest `json:"notifications"`
+	// OwnerNotifications are kept unchanged when omitted
+	OwnerNotifications *OwnerNotificationsRequest `json:"owner_notifications"`
+	// WeeklySummary is kept unchanged when omitted
+	WeeklySummary *WeeklySummaryRequest `json:"weekly_summary"`
+	// BackupBeforeDelete is kept unchanged when omitted
+	BackupBeforeDelete *BackupSettingsRequest `json:"backup_before_delete"`
+}
+
+// BackupSettingsRequest configures the backups taken before cleanups delete
+// volumes, disks and databases
+type BackupSettingsRequest struct {
+	Enabled bool `json:"enabled" example:"true"`
+	// RetentionDays is how long backups are kept; zero uses the default of
+	// 30 days
+	RetentionDays int `json:"retention_days" binding:"min=0,max=3650" example:"30"`
+}
+
+// WeeklySummaryRequest schedules the weekly summary emailed to the admins
+// of an organization, in its local time
+type WeeklySummaryRequest struct {
+	Disabled bool   `json:"disabled" example:"false"`
+	Weekday  string `json:"weekday" binding:"omitempty,oneof=monday tuesday wednesday thursday friday saturday sunday" example:"monday"`
+	Hour     *int   `json:"hour" binding:"omitempty,min=0,max=23" example:"8"`
+	Timezone string `json:"timezone" example:"Europe/Paris"`
+}
+
+// OwnerNotificationsRequest configures how the owners of the resources of
+// policies with the notify action are told about them
+type OwnerNotificationsRequest struct {
+	// Channels are email, slack or both; email when empty
+	Channels []string `json:"channels" binding:"omitempty,dive,oneof=email slack" example:"email,slack"`
+	// Digest sends one message per owner and day
+	Digest bool `json:"digest" example:"true"`
+}
+
+// NotificationSettingsRequest routes events to the notification channels of
+// an organization. The webhook URL and the routing key are kept when left
+// empty; a channel no event is routed to is removed.
+type NotificationSettingsRequest struct {
+	TeamsWebhookURL     string `json:"teams_webhook_url" binding:"omitempty,url,startswith=https://" example:"https://acme.webhook.office.com/webhookb2/..."`
+	PagerDutyRoutingKey string `json:"pagerduty_routing_key" binding:"omitempty,len=32" example:"e93facc04764012d7bfb002500d5d1a6"`
+	// Routes map events (scan.finished, cost.anomaly, policy.matched) to
+	// channels (teams, pagerduty)
+	Routes                  map[string][]string `json:"routes"`
+	AnomalyThresholdPercent int                 `json:"anomaly_threshold_percent" binding:"min=0" example:"50"`
+	AnomalyMinIncrease      float64             `json:"anomaly_min_increase" binding:"min=0" example:"100"`
+}
+
+// CreateOrganizationRequest represents a request to create an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255" example:"Acme"`
+	Slug string `json:"slug" binding:"required" example:"acme"`
+	Plan string `json:"plan" binding:"omitempty,oneof=free pro enterprise" example:"pro"`
+}
+
+// UpdateOrganizationRequest represents a request to update an organization
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255" example:"Acme"`
+	Slug string `json:"slug" binding:"required" example:"acme"`
+	Plan string `json:"plan" binding:"required,oneof=free pro enterprise" example:"enterprise"`
+}
+
+// ListOrganizationsRequest represents query parameters for listing organizations
+type ListOrganizationsRequest struct {
+	IsActive *bool `form:"is_active" example:"true"`
+	Limit    int   `form:"limit,default=20" example:"20"`
+	Offset   int   `form:"offset,default=0" example:"0"`
+}
+
+// OnboardOrganizationRequest represents a request to create an organization
+// with its first admin and the people invited to join it
+type OnboardOrganizationRequest struct {
+	CreateOrganizationRequest
+	Admin   OnboardingAdminRequest `json:"admin" binding:"required"`
+	Invites []InviteRequest        `json:"invites" binding:"omitempty,max=100,dive"`
+}
+
+// OnboardingAdminRequest represents the first admin of an organization
+type OnboardingAdminRequest struct {
+	Email string `json:"email" binding:"required,email" example:"jane@acme.com"`
+	Name  string `json:"name" binding:"max=255" example:"Jane Doe"`
+}
+
+// InviteRequest represents a person invited to join an organization
+type InviteRequest struct {
+	Email string `json:"email" binding:"required,email" example:"john@acme.com"`
+	Role  string `json:"role" binding:"required,oneof=admin member viewer" example:"member"`
+}
+
+// Create godoc
+//
+//	@Summary		Create organization
+//	@Description	Create an organization. Slugs are unique, made of 3 to 100 lowercase letters, digits and single hyphens. The plan defaults to free.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateOrganizationRequest	true	"Organization"
+//	@Success		201		{object}	DataResponse[OrganizationDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations [post]
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if err := entity.ValidateSlug(req.Slug); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var org *model.Organization
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		org, err = createOrganization(tx, req)
+		return err
+	})
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dataResponse(toOrganizationDTO(org)))
+}
+
+// Onboard godoc
+//
+//	@Summary		Onboard organization
+//	@Description	Create an organization together with its first admin user and the invitations of the people joining it, in a single transaction. Existing users are made admin of the new organization, and the invitation links are emailed once it is created.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		OnboardOrganizationRequest	true	"Onboarding"
+//	@Success		201		{object}	DataResponse[OnboardingDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/onboard [post]
+func (h *OrganizationHandler) Onboard(c *gin.Context) {
+	var req OnboardOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if err := entity.ValidateSlug(req.Slug); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	adminEmail := strings.ToLower(req.Admin.Email)
+	seen := map[string]bool{adminEmail: true}
+	for _, invite := range req.Invites {
+		email := strings.ToLower(invite.Email)
+		if seen[email] {
+			apierror.Respond(c, http.StatusBadRequest, "duplicate email in invites: "+invite.Email)
+			return
+		}
+		seen[email] = true
+	}
+
+	var (
+		org         *model.Organization
+		admin       model.User
+		invitations []issuedInvitation
+	)
+	err := h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if org, err = createOrganization(tx, req.CreateOrganizationRequest); err != nil {
+			return err
+		}
+
+		err = tx.Where(model.User{Email: adminEmail}).
+			Attrs(model.User{Name: req.Admin.Name}).
+			FirstOrCreate(&admin).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&model.Membership{
+			OrganizationID: org.ID,
+			UserID:         admin.ID,
+			Role:           string(entity.MemberRoleAdmin),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, invite := range req.Invites {
+			inv, err := issueInvitation(tx, org.ID, strings.ToLower(invite.Email), entity.MemberRole(invite.Role), adminEmail, h.invitations.TTL)
+			if err != nil {
+				return err
+			}
+			invitations = append(invitations, inv)
+		}
+		return nil
+	})
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	dto := OnboardingDTO{
+		Organization: toOrganizationDTO(org),
+		Admin:        toUserDTO(&admin),
+		Invitations:  make([]InvitationDTO, 0, len(invitations)),
+	}
+	for i := range invitations {
+		// The organization exists already; invitations whose email could
+		// not be queued can be sent again from the members endpoint
+		_ = sendInvitation(c.Request.Context(), h.queueClient, h.invitations, org.Name, invitations[i])
+		dto.Invitations = append(dto.Invitations, toInvitationDTO(&invitations[i].Invitation))
+	}
+	c.JSON(http.StatusCreated, dataResponse(dto))
+}
+
+// List godoc
+//
+//	@Summary		List organizations
+//	@Description	Get a paginated list of organizations, oldest first
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			is_active	query		boolean	false	"Filter by active status"
+//	@Param			limit		query		int		false	"Number of items per page"	default(20)
+//	@Param			offset		query		int		false	"Number of items to skip"	default(0)
+//	@Success		200			{object}	PaginatedResponse{data=[]OrganizationDTO}
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/organizations [get]
+func (h *OrganizationHandler) List(c *gin.Context) {
+	var req ListOrganizationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.Organization{})
+	if req.IsActive != nil {
+		query = query.Where("is_active = ?", *req.IsActive)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var orgs []model.Organization
+	if err := query.Limit(req.Limit).Offset(req.Offset).Order("created_at, id").Find(&orgs).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch organizations")
+		return
+	}
+
+	data := make([]OrganizationDTO, 0, len(orgs))
+	for i := range orgs {
+		data = append(data, toOrganizationDTO(&orgs[i]))
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   data,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+// Get godoc
+//
+//	@Summary		Get organization
+//	@Description	Get an organization by ID
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[OrganizationDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id} [get]
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var org model.Organization
+	if err := h.db.WithContext(c.Request.Context()).First(&org, "id = ?", id).Error; err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toOrganizationDTO(&org)))
+}
+
+// Update godoc
+//
+//	@Summary		Update organization
+//	@Description	Rename an organization, change its slug or its plan
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Organization ID"	format(uuid)
+//	@Param			request	body		UpdateOrganizationRequest	true	"Organization"
+//	@Success		200		{object}	DataResponse[OrganizationDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id} [put]
+func (h *OrganizationHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if err := entity.ValidateSlug(req.Slug); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var org model.Organization
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&org, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := checkSlugAvailable(tx, req.Slug, org.ID); err != nil {
+			return err
+		}
+		org.Name, org.Slug, org.Plan = req.Name, req.Slug, req.Plan
+		return tx.Model(&org).Select("name", "slug", "plan").Updates(&org).Error
+	})
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toOrganizationDTO(&org)))
+}
+
+// Deactivate godoc
+//
+//	@Summary		Deactivate organization
+//	@Description	Soft-deactivate an organization: its data is kept, its policies are disabled and its pending or running scans are cancelled. New scans and enabled policies are refused until it is reactivated.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id} [delete]
+func (h *OrganizationHandler) Deactivate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Organization{}).Where("id = ? AND is_active = ?", id, true).Updates(map[string]any{
+			"is_active":      false,
+			"deactivated_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Already deactivated organizations are left as they are
+			var count int64
+			if err := tx.Model(&model.Organization{}).Where("id = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return nil
+		}
+
+		if err := tx.Model(&model.Policy{}).Where("organization_id = ?", id).
+			Updates(map[string]any{"is_enabled": false, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Scan{}).
+			Where("organization_id = ? AND status IN ?", id, []string{string(entity.ScanStatusPending), string(entity.ScanStatusRunning)}).
+			Updates(map[string]any{"status": string(entity.ScanStatusCancelled), "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+	h.cache.Invalidate(c.Request.Context(), id.String())
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "organization deactivated"})
+}
+
+// Usage godoc
+//
+//	@Summary		Get plan usage
+//	@Description	Get the consumption of an organization against the quotas of its plan: cloud accounts, scans today (reset at midnight UTC), resources tracked and history retention
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[UsageDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/usage [get]
+func (h *OrganizationHandler) Usage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var org model.Organization
+	if err := h.db.WithContext(c.Request.Context()).First(&org, "id = ?", id).Error; err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	now := time.Now()
+	usage, err := loadUsage(c.Request.Context(), h.db, org.ID, now)
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to compute usage")
+		return
+	}
+
+	quotas := entity.QuotasFor(org.Plan)
+	dto := UsageDTO{
+		OrganizationID: org.ID.String(),
+		Plan:           org.Plan,
+		Upgrade:        entity.UpgradeFor(org.Plan),
+		ScansResetAt:   entity.StartOfQuotaDay(now).Add(24 * time.Hour),
+	}
+	for _, quota := range []entity.Quota{entity.QuotaCloudAccounts, entity.QuotaScansPerDay, entity.QuotaResources, entity.QuotaRetentionDays} {
+		limit := quotas.Limit(quota)
+		used := usage[quota]
+		dto.Quotas = append(dto.Quotas, QuotaUsageDTO{
+			Quota:     string(quota),
+			Used:      used,
+			Limit:     limit,
+			Exceeded:  limit > 0 && used > int64(limit),
+			Unlimited: limit == 0,
+		})
+	}
+	c.JSON(http.StatusOK, dataResponse(dto))
+}
+
+// GetSettings godoc
+//
+//	@Summary		Get organization settings
+//	@Description	Get the default scan regions, region denylist, owner attribution rules and audit log retention of an organization
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[OrganizationSettingsDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/settings [get]
+func (h *OrganizationHandler) GetSettings(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	ctx := c.Request.Context()
+	settings, err := cache.Fetch(ctx, h.cache, id.String(), "organization.settings", "", h.settingsTTL, func() (OrganizationSettingsDTO, error) {
+		var org model.Organization
+		if err := h.db.WithContext(ctx).First(&org, "id = ?", id).Error; err != nil {
+			return OrganizationSettingsDTO{}, err
+		}
+		return toOrganizationSettingsDTO(&org), nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "organization not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(settings))
+}
+
+// UpdateSettings godoc
+//
+//	@Summary		Update organization settings
+//	@Description	Replace the settings of an organization. Denylist entries ending with "*" match by prefix.
+//	@Description	Owner tag keys are tried in order to attribute resources to an owner; values are mapped through owner aliases or used as-is when they are email addresses. owner_notifications sets how owners are told about the resources of policies with the notify action: by email, Slack direct message or both, at once or in a daily digest.
+//	@Description	weekly_summary sets the day, hour and timezone of the weekly summary emailed to admins (Monday 8:00 UTC by default); it is kept when omitted. backup_before_delete makes deletions snapshot volumes, disks and databases first and keep the snapshots retention_days (30 by default); it is kept when omitted.
+//	@Description	Notifications route events (scan.finished, cost.anomaly, policy.matched) to the teams and pagerduty channels; they are kept when omitted, as are the webhook URL and routing key when left empty.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string								true	"Organization ID"	format(uuid)
+//	@Param			request	body		UpdateOrganizationSettingsRequest	true	"Settings"
+//	@Success		200		{object}	DataResponse[OrganizationSettingsDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/settings [put]
+func (h *OrganizationHandler) UpdateSettings(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req UpdateOrganizationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	for _, pattern := range req.RegionDenylist {
+		if strings.TrimSpace(strings.TrimSuffix(pattern, "*")) == "" {
+			apierror.Respond(c, http.StatusBadRequest, "region denylist entries must not be empty")
+			return
+		}
+	}
+
+	settings := entity.OrganizationSettings{
+		DefaultRegions: req.DefaultRegions,
+		RegionDenylist: req.RegionDenylist,
+	}
+	if denied := settings.DeniedRegions(req.DefaultRegions); len(denied) > 0 {
+		apierror.Respond(c, http.StatusBadRequest, "default regions are denylisted: "+strings.Join(denied, ", "))
+		return
+	}
+
+	aliases := model.JSONB{}
+	for value, email := range req.OwnerAliases {
+		aliases[value] = email
+	}
+
+	repos := model.JSONB{}
+	for state, repo := range req.GitOpsRepos {
+		if !slices.Contains(req.TerraformStates, state) {
+			apierror.Respond(c, http.StatusBadRequest, "gitops repositories must map terraform states: "+state)
+			return
+		}
+		repos[state] = map[string]any{
+			"provider": repo.Provider,
+			"repo":     repo.Repo,
+			"branch":   repo.Branch,
+			"path":     strings.Trim(repo.Path, "/"),
+		}
+	}
+
+	updates := map[string]any{
+		"default_regions":        model.StringArray(req.DefaultRegions),
+		"region_denylist":        model.StringArray(req.RegionDenylist),
+		"owner_tag_keys":         model.StringArray(req.OwnerTagKeys),
+		"owner_aliases":          aliases,
+		"default_owner":          req.DefaultOwner,
+		"audit_retention_days":   req.AuditRetentionDays,
+		"history_retention_days": req.HistoryRetentionDays,
+		"terraform_states":       model.StringArray(req.TerraformStates),
+		"gitops_repos":           repos,
+		"allocation_tag_keys":    model.StringArray(req.AllocationTagKeys),
+	}
+	if req.Notifications != nil {
+		var current model.Organization
+		if err := h.db.WithContext(c.Request.Context()).Select("id", "notifications").First(&current, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				apierror.Respond(c, http.StatusNotFound, "organization not found")
+				return
+			}
+			apierror.Respond(c, http.StatusInternalServerError, "failed to update organization settings")
+			return
+		}
+		notifications, err := notificationSettings(req.Notifications, current.Settings().Notifications)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		updates["notifications"] = notifications
+	}
+	if req.OwnerNotifications != nil {
+		channels := slices.Clone(req.OwnerNotifications.Channels)
+		slices.Sort(channels)
+		channels = slices.Compact(channels)
+		updates["owner_notifications"] = model.JSONB{
+			"channels": channels,
+			"digest":   req.OwnerNotifications.Digest,
+		}
+	}
+	if req.WeeklySummary != nil {
+		summary := entity.WeeklySummary{
+			Disabled: req.WeeklySummary.Disabled,
+			Weekday:  req.WeeklySummary.Weekday,
+			Hour:     req.WeeklySummary.Hour,
+			Timezone: req.WeeklySummary.Timezone,
+		}
+		if err := summary.Validate(); err != nil {
+			apierror.Respond(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		updates["weekly_summary"] = model.JSONB{
+			"disabled": summary.Disabled,
+			"weekday":  summary.Weekday,
+			"hour":     summary.Hour,
+			"timezone": summary.Timezone,
+		}
+	}
+
+	if req.BackupBeforeDelete != nil {
+		updates["backup_before_delete"] = model.JSONB{
+			"enabled":        req.BackupBeforeDelete.Enabled,
+			"retention_days": req.BackupBeforeDelete.RetentionDays,
+		}
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Model(&model.Organization{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update organization settings")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "organization not found")
+		return
+	}
+	h.cache.Invalidate(c.Request.Context(), id.String())
+
+	var org model.Organization
+	h.db.WithContext(c.Request.Context()).First(&org, "id = ?", id)
+
+	c.JSON(http.StatusOK, dataResponse(toOrganizationSettingsDTO(&org)))
+}
+
+// Reactivate godoc
+//
+//	@Summary		Reactivate organization
+//	@Description	Reactivate a deactivated organization. Its policies stay disabled until enabled again.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/reactivate [post]
+func (h *OrganizationHandler) Reactivate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Model(&model.Organization{}).Where("id = ?", id).Updates(map[string]any{
+		"is_active":      true,
+		"deactivated_at": nil,
+	})
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update organization")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "organization not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "organization reactivated"})
+}
+
+// errSlugTaken is returned when another organization uses a slug
+var errSlugTaken = apperrors.NewWithCode(apperrors.ErrAlreadyExists, apperrors.CodeAlreadyExists, "slug is already taken")
+
+// createOrganization creates an active organization, checking its slug is
+// not taken
+func createOrganization(tx *gorm.DB, req CreateOrganizationRequest) (*model.Organization, error) {
+	if err := checkSlugAvailable(tx, req.Slug, uuid.Nil); err != nil {
+		return nil, err
+	}
+	plan := req.Plan
+	if plan == "" {
+		plan = entity.PlanFree
+	}
+	org := &model.Organization{
+		ID:       uuid.New(),
+		Name:     req.Name,
+		Slug:     req.Slug,
+		Plan:     plan,
+		IsActive: true,
+	}
+	if err := tx.Create(org).Error; err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// checkSlugAvailable returns errSlugTaken when an organization other than
+// exceptID uses the slug
+func checkSlugAvailable(tx *gorm.DB, slug string, exceptID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&model.Organization{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errSlugTaken
+	}
+	return nil
+}
+
+// respondOrganizationError maps the errors of organization changes to
+// responses
+func respondOrganizationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Respond(c, http.StatusNotFound, "organization not found")
+	case errors.Is(err, errSlugTaken):
+		apierror.RespondError(c, err)
+	default:
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update organization")
+	}
+}
+
+// requireActiveOrganization responds with an error and returns false when
+// an organization does not exist or was deactivated
+func requireActiveOrganization(c *gin.Context, db *gorm.DB, orgID uuid.UUID) bool {
+	var org model.Organization
+	if err := db.WithContext(c.Request.Context()).Select("is_active").First(&org, "id = ?", orgID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "organization not found")
+			return false
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch organization")
+		return false
+	}
+	if !org.IsActive {
+		apierror.Respond(c, http.StatusConflict, "organization is deactivated")
+		return false
+	}
+	return true
+}
+
+func toOrganizationDTO(org *model.Organization) OrganizationDTO {
+	return OrganizationDTO{
+		ID:            org.ID.String(),
+		Name:          org.Name,
+		Slug:          org.Slug,
+		Plan:          org.Plan,
+		IsActive:      org.IsActive,
+		DeactivatedAt: org.DeactivatedAt,
+		CreatedAt:     org.CreatedAt,
+		UpdatedAt:     org.UpdatedAt,
+	}
+}
+
+func toUserDTO(u *model.User) UserDTO {
+	return UserDTO{ID: u.ID.String(), Email: u.Email, Name: u.Name}
+}
+
+func toInvitationDTO(inv *model.Invitation) InvitationDTO {
+	return InvitationDTO{
+		ID:         inv.ID.String(),
+		Email:      inv.Email,
+		Role:       inv.Role,
+		InvitedBy:  inv.InvitedBy,
+		ExpiresAt:  inv.ExpiresAt,
+		AcceptedAt: inv.AcceptedAt,
+	}
+}
+
+// notificationSettings merges requested notification settings with the
+// current ones, keeping the secrets left empty and dropping the channels no
+// event is routed to
+func notificationSettings(req *NotificationSettingsRequest, current entity.NotificationSettings) (model.JSONB, error) {
+	settings := entity.NotificationSettings{
+		TeamsWebhookURL:         req.TeamsWebhookURL,
+		PagerDutyRoutingKey:     req.PagerDutyRoutingKey,
+		Routes:                  req.Routes,
+		AnomalyThresholdPercent: req.AnomalyThresholdPercent,
+		AnomalyMinIncrease:      req.AnomalyMinIncrease,
+	}
+	if settings.TeamsWebhookURL == "" {
+		settings.TeamsWebhookURL = current.TeamsWebhookURL
+	}
+	if settings.PagerDutyRoutingKey == "" {
+		settings.PagerDutyRoutingKey = current.PagerDutyRoutingKey
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	routed := map[string]bool{}
+	for _, channels := range settings.Routes {
+		for _, channel := range channels {
+			routed[channel] = true
+		}
+	}
+	if !routed[entity.NotificationChannelTeams] {
+		settings.TeamsWebhookURL = ""
+	}
+	if !routed[entity.NotificationChannelPagerDuty] {
+		settings.PagerDutyRoutingKey = ""
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var notifications model.JSONB
+	err = json.Unmarshal(data, &notifications)
+	return notifications, err
+}
+
+func toOrganizationSettingsDTO(org *model.Organization) OrganizationSettingsDTO {
+	settings := org.Settings()
+	dto := OrganizationSettingsDTO{
+		OrganizationID:       org.ID.String(),
+		DefaultRegions:       settings.DefaultRegions,
+		RegionDenylist:       settings.RegionDenylist,
+		OwnerTagKeys:         settings.OwnerRules.TagKeys,
+		OwnerAliases:         settings.OwnerRules.Aliases,
+		DefaultOwner:         settings.OwnerRules.DefaultOwner,
+		AuditRetentionDays:   settings.AuditRetentionDays,
+		HistoryRetentionDays: entity.HistoryRetentionDays(org.Plan, settings.HistoryRetentionDays),
+		TerraformStates:      settings.TerraformStates,
+		AllocationTagKeys:    settings.AllocationTagKeys,
+	}
+	if len(settings.GitOpsRepos) > 0 {
+		dto.GitOpsRepos = make(map[string]GitOpsRepoDTO, len(settings.GitOpsRepos))
+		for state, repo := range settings.GitOpsRepos {
+			dto.GitOpsRepos[state] = GitOpsRepoDTO{Provider: repo.Provider, Repo: repo.Repo, Branch: repo.Branch, Path: repo.Path}
+		}
+	}
+	if dto.DefaultRegions == nil {
+		dto.DefaultRegions = []string{}
+	}
+	if dto.RegionDenylist == nil {
+		dto.RegionDenylist = []string{}
+	}
+	if dto.TerraformStates == nil {
+		dto.TerraformStates = []string{}
+	}
+	if dto.OwnerTagKeys == nil {
+		dto.OwnerTagKeys = entity.DefaultOwnerTagKeys
+	}
+	dto.Notifications = toNotificationSettingsDTO(settings.Notifications)
+	dto.OwnerNotifications = OwnerNotificationsDTO{
+		Channels: settings.OwnerNotifications.NotifyChannels(),
+		Digest:   settings.OwnerNotifications.Digest,
+	}
+	dto.WeeklySummary = WeeklySummaryDTO{
+		Enabled:  !settings.WeeklySummary.Disabled,
+		Weekday:  settings.WeeklySummary.Weekday,
+		Hour:     entity.DefaultWeeklySummaryHour,
+		Timezone: settings.WeeklySummary.Location().String(),
+	}
+	if dto.WeeklySummary.Weekday == "" {
+		dto.WeeklySummary.Weekday = entity.DefaultWeeklySummaryWeekday
+	}
+	if settings.WeeklySummary.Hour != nil {
+		dto.WeeklySummary.Hour = *settings.WeeklySummary.Hour
+	}
+	dto.BackupBeforeDelete = BackupSettingsDTO{
+		Enabled:       settings.BackupBeforeDelete.Enabled,
+		RetentionDays: int(settings.BackupBeforeDelete.Retention() / (24 * time.Hour)),
+	}
+	return dto
+}
+
+func toNotificationSettingsDTO(s entity.NotificationSettings) NotificationSettingsDTO {
+	dto := NotificationSettingsDTO{
+		TeamsConfigured:         s.TeamsWebhookURL != "",
+		PagerDutyConfigured:     s.PagerDutyRoutingKey != "",
+		Routes:                  s.Routes,
+		AnomalyThresholdPercent: s.AnomalyThresholdPercent,
+		AnomalyMinIncrease:      s.AnomalyMinIncrease,
+	}
+	if dto.Routes == nil {
+		dto.Routes = map[string][]string{}
+	}
+	if dto.AnomalyThresholdPercent == 0 {
+		dto.AnomalyThresholdPercent = entity.DefaultAnomalyThresholdPercent
+	}
+	if dto.AnomalyMinIncrease == 0 {
+		dto.AnomalyMinIncrease = entity.DefaultAnomalyMinIncrease
+	}
+	return dto
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// maxOwnerMappingSize is the largest owner mapping file accepted, in bytes
+const maxOwnerMappingSize = 5 << 20
+
+// OwnerHandler handles the owner mapping file of organizations, which
+// attributes resources to their owner ahead of tags and default owners
+type OwnerHandler struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+}
+
+// NewOwnerHandler creates a new OwnerHandler
+func NewOwnerHandler(db *gorm.DB, queueClient *asynq.Client) *OwnerHandler {
+	return &OwnerHandler{db: db, queueClient: queueClient}
+}
+
+// ListMappings godoc
+//
+//	@Summary		List owner mappings
+//	@Description	List the rows of the owner mapping file of an organization, in file order.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[[]OwnerMappingDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/owner-mappings [get]
+func (h *OwnerHandler) ListMappings(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	if !h.organizationExists(c, orgID) {
+		return
+	}
+
+	var mappings []model.OwnerMapping
+	if err := h.db.WithContext(c.Request.Context()).Where("organization_id = ?", orgID).Order("position").Find(&mappings).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch owner mappings")
+		return
+	}
+
+	dtos := make([]OwnerMappingDTO, len(mappings))
+	for i, m := range mappings {
+		dtos[i] = OwnerMappingDTO{Position: m.Position, Pattern: m.Pattern, Owner: m.Owner}
+	}
+	c.JSON(http.StatusOK, dataResponse(dtos))
+}
+
+// UpdateMappings godoc
+//
+//	@Summary		Upload owner mapping file
+//	@Description	Replace the owner mapping file of an organization with a CSV of "resource,owner" rows, with an optional header. Resources are cloud resource IDs; those ending with "*" match by prefix. The first matching row wins, ahead of owner tags and default owners. An empty file removes the mappings.
+//	@Tags			Organizations
+//	@Accept			text/csv
+//	@Produce		json
+//	@Param			id		path		string	true	"Organization ID"	format(uuid)
+//	@Param			file	body		string	true	"Owner mapping file"
+//	@Success		200		{object}	DataResponse[[]OwnerMappingDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/owner-mappings [put]
+func (h *OwnerHandler) UpdateMappings(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	mappings, err := parseOwnerMappings(http.MaxBytesReader(c.Writer, c.Request.Body, maxOwnerMappingSize))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.organizationExists(c, orgID) {
+		return
+	}
+
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ?", orgID).Delete(&model.OwnerMapping{}).Error; err != nil {
+			return err
+		}
+		if len(mappings) == 0 {
+			return nil
+		}
+		for i := range mappings {
+			mappings[i].OrganizationID = orgID
+		}
+		return tx.CreateInBatches(&mappings, 500).Error
+	})
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update owner mappings")
+		return
+	}
+
+	if err := queue.EnqueueOwnerAssignment(c.Request.Context(), h.queueClient, orgID.String()); err != nil {
+		log.Printf("Failed to enqueue owner assignment of org %s: %v", orgID, err)
+	}
+
+	dtos := make([]OwnerMappingDTO, len(mappings))
+	for i, m := range mappings {
+		dtos[i] = OwnerMappingDTO{Position: m.Position, Pattern: m.Pattern, Owner: m.Owner}
+	}
+	c.JSON(http.StatusOK, dataResponse(dtos))
+}
+
+// organizationExists responds 404 when the organization does not exist
+func (h *OwnerHandler) organizationExists(c *gin.Context, orgID uuid.UUID) bool {
+	var org model.Organization
+	if err := h.db.WithContext(c.Request.Context()).Select("id").First(&org, "id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Respond(c, http.StatusNotFound, "organization not found")
+			return false
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch organization")
+		return false
+	}
+	return true
+}
+
+// parseOwnerMappings reads the rows of an owner mapping file. A first row
+// whose owner is not an email address is taken as the header.
+func parseOwnerMappings(r io.Reader) ([]model.OwnerMapping, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var mappings []model.OwnerMapping
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid owner mapping file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != 2 {
+			return nil, fmt.Errorf("line %d: expected 2 columns (resource,owner), got %d", line, len(record))
+		}
+		pattern, owner := strings.TrimSpace(record[0]), strings.ToLower(strings.TrimSpace(record[1]))
+		if _, err := mail.ParseAddress(owner); err != nil || strings.ContainsAny(owner, "<> ") {
+			if len(mappings) == 0 && line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: owner %q is not an email address", line, record[1])
+		}
+		if pattern == "" || pattern == "*" {
+			return nil, fmt.Errorf("line %d: resource is required", line)
+		}
+		mappings = append(mappings, model.OwnerMapping{Position: len(mappings) + 1, Pattern: pattern, Owner: owner})
+	}
+	return mappings, nil
+}
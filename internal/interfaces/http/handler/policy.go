@@ -1,22 +1,32 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
+	"github.com/robfig/cron/v3"
 )
 
 // PolicyHandler handles policy endpoints
 type PolicyHandler struct {
-	db *gorm.DB
+	policies usecase.PolicyService
 }
 
 // NewPolicyHandler creates a new PolicyHandler
-func NewPolicyHandler(db *gorm.DB) *PolicyHandler {
-	return &PolicyHandler{db: db}
+func NewPolicyHandler(policies usecase.PolicyService) *PolicyHandler {
+	return &PolicyHandler{policies: policies}
 }
 
 // CreatePolicyRequest represents a request to create a new policy
@@ -24,57 +34,125 @@ type CreatePolicyRequest struct {
 	OrganizationID string         `json:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
 	Name           string         `json:"name" binding:"required" example:"Delete unused EBS volumes"`
 	Description    string         `json:"description" example:"Automatically delete EBS volumes unused for 30 days"`
-	Provider       string         `json:"provider" binding:"required,oneof=aws azure gcp" example:"aws"`
+	Provider       string         `json:"provider" binding:"required,provider" example:"aws"`
 	ResourceTypes  []string       `json:"resource_types" example:"ebs_volume,ebs_snapshot"`
 	Conditions     map[string]any `json:"conditions"`
 	Actions        []string       `json:"actions" binding:"required,min=1" example:"notify,delete"`
 	Schedule       string         `json:"schedule" example:"0 0 * * *"`
+	// OffHours is required by the "schedule" action
+	OffHours *OffHoursRequest `json:"off_hours"`
+	// ViewID narrows the policy down to the resources of a saved view
+	ViewID string `json:"view_id" example:"550e8400-e29b-41d4-a716-446655440002"`
+	// Version is the version of the policy an update was made from,
+	// rejected with a 409 if it was updated since. The If-Match header may
+	// be used instead; without either the update is unconditional.
+	Version *int `json:"version,omitempty" example:"3"`
+}
+
+// policyETag returns the ETag of a version of a policy
+func policyETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion returns the version of the policy an If-Match header asks
+// for, zero without the header or with "*"
+func ifMatchVersion(c *gin.Context) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid If-Match header %q, expected the ETag of the policy", header)
+	}
+	return version, nil
+}
+
+// OffHoursRequest is when a "schedule" policy stops and restarts its
+// resources. Empty fields default to weekday nights and weekends in UTC.
+type OffHoursRequest struct {
+	Timezone string `json:"timezone" example:"Europe/Paris"`
+	Stop     string `json:"stop" example:"0 19 * * 1-5"`
+	Start    string `json:"start" example:"0 8 * * 1-5"`
+}
+
+func toPolicyDTO(p *entity.Policy) PolicyDTO {
+	var conditions map[string]any
+	raw, _ := json.Marshal(p.Conditions)
+	_ = json.Unmarshal(raw, &conditions)
+
+	dto := PolicyDTO{
+		ID:             p.ID.String(),
+		OrganizationID: p.OrganizationID.String(),
+		Name:           p.Name,
+		Description:    p.Description,
+		Provider:       string(p.Provider),
+		Conditions:     conditions,
+		IsEnabled:      p.IsEnabled,
+		Schedule:       p.Schedule,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+		Version:        p.Version,
+	}
+	for _, t := range p.ResourceTypes {
+		dto.ResourceTypes = append(dto.ResourceTypes, string(t))
+	}
+	for _, a := range p.Actions {
+		dto.Actions = append(dto.Actions, string(a))
+	}
+	if s := p.OffHours; s != nil {
+		dto.OffHours = &OffHoursRequest{Timezone: s.Timezone, Stop: s.Stop, Start: s.Start}
+	}
+	if p.ViewID != nil {
+		id := p.ViewID.String()
+		dto.ViewID = &id
+	}
+	if p.ExternalID != "" {
+		dto.ExternalID = &p.ExternalID
+	}
+	return dto
 }
 
 // Create godoc
 //
 //	@Summary		Create policy
-//	@Description	Create a new cleanup policy
+//	@Description	Create a new cleanup policy. Conditions must only use known keys with values of the right type, the schedule must be a standard cron expression and actions must apply to the selected resource types (e.g. stop only applies to instances). The schedule action stops instances in the off_hours stop window and restarts them in its start window, evaluated in its timezone. view_id narrows the policy down to the resources of a saved view of the organization.
 //	@Tags			Policies
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		CreatePolicyRequest	true	"Policy request"
-//	@Success		201		{object}	map[string]PolicyDTO
+//	@Success		201		{object}	DataResponse[PolicyDTO]
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/policies [post]
 func (h *PolicyHandler) Create(c *gin.Context) {
 	var req CreatePolicyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
 
 	orgID, err := uuid.Parse(req.OrganizationID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid organization ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
 		return
 	}
-
-	policy := model.Policy{
-		ID:             uuid.New(),
-		OrganizationID: orgID,
-		Name:           req.Name,
-		Description:    req.Description,
-		Provider:       req.Provider,
-		ResourceTypes:  req.ResourceTypes,
-		Conditions:     req.Conditions,
-		Actions:        req.Actions,
-		Schedule:       req.Schedule,
-		IsEnabled:      true,
+	policy, err := policyFromRequest(&req, orgID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	if err := h.db.Create(&policy).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to create policy"})
+	policy, err = h.policies.Create(c.Request.Context(), policy)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"data": policy})
+	c.Header("ETag", policyETag(policy.Version))
+	c.JSON(http.StatusCreated, dataResponse(toPolicyDTO(policy)))
 }
 
 // ListPoliciesRequest represents query parameters for listing policies
@@ -83,6 +161,8 @@ type ListPoliciesRequest struct {
 	IsEnabled *bool  `form:"is_enabled" example:"true"`
 	Limit     int    `form:"limit,default=20" example:"20"`
 	Offset    int    `form:"offset,default=0" example:"0"`
+	// Deleted lists the deleted policies instead, which can be restored
+	Deleted bool `form:"deleted" example:"false"`
 }
 
 // List godoc
@@ -92,8 +172,9 @@ type ListPoliciesRequest struct {
 //	@Tags			Policies
 //	@Accept			json
 //	@Produce		json
-//	@Param			provider	query		string	false	"Filter by cloud provider"	Enums(aws, azure, gcp)
+//	@Param			provider	query		string	false	"Filter by cloud provider"
 //	@Param			is_enabled	query		boolean	false	"Filter by enabled status"
+//	@Param			deleted		query		boolean	false	"List the deleted policies, which can be restored"
 //	@Param			limit		query		int		false	"Number of items per page"	default(20)
 //	@Param			offset		query		int		false	"Number of items to skip"	default(0)
 //	@Success		200			{object}	PaginatedResponse{data=[]PolicyDTO}
@@ -103,30 +184,34 @@ type ListPoliciesRequest struct {
 func (h *PolicyHandler) List(c *gin.Context) {
 	var req ListPoliciesRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
 
-	query := h.db.Model(&model.Policy{})
-
-	if req.Provider != "" {
-		query = query.Where("provider = ?", req.Provider)
+	filter := repository.PolicyFilter{
+		IsEnabled: req.IsEnabled,
+		Deleted:   req.Deleted,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
 	}
-	if req.IsEnabled != nil {
-		query = query.Where("is_enabled = ?", *req.IsEnabled)
+	if req.Provider != "" {
+		provider := entity.CloudProvider(req.Provider)
+		filter.Provider = &provider
 	}
 
-	var total int64
-	query.Count(&total)
-
-	var policies []model.Policy
-	if err := query.Limit(req.Limit).Offset(req.Offset).Order("created_at DESC").Find(&policies).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch policies"})
+	policies, total, err := h.policies.List(c.Request.Context(), filter)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
+	data := make([]PolicyDTO, len(policies))
+	for i, p := range policies {
+		data[i] = toPolicyDTO(p)
+	}
+
 	c.JSON(http.StatusOK, PaginatedResponse{
-		Data:   policies,
+		Data:   data,
 		Total:  total,
 		Limit:  req.Limit,
 		Offset: req.Offset,
@@ -141,7 +226,7 @@ func (h *PolicyHandler) List(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		string	true	"Policy ID"	format(uuid)
-//	@Success		200	{object}	map[string]PolicyDTO
+//	@Success		200	{object}	DataResponse[PolicyDTO]
 //	@Failure		400	{object}	ErrorResponse
 //	@Failure		404	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
@@ -150,81 +235,91 @@ func (h *PolicyHandler) Get(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid policy ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid policy ID")
 		return
 	}
 
-	var policy model.Policy
-	if err := h.db.First(&policy, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "policy not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch policy"})
+	policy, err := h.policies.Get(c.Request.Context(), id)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": policy})
+	c.Header("ETag", policyETag(policy.Version))
+	c.JSON(http.StatusOK, dataResponse(toPolicyDTO(policy)))
 }
 
 // Update godoc
 //
 //	@Summary		Update policy
-//	@Description	Update an existing policy
+//	@Description	Update an existing policy. With an If-Match header (the ETag of GET /policies/{id}) or a version in the body, the update is rejected with a 409 giving the current version if the policy was updated since.
 //	@Tags			Policies
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		string				true	"Policy ID"	format(uuid)
-//	@Param			request	body		CreatePolicyRequest	true	"Policy update request"
-//	@Success		200		{object}	map[string]PolicyDTO
-//	@Failure		400		{object}	ErrorResponse
-//	@Failure		404		{object}	ErrorResponse
-//	@Failure		500		{object}	ErrorResponse
+//	@Param			id			path		string				true	"Policy ID"	format(uuid)
+//	@Param			If-Match	header		string				false	"ETag of the policy the update was made from"
+//	@Param			request		body		CreatePolicyRequest	true	"Policy update request"
+//	@Success		200			{object}	DataResponse[PolicyDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
 //	@Router			/policies/{id} [put]
 func (h *PolicyHandler) Update(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid policy ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid policy ID")
 		return
 	}
 
 	var req CreatePolicyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
-
-	updates := map[string]any{
-		"name":           req.Name,
-		"description":    req.Description,
-		"provider":       req.Provider,
-		"resource_types": req.ResourceTypes,
-		"conditions":     req.Conditions,
-		"actions":        req.Actions,
-		"schedule":       req.Schedule,
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if expected == 0 && req.Version != nil {
+		expected = *req.Version
 	}
 
-	result := h.db.Model(&model.Policy{}).Where("id = ?", id).Updates(updates)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to update policy"})
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "policy not found"})
+	policy, err := policyFromRequest(&req, orgID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	policy.Version = expected
 
-	var policy model.Policy
-	h.db.First(&policy, "id = ?", id)
+	policy, err = h.policies.Update(c.Request.Context(), id, policy)
+	if err != nil {
+		// Conflicts give the current version to update from
+		var appErr *apperrors.AppError
+		if apperrors.As(err, &appErr) {
+			if version, ok := appErr.Details["version"].(int); ok {
+				c.Header("ETag", policyETag(version))
+			}
+		}
+		apierror.RespondError(c, err)
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"data": policy})
+	c.Header("ETag", policyETag(policy.Version))
+	c.JSON(http.StatusOK, dataResponse(toPolicyDTO(policy)))
 }
 
 // Delete godoc
 //
 //	@Summary		Delete policy
-//	@Description	Delete a policy
+//	@Description	Delete a policy. It stops applying right away and can be restored with POST /policies/{id}/restore.
 //	@Tags			Policies
 //	@Accept			json
 //	@Produce		json
@@ -238,21 +333,48 @@ func (h *PolicyHandler) Delete(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid policy ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid policy ID")
+		return
+	}
+
+	if err := h.policies.Delete(c.Request.Context(), id); err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
-	result := h.db.Delete(&model.Policy{}, "id = ?", id)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to delete policy"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "policy deleted"})
+}
+
+// Restore godoc
+//
+//	@Summary		Restore policy
+//	@Description	Restore a deleted policy as it was when deleted, enabled or not
+//	@Tags			Policies
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Policy ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[PolicyDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/policies/{id}/restore [post]
+func (h *PolicyHandler) Restore(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid policy ID")
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "policy not found"})
+
+	policy, err := h.policies.Restore(c.Request.Context(), id)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, MessageResponse{Message: "policy deleted"})
+	c.Header("ETag", policyETag(policy.Version))
+	c.JSON(http.StatusOK, dataResponse(toPolicyDTO(policy)))
 }
 
 // Enable godoc
@@ -266,6 +388,7 @@ func (h *PolicyHandler) Delete(c *gin.Context) {
 //	@Success		200	{object}	MessageResponse
 //	@Failure		400	{object}	ErrorResponse
 //	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
 //	@Router			/policies/{id}/enable [post]
 func (h *PolicyHandler) Enable(c *gin.Context) {
@@ -289,21 +412,137 @@ func (h *PolicyHandler) Disable(c *gin.Context) {
 	h.setEnabled(c, false)
 }
 
-func (h *PolicyHandler) setEnabled(c *gin.Context, enabled bool) {
+// Simulate godoc
+//
+//	@Summary		Simulate policy
+//	@Description	Evaluate a policy against the current resources (those of its view, when it has one) without taking any action. Returns the matched resources, the potential savings and, for each condition, how many resources it matched.
+//	@Tags			Policies
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Policy ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[PolicySimulationDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/policies/{id}/simulate [post]
+func (h *PolicyHandler) Simulate(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid policy ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid policy ID")
 		return
 	}
 
-	result := h.db.Model(&model.Policy{}).Where("id = ?", id).Update("is_enabled", enabled)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to update policy"})
+	sim, err := h.policies.Simulate(c.Request.Context(), id)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "policy not found"})
+
+	dto := PolicySimulationDTO{
+		PolicyID:                sim.Policy.ID.String(),
+		Evaluated:               sim.Evaluated,
+		Count:                   len(sim.Matches),
+		EstimatedMonthlySavings: sim.EstimatedMonthlySavings,
+		EstimatedCarbonSavings:  sim.EstimatedCarbonSavings,
+		ConditionMatches:        sim.ConditionMatches,
+		Resources:               make([]SimulatedResourceDTO, len(sim.Matches)),
+	}
+	for _, a := range sim.Policy.Actions {
+		dto.Actions = append(dto.Actions, string(a))
+	}
+	for i, m := range sim.Matches {
+		dto.Resources[i] = SimulatedResourceDTO{
+			ResourceDTO: toResourceDTO(m.Resource),
+			Conditions:  m.Conditions,
+		}
+	}
+
+	c.JSON(http.StatusOK, dataResponse(dto))
+}
+
+// policyFromRequest returns the policy of a request of an organization. It
+// rejects policies whose conditions have unknown keys or values of the
+// wrong type, whose schedule is not a valid cron expression, or whose
+// actions do not apply to the selected resource types.
+func policyFromRequest(req *CreatePolicyRequest, orgID uuid.UUID) (*entity.Policy, error) {
+	var conditions entity.PolicyConditions
+	if len(req.Conditions) > 0 {
+		raw, err := json.Marshal(req.Conditions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conditions: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&conditions); err != nil {
+			return nil, fmt.Errorf("invalid conditions: %s", strings.TrimPrefix(err.Error(), "json: "))
+		}
+	}
+
+	if req.Schedule != "" {
+		if _, err := cron.ParseStandard(req.Schedule); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", req.Schedule, err)
+		}
+	}
+
+	if req.OffHours != nil {
+		s := req.OffHours
+		if s.Timezone == "" {
+			s.Timezone = entity.DefaultOffHoursTimezone
+		}
+		if s.Stop == "" {
+			s.Stop = entity.DefaultOffHoursStop
+		}
+		if s.Start == "" {
+			s.Start = entity.DefaultOffHoursStart
+		}
+		for _, spec := range []string{s.Stop, s.Start} {
+			if _, err := cron.ParseStandard(spec); err != nil {
+				return nil, fmt.Errorf("invalid off_hours window %q: %w", spec, err)
+			}
+		}
+	}
+
+	policy := &entity.Policy{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Provider:       entity.CloudProvider(req.Provider),
+		Conditions:     conditions,
+		Schedule:       req.Schedule,
+	}
+	if s := req.OffHours; s != nil {
+		policy.OffHours = &entity.OffHoursSchedule{Timezone: s.Timezone, Stop: s.Stop, Start: s.Start}
+	}
+	if req.ViewID != "" {
+		viewID, err := uuid.Parse(req.ViewID)
+		if err != nil {
+			return nil, errors.New("invalid view ID")
+		}
+		policy.ViewID = &viewID
+	}
+	for _, t := range req.ResourceTypes {
+		policy.ResourceTypes = append(policy.ResourceTypes, entity.ResourceType(t))
+	}
+	for _, a := range req.Actions {
+		policy.Actions = append(policy.Actions, entity.PolicyAction(a))
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (h *PolicyHandler) setEnabled(c *gin.Context, enabled bool) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid policy ID")
+		return
+	}
+
+	if err := h.policies.SetEnabled(c.Request.Context(), id, enabled); err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
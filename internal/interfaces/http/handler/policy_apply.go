@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ApplyPoliciesRequest represents the desired set of the declaratively
+// managed policies of an organization
+type ApplyPoliciesRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Policies is the full desired set: managed policies missing from it are
+	// deleted. An empty list deletes them all; it cannot be omitted.
+	Policies []PolicySpec `json:"policies" binding:"required,max=500,dive"`
+	// DryRun reports the changes without making them
+	DryRun bool `json:"dry_run" example:"false"`
+}
+
+// PolicySpec represents a desired policy, keyed by its external ID
+type PolicySpec struct {
+	ExternalID    string           `json:"external_id" binding:"required,max=255" example:"ebs-unused-prod"`
+	Name          string           `json:"name" binding:"required" example:"Delete unused EBS volumes"`
+	Description   string           `json:"description" example:"Automatically delete EBS volumes unused for 30 days"`
+	Provider      string           `json:"provider" binding:"required,provider" example:"aws"`
+	ResourceTypes []string         `json:"resource_types" example:"ebs_volume,ebs_snapshot"`
+	Conditions    map[string]any   `json:"conditions"`
+	Actions       []string         `json:"actions" binding:"required,min=1" example:"notify,delete"`
+	Schedule      string           `json:"schedule" example:"0 0 * * *"`
+	OffHours      *OffHoursRequest `json:"off_hours"`
+	ViewID        string           `json:"view_id" example:"550e8400-e29b-41d4-a716-446655440002"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled" example:"true"`
+}
+
+// ApplyPoliciesResponse represents the outcome of an apply, by external ID
+type ApplyPoliciesResponse struct {
+	DryRun    bool     `json:"dry_run" example:"false"`
+	Created   []string `json:"created" example:"ebs-unused-prod"`
+	Updated   []string `json:"updated" example:"idle-vms"`
+	Deleted   []string `json:"deleted" example:"old-snapshots"`
+	Unchanged []string `json:"unchanged" example:"k8s-orphans"`
+	// Policies is the set of managed policies after the apply, or as they
+	// are on a dry run
+	Policies []PolicyDTO `json:"policies"`
+}
+
+// Apply godoc
+//
+//	@Summary		Apply policies
+//	@Description	Converge the declaratively managed policies of an organization to a desired set keyed by external_id: missing policies are created, differing ones updated, and managed policies absent from the set deleted. Applying the same set twice changes nothing. Policies created without external_id are never touched. The whole set is validated before any change and applied in a single transaction; dry_run reports the changes without making them. Also served as PUT /policies:apply.
+//	@Tags			Policies
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ApplyPoliciesRequest	true	"Desired policies"
+//	@Success		200		{object}	DataResponse[ApplyPoliciesResponse]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/policies/apply [put]
+func (h *PolicyHandler) Apply(c *gin.Context) {
+	var req ApplyPoliciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	// Validate the whole set before changing anything
+	input := usecase.ApplyPoliciesInput{OrganizationID: orgID, DryRun: req.DryRun}
+	seen := make(map[string]bool, len(req.Policies))
+	for i, spec := range req.Policies {
+		if seen[spec.ExternalID] {
+			apierror.Respond(c, http.StatusBadRequest, fmt.Sprintf("policies[%d]: duplicate external_id %q", i, spec.ExternalID))
+			return
+		}
+		seen[spec.ExternalID] = true
+		policyReq := spec.request(req.OrganizationID)
+		policy, err := policyFromRequest(&policyReq, orgID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, fmt.Sprintf("policies[%d] (%s): %v", i, spec.ExternalID, err))
+			return
+		}
+		policy.ExternalID = spec.ExternalID
+		policy.IsEnabled = spec.Enabled == nil || *spec.Enabled
+		input.Policies = append(input.Policies, policy)
+	}
+
+	out, err := h.policies.Apply(c.Request.Context(), input)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	resp := ApplyPoliciesResponse{
+		DryRun:    req.DryRun,
+		Created:   out.Created,
+		Updated:   out.Updated,
+		Deleted:   out.Deleted,
+		Unchanged: out.Unchanged,
+		Policies:  make([]PolicyDTO, len(out.Policies)),
+	}
+	for i, p := range out.Policies {
+		resp.Policies[i] = toPolicyDTO(p)
+	}
+
+	c.JSON(http.StatusOK, dataResponse(resp))
+}
+
+// request returns the policy request of a spec, to validate it as one
+func (s PolicySpec) request(orgID string) CreatePolicyRequest {
+	return CreatePolicyRequest{
+		OrganizationID: orgID,
+		Name:           s.Name,
+		Description:    s.Description,
+		Provider:       s.Provider,
+		ResourceTypes:  s.ResourceTypes,
+		Conditions:     s.Conditions,
+		Actions:        s.Actions,
+		Schedule:       s.Schedule,
+		OffHours:       s.OffHours,
+		ViewID:         s.ViewID,
+	}
+}
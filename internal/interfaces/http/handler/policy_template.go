@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyTemplateHandler handles the policy template catalog endpoint
+type PolicyTemplateHandler struct {
+	templates []PolicyTemplateDTO
+}
+
+// NewPolicyTemplateHandler creates a new PolicyTemplateHandler
+func NewPolicyTemplateHandler() *PolicyTemplateHandler {
+	templates := entity.PolicyTemplates()
+	out := make([]PolicyTemplateDTO, len(templates))
+	for i, t := range templates {
+		out[i] = toPolicyTemplateDTO(t)
+	}
+	return &PolicyTemplateHandler{templates: out}
+}
+
+// ListPolicyTemplatesQuery filters the policy templates
+type ListPolicyTemplatesQuery struct {
+	Provider string `form:"provider" binding:"omitempty,provider"`
+}
+
+// List godoc
+//
+//	@Summary		List policy templates
+//	@Description	List ready-made policies for the most common kinds of waste, such as idle load balancers and NAT gateways or detached network interfaces. A policy is created from a template by posting its fields to /policies with the organization.
+//	@Tags			Policies
+//	@Produce		json
+//	@Param			provider	query		string	false	"Provider of the templates"
+//	@Success		200			{object}	DataResponse[[]PolicyTemplateDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Router			/policy-templates [get]
+func (h *PolicyTemplateHandler) List(c *gin.Context) {
+	var query ListPolicyTemplatesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	templates := make([]PolicyTemplateDTO, 0, len(h.templates))
+	for _, t := range h.templates {
+		if query.Provider == "" || t.Provider == query.Provider {
+			templates = append(templates, t)
+		}
+	}
+	c.JSON(http.StatusOK, dataResponse(templates))
+}
+
+func toPolicyTemplateDTO(t entity.PolicyTemplate) PolicyTemplateDTO {
+	types := make([]string, len(t.ResourceTypes))
+	for i, rt := range t.ResourceTypes {
+		types[i] = string(rt)
+	}
+	actions := make([]string, len(t.Actions))
+	for i, a := range t.Actions {
+		actions[i] = string(a)
+	}
+	return PolicyTemplateDTO{
+		ID:            t.ID,
+		Name:          t.Name,
+		Description:   t.Description,
+		Provider:      string(t.Provider),
+		ResourceTypes: types,
+		Conditions:    t.Conditions,
+		Actions:       actions,
+	}
+}
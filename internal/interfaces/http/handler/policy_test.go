@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve sends a request with a JSON body, if any, to a handler mounted on
+// route and returns the response
+func serve(method, route, target, body string, h gin.HandlerFunc, header ...string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Handle(method, route, h)
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// decode decodes the JSON body of a response
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+	return v
+}
+
+// fakePolicies is a PolicyService whose methods are set by each test
+type fakePolicies struct {
+	usecase.PolicyService
+	create     func(*entity.Policy) (*entity.Policy, error)
+	get        func(uuid.UUID) (*entity.Policy, error)
+	list       func(repository.PolicyFilter) ([]*entity.Policy, int64, error)
+	update     func(uuid.UUID, *entity.Policy) (*entity.Policy, error)
+	setEnabled func(uuid.UUID, bool) error
+	simulate   func(uuid.UUID) (*usecase.PolicySimulation, error)
+	apply      func(usecase.ApplyPoliciesInput) (*usecase.ApplyPoliciesOutput, error)
+}
+
+func (f *fakePolicies) Create(ctx context.Context, p *entity.Policy) (*entity.Policy, error) {
+	return f.create(p)
+}
+
+func (f *fakePolicies) Get(ctx context.Context, id uuid.UUID) (*entity.Policy, error) {
+	return f.get(id)
+}
+
+func (f *fakePolicies) List(ctx context.Context, filter repository.PolicyFilter) ([]*entity.Policy, int64, error) {
+	return f.list(filter)
+}
+
+func (f *fakePolicies) Update(ctx context.Context, id uuid.UUID, p *entity.Policy) (*entity.Policy, error) {
+	return f.update(id, p)
+}
+
+func (f *fakePolicies) SetEnabled(ctx context.Context, id uuid.UUID, enabled bool) error {
+	return f.setEnabled(id, enabled)
+}
+
+func (f *fakePolicies) Simulate(ctx context.Context, id uuid.UUID) (*usecase.PolicySimulation, error) {
+	return f.simulate(id)
+}
+
+func (f *fakePolicies) Apply(ctx context.Context, input usecase.ApplyPoliciesInput) (*usecase.ApplyPoliciesOutput, error) {
+	return f.apply(input)
+}
+
+const policyBody = `{
+	"organization_id": "6f1c2b9e-0d4a-4c8e-9a51-3b7d2e8f4a10",
+	"name": "Delete unattached volumes",
+	"provider": "aws",
+	"resource_types": ["ebs_volume"],
+	"conditions": {"unused_days": 30, "regions": ["eu-west-3"]},
+	"actions": ["notify", "delete"],
+	"schedule": "0 6 * * 1"
+}`
+
+func TestPolicyHandlerCreate(t *testing.T) {
+	var got *entity.Policy
+	h := NewPolicyHandler(&fakePolicies{create: func(p *entity.Policy) (*entity.Policy, error) {
+		got = p
+		created := *p
+		created.ID, created.IsEnabled, created.Version = uuid.New(), true, 1
+		return &created, nil
+	}})
+
+	w := serve(http.MethodPost, "/policies", "/policies", policyBody, h.Create)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("ETag = %s, want \"1\"", etag)
+	}
+	if got.OrganizationID != goldenOrgID || got.Conditions.UnusedDays != 30 || got.Conditions.Regions[0] != "eu-west-3" {
+		t.Errorf("policy passed to the service = %+v", got)
+	}
+	if dto := decode[DataResponse[PolicyDTO]](t, w).Data; dto.Conditions["unused_days"] != float64(30) || !dto.IsEnabled {
+		t.Errorf("response = %+v", dto)
+	}
+}
+
+func TestPolicyHandlerCreateInvalid(t *testing.T) {
+	h := NewPolicyHandler(&fakePolicies{})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"unknown condition", strings.Replace(policyBody, `"unused_days"`, `"min_age_days"`, 1), "invalid conditions: unknown field \"min_age_days\""},
+		{"invalid schedule", strings.Replace(policyBody, `0 6 * * 1`, `every monday`, 1), "invalid schedule"},
+		{"action of another type", strings.Replace(policyBody, `"notify", "delete"`, `"stop"`, 1), "does not apply to resource type"},
+		{"invalid view", strings.Replace(policyBody, `"schedule"`, `"view_id": "all", "schedule"`, 1), "invalid view ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(http.MethodPost, "/policies", "/policies", tt.body, h.Create)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+			}
+			if msg := decode[map[string]any](t, w)["message"].(string); !strings.Contains(msg, tt.want) {
+				t.Errorf("message = %s, want %q", msg, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyHandlerGetNotFound(t *testing.T) {
+	h := NewPolicyHandler(&fakePolicies{get: func(uuid.UUID) (*entity.Policy, error) {
+		return nil, apperrors.NewWithCode(apperrors.ErrNotFound, apperrors.CodeNotFound, "policy not found")
+	}})
+
+	w := serve(http.MethodGet, "/policies/:id", "/policies/"+uuid.NewString(), "", h.Get)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/policies/:id", "/policies/42", "", h.Get); w.Code != http.StatusBadRequest {
+		t.Errorf("status of an invalid ID = %d, want 400", w.Code)
+	}
+}
+
+func TestPolicyHandlerList(t *testing.T) {
+	var got repository.PolicyFilter
+	h := NewPolicyHandler(&fakePolicies{list: func(filter repository.PolicyFilter) ([]*entity.Policy, int64, error) {
+		got = filter
+		return []*entity.Policy{{ID: uuid.New(), Name: "a"}}, 7, nil
+	}})
+
+	w := serve(http.MethodGet, "/policies", "/policies?provider=gcp&deleted=true&limit=1&offset=3", "", h.List)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.Provider == nil || *got.Provider != entity.CloudProviderGCP || !got.Deleted || got.Limit != 1 || got.Offset != 3 {
+		t.Errorf("filter = %+v", got)
+	}
+	if resp := decode[PaginatedResponse](t, w); resp.Total != 7 {
+		t.Errorf("total = %d, want 7", resp.Total)
+	}
+}
+
+func TestPolicyHandlerUpdateConflict(t *testing.T) {
+	var version int
+	h := NewPolicyHandler(&fakePolicies{update: func(id uuid.UUID, p *entity.Policy) (*entity.Policy, error) {
+		version = p.Version
+		return nil, &apperrors.AppError{
+			Err:     apperrors.ErrConflict,
+			Code:    apperrors.CodeConflict,
+			Message: "policy was updated since version 3, it is at version 5",
+			Details: map[string]any{"version": 5},
+		}
+	}})
+
+	w := serve(http.MethodPut, "/policies/:id", "/policies/"+uuid.NewString(), policyBody, h.Update, "If-Match", `"3"`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", w.Code, w.Body)
+	}
+	if version != 3 {
+		t.Errorf("version passed to the service = %d, want 3 from If-Match", version)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"5"` {
+		t.Errorf("ETag = %s, want the current version \"5\"", etag)
+	}
+}
+
+func TestPolicyHandlerEnableDeactivated(t *testing.T) {
+	h := NewPolicyHandler(&fakePolicies{setEnabled: func(id uuid.UUID, enabled bool) error {
+		if !enabled {
+			t.Error("Enable disabled the policy")
+		}
+		return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "organization is deactivated")
+	}})
+
+	w := serve(http.MethodPost, "/policies/:id/enable", "/policies/"+uuid.NewString()+"/enable", "", h.Enable)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", w.Code, w.Body)
+	}
+}
+
+func TestPolicyHandlerSimulate(t *testing.T) {
+	policy := &entity.Policy{ID: uuid.New(), Actions: []entity.PolicyAction{entity.PolicyActionDelete}}
+	resource := entity.NewResource(goldenOrgID, entity.CloudProviderAWS, entity.ResourceTypeEBSVolume, "vol-1", "eu-west-3", "cache")
+	h := NewPolicyHandler(&fakePolicies{simulate: func(uuid.UUID) (*usecase.PolicySimulation, error) {
+		return &usecase.PolicySimulation{
+			Policy:                  policy,
+			Evaluated:               4,
+			ConditionMatches:        map[string]int{"unused_days": 2},
+			Matches:                 []usecase.PolicyMatch{{Resource: resource, Conditions: []service.ConditionResult{{Condition: "unused_days", Matched: true}}}},
+			EstimatedMonthlySavings: 8,
+		}, nil
+	}})
+
+	w := serve(http.MethodPost, "/policies/:id/simulate", "/policies/"+policy.ID.String()+"/simulate", "", h.Simulate)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	sim := decode[DataResponse[PolicySimulationDTO]](t, w).Data
+	if sim.Count != 1 || sim.Evaluated != 4 || sim.Resources[0].ResourceID != "vol-1" || sim.Actions[0] != "delete" || sim.EstimatedMonthlySavings != 8 {
+		t.Errorf("simulation = %+v", sim)
+	}
+}
+
+func TestPolicyHandlerApply(t *testing.T) {
+	var got usecase.ApplyPoliciesInput
+	h := NewPolicyHandler(&fakePolicies{apply: func(input usecase.ApplyPoliciesInput) (*usecase.ApplyPoliciesOutput, error) {
+		got = input
+		return &usecase.ApplyPoliciesOutput{Created: []string{"a"}, Updated: []string{}, Deleted: []string{}, Unchanged: []string{"b"}}, nil
+	}})
+
+	body := `{"organization_id": "6f1c2b9e-0d4a-4c8e-9a51-3b7d2e8f4a10", "dry_run": true, "policies": [
+		{"external_id": "a", "name": "A", "provider": "aws", "actions": ["notify"]},
+		{"external_id": "b", "name": "B", "provider": "aws", "actions": ["notify"], "enabled": false}
+	]}`
+	w := serve(http.MethodPut, "/policies/apply", "/policies/apply", body, h.Apply)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if !got.DryRun || len(got.Policies) != 2 {
+		t.Fatalf("input = %+v", got)
+	}
+	if p := got.Policies[0]; p.ExternalID != "a" || !p.IsEnabled || p.OrganizationID != goldenOrgID {
+		t.Errorf("policies[0] = %+v, want a enabled by default", p)
+	}
+	if p := got.Policies[1]; p.ExternalID != "b" || p.IsEnabled {
+		t.Errorf("policies[1] = %+v, want b disabled", p)
+	}
+
+	duplicate := strings.Replace(body, `"external_id": "b"`, `"external_id": "a"`, 1)
+	w = serve(http.MethodPut, "/policies/apply", "/policies/apply", duplicate, h.Apply)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `duplicate external_id \"a\"`) {
+		t.Errorf("duplicate external IDs: status = %d, body = %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Request fields tagged provider accept the registered providers only
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = v.RegisterValidation("provider", func(fl validator.FieldLevel) bool {
+			_, ok := provider.Lookup(fl.Field().String())
+			return ok
+		})
+	}
+}
+
+// ProviderHandler handles the cloud provider registry endpoint
+type ProviderHandler struct {
+	providers     []ProviderDTO
+	resourceTypes []ResourceTypeDTO
+}
+
+// NewProviderHandler creates a new ProviderHandler. Providers register at
+// init, so their descriptions are built once rather than on every request.
+func NewProviderHandler() *ProviderHandler {
+	return &ProviderHandler{providers: providerDTOs(), resourceTypes: resourceTypeDTOs()}
+}
+
+// ProviderDTO describes a supported cloud provider
+type ProviderDTO struct {
+	Name        string `json:"name" example:"aws"`
+	DisplayName string `json:"display_name" example:"Amazon Web Services"`
+	// RegionLabel names what the regions of scans are for the provider
+	RegionLabel   string                     `json:"region_label" example:"region"`
+	ResourceTypes []ProviderResourceTypeDTO  `json:"resource_types"`
+	Credentials   []provider.CredentialField `json:"credentials"`
+	// Integration is set for providers whose accounts can be discovered,
+	// see /organizations/{id}/integrations/{provider}
+	Integration *ProviderIntegrationDTO `json:"integration,omitempty"`
+	// PricingSources are the billing data resource costs can be reconciled
+	// with, see /organizations/{id}/cloud-accounts/{account_id}/pricing
+	PricingSources []provider.PricingSource `json:"pricing_sources"`
+}
+
+// ProviderIntegrationDTO describes the organization-level integration of a
+// provider
+type ProviderIntegrationDTO struct {
+	DisplayName string                     `json:"display_name" example:"AWS Organizations"`
+	Settings    []provider.CredentialField `json:"settings"`
+}
+
+// ProviderResourceTypeDTO describes a resource type of a provider
+type ProviderResourceTypeDTO struct {
+	Type string `json:"type" example:"ec2_instance"`
+	// Actions are the cleanup actions the resource type supports
+	Actions []string `json:"actions" example:"delete,stop,tag,notify,quarantine"`
+}
+
+// ResourceTypeDTO describes a resource type and how its unused resources
+// are detected
+type ResourceTypeDTO struct {
+	Type     string `json:"type" example:"k8s_pvc"`
+	Provider string `json:"provider" example:"kubernetes"`
+	// Scannable is false for the types of providers described but not
+	// scanned yet
+	Scannable bool `json:"scannable" example:"true"`
+	// Heuristics tell when resources of the type are reported unused
+	Heuristics []string `json:"heuristics" example:"Not mounted by any running pod"`
+	// Actions are the cleanup actions the resource type supports
+	Actions []string `json:"actions" example:"delete,tag,notify,quarantine"`
+}
+
+// ListResourceTypesQuery filters the resource types
+type ListResourceTypesQuery struct {
+	Provider string `form:"provider" binding:"omitempty,provider"`
+}
+
+// List godoc
+//
+//	@Summary		List providers
+//	@Description	List the supported cloud providers with their resource types, the cleanup actions of each type, the fields of their credentials, of their organization-level integration and of their pricing sources, for clients to build their forms from rather than hard-coding providers
+//	@Tags			Providers
+//	@Produce		json
+//	@Success		200	{object}	DataResponse[[]ProviderDTO]
+//	@Router			/providers [get]
+func (h *ProviderHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, dataResponse(h.providers))
+}
+
+// ListResourceTypes godoc
+//
+//	@Summary		List resource types
+//	@Description	List the resource types of the registered providers, whether they are scanned and the heuristics reporting their resources unused. Each type is detected by its own plugin.
+//	@Tags			Providers
+//	@Produce		json
+//	@Param			provider	query		string	false	"Provider of the types"
+//	@Success		200			{object}	DataResponse[[]ResourceTypeDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Router			/resource-types [get]
+func (h *ProviderHandler) ListResourceTypes(c *gin.Context) {
+	var query ListResourceTypesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	types := make([]ResourceTypeDTO, 0, len(h.resourceTypes))
+	for _, t := range h.resourceTypes {
+		if query.Provider == "" || t.Provider == query.Provider {
+			types = append(types, t)
+		}
+	}
+	c.JSON(http.StatusOK, dataResponse(types))
+}
+
+func resourceTypeDTOs() []ResourceTypeDTO {
+	var out []ResourceTypeDTO
+	for _, p := range provider.All() {
+		for _, t := range p.ResourceTypes {
+			dto := ResourceTypeDTO{
+				Type:       string(t),
+				Provider:   string(p.Name),
+				Scannable:  p.NewScanner != nil,
+				Heuristics: []string{},
+				Actions:    resourceTypeActions(t),
+			}
+			if d, ok := provider.LookupDetector(t); ok {
+				dto.Scannable = true
+				if d.Heuristics != nil {
+					dto.Heuristics = d.Heuristics
+				}
+			}
+			out = append(out, dto)
+		}
+	}
+	return out
+}
+
+// resourceTypeActions returns the cleanup actions of a resource type
+func resourceTypeActions(t entity.ResourceType) []string {
+	if t.IsStoppable() {
+		return []string{"delete", "stop", "tag", "notify", "quarantine"}
+	}
+	if t.IsPublicIP() {
+		return []string{"release", "delete", "tag", "notify", "quarantine"}
+	}
+	return []string{"delete", "tag", "notify", "quarantine"}
+}
+
+func providerDTOs() []ProviderDTO {
+	all := provider.All()
+	out := make([]ProviderDTO, len(all))
+	for i, p := range all {
+		types := make([]ProviderResourceTypeDTO, len(p.ResourceTypes))
+		for j, t := range p.ResourceTypes {
+			types[j] = ProviderResourceTypeDTO{Type: string(t), Actions: resourceTypeActions(t)}
+		}
+		credentials := p.Credentials
+		if credentials == nil {
+			credentials = []provider.CredentialField{}
+		}
+		pricingSources := make([]provider.PricingSource, len(p.PricingSources))
+		for j, s := range p.PricingSources {
+			if s.Settings == nil {
+				s.Settings = []provider.CredentialField{}
+			}
+			pricingSources[j] = s
+		}
+		out[i] = ProviderDTO{
+			Name:           string(p.Name),
+			DisplayName:    p.DisplayName,
+			RegionLabel:    p.RegionLabel,
+			ResourceTypes:  types,
+			Credentials:    credentials,
+			PricingSources: pricingSources,
+		}
+		if p.Integration != nil {
+			out[i].Integration = &ProviderIntegrationDTO{
+				DisplayName: p.Integration.DisplayName,
+				Settings:    p.Integration.Settings,
+			}
+		}
+	}
+	return out
+}
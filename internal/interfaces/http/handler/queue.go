@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+)
+
+// QueueHandler exposes asynq queue state for operators
+type QueueHandler struct {
+	inspector *asynq.Inspector
+}
+
+// NewQueueHandler creates a new QueueHandler
+func NewQueueHandler(inspector *asynq.Inspector) *QueueHandler {
+	return &QueueHandler{inspector: inspector}
+}
+
+// ListQueueTasksRequest represents query parameters for listing queued tasks
+type ListQueueTasksRequest struct {
+	Queue    string `form:"queue,default=default" example:"default"`
+	State    string `form:"state,default=pending" binding:"oneof=pending active scheduled retry archived completed" example:"pending"`
+	TaskType string `form:"task_type" example:"scan:resources"`
+	Limit    int    `form:"limit,default=50" binding:"min=1,max=500" example:"50"`
+	Offset   int    `form:"offset,default=0" binding:"min=0" example:"0"`
+}
+
+// CancelQueueTaskRequest represents query parameters for cancelling a task
+type CancelQueueTaskRequest struct {
+	Queue string `form:"queue" binding:"required" example:"default"`
+}
+
+// Stats godoc
+//
+//	@Summary		Get queue statistics
+//	@Description	Get pending, active, scheduled, retry and archived task counts for each queue
+//	@Tags			Queue
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	DataResponse[[]QueueStatsDTO]
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/queue/stats [get]
+func (h *QueueHandler) Stats(c *gin.Context) {
+	queues, err := h.inspector.Queues()
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to list queues")
+		return
+	}
+
+	stats := make([]QueueStatsDTO, 0, len(queues))
+	for _, name := range queues {
+		info, err := h.inspector.GetQueueInfo(name)
+		if err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, "failed to fetch queue info")
+			return
+		}
+		stats = append(stats, QueueStatsDTO{
+			Queue:          info.Queue,
+			Size:           info.Size,
+			Pending:        info.Pending,
+			Active:         info.Active,
+			Scheduled:      info.Scheduled,
+			Retry:          info.Retry,
+			Archived:       info.Archived,
+			Completed:      info.Completed,
+			ProcessedToday: info.Processed,
+			FailedToday:    info.Failed,
+			LatencySeconds: info.Latency.Seconds(),
+			MemoryBytes:    info.MemoryUsage,
+			Paused:         info.Paused,
+		})
+	}
+
+	c.JSON(http.StatusOK, dataResponse(stats))
+}
+
+// ListTasks godoc
+//
+//	@Summary		List queued tasks
+//	@Description	List tasks of a queue in a given state. The task_type filter applies to the requested page.
+//	@Tags			Queue
+//	@Accept			json
+//	@Produce		json
+//	@Param			queue		query		string	false	"Queue name"	default(default)
+//	@Param			state		query		string	false	"Task state"	Enums(pending, active, scheduled, retry, archived, completed)	default(pending)
+//	@Param			task_type	query		string	false	"Filter by task type"
+//	@Param			limit		query		int		false	"Number of items per page"	default(50)
+//	@Param			offset		query		int		false	"Number of items to skip"	default(0)
+//	@Success		200			{object}	DataResponse[[]QueueTaskDTO]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/queue/tasks [get]
+func (h *QueueHandler) ListTasks(c *gin.Context) {
+	var req ListQueueTasksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	// asynq paginates by page number, so offsets are rounded down to a page
+	opts := []asynq.ListOption{asynq.PageSize(req.Limit), asynq.Page(req.Offset/req.Limit + 1)}
+
+	var (
+		tasks []*asynq.TaskInfo
+		err   error
+	)
+	switch req.State {
+	case "active":
+		tasks, err = h.inspector.ListActiveTasks(req.Queue, opts...)
+	case "scheduled":
+		tasks, err = h.inspector.ListScheduledTasks(req.Queue, opts...)
+	case "retry":
+		tasks, err = h.inspector.ListRetryTasks(req.Queue, opts...)
+	case "archived":
+		tasks, err = h.inspector.ListArchivedTasks(req.Queue, opts...)
+	case "completed":
+		tasks, err = h.inspector.ListCompletedTasks(req.Queue, opts...)
+	default:
+		tasks, err = h.inspector.ListPendingTasks(req.Queue, opts...)
+	}
+	if err != nil {
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			apierror.Respond(c, http.StatusNotFound, "queue not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to list tasks")
+		return
+	}
+
+	dtos := make([]QueueTaskDTO, 0, len(tasks))
+	for _, t := range tasks {
+		if req.TaskType != "" && t.Type != req.TaskType {
+			continue
+		}
+		dtos = append(dtos, toQueueTaskDTO(t))
+	}
+
+	c.JSON(http.StatusOK, dataResponse(dtos))
+}
+
+// CancelTask godoc
+//
+//	@Summary		Cancel a queued task
+//	@Description	Cancel an active task or remove a pending, scheduled or retrying task from its queue
+//	@Tags			Queue
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"Task ID"
+//	@Param			queue	query		string	true	"Queue name"
+//	@Success		202		{object}	MessageResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/queue/tasks/{id}/cancel [post]
+func (h *QueueHandler) CancelTask(c *gin.Context) {
+	var req CancelQueueTaskRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	id := c.Param("id")
+	info, err := h.inspector.GetTaskInfo(req.Queue, id)
+	if err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			apierror.Respond(c, http.StatusNotFound, "task not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch task")
+		return
+	}
+
+	switch info.State {
+	case asynq.TaskStateActive:
+		// Cancellation is delivered to the worker through its context;
+		// the handler decides how quickly it stops
+		err = h.inspector.CancelProcessing(id)
+	case asynq.TaskStatePending, asynq.TaskStateScheduled, asynq.TaskStateRetry:
+		err = h.inspector.DeleteTask(req.Queue, id)
+	default:
+		apierror.Respond(c, http.StatusConflict, "task is "+info.State.String()+" and cannot be cancelled")
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to cancel task")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "task cancellation requested"})
+}
+
+func toQueueTaskDTO(t *asynq.TaskInfo) QueueTaskDTO {
+	dto := QueueTaskDTO{
+		ID:       t.ID,
+		Queue:    t.Queue,
+		Type:     t.Type,
+		State:    t.State.String(),
+		MaxRetry: t.MaxRetry,
+		Retried:  t.Retried,
+		LastErr:  t.LastErr,
+	}
+	// Payloads are JSON for every task type registered in this repo
+	_ = json.Unmarshal(t.Payload, &dto.Payload)
+	if !t.LastFailedAt.IsZero() {
+		dto.LastFailedAt = &t.LastFailedAt
+	}
+	if !t.NextProcessAt.IsZero() {
+		dto.NextProcessAt = &t.NextProcessAt
+	}
+	return dto
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// organizationUsage is the consumption of an organization, by quota.
+// Retention is a duration rather than a consumption and is left out.
+type organizationUsage map[entity.Quota]int64
+
+// loadUsage counts what an organization consumes of its plan quotas
+func loadUsage(ctx context.Context, db *gorm.DB, orgID uuid.UUID, now time.Time) (organizationUsage, error) {
+	return database.NewOrganizationRepository(db).Usage(ctx, orgID, now)
+}
+
+// respondQuotaError writes the response of a plan limit error: 429 for daily
+// quotas, which reset, and 402 for the others, which need an upgrade. It
+// returns false for other errors.
+func respondQuotaError(c *gin.Context, err error) bool {
+	var quotaErr *entity.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+	status := http.StatusPaymentRequired
+	if quotaErr.Quota == entity.QuotaScansPerDay {
+		status = http.StatusTooManyRequests
+		reset := entity.StartOfQuotaDay(time.Now()).Add(24 * time.Hour)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(reset).Seconds()))))
+	}
+	c.JSON(status, QuotaErrorResponse{
+		Response: apierror.New(c, apierror.Code(status), quotaErr.Error()),
+		Quota:    string(quotaErr.Quota),
+		Plan:     quotaErr.Plan,
+		Limit:    quotaErr.Limit,
+		Used:     quotaErr.Used,
+		Upgrade:  quotaErr.Upgrade,
+	})
+	return true
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecommendationHandler handles recommendation endpoints
+type RecommendationHandler struct {
+	db *gorm.DB
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler
+func NewRecommendationHandler(db *gorm.DB) *RecommendationHandler {
+	return &RecommendationHandler{db: db}
+}
+
+// ListRecommendationsRequest represents query parameters for listing recommendations
+type ListRecommendationsRequest struct {
+	OrganizationID string `form:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type           string `form:"type" binding:"omitempty,oneof=rightsize gp3_migration commitment" example:"rightsize"`
+	Provider       string `form:"provider" binding:"omitempty,provider" example:"aws"`
+	Status         string `form:"status,default=open" binding:"omitempty,oneof=open dismissed" example:"open"`
+	Limit          int    `form:"limit,default=50" example:"50"`
+	Offset         int    `form:"offset,default=0" example:"0"`
+}
+
+// UpdateRecommendationRequest represents a request to dismiss or reopen a
+// recommendation
+type UpdateRecommendationRequest struct {
+	Status string `json:"status" binding:"required,oneof=open dismissed" example:"dismissed"`
+}
+
+// List godoc
+//
+//	@Summary		List recommendations
+//	@Description	Get a paginated list of the recommendations on resources in use, highest estimated savings first. Recommendations are regenerated daily from resource metrics.
+//	@Tags			Recommendations
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	true	"Organization ID"	format(uuid)
+//	@Param			type			query		string	false	"Recommendation type"	Enums(rightsize, gp3_migration, commitment)
+//	@Param			provider		query		string	false	"Cloud provider"
+//	@Param			status			query		string	false	"Recommendation status"	Enums(open, dismissed)	default(open)
+//	@Param			limit			query		int		false	"Number of items per page"	default(50)
+//	@Param			offset			query		int		false	"Number of items to skip"	default(0)
+//	@Success		200				{object}	PaginatedResponse{data=[]RecommendationDTO}
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/recommendations [get]
+func (h *RecommendationHandler) List(c *gin.Context) {
+	var req ListRecommendationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.Recommendation{}).Where("organization_id = ?", orgID)
+
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.Provider != "" {
+		query = query.Where("provider = ?", req.Provider)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var recs []model.Recommendation
+	if err := query.Preload("Resource").Limit(req.Limit).Offset(req.Offset).Order("monthly_savings DESC, id").Find(&recs).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch recommendations")
+		return
+	}
+
+	data := make([]RecommendationDTO, 0, len(recs))
+	for _, rec := range recs {
+		data = append(data, recommendationDTO(rec))
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   data,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+// Update godoc
+//
+//	@Summary		Update recommendation
+//	@Description	Dismiss a recommendation, or reopen a dismissed one. Dismissed recommendations stay dismissed when regenerated.
+//	@Tags			Recommendations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Recommendation ID"	format(uuid)
+//	@Param			request	body		UpdateRecommendationRequest	true	"Status update"
+//	@Success		200		{object}	RecommendationDTO
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/recommendations/{id} [put]
+func (h *RecommendationHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid recommendation ID")
+		return
+	}
+
+	var req UpdateRecommendationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	var rec model.Recommendation
+	if err := db.Preload("Resource").First(&rec, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "recommendation not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch recommendation")
+		return
+	}
+
+	if err := db.Model(&rec).Update("status", req.Status).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update recommendation")
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendationDTO(rec))
+}
+
+func recommendationDTO(m model.Recommendation) RecommendationDTO {
+	dto := RecommendationDTO{
+		ID:             m.ID.String(),
+		OrganizationID: m.OrganizationID.String(),
+		Provider:       m.Provider,
+		Type:           m.Type,
+		Current:        m.Current,
+		Recommended:    m.Recommended,
+		MonthlySavings: m.MonthlySavings,
+		Details:        m.Details,
+		Status:         m.Status,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+	if m.Resource != nil {
+		r := toResourceDTO(database.ResourceEntity(m.Resource))
+		dto.Resource = &r
+	}
+	return dto
+}
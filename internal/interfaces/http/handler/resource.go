@@ -2,97 +2,209 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
-	"gorm.io/gorm"
 )
 
 // ResourceHandler handles resource endpoints
 type ResourceHandler struct {
-	db          *gorm.DB
-	queueClient *asynq.Client
+	resources usecase.ResourceService
 }
 
 // NewResourceHandler creates a new ResourceHandler
-func NewResourceHandler(db *gorm.DB, queueClient *asynq.Client) *ResourceHandler {
-	return &ResourceHandler{
-		db:          db,
-		queueClient: queueClient,
+func NewResourceHandler(resources usecase.ResourceService) *ResourceHandler {
+	return &ResourceHandler{resources: resources}
+}
+
+func toResourceDTO(r *entity.Resource) ResourceDTO {
+	return ResourceDTO{
+		ID:              r.ID.String(),
+		OrganizationID:  r.OrganizationID.String(),
+		Provider:        string(r.Provider),
+		Type:            string(r.Type),
+		ResourceID:      r.ResourceID,
+		Region:          r.Region,
+		AccountID:       r.AccountID,
+		Name:            r.Name,
+		Status:          string(r.Status),
+		Tags:            r.Tags,
+		MonthlyCost:     r.MonthlyCost,
+		CarbonFootprint: r.CarbonFootprint,
+		LastSeenAt:      r.LastSeenAt,
+		SnoozedUntil:    r.SnoozedUntil,
+		ApprovedAt:      r.CleanupApprovedAt,
+		ApprovedBy:      r.CleanupApprovedBy,
+		Owner:           r.Owner,
+		OwnerSource:     string(r.OwnerSource),
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
 	}
 }
 
 // ListResourcesRequest represents query parameters for listing resources
 type ListResourcesRequest struct {
-	Provider string `form:"provider" example:"aws"`
-	Type     string `form:"type" example:"ec2_instance"`
-	Status   string `form:"status" example:"unused"`
-	Region   string `form:"region" example:"us-east-1"`
-	Limit    int    `form:"limit,default=50" example:"50"`
-	Offset   int    `form:"offset,default=0" example:"0"`
+	ResourceFilter
+	// ViewID applies a saved view; the other filters override those of the
+	// view
+	ViewID string `form:"view_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Cursor continues from the next_cursor of the previous page, in place
+	// of offset
+	Cursor string `form:"cursor" example:"eyJzIjoiY29zdCJ9"`
+	Limit  int    `form:"limit,default=50" example:"50"`
+	Offset int    `form:"offset,default=0" example:"0"`
+	// IncludeTotal set to false skips counting the matching resources, and
+	// total is -1 unless the page is the last one
+	IncludeTotal bool `form:"include_total,default=true" example:"true"`
+}
+
+// resourceSorts are the columns resources can be sorted by, in descending
+// order. Age descending lists the oldest resources first.
+var resourceSorts = map[string]keysetSort{
+	"created": {Column: "created_at", Desc: true},
+	"cost":    {Column: "monthly_cost", Desc: true},
+	"carbon":  {Column: "carbon_footprint", Desc: true},
+	"age":     {Column: "created_at", Desc: false},
 }
 
 // List godoc
 //
 //	@Summary		List resources
-//	@Description	Get a paginated list of cloud resources with optional filters
+//	@Description	Get a paginated list of cloud resources with optional filters.
+//	@Description	q matches names, cloud IDs and tag values (every term must match) and accepts tag selectors: tag:env=prod, tag:owner!=platform (also matches untagged resources) and tag:env (tag set). Use double quotes for values with spaces.
 //	@Tags			Resources
 //	@Accept			json
 //	@Produce		json
-//	@Param			provider	query		string	false	"Filter by cloud provider"	Enums(aws, azure, gcp)
+//	@Param			provider	query		string	false	"Filter by cloud provider"
 //	@Param			type		query		string	false	"Filter by resource type"
-//	@Param			status		query		string	false	"Filter by status"	Enums(active, unused, deleted, excluded)
+//	@Param			status		query		string	false	"Filter by status"	Enums(active, unused, deleted, removed, excluded)
 //	@Param			region		query		string	false	"Filter by region"
+//	@Param			account_id	query		string	false	"Filter by provider account, subscription, project or cluster"
+//	@Param			owner		query		string	false	"Filter by owner email"
+//	@Param			q			query		string	false	"Search terms and tag selectors"
+//	@Param			min_cost	query		number	false	"Minimum monthly cost"
+//	@Param			view_id		query		string	false	"Saved view to apply, other filters override it"	format(uuid)
+//	@Param			sort		query		string	false	"Sort by"	Enums(created, cost, carbon, age)	default(created)
+//	@Param			order		query		string	false	"Sort order"	Enums(asc, desc)	default(desc)
+//	@Param			cursor		query		string	false	"Cursor of the next page, from next_cursor; offset is ignored"
 //	@Param			limit		query		int		false	"Number of items per page"	default(50)
 //	@Param			offset		query		int		false	"Number of items to skip"	default(0)
+//	@Param			include_total	query	bool	false	"Count the matching resources; total is -1 when false, unless the page is the last one"	default(true)
 //	@Success		200			{object}	PaginatedResponse{data=[]ResourceDTO}
 //	@Failure		400			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
 //	@Failure		500			{object}	ErrorResponse
 //	@Router			/resources [get]
 func (h *ResourceHandler) List(c *gin.Context) {
 	var req ListResourcesRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
 
-	// Build query
-	query := h.db.Model(&model.Resource{})
-
-	if req.Provider != "" {
-		query = query.Where("provider = ?", req.Provider)
-	}
-	if req.Type != "" {
-		query = query.Where("type = ?", req.Type)
-	}
-	if req.Status != "" {
-		query = query.Where("status = ?", req.Status)
-	}
-	if req.Region != "" {
-		query = query.Where("region = ?", req.Region)
+	input := usecase.ListResourcesInput{Limit: req.Limit, Offset: req.Offset, IncludeTotal: req.IncludeTotal}
+	filter := req.ResourceFilter
+	if req.ViewID != "" {
+		viewID, err := uuid.Parse(req.ViewID)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, "invalid view ID")
+			return
+		}
+		view, err := h.resources.View(c.Request.Context(), viewID)
+		if err != nil {
+			apierror.RespondError(c, err)
+			return
+		}
+		filter = filter.withDefaults(ResourceFilter(view.Filters))
+		input.OrganizationID = &view.OrganizationID
 	}
+	input.Filter = entity.ResourceViewFilter(filter)
 
-	// Count total
-	var total int64
-	query.Count(&total)
+	sort := resourceSort(filter.Sort, filter.Order)
+	if req.Cursor != "" {
+		key, err := resourceCursorKey(sort, req.Cursor)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		input.After = key
+		req.Offset = 0
+	}
 
-	// Fetch resources
-	var resources []model.Resource
-	if err := query.Limit(req.Limit).Offset(req.Offset).Order("created_at DESC").Find(&resources).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch resources"})
+	out, err := h.resources.List(c.Request.Context(), input)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
+	resources, next := keysetPage(sort, out.Resources, req.Limit, func(r **entity.Resource) (string, string) {
+		switch sort.Column {
+		case "monthly_cost":
+			return strconv.FormatFloat((*r).MonthlyCost, 'f', -1, 64), (*r).ID.String()
+		case "carbon_footprint":
+			return strconv.FormatFloat((*r).CarbonFootprint, 'f', -1, 64), (*r).ID.String()
+		}
+		return (*r).CreatedAt.Format(time.RFC3339Nano), (*r).ID.String()
+	})
+
+	data := make([]ResourceDTO, len(resources))
+	for i, r := range resources {
+		data[i] = toResourceDTO(r)
+	}
 
 	c.JSON(http.StatusOK, PaginatedResponse{
-		Data:   resources,
-		Total:  total,
-		Limit:  req.Limit,
-		Offset: req.Offset,
+		Data:       data,
+		Total:      out.Total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+		NextCursor: next,
 	})
 }
 
+// resourceSort returns the keyset sort of the resource list, descending
+// unless order is asc
+func resourceSort(name, order string) keysetSort {
+	sort, ok := resourceSorts[name]
+	if !ok {
+		name, sort = "created", resourceSorts["created"]
+	}
+	if order == "asc" {
+		sort.Desc = !sort.Desc
+	} else {
+		order = "desc"
+	}
+	sort.Name = name + ":" + order
+	return sort
+}
+
+// resourceCursorKey returns the position of a cursor of the resource list
+// in a sort: a creation date or a number, then an ID
+func resourceCursorKey(sort keysetSort, cursor string) (*repository.ResourceKey, error) {
+	cur, err := sort.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(cur.ID)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	key := &repository.ResourceKey{ID: id}
+	if sort.Column == "created_at" {
+		key.CreatedAt, err = time.Parse(time.RFC3339Nano, cur.Value)
+	} else {
+		key.Value, err = strconv.ParseFloat(cur.Value, 64)
+	}
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return key, nil
+}
+
 // Get godoc
 //
 //	@Summary		Get resource by ID
@@ -101,36 +213,31 @@ func (h *ResourceHandler) List(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		string	true	"Resource ID"	format(uuid)
-//	@Success		200	{object}	map[string]ResourceDTO
+//	@Success		200	{object}	DataResponse[ResourceDTO]
 //	@Failure		400	{object}	ErrorResponse
 //	@Failure		404	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
 //	@Router			/resources/{id} [get]
 func (h *ResourceHandler) Get(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := uuid.Parse(idParam)
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid resource ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
 		return
 	}
 
-	var resource model.Resource
-	if err := h.db.First(&resource, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "resource not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch resource"})
+	resource, err := h.resources.Get(c.Request.Context(), id)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": resource})
+	c.JSON(http.StatusOK, dataResponse(toResourceDTO(resource)))
 }
 
 // Delete godoc
 //
-//	@Summary		Delete resource
-//	@Description	Mark a resource as deleted
+//	@Summary		Remove resource from inventory
+//	@Description	Remove a resource from the inventory without acting on it in the cloud. Its status becomes "removed", distinct from "deleted" for resources gone from the cloud, and scans keep it removed until it is reinstated.
 //	@Tags			Resources
 //	@Accept			json
 //	@Produce		json
@@ -141,22 +248,75 @@ func (h *ResourceHandler) Get(c *gin.Context) {
 //	@Failure		500	{object}	ErrorResponse
 //	@Router			/resources/{id} [delete]
 func (h *ResourceHandler) Delete(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := uuid.Parse(idParam)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+
+	if err := h.resources.Remove(c.Request.Context(), id, requestActor(c)); err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "resource removed from inventory"})
+}
+
+// Reinstate godoc
+//
+//	@Summary		Reinstate removed resource
+//	@Description	Add a resource removed from the inventory back to it. It is active until the next scan sets its status.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Resource ID"	format(uuid)
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/resources/{id}/reinstate [post]
+func (h *ResourceHandler) Reinstate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid resource ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
 		return
 	}
 
-	result := h.db.Model(&model.Resource{}).Where("id = ?", id).Update("status", "deleted")
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to delete resource"})
+	if err := h.resources.Reinstate(c.Request.Context(), id, requestActor(c)); err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "resource not found"})
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "resource reinstated"})
+}
+
+// Restore godoc
+//
+//	@Summary		Restore quarantined resource
+//	@Description	Cancel the deletion of a quarantined resource and queue its restore: stopped resources are started again and the quarantine tag is removed. Refused while the installation or the organization is in read-only mode, which keeps the deletion on hold.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Resource ID"	format(uuid)
+//	@Success		202	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/resources/{id}/restore [post]
+func (h *ResourceHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+
+	if err := h.resources.Restore(c.Request.Context(), id); err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, MessageResponse{Message: "resource deleted"})
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "resource restore queued"})
 }
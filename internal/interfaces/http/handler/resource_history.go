@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// resourceHistorySort orders the timeline of a resource, newest first
+var resourceHistorySort = keysetSort{Name: "history", Column: "occurred_at", Desc: true}
+
+// ResourceHistoryRequest represents query parameters for the timeline of a
+// resource
+type ResourceHistoryRequest struct {
+	// Types keeps the events of these types, all of them when empty
+	Types  []string `form:"type" example:"status_changed"`
+	Cursor string   `form:"cursor" example:"eyJzIjoiaGlzdG9yeSJ9"`
+	Limit  int      `form:"limit,default=50" binding:"min=1,max=500" example:"50"`
+}
+
+// ResourceHistoryResponse is a page of the timeline of a resource
+type ResourceHistoryResponse struct {
+	Data  []ResourceEventDTO `json:"data"`
+	Limit int                `json:"limit" example:"50"`
+	// NextCursor fetches the older events when passed as cursor, empty on
+	// the last page
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiaGlzdG9yeSJ9"`
+}
+
+// History godoc
+//
+//	@Summary		Get resource history
+//	@Description	Get the timeline of a resource, newest first: when it was discovered, the scans that found it, the changes of its status and cost, the policies that matched it, the cleanups attempted on it, with their result and how to undo them, their rollbacks, and its exclusions and cleanup approvals. actor is the user ID or email that caused the event, or what recorded it: scan, billing, policy, cleanup or ci, and api for API clients without a session. Events older than the history retention of the organization are purged.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string		true	"Resource ID"	format(uuid)
+//	@Param			type	query		[]string	false	"Filter by event type"	Enums(discovered, scanned, status_changed, cost_changed, policy_matched, cleanup_attempted, cleanup_rolled_back, cleanup_approved, excluded)	collectionFormat(multi)
+//	@Param			cursor	query		string		false	"Cursor of the next page, from next_cursor"
+//	@Param			limit	query		int			false	"Number of events per page"	default(50)
+//	@Success		200		{object}	ResourceHistoryResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/resources/{id}/history [get]
+func (h *ResourceHandler) History(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+	var req ResourceHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	// Fetch one more event than the page to know whether older events
+	// follow
+	input := usecase.ResourceHistoryInput{ResourceID: id, Limit: req.Limit + 1}
+	for _, t := range req.Types {
+		input.Types = append(input.Types, entity.ResourceEventType(t))
+	}
+	if req.Cursor != "" {
+		key, err := resourceEventCursorKey(req.Cursor)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		input.After = key
+	}
+
+	out, err := h.resources.History(c.Request.Context(), input)
+	if err != nil {
+		apierror.RespondError(c, err)
+		return
+	}
+	events, next := keysetPage(resourceHistorySort, out.Events, req.Limit, func(e **entity.ResourceEvent) (string, string) {
+		return (*e).OccurredAt.Format(time.RFC3339Nano), (*e).ID.String()
+	})
+
+	dtos := make([]ResourceEventDTO, len(events))
+	for i, e := range events {
+		dtos[i] = toResourceEventDTO(e, out.PolicyNames)
+	}
+	c.JSON(http.StatusOK, ResourceHistoryResponse{Data: dtos, Limit: req.Limit, NextCursor: next})
+}
+
+// resourceEventCursorKey returns the position of a cursor of the timeline
+// of a resource
+func resourceEventCursorKey(cursor string) (*repository.ResourceEventKey, error) {
+	cur, err := resourceHistorySort.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, cur.Value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	id, err := uuid.Parse(cur.ID)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &repository.ResourceEventKey{OccurredAt: occurredAt, ID: id}, nil
+}
+
+func toResourceEventDTO(e *entity.ResourceEvent, policyNames map[uuid.UUID]string) ResourceEventDTO {
+	dto := ResourceEventDTO{
+		ID:         e.ID.String(),
+		Type:       string(e.Type),
+		Actor:      e.Actor,
+		Data:       e.Data,
+		TaskID:     e.TaskID,
+		OccurredAt: e.OccurredAt,
+	}
+	if e.ScanID != nil {
+		dto.ScanID = e.ScanID.String()
+	}
+	if e.PolicyID != nil {
+		dto.PolicyID = e.PolicyID.String()
+		dto.PolicyName = policyNames[*e.PolicyID]
+	}
+	return dto
+}
+
+// requestActor returns who a request is recorded as made by in the
+// timelines of resources: the user signed in, or api for API clients
+func requestActor(c *gin.Context) string {
+	if sess, ok := middleware.CurrentSession(c); ok && sess.UserID != "" {
+		return sess.UserID
+	}
+	return entity.ResourceEventActorAPI
+}
+
+// recordApprovals records the approval of the cleanup of resources in their
+// timelines, by an owner from a channel such as digest or slack
+func recordApprovals(ctx context.Context, db *gorm.DB, resources []model.Resource, by, channel string) error {
+	events := make([]*entity.ResourceEvent, len(resources))
+	for i, r := range resources {
+		events[i] = entity.NewResourceEvent(r.OrganizationID, r.ID, entity.ResourceEventCleanupApproved, by, map[string]any{"channel": channel})
+	}
+	return database.RecordResourceEvents(db.WithContext(ctx), events...)
+}
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/provider"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Limits of an inventory import
+const (
+	maxResourceImportSize = 10 << 20
+	maxResourceImportRows = 10000
+)
+
+// ResourceImportRow represents a resource of an inventory import. Fields
+// left empty keep the value of a resource already in the inventory.
+type ResourceImportRow struct {
+	Provider   string            `json:"provider" example:"aws"`
+	ResourceID string            `json:"resource_id" example:"vol-0123456789abcdef0"`
+	Type       string            `json:"type" example:"ebs_volume"`
+	Region     string            `json:"region" example:"eu-west-1"`
+	Name       string            `json:"name" example:"data-volume"`
+	AccountID  string            `json:"account_id" example:"123456789012"`
+	Tags       map[string]string `json:"tags"`
+	// MonthlyCost is in USD per month
+	MonthlyCost *float64 `json:"monthly_cost" example:"12.5"`
+	// Cost is accepted in place of monthly_cost
+	Cost *float64 `json:"cost,omitempty" example:"12.5"`
+}
+
+// ImportResourcesResponse reports the outcome of an inventory import
+type ImportResourcesResponse struct {
+	Rows    int `json:"rows" example:"120"`
+	Created int `json:"created" example:"100"`
+	Updated int `json:"updated" example:"18"`
+	Failed  int `json:"failed" example:"2"`
+	// Errors lists the rows left out, in file order
+	Errors []ImportRowError `json:"errors"`
+}
+
+// ImportRowError is a row of an inventory import that was left out
+type ImportRowError struct {
+	// Row is the line of CSV files and the position, from 1, in JSON arrays
+	Row        int    `json:"row" example:"7"`
+	ResourceID string `json:"resource_id,omitempty" example:"vol-0123456789abcdef0"`
+	Error      string `json:"error" example:"unknown resource type \"ebs\" for provider aws"`
+}
+
+// importRow is a parsed row and the error that leaves it out, if any
+type importRow struct {
+	line int
+	ResourceImportRow
+	err error
+}
+
+// Import godoc
+//
+//	@Summary		Import inventory
+//	@Description	Add resources to the inventory of an organization from a CMDB export, without waiting for a scan. The body is a CSV file with a header row (provider, resource_id, type, region, name, account_id, tags, monthly_cost or cost; other columns, e.g. those of resource exports, are ignored) or a JSON array of resources. Tags are a JSON object or "key=value;key=value" in CSV files. Resources are matched on provider and resource_id: new ones are created active, known ones updated with the fields given, their status left to scans. Invalid rows are left out and reported; the others are imported. New resources count towards the resources quota of the plan.
+//	@Tags			Resources
+//	@Accept			text/csv
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string				true	"Organization ID"	format(uuid)
+//	@Param			file			body		[]ResourceImportRow	true	"Resources, as CSV or JSON"
+//	@Success		200				{object}	DataResponse[ImportResourcesResponse]
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		402				{object}	QuotaErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Failure		409				{object}	ErrorResponse
+//	@Failure		415				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/resources/import [post]
+func (h *ResourceHandler) Import(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Query("organization_id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxResourceImportSize)
+	var rows []importRow
+	switch c.ContentType() {
+	case "text/csv":
+		rows, err = parseResourceImportCSV(body)
+	case "application/json":
+		rows, err = parseResourceImportJSON(body)
+	default:
+		apierror.Respond(c, http.StatusUnsupportedMediaType, "inventory imports are text/csv or application/json")
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		apierror.Respond(c, http.StatusBadRequest, "no resources to import")
+		return
+	}
+	if len(rows) > maxResourceImportRows {
+		apierror.Respond(c, http.StatusBadRequest, fmt.Sprintf("at most %d resources can be imported at once, got %d", maxResourceImportRows, len(rows)))
+		return
+	}
+
+	input := usecase.ImportResourcesInput{OrganizationID: orgID, Rows: make([]*usecase.ResourceImportRow, len(rows)), Actor: requestActor(c)}
+	for i := range rows {
+		input.Rows[i] = rows[i].importRow()
+	}
+	out, err := h.resources.Import(c.Request.Context(), input)
+	if err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
+		apierror.RespondError(c, err)
+		return
+	}
+
+	resp := ImportResourcesResponse{Rows: len(rows), Created: out.Created, Updated: out.Updated, Errors: []ImportRowError{}}
+	for _, row := range input.Rows {
+		if row.Err != nil {
+			resp.Errors = append(resp.Errors, ImportRowError{Row: row.Line, ResourceID: row.ResourceID, Error: row.Err.Error()})
+		}
+	}
+	resp.Failed = len(resp.Errors)
+
+	c.JSON(http.StatusOK, dataResponse(resp))
+}
+
+// importRow returns the row to import, with the error that leaves it out:
+// an unknown provider, a missing ID or type, a value too long for its
+// column or a negative cost. Types are checked on import.
+func (r *importRow) importRow() *usecase.ResourceImportRow {
+	if r.err == nil {
+		r.err = r.validate()
+	}
+	return &usecase.ResourceImportRow{
+		Line:        r.line,
+		Provider:    entity.CloudProvider(r.Provider),
+		ResourceID:  r.ResourceID,
+		Type:        entity.ResourceType(r.Type),
+		Region:      r.Region,
+		Name:        r.Name,
+		AccountID:   r.AccountID,
+		Tags:        r.Tags,
+		MonthlyCost: r.MonthlyCost,
+		Err:         r.err,
+	}
+}
+
+func (r *importRow) validate() error {
+	if r.Provider == "" {
+		return errors.New("provider is required")
+	}
+	if _, ok := provider.Lookup(r.Provider); !ok {
+		return fmt.Errorf("unknown provider %q", r.Provider)
+	}
+	if r.ResourceID == "" {
+		return errors.New("resource_id is required")
+	}
+	if r.Type == "" {
+		return errors.New("type is required")
+	}
+	for _, f := range []struct {
+		name, value string
+		max         int
+	}{
+		{"resource_id", r.ResourceID, 255},
+		{"region", r.Region, 50},
+		{"name", r.Name, 255},
+		{"account_id", r.AccountID, 255},
+	} {
+		if len(f.value) > f.max {
+			return fmt.Errorf("%s is longer than %d characters", f.name, f.max)
+		}
+	}
+	if r.Cost != nil {
+		if r.MonthlyCost != nil && *r.MonthlyCost != *r.Cost {
+			return errors.New("monthly_cost and cost differ")
+		}
+		r.MonthlyCost = r.Cost
+	}
+	if r.MonthlyCost != nil && (*r.MonthlyCost < 0 || *r.MonthlyCost >= 1e8) {
+		return fmt.Errorf("monthly_cost %v is out of range", *r.MonthlyCost)
+	}
+	return nil
+}
+
+// resourceImportColumns are the columns read from CSV imports
+var resourceImportColumns = []string{"provider", "resource_id", "type", "region", "name", "account_id", "tags", "monthly_cost", "cost"}
+
+// parseResourceImportCSV reads the rows of a CSV import. The first row is
+// the header; provider, resource_id and type are required columns. Rows
+// whose values cannot be read are returned with their error.
+func parseResourceImportCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid inventory file: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, known := range resourceImportColumns {
+			if name == known {
+				columns[name] = i
+			}
+		}
+	}
+	for _, required := range []string{"provider", "resource_id", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("inventory file has no %s column", required)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid inventory file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line}
+		if len(record) != len(header) {
+			row.err = fmt.Errorf("expected %d columns, got %d", len(header), len(record))
+			rows = append(rows, row)
+			continue
+		}
+		value := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row.Provider = strings.ToLower(value("provider"))
+		row.ResourceID = value("resource_id")
+		row.Type = value("type")
+		row.Region = value("region")
+		row.Name = value("name")
+		row.AccountID = value("account_id")
+		if row.Tags, err = parseImportTags(value("tags")); err != nil {
+			row.err = err
+		}
+		for _, column := range []string{"monthly_cost", "cost"} {
+			if v := value(column); v != "" && row.err == nil {
+				cost, err := strconv.ParseFloat(strings.TrimPrefix(v, "$"), 64)
+				if err != nil {
+					row.err = fmt.Errorf("invalid %s %q", column, v)
+					break
+				}
+				if column == "cost" {
+					row.Cost = &cost
+				} else {
+					row.MonthlyCost = &cost
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseImportTags reads the tags of a CSV row: a JSON object, as in
+// resource exports, or "key=value" pairs separated by semicolons
+func parseImportTags(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	tags := make(map[string]string)
+	if strings.HasPrefix(s, "{") {
+		var values map[string]any
+		if err := json.Unmarshal([]byte(s), &values); err != nil {
+			return nil, fmt.Errorf("invalid tags: %v", err)
+		}
+		for k, v := range values {
+			tags[k] = fmt.Sprint(v)
+		}
+		return tags, nil
+	}
+	for _, pair := range strings.Split(s, ";") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", pair)
+		}
+		tags[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return tags, nil
+}
+
+// parseResourceImportJSON reads the rows of a JSON import, an array of
+// resources. Items that are not resources are returned with their error.
+func parseResourceImportJSON(r io.Reader) ([]importRow, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("invalid inventory file: expected a JSON array of resources: %v", err)
+	}
+	rows := make([]importRow, len(items))
+	for i, item := range items {
+		rows[i].line = i + 1
+		if err := json.Unmarshal(item, &rows[i].ResourceImportRow); err != nil {
+			rows[i].err = fmt.Errorf("invalid resource: %v", err)
+			continue
+		}
+		rows[i].Provider = strings.ToLower(strings.TrimSpace(rows[i].Provider))
+		rows[i].ResourceID = strings.TrimSpace(rows[i].ResourceID)
+		rows[i].Type = strings.TrimSpace(rows[i].Type)
+	}
+	return rows, nil
+}
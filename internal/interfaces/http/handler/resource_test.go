@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+)
+
+// fakeResources is a ResourceService whose methods are set by each test
+type fakeResources struct {
+	usecase.ResourceService
+	list      func(usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error)
+	view      func(uuid.UUID) (*entity.ResourceView, error)
+	remove    func(uuid.UUID, string) error
+	reinstate func(uuid.UUID, string) error
+	restore   func(uuid.UUID) error
+	history   func(usecase.ResourceHistoryInput) (*usecase.ResourceHistoryOutput, error)
+	importFn  func(usecase.ImportResourcesInput) (*usecase.ImportResourcesOutput, error)
+}
+
+func (f *fakeResources) List(ctx context.Context, input usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error) {
+	return f.list(input)
+}
+
+func (f *fakeResources) View(ctx context.Context, id uuid.UUID) (*entity.ResourceView, error) {
+	return f.view(id)
+}
+
+func (f *fakeResources) Remove(ctx context.Context, id uuid.UUID, actor string) error {
+	return f.remove(id, actor)
+}
+
+func (f *fakeResources) Reinstate(ctx context.Context, id uuid.UUID, actor string) error {
+	return f.reinstate(id, actor)
+}
+
+func (f *fakeResources) Restore(ctx context.Context, id uuid.UUID) error {
+	return f.restore(id)
+}
+
+func (f *fakeResources) History(ctx context.Context, input usecase.ResourceHistoryInput) (*usecase.ResourceHistoryOutput, error) {
+	return f.history(input)
+}
+
+func (f *fakeResources) Import(ctx context.Context, input usecase.ImportResourcesInput) (*usecase.ImportResourcesOutput, error) {
+	return f.importFn(input)
+}
+
+// costlyVolumes returns n volumes of decreasing cost
+func costlyVolumes(n int) []*entity.Resource {
+	resources := make([]*entity.Resource, n)
+	for i := range resources {
+		resources[i] = entity.NewResource(goldenOrgID, entity.CloudProviderAWS, entity.ResourceTypeEBSVolume, "vol-"+string(rune('a'+i)), "eu-west-3", "")
+		resources[i].MonthlyCost = float64(100 - 10*i)
+	}
+	return resources
+}
+
+func TestResourceHandlerListView(t *testing.T) {
+	viewID := uuid.New()
+	var got usecase.ListResourcesInput
+	h := NewResourceHandler(&fakeResources{
+		view: func(id uuid.UUID) (*entity.ResourceView, error) {
+			if id != viewID {
+				t.Errorf("view %s fetched, want %s", id, viewID)
+			}
+			return &entity.ResourceView{ID: id, OrganizationID: goldenOrgID, Filters: entity.ResourceViewFilter{
+				Provider: "aws", Status: "unused", Sort: "cost", MinCost: 50,
+			}}, nil
+		},
+		list: func(input usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error) {
+			got = input
+			return &usecase.ListResourcesOutput{Resources: costlyVolumes(3), Total: 12}, nil
+		},
+	})
+
+	w := serve(http.MethodGet, "/resources", "/resources?view_id="+viewID.String()+"&status=active&limit=2", "", h.List)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	want := entity.ResourceViewFilter{Provider: "aws", Status: "active", Sort: "cost", MinCost: 50}
+	if got.Filter != want || got.OrganizationID == nil || *got.OrganizationID != goldenOrgID || got.Limit != 2 || !got.IncludeTotal {
+		t.Errorf("input = %+v, want the view filters overridden by the query", got)
+	}
+	resp := decode[PaginatedResponse](t, w)
+	if resp.Total != 12 || len(resp.Data.([]any)) != 2 || resp.NextCursor == "" {
+		t.Fatalf("response = %+v, want 2 resources of 12 and a next cursor", resp)
+	}
+
+	// The cursor continues after the cost of the last resource of the page
+	w = serve(http.MethodGet, "/resources", "/resources?view_id="+viewID.String()+"&limit=2&offset=4&cursor="+url.QueryEscape(resp.NextCursor), "", h.List)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.After == nil || got.After.Value != 90 || got.After.ID == uuid.Nil {
+		t.Errorf("after = %+v, want the key of the second volume", got.After)
+	}
+	if resp := decode[PaginatedResponse](t, w); resp.Offset != 0 {
+		t.Errorf("offset = %d, want 0 with a cursor", resp.Offset)
+	}
+
+	// A cursor of another sort is refused
+	w = serve(http.MethodGet, "/resources", "/resources?sort=carbon&cursor="+url.QueryEscape(resp.NextCursor), "", h.List)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status of a cursor of another sort = %d, want 400", w.Code)
+	}
+}
+
+func TestResourceHandlerListInvalidSearch(t *testing.T) {
+	h := NewResourceHandler(&fakeResources{list: func(usecase.ListResourcesInput) (*usecase.ListResourcesOutput, error) {
+		return nil, apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, `unterminated quote in "tag:env="prod"`)
+	}})
+
+	w := serve(http.MethodGet, "/resources", `/resources?q=tag:env=%22prod`, "", h.List)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+	}
+}
+
+func TestResourceHandlerStatusChanges(t *testing.T) {
+	id := uuid.New()
+	h := NewResourceHandler(&fakeResources{
+		remove: func(got uuid.UUID, actor string) error {
+			if got != id || actor != entity.ResourceEventActorAPI {
+				t.Errorf("Remove(%s, %s), want %s by %s", got, actor, id, entity.ResourceEventActorAPI)
+			}
+			return nil
+		},
+		reinstate: func(uuid.UUID, string) error {
+			return apperrors.NewWithCode(apperrors.ErrInvalidInput, apperrors.CodeConflict, "resource is not removed from inventory")
+		},
+		restore: func(uuid.UUID) error {
+			readOnly := &service.ReadOnlyError{Scope: service.ReadOnlyOrganization, Reason: "incident"}
+			return &apperrors.AppError{Err: readOnly, Code: apperrors.CodeReadOnly, Message: readOnly.Error(),
+				Details: map[string]any{"scope": readOnly.Scope, "reason": readOnly.Reason}}
+		},
+	})
+
+	if w := serve(http.MethodDelete, "/resources/:id", "/resources/"+id.String(), "", h.Delete); w.Code != http.StatusOK {
+		t.Errorf("Delete status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if w := serve(http.MethodPost, "/resources/:id/reinstate", "/resources/"+id.String()+"/reinstate", "", h.Reinstate); w.Code != http.StatusConflict {
+		t.Errorf("Reinstate status = %d, want 409: %s", w.Code, w.Body)
+	}
+	w := serve(http.MethodPost, "/resources/:id/restore", "/resources/"+id.String()+"/restore", "", h.Restore)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"message":"incident"`) {
+		t.Errorf("Restore status = %d, body = %s, want 403 with the reason", w.Code, w.Body)
+	}
+}
+
+func TestResourceHandlerHistory(t *testing.T) {
+	id, policyID := uuid.New(), uuid.New()
+	var got usecase.ResourceHistoryInput
+	h := NewResourceHandler(&fakeResources{history: func(input usecase.ResourceHistoryInput) (*usecase.ResourceHistoryOutput, error) {
+		got = input
+		events := make([]*entity.ResourceEvent, 3)
+		for i := range events {
+			events[i] = entity.NewResourceEvent(goldenOrgID, id, entity.ResourceEventPolicyMatched, "policy", nil)
+			events[i].PolicyID = &policyID
+			events[i].OccurredAt = goldenUpdated.Add(-time.Duration(i) * time.Hour)
+		}
+		return &usecase.ResourceHistoryOutput{Events: events, PolicyNames: map[uuid.UUID]string{policyID: "Unattached volumes"}}, nil
+	}})
+
+	w := serve(http.MethodGet, "/resources/:id/history", "/resources/"+id.String()+"/history?type=policy_matched&limit=2", "", h.History)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got.ResourceID != id || got.Limit != 3 || len(got.Types) != 1 || got.Types[0] != entity.ResourceEventPolicyMatched {
+		t.Errorf("input = %+v", got)
+	}
+	resp := decode[ResourceHistoryResponse](t, w)
+	if len(resp.Data) != 2 || resp.Data[1].PolicyName != "Unattached volumes" || resp.NextCursor == "" {
+		t.Fatalf("response = %+v, want 2 events named after their policy and a next cursor", resp)
+	}
+
+	serve(http.MethodGet, "/resources/:id/history", "/resources/"+id.String()+"/history?cursor="+url.QueryEscape(resp.NextCursor), "", h.History)
+	if got.After == nil || !got.After.OccurredAt.Equal(goldenUpdated.Add(-time.Hour)) {
+		t.Errorf("after = %+v, want the second event", got.After)
+	}
+}
+
+func TestResourceHandlerImport(t *testing.T) {
+	var got usecase.ImportResourcesInput
+	h := NewResourceHandler(&fakeResources{importFn: func(input usecase.ImportResourcesInput) (*usecase.ImportResourcesOutput, error) {
+		got = input
+		for _, row := range input.Rows {
+			if row.Err == nil && row.Type == "ebs" {
+				row.Err = errors.New(`unknown resource type "ebs" for provider aws`)
+			}
+		}
+		return &usecase.ImportResourcesOutput{Created: 1}, nil
+	}})
+
+	csv := "provider,resource_id,type,region,tags,cost\n" +
+		"aws,vol-1,ebs_volume,eu-west-3,env=prod;team=data,$12.5\n" +
+		"aws,vol-2,ebs,eu-west-3,,\n" +
+		"oracle,ocid-1,block_volume,eu-paris-1,,\n" +
+		"aws,vol-3,ebs_volume,eu-west-3,,-4\n"
+	target := "/resources/import?organization_id=" + goldenOrgID.String()
+	w := serve(http.MethodPost, "/resources/import", target, "", h.Import, "Content-Type", "text/csv")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status of an empty import = %d, want 400", w.Code)
+	}
+	w = serve(http.MethodPost, "/resources/import", target, csv, h.Import, "Content-Type", "text/csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	if got.OrganizationID != goldenOrgID || got.Actor != entity.ResourceEventActorAPI || len(got.Rows) != 4 {
+		t.Fatalf("input = %+v", got)
+	}
+	if row := got.Rows[0]; row.Err != nil || row.Line != 2 || row.Tags["team"] != "data" || row.MonthlyCost == nil || *row.MonthlyCost != 12.5 {
+		t.Errorf("rows[0] = %+v, want vol-1 with its tags and cost", row)
+	}
+
+	resp := decode[DataResponse[ImportResourcesResponse]](t, w).Data
+	want := []ImportRowError{
+		{Row: 3, ResourceID: "vol-2", Error: `unknown resource type "ebs" for provider aws`},
+		{Row: 4, ResourceID: "ocid-1", Error: `unknown provider "oracle"`},
+		{Row: 5, ResourceID: "vol-3", Error: "monthly_cost -4 is out of range"},
+	}
+	if resp.Rows != 4 || resp.Created != 1 || resp.Failed != 3 || len(resp.Errors) != len(want) {
+		t.Fatalf("response = %+v", resp)
+	}
+	for i := range want {
+		if resp.Errors[i] != want[i] {
+			t.Errorf("errors[%d] = %+v, want %+v", i, resp.Errors[i], want[i])
+		}
+	}
+}
+
+func TestResourceHandlerImportQuota(t *testing.T) {
+	h := NewResourceHandler(&fakeResources{importFn: func(usecase.ImportResourcesInput) (*usecase.ImportResourcesOutput, error) {
+		return nil, &entity.QuotaExceededError{Quota: entity.QuotaResources, Plan: "free", Limit: 100, Used: 100, Needed: 101, Upgrade: "pro"}
+	}})
+
+	w := serve(http.MethodPost, "/resources/import", "/resources/import?organization_id="+goldenOrgID.String(),
+		`[{"provider": "aws", "resource_id": "vol-1", "type": "ebs_volume"}]`, h.Import)
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("status = %d, want 402: %s", w.Code, w.Body)
+	}
+	if resp := decode[QuotaErrorResponse](t, w); resp.Quota != string(entity.QuotaResources) || resp.Upgrade != "pro" {
+		t.Errorf("response = %+v", resp)
+	}
+}
@@ -0,0 +1,372 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ResourceViewHandler handles saved resource view endpoints
+type ResourceViewHandler struct {
+	db *gorm.DB
+}
+
+// NewResourceViewHandler creates a new ResourceViewHandler
+func NewResourceViewHandler(db *gorm.DB) *ResourceViewHandler {
+	return &ResourceViewHandler{db: db}
+}
+
+// ResourceFilter filters the resource list. It is given as query parameters
+// of the list or saved as a view.
+type ResourceFilter struct {
+	Provider string `form:"provider" json:"provider,omitempty" example:"aws"`
+	Type     string `form:"type" json:"type,omitempty" example:"ebs_volume"`
+	Status   string `form:"status" json:"status,omitempty" example:"unused"`
+	Region   string `form:"region" json:"region,omitempty" example:"us-east-1"`
+	// AccountID is the provider account, subscription, project or cluster
+	AccountID string `form:"account_id" json:"account_id,omitempty" example:"123456789012"`
+	Owner     string `form:"owner" json:"owner,omitempty" example:"alice@example.com"`
+	// Q searches names, cloud IDs and tag values, with tag:key=value and
+	// tag:key!=value selectors
+	Q string `form:"q" json:"q,omitempty" example:"tag:env=prod"`
+	// MinCost keeps resources costing at least this much per month
+	MinCost float64 `form:"min_cost" json:"min_cost,omitempty" binding:"gte=0" example:"50"`
+	Sort    string  `form:"sort" json:"sort,omitempty" binding:"omitempty,oneof=created cost carbon age" example:"cost"`
+	Order   string  `form:"order" json:"order,omitempty" binding:"omitempty,oneof=asc desc" example:"desc"`
+}
+
+// withDefaults returns the filter with its unset fields taken from base
+func (f ResourceFilter) withDefaults(base ResourceFilter) ResourceFilter {
+	pick := func(v, d string) string {
+		if v != "" {
+			return v
+		}
+		return d
+	}
+	f.Provider = pick(f.Provider, base.Provider)
+	f.Type = pick(f.Type, base.Type)
+	f.Status = pick(f.Status, base.Status)
+	f.Region = pick(f.Region, base.Region)
+	f.AccountID = pick(f.AccountID, base.AccountID)
+	f.Owner = pick(f.Owner, base.Owner)
+	f.Q = pick(f.Q, base.Q)
+	f.Sort = pick(f.Sort, base.Sort)
+	f.Order = pick(f.Order, base.Order)
+	if f.MinCost == 0 {
+		f.MinCost = base.MinCost
+	}
+	return f
+}
+
+// validate checks the search of the filter
+func (f ResourceFilter) validate() error {
+	if f.Q == "" {
+		return nil
+	}
+	_, err := service.ParseResourceSearch(f.Q)
+	return err
+}
+
+// CreateResourceViewRequest represents a request to save a resource view
+type CreateResourceViewRequest struct {
+	OrganizationID string         `json:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name           string         `json:"name" binding:"required,max=255" example:"Unused prod EBS > $50"`
+	Description    string         `json:"description" example:"Production EBS volumes left unattached"`
+	Filters        ResourceFilter `json:"filters"`
+	CreatedBy      string         `json:"created_by" example:"alice@example.com"`
+}
+
+// UpdateResourceViewRequest represents a request to change a resource view
+type UpdateResourceViewRequest struct {
+	Name        string         `json:"name" binding:"required,max=255" example:"Unused prod EBS > $50"`
+	Description string         `json:"description" example:"Production EBS volumes left unattached"`
+	Filters     ResourceFilter `json:"filters"`
+}
+
+// ListResourceViewsRequest represents query parameters for listing views
+type ListResourceViewsRequest struct {
+	OrganizationID string `form:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Limit          int    `form:"limit,default=20" example:"20"`
+	Offset         int    `form:"offset,default=0" example:"0"`
+}
+
+// Create godoc
+//
+//	@Summary		Create resource view
+//	@Description	Save a named filter of the resource list. Views apply to the resource list with view_id, and can select the resources of policies and cleanups.
+//	@Tags			Resource Views
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateResourceViewRequest	true	"View request"
+//	@Success		201		{object}	DataResponse[ResourceViewDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/resource-views [post]
+func (h *ResourceViewHandler) Create(c *gin.Context) {
+	var req CreateResourceViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	if err := req.Filters.validate(); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !requireActiveOrganization(c, h.db, orgID) {
+		return
+	}
+	if !h.checkName(c, orgID, uuid.Nil, req.Name) {
+		return
+	}
+
+	view := model.ResourceView{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Filters:        resourceFilterJSONB(req.Filters),
+		CreatedBy:      req.CreatedBy,
+	}
+	if err := h.db.WithContext(c.Request.Context()).Create(&view).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to create resource view")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dataResponse(toResourceViewDTO(&view)))
+}
+
+// List godoc
+//
+//	@Summary		List resource views
+//	@Description	Get the saved resource views of an organization, by name
+//	@Tags			Resource Views
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	true	"Organization ID"	format(uuid)
+//	@Param			limit			query		int		false	"Number of items per page"	default(20)
+//	@Param			offset			query		int		false	"Number of items to skip"	default(0)
+//	@Success		200				{object}	PaginatedResponse{data=[]ResourceViewDTO}
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/resource-views [get]
+func (h *ResourceViewHandler) List(c *gin.Context) {
+	var req ListResourceViewsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.ResourceView{}).Where("organization_id = ?", orgID)
+
+	var total int64
+	query.Count(&total)
+
+	var views []model.ResourceView
+	if err := query.Limit(req.Limit).Offset(req.Offset).Order("name, id").Find(&views).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch resource views")
+		return
+	}
+
+	data := make([]ResourceViewDTO, len(views))
+	for i := range views {
+		data[i] = toResourceViewDTO(&views[i])
+	}
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   data,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+// Get godoc
+//
+//	@Summary		Get resource view
+//	@Description	Get a saved resource view by its ID
+//	@Tags			Resource Views
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"View ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[ResourceViewDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/resource-views/{id} [get]
+func (h *ResourceViewHandler) Get(c *gin.Context) {
+	view, ok := h.findView(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(toResourceViewDTO(view)))
+}
+
+// Update godoc
+//
+//	@Summary		Update resource view
+//	@Description	Rename a saved resource view or replace its filters. Policies and cleanups referencing the view use the new filters from then on.
+//	@Tags			Resource Views
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"View ID"	format(uuid)
+//	@Param			request	body		UpdateResourceViewRequest	true	"View update request"
+//	@Success		200		{object}	DataResponse[ResourceViewDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/resource-views/{id} [put]
+func (h *ResourceViewHandler) Update(c *gin.Context) {
+	view, ok := h.findView(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateResourceViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if err := req.Filters.validate(); err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.checkName(c, view.OrganizationID, view.ID, req.Name) {
+		return
+	}
+
+	view.Name = req.Name
+	view.Description = req.Description
+	view.Filters = resourceFilterJSONB(req.Filters)
+	if err := h.db.WithContext(c.Request.Context()).Save(view).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update resource view")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toResourceViewDTO(view)))
+}
+
+// Delete godoc
+//
+//	@Summary		Delete resource view
+//	@Description	Delete a saved resource view. Views selecting the resources of a policy cannot be deleted.
+//	@Tags			Resource Views
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"View ID"	format(uuid)
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/resource-views/{id} [delete]
+func (h *ResourceViewHandler) Delete(c *gin.Context) {
+	view, ok := h.findView(c)
+	if !ok {
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	var policies int64
+	if err := db.Model(&model.Policy{}).Where("view_id = ?", view.ID).Count(&policies).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch policies")
+		return
+	}
+	if policies > 0 {
+		apierror.Respond(c, http.StatusConflict, "resource view is used by policies")
+		return
+	}
+
+	if err := db.Delete(&model.ResourceView{}, "id = ?", view.ID).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to delete resource view")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "resource view deleted"})
+}
+
+// findView loads the view of the request path. It writes the error response
+// and returns false when there is none.
+func (h *ResourceViewHandler) findView(c *gin.Context) (*model.ResourceView, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid view ID")
+		return nil, false
+	}
+	var view model.ResourceView
+	if err := h.db.WithContext(c.Request.Context()).First(&view, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "resource view not found")
+			return nil, false
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch resource view")
+		return nil, false
+	}
+	return &view, true
+}
+
+// checkName refuses a view name already used by another view of the
+// organization. It writes the error response and returns false when the
+// view must not be saved.
+func (h *ResourceViewHandler) checkName(c *gin.Context, orgID, viewID uuid.UUID, name string) bool {
+	var count int64
+	err := h.db.WithContext(c.Request.Context()).Model(&model.ResourceView{}).
+		Where("organization_id = ? AND name = ? AND id <> ?", orgID, name, viewID).
+		Count(&count).Error
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch resource views")
+		return false
+	}
+	if count > 0 {
+		apierror.Respond(c, http.StatusConflict, "a resource view with this name already exists")
+		return false
+	}
+	return true
+}
+
+// resourceViewFilter returns the filter saved in a view
+func resourceViewFilter(view *model.ResourceView) ResourceFilter {
+	var f ResourceFilter
+	raw, _ := json.Marshal(view.Filters)
+	_ = json.Unmarshal(raw, &f)
+	return f
+}
+
+// resourceFilterJSONB returns the stored form of a filter
+func resourceFilterJSONB(f ResourceFilter) model.JSONB {
+	out := model.JSONB{}
+	raw, _ := json.Marshal(f)
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+func toResourceViewDTO(view *model.ResourceView) ResourceViewDTO {
+	return ResourceViewDTO{
+		ID:             view.ID.String(),
+		OrganizationID: view.OrganizationID.String(),
+		Name:           view.Name,
+		Description:    view.Description,
+		Filters:        resourceViewFilter(view),
+		CreatedBy:      view.CreatedBy,
+		CreatedAt:      view.CreatedAt,
+		UpdatedAt:      view.UpdatedAt,
+	}
+}
@@ -1,37 +1,36 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
+	"time"
 
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
-	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/domain/repository"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
-	"gorm.io/gorm"
 )
 
 // ScanHandler handles scan endpoints
 type ScanHandler struct {
-	db          *gorm.DB
-	queueClient *asynq.Client
+	scans usecase.ScanService
 }
 
 // NewScanHandler creates a new ScanHandler
-func NewScanHandler(db *gorm.DB, queueClient *asynq.Client) *ScanHandler {
-	return &ScanHandler{
-		db:          db,
-		queueClient: queueClient,
-	}
+func NewScanHandler(scans usecase.ScanService) *ScanHandler {
+	return &ScanHandler{scans: scans}
 }
 
 // CreateScanRequest represents a request to create a new scan
 type CreateScanRequest struct {
 	OrganizationID string   `json:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Provider       string   `json:"provider" binding:"required,oneof=aws azure gcp" example:"aws"`
-	Regions        []string `json:"regions" binding:"required,min=1" example:"us-east-1,eu-west-1"`
+	Provider       string   `json:"provider" binding:"required,provider" example:"aws"`
+	Regions        []string `json:"regions" example:"us-east-1,eu-west-1"`
 	ResourceTypes  []string `json:"resource_types" example:"ec2_instance,ebs_volume"`
+	Force          bool     `json:"force" example:"false"`
+	// Incremental only refreshes the resources changed since the last scan
+	Incremental bool `json:"incremental" example:"false"`
 }
 
 // CreateScanResponse represents the response after creating a scan
@@ -43,82 +42,119 @@ type CreateScanResponse struct {
 // Create godoc
 //
 //	@Summary		Create a new scan
-//	@Description	Create a new cloud resource scan and queue it for processing
+//	@Description	Create a new cloud resource scan and queue it for processing.
+//	@Description	If an identical scan (same organization, provider, regions and resource types) is already pending or running, it is returned with status 200 instead. Set force to queue a new scan anyway.
+//	@Description	Set incremental to only refresh the resources the provider reports as changed since the last scan; the scan runs in full when the scope was not scanned in full recently or the provider does not track changes.
+//	@Description	When regions are omitted, the organization's default regions are scanned. Regions on the organization's denylist are rejected.
+//	@Description	Scans beyond the daily quota of the organization's plan are rejected with 429; organizations over their cloud accounts or resources quota get 402.
 //	@Tags			Scans
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		CreateScanRequest	true	"Scan request"
+//	@Success		200		{object}	CreateScanResponse
 //	@Success		201		{object}	CreateScanResponse
 //	@Failure		400		{object}	ErrorResponse
+//	@Failure		402		{object}	QuotaErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		429		{object}	QuotaErrorResponse
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/scans [post]
 func (h *ScanHandler) Create(c *gin.Context) {
 	var req CreateScanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
 
 	orgID, err := uuid.Parse(req.OrganizationID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid organization ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
 		return
 	}
 
-	// Create scan record
-	scan := model.Scan{
-		ID:             uuid.New(),
+	resourceTypes := make([]entity.ResourceType, len(req.ResourceTypes))
+	for i, t := range req.ResourceTypes {
+		resourceTypes[i] = entity.ResourceType(t)
+	}
+	out, err := h.scans.Create(c.Request.Context(), usecase.CreateScanInput{
 		OrganizationID: orgID,
-		Provider:       req.Provider,
+		Provider:       entity.CloudProvider(req.Provider),
 		Regions:        req.Regions,
-		ResourceTypes:  req.ResourceTypes,
-		Status:         "pending",
-	}
-
-	if err := h.db.Create(&scan).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to create scan"})
+		ResourceTypes:  resourceTypes,
+		Force:          req.Force,
+		Incremental:    req.Incremental,
+	})
+	if err != nil {
+		if !respondQuotaError(c, err) {
+			apierror.RespondError(c, err)
+		}
 		return
 	}
 
-	// Enqueue scan task
-	payload, _ := json.Marshal(queue.ScanResourcesPayload{
-		OrganizationID: req.OrganizationID,
-		Provider:       req.Provider,
-		Regions:        req.Regions,
-		ResourceTypes:  req.ResourceTypes,
-	})
-
-	task := asynq.NewTask(queue.TaskTypeScanResources, payload)
-	if _, err := h.queueClient.Enqueue(task); err != nil {
-		// Update scan status to failed
-		h.db.Model(&scan).Update("status", "failed")
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to enqueue scan task"})
+	if out.Existing {
+		c.JSON(http.StatusOK, CreateScanResponse{
+			Data:    toScanDTO(out.Scan),
+			Message: "an identical scan is already in progress",
+		})
 		return
 	}
-
 	c.JSON(http.StatusCreated, CreateScanResponse{
-		Data: ScanDTO{
-			ID:             scan.ID.String(),
-			OrganizationID: scan.OrganizationID.String(),
-			Provider:       scan.Provider,
-			Regions:        scan.Regions,
-			ResourceTypes:  scan.ResourceTypes,
-			Status:         scan.Status,
-			CreatedAt:      scan.CreatedAt,
-			UpdatedAt:      scan.UpdatedAt,
-		},
+		Data:    toScanDTO(out.Scan),
 		Message: "scan created and queued for processing",
 	})
 }
 
+func toScanDTO(s *entity.Scan) ScanDTO {
+	resourceTypes := make([]string, len(s.ResourceTypes))
+	for i, t := range s.ResourceTypes {
+		resourceTypes[i] = string(t)
+	}
+	var failedRegions map[string]any
+	if len(s.FailedRegions) > 0 {
+		failedRegions = make(map[string]any, len(s.FailedRegions))
+		for region, msg := range s.FailedRegions {
+			failedRegions[region] = msg
+		}
+	}
+	return ScanDTO{
+		ID:               s.ID.String(),
+		OrganizationID:   s.OrganizationID.String(),
+		Provider:         string(s.Provider),
+		Regions:          s.Regions,
+		ResourceTypes:    resourceTypes,
+		Status:           string(s.Status),
+		Mode:             string(s.Mode),
+		ChangesSince:     s.ChangesSince,
+		ResourcesFound:   s.ResourcesFound,
+		UnusedFound:      s.UnusedFound,
+		ResourcesNew:     s.ResourcesNew,
+		ResourcesChanged: s.ResourcesChanged,
+		ResourcesRemoved: s.ResourcesRemoved,
+		EstimatedSavings: s.EstimatedSavings,
+		CarbonSavings:    s.CarbonSavings,
+		ErrorMessage:     s.ErrorMessage,
+		FailedRegions:    failedRegions,
+		StartedAt:        s.StartedAt,
+		CompletedAt:      s.CompletedAt,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+		Version:          s.Version,
+	}
+}
+
 // ListScansRequest represents query parameters for listing scans
 type ListScansRequest struct {
 	Provider string `form:"provider" example:"aws"`
 	Status   string `form:"status" example:"completed"`
+	Cursor   string `form:"cursor" example:"eyJzIjoiY3JlYXRlZCJ9"`
 	Limit    int    `form:"limit,default=20" example:"20"`
 	Offset   int    `form:"offset,default=0" example:"0"`
 }
 
+// scanSort is the order of the scan list, most recent first
+var scanSort = keysetSort{Name: "created", Column: "created_at", Desc: true}
+
 // List godoc
 //
 //	@Summary		List scans
@@ -126,10 +162,11 @@ type ListScansRequest struct {
 //	@Tags			Scans
 //	@Accept			json
 //	@Produce		json
-//	@Param			provider	query		string	false	"Filter by cloud provider"	Enums(aws, azure, gcp)
-//	@Param			status		query		string	false	"Filter by status"	Enums(pending, running, completed, failed, cancelled)
+//	@Param			provider	query		string	false	"Filter by cloud provider"
+//	@Param			status		query		string	false	"Filter by status"	Enums(pending, running, completed, partial, failed, cancelled)
 //	@Param			limit		query		int		false	"Number of items per page"	default(20)
 //	@Param			offset		query		int		false	"Number of items to skip"	default(0)
+//	@Param			cursor		query		string	false	"Cursor of the next page, from next_cursor; offset is ignored"
 //	@Success		200			{object}	PaginatedResponse{data=[]ScanDTO}
 //	@Failure		400			{object}	ErrorResponse
 //	@Failure		500			{object}	ErrorResponse
@@ -137,36 +174,70 @@ type ListScansRequest struct {
 func (h *ScanHandler) List(c *gin.Context) {
 	var req ListScansRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		apierror.RespondInvalid(c, err)
 		return
 	}
 
-	query := h.db.Model(&model.Scan{})
-
+	// Fetch one more scan than the page to know whether another page
+	// follows
+	filter := repository.ScanFilter{Limit: req.Limit + 1, Offset: req.Offset}
 	if req.Provider != "" {
-		query = query.Where("provider = ?", req.Provider)
+		provider := entity.CloudProvider(req.Provider)
+		filter.Provider = &provider
 	}
 	if req.Status != "" {
-		query = query.Where("status = ?", req.Status)
+		status := entity.ScanStatus(req.Status)
+		filter.Status = &status
+	}
+	if req.Cursor != "" {
+		key, err := scanCursorKey(req.Cursor)
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.After = key
+		req.Offset, filter.Offset = 0, 0
 	}
 
-	var total int64
-	query.Count(&total)
-
-	var scans []model.Scan
-	if err := query.Limit(req.Limit).Offset(req.Offset).Order("created_at DESC").Find(&scans).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch scans"})
+	scans, total, err := h.scans.List(c.Request.Context(), filter)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
+	scans, next := keysetPage(scanSort, scans, req.Limit, func(s **entity.Scan) (string, string) {
+		return (*s).CreatedAt.Format(time.RFC3339Nano), (*s).ID.String()
+	})
 
+	data := make([]ScanDTO, len(scans))
+	for i, s := range scans {
+		data[i] = toScanDTO(s)
+	}
 	c.JSON(http.StatusOK, PaginatedResponse{
-		Data:   scans,
-		Total:  total,
-		Limit:  req.Limit,
-		Offset: req.Offset,
+		Data:       data,
+		Total:      total,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+		NextCursor: next,
 	})
 }
 
+// scanCursorKey returns the position of a cursor of the scan list
+func scanCursorKey(cursor string) (*repository.ScanKey, error) {
+	cur, err := scanSort.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, cur.Value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	id, err := uuid.Parse(cur.ID)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &repository.ScanKey{CreatedAt: createdAt, ID: id}, nil
+}
+
 // Get godoc
 //
 //	@Summary		Get scan by ID
@@ -175,7 +246,7 @@ func (h *ScanHandler) List(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		string	true	"Scan ID"	format(uuid)
-//	@Success		200	{object}	map[string]ScanDTO
+//	@Success		200	{object}	DataResponse[ScanDTO]
 //	@Failure		400	{object}	ErrorResponse
 //	@Failure		404	{object}	ErrorResponse
 //	@Failure		500	{object}	ErrorResponse
@@ -184,19 +255,15 @@ func (h *ScanHandler) Get(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid scan ID"})
+		apierror.Respond(c, http.StatusBadRequest, "invalid scan ID")
 		return
 	}
 
-	var scan model.Scan
-	if err := h.db.First(&scan, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "scan not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch scan"})
+	scan, err := h.scans.Get(c.Request.Context(), id)
+	if err != nil {
+		apierror.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": scan})
+	c.JSON(http.StatusOK, dataResponse(toScanDTO(scan)))
 }
@@ -0,0 +1,457 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/slack"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// slackPreviewLimit is the number of resources listed by a cleanup preview
+const slackPreviewLimit = 20
+
+// SlackHandler handles the Slack app: its installation by organizations,
+// the /cloudsweep command and the buttons of scan messages
+type SlackHandler struct {
+	db     *gorm.DB
+	client *slack.Client
+	cfg    config.SlackConfig
+	states *slack.StateSigner
+}
+
+// NewSlackHandler creates a new SlackHandler. The endpoints answer 503 when
+// the app is not configured.
+func NewSlackHandler(db *gorm.DB, cfg config.SlackConfig) *SlackHandler {
+	return &SlackHandler{
+		db:     db,
+		client: slack.NewClient(cfg),
+		cfg:    cfg,
+		states: slack.NewStateSigner(cfg.ClientSecret),
+	}
+}
+
+// GetInstallation godoc
+//
+//	@Summary		Get Slack installation
+//	@Description	Get the Slack workspace and channel an organization installed the app in
+//	@Tags			Organizations
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[SlackInstallationDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/slack [get]
+func (h *SlackHandler) GetInstallation(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	var installation model.SlackInstallation
+	if err := h.db.WithContext(c.Request.Context()).First(&installation, "organization_id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Respond(c, http.StatusNotFound, "slack is not installed")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch slack installation")
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(toSlackInstallationDTO(&installation)))
+}
+
+// Install godoc
+//
+//	@Summary		Install the Slack app
+//	@Description	Redirect to the Slack page installing the app in a workspace for an organization. Slack redirects back to /slack/oauth/callback, which replaces any previous installation of the organization.
+//	@Tags			Organizations
+//	@Param			id	path	string	true	"Organization ID"	format(uuid)
+//	@Success		302
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		503	{object}	ErrorResponse
+//	@Router			/organizations/{id}/slack/install [get]
+func (h *SlackHandler) Install(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	if !requireActiveOrganization(c, h.db, orgID) {
+		return
+	}
+	state := h.states.Sign(slack.InstallState{
+		OrganizationID: orgID.String(),
+		ExpiresAt:      time.Now().Add(h.cfg.StateTTL).Unix(),
+	})
+	c.Redirect(http.StatusFound, h.client.InstallURL(state))
+}
+
+// Callback godoc
+//
+//	@Summary		Finish the Slack installation
+//	@Description	Called by Slack once the app is installed: stores the bot token and the channel picked for scan results
+//	@Tags			Slack
+//	@Produce		json
+//	@Param			code	query		string	true	"Authorization code"
+//	@Param			state	query		string	true	"Installation state"
+//	@Success		200		{object}	DataResponse[SlackInstallationDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse
+//	@Failure		502		{object}	ErrorResponse
+//	@Failure		503		{object}	ErrorResponse
+//	@Router			/slack/oauth/callback [get]
+func (h *SlackHandler) Callback(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+	if reason := c.Query("error"); reason != "" {
+		apierror.Respond(c, http.StatusBadRequest, "slack installation cancelled: "+reason)
+		return
+	}
+	state, err := h.states.Verify(c.Query("state"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	orgID, err := uuid.Parse(state.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, slack.ErrInvalidState.Error())
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		apierror.Respond(c, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	result, err := h.client.Exchange(c.Request.Context(), code)
+	if err != nil {
+		log.Printf("Slack installation of org %s failed: %v", orgID, err)
+		apierror.Respond(c, http.StatusBadGateway, "slack installation failed")
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	var other model.SlackInstallation
+	err = db.First(&other, "team_id = ? AND organization_id <> ?", result.TeamID, orgID).Error
+	if err == nil {
+		apierror.Respond(c, http.StatusConflict, "this Slack workspace is already connected to another organization")
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to save slack installation")
+		return
+	}
+
+	installation := model.SlackInstallation{
+		OrganizationID: orgID,
+		TeamID:         result.TeamID,
+		TeamName:       result.TeamName,
+		BotUserID:      result.BotUserID,
+		AccessToken:    result.AccessToken,
+		ChannelID:      result.ChannelID,
+		ChannelName:    result.ChannelName,
+		InstalledBy:    result.InstalledBy,
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"team_id", "team_name", "bot_user_id", "access_token", "channel_id", "channel_name", "installed_by", "updated_at"}),
+	}).Create(&installation).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to save slack installation")
+		return
+	}
+	c.JSON(http.StatusOK, dataResponse(toSlackInstallationDTO(&installation)))
+}
+
+// DeleteInstallation godoc
+//
+//	@Summary		Uninstall the Slack app
+//	@Description	Forget the Slack installation of an organization: its commands are no longer answered and scan results no longer posted. The app stays in the workspace until removed there.
+//	@Tags			Organizations
+//	@Param			id	path	string	true	"Organization ID"	format(uuid)
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/slack [delete]
+func (h *SlackHandler) DeleteInstallation(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+	result := h.db.WithContext(c.Request.Context()).Delete(&model.SlackInstallation{}, "organization_id = ?", orgID)
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to delete slack installation")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "slack is not installed")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Command godoc
+//
+//	@Summary		Answer a slash command
+//	@Description	Request URL of the /cloudsweep command, signed by Slack. "waste top [N]" lists the N most expensive unused resources of the organization of the workspace.
+//	@Tags			Slack
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Success		200
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		503	{object}	ErrorResponse
+//	@Router			/slack/commands [post]
+func (h *SlackHandler) Command(c *gin.Context) {
+	form, ok := h.verifiedForm(c)
+	if !ok {
+		return
+	}
+	installation, ok := h.installation(c, form.Get("team_id"))
+	if !ok {
+		return
+	}
+
+	cmd, err := slack.ParseCommand(form.Get("text"))
+	if err != nil {
+		c.JSON(http.StatusOK, slack.TextMessage(err.Error()+". "+slack.HelpMessage().Text))
+		return
+	}
+	if cmd.Name == slack.CommandHelp {
+		c.JSON(http.StatusOK, slack.HelpMessage())
+		return
+	}
+
+	var resources []model.Resource
+	err = h.db.WithContext(c.Request.Context()).
+		Where("organization_id = ? AND status = ?", installation.OrganizationID, string(entity.ResourceStatusUnused)).
+		Order("monthly_cost DESC").Limit(cmd.Limit).Find(&resources).Error
+	if err != nil {
+		c.JSON(http.StatusOK, slack.TextMessage("CloudSweep could not list the unused resources, try again later."))
+		return
+	}
+	items, total := wasteItems(resources)
+	c.JSON(http.StatusOK, slack.WasteMessage(items, total))
+}
+
+// Interaction godoc
+//
+//	@Summary		Handle a message button
+//	@Description	Request URL of the interactivity of the app, signed by Slack. "Preview cleanup" lists the unused resources found by the scan of the message. "Approve" approves their cleanup in the name of the user, who must be an admin or member of the organization with the email of their Slack profile, as the approval links of the owner digest do.
+//	@Tags			Slack
+//	@Accept			x-www-form-urlencoded
+//	@Success		200
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		503	{object}	ErrorResponse
+//	@Router			/slack/interactions [post]
+func (h *SlackHandler) Interaction(c *gin.Context) {
+	form, ok := h.verifiedForm(c)
+	if !ok {
+		return
+	}
+	interaction, err := slack.ParseInteraction(form.Get("payload"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	installation, ok := h.installation(c, interaction.Team.ID)
+	if !ok {
+		return
+	}
+	if interaction.Type != "block_actions" || len(interaction.Actions) == 0 {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	// Slack expects an answer within 3 seconds: the outcome goes to the
+	// response URL
+	action := interaction.Actions[0]
+	var msg slack.Message
+	switch action.ActionID {
+	case slack.ActionPreviewCleanup:
+		msg = h.previewScan(c.Request.Context(), installation, action.Value)
+	case slack.ActionApproveCleanup:
+		msg = h.approveScan(c.Request.Context(), installation, action.Value, interaction.User.ID)
+	default:
+		c.Status(http.StatusOK)
+		return
+	}
+	if err := h.client.Respond(c.Request.Context(), interaction.ResponseURL, msg); err != nil {
+		log.Printf("Slack response to %s of team %s failed: %v", action.ActionID, installation.TeamID, err)
+	}
+	c.Status(http.StatusOK)
+}
+
+// previewScan lists the unused resources of a scan waiting for a cleanup
+func (h *SlackHandler) previewScan(ctx context.Context, installation *model.SlackInstallation, scanID string) slack.Message {
+	query, ok := h.scanWaste(ctx, installation, scanID)
+	if !ok {
+		return slack.TextMessage("This scan no longer exists.")
+	}
+	var count int64
+	var total float64
+	var resources []model.Resource
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return slack.TextMessage("CloudSweep could not preview the cleanup, try again later.")
+	}
+	if err := query.Session(&gorm.Session{}).Select("COALESCE(SUM(monthly_cost), 0)").Scan(&total).Error; err != nil {
+		return slack.TextMessage("CloudSweep could not preview the cleanup, try again later.")
+	}
+	if err := query.Session(&gorm.Session{}).Order("monthly_cost DESC").Limit(slackPreviewLimit).Find(&resources).Error; err != nil {
+		return slack.TextMessage("CloudSweep could not preview the cleanup, try again later.")
+	}
+	items, _ := wasteItems(resources)
+	return slack.PreviewMessage(items, total, int(count)-len(items))
+}
+
+// approveScan approves the cleanup of the unused resources of a scan in the
+// name of a Slack user, who must be an admin or member of the organization
+func (h *SlackHandler) approveScan(ctx context.Context, installation *model.SlackInstallation, scanID, userID string) slack.Message {
+	email, err := h.client.UserEmail(ctx, installation.AccessToken, userID)
+	if err != nil || email == "" {
+		return slack.TextMessage("CloudSweep could not read the email of your Slack profile.")
+	}
+	var membership model.Membership
+	err = h.db.WithContext(ctx).Joins("JOIN users ON users.id = memberships.user_id").
+		Where("memberships.organization_id = ? AND LOWER(users.email) = LOWER(?)", installation.OrganizationID, email).
+		First(&membership).Error
+	if err != nil || entity.MemberRole(membership.Role) == entity.MemberRoleViewer {
+		return slack.TextMessage("Only admins and members of the organization can approve cleanups, and " + email + " is not one of them.")
+	}
+
+	query, ok := h.scanWaste(ctx, installation, scanID)
+	if !ok {
+		return slack.TextMessage("This scan no longer exists.")
+	}
+	now := time.Now()
+	var approved []model.Resource
+	result := query.Model(&approved).Where("cleanup_approved_at IS NULL").
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "organization_id"}}}).
+		Updates(map[string]any{"cleanup_approved_at": &now, "cleanup_approved_by": email})
+	if result.Error != nil {
+		return slack.TextMessage("CloudSweep could not approve the cleanup, try again later.")
+	}
+	if err := recordApprovals(ctx, h.db, approved, email, "slack"); err != nil {
+		log.Printf("Failed to record the approvals of scan %s: %v", scanID, err)
+	}
+	return slack.Message{
+		Text:         fmt.Sprintf("Cleanup of %d resources approved by %s.", result.RowsAffected, email),
+		ResponseType: "in_channel",
+	}
+}
+
+// scanWaste selects the unused resources a scan found that are waiting for
+// a cleanup: neither snoozed nor quarantined. ok is false when the scan is
+// not one of the organization.
+func (h *SlackHandler) scanWaste(ctx context.Context, installation *model.SlackInstallation, scanID string) (*gorm.DB, bool) {
+	id, err := uuid.Parse(scanID)
+	if err != nil {
+		return nil, false
+	}
+	var scan model.Scan
+	if err := h.db.WithContext(ctx).First(&scan, "id = ? AND organization_id = ?", id, installation.OrganizationID).Error; err != nil {
+		return nil, false
+	}
+	query := h.db.WithContext(ctx).Model(&model.Resource{}).
+		Where("organization_id = ? AND provider = ? AND status = ?", scan.OrganizationID, scan.Provider, string(entity.ResourceStatusUnused)).
+		Where("quarantined_at IS NULL AND (snoozed_until IS NULL OR snoozed_until < ?)", time.Now())
+	if len(scan.Regions) > 0 {
+		query = query.Where("region IN ?", []string(scan.Regions))
+	}
+	if scan.StartedAt != nil {
+		query = query.Where("last_seen_at >= ?", *scan.StartedAt)
+	}
+	return query, true
+}
+
+// verifiedForm reads the form of a request Slack signed
+func (h *SlackHandler) verifiedForm(c *gin.Context) (url.Values, bool) {
+	if !h.enabled(c) {
+		return nil, false
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "failed to read request")
+		return nil, false
+	}
+	if err := slack.VerifyRequest(h.cfg.SigningSecret, c.GetHeader(slack.HeaderTimestamp), c.GetHeader(slack.HeaderSignature), body, time.Now()); err != nil {
+		apierror.Respond(c, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid form")
+		return nil, false
+	}
+	return form, true
+}
+
+// installation returns the installation of a workspace, answering Slack
+// when the workspace is not connected to an organization
+func (h *SlackHandler) installation(c *gin.Context, teamID string) (*model.SlackInstallation, bool) {
+	var installation model.SlackInstallation
+	if err := h.db.WithContext(c.Request.Context()).First(&installation, "team_id = ?", teamID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusOK, slack.TextMessage("This Slack workspace is not connected to a CloudSweep organization."))
+			return nil, false
+		}
+		c.JSON(http.StatusOK, slack.TextMessage("CloudSweep is unavailable, try again later."))
+		return nil, false
+	}
+	return &installation, true
+}
+
+// enabled responds with an error and returns false when the app is not
+// configured
+func (h *SlackHandler) enabled(c *gin.Context) bool {
+	if h.client == nil {
+		apierror.Respond(c, http.StatusServiceUnavailable, "slack app is not configured")
+		return false
+	}
+	return true
+}
+
+// wasteItems returns the resources listed by Slack messages and their total
+// monthly cost
+func wasteItems(resources []model.Resource) ([]slack.WasteItem, float64) {
+	items := make([]slack.WasteItem, len(resources))
+	var total float64
+	for i, r := range resources {
+		items[i] = slack.WasteItem{Name: r.Name, Type: r.Type, Provider: r.Provider, Region: r.Region, MonthlyCost: r.MonthlyCost}
+		total += r.MonthlyCost
+	}
+	return items, total
+}
+
+func toSlackInstallationDTO(m *model.SlackInstallation) SlackInstallationDTO {
+	return SlackInstallationDTO{
+		OrganizationID: m.OrganizationID.String(),
+		TeamID:         m.TeamID,
+		TeamName:       m.TeamName,
+		ChannelID:      m.ChannelID,
+		ChannelName:    m.ChannelName,
+		InstalledBy:    m.InstalledBy,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
@@ -0,0 +1,417 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/entity"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/oidc"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// oidcStateCookie keeps the login state between the login redirect and the
+// callback
+const oidcStateCookie = "cloudsweep_oidc_state"
+
+// SSOHandler handles the OIDC single sign-on of organizations
+type SSOHandler struct {
+	db     *gorm.DB
+	client *oidc.Client
+	signer *oidc.Signer
+	cfg    config.OIDCConfig
+}
+
+// NewSSOHandler creates a new SSOHandler
+func NewSSOHandler(db *gorm.DB, cfg config.OIDCConfig) *SSOHandler {
+	return &SSOHandler{
+		db:     db,
+		client: oidc.NewClient(),
+		signer: oidc.NewSigner(cfg.SigningKey),
+		cfg:    cfg,
+	}
+}
+
+// UpdateSSOConnectionRequest represents a request to configure the identity
+// provider of an organization
+type UpdateSSOConnectionRequest struct {
+	Issuer   string `json:"issuer" binding:"required,url" example:"https://acme.okta.com"`
+	ClientID string `json:"client_id" binding:"required" example:"0oa1b2c3d4e5f6g7h8i9"`
+	// ClientSecret is kept when left empty on an existing connection
+	ClientSecret   string            `json:"client_secret" example:"s3cr3t"`
+	GroupsClaim    string            `json:"groups_claim" example:"groups"`
+	GroupRoles     map[string]string `json:"group_roles" binding:"omitempty,dive,oneof=admin member viewer"`
+	DefaultRole    string            `json:"default_role" binding:"omitempty,oneof=admin member viewer" example:"viewer"`
+	AllowedDomains []string          `json:"allowed_domains" example:"acme.com"`
+	Enabled        *bool             `json:"enabled" example:"true"`
+}
+
+var (
+	errSSONotAllowed = errors.New("user is not allowed to sign in to this organization")
+	errSSOInactive   = errors.New("organization is deactivated")
+	errSSONoSecret   = errors.New("client_secret is required")
+)
+
+// GetConnection godoc
+//
+//	@Summary		Get SSO connection
+//	@Description	Get the OIDC identity provider of an organization. The client secret is never returned.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[SSOConnectionDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/sso [get]
+func (h *SSOHandler) GetConnection(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var conn model.SSOConnection
+	if err := h.db.WithContext(c.Request.Context()).First(&conn, "organization_id = ?", orgID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "sso connection not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch sso connection")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toSSOConnectionDTO(&conn)))
+}
+
+// UpdateConnection godoc
+//
+//	@Summary		Configure SSO connection
+//	@Description	Create or replace the OIDC identity provider (Okta, Azure AD, Google...) users of an organization sign in with. Groups of the ID token are mapped to roles; users in no mapped group get the default role, or are refused when it is empty.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Organization ID"	format(uuid)
+//	@Param			request	body		UpdateSSOConnectionRequest	true	"Connection"
+//	@Success		200		{object}	DataResponse[SSOConnectionDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/sso [put]
+func (h *SSOHandler) UpdateConnection(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req UpdateSSOConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	var org model.Organization
+	if err := h.db.WithContext(c.Request.Context()).First(&org, "id = ?", orgID).Error; err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	roles := model.JSONB{}
+	for group, role := range req.GroupRoles {
+		roles[group] = role
+	}
+	domains := make([]string, 0, len(req.AllowedDomains))
+	for _, d := range req.AllowedDomains {
+		if d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@")); d != "" {
+			domains = append(domains, d)
+		}
+	}
+
+	conn := model.SSOConnection{OrganizationID: orgID}
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&conn, "organization_id = ?", orgID).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		exists := err == nil
+		if req.ClientSecret != "" {
+			conn.ClientSecret = req.ClientSecret
+		} else if !exists {
+			return errSSONoSecret
+		}
+		conn.Issuer = strings.TrimRight(req.Issuer, "/")
+		conn.ClientID = req.ClientID
+		conn.GroupsClaim = req.GroupsClaim
+		conn.GroupRoles = roles
+		conn.DefaultRole = req.DefaultRole
+		conn.AllowedDomains = domains
+		conn.Enabled = req.Enabled == nil || *req.Enabled
+		if exists {
+			return tx.Save(&conn).Error
+		}
+		return tx.Create(&conn).Error
+	})
+	if err != nil {
+		if errors.Is(err, errSSONoSecret) {
+			apierror.Respond(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to save sso connection")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toSSOConnectionDTO(&conn)))
+}
+
+// DeleteConnection godoc
+//
+//	@Summary		Delete SSO connection
+//	@Description	Remove the OIDC identity provider of an organization. Members keep their access through invitations.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/sso [delete]
+func (h *SSOHandler) DeleteConnection(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Delete(&model.SSOConnection{}, "organization_id = ?", orgID)
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to delete sso connection")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "sso connection not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "sso connection deleted"})
+}
+
+// Login godoc
+//
+//	@Summary		Start SSO login
+//	@Description	Redirect to the login page of the identity provider of an organization. The login state is kept in a short-lived cookie until the callback.
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			organization	query	string	true	"Organization slug or ID"
+//	@Success		302
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		502	{object}	ErrorResponse
+//	@Router			/auth/oidc/login [get]
+func (h *SSOHandler) Login(c *gin.Context) {
+	ref := c.Query("organization")
+	if ref == "" {
+		apierror.Respond(c, http.StatusBadRequest, "organization is required")
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Joins("Organization")
+	if id, err := uuid.Parse(ref); err == nil {
+		query = query.Where("sso_connections.organization_id = ?", id)
+	} else {
+		query = query.Where(`"Organization".slug = ?`, strings.ToLower(ref))
+	}
+	var conn model.SSOConnection
+	if err := query.First(&conn).Error; err != nil || !conn.Enabled || !conn.Organization.IsActive {
+		apierror.Respond(c, http.StatusNotFound, "single sign-on is not enabled for this organization")
+		return
+	}
+
+	state := oidc.LoginState{
+		OrganizationID: conn.OrganizationID.String(),
+		ExpiresAt:      time.Now().Add(h.cfg.StateTTL).Unix(),
+	}
+	for _, s := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		var err error
+		if *s, err = oidc.RandomString(); err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, "failed to start login")
+			return
+		}
+	}
+
+	authURL, err := h.client.AuthCodeURL(c.Request.Context(), conn.Connection(), h.cfg.RedirectURL, state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		log.Printf("SSO login of org %s failed: %v", conn.OrganizationID, err)
+		apierror.Respond(c, http.StatusBadGateway, "identity provider is unavailable")
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, h.signer.SignState(state), int(h.cfg.StateTTL.Seconds()), "/", "", strings.HasPrefix(h.cfg.RedirectURL, "https://"), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+//
+//	@Summary		Complete SSO login
+//	@Description	Redeem the authorization code sent back by the identity provider. Users are created on their first login and their role follows the group mapping of the connection.
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			code	query		string	true	"Authorization code"
+//	@Param			state	query		string	true	"Login state"
+//	@Success		200		{object}	DataResponse[SessionDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/oidc/callback [get]
+func (h *SSOHandler) Callback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		apierror.Respond(c, http.StatusUnauthorized, strings.TrimSpace("login failed: "+e+" "+c.Query("error_description")))
+		return
+	}
+
+	cookie, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "login state is missing, start the login again")
+		return
+	}
+	c.SetCookie(oidcStateCookie, "", -1, "/", "", strings.HasPrefix(h.cfg.RedirectURL, "https://"), true)
+
+	state, err := h.signer.VerifyState(cookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(state.State), []byte(c.Query("state"))) != 1 {
+		apierror.Respond(c, http.StatusBadRequest, "invalid or expired login state, start the login again")
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		apierror.Respond(c, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	var conn model.SSOConnection
+	if err := h.db.WithContext(c.Request.Context()).First(&conn, "organization_id = ? AND enabled", state.OrganizationID).Error; err != nil {
+		apierror.Respond(c, http.StatusForbidden, "single sign-on is not enabled for this organization")
+		return
+	}
+	connection := conn.Connection()
+
+	identity, err := h.client.Exchange(c.Request.Context(), connection, h.cfg.RedirectURL, code, state.Nonce, state.Verifier)
+	if err != nil {
+		log.Printf("SSO callback of org %s failed: %v", conn.OrganizationID, err)
+		apierror.Respond(c, http.StatusUnauthorized, "login failed at the identity provider")
+		return
+	}
+	email := strings.ToLower(identity.Email)
+	if email == "" {
+		apierror.Respond(c, http.StatusForbidden, "identity provider did not share the email address")
+		return
+	}
+	if len(connection.AllowedDomains) > 0 {
+		_, domain, _ := strings.Cut(email, "@")
+		allowed := false
+		for _, d := range connection.AllowedDomains {
+			allowed = allowed || domain == d
+		}
+		if !allowed {
+			apierror.Respond(c, http.StatusForbidden, errSSONotAllowed.Error())
+			return
+		}
+	}
+
+	role, mapped := connection.RoleForGroups(identity.Groups)
+	var membership model.Membership
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		var org model.Organization
+		if err := tx.First(&org, "id = ?", conn.OrganizationID).Error; err != nil {
+			return err
+		}
+		if !org.IsActive {
+			return errSSOInactive
+		}
+
+		var user model.User
+		if err := tx.Where(model.User{Email: email}).Attrs(model.User{Name: identity.Name}).FirstOrCreate(&user).Error; err != nil {
+			return err
+		}
+
+		err := tx.First(&membership, "organization_id = ? AND user_id = ?", org.ID, user.ID).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			if role == "" {
+				return errSSONotAllowed
+			}
+			membership = model.Membership{OrganizationID: org.ID, UserID: user.ID, Role: string(role)}
+			if err := tx.Create(&membership).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case mapped && membership.Role != string(role):
+			// Mapped groups are the source of truth for the role; members in
+			// no mapped group keep the role they were given
+			membership.Role = string(role)
+			if err := tx.Model(&membership).Update("role", membership.Role).Error; err != nil {
+				return err
+			}
+		}
+		membership.User = user
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errSSONotAllowed), errors.Is(err, errSSOInactive):
+			apierror.Respond(c, http.StatusForbidden, err.Error())
+		default:
+			apierror.Respond(c, http.StatusInternalServerError, "failed to sign in")
+		}
+		return
+	}
+
+	expiresAt := time.Now().Add(h.cfg.SessionTTL)
+	token := h.signer.SignSession(oidc.Session{
+		UserID:         membership.UserID.String(),
+		OrganizationID: membership.OrganizationID.String(),
+		Role:           membership.Role,
+		ExpiresAt:      expiresAt.Unix(),
+	})
+	c.JSON(http.StatusOK, dataResponse(SessionDTO{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		Member:    toMemberDTO(&membership),
+	}))
+}
+
+func toSSOConnectionDTO(m *model.SSOConnection) SSOConnectionDTO {
+	conn := m.Connection()
+	roles := make(map[string]string, len(conn.GroupRoles))
+	for group, role := range conn.GroupRoles {
+		roles[group] = string(role)
+	}
+	groupsClaim := conn.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = entity.DefaultGroupsClaim
+	}
+	return SSOConnectionDTO{
+		OrganizationID:  conn.OrganizationID.String(),
+		Issuer:          conn.Issuer,
+		ClientID:        conn.ClientID,
+		ClientSecretSet: conn.ClientSecret != "",
+		GroupsClaim:     groupsClaim,
+		GroupRoles:      roles,
+		DefaultRole:     string(conn.DefaultRole),
+		AllowedDomains:  conn.AllowedDomains,
+		Enabled:         conn.Enabled,
+		UpdatedAt:       conn.UpdatedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// SystemHandler exposes the state of the CloudSweep processes for operators
+type SystemHandler struct {
+	workers *queue.WorkerRegistry
+	cache   *cache.Cache
+}
+
+// NewSystemHandler creates a new SystemHandler
+func NewSystemHandler(workers *queue.WorkerRegistry, results *cache.Cache) *SystemHandler {
+	return &SystemHandler{workers: workers, cache: results}
+}
+
+// CacheStats returns the hits and misses of the cache of this API instance,
+// by kind of cached value. It is empty when the cache is disabled.
+func (h *SystemHandler) CacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, dataResponse(h.cache.Stats()))
+}
+
+// ListWorkers lists the running workers from their last heartbeat. A
+// worker missing its heartbeats drops out of the list.
+func (h *SystemHandler) ListWorkers(c *gin.Context) {
+	workers, err := h.workers.List(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to list workers")
+		return
+	}
+
+	dtos := make([]WorkerDTO, 0, len(workers))
+	for _, w := range workers {
+		tasks := make([]WorkerTaskDTO, 0, len(w.Tasks))
+		for _, t := range w.Tasks {
+			tasks = append(tasks, WorkerTaskDTO{ID: t.ID, Type: t.Type, Queue: t.Queue, StartedAt: t.StartedAt})
+		}
+		waits := make(map[string]ScanQueueWaitDTO, len(w.ScanWaits))
+		for org, s := range w.ScanWaits {
+			waits[org] = ScanQueueWaitDTO{Count: s.Count, AvgMillis: s.AvgMillis, MaxMillis: s.MaxMillis, LastMillis: s.LastMillis}
+		}
+		dtos = append(dtos, WorkerDTO{
+			ID:            w.ID,
+			Hostname:      w.Hostname,
+			PID:           w.PID,
+			Version:       w.Version,
+			StartedAt:     w.StartedAt,
+			LastHeartbeat: w.LastHeartbeat,
+			Concurrency:   w.Concurrency,
+			Active:        w.Active,
+			Tasks:         tasks,
+			Processed:     w.Processed,
+			Failed:        w.Failed,
+			ScanQueueWait: waits,
+		})
+	}
+
+	c.JSON(http.StatusOK, dataResponse(dtos))
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// TaskHandler handles background task endpoints
+type TaskHandler struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+	inspector   *asynq.Inspector
+}
+
+// NewTaskHandler creates a new TaskHandler
+func NewTaskHandler(db *gorm.DB, queueClient *asynq.Client, inspector *asynq.Inspector) *TaskHandler {
+	return &TaskHandler{
+		db:          db,
+		queueClient: queueClient,
+		inspector:   inspector,
+	}
+}
+
+// ListTaskFailuresRequest represents query parameters for listing task failures
+type ListTaskFailuresRequest struct {
+	Status   string `form:"status" example:"dead"`
+	TaskType string `form:"task_type" example:"scan:resources"`
+	Limit    int    `form:"limit,default=50" example:"50"`
+	Offset   int    `form:"offset,default=0" example:"0"`
+}
+
+// ListFailures godoc
+//
+//	@Summary		List task failures
+//	@Description	Get a paginated list of failed background tasks, including those moved to the dead-letter queue
+//	@Tags			Tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			status		query		string	false	"Filter by status"	Enums(retrying, dead, requeued)
+//	@Param			task_type	query		string	false	"Filter by task type"
+//	@Param			limit		query		int		false	"Number of items per page"	default(50)
+//	@Param			offset		query		int		false	"Number of items to skip"	default(0)
+//	@Success		200			{object}	PaginatedResponse{data=[]TaskFailureDTO}
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/tasks/failures [get]
+func (h *TaskHandler) ListFailures(c *gin.Context) {
+	var req ListTaskFailuresRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.TaskFailure{})
+
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.TaskType != "" {
+		query = query.Where("task_type = ?", req.TaskType)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var failures []model.TaskFailure
+	if err := query.Limit(req.Limit).Offset(req.Offset).Order("last_failed_at DESC").Find(&failures).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch task failures")
+		return
+	}
+
+	data := make([]TaskFailureDTO, len(failures))
+	for i := range failures {
+		data[i] = toTaskFailureDTO(&failures[i])
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   data,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+// RetryFailure godoc
+//
+//	@Summary		Retry a failed task
+//	@Description	Move a dead-lettered task back to its queue. If the task is no longer archived, it is re-enqueued from the recorded payload.
+//	@Tags			Tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Task failure ID"	format(uuid)
+//	@Success		202	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		409	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/tasks/failures/{id}/retry [post]
+func (h *TaskHandler) RetryFailure(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid task failure ID")
+		return
+	}
+
+	var failure model.TaskFailure
+	if err := h.db.WithContext(c.Request.Context()).First(&failure, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "task failure not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch task failure")
+		return
+	}
+
+	if failure.Status != queue.FailureStatusDead {
+		apierror.Respond(c, http.StatusConflict, "only dead-lettered tasks can be retried")
+		return
+	}
+
+	// Prefer running the archived task itself so asynq keeps its history;
+	// fall back to a fresh task when it has been purged from the archive
+	if err := h.inspector.RunTask(failure.Queue, failure.TaskID); err != nil {
+		payload, _ := json.Marshal(failure.Payload)
+		task := queue.NewTask(failure.TaskType, payload, asynq.Queue(failure.Queue))
+		if _, err := h.queueClient.EnqueueContext(c.Request.Context(), task); err != nil {
+			apierror.Respond(c, http.StatusInternalServerError, "failed to enqueue task")
+			return
+		}
+	}
+
+	if err := h.db.WithContext(c.Request.Context()).Model(&failure).Update("status", queue.FailureStatusRequeued).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to update task failure")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "task requeued"})
+}
+
+func toTaskFailureDTO(m *model.TaskFailure) TaskFailureDTO {
+	return TaskFailureDTO{
+		ID:           m.ID.String(),
+		TaskID:       m.TaskID,
+		TaskType:     m.TaskType,
+		Queue:        m.Queue,
+		Payload:      m.Payload,
+		Error:        m.Error,
+		Attempts:     m.Attempts,
+		MaxRetry:     m.MaxRetry,
+		Status:       m.Status,
+		LastFailedAt: m.LastFailedAt,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
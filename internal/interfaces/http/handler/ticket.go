@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database/model"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketHandler handles the Jira or ServiceNow tracker of organizations and
+// the tickets opened by policies with the ticket action
+type TicketHandler struct {
+	db *gorm.DB
+}
+
+// NewTicketHandler creates a new TicketHandler
+func NewTicketHandler(db *gorm.DB) *TicketHandler {
+	return &TicketHandler{db: db}
+}
+
+// UpdateTicketIntegrationRequest represents a request to configure the
+// ticket tracker of an organization
+type UpdateTicketIntegrationRequest struct {
+	System   string `json:"system" binding:"required,oneof=jira servicenow" example:"jira"`
+	BaseURL  string `json:"base_url" binding:"required,url" example:"https://acme.atlassian.net"`
+	Username string `json:"username" binding:"required" example:"cloudsweep@acme.com"`
+	// APIToken is kept when left empty on an existing integration
+	APIToken string `json:"api_token" example:"ATATT3xFfGF0"`
+	// Project is the key of the Jira project issues are opened in
+	Project         string `json:"project" example:"OPS"`
+	IssueType       string `json:"issue_type" example:"Task"`
+	DoneTransition  string `json:"done_transition" example:"Done"`
+	AssignmentGroup string `json:"assignment_group" example:"Cloud Operations"`
+}
+
+// ListTicketsRequest represents query parameters for listing tickets
+type ListTicketsRequest struct {
+	OrganizationID string `form:"organization_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TaskID         string `form:"task_id" example:"4a6b2c1e-8f3d-4e2a-9b7c-1d5e6f7a8b9c"`
+	State          string `form:"state" binding:"omitempty,oneof=open closed" example:"open"`
+	Limit          int    `form:"limit,default=50" example:"50"`
+	Offset         int    `form:"offset,default=0" example:"0"`
+}
+
+var (
+	errTicketNoToken   = errors.New("api_token is required")
+	errTicketNoProject = errors.New("project is required for jira")
+)
+
+// GetIntegration godoc
+//
+//	@Summary		Get ticket tracker
+//	@Description	Get the Jira or ServiceNow tracker of an organization. The API token is never returned.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	DataResponse[TicketIntegrationDTO]
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/ticketing [get]
+func (h *TicketHandler) GetIntegration(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var integration model.TicketIntegration
+	if err := h.db.WithContext(c.Request.Context()).First(&integration, "organization_id = ?", orgID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apierror.Respond(c, http.StatusNotFound, "ticket integration not found")
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch ticket integration")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toTicketIntegrationDTO(&integration)))
+}
+
+// UpdateIntegration godoc
+//
+//	@Summary		Configure ticket tracker
+//	@Description	Create or replace the tracker policies with the ticket action open tickets in: Jira issues in a project, or ServiceNow change requests assigned to a group. Tickets are closed once their resource is deleted or in use again.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Organization ID"	format(uuid)
+//	@Param			request	body		UpdateTicketIntegrationRequest	true	"Tracker"
+//	@Success		200		{object}	DataResponse[TicketIntegrationDTO]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/organizations/{id}/ticketing [put]
+func (h *TicketHandler) UpdateIntegration(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	var req UpdateTicketIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+	if req.System == service.TicketSystemJira && strings.TrimSpace(req.Project) == "" {
+		apierror.Respond(c, http.StatusBadRequest, errTicketNoProject.Error())
+		return
+	}
+
+	var org model.Organization
+	if err := h.db.WithContext(c.Request.Context()).First(&org, "id = ?", orgID).Error; err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	integration := model.TicketIntegration{OrganizationID: orgID}
+	err = h.db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&integration, "organization_id = ?", orgID).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		exists := err == nil
+		if req.APIToken != "" {
+			integration.APIToken = req.APIToken
+		} else if !exists {
+			return errTicketNoToken
+		}
+		integration.System = req.System
+		integration.BaseURL = strings.TrimRight(req.BaseURL, "/")
+		integration.Username = req.Username
+		integration.Project = strings.TrimSpace(req.Project)
+		integration.IssueType = req.IssueType
+		integration.DoneTransition = req.DoneTransition
+		integration.AssignmentGroup = req.AssignmentGroup
+		if exists {
+			return tx.Save(&integration).Error
+		}
+		return tx.Create(&integration).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTicketNoToken) {
+			apierror.Respond(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		apierror.Respond(c, http.StatusInternalServerError, "failed to save ticket integration")
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse(toTicketIntegrationDTO(&integration)))
+}
+
+// DeleteIntegration godoc
+//
+//	@Summary		Delete ticket tracker
+//	@Description	Remove the tracker of an organization. Policies with the ticket action fail until another one is configured; the open tickets are closed in CloudSweep only.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Organization ID"	format(uuid)
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/organizations/{id}/ticketing [delete]
+func (h *TicketHandler) DeleteIntegration(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Delete(&model.TicketIntegration{}, "organization_id = ?", orgID)
+	if result.Error != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to delete ticket integration")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Respond(c, http.StatusNotFound, "ticket integration not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "ticket integration deleted"})
+}
+
+// List godoc
+//
+//	@Summary		List tickets
+//	@Description	Get a paginated list of the Jira issues and ServiceNow change requests opened by policies with the ticket action, most recent first. Filter by task_id to get the tickets of a policy run. States are refreshed periodically.
+//	@Tags			Cleanup
+//	@Accept			json
+//	@Produce		json
+//	@Param			organization_id	query		string	true	"Organization ID"	format(uuid)
+//	@Param			task_id			query		string	false	"Task ID"
+//	@Param			state			query		string	false	"Ticket state"	Enums(open, closed)
+//	@Param			limit			query		int		false	"Number of items per page"	default(50)
+//	@Param			offset			query		int		false	"Number of items to skip"	default(0)
+//	@Success		200				{object}	PaginatedResponse{data=[]TicketDTO}
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/cleanup/tickets [get]
+func (h *TicketHandler) List(c *gin.Context) {
+	var req ListTicketsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierror.RespondInvalid(c, err)
+		return
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		apierror.Respond(c, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	query := h.db.WithContext(c.Request.Context()).Model(&model.Ticket{}).Where("organization_id = ?", orgID)
+
+	if req.TaskID != "" {
+		query = query.Where("task_id = ?", req.TaskID)
+	}
+	if req.State != "" {
+		query = query.Where("state = ?", req.State)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var tickets []model.Ticket
+	if err := query.Limit(req.Limit).Offset(req.Offset).Order("created_at DESC").Find(&tickets).Error; err != nil {
+		apierror.Respond(c, http.StatusInternalServerError, "failed to fetch tickets")
+		return
+	}
+
+	data := make([]TicketDTO, 0, len(tickets))
+	for _, ticket := range tickets {
+		data = append(data, TicketDTO{
+			ID:             ticket.ID.String(),
+			ResourceID:     ticket.ResourceID.String(),
+			TaskID:         ticket.TaskID,
+			System:         ticket.System,
+			Key:            ticket.Key,
+			URL:            ticket.URL,
+			State:          ticket.State,
+			ResourceStatus: ticket.ResourceStatus,
+			ClosedAt:       ticket.ClosedAt,
+			CreatedAt:      ticket.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:   data,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+}
+
+func toTicketIntegrationDTO(m *model.TicketIntegration) TicketIntegrationDTO {
+	return TicketIntegrationDTO{
+		OrganizationID:  m.OrganizationID.String(),
+		System:          m.System,
+		BaseURL:         m.BaseURL,
+		Username:        m.Username,
+		APITokenSet:     m.APIToken != "",
+		Project:         m.Project,
+		IssueType:       m.IssueType,
+		DoneTransition:  m.DoneTransition,
+		AssignmentGroup: m.AssignmentGroup,
+		UpdatedAt:       m.UpdatedAt,
+	}
+}
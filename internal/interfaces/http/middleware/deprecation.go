@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation returns a gin middleware announcing the retirement of an API
+// version: responses carry a Deprecation header (RFC 9745) from the date the
+// version is deprecated, a Sunset header (RFC 8594) with the date it stops
+// being served, and a Link to the same route in the successor version.
+// After the sunset, requests get a 410. Zero dates are not announced.
+func Deprecation(deprecated, sunset time.Time, prefix, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !sunset.IsZero() && !time.Now().Before(sunset) {
+			apierror.Abort(c, http.StatusGone, fmt.Sprintf("%s was retired on %s, use %s", prefix, sunset.UTC().Format(time.DateOnly), successor))
+			return
+		}
+
+		if !deprecated.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(deprecated.Unix(), 10))
+		}
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if path, ok := strings.CutPrefix(c.Request.URL.Path, prefix); ok {
+			c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, path))
+		}
+
+		c.Next()
+	}
+}
@@ -1,17 +1,32 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
 	"time"
 
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/cloudsweep/cloudsweep/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// Logger returns a gin middleware for logging requests
+// Logger returns a gin middleware for logging requests at the info log
+// level
 func Logger() gin.HandlerFunc {
-	return gin.LoggerWithConfig(gin.LoggerConfig{
+	logRequest := gin.LoggerWithConfig(gin.LoggerConfig{
 		SkipPaths: []string{"/health", "/ready"},
 	})
+	return func(c *gin.Context) {
+		if !logger.Enabled(logger.LevelInfo) {
+			c.Next()
+			return
+		}
+		logRequest(c)
+	}
 }
 
 // CORS returns a gin middleware for handling CORS
@@ -20,7 +35,7 @@ func CORS() gin.HandlerFunc {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Request-ID")
-		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, Deprecation, Sunset, Link")
 		c.Header("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -45,24 +60,73 @@ func RequestID() gin.HandlerFunc {
 	}
 }
 
-// Timeout returns a gin middleware that sets a request timeout
-func Timeout(timeout time.Duration) gin.HandlerFunc {
+// Timeout returns a gin middleware that cancels the request context after
+// timeout. Database and queue calls made with the request context are
+// aborted, and a request still unanswered at the deadline gets a 504 instead
+// of the handler's error response. Event streams are long-lived and are left
+// alone: streams are the full paths of their routes, e.g. "/api/v1/events",
+// so that clients cannot lift the deadline of other routes.
+func Timeout(timeout time.Duration, streams ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Note: For actual timeout handling, consider using context.WithTimeout
-		// This is a simplified version
-		c.Set("timeout", timeout)
+		if timeout <= 0 || slices.Contains(streams, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		tw := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx, c: c}
+		c.Writer = tw
+
 		c.Next()
+
+		if tw.expired() {
+			c.Abort()
+		}
 	}
 }
 
-// RateLimit returns a gin middleware for rate limiting
-// Note: For production, use a proper rate limiter like golang.org/x/time/rate
-func RateLimit(requestsPerSecond int) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Placeholder for rate limiting logic
-		// In production, implement proper rate limiting with Redis or in-memory store
-		c.Next()
+// timeoutWriter replaces the response with a 504 once the request deadline
+// has passed, as long as nothing was sent to the client yet
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	c        *gin.Context
+	timedOut bool
+}
+
+func (w *timeoutWriter) expired() bool {
+	if !w.timedOut && !w.ResponseWriter.Written() && w.ctx.Err() == context.DeadlineExceeded {
+		w.timedOut = true
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+		body, _ := json.Marshal(apierror.New(w.c, apperrors.CodeTimeout, "request timed out"))
+		w.ResponseWriter.Write(body)
 	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.expired() {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.expired() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
 }
 
 // Auth returns a gin middleware for authentication
@@ -75,8 +139,7 @@ func Auth() gin.HandlerFunc {
 		// For now, just check for Authorization header presence
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(401, gin.H{"error": "authorization header required"})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, "authorization header required")
 			return
 		}
 
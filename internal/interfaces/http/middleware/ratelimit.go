@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitExempt lists paths that are never rate limited so probes keep
+// working under load
+var rateLimitExempt = map[string]bool{
+	"/health": true,
+	"/ready":  true,
+}
+
+// RateLimit returns a gin middleware limiting each client to the current
+// limit of the named route group, read from limits on every request so that
+// reloaded limits apply at once. Clients are identified by their verified
+// session, so it must run after the Session middleware, or by IP address
+// otherwise. If Redis is unavailable requests are let through rather than
+// failing the API.
+func RateLimit(limiter *ratelimit.RedisLimiter, group string, limits func() config.HTTPRateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := limits().Route(group)
+		if limiter == nil || limit.Requests <= 0 || rateLimitExempt[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		decision, err := limiter.Allow(c.Request.Context(), group+":"+clientKey(c), limit)
+		if err != nil {
+			log.Printf("Rate limit check failed, allowing request: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+
+		if !decision.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+			apierror.Abort(c, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// clientKey identifies the caller by the user of its session. Unverified
+// credentials are ignored: keying on them would give a fresh bucket to every
+// made-up token.
+func clientKey(c *gin.Context) string {
+	if sess, ok := CurrentSession(c); ok && sess.UserID != "" {
+		return "user:" + sess.UserID
+	}
+	return "ip:" + c.ClientIP()
+}
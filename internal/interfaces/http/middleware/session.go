@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/oidc"
+	"github.com/gin-gonic/gin"
+)
+
+// sessionKey is the context key of the signed-in session
+const sessionKey = "session"
+
+// Session returns a gin middleware that reads the session of requests
+// carrying a bearer token issued by the SSO callback. Other credentials and
+// invalid tokens are left alone: handlers decide whether a session is
+// required.
+func Session(signer *oidc.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if ok {
+			if sess, err := signer.VerifySession(token); err == nil {
+				c.Set(sessionKey, sess)
+			}
+		}
+		c.Next()
+	}
+}
+
+// CurrentSession returns the session of the request, if signed in
+func CurrentSession(c *gin.Context) (oidc.Session, bool) {
+	v, ok := c.Get(sessionKey)
+	if !ok {
+		return oidc.Session{}, false
+	}
+	sess, ok := v.(oidc.Session)
+	return sess, ok
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/openapi"
+	apperrors "github.com/cloudsweep/cloudsweep/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// ValidateRequest returns a gin middleware rejecting requests that do not
+// match the API documentation (types, enums, required fields) with a 400
+// before they reach handlers. Routes missing from the documentation are not
+// checked.
+func ValidateRequest(spec *openapi.Spec) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op, ok := spec.Operation(c.FullPath(), c.Request.Method)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// Bodies of other media types, such as CSV imports, are left to
+		// their handlers
+		if op.HasBody() && c.ContentType() != "" && c.ContentType() != gin.MIMEJSON {
+			op = op.WithoutBody()
+		}
+
+		var body []byte
+		if op.HasBody() && c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				apierror.Abort(c, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		if err := spec.Validate(op, c.Param, c.Request.URL.Query(), body); err != nil {
+			var validationErr *openapi.ValidationError
+			if !errors.As(err, &validationErr) {
+				apierror.Abort(c, http.StatusBadRequest, err.Error())
+				return
+			}
+			details := make([]apierror.Detail, 0, len(validationErr.Problems))
+			for _, problem := range validationErr.Problems {
+				details = append(details, apierror.Detail{Message: problem})
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, apierror.New(c, apperrors.CodeValidationFailed, err.Error(), details...))
+			return
+		}
+
+		c.Next()
+	}
+}
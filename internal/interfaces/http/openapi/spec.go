@@ -0,0 +1,115 @@
+// Package openapi validates HTTP requests against the generated Swagger 2.0
+// document, so request rules stay the ones published in the API docs, and
+// converts the document to OpenAPI 3.1 for client generators.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Schema is the subset of a Swagger schema object used for validation
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Enum       []any              `json:"enum"`
+	Required   []string           `json:"required"`
+	Properties map[string]*Schema `json:"properties"`
+	Items      *Schema            `json:"items"`
+	AllOf      []*Schema          `json:"allOf"`
+	MinItems   *int               `json:"minItems"`
+	MaxItems   *int               `json:"maxItems"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+}
+
+// Parameter is a Swagger operation parameter
+type Parameter struct {
+	Name             string   `json:"name"`
+	In               string   `json:"in"`
+	Required         bool     `json:"required"`
+	Type             string   `json:"type"`
+	Format           string   `json:"format"`
+	Enum             []any    `json:"enum"`
+	Items            *Schema  `json:"items"`
+	CollectionFormat string   `json:"collectionFormat"`
+	Minimum          *float64 `json:"minimum"`
+	Maximum          *float64 `json:"maximum"`
+	Schema           *Schema  `json:"schema"`
+}
+
+// Operation holds the parameters of one method on one path
+type Operation struct {
+	Parameters []Parameter `json:"parameters"`
+}
+
+// Spec holds the operations of a Swagger document, keyed by gin route
+// (e.g. "/api/v1/scans/:id") and upper-case method
+type Spec struct {
+	operations  map[string]map[string]*Operation
+	definitions map[string]*Schema
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// Load parses a Swagger 2.0 JSON document
+func Load(doc []byte) (*Spec, error) {
+	var raw struct {
+		BasePath    string                                `json:"basePath"`
+		Paths       map[string]map[string]json.RawMessage `json:"paths"`
+		Definitions map[string]*Schema                    `json:"definitions"`
+	}
+	if err := json.Unmarshal(doc, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	spec := &Spec{
+		operations:  make(map[string]map[string]*Operation, len(raw.Paths)),
+		definitions: raw.Definitions,
+	}
+	for path, item := range raw.Paths {
+		ops := make(map[string]*Operation)
+		for _, method := range methods {
+			data, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op Operation
+			if err := json.Unmarshal(data, &op); err != nil {
+				return nil, fmt.Errorf("failed to parse %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			ops[strings.ToUpper(method)] = &op
+		}
+		spec.operations[ginPath(strings.TrimRight(raw.BasePath, "/")+path)] = ops
+	}
+	return spec, nil
+}
+
+// Operation returns the documented operation of a gin route, if any
+func (s *Spec) Operation(route, method string) (*Operation, bool) {
+	op, ok := s.operations[route][method]
+	return op, ok
+}
+
+// resolve follows $ref to the referenced definition
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+	}
+	return schema
+}
+
+// ginPath converts "/scans/{id}" to "/scans/:id"
+func ginPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			segments[i] = ":" + seg[1:len(seg)-1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
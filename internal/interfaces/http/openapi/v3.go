@@ -0,0 +1,365 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents returned by ConvertV3
+const Version = "3.1.0"
+
+// object is a JSON object of a document
+type object = map[string]any
+
+// schemaParameterKeys are the keys of a Swagger 2.0 parameter or header
+// moving to its schema in OpenAPI 3
+var schemaParameterKeys = []string{
+	"type", "format", "items", "enum", "default", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minLength", "maxLength", "pattern", "minItems", "maxItems", "uniqueItems", "multipleOf",
+}
+
+// ConvertV3 converts a Swagger 2.0 JSON document into an OpenAPI 3.1 one,
+// for client generators that no longer read Swagger. Definitions become
+// component schemas under the same names, body and form parameters request
+// bodies, and responses get a content entry per produced media type. The
+// servers are the base path of the document, relative to where it is served.
+func ConvertV3(doc []byte) ([]byte, error) {
+	var swagger object
+	if err := json.Unmarshal(doc, &swagger); err != nil {
+		return nil, fmt.Errorf("failed to parse Swagger document: %w", err)
+	}
+
+	out := object{"openapi": Version, "info": swagger["info"]}
+	basePath, _ := swagger["basePath"].(string)
+	if basePath == "" {
+		basePath = "/"
+	}
+	out["servers"] = []any{object{"url": basePath}}
+	for _, key := range []string{"tags", "externalDocs", "security"} {
+		if v, ok := swagger[key]; ok {
+			out[key] = v
+		}
+	}
+
+	components := object{}
+	if definitions, ok := swagger["definitions"].(object); ok {
+		schemas := object{}
+		for name, schema := range definitions {
+			schemas[name] = convertSchema(schema)
+		}
+		components["schemas"] = schemas
+	}
+	if definitions, ok := swagger["securityDefinitions"].(object); ok {
+		schemes := object{}
+		for name, def := range definitions {
+			schemes[name] = convertSecurityScheme(def)
+		}
+		components["securitySchemes"] = schemes
+	}
+	if len(components) > 0 {
+		out["components"] = components
+	}
+
+	consumes := mediaTypes(swagger["consumes"], nil)
+	produces := mediaTypes(swagger["produces"], nil)
+	paths := object{}
+	if items, ok := swagger["paths"].(object); ok {
+		for path, raw := range items {
+			item, ok := raw.(object)
+			if !ok {
+				continue
+			}
+			converted := object{}
+			for key, value := range item {
+				switch key {
+				case "parameters":
+					params, _ := convertParameters(value, nil)
+					converted[key] = params
+				case "$ref":
+					converted[key] = value
+				default:
+					op, ok := value.(object)
+					if !ok {
+						continue
+					}
+					converted[key] = convertOperation(op, consumes, produces)
+				}
+			}
+			paths[path] = converted
+		}
+	}
+	out["paths"] = paths
+
+	return json.Marshal(out)
+}
+
+// convertOperation converts an operation, moving its body and form
+// parameters to its request body
+func convertOperation(op object, consumes, produces []string) object {
+	consumes = mediaTypes(op["consumes"], consumes)
+	produces = mediaTypes(op["produces"], produces)
+
+	out := object{}
+	for _, key := range []string{"summary", "description", "operationId", "tags", "deprecated", "security", "externalDocs"} {
+		if v, ok := op[key]; ok {
+			out[key] = v
+		}
+	}
+	for key, v := range op {
+		if strings.HasPrefix(key, "x-") {
+			out[key] = v
+		}
+	}
+
+	params, body := convertParameters(op["parameters"], consumes)
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+	if body != nil {
+		out["requestBody"] = body
+	}
+
+	responses := object{}
+	if raw, ok := op["responses"].(object); ok {
+		for code, r := range raw {
+			response, ok := r.(object)
+			if !ok {
+				continue
+			}
+			responses[code] = convertResponse(code, response, produces)
+		}
+	}
+	out["responses"] = responses
+	return out
+}
+
+// convertParameters converts the parameters of an operation, returning the
+// body and form parameters as a request body of the consumed media types
+func convertParameters(raw any, consumes []string) ([]any, object) {
+	list, _ := raw.([]any)
+	params := []any{}
+	var body object
+	form := object{"type": "object", "properties": object{}}
+	var formRequired []any
+	hasForm, multipart := false, false
+
+	for _, p := range list {
+		param, ok := p.(object)
+		if !ok {
+			continue
+		}
+		if ref, ok := param["$ref"].(string); ok {
+			params = append(params, object{"$ref": convertRef(ref)})
+			continue
+		}
+		switch param["in"] {
+		case "body":
+			content := object{}
+			for _, mediaType := range consumes {
+				content[mediaType] = object{"schema": convertSchema(param["schema"])}
+			}
+			body = object{"content": content}
+			copyKeys(body, param, "description", "required")
+		case "formData":
+			hasForm = true
+			name, _ := param["name"].(string)
+			schema := parameterSchema(param)
+			if param["type"] == "file" {
+				multipart = true
+				schema = object{"type": "string", "format": "binary"}
+			}
+			copyKeys(schema, param, "description")
+			form["properties"].(object)[name] = schema
+			if required, _ := param["required"].(bool); required {
+				formRequired = append(formRequired, name)
+			}
+		default:
+			converted := object{"schema": parameterSchema(param)}
+			copyKeys(converted, param, "name", "in", "description", "required", "deprecated", "allowEmptyValue", "example")
+			switch param["collectionFormat"] {
+			case "multi":
+				converted["style"], converted["explode"] = "form", true
+			case "ssv":
+				converted["style"] = "spaceDelimited"
+			case "pipes":
+				converted["style"] = "pipeDelimited"
+			default:
+				if param["type"] == "array" && param["in"] == "query" {
+					converted["style"], converted["explode"] = "form", false
+				}
+			}
+			params = append(params, converted)
+		}
+	}
+
+	if hasForm && body == nil {
+		if len(formRequired) > 0 {
+			form["required"] = formRequired
+		}
+		mediaType := "application/x-www-form-urlencoded"
+		if multipart || slices.Contains(consumes, "multipart/form-data") {
+			mediaType = "multipart/form-data"
+		}
+		body = object{"content": object{mediaType: object{"schema": form}}}
+	}
+	return params, body
+}
+
+// convertResponse converts a response, with its schema as the content of
+// each produced media type
+func convertResponse(code string, response object, produces []string) object {
+	out := object{}
+	if ref, ok := response["$ref"].(string); ok {
+		return object{"$ref": convertRef(ref)}
+	}
+	description, _ := response["description"].(string)
+	if description == "" {
+		if status, err := strconv.Atoi(code); err == nil {
+			description = http.StatusText(status)
+		}
+	}
+	out["description"] = description
+
+	if schema, ok := response["schema"]; ok {
+		content := object{}
+		for _, mediaType := range produces {
+			content[mediaType] = object{"schema": convertSchema(schema)}
+		}
+		out["content"] = content
+	}
+	if headers, ok := response["headers"].(object); ok {
+		converted := object{}
+		for name, h := range headers {
+			header, ok := h.(object)
+			if !ok {
+				continue
+			}
+			c := object{"schema": parameterSchema(header)}
+			copyKeys(c, header, "description")
+			converted[name] = c
+		}
+		out["headers"] = converted
+	}
+	return out
+}
+
+// convertSecurityScheme converts a security definition
+func convertSecurityScheme(raw any) any {
+	def, ok := raw.(object)
+	if !ok {
+		return raw
+	}
+	out := object{}
+	copyKeys(out, def, "description")
+	switch def["type"] {
+	case "basic":
+		out["type"], out["scheme"] = "http", "basic"
+	case "oauth2":
+		out["type"] = "oauth2"
+		flow := object{"scopes": object{}}
+		copyKeys(flow, def, "authorizationUrl", "tokenUrl", "scopes")
+		name := map[string]string{
+			"implicit":    "implicit",
+			"password":    "password",
+			"application": "clientCredentials",
+			"accessCode":  "authorizationCode",
+		}[fmt.Sprint(def["flow"])]
+		out["flows"] = object{name: flow}
+	default:
+		copyKeys(out, def, "type", "name", "in")
+	}
+	return out
+}
+
+// parameterSchema returns the schema of a non-body parameter or header
+func parameterSchema(param object) object {
+	schema := object{}
+	for _, key := range schemaParameterKeys {
+		if v, ok := param[key]; ok {
+			schema[key] = convertSchema(v)
+		}
+	}
+	return schema
+}
+
+// convertSchema converts the references of a schema to components, and
+// Swagger extensions without OpenAPI 3.1 equivalent to JSON Schema
+func convertSchema(raw any) any {
+	switch v := raw.(type) {
+	case object:
+		out := make(object, len(v))
+		for key, value := range v {
+			switch key {
+			case "$ref":
+				if ref, ok := value.(string); ok {
+					out[key] = convertRef(ref)
+					continue
+				}
+			case "x-nullable":
+				continue
+			}
+			out[key] = convertSchema(value)
+		}
+		if nullable, _ := v["x-nullable"].(bool); nullable {
+			if t, ok := out["type"].(string); ok {
+				out["type"] = []any{t, "null"}
+			}
+		}
+		if out["type"] == "file" {
+			out["type"], out["format"] = "string", "binary"
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = convertSchema(item)
+		}
+		return out
+	}
+	return raw
+}
+
+// convertRef points a reference to a definition at its component schema
+func convertRef(ref string) string {
+	if name, ok := strings.CutPrefix(ref, "#/definitions/"); ok {
+		return "#/components/schemas/" + name
+	}
+	if name, ok := strings.CutPrefix(ref, "#/parameters/"); ok {
+		return "#/components/parameters/" + name
+	}
+	if name, ok := strings.CutPrefix(ref, "#/responses/"); ok {
+		return "#/components/responses/" + name
+	}
+	return ref
+}
+
+// mediaTypes returns the media types of a consumes or produces list, or
+// the inherited ones when it has none. JSON is assumed without any.
+func mediaTypes(raw any, inherited []string) []string {
+	list, _ := raw.([]any)
+	var out []string
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		out = inherited
+	}
+	if len(out) == 0 {
+		out = []string{"application/json"}
+	}
+	return out
+}
+
+func copyKeys(dst, src object, keys ...string) {
+	for _, key := range keys {
+		if v, ok := src[key]; ok {
+			dst[key] = v
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ValidationError lists the ways a request does not match its operation
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// HasBody reports whether the operation documents a request body
+func (o *Operation) HasBody() bool {
+	for _, p := range o.Parameters {
+		if p.In == "body" {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutBody returns the operation without its body parameter, for
+// requests whose body is not JSON
+func (o *Operation) WithoutBody() *Operation {
+	out := &Operation{Parameters: make([]Parameter, 0, len(o.Parameters))}
+	for _, p := range o.Parameters {
+		if p.In != "body" {
+			out.Parameters = append(out.Parameters, p)
+		}
+	}
+	return out
+}
+
+// Validate checks the path parameters, query string and JSON body of a
+// request against the operation. pathParam returns the value of a path
+// parameter by name. The returned error is a *ValidationError.
+func (s *Spec) Validate(op *Operation, pathParam func(string) string, query url.Values, body []byte) error {
+	v := &validator{spec: s}
+
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			v.simple(fmt.Sprintf("path parameter %q", p.Name), pathParam(p.Name), p.Type, p.Format, p.Enum, p.Minimum, p.Maximum)
+		case "query":
+			v.query(p, query)
+		case "body":
+			v.body(p, body)
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	spec     *Spec
+	problems []string
+}
+
+func (v *validator) fail(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) query(p Parameter, query url.Values) {
+	name := fmt.Sprintf("query parameter %q", p.Name)
+	values := query[p.Name]
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		if p.Required {
+			v.fail("%s is required", name)
+		}
+		return
+	}
+
+	if p.Type != "array" {
+		v.simple(name, values[0], p.Type, p.Format, p.Enum, p.Minimum, p.Maximum)
+		return
+	}
+	if p.CollectionFormat != "multi" {
+		values = strings.Split(values[0], collectionSeparator(p.CollectionFormat))
+	}
+	if p.Items == nil {
+		return
+	}
+	for _, value := range values {
+		v.simple(name, value, p.Items.Type, p.Items.Format, p.Items.Enum, p.Items.Minimum, p.Items.Maximum)
+	}
+}
+
+// simple checks a non-body value, which is always received as a string
+func (v *validator) simple(name, value, typ, format string, enum []any, min, max *float64) {
+	switch typ {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			v.fail("%s must be an integer", name)
+			return
+		}
+		v.bounds(name, float64(n), min, max)
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			v.fail("%s must be a number", name)
+			return
+		}
+		v.bounds(name, n, min, max)
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			v.fail("%s must be a boolean", name)
+			return
+		}
+	}
+
+	if format == "uuid" {
+		if _, err := uuid.Parse(value); err != nil {
+			v.fail("%s must be a UUID", name)
+			return
+		}
+	}
+	v.enum(name, value, enum)
+}
+
+func (v *validator) body(p Parameter, body []byte) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		if p.Required {
+			v.fail("request body is required")
+		}
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		v.fail("request body must be valid JSON")
+		return
+	}
+	v.value("", value, p.Schema)
+}
+
+// value checks a decoded JSON value against a schema. Null values are
+// treated as absent.
+func (v *validator) value(field string, value any, schema *Schema) {
+	schema = v.spec.resolve(schema)
+	if schema == nil || value == nil {
+		return
+	}
+	for _, sub := range schema.AllOf {
+		v.value(field, value, sub)
+	}
+
+	name := "request body"
+	if field != "" {
+		name = fmt.Sprintf("field %q", field)
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.fail("%s must be an object", name)
+			return
+		}
+		for _, req := range schema.Required {
+			if obj[req] == nil {
+				v.fail("field %q is required", join(field, req))
+			}
+		}
+		props := make([]string, 0, len(schema.Properties))
+		for prop := range schema.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+		for _, prop := range props {
+			if val, ok := obj[prop]; ok {
+				v.value(join(field, prop), val, schema.Properties[prop])
+			}
+		}
+
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			v.fail("%s must be an array", name)
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			v.fail("%s must have at least %d items", name, *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			v.fail("%s must have at most %d items", name, *schema.MaxItems)
+		}
+		for i, item := range items {
+			v.value(fmt.Sprintf("%s[%d]", field, i), item, schema.Items)
+		}
+
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.fail("%s must be a string", name)
+			return
+		}
+		length := utf8.RuneCountInString(s)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			v.fail("%s must be at least %d characters long", name, *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			v.fail("%s must be at most %d characters long", name, *schema.MaxLength)
+		}
+		if schema.Format == "uuid" {
+			if _, err := uuid.Parse(s); err != nil {
+				v.fail("%s must be a UUID", name)
+				return
+			}
+		}
+		v.enum(name, s, schema.Enum)
+
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			v.fail("%s must be an integer", name)
+			return
+		}
+		i, err := n.Int64()
+		if err != nil {
+			v.fail("%s must be an integer", name)
+			return
+		}
+		v.bounds(name, float64(i), schema.Minimum, schema.Maximum)
+		v.enum(name, n.String(), schema.Enum)
+
+	case "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			v.fail("%s must be a number", name)
+			return
+		}
+		f, _ := n.Float64()
+		v.bounds(name, f, schema.Minimum, schema.Maximum)
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail("%s must be a boolean", name)
+		}
+	}
+}
+
+func (v *validator) bounds(name string, n float64, min, max *float64) {
+	if min != nil && n < *min {
+		v.fail("%s must be at least %v", name, *min)
+	}
+	if max != nil && n > *max {
+		v.fail("%s must be at most %v", name, *max)
+	}
+}
+
+func (v *validator) enum(name, value string, enum []any) {
+	if len(enum) == 0 {
+		return
+	}
+	allowed := make([]string, len(enum))
+	for i, e := range enum {
+		allowed[i] = fmt.Sprint(e)
+		if allowed[i] == value {
+			return
+		}
+	}
+	v.fail("%s must be one of %s", name, strings.Join(allowed, ", "))
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+func collectionSeparator(format string) string {
+	switch format {
+	case "ssv":
+		return " "
+	case "tsv":
+		return "\t"
+	case "pipes":
+		return "|"
+	default:
+		return ","
+	}
+}
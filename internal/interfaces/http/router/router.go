@@ -1,20 +1,46 @@
 package router
 
 import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/cloudsweep/cloudsweep/internal/application/usecase"
+	"github.com/cloudsweep/cloudsweep/internal/domain/service"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/allocation"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/cache"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/carbonintensity"
 	"github.com/cloudsweep/cloudsweep/internal/infrastructure/config"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/database"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/events"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/hygiene"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/jobs"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/oidc"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/queue"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/ratelimit"
+	"github.com/cloudsweep/cloudsweep/internal/infrastructure/storage"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/graph"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/apierror"
 	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/handler"
 	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/middleware"
+	"github.com/cloudsweep/cloudsweep/internal/interfaces/http/openapi"
 	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
 	"gorm.io/gorm"
 
-	_ "github.com/cloudsweep/cloudsweep/docs" // Swagger docs
+	"github.com/cloudsweep/cloudsweep/docs" // Swagger docs
 )
 
-// NewRouter creates and configures the Gin router
-func NewRouter(db *gorm.DB, queueClient *asynq.Client, cfg *config.Config) *gin.Engine {
+// NewRouter creates and configures the Gin router. A nil limiter disables
+// rate limiting, a nil fair scheduler queues scans without delay and a nil
+// cache computes dashboards on every request. Rate limits follow the reloads
+// of live.
+func NewRouter(db *gorm.DB, queueClient *asynq.Client, inspector *asynq.Inspector, store storage.ObjectStore, limiter *ratelimit.RedisLimiter, fair *queue.FairScheduler, bus *events.Bus, workers *queue.WorkerRegistry, results *cache.Cache, live *config.Live) *gin.Engine {
+	cfg := live.Get()
+
 	// Set Gin mode
 	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -23,64 +49,437 @@ func NewRouter(db *gorm.DB, queueClient *asynq.Client, cfg *config.Config) *gin.
 	r := gin.New()
 
 	// Global middleware
-	r.Use(gin.Recovery())
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		apierror.Abort(c, http.StatusInternalServerError, "internal error")
+	}))
 	r.Use(middleware.Logger())
 	r.Use(middleware.CORS())
 	r.Use(middleware.RequestID())
+	r.Use(middleware.Timeout(cfg.Server.RequestTimeout, "/api/v1/events", "/api/v2/events"))
+
+	// Sessions issued by the SSO callback scope requests to their
+	// organization, and identify their user to the rate limits
+	r.Use(middleware.Session(oidc.NewSigner(cfg.OIDC.SigningKey)))
+
+	rateLimits := func() config.HTTPRateLimitConfig { return live.Get().Server.RateLimit }
+	r.Use(middleware.RateLimit(limiter, "default", rateLimits))
 
 	// Health check
 	healthHandler := handler.NewHealthHandler(db)
 	r.GET("/health", healthHandler.Check)
 	r.GET("/ready", healthHandler.Ready)
 
-	// Swagger documentation
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Cloud changes are refused in read-only mode, set by the configuration
+	// or by an administrator
+	readOnly := database.NewReadOnlyGuard(db, func() config.ReadOnlyConfig { return live.Get().ReadOnly })
+
+	// Process and installation administration, with the admin token
+	admin := r.Group("/admin", middleware.AdminToken(cfg.Server.AdminToken))
+	{
+		adminHandler := handler.NewAdminHandler(live, db, readOnly)
+		admin.POST("/reload", adminHandler.Reload)
+		admin.GET("/read-only", adminHandler.ReadOnly)
+		admin.PUT("/read-only", adminHandler.SetReadOnly)
+		admin.PUT("/organizations/:id/read-only", adminHandler.SetOrganizationReadOnly)
+	}
+
+	// Swagger documentation of each API version, under /swagger/v1/ and
+	// /swagger/v2/ (/swagger/ is v1)
+	swaggerV1 := ginSwagger.WrapHandler(swaggerFiles.Handler)
+	swaggerV2 := ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.InstanceName(docs.SwaggerInfoV2.InstanceName()))
+	r.GET("/swagger/*any", func(c *gin.Context) {
+		serve := swaggerV1
+		version, file, _ := strings.Cut(strings.TrimPrefix(c.Param("any"), "/"), "/")
+		switch version {
+		case "v2":
+			serve = swaggerV2
+			fallthrough
+		case "v1":
+			// The UI files are shared, at the paths of the /swagger/ document
+			c.Request.URL.Path = "/swagger/" + file
+			c.Request.RequestURI = c.Request.URL.RequestURI()
+		}
+		serve(c)
+	})
+
+	// OpenAPI 3.1 documents of each API version for client generators, under
+	// /openapi/v1.json and /openapi/v2.json (/openapi.json is v1)
+	for _, doc := range []struct {
+		paths []string
+		spec  *swag.Spec
+	}{
+		{paths: []string{"/openapi.json", "/openapi/v1.json"}, spec: docs.SwaggerInfo},
+		{paths: []string{"/openapi/v2.json"}, spec: docs.SwaggerInfoV2},
+	} {
+		spec, err := openapi.ConvertV3([]byte(doc.spec.ReadDoc()))
+		if err != nil {
+			log.Printf("OpenAPI document of %s disabled: %v", doc.spec.BasePath, err)
+			continue
+		}
+		for _, path := range doc.paths {
+			r.GET(path, func(c *gin.Context) {
+				c.Data(http.StatusOK, "application/json", spec)
+			})
+		}
+	}
+
+	// Signed downloads for the local storage backend
+	if local, ok := store.(*storage.LocalStore); ok {
+		fileHandler := handler.NewFileHandler(local)
+		r.GET("/files/*key", fileHandler.Download)
+	}
 
-	// API v1
+	// API versions. A version keeps its routes stable: breaking changes are
+	// made in the next one.
+	scans := usecase.NewScanUseCase(
+		database.NewScanRepository(db),
+		database.NewOrganizationRepository(db),
+		queue.NewScanQueue(queueClient, fair, jobs.NewTracker(db)),
+	)
+	policies := usecase.NewPolicyUseCase(
+		database.NewPolicyRepository(db),
+		database.NewResourceRepository(db),
+		database.NewResourceViewRepository(db),
+		database.NewOrganizationRepository(db),
+		database.NewUnitOfWork(db),
+	)
+	resources := usecase.NewResourceUseCase(
+		database.NewResourceRepository(db),
+		database.NewResourceEventRepository(db),
+		database.NewPolicyRepository(db),
+		database.NewResourceViewRepository(db),
+		database.NewOrganizationRepository(db),
+		database.NewCustomResourceTypeRepository(db),
+		database.NewUnitOfWork(db),
+		events.NewInventoryNotifier(bus, results),
+		queue.NewInventoryQueue(queueClient),
+		readOnly,
+	)
+	cleanups := usecase.NewCleanupUseCase(
+		database.NewResourceRepository(db),
+		database.NewResourceEventRepository(db),
+		database.NewJobRepository(db),
+		database.NewCleanupSessionRepository(db),
+		database.NewResourceViewRepository(db),
+		database.NewOrganizationRepository(db),
+		database.NewUnitOfWork(db),
+		queue.NewCleanupTaskQueue(queueClient, jobs.NewTracker(db)),
+		readOnly,
+	)
+	dashboards := usecase.NewDashboardUseCase(
+		database.NewDashboardRepository(db),
+		database.NewResourceRepository(db),
+		database.NewPolicyRepository(db),
+		database.NewResourceViewRepository(db),
+		database.NewRecommendationRepository(db),
+		database.NewOrganizationRepository(db),
+		hygiene.NewScorer(db),
+		allocation.NewAggregator(db),
+	)
+	deps := apiDeps{
+		db:          db,
+		queueClient: queueClient,
+		inspector:   inspector,
+		store:       store,
+		limiter:     limiter,
+		fair:        fair,
+		bus:         bus,
+		workers:     workers,
+		cache:       results,
+		scans:       scans,
+		policies:    policies,
+		resources:   resources,
+		cleanups:    cleanups,
+		dashboards:  dashboards,
+		readOnly:    readOnly,
+		cfg:         cfg,
+		rateLimits:  rateLimits,
+	}
 	v1 := r.Group("/api/v1")
+	if d := cfg.Server.APIV1; !d.Deprecated.IsZero() || !d.Sunset.IsZero() {
+		v1.Use(middleware.Deprecation(d.Deprecated, d.Sunset, "/api/v1", "/api/v2"))
+	}
+	registerAPI(v1, 1, docs.SwaggerInfo, deps)
+	registerAPI(r.Group("/api/v2"), 2, docs.SwaggerInfoV2, deps)
+
+	// GraphQL API of the dashboard frontend, whose queries are checked
+	// against its schema rather than a Swagger document
+	organizations := usecase.NewOrganizationUseCase(
+		database.NewOrganizationRepository(db),
+		database.NewCloudAccountRepository(db),
+	)
+	graphQL := graph.NewHandler(graph.NewResolver(organizations, resources, scans, policies, dashboards))
+	r.GET("/graphql", graphQL)
+	r.POST("/graphql", graphQL)
+
+	// The router cannot match a colon inside a path segment: serve the
+	// custom-method form PUT /policies:apply by routing it again as
+	// /policies/apply. Other unknown routes get a 404.
+	r.NoRoute(func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if c.Request.Method == http.MethodPut && strings.HasPrefix(path, "/api/") && strings.HasSuffix(path, "/policies:apply") {
+			c.Request.URL.Path = strings.TrimSuffix(path, ":apply") + "/apply"
+			r.HandleContext(c)
+			return
+		}
+		apierror.Respond(c, http.StatusNotFound, "route not found")
+	})
+
+	return r
+}
+
+// apiDeps are the dependencies of the API handlers
+type apiDeps struct {
+	db          *gorm.DB
+	queueClient *asynq.Client
+	inspector   *asynq.Inspector
+	store       storage.ObjectStore
+	limiter     *ratelimit.RedisLimiter
+	fair        *queue.FairScheduler
+	bus         *events.Bus
+	workers     *queue.WorkerRegistry
+	cache       *cache.Cache
+	scans       usecase.ScanService
+	policies    usecase.PolicyService
+	resources   usecase.ResourceService
+	cleanups    usecase.CleanupService
+	dashboards  usecase.DashboardService
+	readOnly    service.ReadOnlyGuard
+	cfg         *config.Config
+	rateLimits  func() config.HTTPRateLimitConfig
+}
+
+// registerAPI mounts the routes of an API version, whose requests are
+// validated against its Swagger document. Versions mount the same handlers;
+// a route whose behavior changes in a version mounts a variant of its handler
+// from that version on (version >= N), leaving earlier versions unchanged.
+func registerAPI(api *gin.RouterGroup, version int, doc *swag.Spec, d apiDeps) {
+	// Requests are validated against the generated Swagger document
+	if spec, err := openapi.Load([]byte(doc.ReadDoc())); err != nil {
+		log.Printf("Request validation disabled: %v", err)
+	} else {
+		api.Use(middleware.ValidateRequest(spec))
+	}
 	{
+		// Cloud providers
+		providerHandler := handler.NewProviderHandler()
+		api.GET("/providers", providerHandler.List)
+		api.GET("/resource-types", providerHandler.ListResourceTypes)
+
+		// Policy templates
+		policyTemplateHandler := handler.NewPolicyTemplateHandler()
+		api.GET("/policy-templates", policyTemplateHandler.List)
+
+		// Organizations
+		organizationHandler := handler.NewOrganizationHandler(d.db, d.queueClient, d.cfg.Invitations, d.cache, d.cfg.Cache.SettingsTTL)
+		memberHandler := handler.NewMemberHandler(d.db, d.queueClient, d.cfg.Invitations)
+		ssoHandler := handler.NewSSOHandler(d.db, d.cfg.OIDC)
+		integrationHandler := handler.NewIntegrationHandler(d.db, d.queueClient)
+		slackHandler := handler.NewSlackHandler(d.db, d.cfg.Slack)
+		ticketHandler := handler.NewTicketHandler(d.db)
+		ownerHandler := handler.NewOwnerHandler(d.db, d.queueClient)
+		cloudAccountHandler := handler.NewCloudAccountHandler(d.db, d.queueClient, d.cfg)
+		customResourceTypeHandler := handler.NewCustomResourceTypeHandler(d.db)
+		organizations := api.Group("/organizations")
+		{
+			organizations.POST("", organizationHandler.Create)
+			organizations.POST("/onboard", organizationHandler.Onboard)
+			organizations.GET("", organizationHandler.List)
+			organizations.GET("/:id", organizationHandler.Get)
+			organizations.PUT("/:id", organizationHandler.Update)
+			organizations.DELETE("/:id", organizationHandler.Deactivate)
+			organizations.POST("/:id/reactivate", organizationHandler.Reactivate)
+			organizations.GET("/:id/usage", organizationHandler.Usage)
+			organizations.GET("/:id/members", memberHandler.List)
+			organizations.POST("/:id/members", memberHandler.Invite)
+			organizations.PUT("/:id/members/:user_id", memberHandler.UpdateRole)
+			organizations.PUT("/:id/members/:user_id/preferences", memberHandler.UpdatePreferences)
+			organizations.DELETE("/:id/members/:user_id", memberHandler.Remove)
+			organizations.GET("/:id/sso", ssoHandler.GetConnection)
+			organizations.PUT("/:id/sso", ssoHandler.UpdateConnection)
+			organizations.DELETE("/:id/sso", ssoHandler.DeleteConnection)
+			organizations.GET("/:id/integrations", integrationHandler.List)
+			organizations.GET("/:id/integrations/:provider", integrationHandler.Get)
+			organizations.PUT("/:id/integrations/:provider", integrationHandler.Update)
+			organizations.DELETE("/:id/integrations/:provider", integrationHandler.Delete)
+			organizations.POST("/:id/integrations/:provider/sync", integrationHandler.Sync)
+			organizations.GET("/:id/slack", slackHandler.GetInstallation)
+			organizations.GET("/:id/slack/install", slackHandler.Install)
+			organizations.DELETE("/:id/slack", slackHandler.DeleteInstallation)
+			organizations.GET("/:id/ticketing", ticketHandler.GetIntegration)
+			organizations.PUT("/:id/ticketing", ticketHandler.UpdateIntegration)
+			organizations.DELETE("/:id/ticketing", ticketHandler.DeleteIntegration)
+			organizations.GET("/:id/cloud-accounts", cloudAccountHandler.List)
+			organizations.PUT("/:id/cloud-accounts/:account_id/pricing", cloudAccountHandler.UpdatePricing)
+			organizations.PUT("/:id/cloud-accounts/:account_id/owner", cloudAccountHandler.UpdateOwner)
+			organizations.DELETE("/:id/cloud-accounts/:account_id", cloudAccountHandler.Delete)
+			organizations.POST("/:id/cloud-accounts/:account_id/restore", cloudAccountHandler.Restore)
+			organizations.POST("/:id/cloud-accounts/:account_id/permissions-check", cloudAccountHandler.CheckPermissions)
+			organizations.GET("/:id/owner-mappings", ownerHandler.ListMappings)
+			organizations.PUT("/:id/owner-mappings", ownerHandler.UpdateMappings)
+			organizations.GET("/:id/custom-resource-types", customResourceTypeHandler.List)
+			organizations.POST("/:id/custom-resource-types", customResourceTypeHandler.Create)
+			organizations.PUT("/:id/custom-resource-types/:type_id", customResourceTypeHandler.Update)
+			organizations.DELETE("/:id/custom-resource-types/:type_id", customResourceTypeHandler.Delete)
+			organizations.GET("/:id/settings", organizationHandler.GetSettings)
+			organizations.PUT("/:id/settings", organizationHandler.UpdateSettings)
+		}
+		api.POST("/invitations/accept", memberHandler.Accept)
+
+		// Single sign-on
+		api.GET("/auth/oidc/login", ssoHandler.Login)
+		api.GET("/auth/oidc/callback", ssoHandler.Callback)
+
+		// Slack app: installation callback, slash command and buttons, signed
+		// by Slack
+		api.GET("/slack/oauth/callback", slackHandler.Callback)
+		api.POST("/slack/commands", slackHandler.Command)
+		api.POST("/slack/interactions", slackHandler.Interaction)
+
 		// Resources
-		resourceHandler := handler.NewResourceHandler(db, queueClient)
-		resources := v1.Group("/resources")
+		resourceHandler := handler.NewResourceHandler(d.resources)
+		carbonHandler := handler.NewCarbonHandler(d.db, service.NewCarbonEstimator(d.cfg.Carbon.PUE, d.cfg.Carbon.GridIntensity), intensitySource(d.cfg.Carbon.Intensity))
+		resources := api.Group("/resources")
 		{
 			resources.GET("", resourceHandler.List)
+			resources.POST("/import", resourceHandler.Import)
 			resources.GET("/:id", resourceHandler.Get)
 			resources.DELETE("/:id", resourceHandler.Delete)
+			resources.GET("/:id/carbon-schedule", carbonHandler.Schedule)
+			resources.GET("/:id/history", resourceHandler.History)
+			resources.POST("/:id/restore", resourceHandler.Restore)
+			resources.POST("/:id/reinstate", resourceHandler.Reinstate)
+		}
+
+		// Saved resource views
+		resourceViewHandler := handler.NewResourceViewHandler(d.db)
+		views := api.Group("/resource-views")
+		{
+			views.POST("", resourceViewHandler.Create)
+			views.GET("", resourceViewHandler.List)
+			views.GET("/:id", resourceViewHandler.Get)
+			views.PUT("/:id", resourceViewHandler.Update)
+			views.DELETE("/:id", resourceViewHandler.Delete)
 		}
 
 		// Scans
-		scanHandler := handler.NewScanHandler(db, queueClient)
-		scans := v1.Group("/scans")
+		scanHandler := handler.NewScanHandler(d.scans)
+		scans := api.Group("/scans")
 		{
-			scans.POST("", scanHandler.Create)
+			scans.POST("", middleware.RateLimit(d.limiter, "scans", d.rateLimits), scanHandler.Create)
 			scans.GET("", scanHandler.List)
 			scans.GET("/:id", scanHandler.Get)
 		}
 
 		// Cleanup
-		cleanupHandler := handler.NewCleanupHandler(db, queueClient)
-		v1.POST("/cleanup", cleanupHandler.Execute)
-		v1.POST("/cleanup/preview", cleanupHandler.Preview)
+		cleanupHandler := handler.NewCleanupHandler(d.cleanups)
+		cleanupLimit := middleware.RateLimit(d.limiter, "cleanup", d.rateLimits)
+		api.POST("/cleanup", cleanupLimit, cleanupHandler.Execute)
+		api.POST("/cleanup/preview", cleanupLimit, cleanupHandler.Preview)
+		api.GET("/cleanup/changes", handler.NewIaCChangeHandler(d.db).List)
+		api.GET("/cleanup/tickets", ticketHandler.List)
+		api.GET("/cleanup/jobs/:id/items", cleanupHandler.ListJobItems)
+		api.POST("/cleanup/jobs/:id/items/:resource_id/rollback", cleanupLimit, cleanupHandler.RollbackJobItem)
+		sessions := api.Group("/cleanup/sessions")
+		{
+			sessions.POST("", cleanupHandler.CreateSession)
+			sessions.GET("/:id", cleanupHandler.GetSession)
+			sessions.DELETE("/:id", cleanupHandler.CancelSession)
+			sessions.GET("/:id/items", cleanupHandler.ListSessionItems)
+			sessions.PUT("/:id/items", cleanupHandler.DecideSession)
+			sessions.POST("/:id/execute", cleanupLimit, cleanupHandler.ExecuteSession)
+		}
 
 		// Policies
-		policyHandler := handler.NewPolicyHandler(db)
-		policies := v1.Group("/policies")
+		policyHandler := handler.NewPolicyHandler(d.policies)
+		policies := api.Group("/policies")
 		{
 			policies.POST("", policyHandler.Create)
 			policies.GET("", policyHandler.List)
+			policies.PUT("/apply", policyHandler.Apply)
 			policies.GET("/:id", policyHandler.Get)
 			policies.PUT("/:id", policyHandler.Update)
 			policies.DELETE("/:id", policyHandler.Delete)
+			policies.POST("/:id/restore", policyHandler.Restore)
 			policies.POST("/:id/enable", policyHandler.Enable)
 			policies.POST("/:id/disable", policyHandler.Disable)
+			policies.POST("/:id/simulate", policyHandler.Simulate)
 		}
 
 		// Dashboard / Stats
-		dashboardHandler := handler.NewDashboardHandler(db)
-		v1.GET("/dashboard/summary", dashboardHandler.Summary)
-		v1.GET("/dashboard/savings", dashboardHandler.Savings)
-		v1.GET("/dashboard/carbon", dashboardHandler.Carbon)
+		dashboardHandler := handler.NewDashboardHandler(d.dashboards, d.cache, d.cfg.Cache.DashboardTTL)
+		api.GET("/dashboard/summary", dashboardHandler.Summary)
+		api.GET("/dashboard/savings", dashboardHandler.Savings)
+		api.GET("/dashboard/carbon", dashboardHandler.Carbon)
+		api.GET("/dashboard/score", dashboardHandler.Score)
+		api.GET("/dashboard/ticker", dashboardHandler.Ticker)
+		api.GET("/dashboard/top-offenders", dashboardHandler.TopOffenders)
+		api.GET("/dashboard/allocation", dashboardHandler.Allocation)
+		api.GET("/dashboard/forecast", dashboardHandler.Forecast)
+
+		// Analytics
+		analyticsHandler := handler.NewAnalyticsHandler(d.db)
+		api.GET("/analytics/tags", analyticsHandler.Tags)
+
+		// Owner digest actions
+		digestHandler := handler.NewDigestHandler(d.db, d.cfg.Digest.SigningKey, d.cfg.Digest.SnoozeFor)
+		api.GET("/digest/action", digestHandler.Confirm)
+		api.POST("/digest/action", digestHandler.Action)
+
+		// Background tasks
+		taskHandler := handler.NewTaskHandler(d.db, d.queueClient, d.inspector)
+		tasks := api.Group("/tasks")
+		{
+			tasks.GET("/failures", taskHandler.ListFailures)
+			tasks.POST("/failures/:id/retry", taskHandler.RetryFailure)
+		}
+
+		// Scans, cleanups and exports running in the background
+		jobHandler := handler.NewJobHandler(d.db)
+		api.GET("/jobs", jobHandler.List)
+		api.GET("/jobs/:id", jobHandler.Get)
+
+		// Recommendations
+		recommendationHandler := handler.NewRecommendationHandler(d.db)
+		api.GET("/recommendations", recommendationHandler.List)
+		api.PUT("/recommendations/:id", recommendationHandler.Update)
+
+		// Provider call audit log
+		auditHandler := handler.NewAuditHandler(d.db)
+		api.GET("/audit/provider-calls", auditHandler.ListProviderCalls)
+
+		// Queue monitoring
+		queueHandler := handler.NewQueueHandler(d.inspector)
+		queueGroup := api.Group("/queue")
+		{
+			queueGroup.GET("/stats", queueHandler.Stats)
+			queueGroup.GET("/tasks", queueHandler.ListTasks)
+			queueGroup.POST("/tasks/:id/cancel", queueHandler.CancelTask)
+		}
+
+		// Worker fleet
+		systemHandler := handler.NewSystemHandler(d.workers, d.cache)
+		api.GET("/system/workers", systemHandler.ListWorkers)
+		api.GET("/system/cache", systemHandler.CacheStats)
+
+		// Live events
+		api.GET("/events", handler.NewEventHandler(d.bus).Stream)
+
+		// Exports
+		exportHandler := handler.NewExportHandler(d.db, d.queueClient, d.store, d.cfg.Storage.URLTTL)
+		exports := api.Group("/exports")
+		{
+			exports.POST("", middleware.RateLimit(d.limiter, "exports", d.rateLimits), exportHandler.Create)
+			exports.GET("/:id", exportHandler.Get)
+		}
 	}
+}
 
-	return r
+// intensitySource returns the live carbon intensity forecast source, or nil
+// when none is configured. A typed nil client must not reach the handler.
+func intensitySource(cfg config.CarbonIntensityConfig) service.CarbonIntensitySource {
+	if client := carbonintensity.NewClient(cfg); client != nil {
+		return client
+	}
+	return nil
 }
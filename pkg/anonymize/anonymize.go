@@ -0,0 +1,106 @@
+// Package anonymize provides deterministic pseudonymization of identifying data
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// identifiers match the identifying values found in free-form text, in the
+// order they are replaced: ARNs embed account IDs
+var identifiers = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"arn", regexp.MustCompile(`arn:aws[a-z-]*:[a-z0-9-]+:[a-z0-9-]*:\d{0,12}:[^\s"',;)\]]+`)},
+	{"email", regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{"id", regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)},
+	{"account", regexp.MustCompile(`\b\d{12}\b`)},
+	{"resource", regexp.MustCompile(`\b(?:i|vol|snap|ami|eni|eipalloc|sg|subnet|vpc|nat|igw|rtb|lt|fs)-[0-9a-f]{8,17}\b`)},
+}
+
+// Anonymizer replaces identifying values with salted hashes. A given
+// Anonymizer always maps the same input to the same output, so relations
+// between records (e.g. a resource and its scan) survive anonymization,
+// while the random salt prevents reversing hashes by brute force.
+type Anonymizer struct {
+	key []byte
+}
+
+// New creates an Anonymizer with a random salt
+func New() (*Anonymizer, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return &Anonymizer{key: key}, nil
+}
+
+// Hash returns a stable 16-character pseudonym for value
+func (a *Anonymizer) Hash(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// Pseudonym returns a human-readable pseudonym such as "volume-3f2a9c1e",
+// suitable for screenshots and demos
+func (a *Anonymizer) Pseudonym(kind, value string) string {
+	if value == "" {
+		return ""
+	}
+	return kind + "-" + a.Hash(value)[:8]
+}
+
+// Tags anonymizes tag values. Keys are kept so the tagging structure
+// remains visible to whoever reads the export.
+func (a *Anonymizer) Tags(tags map[string]any) map[string]string {
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		out[k] = a.Hash(fmt.Sprint(v))
+	}
+	return out
+}
+
+// Value anonymizes an arbitrary JSON-like value: strings are hashed,
+// numbers and booleans are kept, maps and slices are walked recursively
+func (a *Anonymizer) Value(v any) any {
+	switch val := v.(type) {
+	case string:
+		return a.Hash(val)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = a.Value(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = a.Value(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
+
+// Text pseudonymizes the identifiers found in free-form text such as log
+// lines: ARNs, email addresses, UUIDs (organization IDs, Azure
+// subscriptions...), AWS account IDs and EC2-style resource IDs. The rest of
+// the text is kept.
+func (a *Anonymizer) Text(s string) string {
+	for _, id := range identifiers {
+		s = id.re.ReplaceAllStringFunc(s, func(value string) string {
+			return a.Pseudonym(id.kind, value)
+		})
+	}
+	return s
+}
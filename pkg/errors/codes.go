@@ -0,0 +1,47 @@
+package errors
+
+// Error codes identify the kind of an error for API clients, which match on
+// them rather than on messages
+const (
+	CodeInvalidInput       = "invalid_input"
+	CodeValidationFailed   = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeQuotaExceeded      = "quota_exceeded"
+	CodeForbidden          = "forbidden"
+	CodeReadOnly           = "read_only"
+	CodeNotFound           = "not_found"
+	CodeAlreadyExists      = "already_exists"
+	CodeConflict           = "conflict"
+	CodeGone               = "gone"
+	CodeRateLimited        = "rate_limited"
+	CodeInternal           = "internal_error"
+	CodeUpstream           = "upstream_error"
+	CodeServiceUnavailable = "service_unavailable"
+	CodeTimeout            = "timeout"
+)
+
+// CodeOf returns the code of an error: that of the first AppError with a code
+// in its chain, else that of the common error it wraps, else CodeInternal
+func CodeOf(err error) string {
+	var appErr *AppError
+	if As(err, &appErr) && appErr.Code != "" {
+		return appErr.Code
+	}
+	switch {
+	case Is(err, ErrNotFound):
+		return CodeNotFound
+	case Is(err, ErrAlreadyExists):
+		return CodeAlreadyExists
+	case Is(err, ErrConflict):
+		return CodeConflict
+	case Is(err, ErrInvalidInput):
+		return CodeInvalidInput
+	case Is(err, ErrUnauthorized):
+		return CodeUnauthorized
+	case Is(err, ErrForbidden):
+		return CodeForbidden
+	case Is(err, ErrServiceUnavailable):
+		return CodeServiceUnavailable
+	}
+	return CodeInternal
+}
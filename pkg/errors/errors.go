@@ -14,6 +14,7 @@ var (
 	ErrForbidden         = errors.New("forbidden")
 	ErrInternalError     = errors.New("internal error")
 	ErrServiceUnavailable = errors.New("service unavailable")
+	ErrConflict          = errors.New("modified concurrently")
 )
 
 // AppError represents an application error with additional context
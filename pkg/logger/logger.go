@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
 // Level represents a log level
@@ -17,7 +20,7 @@ const (
 
 // Logger represents a structured logger
 type Logger struct {
-	level  Level
+	level  atomic.Int32
 	debug  *log.Logger
 	info   *log.Logger
 	warn   *log.Logger
@@ -26,39 +29,40 @@ type Logger struct {
 
 // New creates a new Logger
 func New(level Level) *Logger {
-	return &Logger{
-		level: level,
+	l := &Logger{
 		debug: log.New(os.Stdout, "[DEBUG] ", log.Ldate|log.Ltime|log.Lshortfile),
 		info:  log.New(os.Stdout, "[INFO]  ", log.Ldate|log.Ltime|log.Lshortfile),
 		warn:  log.New(os.Stdout, "[WARN]  ", log.Ldate|log.Ltime|log.Lshortfile),
 		error: log.New(os.Stderr, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile),
 	}
+	l.level.Store(int32(level))
+	return l
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(msg string, args ...any) {
-	if l.level <= LevelDebug {
+	if l.Enabled(LevelDebug) {
 		l.debug.Printf(msg, args...)
 	}
 }
 
 // Info logs an info message
 func (l *Logger) Info(msg string, args ...any) {
-	if l.level <= LevelInfo {
+	if l.Enabled(LevelInfo) {
 		l.info.Printf(msg, args...)
 	}
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(msg string, args ...any) {
-	if l.level <= LevelWarn {
+	if l.Enabled(LevelWarn) {
 		l.warn.Printf(msg, args...)
 	}
 }
 
 // Error logs an error message
 func (l *Logger) Error(msg string, args ...any) {
-	if l.level <= LevelError {
+	if l.Enabled(LevelError) {
 		l.error.Printf(msg, args...)
 	}
 }
@@ -86,7 +90,34 @@ func Error(msg string, args ...any) {
 	defaultLogger.Error(msg, args...)
 }
 
-// SetLevel sets the log level for the default logger
+// Enabled reports whether messages of the level are logged
+func (l *Logger) Enabled(level Level) bool {
+	return Level(l.level.Load()) <= level
+}
+
+// SetLevel sets the log level for the default logger. It is safe to call
+// while logging, e.g. when the configuration is reloaded.
 func SetLevel(level Level) {
-	defaultLogger.level = level
+	defaultLogger.level.Store(int32(level))
+}
+
+// Enabled reports whether the default logger logs messages of the level
+func Enabled(level Level) bool {
+	return defaultLogger.Enabled(level)
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
 }